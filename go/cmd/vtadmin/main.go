@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"io"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -51,6 +52,9 @@ var (
 	enableRBAC     bool
 	disableRBAC    bool
 
+	auditLogPath    string
+	confirmationTTL time.Duration
+
 	cacheRefreshKey string
 
 	traceCloser io.Closer = &noopCloser{}
@@ -124,6 +128,16 @@ func run(cmd *cobra.Command, args []string) {
 		fatal("must explicitly enable or disable RBAC by passing --no-rbac or --rbac")
 	}
 
+	var auditLog io.Writer
+	if auditLogPath != "" {
+		f, err := os.OpenFile(auditLogPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+		if err != nil {
+			fatal(err)
+		}
+
+		auditLog = f
+	}
+
 	for i, cfg := range configs {
 		cluster, err := cfg.Cluster(ctx)
 		if err != nil {
@@ -152,6 +166,8 @@ func run(cmd *cobra.Command, args []string) {
 		HTTPOpts:              httpOpts,
 		RBAC:                  rbacConfig,
 		EnableDynamicClusters: enableDynamicClusters,
+		AuditLog:              auditLog,
+		ConfirmationTTL:       confirmationTTL,
 	})
 	bootSpan.Finish()
 
@@ -202,6 +218,10 @@ func main() {
 	rootCmd.Flags().StringVar(&rbacConfigPath, "rbac-config", "", "path to an RBAC config file. must be set if passing --rbac")
 	rootCmd.Flags().BoolVar(&enableRBAC, "rbac", false, "whether to enable RBAC. must be set if not passing --rbac")
 	rootCmd.Flags().BoolVar(&disableRBAC, "no-rbac", false, "whether to disable RBAC. must be set if not passing --no-rbac")
+	rootCmd.Flags().DurationVar(&confirmationTTL, "confirmation-ttl", vtadmin.DefaultConfirmationTTL, "how long a tablet action waits for its confirmation by a second actor, when the RBAC config requires one. The pending actions are kept in memory: they are lost when vtadmin restarts, and must be confirmed through the vtadmin they were requested from")
+
+	// Audit flags
+	rootCmd.Flags().StringVar(&auditLogPath, "audit-log-path", "", "path to a file where the mutating tablet actions are appended as JSON lines, in addition to the vtadmin log")
 
 	// Global cache flags (N.B. there are also cluster-specific cache flags)
 	cacheRefreshHelp := "instructs a request to ignore any cached data (if applicable) and refresh the cache;" +
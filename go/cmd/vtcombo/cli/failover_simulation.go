@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"net/http"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtcombo"
+)
+
+// failoverSimulationPath is where the faults of the failover simulation
+// are injected, e.g.
+//
+//	curl -X POST 'http://localhost:15000/debug/failover_simulation?action=kill_primary&keyspace=commerce&shard=0'
+const failoverSimulationPath = "/debug/failover_simulation"
+
+// initFailoverSimulation registers the fault injection handler, and runs the
+// failover simulation while vtcombo serves.
+func initFailoverSimulation(fs *vtcombo.FailoverSimulation) {
+	servenv.HTTPHandleFunc(failoverSimulationPath, func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "faults are injected with POST requests", http.StatusMethodNotAllowed)
+			return
+		}
+		msg, err := injectFault(r, fs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, msg)
+	})
+	servenv.OnRun(fs.Start)
+	servenv.OnTerm(fs.Stop)
+}
+
+// injectFault injects the fault of the request. The actions are:
+//   - kill_primary: kills the primary of the keyspace and shard.
+//   - kill_tablet: kills the tablet.
+//   - revive_tablet: brings back a killed tablet.
+//   - stall_replication: stalls the replication of the tablet.
+//   - resume_replication: resumes the replication of the tablet.
+func injectFault(r *http.Request, fs *vtcombo.FailoverSimulation) (string, error) {
+	tablet := r.FormValue("tablet")
+	switch action := r.FormValue("action"); action {
+	case "kill_primary":
+		alias, err := fs.KillPrimary(r.FormValue("keyspace"), r.FormValue("shard"))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("killed primary %v", alias), nil
+	case "kill_tablet":
+		return fmt.Sprintf("killed tablet %v", tablet), fs.KillTablet(tablet)
+	case "revive_tablet":
+		return fmt.Sprintf("revived tablet %v", tablet), fs.ReviveTablet(r.Context(), tablet)
+	case "stall_replication":
+		return fmt.Sprintf("stalled the replication of tablet %v", tablet), fs.StallReplication(tablet, true)
+	case "resume_replication":
+		return fmt.Sprintf("resumed the replication of tablet %v", tablet), fs.StallReplication(tablet, false)
+	default:
+		return "", fmt.Errorf("unknown action %q", action)
+	}
+}
@@ -79,6 +79,10 @@ In particular, it contains:
 	plannerName           string
 	vschemaPersistenceDir string
 
+	failoverSimulation         bool
+	failoverSimulationDelay    = 5 * time.Second
+	failoverSimulationInterval = time.Second
+
 	tpb               vttestpb.VTTestTopology
 	ts                *topo.Server
 	resilientServer   *srvtopo.ResilientServer
@@ -116,6 +120,11 @@ func init() {
 		"this is neither a perfect nor a production solution for vschema persistence. Consider using the --external_topo_server flag if "+
 		"you require a more complete solution. This flag is ignored if --external_topo_server is set.")
 
+	Main.Flags().BoolVar(&failoverSimulation, "failover-simulation", failoverSimulation, "Simulate the replication of the tablets and run a small embedded vtorc, that replaces the primaries that stop serving by their most up to date replica. "+
+		"Faults are injected with POST requests to "+failoverSimulationPath+", to test how the applications handle failovers.")
+	Main.Flags().DurationVar(&failoverSimulationDelay, "failover-simulation-delay", failoverSimulationDelay, "How long a primary doesn't serve before the failover simulation replaces it.")
+	Main.Flags().DurationVar(&failoverSimulationInterval, "failover-simulation-interval", failoverSimulationInterval, "How often the failover simulation checks the primaries.")
+
 	Main.Flags().Var(vttest.TextTopoData(&tpb), "proto_topo", "vttest proto definition of the topology, encoded in compact text format. See vttest.proto for more information.")
 	Main.Flags().Var(vttest.JSONTopoData(&tpb), "json_topo", "vttest proto definition of the topology, encoded in json format. See vttest.proto for more information.")
 
@@ -236,6 +245,9 @@ func run(cmd *cobra.Command, args []string) (err error) {
 	// to be the "internal" protocol that InitTabletMap registers.
 	cmd.Flags().Set("tablet_manager_protocol", "internal")
 	cmd.Flags().Set("tablet_protocol", "internal")
+	if failoverSimulation {
+		initFailoverSimulation(vtcombo.EnableFailoverSimulation(failoverSimulationDelay, failoverSimulationInterval))
+	}
 	uid, err := vtcombo.InitTabletMap(env, ts, &tpb, mysqld, &dbconfigs.GlobalDBConfigs, schemaDir, startMysql, srvTopoCounts)
 	if err != nil {
 		// ensure we start mysql in the event we fail here
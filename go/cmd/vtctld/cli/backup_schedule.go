@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"vitess.io/vitess/go/vt/backupscheduler"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver"
+	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
+)
+
+var backupScheduleConfig string
+
+func init() {
+	Main.Flags().StringVar(&backupScheduleConfig, "backup-schedule-config", backupScheduleConfig, "Path of the JSON file of the backup schedule policies of the keyspaces. When set, vtctld backs up the shards of the keyspaces on the schedules of their policies, removes the backups beyond their retention, and serves the status of the schedules on /debug/backup_schedule. The vtctlds run the policies of a keyspace one at a time under a topo lock, and skip the shards backed up since the scheduled time. The status and the BackupScheduleLastSuccess metric are kept in memory: they only cover the backups this vtctld ran since it started.")
+}
+
+func initBackupSchedule() {
+	// Start the backup scheduler if needed.
+	if backupScheduleConfig == "" {
+		return
+	}
+	config, err := backupscheduler.LoadConfig(backupScheduleConfig)
+	if err != nil {
+		log.Fatalf("unable to load the backup schedule config, error: %v", err)
+	}
+	client := localvtctldclient.New(grpcvtctldserver.NewVtctldServer(env, ts))
+	scheduler := backupscheduler.NewScheduler(ts, client, config)
+	servenv.HTTPHandle("/debug/backup_schedule", scheduler)
+	servenv.OnRun(scheduler.Open)
+	servenv.OnClose(scheduler.Close)
+}
@@ -81,6 +81,15 @@ func run(cmd *cobra.Command, args []string) error {
 	// Start schema manager service.
 	initSchema()
 
+	// Start the backup scheduler.
+	initBackupSchedule()
+
+	// Start the reverse replication checks.
+	initReverseReplicationCheck()
+
+	// Start the copy progress refreshes.
+	initCopyProgressRefresh()
+
 	// And run the server.
 	servenv.RunDefault()
 
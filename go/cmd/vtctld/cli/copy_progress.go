@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+)
+
+var copyProgressRefreshInterval = time.Minute
+
+func init() {
+	Main.Flags().DurationVar(&copyProgressRefreshInterval, "copy-progress-refresh-interval", copyProgressRefreshInterval, "How often the copy progress of the workflows in the copy phase is read, to export it in the WorkflowTableCopyRows and WorkflowTableCopyBytes metrics. Set to 0 to disable the refreshes.")
+}
+
+func initCopyProgressRefresh() {
+	// Start the copy progress refreshes if needed.
+	if copyProgressRefreshInterval <= 0 {
+		return
+	}
+	ws := workflow.NewServer(env, ts, tmclient.NewTabletManagerClient())
+	timer := timer.NewTimer(copyProgressRefreshInterval)
+	timer.Start(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), copyProgressRefreshInterval)
+		defer cancel()
+		if err := ws.RefreshCopyProgress(ctx); err != nil {
+			log.Errorf("Failed to refresh the copy progress of the workflows: %v", err)
+		}
+	})
+	servenv.OnClose(func() { timer.Stop() })
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtctl/workflow"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+)
+
+var reverseReplicationCheckInterval = time.Minute
+
+func init() {
+	Main.Flags().DurationVar(&reverseReplicationCheckInterval, "reverse-replication-check-interval", reverseReplicationCheckInterval, "How often the reverse workflows of the MoveTables and Reshard workflows whose writes are switched are checked, to export the problems found in the WorkflowReverseReplicationErrors metric. Set to 0 to disable the checks.")
+}
+
+func initReverseReplicationCheck() {
+	// Start the reverse replication checks if needed.
+	if reverseReplicationCheckInterval <= 0 {
+		return
+	}
+	ws := workflow.NewServer(env, ts, tmclient.NewTabletManagerClient())
+	timer := timer.NewTimer(reverseReplicationCheckInterval)
+	timer.Start(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), reverseReplicationCheckInterval)
+		defer cancel()
+		if err := ws.CheckReverseReplication(ctx); err != nil {
+			log.Errorf("Failed to check the reverse replication of the workflows: %v", err)
+		}
+	})
+	servenv.OnClose(func() { timer.Stop() })
+}
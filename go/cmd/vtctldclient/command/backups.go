@@ -29,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
@@ -52,6 +53,19 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandBackupShard,
 	}
+	// ExportTables makes an ExportTables gRPC call to a vtctld.
+	ExportTables = &cobra.Command{
+		Use:   "ExportTables [--shards <shards>] [--tables <tables>] [--exclude-tables <tables>] [--tablet-type <type>] [--name <name>] [--format csv] [--concurrency <concurrency>] <keyspace>",
+		Short: "Exports the tables of a keyspace to files on the BackupStorage used by vtctld, one file per table and shard.",
+		Long: `Exports the tables of a keyspace to files on the BackupStorage used by vtctld, one file per table and shard.
+
+Each shard is exported from a tablet of the given type (replica by default), and each table from a consistent snapshot
+of that tablet. The files of a shard are stored under exports/<keyspace>/<shard>/<name>, with a header row naming the
+columns, and NULL written as \N. The GTID position of the snapshot of each table is part of the output.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandExportTables,
+	}
 	// GetBackups makes a GetBackups gRPC call to a vtctld.
 	GetBackups = &cobra.Command{
 		Use:                   "GetBackups [--limit <limit>] [--json] <keyspace/shard>",
@@ -157,6 +171,44 @@ func commandBackupShard(cmd *cobra.Command, args []string) error {
 	}
 }
 
+var exportTablesOptions = struct {
+	Shards        []string
+	Tables        []string
+	ExcludeTables []string
+	TabletType    topodatapb.TabletType
+	Name          string
+	Format        string
+	Concurrency   int32
+}{
+	TabletType: topodatapb.TabletType_REPLICA,
+}
+
+func commandExportTables(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ExportTables(commandCtx, &vtctldatapb.ExportTablesRequest{
+		Keyspace:      cmd.Flags().Arg(0),
+		Shards:        exportTablesOptions.Shards,
+		Tables:        exportTablesOptions.Tables,
+		ExcludeTables: exportTablesOptions.ExcludeTables,
+		TabletType:    exportTablesOptions.TabletType,
+		Name:          exportTablesOptions.Name,
+		Format:        exportTablesOptions.Format,
+		Concurrency:   exportTablesOptions.Concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 var getBackupsOptions = struct {
 	Limit      uint32
 	OutputJSON bool
@@ -292,6 +344,15 @@ func init() {
 	BackupShard.Flags().BoolVar(&backupOptions.UpgradeSafe, "upgrade-safe", false, "Whether to use innodb_fast_shutdown=0 for the backup so it is safe to use for MySQL upgrades.")
 	Root.AddCommand(BackupShard)
 
+	ExportTables.Flags().StringSliceVar(&exportTablesOptions.Shards, "shards", nil, "Shards to export. All the shards of the keyspace are exported if empty.")
+	ExportTables.Flags().StringSliceVar(&exportTablesOptions.Tables, "tables", nil, "Tables to export. All the tables of the keyspace are exported if empty.")
+	ExportTables.Flags().StringSliceVar(&exportTablesOptions.ExcludeTables, "exclude-tables", nil, "Tables not to export.")
+	ExportTables.Flags().Var((*topoproto.TabletTypeFlag)(&exportTablesOptions.TabletType), "tablet-type", "Type of the tablets to export from.")
+	ExportTables.Flags().StringVar(&exportTablesOptions.Name, "name", "", "Name of the export on the BackupStorage. Defaults to the start time of the export.")
+	ExportTables.Flags().StringVar(&exportTablesOptions.Format, "format", "csv", "Format of the exported files. Only csv is supported.")
+	ExportTables.Flags().Int32Var(&exportTablesOptions.Concurrency, "concurrency", 0, "Number of shards exported in parallel. All the shards are exported in parallel if 0.")
+	Root.AddCommand(ExportTables)
+
 	GetBackups.Flags().Uint32VarP(&getBackupsOptions.Limit, "limit", "l", 0, "Retrieve only the most recent N backups.")
 	GetBackups.Flags().BoolVarP(&getBackupsOptions.OutputJSON, "json", "j", false, "Output backup info in JSON format rather than a list of backups.")
 	Root.AddCommand(GetBackups)
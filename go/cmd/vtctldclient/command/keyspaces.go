@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
 	"vitess.io/vitess/go/constants/sidecar"
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/logutil"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vttime"
@@ -95,6 +96,19 @@ Otherwise, the keyspace must be empty (have no shards), or returns an error.`,
 		Args:                  cobra.ExactArgs(2),
 		RunE:                  commandRemoveKeyspaceCell,
 	}
+	// SafeDeleteKeyspace makes a SafeDeleteKeyspace gRPC call to a vtctld.
+	SafeDeleteKeyspace = &cobra.Command{
+		Use:   "SafeDeleteKeyspace [--dry-run] <keyspace>",
+		Short: "Deletes the specified keyspace once nothing depends on it anymore, after taking a final backup of each of its shards.",
+		Long: `Deletes the specified keyspace once nothing depends on it anymore, after taking a final backup of each of its shards.
+
+The keyspace is only deleted if no routing rule references it, no workflow writes to it or reads from it, and none of
+its serving tablets has recently served queries. A final backup of each shard is then taken, and the keyspace, its
+shards and its tablets are deleted from the topology. With --dry-run, only the dependencies are checked.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSafeDeleteKeyspace,
+	}
 	// SetKeyspaceDurabilityPolicy makes a SetKeyspaceDurabilityPolicy gRPC call to a vtcltd.
 	SetKeyspaceDurabilityPolicy = &cobra.Command{
 		Use:   "SetKeyspaceDurabilityPolicy [--durability-policy=policy_name] <keyspace name>",
@@ -109,6 +123,21 @@ SetKeyspaceDurabilityPolicy --durability-policy='semi_sync' customer`,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandSetKeyspaceDurabilityPolicy,
 	}
+	// SetKeyspaceVtorcConfig makes a SetKeyspaceVtorcConfig gRPC call to a vtctld.
+	SetKeyspaceVtorcConfig = &cobra.Command{
+		Use:   "SetKeyspaceVtorcConfig [--disable-discovery] [--disable-recovery] <keyspace name>",
+		Short: "Sets whether VTOrc manages the specified keyspace.",
+		Long: `Sets whether VTOrc manages the specified keyspace.
+Running VTOrc instances pick up the change without having to be restarted.
+With --disable-recovery, VTOrc still detects and reports problems in the keyspace but doesn't fix them.
+With --disable-discovery, VTOrc stops discovering the tablets of the keyspace altogether.
+
+To stop VTOrc from running recoveries in the customer keyspace, you would use the following command:
+SetKeyspaceVtorcConfig --disable-recovery customer`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetKeyspaceVtorcConfig,
+	}
 	// ValidateSchemaKeyspace makes a ValidateSchemaKeyspace gRPC call to a vtctld.
 	ValidateSchemaKeyspace = &cobra.Command{
 		Use:                   "ValidateSchemaKeyspace [--exclude-tables=<exclude_tables>] [--include-views] [--skip-no-primary] [--include-vschema] <keyspace>",
@@ -325,6 +354,29 @@ func commandRemoveKeyspaceCell(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var safeDeleteKeyspaceOptions = struct {
+	DryRun bool
+}{}
+
+func commandSafeDeleteKeyspace(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	ks := cmd.Flags().Arg(0)
+	resp, err := client.SafeDeleteKeyspace(commandCtx, &vtctldatapb.SafeDeleteKeyspaceRequest{
+		Keyspace: ks,
+		DryRun:   safeDeleteKeyspaceOptions.DryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("SafeDeleteKeyspace(%v) error: %w", ks, err)
+	}
+
+	for _, event := range resp.Events {
+		fmt.Println(logutil.EventString(event))
+	}
+
+	return nil
+}
+
 var setKeyspaceDurabilityPolicyOptions = struct {
 	DurabilityPolicy string
 }{}
@@ -350,6 +402,35 @@ func commandSetKeyspaceDurabilityPolicy(cmd *cobra.Command, args []string) error
 	return nil
 }
 
+var setKeyspaceVtorcConfigOptions = struct {
+	DisableDiscovery bool
+	DisableRecovery  bool
+}{}
+
+func commandSetKeyspaceVtorcConfig(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.SetKeyspaceVtorcConfig(commandCtx, &vtctldatapb.SetKeyspaceVtorcConfigRequest{
+		Keyspace: keyspace,
+		VtorcConfig: &topodatapb.VtorcConfig{
+			DisableDiscovery: setKeyspaceVtorcConfigOptions.DisableDiscovery,
+			DisableRecovery:  setKeyspaceVtorcConfigOptions.DisableRecovery,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 var validateSchemaKeyspaceOptions = struct {
 	ExcludeTables  []string
 	IncludeViews   bool
@@ -425,9 +506,16 @@ func init() {
 	RemoveKeyspaceCell.Flags().BoolVarP(&removeKeyspaceCellOptions.Recursive, "recursive", "r", false, "Also delete all tablets in that cell beloning to the specified keyspace.")
 	Root.AddCommand(RemoveKeyspaceCell)
 
+	SafeDeleteKeyspace.Flags().BoolVar(&safeDeleteKeyspaceOptions.DryRun, "dry-run", false, "Only check that nothing depends on the keyspace anymore, without backing it up or deleting it.")
+	Root.AddCommand(SafeDeleteKeyspace)
+
 	SetKeyspaceDurabilityPolicy.Flags().StringVar(&setKeyspaceDurabilityPolicyOptions.DurabilityPolicy, "durability-policy", "none", "Type of durability to enforce for this keyspace. Default is none. Other values include 'semi_sync' and others as dictated by registered plugins.")
 	Root.AddCommand(SetKeyspaceDurabilityPolicy)
 
+	SetKeyspaceVtorcConfig.Flags().BoolVar(&setKeyspaceVtorcConfigOptions.DisableDiscovery, "disable-discovery", false, "Stops VTOrc from discovering the tablets of the keyspace. Implies --disable-recovery.")
+	SetKeyspaceVtorcConfig.Flags().BoolVar(&setKeyspaceVtorcConfigOptions.DisableRecovery, "disable-recovery", false, "Stops VTOrc from running recoveries on the tablets of the keyspace.")
+	Root.AddCommand(SetKeyspaceVtorcConfig)
+
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.IncludeViews, "include-views", false, "Includes views in compared schemas.")
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.IncludeVSchema, "include-vschema", false, "Includes VSchema validation in validation results.")
 	ValidateSchemaKeyspace.Flags().BoolVar(&validateSchemaKeyspaceOptions.SkipNoPrimary, "skip-no-primary", false, "Skips validation on whether or not a primary exists in shards.")
@@ -50,6 +50,7 @@ import (
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/reshard"
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/vdiff"
 	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/workflow"
+	_ "vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/workflowprofile"
 
 	// These imports register the topo factories to use when --server=internal.
 	_ "vitess.io/vitess/go/vt/topo/consultopo"
@@ -19,10 +19,12 @@ package command
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
@@ -85,6 +87,20 @@ that shard.`,
 			skipClientCreationKey: "true",
 		},
 	}
+	// GetDeniedTables makes a GetDeniedTables gRPC request to a vtctld.
+	GetDeniedTables = &cobra.Command{
+		Use:   "GetDeniedTables [--shards=s1,s2...] <keyspace>",
+		Short: "Returns the tables that are denied on the shards of a keyspace, by tablet type, along with when they expire.",
+		Long: `Returns the tables that are denied on the shards of a keyspace, by tablet type, along with when they expire.
+
+The denied tables are set on the source shards when traffic is switched by a
+MoveTables, or with SetShardTabletControl. A denied table without an expire
+time is only lifted when the workflow completes, or with
+SetShardTabletControl --remove.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetDeniedTables,
+	}
 	// GetShard makes a GetShard gRPC request to a vtctld.
 	GetShard = &cobra.Command{
 		Use:                   "GetShard <keyspace/shard>",
@@ -120,7 +136,7 @@ that shard.`,
 	}
 	// SetShardTabletControl makes a SetShardTabletControl gRPC call to a vtctld.
 	SetShardTabletControl = &cobra.Command{
-		Use:   "SetShardTabletControl [--cells=c1,c2...] [--denied-tables=t1,t2,...] [--remove] [--disable-query-service[=0|false]] <keyspace/shard> <tablet_type>",
+		Use:   "SetShardTabletControl [--cells=c1,c2...] [--denied-tables=t1,t2,...] [--denied-tables-ttl=<duration>] [--remove] [--disable-query-service[=0|false]] <keyspace/shard> <tablet_type>",
 		Short: "Sets the TabletControl record for a shard and tablet type. Only use this for an emergency fix or after a finished MoveTables.",
 		Long: `Sets the TabletControl record for a shard and tablet type.
 
@@ -133,7 +149,10 @@ to true or false. This is useful to fix Reshard operations gone wrong.
 
 To change the list of denied tables, specify the --denied-tables parameter with
 the new list. This is useful to fix tables that are being blocked after a
-MoveTables operation.
+MoveTables operation. With --denied-tables-ttl, the primary of the shard lifts
+the given denied tables once the duration has elapsed, so that they cannot be
+left behind by a cutover that did not complete. The tables that a MoveTables
+workflow is still moving, and those of a frozen record, are kept.
 
 To remove the ShardTabletControl record entirely, use the --remove flag. This is
 useful after a MoveTables has finished to remove serving restrictions.`,
@@ -268,6 +287,32 @@ func commandDeleteShards(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var getDeniedTablesOptions = struct {
+	Shards []string
+}{}
+
+func commandGetDeniedTables(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetDeniedTables(commandCtx, &vtctldatapb.GetDeniedTablesRequest{
+		Keyspace: keyspace,
+		Shards:   getDeniedTablesOptions.Shards,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
 func commandGetShard(cmd *cobra.Command, args []string) error {
 	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
 	if err != nil {
@@ -390,6 +435,7 @@ func commandSetShardIsPrimaryServing(cmd *cobra.Command, args []string) error {
 var setShardTabletControlOptions = struct {
 	Cells               []string
 	DeniedTables        []string
+	DeniedTablesTTL     time.Duration
 	Remove              bool
 	DisableQueryService bool
 }{}
@@ -407,7 +453,7 @@ func commandSetShardTabletControl(cmd *cobra.Command, args []string) error {
 
 	cli.FinishedParsing(cmd)
 
-	resp, err := client.SetShardTabletControl(commandCtx, &vtctldatapb.SetShardTabletControlRequest{
+	req := &vtctldatapb.SetShardTabletControlRequest{
 		Keyspace:            keyspace,
 		Shard:               shard,
 		TabletType:          tabletType,
@@ -415,7 +461,12 @@ func commandSetShardTabletControl(cmd *cobra.Command, args []string) error {
 		DeniedTables:        setShardTabletControlOptions.DeniedTables,
 		Remove:              setShardTabletControlOptions.Remove,
 		DisableQueryService: setShardTabletControlOptions.DisableQueryService,
-	})
+	}
+	if setShardTabletControlOptions.DeniedTablesTTL > 0 {
+		req.DeniedTablesTtl = protoutil.DurationToProto(setShardTabletControlOptions.DeniedTablesTTL)
+	}
+
+	resp, err := client.SetShardTabletControl(commandCtx, req)
 	if err != nil {
 		return err
 	}
@@ -661,6 +712,9 @@ func init() {
 	DeleteShards.Flags().BoolVarP(&deleteShardsOptions.Force, "force", "f", false, "Remove the shard even if it cannot be locked; this should only be used for cleanup operations.")
 	Root.AddCommand(DeleteShards)
 
+	GetDeniedTables.Flags().StringSliceVar(&getDeniedTablesOptions.Shards, "shards", nil, "Only return the denied tables of these shards. Defaults to all the shards of the keyspace.")
+	Root.AddCommand(GetDeniedTables)
+
 	Root.AddCommand(GetShard)
 	Root.AddCommand(GetShardReplication)
 	Root.AddCommand(GenerateShardRanges)
@@ -673,6 +727,7 @@ func init() {
 
 	SetShardTabletControl.Flags().StringSliceVarP(&setShardTabletControlOptions.Cells, "cells", "c", nil, "Specifies a comma-separated list of cells to update.")
 	SetShardTabletControl.Flags().StringSliceVar(&setShardTabletControlOptions.DeniedTables, "denied-tables", nil, "Specifies a comma-separated list of tables to add to the denylist (for MoveTables). Each table name is either an exact match, or a regular expression of the form '/regexp/'.")
+	SetShardTabletControl.Flags().DurationVar(&setShardTabletControlOptions.DeniedTablesTTL, "denied-tables-ttl", 0, "Lifts the denied tables automatically after this duration. By default, they are kept until they are removed.")
 	SetShardTabletControl.Flags().BoolVarP(&setShardTabletControlOptions.Remove, "remove", "r", false, "Removes the specified cells for MoveTables operations.")
 	SetShardTabletControl.Flags().BoolVar(&setShardTabletControlOptions.DisableQueryService, "disable-query-service", false, "Sets the DisableQueryService flag in the specified cells. This flag requires --denied-tables and --remove to be unset; if either is set, this flag is ignored.")
 	Root.AddCommand(SetShardTabletControl)
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetTableKillSwitches makes a GetTableKillSwitches gRPC call to a vtctld.
+	GetTableKillSwitches = &cobra.Command{
+		Use:                   "GetTableKillSwitches <keyspace>",
+		Short:                 "Returns the table kill switches of a keyspace, including the recently cleared ones.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetTableKillSwitches,
+	}
+	// SetTableKillSwitch makes a SetTableKillSwitch gRPC call to a vtctld.
+	SetTableKillSwitch = &cobra.Command{
+		Use:   "SetTableKillSwitch [--reads] [--writes] [--reason=<reason>] [--ttl=<duration>] <keyspace> <table>",
+		Short: "Disables the reads and/or the writes of a table on all the tablets of a keyspace, or clears its kill switch.",
+		Long: `Disables the reads and/or the writes of a table on all the tablets of a keyspace, or clears its kill switch.
+
+The queries that the kill switch disables fail with VT09025. The tablets of the
+keyspace enforce the change the next time they read the kill switches from the
+topo. Without --reads and --writes, the kill switch of the table is cleared.
+
+The change is audited by the tablets as made by the client of the vtctld.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandSetTableKillSwitch,
+	}
+)
+
+func commandGetTableKillSwitches(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetTableKillSwitches(commandCtx, &vtctldatapb.GetTableKillSwitchesRequest{
+		Keyspace: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.KillSwitches)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var setTableKillSwitchOptions = struct {
+	Reads  bool
+	Writes bool
+	Reason string
+	TTL    time.Duration
+}{}
+
+func commandSetTableKillSwitch(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.SetTableKillSwitchRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Table:    cmd.Flags().Arg(1),
+		Reads:    setTableKillSwitchOptions.Reads,
+		Writes:   setTableKillSwitchOptions.Writes,
+		Reason:   setTableKillSwitchOptions.Reason,
+	}
+	if setTableKillSwitchOptions.TTL > 0 {
+		req.Ttl = protoutil.DurationToProto(setTableKillSwitchOptions.TTL)
+	}
+
+	resp, err := client.SetTableKillSwitch(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.KillSwitches)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func init() {
+	Root.AddCommand(GetTableKillSwitches)
+
+	SetTableKillSwitch.Flags().BoolVar(&setTableKillSwitchOptions.Reads, "reads", false, "Disables the reads of the table.")
+	SetTableKillSwitch.Flags().BoolVar(&setTableKillSwitchOptions.Writes, "writes", false, "Disables the writes of the table, including the DDLs.")
+	SetTableKillSwitch.Flags().StringVar(&setTableKillSwitchOptions.Reason, "reason", "", "The reason of the kill switch, returned in the errors of the queries it disables.")
+	SetTableKillSwitch.Flags().DurationVar(&setTableKillSwitchOptions.TTL, "ttl", 0, "Clears the kill switch automatically after this duration. By default, it is kept until it is cleared.")
+	Root.AddCommand(SetTableKillSwitch)
+}
@@ -32,8 +32,13 @@ import (
 var (
 	// Validate makes a Validate gRPC call to a vtctld.
 	Validate = &cobra.Command{
-		Use:                   "Validate [--ping-tablets]",
-		Short:                 "Validates that all nodes reachable from the global replication graph, as well as all tablets in discoverable cells, are consistent.",
+		Use:   "Validate [--ping-tablets] [--deep]",
+		Short: "Validates that all nodes reachable from the global replication graph, as well as all tablets in discoverable cells, are consistent.",
+		Long: `Validates that all nodes reachable from the global replication graph, as well as all tablets in discoverable cells, are consistent.
+
+With --deep, the schema, vschema and versions of every keyspace are validated as well, tablets that belong to
+keyspaces or shards that don't exist are reported, and all the issues found are printed as a JSON report. The
+command fails if the report has issues of ERROR severity.`,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.NoArgs,
 		RunE:                  commandValidate,
@@ -58,6 +63,7 @@ var (
 
 var validateOptions = struct {
 	PingTablets bool
+	Deep        bool
 }{}
 
 func commandValidate(cmd *cobra.Command, args []string) error {
@@ -65,11 +71,16 @@ func commandValidate(cmd *cobra.Command, args []string) error {
 
 	resp, err := client.Validate(commandCtx, &vtctldatapb.ValidateRequest{
 		PingTablets: validateOptions.PingTablets,
+		Deep:        validateOptions.Deep,
 	})
 	if err != nil {
 		return err
 	}
 
+	if validateOptions.Deep {
+		return printValidationReport(resp.Report)
+	}
+
 	buf := &strings.Builder{}
 	if err := consumeValidationResults(resp, buf); err != nil {
 		fmt.Printf("Validation results:\n%s", buf.String() /* note: this should have a trailing newline already */)
@@ -137,6 +148,27 @@ func commandValidateShard(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printValidationReport prints the report of a deep validation as JSON, and
+// returns an error if it has issues of ERROR severity.
+func printValidationReport(report []*vtctldatapb.ValidationIssue) error {
+	data, err := cli.MarshalJSONPretty(&vtctldatapb.ValidateResponse{Report: report})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+
+	errCount := 0
+	for _, issue := range report {
+		if issue.Severity == vtctldatapb.ValidationIssue_ERROR {
+			errCount++
+		}
+	}
+	if errCount > 0 {
+		return fmt.Errorf("%d of the %d issues found during validation are errors; see the report above for details", errCount, len(report))
+	}
+	return nil
+}
+
 func consumeValidationResults(resp *vtctldatapb.ValidateResponse, buf *strings.Builder) error {
 	for _, result := range resp.Results {
 		fmt.Fprintf(buf, "- %s\n", result)
@@ -196,6 +228,7 @@ func init() {
 	pingTabletsUsage := "Indicates whether all tablets should be pinged during the validation process."
 
 	Validate.Flags().BoolVarP(&validateOptions.PingTablets, pingTabletsName, pingTabletsShort, pingTabletsDefault, pingTabletsUsage)
+	Validate.Flags().BoolVar(&validateOptions.Deep, "deep", false, "Also validate the schema, vschema and versions of every keyspace and look for orphan tablets, then print all the issues found as a JSON report.")
 	ValidateKeyspace.Flags().BoolVarP(&validateKeyspaceOptions.PingTablets, pingTabletsName, pingTabletsShort, pingTabletsDefault, pingTabletsUsage)
 	ValidateShard.Flags().BoolVarP(&validateShardOptions.PingTablets, pingTabletsName, pingTabletsShort, pingTabletsDefault, pingTabletsUsage)
 
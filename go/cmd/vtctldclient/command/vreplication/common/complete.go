@@ -3,20 +3,24 @@ package common
 import (
 	"bytes"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
 
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 var CompleteOptions = struct {
-	KeepData         bool
-	KeepRoutingRules bool
-	RenameTables     bool
-	DryRun           bool
-	Shards           []string
+	KeepData                         bool
+	KeepRoutingRules                 bool
+	RenameTables                     bool
+	DryRun                           bool
+	Shards                           []string
+	RequireHealthyReverseReplication bool
+	MaxReverseReplicationLag         time.Duration
 }{}
 
 func GetCompleteCommand(opts *SubCommandsOpts) *cobra.Command {
@@ -30,6 +34,8 @@ func GetCompleteCommand(opts *SubCommandsOpts) *cobra.Command {
 		Args:                  cobra.NoArgs,
 		RunE:                  commandComplete,
 	}
+	cmd.Flags().BoolVar(&CompleteOptions.RequireHealthyReverseReplication, "require-healthy-reverse-replication", false, "Refuse to complete the workflow if the reverse workflow, which is needed to roll back the traffic switch, is missing, not running, or lagging.")
+	cmd.Flags().DurationVar(&CompleteOptions.MaxReverseReplicationLag, "max-reverse-replication-lag", MaxReplicationLagDefault, "The maximum reverse replication lag allowed when --require-healthy-reverse-replication is set.")
 	return cmd
 }
 
@@ -41,12 +47,14 @@ func commandComplete(cmd *cobra.Command, args []string) error {
 	cli.FinishedParsing(cmd)
 
 	req := &vtctldatapb.MoveTablesCompleteRequest{
-		Workflow:         BaseOptions.Workflow,
-		TargetKeyspace:   BaseOptions.TargetKeyspace,
-		KeepData:         CompleteOptions.KeepData,
-		KeepRoutingRules: CompleteOptions.KeepRoutingRules,
-		RenameTables:     CompleteOptions.RenameTables,
-		DryRun:           CompleteOptions.DryRun,
+		Workflow:                         BaseOptions.Workflow,
+		TargetKeyspace:                   BaseOptions.TargetKeyspace,
+		KeepData:                         CompleteOptions.KeepData,
+		KeepRoutingRules:                 CompleteOptions.KeepRoutingRules,
+		RenameTables:                     CompleteOptions.RenameTables,
+		DryRun:                           CompleteOptions.DryRun,
+		RequireHealthyReverseReplication: CompleteOptions.RequireHealthyReverseReplication,
+		MaxReverseReplicationLag:         protoutil.DurationToProto(CompleteOptions.MaxReverseReplicationLag),
 	}
 	resp, err := GetClient().MoveTablesComplete(GetCommandCtx(), req)
 	if err != nil {
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -67,6 +68,7 @@ var (
 		MySQLServerVersion           string
 		TruncateUILen                int
 		TruncateErrLen               int
+		Profile                      string
 	}{}
 )
 
@@ -133,6 +135,10 @@ func ParseTabletTypes(cmd *cobra.Command) error {
 		return fmt.Errorf("no tablet-types flag found")
 	}
 	if !ttf.Changed {
+		if usesProfileFor(cmd, "tablet-types") {
+			CreateOptions.TabletTypes = nil
+			return nil
+		}
 		CreateOptions.TabletTypes = tabletTypesDefault
 	} else if strings.TrimSpace(ttf.Value.String()) == "" {
 		return fmt.Errorf("invalid tablet-types value, at least one valid tablet type must be specified")
@@ -140,7 +146,38 @@ func ParseTabletTypes(cmd *cobra.Command) error {
 	return nil
 }
 
+// usesProfileFor returns true if the value of the named flag should be taken
+// from the workflow profile, because a profile is used and the flag was not set.
+func usesProfileFor(cmd *cobra.Command, name string) bool {
+	if CreateOptions.Profile == "" {
+		return false
+	}
+	f := cmd.Flags().Lookup(name)
+	return f == nil || !f.Changed
+}
+
+// ExplicitSettings returns the names of the boolean settings of the create
+// request that were set with flags, so that the workflow profile doesn't
+// override them, even when they are false.
+func ExplicitSettings(cmd *cobra.Command) []string {
+	var settings []string
+	for flag, setting := range map[string]string{
+		"defer-secondary-keys": "defer_secondary_keys",
+		"stop-after-copy":      "stop_after_copy",
+	} {
+		if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+			settings = append(settings, setting)
+		}
+	}
+	slices.Sort(settings)
+	return settings
+}
+
 func validateOnDDL(cmd *cobra.Command) error {
+	if usesProfileFor(cmd, "on-ddl") {
+		CreateOptions.OnDDL = ""
+		return nil
+	}
 	if _, ok := binlogdatapb.OnDDLAction_value[strings.ToUpper(CreateOptions.OnDDL)]; !ok {
 		return fmt.Errorf("invalid on-ddl value: %s", CreateOptions.OnDDL)
 	}
@@ -172,6 +209,9 @@ func GetOutputFormat(cmd *cobra.Command) (string, error) {
 
 func GetTabletSelectionPreference(cmd *cobra.Command) tabletmanagerdatapb.TabletSelectionPreference {
 	tsp := tabletmanagerdatapb.TabletSelectionPreference_ANY
+	if usesProfileFor(cmd, "tablet-types-in-preference-order") {
+		return tsp
+	}
 	if CreateOptions.TabletTypesInPreferenceOrder {
 		tsp = tabletmanagerdatapb.TabletSelectionPreference_INORDER
 	}
@@ -199,6 +239,10 @@ func OutputStatusResponse(resp *vtctldatapb.WorkflowStatusResponse, format strin
 		}
 		tout.WriteString("\nTraffic State: ")
 		tout.WriteString(resp.TrafficState)
+		for _, rrErr := range resp.ReverseReplicationErrors {
+			tout.WriteString("\nWARNING: ")
+			tout.WriteString(rrErr)
+		}
 		output = tout.Bytes()
 	}
 	fmt.Println(string(output))
@@ -224,6 +268,12 @@ func AddCommonCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&CreateOptions.StopAfterCopy, "stop-after-copy", false, "Stop the workflow after it's finished copying the existing rows and before it starts replicating changes.")
 }
 
+// AddProfileFlag adds the flag to create a workflow using the settings of a
+// workflow profile.
+func AddProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&CreateOptions.Profile, "profile", "", "Name of the workflow profile providing the settings that are not explicitly set with flags (see WorkflowProfile).")
+}
+
 var SwitchTrafficOptions = struct {
 	Cells                     []string
 	TabletTypes               []topodatapb.TabletType
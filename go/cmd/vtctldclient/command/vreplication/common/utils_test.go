@@ -110,11 +110,29 @@ func TestParseAndValidateCreateOptions(t *testing.T) {
 				require.Equal(t, cells, common.CreateOptions.Cells)
 			},
 		},
+		{
+			name: "profile",
+			setFunc: func(cmd *cobra.Command) error {
+				profileFlag := cmd.Flags().Lookup("profile")
+				if err := profileFlag.Value.Set("bulk"); err != nil {
+					return err
+				}
+				profileFlag.Changed = true
+				return nil
+			},
+			wantErr: false,
+			checkFunc: func() {
+				// The settings that were not set with flags are left to the profile.
+				require.Empty(t, common.CreateOptions.TabletTypes)
+				require.Empty(t, common.CreateOptions.OnDDL)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cmd := &cobra.Command{}
 			common.AddCommonCreateFlags(cmd)
+			common.AddProfileFlag(cmd)
 			test := func() error {
 				if tt.setFunc != nil {
 					if err := tt.setFunc(cmd); err != nil {
@@ -136,6 +154,17 @@ func TestParseAndValidateCreateOptions(t *testing.T) {
 	}
 }
 
+func TestExplicitSettings(t *testing.T) {
+	cmd := &cobra.Command{}
+	common.AddCommonCreateFlags(cmd)
+	require.Empty(t, common.ExplicitSettings(cmd))
+
+	for _, name := range []string{"stop-after-copy", "defer-secondary-keys"} {
+		require.NoError(t, cmd.Flags().Set(name, "false"))
+	}
+	require.Equal(t, []string{"defer_secondary_keys", "stop_after_copy"}, common.ExplicitSettings(cmd))
+}
+
 // SetupLocalVtctldClient sets up a local or internal VtctldServer and
 // VtctldClient for tests. It uses a memorytopo instance which contains
 // the cells provided.
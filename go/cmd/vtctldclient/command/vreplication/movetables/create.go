@@ -39,9 +39,13 @@ var (
 		SourceTimeZone      string
 		NoRoutingRules      bool
 		AtomicCopy          bool
+		UniqueKeyColumns    []string
 		WorkflowOptions     vtctldatapb.WorkflowOptions
 	}{}
 
+	// uniqueKeyColumns is the parsed form of createOptions.UniqueKeyColumns.
+	uniqueKeyColumns map[string]string
+
 	// create makes a MoveTablesCreate gRPC call to a vtctld.
 	create = &cobra.Command{
 		Use:                   "create",
@@ -78,6 +82,10 @@ var (
 			if err := checkAtomicCopyOptions(); err != nil {
 				return err
 			}
+			var err error
+			if uniqueKeyColumns, err = parseUniqueKeyColumns(createOptions.UniqueKeyColumns); err != nil {
+				return err
+			}
 			return nil
 		},
 		RunE: commandCreate,
@@ -108,9 +116,12 @@ func commandCreate(cmd *cobra.Command, args []string) error {
 		DeferSecondaryKeys:        common.CreateOptions.DeferSecondaryKeys,
 		AutoStart:                 common.CreateOptions.AutoStart,
 		StopAfterCopy:             common.CreateOptions.StopAfterCopy,
+		Profile:                   common.CreateOptions.Profile,
+		ExplicitSettings:          common.ExplicitSettings(cmd),
 		NoRoutingRules:            createOptions.NoRoutingRules,
 		AtomicCopy:                createOptions.AtomicCopy,
 		WorkflowOptions:           &createOptions.WorkflowOptions,
+		UniqueKeyColumns:          uniqueKeyColumns,
 	}
 
 	resp, err := common.GetClient().MoveTablesCreate(common.GetCommandCtx(), req)
@@ -122,3 +133,23 @@ func commandCreate(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// parseUniqueKeyColumns parses values of the form table:col1,col2 into a map
+// of table name to its comma-separated unique key columns.
+func parseUniqueKeyColumns(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	uniqueKeyColumns := make(map[string]string, len(values))
+	for _, value := range values {
+		table, columns, ok := strings.Cut(value, ":")
+		if !ok || table == "" || columns == "" {
+			return nil, fmt.Errorf("invalid unique key columns %q, expected table:col1,col2", value)
+		}
+		if _, exists := uniqueKeyColumns[table]; exists {
+			return nil, fmt.Errorf("unique key columns specified more than once for table %s", table)
+		}
+		uniqueKeyColumns[table] = columns
+	}
+	return uniqueKeyColumns, nil
+}
@@ -69,6 +69,8 @@ func commandReshardCreate(cmd *cobra.Command, args []string) error {
 		DeferSecondaryKeys:        common.CreateOptions.DeferSecondaryKeys,
 		AutoStart:                 common.CreateOptions.AutoStart,
 		StopAfterCopy:             common.CreateOptions.StopAfterCopy,
+		Profile:                   common.CreateOptions.Profile,
+		ExplicitSettings:          common.ExplicitSettings(cmd),
 		SourceShards:              reshardCreateOptions.sourceShards,
 		TargetShards:              reshardCreateOptions.targetShards,
 		SkipSchemaCopy:            reshardCreateOptions.skipSchemaCopy,
@@ -85,6 +87,7 @@ func commandReshardCreate(cmd *cobra.Command, args []string) error {
 
 func registerCreateCommand(root *cobra.Command) {
 	common.AddCommonCreateFlags(reshardCreate)
+	common.AddProfileFlag(reshardCreate)
 	reshardCreate.Flags().StringSliceVar(&reshardCreateOptions.sourceShards, "source-shards", nil, "Source shards.")
 	reshardCreate.Flags().StringSliceVar(&reshardCreateOptions.targetShards, "target-shards", nil, "Target shards.")
 	reshardCreate.Flags().BoolVar(&reshardCreateOptions.skipSchemaCopy, "skip-schema-copy", false, "Skip copying the schema from the source shards to the target shards.")
@@ -43,7 +43,8 @@ var (
 	}{}
 
 	workflowShowOptions = struct {
-		IncludeLogs bool
+		IncludeLogs     bool
+		IncludeProgress bool
 	}{}
 )
 
@@ -69,6 +70,7 @@ func registerCommands(root *cobra.Command) {
 	show.Flags().StringVarP(&baseOptions.Workflow, "workflow", "w", "", "The workflow you want the details for.")
 	show.MarkFlagRequired("workflow")
 	show.Flags().BoolVar(&workflowShowOptions.IncludeLogs, "include-logs", true, "Include recent logs for the workflow.")
+	show.Flags().BoolVar(&workflowShowOptions.IncludeProgress, "include-progress", false, "Include the copy progress of the tables that are still being copied, with the estimated rows and bytes remaining.")
 	common.AddShardSubsetFlag(show, &baseOptions.Shards)
 	base.AddCommand(show)
 
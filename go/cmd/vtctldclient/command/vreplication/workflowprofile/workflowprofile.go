@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflowprofile
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/cmd/vtctldclient/command/vreplication/common"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// base is the base command for all actions related to workflow profiles.
+	base = &cobra.Command{
+		Use:                   "WorkflowProfile [command] [command-flags]",
+		Short:                 "Manage the named workflow profiles that MoveTables and Reshard workflows can be created with.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"workflowprofile"},
+		Args:                  cobra.ExactArgs(1),
+	}
+)
+
+var profileOptions struct {
+	Name                         string
+	Cells                        []string
+	TabletTypes                  []topodatapb.TabletType
+	TabletTypesInPreferenceOrder bool
+	ExcludeTables                []string
+	OnDDL                        string
+	StopAfterCopy                bool
+	DeferSecondaryKeys           bool
+	RelayLogMaxItems             int64
+	RelayLogMaxSize              int64
+	ParallelInsertWorkers        int64
+	ThrottleRatio                float64
+	ThrottleDuration             time.Duration
+}
+
+var save = &cobra.Command{
+	Use:                   "save",
+	Short:                 "Create or replace a workflow profile.",
+	Example:               `vtctldclient --server localhost:15999 WorkflowProfile save --name bulk --tablet-types rdonly --on-ddl stop --defer-secondary-keys`,
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"Save"},
+	Args:                  cobra.NoArgs,
+	RunE:                  commandSave,
+}
+
+func commandSave(cmd *cobra.Command, args []string) error {
+	if profileOptions.OnDDL != "" {
+		if _, ok := binlogdatapb.OnDDLAction_value[strings.ToUpper(profileOptions.OnDDL)]; !ok {
+			return fmt.Errorf("invalid on-ddl value: %s", profileOptions.OnDDL)
+		}
+	}
+	if profileOptions.ThrottleRatio < 0 || profileOptions.ThrottleRatio > 1 {
+		return fmt.Errorf("invalid throttle-ratio value %v, it must be between 0 and 1", profileOptions.ThrottleRatio)
+	}
+	cli.FinishedParsing(cmd)
+
+	profile := &vtctldatapb.WorkflowProfile{
+		Cells:                 profileOptions.Cells,
+		TabletTypes:           profileOptions.TabletTypes,
+		ExcludeTables:         profileOptions.ExcludeTables,
+		OnDdl:                 strings.ToUpper(profileOptions.OnDDL),
+		StopAfterCopy:         profileOptions.StopAfterCopy,
+		DeferSecondaryKeys:    profileOptions.DeferSecondaryKeys,
+		RelayLogMaxItems:      profileOptions.RelayLogMaxItems,
+		RelayLogMaxSize:       profileOptions.RelayLogMaxSize,
+		ParallelInsertWorkers: profileOptions.ParallelInsertWorkers,
+		ThrottleRatio:         profileOptions.ThrottleRatio,
+	}
+	if profileOptions.ThrottleDuration > 0 {
+		profile.ThrottleDuration = protoutil.DurationToProto(profileOptions.ThrottleDuration)
+	}
+	if profileOptions.TabletTypesInPreferenceOrder {
+		profile.TabletSelectionPreference = tabletmanagerdatapb.TabletSelectionPreference_INORDER
+	}
+	req := &vtctldatapb.WorkflowProfileSaveRequest{
+		Name:    profileOptions.Name,
+		Profile: profile,
+	}
+	if _, err := common.GetClient().WorkflowProfileSave(common.GetCommandCtx(), req); err != nil {
+		return err
+	}
+	fmt.Printf("Workflow profile %s saved successfully\n", req.Name)
+	return nil
+}
+
+var deleteCmd = &cobra.Command{
+	Use:                   "delete",
+	Short:                 "Delete a workflow profile.",
+	Example:               `vtctldclient --server localhost:15999 WorkflowProfile delete --name bulk`,
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"Delete"},
+	Args:                  cobra.NoArgs,
+	RunE:                  commandDelete,
+}
+
+func commandDelete(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.WorkflowProfileDeleteRequest{
+		Name: profileOptions.Name,
+	}
+	if _, err := common.GetClient().WorkflowProfileDelete(common.GetCommandCtx(), req); err != nil {
+		return err
+	}
+	fmt.Printf("Workflow profile %s deleted successfully\n", req.Name)
+	return nil
+}
+
+var show = &cobra.Command{
+	Use:                   "show",
+	Short:                 "Show the settings of a workflow profile.",
+	Example:               `vtctldclient --server localhost:15999 WorkflowProfile show --name bulk`,
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"Show"},
+	Args:                  cobra.NoArgs,
+	RunE:                  commandShow,
+}
+
+func commandShow(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.WorkflowProfileShowRequest{
+		Name: profileOptions.Name,
+	}
+	resp, err := common.GetClient().WorkflowProfileShow(common.GetCommandCtx(), req)
+	if err != nil {
+		return err
+	}
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+var list = &cobra.Command{
+	Use:                   "list",
+	Short:                 "List the names of all workflow profiles.",
+	Example:               `vtctldclient --server localhost:15999 WorkflowProfile list`,
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"List"},
+	Args:                  cobra.NoArgs,
+	RunE:                  commandList,
+}
+
+func commandList(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := common.GetClient().WorkflowProfileList(common.GetCommandCtx(), &vtctldatapb.WorkflowProfileListRequest{})
+	if err != nil {
+		return err
+	}
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func registerCommands(root *cobra.Command) {
+	root.AddCommand(base)
+
+	save.Flags().StringVar(&profileOptions.Name, "name", "", "Name of the workflow profile.")
+	save.MarkFlagRequired("name")
+	save.Flags().StringSliceVarP(&profileOptions.Cells, "cells", "c", nil, "Cells and/or CellAliases to copy table data from.")
+	save.Flags().Var((*topoproto.TabletTypeListFlag)(&profileOptions.TabletTypes), "tablet-types", "Source tablet types to replicate table data from (e.g. PRIMARY,REPLICA,RDONLY).")
+	save.Flags().BoolVar(&profileOptions.TabletTypesInPreferenceOrder, "tablet-types-in-preference-order", true, "When performing source tablet selection, look for candidates in the type order as they are listed in the tablet-types flag.")
+	save.Flags().StringSliceVar(&profileOptions.ExcludeTables, "exclude-tables", nil, "Source tables that MoveTables workflows never copy.")
+	save.Flags().StringVar(&profileOptions.OnDDL, "on-ddl", "", "What to do when DDL is encountered in the VReplication stream. Possible values are IGNORE, STOP, EXEC, and EXEC_IGNORE.")
+	save.Flags().BoolVar(&profileOptions.StopAfterCopy, "stop-after-copy", false, "Stop the workflow after it's finished copying the existing rows and before it starts replicating changes.")
+	save.Flags().BoolVar(&profileOptions.DeferSecondaryKeys, "defer-secondary-keys", false, "Defer secondary index creation for a table until after it has been copied.")
+	save.Flags().Int64Var(&profileOptions.RelayLogMaxItems, "relay-log-max-items", 0, "Maximum number of rows buffered and applied in a batch on the target tablets. 0 uses the relay_log_max_items flag of the tablets.")
+	save.Flags().Int64Var(&profileOptions.RelayLogMaxSize, "relay-log-max-size", 0, "Maximum size, in bytes, buffered and applied in a batch on the target tablets. 0 uses the relay_log_max_size flag of the tablets.")
+	save.Flags().Int64Var(&profileOptions.ParallelInsertWorkers, "parallel-insert-workers", 0, "Number of parallel insertion workers of the copy phase. 0 uses the vreplication-parallel-insert-workers flag of the tablets.")
+	save.Flags().Float64Var(&profileOptions.ThrottleRatio, "throttle-ratio", 0, "Throttle the workflows created with the profile with this ratio, between 0 and 1, in their target keyspace. 0 doesn't throttle them.")
+	save.Flags().DurationVar(&profileOptions.ThrottleDuration, "throttle-duration", 0, "How long the workflows created with the profile are throttled for with --throttle-ratio. Defaults to one hour.")
+	base.AddCommand(save)
+
+	deleteCmd.Flags().StringVar(&profileOptions.Name, "name", "", "Name of the workflow profile.")
+	deleteCmd.MarkFlagRequired("name")
+	base.AddCommand(deleteCmd)
+
+	show.Flags().StringVar(&profileOptions.Name, "name", "", "Name of the workflow profile.")
+	show.MarkFlagRequired("name")
+	base.AddCommand(show)
+
+	base.AddCommand(list)
+}
+
+func init() {
+	common.RegisterCommandHandler("WorkflowProfile", registerCommands)
+}
@@ -149,6 +149,13 @@ func (c *errorClient) Prepare(ctx context.Context, session *vtgatepb.Session, sq
 	return c.fallbackClient.Prepare(ctx, session, sql, bindVariables)
 }
 
+func (c *errorClient) GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) ([]byte, error) {
+	if err := requestToError(sql); err != nil {
+		return nil, err
+	}
+	return c.fallbackClient.GetPlan(ctx, session, sql, bindVariables)
+}
+
 func (c *errorClient) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
 	return c.fallbackClient.CloseSession(ctx, session)
 }
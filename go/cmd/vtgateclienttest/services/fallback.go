@@ -56,6 +56,10 @@ func (c fallbackClient) Prepare(ctx context.Context, session *vtgatepb.Session,
 	return c.fallback.Prepare(ctx, session, sql, bindVariables)
 }
 
+func (c fallbackClient) GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) ([]byte, error) {
+	return c.fallback.GetPlan(ctx, session, sql, bindVariables)
+}
+
 func (c fallbackClient) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
 	return c.fallback.CloseSession(ctx, session)
 }
@@ -68,6 +72,10 @@ func (c fallbackClient) VStream(ctx context.Context, tabletType topodatapb.Table
 	return c.fallback.VStream(ctx, tabletType, vgtid, filter, flags, send)
 }
 
+func (c fallbackClient) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	return c.fallback.GetVStreamCheckpoints(ctx, streamName)
+}
+
 func (c fallbackClient) HandlePanic(err *error) {
 	c.fallback.HandlePanic(err)
 }
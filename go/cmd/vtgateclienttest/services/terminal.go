@@ -67,6 +67,10 @@ func (c *terminalClient) Prepare(ctx context.Context, session *vtgatepb.Session,
 	return session, nil, errTerminal
 }
 
+func (c *terminalClient) GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) ([]byte, error) {
+	return nil, errTerminal
+}
+
 func (c *terminalClient) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
 	return errTerminal
 }
@@ -79,6 +83,10 @@ func (c *terminalClient) VStream(ctx context.Context, tabletType topodatapb.Tabl
 	return errTerminal
 }
 
+func (c *terminalClient) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	return nil, errTerminal
+}
+
 func (c *terminalClient) HandlePanic(err *error) {
 	if x := recover(); x != nil {
 		log.Errorf("Uncaught panic:\n%v\n%s", x, tb.Stack(4))
@@ -92,6 +92,9 @@ func addStatusParts() {
 func init() {
 	servenv.RegisterDefaultFlags()
 	servenv.RegisterFlags()
+	servenv.RegisterGRPCServerFlags()
+	servenv.RegisterGRPCServerAuthFlags()
+	servenv.RegisterServiceMapFlag()
 
 	servenv.MoveFlagsToCobraCommand(Main)
 
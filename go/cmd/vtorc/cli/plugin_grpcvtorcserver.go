@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+// Import and register the gRPC vtorc server
+
+import (
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtorc/grpcvtorcserver"
+)
+
+func init() {
+	servenv.InitServiceMap("grpc", "vtorc")
+	servenv.OnRun(func() {
+		if servenv.GRPCCheckServiceMap("vtorc") {
+			grpcvtorcserver.StartServer(servenv.GRPCServer)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/grpccommon"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vtgate/replay"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+
+	// Import and register the gRPC vtgateconn client
+	_ "vitess.io/vitess/go/vt/vtgate/grpcvtgateconn"
+)
+
+var (
+	server        string
+	queryLog      = "-"
+	speed         = 1.0
+	readOnly      bool
+	fullBindVars  bool
+	timeout       = 30 * time.Second
+	deadline      time.Duration
+	maxMismatches = 100
+	sessionIdle   = replay.DefaultSessionIdleTimeout
+
+	Main = &cobra.Command{
+		Use:   "vtreplay",
+		Short: "vtreplay replays a vtgate query log against a vtgate, and reports the latency and error deltas.",
+		Long: `vtreplay replays a vtgate query log against a vtgate, and reports the latency and error deltas.
+
+The query log must be written in JSON, with --querylog-format=json. Queries of the same
+session are replayed in order on a single session, and different sessions are replayed
+concurrently, at the pace they were recorded multiplied by --speed.
+
+String bind variables are only logged in full when the log is captured from the
+/debug/querylog endpoint with the "full" parameter; pass --full-bind-vars for such logs.
+Queries whose bind variables were not logged in full are skipped.`,
+		Example: `vtreplay --server vtgate:15991 --querylog /var/log/vtgate/querylog.json --speed 2 --read-only`,
+		Args:    cobra.NoArgs,
+		Version: servenv.AppVersion.String(),
+		RunE:    run,
+	}
+)
+
+func init() {
+	servenv.MoveFlagsToCobraCommand(Main)
+
+	Main.Flags().StringVar(&server, "server", server, "vtgate server to replay the queries against")
+	Main.Flags().StringVar(&queryLog, "querylog", queryLog, "query log file to replay, - for stdin")
+	Main.Flags().Float64Var(&speed, "speed", speed, "replay speed multiplier, 0 replays the queries as fast as possible")
+	Main.Flags().BoolVar(&readOnly, "read-only", readOnly, "only replay the queries that cannot modify any data")
+	Main.Flags().BoolVar(&fullBindVars, "full-bind-vars", fullBindVars, "the query log was captured with full bind variables")
+	Main.Flags().DurationVar(&timeout, "timeout", timeout, "timeout for every replayed query")
+	Main.Flags().DurationVar(&deadline, "deadline", deadline, "maximum duration of the replay, 0 for no limit")
+	Main.Flags().IntVar(&maxMismatches, "max-mismatches", maxMismatches, "maximum number of error mismatches to report")
+	Main.Flags().DurationVar(&sessionIdle, "session-idle-timeout", sessionIdle, "time, as recorded in the query log, after which a session without queries is considered ended")
+
+	Main.MarkFlagRequired("server")
+
+	acl.RegisterFlags(Main.Flags())
+	grpccommon.RegisterFlags(Main.Flags())
+}
+
+// session replays queries on a vtgate session.
+type session struct {
+	sn *vtgateconn.VTGateSession
+}
+
+func (s *session) Execute(ctx context.Context, sql string, bindVars map[string]*querypb.BindVariable) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, err := s.sn.Execute(ctx, sql, bindVars)
+	return err
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	logger := logutil.NewConsoleLogger()
+	cmd.SetOutput(logutil.NewLoggerWriter(logger))
+	_ = cmd.Flags().Set("logtostderr", "true")
+
+	ctx := context.Background()
+	if deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+
+	var in io.Reader = os.Stdin
+	if queryLog != "-" {
+		f, err := os.Open(queryLog)
+		if err != nil {
+			return fmt.Errorf("cannot open query log: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	conn, err := vtgateconn.Dial(ctx, server)
+	if err != nil {
+		return fmt.Errorf("cannot connect to vtgate %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	replayer := replay.NewReplayer(replay.Config{
+		Speed:              speed,
+		ReadOnly:           readOnly,
+		FullBindVars:       fullBindVars,
+		MaxMismatches:      maxMismatches,
+		SessionIdleTimeout: sessionIdle,
+	}, func(target string) replay.Session {
+		return &session{sn: conn.Session(target, nil)}
+	})
+	report, err := replayer.Replay(ctx, in)
+	if writeErr := report.Write(os.Stdout); writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		return fmt.Errorf("replay interrupted: %w", err)
+	}
+	return nil
+}
@@ -53,7 +53,9 @@ import (
 var (
 	enforceTableACLConfig        bool
 	tableACLConfig               string
+	tableACLDryRunConfig         string
 	tableACLConfigReloadInterval time.Duration
+	callerRoleMappingFile        string
 	tabletPath                   string
 	tabletConfig                 string
 
@@ -166,7 +168,7 @@ func run(cmd *cobra.Command, args []string) error {
 		DBConfigs:           config.DB.Clone(),
 		QueryServiceControl: qsc,
 		UpdateStream:        binlog.NewUpdateStream(ts, tablet.Keyspace, tabletAlias.Cell, qsc.SchemaEngine(), env.Parser()),
-		VREngine:            vreplication.NewEngine(env, config, ts, tabletAlias.Cell, mysqld, qsc.LagThrottler()),
+		VREngine:            vreplication.NewEngine(env, config, ts, tabletAlias.Cell, mysqld, qsc.LagThrottler(), qsc.Activity()),
 		VDiffEngine:         vdiff.NewEngine(ts, tablet, env.CollationEnv(), env.Parser()),
 	}
 	if err := tm.Start(tablet, config); err != nil {
@@ -256,7 +258,7 @@ func extractOnlineDDL() error {
 }
 
 func createTabletServer(ctx context.Context, env *vtenv.Environment, config *tabletenv.TabletConfig, ts *topo.Server, tabletAlias *topodatapb.TabletAlias, srvTopoCounts *stats.CountersWithSingleLabel) (*tabletserver.TabletServer, error) {
-	if tableACLConfig != "" {
+	if tableACLConfig != "" || tableACLDryRunConfig != "" {
 		// To override default simpleacl, other ACL plugins must set themselves to be default ACL factory
 		tableacl.Register("simpleacl", &simpleacl.Factory{})
 	} else if enforceTableACLConfig {
@@ -270,7 +272,12 @@ func createTabletServer(ctx context.Context, env *vtenv.Environment, config *tab
 		addStatusParts(qsc)
 	})
 	servenv.OnClose(qsc.StopService)
-	qsc.InitACL(tableACLConfig, enforceTableACLConfig, tableACLConfigReloadInterval)
+	qsc.InitACL(tableACLConfig, tableACLDryRunConfig, enforceTableACLConfig, tableACLConfigReloadInterval)
+	if callerRoleMappingFile != "" {
+		if err := qsc.InitCallerRoles(callerRoleMappingFile); err != nil {
+			return nil, err
+		}
+	}
 	return qsc, nil
 }
 
@@ -289,7 +296,9 @@ func init() {
 	acl.RegisterFlags(Main.Flags())
 	Main.Flags().BoolVar(&enforceTableACLConfig, "enforce-tableacl-config", enforceTableACLConfig, "if this flag is true, vttablet will fail to start if a valid tableacl config does not exist")
 	Main.Flags().StringVar(&tableACLConfig, "table-acl-config", tableACLConfig, "path to table access checker config file; send SIGHUP to reload this file")
+	Main.Flags().StringVar(&tableACLDryRunConfig, "table-acl-dry-run-config", tableACLDryRunConfig, "path to a table access checker config file that is evaluated in dry run mode: the queries it would deny are counted and logged, but not denied; send SIGHUP to reload this file")
 	Main.Flags().DurationVar(&tableACLConfigReloadInterval, "table-acl-config-reload-interval", tableACLConfigReloadInterval, "Ticker to reload ACLs. Duration flag, format e.g.: 30s. Default: do not reload")
+	Main.Flags().StringVar(&callerRoleMappingFile, "caller-role-mapping-file", callerRoleMappingFile, "path to a JSON file mapping vtgate users and groups to the MySQL roles activated on the connections of their queries")
 	Main.Flags().StringVar(&tabletPath, "tablet-path", tabletPath, "tablet alias")
 	Main.Flags().StringVar(&tabletConfig, "tablet_config", tabletConfig, "YAML file config for tablet")
 }
@@ -223,6 +223,8 @@ func New() (cmd *cobra.Command) {
 
 	cmd.Flags().DurationVar(&config.VtgateTabletRefreshInterval, "tablet_refresh_interval", 10*time.Second, "Interval at which vtgate refreshes tablet information from topology server.")
 
+	cmd.Flags().BoolVar(&config.FailoverSimulation, "failover-simulation", false, "Run vtcombo with a small embedded vtorc that replaces the primaries that stop serving, and accept fault injections (kill a primary, stall the replication of a replica) on its /debug/failover_simulation endpoint, to test how the applications handle failovers.")
+
 	cmd.Flags().BoolVar(&doCreateTCPUser, "initialize-with-vt-dba-tcp", false, "If this flag is enabled, MySQL will be initialized with an additional user named vt_dba_tcp, who will have access via TCP/IP connection.")
 	acl.RegisterFlags(cmd.Flags())
 
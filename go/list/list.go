@@ -159,3 +159,12 @@ func (l *List[T]) PushBack(v T) *Element[T] {
 func (l *List[T]) PushBackValue(v *Element[T]) {
 	l.insert(v, l.root.prev)
 }
+
+// InsertAfterValue inserts the element v right after mark, which must be an
+// element of l.
+func (l *List[T]) InsertAfterValue(v, mark *Element[T]) {
+	if mark.list != l {
+		panic("inserting after an element of another List")
+	}
+	l.insert(v, mark)
+}
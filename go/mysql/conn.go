@@ -182,6 +182,12 @@ type Conn struct {
 	// by Handler methods.
 	StatusFlags uint16
 
+	// SessionStateChanges are the changes of the session state, other than
+	// the GTIDs of the result, that the OK packet ending the current query
+	// reports to the client, if it tracks them. It is only used by the
+	// server, and is set by Handler methods for each query.
+	SessionStateChanges *SessionStateChanges
+
 	// CharacterSet is the charset for this connection, as negotiated
 	// in our handshake with the server. Note that although the MySQL protocol lists this
 	// as a "character set", the returned byte value is actually a Collation ID,
@@ -223,6 +229,8 @@ type Conn struct {
 type PrepareData struct {
 	ParamsType  []int32
 	ColumnNames []string
+	// Fields are the result columns returned to the client when the statement was prepared.
+	Fields      []*querypb.Field
 	PrepareStmt string
 	BindVars    map[string]*querypb.BindVariable
 	StatementID uint32
@@ -787,16 +795,17 @@ func (c *Conn) writeOKPacketWithHeader(packetOk *PacketOK, headerType byte) erro
 	// assuming CapabilityClientProtocol41
 	length += 4 // status_flags + warnings
 
-	var gtidData []byte
+	statusFlags := packetOk.statusFlags
+	var stateInfo []byte
 	if c.Capabilities&CapabilityClientSessionTrack == CapabilityClientSessionTrack {
 		length += lenEncStringSize(packetOk.info) // info
-		if packetOk.statusFlags&ServerSessionStateChanged == ServerSessionStateChanged {
-			gtidData = getLenEncString([]byte(packetOk.sessionStateData))
-			gtidData = append([]byte{0x00}, gtidData...)
-			gtidData = getLenEncString(gtidData)
-			gtidData = append([]byte{0x03}, gtidData...)
-			gtidData = append(getLenEncInt(uint64(len(gtidData))), gtidData...)
-			length += len(gtidData)
+		stateInfo = packetOk.sessionStateInfo()
+		if len(stateInfo) > 0 {
+			statusFlags |= ServerSessionStateChanged
+		}
+		if statusFlags&ServerSessionStateChanged == ServerSessionStateChanged {
+			stateInfo = getLenEncString(stateInfo)
+			length += len(stateInfo)
 		}
 	} else {
 		length += len(packetOk.info) // info
@@ -807,12 +816,12 @@ func (c *Conn) writeOKPacketWithHeader(packetOk *PacketOK, headerType byte) erro
 	data.writeByte(headerType) // header - OK or EOF
 	data.writeLenEncInt(packetOk.affectedRows)
 	data.writeLenEncInt(packetOk.lastInsertID)
-	data.writeUint16(packetOk.statusFlags)
+	data.writeUint16(statusFlags)
 	data.writeUint16(packetOk.warnings)
 	if c.Capabilities&CapabilityClientSessionTrack == CapabilityClientSessionTrack {
 		data.writeLenEncString(packetOk.info)
-		if packetOk.statusFlags&ServerSessionStateChanged == ServerSessionStateChanged {
-			data.writeEOFString(string(gtidData))
+		if statusFlags&ServerSessionStateChanged == ServerSessionStateChanged {
+			data.writeEOFString(string(stateInfo))
 		}
 	} else {
 		data.writeEOFString(packetOk.info)
@@ -1172,12 +1181,13 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 				sendFinished = true
 				// We should not send any more packets after this.
 				ok := PacketOK{
-					affectedRows:     qr.RowsAffected,
-					lastInsertID:     qr.InsertID,
-					statusFlags:      c.StatusFlags,
-					warnings:         0,
-					info:             "",
-					sessionStateData: qr.SessionStateChanges,
+					affectedRows:        qr.RowsAffected,
+					lastInsertID:        qr.InsertID,
+					statusFlags:         c.StatusFlags,
+					warnings:            0,
+					info:                "",
+					sessionStateData:    qr.SessionStateChanges,
+					sessionStateChanges: c.SessionStateChanges,
 				}
 				return c.writeOKPacket(&ok)
 			}
@@ -1420,12 +1430,13 @@ func (c *Conn) execQuery(query string, handler Handler, more bool) execResult {
 				// to extract the affected rows and last insert id from the result
 				// struct here since clients expect it.
 				ok := PacketOK{
-					affectedRows:     qr.RowsAffected,
-					lastInsertID:     qr.InsertID,
-					statusFlags:      flag,
-					warnings:         handler.WarningCount(c),
-					info:             "",
-					sessionStateData: qr.SessionStateChanges,
+					affectedRows:        qr.RowsAffected,
+					lastInsertID:        qr.InsertID,
+					statusFlags:         flag,
+					warnings:            handler.WarningCount(c),
+					info:                "",
+					sessionStateData:    qr.SessionStateChanges,
+					sessionStateChanges: c.SessionStateChanges,
 				}
 				return c.writeOKPacket(&ok)
 			}
@@ -1523,6 +1534,9 @@ type PacketOK struct {
 
 	// at the moment, we only store GTID information in this field
 	sessionStateData string
+
+	// sessionStateChanges are the other changes of the session state.
+	sessionStateChanges *SessionStateChanges
 }
 
 func (c *Conn) parseOKPacket(packetOK *PacketOK, in []byte) error {
@@ -1588,7 +1602,17 @@ func (c *Conn) parseOKPacket(packetOK *PacketOK, in []byte) error {
 					return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid OK packet session state change length for type %v", sscType)
 				}
 
-				if sscType != SessionTrackGtids {
+				switch sscType {
+				case SessionTrackGtids:
+				case SessionTrackSystemVariables, SessionTrackStateChange:
+					if packetOK.sessionStateChanges == nil {
+						packetOK.sessionStateChanges = &SessionStateChanges{}
+					}
+					if err := packetOK.sessionStateChanges.parse(sscType, sessionLen, data); err != nil {
+						return err
+					}
+					continue
+				default:
 					// Still need to increase the pointer here to indicate we're consuming
 					// but otherwise ignoring the rest of this packet
 					data.pos = data.pos + int(sessionLen)
@@ -281,6 +281,29 @@ func TestBasicPackets(t *testing.T) {
 	assert.EqualValues(89, packetOk.warnings)
 	assert.EqualValues("foo-bar", packetOk.sessionStateData)
 
+	// Write OK packet with session state changes, read it, compare. The
+	// session state changed flag is set since there are changes to report.
+	ok = PacketOK{
+		affectedRows:     1,
+		sessionStateData: "foo-bar",
+		sessionStateChanges: &SessionStateChanges{
+			SystemVariables: []SessionSystemVariable{{Name: "autocommit", Value: "OFF"}, {Name: "time_zone", Value: "+01:00"}},
+			StateChanged:    true,
+		},
+	}
+	err = sConn.writeOKPacket(&ok)
+	require.NoError(err)
+
+	data, err = cConn.ReadPacket()
+	require.NoError(err)
+	packetOk = PacketOK{}
+	err = cConn.parseOKPacket(&packetOk, data)
+	require.NoError(err)
+	assert.EqualValues(1, packetOk.affectedRows)
+	assert.EqualValues(ServerSessionStateChanged, packetOk.statusFlags&ServerSessionStateChanged)
+	assert.EqualValues("foo-bar", packetOk.sessionStateData)
+	assert.Equal(ok.sessionStateChanges, packetOk.sessionStateChanges)
+
 	// Write OK packet with EOF header, read it, compare.
 	ok = PacketOK{
 		affectedRows: 12,
@@ -379,14 +402,16 @@ func TestOkPackets(t *testing.T) {
 00000000  00 00 00 00 40 00 00 00  14 00 0f 0a 61 75 74 6f  |....@.......auto|
 00000010  63 6f 6d 6d 69 74 03 4f  46 46 02 01 31           |commit.OFF..1|`,
 		dataOut: `
-00000000  00 00 00 00 40 00 00 00  04 03 02 00 00           |....@........|`,
+00000000  00 00 00 00 40 00 00 00  18 00 0f 0a 61 75 74 6f  |....@.......auto|
+00000010  63 6f 6d 6d 69 74 03 4f  46 46 02 01 31 03 02 00  |commit.OFF..1...|
+00000020  00                                                |.|`,
 		cc: CapabilityClientProtocol41 | CapabilityClientTransactions | CapabilityClientSessionTrack,
 	}, {
 		dataIn: `
 00000000  00 00 00 00 40 00 00 00  0a 01 05 04 74 65 73 74  |....@.......test|
 00000010  02 01 31                                          |..1|`,
 		dataOut: `
-00000000  00 00 00 00 40 00 00 00  04 03 02 00 00           |....@........|`,
+00000000  00 00 00 00 40 00 00 00  07 02 01 31 03 02 00 00  |....@......1....|`,
 		cc: CapabilityClientProtocol41 | CapabilityClientTransactions | CapabilityClientSessionTrack,
 	}, {
 		dataIn: `0000   00 00 00 03 40 00 00 00 fc 56 04 03   |....@....V..|
@@ -1092,6 +1092,7 @@ func (c *Conn) writePrepare(fld []*querypb.Field, prepare *PrepareData) error {
 	if columnCount > 0 {
 		prepare.ColumnNames = make([]string, columnCount)
 	}
+	prepare.Fields = fld
 
 	ok := PacketComStmtPrepareOK{
 		status:       OKPacket,
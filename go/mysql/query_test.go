@@ -152,6 +152,7 @@ func TestComStmtPrepare(t *testing.T) {
 	if err := sConn.writePrepare(result.Fields, prepare); err != nil {
 		t.Fatalf("sConn.writePrepare failed: %v", err)
 	}
+	require.Equal(t, result.Fields, prepare.Fields, "the prepared fields were not recorded")
 
 	resp, err := cConn.ReadPacket()
 	require.NoError(t, err, "cConn.ReadPacket failed: %v", err)
@@ -585,7 +585,8 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, ch
 		CapabilityClientPluginAuth |
 		CapabilityClientPluginAuthLenencClientData |
 		CapabilityClientDeprecateEOF |
-		CapabilityClientConnAttr
+		CapabilityClientConnAttr |
+		CapabilityClientSessionTrack
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
@@ -709,6 +710,11 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 		c.Capabilities = clientFlags & (CapabilityClientDeprecateEOF | CapabilityClientFoundRows)
 	}
 
+	// set connection capability for tracking the session state
+	if clientFlags&CapabilityClientSessionTrack > 0 {
+		c.Capabilities |= CapabilityClientSessionTrack
+	}
+
 	// set connection capability for executing multi statements
 	if clientFlags&CapabilityClientMultiStatements > 0 {
 		c.Capabilities |= CapabilityClientMultiStatements
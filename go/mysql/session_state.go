@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// SessionStateChanges are the changes of the session state that a server
+// reports in its OK packets to the clients that track them, with
+// CapabilityClientSessionTrack. The GTIDs of the transactions are reported
+// separately, from the SessionStateChanges of the results.
+type SessionStateChanges struct {
+	// SystemVariables are the tracked system variables that changed, with
+	// their new values, as in SESSION_TRACK_SYSTEM_VARIABLES.
+	SystemVariables []SessionSystemVariable
+	// StateChanged tells that the state of the session changed, as in
+	// SESSION_TRACK_STATE_CHANGE.
+	StateChanged bool
+}
+
+// SessionSystemVariable is a tracked system variable that changed.
+type SessionSystemVariable struct {
+	Name  string
+	Value string
+}
+
+// sessionStateInfo encodes the session state changes of the OK packet,
+// without their length. It is empty when there is nothing to report.
+func (packetOk *PacketOK) sessionStateInfo() []byte {
+	var info []byte
+	if changes := packetOk.sessionStateChanges; changes != nil {
+		for _, sysVar := range changes.SystemVariables {
+			data := getLenEncString([]byte(sysVar.Name))
+			data = append(data, getLenEncString([]byte(sysVar.Value))...)
+			info = appendSessionStateChange(info, SessionTrackSystemVariables, data)
+		}
+		if changes.StateChanged {
+			info = appendSessionStateChange(info, SessionTrackStateChange, []byte("1"))
+		}
+	}
+	if packetOk.sessionStateData != "" || packetOk.statusFlags&ServerSessionStateChanged == ServerSessionStateChanged {
+		// The GTIDs are preceded by the encoding specification, 0 being the
+		// only one defined.
+		data := append([]byte{0x00}, getLenEncString([]byte(packetOk.sessionStateData))...)
+		info = appendSessionStateChange(info, SessionTrackGtids, data)
+	}
+	return info
+}
+
+// appendSessionStateChange appends a session state change of the given type.
+func appendSessionStateChange(info []byte, typ uint8, data []byte) []byte {
+	info = append(info, typ)
+	return append(info, getLenEncString(data)...)
+}
+
+// parse reads a session state change of the given type and length, whose
+// header was already read.
+func (changes *SessionStateChanges) parse(typ uint8, length uint64, data *coder) error {
+	switch typ {
+	case SessionTrackSystemVariables:
+		name, ok := data.readLenEncString()
+		if !ok {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid OK packet system variable name: %v", data.data)
+		}
+		value, ok := data.readLenEncString()
+		if !ok {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid OK packet system variable value: %v", data.data)
+		}
+		changes.SystemVariables = append(changes.SystemVariables, SessionSystemVariable{Name: name, Value: value})
+	case SessionTrackStateChange:
+		// Unlike the other changes, the state change is not length encoded.
+		if length != 1 || data.pos >= len(data.data) {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid OK packet state change: %v", data.data)
+		}
+		changes.StateChanged = data.data[data.pos] == '1'
+		data.pos++
+	}
+	return nil
+}
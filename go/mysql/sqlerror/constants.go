@@ -122,6 +122,7 @@ const (
 	ErSPNotVarArg                   = ErrorCode(1414)
 	ERRowIsReferenced2              = ErrorCode(1451)
 	ErNoReferencedRow2              = ErrorCode(1452)
+	ERNeedReprepare                 = ErrorCode(1615)
 	ERDupIndex                      = ErrorCode(1831)
 	ERInnodbReadOnly                = ErrorCode(1874)
 
@@ -314,6 +315,9 @@ const (
 	// SSDataOutOfRange is ER_DATA_OUT_OF_RANGE
 	SSDataOutOfRange = "22003"
 
+	// SSTruncatedWrongValue is ER_TRUNCATED_WRONG_VALUE
+	SSTruncatedWrongValue = "22007"
+
 	// SSConstraintViolation is constraint violation
 	SSConstraintViolation = "23000"
 
@@ -179,6 +179,9 @@ var stateToMysqlCode = map[vterrors.State]mysqlCode{
 	vterrors.BadTableError:                {num: ERBadTable, state: SSUnknownTable},
 	vterrors.CantUseOptionHere:            {num: ERCantUseOptionHere, state: SSClientError},
 	vterrors.DataOutOfRange:               {num: ERDataOutOfRange, state: SSDataOutOfRange},
+	vterrors.DataTooLong:                  {num: ERDataTooLong, state: SSDataTooLong},
+	vterrors.WarnDataOutOfRange:           {num: ERWarnDataOutOfRange, state: SSDataOutOfRange},
+	vterrors.TruncatedWrongValue:          {num: ERTruncatedWrongValue, state: SSTruncatedWrongValue},
 	vterrors.DbCreateExists:               {num: ERDbCreateExists, state: SSUnknownSQLState},
 	vterrors.DbDropExists:                 {num: ERDbDropExists, state: SSUnknownSQLState},
 	vterrors.DupFieldName:                 {num: ERDupFieldName, state: SSDupFieldName},
@@ -218,6 +221,7 @@ var stateToMysqlCode = map[vterrors.State]mysqlCode{
 	vterrors.WrongArguments:               {num: ERWrongArguments, state: SSUnknownSQLState},
 	vterrors.UnknownStmtHandler:           {num: ERUnknownStmtHandler, state: SSUnknownSQLState},
 	vterrors.KeyDoesNotExist:              {num: ERKeyDoesNotExist, state: SSClientError},
+	vterrors.NeedReprepare:                {num: ERNeedReprepare, state: SSUnknownSQLState},
 	vterrors.UnknownTimeZone:              {num: ERUnknownTimeZone, state: SSUnknownSQLState},
 	vterrors.RegexpStringNotTerminated:    {num: ERRegexpStringNotTerminated, state: SSUnknownSQLState},
 	vterrors.RegexpBufferOverflow:         {num: ERRegexpBufferOverflow, state: SSUnknownSQLState},
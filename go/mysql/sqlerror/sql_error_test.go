@@ -154,6 +154,11 @@ func TestNewSQLErrorFromError(t *testing.T) {
 			num: ERNoDb,
 			ss:  SSNoDB,
 		},
+		{
+			err: vterrors.NewErrorf(vtrpc.Code_FAILED_PRECONDITION, vterrors.NeedReprepare, "Prepared statement needs to be re-prepared"),
+			num: ERNeedReprepare,
+			ss:  SSUnknownSQLState,
+		},
 		{
 			err: fmt.Errorf("just some random text here"),
 			num: ERUnknownError,
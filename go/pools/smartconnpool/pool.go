@@ -36,6 +36,10 @@ var (
 
 	// ErrCtxTimeout is returned if a ctx is already expired by the time the connection pool is used
 	ErrCtxTimeout = vterrors.New(vtrpcpb.Code_DEADLINE_EXCEEDED, "resource pool context already expired")
+
+	// ErrQueueFull is returned if a connection get would have to wait while the
+	// maximum number of clients are already waiting for a connection.
+	ErrQueueFull = vterrors.New(vtrpcpb.Code_RESOURCE_EXHAUSTED, "resource pool queue is full")
 )
 
 type Metrics struct {
@@ -47,6 +51,8 @@ type Metrics struct {
 	idleClosed           atomic.Int64
 	diffSetting          atomic.Int64
 	resetSetting         atomic.Int64
+	queueFull            atomic.Int64
+	expiredClosed        atomic.Int64
 }
 
 func (m *Metrics) MaxLifetimeClosed() int64 {
@@ -81,6 +87,14 @@ func (m *Metrics) ResetSettingCount() int64 {
 	return m.resetSetting.Load()
 }
 
+func (m *Metrics) QueueFullCount() int64 {
+	return m.queueFull.Load()
+}
+
+func (m *Metrics) ExpiredClosed() int64 {
+	return m.expiredClosed.Load()
+}
+
 type Connector[C Connection] func(ctx context.Context) (C, error)
 type RefreshCheck func() (bool, error)
 
@@ -89,7 +103,10 @@ type Config[C Connection] struct {
 	IdleTimeout     time.Duration
 	MaxLifetime     time.Duration
 	RefreshInterval time.Duration
-	LogWait         func(time.Time)
+	// MaxWaiters is the maximum number of clients that can wait for a
+	// connection at once, or 0 for no limit.
+	MaxWaiters int64
+	LogWait    func(time.Time)
 }
 
 // stackMask is the number of connection setting stacks minus one;
@@ -117,6 +134,9 @@ type ConnPool[C Connection] struct {
 	active atomic.Int64
 	// capacity is the maximum number of connections that this pool can open
 	capacity atomic.Int64
+	// expiredBefore is the time, in unix nanoseconds, before which the connections
+	// were expired by ConnPool.ExpireConnections, or 0 if they never were
+	expiredBefore atomic.Int64
 
 	// workers is a waitgroup for all the currently running worker goroutines
 	workers sync.WaitGroup
@@ -137,6 +157,8 @@ type ConnPool[C Connection] struct {
 		idleTimeout atomic.Int64
 		// refreshInterval is how often to call the refresh check
 		refreshInterval atomic.Int64
+		// maxWaiters is the maximum number of clients waiting for a connection, or 0 for no limit
+		maxWaiters atomic.Int64
 		// logWait is called every time a client must block waiting for a connection
 		logWait func(time.Time)
 	}
@@ -153,6 +175,7 @@ func NewPool[C Connection](config *Config[C]) *ConnPool[C] {
 	pool.config.maxLifetime.Store(config.MaxLifetime.Nanoseconds())
 	pool.config.idleTimeout.Store(config.IdleTimeout.Nanoseconds())
 	pool.config.refreshInterval.Store(config.RefreshInterval.Nanoseconds())
+	pool.config.maxWaiters.Store(config.MaxWaiters)
 	pool.config.logWait = config.LogWait
 	pool.wait.init()
 
@@ -305,6 +328,24 @@ func (pool *ConnPool[D]) RefreshInterval() time.Duration {
 	return time.Duration(pool.config.refreshInterval.Load())
 }
 
+// MaxWaiters returns the maximum number of clients that can wait for a
+// connection at once, or 0 if there is no limit.
+func (pool *ConnPool[C]) MaxWaiters() int64 {
+	return pool.config.maxWaiters.Load()
+}
+
+// SetMaxWaiters sets the maximum number of clients that can wait for a
+// connection at once. Once it's reached, Get fails right away with ErrQueueFull
+// instead of waiting. 0 means no limit.
+func (pool *ConnPool[C]) SetMaxWaiters(maxWaiters int64) {
+	pool.config.maxWaiters.Store(maxWaiters)
+}
+
+// Waiting returns the number of clients waiting for a connection.
+func (pool *ConnPool[C]) Waiting() int64 {
+	return int64(pool.wait.waiting())
+}
+
 func (pool *ConnPool[C]) recordWait(start time.Time) {
 	pool.Metrics.waitCount.Add(1)
 	pool.Metrics.waitTime.Add(time.Since(start).Nanoseconds())
@@ -346,8 +387,13 @@ func (pool *ConnPool[C]) put(conn *Pooled[C]) {
 		conn.timeUsed = time.Now()
 
 		lifetime := pool.extendedMaxLifetime()
-		if lifetime > 0 && time.Until(conn.timeCreated.Add(lifetime)) < 0 {
-			pool.Metrics.maxLifetimeClosed.Add(1)
+		expired := pool.isExpired(conn)
+		if expired || lifetime > 0 && time.Until(conn.timeCreated.Add(lifetime)) < 0 {
+			if expired {
+				pool.Metrics.expiredClosed.Add(1)
+			} else {
+				pool.Metrics.maxLifetimeClosed.Add(1)
+			}
 			conn.Close()
 			if err := pool.connReopen(context.Background(), conn, conn.timeUsed); err != nil {
 				pool.closedConn()
@@ -469,7 +515,11 @@ func (pool *ConnPool[C]) get(ctx context.Context) (*Pooled[C], error) {
 	// to other clients, wait until one of the connections is returned
 	if conn == nil {
 		start := time.Now()
-		conn, err = pool.wait.waitForConn(ctx, nil)
+		conn, err = pool.wait.waitForConn(ctx, nil, pool.MaxWaiters())
+		if err == ErrQueueFull {
+			pool.Metrics.queueFull.Add(1)
+			return nil, err
+		}
 		if err != nil {
 			return nil, ErrTimeout
 		}
@@ -526,7 +576,11 @@ func (pool *ConnPool[C]) getWithSetting(ctx context.Context, setting *Setting) (
 	// wait for one of them
 	if conn == nil {
 		start := time.Now()
-		conn, err = pool.wait.waitForConn(ctx, setting)
+		conn, err = pool.wait.waitForConn(ctx, setting, pool.MaxWaiters())
+		if err == ErrQueueFull {
+			pool.Metrics.queueFull.Add(1)
+			return nil, err
+		}
 		if err != nil {
 			return nil, ErrTimeout
 		}
@@ -639,6 +693,45 @@ func (pool *ConnPool[C]) closeIdleResources(now time.Time) {
 	closeInStack(&pool.clean)
 }
 
+// ExpireConnections expires all the connections that the pool has opened so far,
+// e.g. because they were opened with credentials that were since rotated. The
+// idle connections are closed, to be opened again on demand, and the borrowed
+// connections are reopened when they are returned to the pool, so that the
+// connections are re-established gradually rather than all at once.
+func (pool *ConnPool[C]) ExpireConnections() {
+	pool.expiredBefore.Store(time.Now().UnixNano())
+
+	var conns []*Pooled[C]
+
+	closeInStack := func(s *connStack[C]) {
+		conns = s.PopAll(conns[:0])
+		slices.Reverse(conns)
+
+		for _, conn := range conns {
+			if pool.isExpired(conn) {
+				pool.Metrics.expiredClosed.Add(1)
+				conn.Close()
+				pool.closedConn()
+				continue
+			}
+
+			s.Push(conn)
+		}
+	}
+
+	for i := 0; i <= stackMask; i++ {
+		closeInStack(&pool.settings[i])
+	}
+	closeInStack(&pool.clean)
+}
+
+// isExpired returns whether the connection was opened before the pool last
+// expired its connections
+func (pool *ConnPool[C]) isExpired(conn *Pooled[C]) bool {
+	expiredBefore := pool.expiredBefore.Load()
+	return expiredBefore != 0 && conn.timeCreated.UnixNano() < expiredBefore
+}
+
 func (pool *ConnPool[C]) StatsJSON() map[string]any {
 	return map[string]any{
 		"Capacity":          int(pool.Capacity()),
@@ -647,6 +740,8 @@ func (pool *ConnPool[C]) StatsJSON() map[string]any {
 		"InUse":             int(pool.InUse()),
 		"WaitCount":         int(pool.Metrics.WaitCount()),
 		"WaitTime":          pool.Metrics.WaitTime(),
+		"Waiting":           int(pool.Waiting()),
+		"QueueFull":         int(pool.Metrics.QueueFullCount()),
 		"IdleTimeout":       pool.IdleTimeout(),
 		"IdleClosed":        int(pool.Metrics.IdleClosed()),
 		"MaxLifetimeClosed": int(pool.Metrics.MaxLifetimeClosed()),
@@ -681,6 +776,12 @@ func (pool *ConnPool[C]) RegisterStats(stats *servenv.Exporter, name string) {
 	stats.NewCounterDurationFunc(name+"WaitTime", "Tablet server wait time", func() time.Duration {
 		return pool.Metrics.WaitTime()
 	})
+	stats.NewGaugeFunc(name+"Waiting", "Tablet server conn pool clients waiting for a connection", func() int64 {
+		return pool.Waiting()
+	})
+	stats.NewCounterFunc(name+"QueueFull", "Tablet server conn pool gets rejected because too many clients were waiting", func() int64 {
+		return pool.Metrics.QueueFullCount()
+	})
 	stats.NewGaugeDurationFunc(name+"IdleTimeout", "Tablet server idle timeout", func() time.Duration {
 		return pool.IdleTimeout()
 	})
@@ -690,6 +791,9 @@ func (pool *ConnPool[C]) RegisterStats(stats *servenv.Exporter, name string) {
 	stats.NewCounterFunc(name+"MaxLifetimeClosed", "Tablet server conn pool refresh closed", func() int64 {
 		return pool.Metrics.MaxLifetimeClosed()
 	})
+	stats.NewCounterFunc(name+"ExpiredClosed", "Tablet server conn pool connections closed because they were expired", func() int64 {
+		return pool.Metrics.ExpiredClosed()
+	})
 	stats.NewCounterFunc(name+"Get", "Tablet server conn pool get count", func() int64 {
 		return pool.Metrics.GetCount()
 	})
@@ -275,6 +275,8 @@ func TestShrinking(t *testing.T) {
 		"InUse":             4,
 		"WaitCount":         0,
 		"WaitTime":          time.Duration(0),
+		"Waiting":           0,
+		"QueueFull":         0,
 		"IdleTimeout":       1 * time.Second,
 		"IdleClosed":        0,
 		"MaxLifetimeClosed": 0,
@@ -305,6 +307,8 @@ func TestShrinking(t *testing.T) {
 		"InUse":             0,
 		"WaitCount":         0,
 		"WaitTime":          time.Duration(0),
+		"Waiting":           0,
+		"QueueFull":         0,
 		"IdleTimeout":       1 * time.Second,
 		"IdleClosed":        0,
 		"MaxLifetimeClosed": 0,
@@ -432,6 +436,8 @@ func TestClosing(t *testing.T) {
 		"InUse":             5,
 		"WaitCount":         0,
 		"WaitTime":          time.Duration(0),
+		"Waiting":           0,
+		"QueueFull":         0,
 		"IdleTimeout":       1 * time.Second,
 		"IdleClosed":        0,
 		"MaxLifetimeClosed": 0,
@@ -454,6 +460,8 @@ func TestClosing(t *testing.T) {
 		"InUse":             0,
 		"WaitCount":         0,
 		"WaitTime":          time.Duration(0),
+		"Waiting":           0,
+		"QueueFull":         0,
 		"IdleTimeout":       1 * time.Second,
 		"IdleClosed":        0,
 		"MaxLifetimeClosed": 0,
@@ -500,6 +508,8 @@ func TestReopen(t *testing.T) {
 		"InUse":             5,
 		"WaitCount":         0,
 		"WaitTime":          time.Duration(0),
+		"Waiting":           0,
+		"QueueFull":         0,
 		"IdleTimeout":       1 * time.Second,
 		"IdleClosed":        0,
 		"MaxLifetimeClosed": 0,
@@ -521,6 +531,8 @@ func TestReopen(t *testing.T) {
 		"InUse":             0,
 		"WaitCount":         0,
 		"WaitTime":          time.Duration(0),
+		"Waiting":           0,
+		"QueueFull":         0,
 		"IdleTimeout":       1 * time.Second,
 		"IdleClosed":        0,
 		"MaxLifetimeClosed": 0,
@@ -530,6 +542,55 @@ func TestReopen(t *testing.T) {
 	assert.EqualValues(t, 0, state.open.Load())
 }
 
+func TestExpireConnections(t *testing.T) {
+	var state TestState
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity: 5,
+		LogWait:  state.LogWait,
+	}).Open(newConnector(&state), nil)
+
+	defer p.Close()
+
+	var conns []*Pooled[*TestConn]
+	for i := 0; i < 4; i++ {
+		r, err := p.Get(ctx, nil)
+		require.NoError(t, err)
+		conns = append(conns, r)
+	}
+	// return half of the connections, so that they're idle in the pool
+	for _, r := range conns[:2] {
+		r.Recycle()
+	}
+	assert.EqualValues(t, 4, state.open.Load())
+
+	// the idle connections are closed at once
+	p.ExpireConnections()
+	assert.EqualValues(t, 2, p.Metrics.ExpiredClosed())
+	assert.EqualValues(t, 2, state.open.Load())
+	assert.EqualValues(t, 2, p.Active())
+	assert.EqualValues(t, 2, p.InUse())
+
+	// the borrowed connections are reopened when they're returned
+	for _, r := range conns[2:] {
+		old := r.Conn
+		r.Recycle()
+		assert.True(t, old.IsClosed())
+		assert.False(t, r.Conn.IsClosed())
+	}
+	assert.EqualValues(t, 4, p.Metrics.ExpiredClosed())
+	assert.EqualValues(t, 2, state.open.Load())
+	assert.EqualValues(t, 2, p.Active())
+
+	// the connections opened since are not expired
+	r, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+	r.Recycle()
+	assert.EqualValues(t, 4, p.Metrics.ExpiredClosed())
+	assert.EqualValues(t, 0, p.Metrics.MaxLifetimeClosed())
+}
+
 func TestIdleTimeout(t *testing.T) {
 	testTimeout := func(t *testing.T, setting *Setting) {
 		var state TestState
@@ -717,6 +778,8 @@ func TestCreateFail(t *testing.T) {
 			"InUse":             0,
 			"WaitCount":         0,
 			"WaitTime":          time.Duration(0),
+			"Waiting":           0,
+			"QueueFull":         0,
 			"IdleTimeout":       1 * time.Second,
 			"IdleClosed":        0,
 			"MaxLifetimeClosed": 0,
@@ -826,6 +889,160 @@ func TestTimeout(t *testing.T) {
 	p.put(r)
 }
 
+func TestMaxWaiters(t *testing.T) {
+	var state TestState
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity:    1,
+		IdleTimeout: time.Second,
+		MaxWaiters:  1,
+		LogWait:     state.LogWait,
+	}).Open(newConnector(&state), nil)
+
+	defer p.Close()
+
+	// take the only connection available
+	r, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+
+	got := make(chan error)
+	go func() {
+		conn, err := p.Get(ctx, nil)
+		if err == nil {
+			p.put(conn)
+		}
+		got <- err
+	}()
+	for p.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the queue is full, so the next clients are rejected right away
+	for _, setting := range []*Setting{nil, sFoo} {
+		_, err = p.Get(ctx, setting)
+		assert.ErrorIs(t, err, ErrQueueFull)
+	}
+	assert.EqualValues(t, 2, p.Metrics.QueueFullCount())
+
+	p.put(r)
+	require.NoError(t, <-got)
+
+	// without a limit, clients wait again
+	p.SetMaxWaiters(0)
+	r, err = p.Get(ctx, nil)
+	require.NoError(t, err)
+	newctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	_, err = p.Get(newctx, nil)
+	cancel()
+	assert.ErrorIs(t, err, ErrTimeout)
+	p.put(r)
+}
+
+func TestWaitPriority(t *testing.T) {
+	var state TestState
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity:    1,
+		IdleTimeout: time.Second,
+		LogWait:     state.LogWait,
+	}).Open(newConnector(&state), nil)
+
+	defer p.Close()
+
+	// take the only connection available
+	r, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+
+	// the clients start waiting from the lowest priority to the highest one,
+	// two of them with the same priority
+	priorities := []int{90, 50, 50, 10}
+	served := make(chan int, len(priorities))
+	for i, priority := range priorities {
+		go func() {
+			conn, err := p.Get(WithPriority(ctx, priority), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			served <- i
+			p.put(conn)
+		}()
+		for p.Waiting() != int64(i+1) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	p.put(r)
+	var order []int
+	for range priorities {
+		order = append(order, <-served)
+	}
+	// the highest priority first, and in order for the same priority
+	assert.Equal(t, []int{3, 1, 2, 0}, order)
+}
+
+func TestWaitPriorityAging(t *testing.T) {
+	var state TestState
+
+	ctx := context.Background()
+	p := NewPool(&Config[*TestConn]{
+		Capacity:    1,
+		IdleTimeout: time.Second,
+		LogWait:     state.LogWait,
+	}).Open(newConnector(&state), nil)
+
+	defer p.Close()
+
+	type served struct {
+		priority int
+		conn     *Pooled[*TestConn]
+	}
+	servedCh := make(chan served)
+	wait := func(priority int) {
+		go func() {
+			conn, err := p.Get(WithPriority(ctx, priority), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			servedCh <- served{priority, conn}
+		}()
+	}
+
+	// take the only connection available
+	r, err := p.Get(ctx, nil)
+	require.NoError(t, err)
+
+	wait(10)
+	for p.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the low priority client is served even though higher priority clients
+	// keep coming, once it has been passed over too many times
+	var highServed int
+	for {
+		wait(0)
+		for p.Waiting() != 2 {
+			time.Sleep(time.Millisecond)
+		}
+		p.put(r)
+		s := <-servedCh
+		r = s.conn
+		if s.priority == 10 {
+			break
+		}
+		highServed++
+	}
+	assert.Equal(t, 9, highServed)
+
+	// serve the last high priority client
+	p.put(r)
+	s := <-servedCh
+	assert.Equal(t, 0, s.priority)
+	p.put(s.conn)
+}
+
 func TestExpired(t *testing.T) {
 	var state TestState
 
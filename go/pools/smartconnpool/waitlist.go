@@ -23,6 +23,21 @@ import (
 	"vitess.io/vitess/go/list"
 )
 
+type priorityKey struct{}
+
+// WithPriority returns a context that makes the pool serve its Get before the
+// ones of lower priority when clients wait for a connection. Like the PRIORITY
+// query directive, 0 is the highest priority, and the higher the value, the
+// lower the priority. Clients without a priority have priority 0.
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func priorityFromContext(ctx context.Context) int {
+	priority, _ := ctx.Value(priorityKey{}).(int)
+	return priority
+}
+
 // waiter represents a client waiting for a connection in the waitlist
 type waiter[C Connection] struct {
 	// setting is the connection Setting that we'd like, or nil if we'd like a
@@ -37,6 +52,8 @@ type waiter[C Connection] struct {
 	sema semaphore
 	// age is the amount of cycles this client has been on the waitlist
 	age uint32
+	// priority is the priority of the waiting client, see WithPriority
+	priority int
 }
 
 type waitlist[C Connection] struct {
@@ -50,13 +67,29 @@ type waitlist[C Connection] struct {
 // The returned connection may _not_ have the requested Setting. This function can
 // also return a `nil` connection even if our context has expired, if the pool has
 // forced an expiration of all waiters in the waitlist.
-func (wl *waitlist[C]) waitForConn(ctx context.Context, setting *Setting) (*Pooled[C], error) {
-	elem := wl.nodes.Get().(*list.Element[waiter[C]])
-	elem.Value = waiter[C]{setting: setting, conn: nil, ctx: ctx}
+// When maxWaiters is positive and that many clients are already waiting,
+// ErrQueueFull is returned right away.
+func (wl *waitlist[C]) waitForConn(ctx context.Context, setting *Setting, maxWaiters int64) (*Pooled[C], error) {
+	priority := priorityFromContext(ctx)
 
 	wl.mu.Lock()
-	// add ourselves as a waiter at the end of the waitlist
-	wl.list.PushBackValue(elem)
+	if maxWaiters > 0 && int64(wl.list.Len()) >= maxWaiters {
+		wl.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	elem := wl.nodes.Get().(*list.Element[waiter[C]])
+	elem.Value = waiter[C]{setting: setting, conn: nil, ctx: ctx, priority: priority}
+	// add ourselves as a waiter after all the waiters with the same or a higher
+	// priority, so that the waiters with the same priority are served in order
+	mark := wl.list.Back()
+	for mark != nil && mark.Value.priority > priority {
+		mark = mark.Prev()
+	}
+	if mark == nil {
+		wl.list.PushFrontValue(elem)
+	} else {
+		wl.list.InsertAfterValue(elem, mark)
+	}
 	wl.mu.Unlock()
 
 	// block on our waiter's semaphore until somebody can hand over a connection to us
@@ -119,32 +152,49 @@ func (wl *waitlist[D]) tryReturnConnSlow(conn *Pooled[D]) bool {
 	)
 
 	wl.mu.Lock()
-	target = wl.list.Front()
-	// iterate through the waitlist looking for either waiters that have been
-	// here too long, or a waiter that is looking exactly for the same Setting
-	// as the one we have in our connection.
-	for e := target; e != nil; e = e.Next() {
-		if e.Value.age > maxAge || e.Value.setting == connSetting {
+	front := wl.list.Front()
+	if front == nil {
+		// there isn't anybody to hand over the connection to, because we've
+		// raced with another client returning another connection
+		wl.mu.Unlock()
+		return false
+	}
+	// the waiters with a lower priority, which are at the back of the list,
+	// are passed over for the ones with the highest priority, but only up to
+	// maxAge times, so that they don't starve while higher priority clients
+	// keep coming. the one closest to the front is served first.
+	for e := wl.list.Back(); e != nil && e.Value.priority != front.Value.priority; e = e.Prev() {
+		if e.Value.age > maxAge {
 			target = e
-			break
 		}
-		// this only ages the waiters that are being skipped over: we'll start
-		// aging the waiters in the back once they get to the front of the pool.
-		// the maxAge of 8 has been set empirically: smaller values cause clients
-		// with a specific setting to slightly starve, and aging all the clients
-		// in the list every time leads to unfairness when the system is at capacity
-		e.Value.age++
-	}
-	if target != nil {
-		wl.list.Remove(target)
 	}
-	wl.mu.Unlock()
-
-	// maybe there isn't anybody to hand over the connection to, because we've
-	// raced with another client returning another connection
 	if target == nil {
-		return false
+		target = front
+		// iterate through the waitlist looking for either waiters that have been
+		// here too long, or a waiter that is looking exactly for the same Setting
+		// as the one we have in our connection. only the waiters with the highest
+		// priority, which are at the front of the list, are considered.
+		for e := front; e != nil && e.Value.priority == front.Value.priority; e = e.Next() {
+			if e.Value.age > maxAge || e.Value.setting == connSetting {
+				target = e
+				break
+			}
+			// this only ages the waiters that are being skipped over: we'll start
+			// aging the waiters with the same priority in the back once they get
+			// to the front of the pool.
+			// the maxAge of 8 has been set empirically: smaller values cause clients
+			// with a specific setting to slightly starve, and aging all the clients
+			// in the list every time leads to unfairness when the system is at capacity
+			e.Value.age++
+		}
+	}
+	for e := wl.list.Back(); e != nil && e.Value.priority != front.Value.priority; e = e.Prev() {
+		if e != target {
+			e.Value.age++
+		}
 	}
+	wl.list.Remove(target)
+	wl.mu.Unlock()
 
 	// if we have a target to return the connection to, simply write the connection
 	// into the waiter and signal their semaphore. they'll wake up to pick up the
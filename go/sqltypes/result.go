@@ -19,6 +19,7 @@ package sqltypes
 import (
 	"crypto/sha256"
 	"fmt"
+	"math"
 	"slices"
 
 	"google.golang.org/protobuf/proto"
@@ -299,6 +300,21 @@ func IncludeFieldsOrDefault(options *querypb.ExecuteOptions) querypb.ExecuteOpti
 	return options.IncludedFields
 }
 
+// StreamChunkSize returns the maximum number of rows and bytes per result of
+// a streaming query, as requested in the passed Execution Options. The requested
+// number of bytes is capped to maxBytes, and defaultBytes is used if options is
+// nil or does not request any. A maximum of 0 rows means no row limit.
+func StreamChunkSize(options *querypb.ExecuteOptions, defaultBytes, maxBytes int) (chunkRows, chunkBytes int) {
+	if options == nil {
+		return 0, defaultBytes
+	}
+	chunkBytes = defaultBytes
+	if options.StreamChunkMaxBytes > 0 {
+		chunkBytes = int(min(options.StreamChunkMaxBytes, uint64(max(maxBytes, 1))))
+	}
+	return int(min(options.StreamChunkMaxRows, math.MaxInt32)), chunkBytes
+}
+
 // StripMetadata will return a new Result that has the same Rows,
 // but the Field objects will have their non-critical metadata emptied.  Note we don't
 // proto.Copy each Field for performance reasons, but we only copy the
@@ -17,6 +17,7 @@ limitations under the License.
 package sqltypes
 
 import (
+	"math"
 	"testing"
 
 	"vitess.io/vitess/go/test/utils"
@@ -345,3 +346,41 @@ func TestAppendResult(t *testing.T) {
 		t.Errorf("Got:\n%#v, want:\n%#v", result, want)
 	}
 }
+
+func TestStreamChunkSize(t *testing.T) {
+	testcases := []struct {
+		name      string
+		options   *querypb.ExecuteOptions
+		wantRows  int
+		wantBytes int
+	}{{
+		name:      "nil options",
+		wantBytes: 1024,
+	}, {
+		name:      "nothing requested",
+		options:   &querypb.ExecuteOptions{},
+		wantBytes: 1024,
+	}, {
+		name:      "smaller chunks",
+		options:   &querypb.ExecuteOptions{StreamChunkMaxRows: 10, StreamChunkMaxBytes: 100},
+		wantRows:  10,
+		wantBytes: 100,
+	}, {
+		name:      "larger chunks",
+		options:   &querypb.ExecuteOptions{StreamChunkMaxBytes: 4096},
+		wantBytes: 4096,
+	}, {
+		name:      "capped",
+		options:   &querypb.ExecuteOptions{StreamChunkMaxRows: 1 << 40, StreamChunkMaxBytes: 1 << 40},
+		wantRows:  math.MaxInt32,
+		wantBytes: 8192,
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, bytes := StreamChunkSize(tc.options, 1024, 8192)
+			if rows != tc.wantRows || bytes != tc.wantBytes {
+				t.Errorf("StreamChunkSize() = (%d, %d), want (%d, %d)", rows, bytes, tc.wantRows, tc.wantBytes)
+			}
+		})
+	}
+}
@@ -1005,9 +1005,10 @@ func getIntFromValue(val any) int {
 }
 
 // WaitForDetectedProblems waits until the given analysis code, alias, keyspace and shard count matches the count expected.
+// Only the detected problems are counted, not the simulated ones.
 func WaitForDetectedProblems(t *testing.T, vtorcInstance *cluster.VTOrcProcess, code, alias, ks, shard string, expect int) {
 	t.Helper()
-	key := strings.Join([]string{code, alias, ks, shard}, ".")
+	key := strings.Join([]string{code, alias, ks, shard, "false"}, ".")
 	timeout := 15 * time.Second
 	startTime := time.Now()
 
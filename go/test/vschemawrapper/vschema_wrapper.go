@@ -49,6 +49,8 @@ type VSchemaWrapper struct {
 	ForeignKeyChecksState *bool
 	Version               plancontext.PlannerVersion
 	EnableViews           bool
+	EnforcedSQLModes      []string
+	TrackedTables         map[string]map[string]*vindexes.TableInfo
 	TestBuilder           func(query string, vschema plancontext.VSchema, keyspace string) (*engine.Plan, error)
 	Env                   *vtenv.Environment
 }
@@ -338,3 +340,11 @@ func (vw *VSchemaWrapper) FindRoutedShard(keyspace, shard string) (string, error
 func (vw *VSchemaWrapper) IsViewsEnabled() bool {
 	return vw.EnableViews
 }
+
+func (vw *VSchemaWrapper) SQLModeChecks() []string {
+	return vw.EnforcedSQLModes
+}
+
+func (vw *VSchemaWrapper) FindTrackedTable(keyspace, table string) *vindexes.TableInfo {
+	return vw.TrackedTables[keyspace][table]
+}
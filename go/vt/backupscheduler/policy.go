@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupscheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Policy is the backup policy of a keyspace.
+type Policy struct {
+	// Keyspace is the keyspace to back up.
+	Keyspace string `json:"keyspace"`
+	// Shards are the shards to back up, all the shards of the keyspace if empty.
+	Shards []string `json:"shards,omitempty"`
+	// Schedule is when the backups run, see ParseSchedule. It is evaluated in UTC.
+	Schedule string `json:"schedule"`
+
+	// AllowPrimary allows backing up a shard from its primary when it has no
+	// other tablet to take the backup from.
+	AllowPrimary bool  `json:"allow_primary,omitempty"`
+	Concurrency  int32 `json:"concurrency,omitempty"`
+	UpgradeSafe  bool  `json:"upgrade_safe,omitempty"`
+
+	// KeepCount is the number of complete backups kept for each shard, 0 to
+	// keep them regardless of their number.
+	KeepCount int `json:"keep_count,omitempty"`
+	// KeepAge is how long the backups of each shard are kept, e.g. "168h",
+	// empty to keep them regardless of their age.
+	KeepAge string `json:"keep_age,omitempty"`
+
+	schedule Schedule
+	keepAge  time.Duration
+}
+
+// Config is the configuration of the backup scheduler.
+type Config struct {
+	Policies []*Policy `json:"policies"`
+}
+
+// LoadConfig reads and validates the configuration file of the backup scheduler.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid backup schedule config %s: %v", path, err)
+	}
+	for _, policy := range config.Policies {
+		if err := policy.init(); err != nil {
+			return nil, fmt.Errorf("invalid backup schedule config %s: %v", path, err)
+		}
+	}
+	return config, nil
+}
+
+// init validates the policy, and parses its schedule and retention.
+func (p *Policy) init() error {
+	if p.Keyspace == "" {
+		return fmt.Errorf("a policy has no keyspace")
+	}
+	var err error
+	if p.schedule, err = ParseSchedule(p.Schedule); err != nil {
+		return fmt.Errorf("policy of keyspace %s: %v", p.Keyspace, err)
+	}
+	if p.KeepCount < 0 {
+		return fmt.Errorf("policy of keyspace %s: keep_count must not be negative", p.Keyspace)
+	}
+	if p.KeepAge != "" {
+		if p.keepAge, err = time.ParseDuration(p.KeepAge); err != nil || p.keepAge <= 0 {
+			return fmt.Errorf("policy of keyspace %s: invalid keep_age %q", p.Keyspace, p.KeepAge)
+		}
+	}
+	return nil
+}
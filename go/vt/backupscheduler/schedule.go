@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupscheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule tells when the backups of a policy run.
+type Schedule interface {
+	// Next returns the first time the backups run after t.
+	Next(t time.Time) time.Time
+}
+
+// scheduleAliases are the predefined schedules.
+var scheduleAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseSchedule parses a schedule. It is either a cron expression with the
+// five minute, hour, day of month, month and day of week fields, each being
+// '*', a value, a range 'a-b' or a list of them, optionally with a '/step'
+// suffix; one of @hourly, @daily, @midnight, @weekly and @monthly; or
+// '@every <duration>'.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", spec, err)
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("invalid schedule %q: the interval must be at least a minute", spec)
+		}
+		return everySchedule(d), nil
+	}
+	if alias, ok := scheduleAliases[spec]; ok {
+		spec = alias
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+	var cs cronSchedule
+	var err error
+	for i, field := range []struct {
+		bits     *uint64
+		min, max int
+	}{
+		{&cs.minutes, 0, 59},
+		{&cs.hours, 0, 23},
+		{&cs.days, 1, 31},
+		{&cs.months, 1, 12},
+		{&cs.weekdays, 0, 7},
+	} {
+		if *field.bits, err = parseCronField(fields[i], field.min, field.max); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", spec, err)
+		}
+	}
+	// Both 0 and 7 are Sunday.
+	if cs.weekdays&(1<<7) != 0 {
+		cs.weekdays |= 1
+	}
+	cs.anyDay = fields[2] == "*"
+	cs.anyWeekday = fields[4] == "*"
+	return &cs, nil
+}
+
+// parseCronField returns the bitset of the values of a cron field.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		valueRange, stepSpec, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepSpec); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		first, last := min, max
+		if valueRange != "*" {
+			firstSpec, lastSpec, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if first, err = strconv.Atoi(firstSpec); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", part)
+			}
+			last = first
+			if isRange {
+				if last, err = strconv.Atoi(lastSpec); err != nil {
+					return 0, fmt.Errorf("invalid value in %q", part)
+				}
+			} else if hasStep {
+				last = max
+			}
+		}
+		if first < min || last > max || first > last {
+			return 0, fmt.Errorf("%q is out of the range %d-%d", part, min, max)
+		}
+		for v := first; v <= last; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// everySchedule runs the backups at a fixed interval.
+type everySchedule time.Duration
+
+// Next is part of the Schedule interface.
+func (es everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(es)).Truncate(time.Minute)
+}
+
+// cronSchedule runs the backups at the minutes matching a cron expression.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday are set when the day of month, or of week, is '*'.
+	// Like with cron, when both are restricted, a day matches either of them.
+	anyDay, anyWeekday bool
+}
+
+// Next is part of the Schedule interface.
+func (cs *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Any schedule matches within 5 years, even the ones on February 29th.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case cs.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !cs.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case cs.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case cs.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return limit
+}
+
+func (cs *cronSchedule) matchDay(t time.Time) bool {
+	day := cs.days&(1<<uint(t.Day())) != 0
+	weekday := cs.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case cs.anyDay && cs.anyWeekday:
+		return true
+	case cs.anyDay:
+		return weekday
+	case cs.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
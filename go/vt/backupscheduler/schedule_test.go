@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupscheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2024, time.May, 15, 10, 30, 45, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		spec string
+		next []time.Time
+	}{{
+		spec: "0 2 * * *",
+		next: []time.Time{at(time.May, 16, 2, 0), at(time.May, 17, 2, 0)},
+	}, {
+		spec: "*/20 * * * *",
+		next: []time.Time{at(time.May, 15, 10, 40), at(time.May, 15, 11, 0)},
+	}, {
+		spec: "30 1,13 * * 0",
+		next: []time.Time{at(time.May, 19, 1, 30), at(time.May, 19, 13, 30), at(time.May, 26, 1, 30)},
+	}, {
+		spec: "0 0 1 * 7",
+		next: []time.Time{at(time.May, 19, 0, 0), at(time.May, 26, 0, 0), at(time.June, 1, 0, 0)},
+	}, {
+		spec: "15 3-4 29 2 *",
+		next: []time.Time{time.Date(2028, time.February, 29, 3, 15, 0, 0, time.UTC), time.Date(2028, time.February, 29, 4, 15, 0, 0, time.UTC)},
+	}, {
+		spec: "@daily",
+		next: []time.Time{at(time.May, 16, 0, 0)},
+	}, {
+		spec: "@every 6h",
+		next: []time.Time{at(time.May, 15, 16, 30), at(time.May, 15, 22, 30)},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			next := now
+			for _, want := range tt.next {
+				next = schedule.Next(next)
+				assert.Equal(t, want, next)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every 1s", "@every x"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
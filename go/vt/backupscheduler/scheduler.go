@@ -0,0 +1,400 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backupscheduler runs the backups of the shards of the keyspaces
+// on schedules, and removes the backups that their retention doesn't keep.
+package backupscheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+
+	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+// backupSchedulesPath is the directory of the global topo where the vtctlds
+// lock the policies of a keyspace while they run them.
+const backupSchedulesPath = "backup_schedules"
+
+var (
+	runs = stats.NewCountersWithMultiLabels("BackupScheduleRuns", "Scheduled shard backups, per keyspace, shard and result", []string{"Keyspace", "Shard", "Result"})
+	// lastSuccess is the unix time of the last successful backup of each shard.
+	lastSuccess = stats.NewGaugesWithMultiLabels("BackupScheduleLastSuccess", "Unix time of the last successful scheduled backup, per keyspace and shard", []string{"Keyspace", "Shard"})
+	removed     = stats.NewCountersWithMultiLabels("BackupScheduleRemovedBackups", "Backups removed by the retention of the backup schedules, per keyspace and shard", []string{"Keyspace", "Shard"})
+)
+
+// Client is the part of the vtctld API the scheduler uses.
+type Client interface {
+	BackupShard(ctx context.Context, in *vtctldatapb.BackupShardRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_BackupShardClient, error)
+	GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error)
+	RemoveBackup(ctx context.Context, in *vtctldatapb.RemoveBackupRequest, opts ...grpc.CallOption) (*vtctldatapb.RemoveBackupResponse, error)
+}
+
+// PolicyStatus is the status of the backups of a policy. It is kept in memory,
+// and only covers the backups this scheduler ran.
+type PolicyStatus struct {
+	Keyspace string
+	Schedule string
+	NextRun  time.Time
+	Shards   []*ShardStatus
+}
+
+// ShardStatus is the status of the scheduled backups of a shard.
+type ShardStatus struct {
+	Shard       string
+	Running     bool
+	LastRun     time.Time
+	LastSuccess time.Time
+	LastError   string
+}
+
+// Scheduler runs the backups of its policies.
+type Scheduler struct {
+	ts       *topo.Server
+	client   Client
+	policies []*Policy
+
+	mu       sync.Mutex
+	statuses map[*Policy]*PolicyStatus
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewScheduler returns a scheduler of the backups of the policies of config.
+func NewScheduler(ts *topo.Server, client Client, config *Config) *Scheduler {
+	s := &Scheduler{
+		ts:       ts,
+		client:   client,
+		policies: config.Policies,
+		statuses: make(map[*Policy]*PolicyStatus),
+	}
+	for _, policy := range s.policies {
+		s.statuses[policy] = &PolicyStatus{
+			Keyspace: policy.Keyspace,
+			Schedule: policy.Schedule,
+		}
+	}
+	return s
+}
+
+// Open starts running the backups on their schedules.
+func (s *Scheduler) Open() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	for _, policy := range s.policies {
+		s.wg.Add(1)
+		go s.run(ctx, policy)
+	}
+}
+
+// Close stops running the backups, and cancels the running ones.
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+}
+
+// run runs the backups of a policy on its schedule until ctx is done. The
+// next backups are scheduled once the current ones end, so they never overlap.
+func (s *Scheduler) run(ctx context.Context, policy *Policy) {
+	defer s.wg.Done()
+	for {
+		next := policy.schedule.Next(time.Now().UTC())
+		s.mu.Lock()
+		s.statuses[policy].NextRun = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runPolicy(ctx, policy, next)
+	}
+}
+
+// runPolicy backs up the shards of a policy one after the other, for its run
+// scheduled at the given time. The vtctlds run the policies of a keyspace
+// under a topo lock, one at a time, and skip the shards that were backed up
+// since the scheduled time, by another vtctld or by hand.
+func (s *Scheduler) runPolicy(ctx context.Context, policy *Policy, scheduled time.Time) {
+	unlock, err := s.lockPolicy(ctx, policy)
+	if err != nil {
+		log.Errorf("Scheduled backups of keyspace %s failed to lock its backup schedule: %v", policy.Keyspace, err)
+		return
+	}
+	defer unlock()
+
+	shards := policy.Shards
+	if len(shards) == 0 {
+		var err error
+		if shards, err = s.ts.GetShardNames(ctx, policy.Keyspace); err != nil {
+			log.Errorf("Scheduled backups of keyspace %s failed to get its shards: %v", policy.Keyspace, err)
+			return
+		}
+		sort.Strings(shards)
+	}
+	for _, shard := range shards {
+		if ctx.Err() != nil {
+			return
+		}
+		backedUp, err := s.backedUpSince(ctx, policy, shard, scheduled)
+		if err != nil {
+			log.Errorf("Scheduled backup of %s/%s failed to get its backups: %v", policy.Keyspace, shard, err)
+			runs.Add([]string{policy.Keyspace, shard, "Error"}, 1)
+			continue
+		}
+		if backedUp {
+			log.Infof("Skipping the scheduled backup of %s/%s, it was backed up since %v", policy.Keyspace, shard, scheduled)
+			runs.Add([]string{policy.Keyspace, shard, "Skipped"}, 1)
+			continue
+		}
+		s.backupShard(ctx, policy, shard)
+	}
+}
+
+// lockPolicy takes the topo lock of the policies of the keyspace of the
+// policy, and returns the function releasing it.
+func (s *Scheduler) lockPolicy(ctx context.Context, policy *Policy) (func(), error) {
+	conn, err := s.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Join(backupSchedulesPath, policy.Keyspace)
+	// The lock implementations need the directory to exist.
+	if _, err := conn.Create(ctx, path.Join(dir, "keyspace"), []byte(policy.Keyspace)); err != nil && !topo.IsErrType(err, topo.NodeExists) {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	ld, err := conn.Lock(ctx, dir, fmt.Sprintf("scheduled backups of %s by vtctld on %s", policy.Keyspace, hostname))
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		// The lock is released even when the scheduler is closing.
+		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+		defer cancel()
+		if err := ld.Unlock(ctx); err != nil {
+			log.Errorf("Scheduled backups of keyspace %s failed to unlock its backup schedule: %v", policy.Keyspace, err)
+		}
+	}, nil
+}
+
+// backedUpSince returns whether the shard has a backup taken since the
+// given time.
+func (s *Scheduler) backedUpSince(ctx context.Context, policy *Policy, shard string, since time.Time) (bool, error) {
+	resp, err := s.client.GetBackups(ctx, &vtctldatapb.GetBackupsRequest{
+		Keyspace: policy.Keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return false, err
+	}
+	// The times of the backups are truncated to the second.
+	since = since.Truncate(time.Second)
+	for _, backup := range resp.Backups {
+		if !protoutil.TimeFromProto(backup.Time).Before(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// backupShard backs up a shard, and then removes the backups of the shard
+// that the retention of the policy doesn't keep.
+func (s *Scheduler) backupShard(ctx context.Context, policy *Policy, shard string) {
+	start := time.Now()
+	status := s.shardStatus(policy, shard)
+	s.mu.Lock()
+	status.Running = true
+	status.LastRun = start
+	s.mu.Unlock()
+
+	log.Infof("Running the scheduled backup of %s/%s", policy.Keyspace, shard)
+	err := s.backup(ctx, policy, shard)
+	if err == nil {
+		lastSuccess.Set([]string{policy.Keyspace, shard}, start.Unix())
+		// The backups are only removed after a successful one, so that the
+		// failures of the backups don't remove the backups to restore from.
+		err = s.enforceRetention(ctx, policy, shard, start)
+	}
+
+	result := "Success"
+	s.mu.Lock()
+	status.Running = false
+	if err != nil {
+		result = "Error"
+		status.LastError = err.Error()
+		log.Errorf("Scheduled backup of %s/%s failed: %v", policy.Keyspace, shard, err)
+	} else {
+		status.LastSuccess = start
+		status.LastError = ""
+	}
+	s.mu.Unlock()
+	runs.Add([]string{policy.Keyspace, shard, result}, 1)
+}
+
+// backup takes a backup of the shard from the tablet that BackupShard picks,
+// the one with the lowest replication lag.
+func (s *Scheduler) backup(ctx context.Context, policy *Policy, shard string) error {
+	stream, err := s.client.BackupShard(ctx, &vtctldatapb.BackupShardRequest{
+		Keyspace:     policy.Keyspace,
+		Shard:        shard,
+		AllowPrimary: policy.AllowPrimary,
+		Concurrency:  policy.Concurrency,
+		UpgradeSafe:  policy.UpgradeSafe,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// enforceRetention removes the backups of the shard beyond the count, or older
+// than the age, that the policy keeps. The most recent complete backup is always
+// kept, and so are the incomplete backups more recent than it, that may still
+// be running.
+func (s *Scheduler) enforceRetention(ctx context.Context, policy *Policy, shard string, now time.Time) error {
+	if policy.KeepCount == 0 && policy.keepAge == 0 {
+		return nil
+	}
+	resp, err := s.client.GetBackups(ctx, &vtctldatapb.GetBackupsRequest{
+		Keyspace: policy.Keyspace,
+		Shard:    shard,
+		Detailed: true,
+	})
+	if err != nil {
+		return err
+	}
+	backups := resp.Backups
+	sort.SliceStable(backups, func(i, j int) bool {
+		return protoutil.TimeFromProto(backups[i].Time).After(protoutil.TimeFromProto(backups[j].Time))
+	})
+
+	kept := 0
+	for _, backup := range backups {
+		// Without details, the status of the backups is unknown, and they are
+		// assumed to be complete.
+		complete := backup.Status == mysqlctlpb.BackupInfo_COMPLETE || backup.Status == mysqlctlpb.BackupInfo_UNKNOWN
+		switch {
+		case kept == 0 && !complete:
+			continue
+		case kept == 0:
+			kept++
+			continue
+		case complete && (policy.KeepCount == 0 || kept < policy.KeepCount) &&
+			(policy.keepAge == 0 || now.Sub(protoutil.TimeFromProto(backup.Time)) <= policy.keepAge):
+			kept++
+			continue
+		}
+
+		log.Infof("Removing the backup %s of %s/%s per its backup schedule retention", backup.Name, policy.Keyspace, shard)
+		if _, err := s.client.RemoveBackup(ctx, &vtctldatapb.RemoveBackupRequest{
+			Keyspace: policy.Keyspace,
+			Shard:    shard,
+			Name:     backup.Name,
+		}); err != nil {
+			return err
+		}
+		removed.Add([]string{policy.Keyspace, shard}, 1)
+	}
+	return nil
+}
+
+// shardStatus returns the status of a shard of a policy, creating it if needed.
+func (s *Scheduler) shardStatus(policy *Policy, shard string) *ShardStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.statuses[policy]
+	for _, shardStatus := range status.Shards {
+		if shardStatus.Shard == shard {
+			return shardStatus
+		}
+	}
+	shardStatus := &ShardStatus{Shard: shard}
+	status.Shards = append(status.Shards, shardStatus)
+	return shardStatus
+}
+
+// Status returns the status of the backups of the policies.
+func (s *Scheduler) Status() []*PolicyStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]*PolicyStatus, 0, len(s.policies))
+	for _, policy := range s.policies {
+		status := *s.statuses[policy]
+		status.Shards = make([]*ShardStatus, 0, len(status.Shards))
+		for _, shardStatus := range s.statuses[policy].Shards {
+			shardStatus := *shardStatus
+			status.Shards = append(status.Shards, &shardStatus)
+		}
+		statuses = append(statuses, &status)
+	}
+	return statuses
+}
+
+// ServeHTTP serves the status of the backups of the policies as JSON.
+func (s *Scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.MONITORING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, err := json.MarshalIndent(s.Status(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(b)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupscheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+// fakeClient takes the backups of the shards in memory.
+type fakeClient struct {
+	mu      sync.Mutex
+	now     time.Time
+	backups map[string][]*mysqlctlpb.BackupInfo
+	failing map[string]error
+	removed []string
+}
+
+func (fc *fakeClient) BackupShard(ctx context.Context, in *vtctldatapb.BackupShardRequest, opts ...grpc.CallOption) (vtctlservicepb.Vtctld_BackupShardClient, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	key := in.Keyspace + "/" + in.Shard
+	if err := fc.failing[key]; err != nil {
+		return &fakeBackupStream{err: err}, nil
+	}
+	fc.backups[key] = append(fc.backups[key], &mysqlctlpb.BackupInfo{
+		Name:   fc.now.Format("2006-01-02.150405"),
+		Time:   protoutil.TimeToProto(fc.now),
+		Status: mysqlctlpb.BackupInfo_COMPLETE,
+	})
+	return &fakeBackupStream{err: io.EOF}, nil
+}
+
+func (fc *fakeClient) GetBackups(ctx context.Context, in *vtctldatapb.GetBackupsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetBackupsResponse, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return &vtctldatapb.GetBackupsResponse{Backups: append([]*mysqlctlpb.BackupInfo(nil), fc.backups[in.Keyspace+"/"+in.Shard]...)}, nil
+}
+
+func (fc *fakeClient) RemoveBackup(ctx context.Context, in *vtctldatapb.RemoveBackupRequest, opts ...grpc.CallOption) (*vtctldatapb.RemoveBackupResponse, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	key := in.Keyspace + "/" + in.Shard
+	for i, backup := range fc.backups[key] {
+		if backup.Name == in.Name {
+			fc.backups[key] = append(fc.backups[key][:i], fc.backups[key][i+1:]...)
+			break
+		}
+	}
+	fc.removed = append(fc.removed, key+"/"+in.Name)
+	return &vtctldatapb.RemoveBackupResponse{}, nil
+}
+
+type fakeBackupStream struct {
+	vtctlservicepb.Vtctld_BackupShardClient
+	err error
+}
+
+func (fs *fakeBackupStream) Recv() (*vtctldatapb.BackupResponse, error) {
+	return nil, fs.err
+}
+
+func writeConfig(t *testing.T, config string) string {
+	path := filepath.Join(t.TempDir(), "backup_schedule.json")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	config, err := LoadConfig(writeConfig(t, `{"policies": [{"keyspace": "ks", "schedule": "@daily", "keep_count": 3, "keep_age": "72h"}]}`))
+	require.NoError(t, err)
+	require.Len(t, config.Policies, 1)
+	assert.Equal(t, 72*time.Hour, config.Policies[0].keepAge)
+	assert.NotNil(t, config.Policies[0].schedule)
+
+	for _, invalid := range []string{
+		`{"policies": [{"schedule": "@daily"}]}`,
+		`{"policies": [{"keyspace": "ks", "schedule": "0 25 * * *"}]}`,
+		`{"policies": [{"keyspace": "ks", "schedule": "@daily", "keep_count": -1}]}`,
+		`{"policies": [{"keyspace": "ks", "schedule": "@daily", "keep_age": "a week"}]}`,
+		`{"policies": `,
+	} {
+		_, err := LoadConfig(writeConfig(t, invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSchedulerRunPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "-80"))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "80-"))
+
+	config, err := LoadConfig(writeConfig(t, `{"policies": [{"keyspace": "ks", "schedule": "@daily", "keep_count": 2}]}`))
+	require.NoError(t, err)
+	client := &fakeClient{
+		backups: map[string][]*mysqlctlpb.BackupInfo{},
+		failing: map[string]error{},
+	}
+	scheduler := NewScheduler(ts, client, config)
+	policy := config.Policies[0]
+
+	start := time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 3; day++ {
+		client.now = start.AddDate(0, 0, day)
+		scheduler.runPolicy(ctx, policy, client.now)
+	}
+	// Both shards are backed up, and only their last 2 backups are kept.
+	for _, shard := range []string{"-80", "80-"} {
+		require.Len(t, client.backups["ks/"+shard], 2, shard)
+		assert.Equal(t, "2024-05-16.000000", client.backups["ks/"+shard][0].Name)
+	}
+	assert.ElementsMatch(t, []string{"ks/-80/2024-05-15.000000", "ks/80-/2024-05-15.000000"}, client.removed)
+
+	// The backups of a shard are not removed when its backup fails.
+	client.failing["ks/80-"] = errors.New("no tablet to back up")
+	client.now = start.AddDate(0, 0, 3)
+	scheduler.runPolicy(ctx, policy, client.now)
+	assert.Len(t, client.removed, 3)
+	assert.Len(t, client.backups["ks/80-"], 2)
+
+	status := scheduler.Status()
+	require.Len(t, status, 1)
+	require.Len(t, status[0].Shards, 2)
+	assert.Equal(t, "-80", status[0].Shards[0].Shard)
+	assert.Empty(t, status[0].Shards[0].LastError)
+	assert.False(t, status[0].Shards[0].LastSuccess.IsZero())
+	assert.Equal(t, "no tablet to back up", status[0].Shards[1].LastError)
+	assert.EqualValues(t, 1, runs.Counts()["ks.80-.Error"])
+
+	response := httptest.NewRecorder()
+	scheduler.ServeHTTP(response, httptest.NewRequest("GET", "/debug/backup_schedule", nil))
+	assert.Contains(t, response.Body.String(), "no tablet to back up")
+
+	// Once open, the next backups are scheduled, until the scheduler is closed.
+	scheduler.Open()
+	assert.Eventually(t, func() bool {
+		return !scheduler.Status()[0].NextRun.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+	scheduler.Close()
+}
+
+func TestSchedulerMultipleVtctlds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+
+	config, err := LoadConfig(writeConfig(t, `{"policies": [{"keyspace": "ks", "schedule": "@daily"}]}`))
+	require.NoError(t, err)
+	client := &fakeClient{
+		backups: map[string][]*mysqlctlpb.BackupInfo{},
+		failing: map[string]error{},
+	}
+	scheduler1 := NewScheduler(ts, client, config)
+	scheduler2 := NewScheduler(ts, client, config)
+	policy := config.Policies[0]
+	client.now = time.Date(2024, time.May, 15, 0, 0, 1, 0, time.UTC)
+	scheduled := client.now.Add(-time.Second)
+
+	// The policy runs one vtctld at a time.
+	unlock, err := scheduler1.lockPolicy(ctx, policy)
+	require.NoError(t, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler2.runPolicy(ctx, policy, scheduled)
+	}()
+	select {
+	case <-done:
+		t.Fatal("ran a policy locked by another vtctld")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-done
+	require.Len(t, client.backups["ks/0"], 1)
+
+	// The other vtctld skips the shards that were backed up for the run.
+	skipped := runs.Counts()["ks.0.Skipped"]
+	scheduler1.runPolicy(ctx, policy, scheduled)
+	assert.Len(t, client.backups["ks/0"], 1)
+	assert.Equal(t, skipped+1, runs.Counts()["ks.0.Skipped"])
+	assert.Empty(t, scheduler1.Status()[0].Shards)
+}
+
+func TestSchedulerRetention(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC)
+	backup := func(name string, age time.Duration, status mysqlctlpb.BackupInfo_Status) *mysqlctlpb.BackupInfo {
+		return &mysqlctlpb.BackupInfo{Name: name, Time: protoutil.TimeToProto(now.Add(-age)), Status: status}
+	}
+	client := &fakeClient{backups: map[string][]*mysqlctlpb.BackupInfo{
+		"ks/0": {
+			backup("old-incomplete", 100*time.Hour, mysqlctlpb.BackupInfo_INCOMPLETE),
+			backup("old", 72*time.Hour, mysqlctlpb.BackupInfo_COMPLETE),
+			backup("recent", 24*time.Hour, mysqlctlpb.BackupInfo_COMPLETE),
+			backup("last", 2*time.Hour, mysqlctlpb.BackupInfo_COMPLETE),
+			backup("running", time.Hour, mysqlctlpb.BackupInfo_INCOMPLETE),
+		},
+	}}
+	scheduler := NewScheduler(nil, client, &Config{})
+	policy := &Policy{Keyspace: "ks", keepAge: 48 * time.Hour}
+	require.NoError(t, scheduler.enforceRetention(context.Background(), policy, "0", now))
+	assert.ElementsMatch(t, []string{"ks/0/old", "ks/0/old-incomplete"}, client.removed)
+
+	// The last complete backup is kept, however old it is.
+	client.removed = nil
+	policy.keepAge = time.Minute
+	require.NoError(t, scheduler.enforceRetention(context.Background(), policy, "0", now))
+	assert.Equal(t, []string{"ks/0/recent"}, client.removed)
+}
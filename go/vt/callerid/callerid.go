@@ -33,6 +33,8 @@ var (
 	immediateCallerIDKey callerIDKey
 	// internal Context key for effective CallerID
 	effectiveCallerIDKey callerIDKey = 1
+	// internal Context key for the component of Vitess that generated a query
+	internalComponentKey callerIDKey = 2
 )
 
 // NewImmediateCallerID creates a querypb.VTGateCallerID initialized with username
@@ -117,3 +119,43 @@ func ImmediateCallerIDFromContext(ctx context.Context) *querypb.VTGateCallerID {
 	}
 	return nil
 }
+
+// InternalCallerIDComponent is the component of the effective CallerIDs of
+// the queries that Vitess generates for itself and sends to other processes.
+const InternalCallerIDComponent = "vitess-internal"
+
+// NewInternalContext marks the Context as the one of queries that Vitess
+// generates for itself, like the ones of schema tracking or message polling,
+// so that they can be excluded from the per-user and per-table metrics.
+// The component names the part of Vitess that generates the queries.
+// Unlike the CallerIDs, the mark is not propagated over RPCs: the requests
+// sent to other processes use an effective CallerID from NewInternalCallerID.
+func NewInternalContext(ctx context.Context, component string) context.Context {
+	return context.WithValue(ctx, internalComponentKey, component)
+}
+
+// NewInternalCallerID creates the effective CallerID of the queries that the
+// given component of Vitess generates for itself and sends to other processes.
+func NewInternalCallerID(component string) *vtrpcpb.CallerID {
+	return NewEffectiveCallerID(component, InternalCallerIDComponent, "")
+}
+
+// InternalComponentFromContext returns the component of Vitess that generated
+// the queries of the Context, or an empty string for the queries of users.
+// Only the mark of NewInternalContext is trusted, since the clients can send
+// any effective CallerID.
+func InternalComponentFromContext(ctx context.Context) string {
+	component, _ := ctx.Value(internalComponentKey).(string)
+	return component
+}
+
+// InternalComponentFromCallerID returns the component of Vitess that sent a
+// request with an effective CallerID from NewInternalCallerID, or an empty
+// string. Since the clients can send any effective CallerID, it must only be
+// used by the RPCs that the clients can't reach, like GetSchema.
+func InternalComponentFromCallerID(ef *vtrpcpb.CallerID) string {
+	if GetComponent(ef) != InternalCallerIDComponent {
+		return ""
+	}
+	return GetPrincipal(ef)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callerid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternalContext(t *testing.T) {
+	ctx := NewContext(context.Background(), NewEffectiveCallerID("user", "", ""), NewImmediateCallerID("vtgate"))
+	assert.Empty(t, InternalComponentFromContext(ctx))
+
+	internal := NewInternalContext(ctx, "Messager")
+	assert.Equal(t, "Messager", InternalComponentFromContext(internal))
+	// The CallerIDs are left untouched.
+	assert.Equal(t, "user", GetPrincipal(EffectiveCallerIDFromContext(internal)))
+	assert.Equal(t, "vtgate", GetUsername(ImmediateCallerIDFromContext(internal)))
+}
+
+func TestInternalCallerID(t *testing.T) {
+	ef := NewInternalCallerID("SchemaTracker")
+	assert.Equal(t, "SchemaTracker", InternalComponentFromCallerID(ef))
+	assert.Empty(t, InternalComponentFromCallerID(NewEffectiveCallerID("SchemaTracker", "app", "")))
+	assert.Empty(t, InternalComponentFromCallerID(nil))
+
+	// The effective CallerID is sent by the clients, so it doesn't mark the
+	// Context as internal.
+	ctx := NewContext(context.Background(), ef, nil)
+	assert.Empty(t, InternalComponentFromContext(ctx))
+}
@@ -24,6 +24,7 @@ package dbconfigs
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"strings"
@@ -88,15 +89,10 @@ func init() {
 	signal.Notify(sigChan, syscall.SIGHUP)
 	go func() {
 		for range sigChan {
-			if fcs, ok := AllCredentialsServers["file"].(*FileCredentialsServer); ok {
-				fcs.mu.Lock()
-				fcs.dbCredentials = nil
-				fcs.mu.Unlock()
-			}
-			if vcs, ok := AllCredentialsServers["vault"].(*VaultCredentialsServer); ok {
-				vcs.mu.Lock()
-				vcs.dbCredsCache = nil
-				vcs.mu.Unlock()
+			// There is nothing to verify the credentials with here, so they
+			// are only swapped if they can be loaded.
+			if err := ReloadCredentials(func(CredentialsServer) error { return nil }); err != nil {
+				log.Errorf("Failed to reload the db credentials, keeping the previous ones: %v", err)
 			}
 		}
 	}()
@@ -133,6 +129,94 @@ func GetCredentialsServer() CredentialsServer {
 	return cs
 }
 
+// reloadableCredentialsServer is a CredentialsServer whose credentials can be
+// loaded again from their source, and then swapped for the ones in use.
+type reloadableCredentialsServer interface {
+	CredentialsServer
+	loadCredentials() (map[string][]string, error)
+	swapCredentials(creds map[string][]string)
+}
+
+// staticCredentialsServer is a CredentialsServer with fixed credentials, e.g.
+// the candidate credentials of ReloadCredentials.
+type staticCredentialsServer map[string][]string
+
+// GetUserAndPassword is part of the CredentialsServer interface
+func (scs staticCredentialsServer) GetUserAndPassword(user string) (string, string, error) {
+	passwd, ok := scs[user]
+	if !ok || len(passwd) == 0 {
+		return "", "", ErrUnknownUser
+	}
+	return user, passwd[0], nil
+}
+
+// reloadMu serializes ReloadCredentials, so that the credentials swapped in
+// are always the ones that were verified.
+var reloadMu sync.Mutex
+
+// ReloadCredentials loads the credentials currently in the file or in Vault
+// into a candidate CredentialsServer, and calls verify with it, e.g. to check
+// that the users can connect with them. The credentials in use are only
+// swapped for the candidate ones if verify succeeds. The connections that are
+// already open are kept.
+func ReloadCredentials(verify func(candidate CredentialsServer) error) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cs, ok := GetCredentialsServer().(reloadableCredentialsServer)
+	if !ok {
+		return fmt.Errorf("db credentials server %v can't reload its credentials", dbCredentialsServer)
+	}
+	creds, err := cs.loadCredentials()
+	if err != nil {
+		return err
+	}
+	if err := verify(staticCredentialsServer(creds)); err != nil {
+		return err
+	}
+	cs.swapCredentials(creds)
+	return nil
+}
+
+var (
+	rotationMu        sync.Mutex
+	rotationListeners = make(map[int]func())
+	nextRotationID    int
+)
+
+// OnCredentialsRotated registers a function that is called when the
+// credentials are rotated, e.g. to expire the pooled connections that were
+// opened with the previous credentials. It returns a function that
+// unregisters it.
+func OnCredentialsRotated(f func()) (unregister func()) {
+	rotationMu.Lock()
+	defer rotationMu.Unlock()
+	id := nextRotationID
+	nextRotationID++
+	rotationListeners[id] = f
+	return func() {
+		rotationMu.Lock()
+		defer rotationMu.Unlock()
+		delete(rotationListeners, id)
+	}
+}
+
+// CredentialsRotated notifies the functions registered with
+// OnCredentialsRotated that the credentials were rotated. It should only be
+// called once the new credentials were reloaded and verified.
+func CredentialsRotated() {
+	rotationMu.Lock()
+	listeners := make([]func(), 0, len(rotationListeners))
+	for _, f := range rotationListeners {
+		listeners = append(listeners, f)
+	}
+	rotationMu.Unlock()
+
+	for _, f := range listeners {
+		f()
+	}
+}
+
 // FileCredentialsServer is a simple implementation of CredentialsServer using
 // a json file. Protected by mu.
 type FileCredentialsServer struct {
@@ -165,16 +249,11 @@ func (fcs *FileCredentialsServer) GetUserAndPassword(user string) (string, strin
 	if fcs.dbCredentials == nil {
 		fcs.dbCredentials = make(map[string][]string)
 
-		data, err := os.ReadFile(dbCredentialsFile)
+		creds, err := readCredentialsFile()
 		if err != nil {
-			log.Warningf("Failed to read dbCredentials file: %v", dbCredentialsFile)
-			return "", "", err
-		}
-
-		if err = json.Unmarshal(data, &fcs.dbCredentials); err != nil {
-			log.Warningf("Failed to parse dbCredentials file: %v", dbCredentialsFile)
 			return "", "", err
 		}
+		fcs.dbCredentials = creds
 	}
 
 	passwd, ok := fcs.dbCredentials[user]
@@ -184,6 +263,34 @@ func (fcs *FileCredentialsServer) GetUserAndPassword(user string) (string, strin
 	return user, passwd[0], nil
 }
 
+func (fcs *FileCredentialsServer) loadCredentials() (map[string][]string, error) {
+	if dbCredentialsFile == "" {
+		return nil, nil
+	}
+	return readCredentialsFile()
+}
+
+func (fcs *FileCredentialsServer) swapCredentials(creds map[string][]string) {
+	fcs.mu.Lock()
+	defer fcs.mu.Unlock()
+	fcs.dbCredentials = creds
+}
+
+func readCredentialsFile() (map[string][]string, error) {
+	data, err := os.ReadFile(dbCredentialsFile)
+	if err != nil {
+		log.Warningf("Failed to read dbCredentials file: %v", dbCredentialsFile)
+		return nil, err
+	}
+
+	creds := make(map[string][]string)
+	if err = json.Unmarshal(data, &creds); err != nil {
+		log.Warningf("Failed to parse dbCredentials file: %v", dbCredentialsFile)
+		return nil, err
+	}
+	return creds, nil
+}
+
 // GetUserAndPassword for Vault implementation
 func (vcs *VaultCredentialsServer) GetUserAndPassword(user string) (string, string, error) {
 	vcs.mu.Lock()
@@ -208,17 +315,46 @@ func (vcs *VaultCredentialsServer) GetUserAndPassword(user string) (string, stri
 		return user, vcs.dbCredsCache[user][0], nil
 	}
 
+	dbCreds, err := vcs.fetchCredentialsLocked()
+	if err != nil {
+		return "", "", err
+	}
+	if dbCreds[user] == nil {
+		log.Warningf("Vault lookup for user not found: %v\n", user)
+		return "", "", ErrUnknownUser
+	}
+
+	vcs.dbCredsCache = dbCreds
+	vcs.cacheValid = true
+	return user, dbCreds[user][0], nil
+}
+
+func (vcs *VaultCredentialsServer) loadCredentials() (map[string][]string, error) {
+	vcs.mu.Lock()
+	defer vcs.mu.Unlock()
+	return vcs.fetchCredentialsLocked()
+}
+
+func (vcs *VaultCredentialsServer) swapCredentials(creds map[string][]string) {
+	vcs.mu.Lock()
+	defer vcs.mu.Unlock()
+	vcs.dbCredsCache = creds
+	vcs.cacheValid = true
+}
+
+// fetchCredentialsLocked reads the credentials from the Vault server.
+func (vcs *VaultCredentialsServer) fetchCredentialsLocked() (map[string][]string, error) {
 	if vaultAddr == "" {
-		return "", "", errors.New("No Vault server specified")
+		return nil, errors.New("No Vault server specified")
 	}
 
 	token, err := readFromFile(vaultTokenFile)
 	if err != nil {
-		return "", "", errors.New("No Vault token in provided filename")
+		return nil, errors.New("No Vault token in provided filename")
 	}
 	secretID, err := readFromFile(vaultRoleSecretIDFile)
 	if err != nil {
-		return "", "", errors.New("No Vault secret_id in provided filename")
+		return nil, errors.New("No Vault secret_id in provided filename")
 	}
 
 	// From here on, errors might be transient, so we use ErrUnknownUser
@@ -260,35 +396,28 @@ func (vcs *VaultCredentialsServer) GetUserAndPassword(user string) (string, stri
 		if err != nil || vcs.vaultClient == nil {
 			log.Errorf("Error in vault client initialization, will retry: %v", err)
 			vcs.vaultClient = nil
-			return "", "", ErrUnknownUser
+			return nil, ErrUnknownUser
 		}
 	}
 
 	secret, err := vcs.vaultClient.GetSecret(vaultPath)
 	if err != nil {
 		log.Errorf("Error in Vault server params: %v", err)
-		return "", "", ErrUnknownUser
+		return nil, ErrUnknownUser
 	}
 
 	if secret.JSONSecret == nil {
 		log.Errorf("Empty DB credentials retrieved from Vault server")
-		return "", "", ErrUnknownUser
+		return nil, ErrUnknownUser
 	}
 
 	dbCreds := make(map[string][]string)
 	if err = json.Unmarshal(secret.JSONSecret, &dbCreds); err != nil {
 		log.Errorf("Error unmarshaling DB credentials from Vault server")
-		return "", "", ErrUnknownUser
-	}
-	if dbCreds[user] == nil {
-		log.Warningf("Vault lookup for user not found: %v\n", user)
-		return "", "", ErrUnknownUser
+		return nil, ErrUnknownUser
 	}
 	log.Infof("Vault client status: %s", vcs.vaultClient.GetStatus())
-
-	vcs.dbCredsCache = dbCreds
-	vcs.cacheValid = true
-	return user, dbCreds[user][0], nil
+	return dbCreds, nil
 }
 
 func readFromFile(filePath string) (string, error) {
@@ -303,10 +432,13 @@ func readFromFile(filePath string) (string, error) {
 }
 
 // WithCredentials returns a copy of the provided ConnParams that we can use
-// to connect, after going through the CredentialsServer.
-func withCredentials(cp *mysql.ConnParams) (*mysql.ConnParams, error) {
+// to connect, after going through the CredentialsServer, or through cs if set.
+func withCredentials(cs CredentialsServer, cp *mysql.ConnParams) (*mysql.ConnParams, error) {
+	if cs == nil {
+		cs = GetCredentialsServer()
+	}
 	result := *cp
-	user, passwd, err := GetCredentialsServer().GetUserAndPassword(cp.Uname)
+	user, passwd, err := cs.GetUserAndPassword(cp.Uname)
 	switch err {
 	case nil:
 		result.Uname = user
@@ -161,6 +161,9 @@ func registerPerUserFlags(fs *pflag.FlagSet, userKey string, uc *UserConfig, cp
 // Connector contains Connection Parameters for mysql connection
 type Connector struct {
 	connParams *mysql.ConnParams
+
+	// credentialsServer overrides the configured credentials server if set.
+	credentialsServer CredentialsServer
 }
 
 // New initializes a ConnParams from mysql connection parameters
@@ -170,6 +173,14 @@ func New(mcp *mysql.ConnParams) Connector {
 	}
 }
 
+// WithCredentialsServer returns a copy of the connector that gets its
+// credentials from cs instead of the configured credentials server, e.g. to
+// verify the candidate credentials of ReloadCredentials.
+func (c Connector) WithCredentialsServer(cs CredentialsServer) Connector {
+	c.credentialsServer = cs
+	return c
+}
+
 // Connect will invoke the mysql.connect method and return a connection
 func (c *Connector) Connect(ctx context.Context) (*mysql.Conn, error) {
 	params, err := c.MysqlParams()
@@ -189,7 +200,7 @@ func (c Connector) MysqlParams() (*mysql.ConnParams, error) {
 		// This is only possible during tests.
 		return nil, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "parameters are empty")
 	}
-	params, err := withCredentials(c.connParams)
+	params, err := withCredentials(c.credentialsServer, c.connParams)
 	if err != nil {
 		return nil, err
 	}
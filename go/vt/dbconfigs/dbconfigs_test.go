@@ -17,6 +17,7 @@ limitations under the License.
 package dbconfigs
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"syscall"
@@ -303,6 +304,68 @@ func hupTest(t *testing.T, tmpFile *os.File, oldStr, newStr string) {
 	}
 }
 
+func TestReloadCredentials(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "credentials.json")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+	dbCredentialsFile = tmpFile.Name()
+	dbCredentialsServer = "file"
+
+	noVerify := func(CredentialsServer) error { return nil }
+	require.NoError(t, os.WriteFile(tmpFile.Name(), []byte(`{"vt_app": ["old"]}`), 0600))
+	// Replace the credentials cached by the previous tests.
+	require.NoError(t, ReloadCredentials(noVerify))
+	_, pass, err := GetCredentialsServer().GetUserAndPassword("vt_app")
+	require.NoError(t, err)
+	require.Equal(t, "old", pass)
+
+	require.NoError(t, os.WriteFile(tmpFile.Name(), []byte(`{"vt_app": ["new"]}`), 0600))
+	_, pass, err = GetCredentialsServer().GetUserAndPassword("vt_app")
+	require.NoError(t, err)
+	require.Equal(t, "old", pass)
+
+	// The candidate credentials are only swapped in once verified.
+	connector := New(&mysql.ConnParams{Uname: "vt_app"})
+	err = ReloadCredentials(func(candidate CredentialsServer) error {
+		params, err := connector.WithCredentialsServer(candidate).MysqlParams()
+		require.NoError(t, err)
+		require.Equal(t, "new", params.Pass)
+		return errors.New("access denied")
+	})
+	require.EqualError(t, err, "access denied")
+	params, err := connector.MysqlParams()
+	require.NoError(t, err)
+	require.Equal(t, "old", params.Pass)
+
+	require.NoError(t, ReloadCredentials(noVerify))
+	params, err = connector.MysqlParams()
+	require.NoError(t, err)
+	require.Equal(t, "new", params.Pass)
+
+	// Credentials that can't be loaded are not swapped in.
+	require.NoError(t, os.WriteFile(tmpFile.Name(), []byte(`{"vt_app": `), 0600))
+	require.Error(t, ReloadCredentials(noVerify))
+	params, err = connector.MysqlParams()
+	require.NoError(t, err)
+	require.Equal(t, "new", params.Pass)
+}
+
+func TestCredentialsRotated(t *testing.T) {
+	var first, second int
+	unregisterFirst := OnCredentialsRotated(func() { first++ })
+	unregisterSecond := OnCredentialsRotated(func() { second++ })
+	defer unregisterSecond()
+
+	CredentialsRotated()
+	require.Equal(t, 1, first)
+	require.Equal(t, 1, second)
+
+	unregisterFirst()
+	CredentialsRotated()
+	require.Equal(t, 1, first)
+	require.Equal(t, 2, second)
+}
+
 func TestYaml(t *testing.T) {
 	db := DBConfigs{
 		Socket: "a",
@@ -36,6 +36,10 @@ import (
 // PooledDBConnection objects.
 type ConnectionPool struct {
 	*smartconnpool.ConnPool[*DBConnection]
+
+	// unregisterRotation stops expiring the connections when the credentials
+	// are rotated, once the pool is closed.
+	unregisterRotation func()
 }
 
 // NewConnectionPool creates a new ConnectionPool. The name is used
@@ -69,6 +73,18 @@ func (cp *ConnectionPool) Open(info dbconfigs.Connector) {
 	}
 
 	cp.ConnPool.Open(connect, refresh)
+	if cp.unregisterRotation == nil {
+		cp.unregisterRotation = dbconfigs.OnCredentialsRotated(cp.ConnPool.ExpireConnections)
+	}
+}
+
+// Close closes the pool, and waits for its connections to be returned.
+func (cp *ConnectionPool) Close() {
+	if cp.unregisterRotation != nil {
+		cp.unregisterRotation()
+		cp.unregisterRotation = nil
+	}
+	cp.ConnPool.Close()
 }
 
 func (cp *ConnectionPool) Get(ctx context.Context) (*PooledDBConnection, error) {
@@ -220,6 +220,7 @@ func (fhc *FakeHealthCheck) RemoveTablet(tablet *topodatapb.Tablet) {
 		return
 	}
 	delete(fhc.items, key)
+	delete(fhc.itemsAlias, tablet.Alias.String())
 }
 
 // ReplaceTablet removes the old tablet and adds the new.
@@ -311,6 +312,7 @@ func (fhc *FakeHealthCheck) Reset() {
 	defer fhc.mu.Unlock()
 
 	fhc.items = make(map[string]*fhcItem)
+	fhc.itemsAlias = make(map[string]*fhcItem)
 	fhc.currentTabletUID = 0
 }
 
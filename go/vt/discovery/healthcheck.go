@@ -343,6 +343,10 @@ func NewHealthCheck(ctx context.Context, retryDelay, healthCheckTimeout time.Dur
 		cells = append(cells, localCell)
 	}
 
+	if _, err := ParseReplicationLagSignal(replicationLagSignal.Get()); err != nil {
+		log.Exitf("Cannot parse discovery_replication_lag_signal parameter: %v", err)
+	}
+
 	for _, c := range cells {
 		log.Infof("Setting up healthcheck for cell: %v", c)
 		if c == "" {
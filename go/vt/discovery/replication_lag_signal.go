@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"fmt"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// ReplicationLagSignal selects which of the replication lag signals reported
+// by a tablet is trusted to judge how far behind it is.
+type ReplicationLagSignal string
+
+const (
+	// ReplicationLagSignalReported uses the replication lag as reported by the
+	// tablet, which depends on the replication tracker mode of the tablet.
+	ReplicationLagSignalReported ReplicationLagSignal = "reported"
+	// ReplicationLagSignalSQLThread uses the lag of the replication SQL thread.
+	ReplicationLagSignalSQLThread ReplicationLagSignal = "sql_thread"
+	// ReplicationLagSignalHeartbeat uses the lag measured from the heartbeats of the primary.
+	ReplicationLagSignalHeartbeat ReplicationLagSignal = "heartbeat"
+)
+
+// ParseReplicationLagSignal parses the name of a replication lag signal.
+// An empty name selects ReplicationLagSignalReported.
+func ParseReplicationLagSignal(name string) (ReplicationLagSignal, error) {
+	switch signal := ReplicationLagSignal(name); signal {
+	case "":
+		return ReplicationLagSignalReported, nil
+	case ReplicationLagSignalReported, ReplicationLagSignalSQLThread, ReplicationLagSignalHeartbeat:
+		return signal, nil
+	}
+	return "", fmt.Errorf("invalid replication lag signal %q, must be one of: %s, %s, %s",
+		name, ReplicationLagSignalReported, ReplicationLagSignalSQLThread, ReplicationLagSignalHeartbeat)
+}
+
+// ReplicationLagSeconds returns the replication lag of the given stats according
+// to the signal. It falls back to the reported replication lag if the tablet
+// did not measure the signal.
+func ReplicationLagSeconds(stats *querypb.RealtimeStats, signal ReplicationLagSignal) uint32 {
+	var lag *uint32
+	if signals := stats.GetReplicationLagSignals(); signals != nil {
+		switch signal {
+		case ReplicationLagSignalSQLThread:
+			lag = signals.SqlThreadLagSeconds
+		case ReplicationLagSignalHeartbeat:
+			lag = signals.HeartbeatLagSeconds
+		}
+	}
+	if lag == nil {
+		return stats.GetReplicationLagSeconds()
+	}
+	return *lag
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestParseReplicationLagSignal(t *testing.T) {
+	signal, err := ParseReplicationLagSignal("")
+	require.NoError(t, err)
+	assert.Equal(t, ReplicationLagSignalReported, signal)
+
+	for _, want := range []ReplicationLagSignal{ReplicationLagSignalReported, ReplicationLagSignalSQLThread, ReplicationLagSignalHeartbeat} {
+		signal, err := ParseReplicationLagSignal(string(want))
+		require.NoError(t, err)
+		assert.Equal(t, want, signal)
+	}
+
+	_, err = ParseReplicationLagSignal("seconds_behind_master")
+	assert.EqualError(t, err, `invalid replication lag signal "seconds_behind_master", must be one of: reported, sql_thread, heartbeat`)
+}
+
+func TestReplicationLagSeconds(t *testing.T) {
+	stats := &querypb.RealtimeStats{
+		ReplicationLagSeconds: 7,
+		ReplicationLagSignals: &querypb.ReplicationLagSignals{
+			SqlThreadLagSeconds: proto.Uint32(1),
+		},
+	}
+	assert.EqualValues(t, 7, ReplicationLagSeconds(stats, ReplicationLagSignalReported))
+	assert.EqualValues(t, 1, ReplicationLagSeconds(stats, ReplicationLagSignalSQLThread))
+	// The heartbeat is not measured, so the reported lag is used.
+	assert.EqualValues(t, 7, ReplicationLagSeconds(stats, ReplicationLagSignalHeartbeat))
+
+	// Tablets that don't report signals at all.
+	stats = &querypb.RealtimeStats{ReplicationLagSeconds: 3}
+	assert.EqualValues(t, 3, ReplicationLagSeconds(stats, ReplicationLagSignalHeartbeat))
+	assert.EqualValues(t, 0, ReplicationLagSeconds(nil, ReplicationLagSignalHeartbeat))
+}
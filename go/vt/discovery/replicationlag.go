@@ -61,6 +61,14 @@ var (
 			Default:  true,
 		},
 	)
+	replicationLagSignal = viperutil.Configure(
+		configKey("replication_lag_signal"),
+		viperutil.Options[string]{
+			FlagName: "discovery_replication_lag_signal",
+			Default:  string(ReplicationLagSignalReported),
+			Dynamic:  true,
+		},
+	)
 )
 
 func init() {
@@ -72,12 +80,14 @@ func registerReplicationFlags(fs *pflag.FlagSet) {
 	fs.Duration("discovery_high_replication_lag_minimum_serving", highReplicationLagMinServing.Default(), "Threshold above which replication lag is considered too high when applying the min_number_serving_vttablets flag.")
 	fs.Int("min_number_serving_vttablets", minNumTablets.Default(), "The minimum number of vttablets for each replicating tablet_type (e.g. replica, rdonly) that will be continue to be used even with replication lag above discovery_low_replication_lag, but still below discovery_high_replication_lag_minimum_serving.")
 	fs.Bool("legacy_replication_lag_algorithm", legacyReplicationLagAlgorithm.Default(), "Use the legacy algorithm when selecting vttablets for serving.")
+	fs.String("discovery_replication_lag_signal", replicationLagSignal.Default(), "The replication lag signal reported by the vttablets that is used to judge their replication lag: reported, sql_thread or heartbeat. The reported replication lag is used for vttablets that don't report the signal.")
 
 	viperutil.BindFlags(fs,
 		lowReplicationLag,
 		highReplicationLagMinServing,
 		minNumTablets,
 		legacyReplicationLagAlgorithm,
+		replicationLagSignal,
 	)
 }
 
@@ -111,19 +121,39 @@ func SetMinNumTablets(numTablets int) {
 	minNumTablets.Set(numTablets)
 }
 
+// GetReplicationLagSignal getter for use by debugenv
+func GetReplicationLagSignal() ReplicationLagSignal {
+	signal, err := ParseReplicationLagSignal(replicationLagSignal.Get())
+	if err != nil {
+		return ReplicationLagSignalReported
+	}
+	return signal
+}
+
+// SetReplicationLagSignal setter for use by debugenv
+func SetReplicationLagSignal(signal ReplicationLagSignal) {
+	replicationLagSignal.Set(string(signal))
+}
+
+// TabletReplicationLag returns the replication lag of the given TabletHealth, as measured
+// by the signal of the discovery_replication_lag_signal flag.
+func TabletReplicationLag(tabletHealth *TabletHealth) uint32 {
+	return ReplicationLagSeconds(tabletHealth.Stats, GetReplicationLagSignal())
+}
+
 // IsReplicationLagHigh verifies that the given TabletHealth refers to a tablet with high
 // replication lag, i.e. higher than the configured discovery_low_replication_lag flag.
 func IsReplicationLagHigh(tabletHealth *TabletHealth) bool {
-	return float64(tabletHealth.Stats.ReplicationLagSeconds) > lowReplicationLag.Get().Seconds()
+	return float64(TabletReplicationLag(tabletHealth)) > lowReplicationLag.Get().Seconds()
 }
 
 // IsReplicationLagVeryHigh verifies that the given TabletHealth refers to a tablet with very high
 // replication lag, i.e. higher than the configured discovery_high_replication_lag_minimum_serving flag.
 func IsReplicationLagVeryHigh(tabletHealth *TabletHealth) bool {
-	return float64(tabletHealth.Stats.ReplicationLagSeconds) > highReplicationLagMinServing.Get().Seconds()
+	return float64(TabletReplicationLag(tabletHealth)) > highReplicationLagMinServing.Get().Seconds()
 }
 
-// FilterStatsByReplicationLag filters the list of TabletHealth by TabletHealth.Stats.ReplicationLagSeconds.
+// FilterStatsByReplicationLag filters the list of TabletHealth by their replication lag, see TabletReplicationLag.
 // Note that TabletHealth that is non-serving or has error is ignored.
 //
 // The simplified logic:
@@ -172,7 +202,7 @@ func filterStatsByLag(tabletHealthList []*TabletHealth) []*TabletHealth {
 		// Save the current replication lag for a stable sort later.
 		list = append(list, tabletLagSnapshot{
 			ts:     ts,
-			replag: ts.Stats.ReplicationLagSeconds})
+			replag: TabletReplicationLag(ts)})
 	}
 
 	// Sort by replication lag.
@@ -235,7 +265,7 @@ func filterStatsByLagWithLegacyAlgorithm(tabletHealthList []*TabletHealth) []*Ta
 		if !IsReplicationLagVeryHigh(ts) {
 			snapshots = append(snapshots, tabletLagSnapshot{
 				ts:     ts,
-				replag: ts.Stats.ReplicationLagSeconds})
+				replag: TabletReplicationLag(ts)})
 		}
 	}
 	if len(snapshots) == 0 {
@@ -249,7 +279,7 @@ func filterStatsByLagWithLegacyAlgorithm(tabletHealthList []*TabletHealth) []*Ta
 		for _, ts := range list {
 			snapshots = append(snapshots, tabletLagSnapshot{
 				ts:     ts,
-				replag: ts.Stats.ReplicationLagSeconds})
+				replag: TabletReplicationLag(ts)})
 		}
 	}
 
@@ -289,7 +319,7 @@ func mean(tabletHealthList []*TabletHealth, idxExclude int) (uint64, error) {
 		if i == idxExclude {
 			continue
 		}
-		sum = sum + uint64(ts.Stats.ReplicationLagSeconds)
+		sum = sum + uint64(TabletReplicationLag(ts))
 		count++
 	}
 	if count == 0 {
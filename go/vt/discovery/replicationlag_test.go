@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
 	"vitess.io/vitess/go/test/utils"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -32,6 +34,7 @@ func init() {
 	highReplicationLagMinServing.Set(2 * time.Hour)
 	minNumTablets.Set(2)
 	legacyReplicationLagAlgorithm.Set(true)
+	replicationLagSignal.Set(string(ReplicationLagSignalReported))
 }
 
 // testSetLegacyReplicationLagAlgorithm is a test helper function, if this is used by a production code path, something is wrong.
@@ -232,3 +235,35 @@ func TestFilterStatsByReplicationLagOneTabletMin(t *testing.T) {
 	// Reset to the default
 	testSetMinNumTablets(2)
 }
+
+func TestFilterStatsByReplicationLagSignal(t *testing.T) {
+	defer utils.EnsureNoLeaks(t)
+	testSetMinNumTablets(1)
+	defer testSetMinNumTablets(2)
+
+	// ts1 reports a low lag, but its heartbeat shows it is actually far behind.
+	ts1 := &TabletHealth{
+		Tablet:  topo.NewTablet(1, "cell", "host1"),
+		Serving: true,
+		Stats: &querypb.RealtimeStats{
+			ReplicationLagSeconds: 1,
+			ReplicationLagSignals: &querypb.ReplicationLagSignals{
+				SqlThreadLagSeconds: proto.Uint32(1),
+				HeartbeatLagSeconds: proto.Uint32(100 * 60),
+			},
+		},
+	}
+	// ts2 doesn't report any signal.
+	ts2 := &TabletHealth{
+		Tablet:  topo.NewTablet(2, "cell", "host2"),
+		Serving: true,
+		Stats:   &querypb.RealtimeStats{ReplicationLagSeconds: 10},
+	}
+	got := FilterStatsByReplicationLag([]*TabletHealth{ts1, ts2})
+	mustMatch(t, []*TabletHealth{ts1, ts2}, got, "FilterStatsByReplicationLag")
+
+	SetReplicationLagSignal(ReplicationLagSignalHeartbeat)
+	defer SetReplicationLagSignal(ReplicationLagSignalReported)
+	got = FilterStatsByReplicationLag([]*TabletHealth{ts1, ts2})
+	mustMatch(t, []*TabletHealth{ts2}, got, "FilterStatsByReplicationLag")
+}
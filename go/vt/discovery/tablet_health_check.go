@@ -238,8 +238,9 @@ func (thc *tabletHealthCheck) isTrivialReplagChange(newStats *query.RealtimeStat
 	// Skip replag filter when replag remains in the low rep lag range,
 	// which should be the case majority of the time.
 	lowRepLag := lowReplicationLag.Get().Seconds()
-	oldRepLag := float64(thc.Stats.ReplicationLagSeconds)
-	newRepLag := float64(newStats.ReplicationLagSeconds)
+	signal := GetReplicationLagSignal()
+	oldRepLag := float64(ReplicationLagSeconds(thc.Stats, signal))
+	newRepLag := float64(ReplicationLagSeconds(newStats, signal))
 	if oldRepLag <= lowRepLag && newRepLag <= lowRepLag {
 		return true
 	}
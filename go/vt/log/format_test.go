@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	oldOutput := jsonOutput
+	jsonOutput = &buf
+	defer func() {
+		require.NoError(t, setFormat(TextFormat))
+		jsonOutput = oldOutput
+	}()
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--log-format", "json"}))
+
+	Infof("hello %s", "world")
+	Warning("careful")
+	ErrorDepth(0, "failed")
+	V(0).Infof("verbose %d", 0)
+	V(100).Infof("too verbose")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+
+	expected := []struct {
+		severity string
+		message  string
+	}{
+		{"INFO", "hello world"},
+		{"WARNING", "careful"},
+		{"ERROR", "failed"},
+		{"INFO", "verbose 0"},
+	}
+	for i, want := range expected {
+		var entry jsonEntry
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &entry))
+		assert.Equal(t, want.severity, entry.Severity)
+		assert.Equal(t, want.message, entry.Message)
+		assert.True(t, strings.HasPrefix(entry.Caller, "format_test.go:"), "caller should be the test, got %s", entry.Caller)
+		assert.False(t, entry.Time.IsZero())
+	}
+
+	assert.Error(t, fs.Set("log-format", "xml"))
+}
+
+func TestVerbosity(t *testing.T) {
+	oldLevel, oldVModule := Verbosity(), VModule()
+	defer func() {
+		require.NoError(t, SetVerbosity(oldLevel))
+		require.NoError(t, SetVModule(oldVModule))
+	}()
+
+	require.NoError(t, SetVerbosity(3))
+	assert.Equal(t, Level(3), Verbosity())
+	assert.True(t, bool(V(3)))
+	assert.False(t, bool(V(4)))
+	assert.Error(t, SetVerbosity(-1))
+
+	require.NoError(t, SetVerbosity(0))
+	require.NoError(t, SetVModule("format_test=2"))
+	assert.Equal(t, "format_test=2", VModule())
+	assert.True(t, bool(V(2)), "the module of the caller should be verbose")
+	assert.False(t, bool(V(3)))
+
+	require.NoError(t, SetVModule(""))
+	assert.False(t, bool(V(2)))
+	assert.Error(t, SetVModule("format_test=x"))
+}
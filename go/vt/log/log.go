@@ -34,9 +34,6 @@ import (
 type Level = glog.Level
 
 var (
-	// V quickly checks if the logging verbosity meets a threshold.
-	V = glog.V
-
 	// Flush ensures any pending I/O is written.
 	Flush = glog.Flush
 
@@ -86,6 +83,7 @@ func RegisterFlags(fs *pflag.FlagSet) {
 		val: fmt.Sprintf("%d", atomic.LoadUint64(&glog.MaxSize)),
 	}
 	fs.Var(&flagVal, "log_rotate_max_size", "size in bytes at which logs are rotated (glog.MaxSize)")
+	fs.Var(&logFormat{val: TextFormat}, "log-format", "format of the Info, Warning and Error logs: text, or json to write them to stderr as JSON lines")
 }
 
 // logRotateMaxSize implements pflag.Value and is used to
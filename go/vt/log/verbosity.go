@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/golang/glog"
+)
+
+// Verbose is returned by V, and logs only if the logging verbosity meets the
+// threshold given to V.
+type Verbose bool
+
+// V quickly checks if the logging verbosity meets a threshold, either the
+// global one or the one of the module of the caller.
+func V(level Level) Verbose {
+	return Verbose(glog.VDepth(1, level))
+}
+
+// Info is equivalent to the global Info function, guarded by the value of v.
+func (v Verbose) Info(args ...any) {
+	if v {
+		infoDepth(1, fmt.Sprint(args...))
+	}
+}
+
+// Infof is equivalent to the global Infof function, guarded by the value of v.
+func (v Verbose) Infof(format string, args ...any) {
+	if v {
+		infoDepth(1, fmt.Sprintf(format, args...))
+	}
+}
+
+// Verbosity returns the global logging verbosity.
+func Verbosity() Level {
+	level, _ := strconv.Atoi(flag.Lookup("v").Value.String())
+	return Level(level)
+}
+
+// SetVerbosity sets the global logging verbosity at runtime, like the -v flag.
+func SetVerbosity(level Level) error {
+	if level < 0 {
+		return fmt.Errorf("invalid verbosity %d: it must not be negative", level)
+	}
+	return flag.Lookup("v").Value.Set(strconv.Itoa(int(level)))
+}
+
+// VModule returns the per-module logging verbosity, as a comma-separated list of
+// pattern=N settings.
+func VModule() string {
+	return flag.Lookup("vmodule").Value.String()
+}
+
+// SetVModule sets the per-module logging verbosity at runtime, like the -vmodule
+// flag. The spec is a comma-separated list of pattern=N settings, where pattern
+// is a glob matched against the name of the source file (without ".go") or its
+// full path, e.g. "planbuilder=3,healthcheck*=2". An empty spec removes all the
+// per-module settings.
+func SetVModule(spec string) error {
+	return flag.Lookup("vmodule").Value.Set(spec)
+}
@@ -344,6 +344,17 @@ func (setting *DDLStrategySetting) RuntimeOptions() []string {
 	return validOpts
 }
 
+// EnforcedBy returns the setting to use when the given enforced setting, such
+// as a keyspace's DDL strategy, takes precedence over this one: the enforced
+// strategy is used, and this setting can only add options to it. A direct
+// enforced strategy takes no options.
+func (setting *DDLStrategySetting) EnforcedBy(enforced *DDLStrategySetting) (*DDLStrategySetting, error) {
+	if enforced.Strategy.IsDirect() || setting.Options == "" {
+		return NewDDLStrategySetting(enforced.Strategy, enforced.Options), nil
+	}
+	return ParseDDLStrategy(fmt.Sprintf("%s %s %s", enforced.Strategy, enforced.Options, setting.Options))
+}
+
 // ToString returns a simple string representation of this instance
 func (setting *DDLStrategySetting) ToString() string {
 	return fmt.Sprintf("DDLStrategySetting: strategy=%v, options=%s", setting.Strategy, setting.Options)
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsDirect(t *testing.T) {
@@ -415,3 +416,57 @@ func TestParseDDLStrategy(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestEnforcedBy(t *testing.T) {
+	tt := []struct {
+		setting     string
+		enforced    string
+		expect      string
+		expectError string
+	}{
+		{
+			setting:  "direct",
+			enforced: "vitess --postpone-launch",
+			expect:   "vitess --postpone-launch",
+		},
+		{
+			setting:  "vitess --allow-concurrent",
+			enforced: "vitess --postpone-launch",
+			expect:   "vitess --postpone-launch --allow-concurrent",
+		},
+		{
+			setting:  "online --allow-concurrent",
+			enforced: "direct",
+			expect:   "direct",
+		},
+		{
+			setting:  "mysql",
+			enforced: "vitess",
+			expect:   "vitess",
+		},
+		{
+			setting:     "gh-ost --max-load=Threads_running=100",
+			enforced:    "vitess",
+			expectError: "invalid flags for vitess strategy",
+		},
+	}
+	for _, ts := range tt {
+		t.Run(ts.setting+"/"+ts.enforced, func(t *testing.T) {
+			setting, err := ParseDDLStrategy(ts.setting)
+			require.NoError(t, err)
+			enforced, err := ParseDDLStrategy(ts.enforced)
+			require.NoError(t, err)
+
+			result, err := setting.EnforcedBy(enforced)
+			if ts.expectError != "" {
+				assert.ErrorContains(t, err, ts.expectError)
+				return
+			}
+			require.NoError(t, err)
+			expect, err := ParseDDLStrategy(ts.expect)
+			require.NoError(t, err)
+			assert.Equal(t, expect.Strategy, result.Strategy)
+			assert.Equal(t, strings.Fields(expect.Options), strings.Fields(result.Options))
+		})
+	}
+}
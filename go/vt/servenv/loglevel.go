@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/log"
+)
+
+// logLevel is the logging verbosity returned by /debug/loglevel.
+type logLevel struct {
+	V       log.Level `json:"v"`
+	VModule string    `json:"vmodule"`
+}
+
+// logLevelHandler shows the logging verbosity, and changes it if the request
+// has a "v" or a "vmodule" parameter, without restarting the process:
+//
+//	curl -X POST 'http://host:port/debug/loglevel?v=1&vmodule=planbuilder=3,healthcheck*=2'
+//
+// The "vmodule" patterns are matched against the names of the source files, and
+// an empty "vmodule" removes all the per-module settings.
+func logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if r.Form.Has("v") {
+			level, err := strconv.Atoi(r.Form.Get("v"))
+			if err == nil {
+				err = log.SetVerbosity(log.Level(level))
+			}
+			if err != nil {
+				http.Error(w, "invalid v: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if r.Form.Has("vmodule") {
+			if err := log.SetVModule(r.Form.Get("vmodule")); err != nil {
+				http.Error(w, "invalid vmodule: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		log.Infof("Set log verbosity to v=%d vmodule=%q", log.Verbosity(), log.VModule())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&logLevel{
+		V:       log.Verbosity(),
+		VModule: log.VModule(),
+	})
+}
+
+func init() {
+	OnInit(func() {
+		HTTPHandleFunc("/debug/loglevel", logLevelHandler)
+	})
+}
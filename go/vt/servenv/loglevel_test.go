@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servenv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	oldLevel, oldVModule := log.Verbosity(), log.VModule()
+	defer func() {
+		require.NoError(t, log.SetVerbosity(oldLevel))
+		require.NoError(t, log.SetVModule(oldVModule))
+	}()
+
+	request := func(method, query string) (*httptest.ResponseRecorder, logLevel) {
+		w := httptest.NewRecorder()
+		logLevelHandler(w, httptest.NewRequest(method, "/debug/loglevel?"+query, nil))
+
+		var level logLevel
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &level))
+		}
+		return w, level
+	}
+
+	w, level := request(http.MethodPost, "v=2&vmodule=planbuilder=3,healthcheck*=1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, logLevel{V: 2, VModule: "planbuilder=3,healthcheck*=1"}, level)
+
+	// GET requests do not change the verbosity.
+	w, level = request(http.MethodGet, "v=5")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, logLevel{V: 2, VModule: "planbuilder=3,healthcheck*=1"}, level)
+
+	// Only the given parameters are changed.
+	w, level = request(http.MethodPost, "vmodule=")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, logLevel{V: 2}, level)
+
+	w, _ = request(http.MethodPost, "v=high")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = request(http.MethodPost, "vmodule=planbuilder")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, log.Level(2), log.Verbosity())
+}
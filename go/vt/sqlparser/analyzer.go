@@ -111,7 +111,7 @@ func ASTToStatementType(stmt Statement) StatementType {
 		return StmtLockTables
 	case *UnlockTables:
 		return StmtUnlockTables
-	case *Flush:
+	case *Flush, *FlushVitessPlans:
 		return StmtFlush
 	case *CallProc:
 		return StmtCallProc
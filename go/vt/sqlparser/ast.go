@@ -427,6 +427,14 @@ type (
 		ForExport    bool
 	}
 
+	// FlushVitessPlans represents a FLUSH VITESS_PLANS statement, that
+	// removes plans from the plan cache of vtgate: the plans that use one of
+	// the tables, or whose query matches the pattern, or all of them.
+	FlushVitessPlans struct {
+		TableNames TableNames
+		Pattern    string
+	}
+
 	// RenameTablePair represents the name of the original table and what it is going to be set in a RENAME TABLE statement.
 	RenameTablePair struct {
 		FromTable TableName
@@ -731,6 +739,7 @@ func (*Delete) iStatement()              {}
 func (*Set) iStatement()                 {}
 func (*DropDatabase) iStatement()        {}
 func (*Flush) iStatement()               {}
+func (*FlushVitessPlans) iStatement()    {}
 func (*Show) iStatement()                {}
 func (*Use) iStatement()                 {}
 func (*Begin) iStatement()               {}
@@ -1993,6 +2002,7 @@ func (c Comments) Parsed() *ParsedComments {
 
 type ParsedComments struct {
 	comments    Comments
+	floating    []PositionedComment
 	_directives *CommentDirectives
 }
 
@@ -171,6 +171,8 @@ func CloneSQLNode(in SQLNode) SQLNode {
 		return CloneRefOfFirstOrLastValueExpr(in)
 	case *Flush:
 		return CloneRefOfFlush(in)
+	case *FlushVitessPlans:
+		return CloneRefOfFlushVitessPlans(in)
 	case *Force:
 		return CloneRefOfForce(in)
 	case *ForeignKeyDefinition:
@@ -1354,6 +1356,16 @@ func CloneRefOfFlush(n *Flush) *Flush {
 	return &out
 }
 
+// CloneRefOfFlushVitessPlans creates a deep clone of the input.
+func CloneRefOfFlushVitessPlans(n *FlushVitessPlans) *FlushVitessPlans {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.TableNames = CloneTableNames(n.TableNames)
+	return &out
+}
+
 // CloneRefOfForce creates a deep clone of the input.
 func CloneRefOfForce(n *Force) *Force {
 	if n == nil {
@@ -2390,6 +2402,7 @@ func CloneRefOfParsedComments(n *ParsedComments) *ParsedComments {
 	}
 	out := *n
 	out.comments = CloneComments(n.comments)
+	out.floating = CloneSliceOfPositionedComment(n.floating)
 	return &out
 }
 
@@ -4186,6 +4199,8 @@ func CloneStatement(in Statement) Statement {
 		return CloneRefOfExplainTab(in)
 	case *Flush:
 		return CloneRefOfFlush(in)
+	case *FlushVitessPlans:
+		return CloneRefOfFlushVitessPlans(in)
 	case *Insert:
 		return CloneRefOfInsert(in)
 	case *Kill:
@@ -4561,6 +4576,18 @@ func CloneComments(n Comments) Comments {
 	return res
 }
 
+// CloneSliceOfPositionedComment creates a deep clone of the input.
+func CloneSliceOfPositionedComment(n []PositionedComment) []PositionedComment {
+	if n == nil {
+		return nil
+	}
+	res := make([]PositionedComment, len(n))
+	for i, x := range n {
+		res[i] = ClonePositionedComment(x)
+	}
+	return res
+}
+
 // CloneSliceOfRefOfPartitionDefinition creates a deep clone of the input.
 func CloneSliceOfRefOfPartitionDefinition(n []*PartitionDefinition) []*PartitionDefinition {
 	if n == nil {
@@ -4721,6 +4748,11 @@ func CloneRefOfTableAndLockType(n *TableAndLockType) *TableAndLockType {
 	return &out
 }
 
+// ClonePositionedComment creates a deep clone of the input.
+func ClonePositionedComment(n PositionedComment) PositionedComment {
+	return *CloneRefOfPositionedComment(&n)
+}
+
 // CloneRefOfRenameTablePair creates a deep clone of the input.
 func CloneRefOfRenameTablePair(n *RenameTablePair) *RenameTablePair {
 	if n == nil {
@@ -4740,3 +4772,12 @@ func CloneRefOfDatabaseOption(n *DatabaseOption) *DatabaseOption {
 	out := *n
 	return &out
 }
+
+// CloneRefOfPositionedComment creates a deep clone of the input.
+func CloneRefOfPositionedComment(n *PositionedComment) *PositionedComment {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	return &out
+}
@@ -170,6 +170,8 @@ func (c *cow) copyOnRewriteSQLNode(n SQLNode, parent SQLNode) (out SQLNode, chan
 		return c.copyOnRewriteRefOfFirstOrLastValueExpr(n, parent)
 	case *Flush:
 		return c.copyOnRewriteRefOfFlush(n, parent)
+	case *FlushVitessPlans:
+		return c.copyOnRewriteRefOfFlushVitessPlans(n, parent)
 	case *Force:
 		return c.copyOnRewriteRefOfForce(n, parent)
 	case *ForeignKeyDefinition:
@@ -2241,6 +2243,28 @@ func (c *cow) copyOnRewriteRefOfFlush(n *Flush, parent SQLNode) (out SQLNode, ch
 	}
 	return
 }
+func (c *cow) copyOnRewriteRefOfFlushVitessPlans(n *FlushVitessPlans, parent SQLNode) (out SQLNode, changed bool) {
+	if n == nil || c.cursor.stop {
+		return n, false
+	}
+	out = n
+	if c.pre == nil || c.pre(n, parent) {
+		_TableNames, changedTableNames := c.copyOnRewriteTableNames(n.TableNames, n)
+		if changedTableNames {
+			res := *n
+			res.TableNames, _ = _TableNames.(TableNames)
+			out = &res
+			if c.cloned != nil {
+				c.cloned(n, out)
+			}
+			changed = true
+		}
+	}
+	if c.post != nil {
+		out, changed = c.postVisit(out, parent, changed)
+	}
+	return
+}
 func (c *cow) copyOnRewriteRefOfForce(n *Force, parent SQLNode) (out SQLNode, changed bool) {
 	if n == nil || c.cursor.stop {
 		return n, false
@@ -7395,6 +7419,8 @@ func (c *cow) copyOnRewriteStatement(n Statement, parent SQLNode) (out SQLNode,
 		return c.copyOnRewriteRefOfExplainTab(n, parent)
 	case *Flush:
 		return c.copyOnRewriteRefOfFlush(n, parent)
+	case *FlushVitessPlans:
+		return c.copyOnRewriteRefOfFlushVitessPlans(n, parent)
 	case *Insert:
 		return c.copyOnRewriteRefOfInsert(n, parent)
 	case *Kill:
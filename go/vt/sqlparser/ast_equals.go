@@ -470,6 +470,12 @@ func (cmp *Comparator) SQLNode(inA, inB SQLNode) bool {
 			return false
 		}
 		return cmp.RefOfFlush(a, b)
+	case *FlushVitessPlans:
+		b, ok := inB.(*FlushVitessPlans)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfFlushVitessPlans(a, b)
 	case *Force:
 		b, ok := inB.(*Force)
 		if !ok {
@@ -2571,6 +2577,18 @@ func (cmp *Comparator) RefOfFlush(a, b *Flush) bool {
 		cmp.TableNames(a.TableNames, b.TableNames)
 }
 
+// RefOfFlushVitessPlans does deep equals between the two objects.
+func (cmp *Comparator) RefOfFlushVitessPlans(a, b *FlushVitessPlans) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Pattern == b.Pattern &&
+		cmp.TableNames(a.TableNames, b.TableNames)
+}
+
 // RefOfForce does deep equals between the two objects.
 func (cmp *Comparator) RefOfForce(a, b *Force) bool {
 	if a == b {
@@ -3749,7 +3767,8 @@ func (cmp *Comparator) RefOfParsedComments(a, b *ParsedComments) bool {
 	if a == nil || b == nil {
 		return false
 	}
-	return cmp.Comments(a.comments, b.comments)
+	return cmp.Comments(a.comments, b.comments) &&
+		cmp.SliceOfPositionedComment(a.floating, b.floating)
 }
 
 // RefOfPartitionDefinition does deep equals between the two objects.
@@ -6885,6 +6904,12 @@ func (cmp *Comparator) Statement(inA, inB Statement) bool {
 			return false
 		}
 		return cmp.RefOfFlush(a, b)
+	case *FlushVitessPlans:
+		b, ok := inB.(*FlushVitessPlans)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfFlushVitessPlans(a, b)
 	case *Insert:
 		b, ok := inB.(*Insert)
 		if !ok {
@@ -7429,6 +7454,19 @@ func (cmp *Comparator) Comments(a, b Comments) bool {
 	return true
 }
 
+// SliceOfPositionedComment does deep equals between the two objects.
+func (cmp *Comparator) SliceOfPositionedComment(a, b []PositionedComment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if !cmp.PositionedComment(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 // SliceOfRefOfPartitionDefinition does deep equals between the two objects.
 func (cmp *Comparator) SliceOfRefOfPartitionDefinition(a, b []*PartitionDefinition) bool {
 	if len(a) != len(b) {
@@ -7615,6 +7653,12 @@ func (cmp *Comparator) RefOfTableAndLockType(a, b *TableAndLockType) bool {
 		a.Lock == b.Lock
 }
 
+// PositionedComment does deep equals between the two objects.
+func (cmp *Comparator) PositionedComment(a, b PositionedComment) bool {
+	return a.Pos == b.Pos &&
+		a.Comment == b.Comment
+}
+
 // RefOfRenameTablePair does deep equals between the two objects.
 func (cmp *Comparator) RefOfRenameTablePair(a, b *RenameTablePair) bool {
 	if a == b {
@@ -7640,6 +7684,18 @@ func (cmp *Comparator) RefOfDatabaseOption(a, b *DatabaseOption) bool {
 		a.Type == b.Type
 }
 
+// RefOfPositionedComment does deep equals between the two objects.
+func (cmp *Comparator) RefOfPositionedComment(a, b *PositionedComment) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Pos == b.Pos &&
+		a.Comment == b.Comment
+}
+
 type Comparator struct {
 	RefOfColName_ func(a, b *ColName) bool
 }
@@ -232,6 +232,18 @@ func (node *Flush) Format(buf *TrackedBuffer) {
 	}
 }
 
+// Format formats the node.
+func (node *FlushVitessPlans) Format(buf *TrackedBuffer) {
+	buf.literal("flush vitess_plans")
+	if len(node.TableNames) != 0 {
+		buf.astPrintf(node, " for table %v", node.TableNames)
+	}
+	if node.Pattern != "" {
+		buf.astPrintf(node, " like ")
+		sqltypes.BufEncodeStringSQL(buf.Builder, node.Pattern)
+	}
+}
+
 // Format formats the node.
 func (node *AlterVschema) Format(buf *TrackedBuffer) {
 	switch node.Action {
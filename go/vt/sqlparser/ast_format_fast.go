@@ -332,6 +332,19 @@ func (node *Flush) FormatFast(buf *TrackedBuffer) {
 	}
 }
 
+// FormatFast formats the node.
+func (node *FlushVitessPlans) FormatFast(buf *TrackedBuffer) {
+	buf.WriteString("flush vitess_plans")
+	if len(node.TableNames) != 0 {
+		buf.WriteString(" for table ")
+		node.TableNames.FormatFast(buf)
+	}
+	if node.Pattern != "" {
+		buf.WriteString(" like ")
+		sqltypes.BufEncodeStringSQL(buf.Builder, node.Pattern)
+	}
+}
+
 // FormatFast formats the node.
 func (node *AlterVschema) FormatFast(buf *TrackedBuffer) {
 	switch node.Action {
@@ -1980,14 +1980,20 @@ func (ty ShowCommandType) ToString() string {
 		return VGtidExecGlobalStr
 	case VitessMigrations:
 		return VitessMigrationsStr
+	case VitessPlans:
+		return VitessPlansStr
 	case VitessReplicationStatus:
 		return VitessReplicationStatusStr
 	case VitessShards:
 		return VitessShardsStr
+	case VitessTableStatus:
+		return VitessTableStatusStr
 	case VitessTablets:
 		return VitessTabletsStr
 	case VitessTarget:
 		return VitessTargetStr
+	case VitessTransactionStatus:
+		return VitessTransactionStatusStr
 	case VitessVariables:
 		return VitessVariablesStr
 	case VschemaTables:
@@ -170,6 +170,8 @@ func (a *application) rewriteSQLNode(parent SQLNode, node SQLNode, replacer repl
 		return a.rewriteRefOfFirstOrLastValueExpr(parent, node, replacer)
 	case *Flush:
 		return a.rewriteRefOfFlush(parent, node, replacer)
+	case *FlushVitessPlans:
+		return a.rewriteRefOfFlushVitessPlans(parent, node, replacer)
 	case *Force:
 		return a.rewriteRefOfForce(parent, node, replacer)
 	case *ForeignKeyDefinition:
@@ -2997,6 +2999,33 @@ func (a *application) rewriteRefOfFlush(parent SQLNode, node *Flush, replacer re
 	}
 	return true
 }
+func (a *application) rewriteRefOfFlushVitessPlans(parent SQLNode, node *FlushVitessPlans, replacer replacerFunc) bool {
+	if node == nil {
+		return true
+	}
+	if a.pre != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.pre(&a.cur) {
+			return true
+		}
+	}
+	if !a.rewriteTableNames(node, node.TableNames, func(newNode, parent SQLNode) {
+		parent.(*FlushVitessPlans).TableNames = newNode.(TableNames)
+	}) {
+		return false
+	}
+	if a.post != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.post(&a.cur) {
+			return false
+		}
+	}
+	return true
+}
 func (a *application) rewriteRefOfForce(parent SQLNode, node *Force, replacer replacerFunc) bool {
 	if node == nil {
 		return true
@@ -10356,6 +10385,8 @@ func (a *application) rewriteStatement(parent SQLNode, node Statement, replacer
 		return a.rewriteRefOfExplainTab(parent, node, replacer)
 	case *Flush:
 		return a.rewriteRefOfFlush(parent, node, replacer)
+	case *FlushVitessPlans:
+		return a.rewriteRefOfFlushVitessPlans(parent, node, replacer)
 	case *Insert:
 		return a.rewriteRefOfInsert(parent, node, replacer)
 	case *Kill:
@@ -384,9 +384,11 @@ func (er *astRewriter) sysVarRewrite(cursor *Cursor, node *Variable) {
 		sysvars.Charset.Name,
 		sysvars.ClientFoundRows.Name,
 		sysvars.DDLStrategy.Name,
+		sysvars.KeysetContinuationToken.Name,
 		sysvars.MigrationContext.Name,
 		sysvars.Names.Name,
 		sysvars.TransactionMode.Name,
+		sysvars.TransactionTag.Name,
 		sysvars.ReadAfterWriteGTID.Name,
 		sysvars.ReadAfterWriteTimeOut.Name,
 		sysvars.SessionEnableSystemSettings.Name,
@@ -170,6 +170,8 @@ func VisitSQLNode(in SQLNode, f Visit) error {
 		return VisitRefOfFirstOrLastValueExpr(in, f)
 	case *Flush:
 		return VisitRefOfFlush(in, f)
+	case *FlushVitessPlans:
+		return VisitRefOfFlushVitessPlans(in, f)
 	case *Force:
 		return VisitRefOfForce(in, f)
 	case *ForeignKeyDefinition:
@@ -1618,6 +1620,18 @@ func VisitRefOfFlush(in *Flush, f Visit) error {
 	}
 	return nil
 }
+func VisitRefOfFlushVitessPlans(in *FlushVitessPlans, f Visit) error {
+	if in == nil {
+		return nil
+	}
+	if cont, err := f(in); err != nil || !cont {
+		return err
+	}
+	if err := VisitTableNames(in.TableNames, f); err != nil {
+		return err
+	}
+	return nil
+}
 func VisitRefOfForce(in *Force, f Visit) error {
 	if in == nil {
 		return nil
@@ -5153,6 +5167,8 @@ func VisitStatement(in Statement, f Visit) error {
 		return VisitRefOfExplainTab(in, f)
 	case *Flush:
 		return VisitRefOfFlush(in, f)
+	case *FlushVitessPlans:
+		return VisitRefOfFlushVitessPlans(in, f)
 	case *Insert:
 		return VisitRefOfInsert(in, f)
 	case *Kill:
@@ -1383,6 +1383,25 @@ func (cached *Flush) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *FlushVitessPlans) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field TableNames vitess.io/vitess/go/vt/sqlparser.TableNames
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.TableNames)) * int64(32))
+		for _, elem := range cached.TableNames {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field Pattern string
+	size += hack.RuntimeAllocSize(int64(len(cached.Pattern)))
+	return size
+}
 func (cached *ForeignKeyDefinition) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -3059,7 +3078,7 @@ func (cached *ParsedComments) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(32)
+		size += int64(64)
 	}
 	// field comments vitess.io/vitess/go/vt/sqlparser.Comments
 	{
@@ -3068,6 +3087,13 @@ func (cached *ParsedComments) CachedSize(alloc bool) int64 {
 			size += hack.RuntimeAllocSize(int64(len(elem)))
 		}
 	}
+	// field floating []vitess.io/vitess/go/vt/sqlparser.PositionedComment
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.floating)) * int64(24))
+		for _, elem := range cached.floating {
+			size += elem.CachedSize(false)
+		}
+	}
 	// field _directives *vitess.io/vitess/go/vt/sqlparser.CommentDirectives
 	size += cached._directives.CachedSize(true)
 	return size
@@ -3323,6 +3349,18 @@ func (cached *PolygonPropertyFuncExpr) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *PositionedComment) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(24)
+	}
+	// field Comment string
+	size += hack.RuntimeAllocSize(int64(len(cached.Comment)))
+	return size
+}
 func (cached *PrepareStmt) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -18,8 +18,10 @@ package sqlparser
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -27,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 const (
@@ -58,17 +61,34 @@ const (
 	// DirectivePriority specifies the priority of a workload. It should be an integer between 0 and MaxPriorityValue,
 	// where 0 is the highest priority, and MaxPriorityValue is the lowest one.
 	DirectivePriority = "PRIORITY"
+	// DirectiveKeysetPaginate paginates a SELECT with keyset continuation tokens instead of OFFSET.
+	DirectiveKeysetPaginate = "KEYSET_PAGINATE"
+	// DirectiveKeysetToken is the continuation token of the page to read with KEYSET_PAGINATE.
+	DirectiveKeysetToken = "KEYSET_TOKEN"
+	// DirectiveTabletType routes a SELECT to the tablets of the given type, regardless of the session target.
+	DirectiveTabletType = "TABLET_TYPE"
+	// DirectiveMaxLag bounds the replication lag of the tablets of DirectiveTabletType, as a duration like 5s.
+	// The query is routed to the primary if no such tablet lags less than that.
+	DirectiveMaxLag = "MAX_LAG"
+	// DirectiveTransactionTag names the transactions of the session, so that they can be identified in the
+	// transaction logs of the tablets.
+	DirectiveTransactionTag = "TRANSACTION_TAG"
 
 	// MaxPriorityValue specifies the maximum value allowed for the priority query directive. Valid priority values are
 	// between zero and MaxPriorityValue.
 	MaxPriorityValue = 100
 
+	// MaxTransactionTagLength is the maximum length of a transaction tag.
+	MaxTransactionTagLength = 64
+
 	// OptimizerHintSetVar is the optimizer hint used in MySQL to set the value of a specific session variable for a query.
 	OptimizerHintSetVar = "SET_VAR"
 )
 
 var ErrInvalidPriority = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid priority value specified in query")
 
+var transactionTagRegexp = regexp.MustCompile(`^[\w.:-]*$`)
+
 func isNonSpace(r rune) bool {
 	return !unicode.IsSpace(r)
 }
@@ -238,8 +258,8 @@ func (c *ParsedComments) ResetDirectives() {
 	c._directives = nil
 }
 
-// Directives parses the comment list for any execution directives
-// of the form:
+// Directives parses the comment list, and the floating comments, for any
+// execution directives of the form:
 //
 //	/*vt+ OPTION_ONE=1 OPTION_TWO OPTION_THREE=abcd */
 //
@@ -252,23 +272,58 @@ func (c *ParsedComments) Directives() *CommentDirectives {
 		c._directives = &CommentDirectives{m: make(map[string]string)}
 
 		for _, commentStr := range c.comments {
-			if commentStr[0:5] != commentDirectivePreamble {
-				continue
-			}
+			c._directives.parse(commentStr)
+		}
+		for _, comment := range c.floating {
+			c._directives.parse(comment.Comment)
+		}
+	}
+	return c._directives
+}
 
-			// Split on whitespace and ignore the first and last directive
-			// since they contain the comment start/end
-			directives := strings.Fields(commentStr)
-			for i := 1; i < len(directives)-1; i++ {
-				directive, val, ok := strings.Cut(directives[i], "=")
-				if !ok {
-					val = "true"
-				}
-				c._directives.m[strings.ToLower(directive)] = val
+func (d *CommentDirectives) parse(commentStr string) {
+	if !strings.HasPrefix(commentStr, commentDirectivePreamble) {
+		return
+	}
+
+	// Split on whitespace and ignore the first and last directive
+	// since they contain the comment start/end
+	directives := strings.Fields(commentStr)
+	for i := 1; i < len(directives)-1; i++ {
+		directive, val, ok := strings.Cut(directives[i], "=")
+		if !ok {
+			val = "true"
+		}
+		d.m[strings.ToLower(directive)] = val
+	}
+}
+
+// RemoveDirective returns the comments without the given execution directive.
+// A directive comment left without any directive is removed altogether.
+func (c *ParsedComments) RemoveDirective(key string) Comments {
+	if c == nil {
+		return nil
+	}
+	var comments Comments
+	for _, commentStr := range c.comments {
+		directives := strings.Fields(commentStr)
+		if !strings.HasPrefix(commentStr, commentDirectivePreamble) || len(directives) < 3 {
+			comments = append(comments, commentStr)
+			continue
+		}
+		kept := directives[:1]
+		for i := 1; i < len(directives)-1; i++ {
+			directive, _, _ := strings.Cut(directives[i], "=")
+			if !strings.EqualFold(directive, key) {
+				kept = append(kept, directives[i])
 			}
 		}
+		if len(kept) == 1 {
+			continue
+		}
+		comments = append(comments, strings.Join(append(kept, directives[len(directives)-1]), " "))
 	}
-	return c._directives
+	return comments
 }
 
 // GetMySQLSetVarValue gets the value of the given variable if it is part of a /*+ SET_VAR() */ MySQL optimizer hint.
@@ -495,6 +550,99 @@ func (c *ParsedComments) Prepend(comment string) Comments {
 	return comments
 }
 
+// PositionedComment is a comment of a query, with its offset in the query.
+type PositionedComment struct {
+	Pos     int
+	Comment string
+}
+
+// Floating returns the comments of the statement that the grammar doesn't
+// keep in the AST, like the comments between its clauses or after it, in the
+// order of the query. They are not formatted back, so that they are never
+// relocated, but they are kept through the rewrites of the statement and
+// their directives are read like the ones of the leading comments.
+func (c *ParsedComments) Floating() []PositionedComment {
+	if c == nil {
+		return nil
+	}
+	return c.floating
+}
+
+// attachFloatingComments keeps the floating comments of the statement that
+// was just parsed in its comments. The comments of a UNION are kept by its
+// first SELECT, like its leading comments.
+func (tkn *Tokenizer) attachFloatingComments() {
+	if len(tkn.floating) == 0 {
+		return
+	}
+	floating := tkn.floating
+	tkn.floating = nil
+
+	stmt := tkn.ParseTree
+	for {
+		union, ok := stmt.(*Union)
+		if !ok {
+			break
+		}
+		stmt = union.Left
+	}
+	cmt, ok := stmt.(Commented)
+	if !ok {
+		return
+	}
+	if parsed := cmt.GetParsedComments(); parsed != nil {
+		parsed.floating = append(parsed.floating, floating...)
+		return
+	}
+	parsed := &ParsedComments{floating: floating}
+	switch node := stmt.(type) {
+	case *Select:
+		node.Comments = parsed
+	case *Insert:
+		node.Comments = parsed
+	case *Update:
+		node.Comments = parsed
+	case *Delete:
+		node.Comments = parsed
+	}
+}
+
+// QueryDirectives returns the execution directives of all the comments of
+// the node, wherever they are in the query. A directive set more than once
+// keeps its first value, so the directives of the node itself win over the
+// ones of the statements it contains.
+func QueryDirectives(node SQLNode) *CommentDirectives {
+	directives := &CommentDirectives{m: make(map[string]string)}
+	for _, parsed := range allParsedComments(node) {
+		for key, val := range parsed.Directives().m {
+			if _, ok := directives.m[key]; !ok {
+				directives.m[key] = val
+			}
+		}
+	}
+	return directives
+}
+
+// allParsedComments returns the comments of the node and of all the nodes
+// it contains, the ones of the node itself first.
+func allParsedComments(node SQLNode) []*ParsedComments {
+	var own *ParsedComments
+	if cmt, ok := node.(Commented); ok {
+		own = cmt.GetParsedComments()
+	}
+	var all []*ParsedComments
+	if own != nil {
+		all = append(all, own)
+	}
+	_ = Walk(func(node SQLNode) (bool, error) {
+		if parsed, ok := node.(*ParsedComments); ok && parsed != own {
+			all = append(all, parsed)
+		}
+		return true, nil
+	}, node)
+	return all
+}
+
 // IsSet checks the directive map for the named directive and returns
 // true if the directive is set and has a true/false or 0/1 value
 func (d *CommentDirectives) IsSet(key string) bool {
@@ -576,9 +724,8 @@ func ForeignKeyChecksState(stmt Statement) *bool {
 }
 
 func checkDirective(stmt Statement, key string) bool {
-	cmt, ok := stmt.(Commented)
-	if ok {
-		return cmt.GetParsedComments().Directives().IsSet(key)
+	if _, ok := stmt.(Commented); ok {
+		return QueryDirectives(stmt).IsSet(key)
 	}
 	return false
 }
@@ -629,6 +776,61 @@ func Consolidator(stmt Statement) querypb.ExecuteOptions_Consolidator {
 	return querypb.ExecuteOptions_CONSOLIDATOR_UNSPECIFIED
 }
 
+// GetTabletTypeFromStatement returns the tablet type and the replication lag bound a SELECT is routed with,
+// from its TABLET_TYPE and MAX_LAG directives. The tablet type is UNKNOWN if the query is not routed by them.
+func GetTabletTypeFromStatement(stmt Statement) (topodatapb.TabletType, time.Duration, error) {
+	switch stmt.(type) {
+	case *Select, *Union:
+	default:
+		return topodatapb.TabletType_UNKNOWN, 0, nil
+	}
+	directives := stmt.(Commented).GetParsedComments().Directives()
+	tabletTypeStr, ok := directives.GetString(DirectiveTabletType, "")
+	if !ok || tabletTypeStr == "" {
+		return topodatapb.TabletType_UNKNOWN, 0, nil
+	}
+	tabletType := topodatapb.TabletType(topodatapb.TabletType_value[strings.ToUpper(tabletTypeStr)])
+	switch tabletType {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+	default:
+		return topodatapb.TabletType_UNKNOWN, 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s value %q, expected primary, replica or rdonly", DirectiveTabletType, tabletTypeStr)
+	}
+
+	maxLagStr, ok := directives.GetString(DirectiveMaxLag, "")
+	if !ok || maxLagStr == "" {
+		return tabletType, 0, nil
+	}
+	maxLag, err := time.ParseDuration(maxLagStr)
+	if err != nil || maxLag <= 0 {
+		return topodatapb.TabletType_UNKNOWN, 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s value %q, expected a positive duration", DirectiveMaxLag, maxLagStr)
+	}
+	return tabletType, maxLag, nil
+}
+
+// GetTransactionTagFromStatement gets the transaction tag from the TRANSACTION_TAG directive of the statement, or
+// an empty string if it has none.
+func GetTransactionTagFromStatement(statement Statement) (string, error) {
+	commentedStatement, ok := statement.(Commented)
+	if !ok {
+		return "", nil
+	}
+
+	tag, _ := commentedStatement.GetParsedComments().Directives().GetString(DirectiveTransactionTag, "")
+	if err := ValidateTransactionTag(tag); err != nil {
+		return "", err
+	}
+	return tag, nil
+}
+
+// ValidateTransactionTag validates that the transaction tag only uses the characters that are safe to log, and is
+// at most MaxTransactionTagLength long.
+func ValidateTransactionTag(tag string) error {
+	if len(tag) > MaxTransactionTagLength || !transactionTagRegexp.MatchString(tag) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid transaction tag %q: use at most %d alphanumeric, dot, dash, underscore and colon characters", tag, MaxTransactionTagLength)
+	}
+	return nil
+}
+
 // GetWorkloadNameFromStatement gets the workload name from the provided Statement, using workloadLabel as the name of
 // the query directive that specifies it.
 func GetWorkloadNameFromStatement(statement Statement) string {
@@ -18,7 +18,9 @@ package sqlparser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,6 +28,7 @@ import (
 	"vitess.io/vitess/go/vt/sysvars"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 func TestSplitComments(t *testing.T) {
@@ -437,6 +440,8 @@ func TestIgnoreMaxMaxMemoryRowsDirective(t *testing.T) {
 		{"update users set name=1", false},
 		{"select /*vt+ IGNORE_MAX_MEMORY_ROWS=1 */ * from users", true},
 		{"select * from users", false},
+		{"select * from users /*vt+ IGNORE_MAX_MEMORY_ROWS=1 */", true},
+		{"select * from users where id in (select /*vt+ IGNORE_MAX_MEMORY_ROWS=1 */ id from t)", true},
 		{"delete /*vt+ IGNORE_MAX_MEMORY_ROWS=1 */ from users", true},
 		{"delete from users", false},
 		{"show /*vt+ IGNORE_MAX_MEMORY_ROWS=1 */ create table users", false},
@@ -545,6 +550,108 @@ func TestGetPriorityFromStatement(t *testing.T) {
 	}
 }
 
+func TestGetTabletTypeFromStatement(t *testing.T) {
+	testCases := []struct {
+		query              string
+		expectedTabletType topodatapb.TabletType
+		expectedMaxLag     time.Duration
+		expectedError      string
+	}{
+		{
+			query:              "select * from users",
+			expectedTabletType: topodatapb.TabletType_UNKNOWN,
+		},
+		{
+			query:              "select /*vt+ TABLET_TYPE=replica */ * from users",
+			expectedTabletType: topodatapb.TabletType_REPLICA,
+		},
+		{
+			query:              "select /*vt+ TABLET_TYPE=rdonly MAX_LAG=5s */ * from users union select * from admins",
+			expectedTabletType: topodatapb.TabletType_RDONLY,
+			expectedMaxLag:     5 * time.Second,
+		},
+		{
+			query:              "select /*vt+ TABLET_TYPE=PRIMARY */ * from users",
+			expectedTabletType: topodatapb.TabletType_PRIMARY,
+		},
+		{
+			query:              "select /*vt+ MAX_LAG=5s */ * from users",
+			expectedTabletType: topodatapb.TabletType_UNKNOWN,
+		},
+		{
+			query:              "update /*vt+ TABLET_TYPE=replica */ users set name=1",
+			expectedTabletType: topodatapb.TabletType_UNKNOWN,
+		},
+		{
+			query:         "select /*vt+ TABLET_TYPE=spare */ * from users",
+			expectedError: `invalid TABLET_TYPE value "spare", expected primary, replica or rdonly`,
+		},
+		{
+			query:         "select /*vt+ TABLET_TYPE=replica MAX_LAG=5 */ * from users",
+			expectedError: `invalid MAX_LAG value "5", expected a positive duration`,
+		},
+	}
+
+	parser := NewTestParser()
+	for _, testCase := range testCases {
+		t.Run(testCase.query, func(t *testing.T) {
+			stmt, err := parser.Parse(testCase.query)
+			require.NoError(t, err)
+			tabletType, maxLag, err := GetTabletTypeFromStatement(stmt)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedTabletType, tabletType)
+			assert.Equal(t, testCase.expectedMaxLag, maxLag)
+		})
+	}
+}
+
+func TestGetTransactionTagFromStatement(t *testing.T) {
+	testCases := []struct {
+		query         string
+		expectedTag   string
+		expectedError string
+	}{
+		{
+			query: "select * from users",
+		},
+		{
+			query:       "select /*vt+ TRANSACTION_TAG=checkout.payment */ * from users",
+			expectedTag: "checkout.payment",
+		},
+		{
+			query:       "update /*vt+ TRANSACTION_TAG=billing:invoice-42 */ users set name = 1",
+			expectedTag: "billing:invoice-42",
+		},
+		{
+			query:         "insert /*vt+ TRANSACTION_TAG=a;b */ into users(id) values (1)",
+			expectedError: `invalid transaction tag "a;b": use at most 64 alphanumeric, dot, dash, underscore and colon characters`,
+		},
+		{
+			query:         "select /*vt+ TRANSACTION_TAG=" + strings.Repeat("a", 65) + " */ * from users",
+			expectedError: `invalid transaction tag "` + strings.Repeat("a", 65) + `": use at most 64 alphanumeric, dot, dash, underscore and colon characters`,
+		},
+	}
+
+	parser := NewTestParser()
+	for _, testCase := range testCases {
+		t.Run(testCase.query, func(t *testing.T) {
+			stmt, err := parser.Parse(testCase.query)
+			require.NoError(t, err)
+			tag, err := GetTransactionTagFromStatement(stmt)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedTag, tag)
+		})
+	}
+}
+
 // TestGetMySQLSetVarValue tests the functionality of GetMySQLSetVarValue
 func TestGetMySQLSetVarValue(t *testing.T) {
 	tests := []struct {
@@ -661,3 +768,130 @@ func TestSetMySQLSetVarValue(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveDirective(t *testing.T) {
+	tests := []struct {
+		name           string
+		comments       []string
+		commentsWanted Comments
+	}{
+		{
+			name:           "Only directive",
+			comments:       []string{"/*vt+ KEYSET_TOKEN=abc */"},
+			commentsWanted: nil,
+		},
+		{
+			name:           "Other directives are kept",
+			comments:       []string{"/*vt+ KEYSET_PAGINATE keyset_token=abc ALLOW_SCATTER */"},
+			commentsWanted: []string{"/*vt+ KEYSET_PAGINATE ALLOW_SCATTER */"},
+		},
+		{
+			name:           "Other comments are kept",
+			comments:       []string{"/* KEYSET_TOKEN=abc */", "/*vt+ KEYSET_TOKEN=abc */", "/*+ SET_VAR(sort_buffer_size = 16M) */"},
+			commentsWanted: []string{"/* KEYSET_TOKEN=abc */", "/*+ SET_VAR(sort_buffer_size = 16M) */"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ParsedComments{
+				comments: tt.comments,
+			}
+			require.EqualValues(t, tt.commentsWanted, c.RemoveDirective(DirectiveKeysetToken))
+		})
+	}
+}
+
+func TestFloatingComments(t *testing.T) {
+	parser := NewTestParser()
+	testCases := []struct {
+		query    string
+		floating []PositionedComment
+		out      string
+	}{{
+		query:    "select /* leading */ a from t /* floating */ where b = 1 /* trailing */",
+		floating: []PositionedComment{{Pos: 30, Comment: "/* floating */"}, {Pos: 57, Comment: "/* trailing */"}},
+		out:      "select /* leading */ a from t where b = 1",
+	}, {
+		query:    "select a from t -- trailing",
+		floating: []PositionedComment{{Pos: 16, Comment: "-- trailing"}},
+		out:      "select a from t",
+	}, {
+		query:    "select a from t1 union /* floating */ select b from t2",
+		floating: []PositionedComment{{Pos: 23, Comment: "/* floating */"}},
+		out:      "select a from t1 union select b from t2",
+	}, {
+		query:    "update t set a = 1 /* floating */ where b = 2",
+		floating: []PositionedComment{{Pos: 19, Comment: "/* floating */"}},
+		out:      "update t set a = 1 where b = 2",
+	}, {
+		query: "select a from t",
+		out:   "select a from t",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tc.query)
+			require.NoError(t, err)
+			// The floating comments are not formatted back.
+			assert.Equal(t, tc.out, String(stmt))
+
+			if union, ok := stmt.(*Union); ok {
+				stmt = union.Left.(*Select)
+			}
+			floating := stmt.(Commented).GetParsedComments().Floating()
+			assert.Equal(t, tc.floating, floating)
+			for _, c := range floating {
+				assert.Equal(t, c.Comment, tc.query[c.Pos:c.Pos+len(c.Comment)])
+			}
+
+			// They are kept through clones and rewrites.
+			clone := CloneStatement(stmt)
+			assert.True(t, Equals.SQLNode(stmt, clone))
+			assert.Equal(t, tc.floating, clone.(Commented).GetParsedComments().Floating())
+		})
+	}
+
+	// Every statement of a multi statement query keeps its own comments.
+	stmts, err := parser.SplitStatements("select 1 from t /* one */; select 2 from t /* two */")
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	assert.Equal(t, "/* one */", stmts[0].(*Select).Comments.Floating()[0].Comment)
+	assert.Equal(t, "/* two */", stmts[1].(*Select).Comments.Floating()[0].Comment)
+}
+
+func TestQueryDirectives(t *testing.T) {
+	parser := NewTestParser()
+	testCases := []struct {
+		query      string
+		directives map[string]string
+	}{{
+		query:      "select /*vt+ QUERY_TIMEOUT_MS=100 */ a from t",
+		directives: map[string]string{"query_timeout_ms": "100"},
+	}, {
+		query:      "select a from (select /*vt+ QUERY_TIMEOUT_MS=100 */ b from t) as dt",
+		directives: map[string]string{"query_timeout_ms": "100"},
+	}, {
+		query:      "select a from t where b in (select /*+ NO_ICP(t2) */ b from t2) /*vt+ SCATTER_ERRORS_AS_WARNINGS */",
+		directives: map[string]string{"scatter_errors_as_warnings": "true"},
+	}, {
+		query:      "select /*vt+ QUERY_TIMEOUT_MS=100 */ a from (select /*vt+ QUERY_TIMEOUT_MS=200 */ b from t) as dt",
+		directives: map[string]string{"query_timeout_ms": "100"},
+	}, {
+		query:      "with cte as (select /*+ BKA(t) */ a from t) select a from cte union select /*vt+ PLANNER=gen4 */ b from t2",
+		directives: map[string]string{"planner": "gen4"},
+	}, {
+		query:      "select a from t",
+		directives: map[string]string{},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.directives, QueryDirectives(stmt).m)
+
+			// The comments survive the normalization of the query.
+			result, err := PrepareAST(stmt, NewReservedVars("vtg", nil), map[string]*querypb.BindVariable{}, false, "ks", 0, "", map[string]string{}, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.directives, QueryDirectives(result.AST).m)
+		})
+	}
+}
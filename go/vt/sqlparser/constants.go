@@ -311,10 +311,13 @@ const (
 	VGtidExecGlobalStr         = " global vgtid_executed"
 	KeyspaceStr                = " keyspaces"
 	VitessMigrationsStr        = " vitess_migrations"
+	VitessPlansStr             = " vitess_plans"
 	VitessReplicationStatusStr = " vitess_replication_status"
 	VitessShardsStr            = " vitess_shards"
+	VitessTableStatusStr       = " vitess_table_status"
 	VitessTabletsStr           = " vitess_tablets"
 	VitessTargetStr            = " vitess_target"
+	VitessTransactionStatusStr = " vitess_transaction status"
 	VitessVariablesStr         = " vitess_metadata variables"
 	VschemaTablesStr           = " vschema tables"
 	VschemaKeyspacesStr        = " vschema keyspaces"
@@ -889,10 +892,13 @@ const (
 	VariableSession
 	VGtidExecGlobal
 	VitessMigrations
+	VitessPlans
 	VitessReplicationStatus
 	VitessShards
+	VitessTableStatus
 	VitessTablets
 	VitessTarget
+	VitessTransactionStatus
 	VitessVariables
 	VschemaTables
 	VschemaKeyspaces
@@ -756,12 +756,15 @@ var keywords = []keyword{
 	{"vitess_metadata", VITESS_METADATA},
 	{"vitess_migration", VITESS_MIGRATION},
 	{"vitess_migrations", VITESS_MIGRATIONS},
+	{"vitess_plans", VITESS_PLANS},
 	{"vitess_replication_status", VITESS_REPLICATION_STATUS},
 	{"vitess_shards", VITESS_SHARDS},
+	{"vitess_table_status", VITESS_TABLE_STATUS},
 	{"vitess_tablets", VITESS_TABLETS},
 	{"vitess_target", VITESS_TARGET},
 	{"vitess_throttled_apps", VITESS_THROTTLED_APPS},
 	{"vitess_throttler", VITESS_THROTTLER},
+	{"vitess_transaction", VITESS_TRANSACTION},
 	{"vschema", VSCHEMA},
 	{"vstream", VSTREAM},
 	{"vtexplain", VTEXPLAIN},
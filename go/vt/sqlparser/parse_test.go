@@ -2173,6 +2173,12 @@ var (
 	}, {
 		input:  "flush no_write_to_binlog slow logs, status, user_resources, relay logs, relay logs for channel s",
 		output: "flush local slow logs, status, user_resources, relay logs, relay logs for channel s",
+	}, {
+		input: "flush vitess_plans",
+	}, {
+		input: "flush vitess_plans for table t1, ks.t2",
+	}, {
+		input: "flush vitess_plans like 'select % from t1%'",
 	}, {
 		input: "show binary logs",
 	}, {
@@ -2394,6 +2400,15 @@ var (
 		input: "show vitess_shards",
 	}, {
 		input: "show vitess_shards like '%'",
+	}, {
+		input:  "show vitess_table_status t1",
+		output: "show vitess_table_status from t1",
+	}, {
+		input: "show vitess_table_status from ks.t1",
+	}, {
+		input: "show vitess_plans",
+	}, {
+		input: "show vitess_plans like '%t1%'",
 	}, {
 		input: "show vitess_tablets",
 	}, {
@@ -2402,6 +2417,8 @@ var (
 		input: "show vitess_tablets where hostname = 'some-tablet'",
 	}, {
 		input: "show vitess_targets",
+	}, {
+		input: "show vitess_transaction status",
 	}, {
 		input: "show vschema tables",
 	}, {
@@ -97,6 +97,7 @@ func (p *Parser) Parse2(sql string) (Statement, BindVars, error) {
 	if tokenizer.ParseTree == nil {
 		return nil, nil, ErrEmpty
 	}
+	tokenizer.attachFloatingComments()
 	return tokenizer.ParseTree, tokenizer.BindVars, nil
 }
 
@@ -164,6 +165,7 @@ func (p *Parser) ParseStrictDDL(sql string) (Statement, error) {
 	if tokenizer.ParseTree == nil {
 		return nil, ErrEmpty
 	}
+	tokenizer.attachFloatingComments()
 	return tokenizer.ParseTree, nil
 }
 
@@ -204,6 +206,7 @@ func parseNext(tokenizer *Tokenizer, strict bool) (Statement, error) {
 	if tokenizer.ParseTree == nil || isCommentOnly {
 		return ParseNext(tokenizer)
 	}
+	tokenizer.attachFloatingComments()
 	return tokenizer.ParseTree, nil
 }
 
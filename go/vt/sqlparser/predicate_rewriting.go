@@ -211,11 +211,23 @@ func simplifyAnd(expr *AndExpr) (Expr, bool) {
 // match for all sides of each comparison.
 // This rewriter takes a query that looks like this WHERE a = 1 and b = 11 or a = 2 and b = 12 or a = 3 and b = 13
 // And rewrite that to WHERE (a, b) IN ((1,11), (2,12), (3,13))
+// Sides that already are tuple comparisons over the same columns, such as
+// (a, b) IN ((1,11), (2,12)) OR a = 3 and b = 13, are merged into the same IN clause.
 func ExtractINFromOR(expr *OrExpr) []Expr {
 	var varNames []*ColName
 	var values []Exprs
 	orSlice := orToSlice(expr)
 	for _, expr := range orSlice {
+		if tupleNames, tupleValues, ok := tupleInComparison(expr); ok {
+			if len(varNames) == 0 {
+				varNames = tupleNames
+			} else if !slices.EqualFunc(varNames, tupleNames, func(col1, col2 *ColName) bool { return col1.Equal(col2) }) {
+				return nil
+			}
+			values = append(values, tupleValues...)
+			continue
+		}
+
 		andSlice := andToSlice(expr)
 		if len(andSlice) == 0 {
 			return nil
@@ -275,6 +287,41 @@ func ExtractINFromOR(expr *OrExpr) []Expr {
 	}}
 }
 
+// tupleInComparison returns the columns and the rows of a `(a, b) IN ((1, 2), (3, 4))` expression.
+func tupleInComparison(expr Expr) ([]*ColName, []Exprs, bool) {
+	cmp, ok := expr.(*ComparisonExpr)
+	if !ok || cmp.Operator != InOp {
+		return nil, nil, false
+	}
+	left, ok := cmp.Left.(ValTuple)
+	if !ok {
+		return nil, nil, false
+	}
+	right, ok := cmp.Right.(ValTuple)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var names []*ColName
+	for _, e := range left {
+		col, ok := e.(*ColName)
+		if !ok {
+			return nil, nil, false
+		}
+		names = append(names, col)
+	}
+
+	var rows []Exprs
+	for _, e := range right {
+		row, ok := e.(ValTuple)
+		if !ok || len(row) != len(names) {
+			return nil, nil, false
+		}
+		rows = append(rows, Exprs(row))
+	}
+	return names, rows, true
+}
+
 func orToSlice(expr *OrExpr) []Expr {
 	var exprs []Expr
 
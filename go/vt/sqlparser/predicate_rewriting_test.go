@@ -180,6 +180,18 @@ func TestExtractINFromOR(in *testing.T) {
 	}, {
 		in:       "a = 1 or a = 2 or a = 3 or a = 4 or a = 5 or a = 6",
 		expected: "(a) in ((1), (2), (3), (4), (5), (6))",
+	}, {
+		in:       "(a, b) in ((1, 41), (2, 42)) or (a, b) in ((3, 43))",
+		expected: "(a, b) in ((1, 41), (2, 42), (3, 43))",
+	}, {
+		in:       "(a, b) in ((1, 41), (2, 42)) or a = 3 and b = 43",
+		expected: "(a, b) in ((1, 41), (2, 42), (3, 43))",
+	}, {
+		in:       "(a, b) in ((1, 41), (2, 42)) or (b, a) in ((43, 3))",
+		expected: "<nil>",
+	}, {
+		in:       "(a, b) in ((1, 41), (2, 42)) or a in (3, 4)",
+		expected: "<nil>",
 	}}
 
 	parser := NewTestParser()
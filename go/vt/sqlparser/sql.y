@@ -343,7 +343,7 @@ func markBindVariable(yylex yyLexer, bvar string) {
 // SHOW tokens
 %token <str> CODE COLLATION COLUMNS DATABASES ENGINES EVENT EXTENDED FIELDS FULL FUNCTION GTID_EXECUTED
 %token <str> KEYSPACES OPEN PLUGINS PRIVILEGES PROCESSLIST SCHEMAS TABLES TRIGGERS USER
-%token <str> VGTID_EXECUTED VITESS_KEYSPACES VITESS_METADATA VITESS_MIGRATIONS VITESS_REPLICATION_STATUS VITESS_SHARDS VITESS_TABLETS VITESS_TARGET VSCHEMA VITESS_THROTTLED_APPS
+%token <str> VGTID_EXECUTED VITESS_KEYSPACES VITESS_METADATA VITESS_MIGRATIONS VITESS_PLANS VITESS_REPLICATION_STATUS VITESS_SHARDS VITESS_TABLE_STATUS VITESS_TABLETS VITESS_TARGET VITESS_TRANSACTION VSCHEMA VITESS_THROTTLED_APPS
 
 // SET tokens
 %token <str> NAMES GLOBAL SESSION ISOLATION LEVEL READ WRITE ONLY REPEATABLE COMMITTED UNCOMMITTED SERIALIZABLE
@@ -4132,6 +4132,10 @@ show_statement:
   {
     $$ = &ShowThrottledApps{}
   }
+| SHOW VITESS_PLANS like_opt
+  {
+    $$ = &Show{&ShowBasic{Command: VitessPlans, Filter: $3}}
+  }
 | SHOW VITESS_REPLICATION_STATUS like_opt
   {
     $$ = &Show{&ShowBasic{Command: VitessReplicationStatus, Filter: $3}}
@@ -4164,6 +4168,14 @@ show_statement:
   {
     $$ = &Show{&ShowBasic{Command: VitessShards, Filter: $3}}
   }
+| SHOW VITESS_TABLE_STATUS table_name
+  {
+    $$ = &Show{&ShowBasic{Command: VitessTableStatus, Tbl: $3}}
+  }
+| SHOW VITESS_TABLE_STATUS from_or_on table_name
+  {
+    $$ = &Show{&ShowBasic{Command: VitessTableStatus, Tbl: $4}}
+  }
 | SHOW VITESS_TABLETS like_or_where_opt
   {
     $$ = &Show{&ShowBasic{Command: VitessTablets, Filter: $3}}
@@ -4172,6 +4184,10 @@ show_statement:
   {
     $$ = &Show{&ShowBasic{Command: VitessTarget}}
   }
+| SHOW VITESS_TRANSACTION STATUS
+  {
+    $$ = &Show{&ShowBasic{Command: VitessTransactionStatus}}
+  }
 /*
  * Catch-all for show statements without vitess keywords:
  */
@@ -4606,6 +4622,18 @@ flush_statement:
   {
     $$ = &Flush{IsLocal: $2, TableNames:$4, ForExport:true}
   }
+| FLUSH VITESS_PLANS
+  {
+    $$ = &FlushVitessPlans{}
+  }
+| FLUSH VITESS_PLANS FOR TABLE table_name_list
+  {
+    $$ = &FlushVitessPlans{TableNames: $5}
+  }
+| FLUSH VITESS_PLANS LIKE STRING
+  {
+    $$ = &FlushVitessPlans{Pattern: string($4)}
+  }
 
 flush_option_list:
   flush_option
@@ -8566,12 +8594,15 @@ non_reserved_keyword:
 | VITESS_METADATA
 | VITESS_MIGRATION
 | VITESS_MIGRATIONS
+| VITESS_PLANS
 | VITESS_REPLICATION_STATUS
 | VITESS_SHARDS
+| VITESS_TABLE_STATUS
 | VITESS_TABLETS
 | VITESS_TARGET
 | VITESS_THROTTLED_APPS
 | VITESS_THROTTLER
+| VITESS_TRANSACTION
 | VSCHEMA
 | VTEXPLAIN
 | WAIT_FOR_EXECUTED_GTID_SET %prec FUNCTION_CALL_NON_KEYWORD
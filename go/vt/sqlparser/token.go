@@ -38,8 +38,10 @@ type Tokenizer struct {
 	ParseTree           Statement
 	BindVars            map[string]struct{}
 
-	lastToken      string
-	posVarIndex    int
+	lastToken   string
+	posVarIndex int
+	// floating are the comments that the grammar doesn't keep in the AST.
+	floating       []PositionedComment
 	partialDDL     Statement
 	multi          bool
 	specialComment *Tokenizer
@@ -71,6 +73,9 @@ func (tkn *Tokenizer) Lex(lval *yySymType) int {
 		if tkn.AllowComments {
 			break
 		}
+		if tkn.specialComment == nil {
+			tkn.floating = append(tkn.floating, PositionedComment{Pos: tkn.Pos - len(val), Comment: val})
+		}
 		typ, val = tkn.Scan()
 	}
 	if typ == 0 || typ == ';' || typ == LEX_ERROR {
@@ -708,6 +713,7 @@ func (tkn *Tokenizer) reset() {
 	tkn.ParseTree = nil
 	tkn.partialDDL = nil
 	tkn.specialComment = nil
+	tkn.floating = nil
 	tkn.posVarIndex = 0
 	tkn.SkipToEnd = false
 }
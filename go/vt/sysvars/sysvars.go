@@ -63,13 +63,17 @@ var (
 	Charset                     = SystemVariable{Name: "charset", Default: utf8mb4, IdentifierAsString: true}
 	ClientFoundRows             = SystemVariable{Name: "client_found_rows", IsBoolean: true, Default: off}
 	SessionEnableSystemSettings = SystemVariable{Name: "enable_system_settings", IsBoolean: true, Default: on}
+	KeysetContinuationToken     = SystemVariable{Name: "keyset_continuation_token", IdentifierAsString: true}
 	Names                       = SystemVariable{Name: "names", Default: utf8mb4, IdentifierAsString: true}
 	SessionUUID                 = SystemVariable{Name: "session_uuid", IdentifierAsString: true}
 	SkipQueryPlanCache          = SystemVariable{Name: "skip_query_plan_cache", IsBoolean: true, Default: off}
 	Socket                      = SystemVariable{Name: "socket", Default: off}
 	SQLSelectLimit              = SystemVariable{Name: "sql_select_limit", Default: off, SupportSetVar: true}
+	TransactionIsolation        = SystemVariable{Name: "transaction_isolation", Case: SCUpper}
 	TransactionMode             = SystemVariable{Name: "transaction_mode", IdentifierAsString: true}
 	TransactionReadOnly         = SystemVariable{Name: "transaction_read_only", IsBoolean: true, Default: off}
+	TransactionTag              = SystemVariable{Name: "transaction_tag", IdentifierAsString: true}
+	TxIsolation                 = SystemVariable{Name: "tx_isolation", Case: SCUpper}
 	TxReadOnly                  = SystemVariable{Name: "tx_read_only", IsBoolean: true, Default: off}
 	Workload                    = SystemVariable{Name: "workload", IdentifierAsString: true}
 	QueryTimeout                = SystemVariable{Name: "query_timeout"}
@@ -87,6 +91,10 @@ var (
 	ReadAfterWriteTimeOut = SystemVariable{Name: "read_after_write_timeout"}
 	SessionTrackGTIDs     = SystemVariable{Name: "session_track_gtids", IdentifierAsString: true}
 
+	// Session state tracking settings
+	SessionTrackStateChange     = SystemVariable{Name: "session_track_state_change", IsBoolean: true, Default: off}
+	SessionTrackSystemVariables = SystemVariable{Name: "session_track_system_variables", IdentifierAsString: true}
+
 	VitessAware = []SystemVariable{
 		Autocommit,
 		ClientFoundRows,
@@ -95,6 +103,7 @@ var (
 		TransactionReadOnly,
 		SQLSelectLimit,
 		TransactionMode,
+		TransactionTag,
 		DDLStrategy,
 		Workload,
 		Charset,
@@ -105,10 +114,13 @@ var (
 		ReadAfterWriteGTID,
 		ReadAfterWriteTimeOut,
 		SessionTrackGTIDs,
+		SessionTrackStateChange,
+		SessionTrackSystemVariables,
 		QueryTimeout,
 	}
 
 	ReadOnly = []SystemVariable{
+		KeysetContinuationToken,
 		Socket,
 		Version,
 		VersionComment,
@@ -223,9 +235,9 @@ var (
 		{Name: "sql_warnings", IsBoolean: true},
 		{Name: "time_zone"},
 		{Name: "tmp_table_size", SupportSetVar: true},
-		{Name: "transaction_isolation", Case: SCUpper},
+		TransactionIsolation,
 		{Name: "transaction_prealloc_size"},
-		{Name: "tx_isolation", Case: SCUpper},
+		TxIsolation,
 		{Name: "unique_checks", IsBoolean: true, SupportSetVar: true},
 		{Name: "updatable_views_with_limit", IsBoolean: true, SupportSetVar: true},
 	}
@@ -261,8 +273,6 @@ var (
 		{Name: "net_retry_count"},
 		{Name: "net_write_timeout"},
 		{Name: "session_track_schema", IsBoolean: true},
-		{Name: "session_track_state_change", IsBoolean: true},
-		{Name: "session_track_system_variables"},
 		{Name: "session_track_transaction_info"},
 		{Name: "sql_auto_is_null", IsBoolean: true, SupportSetVar: true},
 		{Name: "version_tokens_session"},
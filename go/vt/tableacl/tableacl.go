@@ -81,6 +81,10 @@ type tableACL struct {
 // currentTableACL stores current effective ACL information.
 var currentTableACL tableACL
 
+// dryRunTableACL stores the ACL information that is evaluated in dry run mode,
+// to find out which queries a new config would deny before enforcing it.
+var dryRunTableACL tableACL
+
 // Init initiates table ACLs.
 //
 // The config file can be binary-proto-encoded, or json-encoded.
@@ -132,6 +136,26 @@ func InitFromProto(config *tableaclpb.Config) error {
 	return currentTableACL.Set(config)
 }
 
+// InitDryRun initiates the table ACLs that are evaluated in dry run mode, from
+// a config file in the same format as the one of Init.
+func InitDryRun(configFile string) error {
+	return dryRunTableACL.init(configFile, nil)
+}
+
+// InitDryRunFromProto inits the table ACLs that are evaluated in dry run mode
+// from a proto.
+func InitDryRunFromProto(config *tableaclpb.Config) error {
+	return dryRunTableACL.Set(config)
+}
+
+// ClearDryRun unloads the table ACLs that are evaluated in dry run mode.
+func ClearDryRun() {
+	dryRunTableACL.Lock()
+	defer dryRunTableACL.Unlock()
+	dryRunTableACL.entries = nil
+	dryRunTableACL.config = nil
+}
+
 // load loads configurations from a proto-defined Config
 // If err is nil, then entries is guaranteed to be non-nil (though possibly empty).
 func load(config *tableaclpb.Config, newACL func([]string) (acl.ACL, error)) (entries aclEntries, err error) {
@@ -238,7 +262,18 @@ func Authorized(table string, role Role) *ACLResult {
 	return currentTableACL.Authorized(table, role)
 }
 
+// Evaluate returns the same result as Authorized, along with the table name or
+// prefix of the entry that matched the table, which is empty if none did.
+func Evaluate(table string, role Role) (*ACLResult, string) {
+	return currentTableACL.evaluate(table, role)
+}
+
 func (tacl *tableACL) Authorized(table string, role Role) *ACLResult {
+	result, _ := tacl.evaluate(table, role)
+	return result
+}
+
+func (tacl *tableACL) evaluate(table string, role Role) (*ACLResult, string) {
 	tacl.RLock()
 	defer tacl.RUnlock()
 	start := 0
@@ -252,7 +287,7 @@ func (tacl *tableACL) Authorized(table string, role Role) *ACLResult {
 				return &ACLResult{
 					ACL:       acl,
 					GroupName: tacl.entries[mid].groupName,
-				}
+				}, val
 			}
 			break
 		} else if table < val {
@@ -264,7 +299,16 @@ func (tacl *tableACL) Authorized(table string, role Role) *ACLResult {
 	return &ACLResult{
 		ACL:       acl.DenyAllACL{},
 		GroupName: "",
+	}, ""
+}
+
+// EvaluateDryRun is like Evaluate, for the table ACLs that are evaluated in dry
+// run mode. The result is nil if no dry run config is loaded.
+func EvaluateDryRun(table string, role Role) (*ACLResult, string) {
+	if !dryRunTableACL.Valid() {
+		return nil, ""
 	}
+	return dryRunTableACL.evaluate(table, role)
 }
 
 // GetCurrentConfig returns a copy of current tableacl configuration.
@@ -272,6 +316,12 @@ func GetCurrentConfig() *tableaclpb.Config {
 	return currentTableACL.Config()
 }
 
+// GetDryRunConfig returns a copy of the tableacl configuration that is
+// evaluated in dry run mode, or nil if none is loaded.
+func GetDryRunConfig() *tableaclpb.Config {
+	return dryRunTableACL.Config()
+}
+
 func (tacl *tableACL) Config() *tableaclpb.Config {
 	tacl.RLock()
 	defer tacl.RUnlock()
@@ -245,3 +245,62 @@ func TestGetCurrentACLFactoryWithWrongDefault(t *testing.T) {
 		t.Fatalf("there are more than one acl factories, but the default given does not match any of these.")
 	}
 }
+
+func TestTableACLEvaluate(t *testing.T) {
+	tacl := tableACL{factory: &simpleacl.Factory{}}
+	config := &tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group01",
+			TableNamesOrPrefixes: []string{"test_music", "test_data%"},
+			Readers:              []string{"u1"},
+		}},
+	}
+	if err := tacl.Set(config); err != nil {
+		t.Fatalf("tableacl init should succeed, but got error: %v", err)
+	}
+	result, rule := tacl.evaluate("test_data_any", READER)
+	if rule != "test_data%" || result.GroupName != "group01" {
+		t.Fatalf("evaluate(test_data_any) matched rule %q of group %q, want: test_data%% of group01", rule, result.GroupName)
+	}
+	result, rule = tacl.evaluate("unknown_table", READER)
+	if rule != "" || result.GroupName != "" {
+		t.Fatalf("evaluate(unknown_table) matched rule %q of group %q, want no match", rule, result.GroupName)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	dryRunTableACL.factory = &simpleacl.Factory{}
+	defer func() {
+		ClearDryRun()
+		dryRunTableACL.factory = nil
+	}()
+
+	if result, _ := EvaluateDryRun("test_table", READER); result != nil {
+		t.Fatalf("EvaluateDryRun() without a dry run config = %v, want: nil", result)
+	}
+	config := &tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group01",
+			TableNamesOrPrefixes: []string{"test_table"},
+			Readers:              []string{"vt"},
+		}},
+	}
+	if err := InitDryRunFromProto(config); err != nil {
+		t.Fatalf("InitDryRunFromProto() should succeed, but got error: %v", err)
+	}
+	if got := GetDryRunConfig(); !proto.Equal(got, config) {
+		t.Fatalf("GetDryRunConfig() = %v, want: %v", got, config)
+	}
+	result, rule := EvaluateDryRun("test_table", READER)
+	if rule != "test_table" || !result.IsMember(&querypb.VTGateCallerID{Username: "vt"}) {
+		t.Fatalf("user: vt should have reader permission to table: test_table in the dry run config")
+	}
+	if result.IsMember(&querypb.VTGateCallerID{Username: "other"}) {
+		t.Fatalf("user: other should not have reader permission to table: test_table in the dry run config")
+	}
+
+	ClearDryRun()
+	if result, _ := EvaluateDryRun("test_table", READER); result != nil {
+		t.Fatalf("EvaluateDryRun() after ClearDryRun() = %v, want: nil", result)
+	}
+}
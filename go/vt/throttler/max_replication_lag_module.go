@@ -245,11 +245,29 @@ func (m *MaxReplicationLagModule) RecordReplicationLag(t time.Time, th *discover
 		m.mutableConfigMu.Unlock()
 		return
 	}
+	signal := m.mutableConfig.ReplicationLagSignal
 	m.mutableConfigMu.Unlock()
 
 	// Buffer data point for now to unblock the HealthCheck subscriber and process
 	// it asynchronously in ProcessRecords().
-	m.lagRecords <- replicationLagRecord{t, *th}
+	m.lagRecords <- replicationLagRecord{t, withReplicationLagSignal(*th, signal)}
+}
+
+// withReplicationLagSignal returns the given TabletHealth with its replication
+// lag replaced by the one measured by the configured signal, so that the rest of
+// the module doesn't have to care which signal it acts upon.
+func withReplicationLagSignal(th discovery.TabletHealth, signal string) discovery.TabletHealth {
+	if signal == "" || th.Stats == nil {
+		return th
+	}
+	// The signal was validated when the configuration was set.
+	s, _ := discovery.ParseReplicationLagSignal(signal)
+	lag := discovery.ReplicationLagSeconds(th.Stats, s)
+	if lag != th.Stats.ReplicationLagSeconds {
+		th.Stats = th.Stats.CloneVT()
+		th.Stats.ReplicationLagSeconds = lag
+	}
+	return th
 }
 
 // ProcessRecords is the main loop, run in a separate Go routine, which
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"time"
 
+	"vitess.io/vitess/go/vt/discovery"
+
 	throttlerdatapb "vitess.io/vitess/go/vt/proto/throttlerdata"
 )
 
@@ -128,6 +130,9 @@ func (cfg MaxReplicationLagModuleConfig) Verify() error {
 	if cfg.MaxRateApproachThreshold > 1 {
 		return fmt.Errorf("max_rate_approach_threshold must be <=1")
 	}
+	if _, err := discovery.ParseReplicationLagSignal(cfg.ReplicationLagSignal); err != nil {
+		return fmt.Errorf("replication_lag_signal: %v", err)
+	}
 	return nil
 }
 
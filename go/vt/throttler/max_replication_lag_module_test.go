@@ -856,3 +856,34 @@ func TestApplyLatestConfig(t *testing.T) {
 	tf.process(lagRecord(sinceZero(110*time.Second), r2, 0))
 	tf.checkState(stateIncreaseRate, 600, sinceZero(110*time.Second))
 }
+
+func TestWithReplicationLagSignal(t *testing.T) {
+	th := tabletStats(r1, 1)
+	heartbeatLag := uint32(20)
+	th.Stats.ReplicationLagSignals = &querypb.ReplicationLagSignals{
+		HeartbeatLagSeconds: &heartbeatLag,
+	}
+
+	// The reported replication lag is used without a signal.
+	got := withReplicationLagSignal(th, "")
+	require.EqualValues(t, 1, got.Stats.ReplicationLagSeconds)
+
+	// The heartbeat lag replaces the reported replication lag.
+	got = withReplicationLagSignal(th, string(discovery.ReplicationLagSignalHeartbeat))
+	require.EqualValues(t, 20, got.Stats.ReplicationLagSeconds)
+	// The original stats must not be modified.
+	require.EqualValues(t, 1, th.Stats.ReplicationLagSeconds)
+
+	// Signals that weren't measured fall back to the reported replication lag.
+	got = withReplicationLagSignal(th, string(discovery.ReplicationLagSignalSQLThread))
+	require.EqualValues(t, 1, got.Stats.ReplicationLagSeconds)
+}
+
+func TestVerifyReplicationLagSignal(t *testing.T) {
+	config := NewMaxReplicationLagModuleConfig(5)
+	config.ReplicationLagSignal = string(discovery.ReplicationLagSignalHeartbeat)
+	require.NoError(t, config.Verify())
+
+	config.ReplicationLagSignal = "binlog"
+	require.ErrorContains(t, config.Verify(), "invalid replication lag signal \"binlog\"")
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/user"
 	"path"
+	"strconv"
 	"sync"
 	"time"
 
@@ -401,6 +402,48 @@ func CheckShardLocked(ctx context.Context, keyspace, shard string) error {
 	return li.lockDescriptor.Check(ctx)
 }
 
+// GetShardLockHolder returns the lock held on the shard, as read from the
+// topology server, or nil if the shard isn't locked. It can only read the
+// locks of the implementations that store them as files in a locks directory
+// of the shard, like etcd2 and zk2: for the others it always returns nil.
+func (ts *Server) GetShardLockHolder(ctx context.Context, keyspace, shard string) (*Lock, error) {
+	locksPath := path.Join(KeyspacesPath, keyspace, ShardsPath, shard, "locks")
+	entries, err := ts.globalCell.ListDir(ctx, locksPath, false /*full*/)
+	if IsErrType(err, NoNode) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The clients waiting for the lock also have a file in the locks
+	// directory: the holder is the one with the lowest version, which is also
+	// the one with the lowest name for the implementations that don't
+	// version them in order.
+	var holder *Lock
+	var holderVersion int64
+	for _, entry := range entries {
+		contents, version, err := ts.globalCell.Get(ctx, path.Join(locksPath, entry.Name))
+		if IsErrType(err, NoNode) {
+			// The lock was released in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, _ := strconv.ParseInt(version.String(), 10, 64)
+		if holder != nil && v >= holderVersion {
+			continue
+		}
+		l := &Lock{}
+		if err := json.Unmarshal(contents, l); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot JSON-unmarshal lock %v", entry.Name)
+		}
+		holder, holderVersion = l, v
+	}
+	return holder, nil
+}
+
 // lockShard will lock the shard in the topology server.
 // UnlockShard should be called if this returns no error.
 func (l *Lock) lockShard(ctx context.Context, ts *Server, keyspace, shard string) (LockDescriptor, error) {
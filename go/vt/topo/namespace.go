@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// NamespacedRoot returns the root nested under the given namespace. Namespaces
+// let several Vitess clusters, e.g. staging and production, share the same
+// topology servers: the global root and the roots of all the cells of a
+// cluster are nested under its namespace, so a cluster can only ever read and
+// write the data under its own namespace. The root is returned as is if the
+// namespace is empty.
+//
+// The namespace and the root must not contain any ".." element, since it would
+// allow the root to escape its namespace. A root starting with "/" is returned
+// starting with "/".
+func NamespacedRoot(namespace, root string) (string, error) {
+	if namespace == "" {
+		return root, nil
+	}
+
+	trimmed := strings.Trim(namespace, "/")
+	if trimmed == "" {
+		return "", fmt.Errorf("invalid topo namespace %q", namespace)
+	}
+	for _, p := range []string{trimmed, root} {
+		for _, elem := range strings.Split(p, "/") {
+			if elem == ".." {
+				return "", fmt.Errorf("invalid topo path %q in namespace %q: it must not contain '..'", p, namespace)
+			}
+		}
+	}
+
+	namespaced := path.Join(trimmed, root)
+	if strings.HasPrefix(root, "/") {
+		namespaced = "/" + namespaced
+	}
+	return namespaced, nil
+}
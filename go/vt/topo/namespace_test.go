@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestNamespacedRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		root      string
+		expected  string
+		wantErr   bool
+	}{
+		{
+			name:     "no namespace",
+			root:     "/vitess/global",
+			expected: "/vitess/global",
+		},
+		{
+			name:      "absolute root",
+			namespace: "staging",
+			root:      "/vitess/global",
+			expected:  "/staging/vitess/global",
+		},
+		{
+			name:      "relative root",
+			namespace: "/staging/",
+			root:      "vitess/global",
+			expected:  "staging/vitess/global",
+		},
+		{
+			name:      "nested namespace",
+			namespace: "envs/staging",
+			root:      "/vitess/zone1",
+			expected:  "/envs/staging/vitess/zone1",
+		},
+		{
+			name:      "root escaping the namespace",
+			namespace: "staging",
+			root:      "/../production/vitess/global",
+			wantErr:   true,
+		},
+		{
+			name:      "namespace escaping",
+			namespace: "staging/../production",
+			root:      "/vitess/global",
+			wantErr:   true,
+		},
+		{
+			name:      "empty namespace path",
+			namespace: "/",
+			root:      "/vitess/global",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := topo.NamespacedRoot(tt.namespace, tt.root)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, root)
+		})
+	}
+}
+
+// rootRecordingFactory records the roots the connections are created with.
+type rootRecordingFactory struct {
+	*memorytopo.Factory
+
+	mu    sync.Mutex
+	roots map[string]string
+}
+
+func (f *rootRecordingFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	f.mu.Lock()
+	f.roots[cell] = root
+	f.mu.Unlock()
+	return f.Factory.Create(cell, serverAddr, root)
+}
+
+func TestServerInNamespace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+	defer ts.Close()
+	require.NoError(t, ts.UpdateCellInfoFields(ctx, "zone1", func(ci *topodatapb.CellInfo) error {
+		ci.Root = "/vitess/zone1"
+		return nil
+	}))
+	require.NoError(t, ts.UpdateCellInfoFields(ctx, "zone2", func(ci *topodatapb.CellInfo) error {
+		ci.Root = "/../production/vitess/zone2"
+		return nil
+	}))
+
+	recording := &rootRecordingFactory{Factory: factory, roots: map[string]string{}}
+	nts, err := topo.NewWithFactoryInNamespace(recording, "", "/vitess/global", "staging")
+	require.NoError(t, err)
+	defer nts.Close()
+
+	_, err = nts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+
+	_, err = nts.ConnForCell(ctx, "zone2")
+	assert.ErrorContains(t, err, "must not contain '..'", "a cell root must not escape the namespace")
+
+	assert.Equal(t, map[string]string{
+		topo.GlobalCell: "/staging/vitess/global",
+		"zone1":         "/staging/vitess/zone1",
+	}, recording.roots)
+
+	_, err = topo.NewWithFactoryInNamespace(recording, "", "/vitess/global", "staging/..")
+	assert.Error(t, err)
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file provides a registry of the processes of a group that need to
+// know which of them are running, like the vtgates of a cell for the clients
+// that discover them there.
+
+// RegistryMember is the registration of a member of a Registry.
+type RegistryMember struct {
+	Name string
+	Data []byte
+}
+
+// Registry is a directory of a cell in which the members of a group register
+// themselves while they run. Every member refreshes its registration on each
+// of its heartbeats, which changes the version of its node. A registration is
+// stale once its version didn't change for a while, as observed with the clock
+// of the reader, so that the clocks of the members don't need to agree.
+type Registry struct {
+	ts       *Server
+	cell     string
+	dir      string
+	liveness *LivenessTracker
+
+	mu sync.Mutex
+	// stale are the versions of the stale registrations found by the last
+	// call to Members.
+	stale map[string]Version
+}
+
+// NewRegistry returns the registry of the given directory of the cell. The
+// registrations that didn't change for staleAfter are stale.
+func NewRegistry(ts *Server, cell, dir string, staleAfter time.Duration) *Registry {
+	return &Registry{
+		ts:       ts,
+		cell:     cell,
+		dir:      dir,
+		liveness: NewLivenessTracker(staleAfter),
+	}
+}
+
+// Register saves the registration of the named member, or refreshes it if
+// it already exists.
+func (r *Registry) Register(ctx context.Context, name string, data []byte) error {
+	// The names are often host names, which validateObjectName rejects.
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid registry member name %q", name)
+	}
+	conn, err := r.ts.ConnForCell(ctx, r.cell)
+	if err != nil {
+		return err
+	}
+	// A nil version creates the node if it does not exist.
+	_, err = conn.Update(ctx, path.Join(r.dir, name), data, nil)
+	return err
+}
+
+// Unregister deletes the registration of the named member. It returns a
+// NoNode error if the member is not registered.
+func (r *Registry) Unregister(ctx context.Context, name string) error {
+	conn, err := r.ts.ConnForCell(ctx, r.cell)
+	if err != nil {
+		return err
+	}
+	return conn.Delete(ctx, path.Join(r.dir, name), nil)
+}
+
+// Members returns the members whose registrations are not stale at the time
+// now, sorted by name. The registrations seen for the first time are not
+// stale. They are all read at once, unless the topo server can't list them.
+func (r *Registry) Members(ctx context.Context, now time.Time) ([]*RegistryMember, error) {
+	conn, err := r.ts.ConnForCell(ctx, r.cell)
+	if err != nil {
+		return nil, err
+	}
+	kvs, err := r.list(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*RegistryMember
+	stale := make(map[string]Version)
+	names := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		name := path.Base(string(kv.Key))
+		names = append(names, name)
+		if !r.liveness.Observe(name, kv.Version, now) {
+			stale[name] = kv.Version
+			continue
+		}
+		members = append(members, &RegistryMember{Name: name, Data: kv.Value})
+	}
+	r.liveness.Retain(names)
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stale = stale
+	return members, nil
+}
+
+// DeleteStale deletes the stale registrations found by the last call to
+// Members, unless they were refreshed since.
+func (r *Registry) DeleteStale(ctx context.Context) error {
+	r.mu.Lock()
+	stale := r.stale
+	r.stale = nil
+	r.mu.Unlock()
+	if len(stale) == 0 {
+		return nil
+	}
+
+	conn, err := r.ts.ConnForCell(ctx, r.cell)
+	if err != nil {
+		return err
+	}
+	for name, version := range stale {
+		// A member that is still alive refreshed its registration, which
+		// changed its version, so it is not deleted.
+		err := conn.Delete(ctx, path.Join(r.dir, name), version)
+		if err != nil && !IsErrType(err, NoNode) && !IsErrType(err, BadVersion) {
+			return err
+		}
+	}
+	return nil
+}
+
+// list returns the registrations of the directory. The members that
+// unregister while they are read are skipped.
+func (r *Registry) list(ctx context.Context, conn Conn) ([]KVInfo, error) {
+	kvs, err := conn.List(ctx, r.dir+"/")
+	switch {
+	case err == nil:
+		// The prefix also matches the siblings of the directory whose names
+		// start with its name.
+		filtered := kvs[:0]
+		for _, kv := range kvs {
+			if path.Base(path.Dir(string(kv.Key))) == path.Base(r.dir) {
+				filtered = append(filtered, kv)
+			}
+		}
+		return filtered, nil
+	case IsErrType(err, NoNode):
+		return nil, nil
+	case !IsErrType(err, NoImplementation) && !IsErrType(err, ResourceExhausted):
+		return nil, err
+	}
+
+	// The topo server can't list the registrations, so they are read one by one.
+	entries, err := conn.ListDir(ctx, r.dir, false /*full*/)
+	switch {
+	case IsErrType(err, NoNode):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	kvs = make([]KVInfo, 0, len(entries))
+	for _, entry := range entries {
+		data, version, err := conn.Get(ctx, path.Join(r.dir, entry.Name))
+		switch {
+		case IsErrType(err, NoNode):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		kvs = append(kvs, KVInfo{Key: []byte(path.Join(r.dir, entry.Name)), Value: data, Version: version})
+	}
+	return kvs, nil
+}
+
+// LivenessTracker tells whether the nodes that their owners periodically
+// refresh are still refreshed, from the changes of their versions. The time
+// of the changes is that of the observer, so that the clocks of the owners
+// don't need to agree with it.
+type LivenessTracker struct {
+	staleAfter time.Duration
+
+	mu       sync.Mutex
+	observed map[string]*observedVersion
+}
+
+// observedVersion is the last version observed of a node, and the time it
+// was first observed.
+type observedVersion struct {
+	version string
+	since   time.Time
+}
+
+// NewLivenessTracker returns a LivenessTracker of the nodes that are stale
+// once their version didn't change for staleAfter.
+func NewLivenessTracker(staleAfter time.Duration) *LivenessTracker {
+	if staleAfter <= 0 {
+		// Without a duration, the nodes are never stale.
+		staleAfter = time.Duration(1<<63 - 1)
+	}
+	return &LivenessTracker{
+		staleAfter: staleAfter,
+		observed:   make(map[string]*observedVersion),
+	}
+}
+
+// Observe records the current version of the named node at the time now, and
+// returns true if the node is live: its version changed, or it was seen for
+// the first time, less than staleAfter ago.
+func (lt *LivenessTracker) Observe(name string, version Version, now time.Time) bool {
+	if version == nil {
+		return true
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	observed, ok := lt.observed[name]
+	if !ok || observed.version != version.String() {
+		lt.observed[name] = &observedVersion{version: version.String(), since: now}
+		return true
+	}
+	return now.Sub(observed.since) < lt.staleAfter
+}
+
+// Forget forgets the named node, which no longer exists. A node created again
+// with the same name is live, even if its version is the one last observed.
+func (lt *LivenessTracker) Forget(name string) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	delete(lt.observed, name)
+}
+
+// Retain forgets the nodes other than the named ones, which no longer exist.
+func (lt *LivenessTracker) Retain(names []string) {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	for name := range lt.observed {
+		if !keep[name] {
+			delete(lt.observed, name)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	memberNames := func(members []*topo.RegistryMember) []string {
+		var names []string
+		for _, member := range members {
+			names = append(names, member.Name)
+		}
+		return names
+	}
+
+	r1 := topo.NewRegistry(ts, "zone1", "members", time.Minute)
+	r2 := topo.NewRegistry(ts, "zone1", "members", time.Minute)
+	members, err := r1.Members(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, members)
+
+	require.NoError(t, r1.Register(ctx, "m1", []byte("one")))
+	require.NoError(t, r2.Register(ctx, "m2.example.com", []byte("two")))
+	require.Error(t, r1.Register(ctx, "../m3", nil))
+	// The siblings of the directory are not members.
+	require.NoError(t, topo.NewRegistry(ts, "zone1", "members_other", time.Minute).Register(ctx, "m4", nil))
+
+	// The registrations are live when they are first seen, whatever the time
+	// of the reader.
+	start := time.Now()
+	members, err = r1.Members(ctx, start)
+	require.NoError(t, err)
+	assert.Equal(t, []*topo.RegistryMember{{Name: "m1", Data: []byte("one")}, {Name: "m2.example.com", Data: []byte("two")}}, members)
+
+	// A registration is stale once it didn't change for the stale duration,
+	// as observed by the reader.
+	require.NoError(t, r1.Register(ctx, "m1", []byte("one")))
+	members, err = r1.Members(ctx, start.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m1"}, memberNames(members))
+
+	// The members that refresh their registration before it is deleted are kept.
+	require.NoError(t, r2.Register(ctx, "m2.example.com", []byte("two")))
+	require.NoError(t, r1.DeleteStale(ctx))
+	members, err = r1.Members(ctx, start.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2.example.com"}, memberNames(members))
+
+	// The others are deleted.
+	members, err = r1.Members(ctx, start.Add(4*time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, members)
+	require.NoError(t, r1.Register(ctx, "m1", []byte("one")))
+	require.NoError(t, r1.DeleteStale(ctx))
+	members, err = r2.Members(ctx, start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m1"}, memberNames(members))
+
+	require.NoError(t, r1.Unregister(ctx, "m1"))
+	require.True(t, topo.IsErrType(r1.Unregister(ctx, "m1"), topo.NoNode))
+	members, err = r2.Members(ctx, start)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestLivenessTracker(t *testing.T) {
+	lt := topo.NewLivenessTracker(time.Minute)
+	start := time.Now()
+
+	// A node is live when it is first seen, and while its version changes.
+	assert.True(t, lt.Observe("n1", memorytopo.NodeVersion(1), start))
+	assert.True(t, lt.Observe("n1", memorytopo.NodeVersion(1), start.Add(30*time.Second)))
+	assert.True(t, lt.Observe("n1", memorytopo.NodeVersion(2), start.Add(90*time.Second)))
+	assert.False(t, lt.Observe("n1", memorytopo.NodeVersion(2), start.Add(3*time.Minute)))
+
+	// A node created again is live, even with the version last observed.
+	lt.Forget("n1")
+	assert.True(t, lt.Observe("n1", memorytopo.NodeVersion(2), start.Add(3*time.Minute)))
+
+	lt.Retain(nil)
+	assert.True(t, lt.Observe("n1", memorytopo.NodeVersion(2), start.Add(10*time.Minute)))
+}
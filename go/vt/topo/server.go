@@ -91,6 +91,9 @@ const (
 	TabletsPath           = "tablets"
 	MetadataPath          = "metadata"
 	ExternalClusterVitess = "vitess"
+	WorkflowProfilesPath  = "workflow_profiles"
+	VTGateEndpointsPath   = "vtgates"
+	TableKillSwitchesPath = "table_kill_switches"
 )
 
 // Factory is a factory interface to create Conn objects.
@@ -142,6 +145,10 @@ type Server struct {
 	// will read the list of addresses for that cell from the
 	// global cluster and create clients as needed.
 	cellConns map[string]cellConn
+
+	// namespace, if set, is the path under which the global root and the
+	// roots of all the cells are nested. See NamespacedRoot.
+	namespace string
 }
 
 type cellConn struct {
@@ -167,6 +174,10 @@ var (
 	// server.
 	topoGlobalRoot string
 
+	// topoNamespace is the namespace of the global root and the cell roots
+	// in the topology servers.
+	topoNamespace string
+
 	// factories has the factories for the Conn objects.
 	factories = make(map[string]Factory)
 
@@ -188,6 +199,7 @@ func registerTopoFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&topoImplementation, "topo_implementation", topoImplementation, "the topology implementation to use")
 	fs.StringVar(&topoGlobalServerAddress, "topo_global_server_address", topoGlobalServerAddress, "the address of the global topology server")
 	fs.StringVar(&topoGlobalRoot, "topo_global_root", topoGlobalRoot, "the path of the global topology data in the global topology server")
+	fs.StringVar(&topoNamespace, "topo_namespace", topoNamespace, "if set, the global root and the roots of all cells are nested under this path in the topology servers, so that several Vitess clusters can share them")
 }
 
 // RegisterFactory registers a Factory for an implementation for a Server.
@@ -203,6 +215,18 @@ func RegisterFactory(name string, factory Factory) {
 // NewWithFactory creates a new Server based on the given Factory.
 // It also opens the global cell connection.
 func NewWithFactory(factory Factory, serverAddress, root string) (*Server, error) {
+	return NewWithFactoryInNamespace(factory, serverAddress, root, "")
+}
+
+// NewWithFactoryInNamespace creates a new Server based on the given Factory,
+// with the global root and the roots of all the cells nested under the given
+// namespace. It also opens the global cell connection.
+func NewWithFactoryInNamespace(factory Factory, serverAddress, root, namespace string) (*Server, error) {
+	root, err := NamespacedRoot(namespace, root)
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := factory.Create(GlobalCell, serverAddress, root)
 	if err != nil {
 		return nil, err
@@ -225,17 +249,25 @@ func NewWithFactory(factory Factory, serverAddress, root string) (*Server, error
 		globalReadOnlyCell: connReadOnly,
 		factory:            factory,
 		cellConns:          make(map[string]cellConn),
+		namespace:          namespace,
 	}, nil
 }
 
 // OpenServer returns a Server using the provided implementation,
 // address and root for the global server.
 func OpenServer(implementation, serverAddress, root string) (*Server, error) {
+	return OpenServerInNamespace(implementation, serverAddress, root, "")
+}
+
+// OpenServerInNamespace returns a Server using the provided implementation,
+// address and root for the global server, with the global root and the roots
+// of all the cells nested under the given namespace.
+func OpenServerInNamespace(implementation, serverAddress, root, namespace string) (*Server, error) {
 	factory, ok := factories[implementation]
 	if !ok {
 		return nil, NewError(NoImplementation, implementation)
 	}
-	return NewWithFactory(factory, serverAddress, root)
+	return NewWithFactoryInNamespace(factory, serverAddress, root, namespace)
 }
 
 // Open returns a Server using the command line parameter flags
@@ -247,9 +279,9 @@ func Open() *Server {
 	if topoGlobalRoot == "" {
 		log.Exit("topo_global_root must be non-empty")
 	}
-	ts, err := OpenServer(topoImplementation, topoGlobalServerAddress, topoGlobalRoot)
+	ts, err := OpenServerInNamespace(topoImplementation, topoGlobalServerAddress, topoGlobalRoot, topoNamespace)
 	if err != nil {
-		log.Exitf("Failed to open topo server (%v,%v,%v): %v", topoImplementation, topoGlobalServerAddress, topoGlobalRoot, err)
+		log.Exitf("Failed to open topo server (%v,%v,%v,%v): %v", topoImplementation, topoGlobalServerAddress, topoGlobalRoot, topoNamespace, err)
 	}
 	return ts
 }
@@ -290,10 +322,16 @@ func (ts *Server) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 		}
 	}
 
+	// The cell root is nested under the namespace of the server, if any.
+	root, err := NamespacedRoot(ts.namespace, ci.Root)
+	if err != nil {
+		return nil, vterrors.Wrap(err, fmt.Sprintf("failed to create topo connection to %v, %v", ci.ServerAddress, ci.Root))
+	}
+
 	// Connect to the cell topo server, while holding the lock.
 	// This ensures only one connection is established at any given time.
 	// Create the connection and cache it
-	conn, err := ts.factory.Create(cell, ci.ServerAddress, ci.Root)
+	conn, err := ts.factory.Create(cell, ci.ServerAddress, root)
 	switch {
 	case err == nil:
 		conn = NewStatsConn(cell, conn)
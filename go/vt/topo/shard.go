@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"path"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -41,6 +42,7 @@ import (
 	"vitess.io/vitess/go/vt/topo/topoproto"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 const (
@@ -431,10 +433,96 @@ func (si *ShardInfo) UpdateDeniedTables(ctx context.Context, tabletType topodata
 		}
 
 		tc.Cells = addCells(tc.Cells, cells)
+		// The tables are denied again, until they are removed.
+		tc.DeniedTablesExpireTimes = nil
 	}
 	return nil
 }
 
+// SetDeniedTablesExpireTime sets when the given denied tables of the given
+// tablet type are lifted. A zero expireTime denies them until they are removed.
+//
+// This function should be called while holding the keyspace lock.
+func (si *ShardInfo) SetDeniedTablesExpireTime(ctx context.Context, tabletType topodatapb.TabletType, tables []string, expireTime time.Time) error {
+	if err := CheckKeyspaceLocked(ctx, si.keyspace); err != nil {
+		return err
+	}
+	tc := si.GetTabletControl(tabletType)
+	if tc == nil || len(tc.DeniedTables) == 0 {
+		return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no denied tables for tablet type %v in shard %v/%v", tabletType, si.keyspace, si.shardName)
+	}
+	for _, table := range tables {
+		if !slices.Contains(tc.DeniedTables, table) {
+			return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "table %v is not denied for tablet type %v in shard %v/%v", table, tabletType, si.keyspace, si.shardName)
+		}
+	}
+	for _, table := range tables {
+		if expireTime.IsZero() {
+			delete(tc.DeniedTablesExpireTimes, table)
+			continue
+		}
+		if tc.DeniedTablesExpireTimes == nil {
+			tc.DeniedTablesExpireTimes = make(map[string]*vttimepb.Time)
+		}
+		tc.DeniedTablesExpireTimes[table] = protoutil.TimeToProto(expireTime)
+	}
+	return nil
+}
+
+// NextDeniedTableExpireTime returns the earliest expire time of the denied
+// tables of the TabletControl, or the zero time if none of them expires.
+func NextDeniedTableExpireTime(tc *topodatapb.Shard_TabletControl) time.Time {
+	var next time.Time
+	for _, table := range tc.DeniedTables {
+		if expireTime, ok := tc.DeniedTablesExpireTimes[table]; ok {
+			if t := protoutil.TimeFromProto(expireTime); next.IsZero() || t.Before(next) {
+				next = t
+			}
+		}
+	}
+	return next
+}
+
+// RemoveExpiredDeniedTables removes the denied tables whose expire time has
+// passed, and drops the TabletControls that are left without tables. The
+// tables of the frozen TabletControls, and the ones keep returns true for, are
+// left alone: a workflow owns them. It returns whether any table was removed,
+// and whether any expired table was kept.
+func (si *ShardInfo) RemoveExpiredDeniedTables(now time.Time, keep func(table string) bool) (removed, kept bool) {
+	var tabletControls []*topodatapb.Shard_TabletControl
+	for _, tc := range si.TabletControls {
+		if len(tc.DeniedTablesExpireTimes) == 0 {
+			tabletControls = append(tabletControls, tc)
+			continue
+		}
+		var tables []string
+		for _, table := range tc.DeniedTables {
+			expireTime, ok := tc.DeniedTablesExpireTimes[table]
+			if !ok || now.Before(protoutil.TimeFromProto(expireTime)) {
+				tables = append(tables, table)
+				continue
+			}
+			if tc.Frozen || keep(table) {
+				tables = append(tables, table)
+				kept = true
+				continue
+			}
+			log.Infof("Removing the expired denied table %v of tablet type %v in shard %v/%v", table, tc.TabletType, si.keyspace, si.shardName)
+			delete(tc.DeniedTablesExpireTimes, table)
+			removed = true
+		}
+		tc.DeniedTables = tables
+		if len(tc.DeniedTables) == 0 {
+			continue
+		}
+		tabletControls = append(tabletControls, tc)
+	}
+	if removed {
+		si.TabletControls = tabletControls
+	}
+	return removed, kept
+}
+
 func (si *ShardInfo) updatePrimaryTabletControl(tc *topodatapb.Shard_TabletControl, remove bool, tables []string) error {
 	var newTables []string
 	for _, table := range tables {
@@ -469,6 +557,9 @@ func (si *ShardInfo) updatePrimaryTabletControl(tc *topodatapb.Shard_TabletContr
 			}
 		}
 		tc.DeniedTables = newDenyList
+		for _, table := range tables {
+			delete(tc.DeniedTablesExpireTimes, table)
+		}
 		if len(tc.DeniedTables) == 0 {
 			si.removeTabletTypeFromTabletControl(topodatapb.TabletType_PRIMARY)
 		}
@@ -20,12 +20,15 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/test/utils"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 // This file tests the shard related object functionnalities.
@@ -206,6 +209,73 @@ func TestUpdateSourceDeniedTables(t *testing.T) {
 	}
 }
 
+func TestDeniedTablesExpireTime(t *testing.T) {
+	si := NewShardInfo("ks", "sh", &topodatapb.Shard{}, nil)
+	now := time.Now()
+	keepNone := func(string) bool { return false }
+
+	// check we enforce the keyspace lock
+	err := si.SetDeniedTablesExpireTime(context.Background(), topodatapb.TabletType_REPLICA, []string{"t1"}, now)
+	require.EqualError(t, err, "keyspace ks is not locked (no locksInfo)")
+	ctx := lockedKeyspaceContext("ks")
+
+	err = si.SetDeniedTablesExpireTime(ctx, topodatapb.TabletType_REPLICA, []string{"t1"}, now)
+	require.ErrorContains(t, err, "no denied tables for tablet type REPLICA in shard ks/sh")
+
+	require.NoError(t, si.UpdateDeniedTables(ctx, topodatapb.TabletType_PRIMARY, nil, false, []string{"t1", "t2"}))
+	require.NoError(t, si.UpdateDeniedTables(ctx, topodatapb.TabletType_RDONLY, nil, false, []string{"t1"}))
+	err = si.SetDeniedTablesExpireTime(ctx, topodatapb.TabletType_PRIMARY, []string{"t3"}, now)
+	require.ErrorContains(t, err, "table t3 is not denied for tablet type PRIMARY in shard ks/sh")
+
+	// only the given tables expire
+	require.NoError(t, si.SetDeniedTablesExpireTime(ctx, topodatapb.TabletType_PRIMARY, []string{"t1"}, now.Add(time.Minute)))
+	primary := si.GetTabletControl(topodatapb.TabletType_PRIMARY)
+	assert.Equal(t, map[string]*vttimepb.Time{"t1": protoutil.TimeToProto(now.Add(time.Minute))}, primary.DeniedTablesExpireTimes)
+	assert.True(t, now.Add(time.Minute).Equal(NextDeniedTableExpireTime(primary)))
+	assert.True(t, NextDeniedTableExpireTime(si.GetTabletControl(topodatapb.TabletType_RDONLY)).IsZero())
+
+	removed, kept := si.RemoveExpiredDeniedTables(now, keepNone)
+	assert.False(t, removed)
+	assert.False(t, kept)
+
+	// the tables a workflow owns are kept
+	removed, kept = si.RemoveExpiredDeniedTables(now.Add(time.Minute), func(table string) bool { return table == "t1" })
+	assert.False(t, removed)
+	assert.True(t, kept)
+	assert.Equal(t, []string{"t1", "t2"}, primary.DeniedTables)
+
+	// and so are the tables of a frozen TabletControl
+	primary.Frozen = true
+	removed, kept = si.RemoveExpiredDeniedTables(now.Add(time.Minute), keepNone)
+	assert.False(t, removed)
+	assert.True(t, kept)
+	primary.Frozen = false
+
+	removed, kept = si.RemoveExpiredDeniedTables(now.Add(time.Minute), keepNone)
+	assert.True(t, removed)
+	assert.False(t, kept)
+	assert.Equal(t, []string{"t2"}, primary.DeniedTables)
+	assert.Empty(t, primary.DeniedTablesExpireTimes)
+
+	// a TabletControl left without tables is removed
+	require.NoError(t, si.SetDeniedTablesExpireTime(ctx, topodatapb.TabletType_RDONLY, []string{"t1"}, now))
+	removed, _ = si.RemoveExpiredDeniedTables(now, keepNone)
+	assert.True(t, removed)
+	assert.Nil(t, si.GetTabletControl(topodatapb.TabletType_RDONLY))
+	assert.NotNil(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY))
+
+	// a zero time denies the tables until they are removed
+	require.NoError(t, si.SetDeniedTablesExpireTime(ctx, topodatapb.TabletType_PRIMARY, []string{"t2"}, now))
+	require.NoError(t, si.SetDeniedTablesExpireTime(ctx, topodatapb.TabletType_PRIMARY, []string{"t2"}, time.Time{}))
+	assert.Empty(t, primary.DeniedTablesExpireTimes)
+
+	// denying a table again makes it permanent
+	require.NoError(t, si.SetDeniedTablesExpireTime(ctx, topodatapb.TabletType_PRIMARY, []string{"t2"}, now))
+	require.NoError(t, si.UpdateDeniedTables(ctx, topodatapb.TabletType_PRIMARY, nil, true, []string{"t2"}))
+	require.NoError(t, si.UpdateDeniedTables(ctx, topodatapb.TabletType_PRIMARY, nil, false, []string{"t2"}))
+	assert.Empty(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY).DeniedTablesExpireTimes)
+}
+
 func TestValidateShardName(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"time"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file provides the utility methods to save / retrieve the table kill
+// switches of the keyspaces in the topology global cell. The tablets of a
+// keyspace read them periodically, and enforce them.
+
+// TableKillSwitchClearedTTL is how long a cleared table kill switch is kept,
+// so that the tablets of the keyspace can tell who cleared it.
+var TableKillSwitchClearedTTL = time.Hour
+
+// TableKillSwitch disables the reads and/or the writes of a table of a
+// keyspace, until it is cleared or it expires.
+type TableKillSwitch struct {
+	Table      string
+	Reads      bool
+	Writes     bool
+	Reason     string
+	SetBy      string
+	ExpireTime time.Time
+	// ClearedBy is who cleared the kill switch. A cleared kill switch neither
+	// disables the reads nor the writes, and is kept until it expires.
+	ClearedBy string `json:",omitempty"`
+}
+
+// Cleared returns true if the kill switch was cleared.
+func (ks *TableKillSwitch) Cleared() bool {
+	return !ks.Reads && !ks.Writes
+}
+
+// Expired returns true if the kill switch expired at the time now.
+func (ks *TableKillSwitch) Expired(now time.Time) bool {
+	return !ks.ExpireTime.IsZero() && !now.Before(ks.ExpireTime)
+}
+
+// GetTableKillSwitchesPath returns the node path of the table kill switches
+// of the keyspace.
+func GetTableKillSwitchesPath(keyspace string) string {
+	return path.Join(TableKillSwitchesPath, keyspace)
+}
+
+// GetTableKillSwitches returns the table kill switches of the keyspace, by
+// table, including the cleared ones that did not expire yet.
+func (ts *Server) GetTableKillSwitches(ctx context.Context, keyspace string) (map[string]*TableKillSwitch, error) {
+	if err := validateObjectName(keyspace); err != nil {
+		return nil, err
+	}
+	switches, _, err := ts.getTableKillSwitches(ctx, keyspace)
+	return switches, err
+}
+
+// SetTableKillSwitch sets the kill switch of a table of the keyspace, and
+// returns the kill switches of the keyspace.
+func (ts *Server) SetTableKillSwitch(ctx context.Context, keyspace string, ks *TableKillSwitch, now time.Time) (map[string]*TableKillSwitch, error) {
+	if ks.Table == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing table")
+	}
+	if ks.Cleared() {
+		return ts.ClearTableKillSwitch(ctx, keyspace, ks.Table, ks.SetBy, now)
+	}
+	return ts.updateTableKillSwitches(ctx, keyspace, now, func(switches map[string]*TableKillSwitch) {
+		switches[ks.Table] = ks
+	})
+}
+
+// ClearTableKillSwitch clears the kill switch of a table of the keyspace, and
+// returns the kill switches of the keyspace. The cleared kill switch is kept
+// for TableKillSwitchClearedTTL, as cleared by the given user.
+func (ts *Server) ClearTableKillSwitch(ctx context.Context, keyspace, table, by string, now time.Time) (map[string]*TableKillSwitch, error) {
+	if table == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing table")
+	}
+	return ts.updateTableKillSwitches(ctx, keyspace, now, func(switches map[string]*TableKillSwitch) {
+		if _, ok := switches[table]; !ok {
+			return
+		}
+		switches[table] = &TableKillSwitch{
+			Table:      table,
+			ClearedBy:  by,
+			ExpireTime: now.Add(TableKillSwitchClearedTTL),
+		}
+	})
+}
+
+// DeleteTableKillSwitches deletes the table kill switches of the keyspace.
+func (ts *Server) DeleteTableKillSwitches(ctx context.Context, keyspace string) error {
+	if err := validateObjectName(keyspace); err != nil {
+		return err
+	}
+	return ts.globalCell.Delete(ctx, GetTableKillSwitchesPath(keyspace), nil)
+}
+
+func (ts *Server) getTableKillSwitches(ctx context.Context, keyspace string) (map[string]*TableKillSwitch, Version, error) {
+	switches := make(map[string]*TableKillSwitch)
+	data, version, err := ts.globalCell.Get(ctx, GetTableKillSwitchesPath(keyspace))
+	switch {
+	case IsErrType(err, NoNode):
+		return switches, nil, nil
+	case err != nil:
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, &switches); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "invalid table kill switches of keyspace %v", keyspace)
+	}
+	return switches, version, nil
+}
+
+// updateTableKillSwitches updates the table kill switches of the keyspace,
+// and drops the ones that expired. If the kill switches were changed in the
+// meantime, they are read again and the update is retried.
+func (ts *Server) updateTableKillSwitches(ctx context.Context, keyspace string, now time.Time, update func(map[string]*TableKillSwitch)) (map[string]*TableKillSwitch, error) {
+	if err := validateObjectName(keyspace); err != nil {
+		return nil, err
+	}
+	for {
+		switches, version, err := ts.getTableKillSwitches(ctx, keyspace)
+		if err != nil {
+			return nil, err
+		}
+		update(switches)
+		for table, ks := range switches {
+			if ks.Expired(now) {
+				delete(switches, table)
+			}
+		}
+		data, err := json.Marshal(switches)
+		if err != nil {
+			return nil, err
+		}
+		if version == nil {
+			_, err = ts.globalCell.Create(ctx, GetTableKillSwitchesPath(keyspace), data)
+		} else {
+			_, err = ts.globalCell.Update(ctx, GetTableKillSwitchesPath(keyspace), data, version)
+		}
+		if !IsErrType(err, BadVersion) && !IsErrType(err, NodeExists) {
+			// This includes the 'err=nil' case.
+			if err != nil {
+				return nil, err
+			}
+			return switches, nil
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestTableKillSwitches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	switches, err := ts.GetTableKillSwitches(ctx, "ks")
+	require.NoError(t, err)
+	require.Empty(t, switches)
+
+	now := time.Now().Truncate(time.Second)
+	_, err = ts.SetTableKillSwitch(ctx, "ks", &topo.TableKillSwitch{Table: "t1", Writes: true, SetBy: "alice"}, now)
+	require.NoError(t, err)
+	_, err = ts.SetTableKillSwitch(ctx, "ks", &topo.TableKillSwitch{Table: "t2", Reads: true, SetBy: "alice", ExpireTime: now.Add(time.Minute)}, now)
+	require.NoError(t, err)
+	_, err = ts.SetTableKillSwitch(ctx, "ks", &topo.TableKillSwitch{Reads: true}, now)
+	require.Error(t, err)
+
+	switches, err = ts.GetTableKillSwitches(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, switches, 2)
+	require.Equal(t, "alice", switches["t1"].SetBy)
+	require.False(t, switches["t1"].Cleared())
+
+	// A cleared kill switch is kept with whoever cleared it, until it expires.
+	switches, err = ts.ClearTableKillSwitch(ctx, "ks", "t1", "bob", now)
+	require.NoError(t, err)
+	require.True(t, switches["t1"].Cleared())
+	require.Equal(t, "bob", switches["t1"].ClearedBy)
+	// Clearing a table without a kill switch does nothing.
+	switches, err = ts.ClearTableKillSwitch(ctx, "ks", "t3", "bob", now)
+	require.NoError(t, err)
+	require.Len(t, switches, 2)
+
+	// The expired kill switches are dropped on the next update.
+	switches, err = ts.ClearTableKillSwitch(ctx, "ks", "t3", "bob", now.Add(topo.TableKillSwitchClearedTTL))
+	require.NoError(t, err)
+	require.Empty(t, switches)
+
+	require.NoError(t, ts.DeleteTableKillSwitches(ctx, "ks"))
+	require.True(t, topo.IsErrType(ts.DeleteTableKillSwitches(ctx, "ks"), topo.NoNode))
+	switches, err = ts.GetTableKillSwitches(ctx, "ks")
+	require.NoError(t, err)
+	require.Empty(t, switches)
+}
@@ -29,6 +29,8 @@ import (
 
 // ParseDestination parses the string representation of a Destination
 // of the form keyspace:shard@tablet_type. You can use a / instead of a :.
+// The shard can also be a bracketed, comma separated list of shards, as in
+// keyspace:[-80,80-c0]@tablet_type, to target exactly those shards.
 func ParseDestination(targetString string, defaultTabletType topodatapb.TabletType) (string, topodatapb.TabletType, key.Destination, error) {
 	var dest key.Destination
 	var keyspace string
@@ -43,7 +45,16 @@ func ParseDestination(targetString string, defaultTabletType topodatapb.TabletTy
 	}
 	last = strings.LastIndexAny(targetString, "/:")
 	if last != -1 {
-		dest = key.DestinationShard(targetString[last+1:])
+		shardString := targetString[last+1:]
+		if strings.HasPrefix(shardString, "[") {
+			var err error
+			dest, err = parseShardList(shardString)
+			if err != nil {
+				return keyspace, tabletType, dest, err
+			}
+		} else {
+			dest = key.DestinationShard(shardString)
+		}
 		targetString = targetString[:last]
 	}
 	// Try to parse it as a keyspace id or range
@@ -77,3 +88,29 @@ func ParseDestination(targetString string, defaultTabletType topodatapb.TabletTy
 	keyspace = targetString
 	return keyspace, tabletType, dest, nil
 }
+
+// parseShardList parses a bracketed, comma separated list of shard names
+// such as [-80,80-c0]. A list with a single shard is returned as a
+// DestinationShard, so that it is treated exactly like a plain shard target.
+func parseShardList(shardString string) (key.Destination, error) {
+	if !strings.HasSuffix(shardString, "]") {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid shard list provided. Couldn't find list end ']'")
+	}
+	var shards []string
+	seen := make(map[string]bool)
+	for _, shard := range strings.Split(shardString[1:len(shardString)-1], ",") {
+		shard = strings.TrimSpace(shard)
+		if shard == "" {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "empty shard name in shard list %s", shardString)
+		}
+		if seen[shard] {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "duplicate shard %s in shard list %s", shard, shardString)
+		}
+		seen[shard] = true
+		shards = append(shards, shard)
+	}
+	if len(shards) == 1 {
+		return key.DestinationShard(shards[0]), nil
+	}
+	return key.DestinationShards(shards), nil
+}
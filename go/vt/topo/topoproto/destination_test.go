@@ -87,6 +87,21 @@ func TestParseDestination(t *testing.T) {
 		keyspace:     "ks",
 		dest:         key.DestinationShard("-80"),
 		tabletType:   topodatapb.TabletType_PRIMARY,
+	}, {
+		targetString: "ks:[-80,80-90]@replica",
+		keyspace:     "ks",
+		dest:         key.DestinationShards{"-80", "80-90"},
+		tabletType:   topodatapb.TabletType_REPLICA,
+	}, {
+		targetString: "ks/[-80, 80-]",
+		keyspace:     "ks",
+		dest:         key.DestinationShards{"-80", "80-"},
+		tabletType:   topodatapb.TabletType_PRIMARY,
+	}, {
+		targetString: "ks:[80-]",
+		keyspace:     "ks",
+		dest:         key.DestinationShard("80-"),
+		tabletType:   topodatapb.TabletType_PRIMARY,
 	}}
 
 	for _, tcase := range testcases {
@@ -120,4 +135,22 @@ func TestParseDestination(t *testing.T) {
 	if err == nil || err.Error() != want {
 		t.Errorf("executorExec error: %v, want %s", err, want)
 	}
+
+	_, _, _, err = ParseDestination("ks:[-80,80-", topodatapb.TabletType_PRIMARY)
+	want = "invalid shard list provided. Couldn't find list end ']'"
+	if err == nil || err.Error() != want {
+		t.Errorf("executorExec error: %v, want %s", err, want)
+	}
+
+	_, _, _, err = ParseDestination("ks:[-80,,80-]", topodatapb.TabletType_PRIMARY)
+	want = "empty shard name in shard list [-80,,80-]"
+	if err == nil || err.Error() != want {
+		t.Errorf("executorExec error: %v, want %s", err, want)
+	}
+
+	_, _, _, err = ParseDestination("ks:[-80,-80]", topodatapb.TabletType_PRIMARY)
+	want = "duplicate shard -80 in shard list [-80,-80]"
+	if err == nil || err.Error() != want {
+		t.Errorf("executorExec error: %v, want %s", err, want)
+	}
 }
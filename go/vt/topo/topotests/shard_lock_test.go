@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestGetShardLockHolder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "-80"))
+
+	holder, err := ts.GetShardLockHolder(ctx, "ks", "-80")
+	require.NoError(t, err)
+	assert.Nil(t, holder)
+
+	// Lay out the lock files the way etcd2topo does, the holder first and
+	// then a client waiting for the lock.
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	for _, lock := range []struct{ name, action string }{{"b", "holder action"}, {"a", "waiter action"}} {
+		contents, err := json.Marshal(&topo.Lock{Action: lock.action, HostName: "host-" + lock.name, Status: "Running"})
+		require.NoError(t, err)
+		_, err = conn.Create(ctx, "keyspaces/ks/shards/-80/locks/"+lock.name, contents)
+		require.NoError(t, err)
+	}
+	holder, err = ts.GetShardLockHolder(ctx, "ks", "-80")
+	require.NoError(t, err)
+	require.NotNil(t, holder)
+	assert.Equal(t, "holder action", holder.Action)
+	assert.Equal(t, "host-b", holder.HostName)
+}
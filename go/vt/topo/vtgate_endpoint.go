@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// This file provides the utility methods to register / discover the vtgates
+// of a cell in its topology, for the clients that look them up there.
+
+// VTGateEndpoint is how a vtgate advertises itself in the topology of its
+// cell.
+type VTGateEndpoint struct {
+	// Name identifies the vtgate in the cell.
+	Name string `json:"-"`
+	// Address is the host:port of the gRPC server of the vtgate.
+	Address string `json:"address"`
+}
+
+// VTGateEndpoints are the endpoints of the vtgates of a cell. They are kept
+// in a Registry: the vtgates save their endpoint again on every heartbeat,
+// and an endpoint is stale once it didn't change for a while.
+type VTGateEndpoints struct {
+	registry *Registry
+}
+
+// NewVTGateEndpoints returns the endpoints of the vtgates of the cell. The
+// endpoints that didn't change for staleAfter are stale, zero means never.
+func (ts *Server) NewVTGateEndpoints(cell string, staleAfter time.Duration) *VTGateEndpoints {
+	return &VTGateEndpoints{registry: NewRegistry(ts, cell, VTGateEndpointsPath, staleAfter)}
+}
+
+// Save registers the vtgate endpoint, or refreshes its registration if it
+// already exists.
+func (ve *VTGateEndpoints) Save(ctx context.Context, endpoint *VTGateEndpoint) error {
+	if endpoint.Name == "" || endpoint.Address == "" {
+		return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "vtgate endpoint name and address must not be empty")
+	}
+	data, err := json.Marshal(endpoint)
+	if err != nil {
+		return err
+	}
+	return ve.registry.Register(ctx, endpoint.Name, data)
+}
+
+// Delete unregisters the named vtgate endpoint.
+func (ve *VTGateEndpoints) Delete(ctx context.Context, name string) error {
+	return ve.registry.Unregister(ctx, name)
+}
+
+// Get returns the vtgate endpoints that are not stale at the time now,
+// sorted by name. The endpoints seen for the first time are not stale, and
+// the ones that can't be decoded are skipped.
+func (ve *VTGateEndpoints) Get(ctx context.Context, now time.Time) ([]*VTGateEndpoint, error) {
+	members, err := ve.registry.Members(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make([]*VTGateEndpoint, 0, len(members))
+	for _, member := range members {
+		endpoint := &VTGateEndpoint{}
+		if err := json.Unmarshal(member.Data, endpoint); err != nil {
+			log.Warningf("Invalid vtgate endpoint %v: %v", member.Name, err)
+			continue
+		}
+		endpoint.Name = member.Name
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// DeleteStale deletes the stale endpoints found by the last call to Get,
+// unless they were refreshed since.
+func (ve *VTGateEndpoints) DeleteStale(ctx context.Context) error {
+	return ve.registry.DeleteStale(ctx)
+}
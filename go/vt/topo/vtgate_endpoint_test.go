@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestVTGateEndpoints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	ve := ts.NewVTGateEndpoints("zone1", time.Minute)
+	start := time.Now()
+	endpoints, err := ve.Get(ctx, start)
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+
+	require.NoError(t, ve.Save(ctx, &topo.VTGateEndpoint{Name: "vtgate2", Address: "host2:15999"}))
+	require.NoError(t, ve.Save(ctx, &topo.VTGateEndpoint{Name: "vtgate1", Address: "host1:15999"}))
+	require.Error(t, ve.Save(ctx, &topo.VTGateEndpoint{Name: "vtgate3"}))
+
+	endpoints, err = ve.Get(ctx, start)
+	require.NoError(t, err)
+	assert.Equal(t, []*topo.VTGateEndpoint{
+		{Name: "vtgate1", Address: "host1:15999"},
+		{Name: "vtgate2", Address: "host2:15999"},
+	}, endpoints)
+
+	// The endpoints are registered per cell.
+	endpoints, err = ts.NewVTGateEndpoints("zone2", time.Minute).Get(ctx, start)
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+
+	// The endpoints that are not refreshed become stale, and are deleted.
+	require.NoError(t, ve.Save(ctx, &topo.VTGateEndpoint{Name: "vtgate1", Address: "host1:15999"}))
+	endpoints, err = ve.Get(ctx, start.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []*topo.VTGateEndpoint{{Name: "vtgate1", Address: "host1:15999"}}, endpoints)
+	require.NoError(t, ve.DeleteStale(ctx))
+	endpoints, err = ts.NewVTGateEndpoints("zone1", time.Minute).Get(ctx, start)
+	require.NoError(t, err)
+	assert.Equal(t, []*topo.VTGateEndpoint{{Name: "vtgate1", Address: "host1:15999"}}, endpoints)
+
+	require.NoError(t, ve.Delete(ctx, "vtgate1"))
+	endpoints, err = ve.Get(ctx, start)
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+	require.True(t, topo.IsErrType(ve.Delete(ctx, "vtgate1"), topo.NoNode))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// This file provides the utility methods to save / retrieve the workflow
+// profiles in the topology global cell.
+
+// validateWorkflowProfileName checks that the name is a valid object name, so
+// that the profile can't refer to a node outside of WorkflowProfilesPath.
+func validateWorkflowProfileName(name string) error {
+	if err := validateObjectName(name); err != nil {
+		return vterrors.Wrapf(err, "invalid workflow profile name")
+	}
+	return nil
+}
+
+// GetWorkflowProfilePath returns the node path of the named workflow profile.
+func GetWorkflowProfilePath(name string) string {
+	return path.Join(WorkflowProfilesPath, name)
+}
+
+// SaveWorkflowProfile creates the named workflow profile, or replaces it
+// if it already exists.
+func (ts *Server) SaveWorkflowProfile(ctx context.Context, name string, profile *vtctldatapb.WorkflowProfile) error {
+	if err := validateWorkflowProfileName(name); err != nil {
+		return err
+	}
+	data, err := profile.MarshalVT()
+	if err != nil {
+		return err
+	}
+	// A nil version creates the node if it does not exist.
+	_, err = ts.globalCell.Update(ctx, GetWorkflowProfilePath(name), data, nil)
+	return err
+}
+
+// GetWorkflowProfile returns the named workflow profile. It returns a
+// NoNode error if the profile does not exist.
+func (ts *Server) GetWorkflowProfile(ctx context.Context, name string) (*vtctldatapb.WorkflowProfile, error) {
+	if err := validateWorkflowProfileName(name); err != nil {
+		return nil, err
+	}
+	data, _, err := ts.globalCell.Get(ctx, GetWorkflowProfilePath(name))
+	if err != nil {
+		return nil, err
+	}
+	profile := &vtctldatapb.WorkflowProfile{}
+	if err := profile.UnmarshalVT(data); err != nil {
+		return nil, vterrors.Wrap(err, "bad workflow profile data")
+	}
+	return profile, nil
+}
+
+// DeleteWorkflowProfile deletes the named workflow profile.
+func (ts *Server) DeleteWorkflowProfile(ctx context.Context, name string) error {
+	if err := validateWorkflowProfileName(name); err != nil {
+		return err
+	}
+	return ts.globalCell.Delete(ctx, GetWorkflowProfilePath(name), nil)
+}
+
+// GetWorkflowProfileNames returns the names of the existing workflow
+// profiles, sorted.
+func (ts *Server) GetWorkflowProfileNames(ctx context.Context) ([]string, error) {
+	entries, err := ts.globalCell.ListDir(ctx, WorkflowProfilesPath, false /*full*/)
+	switch {
+	case IsErrType(err, NoNode):
+		return nil, nil
+	case err == nil:
+		return DirEntriesToStringArray(entries), nil
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestWorkflowProfiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	names, err := ts.GetWorkflowProfileNames(ctx)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	_, err = ts.GetWorkflowProfile(ctx, "bulk")
+	require.True(t, topo.IsErrType(err, topo.NoNode), "got error %v", err)
+
+	bulk := &vtctldatapb.WorkflowProfile{
+		TabletTypes:        []topodatapb.TabletType{topodatapb.TabletType_RDONLY},
+		OnDdl:              "STOP",
+		DeferSecondaryKeys: true,
+	}
+	require.NoError(t, ts.SaveWorkflowProfile(ctx, "bulk", bulk))
+	require.NoError(t, ts.SaveWorkflowProfile(ctx, "default", &vtctldatapb.WorkflowProfile{Cells: []string{"zone1"}}))
+	require.Error(t, ts.SaveWorkflowProfile(ctx, "", bulk))
+
+	profile, err := ts.GetWorkflowProfile(ctx, "bulk")
+	require.NoError(t, err)
+	utils.MustMatch(t, bulk, profile)
+
+	// Saving an existing profile replaces it.
+	bulk.OnDdl = "EXEC"
+	require.NoError(t, ts.SaveWorkflowProfile(ctx, "bulk", bulk))
+	profile, err = ts.GetWorkflowProfile(ctx, "bulk")
+	require.NoError(t, err)
+	require.Equal(t, "EXEC", profile.OnDdl)
+
+	names, err = ts.GetWorkflowProfileNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bulk", "default"}, names)
+
+	require.NoError(t, ts.DeleteWorkflowProfile(ctx, "bulk"))
+	names, err = ts.GetWorkflowProfileNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"default"}, names)
+	require.True(t, topo.IsErrType(ts.DeleteWorkflowProfile(ctx, "bulk"), topo.NoNode))
+}
+
+func TestWorkflowProfileNames(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	// Names that aren't object names could refer to other nodes of the global cell.
+	for _, name := range []string{"", "../keyspaces/ks/Keyspace", "a/b", "..", "bad name"} {
+		require.Error(t, ts.SaveWorkflowProfile(ctx, name, &vtctldatapb.WorkflowProfile{}), name)
+		_, err := ts.GetWorkflowProfile(ctx, name)
+		require.Error(t, err, name)
+		require.Error(t, ts.DeleteWorkflowProfile(ctx, name), name)
+	}
+	_, err := ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+}
@@ -159,5 +159,10 @@ func KeyspaceEquality(left, right *topodatapb.Keyspace) bool {
 		return false
 	}
 
+	if left.VtorcConfig.GetDisableDiscovery() != right.VtorcConfig.GetDisableDiscovery() ||
+		left.VtorcConfig.GetDisableRecovery() != right.VtorcConfig.GetDisableRecovery() {
+		return false
+	}
+
 	return left.DurabilityPolicy == right.DurabilityPolicy
 }
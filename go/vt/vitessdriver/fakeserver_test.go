@@ -152,6 +152,10 @@ func (f *fakeVTGateService) Prepare(ctx context.Context, session *vtgatepb.Sessi
 	return session, execCase.result.Fields, nil
 }
 
+func (f *fakeVTGateService) GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) ([]byte, error) {
+	return nil, nil
+}
+
 func (f *fakeVTGateService) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
 	return nil
 }
@@ -168,6 +172,10 @@ func (f *fakeVTGateService) VStream(ctx context.Context, tabletType topodatapb.T
 	return nil
 }
 
+func (f *fakeVTGateService) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	return nil, nil
+}
+
 // HandlePanic is part of the VTGateService interface
 func (f *fakeVTGateService) HandlePanic(err *error) {
 	if x := recover(); x != nil {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/pprof"
 	"net/url"
@@ -42,6 +43,7 @@ import (
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtadmin/audit"
 	"vitess.io/vitess/go/vt/vtadmin/cluster"
 	"vitess.io/vitess/go/vt/vtadmin/cluster/dynamic"
 	"vitess.io/vitess/go/vt/vtadmin/errors"
@@ -74,7 +76,9 @@ type API struct {
 	serv         *grpcserver.Server
 	router       *mux.Router
 
-	authz *rbac.Authorizer
+	authz         *rbac.Authorizer
+	audit         *audit.Logger
+	confirmations *confirmations
 
 	options Options
 
@@ -94,6 +98,13 @@ type Options struct {
 	// EnableDynamicClusters makes it so that clients can pass clusters dynamically
 	// in a session-like way, either via HTTP cookies or gRPC metadata.
 	EnableDynamicClusters bool
+	// AuditLog, if set, receives the audit log of the mutating tablet actions
+	// as JSON lines, in addition to the vtadmin log.
+	AuditLog io.Writer
+	// ConfirmationTTL is how long a tablet action which must be confirmed by
+	// a second actor waits for its confirmation. DefaultConfirmationTTL is
+	// used if it is not set.
+	ConfirmationTTL time.Duration
 }
 
 // NewAPI returns a new API, configured to service the given set of clusters,
@@ -141,10 +152,12 @@ func NewAPI(env *vtenv.Environment, clusters []*cluster.Cluster, opts Options) *
 	}
 
 	api := &API{
-		clusters:   clusters,
-		clusterMap: clusterMap,
-		authz:      authz,
-		env:        env,
+		clusters:      clusters,
+		clusterMap:    clusterMap,
+		authz:         authz,
+		audit:         audit.NewLogger(opts.AuditLog),
+		confirmations: newConfirmations(opts.ConfirmationTTL),
+		env:           env,
 	}
 
 	if opts.EnableDynamicClusters {
@@ -299,11 +312,13 @@ func (api *API) WithCluster(c *cluster.Cluster, id string) dynamic.API {
 	defer api.clusterMu.Unlock()
 
 	dynamicAPI := &API{
-		router:  api.router,
-		serv:    api.serv,
-		authz:   api.authz,
-		options: api.options,
-		env:     api.env,
+		router:        api.router,
+		serv:          api.serv,
+		authz:         api.authz,
+		audit:         api.audit,
+		confirmations: api.confirmations,
+		options:       api.options,
+		env:           api.env,
 	}
 
 	if c != nil {
@@ -368,6 +383,7 @@ func (api *API) Handler() http.Handler {
 	router.HandleFunc("/keyspace/{cluster_id}", httpAPI.Adapt(vtadminhttp.CreateKeyspace)).Name("API.CreateKeyspace").Methods("POST")
 	router.HandleFunc("/keyspace/{cluster_id}/{name}", httpAPI.Adapt(vtadminhttp.DeleteKeyspace)).Name("API.DeleteKeyspace").Methods("DELETE")
 	router.HandleFunc("/keyspace/{cluster_id}/{name}", httpAPI.Adapt(vtadminhttp.GetKeyspace)).Name("API.GetKeyspace")
+	router.HandleFunc("/keyspace/{cluster_id}/{name}/denied_tables", httpAPI.Adapt(vtadminhttp.GetDeniedTables)).Name("API.GetDeniedTables")
 	router.HandleFunc("/keyspace/{cluster_id}/{name}/rebuild_keyspace_graph", httpAPI.Adapt(vtadminhttp.RebuildKeyspaceGraph)).Name("API.RebuildKeyspaceGraph").Methods("PUT", "OPTIONS")
 	router.HandleFunc("/keyspace/{cluster_id}/{name}/remove_keyspace_cell", httpAPI.Adapt(vtadminhttp.RemoveKeyspaceCell)).Name("API.RemoveKeyspaceCell").Methods("PUT", "OPTIONS")
 	router.HandleFunc("/keyspace/{cluster_id}/{name}/validate", httpAPI.Adapt(vtadminhttp.ValidateKeyspace)).Name("API.ValidateKeyspace").Methods("PUT", "OPTIONS")
@@ -643,7 +659,25 @@ func (api *API) EmergencyFailoverShard(ctx context.Context, req *vtadminpb.Emerg
 		return nil, nil
 	}
 
-	return c.EmergencyFailoverShard(ctx, req.Options)
+	var resp *vtadminpb.EmergencyFailoverShardResponse
+	pendingConfirmationID, err := api.takeShardAction(ctx, "EmergencyFailoverShard", rbac.EmergencyFailoverShardAction, req.Options.GetKeyspace(), req.Options.GetShard(), c, req, func() (err error) {
+		resp, err = c.EmergencyFailoverShard(ctx, req.Options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if pendingConfirmationID != "" {
+		return &vtadminpb.EmergencyFailoverShardResponse{
+			Cluster:               c.ToProto(),
+			Keyspace:              req.Options.GetKeyspace(),
+			Shard:                 req.Options.GetShard(),
+			PendingConfirmationId: pendingConfirmationID,
+		}, nil
+	}
+
+	return resp, nil
 }
 
 // FindSchema is part of the vtadminpb.VTAdminServer interface.
@@ -882,6 +916,26 @@ func (api *API) GetClusters(ctx context.Context, req *vtadminpb.GetClustersReque
 	}, nil
 }
 
+// GetDeniedTables is part of the vtadminpb.VTAdminServer interface.
+func (api *API) GetDeniedTables(ctx context.Context, req *vtadminpb.GetDeniedTablesRequest) (*vtctldatapb.GetDeniedTablesResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "API.GetDeniedTables")
+	defer span.Finish()
+
+	c, err := api.getClusterForRequest(req.ClusterId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !api.authz.IsAuthorized(ctx, c.ID, rbac.ShardResource, rbac.GetAction) {
+		return nil, nil
+	}
+
+	return c.Vtctld.GetDeniedTables(ctx, &vtctldatapb.GetDeniedTablesRequest{
+		Keyspace: req.Keyspace,
+		Shards:   req.Shards,
+	})
+}
+
 // GetFullStatus is part of the vtadminpb.VTAdminServer interface.
 func (api *API) GetFullStatus(ctx context.Context, req *vtadminpb.GetFullStatusRequest) (*vtctldatapb.GetFullStatusResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "API.GetFullStatus")
@@ -1772,7 +1826,25 @@ func (api *API) PlannedFailoverShard(ctx context.Context, req *vtadminpb.Planned
 		return nil, nil
 	}
 
-	return c.PlannedFailoverShard(ctx, req.Options)
+	var resp *vtadminpb.PlannedFailoverShardResponse
+	pendingConfirmationID, err := api.takeShardAction(ctx, "PlannedFailoverShard", rbac.PlannedFailoverShardAction, req.Options.GetKeyspace(), req.Options.GetShard(), c, req, func() (err error) {
+		resp, err = c.PlannedFailoverShard(ctx, req.Options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if pendingConfirmationID != "" {
+		return &vtadminpb.PlannedFailoverShardResponse{
+			Cluster:               c.ToProto(),
+			Keyspace:              req.Options.GetKeyspace(),
+			Shard:                 req.Options.GetShard(),
+			PendingConfirmationId: pendingConfirmationID,
+		}, nil
+	}
+
+	return resp, nil
 }
 
 // RebuildKeyspaceGraph is a part of the vtadminpb.VTAdminServer interface.
@@ -1834,7 +1906,23 @@ func (api *API) RefreshTabletReplicationSource(ctx context.Context, req *vtadmin
 		return nil, err
 	}
 
-	return c.RefreshTabletReplicationSource(ctx, tablet)
+	var resp *vtadminpb.RefreshTabletReplicationSourceResponse
+	pendingConfirmationID, err := api.takeTabletAction(ctx, "RefreshTabletReplicationSource", rbac.RefreshTabletReplicationSourceAction, tablet, c, req, func() (err error) {
+		resp, err = c.RefreshTabletReplicationSource(ctx, tablet)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if pendingConfirmationID != "" {
+		return &vtadminpb.RefreshTabletReplicationSourceResponse{
+			Cluster:               c.ToProto(),
+			PendingConfirmationId: pendingConfirmationID,
+		}, nil
+	}
+
+	return resp, nil
 }
 
 // ReloadSchemas is part of the vtadminpb.VTAdminServer interface.
@@ -1994,15 +2082,22 @@ func (api *API) SetReadOnly(ctx context.Context, req *vtadminpb.SetReadOnlyReque
 		return nil, err
 	}
 
-	err = c.SetWritable(ctx, &vtctldatapb.SetWritableRequest{
-		TabletAlias: tablet.Tablet.Alias,
-		Writable:    false,
+	pendingConfirmationID, err := api.takeTabletAction(ctx, "SetReadOnly", rbac.ManageTabletWritabilityAction, tablet, c, req, func() error {
+		err := c.SetWritable(ctx, &vtctldatapb.SetWritableRequest{
+			TabletAlias: tablet.Tablet.Alias,
+			Writable:    false,
+		})
+		if err != nil {
+			return fmt.Errorf("Error setting tablet to read-only: %w", err)
+		}
+
+		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("Error setting tablet to read-only: %w", err)
+		return nil, err
 	}
 
-	return &vtadminpb.SetReadOnlyResponse{}, nil
+	return &vtadminpb.SetReadOnlyResponse{PendingConfirmationId: pendingConfirmationID}, nil
 }
 
 // SetReadWrite is part of the vtadminpb.VTAdminServer interface.
@@ -2015,15 +2110,22 @@ func (api *API) SetReadWrite(ctx context.Context, req *vtadminpb.SetReadWriteReq
 		return nil, err
 	}
 
-	err = c.SetWritable(ctx, &vtctldatapb.SetWritableRequest{
-		TabletAlias: tablet.Tablet.Alias,
-		Writable:    true,
+	pendingConfirmationID, err := api.takeTabletAction(ctx, "SetReadWrite", rbac.ManageTabletWritabilityAction, tablet, c, req, func() error {
+		err := c.SetWritable(ctx, &vtctldatapb.SetWritableRequest{
+			TabletAlias: tablet.Tablet.Alias,
+			Writable:    true,
+		})
+		if err != nil {
+			return fmt.Errorf("Error setting tablet to read-write: %w", err)
+		}
+
+		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("Error setting tablet to read-write: %w", err)
+		return nil, err
 	}
 
-	return &vtadminpb.SetReadWriteResponse{}, nil
+	return &vtadminpb.SetReadWriteResponse{PendingConfirmationId: pendingConfirmationID}, nil
 }
 
 // StartReplication is part of the vtadminpb.VTAdminServer interface.
@@ -2037,10 +2139,21 @@ func (api *API) StartReplication(ctx context.Context, req *vtadminpb.StartReplic
 	}
 
 	start := true
-	if err := c.ToggleTabletReplication(ctx, tablet, start); err != nil {
+	pendingConfirmationID, err := api.takeTabletAction(ctx, "StartReplication", rbac.ManageTabletReplicationAction, tablet, c, req, func() error {
+		return c.ToggleTabletReplication(ctx, tablet, start)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	if pendingConfirmationID != "" {
+		return &vtadminpb.StartReplicationResponse{
+			Status:                "pending_confirmation",
+			Cluster:               c.ToProto(),
+			PendingConfirmationId: pendingConfirmationID,
+		}, nil
+	}
+
 	return &vtadminpb.StartReplicationResponse{
 		Status:  "ok",
 		Cluster: c.ToProto(),
@@ -2058,10 +2171,21 @@ func (api *API) StopReplication(ctx context.Context, req *vtadminpb.StopReplicat
 	}
 
 	start := true
-	if err := c.ToggleTabletReplication(ctx, tablet, !start); err != nil {
+	pendingConfirmationID, err := api.takeTabletAction(ctx, "StopReplication", rbac.ManageTabletReplicationAction, tablet, c, req, func() error {
+		return c.ToggleTabletReplication(ctx, tablet, !start)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	if pendingConfirmationID != "" {
+		return &vtadminpb.StopReplicationResponse{
+			Status:                "pending_confirmation",
+			Cluster:               c.ToProto(),
+			PendingConfirmationId: pendingConfirmationID,
+		}, nil
+	}
+
 	return &vtadminpb.StopReplicationResponse{
 		Status:  "ok",
 		Cluster: c.ToProto(),
@@ -2078,7 +2202,26 @@ func (api *API) TabletExternallyPromoted(ctx context.Context, req *vtadminpb.Tab
 		return nil, err
 	}
 
-	return c.TabletExternallyPromoted(ctx, tablet)
+	var resp *vtadminpb.TabletExternallyPromotedResponse
+	pendingConfirmationID, err := api.takeShardAction(ctx, "TabletExternallyPromoted", rbac.TabletExternallyPromotedAction, tablet.Tablet.Keyspace, tablet.Tablet.Shard, c, req, func() (err error) {
+		resp, err = c.TabletExternallyPromoted(ctx, tablet)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if pendingConfirmationID != "" {
+		return &vtadminpb.TabletExternallyPromotedResponse{
+			Cluster:               c.ToProto(),
+			Keyspace:              tablet.Tablet.Keyspace,
+			Shard:                 tablet.Tablet.Shard,
+			NewPrimary:            tablet.Tablet.Alias,
+			PendingConfirmationId: pendingConfirmationID,
+		}, nil
+	}
+
+	return resp, nil
 }
 
 // Validate is part of the vtadminpb.VTAdminServer interface.
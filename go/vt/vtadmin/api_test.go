@@ -872,6 +872,65 @@ func TestGetClusters(t *testing.T) {
 	}
 }
 
+func TestGetDeniedTables(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expireTime := &vttime.Time{Seconds: 1718109296}
+	toposerver := memorytopo.NewServer(ctx, "zone0")
+	testutil.AddShards(ctx, t, toposerver, &vtctldatapb.Shard{
+		Keyspace: "testkeyspace",
+		Name:     "-",
+		Shard: &topodatapb.Shard{
+			TabletControls: []*topodatapb.Shard_TabletControl{{
+				TabletType:              topodatapb.TabletType_PRIMARY,
+				DeniedTables:            []string{"t1", "t2"},
+				DeniedTablesExpireTimes: map[string]*vttime.Time{"t1": expireTime},
+			}},
+		},
+	})
+	vtctldserver := testutil.NewVtctldServerWithTabletManagerClient(t, toposerver, &testutil.TabletManagerClient{}, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return grpcvtctldserver.NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.WithTestServer(t, vtctldserver, func(t *testing.T, vtctldClient vtctldclient.VtctldClient) {
+		clusters := []*cluster.Cluster{
+			vtadmintestutil.BuildCluster(t, vtadmintestutil.TestClusterConfig{
+				Cluster: &vtadminpb.Cluster{
+					Id:   "c0",
+					Name: "cluster0",
+				},
+				VtctldClient: vtctldClient,
+			}),
+		}
+
+		api := NewAPI(vtenv.NewTestEnv(), clusters, Options{})
+		resp, err := api.GetDeniedTables(ctx, &vtadminpb.GetDeniedTablesRequest{
+			ClusterId: "c0",
+			Keyspace:  "testkeyspace",
+		})
+		require.NoError(t, err)
+		expected := &vtctldatapb.GetDeniedTablesResponse{
+			DeniedTables: []*vtctldatapb.GetDeniedTablesResponse_ShardDeniedTables{{
+				Keyspace:    "testkeyspace",
+				Shard:       "-",
+				TabletType:  topodatapb.TabletType_PRIMARY,
+				Tables:      []string{"t1", "t2"},
+				ExpireTimes: map[string]*vttime.Time{"t1": expireTime},
+			}},
+		}
+		assert.Truef(t, proto.Equal(expected, resp), "expected %v, got %v", expected, resp)
+
+		_, err = api.GetDeniedTables(ctx, &vtadminpb.GetDeniedTablesRequest{
+			ClusterId: "doesnt-exist",
+			Keyspace:  "testkeyspace",
+		})
+		assert.Error(t, err)
+	})
+}
+
 func TestGetGates(t *testing.T) {
 	t.Parallel()
 
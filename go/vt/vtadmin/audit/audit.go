@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package audit records the mutating actions taken through the vtadmin API.
+
+Each action results in one or more events: one when the action is requested
+and must be confirmed by a second actor, and one with the outcome of the action
+once it is taken (or refused). Events are always written to the vtadmin log,
+and are additionally written as JSON lines to an optional writer, typically a
+file dedicated to the audit log.
+*/
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// Outcome is the result of an audited action.
+type Outcome string
+
+// Outcome definitions.
+const (
+	// PendingConfirmation is the outcome of an action which was not taken
+	// because it must first be confirmed by a second actor.
+	PendingConfirmation Outcome = "pending_confirmation"
+	// Refused is the outcome of an action which was not taken because its
+	// confirmation was invalid.
+	Refused Outcome = "refused"
+	// Succeeded is the outcome of an action which was taken successfully.
+	Succeeded Outcome = "succeeded"
+	// Failed is the outcome of an action which was taken and failed.
+	Failed Outcome = "failed"
+)
+
+// Event is a single entry of the audit log.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Actor is the name of the actor who took the action, or who requested
+	// it if the action is pending confirmation. It is empty for
+	// unauthenticated actors.
+	Actor     string `json:"actor"`
+	Cluster   string `json:"cluster"`
+	Resource  string `json:"resource"`
+	Action    string `json:"action"`
+	Operation string `json:"operation"`
+	Target    string `json:"target"`
+	// ConfirmationID is the id of the confirmation of the action, if it
+	// required one.
+	ConfirmationID string `json:"confirmation_id,omitempty"`
+	// RequestedBy is the name of the actor who requested the action, if it
+	// was confirmed by Actor.
+	RequestedBy string  `json:"requested_by,omitempty"`
+	Outcome     Outcome `json:"outcome"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Logger writes events to the audit log. A Logger is safe for concurrent use.
+type Logger struct {
+	m sync.Mutex
+	w io.Writer
+}
+
+// NewLogger returns a Logger writing events to the vtadmin log and, if w is
+// non-nil, as JSON lines to w.
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+// Record writes the event to the audit log. Its time is set to now if it is
+// not already set. Failing to write to the writer of the Logger is logged, and
+// does not fail the action.
+func (l *Logger) Record(event *Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	log.Infof("[audit]: actor=%q cluster=%s operation=%s target=%s confirmation_id=%s requested_by=%q outcome=%s error=%q",
+		event.Actor, event.Cluster, event.Operation, event.Target, event.ConfirmationID, event.RequestedBy, event.Outcome, event.Error)
+
+	if l.w == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("[audit]: failed to marshal event: %s", err)
+		return
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if _, err := l.w.Write(append(data, '\n')); err != nil {
+		log.Errorf("[audit]: failed to write event: %s", err)
+	}
+}
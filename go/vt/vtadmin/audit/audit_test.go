@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+
+	requestedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	logger.Record(&Event{
+		Time:           requestedAt,
+		Actor:          "alice",
+		Cluster:        "prod",
+		Resource:       "Tablet",
+		Action:         "manage_tablet_writability",
+		Operation:      "SetReadOnly",
+		Target:         "zone1-0000000100",
+		ConfirmationID: "abc",
+		Outcome:        PendingConfirmation,
+	})
+	logger.Record(&Event{
+		Actor:          "bob",
+		Cluster:        "prod",
+		Resource:       "Tablet",
+		Action:         "manage_tablet_writability",
+		Operation:      "SetReadOnly",
+		Target:         "zone1-0000000100",
+		ConfirmationID: "abc",
+		RequestedBy:    "alice",
+		Outcome:        Succeeded,
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var requested, confirmed Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &requested))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &confirmed))
+
+	assert.Equal(t, requestedAt, requested.Time)
+	assert.Equal(t, PendingConfirmation, requested.Outcome)
+	assert.Empty(t, requested.RequestedBy)
+	assert.NotContains(t, lines[0], "requested_by", "empty optional fields should be omitted")
+
+	assert.False(t, confirmed.Time.IsZero(), "time should be set when recording")
+	assert.Equal(t, "bob", confirmed.Actor)
+	assert.Equal(t, "alice", confirmed.RequestedBy)
+	assert.Equal(t, Succeeded, confirmed.Outcome)
+}
+
+func TestRecordWithoutWriter(t *testing.T) {
+	t.Parallel()
+
+	// Only logs, and must not panic.
+	NewLogger(nil).Record(&Event{Operation: "StartReplication", Outcome: Failed, Error: "boom"})
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtadmin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtadmin/audit"
+	"vitess.io/vitess/go/vt/vtadmin/cluster"
+	"vitess.io/vitess/go/vt/vtadmin/rbac"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtadminpb "vitess.io/vitess/go/vt/proto/vtadmin"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// DefaultConfirmationTTL is the default duration during which an action waits
+// for its confirmation by a second actor.
+const DefaultConfirmationTTL = 15 * time.Minute
+
+// pendingAction is an action requested by an actor, which must be confirmed by
+// a second actor before it is taken.
+type pendingAction struct {
+	id          string
+	requestedBy string
+	operation   string
+	clusterID   string
+	target      string
+	// fingerprint identifies the request of the action, so that the action
+	// which is confirmed is the one that was requested, with the same options.
+	fingerprint string
+	expiresAt   time.Time
+}
+
+// confirmations tracks the actions waiting for their confirmation. It is
+// shared by the API and all of its dynamic copies. The pending actions are
+// only kept in the memory of this vtadmin: they are lost when it restarts, and
+// an action must be confirmed through the same vtadmin that it was requested
+// from.
+type confirmations struct {
+	m       sync.Mutex
+	ttl     time.Duration
+	pending map[string]*pendingAction
+}
+
+func newConfirmations(ttl time.Duration) *confirmations {
+	if ttl <= 0 {
+		ttl = DefaultConfirmationTTL
+	}
+
+	return &confirmations{
+		ttl:     ttl,
+		pending: map[string]*pendingAction{},
+	}
+}
+
+// request records a new pending action, and returns it.
+func (c *confirmations) request(requestedBy string, operation string, clusterID string, target string, fingerprint string) (*pendingAction, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to generate confirmation id")
+	}
+
+	action := &pendingAction{
+		id:          hex.EncodeToString(buf),
+		requestedBy: requestedBy,
+		operation:   operation,
+		clusterID:   clusterID,
+		target:      target,
+		fingerprint: fingerprint,
+		expiresAt:   time.Now().Add(c.ttl),
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.expire()
+	c.pending[action.id] = action
+	return action, nil
+}
+
+// confirm removes the pending action with the given id and returns it, if the
+// action is the same one, on the same target and with the same request, and
+// the confirming actor is not the one who requested it.
+func (c *confirmations) confirm(id string, confirmedBy string, operation string, clusterID string, target string, fingerprint string) (*pendingAction, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.expire()
+
+	action, ok := c.pending[id]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no pending action with confirmation id %s; it may have expired", id)
+	}
+	if action.operation != operation || action.clusterID != clusterID || action.target != target {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "confirmation id %s is for %s on %s in cluster %s", id, action.operation, action.target, action.clusterID)
+	}
+	if action.fingerprint != fingerprint {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "confirmation id %s is for %s on %s with a different request", id, action.operation, action.target)
+	}
+	if action.requestedBy == confirmedBy {
+		return nil, vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "%s on %s must be confirmed by an actor other than %s", operation, target, confirmedBy)
+	}
+
+	delete(c.pending, id)
+	return action, nil
+}
+
+// expire removes the expired pending actions. Callers must hold the lock.
+func (c *confirmations) expire() {
+	now := time.Now()
+	for id, action := range c.pending {
+		if now.After(action.expiresAt) {
+			delete(c.pending, id)
+		}
+	}
+}
+
+// confirmableRequest is the request of an action which may have to be
+// confirmed by a second actor.
+type confirmableRequest interface {
+	proto.Message
+	GetConfirmationId() string
+}
+
+// requestFingerprint returns a hash of the request, without its confirmation
+// id, so that the request of the actor who confirms an action can be checked
+// against the request of the actor who requested it.
+func requestFingerprint(req confirmableRequest) (string, error) {
+	req = proto.Clone(req).(confirmableRequest)
+	m := req.ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("confirmation_id"); fd != nil {
+		m.Clear(fd)
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "failed to marshal the request")
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// takeTabletAction takes the given action on the tablet, as takeAction does.
+func (api *API) takeTabletAction(
+	ctx context.Context,
+	operation string,
+	action rbac.Action,
+	tablet *vtadminpb.Tablet,
+	c *cluster.Cluster,
+	req confirmableRequest,
+	take func() error,
+) (pendingConfirmationID string, err error) {
+	return api.takeAction(ctx, operation, rbac.TabletResource, action, c, topoproto.TabletAliasString(tablet.Tablet.Alias), req, take)
+}
+
+// takeShardAction takes the given action on the shard, as takeAction does.
+func (api *API) takeShardAction(
+	ctx context.Context,
+	operation string,
+	action rbac.Action,
+	keyspace string,
+	shard string,
+	c *cluster.Cluster,
+	req confirmableRequest,
+	take func() error,
+) (pendingConfirmationID string, err error) {
+	return api.takeAction(ctx, operation, rbac.ShardResource, action, c, topoproto.KeyspaceShardString(keyspace, shard), req, take)
+}
+
+// takeAction takes the given action on the target, and records it in the
+// audit log. If the RBAC config requires a confirmation for the action in the
+// target's cluster, and the request has no confirmation id, the action is not
+// taken: it is recorded as pending instead, and the id which a second actor
+// must pass, in an otherwise identical request, to confirm it is returned.
+// Callers must have checked that the actor is authorized to take the action.
+func (api *API) takeAction(
+	ctx context.Context,
+	operation string,
+	resource rbac.Resource,
+	action rbac.Action,
+	c *cluster.Cluster,
+	target string,
+	req confirmableRequest,
+	take func() error,
+) (pendingConfirmationID string, err error) {
+	var actorName string
+	actor, ok := rbac.FromContext(ctx)
+	if ok {
+		actorName = actor.Name
+	}
+
+	event := &audit.Event{
+		Actor:     actorName,
+		Cluster:   c.ID,
+		Resource:  string(resource),
+		Action:    string(action),
+		Operation: operation,
+		Target:    target,
+	}
+
+	if api.authz.RequiresConfirmation(c.ID, resource, action) {
+		if actor == nil {
+			err := vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "%s on %s must be confirmed by a second actor, which requires authentication", operation, event.Target)
+			event.Outcome, event.Error = audit.Refused, err.Error()
+			api.audit.Record(event)
+			return "", err
+		}
+
+		fingerprint, err := requestFingerprint(req)
+		if err != nil {
+			return "", err
+		}
+
+		confirmationID := req.GetConfirmationId()
+		if confirmationID == "" {
+			pending, err := api.confirmations.request(actorName, operation, c.ID, event.Target, fingerprint)
+			if err != nil {
+				return "", err
+			}
+
+			event.ConfirmationID, event.Outcome = pending.id, audit.PendingConfirmation
+			api.audit.Record(event)
+			return pending.id, nil
+		}
+
+		event.ConfirmationID = confirmationID
+		pending, err := api.confirmations.confirm(confirmationID, actorName, operation, c.ID, event.Target, fingerprint)
+		if err != nil {
+			event.Outcome, event.Error = audit.Refused, err.Error()
+			api.audit.Record(event)
+			return "", err
+		}
+
+		event.RequestedBy = pending.requestedBy
+	}
+
+	if err := take(); err != nil {
+		event.Outcome, event.Error = audit.Failed, err.Error()
+		api.audit.Record(event)
+		return "", err
+	}
+
+	event.Outcome = audit.Succeeded
+	api.audit.Record(event)
+	return "", nil
+}
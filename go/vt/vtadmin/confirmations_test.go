@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtadmin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtadmin"
+	"vitess.io/vitess/go/vt/vtadmin/audit"
+	"vitess.io/vitess/go/vt/vtadmin/rbac"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtadminpb "vitess.io/vitess/go/vt/proto/vtadmin"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// auditBuffer collects the audit log of an API.
+type auditBuffer struct {
+	m   sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *auditBuffer) Write(p []byte) (int, error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *auditBuffer) events(t *testing.T) []*audit.Event {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	var events []*audit.Event
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		event := &audit.Event{}
+		require.NoError(t, json.Unmarshal([]byte(line), event))
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func newConfirmationsTestAPI(t *testing.T, ttl time.Duration) (*vtadmin.API, *auditBuffer) {
+	opts := vtadmin.Options{
+		RBAC: &rbac.Config{
+			Rules: []*struct {
+				Resource string
+				Actions  []string
+				Subjects []string
+				Clusters []string
+			}{
+				{
+					Resource: "Tablet",
+					Actions:  []string{"manage_tablet_writability", "manage_tablet_replication"},
+					Subjects: []string{"user:alice", "user:bob"},
+					Clusters: []string{"*"},
+				},
+				{
+					Resource: "Shard",
+					Actions:  []string{"emergency_failover_shard"},
+					Subjects: []string{"user:alice", "user:bob"},
+					Clusters: []string{"*"},
+				},
+			},
+			Confirmations: []*struct {
+				Resource string
+				Actions  []string
+				Clusters []string
+			}{
+				{
+					Resource: "Tablet",
+					Actions:  []string{"manage_tablet_writability"},
+					Clusters: []string{"*"},
+				},
+				{
+					Resource: "Shard",
+					Actions:  []string{"emergency_failover_shard"},
+					Clusters: []string{"*"},
+				},
+			},
+		},
+		AuditLog:        &auditBuffer{},
+		ConfirmationTTL: ttl,
+	}
+	err := opts.RBAC.Reify()
+	require.NoError(t, err, "failed to reify authorization rules: %+v", opts.RBAC.Rules)
+
+	api := vtadmin.NewAPI(vtenv.NewTestEnv(), testClusters(t), opts)
+	t.Cleanup(func() {
+		if err := api.Close(); err != nil {
+			t.Logf("api did not close cleanly: %s", err.Error())
+		}
+	})
+
+	return api, opts.AuditLog.(*auditBuffer)
+}
+
+func TestTabletActionConfirmation(t *testing.T) {
+	t.Parallel()
+
+	api, auditLog := newConfirmationsTestAPI(t, 0)
+	alias := &topodatapb.TabletAlias{
+		Cell: "zone1",
+		Uid:  100,
+	}
+	alice := rbac.NewContext(context.Background(), &rbac.Actor{Name: "alice"})
+	bob := rbac.NewContext(context.Background(), &rbac.Actor{Name: "bob"})
+
+	resp, err := api.SetReadOnly(alice, &vtadminpb.SetReadOnlyRequest{Alias: alias})
+	require.NoError(t, err)
+	confirmationID := resp.PendingConfirmationId
+	require.NotEmpty(t, confirmationID, "SetReadOnly should wait for a confirmation")
+
+	_, err = api.SetReadOnly(alice, &vtadminpb.SetReadOnlyRequest{Alias: alias, ConfirmationId: confirmationID})
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "the requester cannot confirm its own action: %v", err)
+
+	_, err = api.SetReadWrite(bob, &vtadminpb.SetReadWriteRequest{Alias: alias, ConfirmationId: confirmationID})
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "the confirmation is for another operation: %v", err)
+
+	resp, err = api.SetReadOnly(bob, &vtadminpb.SetReadOnlyRequest{Alias: alias, ConfirmationId: confirmationID})
+	require.NoError(t, err)
+	assert.Empty(t, resp.PendingConfirmationId)
+
+	_, err = api.SetReadOnly(bob, &vtadminpb.SetReadOnlyRequest{Alias: alias, ConfirmationId: confirmationID})
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err), "a confirmation can only be used once: %v", err)
+
+	// StartReplication does not require a confirmation.
+	startResp, err := api.StartReplication(alice, &vtadminpb.StartReplicationRequest{Alias: alias})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", startResp.Status)
+	assert.Empty(t, startResp.PendingConfirmationId)
+
+	events := auditLog.events(t)
+	require.Len(t, events, 6)
+	expected := []struct {
+		actor       string
+		operation   string
+		requestedBy string
+		outcome     audit.Outcome
+	}{
+		{actor: "alice", operation: "SetReadOnly", outcome: audit.PendingConfirmation},
+		{actor: "alice", operation: "SetReadOnly", outcome: audit.Refused},
+		{actor: "bob", operation: "SetReadWrite", outcome: audit.Refused},
+		{actor: "bob", operation: "SetReadOnly", requestedBy: "alice", outcome: audit.Succeeded},
+		{actor: "bob", operation: "SetReadOnly", outcome: audit.Refused},
+		{actor: "alice", operation: "StartReplication", outcome: audit.Succeeded},
+	}
+	for i, want := range expected {
+		assert.Equal(t, want.actor, events[i].Actor, "event %d", i)
+		assert.Equal(t, want.operation, events[i].Operation, "event %d", i)
+		assert.Equal(t, want.requestedBy, events[i].RequestedBy, "event %d", i)
+		assert.Equal(t, want.outcome, events[i].Outcome, "event %d", i)
+		assert.Equal(t, "test", events[i].Cluster, "event %d", i)
+		assert.Equal(t, "zone1-0000000100", events[i].Target, "event %d", i)
+	}
+	assert.Equal(t, confirmationID, events[3].ConfirmationID)
+}
+
+func TestShardActionConfirmation(t *testing.T) {
+	t.Parallel()
+
+	api, auditLog := newConfirmationsTestAPI(t, 0)
+	options := &vtctldatapb.EmergencyReparentShardRequest{
+		Keyspace: "test",
+		Shard:    "-",
+	}
+	alice := rbac.NewContext(context.Background(), &rbac.Actor{Name: "alice"})
+	bob := rbac.NewContext(context.Background(), &rbac.Actor{Name: "bob"})
+
+	resp, err := api.EmergencyFailoverShard(alice, &vtadminpb.EmergencyFailoverShardRequest{ClusterId: "test", Options: options})
+	require.NoError(t, err)
+	confirmationID := resp.PendingConfirmationId
+	require.NotEmpty(t, confirmationID, "EmergencyFailoverShard should wait for a confirmation")
+
+	_, err = api.EmergencyFailoverShard(alice, &vtadminpb.EmergencyFailoverShardRequest{ClusterId: "test", Options: options, ConfirmationId: confirmationID})
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err), "the requester cannot confirm its own action: %v", err)
+
+	otherOptions := &vtctldatapb.EmergencyReparentShardRequest{
+		Keyspace:   "test",
+		Shard:      "-",
+		NewPrimary: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+	}
+	_, err = api.EmergencyFailoverShard(bob, &vtadminpb.EmergencyFailoverShardRequest{ClusterId: "test", Options: otherOptions, ConfirmationId: confirmationID})
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "the confirmer cannot change the options of the action: %v", err)
+
+	resp, err = api.EmergencyFailoverShard(bob, &vtadminpb.EmergencyFailoverShardRequest{ClusterId: "test", Options: options, ConfirmationId: confirmationID})
+	require.NoError(t, err)
+	assert.Empty(t, resp.PendingConfirmationId)
+
+	events := auditLog.events(t)
+	require.Len(t, events, 4)
+	expected := []struct {
+		actor       string
+		requestedBy string
+		outcome     audit.Outcome
+	}{
+		{actor: "alice", outcome: audit.PendingConfirmation},
+		{actor: "alice", outcome: audit.Refused},
+		{actor: "bob", outcome: audit.Refused},
+		{actor: "bob", requestedBy: "alice", outcome: audit.Succeeded},
+	}
+	for i, want := range expected {
+		assert.Equal(t, want.actor, events[i].Actor, "event %d", i)
+		assert.Equal(t, "EmergencyFailoverShard", events[i].Operation, "event %d", i)
+		assert.Equal(t, want.requestedBy, events[i].RequestedBy, "event %d", i)
+		assert.Equal(t, want.outcome, events[i].Outcome, "event %d", i)
+		assert.Equal(t, "Shard", events[i].Resource, "event %d", i)
+		assert.Equal(t, "test/-", events[i].Target, "event %d", i)
+	}
+}
+
+func TestTabletActionConfirmationExpired(t *testing.T) {
+	t.Parallel()
+
+	api, _ := newConfirmationsTestAPI(t, time.Millisecond)
+	alias := &topodatapb.TabletAlias{
+		Cell: "zone1",
+		Uid:  100,
+	}
+
+	resp, err := api.SetReadWrite(rbac.NewContext(context.Background(), &rbac.Actor{Name: "alice"}), &vtadminpb.SetReadWriteRequest{Alias: alias})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.PendingConfirmationId)
+
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = api.SetReadWrite(rbac.NewContext(context.Background(), &rbac.Actor{Name: "bob"}), &vtadminpb.SetReadWriteRequest{Alias: alias, ConfirmationId: resp.PendingConfirmationId})
+	assert.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err), "the confirmation should have expired: %v", err)
+}
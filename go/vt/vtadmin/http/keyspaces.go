@@ -78,6 +78,19 @@ func GetKeyspace(ctx context.Context, r Request, api *API) *JSONResponse {
 	return NewJSONResponse(keyspace, err)
 }
 
+// GetDeniedTables implements the http wrapper for
+// /keyspace/{cluster_id}/{name}/denied_tables[?shard=[&shard=]].
+func GetDeniedTables(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := mux.Vars(r.Request)
+	deniedTables, err := api.server.GetDeniedTables(ctx, &vtadminpb.GetDeniedTablesRequest{
+		ClusterId: vars["cluster_id"],
+		Keyspace:  vars["name"],
+		Shards:    r.URL.Query()["shard"],
+	})
+
+	return NewJSONResponse(deniedTables, err)
+}
+
 // GetKeyspaces implements the http wrapper for /keyspaces[?cluster_id=[&cluster_id=]].
 func GetKeyspaces(ctx context.Context, r Request, api *API) *JSONResponse {
 	keyspaces, err := api.server.GetKeyspaces(ctx, &vtadminpb.GetKeyspacesRequest{
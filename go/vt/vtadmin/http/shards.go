@@ -107,7 +107,9 @@ func DeleteShards(ctx context.Context, r Request, api *API) *JSONResponse {
 // EmergencyFailoverShard implements the http wrapper for
 // POST /shard/{cluster_id}/{keyspace}/{shard}/emergency_failover.
 //
-// Query params: none
+// Query params:
+//   - confirmation_id: the id of the pending action to confirm, if the action
+//     must be confirmed by a second actor.
 //
 // POST body is unmarshalled as vtctldatapb.EmergencyReparentShardRequest, but
 // the Keyspace and Shard fields are ignored (coming instead from the route).
@@ -127,8 +129,9 @@ func EmergencyFailoverShard(ctx context.Context, r Request, api *API) *JSONRespo
 	options.Shard = vars["shard"]
 
 	result, err := api.server.EmergencyFailoverShard(ctx, &vtadminpb.EmergencyFailoverShardRequest{
-		ClusterId: vars["cluster_id"],
-		Options:   &options,
+		ClusterId:      vars["cluster_id"],
+		Options:        &options,
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 	return NewJSONResponse(result, err)
 }
@@ -136,7 +139,9 @@ func EmergencyFailoverShard(ctx context.Context, r Request, api *API) *JSONRespo
 // PlannedFailoverShard implements the http wrapper for
 // POST /shard/{cluster_id}/{keyspace}/{shard}/planned_failover.
 //
-// Query params: none
+// Query params:
+//   - confirmation_id: the id of the pending action to confirm, if the action
+//     must be confirmed by a second actor.
 //
 // POST body is unmarshalled as vtctldatapb.PlannedReparentShardRequest, but
 // the Keyspace and Shard fields are ignored (coming instead from the route).
@@ -156,8 +161,9 @@ func PlannedFailoverShard(ctx context.Context, r Request, api *API) *JSONRespons
 	options.Shard = vars["shard"]
 
 	result, err := api.server.PlannedFailoverShard(ctx, &vtadminpb.PlannedFailoverShardRequest{
-		ClusterId: vars["cluster_id"],
-		Options:   &options,
+		ClusterId:      vars["cluster_id"],
+		Options:        &options,
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 	return NewJSONResponse(result, err)
 }
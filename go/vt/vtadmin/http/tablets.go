@@ -126,6 +126,8 @@ func RefreshState(ctx context.Context, r Request, api *API) *JSONResponse {
 // Query params:
 //   - cluster_id: repeatable, list of cluster IDs to restrict to when searching fo
 //     a tablet with that alias.
+//   - confirmation_id: the id of the pending action to confirm, if the action
+//     must be confirmed by a second actor.
 //
 // PUT body is unused; this endpoint takes no additional options.
 func RefreshTabletReplicationSource(ctx context.Context, r Request, api *API) *JSONResponse {
@@ -137,8 +139,9 @@ func RefreshTabletReplicationSource(ctx context.Context, r Request, api *API) *J
 	}
 
 	result, err := api.server.RefreshTabletReplicationSource(ctx, &vtadminpb.RefreshTabletReplicationSourceRequest{
-		Alias:      alias,
-		ClusterIds: r.URL.Query()["cluster_id"],
+		Alias:          alias,
+		ClusterIds:     r.URL.Query()["cluster_id"],
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 
 	return NewJSONResponse(result, err)
@@ -162,6 +165,9 @@ func RunHealthCheck(ctx context.Context, r Request, api *API) *JSONResponse {
 }
 
 // SetReadOnly sets the tablet to read only mode
+//
+// If the action must be confirmed by a second actor, it is only taken when the
+// confirmation_id query param is set to the id returned to the first actor.
 func SetReadOnly(ctx context.Context, r Request, api *API) *JSONResponse {
 	vars := r.Vars()
 
@@ -171,14 +177,18 @@ func SetReadOnly(ctx context.Context, r Request, api *API) *JSONResponse {
 	}
 
 	result, err := api.server.SetReadOnly(ctx, &vtadminpb.SetReadOnlyRequest{
-		Alias:      alias,
-		ClusterIds: r.URL.Query()["cluster_id"],
+		Alias:          alias,
+		ClusterIds:     r.URL.Query()["cluster_id"],
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 
 	return NewJSONResponse(result, err)
 }
 
 // SetReadWrite sets the tablet to read write mode
+//
+// If the action must be confirmed by a second actor, it is only taken when the
+// confirmation_id query param is set to the id returned to the first actor.
 func SetReadWrite(ctx context.Context, r Request, api *API) *JSONResponse {
 	vars := r.Vars()
 
@@ -188,14 +198,18 @@ func SetReadWrite(ctx context.Context, r Request, api *API) *JSONResponse {
 	}
 
 	result, err := api.server.SetReadWrite(ctx, &vtadminpb.SetReadWriteRequest{
-		Alias:      alias,
-		ClusterIds: r.URL.Query()["cluster_id"],
+		Alias:          alias,
+		ClusterIds:     r.URL.Query()["cluster_id"],
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 
 	return NewJSONResponse(result, err)
 }
 
 // StartReplication starts replication on the specified tablet.
+//
+// If the action must be confirmed by a second actor, it is only taken when the
+// confirmation_id query param is set to the id returned to the first actor.
 func StartReplication(ctx context.Context, r Request, api *API) *JSONResponse {
 	vars := r.Vars()
 
@@ -205,14 +219,18 @@ func StartReplication(ctx context.Context, r Request, api *API) *JSONResponse {
 	}
 
 	result, err := api.server.StartReplication(ctx, &vtadminpb.StartReplicationRequest{
-		Alias:      alias,
-		ClusterIds: r.URL.Query()["cluster_id"],
+		Alias:          alias,
+		ClusterIds:     r.URL.Query()["cluster_id"],
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 
 	return NewJSONResponse(result, err)
 }
 
 // StopReplication stops replication on the specified tablet.
+//
+// If the action must be confirmed by a second actor, it is only taken when the
+// confirmation_id query param is set to the id returned to the first actor.
 func StopReplication(ctx context.Context, r Request, api *API) *JSONResponse {
 	vars := r.Vars()
 
@@ -222,8 +240,9 @@ func StopReplication(ctx context.Context, r Request, api *API) *JSONResponse {
 	}
 
 	result, err := api.server.StopReplication(ctx, &vtadminpb.StopReplicationRequest{
-		Alias:      alias,
-		ClusterIds: r.URL.Query()["cluster_id"],
+		Alias:          alias,
+		ClusterIds:     r.URL.Query()["cluster_id"],
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 
 	return NewJSONResponse(result, err)
@@ -234,6 +253,8 @@ func StopReplication(ctx context.Context, r Request, api *API) *JSONResponse {
 //
 // Query params:
 // - `cluster_id`: repeated list of clusterIDs to limit the request to.
+// - `confirmation_id`: the id of the pending action to confirm, if the action
+// must be confirmed by a second actor.
 //
 // POST body is unused; this endpoint takes no additional options.
 func TabletExternallyPromoted(ctx context.Context, r Request, api *API) *JSONResponse {
@@ -245,8 +266,9 @@ func TabletExternallyPromoted(ctx context.Context, r Request, api *API) *JSONRes
 	}
 
 	result, err := api.server.TabletExternallyPromoted(ctx, &vtadminpb.TabletExternallyPromotedRequest{
-		Alias:      alias,
-		ClusterIds: r.URL.Query()["cluster_id"],
+		Alias:          alias,
+		ClusterIds:     r.URL.Query()["cluster_id"],
+		ConfirmationId: r.URL.Query().Get("confirmation_id"),
 	})
 	return NewJSONResponse(result, err)
 }
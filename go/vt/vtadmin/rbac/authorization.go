@@ -25,6 +25,9 @@ import (
 type Authorizer struct {
 	// keyed by resource name
 	policies map[string][]*Rule
+	// keyed by resource name, the actions which must be confirmed by a second
+	// actor.
+	confirmations map[string][]*Rule
 }
 
 // NewAuthorizer returns a new Authorizer based on the given Config, which
@@ -54,7 +57,8 @@ func NewAuthorizer(cfg *Config) (*Authorizer, error) {
 	}
 
 	return &Authorizer{
-		policies: cfg.cfg,
+		policies:      cfg.cfg,
+		confirmations: cfg.confirmations,
 	}, nil
 }
 
@@ -81,3 +85,17 @@ func (authz *Authorizer) IsAuthorized(ctx context.Context, clusterID string, res
 
 	return false
 }
+
+// RequiresConfirmation returns whether the given action on the given resource in
+// the given cluster must be confirmed by a second actor before it is taken.
+func (authz *Authorizer) RequiresConfirmation(clusterID string, resource Resource, action Action) bool {
+	for _, key := range []string{"*", string(resource)} {
+		for _, rule := range authz.confirmations[key] {
+			if rule.Allows(clusterID, action, nil) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
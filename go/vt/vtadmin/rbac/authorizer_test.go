@@ -114,3 +114,87 @@ func TestIsAuthorized(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiresConfirmation(t *testing.T) {
+	t.Parallel()
+
+	authz, err := NewAuthorizer(&Config{
+		Confirmations: []*struct {
+			Resource string
+			Actions  []string
+			Clusters []string
+		}{
+			{
+				Resource: string(TabletResource),
+				Actions:  []string{string(ManageTabletWritabilityAction), string(ManageTabletReplicationAction)},
+				Clusters: []string{"prod"},
+			},
+			{
+				Resource: "*",
+				Actions:  []string{string(DeleteAction)},
+				Clusters: []string{"*"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                 string
+		clusterID            string
+		resource             Resource
+		action               Action
+		requiresConfirmation bool
+	}{
+		{
+			name:                 "confirmed action",
+			clusterID:            "prod",
+			resource:             TabletResource,
+			action:               ManageTabletWritabilityAction,
+			requiresConfirmation: true,
+		},
+		{
+			name:      "other cluster",
+			clusterID: "dev",
+			resource:  TabletResource,
+			action:    ManageTabletWritabilityAction,
+		},
+		{
+			name:      "other action",
+			clusterID: "prod",
+			resource:  TabletResource,
+			action:    RefreshTabletReplicationSourceAction,
+		},
+		{
+			name:                 "resource wildcard",
+			clusterID:            "dev",
+			resource:             KeyspaceResource,
+			action:               DeleteAction,
+			requiresConfirmation: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.requiresConfirmation, authz.RequiresConfirmation(tt.clusterID, tt.resource, tt.action))
+		})
+	}
+
+	t.Run("invalid confirmation", func(t *testing.T) {
+		_, err := NewAuthorizer(&Config{
+			Confirmations: []*struct {
+				Resource string
+				Actions  []string
+				Clusters []string
+			}{
+				{
+					Resource: string(TabletResource),
+					Actions:  []string{"*", string(ManageTabletWritabilityAction)},
+					Clusters: []string{"*"},
+				},
+			},
+		})
+		assert.ErrorContains(t, err, "confirmation 0: actions list cannot include wildcard")
+	})
+}
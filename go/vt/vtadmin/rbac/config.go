@@ -38,10 +38,19 @@ type Config struct {
 		Subjects []string
 		Clusters []string
 	}
+	// Confirmations lists the actions which must be confirmed by a second
+	// actor before they are taken. The actor confirming the action must also
+	// be authorized to take it.
+	Confirmations []*struct {
+		Resource string
+		Actions  []string
+		Clusters []string
+	}
 
 	reified bool
 
 	cfg           map[string][]*Rule
+	confirmations map[string][]*Rule
 	authenticator Authenticator
 	authorizer    *Authorizer
 }
@@ -115,15 +124,37 @@ func (c *Config) Reify() error {
 		byResource[rule.Resource] = resourceRules
 	}
 
+	// reify the confirmations, as rules allowing any subject
+	confirmationsByResource := map[string][]*Rule{}
+	for i, confirmation := range c.Confirmations {
+		actions := sets.New[string](confirmation.Actions...)
+		if actions.Has("*") && actions.Len() > 1 {
+			rec.RecordError(fmt.Errorf("confirmation %d: actions list cannot include wildcard and other actions, have %v", i, sets.List(actions)))
+		}
+
+		clusters := sets.New[string](confirmation.Clusters...)
+		if clusters.Has("*") && clusters.Len() > 1 {
+			rec.RecordError(fmt.Errorf("confirmation %d: clusters list cannot include wildcard and other clusters, have %v", i, sets.List(clusters)))
+		}
+
+		confirmationsByResource[confirmation.Resource] = append(confirmationsByResource[confirmation.Resource], &Rule{
+			actions:  actions,
+			subjects: sets.New[string]("*"),
+			clusters: clusters,
+		})
+	}
+
 	if rec.HasErrors() {
 		return rec.Error()
 	}
 
-	log.Infof("[rbac]: loaded authorizer with %d rules", len(c.Rules))
+	log.Infof("[rbac]: loaded authorizer with %d rules and %d confirmations", len(c.Rules), len(c.Confirmations))
 
 	c.cfg = byResource
+	c.confirmations = confirmationsByResource
 	c.authorizer = &Authorizer{
-		policies: c.cfg,
+		policies:      c.cfg,
+		confirmations: c.confirmations,
 	}
 
 	// reify the authenticator
@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcombo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	// failoverSimulation is the failover simulation, if enabled.
+	failoverSimulation *FailoverSimulation
+
+	simulatedFailovers = stats.NewCountersWithSingleLabel(
+		"FailoverSimulationFailovers",
+		"Number of primaries replaced by the failover simulation, per keyspace/shard",
+		"KeyspaceShard")
+)
+
+// FailoverSimulation is a small vtorc embedded in vtcombo, so that the
+// applications can test how they handle failovers locally. Since all the
+// tablets of vtcombo share a single MySQL, their replication is simulated,
+// and faults are injected into the tablets: a killed tablet stops serving,
+// and a replica whose replication is stalled lags more and more. Like vtorc,
+// the simulation replaces the primaries that stopped serving for a while by
+// their most up to date replica.
+type FailoverSimulation struct {
+	delay time.Duration
+	ticks *timer.Timer
+
+	mu sync.Mutex
+	// unavailableSince are the times the primaries of the shards were first
+	// seen not serving, per keyspace/shard.
+	unavailableSince map[string]time.Time
+}
+
+// EnableFailoverSimulation enables the failover simulation, that replaces
+// the primaries that don't serve for the given delay. It must be called
+// before the tablets are created, and the simulation started once they are.
+func EnableFailoverSimulation(delay, interval time.Duration) *FailoverSimulation {
+	failoverSimulation = &FailoverSimulation{
+		delay:            delay,
+		ticks:            timer.NewTimer(interval),
+		unavailableSince: make(map[string]time.Time),
+	}
+	return failoverSimulation
+}
+
+// Start starts checking the primaries in the background.
+func (fs *FailoverSimulation) Start() {
+	fs.ticks.Start(func() {
+		fs.check(context.Background(), time.Now())
+	})
+}
+
+// Stop stops checking the primaries.
+func (fs *FailoverSimulation) Stop() {
+	fs.ticks.Stop()
+}
+
+// check replaces the primaries that didn't serve for the failover delay.
+func (fs *FailoverSimulation) check(ctx context.Context, now time.Time) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for keyspaceShard, tablets := range tabletsByShard() {
+		primary := shardPrimary(tablets)
+		if primary == nil || primary.qsc.IsServing() {
+			delete(fs.unavailableSince, keyspaceShard)
+			continue
+		}
+		since, ok := fs.unavailableSince[keyspaceShard]
+		if !ok {
+			log.Infof("Failover simulation: primary %v of %v is not serving", topoproto.TabletAliasString(primary.alias), keyspaceShard)
+			fs.unavailableSince[keyspaceShard] = now
+			continue
+		}
+		if now.Sub(since) < fs.delay {
+			continue
+		}
+		candidate := promotionCandidate(tablets)
+		if candidate == nil {
+			log.Warningf("Failover simulation: no replica of %v can be promoted", keyspaceShard)
+			continue
+		}
+		log.Infof("Failover simulation: promoting %v to replace the primary %v of %v", topoproto.TabletAliasString(candidate.alias), topoproto.TabletAliasString(primary.alias), keyspaceShard)
+		// Like an external reparent, the new primary takes over the shard with
+		// a newer primary term, while the old primary stays down.
+		if err := candidate.tm.ChangeType(ctx, topodatapb.TabletType_PRIMARY, false /* semiSync */); err != nil {
+			log.Errorf("Failover simulation: failed to promote %v: %v", topoproto.TabletAliasString(candidate.alias), err)
+			continue
+		}
+		delete(fs.unavailableSince, keyspaceShard)
+		simulatedFailovers.Add(keyspaceShard, 1)
+	}
+}
+
+// tabletsByShard returns the tablets per keyspace/shard.
+func tabletsByShard() map[string][]*comboTablet {
+	tabletMapMu.RLock()
+	defer tabletMapMu.RUnlock()
+	shards := make(map[string][]*comboTablet)
+	for _, tablet := range tabletMap {
+		keyspaceShard := topoproto.KeyspaceShardString(tablet.keyspace, tablet.shard)
+		shards[keyspaceShard] = append(shards[keyspaceShard], tablet)
+	}
+	return shards
+}
+
+// shardPrimary returns the primary of the shard, with the latest primary
+// term, if any.
+func shardPrimary(tablets []*comboTablet) *comboTablet {
+	var primary *comboTablet
+	var termStart time.Time
+	for _, tablet := range tablets {
+		record := tablet.tm.Tablet()
+		if record.Type != topodatapb.TabletType_PRIMARY {
+			continue
+		}
+		if start := protoutil.TimeFromProto(record.PrimaryTermStartTime); primary == nil || start.After(termStart) {
+			primary, termStart = tablet, start
+		}
+	}
+	return primary
+}
+
+// promotionCandidate returns the serving replica of the shard that lags
+// the least, if any.
+func promotionCandidate(tablets []*comboTablet) *comboTablet {
+	var candidates []*comboTablet
+	for _, tablet := range tablets {
+		if tablet.tm.Tablet().Type == topodatapb.TabletType_REPLICA && !tablet.killed.Load() && tablet.qsc.IsServing() {
+			candidates = append(candidates, tablet)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if lagI, lagJ := candidates[i].replication.lag(), candidates[j].replication.lag(); lagI != lagJ {
+			return lagI < lagJ
+		}
+		return candidates[i].uid < candidates[j].uid
+	})
+	return candidates[0]
+}
+
+// findTablet returns the tablet with the given alias.
+func findTablet(alias string) (*comboTablet, error) {
+	tabletAlias, err := topoproto.ParseTabletAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+	tabletMapMu.RLock()
+	defer tabletMapMu.RUnlock()
+	tablet, ok := tabletMap[tabletAlias.Uid]
+	if !ok || !topoproto.TabletAliasEqual(tablet.alias, tabletAlias) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "unknown tablet %v", alias)
+	}
+	return tablet, nil
+}
+
+// KillPrimary kills the primary of the shard: it stops serving until it is
+// revived. It returns the alias of the killed primary.
+func (fs *FailoverSimulation) KillPrimary(keyspace, shard string) (string, error) {
+	primary := shardPrimary(tabletsByShard()[topoproto.KeyspaceShardString(keyspace, shard)])
+	if primary == nil {
+		return "", vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "no primary for %v/%v", keyspace, shard)
+	}
+	alias := topoproto.TabletAliasString(primary.alias)
+	return alias, fs.KillTablet(alias)
+}
+
+// KillTablet kills the tablet: it stops serving until it is revived.
+func (fs *FailoverSimulation) KillTablet(alias string) error {
+	tablet, err := findTablet(alias)
+	if err != nil {
+		return err
+	}
+	record := tablet.tm.Tablet()
+	tablet.killed.Store(true)
+	return tablet.qsc.SetServingType(record.Type, protoutil.TimeFromProto(record.PrimaryTermStartTime).UTC(), false, "killed by the failover simulation")
+}
+
+// ReviveTablet brings a killed tablet back. A primary that was replaced in
+// the meantime comes back as a replica of the new primary.
+func (fs *FailoverSimulation) ReviveTablet(ctx context.Context, alias string) error {
+	tablet, err := findTablet(alias)
+	if err != nil {
+		return err
+	}
+	tablet.killed.Store(false)
+	if tablet.tm.Tablet().Type == topodatapb.TabletType_PRIMARY {
+		primary := shardPrimary(tabletsByShard()[topoproto.KeyspaceShardString(tablet.keyspace, tablet.shard)])
+		if primary != tablet {
+			return tablet.tm.ChangeType(ctx, topodatapb.TabletType_REPLICA, false /* semiSync */)
+		}
+	}
+	return tablet.tm.RefreshState(ctx)
+}
+
+// StallReplication stalls or resumes the replication of the tablet. While
+// its replication is stalled, the lag of a replica grows.
+func (fs *FailoverSimulation) StallReplication(alias string, stalled bool) error {
+	tablet, err := findTablet(alias)
+	if err != nil {
+		return err
+	}
+	tablet.replication.stall(stalled, time.Now())
+	return nil
+}
+
+// simulatedReplication is the MySQL of a tablet, whose replication is
+// simulated: it is healthy, unless it is stalled.
+type simulatedReplication struct {
+	mysqlctl.MysqlDaemon
+
+	// stalledSince is the unix time, in nanoseconds, since which the
+	// replication is stalled, or zero.
+	stalledSince atomic.Int64
+}
+
+// stall stalls or resumes the replication.
+func (sr *simulatedReplication) stall(stalled bool, now time.Time) {
+	if !stalled {
+		sr.stalledSince.Store(0)
+		return
+	}
+	sr.stalledSince.CompareAndSwap(0, now.UnixNano())
+}
+
+// lag returns the replication lag.
+func (sr *simulatedReplication) lag() time.Duration {
+	if sr == nil {
+		return 0
+	}
+	stalledSince := sr.stalledSince.Load()
+	if stalledSince == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, stalledSince))
+}
+
+// ReplicationStatus implements the MysqlDaemon interface.
+func (sr *simulatedReplication) ReplicationStatus() (replication.ReplicationStatus, error) {
+	return replication.ReplicationStatus{
+		IOState:               replication.ReplicationStateRunning,
+		SQLState:              replication.ReplicationStateRunning,
+		ReplicationLagSeconds: uint32(sr.lag().Seconds()),
+	}, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcombo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulatedReplication(t *testing.T) {
+	sr := &simulatedReplication{}
+	status, err := sr.ReplicationStatus()
+	require.NoError(t, err)
+	assert.True(t, status.Healthy())
+	assert.Zero(t, status.ReplicationLagSeconds)
+
+	// A stalled replication lags since it stalled.
+	sr.stall(true, time.Now().Add(-time.Minute))
+	sr.stall(true, time.Now())
+	status, err = sr.ReplicationStatus()
+	require.NoError(t, err)
+	assert.True(t, status.Healthy())
+	assert.GreaterOrEqual(t, status.ReplicationLagSeconds, uint32(60))
+
+	sr.stall(false, time.Now())
+	assert.Zero(t, sr.lag())
+
+	// The tablets without simulated replication don't lag.
+	assert.Zero(t, (*simulatedReplication)(nil).lag())
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -68,10 +70,21 @@ type comboTablet struct {
 	// objects built at construction time
 	qsc tabletserver.Controller
 	tm  *tabletmanager.TabletManager
+
+	// replication simulates the replication of the tablet, if the
+	// failover simulation is enabled.
+	replication *simulatedReplication
+	// killed is set while the tablet is killed by the failover simulation.
+	killed atomic.Bool
 }
 
-// tabletMap maps the tablet uid to the tablet record
-var tabletMap map[uint32]*comboTablet
+var (
+	// tabletMap maps the tablet uid to the tablet record
+	tabletMap map[uint32]*comboTablet
+	// tabletMapMu protects the changes of tabletMap, against the failover
+	// simulation that runs in the background.
+	tabletMapMu sync.RWMutex
+)
 
 // CreateTablet creates an individual tablet, with its tm, and adds
 // it to the map. If it's a primary tablet, it also issues a TER.
@@ -102,6 +115,11 @@ func CreateTablet(
 	if err != nil {
 		return err
 	}
+	var replication *simulatedReplication
+	if failoverSimulation != nil {
+		replication = &simulatedReplication{MysqlDaemon: mysqld}
+		mysqld = replication
+	}
 	tm := &tabletmanager.TabletManager{
 		BatchCtx:            context.Background(),
 		Env:                 env,
@@ -134,6 +152,8 @@ func CreateTablet(
 	}
 	controller.AddStatusHeader()
 	controller.AddStatusPart()
+	tabletMapMu.Lock()
+	defer tabletMapMu.Unlock()
 	tabletMap[uid] = &comboTablet{
 		alias:      alias,
 		keyspace:   keyspace,
@@ -142,8 +162,9 @@ func CreateTablet(
 		dbname:     dbname,
 		uid:        uid,
 
-		qsc: controller,
-		tm:  tm,
+		qsc:         controller,
+		tm:          tm,
+		replication: replication,
 	}
 	return nil
 }
@@ -177,7 +198,9 @@ func InitTabletMap(
 	ensureDatabase bool,
 	srvTopoCounts *stats.CountersWithSingleLabel,
 ) (uint32, error) {
+	tabletMapMu.Lock()
 	tabletMap = make(map[uint32]*comboTablet)
+	tabletMapMu.Unlock()
 
 	ctx := context.Background()
 
@@ -233,7 +256,9 @@ func DeleteKs(
 ) error {
 	for key, tablet := range tabletMap {
 		if tablet.keyspace == ksName {
+			tabletMapMu.Lock()
 			delete(tabletMap, key)
+			tabletMapMu.Unlock()
 			tablet.tm.Stop()
 			tablet.tm.Close()
 			tablet.qsc.SchemaEngine().Close()
@@ -444,6 +469,29 @@ func (itc *internalTabletConn) Execute(
 	return reply, nil
 }
 
+// ExecuteBatch is part of queryservice.QueryService
+// We need to copy the bind variables as tablet server will change them.
+func (itc *internalTabletConn) ExecuteBatch(
+	ctx context.Context,
+	target *querypb.Target,
+	queries []*querypb.BoundQuery,
+	transactionID, reservedID int64,
+	options *querypb.ExecuteOptions,
+) ([]*sqltypes.Result, error) {
+	copied := make([]*querypb.BoundQuery, 0, len(queries))
+	for _, query := range queries {
+		copied = append(copied, &querypb.BoundQuery{
+			Sql:           query.Sql,
+			BindVariables: sqltypes.CopyBindVariables(query.BindVariables),
+		})
+	}
+	reply, err := itc.tablet.qsc.QueryService().ExecuteBatch(ctx, target, copied, transactionID, reservedID, options)
+	if err != nil {
+		return nil, tabletconn.ErrorFromGRPC(vterrors.ToGRPC(err))
+	}
+	return reply, nil
+}
+
 // StreamExecute is part of queryservice.QueryService
 // We need to copy the bind variables as tablet server will change them.
 func (itc *internalTabletConn) StreamExecute(
@@ -733,6 +781,14 @@ func (itmc *internalTabletManagerClient) UnlockTables(ctx context.Context, table
 	return fmt.Errorf("not implemented in vtcombo")
 }
 
+func (itmc *internalTabletManagerClient) AcquireSnapshotLock(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.AcquireSnapshotLockRequest) (*tabletmanagerdatapb.AcquireSnapshotLockResponse, error) {
+	return nil, fmt.Errorf("not implemented in vtcombo")
+}
+
+func (itmc *internalTabletManagerClient) ReleaseSnapshotLock(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (*tabletmanagerdatapb.ReleaseSnapshotLockResponse, error) {
+	return nil, fmt.Errorf("not implemented in vtcombo")
+}
+
 func (itmc *internalTabletManagerClient) Ping(ctx context.Context, tablet *topodatapb.Tablet) error {
 	t, ok := tabletMap[tablet.Alias.Uid]
 	if !ok {
@@ -263,6 +263,15 @@ func (client *gRPCVtctldClient) ExecuteMultiFetchAsDBA(ctx context.Context, in *
 	return client.c.ExecuteMultiFetchAsDBA(ctx, in, opts...)
 }
 
+// ExportTables is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ExportTables(ctx context.Context, in *vtctldatapb.ExportTablesRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTablesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ExportTables(ctx, in, opts...)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	if client.c == nil {
@@ -317,6 +326,15 @@ func (client *gRPCVtctldClient) GetCellsAliases(ctx context.Context, in *vtctlda
 	return client.c.GetCellsAliases(ctx, in, opts...)
 }
 
+// GetDeniedTables is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetDeniedTables(ctx context.Context, in *vtctldatapb.GetDeniedTablesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetDeniedTablesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetDeniedTables(ctx, in, opts...)
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetFullStatus(ctx context.Context, in *vtctldatapb.GetFullStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFullStatusResponse, error) {
 	if client.c == nil {
@@ -452,6 +470,15 @@ func (client *gRPCVtctldClient) GetSrvVSchemas(ctx context.Context, in *vtctldat
 	return client.c.GetSrvVSchemas(ctx, in, opts...)
 }
 
+// GetTableKillSwitches is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetTableKillSwitches(ctx context.Context, in *vtctldatapb.GetTableKillSwitchesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTableKillSwitchesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetTableKillSwitches(ctx, in, opts...)
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetTablet(ctx context.Context, in *vtctldatapb.GetTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTabletResponse, error) {
 	if client.c == nil {
@@ -767,6 +794,15 @@ func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldat
 	return client.c.RunHealthCheck(ctx, in, opts...)
 }
 
+// SafeDeleteKeyspace is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SafeDeleteKeyspace(ctx context.Context, in *vtctldatapb.SafeDeleteKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.SafeDeleteKeyspaceResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SafeDeleteKeyspace(ctx, in, opts...)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	if client.c == nil {
@@ -776,6 +812,15 @@ func (client *gRPCVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context,
 	return client.c.SetKeyspaceDurabilityPolicy(ctx, in, opts...)
 }
 
+// SetKeyspaceVtorcConfig is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetKeyspaceVtorcConfig(ctx context.Context, in *vtctldatapb.SetKeyspaceVtorcConfigRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceVtorcConfigResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetKeyspaceVtorcConfig(ctx, in, opts...)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	if client.c == nil {
@@ -794,6 +839,15 @@ func (client *gRPCVtctldClient) SetShardTabletControl(ctx context.Context, in *v
 	return client.c.SetShardTabletControl(ctx, in, opts...)
 }
 
+// SetTableKillSwitch is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetTableKillSwitch(ctx context.Context, in *vtctldatapb.SetTableKillSwitchRequest, opts ...grpc.CallOption) (*vtctldatapb.SetTableKillSwitchResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetTableKillSwitch(ctx, in, opts...)
+}
+
 // SetWritable is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetWritable(ctx context.Context, in *vtctldatapb.SetWritableRequest, opts ...grpc.CallOption) (*vtctldatapb.SetWritableResponse, error) {
 	if client.c == nil {
@@ -1037,6 +1091,42 @@ func (client *gRPCVtctldClient) WorkflowDelete(ctx context.Context, in *vtctldat
 	return client.c.WorkflowDelete(ctx, in, opts...)
 }
 
+// WorkflowProfileDelete is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) WorkflowProfileDelete(ctx context.Context, in *vtctldatapb.WorkflowProfileDeleteRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileDeleteResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.WorkflowProfileDelete(ctx, in, opts...)
+}
+
+// WorkflowProfileList is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) WorkflowProfileList(ctx context.Context, in *vtctldatapb.WorkflowProfileListRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileListResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.WorkflowProfileList(ctx, in, opts...)
+}
+
+// WorkflowProfileSave is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) WorkflowProfileSave(ctx context.Context, in *vtctldatapb.WorkflowProfileSaveRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileSaveResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.WorkflowProfileSave(ctx, in, opts...)
+}
+
+// WorkflowProfileShow is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) WorkflowProfileShow(ctx context.Context, in *vtctldatapb.WorkflowProfileShowRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileShowResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.WorkflowProfileShow(ctx, in, opts...)
+}
+
 // WorkflowStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) WorkflowStatus(ctx context.Context, in *vtctldatapb.WorkflowStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
 	if client.c == nil {
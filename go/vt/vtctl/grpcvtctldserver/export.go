@@ -0,0 +1,243 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// exportFormatCSV is the format of the files of ExportTables, with a
+	// header row naming the columns.
+	exportFormatCSV = "csv"
+	// exportNullValue is how NULL is written in the exported CSV files, the
+	// same as SELECT ... INTO OUTFILE.
+	exportNullValue = `\N`
+)
+
+// exportDir returns the directory of the exports of a shard on the backup
+// storage. It is kept apart from the backups of the shard.
+func exportDir(keyspace, shard string) string {
+	return filepath.Join("exports", keyspace, shard)
+}
+
+// validateExportName checks that the name of an export is a single element
+// of the paths of the backup storage, so that the export of a shard cannot
+// be written outside of its directory.
+func validateExportName(name string) error {
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid export name %q", name)
+	}
+	return nil
+}
+
+// removeExport removes the export of a shard from the backup storage. It
+// does not use the context of the export, which may be why it failed.
+func removeExport(bs backupstorage.BackupStorage, keyspace, shard, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+
+	if err := bs.RemoveBackup(ctx, exportDir(keyspace, shard), name); err != nil {
+		log.Errorf("failed to remove export %v/%v: %v", exportDir(keyspace, shard), name, err)
+	}
+}
+
+// exportShard exports the tables of a shard from a tablet of the given type,
+// each table from its own consistent snapshot streamed by the rowstreamer.
+func (s *VtctldServer) exportShard(ctx context.Context, bs backupstorage.BackupStorage, req *vtctldatapb.ExportTablesRequest, shard string, tabletType topodatapb.TabletType, name string) (files []*vtctldatapb.ExportTablesResponse_File, err error) {
+	tablet, err := s.exportTablet(ctx, req.Keyspace, shard, tabletType)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := s.tmc.GetSchema(ctx, tablet, &tabletmanagerdatapb.GetSchemaRequest{
+		Tables:          req.Tables,
+		ExcludeTables:   req.ExcludeTables,
+		TableSchemaOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(schema.TableDefinitions) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no tables to export on tablet %v", topoproto.TabletAliasString(tablet.Alias))
+	}
+
+	conn, err := s.dialTablet(tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+
+	bh, err := bs.StartBackup(ctx, exportDir(req.Keyspace, shard), name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			removeExport(bs, req.Keyspace, shard, name)
+		}
+	}()
+
+	target := &querypb.Target{
+		Keyspace:   req.Keyspace,
+		Shard:      shard,
+		TabletType: tablet.Type,
+	}
+	files = make([]*vtctldatapb.ExportTablesResponse_File, 0, len(schema.TableDefinitions))
+	for _, td := range schema.TableDefinitions {
+		file, err := exportTable(ctx, conn, bh, target, td.Name)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "failed to export table %v", td.Name)
+		}
+		file.TabletAlias = tablet.Alias
+		files = append(files, file)
+	}
+
+	if err := bh.EndBackup(ctx); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// exportTablet returns the tablet of the shard to export from: the first, by
+// alias, of the given type.
+func (s *VtctldServer) exportTablet(ctx context.Context, keyspace, shard string, tabletType topodatapb.TabletType) (*topodatapb.Tablet, error) {
+	tablets, err := s.ts.GetTabletMapForShard(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make([]string, 0, len(tablets))
+	for alias, ti := range tablets {
+		if ti.Type == tabletType {
+			aliases = append(aliases, alias)
+		}
+	}
+	if len(aliases) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no %v tablet to export shard %v/%v from", topoproto.TabletTypeLString(tabletType), keyspace, shard)
+	}
+
+	sort.Strings(aliases)
+	return tablets[aliases[0]].Tablet, nil
+}
+
+// exportTable streams the rows of a table to a file of the export.
+func exportTable(ctx context.Context, conn queryservice.QueryService, bh backupstorage.BackupHandle, target *querypb.Target, table string) (*vtctldatapb.ExportTablesResponse_File, error) {
+	filename := table + "." + exportFormatCSV
+	w, err := bh.AddFile(ctx, filename, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &vtctldatapb.ExportTablesResponse_File{
+		Shard: target.Shard,
+		Table: table,
+		Path:  filepath.Join(bh.Directory(), bh.Name(), filename),
+	}
+	cw := newExportWriter(w)
+	err = conn.VStreamRows(ctx, &binlogdatapb.VStreamRowsRequest{
+		Target: target,
+		Query:  "select * from " + sqlescape.EscapeID(table),
+	}, func(resp *binlogdatapb.VStreamRowsResponse) error {
+		if len(resp.Fields) > 0 {
+			file.Position = resp.Gtid
+			if err := cw.writeHeader(resp.Fields); err != nil {
+				return err
+			}
+		}
+		for _, row := range resp.Rows {
+			if err := cw.writeRow(row); err != nil {
+				return err
+			}
+			file.Rows++
+		}
+		return nil
+	})
+	if err == nil {
+		err = cw.flush()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// exportWriter writes the rows streamed by VStreamRows as CSV.
+type exportWriter struct {
+	w      *csv.Writer
+	fields []*querypb.Field
+	record []string
+}
+
+func newExportWriter(w io.Writer) *exportWriter {
+	return &exportWriter{w: csv.NewWriter(w)}
+}
+
+// writeHeader writes the names of the columns.
+func (ew *exportWriter) writeHeader(fields []*querypb.Field) error {
+	ew.fields = fields
+	ew.record = make([]string, len(fields))
+	for i, field := range fields {
+		ew.record[i] = field.Name
+	}
+	return ew.w.Write(ew.record)
+}
+
+// writeRow writes a row, in the format of the header.
+func (ew *exportWriter) writeRow(row *querypb.Row) error {
+	if ew.fields == nil {
+		return errors.New("rows received before the fields")
+	}
+	for i, value := range sqltypes.MakeRowTrusted(ew.fields, row) {
+		if value.IsNull() {
+			ew.record[i] = exportNullValue
+			continue
+		}
+		ew.record[i] = value.ToString()
+	}
+	return ew.w.Write(ew.record)
+}
+
+func (ew *exportWriter) flush() error {
+	ew.w.Flush()
+	return ew.w.Error()
+}
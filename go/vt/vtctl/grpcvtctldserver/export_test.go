@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+func TestExportTables(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	conns := map[string]*sandboxconn.SandboxConn{}
+	tmc := testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		server := NewVtctldServer(vtenv.NewTestEnv(), ts)
+		server.dialer = func(tablet *topodatapb.Tablet, failFast grpcclient.FailFast) (queryservice.QueryService, error) {
+			conn, ok := conns[topoproto.TabletAliasString(tablet.Alias)]
+			if !ok {
+				return nil, fmt.Errorf("tablet %v not found", topoproto.TabletAliasString(tablet.Alias))
+			}
+			return conn, nil
+		}
+		return server
+	})
+
+	fields := sqltypes.MakeTestFields("id|name", "int64|varchar")
+	schema := &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{Name: "t1"}, {Name: "t2"}},
+	}
+	for uid, shard := range map[uint32]string{100: "-80", 101: "-80", 200: "80-"} {
+		tabletType := topodatapb.TabletType_REPLICA
+		if uid == 100 {
+			tabletType = topodatapb.TabletType_PRIMARY
+		}
+		alias := &topodatapb.TabletAlias{Cell: "zone1", Uid: uid}
+		testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+			Alias:    alias,
+			Keyspace: "ks",
+			Shard:    shard,
+			Type:     tabletType,
+		}, nil)
+		tmc.GetSchemaResults[topoproto.TabletAliasString(alias)] = struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{Schema: schema}
+		conn := sandboxconn.NewSandboxConn(nil)
+		conn.VStreamRowsPosition = fmt.Sprintf("MySQL56/00000000-0000-0000-0000-000000000000:1-%d", uid)
+		conns[topoproto.TabletAliasString(alias)] = conn
+	}
+	// setResults sets the rows of t1 and t2, the tables streamed in order
+	// from each tablet.
+	setResults := func() {
+		for alias, conn := range conns {
+			uid := strings.TrimLeft(strings.TrimPrefix(alias, "zone1-"), "0")
+			conn.SetResults([]*sqltypes.Result{
+				sqltypes.MakeTestResult(fields, uid+"|a,b", "2|null"),
+				sqltypes.MakeTestResult(fields),
+			})
+		}
+	}
+	setResults()
+
+	resp, err := vtctld.ExportTables(ctx, &vtctldatapb.ExportTablesRequest{
+		Keyspace: "ks",
+		Name:     "export1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "export1", resp.Name)
+	require.Len(t, resp.Files, 4)
+	file := resp.Files[0]
+	assert.Equal(t, "-80", file.Shard)
+	assert.Equal(t, "t1", file.Table)
+	assert.Equal(t, "zone1-0000000101", topoproto.TabletAliasString(file.TabletAlias))
+	assert.Equal(t, "MySQL56/00000000-0000-0000-0000-000000000000:1-101", file.Position)
+	assert.EqualValues(t, 2, file.Rows)
+	assert.Equal(t, "exports/ks/-80/export1/t1.csv", file.Path)
+	assert.Equal(t, "id,name\n101,\"a,b\"\n2,\\N\n", string(testutil.BackupStorage.Files[file.Path]))
+
+	file = resp.Files[3]
+	assert.Equal(t, "80-", file.Shard)
+	assert.Equal(t, "t2", file.Table)
+	assert.EqualValues(t, 0, file.Rows)
+	assert.Equal(t, "id,name\n", string(testutil.BackupStorage.Files[file.Path]))
+	assert.Contains(t, testutil.BackupStorage.Backups["exports/ks/80-"], "export1")
+	assert.Equal(t, "select * from `t1`", conns["zone1-0000000101"].Queries[0].Sql)
+
+	t.Run("existing export", func(t *testing.T) {
+		_, err := vtctld.ExportTables(ctx, &vtctldatapb.ExportTablesRequest{
+			Keyspace:    "ks",
+			Shards:      []string{"80-"},
+			Name:        "export1",
+			Concurrency: 1,
+		})
+		assert.ErrorContains(t, err, "already exists")
+	})
+
+	t.Run("no tablet of the type", func(t *testing.T) {
+		_, err := vtctld.ExportTables(ctx, &vtctldatapb.ExportTablesRequest{
+			Keyspace:   "ks",
+			TabletType: topodatapb.TabletType_RDONLY,
+		})
+		assert.ErrorContains(t, err, "no rdonly tablet to export shard ks/")
+	})
+
+	t.Run("failed shard", func(t *testing.T) {
+		setResults()
+		conns["zone1-0000000200"].EphemeralShardErr = errors.New("stream failed")
+
+		_, err := vtctld.ExportTables(ctx, &vtctldatapb.ExportTablesRequest{
+			Keyspace: "ks",
+			Name:     "export2",
+		})
+		assert.ErrorContains(t, err, "failed to export shard ks/80-: failed to export table t1: stream failed")
+		// The export of the shard that succeeded was removed.
+		assert.NotContains(t, testutil.BackupStorage.Backups["exports/ks/-80"], "export2")
+		for path := range testutil.BackupStorage.Files {
+			assert.NotContains(t, path, "/export2/")
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		for _, name := range []string{"..", "a/b", `a\b`} {
+			_, err := vtctld.ExportTables(ctx, &vtctldatapb.ExportTablesRequest{
+				Keyspace: "ks",
+				Name:     name,
+			})
+			assert.ErrorContains(t, err, "invalid export name")
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := vtctld.ExportTables(ctx, &vtctldatapb.ExportTablesRequest{
+			Keyspace: "ks",
+			Format:   "parquet",
+		})
+		assert.ErrorContains(t, err, `unsupported export format "parquet"`)
+	})
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// backupStreamFunc adapts a function to the stream that backupTablet sends
+// the backup events to.
+type backupStreamFunc func(resp *vtctldatapb.BackupResponse) error
+
+func (f backupStreamFunc) Send(resp *vtctldatapb.BackupResponse) error {
+	return f(resp)
+}
+
+// keyspaceDependencies returns a description of everything that still
+// depends on the keyspace, and prevents it from being safely deleted.
+func (s *VtctldServer) keyspaceDependencies(ctx context.Context, keyspace string) ([]string, error) {
+	var deps []string
+	for _, check := range []func(context.Context, string) ([]string, error){
+		s.routingRulesDependencies,
+		s.workflowDependencies,
+		s.trafficDependencies,
+	} {
+		checkDeps, err := check(ctx, keyspace)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, checkDeps...)
+	}
+	return deps, nil
+}
+
+// routingRuleKeyspace returns the keyspace of a table of a routing rule, which
+// is either qualified as keyspace.table, or as keyspace@tablet_type.table.
+func routingRuleKeyspace(table string) string {
+	keyspace, _, ok := strings.Cut(table, ".")
+	if !ok {
+		return ""
+	}
+	keyspace, _, _ = strings.Cut(keyspace, "@")
+	return keyspace
+}
+
+// routingRulesDependencies returns the table, shard and keyspace routing rules
+// that route from or to the keyspace.
+func (s *VtctldServer) routingRulesDependencies(ctx context.Context, keyspace string) ([]string, error) {
+	var deps []string
+
+	rr, err := s.ts.GetRoutingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rr.GetRules() {
+		references := routingRuleKeyspace(rule.FromTable) == keyspace
+		for _, table := range rule.ToTables {
+			references = references || routingRuleKeyspace(table) == keyspace
+		}
+		if references {
+			deps = append(deps, fmt.Sprintf("routing rule %s -> %s references it", rule.FromTable, strings.Join(rule.ToTables, ",")))
+		}
+	}
+
+	srr, err := s.ts.GetShardRoutingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range srr.GetRules() {
+		if rule.FromKeyspace == keyspace || rule.ToKeyspace == keyspace {
+			deps = append(deps, fmt.Sprintf("shard routing rule %s.%s -> %s references it", rule.FromKeyspace, rule.Shard, rule.ToKeyspace))
+		}
+	}
+
+	krr, err := s.ts.GetKeyspaceRoutingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range krr.GetRules() {
+		if rule.FromKeyspace == keyspace || rule.ToKeyspace == keyspace {
+			deps = append(deps, fmt.Sprintf("keyspace routing rule %s -> %s references it", rule.FromKeyspace, rule.ToKeyspace))
+		}
+	}
+
+	return deps, nil
+}
+
+// workflowDependencies returns the workflows that write to the keyspace, and
+// the workflows of the other keyspaces that read from it, as read from the
+// shard primaries.
+func (s *VtctldServer) workflowDependencies(ctx context.Context, keyspace string) ([]string, error) {
+	keyspaces, err := s.ts.GetKeyspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := map[string]bool{}
+	for _, ks := range keyspaces {
+		shards, err := s.ts.GetShardNames(ctx, ks)
+		if err != nil {
+			return nil, err
+		}
+		for _, shard := range shards {
+			si, err := s.ts.GetShard(ctx, ks, shard)
+			if err != nil {
+				return nil, err
+			}
+			if si.PrimaryAlias == nil {
+				// The workflows are run by the primary, they cannot be read
+				// without it.
+				return nil, fmt.Errorf("cannot read the workflows of shard %s/%s: it has no primary", ks, shard)
+			}
+			primary, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+			if err != nil {
+				return nil, err
+			}
+			res, err := s.tmc.ReadVReplicationWorkflows(ctx, primary.Tablet, &tabletmanagerdatapb.ReadVReplicationWorkflowsRequest{})
+			if err != nil {
+				return nil, fmt.Errorf("cannot read the workflows of shard %s/%s: %w", ks, shard, err)
+			}
+			for _, wf := range res.GetWorkflows() {
+				if ks == keyspace {
+					deps[fmt.Sprintf("workflow %s.%s writes to it", ks, wf.Workflow)] = true
+					continue
+				}
+				for _, stream := range wf.Streams {
+					if stream.GetBls().GetKeyspace() == keyspace {
+						deps[fmt.Sprintf("workflow %s.%s reads from it", ks, wf.Workflow)] = true
+					}
+				}
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(deps))
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// trafficDependencies returns the serving tablets of the keyspace that have
+// recently served queries. A tablet only serves queries routed by the
+// vtgates, so this tells whether the keyspace is still used by applications.
+func (s *VtctldServer) trafficDependencies(ctx context.Context, keyspace string) ([]string, error) {
+	shards, err := s.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	var tablets []*topo.TabletInfo
+	for _, shard := range shards {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		for _, tablet := range tabletMap {
+			if topoproto.IsServingType(tablet.Type) {
+				tablets = append(tablets, tablet)
+			}
+		}
+	}
+	sort.Slice(tablets, func(i, j int) bool {
+		return topoproto.TabletAliasString(tablets[i].Alias) < topoproto.TabletAliasString(tablets[j].Alias)
+	})
+
+	var deps []string
+	for _, tablet := range tablets {
+		qps, err := s.tabletQPS(ctx, tablet.Tablet)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check the traffic of tablet %v: %w", topoproto.TabletAliasString(tablet.Alias), err)
+		}
+		if qps > 0 {
+			deps = append(deps, fmt.Sprintf("tablet %v recently served %.2f queries per second", topoproto.TabletAliasString(tablet.Alias), qps))
+		}
+	}
+	return deps, nil
+}
+
+// tabletQPS returns the queries per second that the tablet served recently,
+// from its realtime stats.
+func (s *VtctldServer) tabletQPS(ctx context.Context, tablet *topodatapb.Tablet) (float64, error) {
+	conn, err := s.dialTablet(tablet)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close(ctx)
+
+	var qps float64
+	err = conn.StreamHealth(ctx, func(shr *querypb.StreamHealthResponse) error {
+		qps = shr.GetRealtimeStats().GetQps()
+		// The first health response is enough.
+		return io.EOF
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	return qps, nil
+}
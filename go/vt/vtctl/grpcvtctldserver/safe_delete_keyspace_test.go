@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+)
+
+func TestSafeDeleteKeyspace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := memorytopo.NewServer(ctx, "zone1")
+	conns := map[string]*sandboxconn.SandboxConn{}
+	tmc := &testutil.TabletManagerClient{
+		Backups: map[string]struct {
+			Events        []*logutilpb.Event
+			EventInterval time.Duration
+			EventJitter   time.Duration
+			ErrorAfter    time.Duration
+		}{
+			"zone1-0000000101": {
+				Events:        []*logutilpb.Event{{Value: "backup done"}},
+				EventInterval: time.Millisecond,
+				EventJitter:   time.Millisecond,
+			},
+		},
+		PrimaryPositionResults: map[string]struct {
+			Position string
+			Error    error
+		}{
+			"zone1-0000000100": {Position: "some-position"},
+		},
+		ReplicationStatusResults: map[string]struct {
+			Position *replicationdatapb.Status
+			Error    error
+		}{
+			"zone1-0000000101": {Position: &replicationdatapb.Status{}},
+		},
+		ReadVReplicationWorkflowsResults: map[string]struct {
+			Response *tabletmanagerdatapb.ReadVReplicationWorkflowsResponse
+			Error    error
+		}{
+			"zone1-0000000100": {Response: &tabletmanagerdatapb.ReadVReplicationWorkflowsResponse{}},
+			"zone1-0000000200": {Response: &tabletmanagerdatapb.ReadVReplicationWorkflowsResponse{}},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		server := NewVtctldServer(vtenv.NewTestEnv(), ts)
+		server.dialer = func(tablet *topodatapb.Tablet, failFast grpcclient.FailFast) (queryservice.QueryService, error) {
+			conn, ok := conns[topoproto.TabletAliasString(tablet.Alias)]
+			if !ok {
+				return nil, fmt.Errorf("tablet %v not found", topoproto.TabletAliasString(tablet.Alias))
+			}
+			return conn, nil
+		}
+		return server
+	})
+
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{AlsoSetShardPrimary: true},
+		&topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "ks",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		&topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+			Keyspace: "ks",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_REPLICA,
+		},
+		&topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+			Keyspace: "other",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+	)
+	// setQPS sets the queries per second served by a tablet.
+	setQPS := func(alias string, qps float64) {
+		conns[alias].StreamHealthResponse = &querypb.StreamHealthResponse{
+			Serving:       true,
+			RealtimeStats: &querypb.RealtimeStats{Qps: qps},
+		}
+	}
+	for _, alias := range []string{"zone1-0000000100", "zone1-0000000101", "zone1-0000000200"} {
+		conns[alias] = sandboxconn.NewSandboxConn(nil)
+		setQPS(alias, 0)
+	}
+	setQPS("zone1-0000000200", 10)
+
+	safeDelete := func(dryRun bool) (*vtctldatapb.SafeDeleteKeyspaceResponse, error) {
+		return vtctld.SafeDeleteKeyspace(ctx, &vtctldatapb.SafeDeleteKeyspaceRequest{
+			Keyspace: "ks",
+			DryRun:   dryRun,
+		})
+	}
+
+	t.Run("routing rules", func(t *testing.T) {
+		require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{
+			Rules: []*vschemapb.RoutingRule{{FromTable: "t1", ToTables: []string{"ks@replica.t1"}}},
+		}))
+		require.NoError(t, ts.SaveShardRoutingRules(ctx, &vschemapb.ShardRoutingRules{
+			Rules: []*vschemapb.ShardRoutingRule{{FromKeyspace: "other", ToKeyspace: "ks", Shard: "-"}},
+		}))
+		defer func() {
+			require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{}))
+			require.NoError(t, ts.SaveShardRoutingRules(ctx, &vschemapb.ShardRoutingRules{}))
+		}()
+
+		_, err := safeDelete(true)
+		assert.ErrorContains(t, err, "keyspace ks is still in use: routing rule t1 -> ks@replica.t1 references it; shard routing rule other.- -> ks references it")
+	})
+
+	t.Run("workflows", func(t *testing.T) {
+		tmc.ReadVReplicationWorkflowsResults["zone1-0000000200"] = struct {
+			Response *tabletmanagerdatapb.ReadVReplicationWorkflowsResponse
+			Error    error
+		}{Response: &tabletmanagerdatapb.ReadVReplicationWorkflowsResponse{
+			Workflows: []*tabletmanagerdatapb.ReadVReplicationWorkflowResponse{{
+				Workflow: "ks2other",
+				Streams: []*tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream{{
+					Bls: &binlogdatapb.BinlogSource{Keyspace: "ks", Shard: "-"},
+				}},
+			}},
+		}}
+		defer func() {
+			tmc.ReadVReplicationWorkflowsResults["zone1-0000000200"] = struct {
+				Response *tabletmanagerdatapb.ReadVReplicationWorkflowsResponse
+				Error    error
+			}{Response: &tabletmanagerdatapb.ReadVReplicationWorkflowsResponse{}}
+		}()
+
+		_, err := safeDelete(true)
+		assert.ErrorContains(t, err, "keyspace ks is still in use: workflow other.ks2other reads from it")
+	})
+
+	t.Run("traffic", func(t *testing.T) {
+		setQPS("zone1-0000000101", 1.5)
+		defer setQPS("zone1-0000000101", 0)
+
+		_, err := safeDelete(true)
+		assert.ErrorContains(t, err, "keyspace ks is still in use: tablet zone1-0000000101 recently served 1.50 queries per second")
+	})
+
+	t.Run("shard without primary", func(t *testing.T) {
+		require.NoError(t, ts.CreateShard(ctx, "other", "80-"))
+		defer func() {
+			require.NoError(t, ts.DeleteShard(ctx, "other", "80-"))
+		}()
+
+		_, err := safeDelete(true)
+		assert.ErrorContains(t, err, "cannot read the workflows of shard other/80-: it has no primary")
+	})
+
+	t.Run("locked keyspace", func(t *testing.T) {
+		_, unlock, err := ts.LockKeyspace(ctx, "ks", "test")
+		require.NoError(t, err)
+		defer unlock(&err)
+
+		lockCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = vtctld.SafeDeleteKeyspace(lockCtx, &vtctldatapb.SafeDeleteKeyspaceRequest{
+			Keyspace: "ks",
+			DryRun:   true,
+		})
+		assert.ErrorContains(t, err, "deadline exceeded")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		resp, err := safeDelete(true)
+		require.NoError(t, err)
+		require.Len(t, resp.Events, 1)
+		assert.Equal(t, "Nothing depends on keyspace ks anymore", resp.Events[0].Value)
+		_, err = ts.GetKeyspace(ctx, "ks")
+		require.NoError(t, err)
+	})
+
+	resp, err := safeDelete(false)
+	require.NoError(t, err)
+	var events []string
+	for _, event := range resp.Events {
+		events = append(events, event.Value)
+	}
+	assert.Equal(t, []string{
+		"Nothing depends on keyspace ks anymore",
+		"Taking the final backup of shard ks/- on tablet zone1-0000000101",
+		"backup done",
+		"Deleting keyspace ks and its tablets",
+		"Deleted keyspace ks",
+	}, events)
+
+	_, err = ts.GetKeyspace(ctx, "ks")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected keyspace ks to be deleted, got %v", err)
+	_, err = ts.GetTablet(ctx, &topodatapb.TabletAlias{Cell: "zone1", Uid: 101})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected tablet zone1-0000000101 to be deleted, got %v", err)
+	_, err = ts.GetKeyspace(ctx, "other")
+	assert.NoError(t, err)
+
+	_, err = safeDelete(true)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "expected a missing keyspace error, got %v", err)
+}
@@ -32,6 +32,8 @@ import (
 
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 
 	"vitess.io/vitess/go/event"
 	"vitess.io/vitess/go/netutil"
@@ -42,6 +44,7 @@ import (
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -52,6 +55,7 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
 	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/schemamanager"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
@@ -63,6 +67,8 @@ import (
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
@@ -75,6 +81,7 @@ import (
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 const (
@@ -90,6 +97,9 @@ type VtctldServer struct {
 	ts  *topo.Server
 	tmc tmclient.TabletManagerClient
 	ws  *workflow.Server
+	// dialer connects to the query service of the tablets. The dialer of
+	// --tablet_protocol is used if it is nil.
+	dialer tabletconn.TabletDialer
 }
 
 // NewVtctldServer returns a new VtctldServer for the given topo server.
@@ -113,6 +123,15 @@ func NewTestVtctldServer(ts *topo.Server, tmc tmclient.TabletManagerClient) *Vtc
 	}
 }
 
+// dialTablet connects to the query service of a tablet.
+func (s *VtctldServer) dialTablet(tablet *topodatapb.Tablet) (queryservice.QueryService, error) {
+	dialer := s.dialer
+	if dialer == nil {
+		dialer = tabletconn.GetDialer()
+	}
+	return dialer(tablet, grpcclient.FailFast(false))
+}
+
 func panicHandler(err *error) {
 	if x := recover(); x != nil {
 		*err = fmt.Errorf("uncaught panic: %v from: %v", x, string(debug.Stack()))
@@ -462,14 +481,30 @@ func (s *VtctldServer) BackupShard(req *vtctldatapb.BackupShardRequest, stream v
 	span.Annotate("concurrency", req.Concurrency)
 	span.Annotate("incremental_from_pos", req.IncrementalFromPos)
 
-	tablets, stats, err := reparentutil.ShardReplicationStatuses(ctx, s.ts, s.tmc, req.Keyspace, req.Shard)
+	backupTablet, err := s.shardBackupTablet(ctx, req.Keyspace, req.Shard, req.AllowPrimary)
+	if err != nil {
+		return err
+	}
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(backupTablet.Alias))
+
+	r := &vtctldatapb.BackupRequest{Concurrency: req.Concurrency, AllowPrimary: req.AllowPrimary, UpgradeSafe: req.UpgradeSafe, IncrementalFromPos: req.IncrementalFromPos}
+	err = s.backupTablet(ctx, backupTablet, r, stream)
+	return err
+}
+
+// shardBackupTablet returns the tablet of the shard to take a backup from: the
+// replica-type tablet with the lowest replication lag, or, if there is none and
+// allowPrimary is set, the primary.
+func (s *VtctldServer) shardBackupTablet(ctx context.Context, keyspace, shard string, allowPrimary bool) (*topodatapb.Tablet, error) {
+	tablets, stats, err := reparentutil.ShardReplicationStatuses(ctx, s.ts, s.tmc, keyspace, shard)
 
 	// Instead of return on err directly, only return err when no tablets for backup at all
 	if err != nil {
 		tablets = reparentutil.GetBackupCandidates(tablets, stats)
 		// Only return err when no usable tablet
 		if len(tablets) == 0 {
-			return err
+			return nil, err
 		}
 	}
 
@@ -491,7 +526,7 @@ func (s *VtctldServer) BackupShard(req *vtctldatapb.BackupShardRequest, stream v
 		}
 	}
 
-	if backupTablet == nil && req.AllowPrimary {
+	if backupTablet == nil && allowPrimary {
 		for _, tablet := range tablets {
 			if tablet.Type != topodatapb.TabletType_PRIMARY {
 				continue
@@ -503,15 +538,10 @@ func (s *VtctldServer) BackupShard(req *vtctldatapb.BackupShardRequest, stream v
 	}
 
 	if backupTablet == nil {
-		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no tablet available for backup")
-		return err
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no tablet available for backup")
 	}
 
-	span.Annotate("tablet_alias", topoproto.TabletAliasString(backupTablet.Alias))
-
-	r := &vtctldatapb.BackupRequest{Concurrency: req.Concurrency, AllowPrimary: req.AllowPrimary, UpgradeSafe: req.UpgradeSafe, IncrementalFromPos: req.IncrementalFromPos}
-	err = s.backupTablet(ctx, backupTablet, r, stream)
-	return err
+	return backupTablet, nil
 }
 
 func (s *VtctldServer) backupTablet(ctx context.Context, tablet *topodatapb.Tablet, req *vtctldatapb.BackupRequest, stream interface {
@@ -1001,68 +1031,76 @@ func (s *VtctldServer) DeleteKeyspace(ctx context.Context, req *vtctldatapb.Dele
 	}
 
 	if unlock != nil {
-		defer func() {
-			// Attempting to unlock a keyspace we successfully deleted results
-			// in ts.unlockKeyspace returning an error, which can make the
-			// overall RPC _seem_ like it failed.
-			//
-			// So, we do this extra checking to allow for specifically this
-			// scenario to result in "success."
-			origErr := err
-			unlock(&err)
-			if origErr == nil && topo.IsErrType(err, topo.NoNode) {
-				err = nil
-			}
-		}()
+		defer unlockDeletedKeyspace(unlock, &err)
 	}
 
-	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
-	if err != nil {
+	if err = s.deleteKeyspace(ctx, req.Keyspace, req.Recursive, req.Force); err != nil {
 		return nil, err
 	}
 
+	return &vtctldatapb.DeleteKeyspaceResponse{}, nil
+}
+
+// unlockDeletedKeyspace releases the lock of a keyspace that may have been
+// deleted while it was held.
+func unlockDeletedKeyspace(unlock func(*error), err *error) {
+	// Attempting to unlock a keyspace we successfully deleted results
+	// in ts.unlockKeyspace returning an error, which can make the
+	// overall RPC _seem_ like it failed.
+	//
+	// So, we do this extra checking to allow for specifically this
+	// scenario to result in "success."
+	origErr := *err
+	unlock(err)
+	if origErr == nil && topo.IsErrType(*err, topo.NoNode) {
+		*err = nil
+	}
+}
+
+// deleteKeyspace deletes a keyspace, and its shards if recursive is set. The
+// keyspace should be locked, unless force is set.
+func (s *VtctldServer) deleteKeyspace(ctx context.Context, keyspace string, recursive bool, force bool) error {
+	shards, err := s.ts.GetShardNames(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+
 	if len(shards) > 0 {
-		if !req.Recursive {
-			err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %v still has %d shards; use Recursive=true or remove them manually", req.Keyspace, len(shards))
-			return nil, err
+		if !recursive {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %v still has %d shards; use Recursive=true or remove them manually", keyspace, len(shards))
 		}
 
-		log.Infof("Deleting all %d shards (and their tablets) in keyspace %v", len(shards), req.Keyspace)
-		recursive := true
+		log.Infof("Deleting all %d shards (and their tablets) in keyspace %v", len(shards), keyspace)
 		evenIfServing := true
-		force := req.Force
 
 		for _, shard := range shards {
-			log.Infof("Recursively deleting shard %v/%v", req.Keyspace, shard)
-			err = deleteShard(ctx, s.ts, req.Keyspace, shard, recursive, evenIfServing, force)
-			if err != nil {
-				err = fmt.Errorf("cannot delete shard %v/%v: %w", req.Keyspace, shard, err)
-				return nil, err
+			log.Infof("Recursively deleting shard %v/%v", keyspace, shard)
+			if err := deleteShard(ctx, s.ts, keyspace, shard, recursive, evenIfServing, force); err != nil {
+				return fmt.Errorf("cannot delete shard %v/%v: %w", keyspace, shard, err)
 			}
 		}
 	}
 
 	cells, err := s.ts.GetKnownCells(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	for _, cell := range cells {
-		if err := s.ts.DeleteKeyspaceReplication(ctx, cell, req.Keyspace); err != nil && !topo.IsErrType(err, topo.NoNode) {
-			log.Warningf("Cannot delete KeyspaceReplication in cell %v for %v: %v", cell, req.Keyspace, err)
+		if err := s.ts.DeleteKeyspaceReplication(ctx, cell, keyspace); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			log.Warningf("Cannot delete KeyspaceReplication in cell %v for %v: %v", cell, keyspace, err)
 		}
 
-		if err := s.ts.DeleteSrvKeyspace(ctx, cell, req.Keyspace); err != nil && !topo.IsErrType(err, topo.NoNode) {
-			log.Warningf("Cannot delete SrvKeyspace in cell %v for %v: %v", cell, req.Keyspace, err)
+		if err := s.ts.DeleteSrvKeyspace(ctx, cell, keyspace); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			log.Warningf("Cannot delete SrvKeyspace in cell %v for %v: %v", cell, keyspace, err)
 		}
 	}
 
-	err = s.ts.DeleteKeyspace(ctx, req.Keyspace)
-	if err != nil {
-		return nil, err
+	if err := s.ts.DeleteTableKillSwitches(ctx, keyspace); err != nil && !topo.IsErrType(err, topo.NoNode) {
+		log.Warningf("Cannot delete the table kill switches of %v: %v", keyspace, err)
 	}
 
-	return &vtctldatapb.DeleteKeyspaceResponse{}, nil
+	return s.ts.DeleteKeyspace(ctx, keyspace)
 }
 
 // DeleteShards is part of the vtctlservicepb.VtctldServer interface.
@@ -1326,6 +1364,111 @@ func (s *VtctldServer) ExecuteHook(ctx context.Context, req *vtctldatapb.Execute
 	}}, nil
 }
 
+// ExportTables is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ExportTables(ctx context.Context, req *vtctldatapb.ExportTablesRequest) (resp *vtctldatapb.ExportTablesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExportTables")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shards", strings.Join(req.Shards, ","))
+	span.Annotate("tablet_type", topoproto.TabletTypeLString(req.TabletType))
+	span.Annotate("name", req.Name)
+	span.Annotate("format", req.Format)
+	span.Annotate("concurrency", req.Concurrency)
+
+	if err = validateExportName(req.Name); err != nil {
+		return nil, err
+	}
+
+	switch req.Format {
+	case "", exportFormatCSV:
+	default:
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported export format %q, only %q is supported", req.Format, exportFormatCSV)
+		return nil, err
+	}
+
+	tabletType := req.TabletType
+	if tabletType == topodatapb.TabletType_UNKNOWN {
+		tabletType = topodatapb.TabletType_REPLICA
+	}
+
+	name := req.Name
+	if name == "" {
+		name = time.Now().UTC().Format(mysqlctl.BackupTimestampFormat)
+	}
+
+	shards := req.Shards
+	if len(shards) == 0 {
+		shards, err = s.ts.GetShardNames(ctx, req.Keyspace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	var (
+		m     sync.Mutex
+		wg    sync.WaitGroup
+		rec   concurrency.AllErrorRecorder
+		sema  *semaphore.Weighted
+		files = map[string][]*vtctldatapb.ExportTablesResponse_File{}
+	)
+	if req.Concurrency > 0 {
+		sema = semaphore.NewWeighted(int64(req.Concurrency))
+	}
+
+	for _, shard := range shards {
+		wg.Add(1)
+		go func(shard string) {
+			defer wg.Done()
+
+			if sema != nil {
+				if err := sema.Acquire(ctx, 1); err != nil {
+					rec.RecordError(err)
+					return
+				}
+				defer sema.Release(1)
+			}
+
+			shardFiles, err := s.exportShard(ctx, bs, req, shard, tabletType, name)
+			if err != nil {
+				rec.RecordError(vterrors.Wrapf(err, "failed to export shard %v/%v", req.Keyspace, shard))
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+
+			files[shard] = shardFiles
+		}(shard)
+	}
+
+	wg.Wait()
+	if rec.HasErrors() {
+		// Remove the exports of the shards that succeeded, so that a failed
+		// export leaves no partial copy of the keyspace behind.
+		for shard := range files {
+			removeExport(bs, req.Keyspace, shard, name)
+		}
+		err = rec.Error()
+		return nil, err
+	}
+
+	resp = &vtctldatapb.ExportTablesResponse{Name: name}
+	for _, shard := range shards {
+		resp.Files = append(resp.Files, files[shard]...)
+	}
+
+	return resp, nil
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctldatapb.FindAllShardsInKeyspaceRequest) (resp *vtctldatapb.FindAllShardsInKeyspaceResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.FindAllShardsInKeyspace")
@@ -1475,6 +1618,58 @@ func (s *VtctldServer) GetCellsAliases(ctx context.Context, req *vtctldatapb.Get
 	return &vtctldatapb.GetCellsAliasesResponse{Aliases: aliases}, nil
 }
 
+// GetDeniedTables is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetDeniedTables(ctx context.Context, req *vtctldatapb.GetDeniedTablesRequest) (resp *vtctldatapb.GetDeniedTablesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetDeniedTables")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shards", strings.Join(req.Shards, ","))
+
+	shards := req.Shards
+	if len(shards) == 0 {
+		shards, err = s.ts.GetShardNames(ctx, req.Keyspace)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(shards)
+
+	resp = &vtctldatapb.GetDeniedTablesResponse{}
+	for _, shard := range shards {
+		var si *topo.ShardInfo
+		si, err = s.ts.GetShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+		for _, tc := range si.TabletControls {
+			if len(tc.DeniedTables) == 0 {
+				continue
+			}
+			sdt := &vtctldatapb.GetDeniedTablesResponse_ShardDeniedTables{
+				Keyspace:   req.Keyspace,
+				Shard:      shard,
+				TabletType: tc.TabletType,
+				Cells:      tc.Cells,
+				Tables:     tc.DeniedTables,
+			}
+			for _, table := range tc.DeniedTables {
+				if expireTime, ok := tc.DeniedTablesExpireTimes[table]; ok {
+					if sdt.ExpireTimes == nil {
+						sdt.ExpireTimes = make(map[string]*vttimepb.Time)
+					}
+					sdt.ExpireTimes[table] = expireTime
+				}
+			}
+			resp.DeniedTables = append(resp.DeniedTables, sdt)
+		}
+	}
+
+	return resp, nil
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetFullStatus(ctx context.Context, req *vtctldatapb.GetFullStatusRequest) (resp *vtctldatapb.GetFullStatusResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetFullStatus")
@@ -2053,6 +2248,25 @@ func (s *VtctldServer) GetSrvVSchemas(ctx context.Context, req *vtctldatapb.GetS
 	}, nil
 }
 
+// GetTableKillSwitches is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetTableKillSwitches(ctx context.Context, req *vtctldatapb.GetTableKillSwitchesRequest) (resp *vtctldatapb.GetTableKillSwitchesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetTableKillSwitches")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	switches, err := s.ts.GetTableKillSwitches(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetTableKillSwitchesResponse{
+		KillSwitches: tableKillSwitchesToProto(switches),
+	}, nil
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetTablet(ctx context.Context, req *vtctldatapb.GetTabletRequest) (resp *vtctldatapb.GetTabletResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetTablet")
@@ -3417,6 +3631,87 @@ func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunH
 	return &vtctldatapb.RunHealthCheckResponse{}, nil
 }
 
+// SafeDeleteKeyspace is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SafeDeleteKeyspace(ctx context.Context, req *vtctldatapb.SafeDeleteKeyspaceRequest) (resp *vtctldatapb.SafeDeleteKeyspaceResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SafeDeleteKeyspace")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("dry_run", req.DryRun)
+
+	if _, err = s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+
+	// Nothing must start depending on the keyspace between the checks and
+	// its deletion.
+	lctx, unlock, lerr := s.ts.LockKeyspace(ctx, req.Keyspace, "SafeDeleteKeyspace")
+	if lerr != nil {
+		err = lerr
+		return nil, err
+	}
+	ctx = lctx
+	defer unlockDeletedKeyspace(unlock, &err)
+
+	var events []*logutilpb.Event
+	logger := logutil.NewCallbackLogger(func(e *logutilpb.Event) {
+		events = append(events, e)
+	})
+
+	deps, err := s.keyspaceDependencies(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(deps) > 0 {
+		err = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "keyspace %s is still in use: %s", req.Keyspace, strings.Join(deps, "; "))
+		return nil, err
+	}
+	logger.Infof("Nothing depends on keyspace %s anymore", req.Keyspace)
+
+	if req.DryRun {
+		return &vtctldatapb.SafeDeleteKeyspaceResponse{Events: events}, nil
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(shards)
+	for _, shard := range shards {
+		var tablet *topodatapb.Tablet
+		tablet, err = s.shardBackupTablet(ctx, req.Keyspace, shard, true)
+		if err != nil {
+			err = vterrors.Wrapf(err, "cannot take the final backup of shard %s/%s", req.Keyspace, shard)
+			return nil, err
+		}
+
+		logger.Infof("Taking the final backup of shard %s/%s on tablet %s", req.Keyspace, shard, topoproto.TabletAliasString(tablet.Alias))
+		stream := backupStreamFunc(func(resp *vtctldatapb.BackupResponse) error {
+			events = append(events, resp.Event)
+			return nil
+		})
+		if err = s.backupTablet(ctx, tablet, &vtctldatapb.BackupRequest{AllowPrimary: true}, stream); err != nil {
+			err = vterrors.Wrapf(err, "cannot take the final backup of shard %s/%s", req.Keyspace, shard)
+			return nil, err
+		}
+	}
+
+	logger.Infof("Deleting keyspace %s and its tablets", req.Keyspace)
+	if err = s.deleteKeyspace(ctx, req.Keyspace, true /* recursive */, false /* force */); err != nil {
+		return nil, err
+	}
+
+	// The vtgates stop routing to the keyspace once it is gone from the SrvVSchema.
+	if err = s.ts.RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+	logger.Infof("Deleted keyspace %s", req.Keyspace)
+
+	return &vtctldatapb.SafeDeleteKeyspaceResponse{Events: events}, nil
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtctldatapb.SetKeyspaceDurabilityPolicyRequest) (resp *vtctldatapb.SetKeyspaceDurabilityPolicyResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceDurabilityPolicy")
@@ -3458,6 +3753,42 @@ func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtc
 	}, nil
 }
 
+// SetKeyspaceVtorcConfig is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetKeyspaceVtorcConfig(ctx context.Context, req *vtctldatapb.SetKeyspaceVtorcConfigRequest) (resp *vtctldatapb.SetKeyspaceVtorcConfigResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceVtorcConfig")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("disable_discovery", req.VtorcConfig.GetDisableDiscovery())
+	span.Annotate("disable_recovery", req.VtorcConfig.GetDisableRecovery())
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetKeyspaceVtorcConfig")
+	if lockErr != nil {
+		err = lockErr
+		return nil, err
+	}
+
+	defer unlock(&err)
+
+	ki, err := s.ts.GetKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	ki.VtorcConfig = req.VtorcConfig
+
+	err = s.ts.UpdateKeyspace(ctx, ki)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetKeyspaceVtorcConfigResponse{
+		Keyspace: ki.Keyspace,
+	}, nil
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetShardIsPrimaryServing(ctx context.Context, req *vtctldatapb.SetShardIsPrimaryServingRequest) (resp *vtctldatapb.SetShardIsPrimaryServingResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetShardIsPrimaryServing")
@@ -3503,6 +3834,18 @@ func (s *VtctldServer) SetShardTabletControl(ctx context.Context, req *vtctldata
 	span.Annotate("disable_query_service", req.DisableQueryService)
 	span.Annotate("remove", req.Remove)
 
+	ttl, ok, err := protoutil.DurationFromProto(req.DeniedTablesTtl)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		span.Annotate("denied_tables_ttl", ttl.String())
+		if ttl <= 0 || req.Remove || len(req.DeniedTables) == 0 {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a denied tables TTL must be positive, and can only be set when adding denied tables")
+			return nil, err
+		}
+	}
+
 	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, req.Keyspace, "SetShardTabletControl")
 	if lockErr != nil {
 		err = lockErr
@@ -3512,7 +3855,13 @@ func (s *VtctldServer) SetShardTabletControl(ctx context.Context, req *vtctldata
 	defer unlock(&err)
 
 	si, err := s.ts.UpdateShardFields(ctx, req.Keyspace, req.Shard, func(si *topo.ShardInfo) error {
-		return si.UpdateDeniedTables(ctx, req.TabletType, req.Cells, req.Remove, req.DeniedTables)
+		if err := si.UpdateDeniedTables(ctx, req.TabletType, req.Cells, req.Remove, req.DeniedTables); err != nil {
+			return err
+		}
+		if ttl > 0 {
+			return si.SetDeniedTablesExpireTime(ctx, req.TabletType, req.DeniedTables, time.Now().Add(ttl))
+		}
+		return nil
 	})
 
 	switch {
@@ -3540,6 +3889,57 @@ func (s *VtctldServer) SetShardTabletControl(ctx context.Context, req *vtctldata
 	}, nil
 }
 
+// SetTableKillSwitch is part of the vtctlservicepb.VtctldServer interface.
+// The change is recorded as made by the requester, so that the tablets of the
+// keyspace can audit it.
+func (s *VtctldServer) SetTableKillSwitch(ctx context.Context, req *vtctldatapb.SetTableKillSwitchRequest) (resp *vtctldatapb.SetTableKillSwitchResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetTableKillSwitch")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table", req.Table)
+	span.Annotate("reads", req.Reads)
+	span.Annotate("writes", req.Writes)
+
+	ttl, ok, err := protoutil.DurationFromProto(req.Ttl)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		span.Annotate("ttl", ttl.String())
+		if ttl <= 0 || (!req.Reads && !req.Writes) {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "a table kill switch TTL must be positive, and can only be set when disabling the reads or the writes")
+			return nil, err
+		}
+	}
+
+	if _, err = s.ts.GetKeyspace(ctx, req.Keyspace); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ks := &topo.TableKillSwitch{
+		Table:  req.Table,
+		Reads:  req.Reads,
+		Writes: req.Writes,
+		Reason: req.Reason,
+		SetBy:  requester(ctx),
+	}
+	if ttl > 0 {
+		ks.ExpireTime = now.Add(ttl)
+	}
+	switches, err := s.ts.SetTableKillSwitch(ctx, req.Keyspace, ks, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetTableKillSwitchResponse{
+		KillSwitches: tableKillSwitchesToProto(switches),
+	}, nil
+}
+
 // SetWritable is part of the vtctldservicepb.VtctldServer interface.
 func (s *VtctldServer) SetWritable(ctx context.Context, req *vtctldatapb.SetWritableRequest) (resp *vtctldatapb.SetWritableResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetWritable")
@@ -4167,6 +4567,7 @@ func (s *VtctldServer) Validate(ctx context.Context, req *vtctldatapb.ValidateRe
 	defer panicHandler(&err)
 
 	span.Annotate("ping_tablets", req.PingTablets)
+	span.Annotate("deep", req.Deep)
 
 	resp = &vtctldatapb.ValidateResponse{}
 	getKeyspacesCtx, getKeyspacesCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
@@ -4290,6 +4691,10 @@ func (s *VtctldServer) Validate(ctx context.Context, req *vtctldatapb.ValidateRe
 	}
 
 	wg.Wait()
+
+	if req.Deep {
+		resp.Report = s.validateDeep(ctx, keyspaces, resp)
+	}
 	return resp, err
 }
 
@@ -5009,6 +5414,56 @@ func (s *VtctldServer) WorkflowDelete(ctx context.Context, req *vtctldatapb.Work
 	return resp, err
 }
 
+// WorkflowProfileDelete is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowProfileDelete(ctx context.Context, req *vtctldatapb.WorkflowProfileDeleteRequest) (resp *vtctldatapb.WorkflowProfileDeleteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowProfileDelete")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("profile", req.Name)
+
+	resp, err = s.ws.WorkflowProfileDelete(ctx, req)
+	return resp, err
+}
+
+// WorkflowProfileList is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowProfileList(ctx context.Context, req *vtctldatapb.WorkflowProfileListRequest) (resp *vtctldatapb.WorkflowProfileListResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowProfileList")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	resp, err = s.ws.WorkflowProfileList(ctx, req)
+	return resp, err
+}
+
+// WorkflowProfileSave is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowProfileSave(ctx context.Context, req *vtctldatapb.WorkflowProfileSaveRequest) (resp *vtctldatapb.WorkflowProfileSaveResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowProfileSave")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("profile", req.Name)
+
+	resp, err = s.ws.WorkflowProfileSave(ctx, req)
+	return resp, err
+}
+
+// WorkflowProfileShow is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowProfileShow(ctx context.Context, req *vtctldatapb.WorkflowProfileShowRequest) (resp *vtctldatapb.WorkflowProfileShowResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowProfileShow")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("profile", req.Name)
+
+	resp, err = s.ws.WorkflowProfileShow(ctx, req)
+	return resp, err
+}
+
 // WorkflowStatus is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowStatusRequest) (resp *vtctldatapb.WorkflowStatusResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowStatus")
@@ -5205,3 +5660,46 @@ func (s *VtctldServer) GetKeyspaceRoutingRules(ctx context.Context, req *vtctlda
 		KeyspaceRoutingRules: rules,
 	}, nil
 }
+
+// tableKillSwitchesToProto converts the table kill switches of a keyspace,
+// by table, to their proto messages.
+func tableKillSwitchesToProto(switches map[string]*topo.TableKillSwitch) map[string]*vtctldatapb.TableKillSwitch {
+	pbs := make(map[string]*vtctldatapb.TableKillSwitch, len(switches))
+	for table, ks := range switches {
+		pb := &vtctldatapb.TableKillSwitch{
+			Table:     ks.Table,
+			Reads:     ks.Reads,
+			Writes:    ks.Writes,
+			Reason:    ks.Reason,
+			SetBy:     ks.SetBy,
+			ClearedBy: ks.ClearedBy,
+		}
+		if !ks.ExpireTime.IsZero() {
+			pb.ExpireTime = protoutil.TimeToProto(ks.ExpireTime)
+		}
+		pbs[table] = pb
+	}
+	return pbs
+}
+
+// requester returns the identity of the client of the gRPC request: the
+// common name of its verified TLS client certificate, or its static auth
+// username, or else its remote address.
+func requester(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+		if cn := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName; cn != "" {
+			return cn
+		}
+	}
+	if username := servenv.StaticAuthUsernameFromContext(ctx); username != "" {
+		return username
+	}
+	if p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
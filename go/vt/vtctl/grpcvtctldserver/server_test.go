@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"sort"
 	"strings"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/peer"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/replication"
@@ -39,6 +41,7 @@ import (
 	"vitess.io/vitess/go/test/utils"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
 	"vitess.io/vitess/go/vt/proto/vttime"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
@@ -47,6 +50,7 @@ import (
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
@@ -59,6 +63,7 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 func init() {
@@ -3287,6 +3292,31 @@ func TestDeleteKeyspace(t *testing.T) {
 	}
 }
 
+func TestDeleteKeyspaceTableKillSwitches(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{Name: "testkeyspace", Keyspace: &topodatapb.Keyspace{}})
+	_, err := ts.SetTableKillSwitch(ctx, "testkeyspace", &topo.TableKillSwitch{Table: "t1", Writes: true}, time.Now())
+	require.NoError(t, err)
+
+	_, err = vtctld.DeleteKeyspace(ctx, &vtctldatapb.DeleteKeyspaceRequest{Keyspace: "testkeyspace"})
+	require.NoError(t, err)
+
+	// A keyspace created again with the same name has no kill switches.
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	_, _, err = conn.Get(ctx, topo.GetTableKillSwitchesPath("testkeyspace"))
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "got error %v", err)
+}
+
 func TestDeleteShards(t *testing.T) {
 	t.Parallel()
 
@@ -5442,6 +5472,83 @@ func TestGetCellsAliases(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetDeniedTables(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	expireTime := protoutil.TimeToProto(time.Date(2024, time.June, 11, 12, 34, 56, 0, time.UTC))
+	testutil.AddShards(ctx, t, ts,
+		&vtctldatapb.Shard{
+			Keyspace: "testkeyspace",
+			Name:     "-80",
+			Shard: &topodatapb.Shard{
+				TabletControls: []*topodatapb.Shard_TabletControl{
+					{
+						TabletType:              topodatapb.TabletType_REPLICA,
+						Cells:                   []string{"zone1"},
+						DeniedTables:            []string{"t1"},
+						DeniedTablesExpireTimes: map[string]*vttime.Time{"t1": expireTime},
+					},
+					{
+						// Only disables the query service, there are no denied tables.
+						TabletType: topodatapb.TabletType_RDONLY,
+						Cells:      []string{"zone2"},
+					},
+				},
+			},
+		},
+		&vtctldatapb.Shard{
+			Keyspace: "testkeyspace",
+			Name:     "80-",
+			Shard: &topodatapb.Shard{
+				TabletControls: []*topodatapb.Shard_TabletControl{
+					{
+						TabletType:   topodatapb.TabletType_PRIMARY,
+						DeniedTables: []string{"t1", "t2"},
+						// The expire time of a table that is no longer denied is not returned.
+						DeniedTablesExpireTimes: map[string]*vttime.Time{"t2": expireTime, "t3": expireTime},
+					},
+				},
+			},
+		},
+	)
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	resp, err := vtctld.GetDeniedTables(ctx, &vtctldatapb.GetDeniedTablesRequest{Keyspace: "testkeyspace"})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.GetDeniedTablesResponse{
+		DeniedTables: []*vtctldatapb.GetDeniedTablesResponse_ShardDeniedTables{
+			{
+				Keyspace:    "testkeyspace",
+				Shard:       "-80",
+				TabletType:  topodatapb.TabletType_REPLICA,
+				Cells:       []string{"zone1"},
+				Tables:      []string{"t1"},
+				ExpireTimes: map[string]*vttime.Time{"t1": expireTime},
+			},
+			{
+				Keyspace:    "testkeyspace",
+				Shard:       "80-",
+				TabletType:  topodatapb.TabletType_PRIMARY,
+				Tables:      []string{"t1", "t2"},
+				ExpireTimes: map[string]*vttime.Time{"t2": expireTime},
+			},
+		},
+	}, resp)
+
+	resp, err = vtctld.GetDeniedTables(ctx, &vtctldatapb.GetDeniedTablesRequest{Keyspace: "testkeyspace", Shards: []string{"80-"}})
+	require.NoError(t, err)
+	require.Len(t, resp.DeniedTables, 1)
+	assert.Equal(t, "80-", resp.DeniedTables[0].Shard)
+
+	_, err = vtctld.GetDeniedTables(ctx, &vtctldatapb.GetDeniedTablesRequest{Keyspace: "testkeyspace", Shards: []string{"-40"}})
+	assert.Error(t, err)
+}
+
 func TestGetFullStatus(t *testing.T) {
 	t.Parallel()
 
@@ -10791,6 +10898,75 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 	}
 }
 
+func TestSetKeyspaceVtorcConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		keyspaces   []*vtctldatapb.Keyspace
+		req         *vtctldatapb.SetKeyspaceVtorcConfigRequest
+		expected    *vtctldatapb.SetKeyspaceVtorcConfigResponse
+		expectedErr string
+	}{
+		{
+			name: "ok",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name: "ks1",
+					Keyspace: &topodatapb.Keyspace{
+						DurabilityPolicy: "none",
+					},
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceVtorcConfigRequest{
+				Keyspace: "ks1",
+				VtorcConfig: &topodatapb.VtorcConfig{
+					DisableRecovery: true,
+				},
+			},
+			expected: &vtctldatapb.SetKeyspaceVtorcConfigResponse{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy: "none",
+					VtorcConfig: &topodatapb.VtorcConfig{
+						DisableRecovery: true,
+					},
+				},
+			},
+		},
+		{
+			name: "keyspace not found",
+			req: &vtctldatapb.SetKeyspaceVtorcConfigRequest{
+				Keyspace: "ks1",
+			},
+			expectedErr: "node doesn't exist: keyspaces/ks1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddKeyspaces(ctx, t, ts, tt.keyspaces...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			resp, err := vtctld.SetKeyspaceVtorcConfig(ctx, tt.req)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestSetShardIsPrimaryServing(t *testing.T) {
 	t.Parallel()
 
@@ -11138,6 +11314,94 @@ func TestSetShardTabletControl(t *testing.T) {
 	}
 }
 
+func TestSetShardTabletControlDeniedTablesTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{
+		Keyspace: "testkeyspace",
+		Name:     "-",
+	})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	for _, req := range []*vtctldatapb.SetShardTabletControlRequest{
+		{DeniedTablesTtl: protoutil.DurationToProto(-time.Hour), DeniedTables: []string{"t1"}},
+		{DeniedTablesTtl: protoutil.DurationToProto(time.Hour), Remove: true},
+		{DeniedTablesTtl: protoutil.DurationToProto(time.Hour), DisableQueryService: true},
+	} {
+		req.Keyspace, req.Shard, req.TabletType = "testkeyspace", "-", topodatapb.TabletType_REPLICA
+		_, err := vtctld.SetShardTabletControl(ctx, req)
+		assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "request %v", req)
+	}
+
+	before := time.Now()
+	resp, err := vtctld.SetShardTabletControl(ctx, &vtctldatapb.SetShardTabletControlRequest{
+		Keyspace:        "testkeyspace",
+		Shard:           "-",
+		TabletType:      topodatapb.TabletType_REPLICA,
+		DeniedTables:    []string{"t1"},
+		DeniedTablesTtl: protoutil.DurationToProto(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Shard.TabletControls, 1)
+	require.Len(t, resp.Shard.TabletControls[0].DeniedTablesExpireTimes, 1)
+	expireTime := protoutil.TimeFromProto(resp.Shard.TabletControls[0].DeniedTablesExpireTimes["t1"])
+	assert.False(t, expireTime.Before(before.Add(time.Hour)), "expire time %v is less than an hour from %v", expireTime, before)
+	assert.True(t, expireTime.Before(time.Now().Add(time.Hour+time.Second)), "expire time %v is too far ahead", expireTime)
+}
+
+func TestSetTableKillSwitch(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{Name: "testkeyspace", Keyspace: &topodatapb.Keyspace{}})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+	// The changes are recorded as made by the client.
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234}})
+
+	resp, err := vtctld.SetTableKillSwitch(ctx, &vtctldatapb.SetTableKillSwitchRequest{
+		Keyspace: "testkeyspace",
+		Table:    "t1",
+		Writes:   true,
+		Reason:   "corruption",
+		Ttl:      protoutil.DurationToProto(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.KillSwitches, 1)
+	ks := resp.KillSwitches["t1"]
+	assert.True(t, ks.Writes)
+	assert.False(t, ks.Reads)
+	assert.Equal(t, "corruption", ks.Reason)
+	assert.Equal(t, "192.0.2.1:1234", ks.SetBy)
+	assert.NotNil(t, ks.ExpireTime)
+
+	getResp, err := vtctld.GetTableKillSwitches(ctx, &vtctldatapb.GetTableKillSwitchesRequest{Keyspace: "testkeyspace"})
+	require.NoError(t, err)
+	utils.MustMatch(t, resp.KillSwitches, getResp.KillSwitches)
+
+	// Neither disabling the reads nor the writes clears the kill switch.
+	resp, err = vtctld.SetTableKillSwitch(ctx, &vtctldatapb.SetTableKillSwitchRequest{Keyspace: "testkeyspace", Table: "t1"})
+	require.NoError(t, err)
+	ks = resp.KillSwitches["t1"]
+	assert.False(t, ks.Writes)
+	assert.Equal(t, "192.0.2.1:1234", ks.ClearedBy)
+
+	_, err = vtctld.SetTableKillSwitch(ctx, &vtctldatapb.SetTableKillSwitchRequest{Keyspace: "testkeyspace", Table: "t1", Ttl: protoutil.DurationToProto(time.Hour)})
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = vtctld.SetTableKillSwitch(ctx, &vtctldatapb.SetTableKillSwitchRequest{Keyspace: "testkeyspace", Reads: true})
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = vtctld.SetTableKillSwitch(ctx, &vtctldatapb.SetTableKillSwitchRequest{Keyspace: "otherkeyspace", Table: "t1", Reads: true})
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "got error %v", err)
+}
+
 func TestSetWritable(t *testing.T) {
 	t.Parallel()
 
@@ -13144,6 +13408,135 @@ func TestValidate(t *testing.T) {
 	}, resp)
 }
 
+func TestValidateDeep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	tmc := testutil.TabletManagerClient{
+		GetSchemaResults: map[string]struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{},
+	}
+	tablets := []*topodatapb.Tablet{
+		{
+			Keyspace: "ks1",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  100,
+			},
+			Hostname: "ks1-primary",
+		},
+		{
+			Keyspace: "ks1",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_REPLICA,
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  101,
+			},
+			Hostname: "ks1-replica",
+		},
+		{
+			Keyspace: "ks2",
+			Shard:    "-",
+			Type:     topodatapb.TabletType_PRIMARY,
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone2",
+				Uid:  200,
+			},
+			Hostname: "ks2-primary",
+		},
+	}
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary:  true,
+		ForceSetShardPrimary: true,
+		SkipShardCreation:    false,
+	}, tablets...)
+	// A tablet whose keyspace was deleted without it.
+	err := ts.CreateTablet(ctx, &topodatapb.Tablet{
+		Keyspace: "ks3",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone2",
+			Uid:  300,
+		},
+		Hostname: "ks3-replica",
+	})
+	require.NoError(t, err)
+	err = ts.SaveVSchema(ctx, "ks2", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"v1": {Type: "no_such_vindex"},
+		},
+	})
+	require.NoError(t, err)
+
+	// The replica of ks1 has a table that its primary doesn't have, and runs
+	// another version.
+	setSchema := func(alias *topodatapb.TabletAlias, tables ...string) {
+		schema := &tabletmanagerdatapb.SchemaDefinition{}
+		for _, table := range tables {
+			schema.TableDefinitions = append(schema.TableDefinitions, &tabletmanagerdatapb.TableDefinition{
+				Name:    table,
+				Type:    tmutils.TableBaseTable,
+				Columns: []string{"c1"},
+			})
+		}
+		tmc.GetSchemaResults[topoproto.TabletAliasString(alias)] = struct {
+			Schema *tabletmanagerdatapb.SchemaDefinition
+			Error  error
+		}{Schema: schema}
+	}
+	setSchema(tablets[0].Alias, "t1")
+	setSchema(tablets[1].Alias, "t1", "t2")
+	setSchema(tablets[2].Alias, "t1")
+	SetVersionFunc(testutil.MockGetVersionFromTablet(map[string]string{
+		"ks1-primary:0": "version1",
+		"ks1-replica:0": "version2",
+		"ks2-primary:0": "version1",
+	}))
+
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(vtenv.NewTestEnv(), ts)
+	})
+
+	// The report is only built for deep validations.
+	resp, err := vtctld.Validate(ctx, &vtctldatapb.ValidateRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Report)
+
+	resp, err = vtctld.Validate(ctx, &vtctldatapb.ValidateRequest{Deep: true})
+	require.NoError(t, err)
+
+	type issue struct {
+		severity vtctldatapb.ValidationIssue_Severity
+		check    string
+		keyspace string
+		shard    string
+		message  string
+	}
+	want := []issue{
+		{vtctldatapb.ValidationIssue_ERROR, "vschema", "ks2", "", "invalid vschema"},
+		{vtctldatapb.ValidationIssue_WARNING, "schema", "ks1", "-", "zone1-0000000101 has an extra table named t2"},
+		{vtctldatapb.ValidationIssue_WARNING, "version", "ks1", "-", "is different than replica zone1-0000000101"},
+		{vtctldatapb.ValidationIssue_WARNING, "orphan_tablet", "ks3", "-", "tablet zone2-0000000300 belongs to keyspace ks3, which doesn't exist"},
+	}
+	require.Len(t, resp.Report, len(want), "report: %v", resp.Report)
+	for i, w := range want {
+		got := resp.Report[i]
+		assert.Equal(t, w.severity, got.Severity, "issue %d: %v", i, got)
+		assert.Equal(t, w.check, got.Check, "issue %d: %v", i, got)
+		assert.Equal(t, w.keyspace, got.Keyspace, "issue %d: %v", i, got)
+		assert.Equal(t, w.shard, got.Shard, "issue %d: %v", i, got)
+		assert.Contains(t, got.Message, w.message, "issue %d", i)
+	}
+	utils.MustMatch(t, &topodatapb.TabletAlias{Cell: "zone2", Uid: 300}, resp.Report[3].TabletAlias)
+}
+
 func TestValidateSchemaKeyspace(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
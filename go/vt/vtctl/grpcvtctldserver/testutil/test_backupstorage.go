@@ -17,9 +17,14 @@ limitations under the License.
 package testutil
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"path"
 	"sort"
+	"strings"
+	"sync"
 
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 )
@@ -32,6 +37,11 @@ type backupStorage struct {
 	Backups map[string][]string
 	// ListBackupsError is returned from ListBackups when it is non-nil.
 	ListBackupsError error
+	// Files is a mapping of dir/name/filename to the contents of the files
+	// added to the backups started with StartBackup.
+	Files map[string][]byte
+
+	mu sync.Mutex
 }
 
 // ListBackups is part of the backupstorage.BackupStorage interface.
@@ -55,10 +65,19 @@ func (bs *backupStorage) ListBackups(ctx context.Context, dir string) ([]backups
 	return handles, nil
 }
 
-// RemoveBackup is part of the backupstorage.BackupStorage interface.
+// RemoveBackup is part of the backupstorage.BackupStorage interface. It also
+// removes the files of the backups started with StartBackup, ended or not.
 func (bs *backupStorage) RemoveBackup(ctx context.Context, dir string, name string) error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	removedFiles := bs.removeFiles(dir, name)
+
 	bucket, ok := bs.Backups[dir]
 	if !ok {
+		if removedFiles {
+			return nil
+		}
 		return fmt.Errorf("no bucket for key %s in testutil.BackupStorage", dir)
 	}
 
@@ -71,6 +90,9 @@ func (bs *backupStorage) RemoveBackup(ctx context.Context, dir string, name stri
 	}
 
 	if idx == -1 {
+		if removedFiles {
+			return nil
+		}
 		return fmt.Errorf("no backup found for %s/%s", dir, name)
 	}
 
@@ -78,6 +100,20 @@ func (bs *backupStorage) RemoveBackup(ctx context.Context, dir string, name stri
 	return nil
 }
 
+// StartBackup is part of the backupstorage.BackupStorage interface.
+func (bs *backupStorage) StartBackup(ctx context.Context, dir string, name string) (backupstorage.BackupHandle, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	for _, backup := range bs.Backups[dir] {
+		if backup == name {
+			return nil, fmt.Errorf("backup %s/%s already exists", dir, name)
+		}
+	}
+
+	return &backupHandle{bs: bs, directory: dir, name: name}, nil
+}
+
 // Close is part of the backupstorage.BackupStorage interface.
 func (bs *backupStorage) Close() error { return nil }
 
@@ -85,6 +121,7 @@ func (bs *backupStorage) Close() error { return nil }
 type backupHandle struct {
 	backupstorage.BackupHandle
 
+	bs        *backupStorage
 	directory string
 	name      string
 }
@@ -92,6 +129,64 @@ type backupHandle struct {
 func (bh *backupHandle) Directory() string { return bh.directory }
 func (bh *backupHandle) Name() string      { return bh.name }
 
+// AddFile is part of the backupstorage.BackupHandle interface. The contents of
+// the file are stored in BackupStorage.Files when the file is closed.
+func (bh *backupHandle) AddFile(ctx context.Context, filename string, filesize int64) (io.WriteCloser, error) {
+	return &backupFile{bh: bh, filename: filename}, nil
+}
+
+// EndBackup is part of the backupstorage.BackupHandle interface.
+func (bh *backupHandle) EndBackup(ctx context.Context) error {
+	bh.bs.mu.Lock()
+	defer bh.bs.mu.Unlock()
+
+	bh.bs.Backups[bh.directory] = append(bh.bs.Backups[bh.directory], bh.name)
+	return nil
+}
+
+// AbortBackup is part of the backupstorage.BackupHandle interface.
+func (bh *backupHandle) AbortBackup(ctx context.Context) error {
+	bh.bs.mu.Lock()
+	defer bh.bs.mu.Unlock()
+
+	bh.bs.removeFiles(bh.directory, bh.name)
+	return nil
+}
+
+// removeFiles removes the files of a backup, and returns whether it had any.
+// It must be called with bs.mu held.
+func (bs *backupStorage) removeFiles(dir string, name string) bool {
+	prefix := path.Join(dir, name) + "/"
+	removed := false
+	for filename := range bs.Files {
+		if strings.HasPrefix(filename, prefix) {
+			delete(bs.Files, filename)
+			removed = true
+		}
+	}
+	return removed
+}
+
+// backupFile is a file added to a backupHandle.
+type backupFile struct {
+	bytes.Buffer
+
+	bh       *backupHandle
+	filename string
+}
+
+// Close stores the contents of the file in the backup storage.
+func (f *backupFile) Close() error {
+	f.bh.bs.mu.Lock()
+	defer f.bh.bs.mu.Unlock()
+
+	if f.bh.bs.Files == nil {
+		f.bh.bs.Files = map[string][]byte{}
+	}
+	f.bh.bs.Files[path.Join(f.bh.directory, f.bh.name, f.filename)] = f.Bytes()
+	return nil
+}
+
 // handlesByName implements the sort interface for backup handles by Name().
 type handlesByName []backupstorage.BackupHandle
 
@@ -285,6 +285,11 @@ type TabletManagerClient struct {
 		Error  error
 	}
 	// keyed by tablet alias.
+	ReadVReplicationWorkflowsResults map[string]struct {
+		Response *tabletmanagerdatapb.ReadVReplicationWorkflowsResponse
+		Error    error
+	}
+	// keyed by tablet alias.
 	RefreshStateResults map[string]error
 	// keyed by `<tablet_alias>/<wait_pos>`.
 	ReloadSchemaDelays map[string]time.Duration
@@ -875,6 +880,20 @@ func (fake *TabletManagerClient) PromoteReplica(ctx context.Context, tablet *top
 	return "", assert.AnError
 }
 
+// ReadVReplicationWorkflows is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) ReadVReplicationWorkflows(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ReadVReplicationWorkflowsRequest) (*tabletmanagerdatapb.ReadVReplicationWorkflowsResponse, error) {
+	if fake.ReadVReplicationWorkflowsResults == nil {
+		return nil, fmt.Errorf("%w: no ReadVReplicationWorkflows results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.ReadVReplicationWorkflowsResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no ReadVReplicationWorkflows result set for tablet %s", assert.AnError, key)
+}
+
 // RefreshState is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) RefreshState(ctx context.Context, tablet *topodatapb.Tablet) error {
 	if fake.RefreshStateResults == nil {
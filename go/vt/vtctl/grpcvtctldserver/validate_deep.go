@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// The checks of a deep validation, which label the issues of its report.
+const (
+	validationCheckTopo             = "topo"
+	validationCheckReplicationGraph = "replication_graph"
+	validationCheckSchema           = "schema"
+	validationCheckVSchema          = "vschema"
+	validationCheckVersion          = "version"
+	validationCheckOrphanTablet     = "orphan_tablet"
+)
+
+// validationReport collects the issues of a deep validation. It is safe
+// for concurrent use.
+type validationReport struct {
+	mu     sync.Mutex
+	issues []*vtctldatapb.ValidationIssue
+}
+
+func (r *validationReport) add(issue *vtctldatapb.ValidationIssue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.issues = append(r.issues, issue)
+}
+
+// addResults adds the results of one of the Validate* RPCs. The results
+// of the keyspace that are repeated in the results of a shard are only
+// reported for that shard.
+func (r *validationReport) addResults(check string, severity vtctldatapb.ValidationIssue_Severity, keyspace string, results []string, resultsByShard map[string]*vtctldatapb.ValidateShardResponse) {
+	shardResults := make(map[string]bool)
+	for shard, shardResp := range resultsByShard {
+		for _, result := range shardResp.GetResults() {
+			shardResults[result] = true
+			r.add(&vtctldatapb.ValidationIssue{
+				Severity: severity,
+				Check:    check,
+				Keyspace: keyspace,
+				Shard:    shard,
+				Message:  result,
+			})
+		}
+	}
+	for _, result := range results {
+		if shardResults[result] {
+			continue
+		}
+		r.add(&vtctldatapb.ValidationIssue{
+			Severity: severity,
+			Check:    check,
+			Keyspace: keyspace,
+			Message:  result,
+		})
+	}
+}
+
+// sorted returns the issues ordered by severity, most severe first, then
+// by keyspace, shard and check, so that reports can be compared.
+func (r *validationReport) sorted() []*vtctldatapb.ValidationIssue {
+	sort.SliceStable(r.issues, func(i, j int) bool {
+		a, b := r.issues[i], r.issues[j]
+		switch {
+		case a.Severity != b.Severity:
+			return a.Severity > b.Severity
+		case a.Keyspace != b.Keyspace:
+			return a.Keyspace < b.Keyspace
+		case a.Shard != b.Shard:
+			return a.Shard < b.Shard
+		case a.Check != b.Check:
+			return a.Check < b.Check
+		}
+		return a.Message < b.Message
+	})
+	return r.issues
+}
+
+// validateDeep builds the report of a deep validation. It turns the results
+// of the topology and replication graph validations that were already done
+// into issues, then validates the schema, vschema and versions of every
+// keyspace and looks for orphan tablets.
+func (s *VtctldServer) validateDeep(ctx context.Context, keyspaces []string, resp *vtctldatapb.ValidateResponse) []*vtctldatapb.ValidationIssue {
+	report := &validationReport{}
+	report.addResults(validationCheckTopo, vtctldatapb.ValidationIssue_ERROR, "", resp.Results, nil)
+	for keyspace, keyspaceResp := range resp.ResultsByKeyspace {
+		report.addResults(validationCheckTopo, vtctldatapb.ValidationIssue_ERROR, keyspace, keyspaceResp.Results, nil)
+		report.addResults(validationCheckReplicationGraph, vtctldatapb.ValidationIssue_ERROR, keyspace, nil, keyspaceResp.ResultsByShard)
+	}
+
+	// The shards of the keyspaces, nil for those whose shards can't be read.
+	shardsByKeyspace := make(map[string]map[string]bool, len(keyspaces))
+	var wg sync.WaitGroup
+	for _, keyspace := range keyspaces {
+		shardsByKeyspace[keyspace] = nil
+		shards, err := s.ts.GetShardNames(ctx, keyspace)
+		if err != nil {
+			// Already reported by the validation of the keyspace.
+			continue
+		}
+		shardsByKeyspace[keyspace] = make(map[string]bool, len(shards))
+		for _, shard := range shards {
+			shardsByKeyspace[keyspace][shard] = true
+		}
+
+		wg.Add(1)
+		go func(keyspace string, shards []string) {
+			defer wg.Done()
+			s.validateKeyspaceDeep(ctx, keyspace, shards, report)
+		}(keyspace, shards)
+	}
+
+	s.validateOrphanTablets(ctx, shardsByKeyspace, report)
+	wg.Wait()
+	return report.sorted()
+}
+
+// validateKeyspaceDeep adds the schema, vschema and version issues of a
+// keyspace to the report.
+func (s *VtctldServer) validateKeyspaceDeep(ctx context.Context, keyspace string, shards []string, report *validationReport) {
+	schemaResp, err := s.ValidateSchemaKeyspace(ctx, &vtctldatapb.ValidateSchemaKeyspaceRequest{
+		Keyspace: keyspace,
+	})
+	if err != nil {
+		report.addResults(validationCheckSchema, vtctldatapb.ValidationIssue_WARNING, keyspace, []string{err.Error()}, nil)
+	} else {
+		report.addResults(validationCheckSchema, vtctldatapb.ValidationIssue_WARNING, keyspace, schemaResp.Results, schemaResp.ResultsByShard)
+	}
+
+	versionResp, err := s.ValidateVersionKeyspace(ctx, &vtctldatapb.ValidateVersionKeyspaceRequest{
+		Keyspace: keyspace,
+	})
+	if err != nil {
+		report.addResults(validationCheckVersion, vtctldatapb.ValidationIssue_WARNING, keyspace, []string{err.Error()}, nil)
+	} else {
+		report.addResults(validationCheckVersion, vtctldatapb.ValidationIssue_WARNING, keyspace, versionResp.Results, versionResp.ResultsByShard)
+	}
+
+	vs, err := s.ts.GetVSchema(ctx, keyspace)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		// Keyspaces without a vschema are valid.
+		return
+	case err != nil:
+		report.addResults(validationCheckVSchema, vtctldatapb.ValidationIssue_ERROR, keyspace, []string{fmt.Sprintf("GetVSchema(%s) failed: %v", keyspace, err)}, nil)
+		return
+	}
+	if _, err := vindexes.BuildKeyspace(vs, s.ws.SQLParser()); err != nil {
+		report.addResults(validationCheckVSchema, vtctldatapb.ValidationIssue_ERROR, keyspace, []string{fmt.Sprintf("invalid vschema: %v", err)}, nil)
+		return
+	}
+	if !vs.Sharded {
+		// The tables of unsharded keyspaces don't need to be in the vschema.
+		return
+	}
+	vschemaResp, err := s.ValidateVSchema(ctx, &vtctldatapb.ValidateVSchemaRequest{
+		Keyspace: keyspace,
+		Shards:   shards,
+	})
+	if err != nil {
+		report.addResults(validationCheckVSchema, vtctldatapb.ValidationIssue_WARNING, keyspace, []string{err.Error()}, nil)
+		return
+	}
+	report.addResults(validationCheckVSchema, vtctldatapb.ValidationIssue_WARNING, keyspace, vschemaResp.Results, vschemaResp.ResultsByShard)
+}
+
+// validateOrphanTablets adds the tablets of all the known cells that belong
+// to a keyspace or shard that doesn't exist to the report.
+func (s *VtctldServer) validateOrphanTablets(ctx context.Context, shardsByKeyspace map[string]map[string]bool, report *validationReport) {
+	cells, err := s.ts.GetKnownCells(ctx)
+	if err != nil {
+		report.addResults(validationCheckTopo, vtctldatapb.ValidationIssue_ERROR, "", []string{fmt.Sprintf("GetKnownCells failed: %v", err)}, nil)
+		return
+	}
+
+	for _, cell := range cells {
+		tablets, err := s.ts.GetTabletsByCell(ctx, cell, nil)
+		if err != nil {
+			report.addResults(validationCheckTopo, vtctldatapb.ValidationIssue_ERROR, "", []string{fmt.Sprintf("GetTabletsByCell(%v) failed: %v", cell, err)}, nil)
+			continue
+		}
+
+		for _, tablet := range tablets {
+			shards, ok := shardsByKeyspace[tablet.Keyspace]
+			var message string
+			switch {
+			case !ok:
+				message = fmt.Sprintf("tablet %v belongs to keyspace %v, which doesn't exist", tablet.AliasString(), tablet.Keyspace)
+			case shards == nil:
+				// The shards of the keyspace are unknown.
+				continue
+			case !shards[tablet.Shard]:
+				message = fmt.Sprintf("tablet %v belongs to shard %v/%v, which doesn't exist", tablet.AliasString(), tablet.Keyspace, tablet.Shard)
+			default:
+				continue
+			}
+			report.add(&vtctldatapb.ValidationIssue{
+				Severity:    vtctldatapb.ValidationIssue_WARNING,
+				Check:       validationCheckOrphanTablet,
+				Keyspace:    tablet.Keyspace,
+				Shard:       tablet.Shard,
+				TabletAlias: tablet.Alias,
+				Message:     message,
+			})
+		}
+	}
+}
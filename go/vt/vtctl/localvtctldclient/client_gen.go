@@ -251,6 +251,11 @@ func (client *localVtctldClient) ExecuteMultiFetchAsDBA(ctx context.Context, in
 	return client.s.ExecuteMultiFetchAsDBA(ctx, in)
 }
 
+// ExportTables is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ExportTables(ctx context.Context, in *vtctldatapb.ExportTablesRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTablesResponse, error) {
+	return client.s.ExportTables(ctx, in)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	return client.s.FindAllShardsInKeyspace(ctx, in)
@@ -281,6 +286,11 @@ func (client *localVtctldClient) GetCellsAliases(ctx context.Context, in *vtctld
 	return client.s.GetCellsAliases(ctx, in)
 }
 
+// GetDeniedTables is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetDeniedTables(ctx context.Context, in *vtctldatapb.GetDeniedTablesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetDeniedTablesResponse, error) {
+	return client.s.GetDeniedTables(ctx, in)
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetFullStatus(ctx context.Context, in *vtctldatapb.GetFullStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFullStatusResponse, error) {
 	return client.s.GetFullStatus(ctx, in)
@@ -356,6 +366,11 @@ func (client *localVtctldClient) GetSrvVSchemas(ctx context.Context, in *vtctlda
 	return client.s.GetSrvVSchemas(ctx, in)
 }
 
+// GetTableKillSwitches is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetTableKillSwitches(ctx context.Context, in *vtctldatapb.GetTableKillSwitchesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTableKillSwitchesResponse, error) {
+	return client.s.GetTableKillSwitches(ctx, in)
+}
+
 // GetTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetTablet(ctx context.Context, in *vtctldatapb.GetTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTabletResponse, error) {
 	return client.s.GetTablet(ctx, in)
@@ -577,11 +592,21 @@ func (client *localVtctldClient) RunHealthCheck(ctx context.Context, in *vtctlda
 	return client.s.RunHealthCheck(ctx, in)
 }
 
+// SafeDeleteKeyspace is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SafeDeleteKeyspace(ctx context.Context, in *vtctldatapb.SafeDeleteKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.SafeDeleteKeyspaceResponse, error) {
+	return client.s.SafeDeleteKeyspace(ctx, in)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	return client.s.SetKeyspaceDurabilityPolicy(ctx, in)
 }
 
+// SetKeyspaceVtorcConfig is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetKeyspaceVtorcConfig(ctx context.Context, in *vtctldatapb.SetKeyspaceVtorcConfigRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceVtorcConfigResponse, error) {
+	return client.s.SetKeyspaceVtorcConfig(ctx, in)
+}
+
 // SetShardIsPrimaryServing is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetShardIsPrimaryServing(ctx context.Context, in *vtctldatapb.SetShardIsPrimaryServingRequest, opts ...grpc.CallOption) (*vtctldatapb.SetShardIsPrimaryServingResponse, error) {
 	return client.s.SetShardIsPrimaryServing(ctx, in)
@@ -592,6 +617,11 @@ func (client *localVtctldClient) SetShardTabletControl(ctx context.Context, in *
 	return client.s.SetShardTabletControl(ctx, in)
 }
 
+// SetTableKillSwitch is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetTableKillSwitch(ctx context.Context, in *vtctldatapb.SetTableKillSwitchRequest, opts ...grpc.CallOption) (*vtctldatapb.SetTableKillSwitchResponse, error) {
+	return client.s.SetTableKillSwitch(ctx, in)
+}
+
 // SetWritable is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetWritable(ctx context.Context, in *vtctldatapb.SetWritableRequest, opts ...grpc.CallOption) (*vtctldatapb.SetWritableResponse, error) {
 	return client.s.SetWritable(ctx, in)
@@ -727,6 +757,26 @@ func (client *localVtctldClient) WorkflowDelete(ctx context.Context, in *vtctlda
 	return client.s.WorkflowDelete(ctx, in)
 }
 
+// WorkflowProfileDelete is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) WorkflowProfileDelete(ctx context.Context, in *vtctldatapb.WorkflowProfileDeleteRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileDeleteResponse, error) {
+	return client.s.WorkflowProfileDelete(ctx, in)
+}
+
+// WorkflowProfileList is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) WorkflowProfileList(ctx context.Context, in *vtctldatapb.WorkflowProfileListRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileListResponse, error) {
+	return client.s.WorkflowProfileList(ctx, in)
+}
+
+// WorkflowProfileSave is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) WorkflowProfileSave(ctx context.Context, in *vtctldatapb.WorkflowProfileSaveRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileSaveResponse, error) {
+	return client.s.WorkflowProfileSave(ctx, in)
+}
+
+// WorkflowProfileShow is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) WorkflowProfileShow(ctx context.Context, in *vtctldatapb.WorkflowProfileShowRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowProfileShowResponse, error) {
+	return client.s.WorkflowProfileShow(ctx, in)
+}
+
 // WorkflowStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) WorkflowStatus(ctx context.Context, in *vtctldatapb.WorkflowStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
 	return client.s.WorkflowStatus(ctx, in)
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	copyProgressLabels = []string{"keyspace", "workflow", "table", "state"}
+
+	workflowTableCopyStatesMu sync.Mutex
+	// workflowTableCopyStates are the copy states of the tables of each
+	// workflow in the copy phase, by keyspace.workflow, as of the last time
+	// its copy progress was read. The tables that were copied since then are
+	// kept with nothing remaining, until the workflow completes.
+	workflowTableCopyStates = map[string]map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState{}
+
+	// tableCopyRowsGauge and tableCopyBytesGauge export workflowTableCopyStates,
+	// so that long running migrations can be followed on a dashboard. The
+	// state label is one of copied, total or remaining.
+	tableCopyRowsGauge = stats.NewGaugesFuncWithMultiLabels(
+		"WorkflowTableCopyRows",
+		"Number of rows of a table copied by a workflow, in total and remaining, as estimated from the table statistics",
+		copyProgressLabels,
+		func() map[string]int64 {
+			return tableCopyCounts(func(state *vtctldatapb.WorkflowStatusResponse_TableCopyState) (int64, int64, int64) {
+				return state.RowsCopied, state.RowsTotal, state.RowsRemaining
+			})
+		})
+	tableCopyBytesGauge = stats.NewGaugesFuncWithMultiLabels(
+		"WorkflowTableCopyBytes",
+		"Number of bytes of a table copied by a workflow, in total and remaining, as estimated from the table statistics",
+		copyProgressLabels,
+		func() map[string]int64 {
+			return tableCopyCounts(func(state *vtctldatapb.WorkflowStatusResponse_TableCopyState) (int64, int64, int64) {
+				return state.BytesCopied, state.BytesTotal, state.BytesRemaining
+			})
+		})
+)
+
+// tableCopyCounts returns the copied, total and remaining counts of the
+// tables of the workflows in the copy phase, by keyspace.workflow.table.state.
+func tableCopyCounts(counts func(*vtctldatapb.WorkflowStatusResponse_TableCopyState) (copied, total, remaining int64)) map[string]int64 {
+	workflowTableCopyStatesMu.Lock()
+	defer workflowTableCopyStatesMu.Unlock()
+
+	result := make(map[string]int64)
+	for workflow, states := range workflowTableCopyStates {
+		for table, state := range states {
+			copied, total, remaining := counts(state)
+			result[workflow+"."+table+".copied"] = copied
+			result[workflow+"."+table+".total"] = total
+			result[workflow+"."+table+".remaining"] = remaining
+		}
+	}
+	return result
+}
+
+// tableCopyStates converts the copy progress of the tables of a workflow into
+// their copy states, and exports them in the copy progress stats.
+func tableCopyStates(keyspace, workflow string, progress *copyProgress) map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState {
+	var states map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState
+	if progress != nil {
+		states = make(map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState, len(*progress))
+		for table, tp := range *progress {
+			state := &vtctldatapb.WorkflowStatusResponse_TableCopyState{
+				RowsCopied:     tp.TargetRowCount,
+				RowsTotal:      tp.SourceRowCount,
+				RowsRemaining:  max(tp.SourceRowCount-tp.TargetRowCount, 0),
+				BytesCopied:    tp.TargetTableSize,
+				BytesTotal:     tp.SourceTableSize,
+				BytesRemaining: max(tp.SourceTableSize-tp.TargetTableSize, 0),
+			}
+			if tp.SourceRowCount > 0 {
+				state.RowsPercentage = float32(100.0 * float64(tp.TargetRowCount) / float64(tp.SourceRowCount))
+			}
+			if tp.SourceTableSize > 0 {
+				state.BytesPercentage = float32(100.0 * float64(tp.TargetTableSize) / float64(tp.SourceTableSize))
+			}
+			states[table] = state
+		}
+	}
+	exportTableCopyStates(keyspace, workflow, states)
+	return states
+}
+
+// exportTableCopyStates updates the copy progress stats of the workflow. The
+// tables that were copied since the last update have nothing remaining, and
+// the workflow is removed from the stats once it has no table left to copy.
+func exportTableCopyStates(keyspace, workflow string, states map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState) {
+	workflowTableCopyStatesMu.Lock()
+	defer workflowTableCopyStatesMu.Unlock()
+
+	label := fmt.Sprintf("%s.%s", keyspace, workflow)
+	if len(states) == 0 {
+		delete(workflowTableCopyStates, label)
+		return
+	}
+	exported := make(map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState, len(states))
+	for table, state := range workflowTableCopyStates[label] {
+		if _, ok := states[table]; !ok {
+			copied := state.CloneVT()
+			copied.RowsRemaining = 0
+			copied.BytesRemaining = 0
+			exported[table] = copied
+		}
+	}
+	for table, state := range states {
+		exported[table] = state
+	}
+	workflowTableCopyStates[label] = exported
+}
+
+// forgetTableCopyStates removes the workflow from the copy progress stats.
+func forgetTableCopyStates(keyspace, workflow string) {
+	exportTableCopyStates(keyspace, workflow, nil)
+}
+
+// RefreshCopyProgress reads the copy progress of the workflows in the copy
+// phase, and exports it in the WorkflowTableCopyRows and WorkflowTableCopyBytes
+// gauges. The workflows that completed their copy phase, or went away, are
+// removed from the gauges, while the ones whose copy progress could not be
+// read keep their previous one. It is meant to be run periodically.
+func (s *Server) RefreshCopyProgress(ctx context.Context) error {
+	keyspaces, err := s.ts.GetKeyspaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	// copying are the workflows whose copy progress is kept in the stats.
+	copying := make(map[string]bool)
+	for _, keyspace := range keyspaces {
+		res, err := s.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{Keyspace: keyspace})
+		if err != nil {
+			log.Errorf("Failed to read the copy progress of the workflows of keyspace %s: %v", keyspace, err)
+			workflowTableCopyStatesMu.Lock()
+			for label := range workflowTableCopyStates {
+				if strings.HasPrefix(label, keyspace+".") {
+					copying[label] = true
+				}
+			}
+			workflowTableCopyStatesMu.Unlock()
+			continue
+		}
+		for _, wf := range res.GetWorkflows() {
+			if !isCopying(wf) {
+				continue
+			}
+			label := fmt.Sprintf("%s.%s", keyspace, wf.Name)
+			copying[label] = true
+			if _, err := s.getTableCopyStates(ctx, keyspace, wf.Name); err != nil {
+				log.Errorf("Failed to read the copy progress of workflow %s: %v", label, err)
+			}
+		}
+	}
+
+	workflowTableCopyStatesMu.Lock()
+	defer workflowTableCopyStatesMu.Unlock()
+	for label := range workflowTableCopyStates {
+		if !copying[label] {
+			delete(workflowTableCopyStates, label)
+		}
+	}
+	return nil
+}
+
+// getTableCopyStates returns the copy states of the tables that the workflow
+// is still copying.
+func (s *Server) getTableCopyStates(ctx context.Context, keyspace, workflow string) (map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState, error) {
+	ts, state, err := s.getWorkflowState(ctx, keyspace, workflow)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.GetCopyProgress(ctx, ts, state)
+	if err != nil {
+		return nil, err
+	}
+	return tableCopyStates(keyspace, workflow, progress), nil
+}
+
+// addTableCopyStates sets the copy states of the tables of the workflows that
+// have streams in the copy phase.
+func (s *Server) addTableCopyStates(ctx context.Context, keyspace string, workflows []*vtctldatapb.Workflow) error {
+	for _, workflow := range workflows {
+		if !isCopying(workflow) {
+			continue
+		}
+		states, err := s.getTableCopyStates(ctx, keyspace, workflow.Name)
+		if err != nil {
+			return vterrors.Wrapf(err, "failed to get the copy progress of workflow %s", workflow.Name)
+		}
+		workflow.TableCopyStates = states
+	}
+	return nil
+}
+
+// isCopying returns whether any stream of the workflow is in the copy phase.
+func isCopying(workflow *vtctldatapb.Workflow) bool {
+	for _, shardStreams := range workflow.ShardStreams {
+		for _, stream := range shardStreams.GetStreams() {
+			if stream.State == binlogdatapb.VReplicationWorkflowState_Copying.String() {
+				return true
+			}
+		}
+	}
+	return false
+}
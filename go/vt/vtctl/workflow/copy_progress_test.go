@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestTableCopyStates(t *testing.T) {
+	keyspace, workflow := "customer", t.Name()
+	progress := &copyProgress{
+		"t1": {TargetRowCount: 25, SourceRowCount: 100, TargetTableSize: 1024, SourceTableSize: 4096},
+		// The table statistics are estimates, so the target can be ahead of the source.
+		"t2": {TargetRowCount: 12, SourceRowCount: 10, TargetTableSize: 0, SourceTableSize: 0},
+	}
+
+	states := tableCopyStates(keyspace, workflow, progress)
+	require.Len(t, states, 2)
+	assert.Equal(t, &vtctldatapb.WorkflowStatusResponse_TableCopyState{
+		RowsCopied:      25,
+		RowsTotal:       100,
+		RowsPercentage:  25,
+		RowsRemaining:   75,
+		BytesCopied:     1024,
+		BytesTotal:      4096,
+		BytesPercentage: 25,
+		BytesRemaining:  3072,
+	}, states["t1"])
+	assert.Zero(t, states["t2"].RowsRemaining)
+	assert.Zero(t, states["t2"].BytesPercentage)
+
+	label := keyspace + "." + workflow
+	rows := tableCopyRowsGauge.Counts()
+	assert.EqualValues(t, 25, rows[label+".t1.copied"])
+	assert.EqualValues(t, 100, rows[label+".t1.total"])
+	assert.EqualValues(t, 75, rows[label+".t1.remaining"])
+	assert.EqualValues(t, 3072, tableCopyBytesGauge.Counts()[label+".t1.remaining"])
+
+	// A table that is no longer being copied has nothing remaining.
+	delete(*progress, "t1")
+	states = tableCopyStates(keyspace, workflow, progress)
+	require.Len(t, states, 1)
+	rows = tableCopyRowsGauge.Counts()
+	assert.Contains(t, rows, label+".t1.remaining")
+	assert.Zero(t, rows[label+".t1.remaining"])
+	assert.EqualValues(t, 25, rows[label+".t1.copied"])
+	assert.Zero(t, tableCopyBytesGauge.Counts()[label+".t1.remaining"])
+
+	// The workflow is removed from the stats once its copy phase completes.
+	assert.Nil(t, tableCopyStates(keyspace, workflow, nil))
+	assert.NotContains(t, tableCopyRowsGauge.Counts(), label+".t1.copied")
+	assert.NotContains(t, tableCopyBytesGauge.Counts(), label+".t2.copied")
+
+	// Or once it is deleted.
+	tableCopyStates(keyspace, workflow, progress)
+	assert.Contains(t, tableCopyRowsGauge.Counts(), label+".t2.copied")
+	forgetTableCopyStates(keyspace, workflow)
+	assert.NotContains(t, tableCopyRowsGauge.Counts(), label+".t2.copied")
+}
+
+func TestRefreshCopyProgress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	for i, keyspace := range []string{"targetks", "otherks"} {
+		tablet := &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: uint32(100 + i)},
+			Keyspace: keyspace,
+			Shard:    "0",
+			Type:     topodatapb.TabletType_PRIMARY,
+		}
+		require.NoError(t, ts.InitTablet(ctx, tablet, false, true, false))
+		_, err := ts.UpdateShardFields(ctx, keyspace, "0", func(si *topo.ShardInfo) error {
+			si.PrimaryAlias = tablet.Alias
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	state := &vtctldatapb.WorkflowStatusResponse_TableCopyState{RowsCopied: 1, RowsTotal: 2, RowsRemaining: 1}
+	workflowTableCopyStatesMu.Lock()
+	workflowTableCopyStates = map[string]map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState{
+		"targetks.wf":  {"t1": state},
+		"otherks.wf":   {"t1": state},
+		"removedks.wf": {"t1": state},
+	}
+	workflowTableCopyStatesMu.Unlock()
+
+	ws := NewServer(vtenv.NewTestEnv(), ts, &reverseReplicationTMC{failingKeyspace: "otherks"})
+	require.NoError(t, ws.RefreshCopyProgress(ctx))
+	// The workflows that went away are removed from the gauges, and the ones
+	// whose copy progress could not be read keep their previous one.
+	require.Equal(t, map[string]int64{
+		"otherks.wf.t1.copied":    1,
+		"otherks.wf.t1.total":     2,
+		"otherks.wf.t1.remaining": 1,
+	}, tableCopyRowsGauge.Counts())
+}
+
+func TestIsCopying(t *testing.T) {
+	workflow := &vtctldatapb.Workflow{
+		ShardStreams: map[string]*vtctldatapb.Workflow_ShardStream{
+			"-80/zone1-100": {Streams: []*vtctldatapb.Workflow_Stream{{State: binlogdatapb.VReplicationWorkflowState_Running.String()}}},
+			"80-/zone1-200": {Streams: []*vtctldatapb.Workflow_Stream{{State: binlogdatapb.VReplicationWorkflowState_Running.String()}}},
+		},
+	}
+	assert.False(t, isCopying(workflow))
+	workflow.ShardStreams["80-/zone1-200"].Streams[0].State = binlogdatapb.VReplicationWorkflowState_Copying.String()
+	assert.True(t, isCopying(workflow))
+}
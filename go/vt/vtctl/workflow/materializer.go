@@ -105,6 +105,11 @@ func (mz *materializer) getOptionsJSON() (string, error) {
 			vrOptions.SourceKeyspaceAlias = mz.ms.WorkflowOptions.SourceKeyspaceAlias
 		}
 	}
+	if options := mz.ms.WorkflowOptions; options != nil {
+		vrOptions.RelayLogMaxItems = options.RelayLogMaxItems
+		vrOptions.RelayLogMaxSize = options.RelayLogMaxSize
+		vrOptions.ParallelInsertWorkers = options.ParallelInsertWorkers
+	}
 	optionsJSON, err := json.Marshal(vrOptions)
 	if err != nil {
 		return "", err
@@ -201,6 +206,13 @@ func (mz *materializer) generateBinlogSources(ctx context.Context, targetShard *
 			rule := &binlogdatapb.Rule{
 				Match: ts.TargetTable,
 			}
+			if ts.UniqueKeyColumns != "" {
+				// The same columns identify the rows on both the source and
+				// the target, as MoveTables copies tables as they are.
+				rule.SourceUniqueKeyColumns = ts.UniqueKeyColumns
+				rule.TargetUniqueKeyColumns = ts.UniqueKeyColumns
+				rule.SourceUniqueKeyTargetColumns = ts.UniqueKeyColumns
+			}
 
 			if ts.SourceExpression == "" {
 				bls.Filter.Rules = append(bls.Filter.Rules, rule)
@@ -31,6 +31,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
@@ -572,6 +573,48 @@ func TestMoveTablesNoRoutingRules(t *testing.T) {
 	require.Zerof(t, len(rr.Rules), "routing rules should be empty, found %+v", rr.Rules)
 }
 
+// TestMoveTablesUniqueKeyColumns confirms that the unique key column hints of
+// a table are passed on to its vreplication rule.
+func TestMoveTablesUniqueKeyColumns(t *testing.T) {
+	uniqueKeyColumns, err := getUniqueKeyColumns(map[string]string{"t1": "c1, c 2"}, []string{"t1", "t2"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"t1": "c1,c+2"}, uniqueKeyColumns)
+
+	_, err = getUniqueKeyColumns(map[string]string{"t3": "c1"}, []string{"t1", "t2"})
+	require.ErrorContains(t, err, "table t3 which is not being moved")
+	_, err = getUniqueKeyColumns(map[string]string{"t1": "c1,,c2"}, []string{"t1", "t2"})
+	require.ErrorContains(t, err, "invalid unique key columns")
+
+	mz := &materializer{
+		ms: &vtctldatapb.MaterializeSettings{
+			Workflow:       "workflow",
+			SourceKeyspace: "sourceks",
+			TargetKeyspace: "targetks",
+			TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+				TargetTable:      "t1",
+				SourceExpression: "select * from t1",
+				UniqueKeyColumns: uniqueKeyColumns["t1"],
+			}, {
+				TargetTable:      "t2",
+				SourceExpression: "select * from t2",
+			}},
+		},
+		env: vtenv.NewTestEnv(),
+	}
+	shard := topo.NewShardInfo("targetks", "0", &topodatapb.Shard{}, nil)
+	blses, err := mz.generateBinlogSources(context.Background(), shard, []*topo.ShardInfo{shard}, true)
+	require.NoError(t, err)
+	require.Len(t, blses, 1)
+	rules := blses[0].Filter.Rules
+	require.Len(t, rules, 2)
+	require.Equal(t, "c1,c+2", rules[0].SourceUniqueKeyColumns)
+	require.Equal(t, "c1,c+2", rules[0].TargetUniqueKeyColumns)
+	require.Equal(t, "c1,c+2", rules[0].SourceUniqueKeyTargetColumns)
+	require.Empty(t, rules[1].SourceUniqueKeyColumns)
+	require.Empty(t, rules[1].TargetUniqueKeyColumns)
+	require.Empty(t, rules[1].SourceUniqueKeyTargetColumns)
+}
+
 func TestCreateLookupVindexFull(t *testing.T) {
 	ms := &vtctldatapb.MaterializeSettings{
 		Workflow:       "lookup",
@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// WorkflowProfileSave creates or replaces a workflow profile.
+func (s *Server) WorkflowProfileSave(ctx context.Context, req *vtctldatapb.WorkflowProfileSaveRequest) (*vtctldatapb.WorkflowProfileSaveResponse, error) {
+	profile := req.Profile.CloneVT()
+	if profile == nil {
+		profile = &vtctldatapb.WorkflowProfile{}
+	}
+	if profile.OnDdl != "" {
+		profile.OnDdl = strings.ToUpper(profile.OnDdl)
+		if _, ok := binlogdatapb.OnDDLAction_value[profile.OnDdl]; !ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid on_ddl value: %s", req.Profile.OnDdl)
+		}
+	}
+	if profile.RelayLogMaxItems < 0 || profile.RelayLogMaxSize < 0 || profile.ParallelInsertWorkers < 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "batch sizes and parallel insert workers cannot be negative")
+	}
+	if profile.ThrottleRatio < 0 || profile.ThrottleRatio > 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid throttle ratio %v, it must be between 0 and 1", profile.ThrottleRatio)
+	}
+	if err := s.ts.SaveWorkflowProfile(ctx, req.Name, profile); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.WorkflowProfileSaveResponse{}, nil
+}
+
+// WorkflowProfileDelete deletes a workflow profile.
+func (s *Server) WorkflowProfileDelete(ctx context.Context, req *vtctldatapb.WorkflowProfileDeleteRequest) (*vtctldatapb.WorkflowProfileDeleteResponse, error) {
+	if err := s.ts.DeleteWorkflowProfile(ctx, req.Name); err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return nil, profileNotExistsError(req.Name)
+		}
+		return nil, err
+	}
+	return &vtctldatapb.WorkflowProfileDeleteResponse{}, nil
+}
+
+// WorkflowProfileShow returns the settings of a workflow profile.
+func (s *Server) WorkflowProfileShow(ctx context.Context, req *vtctldatapb.WorkflowProfileShowRequest) (*vtctldatapb.WorkflowProfileShowResponse, error) {
+	profile, err := s.getWorkflowProfile(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.WorkflowProfileShowResponse{
+		Name:    req.Name,
+		Profile: profile,
+	}, nil
+}
+
+// WorkflowProfileList returns the names of all the workflow profiles.
+func (s *Server) WorkflowProfileList(ctx context.Context, req *vtctldatapb.WorkflowProfileListRequest) (*vtctldatapb.WorkflowProfileListResponse, error) {
+	names, err := s.ts.GetWorkflowProfileNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.WorkflowProfileListResponse{Names: names}, nil
+}
+
+func profileNotExistsError(name string) error {
+	return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "there is no workflow profile named %s", name)
+}
+
+func (s *Server) getWorkflowProfile(ctx context.Context, name string) (*vtctldatapb.WorkflowProfile, error) {
+	profile, err := s.ts.GetWorkflowProfile(ctx, name)
+	if err != nil {
+		if topo.IsErrType(err, topo.NoNode) {
+			return nil, profileNotExistsError(name)
+		}
+		return nil, vterrors.Wrapf(err, "failed to get workflow profile %s", name)
+	}
+	return profile, nil
+}
+
+// defaultProfileThrottleDuration is how long a workflow created with a profile
+// that throttles it is throttled for, unless the profile sets the duration.
+const defaultProfileThrottleDuration = time.Hour
+
+// applyMoveTablesProfile returns a copy of req where the settings that are not
+// set in the request are taken from the workflow profile it references, if any.
+// The tables excluded by the profile are always excluded. It also returns the
+// profile, which is nil if the request doesn't reference one.
+func (s *Server) applyMoveTablesProfile(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest) (*vtctldatapb.MoveTablesCreateRequest, *vtctldatapb.WorkflowProfile, error) {
+	if req.Profile == "" {
+		return req, nil, nil
+	}
+	profile, err := s.getWorkflowProfile(ctx, req.Profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.CloneVT()
+	applyProfileCommon(profile, req.ExplicitSettings, &req.Cells, &req.TabletTypes, &req.TabletSelectionPreference, &req.OnDdl, &req.StopAfterCopy, &req.DeferSecondaryKeys)
+	req.WorkflowOptions = applyProfileOptions(profile, req.WorkflowOptions)
+	for _, table := range profile.ExcludeTables {
+		if !slices.Contains(req.ExcludeTables, table) {
+			req.ExcludeTables = append(req.ExcludeTables, table)
+		}
+	}
+	return req, profile, nil
+}
+
+// applyReshardProfile returns a copy of req where the settings that are not
+// set in the request are taken from the workflow profile it references, if any.
+// It also returns the profile, which is nil if the request doesn't reference one.
+func (s *Server) applyReshardProfile(ctx context.Context, req *vtctldatapb.ReshardCreateRequest) (*vtctldatapb.ReshardCreateRequest, *vtctldatapb.WorkflowProfile, error) {
+	if req.Profile == "" {
+		return req, nil, nil
+	}
+	profile, err := s.getWorkflowProfile(ctx, req.Profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.CloneVT()
+	applyProfileCommon(profile, req.ExplicitSettings, &req.Cells, &req.TabletTypes, &req.TabletSelectionPreference, &req.OnDdl, &req.StopAfterCopy, &req.DeferSecondaryKeys)
+	req.WorkflowOptions = applyProfileOptions(profile, req.WorkflowOptions)
+	return req, profile, nil
+}
+
+// applyProfileCommon sets the settings that are not set from the profile. The
+// boolean settings listed in explicitSettings keep their value, even when false.
+func applyProfileCommon(profile *vtctldatapb.WorkflowProfile, explicitSettings []string, cells *[]string, tabletTypes *[]topodatapb.TabletType,
+	tabletSelectionPreference *tabletmanagerdatapb.TabletSelectionPreference, onDDL *string, stopAfterCopy, deferSecondaryKeys *bool,
+) {
+	if len(*cells) == 0 {
+		*cells = slices.Clone(profile.Cells)
+	}
+	if len(*tabletTypes) == 0 {
+		*tabletTypes = slices.Clone(profile.TabletTypes)
+	}
+	if *tabletSelectionPreference == tabletmanagerdatapb.TabletSelectionPreference_ANY {
+		*tabletSelectionPreference = profile.TabletSelectionPreference
+	}
+	if *onDDL == "" {
+		*onDDL = profile.OnDdl
+	}
+	if !slices.Contains(explicitSettings, "stop_after_copy") {
+		*stopAfterCopy = *stopAfterCopy || profile.StopAfterCopy
+	}
+	if !slices.Contains(explicitSettings, "defer_secondary_keys") {
+		*deferSecondaryKeys = *deferSecondaryKeys || profile.DeferSecondaryKeys
+	}
+}
+
+// applyProfileOptions returns the workflow options where the batch sizes and the
+// copy parallelism that are not set are taken from the profile.
+func applyProfileOptions(profile *vtctldatapb.WorkflowProfile, options *vtctldatapb.WorkflowOptions) *vtctldatapb.WorkflowOptions {
+	if profile.RelayLogMaxItems == 0 && profile.RelayLogMaxSize == 0 && profile.ParallelInsertWorkers == 0 {
+		return options
+	}
+	if options == nil {
+		options = &vtctldatapb.WorkflowOptions{}
+	}
+	if options.RelayLogMaxItems == 0 {
+		options.RelayLogMaxItems = profile.RelayLogMaxItems
+	}
+	if options.RelayLogMaxSize == 0 {
+		options.RelayLogMaxSize = profile.RelayLogMaxSize
+	}
+	if options.ParallelInsertWorkers == 0 {
+		options.ParallelInsertWorkers = profile.ParallelInsertWorkers
+	}
+	return options
+}
+
+// throttleProfileWorkflow throttles the workflow in its target keyspace with the
+// ratio of the profile it is created with, if any. Like UpdateThrottlerConfig
+// with --throttle-app, it adds a throttled app rule, named after the workflow, to
+// the throttler config of the keyspace.
+func (s *Server) throttleProfileWorkflow(ctx context.Context, profile *vtctldatapb.WorkflowProfile, keyspace, workflow string) error {
+	if profile.GetThrottleRatio() <= 0 {
+		return nil
+	}
+	duration, ok, err := protoutil.DurationFromProto(profile.ThrottleDuration)
+	if err != nil {
+		return vterrors.Wrapf(err, "invalid throttle duration in workflow profile")
+	}
+	if !ok || duration <= 0 {
+		duration = defaultProfileThrottleDuration
+	}
+	rule := &topodatapb.ThrottledAppRule{
+		Name:      workflow,
+		Ratio:     profile.ThrottleRatio,
+		ExpiresAt: protoutil.TimeToProto(time.Now().Add(duration)),
+	}
+	return s.updateKeyspaceThrottledApps(ctx, keyspace, "ThrottleProfileWorkflow", func(throttledApps map[string]*topodatapb.ThrottledAppRule) {
+		throttledApps[rule.Name] = rule
+	})
+}
+
+// unthrottleProfileWorkflow removes the throttled app rule added by
+// throttleProfileWorkflow, when the workflow could not be created.
+func (s *Server) unthrottleProfileWorkflow(ctx context.Context, profile *vtctldatapb.WorkflowProfile, keyspace, workflow string) error {
+	if profile.GetThrottleRatio() <= 0 {
+		return nil
+	}
+	return s.updateKeyspaceThrottledApps(ctx, keyspace, "UnthrottleProfileWorkflow", func(throttledApps map[string]*topodatapb.ThrottledAppRule) {
+		delete(throttledApps, workflow)
+	})
+}
+
+// updateKeyspaceThrottledApps applies update to the throttled app rules of the
+// keyspace, in its throttler config and in those of its SrvKeyspaces.
+func (s *Server) updateKeyspaceThrottledApps(ctx context.Context, keyspace, action string, update func(throttledApps map[string]*topodatapb.ThrottledAppRule)) (err error) {
+	updateConfig := func(throttlerConfig *topodatapb.ThrottlerConfig) *topodatapb.ThrottlerConfig {
+		if throttlerConfig == nil {
+			throttlerConfig = &topodatapb.ThrottlerConfig{}
+		}
+		if throttlerConfig.ThrottledApps == nil {
+			throttlerConfig.ThrottledApps = make(map[string]*topodatapb.ThrottledAppRule)
+		}
+		update(throttlerConfig.ThrottledApps)
+		return throttlerConfig
+	}
+
+	ctx, unlock, lockErr := s.ts.LockKeyspace(ctx, keyspace, action)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock(&err)
+
+	ki, err := s.ts.GetKeyspace(ctx, keyspace)
+	if err != nil {
+		return err
+	}
+	ki.ThrottlerConfig = updateConfig(ki.ThrottlerConfig)
+	if err := s.ts.UpdateKeyspace(ctx, ki); err != nil {
+		return err
+	}
+	_, err = s.ts.UpdateSrvKeyspaceThrottlerConfig(ctx, keyspace, []string{}, updateConfig)
+	return err
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestWorkflowProfiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	ws := &Server{
+		ts: ts,
+	}
+
+	_, err := ws.WorkflowProfileSave(ctx, &vtctldatapb.WorkflowProfileSaveRequest{
+		Name:    "bulk",
+		Profile: &vtctldatapb.WorkflowProfile{OnDdl: "explode"},
+	})
+	require.ErrorContains(t, err, "invalid on_ddl value: explode")
+
+	bulk := &vtctldatapb.WorkflowProfile{
+		Cells:                     []string{"zone1"},
+		TabletTypes:               []topodatapb.TabletType{topodatapb.TabletType_RDONLY},
+		TabletSelectionPreference: tabletmanagerdatapb.TabletSelectionPreference_INORDER,
+		ExcludeTables:             []string{"audit_log"},
+		OnDdl:                     "stop",
+		DeferSecondaryKeys:        true,
+		RelayLogMaxItems:          1000,
+		ParallelInsertWorkers:     4,
+	}
+	_, err = ws.WorkflowProfileSave(ctx, &vtctldatapb.WorkflowProfileSaveRequest{
+		Name:    "bulk",
+		Profile: &vtctldatapb.WorkflowProfile{ThrottleRatio: 1.5},
+	})
+	require.ErrorContains(t, err, "invalid throttle ratio 1.5")
+	_, err = ws.WorkflowProfileSave(ctx, &vtctldatapb.WorkflowProfileSaveRequest{Name: "bulk", Profile: bulk})
+	require.NoError(t, err)
+	require.Equal(t, "stop", bulk.OnDdl, "the request must not be modified")
+
+	show, err := ws.WorkflowProfileShow(ctx, &vtctldatapb.WorkflowProfileShowRequest{Name: "bulk"})
+	require.NoError(t, err)
+	require.Equal(t, "bulk", show.Name)
+	require.Equal(t, "STOP", show.Profile.OnDdl)
+
+	list, err := ws.WorkflowProfileList(ctx, &vtctldatapb.WorkflowProfileListRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bulk"}, list.Names)
+
+	// Unset settings are taken from the profile, and the excluded tables are merged.
+	mtReq := &vtctldatapb.MoveTablesCreateRequest{
+		Workflow:      "wf1",
+		TabletTypes:   []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+		ExcludeTables: []string{"t2", "audit_log"},
+		Profile:       "bulk",
+	}
+	got, profile, err := ws.applyMoveTablesProfile(ctx, mtReq)
+	require.NoError(t, err)
+	utils.MustMatch(t, show.Profile, profile)
+	utils.MustMatch(t, &vtctldatapb.MoveTablesCreateRequest{
+		Workflow:                  "wf1",
+		Cells:                     []string{"zone1"},
+		TabletTypes:               []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+		TabletSelectionPreference: tabletmanagerdatapb.TabletSelectionPreference_INORDER,
+		ExcludeTables:             []string{"t2", "audit_log"},
+		OnDdl:                     "STOP",
+		DeferSecondaryKeys:        true,
+		Profile:                   "bulk",
+		WorkflowOptions: &vtctldatapb.WorkflowOptions{
+			RelayLogMaxItems:      1000,
+			ParallelInsertWorkers: 4,
+		},
+	}, got)
+	require.Empty(t, mtReq.Cells, "the request must not be modified")
+
+	// The boolean settings set explicitly are kept, even when false, and the
+	// workflow options set in the request are kept.
+	rsReq := &vtctldatapb.ReshardCreateRequest{
+		Workflow:         "wf2",
+		OnDdl:            "EXEC",
+		StopAfterCopy:    true,
+		Profile:          "bulk",
+		WorkflowOptions:  &vtctldatapb.WorkflowOptions{ParallelInsertWorkers: 8},
+		ExplicitSettings: []string{"defer_secondary_keys"},
+	}
+	gotReshard, _, err := ws.applyReshardProfile(ctx, rsReq)
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.ReshardCreateRequest{
+		Workflow:                  "wf2",
+		Cells:                     []string{"zone1"},
+		TabletTypes:               []topodatapb.TabletType{topodatapb.TabletType_RDONLY},
+		TabletSelectionPreference: tabletmanagerdatapb.TabletSelectionPreference_INORDER,
+		OnDdl:                     "EXEC",
+		StopAfterCopy:             true,
+		Profile:                   "bulk",
+		WorkflowOptions: &vtctldatapb.WorkflowOptions{
+			RelayLogMaxItems:      1000,
+			ParallelInsertWorkers: 8,
+		},
+		ExplicitSettings: []string{"defer_secondary_keys"},
+	}, gotReshard)
+	require.EqualValues(t, 8, rsReq.WorkflowOptions.ParallelInsertWorkers)
+	require.Zero(t, rsReq.WorkflowOptions.RelayLogMaxItems, "the request must not be modified")
+
+	// Requests without a profile are used as is.
+	noProfile := &vtctldatapb.ReshardCreateRequest{Workflow: "wf3"}
+	gotReshard, profile, err = ws.applyReshardProfile(ctx, noProfile)
+	require.NoError(t, err)
+	require.True(t, noProfile == gotReshard)
+	require.Nil(t, profile)
+
+	_, err = ws.WorkflowProfileDelete(ctx, &vtctldatapb.WorkflowProfileDeleteRequest{Name: "bulk"})
+	require.NoError(t, err)
+	_, err = ws.WorkflowProfileDelete(ctx, &vtctldatapb.WorkflowProfileDeleteRequest{Name: "bulk"})
+	require.Equal(t, vtrpcpb.Code_NOT_FOUND, vterrors.Code(err))
+	_, _, err = ws.applyMoveTablesProfile(ctx, mtReq)
+	require.ErrorContains(t, err, "there is no workflow profile named bulk")
+}
+
+func TestThrottleProfileWorkflow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	ws := &Server{
+		ts: ts,
+	}
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "ks", &topodatapb.SrvKeyspace{}))
+
+	// Profiles that don't throttle don't change the throttler config.
+	require.NoError(t, ws.throttleProfileWorkflow(ctx, nil, "ks", "wf1"))
+	require.NoError(t, ws.throttleProfileWorkflow(ctx, &vtctldatapb.WorkflowProfile{}, "ks", "wf1"))
+	ki, err := ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	require.Nil(t, ki.ThrottlerConfig)
+
+	start := time.Now()
+	profile := &vtctldatapb.WorkflowProfile{
+		ThrottleRatio:    0.5,
+		ThrottleDuration: protoutil.DurationToProto(10 * time.Minute),
+	}
+	require.NoError(t, ws.throttleProfileWorkflow(ctx, profile, "ks", "wf1"))
+	ki, err = ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	rule := ki.ThrottlerConfig.ThrottledApps["wf1"]
+	require.NotNil(t, rule)
+	require.Equal(t, "wf1", rule.Name)
+	require.Equal(t, 0.5, rule.Ratio)
+	expiresAt := protoutil.TimeFromProto(rule.ExpiresAt)
+	require.WithinDuration(t, start.Add(10*time.Minute), expiresAt, time.Minute)
+
+	srvKeyspace, err := ts.GetSrvKeyspace(ctx, "zone1", "ks")
+	require.NoError(t, err)
+	utils.MustMatch(t, rule, srvKeyspace.ThrottlerConfig.ThrottledApps["wf1"])
+
+	// The duration defaults to an hour.
+	require.NoError(t, ws.throttleProfileWorkflow(ctx, &vtctldatapb.WorkflowProfile{ThrottleRatio: 1}, "ks", "wf2"))
+	ki, err = ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, ki.ThrottlerConfig.ThrottledApps, 2)
+	expiresAt = protoutil.TimeFromProto(ki.ThrottlerConfig.ThrottledApps["wf2"].ExpiresAt)
+	require.WithinDuration(t, start.Add(time.Hour), expiresAt, time.Minute)
+
+	// The rule is removed when the workflow could not be created.
+	require.NoError(t, ws.unthrottleProfileWorkflow(ctx, &vtctldatapb.WorkflowProfile{ThrottleRatio: 1}, "ks", "wf2"))
+	ki, err = ts.GetKeyspace(ctx, "ks")
+	require.NoError(t, err)
+	require.Len(t, ki.ThrottlerConfig.ThrottledApps, 1)
+	require.Contains(t, ki.ThrottlerConfig.ThrottledApps, "wf1")
+	srvKeyspace, err = ts.GetSrvKeyspace(ctx, "zone1", "ks")
+	require.NoError(t, err)
+	require.NotContains(t, srvKeyspace.ThrottlerConfig.ThrottledApps, "wf2")
+}
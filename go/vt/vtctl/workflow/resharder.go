@@ -19,6 +19,7 @@ package workflow
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"slices"
 	"sync"
@@ -38,6 +39,7 @@ import (
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -58,6 +60,8 @@ type resharder struct {
 	stopAfterCopy      bool
 	onDDL              string
 	deferSecondaryKeys bool
+	// options are the options of the streams, as JSON.
+	options string
 }
 
 type refStream struct {
@@ -277,6 +281,7 @@ func (rs *resharder) createStreams(ctx context.Context) error {
 		targetPrimary := rs.targetPrimaries[target.ShardName()]
 
 		ig := vreplication.NewInsertGenerator(binlogdatapb.VReplicationWorkflowState_Stopped, targetPrimary.DbName())
+		ig.SetOptions(rs.options)
 
 		// Clone excludeRules to prevent data races.
 		copyExcludeRules := slices.Clone(excludeRules)
@@ -358,3 +363,20 @@ func (rs *resharder) forAll(shards []*topo.ShardInfo, f func(*topo.ShardInfo) er
 	wg.Wait()
 	return allErrors.AggrError(vterrors.Aggregate)
 }
+
+// reshardOptionsJSON returns the options of the streams of a Reshard workflow, as
+// JSON. Only the batch sizes and the copy parallelism apply to Reshard workflows.
+func reshardOptionsJSON(options *vtctldatapb.WorkflowOptions) (string, error) {
+	if options == nil {
+		return "", nil
+	}
+	optionsJSON, err := json.Marshal(&vtctldatapb.WorkflowOptions{
+		RelayLogMaxItems:      options.RelayLogMaxItems,
+		RelayLogMaxSize:       options.RelayLogMaxSize,
+		ParallelInsertWorkers: options.ParallelInsertWorkers,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(optionsJSON), nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	reverseReplicationErrorsMu sync.Mutex
+	// reverseReplicationErrorCounts are the numbers of problems found with the
+	// reverse workflow of each workflow whose writes are switched, by
+	// keyspace.workflow, as of the last CheckReverseReplication.
+	reverseReplicationErrorCounts = map[string]int64{}
+
+	// reverseReplicationErrorsGauge exports reverseReplicationErrorCounts, so
+	// that a broken rollback path can be alerted on.
+	reverseReplicationErrorsGauge = stats.NewGaugesFuncWithMultiLabels(
+		"WorkflowReverseReplicationErrors",
+		"Number of problems found with the reverse replication of a workflow whose writes are switched",
+		[]string{"workflow"},
+		func() map[string]int64 {
+			reverseReplicationErrorsMu.Lock()
+			defer reverseReplicationErrorsMu.Unlock()
+
+			counts := make(map[string]int64, len(reverseReplicationErrorCounts))
+			for workflow, count := range reverseReplicationErrorCounts {
+				counts[workflow] = count
+			}
+			return counts
+		})
+)
+
+// CheckReverseReplication checks the reverse workflow of each MoveTables and
+// Reshard workflow whose writes are switched, and exports the number of
+// problems found with it in the WorkflowReverseReplicationErrors gauge. The
+// workflows that went away, or whose writes are no longer switched, are
+// removed from the gauge, while the ones that could not be checked keep their
+// previous count. It is meant to be run periodically.
+func (s *Server) CheckReverseReplication(ctx context.Context) error {
+	keyspaces, err := s.ts.GetKeyspaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	reverseReplicationErrorsMu.Lock()
+	previous := reverseReplicationErrorCounts
+	reverseReplicationErrorsMu.Unlock()
+
+	counts := make(map[string]int64)
+	// keep carries over the previous count of the workflows that could not
+	// be checked.
+	keep := func(match func(label string) bool) {
+		for label, count := range previous {
+			if match(label) {
+				counts[label] = count
+			}
+		}
+	}
+	for _, keyspace := range keyspaces {
+		res, err := s.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{
+			Keyspace: keyspace,
+			NameOnly: true,
+		})
+		if err != nil {
+			log.Errorf("Failed to check the reverse replication of the workflows of keyspace %s: %v", keyspace, err)
+			keep(func(label string) bool { return strings.HasPrefix(label, keyspace+".") })
+			continue
+		}
+		for _, wf := range res.GetWorkflows() {
+			if strings.HasSuffix(wf.Name, "_reverse") {
+				continue
+			}
+			label := fmt.Sprintf("%s.%s", keyspace, wf.Name)
+			errs, switched, err := s.workflowReverseReplicationErrors(ctx, keyspace, wf.Name)
+			if err != nil {
+				log.Errorf("Failed to check the reverse replication of workflow %s: %v", label, err)
+				keep(func(l string) bool { return l == label })
+				continue
+			}
+			if !switched {
+				continue
+			}
+			if count, ok := previous[label]; !ok || count != int64(len(errs)) {
+				for _, e := range errs {
+					log.Warningf("Workflow %s may not be able to roll back its traffic switch: %s", label, e)
+				}
+			}
+			counts[label] = int64(len(errs))
+		}
+	}
+
+	reverseReplicationErrorsMu.Lock()
+	defer reverseReplicationErrorsMu.Unlock()
+	reverseReplicationErrorCounts = counts
+	return nil
+}
+
+// workflowReverseReplicationErrors returns the problems found with the
+// reverse workflow of a workflow, and whether its writes are switched. Only
+// the MoveTables and Reshard workflows have a reverse workflow.
+func (s *Server) workflowReverseReplicationErrors(ctx context.Context, keyspace, workflow string) ([]string, bool, error) {
+	ts, state, err := s.getWorkflowState(ctx, keyspace, workflow)
+	if err != nil {
+		return nil, false, err
+	}
+	if !state.WritesSwitched || (state.WorkflowType != TypeMoveTables && state.WorkflowType != TypeReshard) {
+		return nil, false, nil
+	}
+	errs, err := s.reverseReplicationErrors(ctx, ts, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	return errs, true, nil
+}
+
+// reverseReplicationErrors verifies the reverse workflow that is created when
+// the writes of a workflow are switched, and which is needed to roll back the
+// traffic switch. It returns a description of each problem found: a source
+// shard without reverse stream, a reverse stream that is not running, or, if
+// maxLag is set, a reverse replication lag greater than maxLag.
+func (s *Server) reverseReplicationErrors(ctx context.Context, ts *trafficSwitcher, maxLag time.Duration) ([]string, error) {
+	keyspace, workflow := ts.SourceKeyspaceName(), ts.ReverseWorkflowName()
+	res, err := s.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{
+		Keyspace: keyspace,
+		Workflow: workflow,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	if len(res.GetWorkflows()) == 0 {
+		errs = append(errs, fmt.Sprintf("reverse workflow %s.%s does not exist", keyspace, workflow))
+	} else {
+		wf := res.Workflows[0]
+		streamShards := make(map[string]bool)
+		for _, shardStreams := range wf.ShardStreams {
+			for _, stream := range shardStreams.Streams {
+				streamShards[stream.Shard] = true
+				if stream.State != binlogdatapb.VReplicationWorkflowState_Running.String() {
+					msg := fmt.Sprintf("reverse stream %d on %s/%s is %s", stream.Id, keyspace, stream.Shard, stream.State)
+					if stream.Message != "" {
+						msg += ": " + stream.Message
+					}
+					errs = append(errs, msg)
+				}
+			}
+		}
+		for shard := range ts.Sources() {
+			if !streamShards[shard] {
+				errs = append(errs, fmt.Sprintf("reverse workflow %s.%s has no stream on shard %s", keyspace, workflow, shard))
+			}
+		}
+		if lag := time.Duration(wf.MaxVReplicationTransactionLag) * time.Second; maxLag > 0 && lag > maxLag {
+			errs = append(errs, fmt.Sprintf("reverse replication lag %v exceeds %v", lag, maxLag))
+		}
+	}
+	sort.Strings(errs)
+	return errs, nil
+}
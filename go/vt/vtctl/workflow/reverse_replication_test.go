@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// reverseReplicationTMC returns the reverse workflow streams of each source
+// shard, and fails on the tablets of failingKeyspace.
+type reverseReplicationTMC struct {
+	tmclient.TabletManagerClient
+	streams         map[string][]*tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream
+	failingKeyspace string
+}
+
+func (tmc *reverseReplicationTMC) ReadVReplicationWorkflows(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ReadVReplicationWorkflowsRequest) (*tabletmanagerdatapb.ReadVReplicationWorkflowsResponse, error) {
+	if tablet.Keyspace == tmc.failingKeyspace {
+		return nil, errors.New("tablet unreachable")
+	}
+	streams := tmc.streams[tablet.Shard]
+	if len(streams) == 0 || len(req.IncludeWorkflows) == 0 {
+		return &tabletmanagerdatapb.ReadVReplicationWorkflowsResponse{}, nil
+	}
+	return &tabletmanagerdatapb.ReadVReplicationWorkflowsResponse{
+		Workflows: []*tabletmanagerdatapb.ReadVReplicationWorkflowResponse{{
+			Workflow:     req.IncludeWorkflows[0],
+			WorkflowType: binlogdatapb.VReplicationWorkflowType_MoveTables,
+			Streams:      streams,
+		}},
+	}, nil
+}
+
+func (tmc *reverseReplicationTMC) VReplicationExec(ctx context.Context, tablet *topodatapb.Tablet, query string) (*querypb.QueryResult, error) {
+	return &querypb.QueryResult{}, nil
+}
+
+func reverseStream(id int32, state binlogdatapb.VReplicationWorkflowState, lag time.Duration) *tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream {
+	return &tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream{
+		Id:    id,
+		State: state,
+		Bls: &binlogdatapb.BinlogSource{
+			Keyspace: "targetks",
+			Shard:    "0",
+			Filter:   &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "t1"}}},
+		},
+		Pos:                  "MySQL56/" + position,
+		TimeUpdated:          protoutil.TimeToProto(time.Now()),
+		TransactionTimestamp: protoutil.TimeToProto(time.Now().Add(-lag)),
+	}
+}
+
+func TestReverseReplicationErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	for i, shard := range []string{"-80", "80-"} {
+		tablet := &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: uint32(100 + i)},
+			Keyspace: "sourceks",
+			Shard:    shard,
+			Type:     topodatapb.TabletType_PRIMARY,
+		}
+		require.NoError(t, ts.InitTablet(ctx, tablet, false, true, false))
+		_, err := ts.UpdateShardFields(ctx, "sourceks", shard, func(si *topo.ShardInfo) error {
+			si.PrimaryAlias = tablet.Alias
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	brokenStream := reverseStream(3, binlogdatapb.VReplicationWorkflowState_Error, 0)
+	brokenStream.Message = "duplicate key"
+
+	tmc := &reverseReplicationTMC{}
+	ws := NewServer(vtenv.NewTestEnv(), ts, tmc)
+	sw := &trafficSwitcher{
+		ws:              ws,
+		workflow:        "wf",
+		reverseWorkflow: "wf_reverse",
+		targetKeyspace:  "targetks",
+		sourceKSSchema:  &vindexes.KeyspaceSchema{Keyspace: &vindexes.Keyspace{Name: "sourceks"}},
+		sources: map[string]*MigrationSource{
+			"-80": {},
+			"80-": {},
+		},
+	}
+
+	testcases := []struct {
+		name    string
+		streams map[string][]*tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream
+		maxLag  time.Duration
+		want    []string
+	}{{
+		name: "healthy",
+		streams: map[string][]*tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream{
+			"-80": {reverseStream(1, binlogdatapb.VReplicationWorkflowState_Running, 0)},
+			"80-": {reverseStream(1, binlogdatapb.VReplicationWorkflowState_Running, 0)},
+		},
+		maxLag: time.Minute,
+	}, {
+		name: "missing workflow",
+		want: []string{"reverse workflow sourceks.wf_reverse does not exist"},
+	}, {
+		name: "missing shard and broken stream",
+		streams: map[string][]*tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream{
+			"-80": {brokenStream},
+		},
+		want: []string{
+			"reverse stream 3 on sourceks/-80 is Error: duplicate key",
+			"reverse workflow sourceks.wf_reverse has no stream on shard 80-",
+		},
+	}, {
+		name: "lagging",
+		streams: map[string][]*tabletmanagerdatapb.ReadVReplicationWorkflowResponse_Stream{
+			"-80": {reverseStream(1, binlogdatapb.VReplicationWorkflowState_Running, 0)},
+			"80-": {reverseStream(1, binlogdatapb.VReplicationWorkflowState_Running, 10*time.Minute)},
+		},
+		maxLag: time.Minute,
+		want:   []string{"reverse replication lag 10m0s exceeds 1m0s"},
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmc.streams = tc.streams
+			errs, err := ws.reverseReplicationErrors(ctx, sw, tc.maxLag)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, errs)
+		})
+	}
+}
+
+func TestCheckReverseReplication(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	for i, keyspace := range []string{"targetks", "otherks"} {
+		tablet := &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: uint32(100 + i)},
+			Keyspace: keyspace,
+			Shard:    "0",
+			Type:     topodatapb.TabletType_PRIMARY,
+		}
+		require.NoError(t, ts.InitTablet(ctx, tablet, false, true, false))
+		_, err := ts.UpdateShardFields(ctx, keyspace, "0", func(si *topo.ShardInfo) error {
+			si.PrimaryAlias = tablet.Alias
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	reverseReplicationErrorsMu.Lock()
+	reverseReplicationErrorCounts = map[string]int64{
+		"targetks.wf":  2,
+		"otherks.wf":   1,
+		"removedks.wf": 1,
+	}
+	reverseReplicationErrorsMu.Unlock()
+
+	ws := NewServer(vtenv.NewTestEnv(), ts, &reverseReplicationTMC{failingKeyspace: "otherks"})
+	require.NoError(t, ws.CheckReverseReplication(ctx))
+	// The workflows that went away are removed from the gauge, and the ones
+	// that could not be checked keep their count.
+	require.Equal(t, map[string]int64{"otherks.wf": 1}, reverseReplicationErrorsGauge.Counts())
+}
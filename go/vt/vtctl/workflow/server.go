@@ -382,6 +382,7 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 	span.Annotate("workflow", req.Workflow)
 	span.Annotate("active_only", req.ActiveOnly)
 	span.Annotate("include_logs", req.IncludeLogs)
+	span.Annotate("include_progress", req.IncludeProgress)
 	span.Annotate("shards", req.Shards)
 
 	readReq := &tabletmanagerdatapb.ReadVReplicationWorkflowsRequest{}
@@ -429,7 +430,7 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 			return nil
 		})
 	}
-	if readWorkflowsEg.Wait() != nil {
+	if err := readWorkflowsEg.Wait(); err != nil {
 		return nil, err
 	}
 
@@ -924,6 +925,12 @@ ORDER BY
 	// Wait for all the log fetchers to finish.
 	fetchLogsWG.Wait()
 
+	if req.IncludeProgress {
+		if err := s.addTableCopyStates(ctx, req.Keyspace, workflows); err != nil {
+			return nil, err
+		}
+	}
+
 	return &vtctldatapb.GetWorkflowsResponse{
 		Workflows: workflows,
 	}, nil
@@ -1300,6 +1307,11 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 	span, ctx := trace.NewSpan(ctx, "workflow.Server.moveTablesCreate")
 	defer span.Finish()
 
+	var profile *vtctldatapb.WorkflowProfile
+	if req, profile, err = s.applyMoveTablesProfile(ctx, req); err != nil {
+		return nil, err
+	}
+
 	span.Annotate("keyspace", req.TargetKeyspace)
 	span.Annotate("workflow", req.Workflow)
 	span.Annotate("workflow_type", workflowType)
@@ -1381,6 +1393,10 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no tables to move")
 	}
 	log.Infof("Found tables to move: %s", strings.Join(tables, ","))
+	uniqueKeyColumns, err := getUniqueKeyColumns(req.UniqueKeyColumns, tables)
+	if err != nil {
+		return nil, err
+	}
 
 	if !vschema.Sharded {
 		// Save the original in case we need to restore it for a late failure
@@ -1422,6 +1438,7 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 			TargetTable:      table,
 			SourceExpression: buf.String(),
 			CreateDdl:        createDDLMode,
+			UniqueKeyColumns: uniqueKeyColumns[table],
 		})
 	}
 	mz := &materializer{
@@ -1433,6 +1450,9 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 		workflowType: workflowType,
 		env:          s.env,
 	}
+	if err := s.throttleProfileWorkflow(ctx, profile, targetKeyspace, req.Workflow); err != nil {
+		return nil, err
+	}
 	err = mz.createWorkflowStreams(&tabletmanagerdatapb.CreateVReplicationWorkflowRequest{
 		Workflow:                  req.Workflow,
 		Cells:                     req.Cells,
@@ -1444,6 +1464,9 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 		StopAfterCopy:             req.StopAfterCopy,
 	})
 	if err != nil {
+		if uerr := s.unthrottleProfileWorkflow(ctx, profile, targetKeyspace, req.Workflow); uerr != nil {
+			err = vterrors.Wrapf(err, "failed to remove the throttled app rule of the workflow: %v", uerr)
+		}
 		return nil, err
 	}
 
@@ -1451,6 +1474,9 @@ func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTabl
 	// have been created, then we clean up the workflow's artifacts.
 	defer func() {
 		if err != nil {
+			if uerr := s.unthrottleProfileWorkflow(ctx, profile, targetKeyspace, req.Workflow); uerr != nil {
+				err = vterrors.Wrapf(err, "failed to remove the throttled app rule of the workflow: %v", uerr)
+			}
 			ts, cerr := s.buildTrafficSwitcher(ctx, ms.TargetKeyspace, ms.Workflow)
 			if cerr != nil {
 				err = vterrors.Wrapf(err, "failed to cleanup workflow artifacts: %v", cerr)
@@ -1647,6 +1673,23 @@ func (s *Server) MoveTablesComplete(ctx context.Context, req *vtctldatapb.MoveTa
 	if !state.WritesSwitched || len(state.ReplicaCellsNotSwitched) > 0 || len(state.RdonlyCellsNotSwitched) > 0 {
 		return nil, ErrWorkflowNotFullySwitched
 	}
+	if req.RequireHealthyReverseReplication {
+		maxLag, set, err := protoutil.DurationFromProto(req.MaxReverseReplicationLag)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "unable to parse MaxReverseReplicationLag into a valid duration")
+		}
+		if !set {
+			maxLag = defaultDuration
+		}
+		rrErrs, err := s.reverseReplicationErrors(ctx, ts, maxLag)
+		if err != nil {
+			return nil, err
+		}
+		if len(rrErrs) > 0 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot complete workflow %s.%s as its traffic switch could not be rolled back: %s",
+				req.TargetKeyspace, req.Workflow, strings.Join(rrErrs, "; "))
+		}
+	}
 	var renameTable TableRemovalType
 	if req.RenameTables {
 		renameTable = RenameTable
@@ -1672,6 +1715,11 @@ func (s *Server) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCrea
 	span, ctx := trace.NewSpan(ctx, "workflow.Server.ReshardCreate")
 	defer span.Finish()
 
+	req, profile, err := s.applyReshardProfile(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	span.Annotate("keyspace", req.Keyspace)
 	span.Annotate("workflow", req.Workflow)
 	span.Annotate("source_shards", req.SourceShards)
@@ -1697,13 +1745,23 @@ func (s *Server) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCrea
 	rs.onDDL = req.OnDdl
 	rs.stopAfterCopy = req.StopAfterCopy
 	rs.deferSecondaryKeys = req.DeferSecondaryKeys
+	if rs.options, err = reshardOptionsJSON(req.WorkflowOptions); err != nil {
+		return nil, err
+	}
 	if !req.SkipSchemaCopy {
 		if err := rs.copySchema(ctx); err != nil {
 			return nil, vterrors.Wrap(err, "copySchema")
 		}
 	}
+	if err := s.throttleProfileWorkflow(ctx, profile, keyspace, req.Workflow); err != nil {
+		return nil, err
+	}
 	if err := rs.createStreams(ctx); err != nil {
-		return nil, vterrors.Wrap(err, "createStreams")
+		err = vterrors.Wrap(err, "createStreams")
+		if uerr := s.unthrottleProfileWorkflow(ctx, profile, keyspace, req.Workflow); uerr != nil {
+			err = vterrors.Wrapf(err, "failed to remove the throttled app rule of the workflow: %v", uerr)
+		}
+		return nil, err
 	}
 
 	if req.AutoStart {
@@ -1991,6 +2049,8 @@ func (s *Server) WorkflowDelete(ctx context.Context, req *vtctldatapb.WorkflowDe
 		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the %s workflow does not exist in the %s keyspace", req.Workflow, req.Keyspace)
 	}
 
+	forgetTableCopyStates(req.Keyspace, req.Workflow)
+
 	response := &vtctldatapb.WorkflowDeleteResponse{}
 	response.Summary = fmt.Sprintf("Successfully cancelled the %s workflow in the %s keyspace", req.Workflow, req.Keyspace)
 	details := make([]*vtctldatapb.WorkflowDeleteResponse_TabletInfo, 0, len(res))
@@ -2017,32 +2077,15 @@ func (s *Server) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowSt
 	resp := &vtctldatapb.WorkflowStatusResponse{
 		TrafficState: state.String(),
 	}
-	if copyProgress != nil {
-		resp.TableCopyState = make(map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState, len(*copyProgress))
-		// We sort the tables for intuitive and consistent output.
-		var tables []string
-		for table := range *copyProgress {
-			tables = append(tables, table)
-		}
-		sort.Strings(tables)
-		var progress tableCopyProgress
-		for _, table := range tables {
-			var rowCountPct, tableSizePct float32
-			resp.TableCopyState[table] = &vtctldatapb.WorkflowStatusResponse_TableCopyState{}
-			progress = *(*copyProgress)[table]
-			if progress.SourceRowCount > 0 {
-				rowCountPct = float32(100.0 * float64(progress.TargetRowCount) / float64(progress.SourceRowCount))
-			}
-			if progress.SourceTableSize > 0 {
-				tableSizePct = float32(100.0 * float64(progress.TargetTableSize) / float64(progress.SourceTableSize))
-			}
-			resp.TableCopyState[table].RowsCopied = progress.TargetRowCount
-			resp.TableCopyState[table].RowsTotal = progress.SourceRowCount
-			resp.TableCopyState[table].RowsPercentage = rowCountPct
-			resp.TableCopyState[table].BytesCopied = progress.TargetTableSize
-			resp.TableCopyState[table].BytesTotal = progress.SourceTableSize
-			resp.TableCopyState[table].BytesPercentage = tableSizePct
+	resp.TableCopyState = tableCopyStates(req.Keyspace, req.Workflow, copyProgress)
+
+	if state.WritesSwitched && (state.WorkflowType == TypeMoveTables || state.WorkflowType == TypeReshard) &&
+		!strings.HasSuffix(req.Workflow, "_reverse") {
+		rrErrs, err := s.reverseReplicationErrors(ctx, ts, defaultDuration)
+		if err != nil {
+			rrErrs = []string{fmt.Sprintf("failed to validate the reverse workflow: %v", err)}
 		}
+		resp.ReverseReplicationErrors = rrErrs
 	}
 
 	workflow, err := s.GetWorkflow(ctx, req.Keyspace, req.Workflow, false, req.Shards)
@@ -3081,6 +3124,17 @@ func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.Wor
 		} else {
 			resp.CurrentState = currentState.String()
 		}
+		if hasPrimary && req.EnableReverseReplication {
+			// Validate that the reverse workflow was created and started, as it
+			// is what makes it possible to roll back the traffic switch.
+			rrErrs, err := s.reverseReplicationErrors(ctx, ts, 0)
+			if err != nil {
+				rrErrs = []string{fmt.Sprintf("failed to validate the reverse workflow: %v", err)}
+			}
+			for _, rrErr := range rrErrs {
+				resp.Summary += fmt.Sprintf("\nWARNING: %s", rrErr)
+			}
+		}
 		log.Infof("SwitchTraffic done for workflow %s.%s, returning response %v", req.Keyspace, req.Workflow, resp)
 	}
 	return resp, nil
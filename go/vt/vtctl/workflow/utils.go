@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,6 +37,7 @@ import (
 
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/textutil"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
@@ -308,6 +310,31 @@ func shouldInclude(table string, excludes []string) bool {
 	return true
 }
 
+// getUniqueKeyColumns validates the unique key column hints of a MoveTables
+// request against the tables being moved and returns them escaped in the
+// form that the vreplication Rule expects.
+func getUniqueKeyColumns(hints map[string]string, tables []string) (map[string]string, error) {
+	if len(hints) == 0 {
+		return nil, nil
+	}
+	uniqueKeyColumns := make(map[string]string, len(hints))
+	for table, columns := range hints {
+		if !slices.Contains(tables, table) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unique key columns specified for table %s which is not being moved", table)
+		}
+		var names []string
+		for _, column := range strings.Split(columns, ",") {
+			column = strings.TrimSpace(column)
+			if column == "" {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid unique key columns %q for table %s", columns, table)
+			}
+			names = append(names, column)
+		}
+		uniqueKeyColumns[table] = textutil.EscapeJoin(names, ",")
+	}
+	return uniqueKeyColumns, nil
+}
+
 // getMigrationID produces a reproducible hash based on the input parameters.
 func getMigrationID(targetKeyspace string, shardTablets []string) (int64, error) {
 	sort.Strings(shardTablets)
@@ -95,6 +95,9 @@ var (
 	VT09022 = errorWithoutState("VT09022", vtrpcpb.Code_FAILED_PRECONDITION, "Destination does not have exactly one shard: %v", "Cannot send query to multiple shards.")
 	VT09023 = errorWithoutState("VT09023", vtrpcpb.Code_FAILED_PRECONDITION, "could not map %v to a keyspace id", "Unable to determine the shard for the given row.")
 	VT09024 = errorWithoutState("VT09024", vtrpcpb.Code_FAILED_PRECONDITION, "could not map %v to a unique keyspace id: %v", "Unable to determine the shard for the given row.")
+	VT09025 = errorWithoutState("VT09025", vtrpcpb.Code_FAILED_PRECONDITION, "%s", "The reads or the writes of the table were disabled at runtime by a table kill switch, until the kill switch is cleared or expires.")
+	VT09026 = errorWithoutState("VT09026", vtrpcpb.Code_FAILED_PRECONDITION, "statement is unsafe for statement-based replication: %s", "The statement is not deterministic, so it can change different rows on the replicas and in the vreplication streams than on the primary, and the tablet rejects such statements. Make the statement deterministic, e.g. with an ORDER BY on the primary key along with its LIMIT.")
+	VT09027 = errorWithoutState("VT09027", vtrpcpb.Code_FAILED_PRECONDITION, "%s is disallowed by the %s planner feature of keyspace %s", "The planner_features of the keyspace in its VSchema disallow the plan of the query. Change the query, or the planner_features of the keyspace.")
 
 	VT10001 = errorWithoutState("VT10001", vtrpcpb.Code_ABORTED, "foreign key constraints are not allowed", "Foreign key constraints are not allowed, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/.")
 
@@ -179,6 +182,9 @@ var (
 		VT09022,
 		VT09023,
 		VT09024,
+		VT09025,
+		VT09026,
+		VT09027,
 		VT10001,
 		VT12001,
 		VT12002,
@@ -47,3 +47,15 @@ const TxKillerRollback = "in use: for tx killer rollback"
 
 // TxClosed regex for connection closed
 var TxClosed = regexp.MustCompile("transaction ([a-z0-9:]+) (?:ended|not found|in use: for tx killer rollback)")
+
+// Reserved connection lost errors
+const (
+	ReservedConnLost           = "reserved connection %d was lost"
+	ReservedConnTempTablesLost = "reserved connection %d was lost along with its temporary tables"
+)
+
+// RxReservedConnLost regex for reserved connection lost errors
+var RxReservedConnLost = regexp.MustCompile("reserved connection ([0-9]+) was lost")
+
+// RxReservedConnTempTablesLost regex for reserved connection lost errors where the temporary tables of the session are gone
+var RxReservedConnTempTablesLost = regexp.MustCompile("reserved connection ([0-9]+) was lost along with its temporary tables")
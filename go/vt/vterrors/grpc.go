@@ -19,9 +19,12 @@ package vterrors
 import (
 	"fmt"
 	"io"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
@@ -50,7 +53,13 @@ func ToGRPC(err error) error {
 	if err == nil {
 		return nil
 	}
-	return status.Errorf(codes.Code(Code(err)), "%v", truncateError(err))
+	st := status.New(codes.Code(Code(err)), truncateError(err))
+	if d, ok := RetryAfter(err); ok {
+		if hinted, derr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(d)}); derr == nil {
+			st = hinted
+		}
+	}
+	return st.Err()
 }
 
 // FromGRPC returns a gRPC error as a vtError, translating between error codes.
@@ -66,8 +75,14 @@ func FromGRPC(err error) error {
 		return err
 	}
 	code := codes.Unknown
+	var retryAfter time.Duration
 	if s, ok := status.FromError(err); ok {
 		code = s.Code()
+		for _, detail := range s.Details() {
+			if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+				retryAfter = retryInfo.GetRetryDelay().AsDuration()
+			}
+		}
 	}
-	return New(vtrpcpb.Code(code), err.Error())
+	return withRetryAfterMessage(New(vtrpcpb.Code(code), err.Error()), retryAfter)
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package vterrors
 
 import (
+	"time"
+
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -31,7 +33,8 @@ func FromVTRPC(rpcErr *vtrpcpb.RPCError) error {
 	if rpcErr == nil {
 		return nil
 	}
-	return New(rpcErr.Code, rpcErr.Message)
+	err := New(rpcErr.Code, rpcErr.Message)
+	return withRetryAfterMessage(err, time.Duration(rpcErr.RetryAfterMs)*time.Millisecond)
 }
 
 // ToVTRPC converts from vtError to a vtrpcpb.RPCError.
@@ -39,8 +42,12 @@ func ToVTRPC(err error) *vtrpcpb.RPCError {
 	if err == nil {
 		return nil
 	}
-	return &vtrpcpb.RPCError{
+	rpcErr := &vtrpcpb.RPCError{
 		Code:    Code(err),
 		Message: err.Error(),
 	}
+	if d, ok := RetryAfter(err); ok {
+		rpcErr.RetryAfterMs = d.Milliseconds()
+	}
+	return rpcErr
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vterrors
+
+import (
+	"fmt"
+	"time"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// retryAfter is an error with a retry-after hint. The hint is carried along
+// the error: in the RPCError and in the gRPC status details of the vtgate
+// API. It is also appended to the message for the MySQL clients, that only
+// get the message, but it is never parsed back from a message, which may
+// hold user data.
+type retryAfter struct {
+	err   error
+	msg   string
+	after time.Duration
+}
+
+// WithRetryAfter returns err with a hint telling the clients to wait for d
+// before retrying. The code and the state of err are kept. If err already
+// has a hint, or d is not positive, err is returned as is.
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil || d <= 0 {
+		return err
+	}
+	if _, ok := RetryAfter(err); ok {
+		return err
+	}
+	return &retryAfter{
+		err:   err,
+		msg:   fmt.Sprintf("%s (retry after %dms)", err.Error(), d.Milliseconds()),
+		after: d,
+	}
+}
+
+// withRetryAfterMessage returns err with the hint d, its message already
+// holding the hint.
+func withRetryAfterMessage(err error, d time.Duration) error {
+	if d <= 0 {
+		return err
+	}
+	return &retryAfter{err: err, msg: err.Error(), after: d}
+}
+
+// RetryAfter returns the retry-after hint of err, if any.
+func RetryAfter(err error) (time.Duration, bool) {
+	for err != nil {
+		if ra, ok := err.(*retryAfter); ok {
+			return ra.after, true
+		}
+		err = Cause(err)
+	}
+	return 0, false
+}
+
+func (ra *retryAfter) Error() string           { return ra.msg }
+func (ra *retryAfter) Cause() error            { return ra.err }
+func (ra *retryAfter) ErrorCode() vtrpcpb.Code { return Code(ra.err) }
+func (ra *retryAfter) ErrorState() State       { return ErrState(ra.err) }
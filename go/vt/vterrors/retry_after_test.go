@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vterrors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestRetryAfter(t *testing.T) {
+	err := NewErrorf(vtrpcpb.Code_DEADLINE_EXCEEDED, QueryInterrupted, "query timed out")
+	_, ok := RetryAfter(err)
+	assert.False(t, ok)
+
+	hinted := WithRetryAfter(err, 1500*time.Millisecond)
+	assert.Equal(t, "query timed out (retry after 1500ms)", hinted.Error())
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, Code(hinted))
+	assert.Equal(t, QueryInterrupted, ErrState(hinted))
+	d, ok := RetryAfter(hinted)
+	require.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	// The hint is only added once, and survives the errors being wrapped.
+	assert.Equal(t, hinted, WithRetryAfter(hinted, time.Second))
+	d, ok = RetryAfter(Wrapf(hinted, "target: ks.-80.primary"))
+	require.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	// It is never parsed back from a message, which may hold user data.
+	_, ok = RetryAfter(errors.New("target: ks.-80.primary: " + hinted.Error()))
+	assert.False(t, ok)
+	_, ok = RetryAfter(New(vtrpcpb.Code_INVALID_ARGUMENT, "duplicate entry 'x (retry after 10ms)'"))
+	assert.False(t, ok)
+
+	assert.Equal(t, err, WithRetryAfter(err, 0))
+	assert.NoError(t, WithRetryAfter(nil, time.Second))
+}
+
+func TestRetryAfterRPC(t *testing.T) {
+	hinted := WithRetryAfter(New(vtrpcpb.Code_DEADLINE_EXCEEDED, "query timed out"), 1500*time.Millisecond)
+
+	rpcErr := ToVTRPC(hinted)
+	assert.EqualValues(t, 1500, rpcErr.RetryAfterMs)
+	err := FromVTRPC(rpcErr)
+	assert.Equal(t, hinted.Error(), err.Error())
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, Code(err))
+	d, ok := RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	err = FromGRPC(ToGRPC(hinted))
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, Code(err))
+	d, ok = RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	// The errors without a hint don't get one.
+	_, ok = RetryAfter(FromVTRPC(ToVTRPC(New(vtrpcpb.Code_UNAVAILABLE, "retry after 10ms"))))
+	assert.False(t, ok)
+	_, ok = RetryAfter(FromGRPC(ToGRPC(New(vtrpcpb.Code_UNAVAILABLE, "retry after 10ms"))))
+	assert.False(t, ok)
+}
@@ -49,6 +49,9 @@ const (
 	WrongArguments
 	BadNullError
 	InvalidGroupFuncUse
+	DataTooLong
+	WarnDataOutOfRange
+	TruncatedWrongValue
 
 	// failed precondition
 	NoDB
@@ -61,6 +64,7 @@ const (
 	NoReferencedRow2
 	UnknownStmtHandler
 	KeyDoesNotExist
+	NeedReprepare
 
 	// not found
 	BadDb
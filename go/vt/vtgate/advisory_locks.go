@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+const (
+	// advisoryLockServiceShard holds the advisory locks in MySQL, on the
+	// first shard of the first keyspace.
+	advisoryLockServiceShard = "shard"
+	// advisoryLockServiceTopo holds the advisory locks in the global topo.
+	advisoryLockServiceTopo = "topo"
+
+	advisoryLocksPath = "advisory_locks"
+)
+
+// advisoryLockTryTimeout bounds the attempts to take a lock without waiting
+// for it. TryLock fails fast on the topo implementations that can tell that
+// a lock is held, the bound is for the ones that wait instead.
+var advisoryLockTryTimeout = time.Second
+
+// newAdvisoryLockService returns the service holding the advisory locks,
+// or nil if they are held by a designated shard.
+func newAdvisoryLockService(service string, serv srvtopo.Server) engine.AdvisoryLockService {
+	if service != advisoryLockServiceTopo {
+		return nil
+	}
+	return &topoAdvisoryLocks{
+		serv:  serv,
+		locks: make(map[string]*topoAdvisoryLock),
+	}
+}
+
+// topoAdvisoryLocks holds the advisory locks of the sessions of this vtgate
+// as locks on directories of the global topo, so that they exclude the
+// sessions of all the vtgates. The session holding a lock is the content of
+// the topo lock, so it goes away with the lease of the vtgate.
+type topoAdvisoryLocks struct {
+	serv srvtopo.Server
+
+	mu sync.Mutex
+	// locks are the locks held by the sessions of this vtgate, by name.
+	locks map[string]*topoAdvisoryLock
+}
+
+type topoAdvisoryLock struct {
+	session string
+	// count is how many times the session acquired the lock.
+	count int
+	ld    topo.LockDescriptor
+	// released is closed when the session releases the lock, for the other
+	// sessions of this vtgate waiting for it.
+	released chan struct{}
+}
+
+var _ engine.AdvisoryLockService = (*topoAdvisoryLocks)(nil)
+
+func advisoryLockDir(name string) string {
+	return path.Join(advisoryLocksPath, url.PathEscape(name))
+}
+
+func (al *topoAdvisoryLocks) conn(ctx context.Context) (topo.Conn, error) {
+	ts, err := al.serv.GetTopoServer()
+	if err != nil {
+		return nil, err
+	}
+	return ts.ConnForCell(ctx, topo.GlobalCell)
+}
+
+// GetLock is part of the engine.AdvisoryLockService interface.
+func (al *topoAdvisoryLocks) GetLock(ctx context.Context, session, name string, timeout time.Duration) (bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// The sessions of this vtgate wait for each other here, rather than in
+	// the topo where the lock is held on behalf of the vtgate.
+	al.mu.Lock()
+	for {
+		l, ok := al.locks[name]
+		if !ok {
+			break
+		}
+		if l.session == session {
+			l.count++
+			al.mu.Unlock()
+			return true, nil
+		}
+		al.mu.Unlock()
+		if timeout == 0 {
+			return false, nil
+		}
+		select {
+		case <-l.released:
+		case <-ctx.Done():
+			return lockWaitDone(ctx, timeout)
+		}
+		al.mu.Lock()
+	}
+	// Hold the name until the topo lock is taken, so that the other
+	// sessions of this vtgate wait for this one.
+	l := &topoAdvisoryLock{session: session, released: make(chan struct{})}
+	al.locks[name] = l
+	al.mu.Unlock()
+
+	ld, err := al.lock(ctx, session, name, timeout)
+	if err != nil {
+		al.mu.Lock()
+		delete(al.locks, name)
+		close(l.released)
+		al.mu.Unlock()
+		if topo.IsErrType(err, topo.Timeout) || topo.IsErrType(err, topo.NodeExists) || topo.IsErrType(err, topo.Interrupted) {
+			return lockWaitDone(ctx, timeout)
+		}
+		return false, err
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	l.ld = ld
+	l.count++
+	return true, nil
+}
+
+// lock takes the lock of the name in the topo.
+func (al *topoAdvisoryLocks) lock(ctx context.Context, session, name string, timeout time.Duration) (topo.LockDescriptor, error) {
+	conn, err := al.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dir := advisoryLockDir(name)
+	// The lock implementations need the directory to exist.
+	if _, err := conn.Create(ctx, path.Join(dir, "lock"), nil); err != nil && !topo.IsErrType(err, topo.NodeExists) {
+		return nil, err
+	}
+	if timeout == 0 {
+		tryCtx, cancel := context.WithTimeout(ctx, advisoryLockTryTimeout)
+		defer cancel()
+		return conn.TryLock(tryCtx, dir, session)
+	}
+	return conn.Lock(ctx, dir, session)
+}
+
+// lockWaitDone returns the result of GetLock once the wait for the lock is
+// over: it is not acquired if the timeout elapsed, and fails otherwise.
+func lockWaitDone(ctx context.Context, timeout time.Duration) (bool, error) {
+	if timeout >= 0 || ctx.Err() == nil {
+		return false, nil
+	}
+	return false, ctx.Err()
+}
+
+// ReleaseLock is part of the engine.AdvisoryLockService interface.
+func (al *topoAdvisoryLocks) ReleaseLock(ctx context.Context, session, name string) (sqltypes.Value, error) {
+	al.mu.Lock()
+	l, ok := al.locks[name]
+	if ok && l.ld != nil && l.session == session {
+		l.count--
+		if l.count > 0 {
+			al.mu.Unlock()
+			return sqltypes.NewInt64(1), nil
+		}
+		delete(al.locks, name)
+	}
+	al.mu.Unlock()
+
+	if !ok {
+		free, err := al.IsFreeLock(ctx, name)
+		if err != nil {
+			return sqltypes.NULL, err
+		}
+		if free {
+			return sqltypes.NULL, nil
+		}
+		return sqltypes.NewInt64(0), nil
+	}
+	if l.ld == nil || l.session != session {
+		return sqltypes.NewInt64(0), nil
+	}
+	if err := al.unlock(ctx, l); err != nil {
+		return sqltypes.NULL, err
+	}
+	return sqltypes.NewInt64(1), nil
+}
+
+// IsFreeLock is part of the engine.AdvisoryLockService interface. A lock
+// held by another vtgate is seen by trying to take it.
+func (al *topoAdvisoryLocks) IsFreeLock(ctx context.Context, name string) (bool, error) {
+	al.mu.Lock()
+	_, held := al.locks[name]
+	al.mu.Unlock()
+	if held {
+		return false, nil
+	}
+
+	conn, err := al.conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	tryCtx, cancel := context.WithTimeout(ctx, advisoryLockTryTimeout)
+	defer cancel()
+	ld, err := conn.TryLock(tryCtx, advisoryLockDir(name), "IS_FREE_LOCK")
+	switch {
+	case err == nil:
+		return true, ld.Unlock(ctx)
+	case topo.IsErrType(err, topo.NoNode):
+		// The lock was never taken.
+		return true, nil
+	case topo.IsErrType(err, topo.NodeExists) || topo.IsErrType(err, topo.Timeout) || topo.IsErrType(err, topo.Interrupted):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// ReleaseAllLocks is part of the engine.AdvisoryLockService interface.
+func (al *topoAdvisoryLocks) ReleaseAllLocks(ctx context.Context, session string) (int, error) {
+	al.mu.Lock()
+	var held []*topoAdvisoryLock
+	for name, l := range al.locks {
+		if l.ld != nil && l.session == session {
+			held = append(held, l)
+			delete(al.locks, name)
+		}
+	}
+	al.mu.Unlock()
+
+	released := 0
+	var firstErr error
+	for _, l := range held {
+		released += l.count
+		if err := al.unlock(ctx, l); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return released, firstErr
+}
+
+// unlock releases the lock in the topo, and wakes up the sessions of this
+// vtgate waiting for it. The lock was already forgotten by this vtgate.
+func (al *topoAdvisoryLocks) unlock(ctx context.Context, l *topoAdvisoryLock) error {
+	defer close(l.released)
+	return l.ld.Unlock(ctx)
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/srvtopo/srvtopotest"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// newTestAdvisoryLocks returns the topo advisory locks of two vtgates
+// sharing the same topo.
+func newTestAdvisoryLocks(t *testing.T) (*topoAdvisoryLocks, *topoAdvisoryLocks) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ts := memorytopo.NewServer(ctx, "cell1")
+	t.Cleanup(ts.Close)
+	// The memory topo waits for the locks in TryLock.
+	oldTryTimeout := advisoryLockTryTimeout
+	advisoryLockTryTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { advisoryLockTryTimeout = oldTryTimeout })
+	serv := srvtopotest.NewPassthroughSrvTopoServer()
+	serv.TopoServer = ts
+	return newAdvisoryLockService(advisoryLockServiceTopo, serv).(*topoAdvisoryLocks),
+		newAdvisoryLockService(advisoryLockServiceTopo, serv).(*topoAdvisoryLocks)
+}
+
+func TestNewAdvisoryLockService(t *testing.T) {
+	assert.Nil(t, newAdvisoryLockService(advisoryLockServiceShard, srvtopotest.NewPassthroughSrvTopoServer()))
+	assert.NotNil(t, newAdvisoryLockService(advisoryLockServiceTopo, srvtopotest.NewPassthroughSrvTopoServer()))
+}
+
+func TestTopoAdvisoryLocks(t *testing.T) {
+	ctx := context.Background()
+	vtgate1, vtgate2 := newTestAdvisoryLocks(t)
+
+	free, err := vtgate2.IsFreeLock(ctx, "a/b")
+	require.NoError(t, err)
+	assert.True(t, free)
+
+	acquired, err := vtgate1.GetLock(ctx, "s1", "a/b", time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	// re-entrant for the same session
+	acquired, err = vtgate1.GetLock(ctx, "s1", "a/b", 0)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	free, err = vtgate2.IsFreeLock(ctx, "a/b")
+	require.NoError(t, err)
+	assert.False(t, free)
+
+	// held by another session, of this vtgate or of another one
+	acquired, err = vtgate1.GetLock(ctx, "s2", "a/b", 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	acquired, err = vtgate1.GetLock(ctx, "s2", "a/b", 0)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	acquired, err = vtgate2.GetLock(ctx, "s3", "a/b", 0)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	acquired, err = vtgate2.GetLock(ctx, "s3", "a/b", 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	released, err := vtgate2.ReleaseLock(ctx, "s3", "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.NewInt64(0), released)
+	released, err = vtgate1.ReleaseLock(ctx, "s2", "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.NewInt64(0), released)
+
+	released, err = vtgate1.ReleaseLock(ctx, "s1", "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.NewInt64(1), released)
+	free, err = vtgate2.IsFreeLock(ctx, "a/b")
+	require.NoError(t, err)
+	assert.False(t, free, "acquired twice, released once")
+
+	released, err = vtgate1.ReleaseLock(ctx, "s1", "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.NewInt64(1), released)
+	free, err = vtgate2.IsFreeLock(ctx, "a/b")
+	require.NoError(t, err)
+	assert.True(t, free)
+
+	released, err = vtgate1.ReleaseLock(ctx, "s1", "a/b")
+	require.NoError(t, err)
+	assert.True(t, released.IsNull())
+
+	acquired, err = vtgate2.GetLock(ctx, "s3", "a/b", 0)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestTopoAdvisoryLocksWait(t *testing.T) {
+	ctx := context.Background()
+	vtgate1, vtgate2 := newTestAdvisoryLocks(t)
+
+	acquired, err := vtgate1.GetLock(ctx, "s1", "l", 0)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	done := make(chan bool)
+	go func() {
+		acquired, err := vtgate2.GetLock(ctx, "s2", "l", -1)
+		assert.NoError(t, err)
+		done <- acquired
+	}()
+	select {
+	case <-done:
+		t.Fatal("acquired a lock held by another session")
+	case <-time.After(50 * time.Millisecond):
+	}
+	_, err = vtgate1.ReleaseLock(ctx, "s1", "l")
+	require.NoError(t, err)
+	assert.True(t, <-done)
+}
+
+func TestTopoAdvisoryLocksWaitSameVTGate(t *testing.T) {
+	ctx := context.Background()
+	vtgate1, _ := newTestAdvisoryLocks(t)
+
+	acquired, err := vtgate1.GetLock(ctx, "s1", "l", 0)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// Another session of the same vtgate waits for the lock to be released.
+	done := make(chan bool)
+	go func() {
+		acquired, err := vtgate1.GetLock(ctx, "s2", "l", 10*time.Second)
+		assert.NoError(t, err)
+		done <- acquired
+	}()
+	select {
+	case <-done:
+		t.Fatal("acquired a lock held by another session")
+	case <-time.After(50 * time.Millisecond):
+	}
+	_, err = vtgate1.ReleaseLock(ctx, "s1", "l")
+	require.NoError(t, err)
+	assert.True(t, <-done)
+
+	free, err := vtgate1.IsFreeLock(ctx, "l")
+	require.NoError(t, err)
+	assert.False(t, free)
+	released, err := vtgate1.ReleaseLock(ctx, "s2", "l")
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.NewInt64(1), released)
+}
+
+func TestTopoAdvisoryLocksReleaseAll(t *testing.T) {
+	ctx := context.Background()
+	vtgate1, vtgate2 := newTestAdvisoryLocks(t)
+
+	for _, name := range []string{"l1", "l2", "l1"} {
+		acquired, err := vtgate1.GetLock(ctx, "s1", name, 0)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	}
+	acquired, err := vtgate1.GetLock(ctx, "s2", "l3", 0)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	released, err := vtgate1.ReleaseAllLocks(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, released)
+
+	for name, want := range map[string]bool{"l1": true, "l2": true, "l3": false} {
+		free, err := vtgate2.IsFreeLock(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, want, free, name)
+	}
+}
+
+func TestExecutorTopoAdvisoryLocks(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	locks, _ := newTestAdvisoryLocks(t)
+	executor.advisoryLocks = locks
+
+	session := NewSafeSession(&vtgatepb.Session{SessionUUID: "s1"})
+	qr, err := executor.Execute(ctx, nil, "TestExecutorTopoAdvisoryLocks", session, "select get_lock('l', 1), is_free_lock('l')", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[INT64(1) INT64(0)]]`, fmt.Sprintf("%v", qr.Rows))
+	assert.Equal(t, "get_lock('l', 1)", qr.Fields[0].Name)
+	assert.Empty(t, sbc1.Queries, "not executed on a shard")
+
+	other := NewSafeSession(&vtgatepb.Session{SessionUUID: "s2"})
+	qr, err = executor.Execute(ctx, nil, "TestExecutorTopoAdvisoryLocks", other, "select get_lock('l', 0), release_lock('l'), release_lock('m')", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[INT64(0) INT64(0) NULL]]`, fmt.Sprintf("%v", qr.Rows))
+
+	_, err = executor.Execute(ctx, nil, "TestExecutorTopoAdvisoryLocks", other, "select is_used_lock('l')", nil)
+	require.ErrorContains(t, err, "VT12001")
+	_, err = executor.Execute(ctx, nil, "TestExecutorTopoAdvisoryLocks", NewSafeSession(&vtgatepb.Session{}), "select get_lock('l', 0)", nil)
+	require.ErrorContains(t, err, "session UUID")
+
+	// the locks of a session are released when it closes
+	require.NoError(t, executor.CloseSession(ctx, session))
+
+	require.NoError(t, executor.CloseSession(ctx, session))
+	free, err := locks.IsFreeLock(ctx, "l")
+	require.NoError(t, err)
+	assert.True(t, free)
+}
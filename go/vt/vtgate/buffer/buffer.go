@@ -30,6 +30,7 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/semaphore"
 
@@ -94,6 +95,22 @@ func CausedByFailover(err error) bool {
 	return isFailover
 }
 
+// IsBufferingError returns true if "err" was returned because of a failover
+// that outlasted the buffering of the request, or that the buffer could not
+// hold the request for.
+func IsBufferingError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, bufErr := range []error{bufferFullError, entryEvictedError, contextCanceledError} {
+		if strings.Contains(err.Error(), bufErr.Error()) {
+			return true
+		}
+	}
+	_, isFailover := isFailoverError(err)
+	return isFailover
+}
+
 // for debugging purposes
 func getReason(err error) string {
 	for _, ce := range ClusterEvents {
@@ -200,6 +217,26 @@ func (b *Buffer) HandleKeyspaceEvent(ksevent *discovery.KeyspaceEvent) {
 	}
 }
 
+// RetryAfter returns how long the clients should wait before retrying the
+// requests that failed because of a failover: until the buffering of the
+// failovers in progress stops, at the latest after the maximum failover
+// duration. If no failover is being buffered, e.g. because it outlasted its
+// buffering, it returns the buffering window.
+func (b *Buffer) RetryAfter() time.Duration {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var retryAfter time.Duration
+	for _, sb := range b.buffers {
+		if left, ok := sb.failoverTimeLeft(); ok {
+			retryAfter = max(retryAfter, left)
+		}
+	}
+	if retryAfter <= 0 {
+		return b.config.Window
+	}
+	return retryAfter
+}
+
 // getOrCreateBuffer returns the ShardBuffer for the given keyspace and shard.
 // It returns nil if Buffer is shut down and all calls should be ignored.
 func (b *Buffer) getOrCreateBuffer(keyspace, shard string) *shardBuffer {
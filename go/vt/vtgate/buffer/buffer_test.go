@@ -858,3 +858,49 @@ func TestParallelRangeIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestIsBufferingError(t *testing.T) {
+	assert.True(t, IsBufferingError(failoverErr))
+	assert.True(t, IsBufferingError(vterrors.Wrapf(bufferFullError, "target: %s.%s.primary", keyspace, shard)))
+	assert.True(t, IsBufferingError(entryEvictedError))
+	assert.True(t, IsBufferingError(vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "%v: %v", contextCanceledError, context.Canceled)))
+	assert.False(t, IsBufferingError(nonFailoverErr))
+	assert.False(t, IsBufferingError(nil))
+}
+
+func TestRetryAfter(t *testing.T) {
+	testAllImplementations(t, func(t *testing.T, fail failover) {
+		resetVariables()
+		defer checkVariables(t)
+
+		now := time.Now()
+		cfg := NewDefaultConfig()
+		cfg.Enabled = true
+		cfg.Window = 10 * time.Second
+		cfg.MaxFailoverDuration = time.Minute
+		cfg.now = func() time.Time { return now }
+		b := New(cfg)
+		defer b.Shutdown()
+		fail(b, oldPrimary, keyspace, shard, now)
+
+		// Without a failover, the clients wait for the buffering window.
+		assert.Equal(t, 10*time.Second, b.RetryAfter())
+
+		// During one, they wait until the buffering of the failover stops.
+		stopped := issueRequest(context.Background(), t, b, failoverErr)
+		if err := waitForRequestsInFlight(b, 1); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(20 * time.Second)
+		assert.Equal(t, 40*time.Second, b.RetryAfter())
+
+		fail(b, newPrimary, keyspace, shard, now)
+		if err := <-stopped; err != nil {
+			t.Fatalf("request should have been buffered and not returned an error: %v", err)
+		}
+		if err := waitForState(b, stateIdle); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 10*time.Second, b.RetryAfter())
+	})
+}
@@ -133,6 +133,17 @@ func (sb *shardBuffer) timeNow() time.Time {
 	return sb.buf.config.now()
 }
 
+// failoverTimeLeft returns the time left until the buffering of the failover
+// in progress stops at the latest, if a failover is being buffered.
+func (sb *shardBuffer) failoverTimeLeft() (time.Duration, bool) {
+	sb.mu.RLock()
+	defer sb.mu.RUnlock()
+	if sb.state != stateBuffering {
+		return 0, false
+	}
+	return sb.lastStart.Add(sb.buf.config.MaxFailoverDuration).Sub(sb.timeNow()), true
+}
+
 // disabled returns true if neither buffering nor the dry-run mode is enabled.
 func (sb *shardBuffer) disabled() bool {
 	return sb.mode == bufferModeDisabled
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// concurrencyBudgetsPath is the directory of the global topo where the
+// vtgates that enforce a concurrency budget register themselves.
+const concurrencyBudgetsPath = "vtgate_concurrency_budgets"
+
+var (
+	// keyspaceConcurrencyBudgets are the maximum numbers of queries that all
+	// the vtgates together run concurrently against each keyspace.
+	keyspaceConcurrencyBudgets map[string]int
+	// concurrencyBudgetHeartbeat is how often the vtgates register themselves.
+	concurrencyBudgetHeartbeat = 10 * time.Second
+
+	concurrencyBudgetLimits = stats.NewGaugesWithSingleLabel(
+		"KeyspaceConcurrencyBudgetLimit",
+		"Maximum number of queries that this vtgate runs concurrently against the keyspace, its share of the budget of the keyspace",
+		"Keyspace")
+	concurrencyBudgetVTGates = stats.NewGaugesWithSingleLabel(
+		"KeyspaceConcurrencyBudgetVTGates",
+		"Number of live vtgates that share the concurrency budget of the keyspace",
+		"Keyspace")
+	concurrencyBudgetRejections = stats.NewCountersWithSingleLabel(
+		"KeyspaceConcurrencyBudgetRejections",
+		"Number of queries rejected because the concurrency budget of the keyspace was exhausted",
+		"Keyspace")
+)
+
+// concurrencyBudgetMember is how a vtgate registers itself in the global
+// topo.
+type concurrencyBudgetMember struct {
+	Keyspaces []string `json:"keyspaces"`
+}
+
+// concurrencyBudget enforces cluster wide limits on the number of queries
+// run concurrently against small keyspaces, to protect them from large
+// fleets of vtgates. The vtgates coordinate through the global topo: each
+// of them periodically registers itself there, and limits the queries it
+// runs to an equal share of the budget of each keyspace, among the vtgates
+// that are alive.
+type concurrencyBudget struct {
+	// registry holds the registrations of the vtgates. A vtgate whose
+	// registration was not refreshed for 3 heartbeats is considered gone.
+	registry  *topo.Registry
+	id        string
+	budgets   map[string]int
+	heartbeat time.Duration
+	ticks     *timer.Timer
+	now       func() time.Time
+
+	mu sync.Mutex
+	// limits are the shares of the budgets of this vtgate.
+	limits map[string]int
+	// inFlight are the numbers of queries running against the keyspaces.
+	inFlight map[string]int
+}
+
+// newConcurrencyBudget returns a concurrencyBudget for the vtgate with the
+// given id, or nil if there are no budgets. Until the vtgate learns about the
+// others, it enforces the whole budget of each keyspace.
+func newConcurrencyBudget(ts *topo.Server, id string, budgets map[string]int, heartbeat time.Duration) *concurrencyBudget {
+	if len(budgets) == 0 {
+		return nil
+	}
+	cb := &concurrencyBudget{
+		registry:  topo.NewRegistry(ts, topo.GlobalCell, concurrencyBudgetsPath, 3*heartbeat),
+		id:        id,
+		budgets:   budgets,
+		heartbeat: heartbeat,
+		ticks:     timer.NewTimer(heartbeat),
+		now:       time.Now,
+		limits:    make(map[string]int, len(budgets)),
+		inFlight:  make(map[string]int, len(budgets)),
+	}
+	for keyspace, budget := range budgets {
+		cb.setLimit(keyspace, budget, 1)
+	}
+	return cb
+}
+
+// Start registers the vtgate and keeps its share of the budgets up to date.
+func (cb *concurrencyBudget) Start() {
+	if cb == nil {
+		return
+	}
+	cb.ticks.Start(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cb.heartbeat)
+		defer cancel()
+		if err := cb.refresh(ctx); err != nil {
+			log.Warningf("Failed to refresh the keyspace concurrency budgets: %v", err)
+		}
+	})
+	cb.ticks.Trigger()
+}
+
+// Stop unregisters the vtgate, so that the others get its share of the
+// budgets on their next heartbeat.
+func (cb *concurrencyBudget) Stop() {
+	if cb == nil {
+		return
+	}
+	cb.ticks.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), cb.heartbeat)
+	defer cancel()
+	if err := cb.registry.Unregister(ctx, cb.id); err != nil && !topo.IsErrType(err, topo.NoNode) {
+		log.Warningf("Failed to unregister from the keyspace concurrency budgets: %v", err)
+	}
+}
+
+// refresh registers the vtgate, then recomputes its share of the budgets
+// from the number of vtgates that are alive. On errors the previous shares
+// are kept.
+func (cb *concurrencyBudget) refresh(ctx context.Context) error {
+	keyspaces := make([]string, 0, len(cb.budgets))
+	for keyspace := range cb.budgets {
+		keyspaces = append(keyspaces, keyspace)
+	}
+	sort.Strings(keyspaces)
+	data, err := json.Marshal(&concurrencyBudgetMember{Keyspaces: keyspaces})
+	if err != nil {
+		return err
+	}
+	if err := cb.registry.Register(ctx, cb.id, data); err != nil {
+		return err
+	}
+
+	members, err := cb.registry.Members(ctx, cb.now())
+	if err != nil {
+		return err
+	}
+	vtgates := make(map[string]int, len(cb.budgets))
+	for _, m := range members {
+		var member concurrencyBudgetMember
+		if err := json.Unmarshal(m.Data, &member); err != nil {
+			log.Warningf("Invalid keyspace concurrency budget registration %v: %v", m.Name, err)
+			continue
+		}
+		for _, keyspace := range member.Keyspaces {
+			vtgates[keyspace]++
+		}
+	}
+	// Clean up after the vtgates that didn't unregister.
+	if err := cb.registry.DeleteStale(ctx); err != nil {
+		log.Warningf("Failed to delete the stale keyspace concurrency budget registrations: %v", err)
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	for keyspace, budget := range cb.budgets {
+		// This vtgate just registered, so it counts at least itself.
+		cb.setLimit(keyspace, budget, max(vtgates[keyspace], 1))
+	}
+	return nil
+}
+
+// setLimit sets the share of the budget of the keyspace among the vtgates.
+// Each vtgate gets at least one query, so that none of them starves.
+func (cb *concurrencyBudget) setLimit(keyspace string, budget, vtgates int) {
+	limit := max(budget/vtgates, 1)
+	cb.limits[keyspace] = limit
+	concurrencyBudgetLimits.Set(keyspace, int64(limit))
+	concurrencyBudgetVTGates.Set(keyspace, int64(vtgates))
+}
+
+// acquire reserves a query of the budgets of the keyspaces of the plan. The
+// returned function must be called once the query is done. It fails without
+// reserving anything if the share of the budget of one of the keyspaces is
+// exhausted.
+func (cb *concurrencyBudget) acquire(plan *engine.Plan) (func(), error) {
+	if cb == nil {
+		return func() {}, nil
+	}
+	keyspaces := cb.keyspaces(plan)
+	if len(keyspaces) == 0 {
+		return func() {}, nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	for _, keyspace := range keyspaces {
+		if cb.inFlight[keyspace] >= cb.limits[keyspace] {
+			concurrencyBudgetRejections.Add(keyspace, 1)
+			return nil, vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "concurrency budget of keyspace %s exhausted: %d queries in flight", keyspace, cb.inFlight[keyspace])
+		}
+	}
+	for _, keyspace := range keyspaces {
+		cb.inFlight[keyspace]++
+	}
+	return func() {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+		for _, keyspace := range keyspaces {
+			cb.inFlight[keyspace]--
+		}
+	}, nil
+}
+
+// keyspaces returns the keyspaces with a budget used by the plan.
+func (cb *concurrencyBudget) keyspaces(plan *engine.Plan) []string {
+	var keyspaces []string
+	for _, table := range plan.TablesUsed {
+		keyspace, _, _ := strings.Cut(table, ".")
+		if _, ok := cb.budgets[keyspace]; ok && !slices.Contains(keyspaces, keyspace) {
+			keyspaces = append(keyspaces, keyspace)
+		}
+	}
+	return keyspaces
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestConcurrencyBudgetShares(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	defer ts.Close()
+
+	budgets := map[string]int{"small": 10, "tiny": 1}
+	cb1 := newConcurrencyBudget(ts, "vtgate1", budgets, time.Minute)
+	cb2 := newConcurrencyBudget(ts, "vtgate2", budgets, time.Minute)
+	cb3 := newConcurrencyBudget(ts, "vtgate3", map[string]int{"small": 10}, time.Minute)
+
+	// Alone, a vtgate enforces the whole budgets.
+	require.NoError(t, cb1.refresh(ctx))
+	assert.Equal(t, map[string]int{"small": 10, "tiny": 1}, cb1.limits)
+
+	// The budgets are shared among the vtgates that enforce them, but every
+	// vtgate can run at least one query.
+	require.NoError(t, cb2.refresh(ctx))
+	require.NoError(t, cb3.refresh(ctx))
+	require.NoError(t, cb1.refresh(ctx))
+	assert.Equal(t, map[string]int{"small": 3, "tiny": 1}, cb1.limits)
+	assert.EqualValues(t, 3, concurrencyBudgetVTGates.Counts()["small"])
+	assert.EqualValues(t, 2, concurrencyBudgetVTGates.Counts()["tiny"])
+
+	// The vtgates that unregister no longer get a share.
+	cb3.Stop()
+	require.NoError(t, cb1.refresh(ctx))
+	assert.Equal(t, map[string]int{"small": 5, "tiny": 1}, cb1.limits)
+
+	// Neither do the vtgates that stopped their heartbeats, whose registration
+	// is deleted.
+	cb1.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, cb1.refresh(ctx))
+	assert.Equal(t, map[string]int{"small": 10, "tiny": 1}, cb1.limits)
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	entries, err := conn.ListDir(ctx, concurrencyBudgetsPath, false)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "vtgate1", entries[0].Name)
+}
+
+func TestConcurrencyBudgetAcquire(t *testing.T) {
+	cb := newConcurrencyBudget(nil, "vtgate1", map[string]int{"small": 2, "tiny": 1}, time.Minute)
+	small := &engine.Plan{TablesUsed: []string{"small.t1", "small.t2"}}
+	both := &engine.Plan{TablesUsed: []string{"small.t1", "tiny.t1"}}
+	other := &engine.Plan{TablesUsed: []string{"big.t1"}}
+
+	release1, err := cb.acquire(small)
+	require.NoError(t, err)
+	release2, err := cb.acquire(both)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"small": 2, "tiny": 1}, cb.inFlight)
+
+	// The budget of small is exhausted.
+	rejections := concurrencyBudgetRejections.Counts()["small"]
+	_, err = cb.acquire(small)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualValues(t, 1, concurrencyBudgetRejections.Counts()["small"]-rejections)
+
+	// The keyspaces without a budget are not limited.
+	release3, err := cb.acquire(other)
+	require.NoError(t, err)
+	release3()
+
+	// Releasing a query frees its keyspaces.
+	release2()
+	assert.Equal(t, map[string]int{"small": 1, "tiny": 0}, cb.inFlight)
+	release4, err := cb.acquire(both)
+	require.NoError(t, err)
+	release4()
+	release1()
+	assert.Equal(t, map[string]int{"small": 0, "tiny": 0}, cb.inFlight)
+
+	// A nil budget limits nothing.
+	var disabled *concurrencyBudget
+	release, err := disabled.acquire(small)
+	require.NoError(t, err)
+	release()
+	assert.Nil(t, newConcurrencyBudget(nil, "vtgate1", nil, time.Minute))
+}
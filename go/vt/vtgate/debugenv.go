@@ -122,6 +122,14 @@ func debugEnvHandler(vtg *VTGate, w http.ResponseWriter, r *http.Request) {
 			setDurationVal(discovery.SetHighReplicationLagMinServing)
 		case "min_num_tablets":
 			setIntVal(discovery.SetMinNumTablets)
+		case "discovery_replication_lag_signal":
+			signal, err := discovery.ParseReplicationLagSignal(value)
+			if err != nil {
+				msg = fmt.Sprintf("Failed setting value for %v: %v", varname, err)
+				break
+			}
+			discovery.SetReplicationLagSignal(signal)
+			msg = fmt.Sprintf("Setting %v to: %v", varname, value)
 		}
 	}
 
@@ -141,6 +149,10 @@ func debugEnvHandler(vtg *VTGate, w http.ResponseWriter, r *http.Request) {
 	addDurationVar("discovery_low_replication_lag", discovery.GetLowReplicationLag)
 	addDurationVar("discovery_high_replication_lag_minimum_serving", discovery.GetHighReplicationLagMinServing)
 	addIntVar("min_num_tablets", discovery.GetMinNumTablets)
+	vars = append(vars, envValue{
+		VarName: "discovery_replication_lag_signal",
+		Value:   string(discovery.GetReplicationLagSignal()),
+	})
 
 	format := r.FormValue("format")
 	if format == "json" {
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+var (
+	// registerEndpoint makes the vtgate advertise its gRPC address in the
+	// topo of its cell, for the clients that discover the vtgates there.
+	registerEndpoint bool
+	// endpointAddress is the advertised address. It defaults to the
+	// hostname and the gRPC port of the vtgate.
+	endpointAddress string
+	// endpointHeartbeat is how often the vtgate registers itself.
+	endpointHeartbeat = 10 * time.Second
+)
+
+// endpointRegistration keeps the endpoint of the vtgate registered in the
+// topo of its cell while it serves, and removes the registrations of the
+// vtgates of the cell that went away without unregistering.
+type endpointRegistration struct {
+	// endpoints are the endpoints of the vtgates of the cell. An endpoint
+	// that was not refreshed for 3 heartbeats is considered gone.
+	endpoints *topo.VTGateEndpoints
+	name      string
+	address   string
+	heartbeat time.Duration
+	ticks     *timer.Timer
+	now       func() time.Time
+}
+
+// newEndpointRegistration returns the registration of the vtgate endpoint, or
+// nil if the vtgate doesn't register itself.
+func newEndpointRegistration(ts *topo.Server, cell, name, address string, heartbeat time.Duration) *endpointRegistration {
+	if !registerEndpoint || address == "" {
+		return nil
+	}
+	return &endpointRegistration{
+		endpoints: ts.NewVTGateEndpoints(cell, 3*heartbeat),
+		name:      name,
+		address:   address,
+		heartbeat: heartbeat,
+		ticks:     timer.NewTimer(heartbeat),
+		now:       time.Now,
+	}
+}
+
+// Start registers the endpoint and keeps its registration up to date.
+func (er *endpointRegistration) Start() {
+	if er == nil {
+		return
+	}
+	er.ticks.Start(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), er.heartbeat)
+		defer cancel()
+		if err := er.refresh(ctx); err != nil {
+			log.Warningf("Failed to register the vtgate endpoint: %v", err)
+		}
+	})
+	er.ticks.Trigger()
+}
+
+// Stop unregisters the endpoint, so that the clients stop using it.
+func (er *endpointRegistration) Stop() {
+	if er == nil {
+		return
+	}
+	er.ticks.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), er.heartbeat)
+	defer cancel()
+	if err := er.endpoints.Delete(ctx, er.name); err != nil && !topo.IsErrType(err, topo.NoNode) {
+		log.Warningf("Failed to unregister the vtgate endpoint: %v", err)
+	}
+}
+
+// refresh registers the endpoint, then deletes the registrations of the
+// vtgates that stopped refreshing theirs.
+func (er *endpointRegistration) refresh(ctx context.Context) error {
+	if err := er.endpoints.Save(ctx, &topo.VTGateEndpoint{Name: er.name, Address: er.address}); err != nil {
+		return err
+	}
+	if _, err := er.endpoints.Get(ctx, er.now()); err != nil {
+		return err
+	}
+	// A vtgate that is still alive refreshed its registration in the
+	// meantime, so it is not deleted.
+	if err := er.endpoints.DeleteStale(ctx); err != nil {
+		log.Warningf("Failed to delete the stale vtgate endpoints: %v", err)
+	}
+	return nil
+}
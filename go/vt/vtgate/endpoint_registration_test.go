@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestEndpointRegistration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	defer ts.Close()
+
+	// The vtgates don't register by default.
+	assert.Nil(t, newEndpointRegistration(ts, "cell1", "vtgate1", "host1:15999", time.Minute))
+	registerEndpoint = true
+	defer func() { registerEndpoint = false }()
+	assert.Nil(t, newEndpointRegistration(ts, "cell1", "vtgate1", "", time.Minute))
+
+	er1 := newEndpointRegistration(ts, "cell1", "vtgate1", "host1:15999", time.Minute)
+	er2 := newEndpointRegistration(ts, "cell1", "vtgate2", "host2:15999", time.Minute)
+	require.NoError(t, er1.refresh(ctx))
+	require.NoError(t, er2.refresh(ctx))
+	require.NoError(t, er1.refresh(ctx))
+	endpoints, err := ts.NewVTGateEndpoints("cell1", 0).Get(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []*topo.VTGateEndpoint{
+		{Name: "vtgate1", Address: "host1:15999"},
+		{Name: "vtgate2", Address: "host2:15999"},
+	}, endpoints)
+
+	// The vtgates whose registration didn't change for 3 heartbeats, as seen
+	// by the others, are unregistered.
+	er1.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, er1.refresh(ctx))
+	endpoints, err = ts.NewVTGateEndpoints("cell1", 0).Get(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []*topo.VTGateEndpoint{{Name: "vtgate1", Address: "host1:15999"}}, endpoints)
+
+	// So are the vtgates that stop.
+	er1.Stop()
+	endpoints, err = ts.NewVTGateEndpoints("cell1", 0).Get(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+}
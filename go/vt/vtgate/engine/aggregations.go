@@ -42,6 +42,10 @@ type AggregateParams struct {
 	WCol   int
 	Type   evalengine.Type
 
+	// ExtraDistinct are the other expressions of a distinct aggregation
+	// on multiple expressions, like COUNT(DISTINCT a, b).
+	ExtraDistinct []DistinctKey `json:",omitempty"`
+
 	Alias    string `json:",omitempty"`
 	Expr     sqlparser.Expr
 	Original *sqlparser.AliasedExpr
@@ -53,6 +57,21 @@ type AggregateParams struct {
 	CollationEnv *collations.Environment
 }
 
+// DistinctKey is a column compared to find the distinct rows of a
+// distinct aggregation, besides the column of the aggregation itself.
+type DistinctKey struct {
+	Col  int
+	WCol int
+	Type evalengine.Type
+}
+
+func (dk DistinctKey) comparableCol(fields []*querypb.Field) int {
+	if dk.WCol >= 0 && !isComparable(fields[dk.Col].Type) {
+		return dk.WCol
+	}
+	return dk.Col
+}
+
 func NewAggregateParam(opcode AggregateOpcode, col int, alias string, collationEnv *collations.Environment) *AggregateParams {
 	out := &AggregateParams{
 		Opcode:       opcode,
@@ -79,6 +98,12 @@ func (ap *AggregateParams) String() string {
 	if sqltypes.IsText(ap.Type.Type()) && ap.CollationEnv.IsSupported(ap.Type.Collation()) {
 		keyCol += " COLLATE " + ap.CollationEnv.LookupName(ap.Type.Collation())
 	}
+	for _, dk := range ap.ExtraDistinct {
+		keyCol += ", " + strconv.Itoa(dk.Col)
+		if dk.WCol >= 0 {
+			keyCol += "|" + strconv.Itoa(dk.WCol)
+		}
+	}
 	dispOrigOp := ""
 	if ap.OrigOpcode != AggregateUnassigned && ap.OrigOpcode != ap.Opcode {
 		dispOrigOp = "_" + ap.OrigOpcode.String()
@@ -107,30 +132,66 @@ type aggregatorDistinct struct {
 	last         sqltypes.Value
 	coll         collations.ID
 	collationEnv *collations.Environment
+
+	// extra are the other columns of a distinct aggregation on multiple
+	// expressions. A row is only a duplicate if all its columns are.
+	extra []aggregatorDistinct
 }
 
 func (a *aggregatorDistinct) shouldReturn(row []sqltypes.Value) (bool, error) {
-	if a.column >= 0 {
-		last := a.last
-		next := row[a.column]
-		if !last.IsNull() {
-			if last.TinyWeightCmp(next) == 0 {
-				cmp, err := evalengine.NullsafeCompare(last, next, a.collationEnv, a.coll)
-				if err != nil {
-					return true, err
-				}
-				if cmp == 0 {
-					return true, nil
-				}
-			}
+	if a.column < 0 {
+		return false, nil
+	}
+	duplicate, err := a.same(row)
+	if err != nil {
+		return true, err
+	}
+	for i := range a.extra {
+		same, err := a.extra[i].same(row)
+		if err != nil {
+			return true, err
 		}
-		a.last = next
+		duplicate = duplicate && same
+	}
+	if duplicate {
+		return true, nil
+	}
+	a.last = row[a.column]
+	for i := range a.extra {
+		a.extra[i].last = row[a.extra[i].column]
 	}
 	return false, nil
 }
 
+// same returns whether the column of the row has the last seen value.
+func (a *aggregatorDistinct) same(row []sqltypes.Value) (bool, error) {
+	last := a.last
+	next := row[a.column]
+	if last.IsNull() || last.TinyWeightCmp(next) != 0 {
+		return false, nil
+	}
+	cmp, err := evalengine.NullsafeCompare(last, next, a.collationEnv, a.coll)
+	if err != nil {
+		return false, err
+	}
+	return cmp == 0, nil
+}
+
+// hasNull returns whether one of the extra columns of the row is NULL.
+func (a *aggregatorDistinct) hasNull(row []sqltypes.Value) bool {
+	for _, extra := range a.extra {
+		if row[extra.column].IsNull() {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *aggregatorDistinct) reset() {
 	a.last = sqltypes.NULL
+	for i := range a.extra {
+		a.extra[i].last = sqltypes.NULL
+	}
 }
 
 type aggregatorCount struct {
@@ -140,7 +201,7 @@ type aggregatorCount struct {
 }
 
 func (a *aggregatorCount) add(row []sqltypes.Value) error {
-	if row[a.from].IsNull() {
+	if row[a.from].IsNull() || a.distinct.hasNull(row) {
 		return nil
 	}
 	if ret, err := a.distinct.shouldReturn(row); ret {
@@ -351,6 +412,23 @@ func isComparable(typ sqltypes.Type) bool {
 	return false
 }
 
+// extraDistinct returns the trackers of the other columns of a distinct
+// aggregation on multiple expressions.
+func extraDistinct(fields []*querypb.Field, aggr *AggregateParams) []aggregatorDistinct {
+	if !aggr.Opcode.IsDistinct() || len(aggr.ExtraDistinct) == 0 {
+		return nil
+	}
+	extra := make([]aggregatorDistinct, 0, len(aggr.ExtraDistinct))
+	for _, dk := range aggr.ExtraDistinct {
+		extra = append(extra, aggregatorDistinct{
+			column:       dk.comparableCol(fields),
+			coll:         dk.Type.Collation(),
+			collationEnv: aggr.CollationEnv,
+		})
+	}
+	return extra
+}
+
 func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (aggregationState, []*querypb.Field, error) {
 	fields = slice.Map(fields, func(from *querypb.Field) *querypb.Field { return from.CloneVT() })
 
@@ -386,6 +464,7 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 					column:       distinct,
 					coll:         aggr.Type.Collation(),
 					collationEnv: aggr.CollationEnv,
+					extra:        extraDistinct(fields, aggr),
 				},
 			}
 
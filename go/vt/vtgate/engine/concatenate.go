@@ -19,7 +19,9 @@ package engine
 import (
 	"context"
 	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 
@@ -40,6 +42,19 @@ type Concatenate struct {
 	// These column offsets do not need to be typed checked - they usually contain weight_string()
 	// columns that are not going to be returned to the user
 	NoNeedToTypeCheck map[int]any
+
+	// streamFields caches the fields of the sources for sequentialStreamExec,
+	// so that they are not fetched from every source on every run.
+	streamFields atomic.Pointer[concatenateFields]
+}
+
+// concatenateFields holds the fields of the sources of a Concatenate, for the
+// types of bind variables they were fetched with.
+type concatenateFields struct {
+	bindVarTypes  string
+	fields        []*querypb.Field
+	fieldTypes    []evalengine.Type
+	needsCoercion []bool
 }
 
 // NewConcatenate creates a Concatenate primitive. The ignoreCols slice contains the offsets that
@@ -340,45 +355,108 @@ func (c *Concatenate) parallelStreamExec(inCtx context.Context, vcursor VCursor,
 }
 
 func (c *Concatenate) sequentialStreamExec(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error, sqlmode evalengine.SQLMode) error {
-	// all the below fields ensure that the fields are sent only once.
-	results := make([][]*sqltypes.Result, len(c.Sources))
+	// The output fields depend on the fields of all the sources, so they are
+	// known upfront. This way, the rows of every source are streamed as they
+	// arrive, instead of being buffered until the last source is done, and the
+	// sources after a limit is reached are never executed.
+	sf, err := c.getStreamFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return err
+	}
+	fieldTypes := sf.fieldTypes
+	if err := callback(&sqltypes.Result{Fields: sf.fields}); err != nil {
+		return err
+	}
 
-	var mu sync.Mutex
 	for idx, source := range c.Sources {
+		needsCoercion := sf.needsCoercion[idx]
 		err := vcursor.StreamExecutePrimitive(ctx, source, bindVars, true, func(resultChunk *sqltypes.Result) error {
 			// check if context has expired.
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
-
-			mu.Lock()
-			defer mu.Unlock()
-			// This visitor will just accumulate all the results into slices
-			results[idx] = append(results[idx], resultChunk)
-
-			return nil
+			if len(resultChunk.Rows) == 0 {
+				// the fields were already sent.
+				return nil
+			}
+			for _, row := range resultChunk.Rows {
+				if len(row) != len(fieldTypes) {
+					return errWrongNumberOfColumnsInSelect
+				}
+				if needsCoercion {
+					if err := c.coerceValuesTo(row, fieldTypes, sqlmode); err != nil {
+						return err
+					}
+				}
+			}
+			return callback(&sqltypes.Result{Rows: resultChunk.Rows})
 		})
 		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	firsts := make([]*sqltypes.Result, len(c.Sources))
-	for i, result := range results {
-		firsts[i] = result[0]
+// getStreamFields returns the fields of the sources, fetching them only when
+// they are not cached yet for the types of the bind variables, which the
+// fields of a source can depend on.
+func (c *Concatenate) getStreamFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*concatenateFields, error) {
+	bvTypes := bindVarTypes(bindVars)
+	if sf := c.streamFields.Load(); sf != nil && sf.bindVarTypes == bvTypes {
+		return sf, nil
 	}
 
-	_, fieldTypes, err := c.getFieldTypes(vcursor, firsts)
+	firsts := make([]*sqltypes.Result, len(c.Sources))
+	for i, source := range c.Sources {
+		res, err := source.GetFields(ctx, vcursor, bindVars)
+		if err != nil {
+			return nil, err
+		}
+		firsts[i] = res
+	}
+	fields, fieldTypes, err := c.getFieldTypes(vcursor, firsts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, res := range results {
-		if err = c.coerceAndVisitResults(res, fieldTypes, callback, sqlmode); err != nil {
-			return err
+
+	// Check if type coercion is needed for each source.
+	needsCoercion := make([]bool, len(c.Sources))
+	for idx, first := range firsts {
+		for colIdx, field := range first.Fields {
+			_, skip := c.NoNeedToTypeCheck[colIdx]
+			if !skip && fieldTypes[colIdx].Type() != field.Type {
+				needsCoercion[idx] = true
+				break
+			}
 		}
 	}
 
-	return nil
+	sf := &concatenateFields{
+		bindVarTypes:  bvTypes,
+		fields:        fields,
+		fieldTypes:    fieldTypes,
+		needsCoercion: needsCoercion,
+	}
+	c.streamFields.Store(sf)
+	return sf, nil
+}
+
+// bindVarTypes describes the names and types of the bind variables.
+func bindVarTypes(bindVars map[string]*querypb.BindVariable) string {
+	names := make([]string, 0, len(bindVars))
+	for name := range bindVars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteByte(':')
+		sb.WriteString(bindVars[name].Type.String())
+		sb.WriteByte(',')
+	}
+	return sb.String()
 }
 
 func (c *Concatenate) coerceAndVisitResults(
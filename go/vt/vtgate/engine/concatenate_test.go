@@ -30,6 +30,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 )
 
 func r(names, types string, rows ...string) *sqltypes.Result {
@@ -114,8 +116,9 @@ func TestConcatenate_NoErrors(t *testing.T) {
 		for _, tx := range []bool{false, true} {
 			var sources []Primitive
 			for _, input := range tc.inputs {
-				// input is added twice, since the first one is used by execute and the next by stream execute
-				sources = append(sources, &fakePrimitive{results: []*sqltypes.Result{input, input}})
+				// input is added thrice, since the first one is used by execute and the next ones by stream execute,
+				// which fetches the fields of the sources upfront when it is sequential
+				sources = append(sources, &fakePrimitive{results: []*sqltypes.Result{input, input, input}})
 			}
 
 			concatenate := NewConcatenate(sources, tc.ignoreTypes)
@@ -150,6 +153,100 @@ func TestConcatenate_NoErrors(t *testing.T) {
 	}
 }
 
+func TestConcatenate_SequentialStreamWithLimit(t *testing.T) {
+	r1 := r("id|col", "int64|varchar", "1|a", "2|b", "3|c")
+	r2 := r("id|col", "int32|varchar", "4|d")
+	r3 := r("id|col", "int64|varchar", "5|e")
+	sources := []*fakePrimitive{
+		{results: []*sqltypes.Result{r1, r1}},
+		{results: []*sqltypes.Result{r2, r2}},
+		{results: []*sqltypes.Result{r3, r3}},
+	}
+	limit := &Limit{
+		Count: evalengine.NewLiteralInt(4),
+		Input: NewConcatenate([]Primitive{sources[0], sources[1], sources[2]}, nil),
+	}
+
+	var chunks []*sqltypes.Result
+	err := limit.TryStreamExecute(context.Background(), &noopVCursor{inTx: true}, nil, true, func(res *sqltypes.Result) error {
+		chunks = append(chunks, res)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The fields are sent first, and the rows are streamed as they arrive.
+	require.NotEmpty(t, chunks)
+	assert.Equal(t, r1.Fields, chunks[0].Fields)
+	var rows []sqltypes.Row
+	for _, chunk := range chunks[1:] {
+		assert.Nil(t, chunk.Fields)
+		rows = append(rows, chunk.Rows...)
+	}
+	require.NoError(t, sqltypes.RowsEquals(r("id|col", "int64|varchar", "1|a", "2|b", "3|c", "4|d").Rows, rows))
+
+	// The last source is never executed, since the limit is reached before it.
+	bv := `__upper_limit: type:INT64 value:"4"`
+	sources[0].ExpectLog(t, []string{"GetFields " + bv, "Execute " + bv + " true", "StreamExecute " + bv + " true"})
+	sources[1].ExpectLog(t, []string{"GetFields " + bv, "Execute " + bv + " true", "StreamExecute " + bv + " true"})
+	sources[2].ExpectLog(t, []string{"GetFields " + bv, "Execute " + bv + " true"})
+}
+
+func TestConcatenate_SequentialStreamCachesFields(t *testing.T) {
+	r1 := r("id", "int64", "1")
+	r2 := r("id", "int32", "2")
+	sources := []*fakePrimitive{
+		{results: []*sqltypes.Result{r1, r1}},
+		{results: []*sqltypes.Result{r2, r2}},
+	}
+	concatenate := NewConcatenate([]Primitive{sources[0], sources[1]}, nil)
+	vcursor := &noopVCursor{inTx: true}
+	bv := map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(1)}
+
+	stream := func() {
+		t.Helper()
+		for _, source := range sources {
+			source.rewind()
+		}
+		qr, err := wrapStreamExecute(concatenate, vcursor, bv, true)
+		require.NoError(t, err)
+		utils.MustMatch(t, r1.Fields, qr.Fields, "fields")
+		require.NoError(t, sqltypes.RowsEquals(r("id", "int64", "1", "2").Rows, qr.Rows))
+	}
+
+	// The fields are fetched on the first run only.
+	stream()
+	sources[0].ExpectLog(t, []string{`GetFields id: type:INT64 value:"1"`, `Execute id: type:INT64 value:"1" true`, `StreamExecute id: type:INT64 value:"1" true`})
+	stream()
+	sources[0].ExpectLog(t, []string{`StreamExecute id: type:INT64 value:"1" true`})
+
+	// The fields are fetched again once the types of the bind variables change.
+	bv = map[string]*querypb.BindVariable{"id": sqltypes.StringBindVariable("1")}
+	stream()
+	sources[0].ExpectLog(t, []string{`GetFields id: type:VARCHAR value:"1"`, `Execute id: type:VARCHAR value:"1" true`, `StreamExecute id: type:VARCHAR value:"1" true`})
+}
+
+func TestConcatenate_SequentialStreamFieldsOfAllSources(t *testing.T) {
+	r1 := r("id", "int64", "1")
+	r2 := r("id", "varchar", "abc")
+	sources := []Primitive{
+		&fakePrimitive{results: []*sqltypes.Result{r1, r1, r1}},
+		&fakePrimitive{results: []*sqltypes.Result{r2, r2, r2}},
+	}
+	concatenate := NewConcatenate(sources, nil)
+	vcursor := &noopVCursor{inTx: true}
+
+	want, err := concatenate.TryExecute(context.Background(), vcursor, nil, true)
+	require.NoError(t, err)
+
+	// The fields of the stream are those of all the sources, so the varchar
+	// isn't coerced to the type of the first source.
+	qr, err := wrapStreamExecute(concatenate, vcursor, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, want.Fields, qr.Fields, "fields")
+	assert.Equal(t, sqltypes.VarChar, qr.Fields[0].Type)
+	require.NoError(t, sqltypes.RowsEquals(want.Rows, qr.Rows))
+}
+
 func TestConcatenate_WithErrors(t *testing.T) {
 	strFailed := "failed"
 
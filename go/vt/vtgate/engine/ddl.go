@@ -42,6 +42,10 @@ type DDL struct {
 	NormalDDL *Send
 	OnlineDDL *OnlineDDL
 
+	// DDLStrategy is the DDL strategy enforced by the keyspace's vschema, if any.
+	// It takes precedence over the session's @@ddl_strategy.
+	DDLStrategy string
+
 	DirectDDLEnabled bool
 	OnlineDDLEnabled bool
 
@@ -55,6 +59,9 @@ func (ddl *DDL) description() PrimitiveDescription {
 	if ddl.CreateTempTable {
 		other["TempTable"] = true
 	}
+	if ddl.DDLStrategy != "" {
+		other["DDLStrategy"] = ddl.DDLStrategy
+	}
 	return PrimitiveDescription{
 		OperatorType: "DDL",
 		Keyspace:     ddl.Keyspace,
@@ -88,10 +95,15 @@ func (ddl *DDL) isOnlineSchemaDDL() bool {
 
 // TryExecute implements the Primitive interface
 func (ddl *DDL) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*query.BindVariable, wantfields bool) (result *sqltypes.Result, err error) {
-	if ddl.CreateTempTable {
-		vcursor.Session().HasCreatedTempTable()
+	if ddl.CreateTempTable || ddl.dropsTempTables(vcursor) {
+		// Temporary tables live in the reserved connection of the session.
 		vcursor.Session().NeedsReservedConn()
-		return vcursor.ExecutePrimitive(ctx, ddl.NormalDDL, bindVars, wantfields)
+		result, err = vcursor.ExecutePrimitive(ctx, ddl.NormalDDL, bindVars, wantfields)
+		if err != nil {
+			return nil, err
+		}
+		ddl.trackTempTables(vcursor)
+		return result, nil
 	}
 
 	// Commit any open transaction before executing the ddl query.
@@ -103,6 +115,15 @@ func (ddl *DDL) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[st
 	if err != nil {
 		return nil, err
 	}
+	if ddl.DDLStrategy != "" {
+		enforcedSetting, err := schema.ParseDDLStrategy(ddl.DDLStrategy)
+		if err != nil {
+			return nil, err
+		}
+		if ddlStrategySetting, err = ddlStrategySetting.EnforcedBy(enforcedSetting); err != nil {
+			return nil, err
+		}
+	}
 	ddl.OnlineDDL.DDLStrategySetting = ddlStrategySetting
 
 	switch {
@@ -119,6 +140,33 @@ func (ddl *DDL) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[st
 	}
 }
 
+// dropsTempTables returns true for a DROP TABLE of temporary tables of the
+// session, which MySQL drops before any table of the same name.
+func (ddl *DDL) dropsTempTables(vcursor VCursor) bool {
+	if ddl.DDL.GetAction() != sqlparser.DropDDLAction || ddl.NormalDDL == nil {
+		return false
+	}
+	tables := ddl.DDL.GetFromTables()
+	for _, table := range tables {
+		if !vcursor.Session().HasTempTable(ddl.NormalDDL.Keyspace.Name, table.Name.String()) {
+			return false
+		}
+	}
+	return len(tables) > 0
+}
+
+// trackTempTables records the temporary tables created or dropped by the DDL
+// in the session.
+func (ddl *DDL) trackTempTables(vcursor VCursor) {
+	if ddl.DDL.GetAction() == sqlparser.DropDDLAction {
+		for _, table := range ddl.DDL.GetFromTables() {
+			vcursor.Session().TempTableDropped(ddl.NormalDDL.Keyspace.Name, table.Name.String())
+		}
+		return
+	}
+	vcursor.Session().TempTableCreated(ddl.NormalDDL.Keyspace.Name, ddl.DDL.GetTable().Name.String())
+}
+
 // TryStreamExecute implements the Primitive interface
 func (ddl *DDL) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*query.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	results, err := ddl.TryExecute(ctx, vcursor, bindVars, wantfields)
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
@@ -77,9 +78,87 @@ func TestDDLTempTable(t *testing.T) {
 	require.NoError(t, err)
 
 	vc.ExpectLog(t, []string{
-		"temp table getting created",
 		"Needs Reserved Conn",
 		"ResolveDestinations ks [] Destinations:DestinationAllShards()",
 		"ExecuteMultiShard false false",
+		"temp table created ks.a",
 	})
 }
+
+func TestDDLDropTempTable(t *testing.T) {
+	send := &Send{
+		Keyspace: &vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		TargetDestination: key.DestinationAllShards{},
+		Query:             "ddl query",
+	}
+
+	// DROP TEMPORARY TABLE.
+	ddl := &DDL{
+		CreateTempTable: true,
+		DDL: &sqlparser.DropTable{
+			Temp:       true,
+			FromTables: sqlparser.TableNames{sqlparser.NewTableName("a")},
+		},
+		NormalDDL: send,
+	}
+	vc := &loggingVCursor{tempTables: []string{"ks.a"}}
+	_, err := ddl.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		"Needs Reserved Conn",
+		"ResolveDestinations ks [] Destinations:DestinationAllShards()",
+		"ExecuteMultiShard false false",
+		"temp table dropped ks.a",
+	})
+
+	// DROP TABLE of temporary tables of the session.
+	ddl = &DDL{
+		DDL: &sqlparser.DropTable{
+			FromTables: sqlparser.TableNames{sqlparser.NewTableName("a"), sqlparser.NewTableName("b")},
+		},
+		NormalDDL: send,
+	}
+	vc = &loggingVCursor{tempTables: []string{"ks.a", "ks.b"}}
+	_, err = ddl.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		"Needs Reserved Conn",
+		"ResolveDestinations ks [] Destinations:DestinationAllShards()",
+		"ExecuteMultiShard false false",
+		"temp table dropped ks.a",
+		"temp table dropped ks.b",
+	})
+}
+
+func TestDDLEnforcedStrategy(t *testing.T) {
+	ddl := &DDL{
+		DDL: &sqlparser.AlterTable{
+			Table: sqlparser.NewTableName("a"),
+		},
+		DDLStrategy:      "vitess --postpone-launch",
+		DirectDDLEnabled: true,
+		OnlineDDL:        &OnlineDDL{},
+		NormalDDL: &Send{
+			Keyspace: &vindexes.Keyspace{
+				Name:    "ks",
+				Sharded: true,
+			},
+			TargetDestination: key.DestinationAllShards{},
+			Query:             "ddl query",
+		},
+	}
+
+	// The session's direct strategy is overridden by the keyspace's strategy.
+	vc := &loggingVCursor{}
+	_, err := ddl.TryExecute(context.Background(), vc, nil, true)
+	require.ErrorIs(t, err, schema.ErrOnlineDDLDisabled)
+	require.Equal(t, schema.DDLStrategyVitess, ddl.OnlineDDL.DDLStrategySetting.Strategy)
+	require.True(t, ddl.OnlineDDL.DDLStrategySetting.IsPostponeLaunch())
+
+	ddl.DDLStrategy = "invalid"
+	_, err = ddl.TryExecute(context.Background(), vc, nil, true)
+	require.ErrorContains(t, err, "Unknown online DDL strategy")
+}
@@ -19,6 +19,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -38,6 +39,10 @@ type (
 		Source    Primitive
 		CheckCols []CheckCol
 		Truncate  int
+		// Ordered is set when the rows of the source are sorted on the checked
+		// columns, e.g. when they are merge-sorted from the shards. Duplicate rows
+		// are then next to each other, and only the last row is kept in memory.
+		Ordered bool
 	}
 	CheckCol struct {
 		Col          int
@@ -45,11 +50,27 @@ type (
 		Type         evalengine.Type
 		CollationEnv *collations.Environment
 	}
+	// deduper filters out the rows already seen by a Distinct.
+	deduper interface {
+		// exists returns the row if it was not seen yet, and nil otherwise.
+		exists(inputRow sqltypes.Row) (sqltypes.Row, error)
+		close()
+	}
 	probeTable struct {
 		seenRows     map[vthash.Hash]struct{}
 		checkCols    []CheckCol
 		sqlmode      evalengine.SQLMode
 		collationEnv *collations.Environment
+		// maxRows is the number of hashes kept in seenRows before they are spilled
+		// to disk, or unlimited if it is not positive.
+		maxRows int
+		spilled spilledHashes
+	}
+	// orderedDeduper dedups the rows of a source sorted on the checked columns.
+	orderedDeduper struct {
+		checkCols    []CheckCol
+		collationEnv *collations.Environment
+		lastRow      sqltypes.Row
 	}
 )
 
@@ -62,11 +83,37 @@ func (pt *probeTable) exists(inputRow sqltypes.Row) (sqltypes.Row, error) {
 	if _, found := pt.seenRows[code]; found {
 		return nil, nil
 	}
+	found, err := pt.spilled.contains(code)
+	if err != nil || found {
+		return nil, err
+	}
 
 	pt.seenRows[code] = struct{}{}
+	if pt.maxRows > 0 && len(pt.seenRows) >= pt.maxRows {
+		if err := pt.spill(); err != nil {
+			return nil, err
+		}
+	}
 	return inputRow, nil
 }
 
+// spill moves the hashes of the rows seen so far to disk.
+func (pt *probeTable) spill() error {
+	hashes := make([]vthash.Hash, 0, len(pt.seenRows))
+	for code := range pt.seenRows {
+		hashes = append(hashes, code)
+	}
+	if err := pt.spilled.add(hashes); err != nil {
+		return err
+	}
+	pt.seenRows = make(map[vthash.Hash]struct{})
+	return nil
+}
+
+func (pt *probeTable) close() {
+	pt.spilled.close()
+}
+
 func (pt *probeTable) hashCodeForRow(inputRow sqltypes.Row) (vthash.Hash, error) {
 	hasher := vthash.New()
 	for i, checkCol := range pt.checkCols {
@@ -90,16 +137,69 @@ func (pt *probeTable) hashCodeForRow(inputRow sqltypes.Row) (vthash.Hash, error)
 	return hasher.Sum128(), nil
 }
 
-func newProbeTable(checkCols []CheckCol, collationEnv *collations.Environment) *probeTable {
+func newProbeTable(checkCols []CheckCol, collationEnv *collations.Environment, maxRows int, spillDir string) *probeTable {
 	cols := make([]CheckCol, len(checkCols))
 	copy(cols, checkCols)
 	return &probeTable{
 		seenRows:     make(map[vthash.Hash]struct{}),
 		checkCols:    cols,
 		collationEnv: collationEnv,
+		maxRows:      maxRows,
+		spilled:      spilledHashes{dir: spillDir},
 	}
 }
 
+func (od *orderedDeduper) exists(inputRow sqltypes.Row) (sqltypes.Row, error) {
+	if od.lastRow != nil {
+		equal, err := od.equalsLastRow(inputRow)
+		if err != nil || equal {
+			return nil, err
+		}
+	}
+	od.lastRow = inputRow
+	return inputRow, nil
+}
+
+func (od *orderedDeduper) equalsLastRow(inputRow sqltypes.Row) (bool, error) {
+	for i, checkCol := range od.checkCols {
+		if checkCol.Col >= len(inputRow) {
+			return false, vterrors.VT13001("index out of range in row when comparing DISTINCT rows")
+		}
+		cmp, err := evalengine.NullsafeCompare(od.lastRow[checkCol.Col], inputRow[checkCol.Col], od.collationEnv, checkCol.Type.Collation())
+		if err != nil {
+			if checkCol.WsCol == nil {
+				return false, err
+			}
+			checkCol = checkCol.SwitchToWeightString()
+			od.checkCols[i] = checkCol
+			cmp, err = evalengine.NullsafeCompare(od.lastRow[checkCol.Col], inputRow[checkCol.Col], od.collationEnv, checkCol.Type.Collation())
+			if err != nil {
+				return false, err
+			}
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (od *orderedDeduper) close() {}
+
+// newDeduper returns the deduper of the rows of the source. Unless the source
+// is ordered, the hashes of the rows seen are spilled to the spill directory
+// whenever there are more than the max memory rows of them.
+func (d *Distinct) newDeduper(vcursor VCursor) deduper {
+	collationEnv := vcursor.Environment().CollationEnv()
+	if d.Ordered {
+		return &orderedDeduper{
+			checkCols:    slices.Clone(d.CheckCols),
+			collationEnv: collationEnv,
+		}
+	}
+	return newProbeTable(d.CheckCols, collationEnv, vcursor.MaxMemoryRows(), vcursor.SpillDir())
+}
+
 // TryExecute implements the Primitive interface
 func (d *Distinct) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
 	input, err := vcursor.ExecutePrimitive(ctx, d.Source, bindVars, wantfields)
@@ -112,10 +212,11 @@ func (d *Distinct) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 		InsertID: input.InsertID,
 	}
 
-	pt := newProbeTable(d.CheckCols, vcursor.Environment().CollationEnv())
+	dd := d.newDeduper(vcursor)
+	defer dd.close()
 
 	for _, row := range input.Rows {
-		appendRow, err := pt.exists(row)
+		appendRow, err := dd.exists(row)
 		if err != nil {
 			return nil, err
 		}
@@ -133,7 +234,8 @@ func (d *Distinct) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 func (d *Distinct) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	var mu sync.Mutex
 
-	pt := newProbeTable(d.CheckCols, vcursor.Environment().CollationEnv())
+	dd := d.newDeduper(vcursor)
+	defer dd.close()
 	err := vcursor.StreamExecutePrimitive(ctx, d.Source, bindVars, wantfields, func(input *sqltypes.Result) error {
 		result := &sqltypes.Result{
 			Fields:   input.Fields,
@@ -142,7 +244,7 @@ func (d *Distinct) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVa
 		mu.Lock()
 		defer mu.Unlock()
 		for _, row := range input.Rows {
-			appendRow, err := pt.exists(row)
+			appendRow, err := dd.exists(row)
 			if err != nil {
 				return err
 			}
@@ -200,6 +302,9 @@ func (d *Distinct) description() PrimitiveDescription {
 	if d.Truncate > 0 {
 		other["ResultColumns"] = d.Truncate
 	}
+	if d.Ordered {
+		other["Ordered"] = true
+	}
 	return PrimitiveDescription{
 		Other:        other,
 		OperatorType: "Distinct",
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"slices"
+	"sort"
+
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vthash"
+)
+
+const (
+	hashSize = int64(len(vthash.Hash{}))
+	// runBlockHashes is the number of hashes of a run read at once when
+	// looking a hash up, and between two entries of the index of the run.
+	runBlockHashes = 256
+)
+
+// spilledHashes is the set of row hashes that a DISTINCT keeps on disk once
+// it has seen more rows than it may keep in memory. Every spill writes the
+// hashes as a sorted run in a temporary file of dir. A run is merged with
+// the previous one as long as the previous one isn't larger, which keeps the
+// number of runs logarithmic in the number of hashes, and the total of the
+// hashes rewritten by the merges in O(n log n).
+type spilledHashes struct {
+	dir  string
+	runs []*spilledRun
+}
+
+// spilledRun is a sorted run of hashes in its own temporary file.
+type spilledRun struct {
+	file  *os.File
+	count int64
+	// index holds the first hash of every block of the run, so that looking
+	// a hash up reads a single block of the file.
+	index []vthash.Hash
+}
+
+func compareHashes(a, b vthash.Hash) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// add adds the given hashes, none of which is already in the set.
+func (sh *spilledHashes) add(hashes []vthash.Hash) error {
+	slices.SortFunc(hashes, compareHashes)
+	run, err := sh.writeRun(func(w *runWriter) error {
+		for _, hash := range hashes {
+			if err := w.write(hash); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return vterrors.Wrapf(err, "spilling DISTINCT rows to disk")
+	}
+	sh.runs = append(sh.runs, run)
+
+	for n := len(sh.runs); n > 1 && sh.runs[n-2].count <= sh.runs[n-1].count; n = len(sh.runs) {
+		merged, err := sh.mergeRuns(sh.runs[n-2], sh.runs[n-1])
+		if err != nil {
+			return vterrors.Wrapf(err, "spilling DISTINCT rows to disk")
+		}
+		sh.runs[n-2].close()
+		sh.runs[n-1].close()
+		sh.runs = append(sh.runs[:n-2], merged)
+	}
+	return nil
+}
+
+// mergeRuns writes the hashes of both runs to a new run.
+func (sh *spilledHashes) mergeRuns(a, b *spilledRun) (*spilledRun, error) {
+	return sh.writeRun(func(w *runWriter) error {
+		ra, rb := a.reader(), b.reader()
+		ha, oka, err := ra.next()
+		if err != nil {
+			return err
+		}
+		hb, okb, err := rb.next()
+		if err != nil {
+			return err
+		}
+		for oka || okb {
+			if !okb || (oka && compareHashes(ha, hb) < 0) {
+				if err := w.write(ha); err != nil {
+					return err
+				}
+				ha, oka, err = ra.next()
+			} else {
+				if err := w.write(hb); err != nil {
+					return err
+				}
+				hb, okb, err = rb.next()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeRun creates a run in a new temporary file, with the sorted hashes
+// written by write.
+func (sh *spilledHashes) writeRun(write func(w *runWriter) error) (*spilledRun, error) {
+	file, err := os.CreateTemp(sh.dir, "vtgate-distinct-")
+	if err != nil {
+		return nil, err
+	}
+	// The file is only used through its descriptor, and goes away with it.
+	_ = os.Remove(file.Name())
+
+	w := &runWriter{run: &spilledRun{file: file}, buf: bufio.NewWriter(file)}
+	if err := write(w); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := w.buf.Flush(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w.run, nil
+}
+
+// contains returns true if the hash is in the set.
+func (sh *spilledHashes) contains(hash vthash.Hash) (bool, error) {
+	for _, run := range sh.runs {
+		found, err := run.contains(hash)
+		if err != nil {
+			return false, vterrors.Wrapf(err, "reading the DISTINCT rows spilled to disk")
+		}
+		if found {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// close releases the files of the set.
+func (sh *spilledHashes) close() {
+	for _, run := range sh.runs {
+		run.close()
+	}
+	sh.runs = nil
+}
+
+// count returns the number of hashes in the set.
+func (sh *spilledHashes) count() int64 {
+	var count int64
+	for _, run := range sh.runs {
+		count += run.count
+	}
+	return count
+}
+
+// contains returns true if the hash is in the run. Only the block of the run
+// that would hold the hash is read.
+func (run *spilledRun) contains(hash vthash.Hash) (bool, error) {
+	block := sort.Search(len(run.index), func(i int) bool {
+		return compareHashes(run.index[i], hash) > 0
+	}) - 1
+	if block < 0 {
+		return false, nil
+	}
+	start := int64(block) * runBlockHashes
+	n := min(runBlockHashes, run.count-start)
+	buf := make([]byte, n*hashSize)
+	if _, err := run.file.ReadAt(buf, start*hashSize); err != nil {
+		return false, err
+	}
+	i := sort.Search(int(n), func(i int) bool {
+		return bytes.Compare(buf[int64(i)*hashSize:int64(i+1)*hashSize], hash[:]) >= 0
+	})
+	return i < int(n) && bytes.Equal(buf[int64(i)*hashSize:int64(i+1)*hashSize], hash[:]), nil
+}
+
+// reader returns a reader of the hashes of the run, in order.
+func (run *spilledRun) reader() *runReader {
+	return &runReader{
+		buf:       bufio.NewReader(io.NewSectionReader(run.file, 0, run.count*hashSize)),
+		remaining: run.count,
+	}
+}
+
+func (run *spilledRun) close() {
+	run.file.Close()
+}
+
+// runWriter writes the sorted hashes of a run, and indexes them.
+type runWriter struct {
+	run *spilledRun
+	buf *bufio.Writer
+}
+
+func (w *runWriter) write(hash vthash.Hash) error {
+	if w.run.count%runBlockHashes == 0 {
+		w.run.index = append(w.run.index, hash)
+	}
+	if _, err := w.buf.Write(hash[:]); err != nil {
+		return err
+	}
+	w.run.count++
+	return nil
+}
+
+// runReader reads the hashes of a run in order.
+type runReader struct {
+	buf       *bufio.Reader
+	remaining int64
+}
+
+// next returns the next hash of the run, or false once all of them were read.
+func (r *runReader) next() (vthash.Hash, bool, error) {
+	var hash vthash.Hash
+	if r.remaining == 0 {
+		return hash, false, nil
+	}
+	if _, err := io.ReadFull(r.buf, hash[:]); err != nil {
+		return hash, false, err
+	}
+	r.remaining--
+	return hash, true, nil
+}
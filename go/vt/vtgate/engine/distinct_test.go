@@ -19,6 +19,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"os"
 	"testing"
 
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
@@ -30,6 +31,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vthash"
 )
 
 func TestDistinct(t *testing.T) {
@@ -217,3 +219,86 @@ func TestWeightStringFallBack(t *testing.T) {
 		Type:  evalengine.NewType(sqltypes.VarBinary, collations.CollationBinaryID),
 	}}, distinct.CheckCols, "checkCols should not be updated")
 }
+
+func TestDistinctOrdered(t *testing.T) {
+	input := r("myid|id", "varchar|int64",
+		"horse|1", "Horse|1", "horse|2", "monkey|1", "MONKEY|1", "null|1", "null|1")
+	distinct := &Distinct{
+		Source: &fakePrimitive{results: []*sqltypes.Result{input}},
+		CheckCols: []CheckCol{
+			{Col: 0, Type: evalengine.NewType(sqltypes.VarChar, collations.CollationUtf8mb4ID), CollationEnv: collations.MySQL8()},
+			{Col: 1, Type: evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID), CollationEnv: collations.MySQL8()},
+		},
+		Ordered: true,
+	}
+	expected := `[[VARCHAR("horse") INT64(1)] [VARCHAR("horse") INT64(2)] [VARCHAR("monkey") INT64(1)] [NULL INT64(1)]]`
+
+	qr, err := distinct.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	require.NoError(t, sqltypes.RowsEqualsStr(expected, qr.Rows))
+
+	// The rows of a stream are deduplicated across its results, which have two
+	// rows each.
+	distinct.Source = &fakePrimitive{results: []*sqltypes.Result{input}}
+	qr, err = wrapStreamExecute(distinct, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	require.NoError(t, sqltypes.RowsEqualsStr(expected, qr.Rows))
+}
+
+func TestDistinctSpill(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id", "int64")
+	var rows, expected []string
+	for i := 0; i < 3*testMaxMemoryRows; i++ {
+		rows = append(rows, fmt.Sprintf("%d", i%(2*testMaxMemoryRows)))
+		if i < 2*testMaxMemoryRows {
+			expected = append(expected, rows[i])
+		}
+	}
+	distinct := &Distinct{
+		Source: &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(fields, rows...)}},
+		CheckCols: []CheckCol{
+			{Col: 0, Type: evalengine.NewType(sqltypes.Int64, collations.CollationBinaryID), CollationEnv: collations.MySQL8()},
+		},
+	}
+
+	// The distinct rows don't fit in memory, the hashes of the rows seen are
+	// spilled to disk.
+	result, err := wrapStreamExecute(distinct, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(fields, expected...).Rows, result.Rows)
+}
+
+func TestSpilledHashes(t *testing.T) {
+	dir := t.TempDir()
+	sh := spilledHashes{dir: dir}
+	defer sh.close()
+	hash := func(i int) vthash.Hash {
+		hasher := vthash.New()
+		hasher.Write64(uint64(i))
+		return hasher.Sum128()
+	}
+
+	var batch []vthash.Hash
+	for i := 0; i < 1000; i++ {
+		batch = append(batch, hash(i))
+		if len(batch) == 300 {
+			require.NoError(t, sh.add(batch))
+			batch = nil
+		}
+	}
+	require.EqualValues(t, 900, sh.count())
+	// The first two runs of 300 hashes were merged, the third one was not.
+	require.Len(t, sh.runs, 2)
+	require.EqualValues(t, 600, sh.runs[0].count)
+	require.Len(t, sh.runs[0].index, 3)
+	for i := 0; i < 1000; i++ {
+		found, err := sh.contains(hash(i))
+		require.NoError(t, err)
+		require.Equal(t, i < 900, found, "hash of %d", i)
+	}
+
+	// The runs are written to the spill directory, and removed right away.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
@@ -95,6 +95,10 @@ func (t *noopVCursor) RemoveAdvisoryLock(name string) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) AdvisoryLockService() AdvisoryLockService {
+	return nil
+}
+
 func (t *noopVCursor) ReleaseLock(context.Context) error {
 	// TODO implement me
 	panic("implement me")
@@ -116,6 +120,10 @@ func (t *noopVCursor) SetExec(ctx context.Context, name string, value string) er
 	panic("implement me")
 }
 
+func (t *noopVCursor) FlushPlans(ctx context.Context, tables []sqlparser.TableName, pattern string) (int, error) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) ThrottleApp(ctx context.Context, throttleAppRule *topodatapb.ThrottledAppRule) error {
 	panic("implement me")
 }
@@ -227,10 +235,26 @@ func (t *noopVCursor) SetSessionTrackGTIDs(b bool) {
 	panic("implement me")
 }
 
-func (t *noopVCursor) HasCreatedTempTable() {
+func (t *noopVCursor) SetSessionTrackStateChange(b bool) {
+	panic("implement me")
+}
+
+func (t *noopVCursor) SetSessionTrackSystemVariables(s string) {
+	panic("implement me")
+}
+
+func (t *noopVCursor) TempTableCreated(keyspace, table string) {
+	panic("implement me")
+}
+
+func (t *noopVCursor) TempTableDropped(keyspace, table string) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) HasTempTable(keyspace, table string) bool {
+	return false
+}
+
 func (t *noopVCursor) LookupRowLockShardSession() vtgatepb.CommitOrder {
 	panic("implement me")
 }
@@ -305,6 +329,10 @@ func (t *noopVCursor) SetTransactionMode(vtgatepb.TransactionMode) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) SetTransactionIsolation(querypb.ExecuteOptions_TransactionIsolation) error {
+	panic("implement me")
+}
+
 func (t *noopVCursor) SetWorkload(querypb.ExecuteOptions_Workload) {
 	panic("implement me")
 }
@@ -313,6 +341,10 @@ func (t *noopVCursor) SetWorkloadName(string) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) SetTransactionTag(string) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) SetPlannerVersion(querypb.ExecuteOptions_PlannerVersion) {
 	panic("implement me")
 }
@@ -329,6 +361,10 @@ func (t *noopVCursor) SetTarget(string) error {
 	panic("implement me")
 }
 
+func (t *noopVCursor) SpillDir() string {
+	return ""
+}
+
 func (t *noopVCursor) MaxMemoryRows() int {
 	return testMaxMemoryRows
 }
@@ -352,6 +388,10 @@ func (t *noopVCursor) ExecuteMultiShard(ctx context.Context, primitive Primitive
 	panic("unimplemented")
 }
 
+func (t *noopVCursor) ExecuteBatchMultiShard(ctx context.Context, primitive Primitive, rss []*srvtopo.ResolvedShard, queries [][]*querypb.BoundQuery) (*sqltypes.Result, []error) {
+	panic("unimplemented")
+}
+
 func (t *noopVCursor) AutocommitApproval() bool {
 	panic("unimplemented")
 }
@@ -421,10 +461,20 @@ type loggingVCursor struct {
 	shardSession []*srvtopo.ResolvedShard
 
 	parser *sqlparser.Parser
+
+	tempTables []string
+}
+
+func (f *loggingVCursor) TempTableCreated(keyspace, table string) {
+	f.log = append(f.log, fmt.Sprintf("temp table created %s.%s", keyspace, table))
+}
+
+func (f *loggingVCursor) TempTableDropped(keyspace, table string) {
+	f.log = append(f.log, fmt.Sprintf("temp table dropped %s.%s", keyspace, table))
 }
 
-func (f *loggingVCursor) HasCreatedTempTable() {
-	f.log = append(f.log, "temp table getting created")
+func (f *loggingVCursor) HasTempTable(keyspace, table string) bool {
+	return slices.Contains(f.tempTables, keyspace+"."+table)
 }
 
 func (f *loggingVCursor) Commit(_ context.Context) error {
@@ -562,6 +612,16 @@ func (f *loggingVCursor) ExecuteMultiShard(ctx context.Context, primitive Primit
 	return res, f.multiShardErrs
 }
 
+func (f *loggingVCursor) ExecuteBatchMultiShard(ctx context.Context, primitive Primitive, rss []*srvtopo.ResolvedShard, queries [][]*querypb.BoundQuery) (*sqltypes.Result, []error) {
+	f.log = append(f.log, fmt.Sprintf("ExecuteBatchMultiShard %v", printResolvedShardBatches(rss, queries)))
+	res, err := f.nextResult()
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return res, f.multiShardErrs
+}
+
 func (f *loggingVCursor) AutocommitApproval() bool {
 	return true
 }
@@ -768,6 +828,11 @@ func (f *loggingVCursor) SetTransactionMode(vtgatepb.TransactionMode) {
 	panic("implement me")
 }
 
+func (f *loggingVCursor) SetTransactionIsolation(level querypb.ExecuteOptions_TransactionIsolation) error {
+	f.log = append(f.log, fmt.Sprintf("TransactionIsolation set to %s", level.String()))
+	return nil
+}
+
 func (f *loggingVCursor) SetWorkload(querypb.ExecuteOptions_Workload) {
 	panic("implement me")
 }
@@ -776,6 +841,10 @@ func (f *loggingVCursor) SetWorkloadName(string) {
 	panic("implement me")
 }
 
+func (f *loggingVCursor) SetTransactionTag(tag string) {
+	f.log = append(f.log, "TransactionTag set to "+tag)
+}
+
 func (f *loggingVCursor) SetPlannerVersion(querypb.ExecuteOptions_PlannerVersion) {
 	panic("implement me")
 }
@@ -891,6 +960,21 @@ func printResolvedShardQueries(rss []*srvtopo.ResolvedShard, queries []*querypb.
 	return buf.String()
 }
 
+func printResolvedShardBatches(rss []*srvtopo.ResolvedShard, queries [][]*querypb.BoundQuery) string {
+	buf := &bytes.Buffer{}
+	for i, rs := range rss {
+		fmt.Fprintf(buf, "%s.%s: [", rs.Target.Keyspace, rs.Target.Shard)
+		for j, query := range queries[i] {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(buf, "%s {%s}", query.Sql, printBindVars(query.BindVariables))
+		}
+		buf.WriteString("] ")
+	}
+	return buf.String()
+}
+
 func printResolvedShardsBindVars(rss []*srvtopo.ResolvedShard, bvs []map[string]*querypb.BindVariable) string {
 	buf := &bytes.Buffer{}
 	for i, rs := range rss {
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+var _ Primitive = (*FlushPlans)(nil)
+
+// FlushPlans is a primitive that removes plans from the plan cache of this vtgate.
+// Only the plans using one of the Tables, or whose query matches Pattern, are removed.
+// When neither is set, every cached plan is removed.
+type FlushPlans struct {
+	noInputs
+	noTxNeeded
+
+	Tables  []sqlparser.TableName
+	Pattern string
+}
+
+func (f *FlushPlans) RouteType() string {
+	return "FlushPlans"
+}
+
+func (f *FlushPlans) GetKeyspaceName() string {
+	return ""
+}
+
+func (f *FlushPlans) GetTableName() string {
+	return ""
+}
+
+func (f *FlushPlans) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*query.BindVariable) (*sqltypes.Result, error) {
+	return &sqltypes.Result{}, nil
+}
+
+func (f *FlushPlans) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*query.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	flushed, err := vcursor.FlushPlans(ctx, f.Tables, f.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{RowsAffected: uint64(flushed)}, nil
+}
+
+func (f *FlushPlans) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*query.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	qr, err := f.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(qr)
+}
+
+func (f *FlushPlans) description() PrimitiveDescription {
+	other := map[string]any{}
+	if len(f.Tables) > 0 {
+		other["Tables"] = sqlparser.String(sqlparser.TableNames(f.Tables))
+	}
+	if f.Pattern != "" {
+		other["Pattern"] = f.Pattern
+	}
+	return PrimitiveDescription{
+		OperatorType: "FlushPlans",
+		Other:        other,
+	}
+}
@@ -19,6 +19,9 @@ package engine
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/mysql/collations"
 
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
@@ -55,6 +58,27 @@ type Lock struct {
 type LockFunc struct {
 	Typ  *sqlparser.LockingFunc
 	Name evalengine.Expr
+	// Timeout is the timeout of GET_LOCK, in seconds.
+	Timeout evalengine.Expr
+}
+
+// AdvisoryLockService holds the advisory locks of the sessions outside of
+// MySQL, e.g. in the topo, instead of on a designated shard.
+type AdvisoryLockService interface {
+	// GetLock acquires the named lock for the session, waiting for it up to
+	// the timeout, or forever if the timeout is negative. It returns false
+	// if the lock wasn't acquired before the timeout. A session can acquire
+	// the same lock several times, and must release it as many times.
+	GetLock(ctx context.Context, session, name string, timeout time.Duration) (bool, error)
+	// ReleaseLock releases the named lock held by the session. It returns 1
+	// if it was released, 0 if another session holds it, or NULL if no
+	// session holds it.
+	ReleaseLock(ctx context.Context, session, name string) (sqltypes.Value, error)
+	// IsFreeLock returns whether no session holds the named lock.
+	IsFreeLock(ctx context.Context, name string) (bool, error)
+	// ReleaseAllLocks releases all the locks held by the session, and
+	// returns how many times they were acquired.
+	ReleaseAllLocks(ctx context.Context, session string) (int, error)
 }
 
 // RouteType is part of the Primitive interface
@@ -74,9 +98,96 @@ func (l *Lock) GetTableName() string {
 
 // TryExecute is part of the Primitive interface
 func (l *Lock) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if als := vcursor.AdvisoryLockService(); als != nil {
+		return l.execLockService(ctx, vcursor, bindVars, als)
+	}
 	return l.execLock(ctx, vcursor, bindVars)
 }
 
+// execLockService executes the lock functions on the advisory lock service
+// rather than on a designated shard.
+func (l *Lock) execLockService(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, als AdvisoryLockService) (*sqltypes.Result, error) {
+	session := vcursor.Session().GetSessionUUID()
+	if session == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "advisory locks held outside of MySQL can only be used by sessions with a session UUID")
+	}
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	var rrow sqltypes.Row
+	for _, lf := range l.LockFunctions {
+		var name string
+		if lf.Name != nil {
+			er, err := env.Evaluate(lf.Name)
+			if err != nil {
+				return nil, err
+			}
+			name = er.Value(vcursor.ConnCollation()).ToString()
+		}
+		var res sqltypes.Value
+		switch lf.Typ.Type {
+		case sqlparser.GetLock:
+			er, err := env.Evaluate(lf.Timeout)
+			if err != nil {
+				return nil, err
+			}
+			seconds, err := er.Value(vcursor.ConnCollation()).ToFloat64()
+			if err != nil {
+				return nil, err
+			}
+			acquired, err := als.GetLock(ctx, session, name, time.Duration(seconds*float64(time.Second)))
+			if err != nil {
+				return nil, err
+			}
+			res = boolToInt64Value(acquired)
+		case sqlparser.ReleaseLock:
+			var err error
+			if res, err = als.ReleaseLock(ctx, session, name); err != nil {
+				return nil, err
+			}
+		case sqlparser.IsFreeLock:
+			free, err := als.IsFreeLock(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			res = boolToInt64Value(free)
+		case sqlparser.ReleaseAllLocks:
+			released, err := als.ReleaseAllLocks(ctx, session)
+			if err != nil {
+				return nil, err
+			}
+			res = sqltypes.NewInt64(int64(released))
+		default:
+			return nil, vterrors.VT12001(fmt.Sprintf("%s with the advisory locks held outside of MySQL", sqlparser.String(lf.Typ)))
+		}
+		rrow = append(rrow, res)
+	}
+	return &sqltypes.Result{
+		Fields: l.serviceFields(),
+		Rows:   []sqltypes.Row{rrow},
+	}, nil
+}
+
+func boolToInt64Value(b bool) sqltypes.Value {
+	if b {
+		return sqltypes.NewInt64(1)
+	}
+	return sqltypes.NewInt64(0)
+}
+
+// serviceFields returns the fields of the lock functions executed on the
+// advisory lock service, as MySQL returns them.
+func (l *Lock) serviceFields() []*querypb.Field {
+	fields := make([]*querypb.Field, 0, len(l.LockFunctions))
+	for _, lf := range l.LockFunctions {
+		fields = append(fields, &querypb.Field{
+			Name:    sqlparser.String(lf.Typ),
+			Type:    sqltypes.Int64,
+			Charset: collations.CollationBinaryID,
+			Flags:   uint32(querypb.MySqlFlag_BINARY_FLAG | querypb.MySqlFlag_NUM_FLAG),
+		})
+	}
+	return fields
+}
+
 func (l *Lock) execLock(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
 	rss, _, err := vcursor.ResolveDestinations(ctx, l.Keyspace.Name, nil, []key.Destination{l.TargetDestination})
 	if err != nil {
@@ -162,6 +273,9 @@ func (l *Lock) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars m
 
 // GetFields is part of the Primitive interface
 func (l *Lock) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	if vcursor.AdvisoryLockService() != nil {
+		return &sqltypes.Result{Fields: l.serviceFields()}, nil
+	}
 	rss, _, err := vcursor.ResolveDestinations(ctx, l.Keyspace.Name, nil, []key.Destination{l.TargetDestination})
 	if err != nil {
 		return nil, err
@@ -325,6 +325,63 @@ func TestOrderedAggregateExecuteCountDistinct(t *testing.T) {
 	utils.MustMatch(t, wantResult, result)
 }
 
+func TestOrderedAggregateExecuteCountDistinctMultipleColumns(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"col1|col2|col3",
+				"varbinary|int64|varchar",
+			),
+			// Two identical tuples
+			"a|1|x",
+			"a|1|x",
+			// Only the second column differs
+			"b|1|x",
+			"b|1|y",
+			// A null in any column is not counted
+			"c|1|null",
+			"c|2|x",
+			"d|null|x",
+			// Only the first column differs
+			"e|1|x",
+			"e|2|x",
+			// Duplicates around a skipped null
+			"f|1|x",
+			"f|1|null",
+			"f|1|x",
+		)},
+	}
+
+	aggr := NewAggregateParam(AggregateCountDistinct, 1, "count(distinct col2, col3)", collations.MySQL8())
+	aggr.ExtraDistinct = []DistinctKey{{
+		Col:  2,
+		WCol: -1,
+		Type: evalengine.NewType(sqltypes.VarChar, collations.MySQL8().DefaultConnectionCharset()),
+	}}
+	oa := &OrderedAggregate{
+		Aggregates:  []*AggregateParams{aggr},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input:       fp,
+	}
+
+	result, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+
+	wantResult := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col1|count(distinct col2, col3)|col3",
+			"varbinary|int64|varchar",
+		),
+		"a|1|x",
+		"b|2|x",
+		"c|1|null",
+		"d|0|x",
+		"e|2|x",
+		"f|1|x",
+	)
+	utils.MustMatch(t, wantResult, result)
+}
+
 func TestOrderedAggregateStreamCountDistinct(t *testing.T) {
 	fp := &fakePrimitive{
 		results: []*sqltypes.Result{sqltypes.MakeTestResult(
@@ -45,6 +45,8 @@ type Plan struct {
 	RowsReturned uint64 // Total number of rows
 	RowsAffected uint64 // Total number of rows
 	Errors       uint64 // Total number of errors
+
+	drift driftTracker // Compares the recent executions to the first ones
 }
 
 // AddStats updates the plan execution statistics
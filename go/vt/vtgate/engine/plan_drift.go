@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// PlanDriftLatency is the reason of the drift of the plans whose
+	// executions got slower.
+	PlanDriftLatency = "Latency"
+	// PlanDriftRows is the reason of the drift of the plans whose executions
+	// return or affect more rows.
+	PlanDriftRows = "Rows"
+
+	// The drifts of the plans whose executions stay cheap are ignored: they
+	// are usually noise, and are not worth a new plan anyway.
+	driftMinLatency = time.Millisecond
+	driftMinRows    = 100
+)
+
+// PlanDrift describes how far the recent executions of a plan drifted from
+// its first ones, which reflect the data the plan was built for.
+type PlanDrift struct {
+	Reason          string
+	BaselineLatency time.Duration
+	RecentLatency   time.Duration
+	BaselineRows    float64
+	RecentRows      float64
+}
+
+// planWindow is the totals of a window of executions of a plan.
+type planWindow struct {
+	execCount uint64
+	execTime  uint64
+	rows      uint64
+}
+
+func (w planWindow) latency() time.Duration {
+	return time.Duration(w.execTime / w.execCount)
+}
+
+func (w planWindow) avgRows() float64 {
+	return float64(w.rows) / float64(w.execCount)
+}
+
+// driftTracker compares the windows of executions of a plan to its first one.
+type driftTracker struct {
+	mu sync.Mutex
+	// windowEnd is the execution count at the end of the last complete window.
+	windowEnd atomic.Uint64
+	// baseline is the first window, or the window of the last drift.
+	baseline planWindow
+	// end is the totals of the executions at the end of the last complete window.
+	end planWindow
+}
+
+// CheckDrift returns the drift of the plan if its last window of executions
+// is more than factor times slower, or returns more than factor times more
+// rows, than its baseline. The baseline is the first window of executions,
+// and is reset to the window that drifted, so that a drift is reported once.
+// It returns nil while the window is not complete.
+func (p *Plan) CheckDrift(window uint64, factor float64) *PlanDrift {
+	execCount := atomic.LoadUint64(&p.ExecCount)
+	if window == 0 || execCount-p.drift.windowEnd.Load() < window {
+		return nil
+	}
+
+	p.drift.mu.Lock()
+	defer p.drift.mu.Unlock()
+	if execCount-p.drift.windowEnd.Load() < window {
+		// Another execution completed the window first.
+		return nil
+	}
+	total := planWindow{
+		execCount: execCount,
+		execTime:  atomic.LoadUint64(&p.ExecTime),
+		rows:      atomic.LoadUint64(&p.RowsReturned) + atomic.LoadUint64(&p.RowsAffected),
+	}
+	recent := planWindow{
+		execCount: total.execCount - p.drift.end.execCount,
+		execTime:  total.execTime - p.drift.end.execTime,
+		rows:      total.rows - p.drift.end.rows,
+	}
+	p.drift.end = total
+	p.drift.windowEnd.Store(total.execCount)
+	if p.drift.baseline.execCount == 0 {
+		p.drift.baseline = recent
+		return nil
+	}
+
+	baseline := p.drift.baseline
+	drift := &PlanDrift{
+		BaselineLatency: baseline.latency(),
+		RecentLatency:   recent.latency(),
+		BaselineRows:    baseline.avgRows(),
+		RecentRows:      recent.avgRows(),
+	}
+	switch {
+	case drift.RecentLatency >= driftMinLatency && float64(drift.RecentLatency) > factor*float64(max(drift.BaselineLatency, 1)):
+		drift.Reason = PlanDriftLatency
+	case drift.RecentRows >= driftMinRows && drift.RecentRows > factor*max(drift.BaselineRows, 1):
+		drift.Reason = PlanDriftRows
+	default:
+		return nil
+	}
+	p.drift.baseline = recent
+	return drift
+}
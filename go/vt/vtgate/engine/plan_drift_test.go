@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeWindow adds the stats of a window of executions to the plan, and
+// returns the drift that the last one reported.
+func executeWindow(p *Plan, window int, latency time.Duration, rows uint64) *PlanDrift {
+	var drift *PlanDrift
+	for i := 0; i < window; i++ {
+		p.AddStats(1, latency, 1, 0, rows, 0)
+		if d := p.CheckDrift(uint64(window), 10); d != nil {
+			drift = d
+		}
+	}
+	return drift
+}
+
+func TestPlanCheckDriftLatency(t *testing.T) {
+	p := &Plan{}
+	// The first window is the baseline.
+	assert.Nil(t, executeWindow(p, 10, 2*time.Millisecond, 1))
+	assert.Nil(t, executeWindow(p, 10, 10*time.Millisecond, 1))
+
+	drift := executeWindow(p, 10, 30*time.Millisecond, 1)
+	require.NotNil(t, drift)
+	assert.Equal(t, PlanDriftLatency, drift.Reason)
+	assert.Equal(t, 2*time.Millisecond, drift.BaselineLatency)
+	assert.Equal(t, 30*time.Millisecond, drift.RecentLatency)
+
+	// The window that drifted is the new baseline.
+	assert.Nil(t, executeWindow(p, 10, 30*time.Millisecond, 1))
+
+	// The drifts of cheap plans are ignored.
+	p = &Plan{}
+	assert.Nil(t, executeWindow(p, 10, time.Microsecond, 1))
+	assert.Nil(t, executeWindow(p, 10, 100*time.Microsecond, 1))
+}
+
+func TestPlanCheckDriftRows(t *testing.T) {
+	p := &Plan{}
+	assert.Nil(t, executeWindow(p, 10, time.Microsecond, 0))
+	// Few rows are not a drift, even if there were none.
+	assert.Nil(t, executeWindow(p, 10, time.Microsecond, 50))
+
+	drift := executeWindow(p, 10, time.Microsecond, 1000)
+	require.NotNil(t, drift)
+	assert.Equal(t, PlanDriftRows, drift.Reason)
+	assert.EqualValues(t, 0, drift.BaselineRows)
+	assert.EqualValues(t, 1000, drift.RecentRows)
+}
+
+func TestPlanCheckDriftWindow(t *testing.T) {
+	p := &Plan{}
+	p.AddStats(5, 5*time.Second, 5, 0, 5, 0)
+	// The window is not complete.
+	assert.Nil(t, p.CheckDrift(10, 10))
+	assert.Nil(t, p.CheckDrift(0, 10))
+	assert.Zero(t, p.drift.baseline.execCount)
+
+	p.AddStats(5, 5*time.Second, 5, 0, 5, 0)
+	assert.Nil(t, p.CheckDrift(10, 10))
+	assert.EqualValues(t, 10, p.drift.baseline.execCount)
+	assert.EqualValues(t, 10, p.drift.windowEnd.Load())
+}
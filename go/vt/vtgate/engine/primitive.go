@@ -50,6 +50,9 @@ type (
 		GetKeyspace() string
 		// MaxMemoryRows returns the maxMemoryRows flag value.
 		MaxMemoryRows() int
+		// SpillDir returns the directory of the temporary files of the
+		// intermediate results spilled to disk, or "" for the default one.
+		SpillDir() string
 
 		// ExceedsMaxMemoryRows returns a boolean indicating whether
 		// the maxMemoryRows value has been exceeded. Returns false
@@ -71,6 +74,8 @@ type (
 
 		// Shard-level functions.
 		ExecuteMultiShard(ctx context.Context, primitive Primitive, rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, canAutocommit bool) (*sqltypes.Result, []error)
+		// ExecuteBatchMultiShard sends the batch queries[i] to rss[i] in a single round trip.
+		ExecuteBatchMultiShard(ctx context.Context, primitive Primitive, rss []*srvtopo.ResolvedShard, queries [][]*querypb.BoundQuery) (*sqltypes.Result, []error)
 		ExecuteStandalone(ctx context.Context, primitive Primitive, query string, bindVars map[string]*querypb.BindVariable, rs *srvtopo.ResolvedShard) (*sqltypes.Result, error)
 		StreamExecuteMulti(ctx context.Context, primitive Primitive, query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error
 
@@ -114,6 +119,9 @@ type (
 		ShowExec(ctx context.Context, command sqlparser.ShowCommandType, filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
 		// SetExec takes in k,v pair and use executor to set them in topo metadata.
 		SetExec(ctx context.Context, name string, value string) error
+		// FlushPlans removes the cached plans using any of the given tables or whose query matches the LIKE pattern.
+		// With no tables and no pattern, all the cached plans are removed. It returns the number of plans removed.
+		FlushPlans(ctx context.Context, tables []sqlparser.TableName, pattern string) (int, error)
 		// ThrottleApp sets a ThrottlerappRule in topo
 		ThrottleApp(ctx context.Context, throttleAppRule *topodatapb.ThrottledAppRule) error
 
@@ -123,6 +131,10 @@ type (
 		// ReleaseLock releases all the held advisory locks.
 		ReleaseLock(ctx context.Context) error
 
+		// AdvisoryLockService returns the service holding the advisory locks,
+		// or nil if they are held by a designated shard.
+		AdvisoryLockService() AdvisoryLockService
+
 		// GetWarmingReadsPercent gets the percentage of queries to clone to replicas for bufferpool warming
 		GetWarmingReadsPercent() int
 
@@ -159,11 +171,15 @@ type (
 		SetSkipQueryPlanCache(context.Context, bool) error
 		SetSQLSelectLimit(int64) error
 		SetTransactionMode(vtgatepb.TransactionMode)
+		// SetTransactionIsolation sets the isolation level of the next transaction
+		SetTransactionIsolation(querypb.ExecuteOptions_TransactionIsolation) error
 		SetWorkload(querypb.ExecuteOptions_Workload)
 		SetPlannerVersion(querypb.ExecuteOptions_PlannerVersion)
 		SetConsolidator(querypb.ExecuteOptions_Consolidator)
 		SetWorkloadName(string)
 		SetPriority(string)
+		// SetTransactionTag sets the tag recorded by the tablets with the current transaction of the session
+		SetTransactionTag(string)
 		SetFoundRows(uint64)
 
 		SetDDLStrategy(string)
@@ -183,9 +199,15 @@ type (
 		SetReadAfterWriteGTID(string)
 		SetReadAfterWriteTimeout(float64)
 		SetSessionTrackGTIDs(bool)
-
-		// HasCreatedTempTable will mark the session as having created temp tables
-		HasCreatedTempTable()
+		SetSessionTrackStateChange(bool)
+		SetSessionTrackSystemVariables(string)
+
+		// TempTableCreated records a temporary table created in the session
+		TempTableCreated(keyspace, table string)
+		// TempTableDropped forgets a temporary table dropped from the session
+		TempTableDropped(keyspace, table string)
+		// HasTempTable returns true if the temporary table exists in the session
+		HasTempTable(keyspace, table string) bool
 		GetWarnings() []*querypb.QueryWarning
 
 		// AnyAdvisoryLockTaken returns true of any advisory lock is taken
@@ -18,17 +18,40 @@ package engine
 
 import (
 	"context"
+	"sort"
+	"strings"
 
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/sysvars"
 )
 
 var _ Primitive = (*ReplaceVariables)(nil)
 
+// globalVariables are the variables whose values vtgate manages for all the
+// sessions, the only ones replaced in SHOW GLOBAL VARIABLES.
+var globalVariables = map[string]bool{
+	sysvars.Version.Name:        true,
+	sysvars.VersionComment.Name: true,
+	sysvars.Socket.Name:         true,
+}
+
 // ReplaceVariables is used in SHOW VARIABLES statements so that it replaces the values for vitess-aware variables
 type ReplaceVariables struct {
 	noTxNeeded
 	Input Primitive
+
+	// Like is the LIKE pattern of the statement, if any. The vitess-aware
+	// variables which MySQL does not know are added to the result of SHOW
+	// SESSION VARIABLES when they match it. They are never added when the
+	// statement has a WHERE filter, which only MySQL evaluates.
+	Like string
+	// Global is set for SHOW GLOBAL VARIABLES. Only the values managed by
+	// vtgate for all the sessions are replaced then, not those of the session.
+	Global bool
+	// HasWhere is set when the statement has a WHERE filter.
+	HasWhere bool
 }
 
 // NewReplaceVariables is used to create a new ReplaceVariables primitive
@@ -57,18 +80,33 @@ func (r *ReplaceVariables) TryExecute(ctx context.Context, vcursor VCursor, bind
 	if err != nil {
 		return nil, err
 	}
-	replaceVariables(qr, bindVars)
+	seen := r.replaceVariables(qr, bindVars)
+	if missing := r.missingVariables(seen, bindVars); len(missing) > 0 {
+		qr.Rows = append(qr.Rows, missing...)
+		sort.SliceStable(qr.Rows, func(i, j int) bool {
+			return qr.Rows[i][0].ToString() < qr.Rows[j][0].ToString()
+		})
+	}
 	return qr, nil
 }
 
 // TryStreamExecute implements the Primitive interface
 func (r *ReplaceVariables) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	innerCallback := callback
+	seen := map[string]bool{}
 	callback = func(result *sqltypes.Result) error {
-		replaceVariables(result, bindVars)
+		for name := range r.replaceVariables(result, bindVars) {
+			seen[name] = true
+		}
 		return innerCallback(result)
 	}
-	return vcursor.StreamExecutePrimitive(ctx, r.Input, bindVars, wantfields, callback)
+	if err := vcursor.StreamExecutePrimitive(ctx, r.Input, bindVars, wantfields, callback); err != nil {
+		return err
+	}
+	if missing := r.missingVariables(seen, bindVars); len(missing) > 0 {
+		return innerCallback(&sqltypes.Result{Rows: missing})
+	}
+	return nil
 }
 
 // GetFields implements the Primitive interface
@@ -83,17 +121,58 @@ func (r *ReplaceVariables) Inputs() ([]Primitive, []map[string]any) {
 
 // description implements the Primitive interface
 func (r *ReplaceVariables) description() PrimitiveDescription {
+	other := map[string]any{}
+	if r.Global {
+		other["Global"] = true
+	}
+	if r.Like != "" {
+		other["Like"] = r.Like
+	}
 	return PrimitiveDescription{
 		OperatorType: "ReplaceVariables",
+		Other:        other,
 	}
 }
 
-func replaceVariables(qr *sqltypes.Result, bindVars map[string]*querypb.BindVariable) {
+// replaceVariables replaces the values of the vitess-aware variables in the
+// result, and returns the names of all the variables of the result.
+func (r *ReplaceVariables) replaceVariables(qr *sqltypes.Result, bindVars map[string]*querypb.BindVariable) map[string]bool {
+	seen := make(map[string]bool, len(qr.Rows))
 	for i, row := range qr.Rows {
 		variableName := row[0].ToString()
+		seen[variableName] = true
+		if r.Global && !globalVariables[variableName] {
+			// The session values do not apply to the global variables.
+			continue
+		}
 		res, found := bindVars["__vt"+variableName]
 		if found {
 			qr.Rows[i][1] = sqltypes.NewVarChar(string(res.GetValue()))
 		}
 	}
+	return seen
+}
+
+// missingVariables returns the rows of the vitess-aware variables of the
+// session which match the statement but were not in the result of MySQL.
+func (r *ReplaceVariables) missingVariables(seen map[string]bool, bindVars map[string]*querypb.BindVariable) []sqltypes.Row {
+	if r.Global || r.HasWhere {
+		return nil
+	}
+	like := sqlparser.LikeToRegexp(strings.ToLower(r.Like))
+	var rows []sqltypes.Row
+	for _, variable := range sysvars.VitessAware {
+		if seen[variable.Name] || !like.MatchString(variable.Name) {
+			continue
+		}
+		res, found := bindVars["__vt"+variable.Name]
+		if !found {
+			continue
+		}
+		rows = append(rows, sqltypes.Row{
+			sqltypes.NewVarChar(variable.Name),
+			sqltypes.NewVarChar(string(res.GetValue())),
+		})
+	}
+	return rows
 }
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestReplaceVariables(t *testing.T) {
+	fields := sqltypes.MakeTestFields("Variable_name|Value", "varchar|varchar")
+	bindVars := map[string]*querypb.BindVariable{
+		"__vtautocommit":    sqltypes.BoolBindVariable(true),
+		"__vtversion":       sqltypes.StringBindVariable("8.0.30-Vitess"),
+		"__vtsql_mode":      sqltypes.StringBindVariable("STRICT_TRANS_TABLES"),
+		"__vtddl_strategy":  sqltypes.StringBindVariable("vitess"),
+		"__vtworkload":      sqltypes.StringBindVariable("OLTP"),
+		"__vtsession_uuid":  sqltypes.StringBindVariable("uuid"),
+		"__vtquery_timeout": sqltypes.Int64BindVariable(0),
+	}
+	input := func() *sqltypes.Result {
+		return sqltypes.MakeTestResult(fields,
+			"autocommit|OFF",
+			"sql_mode|ONLY_FULL_GROUP_BY",
+			"version|8.0.30",
+			"wait_timeout|28800",
+		)
+	}
+
+	tests := []struct {
+		name string
+		rv   *ReplaceVariables
+		want *sqltypes.Result
+	}{{
+		name: "session",
+		rv:   &ReplaceVariables{},
+		want: sqltypes.MakeTestResult(fields,
+			"autocommit|1",
+			"ddl_strategy|vitess",
+			"query_timeout|0",
+			"session_uuid|uuid",
+			"sql_mode|STRICT_TRANS_TABLES",
+			"version|8.0.30-Vitess",
+			"wait_timeout|28800",
+			"workload|OLTP",
+		),
+	}, {
+		name: "session like",
+		rv:   &ReplaceVariables{Like: "%WORK%"},
+		want: sqltypes.MakeTestResult(fields,
+			"autocommit|1",
+			"sql_mode|STRICT_TRANS_TABLES",
+			"version|8.0.30-Vitess",
+			"wait_timeout|28800",
+			"workload|OLTP",
+		),
+	}, {
+		name: "session where",
+		rv:   &ReplaceVariables{HasWhere: true},
+		want: sqltypes.MakeTestResult(fields,
+			"autocommit|1",
+			"sql_mode|STRICT_TRANS_TABLES",
+			"version|8.0.30-Vitess",
+			"wait_timeout|28800",
+		),
+	}, {
+		name: "global",
+		rv:   &ReplaceVariables{Global: true},
+		want: sqltypes.MakeTestResult(fields,
+			"autocommit|OFF",
+			"sql_mode|ONLY_FULL_GROUP_BY",
+			"version|8.0.30-Vitess",
+			"wait_timeout|28800",
+		),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rv.Input = &fakePrimitive{results: []*sqltypes.Result{input()}}
+			result, err := tt.rv.TryExecute(context.Background(), &noopVCursor{}, bindVars, true)
+			require.NoError(t, err)
+			expectResult(t, result, tt.want)
+
+			// The rows added to a stream come last.
+			tt.rv.Input = &fakePrimitive{results: []*sqltypes.Result{input()}}
+			result, err = wrapStreamExecute(tt.rv, &noopVCursor{}, bindVars, true)
+			require.NoError(t, err)
+			expectResultAnyOrder(t, result, tt.want)
+		})
+	}
+}
@@ -95,6 +95,16 @@ type Route struct {
 	// select count(*) from tbl where lookupColumn = 'not there'
 	// select exists(<subq>)
 	NoRoutesSpecialHandling bool
+
+	// InListChunkSize, when set on an IN route, splits the per-shard list of vindex
+	// values into batches of at most this size. Each batch is sent as a separate
+	// query, so that very large IN lists don't turn into a single huge query per shard.
+	InListChunkSize int
+
+	// ShardRefetchTimeout, when set, queries again, within this timeout, the shards
+	// that failed with a transient error, instead of failing the whole query. It is
+	// only set on the multi-shard routes that feed the aggregation of a plain read.
+	ShardRefetchTimeout time.Duration
 }
 
 // NewRoute creates a Route.
@@ -209,30 +219,145 @@ func (route *Route) executeShards(
 		}
 	}
 
+	var result *sqltypes.Result
+	var err error
+	if route.chunkInList() {
+		result, err = route.executeChunks(ctx, vcursor, bindVars, rss, bvs)
+	} else {
+		result, err = route.executeOnShards(ctx, vcursor, bindVars, rss, bvs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(route.OrderBy) == 0 {
+		return result, nil
+	}
+
+	return route.sort(result)
+}
+
+func (route *Route) executeOnShards(
+	ctx context.Context,
+	vcursor VCursor,
+	bindVars map[string]*querypb.BindVariable,
+	rss []*srvtopo.ResolvedShard,
+	bvs []map[string]*querypb.BindVariable,
+) (*sqltypes.Result, error) {
 	queries := getQueries(route.Query, bvs)
-	result, errs := vcursor.ExecuteMultiShard(ctx, route, rss, queries, false /* rollbackOnError */, false /* canAutocommit */)
+	var result *sqltypes.Result
+	var errs []error
+	if route.canRefetch(vcursor, len(rss)) {
+		result, errs = route.executeWithRefetch(ctx, vcursor, rss, queries)
+	} else {
+		result, errs = vcursor.ExecuteMultiShard(ctx, route, rss, queries, false /* rollbackOnError */, false /* canAutocommit */)
+	}
+
+	route.executeWarmingReplicaRead(ctx, vcursor, bindVars, func(replicaVCursor VCursor, rss []*srvtopo.ResolvedShard) []error {
+		_, errs := replicaVCursor.ExecuteMultiShard(ctx, route, rss, queries, false /* rollbackOnError */, false /* autocommit */)
+		return errs
+	})
 
-	route.executeWarmingReplicaRead(ctx, vcursor, bindVars, queries)
+	if err := route.handleShardErrors(vcursor, len(rss), errs); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	if errs != nil {
-		errs = filterOutNilErrors(errs)
-		if !route.ScatterErrorsAsWarnings || len(errs) == len(rss) {
-			return nil, vterrors.Aggregate(errs)
-		}
+// executeChunks splits the IN list of every shard into batches of at most
+// InListChunkSize values. All the batches of a shard are sent to it in a single
+// round trip, and executed there one after the other.
+func (route *Route) executeChunks(
+	ctx context.Context,
+	vcursor VCursor,
+	bindVars map[string]*querypb.BindVariable,
+	rss []*srvtopo.ResolvedShard,
+	bvs []map[string]*querypb.BindVariable,
+) (*sqltypes.Result, error) {
+	queries := make([][]*querypb.BoundQuery, len(rss))
+	for i := range rss {
+		queries[i] = getQueries(route.Query, chunkBindVars(bvs[i], route.InListChunkSize))
+	}
+	result, errs := vcursor.ExecuteBatchMultiShard(ctx, route, rss, queries)
 
-		partialSuccessScatterQueries.Add(1)
+	route.executeWarmingReplicaRead(ctx, vcursor, bindVars, func(replicaVCursor VCursor, rss []*srvtopo.ResolvedShard) []error {
+		_, errs := replicaVCursor.ExecuteBatchMultiShard(ctx, route, rss, queries)
+		return errs
+	})
 
-		for _, err := range errs {
-			serr := sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError)
-			vcursor.Session().RecordWarning(&querypb.QueryWarning{Code: uint32(serr.Num), Message: err.Error()})
-		}
+	if err := route.handleShardErrors(vcursor, len(rss), errs); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	if len(route.OrderBy) == 0 {
-		return result, nil
+// handleShardErrors returns the aggregated shard errors, unless the route
+// allows scatter errors as warnings and at least one shard succeeded, in
+// which case the errors are recorded as warnings instead.
+func (route *Route) handleShardErrors(vcursor VCursor, numShards int, errs []error) error {
+	if errs == nil {
+		return nil
+	}
+	errs = filterOutNilErrors(errs)
+	if !route.ScatterErrorsAsWarnings || len(errs) == numShards {
+		return vterrors.Aggregate(errs)
 	}
 
-	return route.sort(result)
+	partialSuccessScatterQueries.Add(1)
+
+	for _, err := range errs {
+		serr := sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError)
+		vcursor.Session().RecordWarning(&querypb.QueryWarning{Code: uint32(serr.Num), Message: err.Error()})
+	}
+	return nil
+}
+
+func (route *Route) chunkInList() bool {
+	return route.InListChunkSize > 0 && route.Opcode == IN
+}
+
+type shardRound struct {
+	rss []*srvtopo.ResolvedShard
+	bvs []map[string]*querypb.BindVariable
+}
+
+// chunkShardVars splits the ListVarName values of every shard into batches of at most size
+// values. The n-th round contains the n-th batch of every shard that still has values left.
+func chunkShardVars(rss []*srvtopo.ResolvedShard, bvs []map[string]*querypb.BindVariable, size int) []shardRound {
+	var rounds []shardRound
+	for i, rs := range rss {
+		for r, bv := range chunkBindVars(bvs[i], size) {
+			if r == len(rounds) {
+				rounds = append(rounds, shardRound{})
+			}
+			rounds[r].rss = append(rounds[r].rss, rs)
+			rounds[r].bvs = append(rounds[r].bvs, bv)
+		}
+	}
+	return rounds
+}
+
+// chunkBindVars splits the ListVarName values of bv into batches of at most size values,
+// and returns a copy of bv for every batch. bv is returned as is if it fits in one batch.
+func chunkBindVars(bv map[string]*querypb.BindVariable, size int) []map[string]*querypb.BindVariable {
+	vals := bv[ListVarName]
+	if vals == nil || len(vals.Values) <= size {
+		return []map[string]*querypb.BindVariable{bv}
+	}
+	var chunks []map[string]*querypb.BindVariable
+	for start := 0; start < len(vals.Values); start += size {
+		end := min(start+size, len(vals.Values))
+		newbv := make(map[string]*querypb.BindVariable, len(bv))
+		for k, v := range bv {
+			newbv[k] = v
+		}
+		newbv[ListVarName] = &querypb.BindVariable{
+			Type:   querypb.Type_TUPLE,
+			Values: vals.Values[start:end],
+		}
+		chunks = append(chunks, newbv)
+	}
+	return chunks
 }
 
 func filterOutNilErrors(errs []error) []error {
@@ -302,27 +427,46 @@ func (route *Route) streamExecuteShards(
 		}
 	}
 
-	if len(route.OrderBy) == 0 {
-		errs := vcursor.StreamExecuteMulti(ctx, route, route.Query, rss, bvs, false /* rollbackOnError */, false /* autocommit */, func(qr *sqltypes.Result) error {
-			return callback(qr.Truncate(route.TruncateColumnCount))
-		})
-		if len(errs) > 0 {
-			if !route.ScatterErrorsAsWarnings || len(errs) == len(rss) {
-				return vterrors.Aggregate(errs)
-			}
-			partialSuccessScatterQueries.Add(1)
-			for _, err := range errs {
-				sErr := sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError)
-				vcursor.Session().RecordWarning(&querypb.QueryWarning{Code: uint32(sErr.Num), Message: err.Error()})
+	if len(route.OrderBy) == 0 && route.chunkInList() {
+		for _, round := range chunkShardVars(rss, bvs, route.InListChunkSize) {
+			if err := route.streamExecuteOnShards(ctx, vcursor, callback, round.rss, round.bvs); err != nil {
+				return err
 			}
 		}
 		return nil
 	}
 
+	if len(route.OrderBy) == 0 {
+		return route.streamExecuteOnShards(ctx, vcursor, callback, rss, bvs)
+	}
+
 	// There is an order by. We have to merge-sort.
 	return route.mergeSort(ctx, vcursor, bindVars, wantfields, callback, rss, bvs)
 }
 
+func (route *Route) streamExecuteOnShards(
+	ctx context.Context,
+	vcursor VCursor,
+	callback func(*sqltypes.Result) error,
+	rss []*srvtopo.ResolvedShard,
+	bvs []map[string]*querypb.BindVariable,
+) error {
+	errs := vcursor.StreamExecuteMulti(ctx, route, route.Query, rss, bvs, false /* rollbackOnError */, false /* autocommit */, func(qr *sqltypes.Result) error {
+		return callback(qr.Truncate(route.TruncateColumnCount))
+	})
+	if len(errs) > 0 {
+		if !route.ScatterErrorsAsWarnings || len(errs) == len(rss) {
+			return vterrors.Aggregate(errs)
+		}
+		partialSuccessScatterQueries.Add(1)
+		for _, err := range errs {
+			sErr := sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError)
+			vcursor.Session().RecordWarning(&querypb.QueryWarning{Code: uint32(sErr.Num), Message: err.Error()})
+		}
+	}
+	return nil
+}
+
 func (route *Route) mergeSort(
 	ctx context.Context,
 	vcursor VCursor,
@@ -443,6 +587,12 @@ func (route *Route) description() PrimitiveDescription {
 	if route.QueryTimeout > 0 {
 		other["QueryTimeout"] = route.QueryTimeout
 	}
+	if route.InListChunkSize > 0 {
+		other["InListChunkSize"] = route.InListChunkSize
+	}
+	if route.ShardRefetchTimeout > 0 {
+		other["ShardRefetchTimeout"] = route.ShardRefetchTimeout.String()
+	}
 	return PrimitiveDescription{
 		OperatorType:      "Route",
 		Variant:           route.Opcode.String(),
@@ -534,7 +684,10 @@ func orderByToString(in any) string {
 	return obp.String()
 }
 
-func (route *Route) executeWarmingReplicaRead(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, queries []*querypb.BoundQuery) {
+// executeWarmingReplicaRead sends, for a share of the reads, the same queries
+// to the replicas in the background, to keep their buffer pool warm. execute
+// sends the queries of the route to the given replica shards.
+func (route *Route) executeWarmingReplicaRead(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, execute func(replicaVCursor VCursor, rss []*srvtopo.ResolvedShard) []error) {
 	switch route.Opcode {
 	case Unsharded, Scatter, Equal, EqualUnique, IN, MultiEqual:
 		// no-op
@@ -561,7 +714,7 @@ func (route *Route) executeWarmingReplicaRead(ctx context.Context, vcursor VCurs
 				return
 			}
 
-			_, errs := replicaVCursor.ExecuteMultiShard(ctx, route, rss, queries, false /* rollbackOnError */, false /* autocommit */)
+			errs := execute(replicaVCursor, rss)
 			if len(errs) > 0 {
 				log.Warningf("Failed to execute warming replica read: %v", errs)
 			} else {
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var partialRefetches = stats.NewCountersWithSingleLabel(
+	"ScatterPartialRefetches",
+	"Number of shards of scatter aggregations whose results were fetched again after a transient error, by result",
+	"Result")
+
+// canRefetch returns true if the shards of the route that fail with a
+// transient error can be queried again. The shards must be queried outside
+// of a transaction or reserved connection, so that a query is never repeated
+// in a session whose state it could have changed.
+func (route *Route) canRefetch(vcursor VCursor, numShards int) bool {
+	if route.ShardRefetchTimeout <= 0 || numShards < 2 {
+		return false
+	}
+	session := vcursor.Session()
+	return !session.InTransaction() && !session.InReservedConn()
+}
+
+// executeWithRefetch queries every shard on its own, so that the shards
+// that fail with a transient error can be queried again, once, within
+// ShardRefetchTimeout. The results of the shards are merged in shard order,
+// and the errors that remain are returned like the ones of a single query
+// to all the shards.
+func (route *Route) executeWithRefetch(
+	ctx context.Context,
+	vcursor VCursor,
+	rss []*srvtopo.ResolvedShard,
+	queries []*querypb.BoundQuery,
+) (*sqltypes.Result, []error) {
+	results := make([]*sqltypes.Result, len(rss))
+	errs := make([]error, len(rss))
+	route.executeEachShard(ctx, vcursor, rss, queries, results, errs, func(int) bool { return true })
+
+	var refetch []bool
+	for i, err := range errs {
+		if err != nil && isTransientShardError(err) {
+			if refetch == nil {
+				refetch = make([]bool, len(rss))
+			}
+			refetch[i] = true
+		}
+	}
+	if refetch != nil {
+		refetchCtx, cancel := context.WithTimeout(ctx, route.ShardRefetchTimeout)
+		route.executeEachShard(refetchCtx, vcursor, rss, queries, results, errs, func(i int) bool { return refetch[i] })
+		cancel()
+		for i := range refetch {
+			switch {
+			case !refetch[i]:
+			case errs[i] == nil:
+				partialRefetches.Add("Success", 1)
+			default:
+				partialRefetches.Add("Failure", 1)
+			}
+		}
+	}
+
+	result := &sqltypes.Result{}
+	var shardErrs []error
+	for i, err := range errs {
+		if err != nil {
+			shardErrs = append(shardErrs, err)
+			continue
+		}
+		result.AppendResult(results[i])
+	}
+	return result, shardErrs
+}
+
+// executeEachShard concurrently queries each selected shard on its own.
+func (route *Route) executeEachShard(
+	ctx context.Context,
+	vcursor VCursor,
+	rss []*srvtopo.ResolvedShard,
+	queries []*querypb.BoundQuery,
+	results []*sqltypes.Result,
+	errs []error,
+	selected func(int) bool,
+) {
+	var wg sync.WaitGroup
+	for i := range rss {
+		if !selected(i) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			qr, shardErrs := vcursor.ExecuteMultiShard(ctx, route, rss[i:i+1], queries[i:i+1], false /* rollbackOnError */, false /* canAutocommit */)
+			results[i], errs[i] = qr, vterrors.Aggregate(filterOutNilErrors(shardErrs))
+		}(i)
+	}
+	wg.Wait()
+}
+
+// isTransientShardError returns true if the shard error is expected to go
+// away by itself, for instance while the tablets of the shard are being
+// replaced, so that querying the shard again is worth it.
+func isTransientShardError(err error) bool {
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_CLUSTER_EVENT:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// refetchVCursor answers each shard with a row of the shard name,
+// after failing with the errors queued for the shard.
+type refetchVCursor struct {
+	*loggingVCursor
+
+	mu       sync.Mutex
+	failures map[string][]error
+	queried  []string
+}
+
+func (vc *refetchVCursor) ExecuteMultiShard(ctx context.Context, primitive Primitive, rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, canAutocommit bool) (*sqltypes.Result, []error) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	result := &sqltypes.Result{}
+	var errs []error
+	for _, rs := range rss {
+		shard := rs.Target.Shard
+		vc.queried = append(vc.queried, shard)
+		if failures := vc.failures[shard]; len(failures) > 0 {
+			vc.failures[shard] = failures[1:]
+			errs = append(errs, failures[0])
+			continue
+		}
+		result.AppendResult(sqltypes.MakeTestResult(sqltypes.MakeTestFields("shard", "varchar"), shard))
+	}
+	return result, errs
+}
+
+func (vc *refetchVCursor) queriedShards() []string {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	queried := append([]string(nil), vc.queried...)
+	sort.Strings(queried)
+	return queried
+}
+
+func newRefetchRoute() *Route {
+	route := NewRoute(Scatter, &vindexes.Keyspace{Name: "ks", Sharded: true}, "dummy_select", "dummy_select_field")
+	route.ShardRefetchTimeout = time.Second
+	return route
+}
+
+func TestRouteRefetchTransientShardError(t *testing.T) {
+	unavailable := vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy tablet")
+	vc := &refetchVCursor{
+		loggingVCursor: &loggingVCursor{shards: []string{"-20", "20-40", "40-"}},
+		failures:       map[string][]error{"20-40": {unavailable}},
+	}
+	successes := partialRefetches.Counts()["Success"]
+
+	result, err := newRefetchRoute().TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	// Only the failed shard is queried again, and the results are merged in shard order.
+	assert.Equal(t, []string{"-20", "20-40", "20-40", "40-"}, vc.queriedShards())
+	expectResult(t, result, sqltypes.MakeTestResult(sqltypes.MakeTestFields("shard", "varchar"), "-20", "20-40", "40-"))
+	assert.EqualValues(t, successes+1, partialRefetches.Counts()["Success"])
+}
+
+func TestRouteRefetchFailure(t *testing.T) {
+	unavailable := vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy tablet")
+	vc := &refetchVCursor{
+		loggingVCursor: &loggingVCursor{shards: []string{"-20", "20-"}},
+		failures:       map[string][]error{"20-": {unavailable, unavailable}},
+	}
+	failures := partialRefetches.Counts()["Failure"]
+
+	// A shard is queried again only once.
+	_, err := newRefetchRoute().TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "no healthy tablet")
+	assert.Equal(t, []string{"-20", "20-", "20-"}, vc.queriedShards())
+	assert.EqualValues(t, failures+1, partialRefetches.Counts()["Failure"])
+}
+
+func TestRouteRefetchNotTransient(t *testing.T) {
+	vc := &refetchVCursor{
+		loggingVCursor: &loggingVCursor{shards: []string{"-20", "20-"}},
+		failures:       map[string][]error{"20-": {vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "syntax error")}},
+	}
+	_, err := newRefetchRoute().TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "syntax error")
+	assert.Equal(t, []string{"-20", "20-"}, vc.queriedShards())
+}
+
+func TestRouteRefetchInTransaction(t *testing.T) {
+	unavailable := vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no healthy tablet")
+	vc := &refetchVCursor{
+		loggingVCursor: &loggingVCursor{shards: []string{"-20", "20-"}},
+		failures:       map[string][]error{"20-": {unavailable}},
+	}
+	vc.inTx = true
+
+	// The shards are not queried again in a transaction.
+	_, err := newRefetchRoute().TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "no healthy tablet")
+	assert.Equal(t, []string{"-20", "20-"}, vc.queriedShards())
+}
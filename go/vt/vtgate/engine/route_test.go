@@ -517,6 +517,115 @@ func TestINUnique(t *testing.T) {
 	expectResult(t, result, defaultSelectResult)
 }
 
+func TestINChunked(t *testing.T) {
+	vindex, _ := vindexes.CreateVindex("hash", "", nil)
+	sel := NewRoute(
+		IN,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+	sel.Values = []evalengine.Expr{
+		evalengine.TupleExpr{
+			evalengine.NewLiteralInt(1),
+			evalengine.NewLiteralInt(2),
+			evalengine.NewLiteralInt(4),
+		},
+	}
+	sel.InListChunkSize = 1
+	vc := &loggingVCursor{
+		shards:       []string{"-20", "20-"},
+		shardForKsid: []string{"-20", "-20", "20-"},
+		results:      []*sqltypes.Result{defaultSelectResult, defaultSelectResult},
+	}
+	result, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [type:INT64 value:"1" type:INT64 value:"2" type:INT64 value:"4"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6),DestinationKeyspaceID(06e7ea22ce92708f),DestinationKeyspaceID(d2fd8867d50d2dfe)`,
+		`ExecuteBatchMultiShard ` +
+			`ks.-20: [dummy_select {__vals: type:TUPLE values:{type:INT64 value:"1"}}, dummy_select {__vals: type:TUPLE values:{type:INT64 value:"2"}}] ` +
+			`ks.20-: [dummy_select {__vals: type:TUPLE values:{type:INT64 value:"4"}}] `,
+	})
+	expectResult(t, result, defaultSelectResult)
+
+	vc.Rewind()
+	_, err = wrapStreamExecute(sel, vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [type:INT64 value:"1" type:INT64 value:"2" type:INT64 value:"4"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6),DestinationKeyspaceID(06e7ea22ce92708f),DestinationKeyspaceID(d2fd8867d50d2dfe)`,
+		`StreamExecuteMulti dummy_select ks.-20: {__vals: type:TUPLE values:{type:INT64 value:"1"}} ks.20-: {__vals: type:TUPLE values:{type:INT64 value:"4"}} `,
+		`StreamExecuteMulti dummy_select ks.-20: {__vals: type:TUPLE values:{type:INT64 value:"2"}} `,
+	})
+}
+
+// warmingVCursor sends all the reads again to its replica.
+type warmingVCursor struct {
+	*loggingVCursor
+	replica      *loggingVCursor
+	warmingReads chan bool
+}
+
+func (vc *warmingVCursor) GetWarmingReadsPercent() int {
+	return 100
+}
+
+func (vc *warmingVCursor) GetWarmingReadsChannel() chan bool {
+	return vc.warmingReads
+}
+
+func (vc *warmingVCursor) CloneForReplicaWarming(ctx context.Context) VCursor {
+	return vc.replica
+}
+
+func TestINChunkedWarmingReads(t *testing.T) {
+	vindex, _ := vindexes.CreateVindex("hash", "", nil)
+	sel := NewRoute(
+		IN,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+	sel.Values = []evalengine.Expr{
+		evalengine.TupleExpr{
+			evalengine.NewLiteralInt(1),
+			evalengine.NewLiteralInt(2),
+		},
+	}
+	sel.InListChunkSize = 1
+	vc := &warmingVCursor{
+		loggingVCursor: &loggingVCursor{
+			shards:       []string{"-20", "20-"},
+			shardForKsid: []string{"-20", "-20"},
+			results:      []*sqltypes.Result{defaultSelectResult},
+		},
+		replica: &loggingVCursor{
+			shards:       []string{"-20", "20-"},
+			shardForKsid: []string{"-20", "-20"},
+			results:      []*sqltypes.Result{defaultSelectResult},
+		},
+		warmingReads: make(chan bool, 1),
+	}
+	_, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+
+	// The chunks are also sent to the replica. The warming read releases its
+	// slot of the channel once it is done.
+	vc.warmingReads <- true
+	vc.replica.ExpectLog(t, []string{
+		`ResolveDestinations ks [type:INT64 value:"1" type:INT64 value:"2"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6),DestinationKeyspaceID(06e7ea22ce92708f)`,
+		`ExecuteBatchMultiShard ` +
+			`ks.-20: [dummy_select {__vals: type:TUPLE values:{type:INT64 value:"1"}}, dummy_select {__vals: type:TUPLE values:{type:INT64 value:"2"}}] `,
+	})
+}
+
 func TestINNonUnique(t *testing.T) {
 	vindex, _ := vindexes.CreateVindex("lookup", "", map[string]string{
 		"table": "lkp",
@@ -1646,6 +1755,54 @@ func TestMultiEqualMultiCol(t *testing.T) {
 	expectResult(t, result, defaultSelectResult)
 }
 
+func TestMultiEqualMultiColWithSingleValue(t *testing.T) {
+	vindex, _ := vindexes.CreateVindex("region_experimental", "", map[string]string{"region_bytes": "1"})
+	sel := NewRoute(
+		MultiEqual,
+		&vindexes.Keyspace{Name: "ks", Sharded: true},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex
+	// cola = 1 and (colb, colc) in ((2, 5), (4, 6)) with a vindex on (cola, colb)
+	sel.Values = []evalengine.Expr{
+		evalengine.NewLiteralInt(1),
+		evalengine.NewTupleExpr(
+			evalengine.NewLiteralInt(2),
+			evalengine.NewLiteralInt(4),
+		),
+	}
+
+	vc := &loggingVCursor{
+		shards:       []string{"-20", "20-40", "40-"},
+		shardForKsid: []string{"-20", "40-"},
+		results:      []*sqltypes.Result{defaultSelectResult},
+	}
+	result, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinationsMultiCol ks [[INT64(1) INT64(2)] [INT64(1) INT64(4)]] Destinations:DestinationKeyspaceID(0106e7ea22ce92708f),DestinationKeyspaceID(01d2fd8867d50d2dfe)`,
+		`ExecuteMultiShard ks.-20: dummy_select {} ks.40-: dummy_select {} false false`,
+	})
+	expectResult(t, result, defaultSelectResult)
+
+	// tuples of different lengths cannot be paired up.
+	sel.Values = []evalengine.Expr{
+		evalengine.NewTupleExpr(
+			evalengine.NewLiteralInt(1),
+			evalengine.NewLiteralInt(3),
+			evalengine.NewLiteralInt(5),
+		),
+		evalengine.NewTupleExpr(
+			evalengine.NewLiteralInt(2),
+			evalengine.NewLiteralInt(4),
+		),
+	}
+	vc.Rewind()
+	_, err = sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.ErrorContains(t, err, "tuple values for multi column vindex have mismatched lengths: 3 and 2")
+}
+
 func TestBuildRowColValues(t *testing.T) {
 	out := buildRowColValues([][]sqltypes.Value{
 		{sqltypes.NewInt64(1), sqltypes.NewInt64(10)},
@@ -416,13 +416,27 @@ func (rp *RoutingParameters) multiEqual(ctx context.Context, vcursor VCursor, bi
 
 func (rp *RoutingParameters) multiEqualMultiCol(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
 	var multiColValues [][]sqltypes.Value
+	// columns compared with a single value, e.g. `cola = 1 and (colb, colc) in ((2, 3), (4, 5))`,
+	// apply to every row of the tuple comparison.
+	isSingleVal := map[int]any{}
+	rows := 0
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
-	for _, rvalue := range rp.Values {
+	for colIdx, rvalue := range rp.Values {
 		v, err := env.Evaluate(rvalue)
 		if err != nil {
 			return nil, nil, err
 		}
-		multiColValues = append(multiColValues, v.TupleValues())
+		colValues := v.TupleValues()
+		if colValues == nil {
+			isSingleVal[colIdx] = nil
+			colValues = []sqltypes.Value{v.Value(vcursor.ConnCollation())}
+		} else {
+			if rows != 0 && rows != len(colValues) {
+				return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "tuple values for multi column vindex have mismatched lengths: %d and %d", rows, len(colValues))
+			}
+			rows = len(colValues)
+		}
+		multiColValues = append(multiColValues, colValues)
 	}
 
 	// transpose from multi col value to vindex keys with one value from each multi column values.
@@ -433,10 +447,17 @@ func (rp *RoutingParameters) multiEqualMultiCol(ctx context.Context, vcursor VCu
 	// [1,2,5]
 	// [3,4,6]
 
-	rowColValues := make([][]sqltypes.Value, len(multiColValues[0]))
-	for _, colValues := range multiColValues {
-		for row, colVal := range colValues {
-			rowColValues[row] = append(rowColValues[row], colVal)
+	if len(isSingleVal) == len(multiColValues) {
+		rows = 1
+	}
+	rowColValues := make([][]sqltypes.Value, rows)
+	for colIdx, colValues := range multiColValues {
+		for row := range rowColValues {
+			if _, single := isSingleVal[colIdx]; single {
+				rowColValues[row] = append(rowColValues[row], colValues[0])
+				continue
+			}
+			rowColValues[row] = append(rowColValues[row], colValues[row])
 		}
 	}
 
@@ -93,6 +93,13 @@ type (
 		Expr evalengine.Expr
 	}
 
+	// NextTxIsolation implements the SetOp interface and will write the isolation level of the
+	// next transaction into the session, like SET TRANSACTION ISOLATION LEVEL does.
+	NextTxIsolation struct {
+		Name string
+		Expr evalengine.Expr
+	}
+
 	// VitessMetadata implements the SetOp interface and will write the changes variable into the topo server
 	VitessMetadata struct {
 		Name, Value string
@@ -466,6 +473,15 @@ func (svss *SysVarSetAware) Execute(ctx context.Context, vcursor VCursor, env *e
 			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongValueForVar, "invalid transaction_mode: %s", str)
 		}
 		vcursor.Session().SetTransactionMode(vtgatepb.TransactionMode(out))
+	case sysvars.TransactionTag.Name:
+		str, err := svss.evalAsString(env, vcursor)
+		if err != nil {
+			return err
+		}
+		if err := sqlparser.ValidateTransactionTag(str); err != nil {
+			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongValueForVar, "invalid transaction_tag: %s", str)
+		}
+		vcursor.Session().SetTransactionTag(str)
 	case sysvars.Workload.Name:
 		str, err := svss.evalAsString(env, vcursor)
 		if err != nil {
@@ -539,6 +555,17 @@ func (svss *SysVarSetAware) Execute(ctx context.Context, vcursor VCursor, env *e
 		default:
 			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongValueForVar, "variable 'session_track_gtids' can't be set to the value of '%s'", str)
 		}
+	case sysvars.SessionTrackStateChange.Name:
+		err = svss.setBoolSysVar(ctx, env, func(_ context.Context, enable bool) error {
+			vcursor.Session().SetSessionTrackStateChange(enable)
+			return nil
+		})
+	case sysvars.SessionTrackSystemVariables.Name:
+		str, err := svss.evalAsString(env, vcursor)
+		if err != nil {
+			return err
+		}
+		vcursor.Session().SetSessionTrackSystemVariables(str)
 	default:
 		return vterrors.NewErrorf(vtrpcpb.Code_NOT_FOUND, vterrors.UnknownSystemVariable, "unknown system variable '%s'", svss.Name)
 	}
@@ -607,6 +634,51 @@ func (svss *SysVarSetAware) VariableName() string {
 	return svss.Name
 }
 
+var _ SetOp = (*NextTxIsolation)(nil)
+
+// txIsolationLevels maps the values of transaction_isolation to the isolation levels of the tablets.
+var txIsolationLevels = map[string]querypb.ExecuteOptions_TransactionIsolation{
+	sqlparser.RepeatableReadStr:  querypb.ExecuteOptions_REPEATABLE_READ,
+	sqlparser.ReadCommittedStr:   querypb.ExecuteOptions_READ_COMMITTED,
+	sqlparser.ReadUncommittedStr: querypb.ExecuteOptions_READ_UNCOMMITTED,
+	sqlparser.SerializableStr:    querypb.ExecuteOptions_SERIALIZABLE,
+}
+
+// MarshalJSON marshals all the json
+func (ntx *NextTxIsolation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string
+		Name string
+		Expr string
+	}{
+		Type: "NextTxIsolation",
+		Name: ntx.Name,
+		Expr: sqlparser.String(ntx.Expr),
+	})
+}
+
+// Execute implements the SetOp interface method
+func (ntx *NextTxIsolation) Execute(ctx context.Context, vcursor VCursor, env *evalengine.ExpressionEnv) error {
+	value, err := env.Evaluate(ntx.Expr)
+	if err != nil {
+		return err
+	}
+	v := value.Value(vcursor.ConnCollation())
+	if !v.IsText() && !v.IsBinary() {
+		return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongTypeForVar, "incorrect argument type to variable '%s': %s", ntx.Name, v.Type().String())
+	}
+	level, ok := txIsolationLevels[strings.ToLower(v.ToString())]
+	if !ok {
+		return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongValueForVar, "variable '%s' can't be set to the value of '%s'", ntx.Name, v.ToString())
+	}
+	return vcursor.Session().SetTransactionIsolation(level)
+}
+
+// VariableName implements the SetOp interface method
+func (ntx *NextTxIsolation) VariableName() string {
+	return ntx.Name
+}
+
 var _ SetOp = (*VitessMetadata)(nil)
 
 func (v *VitessMetadata) Execute(ctx context.Context, vcursor VCursor, env *evalengine.ExpressionEnv) error {
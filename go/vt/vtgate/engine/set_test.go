@@ -29,6 +29,7 @@ import (
 
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
@@ -557,6 +558,26 @@ func TestSetTable(t *testing.T) {
 		qr: []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("new", "varchar"),
 			"a",
 		)},
+	}, {
+		testName: "next transaction isolation",
+		setOps: []SetOp{
+			&NextTxIsolation{
+				Name: "transaction_isolation",
+				Expr: evalengine.NewLiteralString([]byte("READ-COMMITTED"), collations.SystemCollation),
+			},
+		},
+		expectedQueryLog: []string{
+			"TransactionIsolation set to READ_COMMITTED",
+		},
+	}, {
+		testName: "next transaction isolation invalid",
+		setOps: []SetOp{
+			&NextTxIsolation{
+				Name: "transaction_isolation",
+				Expr: evalengine.NewLiteralString([]byte("snapshot"), collations.SystemCollation),
+			},
+		},
+		expectedError: "variable 'transaction_isolation' can't be set to the value of 'snapshot'",
 	}}
 
 	for _, tc := range tests {
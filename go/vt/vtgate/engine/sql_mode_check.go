@@ -0,0 +1,255 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The sql_mode checks that vtgate can enforce on the inserted values.
+const (
+	// SQLModeNoZeroDate rejects the zero dates inserted in date columns.
+	SQLModeNoZeroDate = "NO_ZERO_DATE"
+	// SQLModeStrictTransTables rejects the strings too long for their column
+	// and the integers out of the range of their column.
+	SQLModeStrictTransTables = "STRICT_TRANS_TABLES"
+)
+
+// IsEnforcedSQLMode returns true if vtgate can enforce the checks of the mode.
+func IsEnforcedSQLMode(mode string) bool {
+	return mode == SQLModeNoZeroDate || mode == SQLModeStrictTransTables
+}
+
+var _ Primitive = (*SQLModeCheck)(nil)
+
+// SQLModeCheck checks the values inserted by its input against the sql_mode of
+// the session before executing it, so that the invalid values are rejected
+// before the insert is sent to the shards. A value is only checked if it can be
+// evaluated by vtgate: the others are left for MySQL to check.
+type SQLModeCheck struct {
+	Input  Primitive
+	Checks []*ValueCheck
+}
+
+// ValueCheck is the check of a value inserted in a column.
+type ValueCheck struct {
+	// SQLMode is the mode that enables the check. The checks of all the modes
+	// only apply in strict mode, like in MySQL.
+	SQLMode string
+	Column  string
+	// Row is the position of the value in the inserted rows, starting at 1.
+	Row  int
+	Type querypb.Type
+	Size int32
+	Expr evalengine.Expr
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (s *SQLModeCheck) RouteType() string {
+	return s.Input.RouteType()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (s *SQLModeCheck) GetKeyspaceName() string {
+	return s.Input.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (s *SQLModeCheck) GetTableName() string {
+	return s.Input.GetTableName()
+}
+
+// GetFields implements the Primitive interface.
+func (s *SQLModeCheck) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return s.Input.GetFields(ctx, vcursor, bindVars)
+}
+
+// NeedsTransaction implements the Primitive interface.
+func (s *SQLModeCheck) NeedsTransaction() bool {
+	return s.Input.NeedsTransaction()
+}
+
+// TryExecute implements the Primitive interface.
+func (s *SQLModeCheck) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if err := s.check(ctx, vcursor, bindVars); err != nil {
+		return nil, err
+	}
+	return vcursor.ExecutePrimitive(ctx, s.Input, bindVars, wantfields)
+}
+
+// TryStreamExecute implements the Primitive interface.
+func (s *SQLModeCheck) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	if err := s.check(ctx, vcursor, bindVars); err != nil {
+		return err
+	}
+	return vcursor.StreamExecutePrimitive(ctx, s.Input, bindVars, wantfields, callback)
+}
+
+// Inputs implements the Primitive interface.
+func (s *SQLModeCheck) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{s.Input}, nil
+}
+
+func (s *SQLModeCheck) description() PrimitiveDescription {
+	checks := make([]string, 0, len(s.Checks))
+	for _, c := range s.Checks {
+		checks = append(checks, fmt.Sprintf("%s row %d: %s", c.Column, c.Row, c.SQLMode))
+	}
+	return PrimitiveDescription{
+		OperatorType: "SQLModeCheck",
+		Other:        map[string]any{"Checks": checks},
+	}
+}
+
+func (s *SQLModeCheck) check(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) error {
+	modes := map[string]bool{}
+	for _, mode := range strings.Split(strings.ToUpper(vcursor.SQLMode()), ",") {
+		modes[strings.TrimSpace(mode)] = true
+	}
+	if !modes[SQLModeStrictTransTables] && !modes["STRICT_ALL_TABLES"] {
+		// Without a strict mode, MySQL only warns about the invalid values.
+		return nil
+	}
+
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	for _, c := range s.Checks {
+		if !modes[c.SQLMode] {
+			continue
+		}
+		res, err := env.Evaluate(c.Expr)
+		if err != nil {
+			// MySQL returns the error, if any.
+			continue
+		}
+		value := res.Value(vcursor.ConnCollation())
+		if value.IsNull() {
+			continue
+		}
+		if err := c.check(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ValueCheck) check(value sqltypes.Value) error {
+	switch {
+	case sqltypes.IsDate(c.Type):
+		if isZeroDate(value) {
+			typ := "date"
+			if c.Type != sqltypes.Date {
+				typ = "datetime"
+			}
+			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.TruncatedWrongValue, "Incorrect %s value: '%s' for column '%s' at row %d", typ, value.ToString(), c.Column, c.Row)
+		}
+	case sqltypes.IsIntegral(c.Type):
+		if !integerInRange(c.Type, value) {
+			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WarnDataOutOfRange, "Out of range value for column '%s' at row %d", c.Column, c.Row)
+		}
+	default:
+		var length int
+		if sqltypes.IsText(c.Type) {
+			// The trailing spaces are truncated with a note only.
+			length = utf8.RuneCountInString(strings.TrimRight(value.ToString(), " "))
+		} else {
+			length = len(value.Raw())
+		}
+		if length > int(c.Size) {
+			return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.DataTooLong, "Data too long for column '%s' at row %d", c.Column, c.Row)
+		}
+	}
+	return nil
+}
+
+// isZeroDate returns true if the value is a zero date or datetime, or the 0
+// number which MySQL converts to it.
+func isZeroDate(value sqltypes.Value) bool {
+	if value.IsIntegral() {
+		return value.ToString() == "0"
+	}
+	if !value.IsText() && !value.IsBinary() && !value.IsDate() && !value.IsDateTime() && !value.IsTimestamp() {
+		return false
+	}
+	s := value.ToString()
+	date, clock, hasClock := strings.Cut(s, " ")
+	if date != "0000-00-00" {
+		return false
+	}
+	if !hasClock {
+		return true
+	}
+	clock, frac, _ := strings.Cut(clock, ".")
+	return clock == "00:00:00" && strings.Trim(frac, "0") == ""
+}
+
+// integerInRange returns false if the integer value is out of the range of the
+// integer type. The values which are not integers are not checked.
+func integerInRange(typ querypb.Type, value sqltypes.Value) bool {
+	var minValue int64
+	var maxValue uint64
+	switch typ {
+	case sqltypes.Int8:
+		minValue, maxValue = math.MinInt8, math.MaxInt8
+	case sqltypes.Uint8:
+		maxValue = math.MaxUint8
+	case sqltypes.Int16:
+		minValue, maxValue = math.MinInt16, math.MaxInt16
+	case sqltypes.Uint16:
+		maxValue = math.MaxUint16
+	case sqltypes.Int24:
+		minValue, maxValue = -1<<23, 1<<23-1
+	case sqltypes.Uint24:
+		maxValue = 1<<24 - 1
+	case sqltypes.Int32:
+		minValue, maxValue = math.MinInt32, math.MaxInt32
+	case sqltypes.Uint32:
+		maxValue = math.MaxUint32
+	case sqltypes.Int64:
+		minValue, maxValue = math.MinInt64, math.MaxInt64
+	case sqltypes.Uint64:
+		maxValue = math.MaxUint64
+	default:
+		return true
+	}
+
+	switch {
+	case value.IsSigned():
+		n, err := value.ToInt64()
+		if err != nil {
+			return true
+		}
+		return n >= minValue && (n < 0 || uint64(n) <= maxValue)
+	case value.IsUnsigned():
+		n, err := value.ToUint64()
+		if err != nil {
+			return true
+		}
+		return n <= maxValue
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+type sqlModeVCursor struct {
+	noopVCursor
+	sqlMode string
+}
+
+func (vc *sqlModeVCursor) SQLMode() string {
+	return vc.sqlMode
+}
+
+func TestSQLModeCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		typ    querypb.Type
+		size   int32
+		value  *querypb.BindVariable
+		sqlErr sqlerror.ErrorCode
+	}{{
+		name:   "zero date",
+		mode:   SQLModeNoZeroDate,
+		typ:    sqltypes.Date,
+		value:  sqltypes.StringBindVariable("0000-00-00"),
+		sqlErr: sqlerror.ERTruncatedWrongValue,
+	}, {
+		name:   "zero datetime",
+		mode:   SQLModeNoZeroDate,
+		typ:    sqltypes.Datetime,
+		value:  sqltypes.StringBindVariable("0000-00-00 00:00:00.000"),
+		sqlErr: sqlerror.ERTruncatedWrongValue,
+	}, {
+		name:   "zero number",
+		mode:   SQLModeNoZeroDate,
+		typ:    sqltypes.Timestamp,
+		value:  sqltypes.Int64BindVariable(0),
+		sqlErr: sqlerror.ERTruncatedWrongValue,
+	}, {
+		name:  "date",
+		mode:  SQLModeNoZeroDate,
+		typ:   sqltypes.Date,
+		value: sqltypes.StringBindVariable("2024-01-01"),
+	}, {
+		name:   "varchar too long",
+		mode:   SQLModeStrictTransTables,
+		typ:    sqltypes.VarChar,
+		size:   3,
+		value:  sqltypes.StringBindVariable("abcd"),
+		sqlErr: sqlerror.ERDataTooLong,
+	}, {
+		name:  "varchar with trailing spaces",
+		mode:  SQLModeStrictTransTables,
+		typ:   sqltypes.VarChar,
+		size:  3,
+		value: sqltypes.StringBindVariable("abc  "),
+	}, {
+		name:  "varchar in runes",
+		mode:  SQLModeStrictTransTables,
+		typ:   sqltypes.VarChar,
+		size:  3,
+		value: sqltypes.StringBindVariable("äöü"),
+	}, {
+		name:   "varbinary in bytes",
+		mode:   SQLModeStrictTransTables,
+		typ:    sqltypes.VarBinary,
+		size:   3,
+		value:  sqltypes.StringBindVariable("äöü"),
+		sqlErr: sqlerror.ERDataTooLong,
+	}, {
+		name:   "tinyint out of range",
+		mode:   SQLModeStrictTransTables,
+		typ:    sqltypes.Int8,
+		value:  sqltypes.Int64BindVariable(128),
+		sqlErr: sqlerror.ERWarnDataOutOfRange,
+	}, {
+		name:  "tinyint",
+		mode:  SQLModeStrictTransTables,
+		typ:   sqltypes.Int8,
+		value: sqltypes.Int64BindVariable(-128),
+	}, {
+		name:   "unsigned negative",
+		mode:   SQLModeStrictTransTables,
+		typ:    sqltypes.Uint32,
+		value:  sqltypes.Int64BindVariable(-1),
+		sqlErr: sqlerror.ERWarnDataOutOfRange,
+	}, {
+		name:   "bigint out of range",
+		mode:   SQLModeStrictTransTables,
+		typ:    sqltypes.Int64,
+		value:  sqltypes.Uint64BindVariable(1 << 63),
+		sqlErr: sqlerror.ERWarnDataOutOfRange,
+	}, {
+		name:  "not an integer",
+		mode:  SQLModeStrictTransTables,
+		typ:   sqltypes.Int8,
+		value: sqltypes.StringBindVariable("1000"),
+	}, {
+		name:  "null",
+		mode:  SQLModeStrictTransTables,
+		typ:   sqltypes.Int8,
+		value: sqltypes.NullBindVariable,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &SQLModeCheck{
+				Input: &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}},
+				Checks: []*ValueCheck{{
+					SQLMode: tt.mode,
+					Column:  "c",
+					Row:     2,
+					Type:    tt.typ,
+					Size:    tt.size,
+					Expr:    evalengine.NewBindVar("v", evalengine.Type{}),
+				}},
+			}
+			bindVars := map[string]*querypb.BindVariable{"v": tt.value}
+
+			_, err := check.TryExecute(context.Background(), &noopVCursor{}, bindVars, false)
+			if tt.sqlErr == 0 {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, "for column 'c' at row 2")
+				require.Equal(t, tt.sqlErr, sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError).Number())
+			}
+
+			// Without a strict mode, the values are not checked.
+			check.Input = &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+			_, err = check.TryExecute(context.Background(), &sqlModeVCursor{sqlMode: "NO_ZERO_DATE"}, bindVars, false)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSQLModeCheckDisabledMode(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+	check := &SQLModeCheck{
+		Input: input,
+		Checks: []*ValueCheck{{
+			SQLMode: SQLModeNoZeroDate,
+			Column:  "c",
+			Row:     1,
+			Type:    sqltypes.Date,
+			Expr:    evalengine.NewBindVar("v", evalengine.Type{}),
+		}},
+	}
+	bindVars := map[string]*querypb.BindVariable{"v": sqltypes.StringBindVariable("0000-00-00")}
+
+	// The session does not have NO_ZERO_DATE.
+	result, err := check.TryExecute(context.Background(), &sqlModeVCursor{sqlMode: "STRICT_TRANS_TABLES"}, bindVars, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.RowsAffected)
+
+	input.rewind()
+	err = check.TryStreamExecute(context.Background(), &noopVCursor{}, bindVars, false, func(*sqltypes.Result) error { return nil })
+	require.ErrorContains(t, err, "Incorrect date value: '0000-00-00' for column 'c' at row 1")
+}
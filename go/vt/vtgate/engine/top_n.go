@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var _ Primitive = (*TopN)(nil)
+
+// TopN is a primitive that performs an ORDER BY followed by a LIMIT in memory.
+// Instead of sorting all the rows of its input, it only keeps the count+offset
+// first rows in a heap, so its memory is bounded by the limit.
+type TopN struct {
+	Count   evalengine.Expr
+	Offset  evalengine.Expr
+	OrderBy evalengine.Comparison
+	Input   Primitive
+
+	// TruncateColumnCount specifies the number of columns to return
+	// in the final result. Rest of the columns are truncated
+	// from the result received. If 0, no truncation happens.
+	TruncateColumnCount int `json:",omitempty"`
+}
+
+// RouteType returns a description of the query routing type used by the primitive.
+func (t *TopN) RouteType() string {
+	return t.Input.RouteType()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (t *TopN) GetKeyspaceName() string {
+	return t.Input.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (t *TopN) GetTableName() string {
+	return t.Input.GetTableName()
+}
+
+// SetTruncateColumnCount sets the truncate column count.
+func (t *TopN) SetTruncateColumnCount(count int) {
+	t.TruncateColumnCount = count
+}
+
+// TryExecute satisfies the Primitive interface.
+func (t *TopN) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (_ *sqltypes.Result, err error) {
+	defer evalengine.PanicHandler(&err)
+
+	count, offset, err := t.getCountAndOffset(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	bindVars["__upper_limit"] = sqltypes.Int64BindVariable(int64(count + offset))
+
+	result, err := vcursor.ExecutePrimitive(ctx, t.Input, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+
+	sorter := &evalengine.Sorter{
+		Compare: t.OrderBy,
+		Limit:   count + offset,
+	}
+	if sorter.Limit > 0 {
+		for _, row := range result.Rows {
+			sorter.Push(row)
+		}
+	}
+	result.Rows = applyOffset(sorter.Sorted(), offset)
+	return result.Truncate(t.TruncateColumnCount), nil
+}
+
+// TryStreamExecute satisfies the Primitive interface.
+func (t *TopN) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) (err error) {
+	defer evalengine.PanicHandler(&err)
+
+	count, offset, err := t.getCountAndOffset(ctx, vcursor, bindVars)
+	if err != nil {
+		return err
+	}
+	bindVars = copyBindVars(bindVars)
+	bindVars["__upper_limit"] = sqltypes.Int64BindVariable(int64(count + offset))
+
+	cb := func(qr *sqltypes.Result) error {
+		return callback(qr.Truncate(t.TruncateColumnCount))
+	}
+
+	sorter := &evalengine.Sorter{
+		Compare: t.OrderBy,
+		Limit:   count + offset,
+	}
+
+	var mu sync.Mutex
+	err = vcursor.StreamExecutePrimitive(ctx, t.Input, bindVars, wantfields, func(qr *sqltypes.Result) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(qr.Fields) != 0 {
+			if err := cb(&sqltypes.Result{Fields: qr.Fields}); err != nil {
+				return err
+			}
+		}
+		if sorter.Limit == 0 {
+			return nil
+		}
+		for _, row := range qr.Rows {
+			sorter.Push(row)
+		}
+		if vcursor.ExceedsMaxMemoryRows(sorter.Len()) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return cb(&sqltypes.Result{Rows: applyOffset(sorter.Sorted(), offset)})
+}
+
+// GetFields satisfies the Primitive interface.
+func (t *TopN) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return t.Input.GetFields(ctx, vcursor, bindVars)
+}
+
+// Inputs returns the input to the top-N
+func (t *TopN) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{t.Input}, nil
+}
+
+// NeedsTransaction implements the Primitive interface
+func (t *TopN) NeedsTransaction() bool {
+	return t.Input.NeedsTransaction()
+}
+
+func (t *TopN) getCountAndOffset(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (count int, offset int, err error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	count, err = getIntFrom(env, vcursor, t.Count)
+	if err != nil {
+		return
+	}
+	offset, err = getIntFrom(env, vcursor, t.Offset)
+	return
+}
+
+func applyOffset(rows []sqltypes.Row, offset int) []sqltypes.Row {
+	if offset >= len(rows) {
+		return nil
+	}
+	return rows[offset:]
+}
+
+func (t *TopN) description() PrimitiveDescription {
+	other := map[string]any{
+		"OrderBy": GenericJoin(t.OrderBy, orderByParamsToString),
+	}
+	if t.Count != nil {
+		other["Count"] = sqlparser.String(t.Count)
+	}
+	if t.Offset != nil {
+		other["Offset"] = sqlparser.String(t.Offset)
+	}
+	if t.TruncateColumnCount > 0 {
+		other["ResultColumns"] = t.TruncateColumnCount
+	}
+	return PrimitiveDescription{
+		OperatorType: "TopN",
+		Other:        other,
+	}
+}
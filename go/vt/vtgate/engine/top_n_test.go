@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func TestTopNExecute(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varbinary|decimal",
+	)
+	input := func() Primitive {
+		return &fakePrimitive{
+			results: []*sqltypes.Result{sqltypes.MakeTestResult(
+				fields,
+				"a|5",
+				"g|2",
+				"a|1",
+				"c|4",
+				"c|3",
+			)},
+		}
+	}
+
+	topN := &TopN{
+		Count: evalengine.NewLiteralInt(3),
+		OrderBy: []evalengine.OrderByParams{{
+			WeightStringCol: -1,
+			Col:             1,
+		}},
+		Input:               input(),
+		TruncateColumnCount: 1,
+	}
+
+	bv := map[string]*querypb.BindVariable{}
+	result, err := topN.TryExecute(context.Background(), &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(fields[:1], "a", "g", "c"), result)
+	// The routes of the input only need to return the first rows.
+	utils.MustMatch(t, sqltypes.Int64BindVariable(3), bv["__upper_limit"])
+
+	topN.Input = input()
+	topN.Offset = evalengine.NewLiteralInt(2)
+	result, err = wrapStreamExecute(topN, &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(fields[:1], "c", "c", "a"), result)
+
+	topN.Input = input()
+	topN.Offset = evalengine.NewLiteralInt(4)
+	result, err = topN.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(fields[:1], "a"), result)
+
+	topN.Input = input()
+	topN.Count = evalengine.NewLiteralInt(0)
+	topN.Offset = nil
+	result, err = wrapStreamExecute(topN, &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, sqltypes.MakeTestResult(fields[:1]), result)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var _ Primitive = (*TrackedColumns)(nil)
+
+// TrackedColumns answers SHOW COLUMNS and DESCRIBE from the schema of the
+// table tracked by vtgate, instead of sending them to a tablet. Since the
+// tracked schema can lag behind the one of the tablets, every result comes
+// with a warning that tells when the table was tracked.
+type TrackedColumns struct {
+	noInputs
+	noTxNeeded
+
+	Keyspace  *vindexes.Keyspace
+	Table     string
+	TrackedAt time.Time
+
+	Fields []*querypb.Field
+	Rows   [][]sqltypes.Value
+}
+
+// RouteType implements the Primitive interface
+func (tc *TrackedColumns) RouteType() string {
+	return "TrackedColumns"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (tc *TrackedColumns) GetKeyspaceName() string {
+	return tc.Keyspace.Name
+}
+
+// GetTableName implements the Primitive interface
+func (tc *TrackedColumns) GetTableName() string {
+	return tc.Table
+}
+
+// TryExecute implements the Primitive interface
+func (tc *TrackedColumns) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	vcursor.Session().RecordWarning(&querypb.QueryWarning{
+		Message: fmt.Sprintf("columns of %s.%s served from the schema tracked by vtgate at %s, %v ago",
+			tc.Keyspace.Name, tc.Table, tc.TrackedAt.UTC().Format(time.RFC3339), time.Since(tc.TrackedAt).Truncate(time.Second)),
+	})
+	return &sqltypes.Result{
+		Fields: tc.Fields,
+		Rows:   tc.Rows,
+	}, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (tc *TrackedColumns) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	result, err := tc.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(result)
+}
+
+// GetFields implements the Primitive interface
+func (tc *TrackedColumns) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return &sqltypes.Result{Fields: tc.Fields}, nil
+}
+
+func (tc *TrackedColumns) description() PrimitiveDescription {
+	return PrimitiveDescription{
+		OperatorType: "TrackedColumns",
+		Keyspace:     tc.Keyspace,
+		Other: map[string]any{
+			"Table":     tc.Table,
+			"TrackedAt": tc.TrackedAt.UTC().Format(time.RFC3339),
+			"RowCount":  len(tc.Rows),
+		},
+	}
+}
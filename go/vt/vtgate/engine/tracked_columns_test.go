@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func TestTrackedColumns(t *testing.T) {
+	fields := sqltypes.MakeTestFields("Field|Type", "varchar|varchar")
+	tc := &TrackedColumns{
+		Keyspace:  &vindexes.Keyspace{Name: "ks"},
+		Table:     "t1",
+		TrackedAt: time.Now().Add(-90 * time.Second),
+		Fields:    fields,
+		Rows:      sqltypes.MakeTestResult(fields, "id|bigint", "name|varchar(64)").Rows,
+	}
+
+	vc := &loggingVCursor{}
+	result, err := tc.TryExecute(context.Background(), vc, nil, true)
+	require.NoError(t, err)
+	expectResult(t, result, sqltypes.MakeTestResult(fields, "id|bigint", "name|varchar(64)"))
+	require.Len(t, vc.warnings, 1)
+	assert.Contains(t, vc.warnings[0].Message, "columns of ks.t1 served from the schema tracked by vtgate at "+tc.TrackedAt.UTC().Format(time.RFC3339))
+	assert.Contains(t, vc.warnings[0].Message, " ago")
+
+	vc = &loggingVCursor{}
+	result, err = wrapStreamExecute(tc, vc, nil, true)
+	require.NoError(t, err)
+	expectResult(t, result, sqltypes.MakeTestResult(fields, "id|bigint", "name|varchar(64)"))
+	require.Len(t, vc.warnings, 1)
+
+	result, err = tc.GetFields(context.Background(), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, fields, result.Fields)
+	assert.Empty(t, result.Rows)
+}
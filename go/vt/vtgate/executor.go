@@ -23,6 +23,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -76,6 +79,9 @@ var (
 	queriesProcessedByTable = stats.NewCountersWithMultiLabels("QueriesProcessedByTable", "Queries processed at vtgate by plan type, keyspace and table", []string{"Plan", "Keyspace", "Table"})
 	queriesRoutedByTable    = stats.NewCountersWithMultiLabels("QueriesRoutedByTable", "Queries routed from vtgate to vttablet by plan type, keyspace and table", []string{"Plan", "Keyspace", "Table"})
 
+	// queriesProcessedByOrigin tells the queries of the users apart from the ones that Vitess generates for itself.
+	queriesProcessedByOrigin = stats.NewCountersWithMultiLabels("QueriesProcessedByOrigin", "Queries processed at vtgate by origin, user or the component of Vitess that generated them, and plan type", []string{"Origin", "Plan"})
+
 	exceedMemoryRowsLogger = logutil.NewThrottledLogger("ExceedMemoryRows", 1*time.Minute)
 )
 
@@ -105,10 +111,11 @@ type Executor struct {
 	txConn      *TxConn
 	pv          plancontext.PlannerVersion
 
-	mu           sync.Mutex
-	vschema      *vindexes.VSchema
-	streamSize   int
-	vschemaStats *VSchemaStats
+	mu            sync.Mutex
+	vschema       *vindexes.VSchema
+	streamSize    int
+	maxStreamSize int
+	vschemaStats  *VSchemaStats
 
 	plans *PlanCache
 	epoch atomic.Uint32
@@ -127,6 +134,24 @@ type Executor struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// inListChunkSize is the IN list size above which queries get a plan variant
+	// that batches the list values per shard. 0 disables plan specialization.
+	inListChunkSize int
+
+	// refetchTimeout is the timeout to query again the shards of scatter aggregations
+	// that fail with a transient error. 0 disables it.
+	refetchTimeout time.Duration
+
+	// collapser shares the result of identical concurrent reads, nil if disabled.
+	collapser *queryCollapser
+	// concurrencyBudget limits the queries run concurrently against keyspaces, nil if disabled.
+	concurrencyBudget *concurrencyBudget
+	// planDrift detects the plans whose executions drift from their first ones, nil if disabled.
+	planDrift *planDriftDetector
+
+	// advisoryLocks holds the advisory locks outside of MySQL, nil if they are held by a designated shard.
+	advisoryLocks engine.AdvisoryLockService
 }
 
 var executorOnce sync.Once
@@ -134,6 +159,7 @@ var executorOnce sync.Once
 const pathQueryPlans = "/debug/query_plans"
 const pathScatterStats = "/debug/scatter_stats"
 const pathVSchema = "/debug/vschema"
+const pathPlanDrift = "/debug/plan_drift"
 
 type PlanCacheKey = theine.HashKey256
 type PlanCache = theine.Store[PlanCacheKey, *engine.Plan]
@@ -169,12 +195,18 @@ func NewExecutor(
 		normalize:           normalize,
 		warnShardedOnly:     warnOnShardedOnly,
 		streamSize:          streamSize,
+		maxStreamSize:       maxStreamBufferSize,
 		schemaTracker:       schemaTracker,
 		allowScatter:        !noScatter,
 		pv:                  pv,
 		plans:               plans,
 		warmingReadsPercent: warmingReadsPercent,
 		warmingReadsChannel: make(chan bool, warmingReadsConcurrency),
+		inListChunkSize:     inListChunkSize,
+		refetchTimeout:      scatterAggregationRefetchTimeout,
+		collapser:           newQueryCollapser(queryCollapsingKeyspaces),
+		planDrift:           newPlanDriftDetector(planDriftMode, planDriftWindow, planDriftFactor),
+		advisoryLocks:       newAdvisoryLockService(advisoryLockService, serv),
 	}
 
 	vschemaacl.Init()
@@ -210,6 +242,7 @@ func NewExecutor(
 		servenv.HTTPHandle(pathQueryPlans, e)
 		servenv.HTTPHandle(pathScatterStats, e)
 		servenv.HTTPHandle(pathVSchema, e)
+		servenv.HTTPHandle(pathPlanDrift, e)
 	})
 	return e
 }
@@ -293,6 +326,9 @@ func (e *Executor) StreamExecute(
 		var seenResults atomic.Bool
 		var resultMu sync.Mutex
 		result := &sqltypes.Result{}
+		var keysetRows int
+		var keysetLastRow sqltypes.Row
+		chunkRows, chunkBytes := safeSession.StreamChunkSize(e.streamSize, e.maxStreamSize)
 		if canReturnRows(plan.Type) {
 			srr.callback = func(qr *sqltypes.Result) error {
 				resultMu.Lock()
@@ -308,6 +344,10 @@ func (e *Executor) StreamExecute(
 					seenResults.Store(true)
 				}
 
+				if len(qr.Rows) > 0 {
+					keysetRows += len(qr.Rows)
+					keysetLastRow = qr.Rows[len(qr.Rows)-1]
+				}
 				for _, row := range qr.Rows {
 					result.Rows = append(result.Rows, row)
 
@@ -315,7 +355,7 @@ func (e *Executor) StreamExecute(
 						byteCount += col.Len()
 					}
 
-					if byteCount >= e.streamSize {
+					if byteCount >= chunkBytes || (chunkRows > 0 && len(result.Rows) >= chunkRows) {
 						err := callback(result)
 						seenResults.Store(true)
 						result = &sqltypes.Result{}
@@ -329,10 +369,17 @@ func (e *Executor) StreamExecute(
 			}
 		}
 
+		release, err := e.concurrencyBudget.acquire(plan)
+		if err != nil {
+			return err
+		}
+		defer release()
+
 		// 4: Execute!
-		err := vc.StreamExecutePrimitive(ctx, plan.Instructions, bindVars, true, func(qr *sqltypes.Result) error {
+		err = vc.StreamExecutePrimitive(ctx, plan.Instructions, bindVars, true, func(qr *sqltypes.Result) error {
 			return srr.storeResultStats(plan.Type, qr)
 		})
+		recordTableStats(ctx, plan, vc.TabletType().String(), time.Since(logStats.StartTime), err)
 
 		// Check if there was partial DML execution. If so, rollback the effect of the partially executed query.
 		if err != nil {
@@ -346,6 +393,14 @@ func (e *Executor) StreamExecute(
 			return nil
 		}
 
+		if vc.keysetPagination != nil {
+			token, err := vc.keysetPagination.continuationToken(keysetRows, keysetLastRow)
+			if err != nil {
+				return err
+			}
+			safeSession.SetKeysetContinuationToken(token)
+		}
+
 		// Send left-over rows if there is no error on execution.
 		if len(result.Rows) > 0 || !seenResults.Load() {
 			if err := callback(result); err != nil {
@@ -359,7 +414,7 @@ func (e *Executor) StreamExecute(
 		logStats.ExecuteTime = time.Since(execStart)
 		logStats.ActiveKeyspace = vc.keyspace
 
-		e.updateQueryCounts(plan.Instructions.RouteType(), plan.Instructions.GetKeyspaceName(), plan.Instructions.GetTableName(), int64(logStats.ShardQueries))
+		e.updateQueryCounts(ctx, plan.Instructions.RouteType(), plan.Instructions.GetKeyspaceName(), plan.Instructions.GetTableName(), int64(logStats.ShardQueries))
 
 		return err
 	}
@@ -479,6 +534,12 @@ func (e *Executor) addNeededBindVars(vcursor *vcursorImpl, bindVarNeeds *sqlpars
 				txMode = getTxMode()
 			}
 			bindVars[key] = sqltypes.StringBindVariable(txMode.String())
+		case sysvars.TransactionTag.Name:
+			var v string
+			ifOptionsExist(session, func(options *querypb.ExecuteOptions) {
+				v = options.TransactionTag
+			})
+			bindVars[key] = sqltypes.StringBindVariable(v)
 		case sysvars.Workload.Name:
 			var v string
 			ifOptionsExist(session, func(options *querypb.ExecuteOptions) {
@@ -491,6 +552,8 @@ func (e *Executor) addNeededBindVars(vcursor *vcursorImpl, bindVarNeeds *sqlpars
 			bindVars[key] = sqltypes.StringBindVariable(session.MigrationContext)
 		case sysvars.SessionUUID.Name:
 			bindVars[key] = sqltypes.StringBindVariable(session.SessionUUID)
+		case sysvars.KeysetContinuationToken.Name:
+			bindVars[key] = sqltypes.StringBindVariable(session.GetKeysetContinuationToken())
 		case sysvars.SessionEnableSystemSettings.Name:
 			bindVars[key] = sqltypes.BoolBindVariable(session.EnableSystemSettings)
 		case sysvars.ReadAfterWriteGTID.Name:
@@ -513,6 +576,10 @@ func (e *Executor) addNeededBindVars(vcursor *vcursorImpl, bindVarNeeds *sqlpars
 				}
 			})
 			bindVars[key] = sqltypes.StringBindVariable(v)
+		case sysvars.SessionTrackStateChange.Name:
+			bindVars[key] = sqltypes.BoolBindVariable(session.GetSessionTrack().GetStateChange())
+		case sysvars.SessionTrackSystemVariables.Name:
+			bindVars[key] = sqltypes.StringBindVariable(sessionTrackSystemVariables(session.Session))
 		case sysvars.Version.Name:
 			bindVars[key] = sqltypes.StringBindVariable(servenv.AppVersion.MySQLVersion())
 		case sysvars.VersionComment.Name:
@@ -580,7 +647,7 @@ func (e *Executor) handleBegin(ctx context.Context, safeSession *SafeSession, lo
 	err := e.txConn.Begin(ctx, safeSession, begin.TxAccessModes)
 	logStats.ExecuteTime = time.Since(execStart)
 
-	e.updateQueryCounts("Begin", "", "", 0)
+	e.updateQueryCounts(ctx, "Begin", "", "", 0)
 
 	return &sqltypes.Result{}, err
 }
@@ -589,7 +656,7 @@ func (e *Executor) handleCommit(ctx context.Context, safeSession *SafeSession, l
 	execStart := time.Now()
 	logStats.PlanTime = execStart.Sub(logStats.StartTime)
 	logStats.ShardQueries = uint64(len(safeSession.ShardSessions))
-	e.updateQueryCounts("Commit", "", "", int64(logStats.ShardQueries))
+	e.updateQueryCounts(ctx, "Commit", "", "", int64(logStats.ShardQueries))
 
 	err := e.txConn.Commit(ctx, safeSession)
 	logStats.CommitTime = time.Since(execStart)
@@ -605,7 +672,7 @@ func (e *Executor) handleRollback(ctx context.Context, safeSession *SafeSession,
 	execStart := time.Now()
 	logStats.PlanTime = execStart.Sub(logStats.StartTime)
 	logStats.ShardQueries = uint64(len(safeSession.ShardSessions))
-	e.updateQueryCounts("Rollback", "", "", int64(logStats.ShardQueries))
+	e.updateQueryCounts(ctx, "Rollback", "", "", int64(logStats.ShardQueries))
 	err := e.txConn.Rollback(ctx, safeSession)
 	logStats.CommitTime = time.Since(execStart)
 	return &sqltypes.Result{}, err
@@ -615,7 +682,7 @@ func (e *Executor) handleSavepoint(ctx context.Context, safeSession *SafeSession
 	execStart := time.Now()
 	logStats.PlanTime = execStart.Sub(logStats.StartTime)
 	logStats.ShardQueries = uint64(len(safeSession.ShardSessions))
-	e.updateQueryCounts(planType, "", "", int64(logStats.ShardQueries))
+	e.updateQueryCounts(ctx, planType, "", "", int64(logStats.ShardQueries))
 	defer func() {
 		logStats.ExecuteTime = time.Since(execStart)
 	}()
@@ -676,7 +743,7 @@ func (e *Executor) executeSPInAllSessions(ctx context.Context, safeSession *Safe
 func (e *Executor) handleKill(ctx context.Context, mysqlCtx vtgateservice.MySQLConnection, stmt sqlparser.Statement, logStats *logstats.LogStats) (result *sqltypes.Result, err error) {
 	execStart := time.Now()
 	logStats.PlanTime = execStart.Sub(logStats.StartTime)
-	e.updateQueryCounts("Kill", "", "", 0)
+	e.updateQueryCounts(ctx, "Kill", "", "", 0)
 	defer func() {
 		logStats.ExecuteTime = time.Since(execStart)
 	}()
@@ -705,7 +772,17 @@ func (e *Executor) handleKill(ctx context.Context, mysqlCtx vtgateservice.MySQLC
 // CloseSession releases the current connection, which rollbacks open transactions and closes reserved connections.
 // It is called then the MySQL servers closes the connection to its client.
 func (e *Executor) CloseSession(ctx context.Context, safeSession *SafeSession) error {
-	return e.txConn.ReleaseAll(ctx, safeSession)
+	tempTables := safeSession.GetTempTables()
+	err := e.txConn.ReleaseAll(ctx, safeSession)
+	if err != nil && len(tempTables) > 0 {
+		log.Warningf("Failed to release the reserved connections of the temporary tables %v, they are dropped when the connections time out on the tablets: %v", tempTables, err)
+	}
+	if e.advisoryLocks != nil && safeSession.GetSessionUUID() != "" {
+		if _, lerr := e.advisoryLocks.ReleaseAllLocks(ctx, safeSession.GetSessionUUID()); lerr != nil {
+			log.Warningf("Failed to release the advisory locks of session %v: %v", safeSession.GetSessionUUID(), lerr)
+		}
+	}
+	return err
 }
 
 func (e *Executor) setVitessMetadata(ctx context.Context, name, value string) error {
@@ -758,6 +835,70 @@ func (e *Executor) showVitessMetadata(ctx context.Context, filter *sqlparser.Sho
 	}, nil
 }
 
+// flushPlans removes the cached plans for which match returns true, and returns how many were removed.
+func (e *Executor) flushPlans(ctx context.Context, match func(plan *engine.Plan) bool) (int, error) {
+	user := callerid.ImmediateCallerIDFromContext(ctx)
+	if !vschemaacl.Authorized(user) {
+		return 0, vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "User '%s' not authorized to flush vitess plans", user.GetUsername())
+	}
+
+	// The cache cannot be modified while ranging over it, so collect the keys first.
+	var keys []PlanCacheKey
+	e.plans.Range(e.epoch.Load(), func(key PlanCacheKey, plan *engine.Plan) bool {
+		if match(plan) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	for _, key := range keys {
+		e.plans.Delete(key)
+	}
+	return len(keys), nil
+}
+
+// showVitessPlans lists the cached plans. The queries can hold user data, so
+// only the users allowed to flush the plans can list them.
+func (e *Executor) showVitessPlans(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error) {
+	user := callerid.ImmediateCallerIDFromContext(ctx)
+	if !vschemaacl.Authorized(user) {
+		return nil, vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "User '%s' not authorized to show vitess plans", user.GetUsername())
+	}
+
+	var queryRegexp *regexp.Regexp
+	if filter != nil && filter.Like != "" {
+		queryRegexp = sqlparser.LikeToRegexp(filter.Like)
+	}
+
+	var rows [][]sqltypes.Value
+	e.ForEachPlan(func(plan *engine.Plan) bool {
+		if queryRegexp != nil && !queryRegexp.MatchString(plan.Original) {
+			return true
+		}
+		execCount, execTime, shardQueries, rowsAffected, rowsReturned, errors := plan.Stats()
+		rows = append(rows, buildVarCharRow(
+			plan.Original,
+			plan.Type.String(),
+			strings.Join(plan.TablesUsed, ","),
+			strconv.FormatUint(execCount, 10),
+			execTime.String(),
+			strconv.FormatUint(shardQueries, 10),
+			strconv.FormatUint(rowsReturned, 10),
+			strconv.FormatUint(rowsAffected, 10),
+			strconv.FormatUint(errors, 10),
+		))
+		return true
+	})
+	// The cache has no stable order, sort by query to keep the output deterministic.
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0].ToString() < rows[j][0].ToString()
+	})
+
+	return &sqltypes.Result{
+		Fields: buildVarCharFields("Query", "Type", "Tables", "ExecCount", "ExecTime", "ShardQueries", "RowsReturned", "RowsAffected", "Errors"),
+		Rows:   rows,
+	}, nil
+}
+
 type tabletFilter func(tablet *topodatapb.Tablet, servingState string, primaryTermStartTime int64) bool
 
 func (e *Executor) showShards(ctx context.Context, filter *sqlparser.ShowFilter, destTabletType topodatapb.TabletType) (*sqltypes.Result, error) {
@@ -844,6 +985,43 @@ func (e *Executor) showShards(ctx context.Context, filter *sqlparser.ShowFilter,
 	}, nil
 }
 
+// showVitessTransactionStatus returns the state of the transaction and of the
+// connections held by the session, to debug the sessions which are stuck.
+func (e *Executor) showVitessTransactionStatus(session *SafeSession) *sqltypes.Result {
+	inTransaction := "0"
+	if session.InTransaction() {
+		inTransaction = "1"
+	}
+	mode := session.GetTransactionMode()
+	if mode == vtgatepb.TransactionMode_UNSPECIFIED {
+		mode = e.txConn.mode
+	}
+
+	var shards []string
+	reserved := 0
+	for _, shardSession := range session.allShardSessions() {
+		target := shardSession.GetTarget()
+		shards = append(shards, fmt.Sprintf("%s@%s %s",
+			topoproto.KeyspaceShardString(target.GetKeyspace(), target.GetShard()),
+			topoproto.TabletTypeLString(target.GetTabletType()),
+			topoproto.TabletAliasString(shardSession.TabletAlias)))
+		if shardSession.ReservedId != 0 {
+			reserved++
+		}
+	}
+
+	return &sqltypes.Result{
+		Fields: buildVarCharFields("In_transaction", "Transaction_mode", "Shards", "Reserved_connections", "Savepoints"),
+		Rows: [][]sqltypes.Value{buildVarCharRow(
+			inTransaction,
+			mode.String(),
+			strings.Join(shards, ", "),
+			strconv.Itoa(reserved),
+			strings.Join(session.SavePoints(), "; "),
+		)},
+	}
+}
+
 func (e *Executor) showTablets(filter *sqlparser.ShowFilter) (*sqltypes.Result, error) {
 	getTabletFilters := func(filter *sqlparser.ShowFilter) []tabletFilter {
 		var filters []tabletFilter
@@ -1091,6 +1269,20 @@ func (e *Executor) getPlan(
 		return nil, err
 	}
 	vcursor.SetPriority(priority)
+	transactionTag, err := sqlparser.GetTransactionTagFromStatement(stmt)
+	if err != nil {
+		return nil, err
+	}
+	if transactionTag != "" {
+		vcursor.SetTransactionTag(transactionTag)
+	}
+	if err := vcursor.setTabletTypeOverride(stmt); err != nil {
+		return nil, err
+	}
+	vcursor.keysetPagination, err = prepareKeysetPagination(stmt, reservedVars, bindVars)
+	if err != nil {
+		return nil, err
+	}
 
 	setVarComment, err := prepareSetVarComment(vcursor, stmt)
 	if err != nil {
@@ -1125,12 +1317,83 @@ func (e *Executor) getPlan(
 	logStats.SQL = comments.Leading + query + comments.Trailing
 	logStats.BindVariables = sqltypes.CopyBindVariables(bindVars)
 
-	return e.cacheAndBuildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds, logStats)
+	return e.cacheAndBuildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds, e.inListBucket(bindVars), logStats)
+}
+
+// In list buckets used to specialize plans by the size of the IN lists in the query.
+const (
+	inListNone   = ""
+	inListSingle = "single"
+	inListFew    = "few"
+	inListMany   = "many"
+)
+
+// inListBucket classifies the largest list bind variable of the query.
+// Queries in the inListMany bucket get a plan that batches the list values per shard.
+func (e *Executor) inListBucket(bindVars map[string]*querypb.BindVariable) string {
+	if e.inListChunkSize <= 0 {
+		return inListNone
+	}
+	largest := 0
+	for _, bv := range bindVars {
+		if bv.Type == querypb.Type_TUPLE {
+			largest = max(largest, len(bv.Values))
+		}
+	}
+	switch {
+	case largest == 0:
+		return inListNone
+	case largest == 1:
+		return inListSingle
+	case largest <= e.inListChunkSize:
+		return inListFew
+	default:
+		return inListMany
+	}
+}
+
+// specializeForInList marks every IN route of the plan to send its values in batches of chunkSize.
+func specializeForInList(primitive engine.Primitive, chunkSize int) {
+	if route, ok := primitive.(*engine.Route); ok && route.Opcode == engine.IN {
+		route.InListChunkSize = chunkSize
+	}
+	inputs, _ := primitive.Inputs()
+	for _, input := range inputs {
+		specializeForInList(input, chunkSize)
+	}
+}
+
+// isPlainRead returns true if the statement is a read that takes no locks,
+// which can be sent again to a shard without any side effect.
+func isPlainRead(stmt sqlparser.Statement) bool {
+	sel, ok := stmt.(sqlparser.SelectStatement)
+	return ok && sel.GetLock() == sqlparser.NoLock
+}
+
+// specializeForRefetch marks the multi-shard routes that feed an aggregation to query again,
+// within the timeout, the shards that fail with a transient error.
+func specializeForRefetch(primitive engine.Primitive, timeout time.Duration, underAggregation bool) {
+	switch primitive := primitive.(type) {
+	case *engine.Route:
+		if underAggregation && !primitive.Opcode.IsSingleShard() {
+			primitive.ShardRefetchTimeout = timeout
+		}
+	case *engine.ScalarAggregate, *engine.OrderedAggregate:
+		underAggregation = true
+	}
+	inputs, _ := primitive.Inputs()
+	for _, input := range inputs {
+		specializeForRefetch(input, timeout, underAggregation)
+	}
 }
 
-func (e *Executor) hashPlan(ctx context.Context, vcursor *vcursorImpl, query string) PlanCacheKey {
+func (e *Executor) hashPlan(ctx context.Context, vcursor *vcursorImpl, query string, inList string) PlanCacheKey {
 	hasher := vthash.New256()
 	vcursor.keyForPlan(ctx, query, hasher)
+	if inList != inListNone {
+		_, _ = hasher.WriteString("+InList:")
+		_, _ = hasher.WriteString(inList)
+	}
 
 	var planKey PlanCacheKey
 	hasher.Sum(planKey[:0])
@@ -1144,16 +1407,24 @@ func (e *Executor) buildStatement(
 	stmt sqlparser.Statement,
 	reservedVars *sqlparser.ReservedVars,
 	bindVarNeeds *sqlparser.BindVarNeeds,
+	inList string,
 ) (*engine.Plan, error) {
 	plan, err := planbuilder.BuildFromStmt(ctx, query, stmt, reservedVars, vcursor, bindVarNeeds, enableOnlineDDL, enableDirectDDL)
 	if err != nil {
 		return nil, err
 	}
 
+	if inList == inListMany {
+		specializeForInList(plan.Instructions, e.inListChunkSize)
+	}
+	if e.refetchTimeout > 0 && isPlainRead(stmt) {
+		specializeForRefetch(plan.Instructions, e.refetchTimeout, false)
+	}
+
 	plan.Warnings = vcursor.warnings
 	vcursor.warnings = nil
 
-	err = e.checkThatPlanIsValid(stmt, plan)
+	err = e.checkThatPlanIsValid(stmt, plan, vcursor.vschema)
 	return plan, err
 }
 
@@ -1164,20 +1435,21 @@ func (e *Executor) cacheAndBuildStatement(
 	stmt sqlparser.Statement,
 	reservedVars *sqlparser.ReservedVars,
 	bindVarNeeds *sqlparser.BindVarNeeds,
+	inList string,
 	logStats *logstats.LogStats,
 ) (*engine.Plan, error) {
 	planCachable := sqlparser.CachePlan(stmt) && vcursor.safeSession.cachePlan()
 	if planCachable {
-		planKey := e.hashPlan(ctx, vcursor, query)
+		planKey := e.hashPlan(ctx, vcursor, query, inList)
 
 		var plan *engine.Plan
 		var err error
 		plan, logStats.CachedPlan, err = e.plans.GetOrLoad(planKey, e.epoch.Load(), func() (*engine.Plan, error) {
-			return e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds)
+			return e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds, inList)
 		})
 		return plan, err
 	}
-	return e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds)
+	return e.buildStatement(ctx, vcursor, query, stmt, reservedVars, bindVarNeeds, inList)
 }
 
 func (e *Executor) canNormalizeStatement(stmt sqlparser.Statement, setVarComment string) bool {
@@ -1235,6 +1507,8 @@ func (e *Executor) ServeHTTP(response http.ResponseWriter, request *http.Request
 		returnAsJSON(response, e.VSchema())
 	case pathScatterStats:
 		e.WriteScatterStats(response)
+	case pathPlanDrift:
+		returnAsJSON(response, e.planDrift.Reports())
 	default:
 		response.WriteHeader(http.StatusNotFound)
 	}
@@ -1267,10 +1541,17 @@ func (e *Executor) ClearPlans() {
 	e.epoch.Add(1)
 }
 
-func (e *Executor) updateQueryCounts(planType, keyspace, tableName string, shardQueries int64) {
+func (e *Executor) updateQueryCounts(ctx context.Context, planType, keyspace, tableName string, shardQueries int64) {
 	queriesProcessed.Add(planType, 1)
 	queriesRouted.Add(planType, shardQueries)
-	if tableName != "" {
+	component := callerid.InternalComponentFromContext(ctx)
+	origin := component
+	if origin == "" {
+		origin = "user"
+	}
+	queriesProcessedByOrigin.Add([]string{origin, planType}, 1)
+	// The per table counts only count the queries of the users.
+	if tableName != "" && component == "" {
 		queriesProcessedByTable.Add([]string{planType, keyspace, tableName}, 1)
 		queriesRoutedByTable.Add([]string{planType, keyspace, tableName}, shardQueries)
 	}
@@ -1431,6 +1712,11 @@ func (e *Executor) ExecuteMultiShard(ctx context.Context, primitive engine.Primi
 	return e.scatterConn.ExecuteMultiShard(ctx, primitive, rss, queries, session, autocommit, ignoreMaxMemoryRows)
 }
 
+// ExecuteBatchMultiShard implements the IExecutor interface
+func (e *Executor) ExecuteBatchMultiShard(ctx context.Context, primitive engine.Primitive, rss []*srvtopo.ResolvedShard, queries [][]*querypb.BoundQuery, session *SafeSession, ignoreMaxMemoryRows bool) (qr *sqltypes.Result, errs []error) {
+	return e.scatterConn.ExecuteBatchMultiShard(ctx, primitive, rss, queries, session, ignoreMaxMemoryRows)
+}
+
 // StreamExecuteMulti implements the IExecutor interface
 func (e *Executor) StreamExecuteMulti(ctx context.Context, primitive engine.Primitive, query string, rss []*srvtopo.ResolvedShard, vars []map[string]*querypb.BindVariable, session *SafeSession, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
 	return e.scatterConn.StreamExecuteMulti(ctx, primitive, query, rss, vars, session, autocommit, callback)
@@ -1488,7 +1774,10 @@ func (e *Executor) startVStream(ctx context.Context, rss []*srvtopo.ResolvedShar
 	return nil
 }
 
-func (e *Executor) checkThatPlanIsValid(stmt sqlparser.Statement, plan *engine.Plan) error {
+func (e *Executor) checkThatPlanIsValid(stmt sqlparser.Statement, plan *engine.Plan, vschema *vindexes.VSchema) error {
+	if err := checkPlannerFeatures(vschema, stmt, plan); err != nil {
+		return err
+	}
 	if e.allowScatter || plan.Instructions == nil || sqlparser.AllowScatterDirective(stmt) {
 		return nil
 	}
@@ -1547,6 +1836,10 @@ func getTabletThrottlerStatus(tabletHostPort string) (string, error) {
 	return status, nil
 }
 
+func (e *Executor) advisoryLockService() engine.AdvisoryLockService {
+	return e.advisoryLocks
+}
+
 // ReleaseLock implements the IExecutor interface
 func (e *Executor) ReleaseLock(ctx context.Context, session *SafeSession) error {
 	return e.txConn.ReleaseLock(ctx, session)
@@ -1578,6 +1871,26 @@ func (e *Executor) planPrepareStmt(ctx context.Context, vcursor *vcursorImpl, qu
 	return plan, stmt, nil
 }
 
+// GetPlan returns the plan of the query for the target of the session,
+// the same plan that executing the query would use, without executing it.
+func (e *Executor) GetPlan(ctx context.Context, safeSession *SafeSession, sql string, bindVars map[string]*querypb.BindVariable) (*engine.Plan, error) {
+	if bindVars == nil {
+		bindVars = make(map[string]*querypb.BindVariable)
+	}
+	query, comments := sqlparser.SplitMarginComments(sql)
+	stmt, reservedVars, err := parseAndValidateQuery(query, e.env.Parser())
+	if err != nil {
+		return nil, err
+	}
+
+	logStats := logstats.NewLogStats(ctx, "GetPlan", sql, safeSession.GetSessionUUID(), bindVars)
+	vcursor, err := newVCursorImpl(safeSession, comments, e, logStats, e.vm, e.VSchema(), e.resolver.resolver, e.serv, e.warnShardedOnly, e.pv)
+	if err != nil {
+		return nil, err
+	}
+	return e.getPlan(ctx, vcursor, query, stmt, comments, bindVars, reservedVars, e.normalize, logStats)
+}
+
 func (e *Executor) Close() {
 	e.scatterConn.Close()
 	topo, err := e.serv.GetTopoServer()
@@ -652,6 +652,56 @@ func TestStreamBuffering(t *testing.T) {
 	utils.MustMatch(t, wantResults, results)
 }
 
+func TestStreamChunkSizeNegotiation(t *testing.T) {
+	executor, _, _, sbclookup, _ := createExecutorEnv(t)
+	executor.maxStreamSize = 1000
+
+	fields := []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int32, Charset: collations.CollationBinaryID, Flags: uint32(querypb.MySqlFlag_NUM_FLAG)},
+	}
+	rows := [][]sqltypes.Value{
+		{sqltypes.NewInt32(1)},
+		{sqltypes.NewInt32(2)},
+		{sqltypes.NewInt32(3)},
+	}
+	sbclookup.SetResults([]*sqltypes.Result{{Fields: fields, Rows: rows}})
+
+	// The client asks for chunks of at most 2 rows, and for more bytes than vtgate allows.
+	session := &vtgatepb.Session{
+		TargetString: "@primary",
+		Options: &querypb.ExecuteOptions{
+			StreamChunkMaxRows:  2,
+			StreamChunkMaxBytes: 1 << 20,
+		},
+	}
+	var results []*sqltypes.Result
+	err := executor.StreamExecute(
+		context.Background(),
+		nil,
+		"TestStreamChunkSizeNegotiation",
+		NewSafeSession(session),
+		"select id from music_user_map where id = 1",
+		nil,
+		func(qr *sqltypes.Result) error {
+			results = append(results, qr)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	wantResults := []*sqltypes.Result{
+		{Fields: fields},
+		{Rows: rows[:2]},
+		{Rows: rows[2:]},
+	}
+	utils.MustMatch(t, wantResults, results)
+
+	// The negotiated sizes are returned in the session, and sent to the tablet.
+	assert.EqualValues(t, 2, session.Options.StreamChunkMaxRows)
+	assert.EqualValues(t, 1000, session.Options.StreamChunkMaxBytes)
+	require.NotEmpty(t, sbclookup.Options)
+	assert.EqualValues(t, 1000, sbclookup.Options[len(sbclookup.Options)-1].StreamChunkMaxBytes)
+}
+
 func TestStreamLimitOffset(t *testing.T) {
 	returnRows := map[string][]sqltypes.Row{
 		"-20": [][]sqltypes.Value{{
@@ -3112,6 +3162,16 @@ func TestSelectWithUnionAll(t *testing.T) {
 
 	_, err = executorStream(ctx, executor, sql)
 	require.NoError(t, err)
+	// The session isn't autocommit, so the sources are streamed one after the
+	// other in the transaction, after their fields are fetched.
+	fieldQuery := &querypb.BoundQuery{
+		Sql: "select id from `user` where 1 != 1",
+		BindVariables: map[string]*querypb.BindVariable{
+			"vtg1": bv,
+			"vtg2": bv,
+		},
+	}
+	sbc1WantQueries = append([]*querypb.BoundQuery{fieldQuery, fieldQuery}, sbc1WantQueries...)
 	utils.MustMatch(t, sbc1WantQueries, sbc1.Queries, "sbc1")
 	utils.MustMatch(t, sbc2WantQueries, sbc2.Queries, "sbc2")
 }
@@ -4288,6 +4348,40 @@ func TestWarmingReads(t *testing.T) {
 	require.Nil(t, replica.GetQueries())
 }
 
+func TestSelectTabletTypeDirective(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	executor, primary, replica := createExecutorEnvWithPrimaryReplicaConn(t, ctx, 0)
+	target := &querypb.Target{Keyspace: KsTestUnsharded, Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	for _, th := range executor.scatterConn.gateway.hc.GetHealthyTabletStats(target) {
+		th.Stats.ReplicationLagSeconds = 3
+	}
+	session := NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded, Autocommit: true})
+
+	_, err := executor.Execute(ctx, nil, "TestSelectTabletTypeDirective", session, "select /*vt+ TABLET_TYPE=replica */ id from user", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, primary.ExecCount.Load())
+	assert.EqualValues(t, 1, replica.ExecCount.Load())
+
+	_, err = executor.Execute(ctx, nil, "TestSelectTabletTypeDirective", session, "select /*vt+ TABLET_TYPE=replica MAX_LAG=5s */ id from user", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, primary.ExecCount.Load())
+	assert.EqualValues(t, 2, replica.ExecCount.Load())
+
+	// The replica lags too much, the primary serves the query.
+	_, err = executor.Execute(ctx, nil, "TestSelectTabletTypeDirective", session, "select /*vt+ TABLET_TYPE=replica MAX_LAG=2s */ id from user", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, primary.ExecCount.Load())
+	assert.EqualValues(t, 2, replica.ExecCount.Load())
+
+	_, err = executor.Execute(ctx, nil, "TestSelectTabletTypeDirective", session, "select /*vt+ TABLET_TYPE=replica MAX_LAG=fresh */ id from user", nil)
+	require.EqualError(t, err, `invalid MAX_LAG value "fresh", expected a positive duration`)
+
+	_, err = executor.Execute(ctx, nil, "TestSelectTabletTypeDirective", session, "begin", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "TestSelectTabletTypeDirective", session, "select /*vt+ TABLET_TYPE=replica */ id from user", nil)
+	require.ErrorContains(t, err, "can't route the query to replica tablets because you have an active transaction")
+}
+
 // waitUntilQueryCount waits until the number of queries run on the tablet reach the specified count.
 func waitUntilQueryCount(t *testing.T, tab *sandboxconn.SandboxConn, count int) {
 	timeout := time.After(1 * time.Second)
@@ -4343,3 +4437,62 @@ func TestStreamJoinQuery(t *testing.T) {
 		utils.MustMatch(t, wantResult.Rows[idx], result.Rows[idx], "mismatched on: ", strconv.Itoa(idx))
 	}
 }
+
+func TestSelectKeysetPaginate(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id|col|weight_string(id)", "int64|varchar|varbinary")
+	var sbc1, sbc2 *sandboxconn.SandboxConn
+	executor, ctx := createExecutorEnvCallback(t, func(shard, ks string, tabletType topodatapb.TabletType, conn *sandboxconn.SandboxConn) {
+		switch {
+		case ks == KsTestSharded && shard == "-20":
+			sbc1 = conn
+		case ks == KsTestSharded && shard == "40-60":
+			sbc2 = conn
+		case ks == KsTestSharded:
+			conn.SetResults([]*sqltypes.Result{{Fields: fields}, {Fields: fields}, {Fields: fields}})
+		}
+	})
+	session := &vtgatepb.Session{TargetString: "@primary"}
+
+	sbc1.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(fields, "1|a|", "3|c|")})
+	sbc2.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(fields, "2|b|", "4|d|")})
+	result, err := executorExec(ctx, executor, session, "select /*vt+ KEYSET_PAGINATE */ id, col from user order by id limit 2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[INT64(1) VARCHAR("a")] [INT64(2) VARCHAR("b")]]`, fmt.Sprintf("%v", result.Rows))
+	token, err := encodeKeysetToken([]sqltypes.Value{sqltypes.NewInt64(2)})
+	require.NoError(t, err)
+	assert.Equal(t, token, session.KeysetContinuationToken)
+
+	// The next page replaces the offset with the continuation token.
+	sbc1.Queries = nil
+	sbc1.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(fields, "3|c|")})
+	sbc2.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(fields, "4|d|")})
+	query := fmt.Sprintf("select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=%s */ id, col from user order by id limit 2 offset 2", token)
+	result, err = executorExec(ctx, executor, session, query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[INT64(3) VARCHAR("c")] [INT64(4) VARCHAR("d")]]`, fmt.Sprintf("%v", result.Rows))
+	require.Len(t, sbc1.Queries, 1)
+	assert.Equal(t, "select /*vt+ KEYSET_PAGINATE */ id, col, weight_string(id) from `user` where id > :keyset order by `user`.id asc limit :__upper_limit", sbc1.Queries[0].Sql)
+	assert.Equal(t, sqltypes.Int64BindVariable(2), sbc1.Queries[0].BindVariables["keyset"])
+	token, err = encodeKeysetToken([]sqltypes.Value{sqltypes.NewInt64(4)})
+	require.NoError(t, err)
+	assert.Equal(t, token, session.KeysetContinuationToken)
+
+	result, err = executorExec(ctx, executor, session, "select @@keyset_continuation_token", nil)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`[[VARCHAR(%q)]]`, token), fmt.Sprintf("%v", result.Rows))
+
+	// The token is cleared after the last page.
+	sbc1.SetResults([]*sqltypes.Result{{Fields: fields}})
+	sbc2.SetResults([]*sqltypes.Result{{Fields: fields}})
+	query = fmt.Sprintf("select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=%s */ id, col from user order by id limit 2", token)
+	_, err = executorExec(ctx, executor, session, query, nil)
+	require.NoError(t, err)
+	assert.Empty(t, session.KeysetContinuationToken)
+
+	_, err = executorExec(ctx, executor, session, "select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=!! */ id, col from user order by id limit 2", nil)
+	require.ErrorContains(t, err, `invalid keyset continuation token: "!!"`)
+	_, err = executorExec(ctx, executor, session, "select /*vt+ KEYSET_PAGINATE */ id, col from user order by id", nil)
+	require.ErrorContains(t, err, "VT12001: unsupported: KEYSET_PAGINATE without ORDER BY and LIMIT")
+	_, err = executorExec(ctx, executor, session, "set @@keyset_continuation_token = 'abc'", nil)
+	require.Error(t, err)
+}
@@ -134,6 +134,18 @@ func TestExecutorSet(t *testing.T) {
 	}, {
 		in:  "set transaction_mode = 1",
 		err: "incorrect argument type to variable 'transaction_mode': INT64",
+	}, {
+		in:  "set transaction_tag = 'checkout.payment'",
+		out: &vtgatepb.Session{Autocommit: true, Options: &querypb.ExecuteOptions{TransactionTag: "checkout.payment"}},
+	}, {
+		in:  "set transaction_tag = ''",
+		out: &vtgatepb.Session{Autocommit: true},
+	}, {
+		in:  "set transaction_tag = 'a b'",
+		err: "invalid transaction_tag: a b",
+	}, {
+		in:  "set transaction_tag = 1",
+		err: "incorrect argument type to variable 'transaction_tag': INT64",
 	}, {
 		in:  "set workload = 'unspecified'",
 		out: &vtgatepb.Session{Autocommit: true, Options: &querypb.ExecuteOptions{Workload: querypb.ExecuteOptions_UNSPECIFIED}},
@@ -222,11 +234,17 @@ func TestExecutorSet(t *testing.T) {
 		in:  "set session transaction isolation level serializable",
 		out: &vtgatepb.Session{Autocommit: true},
 	}, {
-		in: "set transaction isolation level serializable",
-		out: &vtgatepb.Session{
-			Autocommit: true,
-			Warnings:   []*querypb.QueryWarning{{Code: uint32(sqlerror.ERNotSupportedYet), Message: "converted 'next transaction' scope to 'session' scope"}},
-		},
+		in:  "set transaction isolation level serializable",
+		out: &vtgatepb.Session{Autocommit: true, TransactionIsolation: querypb.ExecuteOptions_SERIALIZABLE},
+	}, {
+		in:  "set transaction isolation level read uncommitted, read only",
+		out: &vtgatepb.Session{Autocommit: true, TransactionIsolation: querypb.ExecuteOptions_READ_UNCOMMITTED, Warnings: []*querypb.QueryWarning{{Code: uint32(sqlerror.ERNotSupportedYet), Message: "converted 'next transaction' scope to 'session' scope"}}},
+	}, {
+		in:  "set @@transaction_isolation = 'READ-COMMITTED'",
+		out: &vtgatepb.Session{Autocommit: true, TransactionIsolation: querypb.ExecuteOptions_READ_COMMITTED},
+	}, {
+		in:  "set @@transaction_isolation = 'dirty-read'",
+		err: "variable 'transaction_isolation' can't be set to the value of 'dirty-read'",
 	}, {
 		in:  "set transaction read only",
 		out: &vtgatepb.Session{Autocommit: true, Warnings: []*querypb.QueryWarning{{Code: uint32(sqlerror.ERNotSupportedYet), Message: "converted 'next transaction' scope to 'session' scope"}}},
@@ -263,6 +281,15 @@ func TestExecutorSet(t *testing.T) {
 	}, {
 		in:  "set @@query_timeout = 50, query_timeout = 75",
 		out: &vtgatepb.Session{Autocommit: true, QueryTimeout: 75},
+	}, {
+		in:  "set session_track_state_change = on",
+		out: &vtgatepb.Session{Autocommit: true, SessionTrack: &vtgatepb.SessionTrack{StateChange: true, SystemVariables: defaultSessionTrackSystemVariables}},
+	}, {
+		in:  "set session_track_system_variables = '*'",
+		out: &vtgatepb.Session{Autocommit: true, SessionTrack: &vtgatepb.SessionTrack{SystemVariables: "*"}},
+	}, {
+		in:  "set session_track_state_change = 2",
+		err: "variable 'session_track_state_change' can't be set to the value: 2 is not a boolean",
 	}}
 	for i, tcase := range testcases {
 		t.Run(fmt.Sprintf("%d-%s", i, tcase.in), func(t *testing.T) {
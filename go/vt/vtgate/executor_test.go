@@ -50,6 +50,7 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
+	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -194,6 +195,56 @@ func TestExecutorTransactionsNoAutoCommit(t *testing.T) {
 	require.EqualError(t, err, `can't execute the given command because you have an active transaction`)
 }
 
+func TestExecutorNextTransactionIsolation(t *testing.T) {
+	executor, sbc1, sbc2, _, ctx := createExecutorEnv(t)
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
+	exec := func(sql string) error {
+		_, err := executor.Execute(ctx, nil, "TestExecute", session, sql, nil)
+		return err
+	}
+	lastIsolation := func(sbc *sandboxconn.SandboxConn) querypb.ExecuteOptions_TransactionIsolation {
+		require.NotEmpty(t, sbc.Options)
+		return sbc.Options[len(sbc.Options)-1].GetTransactionIsolation()
+	}
+
+	// The isolation level is sent to the shards of the next transaction, and cleared when it ends.
+	require.NoError(t, exec("set transaction isolation level read committed"))
+	require.NoError(t, exec("begin"))
+	require.NoError(t, exec("select id from `user` where id = 1"))
+	assert.Equal(t, querypb.ExecuteOptions_READ_COMMITTED, lastIsolation(sbc1))
+	require.EqualError(t, exec("set transaction isolation level serializable"), "Transaction characteristics can't be changed while a transaction is in progress")
+	require.NoError(t, exec("commit"))
+	assert.Equal(t, querypb.ExecuteOptions_DEFAULT, session.TransactionIsolation)
+
+	// The plan doesn't depend on the isolation level.
+	plans := executor.plans.Len()
+	require.NoError(t, exec("begin"))
+	require.NoError(t, exec("select id from `user` where id = 1"))
+	assert.Equal(t, querypb.ExecuteOptions_DEFAULT, lastIsolation(sbc1))
+	require.NoError(t, exec("rollback"))
+	assert.Equal(t, plans, executor.plans.Len())
+
+	// Serializable transactions can't span several shards.
+	require.NoError(t, exec("set transaction isolation level serializable"))
+	require.NoError(t, exec("begin"))
+	require.NoError(t, exec("select id from `user` where id = 1"))
+	assert.Equal(t, querypb.ExecuteOptions_SERIALIZABLE, lastIsolation(sbc1))
+	sbc2Calls := len(sbc2.Options)
+	require.ErrorContains(t, exec("select id from `user` where id = 3"), "VT12001: unsupported: transaction isolation level SERIALIZABLE in a cross-shard transaction")
+	assert.Len(t, sbc2.Options, sbc2Calls)
+	require.NoError(t, exec("rollback"))
+
+	// In autocommit mode, the isolation level is consumed by the next statement
+	// run outside of a transaction, and can't be changed once one is open.
+	session = NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true})
+	require.NoError(t, exec("set transaction isolation level read committed"))
+	require.NoError(t, exec("select id from `user` where id = 1"))
+	assert.Equal(t, querypb.ExecuteOptions_DEFAULT, session.TransactionIsolation)
+	require.NoError(t, exec("begin"))
+	require.EqualError(t, exec("set transaction isolation level serializable"), "Transaction characteristics can't be changed while a transaction is in progress")
+	require.NoError(t, exec("rollback"))
+}
+
 func TestDirectTargetRewrites(t *testing.T) {
 	executor, _, _, sbclookup, ctx := createExecutorEnv(t)
 
@@ -1078,6 +1129,35 @@ func TestExecutorShow(t *testing.T) {
 	assert.EqualError(t, err, want, query)
 }
 
+func TestExecutorShowCreateTableVSchemaAnnotation(t *testing.T) {
+	createResult := &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Name: "Table", Type: sqltypes.VarChar, Charset: uint32(collations.MySQL8().DefaultConnectionCharset())},
+			{Name: "Create Table", Type: sqltypes.VarChar, Charset: uint32(collations.MySQL8().DefaultConnectionCharset())},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewVarChar("user"),
+			sqltypes.NewVarChar("CREATE TABLE `user` (\n  `id` bigint NOT NULL\n) ENGINE=InnoDB"),
+		}},
+	}
+	executor, ctx := createExecutorEnvCallback(t, func(shard, ks string, tabletType topodatapb.TabletType, conn *sandboxconn.SandboxConn) {
+		if ks == KsTestSharded {
+			conn.SetResults([]*sqltypes.Result{createResult})
+		}
+	})
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
+
+	qr, err := executor.Execute(ctx, nil, "TestExecute", session, "show create table user", nil)
+	require.NoError(t, err)
+	require.Len(t, qr.Rows, 1)
+	assert.Equal(t, "user", qr.Rows[0][0].ToString())
+	assert.Equal(t, "CREATE TABLE `user` (\n  `id` bigint NOT NULL\n) ENGINE=InnoDB"+
+		"\n/* vschema: vindex hash_index (Id) using hash, primary */"+
+		"\n/* vschema: vindex name_user_map (`name`) using lookup_hash, owned */"+
+		"\n/* vschema: auto_increment id using sequence TestUnsharded.user_seq */", qr.Rows[0][1].ToString())
+	assert.Equal(t, []string{"Table", "Create Table"}, []string{qr.Fields[0].Name, qr.Fields[1].Name})
+}
+
 func TestExecutorShowTargeted(t *testing.T) {
 	executor, _, sbc2, _, ctx := createExecutorEnv(t)
 
@@ -1105,6 +1185,21 @@ func TestExecutorShowTargeted(t *testing.T) {
 	}
 }
 
+func TestExecutorShardListTargeted(t *testing.T) {
+	executor, sbc1, sbc2, sbclookup, ctx := createExecutorEnv(t)
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "TestExecutor:[-20,40-60]"})
+	sql := "select id from user where name = 'foo'"
+	_, err := executor.Execute(ctx, nil, "TestExecutorShardListTargeted", session, sql, nil)
+	require.NoError(t, err)
+
+	// The query is sent to exactly the listed shards.
+	wantQueries := []*querypb.BoundQuery{{Sql: "select id from `user` where `name` = 'foo'", BindVariables: map[string]*querypb.BindVariable{}}}
+	utils.MustMatch(t, wantQueries, sbc1.Queries)
+	utils.MustMatch(t, wantQueries, sbc2.Queries)
+	assert.Empty(t, sbclookup.Queries)
+}
+
 func TestExecutorUse(t *testing.T) {
 	executor, _, _, _, ctx := createExecutorEnv(t)
 
@@ -1113,10 +1208,12 @@ func TestExecutorUse(t *testing.T) {
 	stmts := []string{
 		"use TestExecutor",
 		"use `TestExecutor:-80@primary`",
+		"use `TestExecutor:[-20,40-60]@primary`",
 	}
 	want := []string{
 		"TestExecutor",
 		"TestExecutor:-80@primary",
+		"TestExecutor:[-20,40-60]@primary",
 	}
 	for i, stmt := range stmts {
 		_, err := executor.Execute(ctx, nil, "TestExecute", session, stmt, nil)
@@ -1594,7 +1691,7 @@ func assertCacheContains(t *testing.T, e *Executor, vc *vcursorImpl, sql string)
 			return true
 		})
 	} else {
-		h := e.hashPlan(context.Background(), vc, sql)
+		h := e.hashPlan(context.Background(), vc, sql, inListNone)
 		plan, _ = e.plans.Get(h, e.epoch.Load())
 	}
 	require.Truef(t, plan != nil, "plan not found for query: %s", sql)
@@ -1668,6 +1765,75 @@ func TestGetPlanCacheUnnormalized(t *testing.T) {
 	})
 }
 
+func TestExecutorFlushVitessPlans(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
+
+	queries := []string{
+		"select id from user where id = 1",
+		"select id from music where id = 1",
+		"select id from user_extra where user_id = 1",
+		"select id from music where id in (1, 2)",
+	}
+	cachePlans := func() {
+		for _, query := range queries {
+			_, err := executor.Execute(ctx, nil, "TestExecute", session, query, nil)
+			require.NoError(t, err)
+		}
+		// Wait for cache to settle
+		time.Sleep(100 * time.Millisecond)
+		assertCacheSize(t, executor.plans, len(queries))
+	}
+	flush := func(sql string) uint64 {
+		t.Helper()
+		qr, err := executor.Execute(ctx, nil, "TestExecute", session, sql, nil)
+		require.NoError(t, err)
+		// Wait for cache to settle
+		time.Sleep(100 * time.Millisecond)
+		return qr.RowsAffected
+	}
+
+	cachePlans()
+	ctxRedUser := callerid.NewContext(ctx, &vtrpcpb.CallerID{}, &querypb.VTGateCallerID{Username: "redUser"})
+	_, err := executor.Execute(ctxRedUser, nil, "TestExecute", session, "flush vitess_plans", nil)
+	require.EqualError(t, err, "User 'redUser' not authorized to flush vitess plans")
+	assertCacheSize(t, executor.plans, len(queries))
+	_, err = executor.Execute(ctxRedUser, nil, "TestExecute", session, "show vitess_plans", nil)
+	require.EqualError(t, err, "User 'redUser' not authorized to show vitess plans")
+
+	vschemaacl.AuthorizedDDLUsers = "%"
+	vschemaacl.Init()
+	defer func() {
+		vschemaacl.AuthorizedDDLUsers = ""
+		vschemaacl.Init()
+	}()
+
+	qr, err := executor.Execute(ctx, nil, "TestExecute", session, "show vitess_plans like '%music%'", nil)
+	require.NoError(t, err)
+	require.Len(t, qr.Rows, 2)
+	assert.Equal(t, "select id from music where id = 1", qr.Rows[0][0].ToString())
+	assert.Equal(t, "SELECT", qr.Rows[0][1].ToString())
+	assert.Equal(t, "TestExecutor.music", qr.Rows[0][2].ToString())
+	assert.Equal(t, "1", qr.Rows[0][3].ToString())
+	assert.Equal(t, "select id from music where id in (1, 2)", qr.Rows[1][0].ToString())
+
+	assert.EqualValues(t, 2, flush("flush vitess_plans for table music"))
+	assertCacheSize(t, executor.plans, 2)
+	assertCacheContains(t, executor, nil, "select id from `user` where id = 1")
+	assertCacheContains(t, executor, nil, "select id from user_extra where user_id = 1")
+
+	assert.EqualValues(t, 0, flush("flush vitess_plans for table TestUnsharded.user"))
+	assert.EqualValues(t, 1, flush("flush vitess_plans for table TestExecutor.user"))
+	assertCacheSize(t, executor.plans, 1)
+
+	assert.EqualValues(t, 1, flush("flush vitess_plans like '%user_extra%'"))
+	assertCacheSize(t, executor.plans, 0)
+
+	cachePlans()
+	assert.EqualValues(t, len(queries), flush("flush vitess_plans"))
+	assertCacheSize(t, executor.plans, 0)
+}
+
 func TestGetPlanCacheNormalized(t *testing.T) {
 	t.Run("Cache", func(t *testing.T) {
 		r, _, _, _, ctx := createExecutorEnv(t)
@@ -1750,6 +1916,109 @@ func TestGetPlanNormalized(t *testing.T) {
 	assertCacheContains(t, r, unshardedvc, normalized)
 }
 
+func TestGetPlanInListBuckets(t *testing.T) {
+	r, _, _, _, ctx := createExecutorEnv(t)
+
+	r.normalize = true
+	r.inListChunkSize = 2
+	vc, _ := newVCursorImpl(NewSafeSession(&vtgatepb.Session{TargetString: "@unknown"}), makeComments(""), r, nil, r.vm, r.VSchema(), r.resolver.resolver, nil, false, pv)
+
+	single, _ := getPlanCached(t, ctx, r, vc, "select * from user where id in (1)", makeComments(""), map[string]*querypb.BindVariable{}, false)
+	few, _ := getPlanCached(t, ctx, r, vc, "select * from user where id in (1, 2)", makeComments(""), map[string]*querypb.BindVariable{}, false)
+	few2, _ := getPlanCached(t, ctx, r, vc, "select * from user where id in (3, 4)", makeComments(""), map[string]*querypb.BindVariable{}, false)
+	many, _ := getPlanCached(t, ctx, r, vc, "select * from user where id in (1, 2, 3)", makeComments(""), map[string]*querypb.BindVariable{}, false)
+
+	assert.Same(t, few, few2)
+	assert.NotSame(t, single, few)
+	assert.NotSame(t, few, many)
+
+	isRoute := func(p engine.Primitive) bool {
+		_, ok := p.(*engine.Route)
+		return ok
+	}
+	assert.Zero(t, engine.Find(isRoute, few.Instructions).(*engine.Route).InListChunkSize)
+	assert.Equal(t, 2, engine.Find(isRoute, many.Instructions).(*engine.Route).InListChunkSize)
+}
+
+func TestExecutorScatterAggregationRefetch(t *testing.T) {
+	executor, sbc1, sbc2, _, ctx := createExecutorEnv(t)
+	session := &vtgatepb.Session{TargetString: "@primary", Autocommit: true}
+
+	sbc1.MustFailCodes[vtrpcpb.Code_UNAVAILABLE] = 1
+	_, err := executorExec(ctx, executor, session, "select count(*) from user", nil)
+	require.ErrorContains(t, err, "UNAVAILABLE error")
+
+	// Only the failed shard is queried again.
+	executor.refetchTimeout = time.Second
+	executor.ClearPlans()
+	sbc1.ExecCount.Store(0)
+	sbc2.ExecCount.Store(0)
+	sbc1.MustFailCodes[vtrpcpb.Code_UNAVAILABLE] = 1
+	result, err := executorExec(ctx, executor, session, "select count(*) from user", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "INT64(8)", result.Rows[0][0].String())
+	assert.EqualValues(t, 2, sbc1.ExecCount.Load())
+	assert.EqualValues(t, 1, sbc2.ExecCount.Load())
+
+	// The reads that take locks are never sent again.
+	vc, _ := newVCursorImpl(NewSafeSession(session), makeComments(""), executor, nil, executor.vm, executor.VSchema(), executor.resolver.resolver, nil, false, pv)
+	plan, _ := getPlanCached(t, ctx, executor, vc, "select count(*) from user for update", makeComments(""), map[string]*querypb.BindVariable{}, false)
+	isRoute := func(p engine.Primitive) bool {
+		_, ok := p.(*engine.Route)
+		return ok
+	}
+	assert.Zero(t, engine.Find(isRoute, plan.Instructions).(*engine.Route).ShardRefetchTimeout)
+}
+
+func TestInListBucket(t *testing.T) {
+	e := &Executor{}
+	list := func(n int) *querypb.BindVariable {
+		bv := &querypb.BindVariable{Type: querypb.Type_TUPLE}
+		for i := 0; i < n; i++ {
+			bv.Values = append(bv.Values, sqltypes.ValueToProto(sqltypes.NewInt64(int64(i))))
+		}
+		return bv
+	}
+	bvs := map[string]*querypb.BindVariable{"a": list(10)}
+	assert.Equal(t, inListNone, e.inListBucket(bvs))
+
+	e.inListChunkSize = 5
+	assert.Equal(t, inListNone, e.inListBucket(map[string]*querypb.BindVariable{"a": sqltypes.Int64BindVariable(1)}))
+	assert.Equal(t, inListSingle, e.inListBucket(map[string]*querypb.BindVariable{"a": list(1)}))
+	assert.Equal(t, inListFew, e.inListBucket(map[string]*querypb.BindVariable{"a": list(1), "b": list(5)}))
+	assert.Equal(t, inListMany, e.inListBucket(map[string]*querypb.BindVariable{"a": list(6), "b": list(2)}))
+}
+
+func TestExecutorTransactionTag(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", InTransaction: true})
+	_, err := executor.Execute(ctx, nil, "TestExecutorTransactionTag", session, "select /*vt+ TRANSACTION_TAG=checkout.payment */ id from user where id = 1", nil)
+	require.NoError(t, err)
+	require.Len(t, sbc1.Options, 1)
+	assert.Equal(t, "checkout.payment", sbc1.Options[0].TransactionTag)
+	assert.Equal(t, "checkout.payment", session.GetOptions().TransactionTag)
+
+	_, err = executor.Execute(ctx, nil, "TestExecutorTransactionTag", session, "select /*vt+ TRANSACTION_TAG=a;b */ id from user where id = 1", nil)
+	require.ErrorContains(t, err, `invalid transaction tag "a;b"`)
+
+	// The tag only applies to the current transaction.
+	_, err = executor.Execute(ctx, nil, "TestExecutorTransactionTag", session, "commit", nil)
+	require.NoError(t, err)
+	assert.Empty(t, session.GetOptions().TransactionTag)
+
+	_, err = executor.Execute(ctx, nil, "TestExecutorTransactionTag", session, "begin", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "TestExecutorTransactionTag", session, "set transaction_tag = 'checkout.refund'", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "TestExecutorTransactionTag", session, "select id from user where id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "checkout.refund", sbc1.Options[len(sbc1.Options)-1].TransactionTag)
+	_, err = executor.Execute(ctx, nil, "TestExecutorTransactionTag", session, "rollback", nil)
+	require.NoError(t, err)
+	assert.Empty(t, session.GetOptions().TransactionTag)
+}
+
 func TestGetPlanPriority(t *testing.T) {
 
 	testCases := []struct {
@@ -2636,6 +2905,22 @@ func TestExecutorTempTable(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, before, executor.plans.Len())
+	assert.Equal(t, []string{KsTestUnsharded + ".temp_t"}, session.TempTables)
+	assert.True(t, session.InReservedConn())
+
+	// Plans are cached again once the temporary tables are dropped.
+	_, err = executor.Execute(ctx, nil, "TestExecutorTempTable", session, "drop table temp_t", nil)
+	require.NoError(t, err)
+	assert.Empty(t, session.TempTables)
+	assert.True(t, session.cachePlan())
+
+	// The temporary tables are gone with the reserved connections.
+	_, err = executor.Execute(ctx, nil, "TestExecutorTempTable", session, creatQuery, nil)
+	require.NoError(t, err)
+	assert.False(t, session.cachePlan())
+	require.NoError(t, executor.CloseSession(ctx, session))
+	assert.Empty(t, session.TempTables)
+	assert.True(t, session.cachePlan())
 }
 
 func TestExecutorShowVitessMigrations(t *testing.T) {
@@ -2649,6 +2934,52 @@ func TestExecutorShowVitessMigrations(t *testing.T) {
 	assert.Contains(t, sbc2.StringQueries(), "show vitess_migrations")
 }
 
+func TestExecutorShowVitessTableStatus(t *testing.T) {
+	executor, ctx := createExecutorEnvCallback(t, func(shard, ks string, tabletType topodatapb.TabletType, conn *sandboxconn.SandboxConn) {
+		if ks == KsTestSharded {
+			conn.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("Name|Shards|Rows|Data_length|Index_length|Data_free|Auto_increment", "varchar|int64|uint64|uint64|uint64|uint64|uint64"),
+				"user|1|10|16384|8192|0|11",
+			)})
+		}
+	})
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: KsTestSharded})
+	qr, err := executor.Execute(ctx, nil, "TestExecutorShowVitessTableStatus", session, "show vitess_table_status user", nil)
+	require.NoError(t, err)
+	require.Len(t, qr.Rows, 1)
+	assert.Equal(t, `[VARCHAR("user") INT64(8) DECIMAL(80) DECIMAL(131072) DECIMAL(65536) DECIMAL(0) UINT64(11)]`, fmt.Sprintf("%v", qr.Rows[0]))
+}
+
+func TestExecutorShowVitessTransactionStatus(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+
+	session := NewAutocommitSession(&vtgatepb.Session{})
+	qr, err := executor.Execute(ctx, nil, "TestExecutorShowVitessTransactionStatus", session, "show vitess_transaction status", nil)
+	require.NoError(t, err)
+	require.Equal(t, buildVarCharFields("In_transaction", "Transaction_mode", "Shards", "Reserved_connections", "Savepoints"), qr.Fields)
+	assert.Equal(t, `[[VARCHAR("0") VARCHAR("TWOPC") VARCHAR("") VARCHAR("0") VARCHAR("")]]`, fmt.Sprintf("%v", qr.Rows))
+
+	session = NewSafeSession(&vtgatepb.Session{
+		InTransaction:   true,
+		TransactionMode: vtgatepb.TransactionMode_SINGLE,
+		ShardSessions: []*vtgatepb.Session_ShardSession{{
+			Target:        &querypb.Target{Keyspace: KsTestSharded, Shard: "-20", TabletType: topodatapb.TabletType_PRIMARY},
+			TransactionId: 1,
+			TabletAlias:   &topodatapb.TabletAlias{Cell: "aa", Uid: 1},
+		}, {
+			Target:        &querypb.Target{Keyspace: KsTestSharded, Shard: "20-40", TabletType: topodatapb.TabletType_PRIMARY},
+			TransactionId: 2,
+			ReservedId:    2,
+			TabletAlias:   &topodatapb.TabletAlias{Cell: "aa", Uid: 2},
+		}},
+		Savepoints: []string{"savepoint a", "savepoint b"},
+	})
+	qr, err = executor.Execute(ctx, nil, "TestExecutorShowVitessTransactionStatus", session, "show vitess_transaction status", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[[VARCHAR("1") VARCHAR("SINGLE") VARCHAR("TestExecutor/-20@primary aa-0000000001, TestExecutor/20-40@primary aa-0000000002") VARCHAR("1") VARCHAR("savepoint a; savepoint b")]]`, fmt.Sprintf("%v", qr.Rows))
+}
+
 func TestExecutorDescHash(t *testing.T) {
 	executor, _, _, _, ctx := createExecutorEnv(t)
 
@@ -177,6 +177,11 @@ func (conn *FakeVTGateConn) Prepare(ctx context.Context, session *vtgatepb.Sessi
 	return s, reply.Fields, nil
 }
 
+// GetPlan please see vtgateconn.Impl.GetPlan
+func (conn *FakeVTGateConn) GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVars map[string]*querypb.BindVariable) ([]byte, error) {
+	panic("not implemented")
+}
+
 // CloseSession please see vtgateconn.Impl.CloseSession
 func (conn *FakeVTGateConn) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
 	panic("not implemented")
@@ -194,6 +199,11 @@ func (conn *FakeVTGateConn) VStream(ctx context.Context, tabletType topodatapb.T
 	return nil, fmt.Errorf("NYI")
 }
 
+// GetVStreamCheckpoints please see vtgateconn.Impl.GetVStreamCheckpoints
+func (conn *FakeVTGateConn) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	panic("not implemented")
+}
+
 // Close please see vtgateconn.Impl.Close
 func (conn *FakeVTGateConn) Close() {
 }
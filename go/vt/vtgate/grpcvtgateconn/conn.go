@@ -219,6 +219,25 @@ func (conn *vtgateConn) Prepare(ctx context.Context, session *vtgatepb.Session,
 	return response.Session, response.Fields, nil
 }
 
+func (conn *vtgateConn) GetPlan(ctx context.Context, session *vtgatepb.Session, query string, bindVars map[string]*querypb.BindVariable) ([]byte, error) {
+	request := &vtgatepb.GetPlanRequest{
+		CallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		Session:  session,
+		Query: &querypb.BoundQuery{
+			Sql:           query,
+			BindVariables: bindVars,
+		},
+	}
+	response, err := conn.c.GetPlan(ctx, request)
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	if response.Error != nil {
+		return nil, vterrors.FromVTRPC(response.Error)
+	}
+	return response.Plan, nil
+}
+
 func (conn *vtgateConn) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
 	request := &vtgatepb.CloseSessionRequest{
 		CallerId: callerid.EffectiveCallerIDFromContext(ctx),
@@ -274,6 +293,21 @@ func (conn *vtgateConn) VStream(ctx context.Context, tabletType topodatapb.Table
 	}, nil
 }
 
+func (conn *vtgateConn) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	request := &vtgatepb.GetVStreamCheckpointsRequest{
+		CallerId:   callerid.EffectiveCallerIDFromContext(ctx),
+		StreamName: streamName,
+	}
+	response, err := conn.c.GetVStreamCheckpoints(ctx, request)
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	if response.Error != nil {
+		return nil, vterrors.FromVTRPC(response.Error)
+	}
+	return response.Checkpoints, nil
+}
+
 func (conn *vtgateConn) Close() {
 	conn.cc.Close()
 }
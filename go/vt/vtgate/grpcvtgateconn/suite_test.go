@@ -226,6 +226,21 @@ func (f *fakeVTGateService) Prepare(ctx context.Context, session *vtgatepb.Sessi
 	return session, execCase.result.Fields, nil
 }
 
+// GetPlan is part of the VTGateService interface
+func (f *fakeVTGateService) GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) ([]byte, error) {
+	if f.hasError {
+		return nil, errTestVtGateError
+	}
+	if f.panics {
+		panic(fmt.Errorf("test forced panic"))
+	}
+	f.checkCallerID(ctx, "GetPlan")
+	if _, ok := execMap[sql]; !ok {
+		return nil, fmt.Errorf("no match for: %s", sql)
+	}
+	return []byte(fmt.Sprintf(`{"Original":%q,"Target":%q}`, sql, session.TargetString)), nil
+}
+
 // CloseSession is part of the VTGateService interface
 func (f *fakeVTGateService) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
 	panic("unimplemented")
@@ -250,6 +265,24 @@ func (f *fakeVTGateService) VStream(ctx context.Context, tabletType topodatapb.T
 	panic("unimplemented")
 }
 
+// GetVStreamCheckpoints is part of the VTGateService interface
+func (f *fakeVTGateService) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	if f.hasError {
+		return nil, errTestVtGateError
+	}
+	if f.panics {
+		panic(fmt.Errorf("test forced panic"))
+	}
+	f.checkCallerID(ctx, "GetVStreamCheckpoints")
+	return []*vtgatepb.VStreamCheckpoint{{
+		Name:            streamName,
+		TabletType:      topodatapb.TabletType_REPLICA,
+		Vgtid:           &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "-80", Gtid: "pos"}}},
+		CheckpointToken: "token",
+		Active:          true,
+	}}, nil
+}
+
 // CreateFakeServer returns the fake server for the tests
 func CreateFakeServer(t *testing.T) vtgateservice.VTGateService {
 	return &fakeVTGateService{
@@ -292,6 +325,8 @@ func RunTests(t *testing.T, impl vtgateconn.Impl, fakeServer vtgateservice.VTGat
 	testStreamExecute(t, session)
 	testExecuteBatch(t, session)
 	testPrepare(t, session)
+	testGetPlan(t, session)
+	testGetVStreamCheckpoints(t, conn)
 
 	// force a panic at every call, then test that works
 	fs.panics = true
@@ -299,6 +334,8 @@ func RunTests(t *testing.T, impl vtgateconn.Impl, fakeServer vtgateservice.VTGat
 	testExecuteBatchPanic(t, session)
 	testStreamExecutePanic(t, session)
 	testPreparePanic(t, session)
+	testGetPlanPanic(t, session)
+	testGetVStreamCheckpointsPanic(t, conn)
 	fs.panics = false
 }
 
@@ -318,6 +355,8 @@ func RunErrorTests(t *testing.T, fakeServer vtgateservice.VTGateService) {
 	testExecuteBatchError(t, session, fs)
 	testStreamExecuteError(t, session, fs)
 	testPrepareError(t, session, fs)
+	testGetPlanError(t, session, fs)
+	testGetVStreamCheckpointsError(t, conn)
 	fs.hasError = false
 }
 
@@ -524,6 +563,56 @@ func testPreparePanic(t *testing.T, session *vtgateconn.VTGateSession) {
 	expectPanic(t, err)
 }
 
+func testGetPlan(t *testing.T, session *vtgateconn.VTGateSession) {
+	ctx := newContext()
+	execCase := execMap["request1"]
+	plan, err := session.GetPlan(ctx, execCase.execQuery.SQL, execCase.execQuery.BindVariables)
+	require.NoError(t, err)
+	require.JSONEq(t, fmt.Sprintf(`{"Original":%q,"Target":"connection_ks@rdonly"}`, execCase.execQuery.SQL), string(plan))
+
+	_, err = session.GetPlan(ctx, "none", nil)
+	require.EqualError(t, err, "no match for: none")
+}
+
+func testGetPlanError(t *testing.T, session *vtgateconn.VTGateSession, fake *fakeVTGateService) {
+	ctx := newContext()
+	execCase := execMap["errorRequst"]
+
+	_, err := session.GetPlan(ctx, execCase.execQuery.SQL, execCase.execQuery.BindVariables)
+	verifyError(t, err, "GetPlan")
+}
+
+func testGetPlanPanic(t *testing.T, session *vtgateconn.VTGateSession) {
+	ctx := newContext()
+	execCase := execMap["request1"]
+	_, err := session.GetPlan(ctx, execCase.execQuery.SQL, execCase.execQuery.BindVariables)
+	expectPanic(t, err)
+}
+
+func testGetVStreamCheckpoints(t *testing.T, conn *vtgateconn.VTGateConn) {
+	ctx := newContext()
+	checkpoints, err := conn.GetVStreamCheckpoints(ctx, "stream1")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	require.Equal(t, "stream1", checkpoints[0].Name)
+	require.Equal(t, topodatapb.TabletType_REPLICA, checkpoints[0].TabletType)
+	require.Equal(t, "pos", checkpoints[0].Vgtid.ShardGtids[0].Gtid)
+	require.Equal(t, "token", checkpoints[0].CheckpointToken)
+	require.True(t, checkpoints[0].Active)
+}
+
+func testGetVStreamCheckpointsError(t *testing.T, conn *vtgateconn.VTGateConn) {
+	ctx := newContext()
+	_, err := conn.GetVStreamCheckpoints(ctx, "stream1")
+	verifyError(t, err, "GetVStreamCheckpoints")
+}
+
+func testGetVStreamCheckpointsPanic(t *testing.T, conn *vtgateconn.VTGateConn) {
+	ctx := newContext()
+	_, err := conn.GetVStreamCheckpoints(ctx, "stream1")
+	expectPanic(t, err)
+}
+
 var testCallerID = &vtrpcpb.CallerID{
 	Principal:    "test_principal",
 	Component:    "test_component",
@@ -232,6 +232,23 @@ func (vtg *VTGate) Prepare(ctx context.Context, request *vtgatepb.PrepareRequest
 	}, nil
 }
 
+// GetPlan is the RPC version of vtgateservice.VTGateService method
+func (vtg *VTGate) GetPlan(ctx context.Context, request *vtgatepb.GetPlanRequest) (response *vtgatepb.GetPlanResponse, err error) {
+	defer vtg.server.HandlePanic(&err)
+	ctx = withCallerIDContext(ctx, request.CallerId)
+
+	session := request.Session
+	if session == nil {
+		session = &vtgatepb.Session{Autocommit: true}
+	}
+
+	plan, err := vtg.server.GetPlan(ctx, session, request.Query.Sql, request.Query.BindVariables)
+	return &vtgatepb.GetPlanResponse{
+		Plan:  plan,
+		Error: vterrors.ToVTRPC(err),
+	}, nil
+}
+
 // CloseSession is the RPC version of vtgateservice.VTGateService method
 func (vtg *VTGate) CloseSession(ctx context.Context, request *vtgatepb.CloseSessionRequest) (response *vtgatepb.CloseSessionResponse, err error) {
 	defer vtg.server.HandlePanic(&err)
@@ -270,19 +287,47 @@ func (vtg *VTGate) VStream(request *vtgatepb.VStreamRequest, stream vtgateservic
 	if tabletType == topodatapb.TabletType_UNKNOWN {
 		tabletType = topodatapb.TabletType_PRIMARY
 	}
+	vgtid := request.Vgtid
+	if request.CheckpointToken != "" {
+		if len(vgtid.GetShardGtids()) != 0 {
+			return vterrors.ToGRPC(vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "only one of vgtid and checkpoint_token can be set"))
+		}
+		if vgtid, err = vtgate.DecodeCheckpointToken(request.CheckpointToken); err != nil {
+			return vterrors.ToGRPC(err)
+		}
+	}
 	vtgErr := vtg.server.VStream(ctx,
 		tabletType,
-		request.Vgtid,
+		vgtid,
 		request.Filter,
 		request.Flags,
 		func(events []*binlogdatapb.VEvent) error {
-			return stream.Send(&vtgatepb.VStreamResponse{
+			response := &vtgatepb.VStreamResponse{
 				Events: events,
-			})
+			}
+			if vgtid := vtgate.LastVGtid(events); vgtid != nil {
+				token, err := vtgate.EncodeCheckpointToken(vgtid)
+				if err != nil {
+					return err
+				}
+				response.CheckpointToken = token
+			}
+			return stream.Send(response)
 		})
 	return vterrors.ToGRPC(vtgErr)
 }
 
+// GetVStreamCheckpoints is the RPC version of vtgateservice.VTGateService method
+func (vtg *VTGate) GetVStreamCheckpoints(ctx context.Context, request *vtgatepb.GetVStreamCheckpointsRequest) (response *vtgatepb.GetVStreamCheckpointsResponse, err error) {
+	defer vtg.server.HandlePanic(&err)
+	ctx = withCallerIDContext(ctx, request.CallerId)
+	checkpoints, vtgErr := vtg.server.GetVStreamCheckpoints(ctx, request.StreamName)
+	return &vtgatepb.GetVStreamCheckpointsResponse{
+		Checkpoints: checkpoints,
+		Error:       vterrors.ToVTRPC(vtgErr),
+	}, nil
+}
+
 func init() {
 	vtgate.RegisterVTGates = append(vtgate.RegisterVTGates, func(vtGate vtgateservice.VTGateService) {
 		if servenv.GRPCCheckServiceMap("vtgateservice") {
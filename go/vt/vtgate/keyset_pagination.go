@@ -0,0 +1,257 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// keysetPagination is the state of a SELECT paginated with the KEYSET_PAGINATE
+// directive, which is needed to compute the continuation token of its result.
+//
+// A page is read with:
+//
+//	select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=<token> */ ... order by ... limit N
+//
+// where the token is the one of the previous page, and is omitted for the first
+// page. The token holds the values of the ORDER BY columns of the last row of the
+// previous page. vtgate replaces the OFFSET of the query with a predicate that
+// skips the rows up to that row, so that every shard of a scatter query returns
+// at most N rows, however deep the page is. The token of the page is stored in
+// the session and can be read with @@keyset_continuation_token. It is empty
+// after the last page.
+//
+// The ORDER BY columns must be selected, and must identify the rows uniquely.
+type keysetPagination struct {
+	// columns are the offsets of the ORDER BY columns in the result.
+	columns []int
+	// limit is the page size.
+	limit int
+}
+
+// prepareKeysetPagination rewrites the statement to read the page of the
+// continuation token of its KEYSET_PAGINATE directive. It returns nil if the
+// statement does not have the directive.
+func prepareKeysetPagination(stmt sqlparser.Statement, reservedVars *sqlparser.ReservedVars, bindVars map[string]*querypb.BindVariable) (*keysetPagination, error) {
+	commented, ok := stmt.(sqlparser.Commented)
+	if !ok {
+		return nil, nil
+	}
+	directives := commented.GetParsedComments().Directives()
+	if !directives.IsSet(sqlparser.DirectiveKeysetPaginate) {
+		return nil, nil
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, vterrors.VT12001("KEYSET_PAGINATE on a statement that is not a SELECT")
+	}
+	if len(sel.OrderBy) == 0 || sel.Limit == nil || sel.Limit.Rowcount == nil {
+		return nil, vterrors.VT12001("KEYSET_PAGINATE without ORDER BY and LIMIT")
+	}
+	limit, err := keysetLimit(sel.Limit.Rowcount, bindVars)
+	if err != nil {
+		return nil, err
+	}
+
+	kp := &keysetPagination{limit: limit}
+	exprs := make([]sqlparser.Expr, 0, len(sel.OrderBy))
+	for _, order := range sel.OrderBy {
+		offset, expr, err := keysetColumn(sel, order.Expr)
+		if err != nil {
+			return nil, err
+		}
+		kp.columns = append(kp.columns, offset)
+		exprs = append(exprs, expr)
+	}
+
+	token, ok := directives.GetString(sqlparser.DirectiveKeysetToken, "")
+	if !ok {
+		return kp, nil
+	}
+	values, err := decodeKeysetToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(exprs) {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "keyset continuation token has %d values, but the query is ordered by %d columns", len(values), len(exprs))
+	}
+
+	// The rows after the last row of the previous page are the ones that are
+	// equal on the first i ORDER BY columns, and after it on the next one.
+	var after []sqlparser.Expr
+	var equal []sqlparser.Expr
+	for i, expr := range exprs {
+		value := values[i]
+		var arg sqlparser.Expr
+		if !value.IsNull() {
+			name := reservedVars.ReserveVariable("keyset")
+			bindVars[name] = sqltypes.ValueBindVariable(value)
+			arg = sqlparser.NewArgument(name)
+		}
+		if next := keysetAfter(expr, arg, sel.OrderBy[i].Direction); next != nil {
+			after = append(after, sqlparser.AndExpressions(append(equal[:len(equal):len(equal)], next)...))
+		}
+		if arg == nil {
+			equal = append(equal, &sqlparser.IsExpr{Left: expr, Right: sqlparser.IsNullOp})
+		} else {
+			equal = append(equal, sqlparser.NewComparisonExpr(sqlparser.EqualOp, expr, arg, nil))
+		}
+	}
+	if len(after) == 0 {
+		// The last row was the last possible one, so the page is empty.
+		sel.AddWhere(sqlparser.NewIntLiteral("0"))
+	} else {
+		pred := after[0]
+		for _, next := range after[1:] {
+			pred = &sqlparser.OrExpr{Left: pred, Right: next}
+		}
+		sel.AddWhere(pred)
+	}
+	sel.Limit.Offset = nil
+	sel.SetComments(sel.Comments.RemoveDirective(sqlparser.DirectiveKeysetToken))
+	return kp, nil
+}
+
+// keysetAfter returns the predicate of the values of expr that are after arg
+// in the given direction, where a nil arg is NULL. NULL is before any other
+// value, like in MySQL. It returns nil if no value is after arg.
+func keysetAfter(expr, arg sqlparser.Expr, direction sqlparser.OrderDirection) sqlparser.Expr {
+	switch {
+	case arg == nil && direction == sqlparser.DescOrder:
+		return nil
+	case arg == nil:
+		return &sqlparser.IsExpr{Left: expr, Right: sqlparser.IsNotNullOp}
+	case direction == sqlparser.DescOrder:
+		return &sqlparser.OrExpr{
+			Left:  sqlparser.NewComparisonExpr(sqlparser.LessThanOp, expr, arg, nil),
+			Right: &sqlparser.IsExpr{Left: expr, Right: sqlparser.IsNullOp},
+		}
+	default:
+		return sqlparser.NewComparisonExpr(sqlparser.GreaterThanOp, expr, arg, nil)
+	}
+}
+
+// keysetLimit returns the row count of the LIMIT clause.
+func keysetLimit(rowcount sqlparser.Expr, bindVars map[string]*querypb.BindVariable) (int, error) {
+	var limit int64
+	var err error
+	switch rowcount := rowcount.(type) {
+	case *sqlparser.Literal:
+		limit, err = strconv.ParseInt(rowcount.Val, 10, 64)
+	case *sqlparser.Argument:
+		bv, ok := bindVars[rowcount.Name]
+		if !ok {
+			return 0, vterrors.VT03026(rowcount.Name)
+		}
+		var value sqltypes.Value
+		if value, err = sqltypes.BindVariableToValue(bv); err == nil {
+			limit, err = value.ToInt64()
+		}
+	default:
+		err = vterrors.VT12001("KEYSET_PAGINATE with a LIMIT that is not a number")
+	}
+	if err != nil || limit < 0 {
+		return 0, vterrors.VT12001("KEYSET_PAGINATE with a LIMIT that is not a number")
+	}
+	return int(limit), nil
+}
+
+// keysetColumn returns the offset of the ORDER BY expression in the result, and
+// the selected expression it refers to.
+func keysetColumn(sel *sqlparser.Select, orderBy sqlparser.Expr) (int, sqlparser.Expr, error) {
+	col, isCol := orderBy.(*sqlparser.ColName)
+	for i, selectExpr := range sel.SelectExprs {
+		ae, ok := selectExpr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return 0, nil, vterrors.VT12001("KEYSET_PAGINATE with a SELECT that is not a list of expressions")
+		}
+		switch {
+		case sqlparser.Equals.Expr(ae.Expr, orderBy):
+		case isCol && col.Qualifier.IsEmpty() && !ae.As.IsEmpty() && ae.As.Equal(col.Name):
+		case isCol && col.Qualifier.IsEmpty() && ae.As.IsEmpty() && isColNamed(ae.Expr, col.Name):
+		default:
+			continue
+		}
+		if sqlparser.ContainsAggregation(ae.Expr) {
+			return 0, nil, vterrors.VT12001("KEYSET_PAGINATE ordered by an aggregation")
+		}
+		return i, ae.Expr, nil
+	}
+	return 0, nil, vterrors.VT12001("KEYSET_PAGINATE ordered by a column that is not selected: " + sqlparser.String(orderBy))
+}
+
+func isColNamed(expr sqlparser.Expr, name sqlparser.IdentifierCI) bool {
+	col, ok := expr.(*sqlparser.ColName)
+	return ok && col.Name.Equal(name)
+}
+
+// continuationToken returns the token of the page that ends with lastRow, or
+// an empty token if it is the last page.
+func (kp *keysetPagination) continuationToken(rowCount int, lastRow sqltypes.Row) (string, error) {
+	if rowCount == 0 || rowCount < kp.limit {
+		return "", nil
+	}
+	values := make([]sqltypes.Value, 0, len(kp.columns))
+	for _, offset := range kp.columns {
+		if offset >= len(lastRow) {
+			return "", vterrors.VT13001("KEYSET_PAGINATE column is missing from the result")
+		}
+		values = append(values, lastRow[offset])
+	}
+	return encodeKeysetToken(values)
+}
+
+// encodeKeysetToken encodes the values of a continuation token.
+func encodeKeysetToken(values []sqltypes.Value) (string, error) {
+	bv := &querypb.BindVariable{Type: querypb.Type_TUPLE}
+	for _, value := range values {
+		bv.Values = append(bv.Values, sqltypes.ValueToProto(value))
+	}
+	buf, err := bv.MarshalVT()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// decodeKeysetToken decodes the values of a continuation token.
+func decodeKeysetToken(token string) ([]sqltypes.Value, error) {
+	invalid := func() error {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid keyset continuation token: %s", strconv.Quote(token))
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid()
+	}
+	bv := &querypb.BindVariable{}
+	if err := bv.UnmarshalVT(buf); err != nil || bv.Type != querypb.Type_TUPLE {
+		return nil, invalid()
+	}
+	values := make([]sqltypes.Value, 0, len(bv.Values))
+	for _, value := range bv.Values {
+		values = append(values, sqltypes.ProtoToValue(value))
+	}
+	return values, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestPrepareKeysetPagination(t *testing.T) {
+	token := func(values ...sqltypes.Value) string {
+		token, err := encodeKeysetToken(values)
+		require.NoError(t, err)
+		return token
+	}
+	tests := []struct {
+		name     string
+		query    string
+		bindVars map[string]*querypb.BindVariable
+		columns  []int
+		limit    int
+		want     string
+		wantBVs  map[string]*querypb.BindVariable
+		wantErr  string
+	}{{
+		name:    "no directive",
+		query:   "select id from t order by id limit 10 offset 20",
+		want:    "select id from t order by id asc limit 20, 10",
+		wantBVs: map[string]*querypb.BindVariable{},
+	}, {
+		name:    "first page",
+		query:   "select /*vt+ KEYSET_PAGINATE */ a, id from t order by id limit 10",
+		columns: []int{1},
+		limit:   10,
+		want:    "select /*vt+ KEYSET_PAGINATE */ a, id from t order by id asc limit 10",
+		wantBVs: map[string]*querypb.BindVariable{},
+	}, {
+		name:    "next page",
+		query:   "select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=" + token(sqltypes.NewInt64(5)) + " */ a, id from t where a = 1 order by id limit 10 offset 10",
+		columns: []int{1},
+		limit:   10,
+		want:    "select /*vt+ KEYSET_PAGINATE */ a, id from t where a = 1 and id > :keyset order by id asc limit 10",
+		wantBVs: map[string]*querypb.BindVariable{"keyset": sqltypes.Int64BindVariable(5)},
+	}, {
+		name:  "several columns and directions",
+		query: "select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=" + token(sqltypes.NewVarChar("x"), sqltypes.NewInt64(5)) + " */ t.a as b, id from t order by b desc, id limit ?",
+		bindVars: map[string]*querypb.BindVariable{
+			"v1": sqltypes.Int64BindVariable(3),
+		},
+		columns: []int{0, 1},
+		limit:   3,
+		want:    "select /*vt+ KEYSET_PAGINATE */ t.a as b, id from t where t.a < :keyset or t.a is null or t.a = :keyset and id > :keyset1 order by b desc, id asc limit :v1",
+		wantBVs: map[string]*querypb.BindVariable{
+			"v1":      sqltypes.Int64BindVariable(3),
+			"keyset":  sqltypes.StringBindVariable("x"),
+			"keyset1": sqltypes.Int64BindVariable(5),
+		},
+	}, {
+		name:    "null values",
+		query:   "select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=" + token(sqltypes.NULL, sqltypes.NULL) + " */ a, id from t order by a, id desc limit 10",
+		columns: []int{0, 1},
+		limit:   10,
+		want:    "select /*vt+ KEYSET_PAGINATE */ a, id from t where a is not null order by a asc, id desc limit 10",
+		wantBVs: map[string]*querypb.BindVariable{},
+	}, {
+		name:    "last possible row",
+		query:   "select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=" + token(sqltypes.NULL) + " */ a from t order by a desc limit 10",
+		columns: []int{0},
+		limit:   10,
+		want:    "select /*vt+ KEYSET_PAGINATE */ a from t where 0 order by a desc limit 10",
+		wantBVs: map[string]*querypb.BindVariable{},
+	}, {
+		name:    "not a select",
+		query:   "select /*vt+ KEYSET_PAGINATE */ id from t union select id from u order by id limit 10",
+		wantErr: "VT12001: unsupported: KEYSET_PAGINATE on a statement that is not a SELECT",
+	}, {
+		name:    "column not selected",
+		query:   "select /*vt+ KEYSET_PAGINATE */ a from t order by id limit 10",
+		wantErr: "VT12001: unsupported: KEYSET_PAGINATE ordered by a column that is not selected: id",
+	}, {
+		name:    "star expression",
+		query:   "select /*vt+ KEYSET_PAGINATE */ * from t order by id limit 10",
+		wantErr: "VT12001: unsupported: KEYSET_PAGINATE with a SELECT that is not a list of expressions",
+	}, {
+		name:    "aggregation",
+		query:   "select /*vt+ KEYSET_PAGINATE */ count(*) as c from t group by a order by c limit 10",
+		wantErr: "VT12001: unsupported: KEYSET_PAGINATE ordered by an aggregation",
+	}, {
+		name:    "token of another query",
+		query:   "select /*vt+ KEYSET_PAGINATE KEYSET_TOKEN=" + token(sqltypes.NewInt64(1), sqltypes.NewInt64(2)) + " */ id from t order by id limit 10",
+		wantErr: "keyset continuation token has 2 values, but the query is ordered by 1 columns",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, known, err := sqlparser.NewTestParser().Parse2(tt.query)
+			require.NoError(t, err)
+			bindVars := tt.bindVars
+			if bindVars == nil {
+				bindVars = map[string]*querypb.BindVariable{}
+			}
+			kp, err := prepareKeysetPagination(stmt, sqlparser.NewReservedVars("vtg", known), bindVars)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sqlparser.String(stmt))
+			assert.Equal(t, tt.wantBVs, bindVars)
+			if tt.columns == nil {
+				assert.Nil(t, kp)
+				return
+			}
+			assert.Equal(t, &keysetPagination{columns: tt.columns, limit: tt.limit}, kp)
+		})
+	}
+}
+
+func TestKeysetContinuationToken(t *testing.T) {
+	kp := &keysetPagination{columns: []int{1, 0}, limit: 2}
+	row := sqltypes.Row{sqltypes.NewVarBinary("b"), sqltypes.NewInt64(2)}
+
+	token, err := kp.continuationToken(2, row)
+	require.NoError(t, err)
+	values, err := decodeKeysetToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewVarBinary("b")}, values)
+	assert.False(t, strings.ContainsAny(token, "= */"))
+
+	// A short page is the last one.
+	token, err = kp.continuationToken(1, row)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+}
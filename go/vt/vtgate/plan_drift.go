@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+// The modes of the plan drift detection.
+const (
+	planDriftOff  = "off"
+	planDriftFlag = "flag"
+)
+
+// maxPlanDriftReports is the number of queries whose drifts are reported.
+// Beyond it, the ones that drifted the longest ago are dropped.
+const maxPlanDriftReports = 1000
+
+var planDrifts = stats.NewCountersWithSingleLabel("PlanDrifts", "Plans whose recent executions drifted far from their first ones", "Reason")
+
+// PlanDriftReport is a query whose plan drifted.
+type PlanDriftReport struct {
+	Query           string
+	Reason          string
+	BaselineLatency time.Duration
+	RecentLatency   time.Duration
+	BaselineRows    float64
+	RecentRows      float64
+	// Drifts is the number of times the plans of the query drifted.
+	Drifts       int
+	FirstDrifted time.Time
+	LastDrifted  time.Time
+}
+
+// planDriftDetector detects the plans whose executions drift far from their
+// first ones, e.g. because the data grew or a plan-time assumption doesn't
+// hold anymore, and reports their queries.
+type planDriftDetector struct {
+	window uint64
+	factor float64
+
+	mu      sync.Mutex
+	reports map[string]*PlanDriftReport
+	now     func() time.Time
+}
+
+// newPlanDriftDetector returns a planDriftDetector for the mode, or nil if
+// the detection is off.
+func newPlanDriftDetector(mode string, window int, factor float64) *planDriftDetector {
+	if mode != planDriftFlag {
+		return nil
+	}
+	return &planDriftDetector{
+		window:  uint64(window),
+		factor:  factor,
+		reports: make(map[string]*PlanDriftReport),
+		now:     time.Now,
+	}
+}
+
+// check reports the plan if its last window of executions drifted.
+func (d *planDriftDetector) check(plan *engine.Plan) {
+	if d == nil {
+		return
+	}
+	drift := plan.CheckDrift(d.window, d.factor)
+	if drift == nil {
+		return
+	}
+	planDrifts.Add(drift.Reason, 1)
+	log.Infof("Plan of %q drifted: %s went from %v and %.1f rows to %v and %.1f rows per execution", plan.Original, drift.Reason, drift.BaselineLatency, drift.BaselineRows, drift.RecentLatency, drift.RecentRows)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	report, ok := d.reports[plan.Original]
+	if !ok {
+		d.evict()
+		report = &PlanDriftReport{Query: plan.Original, FirstDrifted: now}
+		d.reports[plan.Original] = report
+	}
+	report.Reason = drift.Reason
+	report.BaselineLatency = drift.BaselineLatency
+	report.RecentLatency = drift.RecentLatency
+	report.BaselineRows = drift.BaselineRows
+	report.RecentRows = drift.RecentRows
+	report.Drifts++
+	report.LastDrifted = now
+}
+
+// evict drops the report that drifted the longest ago when the reports are full.
+func (d *planDriftDetector) evict() {
+	if len(d.reports) < maxPlanDriftReports {
+		return
+	}
+	var oldest *PlanDriftReport
+	for _, report := range d.reports {
+		if oldest == nil || report.LastDrifted.Before(oldest.LastDrifted) {
+			oldest = report
+		}
+	}
+	delete(d.reports, oldest.Query)
+}
+
+// Reports returns the queries whose plans drifted, the most recent first.
+func (d *planDriftDetector) Reports() []PlanDriftReport {
+	if d == nil {
+		return []PlanDriftReport{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	reports := make([]PlanDriftReport, 0, len(d.reports))
+	for _, report := range d.reports {
+		reports = append(reports, *report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].LastDrifted.Equal(reports[j].LastDrifted) {
+			return reports[i].LastDrifted.After(reports[j].LastDrifted)
+		}
+		return reports[i].Query < reports[j].Query
+	})
+	return reports
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// driftPlan runs a baseline window of fast executions of the plan, then a
+// window of slow ones, checking it for drifts after each one.
+func driftPlan(d *planDriftDetector, plan *engine.Plan) {
+	for i := uint64(0); i < d.window; i++ {
+		plan.AddStats(1, time.Millisecond, 1, 0, 1, 0)
+		d.check(plan)
+	}
+	for i := uint64(0); i < d.window; i++ {
+		plan.AddStats(1, time.Second, 1, 0, 1, 0)
+		d.check(plan)
+	}
+}
+
+func TestPlanDriftDetector(t *testing.T) {
+	assert.Nil(t, newPlanDriftDetector(planDriftOff, 10, 10))
+	assert.Empty(t, (*planDriftDetector)(nil).Reports())
+	(*planDriftDetector)(nil).check(&engine.Plan{})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newPlanDriftDetector(planDriftFlag, 10, 10)
+	d.now = func() time.Time { return now }
+	driftsBefore := planDrifts.Counts()[engine.PlanDriftLatency]
+
+	driftPlan(d, &engine.Plan{Original: "select * from t1"})
+	now = now.Add(time.Minute)
+	driftPlan(d, &engine.Plan{Original: "select * from t2"})
+	// A new plan of the same query drifts again.
+	now = now.Add(time.Minute)
+	driftPlan(d, &engine.Plan{Original: "select * from t1"})
+
+	reports := d.Reports()
+	require.Len(t, reports, 2)
+	assert.Equal(t, "select * from t1", reports[0].Query)
+	assert.Equal(t, engine.PlanDriftLatency, reports[0].Reason)
+	assert.Equal(t, time.Millisecond, reports[0].BaselineLatency)
+	assert.Equal(t, time.Second, reports[0].RecentLatency)
+	assert.Equal(t, 2, reports[0].Drifts)
+	assert.Equal(t, now.Add(-2*time.Minute), reports[0].FirstDrifted)
+	assert.Equal(t, now, reports[0].LastDrifted)
+	assert.Equal(t, "select * from t2", reports[1].Query)
+	assert.EqualValues(t, 3, planDrifts.Counts()[engine.PlanDriftLatency]-driftsBefore)
+}
+
+func TestPlanDriftDetectorEviction(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newPlanDriftDetector(planDriftFlag, 1, 10)
+	d.now = func() time.Time { return now }
+	for i := 0; i <= maxPlanDriftReports; i++ {
+		now = now.Add(time.Second)
+		driftPlan(d, &engine.Plan{Original: fmt.Sprintf("select %d from dual", i)})
+	}
+	reports := d.Reports()
+	require.Len(t, reports, maxPlanDriftReports)
+	assert.Equal(t, fmt.Sprintf("select %d from dual", maxPlanDriftReports), reports[0].Query)
+	// The report that drifted the longest ago was dropped.
+	assert.Equal(t, "select 1 from dual", reports[len(reports)-1].Query)
+}
+
+func TestExecutorPlanDrift(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+	executor.planDrift = newPlanDriftDetector(planDriftFlag, 2, 10)
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
+	query := "select id from music_user_map where id = 1"
+
+	_, err := executor.Execute(ctx, nil, "TestExecute", session, query, nil)
+	require.NoError(t, err)
+	// Wait for cache to settle
+	time.Sleep(100 * time.Millisecond)
+	plan := assertCacheContains(t, executor, nil, query)
+
+	// The next executions of the plan are much slower than the first ones.
+	plan.AddStats(1, time.Millisecond, 1, 0, 1, 0)
+	require.Nil(t, plan.CheckDrift(2, 10))
+	plan.AddStats(1, time.Second, 1, 0, 1, 0)
+	_, err = executor.Execute(ctx, nil, "TestExecute", session, query, nil)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	reports := executor.planDrift.Reports()
+	require.Len(t, reports, 1)
+	assert.Equal(t, query, reports[0].Query)
+	assert.Equal(t, 1, reports[0].Drifts)
+	// The plan that drifted stays cached.
+	assert.Same(t, plan, assertCacheContains(t, executor, nil, query))
+}
@@ -85,6 +85,17 @@ func (e *Executor) newExecute(
 		return err
 	}
 
+	// In autocommit mode, the statements run outside of a transaction are
+	// transactions of their own, which consume the isolation level set for
+	// the next transaction.
+	if safeSession.Autocommit && !safeSession.InTransaction() {
+		switch sqlparser.ASTToStatementType(stmt) {
+		case sqlparser.StmtSet, sqlparser.StmtBegin:
+		default:
+			defer safeSession.ClearTransactionIsolation()
+		}
+	}
+
 	var lastVSchemaCreated time.Time
 	vs := e.VSchema()
 	lastVSchemaCreated = vs.GetCreated()
@@ -145,6 +156,9 @@ func (e *Executor) newExecute(
 		}
 
 		// 5: Execute the plan and retry if needed
+		if vcursor.maxReplicationLag > 0 {
+			ctx = withMaxReplicationLag(ctx, vcursor.maxReplicationLag)
+		}
 		if plan.Instructions.NeedsTransaction() {
 			err = e.insideTransaction(ctx, safeSession, logStats,
 				func() error {
@@ -277,8 +291,22 @@ func (e *Executor) executePlan(
 	execStart time.Time,
 ) (*sqltypes.Result, error) {
 
+	release, err := e.concurrencyBudget.acquire(plan)
+	if err != nil {
+		e.setLogStats(logStats, plan, vcursor, execStart, err, nil)
+		return nil, err
+	}
+	defer release()
+
 	// 4: Execute!
-	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	var qr *sqltypes.Result
+	if keyspace, ok := e.collapser.keyspace(plan, vcursor, safeSession); ok {
+		qr, err = e.collapser.execute(ctx, keyspace, plan, vcursor, safeSession, bindVars, func() (*sqltypes.Result, error) {
+			return vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+		})
+	} else {
+		qr, err = vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	}
 
 	// 5: Log and add statistics
 	e.setLogStats(logStats, plan, vcursor, execStart, err, qr)
@@ -287,6 +315,18 @@ func (e *Executor) executePlan(
 	if err != nil {
 		return nil, e.rollbackExecIfNeeded(ctx, safeSession, bindVars, logStats, err)
 	}
+
+	if vcursor.keysetPagination != nil && qr != nil {
+		var lastRow sqltypes.Row
+		if len(qr.Rows) > 0 {
+			lastRow = qr.Rows[len(qr.Rows)-1]
+		}
+		token, err := vcursor.keysetPagination.continuationToken(len(qr.Rows), lastRow)
+		if err != nil {
+			return nil, err
+		}
+		safeSession.SetKeysetContinuationToken(token)
+	}
 	return qr, nil
 }
 
@@ -342,14 +382,16 @@ func (e *Executor) setLogStats(logStats *logstats.LogStats, plan *engine.Plan, v
 	logStats.ActiveKeyspace = vcursor.keyspace
 	logStats.TablesUsed = plan.TablesUsed
 	logStats.TabletType = vcursor.TabletType().String()
+	recordTableStats(logStats.Ctx, plan, logStats.TabletType, time.Since(logStats.StartTime), err)
 	errCount := e.logExecutionEnd(logStats, execStart, plan, err, qr)
 	plan.AddStats(1, time.Since(logStats.StartTime), logStats.ShardQueries, logStats.RowsAffected, logStats.RowsReturned, errCount)
+	e.planDrift.check(plan)
 }
 
 func (e *Executor) logExecutionEnd(logStats *logstats.LogStats, execStart time.Time, plan *engine.Plan, err error, qr *sqltypes.Result) uint64 {
 	logStats.ExecuteTime = time.Since(execStart)
 
-	e.updateQueryCounts(plan.Instructions.RouteType(), plan.Instructions.GetKeyspaceName(), plan.Instructions.GetTableName(), int64(logStats.ShardQueries))
+	e.updateQueryCounts(logStats.Ctx, plan.Instructions.RouteType(), plan.Instructions.GetKeyspaceName(), plan.Instructions.GetTableName(), int64(logStats.ShardQueries))
 
 	var errCount uint64
 	if err != nil {
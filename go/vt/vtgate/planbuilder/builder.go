@@ -135,12 +135,11 @@ func getConfiguredPlanner(vschema plancontext.VSchema, stmt sqlparser.Statement,
 }
 
 func getPlannerFromQueryHint(stmt sqlparser.Statement) (plancontext.PlannerVersion, bool) {
-	cm, isCom := stmt.(sqlparser.Commented)
-	if !isCom {
+	if _, isCom := stmt.(sqlparser.Commented); !isCom {
 		return plancontext.PlannerVersion(0), false
 	}
 
-	d := cm.GetParsedComments().Directives()
+	d := sqlparser.QueryDirectives(stmt)
 	val, ok := d.GetString(sqlparser.DirectiveQueryPlanner, "")
 	if !ok {
 		return plancontext.PlannerVersion(0), false
@@ -214,6 +213,8 @@ func createInstructionFor(ctx context.Context, query string, stmt sqlparser.Stat
 		return buildRoutePlan(stmt, reservedVars, vschema, buildUnlockPlan)
 	case *sqlparser.Flush:
 		return buildFlushPlan(stmt, vschema)
+	case *sqlparser.FlushVitessPlans:
+		return newPlanResult(&engine.FlushPlans{Tables: stmt.TableNames, Pattern: stmt.Pattern}), nil
 	case *sqlparser.CallProc:
 		return buildCallProcPlan(stmt, vschema)
 	case *sqlparser.Stream:
@@ -72,6 +72,11 @@ func buildGeneralDDLPlan(ctx context.Context, sql string, ddlStatement sqlparser
 
 		CreateTempTable: ddlStatement.IsTemporary(),
 	}
+	if vs := vschema.GetVSchema(); vs != nil {
+		if ks := vs.Keyspaces[normalDDLPlan.Keyspace.Name]; ks != nil {
+			eddl.DDLStrategy = ks.DDLStrategy
+		}
+	}
 	tc := &tableCollector{}
 	for _, tbl := range ddlStatement.AffectedTables() {
 		tc.addASTTable(normalDDLPlan.Keyspace.Name, tbl)
@@ -27,16 +27,18 @@ type distinct struct {
 	logicalPlanCommon
 	checkCols      []engine.CheckCol
 	truncateColumn int
+	ordered        bool
 
 	// needToTruncate is the old way to check weight_string column and set truncation.
 	needToTruncate bool
 }
 
-func newDistinct(source logicalPlan, checkCols []engine.CheckCol, truncateColumn int) logicalPlan {
+func newDistinct(source logicalPlan, checkCols []engine.CheckCol, truncateColumn int, ordered bool) logicalPlan {
 	return &distinct{
 		logicalPlanCommon: newBuilderCommon(source),
 		checkCols:         checkCols,
 		truncateColumn:    truncateColumn,
+		ordered:           ordered,
 	}
 }
 
@@ -58,5 +60,6 @@ func (d *distinct) Primitive() engine.Primitive {
 		Source:    d.input.Primitive(),
 		CheckCols: d.checkCols,
 		Truncate:  truncate,
+		Ordered:   d.ordered,
 	}
 }
@@ -17,10 +17,14 @@ limitations under the License.
 package planbuilder
 
 import (
+	"slices"
+
+	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
@@ -53,7 +57,8 @@ func gen4InsertStmtPlanner(version querypb.ExecuteOptions_PlannerVersion, insStm
 		if tables[0].AutoIncrement == nil && !ctx.SemTable.ForeignKeysPresent() {
 			plan := insertUnshardedShortcut(insStmt, ks, tables)
 			setCommentDirectivesOnPlan(plan, insStmt)
-			return newPlanResult(plan.Primitive(), operators.QualifiedTables(ks, tables)...), nil
+			prim := withSQLModeChecks(plan.Primitive(), sqlModeChecks(vschema, insStmt, tables[0]))
+			return newPlanResult(prim, operators.QualifiedTables(ks, tables)...), nil
 		}
 	}
 
@@ -70,6 +75,9 @@ func gen4InsertStmtPlanner(version querypb.ExecuteOptions_PlannerVersion, insStm
 		return nil, err
 	}
 
+	// The values are checked before planning the query, which rewrites them.
+	checks := sqlModeChecks(vschema, insStmt, tblInfo.GetVindexTable())
+
 	op, err := operators.PlanQuery(ctx, insStmt)
 	if err != nil {
 		return nil, err
@@ -80,7 +88,99 @@ func gen4InsertStmtPlanner(version querypb.ExecuteOptions_PlannerVersion, insStm
 		return nil, err
 	}
 
-	return newPlanResult(plan.Primitive(), operators.TablesUsed(op)...), nil
+	return newPlanResult(withSQLModeChecks(plan.Primitive(), checks), operators.TablesUsed(op)...), nil
+}
+
+// sqlModeChecks returns the checks of the inserted values for the sql_mode
+// checks enforced by vtgate. Only the values of the columns known by the schema
+// tracking, and that vtgate can evaluate, are checked.
+func sqlModeChecks(vschema plancontext.VSchema, insStmt *sqlparser.Insert, vTbl *vindexes.Table) []*engine.ValueCheck {
+	enforced := vschema.SQLModeChecks()
+	if len(enforced) == 0 || insStmt.Ignore || vTbl == nil {
+		return nil
+	}
+	rows, ok := insStmt.Rows.(sqlparser.Values)
+	if !ok {
+		return nil
+	}
+
+	var columns []*vindexes.Column
+	if len(insStmt.Columns) == 0 {
+		if !vTbl.ColumnListAuthoritative {
+			return nil
+		}
+		for i := range vTbl.Columns {
+			if !vTbl.Columns[i].Invisible {
+				columns = append(columns, &vTbl.Columns[i])
+			}
+		}
+	} else {
+		for _, name := range insStmt.Columns {
+			var column *vindexes.Column
+			for i := range vTbl.Columns {
+				if vTbl.Columns[i].Name.Equal(name) {
+					column = &vTbl.Columns[i]
+					break
+				}
+			}
+			columns = append(columns, column)
+		}
+	}
+
+	cfg := &evalengine.Config{
+		Collation:   vschema.ConnCollation(),
+		Environment: vschema.Environment(),
+	}
+	var checks []*engine.ValueCheck
+	for i, row := range rows {
+		for j, expr := range row {
+			if j >= len(columns) || columns[j] == nil {
+				continue
+			}
+			mode := sqlModeOfColumn(columns[j])
+			if mode == "" || !slices.Contains(enforced, mode) {
+				continue
+			}
+			evalExpr, err := evalengine.Translate(expr, cfg)
+			if err != nil {
+				continue
+			}
+			checks = append(checks, &engine.ValueCheck{
+				SQLMode: mode,
+				Column:  columns[j].Name.String(),
+				Row:     i + 1,
+				Type:    columns[j].Type,
+				Size:    columns[j].Size,
+				Expr:    evalExpr,
+			})
+		}
+	}
+	return checks
+}
+
+// sqlModeOfColumn returns the sql_mode which checks the values of the column,
+// if any.
+func sqlModeOfColumn(column *vindexes.Column) string {
+	switch {
+	case sqltypes.IsDate(column.Type):
+		return engine.SQLModeNoZeroDate
+	case sqltypes.IsIntegral(column.Type):
+		return engine.SQLModeStrictTransTables
+	}
+	switch column.Type {
+	case sqltypes.Char, sqltypes.VarChar, sqltypes.Binary, sqltypes.VarBinary:
+		if column.Size > 0 {
+			return engine.SQLModeStrictTransTables
+		}
+	}
+	return ""
+}
+
+func withSQLModeChecks(prim engine.Primitive, checks []*engine.ValueCheck) engine.Primitive {
+	if len(checks) == 0 {
+		return prim
+	}
+	return &engine.SQLModeCheck{Input: prim, Checks: checks}
 }
 
 func errOutIfPlanCannotBeConstructed(ctx *plancontext.PlanningContext, vTbl *vindexes.Table) error {
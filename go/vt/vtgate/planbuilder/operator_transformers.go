@@ -293,6 +293,14 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 		aggrParam.OrigOpcode = aggr.OriginalOpCode
 		aggrParam.WCol = aggr.WSOffset
 		aggrParam.Type = aggr.GetTypeCollation(ctx)
+		for _, extra := range aggr.ExtraDistinct {
+			typ, _ := ctx.SemTable.TypeForExpr(extra.Expr)
+			aggrParam.ExtraDistinct = append(aggrParam.ExtraDistinct, engine.DistinctKey{
+				Col:  extra.ColOffset,
+				WCol: extra.WSOffset,
+				Type: typ,
+			})
+		}
 		oa.aggregates = append(oa.aggregates, aggrParam)
 	}
 	for _, groupBy := range op.Grouping {
@@ -315,7 +323,7 @@ func transformDistinct(ctx *plancontext.PlanningContext, op *operators.Distinct)
 	if err != nil {
 		return nil, err
 	}
-	return newDistinct(src, op.Columns, op.Truncate), nil
+	return newDistinct(src, op.Columns, op.Truncate, op.Ordered), nil
 }
 
 func transformOrdering(ctx *plancontext.PlanningContext, op *operators.Ordering) (logicalPlan, error) {
@@ -331,14 +339,20 @@ func createMemorySort(ctx *plancontext.PlanningContext, src logicalPlan, orderin
 	primitive := &engine.MemorySort{
 		TruncateColumnCount: ordering.ResultColumns,
 	}
+	primitive.OrderBy = createOrderByParams(ctx, ordering)
 	ms := &memorySort{
 		resultsBuilder: newResultsBuilder(src, primitive),
 		eMemorySort:    primitive,
 	}
 
+	return ms, nil
+}
+
+func createOrderByParams(ctx *plancontext.PlanningContext, ordering *operators.Ordering) evalengine.Comparison {
+	var orderBy evalengine.Comparison
 	for idx, order := range ordering.Order {
 		typ, _ := ctx.SemTable.TypeForExpr(order.SimplifiedExpr)
-		ms.eMemorySort.OrderBy = append(ms.eMemorySort.OrderBy, evalengine.OrderByParams{
+		orderBy = append(orderBy, evalengine.OrderByParams{
 			Col:             ordering.Offset[idx],
 			WeightStringCol: ordering.WOffset[idx],
 			Desc:            order.Inner.Direction == sqlparser.DescOrder,
@@ -346,8 +360,7 @@ func createMemorySort(ctx *plancontext.PlanningContext, src logicalPlan, orderin
 			CollationEnv:    ctx.VSchema.Environment().CollationEnv(),
 		})
 	}
-
-	return ms, nil
+	return orderBy
 }
 
 func transformProjection(ctx *plancontext.PlanningContext, op *operators.Projection) (logicalPlan, error) {
@@ -835,6 +848,12 @@ func transformUnionPlan(ctx *plancontext.PlanningContext, op *operators.Union) (
 }
 
 func transformLimit(ctx *plancontext.PlanningContext, op *operators.Limit) (logicalPlan, error) {
+	if ordering, ok := op.Source.(*operators.Ordering); ok {
+		// An ordering that has to be done in memory and directly limited
+		// only needs to keep the first rows.
+		return transformTopN(ctx, op, ordering)
+	}
+
 	plan, err := transformToLogicalPlan(ctx, op.Source)
 	if err != nil {
 		return nil, err
@@ -843,6 +862,37 @@ func transformLimit(ctx *plancontext.PlanningContext, op *operators.Limit) (logi
 	return createLimit(plan, op.AST, ctx.VSchema.Environment(), ctx.VSchema.ConnCollation())
 }
 
+func transformTopN(ctx *plancontext.PlanningContext, op *operators.Limit, ordering *operators.Ordering) (logicalPlan, error) {
+	plan, err := transformToLogicalPlan(ctx, ordering.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	primitive := &engine.TopN{
+		OrderBy:             createOrderByParams(ctx, ordering),
+		TruncateColumnCount: ordering.ResultColumns,
+	}
+	cfg := &evalengine.Config{
+		Collation:   ctx.VSchema.ConnCollation(),
+		Environment: ctx.VSchema.Environment(),
+	}
+	primitive.Count, err = evalengine.Translate(op.AST.Rowcount, cfg)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "unexpected expression in LIMIT")
+	}
+	if op.AST.Offset != nil {
+		primitive.Offset, err = evalengine.Translate(op.AST.Offset, cfg)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "unexpected expression in OFFSET")
+		}
+	}
+
+	return &topN{
+		resultsBuilder: newResultsBuilder(plan, primitive),
+		eTopN:          primitive,
+	}, nil
+}
+
 func createLimit(input logicalPlan, limit *sqlparser.Limit, env *vtenv.Environment, coll collations.ID) (logicalPlan, error) {
 	plan := newLimit(input)
 	cfg := &evalengine.Config{
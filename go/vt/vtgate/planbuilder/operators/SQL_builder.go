@@ -338,6 +338,7 @@ func stripDownQuery(from, to sqlparser.SelectStatement) {
 			panic(vterrors.VT13001("AST did not match"))
 		}
 		toNode.Distinct = node.Distinct
+		toNode.StraightJoinHint = node.StraightJoinHint
 		toNode.GroupBy = node.GroupBy
 		toNode.Having = node.Having
 		toNode.OrderBy = node.OrderBy
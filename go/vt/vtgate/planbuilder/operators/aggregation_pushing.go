@@ -27,13 +27,6 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 )
 
-func errDistinctAggrWithMultiExpr(f sqlparser.AggrFunc) {
-	if f == nil {
-		panic(vterrors.VT12001("distinct aggregation function with multiple expressions"))
-	}
-	panic(vterrors.VT12001(fmt.Sprintf("distinct aggregation function with multiple expressions '%s'", sqlparser.String(f))))
-}
-
 func tryPushAggregator(ctx *plancontext.PlanningContext, aggregator *Aggregator) (output Operator, applyResult *ApplyResult) {
 	if aggregator.Pushed {
 		return aggregator, NoRewrite
@@ -180,12 +173,10 @@ func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator,
 			continue
 		}
 
-		if len(distinctExprs) != 1 {
-			errDistinctAggrWithMultiExpr(aggr.Func)
-		}
-
 		// We handle a distinct aggregation by turning it into a group by and
-		// doing the aggregating on the vtgate level instead
+		// doing the aggregating on the vtgate level instead.
+		// The other expressions of a distinct aggregation on multiple expressions
+		// are grouped by once the vtgate aggregator asks for them.
 		aeDistinctExpr := aeWrap(distinctExprs[0])
 		aggrBelowRoute.Columns[aggr.ColOffset] = aeDistinctExpr
 
@@ -201,7 +192,7 @@ func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator,
 	}
 
 	if !canPushDistinctAggr {
-		aggregator.DistinctExpr = distinctExprs[0]
+		aggregator.DistinctExprs = distinctExprs
 	}
 }
 
@@ -229,6 +220,10 @@ func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator)
 		if len(distinctExprs) == 0 {
 			distinctExprs = args
 		}
+		if len(args) != len(distinctExprs) {
+			differentExpr = aggr.Original
+			continue
+		}
 		for idx, expr := range distinctExprs {
 			if !ctx.SemTable.EqualsExpr(expr, args[idx]) {
 				differentExpr = aggr.Original
@@ -551,10 +546,7 @@ func splitAggrColumnsToLeftAndRight(
 	// Distinct aggregation cannot be pushed down in the join.
 	// We keep node of the distinct aggregation expression to be used later for ordering.
 	if !canPushDistinctAggr {
-		if len(distinctExprs) != 1 {
-			errDistinctAggrWithMultiExpr(nil)
-		}
-		aggregator.DistinctExpr = distinctExprs[0]
+		aggregator.DistinctExprs = distinctExprs
 		return nil, errAbortAggrPushing
 	}
 
@@ -40,10 +40,10 @@ type (
 		Grouping     []GroupBy
 		Aggregations []Aggr
 
-		// We support a single distinct aggregation per aggregator. It is stored here.
+		// We support a single distinct aggregation per aggregator. Its expressions are stored here.
 		// When planning the ordering that the OrderedAggregate will require,
-		// this needs to be the last ORDER BY expression
-		DistinctExpr sqlparser.Expr
+		// these need to be the last ORDER BY expressions
+		DistinctExprs sqlparser.Exprs
 
 		// Pushed will be set to true once this aggregation has been pushed deeper in the tree
 		Pushed        bool
@@ -282,16 +282,41 @@ func (a *Aggregator) planOffsets(ctx *plancontext.PlanningContext) Operator {
 	}
 
 	for idx, aggr := range a.Aggregations {
-		if !aggr.NeedsWeightString(ctx) {
-			continue
+		if aggr.NeedsWeightString(ctx) {
+			arg := aggr.getPushColumn()
+			offset := a.internalAddColumn(ctx, aeWrap(weightStringFor(arg)), true)
+			a.Aggregations[idx].WSOffset = offset
 		}
-		arg := aggr.getPushColumn()
-		offset := a.internalAddColumn(ctx, aeWrap(weightStringFor(arg)), true)
-		a.Aggregations[idx].WSOffset = offset
+		a.planExtraDistinctOffsets(ctx, idx, true)
 	}
 	return nil
 }
 
+// planExtraDistinctOffsets adds the columns of the other expressions of a
+// distinct aggregation on multiple expressions, which are compared with the
+// first one to find the distinct rows.
+func (a *Aggregator) planExtraDistinctOffsets(ctx *plancontext.PlanningContext, idx int, addToGroupBy bool) {
+	aggr := a.Aggregations[idx]
+	if aggr.OpCode != opcode.AggregateCountDistinct || aggr.ExtraDistinct != nil {
+		return
+	}
+	args := aggr.Func.GetArgs()
+	if len(args) < 2 {
+		return
+	}
+	for _, arg := range args[1:] {
+		extra := DistinctOffset{
+			Expr:      arg,
+			ColOffset: a.internalAddColumn(ctx, aeWrap(arg), addToGroupBy),
+			WSOffset:  -1,
+		}
+		if ctx.SemTable.NeedsWeightString(arg) {
+			extra.WSOffset = a.internalAddColumn(ctx, aeWrap(weightStringFor(arg)), addToGroupBy)
+		}
+		a.Aggregations[idx].ExtraDistinct = append(a.Aggregations[idx].ExtraDistinct, extra)
+	}
+}
+
 func (aggr Aggr) getPushColumn() sqlparser.Expr {
 	switch aggr.OpCode {
 	case opcode.AggregateAnyValue:
@@ -303,6 +328,9 @@ func (aggr Aggr) getPushColumn() sqlparser.Expr {
 			panic(vterrors.VT12001("group_concat with more than 1 column"))
 		}
 		return aggr.Func.GetArg()
+	case opcode.AggregateCountDistinct:
+		// The other expressions are pushed as the extra distinct columns.
+		return aggr.Func.GetArg()
 	default:
 		if len(aggr.Func.GetArgs()) > 1 {
 			panic(vterrors.VT03001(sqlparser.String(aggr.Func)))
@@ -384,13 +412,12 @@ func (a *Aggregator) pushRemainingGroupingColumnsAndWeightStrings(ctx *planconte
 		a.Grouping[idx].WSOffset = offset
 	}
 	for idx, aggr := range a.Aggregations {
-		if aggr.WSOffset != -1 || !aggr.NeedsWeightString(ctx) {
-			continue
+		if aggr.WSOffset == -1 && aggr.NeedsWeightString(ctx) {
+			arg := aggr.getPushColumn()
+			offset := a.internalAddColumn(ctx, aeWrap(weightStringFor(arg)), false)
+			a.Aggregations[idx].WSOffset = offset
 		}
-
-		arg := aggr.getPushColumn()
-		offset := a.internalAddColumn(ctx, aeWrap(weightStringFor(arg)), false)
-		a.Aggregations[idx].WSOffset = offset
+		a.planExtraDistinctOffsets(ctx, idx, false)
 	}
 }
 
@@ -48,7 +48,12 @@ func translateQueryToOp(ctx *plancontext.PlanningContext, selStmt sqlparser.Stat
 }
 
 func createOperatorFromSelect(ctx *plancontext.PlanningContext, sel *sqlparser.Select) Operator {
+	// The straight_join hint only applies to the FROM clause of this SELECT,
+	// and not to the derived tables it might contain.
+	outerStraightJoin := ctx.StraightJoin
+	ctx.StraightJoin = sel.StraightJoinHint
 	op := crossJoin(ctx, sel.From)
+	ctx.StraightJoin = outerStraightJoin
 
 	if sel.Where != nil {
 		op = addWherePredicates(ctx, sel.Where.Expr, op)
@@ -227,6 +232,9 @@ func getOperatorFromJoinTableExpr(ctx *plancontext.PlanningContext, tableExpr *s
 
 	switch tableExpr.Join {
 	case sqlparser.NormalJoinType:
+		if ctx.StraightJoin {
+			return createStraightJoin(ctx, tableExpr, lhs, rhs)
+		}
 		return createInnerJoin(ctx, tableExpr, lhs, rhs)
 	case sqlparser.LeftJoinType, sqlparser.RightJoinType:
 		return createLeftOuterJoin(ctx, tableExpr, lhs, rhs)
@@ -288,9 +296,13 @@ func crossJoin(ctx *plancontext.PlanningContext, exprs sqlparser.TableExprs) Ope
 	var output Operator
 	for _, tableExpr := range exprs {
 		op := getOperatorFromTableExpr(ctx, tableExpr, len(exprs) == 1)
-		if output == nil {
+		switch {
+		case output == nil:
 			output = op
-		} else {
+		case ctx.StraightJoin:
+			// the tables have to be joined in the order they are listed
+			output = &Join{LHS: output, RHS: op, JoinType: sqlparser.StraightJoinType}
+		default:
 			output = createJoin(ctx, output, op)
 		}
 	}
@@ -42,6 +42,10 @@ type (
 		Columns []engine.CheckCol
 
 		Truncate int
+
+		// Ordered is set during offset planning when the rows of the source are
+		// sorted on the distinct columns.
+		Ordered bool
 	}
 )
 
@@ -64,9 +68,34 @@ func (d *Distinct) planOffsets(ctx *plancontext.PlanningContext) Operator {
 			CollationEnv: ctx.VSchema.Environment().CollationEnv(),
 		})
 	}
+	d.Ordered = orderedOn(ctx, d.Source.GetOrdering(ctx), columns)
 	return nil
 }
 
+// orderedOn returns true if rows sorted by the given ordering have the rows
+// that are equal on the given columns next to each other, that is if the
+// ordering starts with all the columns, in any order.
+func orderedOn(ctx *plancontext.PlanningContext, ordering []OrderBy, columns []*sqlparser.AliasedExpr) bool {
+	covered := make([]bool, len(columns))
+	left := len(columns)
+	for _, order := range ordering {
+		if left == 0 {
+			break
+		}
+		idx := slices.IndexFunc(columns, func(col *sqlparser.AliasedExpr) bool {
+			return ctx.SemTable.EqualsExprWithDeps(col.Expr, order.SimplifiedExpr)
+		})
+		if idx < 0 {
+			return false
+		}
+		if !covered[idx] {
+			covered[idx] = true
+			left--
+		}
+	}
+	return left == 0
+}
+
 func (d *Distinct) Clone(inputs []Operator) Operator {
 	return &Distinct{
 		Required:          d.Required,
@@ -75,6 +104,7 @@ func (d *Distinct) Clone(inputs []Operator) Operator {
 		QP:                d.QP,
 		PushedPerformance: d.PushedPerformance,
 		Truncate:          d.Truncate,
+		Ordered:           d.Ordered,
 	}
 }
 
@@ -87,7 +87,8 @@ func (j *Join) Compact(ctx *plancontext.PlanningContext) (Operator, *ApplyResult
 
 func createStraightJoin(ctx *plancontext.PlanningContext, join *sqlparser.JoinTableExpr, lhs, rhs Operator) Operator {
 	// for inner joins we can treat the predicates as filters on top of the join
-	joinOp := &Join{LHS: lhs, RHS: rhs, JoinType: join.Join}
+	// a normal join under the straight_join select hint is planned as a straight join too
+	joinOp := &Join{LHS: lhs, RHS: rhs, JoinType: sqlparser.StraightJoinType}
 
 	return addJoinPredicates(ctx, join.Condition.On, joinOp)
 }
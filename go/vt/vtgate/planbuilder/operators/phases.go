@@ -234,12 +234,12 @@ func addOrderingFor(aggrOp *Aggregator) {
 	orderBys := slice.Map(aggrOp.Grouping, func(from GroupBy) OrderBy {
 		return from.AsOrderBy()
 	})
-	if aggrOp.DistinctExpr != nil {
+	for _, expr := range aggrOp.DistinctExprs {
 		orderBys = append(orderBys, OrderBy{
 			Inner: &sqlparser.Order{
-				Expr: aggrOp.DistinctExpr,
+				Expr: expr,
 			},
-			SimplifiedExpr: aggrOp.DistinctExpr,
+			SimplifiedExpr: expr,
 		})
 	}
 	aggrOp.Source = &Ordering{
@@ -252,9 +252,7 @@ func needsOrdering(ctx *plancontext.PlanningContext, in *Aggregator) bool {
 	requiredOrder := slice.Map(in.Grouping, func(from GroupBy) sqlparser.Expr {
 		return from.Inner
 	})
-	if in.DistinctExpr != nil {
-		requiredOrder = append(requiredOrder, in.DistinctExpr)
-	}
+	requiredOrder = append(requiredOrder, in.DistinctExprs...)
 	if len(requiredOrder) == 0 {
 		return false
 	}
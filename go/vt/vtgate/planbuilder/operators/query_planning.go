@@ -221,11 +221,38 @@ func tryPushLimit(in *Limit) (Operator, *ApplyResult) {
 		return tryPushingDownLimitInRoute(in, src)
 	case *Aggregator:
 		return in, NoRewrite
+	case *Union:
+		return tryPushLimitUnderUnion(in, src)
 	default:
 		return setUpperLimit(in)
 	}
 }
 
+// tryPushLimitUnderUnion pushes the limit of a UNION ALL into each of its
+// sources, on top of the upper limit that is pushed into their routes. This
+// way, the sources that aren't a route, like joins, also stop producing rows
+// once the limit is reached when the results are streamed, and only the final
+// limit is applied to the concatenation of the sources.
+func tryPushLimitUnderUnion(in *Limit, src *Union) (Operator, *ApplyResult) {
+	if in.Pushed || src.distinct {
+		return setUpperLimit(in)
+	}
+	_, result := setUpperLimit(in)
+	for i, source := range src.Sources {
+		if _, isRoute := source.(*Route); isRoute {
+			// the upper limit is already part of the query of the route
+			continue
+		}
+		src.Sources[i] = &Limit{
+			Source: source,
+			AST:    &sqlparser.Limit{Rowcount: sqlparser.NewArgument("__upper_limit")},
+			Pushed: true,
+		}
+		result = result.Merge(Rewrote("push limit under union all"))
+	}
+	return in, result
+}
+
 func tryPushingDownLimitInRoute(in *Limit, src *Route) (Operator, *ApplyResult) {
 	if src.IsSingleShardOrByDestination() {
 		return Swap(in, src, "push limit under route")
@@ -87,9 +87,21 @@ type (
 		ColOffset int
 		WSOffset  int
 
+		// ExtraDistinct are the other expressions of a distinct aggregation on
+		// multiple expressions, like COUNT(DISTINCT a, b), and their offsets
+		ExtraDistinct []DistinctOffset
+
 		SubQueryExpression []*SubQuery
 	}
 
+	// DistinctOffset is an expression of a distinct aggregation, and the offsets
+	// of its value and weight string on the aggregator
+	DistinctOffset struct {
+		Expr      sqlparser.Expr
+		ColOffset int
+		WSOffset  int
+	}
+
 	AggrRewriter struct {
 		qp     *QueryProjection
 		st     *semantics.SemTable
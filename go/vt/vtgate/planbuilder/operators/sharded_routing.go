@@ -307,6 +307,10 @@ func (tr *ShardedRouting) planLikeOp(ctx *plancontext.PlanningContext, node *sql
 	if !ok {
 		return false
 	}
+	if node.Escape != nil {
+		// the prefix of the pattern is found assuming the default escape character
+		return false
+	}
 
 	vdValue := node.Right
 	val := makeEvalEngineExpr(ctx, vdValue)
@@ -315,8 +319,8 @@ func (tr *ShardedRouting) planLikeOp(ctx *plancontext.PlanningContext, node *sql
 	}
 	selectEqual := func(*vindexes.ColumnVindex) engine.Opcode { return engine.Equal }
 	vdx := func(vindex *vindexes.ColumnVindex) vindexes.Vindex {
-		if prefixable, ok := vindex.Vindex.(vindexes.Prefixable); ok {
-			return prefixable.PrefixVindex()
+		if prefixVindex := vindexes.LikePrefixVindex(vindex.Vindex); prefixVindex != nil {
+			return prefixVindex
 		}
 
 		// if we can't use the vindex as a prefix-vindex, we can't use this vindex at all
@@ -451,6 +455,9 @@ func (tr *ShardedRouting) processMultiColumnVindex(
 		if isPresent {
 			continue
 		}
+		if !canCombineMultiColumnOpcodes(op.OpCode, opcode(v.ColVindex)) {
+			continue
+		}
 		option := copyOption(op)
 		optionReady := option.updateWithNewColumn(colLoweredName, valueExpr, indexOfCol, value, node, v.ColVindex, opcode)
 		if optionReady {
@@ -471,6 +478,13 @@ func (tr *ShardedRouting) processMultiColumnVindex(
 	return newVindexFound
 }
 
+// canCombineMultiColumnOpcodes returns false when one column of a multi-column vindex comes
+// from an IN list and another from a tuple comparison. IN values are combined as a cross product
+// while tuple values are paired up row by row, so a single route cannot represent both.
+func canCombineMultiColumnOpcodes(a, b engine.Opcode) bool {
+	return !(a == engine.IN && b == engine.MultiEqual || a == engine.MultiEqual && b == engine.IN)
+}
+
 func (tr *ShardedRouting) getLoweredNameAndIndex(colVindex *vindexes.ColumnVindex, column *sqlparser.ColName) (string, int) {
 	colLoweredName := ""
 	indexOfCol := -1
@@ -282,6 +282,62 @@ func TestViews(t *testing.T) {
 	testFile(t, "view_cases.json", makeTestOutput(t), vschemaWrapper, false)
 }
 
+func TestSQLModeChecks(t *testing.T) {
+	lv := loadSchema(t, "vschemas/schema.json", true)
+	unsharded := lv.Keyspaces["main"].Tables["unsharded"]
+	unsharded.Columns = []vindexes.Column{
+		{Name: sqlparser.NewIdentifierCI("id"), Type: sqltypes.Int8},
+		{Name: sqlparser.NewIdentifierCI("name"), Type: sqltypes.VarChar, Size: 3},
+		{Name: sqlparser.NewIdentifierCI("created"), Type: sqltypes.Date},
+		{Name: sqlparser.NewIdentifierCI("description"), Type: sqltypes.Text},
+	}
+	unsharded.ColumnListAuthoritative = true
+	vschema := &vschemawrapper.VSchemaWrapper{
+		V:                lv,
+		Env:              vtenv.NewTestEnv(),
+		EnforcedSQLModes: []string{engine.SQLModeStrictTransTables},
+	}
+
+	checks := func(plan *engine.Plan) []string {
+		check, ok := plan.Instructions.(*engine.SQLModeCheck)
+		if !ok {
+			return nil
+		}
+		var checks []string
+		for _, c := range check.Checks {
+			checks = append(checks, fmt.Sprintf("%s %d %s", c.Column, c.Row, c.SQLMode))
+		}
+		return checks
+	}
+
+	plan, err := TestBuilder("insert into unsharded values (1, 'a', '2024-01-01', 'b'), (2, 'c', '0000-00-00', 'd')", vschema, "main")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id 1 STRICT_TRANS_TABLES", "name 1 STRICT_TRANS_TABLES", "id 2 STRICT_TRANS_TABLES", "name 2 STRICT_TRANS_TABLES"}, checks(plan))
+
+	plan, err = TestBuilder("insert into unsharded(created, name) values ('0000-00-00', 'abcd')", vschema, "main")
+	require.NoError(t, err)
+	require.Equal(t, []string{"name 1 STRICT_TRANS_TABLES"}, checks(plan))
+
+	// The sharded inserts are checked before their values are rewritten.
+	plan, err = TestBuilder("insert into user_extra(user_id, col) values (1, 100000)", vschema, "user")
+	require.NoError(t, err)
+	require.Equal(t, []string{"col 1 STRICT_TRANS_TABLES"}, checks(plan))
+
+	plan, err = TestBuilder("insert ignore into unsharded(id) values (1000)", vschema, "main")
+	require.NoError(t, err)
+	require.Nil(t, checks(plan))
+
+	vschema.EnforcedSQLModes = []string{engine.SQLModeNoZeroDate}
+	plan, err = TestBuilder("insert into unsharded(id, created) values (1, '0000-00-00')", vschema, "main")
+	require.NoError(t, err)
+	require.Equal(t, []string{"created 1 NO_ZERO_DATE"}, checks(plan))
+
+	vschema.EnforcedSQLModes = nil
+	plan, err = TestBuilder("insert into unsharded(id, created) values (1000, '0000-00-00')", vschema, "main")
+	require.NoError(t, err)
+	require.Nil(t, checks(plan))
+}
+
 func TestOne(t *testing.T) {
 	reset := operators.EnableDebugPrinting()
 	defer reset()
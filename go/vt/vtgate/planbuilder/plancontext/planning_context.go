@@ -57,6 +57,10 @@ type PlanningContext struct {
 
 	// Statement contains the originally parsed statement
 	Statement sqlparser.Statement
+
+	// StraightJoin is set while the FROM clause of a SELECT STRAIGHT_JOIN is turned into
+	// operators, so that its tables are joined in the order they are listed.
+	StraightJoin bool
 }
 
 // CreatePlanningContext initializes a new PlanningContext with the given parameters.
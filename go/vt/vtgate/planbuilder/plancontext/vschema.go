@@ -79,6 +79,15 @@ type VSchema interface {
 	// IsViewsEnabled returns true if Vitess manages the views.
 	IsViewsEnabled() bool
 
+	// SQLModeChecks returns the sql_mode checks of the inserted values that
+	// vtgate enforces itself.
+	SQLModeChecks() []string
+
+	// FindTrackedTable returns the schema of the table as tracked by vtgate,
+	// or nil if the table isn't tracked, or if SHOW COLUMNS and DESCRIBE
+	// must not be answered from the tracked schema.
+	FindTrackedTable(keyspace, table string) *vindexes.TableInfo
+
 	// GetUDV returns user defined value from the variable passed.
 	GetUDV(name string) *querypb.BindVariable
 
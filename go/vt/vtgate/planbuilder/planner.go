@@ -46,13 +46,11 @@ func gen4Planner(query string, plannerVersion querypb.ExecuteOptions_PlannerVers
 
 // setCommentDirectivesOnPlan adds comments to queries
 func setCommentDirectivesOnPlan(plan logicalPlan, stmt sqlparser.Statement) {
-	var directives *sqlparser.CommentDirectives
-	cmt, ok := stmt.(sqlparser.Commented)
-	if !ok {
+	if _, ok := stmt.(sqlparser.Commented); !ok {
 		return
 	}
 
-	directives = cmt.GetParsedComments().Directives()
+	directives := sqlparser.QueryDirectives(stmt)
 	scatterAsWarns := directives.IsSet(sqlparser.DirectiveScatterErrorsAsWarnings)
 	timeout := queryTimeout(directives)
 	multiShardAutoCommit := directives.IsSet(sqlparser.DirectiveMultiShardAutocommit)
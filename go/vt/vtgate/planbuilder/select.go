@@ -18,6 +18,7 @@ package planbuilder
 
 import (
 	"fmt"
+	"strings"
 
 	"vitess.io/vitess/go/vt/key"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -76,11 +77,17 @@ func gen4SelectStmtPlanner(
 		// TODO: this should move to the operator side of planning
 		plan2, tablesUsed := gen4PredicateRewrite(stmt, getPlan)
 		if plan2 != nil {
+			if err := checkLockModifiers(stmt, plan2.Primitive()); err != nil {
+				return nil, err
+			}
 			return newPlanResult(plan2.Primitive(), tablesUsed...), nil
 		}
 	}
 
 	primitive := plan.Primitive()
+	if err := checkLockModifiers(stmt, primitive); err != nil {
+		return nil, err
+	}
 	if !isSel {
 		return newPlanResult(primitive, tablesUsed...), nil
 	}
@@ -100,6 +107,69 @@ func gen4SelectStmtPlanner(
 	return newPlanResult(primitive, tablesUsed...), nil
 }
 
+// checkLockModifiers fails the locking reads with NOWAIT or SKIP LOCKED that
+// are not sent to a single shard. The modifiers are pushed down to the shard
+// as is, which makes job queues work through Vitess, but across shards the
+// rows that are locked or skipped by each shard would not add up to what the
+// query asked for: a NOWAIT query could fail after locking the rows of some
+// shards, and a SKIP LOCKED query with a limit could skip more rows than
+// needed on some shards.
+func checkLockModifiers(stmt sqlparser.SelectStatement, primitive engine.Primitive) error {
+	lock := stmt.GetLock()
+	switch lock {
+	case sqlparser.ForUpdateLockNoWait, sqlparser.ForUpdateLockSkipLocked, sqlparser.ForShareLockNoWait, sqlparser.ForShareLockSkipLocked:
+	default:
+		return nil
+	}
+	// the primitives evaluated by vtgate on top of a single route don't lock
+	// anything by themselves.
+	for unwrapped := false; !unwrapped; {
+		switch prim := primitive.(type) {
+		case *engine.Limit:
+			primitive = prim.Input
+		case *engine.Projection:
+			primitive = prim.Input
+		case *engine.SimpleProjection:
+			primitive = prim.Input
+		default:
+			unwrapped = true
+		}
+	}
+	modifier := strings.TrimSpace(lock.ToString())
+	switch prim := primitive.(type) {
+	case *engine.Route:
+		if prim.Opcode.IsSingleShard() || prim.Opcode == engine.None {
+			return nil
+		}
+		return vterrors.VT12001(fmt.Sprintf("%s on %s, it must be routed to a single shard", modifier, describeMultiShardRoute(prim.Opcode)))
+	case *engine.VindexLookup:
+		if prim.Opcode.IsSingleShard() {
+			return nil
+		}
+		return vterrors.VT12001(fmt.Sprintf("%s on %s, it must be routed to a single shard", modifier, describeMultiShardRoute(prim.Opcode)))
+	}
+	return vterrors.VT12001(fmt.Sprintf("%s on a cross-shard query, it must be routed to a single shard", modifier))
+}
+
+// describeMultiShardRoute describes why a query with the given opcode can be
+// sent to more than one shard.
+func describeMultiShardRoute(opcode engine.Opcode) string {
+	switch opcode {
+	case engine.Scatter:
+		return "a scatter query"
+	case engine.Equal:
+		return "a query routed by a non-unique vindex"
+	case engine.IN, engine.MultiEqual:
+		return "a query routed by several vindex values"
+	case engine.SubShard:
+		return "a query routed to a range of shards"
+	case engine.ByDestination:
+		return "a query targeting several shards"
+	default:
+		return "a cross-shard query"
+	}
+}
+
 func gen4planSQLCalcFoundRows(vschema plancontext.VSchema, sel *sqlparser.Select, query string, reservedVars *sqlparser.ReservedVars) (*planResult, error) {
 	ksName := ""
 	if ks, _ := vschema.DefaultKeyspace(); ks != nil {
@@ -298,6 +368,16 @@ func handleDualSelects(sel *sqlparser.Select, vschema plancontext.VSchema) (engi
 				}
 				elem.Name = n
 			}
+			if lFunc.Timeout != nil {
+				t, err := evalengine.Translate(lFunc.Timeout, &evalengine.Config{
+					Collation:   vschema.ConnCollation(),
+					Environment: vschema.Environment(),
+				})
+				if err != nil {
+					return nil, err
+				}
+				elem.Timeout = t
+			}
 			lockFunctions = append(lockFunctions, elem)
 			continue
 		}
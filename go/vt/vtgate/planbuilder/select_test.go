@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func TestCheckLockModifiers(t *testing.T) {
+	ks := &vindexes.Keyspace{Name: "ks", Sharded: true}
+	stmt, err := sqlparser.NewTestParser().Parse("select id from user where id = 1 for update nowait")
+	require.NoError(t, err)
+	sel := stmt.(*sqlparser.Select)
+
+	// a route to a single shard wrapped by primitives evaluated by vtgate
+	single := engine.NewRoute(engine.EqualUnique, ks, "", "")
+	assert.NoError(t, checkLockModifiers(sel, &engine.Limit{Input: &engine.Projection{Input: single}}))
+
+	scatter := engine.NewRoute(engine.Scatter, ks, "", "")
+	err = checkLockModifiers(sel, &engine.Limit{Input: scatter})
+	assert.EqualError(t, err, "VT12001: unsupported: for update nowait on a scatter query, it must be routed to a single shard")
+
+	err = checkLockModifiers(sel, &engine.Join{Left: single, Right: single})
+	assert.EqualError(t, err, "VT12001: unsupported: for update nowait on a cross-shard query, it must be routed to a single shard")
+}
@@ -83,6 +83,19 @@ func buildSetPlan(stmt *sqlparser.Set, vschema plancontext.VSchema) (*planResult
 			}
 			setOps = append(setOps, setOp)
 		case sqlparser.NextTxScope, sqlparser.SessionScope:
+			if isNextTxIsolation(expr) {
+				// The isolation level of the next transaction is kept in the session, and
+				// sent to the tablets with the queries of the transaction.
+				evalExpr, err := ec.convert(expr.Expr, false /*boolean*/, true /*identifierAsString*/)
+				if err != nil {
+					return nil, err
+				}
+				setOps = append(setOps, &engine.NextTxIsolation{
+					Name: expr.Var.Name.Lowered(),
+					Expr: evalExpr,
+				})
+				continue
+			}
 			planFunc, err := sysvarPlanningFuncs.Get(vschema.Environment(), expr)
 			if err != nil {
 				return nil, err
@@ -126,6 +139,16 @@ func buildSetPlan(stmt *sqlparser.Set, vschema plancontext.VSchema) (*planResult
 	}), nil
 }
 
+// isNextTxIsolation returns whether the expression sets the isolation level of the next
+// transaction, as SET TRANSACTION ISOLATION LEVEL and SET @@transaction_isolation do.
+func isNextTxIsolation(expr *sqlparser.SetExpr) bool {
+	if expr.Var.Scope != sqlparser.NextTxScope {
+		return false
+	}
+	name := expr.Var.Name.Lowered()
+	return name == sysvars.TransactionIsolation.Name || name == sysvars.TxIsolation.Name
+}
+
 func buildSetOpReadOnly(setting) planFunc {
 	return func(expr *sqlparser.SetExpr, schema plancontext.VSchema, _ *expressionConverter) (engine.SetOp, error) {
 		return nil, vterrors.VT03010(expr.Var.Name)
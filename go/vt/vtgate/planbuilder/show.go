@@ -35,6 +35,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	popcode "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
@@ -108,11 +109,13 @@ func buildShowBasicPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema)
 		return buildPluginsPlan()
 	case sqlparser.Engines:
 		return buildEnginesPlan()
-	case sqlparser.VitessReplicationStatus, sqlparser.VitessShards, sqlparser.VitessTablets, sqlparser.VitessVariables:
+	case sqlparser.VitessReplicationStatus, sqlparser.VitessShards, sqlparser.VitessTablets, sqlparser.VitessTransactionStatus, sqlparser.VitessVariables, sqlparser.VitessPlans:
 		return &engine.ShowExec{
 			Command:    show.Command,
 			ShowFilter: show.Filter,
 		}, nil
+	case sqlparser.VitessTableStatus:
+		return buildShowVitessTableStatusPlan(show, vschema)
 	case sqlparser.VitessTarget:
 		return buildShowTargetPlan(vschema)
 	case sqlparser.VschemaTables:
@@ -162,8 +165,13 @@ func buildVariablePlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (
 	if err != nil {
 		return nil, err
 	}
-	plan = engine.NewReplaceVariables(plan)
-	return plan, nil
+	rv := engine.NewReplaceVariables(plan)
+	rv.Global = show.Command == sqlparser.VariableGlobal
+	if show.Filter != nil {
+		rv.Like = show.Filter.Like
+		rv.HasWhere = show.Filter.Filter != nil
+	}
+	return rv, nil
 }
 
 func buildShowTblPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (engine.Primitive, error) {
@@ -198,6 +206,17 @@ func buildShowTblPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (e
 			dest = destination
 		}
 		ks = table.Keyspace
+
+		// A filter on the other columns than the field name is left to the tablet.
+		if show.Command == sqlparser.Column && !show.Full && destination == nil && (show.Filter == nil || show.Filter.Filter == nil) {
+			var filter *regexp.Regexp
+			if show.Filter != nil {
+				filter = sqlparser.LikeToRegexp(show.Filter.Like)
+			}
+			if prim := trackedColumnsPlan(vschema, ks, table.Name.String(), filter); prim != nil {
+				return prim, nil
+			}
+		}
 	}
 
 	return &engine.Send{
@@ -487,6 +506,7 @@ func buildCreateDbPlan(show *sqlparser.ShowCreate, vschema plancontext.VSchema)
 func buildCreateTblPlan(show *sqlparser.ShowCreate, vschema plancontext.VSchema) (engine.Primitive, error) {
 	dest := key.Destination(key.DestinationAnyShard{})
 	var ks *vindexes.Keyspace
+	var annotation string
 	var err error
 
 	if show.Op.Qualifier.NotEmpty() && sqlparser.SystemSchema(show.Op.Qualifier.String()) {
@@ -508,16 +528,80 @@ func buildCreateTblPlan(show *sqlparser.ShowCreate, vschema plancontext.VSchema)
 		}
 		show.Op.Qualifier = sqlparser.NewIdentifierCS("")
 		show.Op.Name = tbl.Name
+		annotation = vschemaAnnotation(tbl)
 	}
 
-	return &engine.Send{
+	send := &engine.Send{
 		Keyspace:          ks,
 		TargetDestination: dest,
 		Query:             sqlparser.String(show),
 		IsDML:             false,
 		SingleShardOnly:   true,
+	}
+	if annotation == "" {
+		return send, nil
+	}
+
+	// The definition of the table on the shard is annotated with its vschema.
+	cfg := &evalengine.Config{
+		Collation:   vschema.ConnCollation(),
+		Environment: vschema.Environment(),
+	}
+	tableExpr, err := evalengine.Translate(sqlparser.NewOffset(0, sqlparser.NewColName("Table")), cfg)
+	if err != nil {
+		return nil, err
+	}
+	createExpr, err := evalengine.Translate(&sqlparser.FuncExpr{
+		Name:  sqlparser.NewIdentifierCI("concat"),
+		Exprs: sqlparser.Exprs{sqlparser.NewOffset(1, sqlparser.NewColName("Create Table")), sqlparser.NewStrLiteral(annotation)},
+	}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &engine.Projection{
+		Cols:  []string{"Table", "Create Table"},
+		Exprs: []evalengine.Expr{tableExpr, createExpr},
+		Input: send,
 	}, nil
+}
 
+// vschemaAnnotation returns the comments that describe the vschema of the table,
+// which are appended to its definition by SHOW CREATE TABLE.
+func vschemaAnnotation(tbl *vindexes.Table) string {
+	var buf strings.Builder
+	annotate := func(format string, args ...any) {
+		buf.WriteString("\n/* vschema: ")
+		fmt.Fprintf(&buf, format, args...)
+		buf.WriteString(" */")
+	}
+	switch tbl.Type {
+	case vindexes.TypeSequence:
+		annotate("sequence")
+	case vindexes.TypeReference:
+		if tbl.Source != nil {
+			annotate("reference to %s", sqlparser.String(tbl.Source.TableName))
+		} else {
+			annotate("reference")
+		}
+	}
+	for i, cv := range tbl.ColumnVindexes {
+		var opts []string
+		if i == 0 {
+			opts = append(opts, "primary")
+		}
+		if cv.Owned {
+			opts = append(opts, "owned")
+		}
+		opt := ""
+		if len(opts) > 0 {
+			opt = ", " + strings.Join(opts, ", ")
+		}
+		annotate("vindex %s %s using %s%s", sqlparser.String(sqlparser.NewIdentifierCS(cv.Name)), sqlparser.String(sqlparser.Columns(cv.Columns)), cv.Type, opt)
+	}
+	if tbl.AutoIncrement != nil && tbl.AutoIncrement.Sequence != nil {
+		annotate("auto_increment %s using sequence %s", sqlparser.String(tbl.AutoIncrement.Column), sqlparser.String(tbl.AutoIncrement.Sequence.GetTableName()))
+	}
+	return buf.String()
 }
 
 func buildCreatePlan(show *sqlparser.ShowCreate, vschema plancontext.VSchema) (engine.Primitive, error) {
@@ -590,6 +674,40 @@ func buildShowGtidPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (
 	}, nil
 }
 
+// buildShowVitessTableStatusPlan builds the plan for SHOW VITESS_TABLE_STATUS, which
+// aggregates the status of a table across all the shards of its keyspace.
+func buildShowVitessTableStatusPlan(show *sqlparser.ShowBasic, vschema plancontext.VSchema) (engine.Primitive, error) {
+	table, _, _, _, err := vschema.FindTable(show.Tbl)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, vterrors.VT05004(show.Tbl.Name.String())
+	}
+
+	query := fmt.Sprintf("select table_name as `Name`, 1 as `Shards`, table_rows as `Rows`, data_length as `Data_length`, "+
+		"index_length as `Index_length`, data_free as `Data_free`, auto_increment as `Auto_increment` "+
+		"from information_schema.`tables` where table_schema = database() and table_name = %s",
+		sqlparser.String(sqlparser.NewStrLiteral(table.Name.String())))
+	collationEnv := vschema.Environment().CollationEnv()
+	return &engine.ScalarAggregate{
+		Aggregates: []*engine.AggregateParams{
+			engine.NewAggregateParam(popcode.AggregateAnyValue, 0, "Name", collationEnv),
+			engine.NewAggregateParam(popcode.AggregateCountStar, 1, "Shards", collationEnv),
+			engine.NewAggregateParam(popcode.AggregateSum, 2, "Rows", collationEnv),
+			engine.NewAggregateParam(popcode.AggregateSum, 3, "Data_length", collationEnv),
+			engine.NewAggregateParam(popcode.AggregateSum, 4, "Index_length", collationEnv),
+			engine.NewAggregateParam(popcode.AggregateSum, 5, "Data_free", collationEnv),
+			engine.NewAggregateParam(popcode.AggregateMax, 6, "Auto_increment", collationEnv),
+		},
+		Input: &engine.Send{
+			Keyspace:          table.Keyspace,
+			TargetDestination: key.DestinationAllShards{},
+			Query:             query,
+		},
+	}, nil
+}
+
 func buildWarnings() (engine.Primitive, error) {
 
 	f := func(sa engine.SessionActions) (*sqltypes.Result, error) {
@@ -767,3 +885,174 @@ func buildVschemaVindexesPlan(show *sqlparser.ShowBasic, vschema plancontext.VSc
 	), nil
 
 }
+
+// trackedColumnsPlan returns a plan that answers SHOW COLUMNS for the table
+// from its schema tracked by vtgate, or nil if the table isn't tracked, in
+// which case the query is sent to a tablet.
+func trackedColumnsPlan(vschema plancontext.VSchema, ks *vindexes.Keyspace, tableName string, filter *regexp.Regexp) engine.Primitive {
+	tbl := vschema.FindTrackedTable(ks.Name, tableName)
+	if tbl == nil || len(tbl.ColumnDefinitions) == 0 {
+		return nil
+	}
+
+	fields := buildVarCharFields("Field", "Type", "Null", "Key", "Default", "Extra")
+	// The default of a column can be NULL.
+	fields[4].Flags = 0
+
+	keys := trackedColumnKeys(tbl)
+	var rows [][]sqltypes.Value
+	for _, col := range tbl.ColumnDefinitions {
+		name := col.Name.String()
+		if filter != nil && !filter.MatchString(name) {
+			continue
+		}
+		null := "YES"
+		if keys[col.Name.Lowered()] == "PRI" || (col.Type.Options != nil && col.Type.Options.Null != nil && !*col.Type.Options.Null) {
+			null = "NO"
+		}
+		rows = append(rows, []sqltypes.Value{
+			sqltypes.NewVarChar(name),
+			sqltypes.NewVarChar(trackedColumnType(col.Type)),
+			sqltypes.NewVarChar(null),
+			sqltypes.NewVarChar(keys[col.Name.Lowered()]),
+			trackedColumnDefault(col.Type.Options),
+			sqltypes.NewVarChar(trackedColumnExtra(col.Type.Options)),
+		})
+	}
+	return &engine.TrackedColumns{
+		Keyspace:  ks,
+		Table:     tableName,
+		TrackedAt: tbl.TrackedAt,
+		Fields:    fields,
+		Rows:      rows,
+	}
+}
+
+// trackedColumnKeys returns the Key column of SHOW COLUMNS by lowered column
+// name. Like MySQL, PRI takes precedence over UNI, which takes precedence over MUL.
+func trackedColumnKeys(tbl *vindexes.TableInfo) map[string]string {
+	keys := make(map[string]string)
+	rank := map[string]int{"": 0, "MUL": 1, "UNI": 2, "PRI": 3}
+	setKey := func(col, key string) {
+		if rank[key] > rank[keys[col]] {
+			keys[col] = key
+		}
+	}
+	for _, idx := range tbl.Indexes {
+		if len(idx.Columns) == 0 || idx.Columns[0].Expression != nil {
+			continue
+		}
+		switch {
+		case idx.Info.Type == sqlparser.IndexTypePrimary:
+			for _, col := range idx.Columns {
+				setKey(col.Column.Lowered(), "PRI")
+			}
+		case idx.Info.Type == sqlparser.IndexTypeUnique && len(idx.Columns) == 1:
+			setKey(idx.Columns[0].Column.Lowered(), "UNI")
+		default:
+			setKey(idx.Columns[0].Column.Lowered(), "MUL")
+		}
+	}
+	for _, col := range tbl.ColumnDefinitions {
+		if col.Type.Options == nil {
+			continue
+		}
+		switch col.Type.Options.KeyOpt {
+		case sqlparser.ColKeyPrimary:
+			setKey(col.Name.Lowered(), "PRI")
+		case sqlparser.ColKeyUnique, sqlparser.ColKeyUniqueKey:
+			setKey(col.Name.Lowered(), "UNI")
+		case sqlparser.ColKey, sqlparser.ColKeySpatialKey, sqlparser.ColKeyFulltextKey:
+			setKey(col.Name.Lowered(), "MUL")
+		}
+	}
+	return keys
+}
+
+// trackedColumnType returns the Type column of SHOW COLUMNS.
+func trackedColumnType(ct *sqlparser.ColumnType) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(ct.Type))
+	switch {
+	case ct.Length != nil && ct.Scale != nil:
+		fmt.Fprintf(&b, "(%d,%d)", *ct.Length, *ct.Scale)
+	case ct.Length != nil:
+		fmt.Fprintf(&b, "(%d)", *ct.Length)
+	case len(ct.EnumValues) > 0:
+		fmt.Fprintf(&b, "(%s)", strings.Join(ct.EnumValues, ","))
+	}
+	if ct.Unsigned {
+		b.WriteString(" unsigned")
+	}
+	if ct.Zerofill {
+		b.WriteString(" zerofill")
+	}
+	return b.String()
+}
+
+// trackedColumnDefault returns the Default column of SHOW COLUMNS.
+func trackedColumnDefault(opts *sqlparser.ColumnTypeOptions) sqltypes.Value {
+	if opts == nil || opts.Default == nil {
+		return sqltypes.NULL
+	}
+	switch def := opts.Default.(type) {
+	case *sqlparser.NullVal:
+		return sqltypes.NULL
+	case *sqlparser.Literal:
+		if opts.DefaultLiteral {
+			return sqltypes.NewVarChar(def.Val)
+		}
+	}
+	return sqltypes.NewVarChar(trackedColumnExpr(opts.Default))
+}
+
+// trackedColumnDefaultGenerated returns whether the default of the column is
+// an expression, e.g. CURRENT_TIMESTAMP, which MySQL shows as DEFAULT_GENERATED.
+func trackedColumnDefaultGenerated(opts *sqlparser.ColumnTypeOptions) bool {
+	switch opts.Default.(type) {
+	case nil, *sqlparser.NullVal:
+		return false
+	case *sqlparser.CurTimeFuncExpr:
+		return true
+	}
+	return !opts.DefaultLiteral
+}
+
+// trackedColumnExpr returns the default or on update expression of a column
+// as MySQL shows it, i.e. with CURRENT_TIMESTAMP and its synonyms as
+// CURRENT_TIMESTAMP.
+func trackedColumnExpr(expr sqlparser.Expr) string {
+	ts, ok := expr.(*sqlparser.CurTimeFuncExpr)
+	if !ok {
+		return sqlparser.String(expr)
+	}
+	if ts.Fsp > 0 {
+		return fmt.Sprintf("CURRENT_TIMESTAMP(%d)", ts.Fsp)
+	}
+	return "CURRENT_TIMESTAMP"
+}
+
+// trackedColumnExtra returns the Extra column of SHOW COLUMNS.
+func trackedColumnExtra(opts *sqlparser.ColumnTypeOptions) string {
+	if opts == nil {
+		return ""
+	}
+	var extra []string
+	if opts.Autoincrement {
+		extra = append(extra, "auto_increment")
+	}
+	if trackedColumnDefaultGenerated(opts) {
+		extra = append(extra, "DEFAULT_GENERATED")
+	}
+	if opts.OnUpdate != nil {
+		extra = append(extra, "on update "+trackedColumnExpr(opts.OnUpdate))
+	}
+	if opts.As != nil {
+		if opts.Storage == sqlparser.StoredStorage {
+			extra = append(extra, "STORED GENERATED")
+		} else {
+			extra = append(extra, "VIRTUAL GENERATED")
+		}
+	}
+	return strings.Join(extra, " ")
+}
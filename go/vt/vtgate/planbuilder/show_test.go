@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"vitess.io/vitess/go/test/vschemawrapper"
 	"vitess.io/vitess/go/vt/vtenv"
@@ -30,6 +31,7 @@ import (
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
 
@@ -122,3 +124,96 @@ func TestGenerateCharsetRows(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildShowColumnsFromTrackedSchema(t *testing.T) {
+	env := vtenv.NewTestEnv()
+	stmt, err := env.Parser().Parse("create table `user`(" +
+		"id bigint unsigned not null auto_increment, " +
+		"name varchar(64) not null default 'x', " +
+		"email varchar(255), " +
+		"status enum('a','b') default null, " +
+		"price decimal(10,2), " +
+		"updated timestamp default current_timestamp on update current_timestamp, " +
+		"ux int, " +
+		"created datetime(3) default current_timestamp(3), " +
+		"seed double default (rand()), " +
+		"primary key(id), unique key(email), key(name, ux))")
+	require.NoError(t, err)
+	ddl := stmt.(*sqlparser.CreateTable)
+	trackedAt := time.Now().Add(-time.Minute)
+	vschema := &vschemawrapper.VSchemaWrapper{
+		V:   loadSchema(t, "vschemas/schema.json", true),
+		Env: env,
+		TrackedTables: map[string]map[string]*vindexes.TableInfo{
+			"user": {"user": {
+				Indexes:           ddl.TableSpec.Indexes,
+				ColumnDefinitions: ddl.TableSpec.Columns,
+				TrackedAt:         trackedAt,
+			}},
+		},
+	}
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{{
+		query: "show columns from user",
+		expected: `[[VARCHAR("id") VARCHAR("bigint unsigned") VARCHAR("NO") VARCHAR("PRI") NULL VARCHAR("auto_increment")] ` +
+			`[VARCHAR("name") VARCHAR("varchar(64)") VARCHAR("NO") VARCHAR("MUL") VARCHAR("x") VARCHAR("")] ` +
+			`[VARCHAR("email") VARCHAR("varchar(255)") VARCHAR("YES") VARCHAR("UNI") NULL VARCHAR("")] ` +
+			`[VARCHAR("status") VARCHAR("enum('a','b')") VARCHAR("YES") VARCHAR("") NULL VARCHAR("")] ` +
+			`[VARCHAR("price") VARCHAR("decimal(10,2)") VARCHAR("YES") VARCHAR("") NULL VARCHAR("")] ` +
+			`[VARCHAR("updated") VARCHAR("timestamp") VARCHAR("YES") VARCHAR("") VARCHAR("CURRENT_TIMESTAMP") VARCHAR("DEFAULT_GENERATED on update CURRENT_TIMESTAMP")] ` +
+			`[VARCHAR("ux") VARCHAR("int") VARCHAR("YES") VARCHAR("") NULL VARCHAR("")] ` +
+			`[VARCHAR("created") VARCHAR("datetime(3)") VARCHAR("YES") VARCHAR("") VARCHAR("CURRENT_TIMESTAMP(3)") VARCHAR("DEFAULT_GENERATED")] ` +
+			`[VARCHAR("seed") VARCHAR("double") VARCHAR("YES") VARCHAR("") VARCHAR("rand()") VARCHAR("DEFAULT_GENERATED")]]`,
+	}, {
+		query:    "show columns from user like 'u%'",
+		expected: `[[VARCHAR("updated") VARCHAR("timestamp") VARCHAR("YES") VARCHAR("") VARCHAR("CURRENT_TIMESTAMP") VARCHAR("DEFAULT_GENERATED on update CURRENT_TIMESTAMP")] [VARCHAR("ux") VARCHAR("int") VARCHAR("YES") VARCHAR("") NULL VARCHAR("")]]`,
+	}, {
+		query:    "describe user 'e%'",
+		expected: `[[VARCHAR("email") VARCHAR("varchar(255)") VARCHAR("YES") VARCHAR("UNI") NULL VARCHAR("")]]`,
+	}, {
+		query:    "describe user ux",
+		expected: `[[VARCHAR("ux") VARCHAR("int") VARCHAR("YES") VARCHAR("") NULL VARCHAR("")]]`,
+	}}
+	for _, tcase := range testCases {
+		t.Run(tcase.query, func(t *testing.T) {
+			stmt, err := env.Parser().Parse(tcase.query)
+			require.NoError(t, err)
+
+			var primitive engine.Primitive
+			switch stmt := stmt.(type) {
+			case *sqlparser.Show:
+				primitive, err = buildShowTblPlan(stmt.Internal.(*sqlparser.ShowBasic), vschema)
+			case *sqlparser.ExplainTab:
+				var res *planResult
+				res, err = explainTabPlan(stmt, vschema)
+				if res != nil {
+					primitive = res.primitive
+				}
+			}
+			require.NoError(t, err)
+			require.IsType(t, &engine.TrackedColumns{}, primitive)
+			tc := primitive.(*engine.TrackedColumns)
+			require.Equal(t, tcase.expected, fmt.Sprintf("%v", tc.Rows))
+			require.Len(t, tc.Fields, 6)
+			require.Equal(t, trackedAt, tc.TrackedAt)
+		})
+	}
+
+	// The queries that the tracked schema can't answer are sent to a tablet.
+	for _, query := range []string{
+		"show full columns from user",
+		"show columns from user where `Key` = 'PRI'",
+		"show columns from music",
+	} {
+		t.Run(query, func(t *testing.T) {
+			stmt, err := env.Parser().Parse(query)
+			require.NoError(t, err)
+			primitive, err := buildShowTblPlan(stmt.(*sqlparser.Show).Internal.(*sqlparser.ShowBasic), vschema)
+			require.NoError(t, err)
+			require.IsType(t, &engine.Send{}, primitive)
+		})
+	}
+}
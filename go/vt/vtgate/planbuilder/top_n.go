@@ -0,0 +1,38 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+var _ logicalPlan = (*topN)(nil)
+
+// topN is the logicalPlan for engine.TopN.
+// This gets built instead of a limit over a memorySort,
+// when the rows returned from an underlying operation
+// need to be both sorted and limited in memory.
+type topN struct {
+	resultsBuilder
+	eTopN *engine.TopN
+}
+
+// Primitive implements the logicalPlan interface
+func (t *topN) Primitive() engine.Primitive {
+	t.eTopN.Input = t.input.Primitive()
+	return t.eTopN
+}
@@ -19,6 +19,7 @@ package planbuilder
 import (
 	"context"
 	"encoding/json"
+	"regexp"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
@@ -42,12 +43,20 @@ func buildVExplainPlan(ctx context.Context, vexplainStmt *sqlparser.VExplainStmt
 }
 
 func explainTabPlan(explain *sqlparser.ExplainTab, vschema plancontext.VSchema) (*planResult, error) {
-	_, _, ks, _, destination, err := vschema.FindTableOrVindex(explain.Table)
+	table, _, ks, _, destination, err := vschema.FindTableOrVindex(explain.Table)
 	if err != nil {
 		return nil, err
 	}
 	explain.Table.Qualifier = sqlparser.NewIdentifierCS("")
 
+	if table != nil && destination == nil {
+		if filter, ok := explainTabFilter(explain.Wild, vschema); ok {
+			if prim := trackedColumnsPlan(vschema, table.Keyspace, table.Name.String(), filter); prim != nil {
+				return newPlanResult(prim, singleTable(table.Keyspace.Name, table.Name.String())), nil
+			}
+		}
+	}
+
 	if destination == nil {
 		destination = key.DestinationAnyShard{}
 	}
@@ -68,6 +77,26 @@ func explainTabPlan(explain *sqlparser.ExplainTab, vschema plancontext.VSchema)
 	}, singleTable(keyspace.Name, explain.Table.Name.String())), nil
 }
 
+// explainTabFilter returns the column name filter of DESCRIBE, which is
+// either a column name or a quoted pattern, and false if it can't be turned
+// into one.
+func explainTabFilter(wild string, vschema plancontext.VSchema) (*regexp.Regexp, bool) {
+	if wild == "" {
+		return nil, true
+	}
+	expr, err := vschema.Environment().Parser().ParseExpr(wild)
+	if err != nil {
+		return nil, false
+	}
+	switch expr := expr.(type) {
+	case *sqlparser.Literal:
+		return sqlparser.LikeToRegexp(expr.Val), true
+	case *sqlparser.ColName:
+		return sqlparser.LikeToRegexp(expr.Name.String()), true
+	}
+	return nil, false
+}
+
 func buildVExplainVtgatePlan(ctx context.Context, explainStatement sqlparser.Statement, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema, enableOnlineDDL, enableDirectDDL bool) (*planResult, error) {
 	innerInstruction, err := createInstructionFor(ctx, sqlparser.String(explainStatement), explainStatement, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
 	if err != nil {
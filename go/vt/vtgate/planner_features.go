@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"fmt"
+	"sort"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+// The planner features of a keyspace, named after their vschema fields.
+const (
+	featureDisallowScatter            = "disallow_scatter"
+	featureDisallowCrossKeyspaceJoins = "disallow_cross_keyspace_joins"
+	featureRequireVindexPredicate     = "require_vindex_predicate"
+)
+
+var plannerFeatureDenials = stats.NewCountersWithMultiLabels("PlannerFeatureDenials", "Queries whose plans were disallowed by the planner features of a keyspace", []string{"Keyspace", "Feature"})
+
+// checkPlannerFeatures returns an error if the plan is disallowed by the
+// planner features of one of the keyspaces it routes to.
+func checkPlannerFeatures(vschema *vindexes.VSchema, stmt sqlparser.Statement, plan *engine.Plan) error {
+	if vschema == nil || plan.Instructions == nil {
+		return nil
+	}
+	pf := &plannerFeatures{vschema: vschema, allowScatter: sqlparser.AllowScatterDirective(stmt)}
+	engine.Find(func(node engine.Primitive) bool {
+		switch node := node.(type) {
+		case *engine.Route:
+			pf.checkRouting(node.RoutingParameters, node.GetTableName(), true /* isRead */)
+		case *engine.Update:
+			if node.DML != nil {
+				pf.checkRouting(node.RoutingParameters, node.GetTableName(), false /* isRead */)
+			}
+		case *engine.Delete:
+			if node.DML != nil {
+				pf.checkRouting(node.RoutingParameters, node.GetTableName(), false /* isRead */)
+			}
+		case *engine.Join:
+			pf.checkJoin("a join", node.Left, node.Right)
+		case *engine.HashJoin:
+			pf.checkJoin("a join", node.Left, node.Right)
+		case *engine.SemiJoin:
+			pf.checkJoin("a join", node.Left, node.Right)
+		case *engine.UncorrelatedSubquery:
+			pf.checkJoin("a subquery", node.Outer, node.Subquery)
+		case *engine.Concatenate:
+			pf.checkJoin("a union", node.Sources...)
+		}
+		return pf.err != nil
+	}, plan.Instructions)
+	return pf.err
+}
+
+// plannerFeatures checks the primitives of a plan against the planner
+// features of their keyspaces, and keeps the first denial.
+type plannerFeatures struct {
+	vschema      *vindexes.VSchema
+	allowScatter bool
+	err          error
+}
+
+func (pf *plannerFeatures) features(keyspace string) *vschemapb.PlannerFeatures {
+	ks := pf.vschema.Keyspaces[keyspace]
+	if ks == nil {
+		return nil
+	}
+	return ks.PlannerFeatures
+}
+
+func (pf *plannerFeatures) deny(keyspace, feature, what string) {
+	plannerFeatureDenials.Add([]string{keyspace, feature}, 1)
+	pf.err = vterrors.VT09027(what, feature, keyspace)
+}
+
+// checkRouting denies the scatter reads, unless the query has the
+// ALLOW_SCATTER directive, and the reads and writes that aren't routed by
+// a vindex.
+func (pf *plannerFeatures) checkRouting(rp *engine.RoutingParameters, table string, isRead bool) {
+	if rp == nil || rp.Keyspace == nil || rp.Opcode != engine.Scatter {
+		return
+	}
+	features := pf.features(rp.Keyspace.Name)
+	switch {
+	case features.GetRequireVindexPredicate():
+		pf.deny(rp.Keyspace.Name, featureRequireVindexPredicate, fmt.Sprintf("a statement on table %s that isn't routed by a vindex", table))
+	case isRead && features.GetDisallowScatter() && !pf.allowScatter:
+		pf.deny(rp.Keyspace.Name, featureDisallowScatter, fmt.Sprintf("a scatter query on table %s", table))
+	}
+}
+
+// checkJoin denies the joins, subqueries and unions that combine the tables
+// of a keyspace with the tables of another keyspace.
+func (pf *plannerFeatures) checkJoin(what string, inputs ...engine.Primitive) {
+	keyspaces := make(map[string]bool)
+	for _, input := range inputs {
+		routedKeyspaces(input, keyspaces)
+	}
+	if len(keyspaces) < 2 {
+		return
+	}
+	names := make([]string, 0, len(keyspaces))
+	for keyspace := range keyspaces {
+		names = append(names, keyspace)
+	}
+	sort.Strings(names)
+	for i, keyspace := range names {
+		if !pf.features(keyspace).GetDisallowCrossKeyspaceJoins() {
+			continue
+		}
+		other := names[0]
+		if i == 0 {
+			other = names[1]
+		}
+		pf.deny(keyspace, featureDisallowCrossKeyspaceJoins, what+" with keyspace "+other)
+		return
+	}
+}
+
+// routedKeyspaces adds the keyspaces that the primitive routes to.
+func routedKeyspaces(primitive engine.Primitive, keyspaces map[string]bool) {
+	switch primitive := primitive.(type) {
+	case *engine.Route:
+		// The information_schema queries are not routed to the tables of a keyspace.
+		if primitive.Keyspace != nil && primitive.Opcode != engine.DBA {
+			keyspaces[primitive.Keyspace.Name] = true
+		}
+		return
+	case *engine.VindexLookup:
+		// The lookup of a vindex is not a query of the tables of its keyspace.
+		routedKeyspaces(primitive.SendTo, keyspaces)
+		return
+	}
+	inputs, _ := primitive.Inputs()
+	for _, input := range inputs {
+		routedKeyspaces(input, keyspaces)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestPlannerFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		keyspace string
+		features *vschemapb.PlannerFeatures
+		query    string
+		err      string
+		// primitive is the primitive that combines the keyspaces in the
+		// plan of the query.
+		primitive string
+	}{{
+		name:     "no features",
+		keyspace: KsTestSharded,
+		query:    "select id from `user`",
+	}, {
+		name:     "scatter",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{DisallowScatter: true},
+		query:    "select id from `user`",
+		err:      "VT09027: a scatter query on table `user` is disallowed by the disallow_scatter planner feature of keyspace TestExecutor",
+	}, {
+		name:     "scatter with the ALLOW_SCATTER directive",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{DisallowScatter: true},
+		query:    "select /*vt+ ALLOW_SCATTER */ id from `user`",
+	}, {
+		name:     "scatter write",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{DisallowScatter: true},
+		query:    "update user_extra set extra = 1",
+	}, {
+		name:     "vindex routed read",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{DisallowScatter: true, RequireVindexPredicate: true},
+		query:    "select id from `user` where id in (1, 2)",
+	}, {
+		name:     "read without vindex predicate",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{RequireVindexPredicate: true},
+		query:    "select /*vt+ ALLOW_SCATTER */ id from `user` where col = 1",
+		err:      "VT09027: a statement on table `user` that isn't routed by a vindex is disallowed by the require_vindex_predicate planner feature of keyspace TestExecutor",
+	}, {
+		name:     "write without vindex predicate",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{RequireVindexPredicate: true},
+		query:    "update user_extra set extra = 1",
+		err:      "VT09027: a statement on table user_extra that isn't routed by a vindex is disallowed by the require_vindex_predicate planner feature of keyspace TestExecutor",
+	}, {
+		name:     "vindex routed write",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{RequireVindexPredicate: true},
+		query:    "update user_extra set extra = 1 where user_id = 1",
+	}, {
+		name:     "cross-keyspace join",
+		keyspace: KsTestUnsharded,
+		features: &vschemapb.PlannerFeatures{DisallowCrossKeyspaceJoins: true},
+		query:    "select u.id from `user` u join simple s on u.id = s.id where u.id = 1",
+		err:      "VT09027: a join with keyspace TestExecutor is disallowed by the disallow_cross_keyspace_joins planner feature of keyspace TestUnsharded",
+	}, {
+		name:      "cross-keyspace hash join",
+		keyspace:  KsTestUnsharded,
+		features:  &vschemapb.PlannerFeatures{DisallowCrossKeyspaceJoins: true},
+		query:     "select u.id, s.id from (select id, textcol from `user` limit 10) u join (select id, col from simple limit 10) s on u.textcol = s.col",
+		err:       "VT09027: a join with keyspace TestExecutor is disallowed by the disallow_cross_keyspace_joins planner feature of keyspace TestUnsharded",
+		primitive: "*engine.HashJoin",
+	}, {
+		name:      "cross-keyspace subquery",
+		keyspace:  KsTestUnsharded,
+		features:  &vschemapb.PlannerFeatures{DisallowCrossKeyspaceJoins: true},
+		query:     "select id from `user` where id in (select id from simple)",
+		err:       "VT09027: a subquery with keyspace TestExecutor is disallowed by the disallow_cross_keyspace_joins planner feature of keyspace TestUnsharded",
+		primitive: "*engine.UncorrelatedSubquery",
+	}, {
+		name:      "cross-keyspace union",
+		keyspace:  KsTestUnsharded,
+		features:  &vschemapb.PlannerFeatures{DisallowCrossKeyspaceJoins: true},
+		query:     "select id from `user` union select id from simple",
+		err:       "VT09027: a union with keyspace TestExecutor is disallowed by the disallow_cross_keyspace_joins planner feature of keyspace TestUnsharded",
+		primitive: "*engine.Concatenate",
+	}, {
+		name:     "join in the same keyspace",
+		keyspace: KsTestSharded,
+		features: &vschemapb.PlannerFeatures{DisallowCrossKeyspaceJoins: true},
+		query:    "select u.id from `user` u join user_extra e on u.id = e.col where u.id = 1",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, _, _, _, ctx := createExecutorEnv(t)
+			executor.vschema.Keyspaces[tt.keyspace].PlannerFeatures = tt.features
+			// The hash joins need the types of the join columns.
+			executor.vschema.Keyspaces[KsTestUnsharded].Tables["simple"].Columns = []vindexes.Column{{
+				Name: sqlparser.NewIdentifierCI("col"),
+				Type: querypb.Type_VARCHAR,
+			}}
+
+			denials := plannerFeatureDenials.Counts()
+			session := &vtgatepb.Session{TargetString: "@primary"}
+			_, err := executorExec(ctx, executor, session, tt.query, nil)
+			if tt.err == "" {
+				require.NoError(t, err)
+				require.Equal(t, denials, plannerFeatureDenials.Counts())
+				return
+			}
+			require.EqualError(t, err, tt.err)
+			require.NotEqual(t, denials, plannerFeatureDenials.Counts())
+
+			if tt.primitive != "" {
+				// The plan of the query once it is allowed.
+				executor.vschema.Keyspaces[tt.keyspace].PlannerFeatures = nil
+				vc, err := newVCursorImpl(NewSafeSession(session), makeComments(""), executor, nil, executor.vm, executor.VSchema(), executor.resolver.resolver, nil, false, pv)
+				require.NoError(t, err)
+				plan, _ := getPlanCached(t, ctx, executor, vc, tt.query, makeComments(""), nil, true)
+				require.Truef(t, engine.Exists(func(node engine.Primitive) bool {
+					return fmt.Sprintf("%T", node) == tt.primitive
+				}, plan.Instructions), "no %s in the plan", tt.primitive)
+			}
+		})
+	}
+}
@@ -43,6 +43,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtenv"
@@ -73,6 +74,8 @@ var (
 	mysqlSlowConnectWarnThreshold time.Duration
 	mysqlConnBufferPooling        bool
 
+	mysqlPinPreparedResultSchema bool
+
 	mysqlDefaultWorkloadName = "OLTP"
 	mysqlDefaultWorkload     int32
 
@@ -99,6 +102,7 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&mysqlConnWriteTimeout, "mysql_server_write_timeout", mysqlConnWriteTimeout, "connection write timeout")
 	fs.DurationVar(&mysqlQueryTimeout, "mysql_server_query_timeout", mysqlQueryTimeout, "mysql query timeout")
 	fs.BoolVar(&mysqlConnBufferPooling, "mysql-server-pool-conn-read-buffers", mysqlConnBufferPooling, "If set, the server will pool incoming connection read buffers")
+	fs.BoolVar(&mysqlPinPreparedResultSchema, "mysql-server-pin-prepared-result-schema", mysqlPinPreparedResultSchema, "If set, prepared statements fail with a re-prepare error (1615) when the columns of their result differ from the ones returned when they were prepared")
 	fs.DurationVar(&mysqlKeepAlivePeriod, "mysql-server-keepalive-period", mysqlKeepAlivePeriod, "TCP period between keep-alives")
 	fs.DurationVar(&mysqlServerFlushDelay, "mysql_server_flush_delay", mysqlServerFlushDelay, "Delay after which buffered response will be flushed to the client.")
 	fs.StringVar(&mysqlDefaultWorkloadName, "mysql_default_workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
@@ -248,6 +252,7 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 		}
 	}()
 
+	state := snapshotSessionState(c, session)
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
 		session, err := vh.vtg.StreamExecute(ctx, vh, session, query, make(map[string]*querypb.BindVariable), callback)
 		if err != nil {
@@ -262,6 +267,7 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 		return err
 	}
 	fillInTxStatusFlags(c, session)
+	trackSessionState(c, state, session, result)
 	return callback(result)
 }
 
@@ -354,6 +360,11 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		}
 	}()
 
+	if mysqlPinPreparedResultSchema {
+		callback = pinPreparedResultSchema(prepare, callback)
+	}
+
+	state := snapshotSessionState(c, session)
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
 		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars, callback)
 		if err != nil {
@@ -367,10 +378,46 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		return sqlerror.NewSQLErrorFromError(err)
 	}
 	fillInTxStatusFlags(c, session)
+	trackSessionState(c, state, session, qr)
 
 	return callback(qr)
 }
 
+// pinPreparedResultSchema wraps the callback of a prepared statement execution so that the result is
+// rejected with a re-prepare error, before anything is sent to the client, if its columns differ from
+// the ones returned when the statement was prepared. This can happen after a schema change or after
+// the plan of the statement changed. Statements prepared without any result column are not checked,
+// since they either do not return rows or could not be described at prepare time.
+func pinPreparedResultSchema(prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	if len(prepare.Fields) == 0 {
+		return callback
+	}
+	checked := false
+	return func(qr *sqltypes.Result) error {
+		if !checked {
+			checked = true
+			if !preparedFieldsMatch(prepare.Fields, qr.Fields) {
+				return sqlerror.NewSQLErrorFromError(vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.NeedReprepare, "Prepared statement needs to be re-prepared"))
+			}
+		}
+		return callback(qr)
+	}
+}
+
+// preparedFieldsMatch returns true if the result fields have the names and types of the prepared fields.
+func preparedFieldsMatch(prepared, fields []*querypb.Field) bool {
+	if len(prepared) != len(fields) {
+		return false
+	}
+	for i, field := range fields {
+		// The names of the prepared fields were rewritten the same way when they were sent to the client.
+		if prepared[i].Name != strings.Replace(field.Name, "'?'", "?", -1) || prepared[i].Type != field.Type {
+			return false
+		}
+	}
+	return true
+}
+
 func (vh *vtgateHandler) WarningCount(c *mysql.Conn) uint16 {
 	return uint16(len(vh.session(c).GetWarnings()))
 }
@@ -32,6 +32,7 @@ import (
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/mysql/sqlerror"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/trace"
@@ -422,3 +423,61 @@ func TestGracefulShutdownWithTransaction(t *testing.T) {
 
 	require.True(t, mysqlConn.IsMarkedForClose())
 }
+
+func TestPinPreparedResultSchema(t *testing.T) {
+	prepare := &mysql.PrepareData{
+		Fields: sqltypes.MakeTestFields("id|? + 1", "int64|decimal"),
+	}
+
+	tests := []struct {
+		name   string
+		fields []*querypb.Field
+		match  bool
+	}{{
+		name:   "same columns",
+		fields: sqltypes.MakeTestFields("id|'?' + 1", "int64|decimal"),
+		match:  true,
+	}, {
+		name:   "renamed column",
+		fields: sqltypes.MakeTestFields("user_id|'?' + 1", "int64|decimal"),
+	}, {
+		name:   "column type changed",
+		fields: sqltypes.MakeTestFields("id|'?' + 1", "varchar|decimal"),
+	}, {
+		name:   "column added",
+		fields: sqltypes.MakeTestFields("id|'?' + 1|name", "int64|decimal|varchar"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []*sqltypes.Result
+			callback := pinPreparedResultSchema(prepare, func(qr *sqltypes.Result) error {
+				results = append(results, qr)
+				return nil
+			})
+
+			err := callback(&sqltypes.Result{Fields: tt.fields})
+			if !tt.match {
+				require.Error(t, err)
+				sqlErr, ok := err.(*sqlerror.SQLError)
+				require.True(t, ok, "not a SQLError: %T", err)
+				assert.Equal(t, sqlerror.ERNeedReprepare, sqlErr.Number())
+				assert.Empty(t, results)
+				return
+			}
+			require.NoError(t, err)
+			// Only the first result of a stream carries the fields.
+			require.NoError(t, callback(&sqltypes.Result{Rows: [][]sqltypes.Value{{sqltypes.NewInt64(1), sqltypes.NewDecimal("2")}}}))
+			assert.Len(t, results, 2)
+		})
+	}
+
+	t.Run("statement prepared without columns", func(t *testing.T) {
+		called := false
+		callback := pinPreparedResultSchema(&mysql.PrepareData{}, func(qr *sqltypes.Result) error {
+			called = true
+			return nil
+		})
+		require.NoError(t, callback(&sqltypes.Result{Fields: sqltypes.MakeTestFields("id", "int64")}))
+		assert.True(t, called)
+	})
+}
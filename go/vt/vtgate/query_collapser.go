@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+var collapsedQueries = stats.NewCountersWithSingleLabel("CollapsedQueries", "Read queries that shared the result of an identical query already in flight", "Keyspace")
+
+// queryCollapser collapses identical read queries that run concurrently
+// against the configured keyspaces into a single execution, whose result
+// is shared with all of them. This avoids dogpiling the tablets when many
+// clients run the same query at the same time.
+type queryCollapser struct {
+	keyspaces map[string]bool
+
+	mu       sync.Mutex
+	inFlight map[string]*collapsedQuery
+}
+
+// collapsedQuery is a query in flight, whose result is shared with the
+// identical queries that wait for it.
+type collapsedQuery struct {
+	// done is closed once the query has completed.
+	done     chan struct{}
+	result   *sqltypes.Result
+	warnings []*querypb.QueryWarning
+	err      error
+	// canceled is set if the query failed because the context of the
+	// caller that ran it is done, in which case the error isn't shared.
+	canceled bool
+}
+
+// newQueryCollapser returns a queryCollapser for the given keyspaces,
+// or nil if there are none.
+func newQueryCollapser(keyspaces []string) *queryCollapser {
+	if len(keyspaces) == 0 {
+		return nil
+	}
+	qc := &queryCollapser{
+		keyspaces: make(map[string]bool, len(keyspaces)),
+		inFlight:  make(map[string]*collapsedQuery),
+	}
+	for _, ks := range keyspaces {
+		qc.keyspaces[ks] = true
+	}
+	return qc
+}
+
+// keyspace returns the keyspace of the plan if its result can be shared
+// with identical queries. Only reads outside of transactions and reserved
+// connections are collapsed, and all the tables they use must belong to the
+// same configured keyspace.
+func (qc *queryCollapser) keyspace(plan *engine.Plan, vc *vcursorImpl, session *SafeSession) (string, bool) {
+	if qc == nil || plan.Type != sqlparser.StmtSelect || len(plan.TablesUsed) == 0 {
+		return "", false
+	}
+	if session.InTransaction() || session.InReservedConn() || len(session.SystemVariables) > 0 {
+		return "", false
+	}
+	if vc.keysetPagination != nil || plan.Instructions.NeedsTransaction() {
+		return "", false
+	}
+	var keyspace string
+	for _, table := range plan.TablesUsed {
+		ks, _, _ := strings.Cut(table, ".")
+		if !qc.keyspaces[ks] || (keyspace != "" && ks != keyspace) {
+			return "", false
+		}
+		keyspace = ks
+	}
+	return keyspace, true
+}
+
+// execute runs exec unless an identical query is already in flight, in which
+// case it waits for that query, or until ctx is done, and returns its result
+// and its warnings instead. If the query in flight failed only because the
+// context of its caller is done, exec is run instead.
+func (qc *queryCollapser) execute(ctx context.Context, keyspace string, plan *engine.Plan, vc *vcursorImpl, session *SafeSession, bindVars map[string]*querypb.BindVariable, exec func() (*sqltypes.Result, error)) (*sqltypes.Result, error) {
+	key := collapseKey(ctx, plan, vc, session, bindVars)
+
+	qc.mu.Lock()
+	q, waiting := qc.inFlight[key]
+	if !waiting {
+		q = &collapsedQuery{done: make(chan struct{})}
+		qc.inFlight[key] = q
+	}
+	qc.mu.Unlock()
+
+	if !waiting {
+		warnings := len(session.GetWarnings())
+		q.result, q.err = exec()
+		q.canceled = q.err != nil && ctx.Err() != nil
+		if all := session.GetWarnings(); len(all) > warnings {
+			q.warnings = append([]*querypb.QueryWarning(nil), all[warnings:]...)
+		}
+
+		qc.mu.Lock()
+		delete(qc.inFlight, key)
+		qc.mu.Unlock()
+		close(q.done)
+		if q.err != nil {
+			return nil, q.err
+		}
+		// Every query gets its own copy, since the callers are free to modify
+		// it while the waiters copy the shared result.
+		return q.result.Copy(), nil
+	}
+
+	collapsedQueries.Add(keyspace, 1)
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if q.canceled {
+		return exec()
+	}
+	if q.err != nil {
+		return nil, q.err
+	}
+	for _, warning := range q.warnings {
+		session.RecordWarning(warning)
+	}
+	return q.result.Copy(), nil
+}
+
+// collapseKey identifies a read query: two queries with the same key
+// return the same result. Queries of different callers are never collapsed,
+// since the tablets may enforce different rules for each of them.
+func collapseKey(ctx context.Context, plan *engine.Plan, vc *vcursorImpl, session *SafeSession, bindVars map[string]*querypb.BindVariable) string {
+	var key strings.Builder
+	key.WriteString(session.TargetString)
+	key.WriteByte(0)
+	key.WriteString(vc.TabletType().String())
+	key.WriteByte(0)
+	// The caller ids and the session options, such as sql_select_limit, hold
+	// no map, so their serialization is deterministic.
+	effectiveCallerID, _ := callerid.EffectiveCallerIDFromContext(ctx).MarshalVT()
+	key.Write(effectiveCallerID)
+	key.WriteByte(0)
+	immediateCallerID, _ := callerid.ImmediateCallerIDFromContext(ctx).MarshalVT()
+	key.Write(immediateCallerID)
+	key.WriteByte(0)
+	options, _ := session.GetOptions().MarshalVT()
+	key.Write(options)
+	key.WriteByte(0)
+	key.WriteString(plan.Original)
+
+	names := make([]string, 0, len(bindVars))
+	for name := range bindVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key.WriteByte(0)
+		key.WriteString(name)
+		key.WriteByte(0)
+		// The serialization of a single bind variable is deterministic.
+		bv, _ := bindVars[name].MarshalVT()
+		key.Write(bv)
+	}
+	return key.String()
+}
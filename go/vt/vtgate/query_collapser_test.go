@@ -0,0 +1,295 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/callerid"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+func TestNewQueryCollapser(t *testing.T) {
+	assert.Nil(t, newQueryCollapser(nil))
+
+	// A nil collapser collapses nothing.
+	var qc *queryCollapser
+	_, ok := qc.keyspace(&engine.Plan{Type: sqlparser.StmtSelect, TablesUsed: []string{"ks.t1"}}, &vcursorImpl{}, NewSafeSession(nil))
+	assert.False(t, ok)
+}
+
+func TestQueryCollapserKeyspace(t *testing.T) {
+	qc := newQueryCollapser([]string{"ks", "other"})
+	route := &engine.Route{}
+	tests := []struct {
+		name      string
+		plan      *engine.Plan
+		session   *vtgatepb.Session
+		keyspace  string
+		collapsed bool
+	}{{
+		name:      "select",
+		plan:      &engine.Plan{Type: sqlparser.StmtSelect, TablesUsed: []string{"ks.t1", "ks.t2"}, Instructions: route},
+		keyspace:  "ks",
+		collapsed: true,
+	}, {
+		name: "keyspace not configured",
+		plan: &engine.Plan{Type: sqlparser.StmtSelect, TablesUsed: []string{"unknown.t1"}, Instructions: route},
+	}, {
+		name: "tables of several keyspaces",
+		plan: &engine.Plan{Type: sqlparser.StmtSelect, TablesUsed: []string{"ks.t1", "other.t2"}, Instructions: route},
+	}, {
+		name: "no tables",
+		plan: &engine.Plan{Type: sqlparser.StmtSelect, Instructions: route},
+	}, {
+		name: "update",
+		plan: &engine.Plan{Type: sqlparser.StmtUpdate, TablesUsed: []string{"ks.t1"}, Instructions: route},
+	}, {
+		name:    "in a transaction",
+		plan:    &engine.Plan{Type: sqlparser.StmtSelect, TablesUsed: []string{"ks.t1"}, Instructions: route},
+		session: &vtgatepb.Session{InTransaction: true, ShardSessions: []*vtgatepb.Session_ShardSession{{}}},
+	}, {
+		name:    "with system variables",
+		plan:    &engine.Plan{Type: sqlparser.StmtSelect, TablesUsed: []string{"ks.t1"}, Instructions: route},
+		session: &vtgatepb.Session{SystemVariables: map[string]string{"sql_mode": "''"}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyspace, collapsed := qc.keyspace(tt.plan, &vcursorImpl{}, NewSafeSession(tt.session))
+			assert.Equal(t, tt.collapsed, collapsed)
+			assert.Equal(t, tt.keyspace, keyspace)
+		})
+	}
+}
+
+func TestCollapseKey(t *testing.T) {
+	plan := &engine.Plan{Original: "select * from t1 where id = :id"}
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "ks"})
+	replica := &vcursorImpl{tabletType: topodatapb.TabletType_REPLICA}
+	bindVars := func(id int64) map[string]*querypb.BindVariable {
+		return map[string]*querypb.BindVariable{
+			"id":   sqltypes.Int64BindVariable(id),
+			"name": sqltypes.StringBindVariable("a"),
+		}
+	}
+
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("alice", "", ""), callerid.NewImmediateCallerID("app"))
+
+	key := collapseKey(ctx, plan, replica, session, bindVars(1))
+	assert.Equal(t, key, collapseKey(ctx, plan, replica, session, bindVars(1)))
+	assert.NotEqual(t, key, collapseKey(ctx, plan, replica, session, bindVars(2)))
+	assert.NotEqual(t, key, collapseKey(ctx, plan, &vcursorImpl{tabletType: topodatapb.TabletType_PRIMARY}, session, bindVars(1)))
+	assert.NotEqual(t, key, collapseKey(ctx, plan, replica, NewSafeSession(&vtgatepb.Session{TargetString: "ks:-80"}), bindVars(1)))
+	assert.NotEqual(t, key, collapseKey(ctx, &engine.Plan{Original: "select * from t2 where id = :id"}, replica, session, bindVars(1)))
+	assert.NotEqual(t, key, collapseKey(ctx, plan, replica, NewSafeSession(&vtgatepb.Session{TargetString: "ks", Options: &querypb.ExecuteOptions{SqlSelectLimit: 1}}), bindVars(1)))
+
+	// Queries of different callers are not collapsed.
+	bob := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("bob", "", ""), callerid.NewImmediateCallerID("app"))
+	assert.NotEqual(t, key, collapseKey(bob, plan, replica, session, bindVars(1)))
+	other := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("alice", "", ""), callerid.NewImmediateCallerID("other"))
+	assert.NotEqual(t, key, collapseKey(other, plan, replica, session, bindVars(1)))
+}
+
+func TestQueryCollapserExecute(t *testing.T) {
+	qc := newQueryCollapser([]string{"ks"})
+	plan := &engine.Plan{Original: "select * from t1"}
+	session := NewSafeSession(nil)
+	vc := &vcursorImpl{}
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2")
+
+	var executions atomic.Int32
+	release := make(chan struct{})
+	exec := func() (*sqltypes.Result, error) {
+		executions.Add(1)
+		<-release
+		return want, nil
+	}
+
+	before := collapsedQueries.Counts()["ks"]
+	const queries = 5
+	results := make([]*sqltypes.Result, queries)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			qr, err := qc.execute(context.Background(), "ks", plan, vc, session, nil, exec)
+			assert.NoError(t, err)
+			results[i] = qr
+		}()
+	}
+	// Release the query in flight once all the others wait for it.
+	require.Eventually(t, func() bool {
+		return collapsedQueries.Counts()["ks"]-before == queries-1
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, executions.Load())
+	for i, qr := range results {
+		assert.Truef(t, want.Equal(qr), "result %d: %v", i, qr)
+		// Every caller gets its own copy of the result, including the one
+		// that ran the query.
+		assert.NotSamef(t, want, qr, "result %d", i)
+	}
+
+	// Errors are shared as well.
+	release = make(chan struct{})
+	failure := errors.New("query failed")
+	exec = func() (*sqltypes.Result, error) {
+		<-release
+		return nil, failure
+	}
+	before = collapsedQueries.Counts()["ks"]
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := qc.execute(context.Background(), "ks", plan, vc, session, nil, exec)
+			errs <- err
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return collapsedQueries.Counts()["ks"]-before == 1
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	assert.ErrorIs(t, <-errs, failure)
+	assert.ErrorIs(t, <-errs, failure)
+}
+
+func TestQueryCollapserExecuteCanceled(t *testing.T) {
+	qc := newQueryCollapser([]string{"ks"})
+	plan := &engine.Plan{Original: "select * from t1"}
+	session := NewSafeSession(nil)
+	vc := &vcursorImpl{}
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+
+	// The query in flight fails because the context of its caller is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leader := func() (*sqltypes.Result, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	errs := make(chan error, 1)
+	go func() {
+		_, err := qc.execute(ctx, "ks", plan, vc, session, nil, leader)
+		errs <- err
+	}()
+	<-started
+
+	var executions atomic.Int32
+	waiter := func() (*sqltypes.Result, error) {
+		executions.Add(1)
+		return want, nil
+	}
+	before := collapsedQueries.Counts()["ks"]
+	results := make(chan *sqltypes.Result, 1)
+	go func() {
+		qr, err := qc.execute(context.Background(), "ks", plan, vc, session, nil, waiter)
+		assert.NoError(t, err)
+		results <- qr
+	}()
+	require.Eventually(t, func() bool {
+		return collapsedQueries.Counts()["ks"]-before == 1
+	}, 5*time.Second, time.Millisecond)
+	cancel()
+
+	// The waiter doesn't fail with the error of the canceled caller, but runs
+	// the query itself.
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	assert.True(t, want.Equal(<-results))
+	assert.EqualValues(t, 1, executions.Load())
+}
+
+func TestQueryCollapserExecuteWarnings(t *testing.T) {
+	qc := newQueryCollapser([]string{"ks"})
+	plan := &engine.Plan{Original: "select * from t1"}
+	vc := &vcursorImpl{}
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	warning := &querypb.QueryWarning{Code: 1235, Message: "warning"}
+
+	original := NewSafeSession(nil)
+	release := make(chan struct{})
+	exec := func() (*sqltypes.Result, error) {
+		<-release
+		original.RecordWarning(warning)
+		return want, nil
+	}
+
+	before := collapsedQueries.Counts()["ks"]
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := qc.execute(context.Background(), "ks", plan, vc, original, nil, exec)
+		assert.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool {
+		qc.mu.Lock()
+		defer qc.mu.Unlock()
+		return len(qc.inFlight) == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// A waiter whose context is done stops waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := qc.execute(ctx, "ks", plan, vc, NewSafeSession(nil), nil, exec)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The warnings of the query in flight are passed on to its waiters.
+	waiter := NewSafeSession(nil)
+	errs := make(chan error, 1)
+	go func() {
+		_, err := qc.execute(context.Background(), "ks", plan, vc, waiter, nil, exec)
+		errs <- err
+	}()
+	require.Eventually(t, func() bool {
+		return collapsedQueries.Counts()["ks"]-before == 2
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	<-done
+	require.NoError(t, <-errs)
+	utils.MustMatch(t, []*querypb.QueryWarning{warning}, waiter.GetWarnings())
+}
+
+func TestExecutorQueryCollapsing(t *testing.T) {
+	executor, sbc1, sbc2, _, ctx := createExecutorEnv(t)
+	executor.collapser = newQueryCollapser([]string{KsTestSharded})
+	session := &vtgatepb.Session{TargetString: KsTestSharded}
+
+	// Queries that don't run concurrently are not collapsed.
+	for range 2 {
+		_, err := executorExec(ctx, executor, session, "select id from user", nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, sbc1.ExecCount.Load())
+	assert.EqualValues(t, 2, sbc2.ExecCount.Load())
+}
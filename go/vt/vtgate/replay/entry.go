@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// logTimeFormat is the format the query log uses for the Start and End fields.
+const logTimeFormat = "2006-01-02 15:04:05.000000"
+
+// ErrNotReplayable is returned by ParseEntry for log lines that were parsed,
+// but that cannot be replayed faithfully, because the query log only kept a
+// summary of some of their bind variables.
+var ErrNotReplayable = errors.New("query log entry cannot be replayed")
+
+// Entry is a single query of vtgate's JSON query log.
+type Entry struct {
+	Method         string
+	Start          time.Time
+	TotalTime      time.Duration
+	StmtType       string
+	SQL            string
+	BindVars       map[string]*querypb.BindVariable
+	Error          string
+	TabletType     string
+	SessionUUID    string
+	ActiveKeyspace string
+}
+
+// Target returns the target string the entry was executed against.
+func (e *Entry) Target() string {
+	target := e.ActiveKeyspace
+	if e.TabletType != "" {
+		target += "@" + strings.ToLower(e.TabletType)
+	}
+	return target
+}
+
+// IsReadOnly returns true if replaying the entry cannot modify any data.
+func (e *Entry) IsReadOnly() bool {
+	switch sqlparser.Preview(e.SQL) {
+	case sqlparser.StmtSelect, sqlparser.StmtShow, sqlparser.StmtExplain:
+		return true
+	default:
+		return false
+	}
+}
+
+type logBindVar struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type logLine struct {
+	Method         string                `json:"Method"`
+	Start          string                `json:"Start"`
+	TotalTime      float64               `json:"TotalTime"`
+	StmtType       string                `json:"StmtType"`
+	SQL            string                `json:"SQL"`
+	BindVars       map[string]logBindVar `json:"BindVars"`
+	Error          string                `json:"Error"`
+	TabletType     string                `json:"TabletType"`
+	SessionUUID    string                `json:"SessionUUID"`
+	ActiveKeyspace string                `json:"ActiveKeyspace"`
+}
+
+// ParseEntry parses a line of vtgate's query log, as written with --querylog-format=json.
+// The query log only records the length of string bind variables, unless it was captured
+// with full bind variables (the "full" parameter of /debug/querylog), so fullBindVars tells
+// whether string values can be trusted. Entries that lost the value of any bind variable
+// are returned along with ErrNotReplayable.
+func ParseEntry(line []byte, fullBindVars bool) (*Entry, error) {
+	var ll logLine
+	if err := json.Unmarshal(line, &ll); err != nil {
+		return nil, fmt.Errorf("invalid query log line: %w", err)
+	}
+	start, err := time.ParseInLocation(logTimeFormat, ll.Start, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query log start time %q: %w", ll.Start, err)
+	}
+	entry := &Entry{
+		Method:         ll.Method,
+		Start:          start,
+		TotalTime:      time.Duration(ll.TotalTime * float64(time.Second)),
+		StmtType:       ll.StmtType,
+		SQL:            ll.SQL,
+		BindVars:       make(map[string]*querypb.BindVariable, len(ll.BindVars)),
+		Error:          ll.Error,
+		TabletType:     ll.TabletType,
+		SessionUUID:    ll.SessionUUID,
+		ActiveKeyspace: ll.ActiveKeyspace,
+	}
+	replayable := true
+	for name, lbv := range ll.BindVars {
+		bv, ok, err := parseBindVar(lbv, fullBindVars)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bind variable %s: %w", name, err)
+		}
+		if !ok {
+			replayable = false
+			continue
+		}
+		entry.BindVars[name] = bv
+	}
+	if !replayable {
+		return entry, ErrNotReplayable
+	}
+	return entry, nil
+}
+
+// parseBindVar returns the bind variable logged as lbv, and false if its value was not logged.
+func parseBindVar(lbv logBindVar, fullBindVars bool) (*querypb.BindVariable, bool, error) {
+	typ, ok := querypb.Type_value[lbv.Type]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown type %q", lbv.Type)
+	}
+	switch t := querypb.Type(typ); {
+	case t == sqltypes.Null:
+		return sqltypes.NullBindVariable, true, nil
+	case t == sqltypes.Tuple:
+		// Tuples are logged as their number of items only.
+		return nil, false, nil
+	case sqltypes.IsIntegral(t) || sqltypes.IsFloat(t):
+		return &querypb.BindVariable{Type: t, Value: []byte(lbv.Value)}, true, nil
+	default:
+		if !fullBindVars {
+			return nil, false, nil
+		}
+		var value string
+		if err := json.Unmarshal(lbv.Value, &value); err != nil {
+			return nil, false, err
+		}
+		return &querypb.BindVariable{Type: t, Value: []byte(value)}, true, nil
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/streamlog"
+	"vitess.io/vitess/go/vt/vtgate/logstats"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// logLineFor returns the JSON query log line vtgate would write for the given query.
+func logLineFor(t *testing.T, sessionUUID string, start time.Time, totalTime time.Duration, stmtType, sql string, bindVars map[string]*querypb.BindVariable, execErr error, full bool) string {
+	t.Helper()
+	streamlog.SetQueryLogFormat(streamlog.QueryLogFormatJSON)
+	defer streamlog.SetQueryLogFormat(streamlog.QueryLogFormatText)
+
+	stats := logstats.NewLogStats(context.Background(), "Execute", sql, sessionUUID, bindVars)
+	stats.StartTime = start
+	stats.EndTime = start.Add(totalTime)
+	stats.StmtType = stmtType
+	stats.TabletType = "PRIMARY"
+	stats.ActiveKeyspace = "ks"
+	stats.Error = execErr
+
+	params := url.Values{}
+	if full {
+		params.Set("full", "true")
+	}
+	var buf bytes.Buffer
+	require.NoError(t, stats.Logf(&buf, params))
+	return buf.String()
+}
+
+func TestParseEntry(t *testing.T) {
+	start := time.Date(2024, 3, 4, 5, 6, 7, 8000, time.Local)
+	bindVars := map[string]*querypb.BindVariable{
+		"vtg1": sqltypes.Int64BindVariable(42),
+		"vtg2": sqltypes.Float64BindVariable(1.5),
+		"vtg3": sqltypes.NullBindVariable,
+	}
+	line := logLineFor(t, "uuid", start, 1500*time.Microsecond, "SELECT", "select * from t where a = :vtg1 and b = :vtg2 and c = :vtg3", bindVars, errors.New("boom"), false)
+
+	entry, err := ParseEntry([]byte(line), false)
+	require.NoError(t, err)
+	assert.Equal(t, "Execute", entry.Method)
+	assert.True(t, start.Equal(entry.Start), "got start %v", entry.Start)
+	assert.Equal(t, 1500*time.Microsecond, entry.TotalTime)
+	assert.Equal(t, "SELECT", entry.StmtType)
+	assert.Equal(t, "select * from t where a = :vtg1 and b = :vtg2 and c = :vtg3", entry.SQL)
+	assert.Equal(t, "boom", entry.Error)
+	assert.Equal(t, "uuid", entry.SessionUUID)
+	assert.Equal(t, "ks@primary", entry.Target())
+	assert.True(t, entry.IsReadOnly())
+	assert.True(t, sqltypes.BindVariablesEqual(bindVars, entry.BindVars), "got bind variables %v", entry.BindVars)
+}
+
+func TestParseEntryStringBindVars(t *testing.T) {
+	bindVars := map[string]*querypb.BindVariable{
+		"vtg1": sqltypes.StringBindVariable("foo"),
+	}
+	sql := "insert into t(a) values (:vtg1)"
+
+	// Without the full bind variables, only the length of the string is logged.
+	line := logLineFor(t, "uuid", time.Now(), time.Millisecond, "INSERT", sql, bindVars, nil, false)
+	entry, err := ParseEntry([]byte(line), false)
+	require.ErrorIs(t, err, ErrNotReplayable)
+	assert.False(t, entry.IsReadOnly())
+
+	line = logLineFor(t, "uuid", time.Now(), time.Millisecond, "INSERT", sql, bindVars, nil, true)
+	entry, err = ParseEntry([]byte(line), true)
+	require.NoError(t, err)
+	assert.True(t, sqltypes.BindVariablesEqual(bindVars, entry.BindVars), "got bind variables %v", entry.BindVars)
+
+	// Tuples are never logged in full.
+	tuple, err := sqltypes.BuildBindVariable([]int64{1, 2})
+	require.NoError(t, err)
+	line = logLineFor(t, "uuid", time.Now(), time.Millisecond, "SELECT", "select * from t where a in ::vtg1", map[string]*querypb.BindVariable{"vtg1": tuple}, nil, true)
+	_, err = ParseEntry([]byte(line), true)
+	require.ErrorIs(t, err, ErrNotReplayable)
+
+	_, err = ParseEntry([]byte("not json"), false)
+	require.ErrorContains(t, err, "invalid query log line")
+}
@@ -0,0 +1,357 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package replay replays vtgate's structured query log against another vtgate.
+
+Every logged query is executed again, at the pace it was originally received
+(or faster, with a speed multiplier), and the latency and error observed for it
+are compared with the ones that were logged. This makes it possible to validate
+an upgrade or a vschema change against a recording of production traffic.
+
+Queries of the same logged session are replayed in order on a single session,
+while different sessions are replayed concurrently.
+*/
+package replay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// Session executes queries on the vtgate the log is replayed against.
+// A Session is only used by one goroutine at a time.
+type Session interface {
+	Execute(ctx context.Context, sql string, bindVars map[string]*querypb.BindVariable) error
+}
+
+// SessionFactory creates the Session used to replay the queries of a logged session.
+type SessionFactory func(target string) Session
+
+// Config configures a Replayer.
+type Config struct {
+	// Speed is the replay speed multiplier: 2 replays the log twice as fast as it
+	// was recorded. A Speed of 0 replays the log as fast as possible.
+	Speed float64
+	// ReadOnly only replays the queries that cannot modify any data.
+	ReadOnly bool
+	// FullBindVars tells that the log was captured with the full bind variables.
+	FullBindVars bool
+	// MaxMismatches is the maximum number of error mismatches kept in the report.
+	MaxMismatches int
+	// SessionIdleTimeout is how long, in log time, a session can go without any
+	// query before it is retired. The query log does not record when sessions end,
+	// so this bounds the number of sessions kept over a long replay. It defaults
+	// to DefaultSessionIdleTimeout.
+	SessionIdleTimeout time.Duration
+}
+
+// DefaultSessionIdleTimeout is the SessionIdleTimeout used when none is configured.
+const DefaultSessionIdleTimeout = time.Minute
+
+func (c *Config) sessionIdleTimeout() time.Duration {
+	if c.SessionIdleTimeout <= 0 {
+		return DefaultSessionIdleTimeout
+	}
+	return c.SessionIdleTimeout
+}
+
+// Replayer replays a query log.
+type Replayer struct {
+	config     Config
+	newSession SessionFactory
+}
+
+// NewReplayer returns a Replayer that replays queries on the sessions created by newSession.
+func NewReplayer(config Config, newSession SessionFactory) *Replayer {
+	return &Replayer{
+		config:     config,
+		newSession: newSession,
+	}
+}
+
+// Stats compares the original and replayed executions of a set of queries.
+type Stats struct {
+	Count          int
+	OriginalTime   time.Duration
+	ReplayTime     time.Duration
+	OriginalErrors int
+	ReplayErrors   int
+}
+
+func (s *Stats) add(entry *Entry, replayTime time.Duration, replayErr error) {
+	s.Count++
+	s.OriginalTime += entry.TotalTime
+	s.ReplayTime += replayTime
+	if entry.Error != "" {
+		s.OriginalErrors++
+	}
+	if replayErr != nil {
+		s.ReplayErrors++
+	}
+}
+
+// LatencyDelta returns the relative change of the average latency, as a fraction
+// of the original average latency.
+func (s *Stats) LatencyDelta() float64 {
+	if s.OriginalTime == 0 {
+		return 0
+	}
+	return float64(s.ReplayTime-s.OriginalTime) / float64(s.OriginalTime)
+}
+
+// Mismatch is a query that failed either originally or when replayed, but not both.
+type Mismatch struct {
+	SQL           string
+	OriginalError string
+	ReplayError   string
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	// Replayed is the number of queries that were replayed.
+	Replayed int
+	// Skipped is the number of queries that were filtered out or could not be replayed.
+	Skipped int
+	// Total aggregates the stats of all the replayed queries.
+	Total Stats
+	// ByStmtType breaks the stats down by statement type.
+	ByStmtType map[string]*Stats
+	// Mismatches lists queries whose replay did not fail the same way as the original.
+	Mismatches []Mismatch
+}
+
+// StmtTypes returns the statement types of the report, sorted.
+func (r *Report) StmtTypes() []string {
+	types := make([]string, 0, len(r.ByStmtType))
+	for stmtType := range r.ByStmtType {
+		types = append(types, stmtType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Write writes a human readable summary of the report.
+func (r *Report) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Replayed: %d, Skipped: %d\n", r.Replayed, r.Skipped); err != nil {
+		return err
+	}
+	writeStats := func(name string, s *Stats) error {
+		if s.Count == 0 {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "%-12s count: %d, avg original: %v, avg replay: %v, latency delta: %+.1f%%, errors: %d -> %d\n",
+			name, s.Count, s.OriginalTime/time.Duration(s.Count), s.ReplayTime/time.Duration(s.Count), 100*s.LatencyDelta(), s.OriginalErrors, s.ReplayErrors)
+		return err
+	}
+	for _, stmtType := range r.StmtTypes() {
+		if err := writeStats(stmtType, r.ByStmtType[stmtType]); err != nil {
+			return err
+		}
+	}
+	if err := writeStats("TOTAL", &r.Total); err != nil {
+		return err
+	}
+	for _, m := range r.Mismatches {
+		if _, err := fmt.Fprintf(w, "mismatch: %s\n  original error: %q\n  replay error: %q\n", m.SQL, m.OriginalError, m.ReplayError); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay is the state of a single run of Replay.
+type replay struct {
+	*Replayer
+
+	mu     sync.Mutex
+	report *Report
+
+	wg       sync.WaitGroup
+	sessions map[string]*sessionWorker
+	// retired holds the workers of the retired sessions that may still be
+	// replaying their last queries.
+	retired   map[string]*sessionWorker
+	lastSweep time.Time
+}
+
+// sessionWorker replays the queries of a logged session in order.
+type sessionWorker struct {
+	queue chan *Entry
+	// done is closed once all the queries of the queue were replayed.
+	done chan struct{}
+	// lastStart is the log time of the latest query of the session.
+	lastStart time.Time
+}
+
+// Replay replays the query log read from r, and returns the report once all
+// the replayed queries completed. Replay stops early if ctx is done.
+func (rp *Replayer) Replay(ctx context.Context, r io.Reader) (*Report, error) {
+	run := &replay{
+		Replayer: rp,
+		report:   &Report{ByStmtType: make(map[string]*Stats)},
+		sessions: make(map[string]*sessionWorker),
+		retired:  make(map[string]*sessionWorker),
+	}
+	err := run.dispatch(ctx, r)
+	for _, worker := range run.sessions {
+		close(worker.queue)
+	}
+	run.wg.Wait()
+	return run.report, err
+}
+
+// dispatch reads the log, and hands every entry to its session once it is due.
+func (run *replay) dispatch(ctx context.Context, r io.Reader) error {
+	var logStart, replayStart time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry, err := ParseEntry(scanner.Bytes(), run.config.FullBindVars)
+		if errors.Is(err, ErrNotReplayable) || (err == nil && run.config.ReadOnly && !entry.IsReadOnly()) {
+			run.skip()
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if logStart.IsZero() {
+			logStart, replayStart = entry.Start, time.Now()
+		}
+		if run.config.Speed > 0 {
+			due := replayStart.Add(time.Duration(float64(entry.Start.Sub(logStart)) / run.config.Speed))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+
+		run.retireIdleSessions(entry.Start)
+		select {
+		case run.sessionQueue(ctx, entry) <- entry:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return scanner.Err()
+}
+
+// sessionQueue returns the queue of the session the entry belongs to,
+// and starts replaying the session if it is new.
+func (run *replay) sessionQueue(ctx context.Context, entry *Entry) chan *Entry {
+	worker, ok := run.sessions[entry.SessionUUID]
+	if !ok {
+		worker = &sessionWorker{
+			queue: make(chan *Entry, 100),
+			done:  make(chan struct{}),
+		}
+		run.sessions[entry.SessionUUID] = worker
+		// A session that was retired too early must not replay its new queries
+		// before its old ones.
+		previous := run.retired[entry.SessionUUID]
+		delete(run.retired, entry.SessionUUID)
+		session := run.newSession(entry.Target())
+		run.wg.Add(1)
+		go func() {
+			defer run.wg.Done()
+			defer close(worker.done)
+			if previous != nil {
+				<-previous.done
+			}
+			for entry := range worker.queue {
+				if ctx.Err() != nil {
+					continue
+				}
+				start := time.Now()
+				err := session.Execute(ctx, entry.SQL, entry.BindVars)
+				run.record(entry, time.Since(start), err)
+			}
+		}()
+	}
+	if entry.Start.After(worker.lastStart) {
+		worker.lastStart = entry.Start
+	}
+	return worker.queue
+}
+
+// retireIdleSessions closes the queues of the sessions that had no query
+// for SessionIdleTimeout before now, which is a log time. The sessions are
+// swept at most once per SessionIdleTimeout.
+func (run *replay) retireIdleSessions(now time.Time) {
+	idleTimeout := run.config.sessionIdleTimeout()
+	if now.Sub(run.lastSweep) < idleTimeout {
+		return
+	}
+	run.lastSweep = now
+	for uuid, worker := range run.retired {
+		select {
+		case <-worker.done:
+			delete(run.retired, uuid)
+		default:
+		}
+	}
+	for uuid, worker := range run.sessions {
+		if now.Sub(worker.lastStart) >= idleTimeout {
+			close(worker.queue)
+			delete(run.sessions, uuid)
+			run.retired[uuid] = worker
+		}
+	}
+}
+
+func (run *replay) skip() {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.report.Skipped++
+}
+
+func (run *replay) record(entry *Entry, replayTime time.Duration, replayErr error) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	report := run.report
+	report.Replayed++
+	report.Total.add(entry, replayTime, replayErr)
+	stats, ok := report.ByStmtType[entry.StmtType]
+	if !ok {
+		stats = &Stats{}
+		report.ByStmtType[entry.StmtType] = stats
+	}
+	stats.add(entry, replayTime, replayErr)
+
+	if (entry.Error != "") != (replayErr != nil) && len(report.Mismatches) < run.config.MaxMismatches {
+		mismatch := Mismatch{SQL: entry.SQL, OriginalError: entry.Error}
+		if replayErr != nil {
+			mismatch.ReplayError = replayErr.Error()
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+type fakeVTGate struct {
+	mu       sync.Mutex
+	executed map[string][]string
+	errors   map[string]error
+}
+
+type fakeSession struct {
+	vtgate *fakeVTGate
+	target string
+}
+
+func (s *fakeSession) Execute(ctx context.Context, sql string, bindVars map[string]*querypb.BindVariable) error {
+	s.vtgate.mu.Lock()
+	defer s.vtgate.mu.Unlock()
+	s.vtgate.executed[s.target] = append(s.vtgate.executed[s.target], sql)
+	return s.vtgate.errors[sql]
+}
+
+func newFakeVTGate() *fakeVTGate {
+	return &fakeVTGate{
+		executed: make(map[string][]string),
+		errors:   make(map[string]error),
+	}
+}
+
+func (f *fakeVTGate) newSession(target string) Session {
+	return &fakeSession{vtgate: f, target: target}
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Now()
+	oneInt := map[string]*querypb.BindVariable{"vtg1": sqltypes.Int64BindVariable(1)}
+	oneStr := map[string]*querypb.BindVariable{"vtg1": sqltypes.StringBindVariable("a")}
+	log := strings.Join([]string{
+		logLineFor(t, "s1", start, time.Millisecond, "SELECT", "select 1 from t1 where id = :vtg1", oneInt, nil, false),
+		logLineFor(t, "s2", start, time.Millisecond, "INSERT", "insert into t1(id) values (:vtg1)", oneInt, nil, false),
+		logLineFor(t, "s1", start.Add(time.Millisecond), time.Millisecond, "SELECT", "select 2 from t1 where id = :vtg1", oneInt, errors.New("original error"), false),
+		logLineFor(t, "s2", start.Add(time.Millisecond), time.Millisecond, "SELECT", "select 3 from t1 where name = :vtg1", oneStr, nil, false),
+		logLineFor(t, "s1", start.Add(2*time.Millisecond), time.Millisecond, "SELECT", "select 4 from t1", nil, nil, false),
+	}, "")
+
+	vtgate := newFakeVTGate()
+	vtgate.errors["select 4 from t1"] = errors.New("replay error")
+	replayer := NewReplayer(Config{ReadOnly: true, MaxMismatches: 10}, vtgate.newSession)
+	report, err := replayer.Replay(context.Background(), strings.NewReader(log))
+	require.NoError(t, err)
+
+	// The insert is filtered out, and the string bind variable was not logged in full.
+	assert.Equal(t, 3, report.Replayed)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, []string{"select 1 from t1 where id = :vtg1", "select 2 from t1 where id = :vtg1", "select 4 from t1"}, vtgate.executed["ks@primary"])
+
+	assert.Equal(t, []string{"SELECT"}, report.StmtTypes())
+	stats := report.ByStmtType["SELECT"]
+	assert.Equal(t, 3, stats.Count)
+	assert.Equal(t, 3*time.Millisecond, stats.OriginalTime)
+	assert.Equal(t, 1, stats.OriginalErrors)
+	assert.Equal(t, 1, stats.ReplayErrors)
+	assert.Equal(t, *stats, report.Total)
+	assert.Equal(t, []Mismatch{{
+		SQL:           "select 2 from t1 where id = :vtg1",
+		OriginalError: "original error",
+	}, {
+		SQL:         "select 4 from t1",
+		ReplayError: "replay error",
+	}}, report.Mismatches)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	assert.Contains(t, buf.String(), "Replayed: 3, Skipped: 2\n")
+	assert.Contains(t, buf.String(), "errors: 1 -> 1\n")
+}
+
+func TestReplaySessionOrder(t *testing.T) {
+	start := time.Now()
+	var lines []string
+	var want []string
+	for i := 0; i < 50; i++ {
+		sql := "select " + strings.Repeat("1", i+1)
+		lines = append(lines, logLineFor(t, "s1", start, time.Millisecond, "SELECT", sql, nil, nil, false))
+		want = append(want, sql)
+	}
+
+	vtgate := newFakeVTGate()
+	report, err := NewReplayer(Config{}, vtgate.newSession).Replay(context.Background(), strings.NewReader(strings.Join(lines, "")))
+	require.NoError(t, err)
+	assert.Equal(t, 50, report.Replayed)
+	assert.Equal(t, want, vtgate.executed["ks@primary"])
+}
+
+func TestReplayRetiresIdleSessions(t *testing.T) {
+	start := time.Now()
+	log := strings.Join([]string{
+		logLineFor(t, "s1", start, time.Millisecond, "SELECT", "select 1", nil, nil, false),
+		logLineFor(t, "s2", start.Add(30*time.Second), time.Millisecond, "SELECT", "select 2", nil, nil, false),
+		logLineFor(t, "s1", start.Add(2*time.Minute), time.Millisecond, "SELECT", "select 3", nil, nil, false),
+		logLineFor(t, "s3", start.Add(4*time.Minute), time.Millisecond, "SELECT", "select 4", nil, nil, false),
+	}, "")
+
+	vtgate := newFakeVTGate()
+	run := &replay{
+		Replayer: NewReplayer(Config{SessionIdleTimeout: time.Minute}, vtgate.newSession),
+		report:   &Report{ByStmtType: make(map[string]*Stats)},
+		sessions: make(map[string]*sessionWorker),
+		retired:  make(map[string]*sessionWorker),
+	}
+	require.NoError(t, run.dispatch(context.Background(), strings.NewReader(log)))
+
+	// Only the session of the last query is still open.
+	assert.Len(t, run.sessions, 1)
+	assert.Contains(t, run.sessions, "s3")
+	for _, worker := range run.sessions {
+		close(worker.queue)
+	}
+	run.wg.Wait()
+
+	// The session that was retired and came back still replayed its queries in order.
+	assert.Equal(t, 4, run.report.Replayed)
+	executed := vtgate.executed["ks@primary"]
+	assert.ElementsMatch(t, []string{"select 1", "select 2", "select 3", "select 4"}, executed)
+	assert.Less(t, slices.Index(executed, "select 1"), slices.Index(executed, "select 3"))
+}
+
+func TestReplaySpeed(t *testing.T) {
+	start := time.Now()
+	log := logLineFor(t, "s1", start, time.Millisecond, "SELECT", "select 1", nil, nil, false) +
+		logLineFor(t, "s2", start.Add(time.Second), time.Millisecond, "SELECT", "select 2", nil, nil, false)
+
+	// At 20x, the second query is replayed 50ms after the first one.
+	vtgate := newFakeVTGate()
+	replayStart := time.Now()
+	report, err := NewReplayer(Config{Speed: 20}, vtgate.newSession).Replay(context.Background(), strings.NewReader(log))
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Replayed)
+	assert.GreaterOrEqual(t, time.Since(replayStart), 50*time.Millisecond)
+
+	// The replay stops when the context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	report, err = NewReplayer(Config{Speed: 1}, vtgate.newSession).Replay(ctx, strings.NewReader(log))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, report.Replayed)
+}
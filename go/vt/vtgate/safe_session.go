@@ -18,6 +18,7 @@ package vtgate
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -26,6 +27,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/mysql/datetime"
+	"vitess.io/vitess/go/sqltypes"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/srvtopo"
@@ -175,6 +177,7 @@ func (session *SafeSession) Reset() {
 	session.mu.Lock()
 	defer session.mu.Unlock()
 	session.resetCommonLocked()
+	session.resetTempTablesLocked()
 	session.ShardSessions = nil
 	session.PreSessions = nil
 	session.PostSessions = nil
@@ -185,6 +188,7 @@ func (session *SafeSession) ResetAll() {
 	session.mu.Lock()
 	defer session.mu.Unlock()
 	session.resetCommonLocked()
+	session.resetTempTablesLocked()
 	session.ShardSessions = nil
 	session.PreSessions = nil
 	session.PostSessions = nil
@@ -198,11 +202,28 @@ func (session *SafeSession) resetCommonLocked() {
 	session.Session.InTransaction = false
 	session.commitOrder = vtgatepb.CommitOrder_NORMAL
 	session.Savepoints = nil
+	if session.Autocommit || len(session.ShardSessions)+len(session.PreSessions)+len(session.PostSessions) > 0 {
+		// The isolation level and the tag apply to a single transaction. The implicit
+		// transactions of autocommit=0 that never ran on any shard don't consume them.
+		session.Session.TransactionIsolation = querypb.ExecuteOptions_DEFAULT
+		if session.Options != nil {
+			session.Options.TransactionTag = ""
+		}
+	}
 	if session.Options != nil {
 		session.Options.TransactionAccessMode = nil
 	}
 }
 
+// resetTempTablesLocked forgets the temporary tables of the session, which
+// are gone with its released reserved connections.
+func (session *SafeSession) resetTempTablesLocked() {
+	session.TempTables = nil
+	if session.Options != nil {
+		session.Options.HasCreatedTempTables = false
+	}
+}
+
 // SetQueryTimeout sets the query timeout
 func (session *SafeSession) SetQueryTimeout(queryTimeout int64) {
 	session.mu.Lock()
@@ -600,6 +621,21 @@ func (session *SafeSession) SetOptions(options *querypb.ExecuteOptions) {
 	session.Options = options
 }
 
+// StreamChunkSize returns the maximum number of rows and bytes per result of a
+// streaming query for the session. The sizes requested by the client are capped
+// to maxBytes, and the negotiated sizes are stored back in the session options,
+// so that the tablets honor them too and the client can see them.
+func (session *SafeSession) StreamChunkSize(defaultBytes, maxBytes int) (int, int) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	options := session.Options
+	chunkRows, chunkBytes := sqltypes.StreamChunkSize(options, defaultBytes, maxBytes)
+	if options != nil && options.StreamChunkMaxBytes > 0 {
+		options.StreamChunkMaxBytes = uint64(chunkBytes)
+	}
+	return chunkRows, chunkBytes
+}
+
 // StoreSavepoint stores the savepoint and release savepoint queries in the session
 func (session *SafeSession) StoreSavepoint(sql string) {
 	session.mu.Lock()
@@ -696,6 +732,14 @@ func (session *SafeSession) ResetShard(tabletAlias *topodatapb.TabletAlias) erro
 	session.mu.Lock()
 	defer session.mu.Unlock()
 
+	// The temporary tables of the keyspace only exist in the reserved connection.
+	shardSessions := slices.Concat(session.PreSessions, session.ShardSessions, session.PostSessions)
+	for _, shardSession := range shardSessions {
+		if proto.Equal(shardSession.TabletAlias, tabletAlias) && shardSession.TransactionId == 0 {
+			session.forgetTempTablesLocked(shardSession.Target.GetKeyspace())
+		}
+	}
+
 	// Always append, in order for rollback to succeed.
 	switch session.commitOrder {
 	case vtgatepb.CommitOrder_NORMAL:
@@ -723,6 +767,65 @@ func (session *SafeSession) ResetShard(tabletAlias *topodatapb.TabletAlias) erro
 	return nil
 }
 
+// AddTempTable records a temporary table created in the session. Query plans
+// are not cached while the session has temporary tables, as they can shadow
+// the tables of the keyspace.
+func (session *SafeSession) AddTempTable(keyspace, table string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	name := tempTableName(keyspace, table)
+	if !slices.Contains(session.TempTables, name) {
+		session.TempTables = append(session.TempTables, name)
+	}
+	session.GetOrCreateOptions().HasCreatedTempTables = true
+}
+
+// RemoveTempTable forgets a temporary table dropped from the session.
+func (session *SafeSession) RemoveTempTable(keyspace, table string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	name := tempTableName(keyspace, table)
+	session.TempTables = slices.DeleteFunc(session.TempTables, func(tempTable string) bool {
+		return tempTable == name
+	})
+	session.updateTempTablesLocked()
+}
+
+// HasTempTable returns true if the temporary table exists in the session.
+func (session *SafeSession) HasTempTable(keyspace, table string) bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return slices.Contains(session.TempTables, tempTableName(keyspace, table))
+}
+
+// GetTempTables returns the temporary tables of the session.
+func (session *SafeSession) GetTempTables() []string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return slices.Clone(session.TempTables)
+}
+
+// forgetTempTablesLocked forgets the temporary tables of a keyspace.
+func (session *SafeSession) forgetTempTablesLocked(keyspace string) {
+	prefix := keyspace + "."
+	session.TempTables = slices.DeleteFunc(session.TempTables, func(tempTable string) bool {
+		return strings.HasPrefix(tempTable, prefix)
+	})
+	session.updateTempTablesLocked()
+}
+
+// updateTempTablesLocked lets query plans be cached again once the session
+// has no temporary tables left.
+func (session *SafeSession) updateTempTablesLocked() {
+	if len(session.TempTables) == 0 && session.Options != nil {
+		session.Options.HasCreatedTempTables = false
+	}
+}
+
+func tempTableName(keyspace, table string) string {
+	return keyspace + "." + table
+}
+
 // SetDDLStrategy set the DDLStrategy setting.
 func (session *SafeSession) SetDDLStrategy(strategy string) {
 	session.mu.Lock()
@@ -744,6 +847,82 @@ func (session *SafeSession) SetMigrationContext(migrationContext string) {
 	session.MigrationContext = migrationContext
 }
 
+// SetTransactionIsolation sets the isolation level of the next transaction.
+// It can't be changed once a transaction is open. With autocommit=0, the
+// implicit transaction is only open once it has begun on a shard.
+func (session *SafeSession) SetTransactionIsolation(level querypb.ExecuteOptions_TransactionIsolation) error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.Session.InTransaction && (session.Autocommit || len(session.transactionShardsLocked()) > 0) {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "Transaction characteristics can't be changed while a transaction is in progress")
+	}
+	session.Session.TransactionIsolation = level
+	return nil
+}
+
+// ClearTransactionIsolation forgets the isolation level set for the next transaction.
+func (session *SafeSession) ClearTransactionIsolation() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.Session.TransactionIsolation = querypb.ExecuteOptions_DEFAULT
+}
+
+// ExecuteOptions returns the options sent to the tablets with the queries of the session.
+// In a transaction, the isolation level set for the transaction overrides the one of the
+// session options, so that the transaction begins with it on every shard.
+func (session *SafeSession) ExecuteOptions() *querypb.ExecuteOptions {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if !session.Session.InTransaction || session.Session.TransactionIsolation == querypb.ExecuteOptions_DEFAULT {
+		return session.Options
+	}
+	options := session.Options.CloneVT()
+	if options == nil {
+		options = &querypb.ExecuteOptions{}
+	}
+	options.TransactionIsolation = session.Session.TransactionIsolation
+	return options
+}
+
+// crossShardUnsupportedTxIsolations are the isolation levels that can't be guaranteed for a
+// transaction that spans several shards, since every shard enforces them on its own.
+var crossShardUnsupportedTxIsolations = map[querypb.ExecuteOptions_TransactionIsolation]bool{
+	querypb.ExecuteOptions_SERIALIZABLE: true,
+}
+
+// ValidateTransactionIsolation returns an error if the isolation level set for the transaction
+// isn't supported once the transaction runs on the given shards, in addition to the shards it
+// already runs on.
+func (session *SafeSession) ValidateTransactionIsolation(rss []*srvtopo.ResolvedShard) error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	level := session.Session.TransactionIsolation
+	if !session.Session.InTransaction || !crossShardUnsupportedTxIsolations[level] {
+		return nil
+	}
+	shards := session.transactionShardsLocked()
+	for _, rs := range rss {
+		shards[rs.Target.Keyspace+"/"+rs.Target.Shard] = true
+	}
+	if len(shards) > 1 {
+		return vterrors.VT12001(fmt.Sprintf("transaction isolation level %s in a cross-shard transaction", level.String()))
+	}
+	return nil
+}
+
+// transactionShardsLocked returns the keyspace/shard of the shards the transaction has begun on.
+func (session *SafeSession) transactionShardsLocked() map[string]bool {
+	shards := make(map[string]bool)
+	for _, sessions := range [][]*vtgatepb.Session_ShardSession{session.ShardSessions, session.PreSessions, session.PostSessions} {
+		for _, shardSession := range sessions {
+			if shardSession.TransactionId != 0 {
+				shards[shardSession.Target.Keyspace+"/"+shardSession.Target.Shard] = true
+			}
+		}
+	}
+	return shards
+}
+
 // GetMigrationContext returns the migration_context value.
 func (session *SafeSession) GetMigrationContext() string {
 	session.mu.Lock()
@@ -758,6 +937,20 @@ func (session *SafeSession) GetSessionUUID() string {
 	return session.SessionUUID
 }
 
+// SetKeysetContinuationToken sets the continuation token of the last query paginated with KEYSET_PAGINATE.
+func (session *SafeSession) SetKeysetContinuationToken(token string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.KeysetContinuationToken = token
+}
+
+// GetKeysetContinuationToken returns the continuation token of the last query paginated with KEYSET_PAGINATE.
+func (session *SafeSession) GetKeysetContinuationToken() string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.KeysetContinuationToken
+}
+
 // SetSessionEnableSystemSettings set the SessionEnableSystemSettings setting.
 func (session *SafeSession) SetSessionEnableSystemSettings(allow bool) {
 	session.mu.Lock()
@@ -802,6 +995,30 @@ func (session *SafeSession) SetSessionTrackGtids(enable bool) {
 	session.ReadAfterWrite.SessionTrackGtids = enable
 }
 
+// SetSessionTrackStateChange set the session_track_state_change setting.
+func (session *SafeSession) SetSessionTrackStateChange(enable bool) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.initSessionTrack()
+	session.SessionTrack.StateChange = enable
+}
+
+// SetSessionTrackSystemVariables set the session_track_system_variables setting.
+func (session *SafeSession) SetSessionTrackSystemVariables(sysVars string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.initSessionTrack()
+	session.SessionTrack.SystemVariables = sysVars
+}
+
+// initSessionTrack sets the session state tracking settings to their
+// defaults, unless they were already changed.
+func (session *SafeSession) initSessionTrack() {
+	if session.SessionTrack == nil {
+		session.SessionTrack = &vtgatepb.SessionTrack{SystemVariables: defaultSessionTrackSystemVariables}
+	}
+}
+
 func removeShard(tabletAlias *topodatapb.TabletAlias, sessions []*vtgatepb.Session_ShardSession) ([]*vtgatepb.Session_ShardSession, error) {
 	idx := -1
 	for i, session := range sessions {
@@ -858,6 +1075,19 @@ func (session *SafeSession) isTxOpen() bool {
 	return len(session.ShardSessions) > 0 || len(session.PreSessions) > 0 || len(session.PostSessions) > 0
 }
 
+// allShardSessions returns the shard sessions of all the commit orders, and
+// the lock session, if any.
+func (session *SafeSession) allShardSessions() []*vtgatepb.Session_ShardSession {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	sessions := slices.Concat(session.PreSessions, session.ShardSessions, session.PostSessions)
+	if session.LockSession != nil {
+		sessions = append(sessions, session.LockSession)
+	}
+	return sessions
+}
+
 // getSessions returns the shard session for the current commit order.
 func (session *SafeSession) getSessions() []*vtgatepb.Session_ShardSession {
 	session.mu.Lock()
@@ -98,3 +98,34 @@ func TestTimeZone(t *testing.T) {
 		})
 	}
 }
+
+func TestSafeSessionTempTables(t *testing.T) {
+	session := NewSafeSession(&vtgatepb.Session{})
+	session.AddTempTable("ks", "t1")
+	session.AddTempTable("ks", "t1")
+	session.AddTempTable("ks2", "t2")
+	assert.Equal(t, []string{"ks.t1", "ks2.t2"}, session.TempTables)
+	assert.True(t, session.HasTempTable("ks", "t1"))
+	assert.False(t, session.HasTempTable("ks2", "t1"))
+	assert.False(t, session.cachePlan())
+
+	session.RemoveTempTable("ks", "t1")
+	assert.Equal(t, []string{"ks2.t2"}, session.TempTables)
+	assert.False(t, session.cachePlan())
+
+	// Resetting the reserved connection of a keyspace forgets its temporary tables.
+	alias := &topodatapb.TabletAlias{Cell: "cell", Uid: 1}
+	session.ShardSessions = []*vtgatepb.Session_ShardSession{{
+		Target:      &querypb.Target{Keyspace: "ks2", Shard: "0"},
+		TabletAlias: alias,
+		ReservedId:  1,
+	}}
+	require.NoError(t, session.ResetShard(alias))
+	assert.Empty(t, session.TempTables)
+	assert.True(t, session.cachePlan())
+
+	session.AddTempTable("ks", "t1")
+	session.ResetAll()
+	assert.Empty(t, session.TempTables)
+	assert.True(t, session.cachePlan())
+}
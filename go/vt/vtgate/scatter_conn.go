@@ -18,6 +18,7 @@ package vtgate
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"runtime/debug"
 	"sync"
@@ -178,7 +179,7 @@ func (stc *ScatterConn) ExecuteMultiShard(
 			reservedID := info.reservedID
 
 			if session != nil && session.Session != nil {
-				opts = session.Session.Options
+				opts = session.ExecuteOptions()
 			}
 
 			if autocommit {
@@ -275,6 +276,108 @@ func (stc *ScatterConn) ExecuteMultiShard(
 	return qr, allErrors.GetErrors()
 }
 
+// ExecuteBatchMultiShard executes a batch of queries on every shard, sending the
+// whole batch of a shard in a single round trip. The results of all the queries
+// are appended together. Sessions with an open transaction or a reserved connection
+// may still need to begin or reserve on some of the shards, which the batch API cannot
+// do, so for those the batches are executed in rounds through ExecuteMultiShard instead.
+func (stc *ScatterConn) ExecuteBatchMultiShard(
+	ctx context.Context,
+	primitive engine.Primitive,
+	rss []*srvtopo.ResolvedShard,
+	queries [][]*querypb.BoundQuery,
+	session *SafeSession,
+	ignoreMaxMemoryRows bool,
+) (qr *sqltypes.Result, errs []error) {
+	if len(rss) != len(queries) {
+		return nil, []error{vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] got mismatched number of queries and shards")}
+	}
+
+	if session.InTransaction() || session.InReservedConn() {
+		return stc.executeBatchInRounds(ctx, primitive, rss, queries, session, ignoreMaxMemoryRows)
+	}
+
+	// mu protects qr
+	var mu sync.Mutex
+	qr = new(sqltypes.Result)
+
+	allErrors := stc.multiGoTransaction(
+		ctx,
+		"ExecuteBatch",
+		rss,
+		session,
+		false, /* autocommit */
+		func(rs *srvtopo.ResolvedShard, i int, info *shardActionInfo) (*shardActionInfo, error) {
+			if info.actionNeeded != nothing {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] unexpected actionNeeded on batch execution: %v", info.actionNeeded)
+			}
+			var opts *querypb.ExecuteOptions
+			if session != nil && session.Session != nil {
+				opts = session.ExecuteOptions()
+			}
+			results, err := rs.Gateway.ExecuteBatch(ctx, rs.Target, queries[i], info.transactionID, info.reservedID, opts)
+			for _, query := range queries[i] {
+				session.logging.log(primitive, rs.Target, rs.Gateway, query.Sql, false, query.BindVariables)
+			}
+			if err != nil {
+				return info, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, innerqr := range results {
+				// Don't append more rows if row count is exceeded.
+				if ignoreMaxMemoryRows || len(qr.Rows) <= maxMemoryRows {
+					qr.AppendResult(innerqr)
+				}
+			}
+			return info, nil
+		},
+	)
+
+	if !ignoreMaxMemoryRows && len(qr.Rows) > maxMemoryRows {
+		return nil, []error{vterrors.NewErrorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.NetPacketTooLarge, "in-memory row count exceeded allowed limit of %d", maxMemoryRows)}
+	}
+
+	return qr, allErrors.GetErrors()
+}
+
+// executeBatchInRounds executes the n-th query of every shard's batch in the n-th
+// round, so that every round can go through the regular ExecuteMultiShard path.
+func (stc *ScatterConn) executeBatchInRounds(
+	ctx context.Context,
+	primitive engine.Primitive,
+	rss []*srvtopo.ResolvedShard,
+	queries [][]*querypb.BoundQuery,
+	session *SafeSession,
+	ignoreMaxMemoryRows bool,
+) (*sqltypes.Result, []error) {
+	qr := new(sqltypes.Result)
+	var allErrs []error
+	for round := 0; ; round++ {
+		var roundRss []*srvtopo.ResolvedShard
+		var roundQueries []*querypb.BoundQuery
+		for i, rs := range rss {
+			if round < len(queries[i]) {
+				roundRss = append(roundRss, rs)
+				roundQueries = append(roundQueries, queries[i][round])
+			}
+		}
+		if len(roundRss) == 0 {
+			return qr, allErrs
+		}
+		innerqr, errs := stc.ExecuteMultiShard(ctx, primitive, roundRss, roundQueries, session, false /* autocommit */, ignoreMaxMemoryRows)
+		allErrs = append(allErrs, errs...)
+		if innerqr != nil {
+			qr.AppendResult(innerqr)
+		}
+		// Every round is checked on its own by ExecuteMultiShard, so the total is checked here.
+		if !ignoreMaxMemoryRows && len(qr.Rows) > maxMemoryRows {
+			return nil, []error{vterrors.NewErrorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.NetPacketTooLarge, "in-memory row count exceeded allowed limit of %d", maxMemoryRows)}
+		}
+	}
+}
+
 func (stc *ScatterConn) runLockQuery(ctx context.Context, session *SafeSession) {
 	rs := &srvtopo.ResolvedShard{Target: session.LockSession.Target, Gateway: stc.gateway}
 	query := &querypb.BoundQuery{Sql: "select 1", BindVariables: nil}
@@ -296,6 +399,13 @@ func checkAndResetShardSession(info *shardActionInfo, err error, session *SafeSe
 	}
 	if retry != none {
 		_ = session.ResetShard(info.alias)
+		if vterrors.RxReservedConnTempTablesLost.MatchString(err.Error()) {
+			session.RecordWarning(&querypb.QueryWarning{
+				Code:    uint32(sqlerror.ERQueryInterrupted),
+				Message: fmt.Sprintf("the reserved connection to %s/%s was recreated, temporary tables created in the session are lost", target.Keyspace, target.Shard),
+			})
+			warnings.Add("ReservedConnTempTablesLost", 1)
+		}
 	}
 	return retry
 }
@@ -376,7 +486,7 @@ func (stc *ScatterConn) StreamExecuteMulti(
 			reservedID := info.reservedID
 
 			if session != nil && session.Session != nil {
-				opts = session.Session.Options
+				opts = session.ExecuteOptions()
 			}
 
 			if autocommit {
@@ -636,6 +746,12 @@ func (stc *ScatterConn) multiGoTransaction(
 	if numShards == 0 {
 		return allErrors
 	}
+	if !autocommit {
+		if err := session.ValidateTransactionIsolation(rss); err != nil {
+			allErrors.RecordError(err)
+			return allErrors
+		}
+	}
 	oneShard := func(rs *srvtopo.ResolvedShard, i int) {
 		var err error
 		startTime, statsKey := stc.startAction(name, rs.Target)
@@ -776,6 +892,10 @@ func (stc *ScatterConn) ExecuteLock(ctx context.Context, rs *srvtopo.ResolvedSha
 }
 
 func wasConnectionClosed(err error) bool {
+	if vterrors.RxReservedConnLost.MatchString(err.Error()) {
+		// The tablet could not re-establish the reserved connection.
+		return true
+	}
 	sqlErr := sqlerror.NewSQLErrorFromError(err).(*sqlerror.SQLError)
 	message := sqlErr.Error()
 
@@ -108,6 +108,64 @@ func TestExecuteFailOnAutocommit(t *testing.T) {
 	utils.MustMatch(t, []*querypb.BoundQuery{queries[1]}, sbc1.Queries, "")
 }
 
+func TestExecuteBatchMultiShard(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	createSandbox("TestExecuteBatchMultiShard")
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(ctx, hc, newSandboxForCells(ctx, []string{"aa"}), "aa")
+	sbc0 := hc.AddTestTablet("aa", "0", 1, "TestExecuteBatchMultiShard", "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	sbc1 := hc.AddTestTablet("aa", "1", 1, "TestExecuteBatchMultiShard", "1", topodatapb.TabletType_PRIMARY, true, 1, nil)
+
+	rss := []*srvtopo.ResolvedShard{{
+		Target:  &querypb.Target{Keyspace: "TestExecuteBatchMultiShard", Shard: "0", TabletType: topodatapb.TabletType_PRIMARY},
+		Gateway: sbc0,
+	}, {
+		Target:  &querypb.Target{Keyspace: "TestExecuteBatchMultiShard", Shard: "1", TabletType: topodatapb.TabletType_PRIMARY},
+		Gateway: sbc1,
+	}}
+	queries := [][]*querypb.BoundQuery{{
+		{Sql: "query1", BindVariables: map[string]*querypb.BindVariable{"bv0": sqltypes.Int64BindVariable(0)}},
+		{Sql: "query2", BindVariables: map[string]*querypb.BindVariable{"bv1": sqltypes.Int64BindVariable(1)}},
+	}, {
+		{Sql: "query3", BindVariables: map[string]*querypb.BindVariable{"bv2": sqltypes.Int64BindVariable(2)}},
+	}}
+
+	// Outside of a transaction, every shard gets its whole batch in a single round trip.
+	qr, errs := sc.ExecuteBatchMultiShard(ctx, nil, rss, queries, NewSafeSession(&vtgatepb.Session{}), false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	assert.Len(t, qr.Rows, 3)
+	assert.EqualValues(t, 1, sbc0.ExecBatchCount.Load())
+	assert.EqualValues(t, 1, sbc1.ExecBatchCount.Load())
+	utils.MustMatch(t, queries[0], sbc0.Queries)
+	utils.MustMatch(t, queries[1], sbc1.Queries)
+
+	// In a transaction, the shards may need to begin first, so the batches are executed in rounds.
+	sbc0.Queries = nil
+	sbc1.Queries = nil
+	session := NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	qr, errs = sc.ExecuteBatchMultiShard(ctx, nil, rss, queries, session, false)
+	require.NoError(t, vterrors.Aggregate(errs))
+	assert.Len(t, qr.Rows, 3)
+	assert.EqualValues(t, 1, sbc0.ExecBatchCount.Load())
+	assert.EqualValues(t, 1, sbc1.ExecBatchCount.Load())
+	assert.EqualValues(t, 1, sbc0.BeginCount.Load())
+	assert.EqualValues(t, 1, sbc1.BeginCount.Load())
+	utils.MustMatch(t, queries[0], sbc0.Queries)
+	utils.MustMatch(t, queries[1], sbc1.Queries)
+	assert.Len(t, session.ShardSessions, 2)
+
+	// The row limit applies to the rows of all the rounds together.
+	save := maxMemoryRows
+	maxMemoryRows = 2
+	defer func() { maxMemoryRows = save }()
+	_, errs = sc.ExecuteBatchMultiShard(ctx, nil, rss, queries, session, false)
+	require.EqualError(t, vterrors.Aggregate(errs), "in-memory row count exceeded allowed limit of 2")
+	qr, errs = sc.ExecuteBatchMultiShard(ctx, nil, rss, queries, session, true)
+	require.NoError(t, vterrors.Aggregate(errs))
+	assert.Len(t, qr.Rows, 3)
+}
+
 func TestExecutePanic(t *testing.T) {
 	ctx := utils.LeakCheckContext(t)
 
@@ -422,6 +480,16 @@ func TestReservedConnFail(t *testing.T) {
 	assert.NotEqual(t, oldRId, session.Session.ShardSessions[0].ReservedId, "should have recreated a reserved connection since the last connection was lost")
 	oldRId = session.Session.ShardSessions[0].ReservedId
 
+	sbc0.Queries = nil
+	sbc0.EphemeralShardErr = vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, vterrors.ReservedConnTempTablesLost+": lost connection", oldRId)
+	_ = executeOnShardsReturnsErr(t, ctx, res, keyspace, sc, session, destinations)
+	assert.Equal(t, 2, len(sbc0.Queries), "one for the failed attempt, and one for the retry")
+	require.Equal(t, 1, len(session.ShardSessions))
+	assert.NotEqual(t, oldRId, session.Session.ShardSessions[0].ReservedId, "should have recreated a reserved connection since the last connection was lost")
+	require.Len(t, session.Warnings, 1)
+	assert.Contains(t, session.Warnings[0].Message, "temporary tables created in the session are lost")
+	oldRId = session.Session.ShardSessions[0].ReservedId
+
 	sbc0.Queries = nil
 	sbc0.EphemeralShardErr = vterrors.New(vtrpcpb.Code_CLUSTER_EVENT, "operation not allowed in state NOT_SERVING during query: query1")
 	_ = executeOnShardsReturnsErr(t, ctx, res, keyspace, sc, session, destinations)
@@ -521,6 +589,14 @@ func TestIsConnClosed(t *testing.T) {
 		"tx getting killed by tx killer",
 		sqlerror.NewSQLError(sqlerror.ERQueryInterrupted, sqlerror.SSUnknownSQLState, "transaction 111 in use: for tx killer rollback"),
 		true,
+	}, {
+		"reserved connection lost",
+		vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, vterrors.ReservedConnLost+": lost connection", 111),
+		true,
+	}, {
+		"reserved connection lost with temporary tables",
+		vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, vterrors.ReservedConnTempTablesLost+": lost connection", 111),
+		true,
 	}}
 
 	for _, tCase := range testCases {
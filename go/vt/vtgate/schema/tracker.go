@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"vitess.io/vitess/go/ptr"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/log"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -62,7 +63,7 @@ const defaultConsumeDelay = 1 * time.Second
 // NewTracker creates the tracker object.
 func NewTracker(ch chan *discovery.TabletHealth, enableViews bool, parser *sqlparser.Parser) *Tracker {
 	t := &Tracker{
-		ctx:          context.Background(),
+		ctx:          callerid.NewInternalContext(callerid.NewContext(context.Background(), callerid.NewInternalCallerID("SchemaTracker"), nil), "SchemaTracker"),
 		ch:           ch,
 		tables:       &tableMap{m: make(map[keyspaceStr]map[tableNameStr]*vindexes.TableInfo)},
 		tracked:      map[keyspaceStr]*updateController{},
@@ -306,7 +307,13 @@ func (t *Tracker) updateTables(keyspace string, res map[string]string) {
 
 		cols := getColumns(ddl.TableSpec)
 		fks := getForeignKeys(ddl.TableSpec)
-		t.tables.set(keyspace, tableName, cols, fks, ddl.TableSpec.Indexes)
+		t.tables.set(keyspace, tableName, &vindexes.TableInfo{
+			Columns:           cols,
+			ForeignKeys:       fks,
+			Indexes:           ddl.TableSpec.Indexes,
+			ColumnDefinitions: ddl.TableSpec.Columns,
+			TrackedAt:         time.Now(),
+		})
 	}
 }
 
@@ -430,13 +437,13 @@ type tableMap struct {
 	m map[keyspaceStr]map[tableNameStr]*vindexes.TableInfo
 }
 
-func (tm *tableMap) set(ks, tbl string, cols []vindexes.Column, fks []*sqlparser.ForeignKeyDefinition, indexes []*sqlparser.IndexDefinition) {
+func (tm *tableMap) set(ks, tbl string, info *vindexes.TableInfo) {
 	m := tm.m[ks]
 	if m == nil {
 		m = make(map[tableNameStr]*vindexes.TableInfo)
 		tm.m[ks] = m
 	}
-	m[tbl] = &vindexes.TableInfo{Columns: cols, ForeignKeys: fks, Indexes: indexes}
+	m[tbl] = info
 }
 
 func (tm *tableMap) get(ks, tbl string) *vindexes.TableInfo {
@@ -17,7 +17,10 @@ limitations under the License.
 package semantics
 
 import (
+	"strings"
+
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
@@ -61,10 +64,100 @@ func (t *typer) up(cursor *sqlparser.Cursor) error {
 			}
 		}
 		t.m[node] = code.ResolveType(inputType, t.collationEnv)
+	case *sqlparser.CollateExpr:
+		t.typeCollateExpr(node)
+	case *sqlparser.ConvertExpr:
+		t.typeConvert(node, node.Type)
+	case *sqlparser.CastExpr:
+		if !node.Array {
+			t.typeConvert(node, node.Type)
+		}
+	case *sqlparser.BinaryExpr:
+		t.typeArithmetic(node)
 	}
 	return nil
 }
 
+// convertTypes are the types of the CAST and CONVERT expressions, for the
+// target types which do not depend on the converted expression.
+var convertTypes = map[string]sqltypes.Type{
+	"binary":           sqltypes.VarBinary,
+	"signed":           sqltypes.Int64,
+	"signed integer":   sqltypes.Int64,
+	"unsigned":         sqltypes.Uint64,
+	"unsigned integer": sqltypes.Uint64,
+	"decimal":          sqltypes.Decimal,
+	"double":           sqltypes.Float64,
+	"float":            sqltypes.Float32,
+	"real":             sqltypes.Float64,
+	"date":             sqltypes.Date,
+	"datetime":         sqltypes.Datetime,
+	"time":             sqltypes.Time,
+}
+
+// typeCollateExpr types an expression with an explicit collation: only strings
+// can have one, so the expression is a string even if the type of its operand
+// is unknown.
+func (t *typer) typeCollateExpr(node *sqlparser.CollateExpr) {
+	coll := t.collationEnv.LookupByName(node.Collation)
+	if coll == collations.Unknown {
+		return
+	}
+	typ := sqltypes.VarChar
+	if inner, ok := t.m[node.Expr]; ok && sqltypes.IsText(inner.Type()) {
+		typ = inner.Type()
+	}
+	if coll == collations.CollationBinaryID {
+		typ = sqltypes.VarBinary
+	}
+	t.m[node] = evalengine.NewType(typ, coll)
+}
+
+func (t *typer) typeConvert(node sqlparser.Expr, convertType *sqlparser.ConvertType) {
+	typ, ok := convertTypes[strings.ToLower(convertType.Type)]
+	if !ok {
+		return
+	}
+	// Like for the FLOAT columns, a FLOAT with a precision above 24 is a DOUBLE.
+	if typ == sqltypes.Float32 && convertType.Length != nil && *convertType.Length > 24 {
+		typ = sqltypes.Float64
+	}
+	t.m[node] = evalengine.NewType(typ, collations.CollationForType(typ, t.collationEnv.DefaultConnectionCharset()))
+}
+
+// typeArithmetic types the arithmetic operations of numbers. The operations of
+// other types, or of unknown types, are left untyped.
+func (t *typer) typeArithmetic(node *sqlparser.BinaryExpr) {
+	left, lok := t.m[node.Left]
+	right, rok := t.m[node.Right]
+	if !lok || !rok || !sqltypes.IsNumber(left.Type()) || !sqltypes.IsNumber(right.Type()) {
+		return
+	}
+
+	var typ sqltypes.Type
+	switch node.Operator {
+	case sqlparser.IntDivOp:
+		typ = sqltypes.Int64
+		if sqltypes.IsUnsigned(left.Type()) || sqltypes.IsUnsigned(right.Type()) {
+			typ = sqltypes.Uint64
+		}
+	case sqlparser.PlusOp, sqlparser.MinusOp, sqlparser.MultOp, sqlparser.DivOp, sqlparser.ModOp:
+		switch {
+		case sqltypes.IsFloat(left.Type()) || sqltypes.IsFloat(right.Type()):
+			typ = sqltypes.Float64
+		case node.Operator == sqlparser.DivOp || left.Type() == sqltypes.Decimal || right.Type() == sqltypes.Decimal:
+			typ = sqltypes.Decimal
+		case sqltypes.IsUnsigned(left.Type()) || sqltypes.IsUnsigned(right.Type()):
+			typ = sqltypes.Uint64
+		default:
+			typ = sqltypes.Int64
+		}
+	default:
+		return
+	}
+	t.m[node] = evalengine.NewType(typ, collations.CollationBinaryID)
+}
+
 func (t *typer) setTypeFor(node *sqlparser.ColName, typ evalengine.Type) {
 	t.m[node] = typ
 }
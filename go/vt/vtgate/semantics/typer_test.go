@@ -92,3 +92,47 @@ func TestColumnCollations(t *testing.T) {
 		})
 	}
 }
+
+func TestExpressionTypes(t *testing.T) {
+	tests := []struct {
+		expr, typ         string
+		needsWeightString bool
+	}{
+		{expr: "textcol", typ: "VARCHAR", needsWeightString: true},
+		{expr: "textcol collate utf8mb4_bin", typ: "VARCHAR"},
+		{expr: "textcol collate binary", typ: "VARBINARY"},
+		{expr: "uid collate utf8mb4_bin", typ: "VARCHAR"},
+		{expr: "cast(textcol as binary)", typ: "VARBINARY"},
+		{expr: "binary textcol", typ: "VARBINARY"},
+		{expr: "cast(textcol as signed)", typ: "INT64"},
+		{expr: "convert(textcol, unsigned)", typ: "UINT64"},
+		{expr: "cast(textcol as float)", typ: "FLOAT32"},
+		{expr: "cast(textcol as float(30))", typ: "FLOAT64"},
+		{expr: "cast(textcol as double)", typ: "FLOAT64"},
+		{expr: "uid + uid", typ: "INT64"},
+		{expr: "uid / 2", typ: "DECIMAL"},
+		{expr: "uid div 2", typ: "INT64"},
+		{expr: "uid * 1.5e0", typ: "FLOAT64"},
+		{expr: "textcol + 1", needsWeightString: true},
+		{expr: "cast(textcol as char)", needsWeightString: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			parse, err := sqlparser.NewTestParser().Parse("select " + test.expr + " from t2")
+			require.NoError(t, err)
+
+			st, err := Analyze(parse, "d", fakeSchemaInfo())
+			require.NoError(t, err)
+			expr := extract(parse.(*sqlparser.Select), 0)
+			typ, found := st.TypeForExpr(expr)
+			if test.typ == "" {
+				require.False(t, found, "expression was typed")
+			} else {
+				require.True(t, found, "expression was not typed")
+				require.Equal(t, test.typ, typ.Type().String())
+			}
+			require.Equal(t, test.needsWeightString, st.NeedsWeightString(expr))
+		})
+	}
+}
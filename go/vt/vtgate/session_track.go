@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"maps"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sysvars"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// defaultSessionTrackSystemVariables is the default value of
+// session_track_system_variables, as in MySQL.
+const defaultSessionTrackSystemVariables = "time_zone,autocommit,character_set_client,character_set_results,character_set_connection"
+
+// sessionTrackSystemVariables returns the system variables whose changes
+// are reported to the client, as a comma separated list, or * for all.
+func sessionTrackSystemVariables(session *vtgatepb.Session) string {
+	if sessionTrack := session.GetSessionTrack(); sessionTrack != nil {
+		return sessionTrack.SystemVariables
+	}
+	return defaultSessionTrackSystemVariables
+}
+
+// sessionState is a snapshot of the parts of the session state whose
+// changes the clients can track.
+type sessionState struct {
+	autocommit           bool
+	targetString         string
+	systemVariables      map[string]string
+	userDefinedVariables map[string]*querypb.BindVariable
+	trackVariables       map[string]string
+}
+
+// newSessionState takes a snapshot of the state of the session.
+func newSessionState(session *vtgatepb.Session) *sessionState {
+	return &sessionState{
+		autocommit:           session.Autocommit,
+		targetString:         session.TargetString,
+		systemVariables:      maps.Clone(session.SystemVariables),
+		userDefinedVariables: maps.Clone(session.UserDefinedVariables),
+		trackVariables:       sessionTrackVariables(session),
+	}
+}
+
+// sessionTrackVariables returns the values of the Vitess aware system
+// variables that change the tracking of the session state.
+func sessionTrackVariables(session *vtgatepb.Session) map[string]string {
+	trackGtids := "OFF"
+	if session.GetReadAfterWrite().GetSessionTrackGtids() {
+		trackGtids = "OWN_GTID"
+	}
+	stateChange := "OFF"
+	if session.GetSessionTrack().GetStateChange() {
+		stateChange = "ON"
+	}
+	return map[string]string{
+		sysvars.SessionTrackGTIDs.Name:           trackGtids,
+		sysvars.SessionTrackStateChange.Name:     stateChange,
+		sysvars.SessionTrackSystemVariables.Name: sessionTrackSystemVariables(session),
+	}
+}
+
+// sessionStateChanges returns the changes of the session since the snapshot
+// that the client tracks, or nil if there are none. Like MySQL, it reports
+// the new values of the tracked system variables that were set, and whether
+// any system variable, user defined variable or the default database changed.
+func sessionStateChanges(before *sessionState, session *vtgatepb.Session) *mysql.SessionStateChanges {
+	after := newSessionState(session)
+	changed := make(map[string]string)
+	for name, value := range after.systemVariables {
+		if prev, ok := before.systemVariables[name]; !ok || prev != value {
+			changed[name] = sysVarValue(value)
+		}
+	}
+	if before.autocommit != after.autocommit {
+		autocommit := "OFF"
+		if after.autocommit {
+			autocommit = "ON"
+		}
+		changed[sysvars.Autocommit.Name] = autocommit
+	}
+	for name, value := range after.trackVariables {
+		if before.trackVariables[name] != value {
+			changed[name] = value
+		}
+	}
+
+	changes := &mysql.SessionStateChanges{}
+	tracked := strings.Split(strings.ToLower(sessionTrackSystemVariables(session)), ",")
+	for name, value := range changed {
+		for _, t := range tracked {
+			if t = strings.TrimSpace(t); t == "*" || t == name {
+				changes.SystemVariables = append(changes.SystemVariables, mysql.SessionSystemVariable{Name: name, Value: value})
+				break
+			}
+		}
+	}
+	sort.Slice(changes.SystemVariables, func(i, j int) bool {
+		return changes.SystemVariables[i].Name < changes.SystemVariables[j].Name
+	})
+
+	if session.GetSessionTrack().GetStateChange() {
+		changes.StateChanged = len(changed) > 0 ||
+			before.targetString != after.targetString ||
+			!maps.EqualFunc(before.userDefinedVariables, after.userDefinedVariables, func(a, b *querypb.BindVariable) bool {
+				return proto.Equal(a, b)
+			})
+	}
+	if len(changes.SystemVariables) == 0 && !changes.StateChanged {
+		return nil
+	}
+	return changes
+}
+
+// sysVarValue returns the value of a system variable of the session, which
+// is kept encoded as SQL.
+func sysVarValue(value string) string {
+	if decoded, err := sqltypes.DecodeStringSQL(value); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// snapshotSessionState takes a snapshot of the state of the session before
+// a query, if the client of the connection tracks its changes. Otherwise it
+// returns nil.
+func snapshotSessionState(c *mysql.Conn, session *vtgatepb.Session) *sessionState {
+	// The changes of the previous query must not be reported again.
+	c.SessionStateChanges = nil
+	if c.Capabilities&mysql.CapabilityClientSessionTrack == 0 {
+		return nil
+	}
+	return newSessionState(session)
+}
+
+// trackSessionState reports the changes of the session since the snapshot
+// to the client of the connection, if it tracks them. The GTIDs of the
+// result are only reported if the session tracks them.
+func trackSessionState(c *mysql.Conn, before *sessionState, session *vtgatepb.Session, result *sqltypes.Result) {
+	if before == nil {
+		return
+	}
+	c.SessionStateChanges = sessionStateChanges(before, session)
+	if result != nil && !session.GetReadAfterWrite().GetSessionTrackGtids() {
+		result.SessionStateChanges = ""
+	}
+}
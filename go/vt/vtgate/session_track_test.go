@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestSessionStateChanges(t *testing.T) {
+	executor, _, _, _, ctx := createExecutorEnv(t)
+
+	testCases := []struct {
+		name    string
+		setup   string
+		query   string
+		changes *mysql.SessionStateChanges
+	}{{
+		name:  "untracked variable",
+		query: "set @@sql_select_limit = 10",
+	}, {
+		name:  "tracked variable",
+		query: "set autocommit = 0",
+		changes: &mysql.SessionStateChanges{
+			SystemVariables: []mysql.SessionSystemVariable{{Name: "autocommit", Value: "OFF"}},
+		},
+	}, {
+		name:  "tracked variable set to the same value",
+		query: "set autocommit = 1",
+	}, {
+		name:  "all variables tracked",
+		setup: "set session_track_system_variables = '*'",
+		query: "set session_track_gtids = own_gtid, autocommit = 0",
+		changes: &mysql.SessionStateChanges{
+			SystemVariables: []mysql.SessionSystemVariable{{Name: "autocommit", Value: "OFF"}, {Name: "session_track_gtids", Value: "OWN_GTID"}},
+		},
+	}, {
+		name:  "state change of a user defined variable",
+		setup: "set session_track_state_change = 1",
+		query: "set @foo = 'bar'",
+		changes: &mysql.SessionStateChanges{
+			StateChanged: true,
+		},
+	}, {
+		name:  "state change of the default database",
+		setup: "set session_track_state_change = 1, session_track_system_variables = ''",
+		query: "use TestExecutor",
+		changes: &mysql.SessionStateChanges{
+			StateChanged: true,
+		},
+	}, {
+		name:  "no state change",
+		setup: "set session_track_state_change = 1",
+		query: "select id from user where id = 1",
+	}, {
+		name:  "state change of a tracked variable",
+		setup: "set session_track_state_change = 1",
+		query: "set autocommit = 0",
+		changes: &mysql.SessionStateChanges{
+			SystemVariables: []mysql.SessionSystemVariable{{Name: "autocommit", Value: "OFF"}},
+			StateChanged:    true,
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			session := NewAutocommitSession(&vtgatepb.Session{})
+			if tc.setup != "" {
+				_, err := executor.Execute(ctx, nil, "TestSessionStateChanges", session, tc.setup, nil)
+				require.NoError(t, err)
+			}
+			before := newSessionState(session.Session)
+			_, err := executor.Execute(ctx, nil, "TestSessionStateChanges", session, tc.query, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.changes, sessionStateChanges(before, session.Session))
+		})
+	}
+}
+
+func TestTrackSessionState(t *testing.T) {
+	session := &vtgatepb.Session{Autocommit: true}
+	result := &sqltypes.Result{SessionStateChanges: "uuid:1-5"}
+
+	// The clients that don't track the session state get nothing.
+	c := &mysql.Conn{SessionStateChanges: &mysql.SessionStateChanges{StateChanged: true}}
+	state := snapshotSessionState(c, session)
+	assert.Nil(t, state)
+	assert.Nil(t, c.SessionStateChanges)
+
+	// The GTIDs are only reported if the session tracks them.
+	c.Capabilities |= mysql.CapabilityClientSessionTrack
+	state = snapshotSessionState(c, session)
+	require.NotNil(t, state)
+	session.Autocommit = false
+	trackSessionState(c, state, session, result)
+	assert.Equal(t, &mysql.SessionStateChanges{
+		SystemVariables: []mysql.SessionSystemVariable{{Name: "autocommit", Value: "OFF"}},
+	}, c.SessionStateChanges)
+	assert.Empty(t, result.SessionStateChanges)
+
+	session.ReadAfterWrite = &vtgatepb.ReadAfterWrite{SessionTrackGtids: true}
+	result.SessionStateChanges = "uuid:1-5"
+	state = snapshotSessionState(c, session)
+	trackSessionState(c, state, session, result)
+	assert.Nil(t, c.SessionStateChanges)
+	assert.Equal(t, "uuid:1-5", result.SessionStateChanges)
+}
+
+func TestSysVarValue(t *testing.T) {
+	assert.Equal(t, "+01:00", sysVarValue("'+01:00'"))
+	assert.Equal(t, "it's", sysVarValue(`'it\'s'`))
+	assert.Equal(t, "10", sysVarValue("10"))
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+// otherTable is the table label used for the tables that are not tracked
+// individually once --table-stats-max-tables is reached.
+const otherTable = "other"
+
+var (
+	// tableStatsMaxTables is the number of distinct keyspace and table pairs
+	// for which per table query stats are kept. 0 disables them.
+	tableStatsMaxTables = 1000
+
+	tableStatsLabels = []string{"Keyspace", "Table", "Plan", "TabletType"}
+
+	queryTimingsByTable = stats.NewMultiTimings(
+		"QueryTimingsByTable",
+		"Query latencies at vtgate by keyspace, table, plan type and tablet type",
+		tableStatsLabels)
+
+	queryErrorsByTable = stats.NewCountersWithMultiLabels(
+		"QueryErrorsByTable",
+		"Query errors at vtgate by keyspace, table, plan type and tablet type",
+		tableStatsLabels)
+
+	tableStatsOverflow = stats.NewCounter(
+		"QueryTableStatsOverflow",
+		"Number of times a table used by a query was counted as other because --table-stats-max-tables tables were already tracked")
+
+	trackedTables = newTableTracker()
+)
+
+// tableTracker bounds the cardinality of the per table query stats by
+// remembering the tables that are tracked individually.
+type tableTracker struct {
+	mu     sync.Mutex
+	tables map[string]struct{}
+}
+
+func newTableTracker() *tableTracker {
+	return &tableTracker{tables: make(map[string]struct{})}
+}
+
+// label returns the table label to use for keyspace.table: the table itself
+// if it is, or can still be, tracked, and otherTable if the limit is reached.
+func (tt *tableTracker) label(keyspace, table string, maxTables int) string {
+	key := keyspace + "." + table
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if _, ok := tt.tables[key]; ok {
+		return table
+	}
+	if len(tt.tables) >= maxTables {
+		return otherTable
+	}
+	tt.tables[key] = struct{}{}
+	return table
+}
+
+// recordTableStats updates the per table query stats with the execution of
+// a plan. A query that uses several tables is counted for each of them. The
+// queries that Vitess generates for itself aren't counted.
+func recordTableStats(ctx context.Context, plan *engine.Plan, tabletType string, elapsed time.Duration, err error) {
+	if tableStatsMaxTables <= 0 || plan == nil || plan.Instructions == nil {
+		return
+	}
+	if callerid.InternalComponentFromContext(ctx) != "" {
+		return
+	}
+	planType := plan.Instructions.RouteType()
+	for _, tableUsed := range plan.TablesUsed {
+		keyspace, table, ok := strings.Cut(tableUsed, ".")
+		if !ok {
+			keyspace, table = plan.Instructions.GetKeyspaceName(), tableUsed
+		}
+		label := trackedTables.label(keyspace, table, tableStatsMaxTables)
+		if label == otherTable {
+			tableStatsOverflow.Add(1)
+		}
+		names := []string{keyspace, label, planType, tabletType}
+		queryTimingsByTable.Add(names, elapsed)
+		if err != nil {
+			queryErrorsByTable.Add(names, 1)
+		}
+	}
+}
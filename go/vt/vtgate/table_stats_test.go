@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/callerid"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestTableTrackerLimit(t *testing.T) {
+	tt := newTableTracker()
+	assert.Equal(t, "t1", tt.label("ks", "t1", 2))
+	assert.Equal(t, "t2", tt.label("ks", "t2", 2))
+	assert.Equal(t, otherTable, tt.label("ks", "t3", 2))
+	assert.Equal(t, otherTable, tt.label("ks2", "t1", 2))
+	// Tables that are already tracked keep their own label.
+	assert.Equal(t, "t1", tt.label("ks", "t1", 2))
+	assert.Equal(t, "t2", tt.label("ks", "t2", 2))
+}
+
+func TestExecutorTableStats(t *testing.T) {
+	executor, sbc1, _, _, ctx := createExecutorEnv(t)
+	session := &vtgatepb.Session{TargetString: "@primary"}
+
+	key := "TestExecutor.user.EqualUnique.PRIMARY"
+	countsBefore := queryTimingsByTable.Counts()[key]
+	errorsBefore := queryErrorsByTable.Counts()[key]
+	userQueries := queriesProcessedByOrigin.Counts()["user.EqualUnique"]
+	internalQueries := queriesProcessedByOrigin.Counts()["VindexLookup.EqualUnique"]
+
+	_, err := executorExec(ctx, executor, session, "select id from `user` where id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, countsBefore+1, queryTimingsByTable.Counts()[key])
+	assert.Equal(t, errorsBefore, queryErrorsByTable.Counts()[key])
+
+	sbc1.MustFailCodes[vtrpcpb.Code_INVALID_ARGUMENT] = 1
+	_, err = executorExec(ctx, executor, session, "select id from `user` where id = 1", nil)
+	require.Error(t, err)
+	assert.Equal(t, countsBefore+2, queryTimingsByTable.Counts()[key])
+	assert.Equal(t, errorsBefore+1, queryErrorsByTable.Counts()[key])
+
+	_, err = executorStream(ctx, executor, "select id from `user` where id = 1")
+	require.NoError(t, err)
+	assert.Equal(t, countsBefore+3, queryTimingsByTable.Counts()[key])
+
+	// The queries that vtgate generates for itself aren't counted, but they
+	// are told apart from the queries of the users.
+	_, err = executorExec(callerid.NewInternalContext(ctx, "VindexLookup"), executor, session, "select id from `user` where id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, countsBefore+3, queryTimingsByTable.Counts()[key])
+	assert.Equal(t, userQueries+3, queriesProcessedByOrigin.Counts()["user.EqualUnique"])
+	assert.Equal(t, internalQueries+1, queriesProcessedByOrigin.Counts()["VindexLookup.EqualUnique"])
+
+	// No per table stats are kept when they are disabled.
+	defer func(maxTables int) { tableStatsMaxTables = maxTables }(tableStatsMaxTables)
+	tableStatsMaxTables = 0
+	_, err = executorExec(ctx, executor, session, "select id from `user` where id = 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, countsBefore+3, queryTimingsByTable.Counts()[key])
+}
@@ -0,0 +1,367 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+var (
+	enableTabletCircuitBreaker bool
+	tabletCircuitBreakerConfig = circuitBreakerConfig{
+		errorRate:          0.5,
+		minRequests:        20,
+		window:             10 * time.Second,
+		ejectionDuration:   30 * time.Second,
+		maxEjectionPercent: 50,
+	}
+
+	circuitBreakerEjections = stats.NewCountersWithMultiLabels(
+		"TabletCircuitBreakerEjections",
+		"Number of times a tablet was ejected from the routing pool by its circuit breaker",
+		[]string{"Keyspace", "ShardName", "DbType", "Reason"})
+	circuitBreakerEjected = stats.NewGaugesWithMultiLabels(
+		"TabletCircuitBreakerEjectedTablets",
+		"Number of tablets currently ejected from the routing pool by their circuit breaker",
+		[]string{"Keyspace", "ShardName", "DbType"})
+)
+
+// The reasons a tablet is ejected for.
+const (
+	ejectionReasonErrorRate   = "ErrorRate"
+	ejectionReasonLatency     = "Latency"
+	ejectionReasonFailedProbe = "FailedProbe"
+)
+
+func init() {
+	servenv.OnParseFor("vtgate", func(fs *pflag.FlagSet) {
+		fs.BoolVar(&enableTabletCircuitBreaker, "enable-tablet-circuit-breaker", enableTabletCircuitBreaker, "Eject the tablets that have elevated error rates or latencies from the routing pool for a while, independently of their health check status.")
+		fs.Float64Var(&tabletCircuitBreakerConfig.errorRate, "tablet-circuit-breaker-error-rate", tabletCircuitBreakerConfig.errorRate, "Fraction of the requests of a tablet that fail within the window above which its circuit breaker ejects it. 0 disables the error rate check.")
+		fs.DurationVar(&tabletCircuitBreakerConfig.latency, "tablet-circuit-breaker-latency", tabletCircuitBreakerConfig.latency, "Average latency of the requests of a tablet within the window above which its circuit breaker ejects it. 0 disables the latency check.")
+		fs.IntVar(&tabletCircuitBreakerConfig.minRequests, "tablet-circuit-breaker-min-requests", tabletCircuitBreakerConfig.minRequests, "Minimum number of requests to a tablet within the window before its circuit breaker can eject it.")
+		fs.DurationVar(&tabletCircuitBreakerConfig.window, "tablet-circuit-breaker-window", tabletCircuitBreakerConfig.window, "Window over which the error rate and latency of a tablet are measured.")
+		fs.DurationVar(&tabletCircuitBreakerConfig.ejectionDuration, "tablet-circuit-breaker-ejection-duration", tabletCircuitBreakerConfig.ejectionDuration, "Time a tablet stays ejected before its circuit breaker lets a probe request through.")
+		fs.IntVar(&tabletCircuitBreakerConfig.maxEjectionPercent, "tablet-circuit-breaker-max-ejection-percent", tabletCircuitBreakerConfig.maxEjectionPercent, "Maximum percentage of the healthy tablets of a keyspace/shard/tablet type that can be ejected at the same time.")
+	})
+}
+
+// circuitBreakerConfig configures when the circuit breakers eject tablets.
+type circuitBreakerConfig struct {
+	// errorRate is the fraction of failed requests that ejects a tablet.
+	errorRate float64
+	// latency is the average latency that ejects a tablet.
+	latency time.Duration
+	// minRequests is the number of requests needed within a window to eject a tablet.
+	minRequests int
+	// window is the duration over which the requests are measured.
+	window time.Duration
+	// ejectionDuration is the time a tablet is ejected before it is probed.
+	ejectionDuration time.Duration
+	// maxEjectionPercent caps the share of the healthy tablets of a target that are ejected.
+	maxEjectionPercent int
+}
+
+type circuitState int
+
+const (
+	// circuitClosed lets the requests through.
+	circuitClosed circuitState = iota
+	// circuitOpen ejects the tablet until the ejection ends.
+	circuitOpen
+	// circuitHalfOpen lets a single probe request through, which closes
+	// the circuit if it succeeds, or opens it again if it fails.
+	circuitHalfOpen
+)
+
+// tabletCircuitBreaker measures the requests of a tablet over a window.
+type tabletCircuitBreaker struct {
+	alias  *topodatapb.TabletAlias
+	labels []string
+	state  circuitState
+
+	windowStart time.Time
+	requests    int
+	errors      int
+	latency     time.Duration
+
+	ejectedUntil time.Time
+	probing      bool
+}
+
+// tabletCircuitBreakers are the circuit breakers of the tablets the
+// gateway routes requests to.
+type tabletCircuitBreakers struct {
+	config circuitBreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*tabletCircuitBreaker
+}
+
+func newTabletCircuitBreakers(config circuitBreakerConfig) *tabletCircuitBreakers {
+	return &tabletCircuitBreakers{
+		config:   config,
+		now:      time.Now,
+		breakers: make(map[string]*tabletCircuitBreaker),
+	}
+}
+
+// allow returns whether a request can be routed to the tablet. When the
+// ejection of the tablet has ended, the request is the probe of the tablet.
+func (cbs *tabletCircuitBreakers) allow(tablet *topodatapb.Tablet) bool {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	cb, ok := cbs.breakers[topoproto.TabletAliasString(tablet.Alias)]
+	if !ok {
+		return true
+	}
+	switch cb.state {
+	case circuitOpen:
+		if cbs.now().Before(cb.ejectedUntil) {
+			return false
+		}
+		cb.state = circuitHalfOpen
+		cb.probing = true
+		return true
+	case circuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a request to the tablet, and ejects the
+// tablet if it is an outlier. healthy is the number of healthy tablets of
+// the target of the request.
+func (cbs *tabletCircuitBreakers) record(target *querypb.Target, tablet *topodatapb.Tablet, healthy int, elapsed time.Duration, err error) {
+	failed := isTabletError(err)
+
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	alias := topoproto.TabletAliasString(tablet.Alias)
+	now := cbs.now()
+	cb, ok := cbs.breakers[alias]
+	if !ok {
+		cb = &tabletCircuitBreaker{
+			alias:       tablet.Alias,
+			labels:      []string{target.Keyspace, target.Shard, topoproto.TabletTypeLString(target.TabletType)},
+			windowStart: now,
+		}
+		cbs.breakers[alias] = cb
+	}
+
+	switch cb.state {
+	case circuitOpen:
+		// The request was routed before the tablet was ejected.
+		return
+	case circuitHalfOpen:
+		cb.probing = false
+		if failed {
+			cbs.eject(alias, cb, ejectionReasonFailedProbe)
+			return
+		}
+		log.Infof("Circuit breaker of tablet %s closed after a successful probe", alias)
+		cb.state = circuitClosed
+		circuitBreakerEjected.Add(cb.labels, -1)
+		cb.reset(now)
+		return
+	}
+
+	if now.Sub(cb.windowStart) >= cbs.config.window {
+		cb.reset(now)
+	}
+	cb.requests++
+	cb.latency += elapsed
+	if failed {
+		cb.errors++
+	}
+	if cb.requests < cbs.config.minRequests {
+		return
+	}
+
+	var reason string
+	switch {
+	case cbs.config.errorRate > 0 && float64(cb.errors) >= cbs.config.errorRate*float64(cb.requests):
+		reason = ejectionReasonErrorRate
+	case cbs.config.latency > 0 && cb.latency >= cbs.config.latency*time.Duration(cb.requests):
+		reason = ejectionReasonLatency
+	default:
+		return
+	}
+	if cbs.ejectedLocked(cb.labels) >= healthy*cbs.config.maxEjectionPercent/100 {
+		return
+	}
+	circuitBreakerEjected.Add(cb.labels, 1)
+	cbs.eject(alias, cb, reason)
+}
+
+// removeStale removes the circuit breakers of the tablets that left the health
+// check, or that now serve another target. targetOf returns the target of a
+// tablet in the health check, or nil if the tablet is not in it anymore.
+func (cbs *tabletCircuitBreakers) removeStale(targetOf func(alias *topodatapb.TabletAlias) *querypb.Target) {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	for alias, cb := range cbs.breakers {
+		target := targetOf(cb.alias)
+		if target != nil && target.Keyspace == cb.labels[0] && target.Shard == cb.labels[1] && topoproto.TabletTypeLString(target.TabletType) == cb.labels[2] {
+			continue
+		}
+		if cb.state != circuitClosed {
+			circuitBreakerEjected.Add(cb.labels, -1)
+		}
+		delete(cbs.breakers, alias)
+	}
+}
+
+// watchCircuitBreakers periodically removes the circuit breakers of the tablets
+// that left the health check, until the context is done.
+func (gw *TabletGateway) watchCircuitBreakers(ctx context.Context) {
+	ticker := time.NewTicker(gw.circuitBreakers.config.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			gw.removeStaleCircuitBreakers()
+		}
+	}
+}
+
+// removeStaleCircuitBreakers removes the circuit breakers of the tablets that
+// are not in the health check anymore.
+func (gw *TabletGateway) removeStaleCircuitBreakers() {
+	gw.circuitBreakers.removeStale(func(alias *topodatapb.TabletAlias) *querypb.Target {
+		th, err := gw.hc.GetTabletHealthByAlias(alias)
+		if err != nil {
+			return nil
+		}
+		return th.Target
+	})
+}
+
+// eject opens the circuit of the tablet.
+func (cbs *tabletCircuitBreakers) eject(alias string, cb *tabletCircuitBreaker, reason string) {
+	log.Warningf("Circuit breaker of tablet %s ejected it from the routing pool for %v: %s (%d errors in %d requests)", alias, cbs.config.ejectionDuration, reason, cb.errors, cb.requests)
+	cb.state = circuitOpen
+	cb.ejectedUntil = cbs.now().Add(cbs.config.ejectionDuration)
+	circuitBreakerEjections.Add(append(cb.labels, reason), 1)
+}
+
+// ejectedLocked returns the number of ejected tablets of the target with the given labels.
+func (cbs *tabletCircuitBreakers) ejectedLocked(labels []string) int {
+	ejected := 0
+	for _, cb := range cbs.breakers {
+		if cb.state != circuitClosed && cb.labels[0] == labels[0] && cb.labels[1] == labels[1] && cb.labels[2] == labels[2] {
+			ejected++
+		}
+	}
+	return ejected
+}
+
+func (cb *tabletCircuitBreaker) reset(now time.Time) {
+	cb.windowStart = now
+	cb.requests = 0
+	cb.errors = 0
+	cb.latency = 0
+}
+
+// isTabletError returns whether the error is a failure of the tablet, as
+// opposed to an error caused by the request itself. Timeouts and unknown
+// errors are not counted, since they also cover slow queries, lock wait
+// timeouts and the MySQL errors of the application.
+func isTabletError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_RESOURCE_EXHAUSTED, vtrpcpb.Code_INTERNAL:
+		return true
+	}
+	return false
+}
+
+// firstResultConn records when the first result of a stream is received, so
+// that the latency of a stream is measured up to its first result rather than
+// over the whole stream, whose duration depends on the size of the result.
+type firstResultConn struct {
+	queryservice.QueryService
+	firstResult time.Time
+}
+
+// latency returns the time from start to the first result of the stream,
+// or until now if the request was not a stream or returned no result.
+func (c *firstResultConn) latency(start time.Time) time.Duration {
+	if c.firstResult.IsZero() {
+		return time.Since(start)
+	}
+	return c.firstResult.Sub(start)
+}
+
+func (c *firstResultConn) onResult(callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	return func(qr *sqltypes.Result) error {
+		if c.firstResult.IsZero() {
+			c.firstResult = time.Now()
+		}
+		return callback(qr)
+	}
+}
+
+// StreamExecute is part of the queryservice.QueryService interface.
+func (c *firstResultConn) StreamExecute(ctx context.Context, target *querypb.Target, query string, bindVars map[string]*querypb.BindVariable, transactionID int64, reservedID int64, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) error {
+	return c.QueryService.StreamExecute(ctx, target, query, bindVars, transactionID, reservedID, options, c.onResult(callback))
+}
+
+// BeginStreamExecute is part of the queryservice.QueryService interface.
+func (c *firstResultConn) BeginStreamExecute(ctx context.Context, target *querypb.Target, preQueries []string, query string, bindVars map[string]*querypb.BindVariable, reservedID int64, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) (queryservice.TransactionState, error) {
+	return c.QueryService.BeginStreamExecute(ctx, target, preQueries, query, bindVars, reservedID, options, c.onResult(callback))
+}
+
+// ReserveStreamExecute is part of the queryservice.QueryService interface.
+func (c *firstResultConn) ReserveStreamExecute(ctx context.Context, target *querypb.Target, preQueries []string, sql string, bindVariables map[string]*querypb.BindVariable, transactionID int64, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) (queryservice.ReservedState, error) {
+	return c.QueryService.ReserveStreamExecute(ctx, target, preQueries, sql, bindVariables, transactionID, options, c.onResult(callback))
+}
+
+// ReserveBeginStreamExecute is part of the queryservice.QueryService interface.
+func (c *firstResultConn) ReserveBeginStreamExecute(ctx context.Context, target *querypb.Target, preQueries []string, postBeginQueries []string, sql string, bindVariables map[string]*querypb.BindVariable, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) (queryservice.ReservedTransactionState, error) {
+	return c.QueryService.ReserveBeginStreamExecute(ctx, target, preQueries, postBeginQueries, sql, bindVariables, options, c.onResult(callback))
+}
+
+// MessageStream is part of the queryservice.QueryService interface.
+func (c *firstResultConn) MessageStream(ctx context.Context, target *querypb.Target, name string, callback func(*sqltypes.Result) error) error {
+	return c.QueryService.MessageStream(ctx, target, name, c.onResult(callback))
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func newTestCircuitBreakers(now *time.Time) *tabletCircuitBreakers {
+	cbs := newTabletCircuitBreakers(circuitBreakerConfig{
+		errorRate:          0.5,
+		latency:            time.Second,
+		minRequests:        4,
+		window:             10 * time.Second,
+		ejectionDuration:   30 * time.Second,
+		maxEjectionPercent: 50,
+	})
+	cbs.now = func() time.Time { return *now }
+	return cbs
+}
+
+func TestTabletCircuitBreakerErrorRate(t *testing.T) {
+	now := time.Now()
+	cbs := newTestCircuitBreakers(&now)
+	target := &querypb.Target{Keyspace: "cb_errors", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	tablet1 := topo.NewTablet(1, "cell", "host1")
+	tablet2 := topo.NewTablet(2, "cell", "host2")
+	failure := vterrors.New(vtrpcpb.Code_UNAVAILABLE, "unavailable")
+
+	// The requests of the previous window are forgotten.
+	cbs.record(target, tablet1, 2, time.Millisecond, failure)
+	cbs.record(target, tablet1, 2, time.Millisecond, failure)
+	now = now.Add(10 * time.Second)
+
+	// Errors caused by the requests are not failures of the tablet.
+	cbs.record(target, tablet1, 2, time.Millisecond, nil)
+	cbs.record(target, tablet1, 2, time.Millisecond, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "bad query"))
+	cbs.record(target, tablet1, 2, time.Millisecond, vterrors.New(vtrpcpb.Code_DEADLINE_EXCEEDED, "lock wait timeout exceeded"))
+	cbs.record(target, tablet1, 2, time.Millisecond, errors.New("unmapped mysql error"))
+	for i := 0; i < 3; i++ {
+		cbs.record(target, tablet1, 2, time.Millisecond, failure)
+	}
+	assert.True(t, cbs.allow(tablet1))
+	cbs.record(target, tablet1, 2, time.Millisecond, failure)
+	assert.False(t, cbs.allow(tablet1))
+	assert.EqualValues(t, 1, circuitBreakerEjections.Counts()["cb_errors.0.replica.ErrorRate"])
+	assert.EqualValues(t, 1, circuitBreakerEjected.Counts()["cb_errors.0.replica"])
+
+	// At most half of the healthy tablets are ejected.
+	for i := 0; i < 4; i++ {
+		cbs.record(target, tablet2, 2, time.Millisecond, failure)
+	}
+	assert.True(t, cbs.allow(tablet2))
+
+	// After the ejection, a single probe goes through, and closes the circuit if it succeeds.
+	now = now.Add(30 * time.Second)
+	assert.True(t, cbs.allow(tablet1))
+	assert.False(t, cbs.allow(tablet1))
+	cbs.record(target, tablet1, 2, time.Millisecond, nil)
+	assert.True(t, cbs.allow(tablet1))
+	assert.True(t, cbs.allow(tablet1))
+	assert.EqualValues(t, 0, circuitBreakerEjected.Counts()["cb_errors.0.replica"])
+}
+
+func TestTabletCircuitBreakerFailedProbe(t *testing.T) {
+	now := time.Now()
+	cbs := newTestCircuitBreakers(&now)
+	target := &querypb.Target{Keyspace: "cb_latency", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	tablet := topo.NewTablet(1, "cell", "host1")
+
+	for i := 0; i < 4; i++ {
+		cbs.record(target, tablet, 3, 2*time.Second, nil)
+	}
+	assert.False(t, cbs.allow(tablet))
+	assert.EqualValues(t, 1, circuitBreakerEjections.Counts()["cb_latency.0.replica.Latency"])
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, cbs.allow(tablet))
+	cbs.record(target, tablet, 3, time.Millisecond, vterrors.New(vtrpcpb.Code_UNAVAILABLE, "connection refused"))
+	assert.False(t, cbs.allow(tablet))
+	assert.EqualValues(t, 1, circuitBreakerEjections.Counts()["cb_latency.0.replica.FailedProbe"])
+	assert.EqualValues(t, 1, circuitBreakerEjected.Counts()["cb_latency.0.replica"])
+}
+
+func TestTabletGatewayCircuitBreaker(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	hc := discovery.NewFakeHealthCheck(nil)
+	tg := NewTabletGateway(ctx, hc, &fakeTopoServer{}, "cell")
+	defer tg.Close(ctx)
+	now := time.Now()
+	tg.circuitBreakers = newTestCircuitBreakers(&now)
+
+	target := &querypb.Target{Keyspace: "cb_gateway", Shard: "0", TabletType: topodatapb.TabletType_REPLICA}
+	sc1 := hc.AddTestTablet("cell", "1.1.1.1", 1001, target.Keyspace, target.Shard, target.TabletType, true, 10, nil)
+	sc2 := hc.AddTestTablet("cell", "1.1.1.1", 1002, target.Keyspace, target.Shard, target.TabletType, true, 10, nil)
+	sc1.MustFailCodes[vtrpcpb.Code_RESOURCE_EXHAUSTED] = 1000
+
+	// The failing tablet is ejected once it has served enough requests.
+	for sc1.ExecCount.Load() < 4 {
+		_, _ = tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	}
+	served := sc2.ExecCount.Load()
+	for i := 0; i < 10; i++ {
+		_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 4, sc1.ExecCount.Load())
+	assert.EqualValues(t, served+10, sc2.ExecCount.Load())
+	assert.EqualValues(t, 1, circuitBreakerEjections.Counts()["cb_gateway.0.replica.ErrorRate"])
+
+	// The ejected tablet is still used if it is the only one left.
+	hc.Reset()
+	sc1 = hc.AddTestTablet("cell", "1.1.1.1", 1001, target.Keyspace, target.Shard, target.TabletType, true, 10, nil)
+	_, err := tg.Execute(ctx, target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sc1.ExecCount.Load())
+
+	// The circuit breakers of the tablets that left the health check are removed.
+	tg.removeStaleCircuitBreakers()
+	assert.Len(t, tg.circuitBreakers.breakers, 1)
+	hc.RemoveTablet(sc1.Tablet())
+	tg.removeStaleCircuitBreakers()
+	assert.Empty(t, tg.circuitBreakers.breakers)
+	assert.EqualValues(t, 0, circuitBreakerEjected.Counts()["cb_gateway.0.replica"])
+}
+
+type streamingQueryService struct {
+	queryservice.QueryService
+}
+
+func (streamingQueryService) StreamExecute(ctx context.Context, target *querypb.Target, query string, bindVars map[string]*querypb.BindVariable, transactionID int64, reservedID int64, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) error {
+	for i := 0; i < 2; i++ {
+		if err := callback(&sqltypes.Result{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestFirstResultConnLatency(t *testing.T) {
+	conn := &firstResultConn{QueryService: streamingQueryService{}}
+	start := time.Now()
+	err := conn.StreamExecute(context.Background(), nil, "select 1", nil, 0, 0, nil, func(*sqltypes.Result) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The latency of the stream is the time to its first result, not its whole duration.
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Less(t, conn.latency(start), 50*time.Millisecond)
+}
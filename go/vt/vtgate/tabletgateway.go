@@ -29,6 +29,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
@@ -55,6 +56,11 @@ var (
 	retryCount = 2
 
 	logCollations = logutil.NewThrottledLogger("CollationInconsistent", 1*time.Minute)
+
+	maxLagPrimaryFallbacks = stats.NewCountersWithMultiLabels(
+		"MaxLagPrimaryFallbacks",
+		"Queries with a MAX_LAG directive served by the primary because no tablet of their tablet type lagged less",
+		[]string{"Keyspace", "Shard", "TabletType"})
 )
 
 func init() {
@@ -84,6 +90,9 @@ type TabletGateway struct {
 
 	// buffer, if enabled, buffers requests during a detected PRIMARY failover.
 	buffer *buffer.Buffer
+
+	// circuitBreakers, if enabled, eject the tablets with elevated error rates or latencies.
+	circuitBreakers *tabletCircuitBreakers
 }
 
 func createHealthCheck(ctx context.Context, retryDelay, timeout time.Duration, ts *topo.Server, cell, cellsToWatch string) discovery.HealthCheck {
@@ -111,6 +120,10 @@ func NewTabletGateway(ctx context.Context, hc discovery.HealthCheck, serv srvtop
 		retryCount:        retryCount,
 		statusAggregators: make(map[string]*TabletStatusAggregator),
 	}
+	if enableTabletCircuitBreaker {
+		gw.circuitBreakers = newTabletCircuitBreakers(tabletCircuitBreakerConfig)
+		go gw.watchCircuitBreakers(ctx)
+	}
 	gw.setupBuffering(ctx)
 	gw.QueryService = queryservice.Wrap(nil, gw.withRetry)
 	return gw
@@ -207,6 +220,17 @@ func (gw *TabletGateway) Close(_ context.Context) error {
 	return gw.hc.Close()
 }
 
+// FailoverRetryAfter returns how long the clients should wait before retrying
+// the queries that failed because of a failover, see buffer.Buffer.RetryAfter.
+// It returns 0 if the requests are not buffered, since the gateway then
+// doesn't track the failovers.
+func (gw *TabletGateway) FailoverRetryAfter() time.Duration {
+	if gw == nil || gw.buffer == nil {
+		return 0
+	}
+	return gw.buffer.RetryAfter()
+}
+
 // CacheStatus returns a list of TabletCacheStatus per
 // keyspace/shard/tablet_type.
 func (gw *TabletGateway) CacheStatus() TabletCacheStatusList {
@@ -220,6 +244,31 @@ func (gw *TabletGateway) CacheStatus() TabletCacheStatusList {
 	return res
 }
 
+type maxReplicationLagKey struct{}
+
+// withMaxReplicationLag returns a context with which the gateway only uses the
+// replicas lagging at most maxLag, or the primary if none of them does.
+func withMaxReplicationLag(ctx context.Context, maxLag time.Duration) context.Context {
+	return context.WithValue(ctx, maxReplicationLagKey{}, maxLag)
+}
+
+// maxReplicationLagFromContext returns the replication lag bound of the context, if any.
+func maxReplicationLagFromContext(ctx context.Context) (time.Duration, bool) {
+	maxLag, ok := ctx.Value(maxReplicationLagKey{}).(time.Duration)
+	return maxLag, ok
+}
+
+// withinReplicationLag returns the tablets lagging at most maxLag.
+func withinReplicationLag(tablets []*discovery.TabletHealth, maxLag time.Duration) []*discovery.TabletHealth {
+	var fresh []*discovery.TabletHealth
+	for _, th := range tablets {
+		if th.Stats != nil && time.Duration(discovery.TabletReplicationLag(th))*time.Second <= maxLag {
+			fresh = append(fresh, th)
+		}
+	}
+	return fresh
+}
+
 // withRetry gets available connections and executes the action. If there are retryable errors,
 // it retries retryCount times before failing. It does not retry if the connection is in
 // the middle of a transaction. While returning the error check if it maybe a result of
@@ -279,6 +328,16 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 		}
 
 		tablets := gw.hc.GetHealthyTabletStats(target)
+		if maxLag, ok := maxReplicationLagFromContext(ctx); ok && target.TabletType != topodatapb.TabletType_PRIMARY {
+			tablets = withinReplicationLag(tablets, maxLag)
+			if len(tablets) == 0 {
+				// none of the tablets is fresh enough, the primary serves the query instead
+				maxLagPrimaryFallbacks.Add([]string{target.Keyspace, target.Shard, topoproto.TabletTypeLString(target.TabletType)}, 1)
+				target = target.CloneVT()
+				target.TabletType = topodatapb.TabletType_PRIMARY
+				tablets = gw.hc.GetHealthyTabletStats(target)
+			}
+		}
 		if len(tablets) == 0 {
 			// if we have a keyspace event watcher, check if the reason why our primary is not available is that it's currently being resharded
 			// or if a reparent operation is in progress.
@@ -308,13 +367,24 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 
 		gw.shuffleTablets(gw.localCell, tablets)
 
-		var th *discovery.TabletHealth
-		// skip tablets we tried before
+		var th, ejected *discovery.TabletHealth
+		// skip tablets we tried before, and the ones ejected by their circuit breaker
 		for _, t := range tablets {
-			if _, ok := invalidTablets[topoproto.TabletAliasString(t.Tablet.Alias)]; !ok {
-				th = t
-				break
+			if _, ok := invalidTablets[topoproto.TabletAliasString(t.Tablet.Alias)]; ok {
+				continue
 			}
+			if gw.circuitBreakers != nil && !gw.circuitBreakers.allow(t.Tablet) {
+				if ejected == nil {
+					ejected = t
+				}
+				continue
+			}
+			th = t
+			break
+		}
+		if th == nil {
+			// the ejected tablets are still healthy, so they are used rather than failing the request
+			th = ejected
 		}
 		if th == nil {
 			// do not override error from last attempt.
@@ -329,15 +399,28 @@ func (gw *TabletGateway) withRetry(ctx context.Context, target *querypb.Target,
 		if th.Conn == nil {
 			err = vterrors.VT14003(tabletLastUsed)
 			invalidTablets[topoproto.TabletAliasString(tabletLastUsed.Alias)] = true
+			if gw.circuitBreakers != nil {
+				gw.circuitBreakers.record(target, tabletLastUsed, len(tablets), 0, err)
+			}
 			continue
 		}
 
 		gw.updateDefaultConnCollation(tabletLastUsed)
 
+		conn := th.Conn
+		var frc *firstResultConn
+		if gw.circuitBreakers != nil {
+			frc = &firstResultConn{QueryService: conn}
+			conn = frc
+		}
+
 		startTime := time.Now()
 		var canRetry bool
-		canRetry, err = inner(ctx, target, th.Conn)
+		canRetry, err = inner(ctx, target, conn)
 		gw.updateStats(target, startTime, err)
+		if gw.circuitBreakers != nil {
+			gw.circuitBreakers.record(target, tabletLastUsed, len(tablets), frc.latency(startTime), err)
+		}
 		if canRetry {
 			invalidTablets[topoproto.TabletAliasString(tabletLastUsed.Alias)] = true
 			continue
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -177,6 +178,45 @@ func TestTabletGatewayReplicaTransactionError(t *testing.T) {
 	verifyContainsError(t, err, "query service can only be used for non-transactional queries on replicas", vtrpcpb.Code_INTERNAL)
 }
 
+func TestTabletGatewayMaxReplicationLag(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	target := &querypb.Target{
+		Keyspace:   "max_lag",
+		Shard:      "0",
+		TabletType: topodatapb.TabletType_REPLICA,
+	}
+	hc := discovery.NewFakeHealthCheck(nil)
+	tg := NewTabletGateway(ctx, hc, &fakeTopoServer{}, "cell")
+	defer tg.Close(ctx)
+
+	primary := hc.AddTestTablet("cell", "1.1.1.1", 1001, target.Keyspace, target.Shard, topodatapb.TabletType_PRIMARY, true, 10, nil)
+	lagging := hc.AddTestTablet("cell", "1.1.1.1", 1002, target.Keyspace, target.Shard, target.TabletType, true, 10, nil)
+	fresh := hc.AddTestTablet("cell", "1.1.1.1", 1003, target.Keyspace, target.Shard, target.TabletType, true, 10, nil)
+	for _, th := range hc.GetHealthyTabletStats(target) {
+		if th.Conn == lagging {
+			th.Stats.ReplicationLagSeconds = 10
+		} else {
+			th.Stats.ReplicationLagSeconds = 2
+		}
+	}
+
+	// Only the replicas lagging less than the bound serve the queries.
+	for i := 0; i < 10; i++ {
+		_, err := tg.Execute(withMaxReplicationLag(ctx, 5*time.Second), target, "query", nil, 0, 0, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 0, lagging.ExecCount.Load())
+	assert.EqualValues(t, 10, fresh.ExecCount.Load())
+
+	// The primary serves them if none of the replicas does.
+	_, err := tg.Execute(withMaxReplicationLag(ctx, time.Second), target, "query", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, primary.ExecCount.Load())
+	assert.EqualValues(t, 1, maxLagPrimaryFallbacks.Counts()["max_lag.0.replica"])
+	assert.Equal(t, topodatapb.TabletType_REPLICA, target.TabletType)
+}
+
 func testTabletGatewayGeneric(t *testing.T, ctx context.Context, f func(ctx context.Context, tg *TabletGateway, target *querypb.Target) error) {
 	t.Helper()
 	keyspace := "ks"
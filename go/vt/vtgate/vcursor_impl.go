@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"regexp"
 	"sort"
 	"strings"
 	"sync/atomic"
@@ -67,18 +68,23 @@ var _ vindexes.VCursor = (*vcursorImpl)(nil)
 type iExecute interface {
 	Execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConnection, method string, session *SafeSession, s string, vars map[string]*querypb.BindVariable) (*sqltypes.Result, error)
 	ExecuteMultiShard(ctx context.Context, primitive engine.Primitive, rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, session *SafeSession, autocommit bool, ignoreMaxMemoryRows bool) (qr *sqltypes.Result, errs []error)
+	ExecuteBatchMultiShard(ctx context.Context, primitive engine.Primitive, rss []*srvtopo.ResolvedShard, queries [][]*querypb.BoundQuery, session *SafeSession, ignoreMaxMemoryRows bool) (qr *sqltypes.Result, errs []error)
 	StreamExecuteMulti(ctx context.Context, primitive engine.Primitive, query string, rss []*srvtopo.ResolvedShard, vars []map[string]*querypb.BindVariable, session *SafeSession, autocommit bool, callback func(reply *sqltypes.Result) error) []error
 	ExecuteLock(ctx context.Context, rs *srvtopo.ResolvedShard, query *querypb.BoundQuery, session *SafeSession, lockFuncType sqlparser.LockingFuncType) (*sqltypes.Result, error)
 	Commit(ctx context.Context, safeSession *SafeSession) error
 	ExecuteMessageStream(ctx context.Context, rss []*srvtopo.ResolvedShard, name string, callback func(*sqltypes.Result) error) error
 	ExecuteVStream(ctx context.Context, rss []*srvtopo.ResolvedShard, filter *binlogdatapb.Filter, gtid string, callback func(evs []*binlogdatapb.VEvent) error) error
 	ReleaseLock(ctx context.Context, session *SafeSession) error
+	advisoryLockService() engine.AdvisoryLockService
 
 	showVitessReplicationStatus(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
 	showShards(ctx context.Context, filter *sqlparser.ShowFilter, destTabletType topodatapb.TabletType) (*sqltypes.Result, error)
 	showTablets(filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
+	showVitessTransactionStatus(session *SafeSession) *sqltypes.Result
 	showVitessMetadata(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
 	setVitessMetadata(ctx context.Context, name, value string) error
+	showVitessPlans(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
+	flushPlans(ctx context.Context, match func(plan *engine.Plan) bool) (int, error)
 
 	// TODO: remove when resolver is gone
 	ParseDestinationTarget(targetString string) (string, topodatapb.TabletType, key.Destination, error)
@@ -124,6 +130,15 @@ type vcursorImpl struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// schemaTracker is the schema tracker of the executor, if any.
+	schemaTracker SchemaInfo
+
+	// keysetPagination is set when the query is paginated with the KEYSET_PAGINATE directive.
+	keysetPagination *keysetPagination
+
+	// maxReplicationLag is the replication lag bound of the MAX_LAG directive, if any.
+	maxReplicationLag time.Duration
 }
 
 // newVcursorImpl creates a vcursorImpl. Before creating this object, you have to separate out any marginComments that came with
@@ -170,9 +185,11 @@ func newVCursorImpl(
 
 	warmingReadsPct := 0
 	var warmingReadsChan chan bool
+	var schemaTracker SchemaInfo
 	if executor != nil {
 		warmingReadsPct = executor.warmingReadsPercent
 		warmingReadsChan = executor.warmingReadsChannel
+		schemaTracker = executor.schemaTracker
 	}
 	return &vcursorImpl{
 		safeSession:         safeSession,
@@ -191,6 +208,7 @@ func newVCursorImpl(
 		pv:                  pv,
 		warmingReadsPercent: warmingReadsPct,
 		warmingReadsChannel: warmingReadsChan,
+		schemaTracker:       schemaTracker,
 	}, nil
 }
 
@@ -229,6 +247,11 @@ func (vc *vcursorImpl) MaxMemoryRows() int {
 	return maxMemoryRows
 }
 
+// SpillDir returns the spillDir flag value.
+func (vc *vcursorImpl) SpillDir() string {
+	return spillDir
+}
+
 // ExceedsMaxMemoryRows returns a boolean indicating whether the maxMemoryRows value has been exceeded.
 // Returns false if the max memory rows override directive is set to true.
 func (vc *vcursorImpl) ExceedsMaxMemoryRows(numRows int) bool {
@@ -543,7 +566,8 @@ func (vc *vcursorImpl) Execute(ctx context.Context, method string, query string,
 		return nil, err
 	}
 
-	qr, err := vc.executor.Execute(ctx, nil, method, session, vc.marginComments.Leading+query+vc.marginComments.Trailing, bindVars)
+	// The queries of the vindexes are generated by vtgate itself.
+	qr, err := vc.executor.Execute(callerid.NewInternalContext(ctx, method), nil, method, session, vc.marginComments.Leading+query+vc.marginComments.Trailing, bindVars)
 	vc.setRollbackOnPartialExecIfRequired(err != nil, rollbackOnError)
 
 	return qr, err
@@ -558,7 +582,7 @@ func (vc *vcursorImpl) markSavepoint(ctx context.Context, needsRollbackOnParialE
 	}
 	uID := fmt.Sprintf("_vt%s", strings.ReplaceAll(uuid.NewString(), "-", "_"))
 	spQuery := fmt.Sprintf("%ssavepoint %s%s", vc.marginComments.Leading, uID, vc.marginComments.Trailing)
-	_, err := vc.executor.Execute(ctx, nil, "MarkSavepoint", vc.safeSession, spQuery, bindVars)
+	_, err := vc.executor.Execute(callerid.NewInternalContext(ctx, "MarkSavepoint"), nil, "MarkSavepoint", vc.safeSession, spQuery, bindVars)
 	if err != nil {
 		return err
 	}
@@ -583,6 +607,18 @@ func (vc *vcursorImpl) ExecuteMultiShard(ctx context.Context, primitive engine.P
 	return qr, errs
 }
 
+// ExecuteBatchMultiShard is part of the engine.VCursor interface.
+func (vc *vcursorImpl) ExecuteBatchMultiShard(ctx context.Context, primitive engine.Primitive, rss []*srvtopo.ResolvedShard, queries [][]*querypb.BoundQuery) (*sqltypes.Result, []error) {
+	noOfQueries := 0
+	commented := make([][]*querypb.BoundQuery, len(queries))
+	for i, batch := range queries {
+		noOfQueries += len(batch)
+		commented[i] = commentedShardQueries(batch, vc.marginComments)
+	}
+	atomic.AddUint64(&vc.logStats.ShardQueries, uint64(noOfQueries))
+	return vc.executor.ExecuteBatchMultiShard(ctx, primitive, rss, commented, vc.safeSession, vc.ignoreMaxMemoryRows)
+}
+
 // StreamExecuteMulti is the streaming version of ExecuteMultiShard.
 func (vc *vcursorImpl) StreamExecuteMulti(ctx context.Context, primitive engine.Primitive, query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
 	noOfShards := len(rss)
@@ -883,6 +919,11 @@ func (vc *vcursorImpl) SetTransactionMode(mode vtgatepb.TransactionMode) {
 	vc.safeSession.TransactionMode = mode
 }
 
+// SetTransactionIsolation implements the SessionActions interface
+func (vc *vcursorImpl) SetTransactionIsolation(level querypb.ExecuteOptions_TransactionIsolation) error {
+	return vc.safeSession.SetTransactionIsolation(level)
+}
+
 // SetWorkload implements the SessionActions interface
 func (vc *vcursorImpl) SetWorkload(workload querypb.ExecuteOptions_Workload) {
 	vc.safeSession.GetOrCreateOptions().Workload = workload
@@ -902,6 +943,30 @@ func (vc *vcursorImpl) SetPriority(priority string) {
 
 }
 
+// SetTransactionTag implements the SessionActions interface
+func (vc *vcursorImpl) SetTransactionTag(tag string) {
+	if tag != "" {
+		vc.safeSession.GetOrCreateOptions().TransactionTag = tag
+	} else if vc.safeSession.Options != nil {
+		vc.safeSession.Options.TransactionTag = ""
+	}
+}
+
+// setTabletTypeOverride routes the query to the tablet type of its TABLET_TYPE
+// directive instead of the one of the session target, if it has one.
+func (vc *vcursorImpl) setTabletTypeOverride(stmt sqlparser.Statement) error {
+	tabletType, maxLag, err := sqlparser.GetTabletTypeFromStatement(stmt)
+	if err != nil || tabletType == topodatapb.TabletType_UNKNOWN {
+		return err
+	}
+	if vc.safeSession.InTransaction() && tabletType != topodatapb.TabletType_PRIMARY {
+		return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.LockOrActiveTransaction, "can't route the query to %s tablets because you have an active transaction", topoprotopb.TabletTypeLString(tabletType))
+	}
+	vc.tabletType = tabletType
+	vc.maxReplicationLag = maxLag
+	return nil
+}
+
 // SetConsolidator implements the SessionActions interface
 func (vc *vcursorImpl) SetConsolidator(consolidator querypb.ExecuteOptions_Consolidator) {
 	// Avoid creating session Options when they do not yet exist and the
@@ -975,9 +1040,29 @@ func (vc *vcursorImpl) SetSessionTrackGTIDs(enable bool) {
 	vc.safeSession.SetSessionTrackGtids(enable)
 }
 
-// HasCreatedTempTable implements the SessionActions interface
-func (vc *vcursorImpl) HasCreatedTempTable() {
-	vc.safeSession.GetOrCreateOptions().HasCreatedTempTables = true
+// SetSessionTrackStateChange implements the SessionActions interface
+func (vc *vcursorImpl) SetSessionTrackStateChange(enable bool) {
+	vc.safeSession.SetSessionTrackStateChange(enable)
+}
+
+// SetSessionTrackSystemVariables implements the SessionActions interface
+func (vc *vcursorImpl) SetSessionTrackSystemVariables(sysVars string) {
+	vc.safeSession.SetSessionTrackSystemVariables(sysVars)
+}
+
+// TempTableCreated implements the SessionActions interface
+func (vc *vcursorImpl) TempTableCreated(keyspace, table string) {
+	vc.safeSession.AddTempTable(keyspace, table)
+}
+
+// TempTableDropped implements the SessionActions interface
+func (vc *vcursorImpl) TempTableDropped(keyspace, table string) {
+	vc.safeSession.RemoveTempTable(keyspace, table)
+}
+
+// HasTempTable implements the SessionActions interface
+func (vc *vcursorImpl) HasTempTable(keyspace, table string) bool {
+	return vc.safeSession.HasTempTable(keyspace, table)
 }
 
 // GetWarnings implements the SessionActions interface
@@ -1180,8 +1265,12 @@ func (vc *vcursorImpl) ShowExec(ctx context.Context, command sqlparser.ShowComma
 		return vc.executor.showShards(ctx, filter, vc.tabletType)
 	case sqlparser.VitessTablets:
 		return vc.executor.showTablets(filter)
+	case sqlparser.VitessTransactionStatus:
+		return vc.executor.showVitessTransactionStatus(vc.safeSession), nil
 	case sqlparser.VitessVariables:
 		return vc.executor.showVitessMetadata(ctx, filter)
+	case sqlparser.VitessPlans:
+		return vc.executor.showVitessPlans(ctx, filter)
 	default:
 		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "bug: unexpected show command: %v", command)
 	}
@@ -1199,6 +1288,47 @@ func (vc *vcursorImpl) SetExec(ctx context.Context, name string, value string) e
 	return vc.executor.setVitessMetadata(ctx, name, value)
 }
 
+func (vc *vcursorImpl) FlushPlans(ctx context.Context, tables []sqlparser.TableName, pattern string) (int, error) {
+	var queryRegexp *regexp.Regexp
+	if pattern != "" {
+		queryRegexp = sqlparser.LikeToRegexp(pattern)
+	}
+	// Unqualified tables are resolved against the current keyspace, or match any keyspace when there is none.
+	matchesTable := func(tableUsed string) bool {
+		for _, table := range tables {
+			ks := table.Qualifier.String()
+			if ks == "" {
+				ks = vc.keyspace
+			}
+			if ks == "" {
+				_, name, _ := strings.Cut(tableUsed, ".")
+				if name == table.Name.String() {
+					return true
+				}
+				continue
+			}
+			if tableUsed == ks+"."+table.Name.String() {
+				return true
+			}
+		}
+		return false
+	}
+	return vc.executor.flushPlans(ctx, func(plan *engine.Plan) bool {
+		if queryRegexp != nil && queryRegexp.MatchString(plan.Original) {
+			return true
+		}
+		if len(tables) > 0 {
+			for _, tableUsed := range plan.TablesUsed {
+				if matchesTable(tableUsed) {
+					return true
+				}
+			}
+			return false
+		}
+		return queryRegexp == nil
+	})
+}
+
 func (vc *vcursorImpl) ThrottleApp(ctx context.Context, throttledAppRule *topodatapb.ThrottledAppRule) (err error) {
 	if throttledAppRule == nil {
 		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "ThrottleApp: nil rule")
@@ -1256,6 +1386,11 @@ func (vc *vcursorImpl) ReleaseLock(ctx context.Context) error {
 	return vc.executor.ReleaseLock(ctx, vc.safeSession)
 }
 
+// AdvisoryLockService implements the VCursor interface.
+func (vc *vcursorImpl) AdvisoryLockService() engine.AdvisoryLockService {
+	return vc.executor.advisoryLockService()
+}
+
 func (vc *vcursorImpl) cloneWithAutocommitSession() *vcursorImpl {
 	safeSession := NewAutocommitSession(vc.safeSession.Session)
 	safeSession.logging = vc.safeSession.logging
@@ -1292,6 +1427,28 @@ func (vc *vcursorImpl) IsViewsEnabled() bool {
 	return enableViews
 }
 
+func (vc *vcursorImpl) SQLModeChecks() []string {
+	return enforceSQLModeChecks
+}
+
+// FindTrackedTable implements the VSchema interface.
+func (vc *vcursorImpl) FindTrackedTable(keyspace, table string) *vindexes.TableInfo {
+	if showColumnsPassthrough || vc.schemaTracker == nil {
+		return nil
+	}
+	tables := vc.schemaTracker.Tables(keyspace)
+	if tbl, ok := tables[table]; ok {
+		return tbl
+	}
+	// The table can be named in another case than the tablets reported it.
+	for name, tbl := range tables {
+		if strings.EqualFold(name, table) {
+			return tbl
+		}
+	}
+	return nil
+}
+
 func (vc *vcursorImpl) GetUDV(name string) *querypb.BindVariable {
 	return vc.safeSession.GetUDV(name)
 }
@@ -1325,7 +1482,7 @@ func (vc *vcursorImpl) CloneForReplicaWarming(ctx context.Context) engine.VCurso
 	immediateCallerId := callerid.ImmediateCallerIDFromContext(ctx)
 
 	timedCtx, _ := context.WithTimeout(context.Background(), warmingReadsQueryTimeout) //nolint
-	clonedCtx := callerid.NewContext(timedCtx, callerId, immediateCallerId)
+	clonedCtx := callerid.NewInternalContext(callerid.NewContext(timedCtx, callerId, immediateCallerId), "WarmingReads")
 
 	v := &vcursorImpl{
 		safeSession:         NewAutocommitSession(vc.safeSession.Session),
@@ -326,3 +326,17 @@ func TestFirstSortedKeyspace(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, ks3Schema.Keyspace, ks)
 }
+
+func TestFindTrackedTable(t *testing.T) {
+	tbl := &vindexes.TableInfo{Columns: []vindexes.Column{{Name: sqlparser.NewIdentifierCI("id")}}}
+	vc := &vcursorImpl{schemaTracker: &fakeSchema{t: map[string]*vindexes.TableInfo{"t1": tbl}}}
+	require.Same(t, tbl, vc.FindTrackedTable("ks", "t1"))
+	require.Same(t, tbl, vc.FindTrackedTable("ks", "T1"))
+	require.Nil(t, vc.FindTrackedTable("ks", "t2"))
+
+	defer func(old bool) { showColumnsPassthrough = old }(showColumnsPassthrough)
+	showColumnsPassthrough = true
+	require.Nil(t, vc.FindTrackedTable("ks", "t1"))
+
+	require.Nil(t, (&vcursorImpl{}).FindTrackedTable("ks", "t1"))
+}
@@ -29,6 +29,7 @@ var (
 	_ SingleColumn    = (*Binary)(nil)
 	_ Reversible      = (*Binary)(nil)
 	_ Hashing         = (*Binary)(nil)
+	_ PrefixRange     = (*Binary)(nil)
 	_ ParamValidating = (*Binary)(nil)
 )
 
@@ -92,6 +93,15 @@ func (vind *Binary) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Val
 	return out, nil
 }
 
+// MapPrefix maps each prefix to the keyspace range holding all the ids that start with it.
+func (vind *Binary) MapPrefix(ctx context.Context, vcursor VCursor, prefixes [][]byte) ([]key.Destination, error) {
+	out := make([]key.Destination, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		out = append(out, NewKeyRangeFromPrefix(prefix))
+	}
+	return out, nil
+}
+
 func (vind *Binary) Hash(id sqltypes.Value) ([]byte, error) {
 	return id.ToBytes()
 }
@@ -602,6 +602,20 @@ func (cached *clCommon) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeAllocSize(int64(len(cached.updateLookupQuery)))
 	return size
 }
+func (cached *likePrefix) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(16)
+	}
+	// field PrefixRange vitess.io/vitess/go/vt/vtgate/vindexes.PrefixRange
+	if cc, ok := cached.PrefixRange.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
 func (cached *lookupInternal) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
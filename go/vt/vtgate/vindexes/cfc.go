@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"strings"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
@@ -346,14 +347,15 @@ func (vind *prefixCFC) IsUnique() bool {
 }
 
 // Map can map ids to key.Destination objects.
-func (vind *prefixCFC) Map(_ context.Context, _ VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
+func (vind *prefixCFC) Map(_ context.Context, vcursor VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
 	out := make([]key.Destination, len(ids))
+	noBackslashEscapes := noBackslashEscapes(vcursor)
 	for i, id := range ids {
 		value, err := id.ToBytes()
 		if err != nil {
 			return out, err
 		}
-		prefix := findPrefix(value)
+		prefix := findPrefix(value, noBackslashEscapes)
 		begin, err := vind.computeKsid(prefix, true)
 		if err != nil {
 			return nil, err
@@ -366,8 +368,16 @@ func (vind *prefixCFC) Map(_ context.Context, _ VCursor, ids []sqltypes.Value) (
 // findPrefix returns the 'prefix' of the string literal in LIKE expression.
 // The prefix is the prefix of the string literal up until the first unescaped
 // meta character (% and _). Other escape sequences are escaped according to
-// https://dev.mysql.com/doc/refman/8.0/en/string-literals.html.
-func findPrefix(str []byte) []byte {
+// https://dev.mysql.com/doc/refman/8.0/en/string-literals.html, unless the
+// NO_BACKSLASH_ESCAPES sql mode is set, in which case a backslash is an ordinary
+// character in LIKE patterns as well.
+func findPrefix(str []byte, noBackslashEscapes bool) []byte {
+	if noBackslashEscapes {
+		if p := bytes.IndexAny(str, `%_`); p >= 0 {
+			return str[:p]
+		}
+		return str
+	}
 	buf := new(bytes.Buffer)
 L:
 	for len(str) > 0 {
@@ -429,3 +439,17 @@ func xxhash64(in []byte) []byte {
 func init() {
 	Register("cfc", newCFC)
 }
+
+// noBackslashEscapes returns true if the NO_BACKSLASH_ESCAPES sql mode is set
+// for the session of the vcursor.
+func noBackslashEscapes(vcursor VCursor) bool {
+	if vcursor == nil {
+		return false
+	}
+	for _, mode := range strings.Split(strings.ToUpper(vcursor.SQLMode()), ",") {
+		if strings.TrimSpace(mode) == "NO_BACKSLASH_ESCAPES" {
+			return true
+		}
+	}
+	return false
+}
@@ -533,8 +533,13 @@ func TestCFCFindPrefixEscape(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		assert.EqualValues(t, tc.prefix, string(findPrefix([]byte(tc.str))))
+		assert.EqualValues(t, tc.prefix, string(findPrefix([]byte(tc.str), false)))
 	}
+
+	// With NO_BACKSLASH_ESCAPES, a backslash doesn't escape the meta characters.
+	assert.EqualValues(t, `a\`, string(findPrefix([]byte(`a\%c`), true)))
+	assert.EqualValues(t, `a\`, string(findPrefix([]byte(`a\_b`), true)))
+	assert.EqualValues(t, `a\b`, string(findPrefix([]byte(`a\b`), true)))
 }
 
 func TestDestinationKeyRangeFromPrefix(t *testing.T) {
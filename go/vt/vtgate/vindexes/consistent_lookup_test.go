@@ -32,6 +32,7 @@ import (
 	"vitess.io/vitess/go/mysql/sqlerror"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/config"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
@@ -560,6 +561,10 @@ func (vc *loggingVCursor) InTransactionAndIsDML() bool {
 	return false
 }
 
+func (vc *loggingVCursor) SQLMode() string {
+	return config.DefaultSQLMode
+}
+
 func (vc *loggingVCursor) ConnCollation() collations.ID {
 	return vc.Environment().CollationEnv().DefaultConnectionCharset()
 }
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+)
+
+var _ SingleColumn = (*likePrefix)(nil)
+
+// LikePrefixVindex returns the vindex to route a 'LIKE' expression on a column
+// of the given vindex with, or nil if the vindex cannot narrow down the shards
+// for a pattern.
+func LikePrefixVindex(vindex Vindex) SingleColumn {
+	switch vindex := vindex.(type) {
+	case Prefixable:
+		return vindex.PrefixVindex()
+	case PrefixRange:
+		return &likePrefix{PrefixRange: vindex}
+	}
+	return nil
+}
+
+// likePrefix routes 'LIKE' patterns of a PrefixRange vindex. The constant prefix
+// is extracted from the pattern when the route is executed, so it works the same
+// for literals and for the bind variables they are normalized into.
+type likePrefix struct {
+	PrefixRange
+}
+
+// Cost is higher than the cost of the underlying vindex since a
+// prefix usually resolves to more than one shard.
+func (vind *likePrefix) Cost() int {
+	return vind.PrefixRange.Cost() + 1
+}
+
+// IsUnique returns false since a prefix can match many ids.
+func (vind *likePrefix) IsUnique() bool {
+	return false
+}
+
+// Map maps the constant prefix of each pattern to a keyspace range.
+func (vind *likePrefix) Map(ctx context.Context, vcursor VCursor, patterns []sqltypes.Value) ([]key.Destination, error) {
+	prefixes := make([][]byte, 0, len(patterns))
+	noBackslashEscapes := noBackslashEscapes(vcursor)
+	for _, pattern := range patterns {
+		value, err := pattern.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, findPrefix(value, noBackslashEscapes))
+	}
+	return vind.MapPrefix(ctx, vcursor, prefixes)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestLikePrefixVindex(t *testing.T) {
+	cfc := makeCFC(t, map[string]string{"hash": "md5", "offsets": "[2,4]"})
+	assert.Equal(t, cfc.PrefixVindex(), LikePrefixVindex(cfc))
+
+	hash, err := CreateVindex("hash", "hash", nil)
+	require.NoError(t, err)
+	assert.Nil(t, LikePrefixVindex(hash))
+
+	prefix := LikePrefixVindex(binOnlyVindex)
+	require.NotNil(t, prefix)
+	assert.Equal(t, "binary_varchar", prefix.String())
+	assert.False(t, prefix.IsUnique())
+	assert.False(t, prefix.NeedsVCursor())
+	assert.Equal(t, 1, prefix.Cost())
+}
+
+func TestLikePrefixVindexMap(t *testing.T) {
+	prefix := LikePrefixVindex(binOnlyVindex)
+	got, err := prefix.Map(context.Background(), nil, []sqltypes.Value{
+		sqltypes.NewVarBinary("ab%"),
+		sqltypes.NewVarBinary(`a\_b_`),
+		sqltypes.NewVarBinary("a\xff%"),
+		sqltypes.NewVarBinary("%ab"),
+		sqltypes.NewVarBinary("abc"),
+	})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte("ab"), End: []byte("ac")}},
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte("a_b"), End: []byte("a_c")}},
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte("a\xff"), End: []byte("b\x00")}},
+		key.DestinationAllShards{},
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte("abc"), End: []byte("abd")}},
+	}
+	assert.Equal(t, want, got)
+
+	// With NO_BACKSLASH_ESCAPES, the backslash is part of the prefix.
+	got, err = prefix.Map(context.Background(), &vcursor{sqlMode: "STRICT_TRANS_TABLES,NO_BACKSLASH_ESCAPES"}, []sqltypes.Value{
+		sqltypes.NewVarBinary(`a\_b_`),
+	})
+	require.NoError(t, err)
+	want = []key.Destination{
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte(`a\`), End: []byte(`a]`)}},
+	}
+	assert.Equal(t, want, got)
+}
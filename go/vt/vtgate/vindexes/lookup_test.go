@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/config"
 	"vitess.io/vitess/go/test/utils"
 
 	"github.com/stretchr/testify/assert"
@@ -52,6 +53,7 @@ type vcursor struct {
 	autocommits int
 	pre, post   int
 	keys        []sqltypes.Value
+	sqlMode     string
 }
 
 func (vc *vcursor) LookupRowLockShardSession() vtgatepb.CommitOrder {
@@ -115,6 +117,13 @@ func (vc *vcursor) execute(query string, bindvars map[string]*querypb.BindVariab
 	panic("unexpected")
 }
 
+func (vc *vcursor) SQLMode() string {
+	if vc.sqlMode != "" {
+		return vc.sqlMode
+	}
+	return config.DefaultSQLMode
+}
+
 func (vc *vcursor) ConnCollation() collations.ID {
 	return vc.Environment().CollationEnv().DefaultConnectionCharset()
 }
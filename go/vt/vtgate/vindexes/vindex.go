@@ -46,6 +46,7 @@ type (
 		LookupRowLockShardSession() vtgatepb.CommitOrder
 		ConnCollation() collations.ID
 		Environment() *vtenv.Environment
+		SQLMode() string
 	}
 
 	// Vindex defines the interface required to register a vindex.
@@ -138,6 +139,15 @@ type (
 		PrefixVindex() SingleColumn
 	}
 
+	// A PrefixRange vindex is one whose keyspace ids preserve the byte order of
+	// its ids, so that all the ids sharing a prefix map to a single keyspace range.
+	// MapPrefix maps each prefix to that range. It's being used to reduce the fan
+	// out for 'LIKE' expressions that have a constant prefix.
+	PrefixRange interface {
+		SingleColumn
+		MapPrefix(ctx context.Context, vcursor VCursor, prefixes [][]byte) ([]key.Destination, error)
+	}
+
 	// A Lookup vindex is one that needs to lookup
 	// a previously stored map to compute the keyspace
 	// id from an id. This means that the creation of
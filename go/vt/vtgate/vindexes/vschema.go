@@ -35,6 +35,7 @@ import (
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/schema"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
@@ -159,6 +160,11 @@ type TableInfo struct {
 	Columns     []Column
 	ForeignKeys []*sqlparser.ForeignKeyDefinition
 	Indexes     []*sqlparser.IndexDefinition
+	// ColumnDefinitions are the definitions of the columns, as they appear in
+	// the CREATE TABLE statement of the table.
+	ColumnDefinitions []*sqlparser.ColumnDefinition
+	// TrackedAt is when the schema tracker loaded the table.
+	TrackedAt time.Time
 }
 
 // IsUnique is used to tell whether the ColumnVindex
@@ -245,6 +251,10 @@ type KeyspaceSchema struct {
 	Views           map[string]sqlparser.SelectStatement
 	Error           error
 	MultiTenantSpec *vschemapb.MultiTenantSpec
+	// DDLStrategy is the DDL strategy enforced for the keyspace, if any.
+	DDLStrategy string
+	// PlannerFeatures restricts the plans of the queries on the keyspace, if set.
+	PlannerFeatures *vschemapb.PlannerFeatures
 }
 
 type ksJSON struct {
@@ -255,6 +265,8 @@ type ksJSON struct {
 	Views           map[string]string          `json:"views,omitempty"`
 	Error           string                     `json:"error,omitempty"`
 	MultiTenantSpec *vschemapb.MultiTenantSpec `json:"multi_tenant_spec,omitempty"`
+	DDLStrategy     string                     `json:"ddl_strategy,omitempty"`
+	PlannerFeatures *vschemapb.PlannerFeatures `json:"planner_features,omitempty"`
 }
 
 // findTable looks for the table with the requested tablename in the keyspace.
@@ -288,6 +300,8 @@ func (ks *KeyspaceSchema) MarshalJSON() ([]byte, error) {
 		ForeignKeyMode:  ks.ForeignKeyMode.String(),
 		Vindexes:        ks.Vindexes,
 		MultiTenantSpec: ks.MultiTenantSpec,
+		DDLStrategy:     ks.DDLStrategy,
+		PlannerFeatures: ks.PlannerFeatures,
 	}
 	if ks.Error != nil {
 		ksJ.Error = ks.Error.Error()
@@ -379,9 +393,16 @@ func buildKeyspaces(source *vschemapb.SrvVSchema, vschema *VSchema, parser *sqlp
 			Tables:          make(map[string]*Table),
 			Vindexes:        make(map[string]Vindex),
 			MultiTenantSpec: ks.MultiTenantSpec,
+			DDLStrategy:     ks.DdlStrategy,
+			PlannerFeatures: ks.PlannerFeatures,
 		}
 		vschema.Keyspaces[ksname] = ksvschema
 		ksvschema.Error = buildTables(ks, vschema, ksvschema, parser)
+		if ksvschema.Error == nil && ks.DdlStrategy != "" {
+			if _, err := schema.ParseDDLStrategy(ks.DdlStrategy); err != nil {
+				ksvschema.Error = vterrors.Wrapf(err, "invalid ddl_strategy for keyspace %s", ksname)
+			}
+		}
 	}
 }
 
@@ -299,6 +299,38 @@ func TestMultiTenantAttribute(t *testing.T) {
 	}
 }
 
+// TestDDLStrategy verifies that the keyspace's DDL strategy is kept in KeyspaceSchema, and validated.
+func TestDDLStrategy(t *testing.T) {
+	ksSchema, err := BuildKeyspace(&vschemapb.Keyspace{
+		DdlStrategy: "vitess --postpone-launch",
+	}, sqlparser.NewTestParser())
+	require.NoError(t, err)
+	require.Equal(t, "vitess --postpone-launch", ksSchema.DDLStrategy)
+
+	_, err = BuildKeyspace(&vschemapb.Keyspace{
+		DdlStrategy: "unknown",
+	}, sqlparser.NewTestParser())
+	require.ErrorContains(t, err, "invalid ddl_strategy for keyspace")
+}
+
+// TestPlannerFeatures verifies that the keyspace's planner features are kept in KeyspaceSchema.
+func TestPlannerFeatures(t *testing.T) {
+	ksSchema, err := BuildKeyspace(&vschemapb.Keyspace{
+		PlannerFeatures: &vschemapb.PlannerFeatures{
+			DisallowScatter:        true,
+			RequireVindexPredicate: true,
+		},
+	}, sqlparser.NewTestParser())
+	require.NoError(t, err)
+	require.True(t, ksSchema.PlannerFeatures.DisallowScatter)
+	require.False(t, ksSchema.PlannerFeatures.DisallowCrossKeyspaceJoins)
+	require.True(t, ksSchema.PlannerFeatures.RequireVindexPredicate)
+
+	out, err := json.Marshal(ksSchema)
+	require.NoError(t, err)
+	require.Contains(t, string(out), `"planner_features":{"disallow_scatter":true,"require_vindex_predicate":true}`)
+}
+
 func TestForeignKeyMode(t *testing.T) {
 	tests := []struct {
 		name         string
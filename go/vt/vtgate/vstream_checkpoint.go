@@ -0,0 +1,360 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// checkpointTokenPrefix versions the encoding of the checkpoint tokens.
+const checkpointTokenPrefix = "v1."
+
+// maxVStreamCheckpoints is the number of named streams whose checkpoints
+// are kept. Beyond it, the least recently updated inactive ones are dropped.
+const maxVStreamCheckpoints = 1000
+
+// vstreamCheckpointsPath is the directory of the global topo where the
+// checkpoints of the named streams are saved.
+const vstreamCheckpointsPath = "vstream_checkpoints"
+
+// vstreamCheckpointSaveInterval is how often the positions of the named
+// streams running on this vtgate are saved.
+var vstreamCheckpointSaveInterval = time.Second
+
+// EncodeCheckpointToken returns the checkpoint token of a VStream position.
+// Clients treat the tokens as opaque, and resume a VStream from one with the
+// checkpoint_token of the VStreamRequest.
+func EncodeCheckpointToken(vgtid *binlogdatapb.VGtid) (string, error) {
+	b, err := vgtid.MarshalVT()
+	if err != nil {
+		return "", err
+	}
+	return checkpointTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCheckpointToken returns the VStream position of a checkpoint token.
+func DecodeCheckpointToken(token string) (*binlogdatapb.VGtid, error) {
+	encoded, ok := strings.CutPrefix(token, checkpointTokenPrefix)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid checkpoint token %q", token)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid checkpoint token %q: %v", token, err)
+	}
+	vgtid := &binlogdatapb.VGtid{}
+	if err := vgtid.UnmarshalVT(b); err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid checkpoint token %q: %v", token, err)
+	}
+	if len(vgtid.ShardGtids) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid checkpoint token %q: no position", token)
+	}
+	return vgtid, nil
+}
+
+// LastVGtid returns the position of the last VGTID event of events, if any.
+func LastVGtid(events []*binlogdatapb.VEvent) *binlogdatapb.VGtid {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type == binlogdatapb.VEventType_VGTID {
+			return events[i].Vgtid
+		}
+	}
+	return nil
+}
+
+// vstreamCatalog records the last positions that the named VStreams sent
+// to their clients, so that they can be resumed from them. The positions are
+// saved in the global topo, so that they survive the restarts of the vtgate
+// and can be read from any vtgate. The positions of the streams running on
+// this vtgate are saved periodically, and when the streams end.
+type vstreamCatalog struct {
+	serv  srvtopo.Server
+	ticks *timer.Timer
+	// ticksMu serializes starting and stopping the periodic saves, which
+	// run while some streams are active.
+	ticksMu sync.Mutex
+
+	mu sync.Mutex
+	// active are the checkpoints of the named streams running on this vtgate.
+	active map[string]*vtgatepb.VStreamCheckpoint
+	// dirty are the names of the active checkpoints that changed since they
+	// were last saved.
+	dirty map[string]bool
+}
+
+func newVStreamCatalog(serv srvtopo.Server) *vstreamCatalog {
+	return &vstreamCatalog{
+		serv:   serv,
+		ticks:  timer.NewTimer(vstreamCheckpointSaveInterval),
+		active: make(map[string]*vtgatepb.VStreamCheckpoint),
+		dirty:  make(map[string]bool),
+	}
+}
+
+func vstreamCheckpointPath(name string) string {
+	return path.Join(vstreamCheckpointsPath, url.PathEscape(name))
+}
+
+func (c *vstreamCatalog) conn(ctx context.Context) (topo.Conn, error) {
+	ts, err := c.serv.GetTopoServer()
+	if err != nil {
+		return nil, err
+	}
+	return ts.ConnForCell(ctx, topo.GlobalCell)
+}
+
+// start marks the stream of the name as active, and saves its position. Only
+// one stream of a name can be active at a time on a vtgate.
+func (c *vstreamCatalog) start(ctx context.Context, name string, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid) error {
+	c.mu.Lock()
+	if _, ok := c.active[name]; ok {
+		c.mu.Unlock()
+		return vterrors.Errorf(vtrpcpb.Code_ALREADY_EXISTS, "a vstream named %s is already running", name)
+	}
+	checkpoint := &vtgatepb.VStreamCheckpoint{Name: name, TabletType: tabletType, Active: true}
+	setCheckpointPosition(checkpoint, vgtid, time.Now())
+	c.active[name] = checkpoint
+	saved := checkpoint.CloneVT()
+	c.mu.Unlock()
+
+	if err := c.evict(ctx, name); err != nil {
+		log.Warningf("Failed to evict the vstream checkpoints: %v", err)
+	}
+	if err := c.save(ctx, saved); err != nil {
+		c.mu.Lock()
+		delete(c.active, name)
+		c.mu.Unlock()
+		return err
+	}
+	c.ticksMu.Lock()
+	defer c.ticksMu.Unlock()
+	c.ticks.Start(c.saveActive)
+	return nil
+}
+
+// stop marks the stream of the name as inactive, and saves its last position.
+func (c *vstreamCatalog) stop(name string) {
+	c.mu.Lock()
+	checkpoint, ok := c.active[name]
+	dirty := c.dirty[name]
+	delete(c.active, name)
+	delete(c.dirty, name)
+	c.mu.Unlock()
+	c.stopTicksIfIdle()
+	if !ok || !dirty {
+		return
+	}
+	// The stream context is done by now.
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+	if err := c.save(ctx, checkpoint); err != nil {
+		log.Warningf("Failed to save the last position of vstream %s: %v", name, err)
+	}
+}
+
+func (c *vstreamCatalog) stopTicksIfIdle() {
+	c.ticksMu.Lock()
+	defer c.ticksMu.Unlock()
+	c.mu.Lock()
+	idle := len(c.active) == 0
+	c.mu.Unlock()
+	if idle {
+		c.ticks.Stop()
+	}
+}
+
+// record sets the last position the stream of the name sent. It is saved
+// with the next periodic save.
+func (c *vstreamCatalog) record(name string, vgtid *binlogdatapb.VGtid) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if checkpoint, ok := c.active[name]; ok {
+		setCheckpointPosition(checkpoint, vgtid, time.Now())
+		c.dirty[name] = true
+	}
+}
+
+// saveActive saves the positions of the active streams that changed since
+// they were last saved.
+func (c *vstreamCatalog) saveActive() {
+	c.mu.Lock()
+	checkpoints := make([]*vtgatepb.VStreamCheckpoint, 0, len(c.dirty))
+	for name := range c.dirty {
+		checkpoints = append(checkpoints, c.active[name].CloneVT())
+	}
+	clear(c.dirty)
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+	for _, checkpoint := range checkpoints {
+		if err := c.save(ctx, checkpoint); err != nil {
+			log.Warningf("Failed to save the position of vstream %s: %v", checkpoint.Name, err)
+			c.mu.Lock()
+			if _, ok := c.active[checkpoint.Name]; ok {
+				c.dirty[checkpoint.Name] = true
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// save saves the checkpoint in the global topo. Whether the stream is active
+// is only known to the vtgate running it, so it is not saved.
+func (c *vstreamCatalog) save(ctx context.Context, checkpoint *vtgatepb.VStreamCheckpoint) error {
+	conn, err := c.conn(ctx)
+	if err != nil {
+		return err
+	}
+	checkpoint = checkpoint.CloneVT()
+	checkpoint.Active = false
+	data, err := checkpoint.MarshalVT()
+	if err != nil {
+		return err
+	}
+	_, err = conn.Update(ctx, vstreamCheckpointPath(checkpoint.Name), data, nil)
+	return err
+}
+
+func setCheckpointPosition(checkpoint *vtgatepb.VStreamCheckpoint, vgtid *binlogdatapb.VGtid, now time.Time) {
+	// The token of an invalid position is left empty, and such a position
+	// can't be resumed from anyway.
+	checkpoint.Vgtid = vgtid.CloneVT()
+	checkpoint.CheckpointToken, _ = EncodeCheckpointToken(vgtid)
+	checkpoint.TimeUpdated = protoutil.TimeToProto(now)
+}
+
+// evict drops the least recently updated checkpoint that isn't active on
+// this vtgate when the catalog is full, before the checkpoint of a new
+// stream is saved.
+func (c *vstreamCatalog) evict(ctx context.Context, name string) error {
+	conn, err := c.conn(ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := conn.ListDir(ctx, vstreamCheckpointsPath, false)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		return nil
+	case err != nil:
+		return err
+	case len(entries) < maxVStreamCheckpoints:
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.Name == url.PathEscape(name) {
+			return nil
+		}
+	}
+	checkpoints, err := c.read(ctx, conn, entries)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	var oldest *vtgatepb.VStreamCheckpoint
+	for _, checkpoint := range checkpoints {
+		if _, ok := c.active[checkpoint.Name]; ok {
+			continue
+		}
+		if oldest == nil || protoutil.TimeFromProto(checkpoint.TimeUpdated).Before(protoutil.TimeFromProto(oldest.TimeUpdated)) {
+			oldest = checkpoint
+		}
+	}
+	c.mu.Unlock()
+	if oldest == nil {
+		return nil
+	}
+	if err := conn.Delete(ctx, vstreamCheckpointPath(oldest.Name), nil); err != nil && !topo.IsErrType(err, topo.NoNode) {
+		return err
+	}
+	return nil
+}
+
+// read returns the saved checkpoints of the directory entries.
+func (c *vstreamCatalog) read(ctx context.Context, conn topo.Conn, entries []topo.DirEntry) ([]*vtgatepb.VStreamCheckpoint, error) {
+	checkpoints := make([]*vtgatepb.VStreamCheckpoint, 0, len(entries))
+	for _, entry := range entries {
+		data, _, err := conn.Get(ctx, path.Join(vstreamCheckpointsPath, entry.Name))
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			// Evicted in the meantime.
+			continue
+		case err != nil:
+			return nil, err
+		}
+		checkpoint := &vtgatepb.VStreamCheckpoint{}
+		if err := checkpoint.UnmarshalVT(data); err != nil {
+			return nil, vterrors.Wrapf(err, "invalid checkpoint of vstream %s", entry.Name)
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, nil
+}
+
+// list returns the saved checkpoints sorted by name, only the one of the name
+// if set. The ones of the streams running on this vtgate are active, and
+// have their last position.
+func (c *vstreamCatalog) list(ctx context.Context, name string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	conn, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entries []topo.DirEntry
+	if name != "" {
+		entries = []topo.DirEntry{{Name: url.PathEscape(name)}}
+	} else {
+		entries, err = conn.ListDir(ctx, vstreamCheckpointsPath, false)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return nil, err
+		}
+	}
+	checkpoints, err := c.read(ctx, conn, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, checkpoint := range checkpoints {
+		if active, ok := c.active[checkpoint.Name]; ok {
+			checkpoints[i] = active.CloneVT()
+		}
+	}
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Name < checkpoints[j].Name
+	})
+	return checkpoints, nil
+}
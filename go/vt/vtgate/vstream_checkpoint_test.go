@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestCheckpointToken(t *testing.T) {
+	vgtid := &binlogdatapb.VGtid{
+		ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: "ks",
+			Shard:    "-80",
+			Gtid:     "MySQL56/a:1-10",
+			TablePKs: []*binlogdatapb.TableLastPK{{TableName: "t1"}},
+		}, {
+			Keyspace: "ks",
+			Shard:    "80-",
+			Gtid:     "MySQL56/b:1-20",
+		}},
+	}
+	token, err := EncodeCheckpointToken(vgtid)
+	require.NoError(t, err)
+	got, err := DecodeCheckpointToken(token)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(vgtid, got), "got %v, want %v", got, vgtid)
+
+	emptyToken, err := EncodeCheckpointToken(&binlogdatapb.VGtid{})
+	require.NoError(t, err)
+	for _, token := range []string{"", "ks:-80@pos", "v1.!!", "v1.AAAA", emptyToken} {
+		_, err := DecodeCheckpointToken(token)
+		assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "token %q", token)
+	}
+}
+
+func TestLastVGtid(t *testing.T) {
+	vgtid1 := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "0", Gtid: "pos1"}}}
+	vgtid2 := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "0", Gtid: "pos2"}}}
+	assert.Nil(t, LastVGtid([]*binlogdatapb.VEvent{{Type: binlogdatapb.VEventType_HEARTBEAT}}))
+	assert.Equal(t, vgtid2, LastVGtid([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: vgtid1},
+		{Type: binlogdatapb.VEventType_COMMIT},
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: vgtid2},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}))
+}
+
+func TestVStreamCatalogEviction(t *testing.T) {
+	ctx := context.Background()
+	c := newVStreamCatalog(getSandboxTopo(ctx, "aa", "ks", []string{"0"}))
+	vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "0", Gtid: "pos"}}}
+	for i := 0; i < maxVStreamCheckpoints; i++ {
+		name := fmt.Sprintf("stream%04d", i)
+		require.NoError(t, c.start(ctx, name, topodatapb.TabletType_REPLICA, vgtid))
+		// The first stream stays active.
+		if i > 0 {
+			c.stop(name)
+		}
+	}
+	// The oldest inactive stream is dropped, but not the active one.
+	require.NoError(t, c.start(ctx, "new", topodatapb.TabletType_REPLICA, vgtid))
+	checkpoints, err := c.list(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, maxVStreamCheckpoints)
+	assert.Equal(t, "new", checkpoints[0].Name)
+	assert.Equal(t, "stream0000", checkpoints[1].Name)
+	assert.Equal(t, "stream0002", checkpoints[2].Name)
+	c.stop("new")
+	c.stop("stream0000")
+}
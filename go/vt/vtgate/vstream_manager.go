@@ -51,6 +51,9 @@ type vstreamManager struct {
 
 	vstreamsCreated *stats.CountersWithMultiLabels
 	vstreamsLag     *stats.GaugesWithMultiLabels
+
+	// catalog records the last positions of the named vstreams.
+	catalog *vstreamCatalog
 }
 
 // maxSkewTimeoutSeconds is the maximum allowed skew between two streams when the MinimizeSkew flag is set
@@ -98,6 +101,10 @@ type vstream struct {
 	// default behavior is to automatically migrate the resharded streams from the old to the new shards
 	stopOnReshard bool
 
+	// name is the stream_name set by the client, under which the positions
+	// sent to the client are recorded in the catalog of the vstreamManager.
+	name string
+
 	// mutex used to synchronize access to skew detection parameters
 	skewMu sync.Mutex
 	// channel is created whenever there is a skew detected. closing it implies the current skew has been fixed
@@ -144,6 +151,7 @@ func newVStreamManager(resolver *srvtopo.Resolver, serv srvtopo.Server, cell str
 			"VStreamsLag",
 			"Difference between event current time and the binlog event timestamp",
 			[]string{"Keyspace", "ShardName", "TabletType"}),
+		catalog: newVStreamCatalog(serv),
 	}
 }
 
@@ -182,10 +190,23 @@ func (vsm *vstreamManager) VStream(ctx context.Context, tabletType topodatapb.Ta
 			CellPreference: flags.GetCellPreference(),
 			TabletOrder:    flags.GetTabletOrder(),
 		},
+		name: flags.GetStreamName(),
+	}
+	if vs.name != "" {
+		if err := vsm.catalog.start(ctx, vs.name, tabletType, vgtid); err != nil {
+			return err
+		}
+		defer vsm.catalog.stop(vs.name)
 	}
 	return vs.stream(ctx)
 }
 
+// GetCheckpoints returns the last positions of the named vstreams, only the
+// one of the name if set.
+func (vsm *vstreamManager) GetCheckpoints(ctx context.Context, name string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	return vsm.catalog.list(ctx, name)
+}
+
 // resolveParams provides defaults for the inputs if they're not specified.
 func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
 	filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (*binlogdatapb.VGtid, *binlogdatapb.Filter, *vtgatepb.VStreamFlags, error) {
@@ -320,6 +341,13 @@ func (vs *vstream) sendEvents(ctx context.Context) {
 			})
 			return err
 		}
+		// The position is only recorded once the client has it, so that
+		// resuming from it doesn't skip events.
+		if vs.name != "" {
+			if vgtid := LastVGtid(evs); vgtid != nil {
+				vs.vsm.catalog.record(vs.name, vgtid)
+			}
+		}
 		return nil
 	}
 	for {
@@ -936,6 +964,17 @@ func (vs *vstream) getJournalEvent(ctx context.Context, sgtid *binlogdatapb.Shar
 			vs.startOneStream(ctx, sgtid)
 		}
 		vs.vgtid.ShardGtids = newsgtids
+
+		// Send the new position right away, before any event of the new shards,
+		// so that the clients checkpoint positions of the serving shards, and
+		// don't depend on the old shards to resume from.
+		select {
+		case <-ctx.Done():
+		case vs.eventCh <- []*binlogdatapb.VEvent{{
+			Type:  binlogdatapb.VEventType_VGTID,
+			Vgtid: vs.vgtid.CloneVT(),
+		}}:
+		}
 	}
 	close(je.done)
 	return je, nil
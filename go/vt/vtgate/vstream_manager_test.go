@@ -198,6 +198,91 @@ func TestVStreamEvents(t *testing.T) {
 	<-ch
 }
 
+func TestVStreamCheckpoints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cell := "aa"
+	ks := "TestVStream"
+	_ = createSandbox(ks)
+	hc := discovery.NewFakeHealthCheck(nil)
+	st := getSandboxTopo(ctx, cell, ks, []string{"-20"})
+
+	vsm := newTestVStreamManager(ctx, hc, st, cell)
+	sbc0 := hc.AddTestTablet(cell, "1.1.1.1", 1001, ks, "-20", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, ctx, st, ks, "-20", sbc0.Tablet())
+
+	sbc0.AddVStreamEvents([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_GTID, Gtid: "gtid01"},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}, nil)
+	want1 := &binlogdatapb.VStreamResponse{Events: []*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: ks,
+				Shard:    "-20",
+				Gtid:     "gtid01",
+			}},
+		}},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}}
+
+	vgtid := &binlogdatapb.VGtid{
+		ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: ks,
+			Shard:    "-20",
+			Gtid:     "pos",
+		}},
+	}
+	flags := &vtgatepb.VStreamFlags{StreamName: "stream1"}
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	ch := startVStream(streamCtx, t, vsm, vgtid, flags)
+	verifyEvents(t, ch, want1)
+
+	// The position is recorded once the client received it.
+	var checkpoint *vtgatepb.VStreamCheckpoint
+	require.Eventually(t, func() bool {
+		checkpoints, err := vsm.GetCheckpoints(ctx, "stream1")
+		require.NoError(t, err)
+		if len(checkpoints) != 1 || checkpoints[0].Vgtid.ShardGtids[0].Gtid != "gtid01" {
+			return false
+		}
+		checkpoint = checkpoints[0]
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, checkpoint.Active)
+	assert.Equal(t, topodatapb.TabletType_PRIMARY, checkpoint.TabletType)
+	assert.NotNil(t, checkpoint.TimeUpdated)
+	resumeFrom, err := DecodeCheckpointToken(checkpoint.CheckpointToken)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(want1.Events[0].Vgtid, resumeFrom), "got %v, want %v", resumeFrom, want1.Events[0].Vgtid)
+	checkpoints, err := vsm.GetCheckpoints(ctx, "stream2")
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+
+	// Only one stream of a name can run at a time.
+	err = vsm.VStream(ctx, topodatapb.TabletType_PRIMARY, vgtid, nil, flags, func(events []*binlogdatapb.VEvent) error {
+		return nil
+	})
+	assert.Equal(t, vtrpcpb.Code_ALREADY_EXISTS, vterrors.Code(err))
+
+	// The checkpoint is kept once the stream ends.
+	streamCancel()
+	require.Eventually(t, func() bool {
+		checkpoints, err := vsm.GetCheckpoints(ctx, "")
+		require.NoError(t, err)
+		return len(checkpoints) == 1 && !checkpoints[0].Active
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The checkpoint is saved in the topo, so that another vtgate, or this one
+	// after a restart, can resume the stream from it.
+	vsm2 := newTestVStreamManager(ctx, hc, st, cell)
+	checkpoints, err = vsm2.GetCheckpoints(ctx, "stream1")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	assert.False(t, checkpoints[0].Active)
+	assert.Equal(t, checkpoint.CheckpointToken, checkpoints[0].CheckpointToken)
+}
+
 // TestVStreamChunks ensures that a transaction that's broken
 // into chunks is sent together.
 func TestVStreamChunks(t *testing.T) {
@@ -648,6 +733,21 @@ func TestVStreamJournalOneToMany(t *testing.T) {
 	ch := startVStream(ctx, t, vsm, vgtid, nil)
 	verifyEvents(t, ch, want1)
 
+	// The position of the new shards is sent as soon as the stream moves to them.
+	verifyEvents(t, ch, &binlogdatapb.VStreamResponse{Events: []*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: ks,
+				Shard:    "-10",
+				Gtid:     "pos10",
+			}, {
+				Keyspace: ks,
+				Shard:    "10-20",
+				Gtid:     "pos1020",
+			}},
+		}},
+	}})
+
 	// The following two events from the different shards can come in any order.
 	// But the resulting VGTID should be the same after both are received.
 	<-ch
@@ -789,6 +889,16 @@ func TestVStreamJournalManyToOne(t *testing.T) {
 	if !proto.Equal(gotEvent, wantevent) {
 		t.Errorf("vgtid: %v, want %v", got.Events[0], wantevent)
 	}
+	// The position of the new shard is sent as soon as the stream moves to it.
+	verifyEvents(t, ch, &binlogdatapb.VStreamResponse{Events: []*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: ks,
+				Shard:    "-20",
+				Gtid:     "pos20",
+			}},
+		}},
+	}})
 	verifyEvents(t, ch, want1)
 }
 
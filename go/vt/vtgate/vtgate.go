@@ -20,11 +20,14 @@ package vtgate
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,6 +39,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/tb"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -52,8 +56,11 @@ import (
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/buffer"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	vtschema "vitess.io/vitess/go/vt/vtgate/schema"
+	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 	"vitess.io/vitess/go/vt/vtgate/vtgateservice"
 )
 
@@ -61,15 +68,21 @@ var (
 	transactionMode  = "MULTI"
 	normalizeQueries = true
 	streamBufferSize = 32 * 1024
+	// maxStreamBufferSize caps the stream buffer size clients can request in their session options
+	maxStreamBufferSize = 4 * 1024 * 1024
 
 	terseErrors      bool
 	truncateErrorLen int
+	// maxRetryAfterHint caps the retry-after hints added to the errors of the
+	// queries that timed out or outlasted a failover, 0 to add none.
+	maxRetryAfterHint time.Duration
 
 	// plan cache related flag
 	queryPlanCacheMemory int64 = 32 * 1024 * 1024 // 32mb
 
 	maxMemoryRows   = 300000
 	warnMemoryRows  = 30000
+	spillDir        string
 	maxPayloadSize  int
 	warnPayloadSize int
 
@@ -117,17 +130,49 @@ var (
 	warmingReadsPercent      = 0
 	warmingReadsQueryTimeout = 5 * time.Second
 	warmingReadsConcurrency  = 500
+
+	// inListChunkSize enables plan variants for IN lists larger than this size
+	inListChunkSize = 0
+
+	// scatterAggregationRefetchTimeout enables querying again the shards of scatter aggregations that fail with a transient error
+	scatterAggregationRefetchTimeout time.Duration
+
+	// showColumnsPassthrough sends SHOW COLUMNS and DESCRIBE to the tablets,
+	// instead of answering them from the tracked schema.
+	showColumnsPassthrough = false
+
+	// queryCollapsingKeyspaces are the keyspaces whose identical concurrent reads share a single execution
+	queryCollapsingKeyspaces []string
+
+	// enforceSQLModeChecks are the sql_mode checks of the inserted values done by vtgate
+	enforceSQLModeChecks []string
+
+	// planDriftMode flags the plans whose executions drift far from their first ones
+	planDriftMode   = planDriftOff
+	planDriftWindow = 100
+	planDriftFactor = 10.0
+
+	// advisoryLockService is where the advisory locks of GET_LOCK are held
+	advisoryLockService = advisoryLockServiceShard
 )
 
 func registerFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&transactionMode, "transaction_mode", transactionMode, "SINGLE: disallow multi-db transactions, MULTI: allow multi-db transactions with best effort commit, TWOPC: allow multi-db transactions with 2pc commit")
 	fs.BoolVar(&normalizeQueries, "normalize_queries", normalizeQueries, "Rewrite queries with bind vars. Turn this off if the app itself sends normalized queries with bind vars.")
 	fs.BoolVar(&terseErrors, "vtgate-config-terse-errors", terseErrors, "prevent bind vars from escaping in returned errors")
+	fs.DurationVar(&maxRetryAfterHint, "max-retry-after-hint", maxRetryAfterHint, "When greater than 0, the errors of the queries that time out or fail during a failover tell the clients how long to wait before retrying, at most this long: as long as the query ran before it timed out, or until the buffering of the failover stops (only with --enable_buffer). The hint is a '(retry after <N>ms)' suffix of the error message, and a field of the error for the gRPC clients.")
 	fs.IntVar(&truncateErrorLen, "truncate-error-len", truncateErrorLen, "truncate errors sent to client if they are longer than this value (0 means do not truncate)")
+	fs.StringToIntVar(&keyspaceConcurrencyBudgets, "keyspace-concurrency-budget", keyspaceConcurrencyBudgets, "Maximum number of queries that all the vtgates together run concurrently against a keyspace, as a comma separated list of keyspace=budget. The vtgates register themselves in the global topo, and each of them enforces an equal share of the budget, of at least one query. Queries beyond the share of a vtgate fail immediately.")
+	fs.DurationVar(&concurrencyBudgetHeartbeat, "keyspace-concurrency-budget-heartbeat", concurrencyBudgetHeartbeat, "How often the vtgates that enforce a keyspace concurrency budget register themselves in the global topo and recompute their share of the budgets. A vtgate whose registration didn't change for 3 heartbeats, as seen by the other vtgates, no longer gets a share.")
+	fs.BoolVar(&registerEndpoint, "topo-register-endpoint", registerEndpoint, "Register the gRPC address of the vtgate in the topo of its cell, so that the clients can discover it there.")
+	fs.StringVar(&endpointAddress, "topo-endpoint-address", endpointAddress, "The gRPC address registered in the topo by --topo-register-endpoint. Defaults to the hostname and the gRPC port of the vtgate.")
+	fs.DurationVar(&endpointHeartbeat, "topo-endpoint-heartbeat", endpointHeartbeat, "How often the vtgate registers its endpoint in the topo. The registrations that didn't change for 3 heartbeats, as seen by the other vtgates, are removed.")
 	fs.IntVar(&streamBufferSize, "stream_buffer_size", streamBufferSize, "the number of bytes sent from vtgate for each stream call. It's recommended to keep this value in sync with vttablet's query-server-config-stream-buffer-size.")
+	fs.IntVar(&maxStreamBufferSize, "max-stream-buffer-size", maxStreamBufferSize, "the maximum number of bytes a client can request to be sent from vtgate for each stream call, overriding stream_buffer_size.")
 	fs.Int64Var(&queryPlanCacheMemory, "gate_query_cache_memory", queryPlanCacheMemory, "gate server query cache size in bytes, maximum amount of memory to be cached. vtgate analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 	fs.IntVar(&maxMemoryRows, "max_memory_rows", maxMemoryRows, "Maximum number of rows that will be held in memory for intermediate results as well as the final result.")
 	fs.IntVar(&warnMemoryRows, "warn_memory_rows", warnMemoryRows, "Warning threshold for in-memory results. A row count higher than this amount will cause the VtGateWarnings.ResultsExceeded counter to be incremented.")
+	fs.StringVar(&spillDir, "spill-dir", spillDir, "Directory of the temporary files of the intermediate results spilled to disk, such as the rows seen by a DISTINCT beyond max_memory_rows. Defaults to the temporary directory of the system.")
 	fs.StringVar(&defaultDDLStrategy, "ddl_strategy", defaultDDLStrategy, "Set default strategy for DDL statements. Override with @@ddl_strategy session variable")
 	fs.StringVar(&dbDDLPlugin, "dbddl_plugin", dbDDLPlugin, "controls how to handle CREATE/DROP DATABASE. use it if you are using your own database provisioning service")
 	fs.BoolVar(&noScatter, "no_scatter", noScatter, "when set to true, the planner will fail instead of producing a plan that includes scatter queries")
@@ -153,6 +198,16 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&warmingReadsPercent, "warming-reads-percent", 0, "Percentage of reads on the primary to forward to replicas. Useful for keeping buffer pools warm")
 	fs.IntVar(&warmingReadsConcurrency, "warming-reads-concurrency", 500, "Number of concurrent warming reads allowed")
 	fs.DurationVar(&warmingReadsQueryTimeout, "warming-reads-query-timeout", 5*time.Second, "Timeout of warming read queries")
+	fs.IntVar(&inListChunkSize, "in-list-chunk-size", inListChunkSize, "When greater than 0, queries with IN lists larger than this get a dedicated plan that sends the list values to each shard in batches of at most this size")
+	fs.DurationVar(&scatterAggregationRefetchTimeout, "scatter-aggregation-refetch-timeout", scatterAggregationRefetchTimeout, "When greater than 0, the shards of a scatter aggregation outside of a transaction that fail with a transient error are queried again within this timeout, instead of failing the query")
+	fs.IntVar(&tableStatsMaxTables, "table-stats-max-tables", tableStatsMaxTables, "Maximum number of distinct tables for which per table query, error and latency stats are kept. Tables beyond the limit are reported as 'other'. 0 disables the per table stats.")
+	fs.BoolVar(&showColumnsPassthrough, "show-columns-passthrough", showColumnsPassthrough, "Send SHOW COLUMNS and DESCRIBE to the tablets, instead of answering them from the schema tracked by vtgate when the table is tracked")
+	fs.StringSliceVar(&queryCollapsingKeyspaces, "query-collapsing-keyspaces", queryCollapsingKeyspaces, "Keyspaces for which identical read queries that run concurrently outside of transactions share a single execution and its result")
+	fs.StringSliceVar(&enforceSQLModeChecks, "enforce-sql-mode", enforceSQLModeChecks, "sql_mode checks of the inserted values that vtgate enforces itself, when they are in the sql_mode of the session, so that invalid values are rejected before they are sent to the shards. Valid values are: NO_ZERO_DATE, STRICT_TRANS_TABLES")
+	fs.StringVar(&planDriftMode, "plan-drift-mode", planDriftMode, "What to do with the cached plans whose executions drift far from their first ones, e.g. get much slower or return many more rows: off, or flag to report them on /debug/plan_drift")
+	fs.IntVar(&planDriftWindow, "plan-drift-window", planDriftWindow, "Number of executions of a plan averaged for its baseline, and for each later comparison to it, see --plan-drift-mode")
+	fs.StringVar(&advisoryLockService, "advisory-lock-service", advisoryLockService, "Where the advisory locks of GET_LOCK are held: shard to hold them in MySQL on the first shard of the first keyspace, or topo to hold them in the global topo, released when the sessions close")
+	fs.Float64Var(&planDriftFactor, "plan-drift-factor", planDriftFactor, "How many times slower, or how many times more rows, the executions of a plan must get compared to its baseline to drift, see --plan-drift-mode")
 }
 
 func init() {
@@ -187,7 +242,9 @@ var (
 	// Error counters should be global so they can be set from anywhere
 	errorCounts = stats.NewCountersWithMultiLabels("VtgateApiErrorCounts", "Vtgate API error counts per error type", []string{"Operation", "Keyspace", "DbType", "Code"})
 
-	warnings = stats.NewCountersWithSingleLabel("VtGateWarnings", "Vtgate warnings", "type", "IgnoredSet", "NonAtomicCommit", "ResultsExceeded", "WarnPayloadSizeExceeded", "WarnUnshardedOnly")
+	retryAfterHints = stats.NewCountersWithSingleLabel("VtgateRetryAfterHints", "Vtgate API errors returned with a retry-after hint, per error code", "Code")
+
+	warnings = stats.NewCountersWithSingleLabel("VtGateWarnings", "Vtgate warnings", "type", "IgnoredSet", "NonAtomicCommit", "ResultsExceeded", "WarnPayloadSizeExceeded", "WarnUnshardedOnly", "ReservedConnTempTablesLost")
 
 	vstreamSkewDelayCount = stats.NewCounter("VStreamEventsDelayedBySkewAlignment",
 		"Number of events that had to wait because the skew across shards was too high")
@@ -231,6 +288,7 @@ type VTGate struct {
 	// the throttled loggers for all errors, one per API entry
 	logExecute       *logutil.ThrottledLogger
 	logPrepare       *logutil.ThrottledLogger
+	logGetPlan       *logutil.ThrottledLogger
 	logStreamExecute *logutil.ThrottledLogger
 }
 
@@ -274,6 +332,24 @@ func Init(
 	if _, err := schema.ParseDDLStrategy(defaultDDLStrategy); err != nil {
 		log.Fatalf("Invalid value for -ddl_strategy: %v", err.Error())
 	}
+	for _, mode := range enforceSQLModeChecks {
+		if !engine.IsEnforcedSQLMode(mode) {
+			log.Fatalf("Invalid value for --enforce-sql-mode: %v", mode)
+		}
+	}
+	switch planDriftMode {
+	case planDriftOff, planDriftFlag:
+	default:
+		log.Fatalf("Invalid value for --plan-drift-mode: %v", planDriftMode)
+	}
+	if planDriftWindow <= 0 || planDriftFactor <= 1 {
+		log.Fatalf("--plan-drift-window must be > 0 and --plan-drift-factor must be > 1")
+	}
+	switch advisoryLockService {
+	case advisoryLockServiceShard, advisoryLockServiceTopo:
+	default:
+		log.Fatalf("Invalid value for --advisory-lock-service: %v", advisoryLockService)
+	}
 	tc := NewTxConn(gw, getTxMode())
 	// ScatterConn depends on TxConn to perform forced rollbacks.
 	sc := NewScatterConn("VttabletCall", tc, gw)
@@ -329,6 +405,19 @@ func Init(
 		log.Fatalf("error initializing query logger: %v", err)
 	}
 
+	// The vtgates register themselves under their host and port to share the concurrency budgets,
+	// and to advertise their endpoint to the clients.
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	executor.concurrencyBudget = newConcurrencyBudget(ts, fmt.Sprintf("%s-%d", hostname, servenv.Port()), keyspaceConcurrencyBudgets, concurrencyBudgetHeartbeat)
+	address := endpointAddress
+	if address == "" && servenv.GRPCPort() != 0 {
+		address = net.JoinHostPort(hostname, strconv.Itoa(servenv.GRPCPort()))
+	}
+	endpoint := newEndpointRegistration(ts, cell, fmt.Sprintf("%s-%d", hostname, servenv.Port()), address, endpointHeartbeat)
+
 	// connect the schema tracker with the vschema manager
 	if enableSchemaChangeSignal {
 		st.RegisterSignalReceiver(executor.vm.Rebuild)
@@ -353,6 +442,8 @@ func Init(
 		if st != nil && enableSchemaChangeSignal {
 			st.Start()
 		}
+		executor.concurrencyBudget.Start()
+		endpoint.Start()
 		srv := initMySQLProtocol(vtgateInst)
 		if srv != nil {
 			servenv.OnTermSync(srv.shutdownMysqlProtocolAndDrain)
@@ -363,6 +454,8 @@ func Init(
 		if st != nil && enableSchemaChangeSignal {
 			st.Stop()
 		}
+		endpoint.Stop()
+		executor.concurrencyBudget.Stop()
 	})
 	vtgateInst.registerDebugHealthHandler()
 	vtgateInst.registerDebugEnvHandler()
@@ -446,7 +539,8 @@ func (vtg *VTGate) Execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConn
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"Execute", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	start := time.Now()
+	defer vtg.timings.Record(statsKey, start)
 
 	if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
 		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", bvErr)
@@ -466,7 +560,7 @@ func (vtg *VTGate) Execute(ctx context.Context, mysqlCtx vtgateservice.MySQLConn
 		"BindVariables": bindVariables,
 		"Session":       session,
 	}
-	err = recordAndAnnotateError(err, statsKey, query, vtg.logExecute, vtg.executor.vm.parser)
+	err = recordAndAnnotateError(err, statsKey, query, vtg.logExecute, vtg.executor.vm.parser, vtg.retryAfterHint(err, start))
 	return session, nil, err
 }
 
@@ -505,7 +599,8 @@ func (vtg *VTGate) StreamExecute(ctx context.Context, mysqlCtx vtgateservice.MyS
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"StreamExecute", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
 
-	defer vtg.timings.Record(statsKey, time.Now())
+	start := time.Now()
+	defer vtg.timings.Record(statsKey, start)
 
 	safeSession := NewSafeSession(session)
 	var err error
@@ -532,11 +627,39 @@ func (vtg *VTGate) StreamExecute(ctx context.Context, mysqlCtx vtgateservice.MyS
 			"BindVariables": bindVariables,
 			"Session":       session,
 		}
-		return safeSession.Session, recordAndAnnotateError(err, statsKey, query, vtg.logStreamExecute, vtg.executor.vm.parser)
+		return safeSession.Session, recordAndAnnotateError(err, statsKey, query, vtg.logStreamExecute, vtg.executor.vm.parser, vtg.retryAfterHint(err, start))
 	}
 	return safeSession.Session, nil
 }
 
+// GetPlan returns the JSON serialized plan of the query for the target of the
+// session, without executing it.
+func (vtg *VTGate) GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) ([]byte, error) {
+	// In this context, we don't care if we can't fully parse destination
+	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
+	statsKey := []string{"GetPlan", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
+	start := time.Now()
+	defer vtg.timings.Record(statsKey, start)
+
+	plan, err := vtg.executor.GetPlan(ctx, NewSafeSession(session), sql, bindVariables)
+	if err == nil {
+		// The execution stats of a cached plan are not part of its serialized plan.
+		return json.Marshal(&engine.Plan{
+			Type:         plan.Type,
+			Original:     plan.Original,
+			Instructions: plan.Instructions,
+			TablesUsed:   plan.TablesUsed,
+		})
+	}
+
+	query := map[string]any{
+		"Sql":           sql,
+		"BindVariables": bindVariables,
+		"Session":       session,
+	}
+	return nil, recordAndAnnotateError(err, statsKey, query, vtg.logGetPlan, vtg.executor.vm.parser, vtg.retryAfterHint(err, start))
+}
+
 // CloseSession closes the session, rolling back any implicit transactions. This has the
 // same effect as if a "rollback" statement was executed, but does not affect the query
 // statistics.
@@ -554,7 +677,8 @@ func (vtg *VTGate) Prepare(ctx context.Context, session *vtgatepb.Session, sql s
 	// In this context, we don't care if we can't fully parse destination
 	destKeyspace, destTabletType, _, _ := vtg.executor.ParseDestinationTarget(session.TargetString)
 	statsKey := []string{"Prepare", destKeyspace, topoproto.TabletTypeLString(destTabletType)}
-	defer vtg.timings.Record(statsKey, time.Now())
+	start := time.Now()
+	defer vtg.timings.Record(statsKey, start)
 
 	if bvErr := sqltypes.ValidateBindVariables(bindVariables); bvErr != nil {
 		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v", bvErr)
@@ -572,7 +696,7 @@ handleError:
 		"BindVariables": bindVariables,
 		"Session":       session,
 	}
-	err = recordAndAnnotateError(err, statsKey, query, vtg.logPrepare, vtg.executor.vm.parser)
+	err = recordAndAnnotateError(err, statsKey, query, vtg.logPrepare, vtg.executor.vm.parser, vtg.retryAfterHint(err, start))
 	return session, nil, err
 }
 
@@ -581,6 +705,16 @@ func (vtg *VTGate) VStream(ctx context.Context, tabletType topodatapb.TabletType
 	return vtg.vsm.VStream(ctx, tabletType, vgtid, filter, flags, send)
 }
 
+// GetVStreamCheckpoints returns the last positions of the named VStreams,
+// only the one of streamName if set.
+func (vtg *VTGate) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	user := callerid.ImmediateCallerIDFromContext(ctx)
+	if !vschemaacl.Authorized(user) {
+		return nil, vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "User '%s' not authorized to read the vstream checkpoints", user.GetUsername())
+	}
+	return vtg.vsm.GetCheckpoints(ctx, streamName)
+}
+
 // GetGatewayCacheStatus returns a displayable version of the Gateway cache.
 func (vtg *VTGate) GetGatewayCacheStatus() TabletCacheStatusList {
 	return vtg.gw.CacheStatus()
@@ -609,7 +743,7 @@ func truncateErrorStrings(data map[string]any, parser *sqlparser.Parser) map[str
 	return ret
 }
 
-func recordAndAnnotateError(err error, statsKey []string, request map[string]any, logger *logutil.ThrottledLogger, parser *sqlparser.Parser) error {
+func recordAndAnnotateError(err error, statsKey []string, request map[string]any, logger *logutil.ThrottledLogger, parser *sqlparser.Parser, retryAfter time.Duration) error {
 	ec := vterrors.Code(err)
 	fullKey := []string{
 		statsKey[0],
@@ -624,6 +758,12 @@ func recordAndAnnotateError(err error, statsKey []string, request map[string]any
 		err = errors.New(str)
 	}
 
+	// The hint is added last, so that it is kept along the error.
+	if retryAfter > 0 {
+		retryAfterHints.Add(ec.String(), 1)
+		err = vterrors.WithRetryAfter(err, retryAfter)
+	}
+
 	// Traverse the request structure and truncate any long values
 	request = truncateErrorStrings(request, parser)
 
@@ -650,6 +790,31 @@ func recordAndAnnotateError(err error, statsKey []string, request map[string]any
 	return err
 }
 
+// retryAfterHint returns how long the clients should wait before retrying
+// the query started at start, that failed with err, or 0 if waiting doesn't
+// help. A query that timed out waits for as long as it ran, for the load
+// that slowed it down to go away, and a query that outlasted a failover
+// waits for the failover to end. The hints are capped by
+// --max-retry-after-hint, and the ones under a millisecond are dropped.
+func (vtg *VTGate) retryAfterHint(err error, start time.Time) time.Duration {
+	if maxRetryAfterHint <= 0 {
+		return 0
+	}
+	var hint time.Duration
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_DEADLINE_EXCEEDED:
+		hint = time.Since(start)
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_CLUSTER_EVENT, vtrpcpb.Code_FAILED_PRECONDITION:
+		if buffer.IsBufferingError(err) {
+			hint = vtg.gw.FailoverRetryAfter()
+		}
+	}
+	if hint < time.Millisecond {
+		return 0
+	}
+	return min(hint, maxRetryAfterHint)
+}
+
 func formatError(err error) error {
 	if err == nil {
 		return nil
@@ -679,6 +844,7 @@ func newVTGate(executor *Executor, resolver *Resolver, vsm *vstreamManager, tc *
 
 		logExecute:       logutil.NewThrottledLogger("Execute", 5*time.Second),
 		logPrepare:       logutil.NewThrottledLogger("Prepare", 5*time.Second),
+		logGetPlan:       logutil.NewThrottledLogger("GetPlan", 5*time.Second),
 		logStreamExecute: logutil.NewThrottledLogger("StreamExecute", 5*time.Second),
 	}
 }
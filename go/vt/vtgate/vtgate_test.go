@@ -18,8 +18,10 @@ package vtgate
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,8 +30,12 @@ import (
 	"vitess.io/vitess/go/test/utils"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -179,6 +185,52 @@ func TestVTGatePrepareError(t *testing.T) {
 	}
 }
 
+func TestVTGateGetPlan(t *testing.T) {
+	vtg, sbc, ctx := createVtgateEnv(t)
+
+	tcases := []struct {
+		sql     string
+		variant string
+	}{{
+		sql:     "select id from `user`",
+		variant: "Scatter",
+	}, {
+		sql:     "select id from `user` where id = 1",
+		variant: "EqualUnique",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			res, err := vtg.GetPlan(ctx, &vtgatepb.Session{TargetString: "@primary"}, tcase.sql, nil)
+			require.NoError(t, err)
+
+			var plan struct {
+				QueryType    string
+				Instructions struct {
+					OperatorType string
+					Variant      string
+					Keyspace     struct{ Name string }
+				}
+				TablesUsed []string
+				ExecCount  uint64
+			}
+			require.NoError(t, json.Unmarshal(res, &plan))
+			assert.Equal(t, "SELECT", plan.QueryType)
+			assert.Equal(t, "Route", plan.Instructions.OperatorType)
+			assert.Equal(t, tcase.variant, plan.Instructions.Variant)
+			assert.Equal(t, KsTestSharded, plan.Instructions.Keyspace.Name)
+			assert.Equal(t, []string{"TestExecutor.user"}, plan.TablesUsed)
+			assert.Zero(t, plan.ExecCount)
+		})
+	}
+	// The queries are only planned.
+	assert.Zero(t, sbc.ExecCount.Load())
+
+	counts := errorCounts.Counts()
+	_, err := vtg.GetPlan(ctx, &vtgatepb.Session{TargetString: KsTestUnsharded + "@primary"}, "bad select id from t1", nil)
+	require.Error(t, err)
+	assert.Equal(t, counts["GetPlan.TestUnsharded.primary.INVALID_ARGUMENT"]+1, errorCounts.Counts()["GetPlan.TestUnsharded.primary.INVALID_ARGUMENT"])
+}
+
 func TestVTGateExecuteWithKeyspaceShard(t *testing.T) {
 	vtg, _, ctx := createVtgateEnv(t)
 
@@ -368,6 +420,50 @@ func testErrorPropagation(t *testing.T, ctx context.Context, vtg *VTGate, sbcs [
 	}
 }
 
+func TestVTGateRetryAfterHint(t *testing.T) {
+	vtg, _, _ := createVtgateEnv(t)
+
+	save := maxRetryAfterHint
+	maxRetryAfterHint = 250 * time.Millisecond
+	defer func() { maxRetryAfterHint = save }()
+
+	// A query that timed out waits for as long as it ran, up to the maximum.
+	timeout := vterrors.New(vtrpcpb.Code_DEADLINE_EXCEEDED, "timeout")
+	hint := vtg.retryAfterHint(timeout, time.Now().Add(-100*time.Millisecond))
+	assert.GreaterOrEqual(t, hint, 100*time.Millisecond)
+	assert.Less(t, hint, 250*time.Millisecond)
+	assert.Equal(t, 250*time.Millisecond, vtg.retryAfterHint(timeout, time.Now().Add(-time.Second)))
+
+	// Errors the clients can't fix by waiting get no hint, and neither do the
+	// failovers when the requests aren't buffered.
+	assert.Zero(t, vtg.retryAfterHint(vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "invalid"), time.Now().Add(-time.Second)))
+	assert.Zero(t, vtg.retryAfterHint(vterrors.New(vtrpcpb.Code_CLUSTER_EVENT, "current keyspace is being resharded"), time.Now().Add(-time.Second)))
+
+	maxRetryAfterHint = 0
+	assert.Zero(t, vtg.retryAfterHint(timeout, time.Now().Add(-time.Second)))
+}
+
+func TestRecordAndAnnotateErrorRetryAfter(t *testing.T) {
+	saveTerse := terseErrors
+	terseErrors = true
+	defer func() { terseErrors = saveTerse }()
+
+	hints := retryAfterHints.Counts()
+	logger := logutil.NewThrottledLogger("RetryAfter", time.Second)
+	statsKey := []string{"Execute", KsTestUnsharded, "primary"}
+
+	// The hint is kept when the error is made terse.
+	err := recordAndAnnotateError(vterrors.New(vtrpcpb.Code_DEADLINE_EXCEEDED, "timeout"), statsKey, nil, logger, sqlparser.NewTestParser(), 250*time.Millisecond)
+	hint, ok := vterrors.RetryAfter(err)
+	require.True(t, ok, err.Error())
+	assert.Equal(t, 250*time.Millisecond, hint)
+	assert.Equal(t, hints["DEADLINE_EXCEEDED"]+1, retryAfterHints.Counts()["DEADLINE_EXCEEDED"])
+
+	err = recordAndAnnotateError(vterrors.New(vtrpcpb.Code_DEADLINE_EXCEEDED, "timeout"), statsKey, nil, logger, sqlparser.NewTestParser(), 0)
+	_, ok = vterrors.RetryAfter(err)
+	assert.False(t, ok, err.Error())
+}
+
 // TestErrorPropagation tests an error returned by sandboxconn is
 // properly propagated through vtgate layers.  We need both a primary
 // tablet and a rdonly tablet because we don't control the routing of
@@ -711,6 +807,25 @@ func TestVSchemaVindexUnknownParams(t *testing.T) {
 	require.Equal(t, int64(0), unknownParams)
 }
 
+func TestVTGateGetVStreamCheckpointsAuthorization(t *testing.T) {
+	vtg, _, ctx := createVtgateEnv(t)
+	ctx = callerid.NewContext(ctx, nil, &querypb.VTGateCallerID{Username: "redUser"})
+
+	_, err := vtg.GetVStreamCheckpoints(ctx, "")
+	require.EqualError(t, err, "User 'redUser' not authorized to read the vstream checkpoints")
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+
+	vschemaacl.AuthorizedDDLUsers = "redUser"
+	vschemaacl.Init()
+	defer func() {
+		vschemaacl.AuthorizedDDLUsers = ""
+		vschemaacl.Init()
+	}()
+	checkpoints, err := vtg.GetVStreamCheckpoints(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
+
 func createVtgateEnv(t testing.TB) (*VTGate, *sandboxconn.SandboxConn, context.Context) {
 	cell := "aa"
 	sb := createSandbox(KsTestSharded)
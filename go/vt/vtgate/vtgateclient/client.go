@@ -0,0 +1,275 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vtgateclient is a Go client of the vtgate API. Unlike the
+// database/sql driver of the vitessdriver package, it exposes the sessions
+// of vtgate. On top of the vtgateconn package, it discovers the vtgates,
+// spreads the calls over a pool of connections to them, and retries the
+// calls that fail with a transient error.
+//
+//	client, err := vtgateclient.New(ctx, vtgateclient.Options{
+//		Discovery: &vtgateclient.TopoDiscovery{TS: ts, Cells: []string{"zone1"}},
+//	})
+//	...
+//	defer client.Close()
+//	session := client.Session("commerce@primary", nil)
+//	result, err := session.Execute(ctx, "select * from customer", nil)
+package vtgateclient
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Options configure a Client.
+type Options struct {
+	// Protocol is the vtgateconn protocol used to dial the vtgates. It
+	// defaults to the --vtgate_protocol flag.
+	Protocol string
+	// Discovery finds the vtgates. It is required.
+	Discovery Discovery
+	// ConnsPerVTGate is the number of connections to each vtgate the calls
+	// are spread over. It defaults to 1.
+	ConnsPerVTGate int
+	// RefreshInterval is how often the vtgates are discovered again. It
+	// defaults to 30 seconds.
+	RefreshInterval time.Duration
+	// Retry is the retry policy of the calls. The zero value disables
+	// the retries, see DefaultRetryPolicy.
+	Retry RetryPolicy
+}
+
+// Client is a pool of connections to the discovered vtgates. It can be used
+// concurrently, and must be closed to release its connections.
+type Client struct {
+	opts  Options
+	ticks *timer.Timer
+
+	mu sync.Mutex
+	// conns are the connections to each vtgate.
+	conns map[string][]*vtgateconn.VTGateConn
+	// addresses are the addresses of the vtgates, sorted.
+	addresses []string
+	// retired are the connections to the vtgates that are no longer
+	// discovered. They are closed on the next refresh, once the calls in
+	// flight on them are likely done.
+	retired []*vtgateconn.VTGateConn
+	// next is the position of the next connection to use, round robin.
+	next   int
+	closed bool
+}
+
+// New discovers the vtgates, connects to them, and returns a Client that
+// keeps discovering them in the background. It fails if no vtgate can be
+// connected to.
+func New(ctx context.Context, opts Options) (*Client, error) {
+	if opts.Discovery == nil {
+		return nil, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "vtgate discovery is required")
+	}
+	if opts.Protocol == "" {
+		opts.Protocol = vtgateconn.GetVTGateProtocol()
+	}
+	if opts.ConnsPerVTGate <= 0 {
+		opts.ConnsPerVTGate = 1
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = 30 * time.Second
+	}
+	c := &Client{
+		opts:  opts,
+		ticks: timer.NewTimer(opts.RefreshInterval),
+		conns: make(map[string][]*vtgateconn.VTGateConn),
+	}
+	if err := c.refresh(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if len(c.Addresses()) == 0 {
+		c.Close()
+		return nil, vterrors.New(vtrpcpb.Code_UNAVAILABLE, "no vtgate discovered")
+	}
+	c.ticks.Start(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.RefreshInterval)
+		defer cancel()
+		if err := c.refresh(ctx); err != nil {
+			log.Warningf("Failed to discover the vtgates: %v", err)
+		}
+	})
+	return c, nil
+}
+
+// Addresses returns the addresses of the vtgates the client is connected to.
+func (c *Client) Addresses() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.addresses)
+}
+
+// Close closes all the connections. The sessions of the client can't be
+// used afterwards.
+func (c *Client) Close() {
+	c.ticks.Stop()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conns := range c.conns {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+	for _, conn := range c.retired {
+		conn.Close()
+	}
+	c.conns, c.addresses, c.retired = nil, nil, nil
+	c.closed = true
+}
+
+// refresh connects to the newly discovered vtgates, and retires the
+// connections to the vtgates that are gone. If the discovery fails, the
+// known vtgates are kept.
+func (c *Client) refresh(ctx context.Context) error {
+	addresses, err := c.opts.Discovery.Addresses(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	retired := c.retired
+	c.retired = nil
+	var added []string
+	for _, address := range addresses {
+		if _, ok := c.conns[address]; !ok && !slices.Contains(added, address) {
+			added = append(added, address)
+		}
+	}
+	c.mu.Unlock()
+	for _, conn := range retired {
+		conn.Close()
+	}
+
+	// Dial outside of the lock, so that the calls keep going meanwhile.
+	dialed := make(map[string][]*vtgateconn.VTGateConn, len(added))
+	for _, address := range added {
+		conns, err := c.dial(ctx, address)
+		if err != nil {
+			log.Warningf("Failed to connect to vtgate %v: %v", address, err)
+			continue
+		}
+		dialed[address] = conns
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		for _, conns := range dialed {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}
+		return nil
+	}
+	for address, conns := range dialed {
+		c.conns[address] = conns
+	}
+	for address, conns := range c.conns {
+		if !slices.Contains(addresses, address) {
+			c.retired = append(c.retired, conns...)
+			delete(c.conns, address)
+		}
+	}
+	c.addresses = c.addresses[:0]
+	for address := range c.conns {
+		c.addresses = append(c.addresses, address)
+	}
+	sort.Strings(c.addresses)
+	return nil
+}
+
+// dial opens the connections to the vtgate.
+func (c *Client) dial(ctx context.Context, address string) ([]*vtgateconn.VTGateConn, error) {
+	conns := make([]*vtgateconn.VTGateConn, 0, c.opts.ConnsPerVTGate)
+	for i := 0; i < c.opts.ConnsPerVTGate; i++ {
+		conn, err := vtgateconn.DialProtocol(ctx, c.opts.Protocol, address)
+		if err != nil {
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+// pick returns the next connection to use, round robin, and the address of
+// its vtgate. The vtgates to avoid are skipped, unless there are no others.
+func (c *Client) pick(avoid []string) (*vtgateconn.VTGateConn, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, "", vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "vtgate client is closed")
+	}
+	if len(c.addresses) == 0 {
+		return nil, "", vterrors.New(vtrpcpb.Code_UNAVAILABLE, "no vtgate available")
+	}
+	candidates := make([]string, 0, len(c.addresses))
+	for _, address := range c.addresses {
+		if !slices.Contains(avoid, address) {
+			candidates = append(candidates, address)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = c.addresses
+	}
+	address := candidates[c.next%len(candidates)]
+	conns := c.conns[address]
+	conn := conns[(c.next/len(candidates))%len(conns)]
+	c.next++
+	return conn, address, nil
+}
+
+// Session returns a new session of the client, that targets the given
+// keyspace and tablet type, e.g. "commerce@primary".
+func (c *Client) Session(targetString string, options *querypb.ExecuteOptions) *Session {
+	return &Session{
+		client: c,
+		session: &vtgatepb.Session{
+			TargetString: targetString,
+			Options:      options,
+			Autocommit:   true,
+		},
+	}
+}
+
+// SessionFromPb returns a session of the client that resumes the given
+// proto session, e.g. one that was saved by another client.
+func (c *Client) SessionFromPb(session *vtgatepb.Session) *Session {
+	return &Session{
+		client:  c,
+		session: session,
+	}
+}
@@ -0,0 +1,475 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgateclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// fakeVTGates are vtgates reachable through the "fakevtgates" protocol.
+type fakeVTGates struct {
+	mu      sync.Mutex
+	dials   map[string]int
+	closes  map[string]int
+	calls   map[string]int
+	errs    map[string]error
+	streams map[string]int
+}
+
+func newFakeVTGates(t *testing.T) (*fakeVTGates, string) {
+	fv := &fakeVTGates{
+		dials:   make(map[string]int),
+		closes:  make(map[string]int),
+		calls:   make(map[string]int),
+		errs:    make(map[string]error),
+		streams: make(map[string]int),
+	}
+	protocol := "fakevtgates_" + t.Name()
+	vtgateconn.RegisterDialer(protocol, func(ctx context.Context, address string) (vtgateconn.Impl, error) {
+		fv.mu.Lock()
+		defer fv.mu.Unlock()
+		fv.dials[address]++
+		return &fakeVTGateConn{fv: fv, address: address}, nil
+	})
+	t.Cleanup(func() { vtgateconn.DeregisterDialer(protocol) })
+	return fv, protocol
+}
+
+func (fv *fakeVTGates) setError(address string, err error) {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+	fv.errs[address] = err
+}
+
+func (fv *fakeVTGates) count(counts map[string]int, address string) int {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+	return counts[address]
+}
+
+// call records a call on the vtgate, and returns its error if any.
+func (fv *fakeVTGates) call(address string) error {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+	fv.calls[address]++
+	return fv.errs[address]
+}
+
+type fakeVTGateConn struct {
+	vtgateconn.Impl
+	fv      *fakeVTGates
+	address string
+}
+
+func (conn *fakeVTGateConn) result() *sqltypes.Result {
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("vtgate", "varchar"), conn.address)
+}
+
+func (conn *fakeVTGateConn) Execute(ctx context.Context, session *vtgatepb.Session, query string, bindVars map[string]*querypb.BindVariable) (*vtgatepb.Session, *sqltypes.Result, error) {
+	if err := conn.fv.call(conn.address); err != nil {
+		return session, nil, err
+	}
+	session = session.CloneVT()
+	switch query {
+	case "begin":
+		session.InTransaction = true
+	case "commit":
+		session.InTransaction = false
+	}
+	return session, conn.result(), nil
+}
+
+func (conn *fakeVTGateConn) ExecuteBatch(ctx context.Context, session *vtgatepb.Session, queries []string, bindVars []map[string]*querypb.BindVariable) (*vtgatepb.Session, []sqltypes.QueryResponse, error) {
+	if err := conn.fv.call(conn.address); err != nil {
+		return session, nil, err
+	}
+	return session, []sqltypes.QueryResponse{{QueryResult: conn.result()}}, nil
+}
+
+func (conn *fakeVTGateConn) StreamExecute(ctx context.Context, session *vtgatepb.Session, query string, bindVars map[string]*querypb.BindVariable, processResponse func(*vtgatepb.StreamExecuteResponse)) (sqltypes.ResultStream, error) {
+	conn.fv.mu.Lock()
+	conn.fv.streams[conn.address]++
+	broken := conn.fv.errs[conn.address] != nil
+	conn.fv.mu.Unlock()
+	// The streams of the vtgates with an error fail after the given number
+	// of results.
+	failAfter := -1
+	if broken {
+		failAfter = 0
+		_, _ = fmt.Sscanf(query, "select 'fail after %d'", &failAfter)
+	}
+	result := conn.result()
+	return &fakeStream{
+		conn:      conn,
+		failAfter: failAfter,
+		results:   []*sqltypes.Result{{Fields: result.Fields}, {Rows: result.Rows}},
+		update: func() {
+			processResponse(&vtgatepb.StreamExecuteResponse{Session: &vtgatepb.Session{TargetString: conn.address}})
+		},
+	}, nil
+}
+
+type fakeStream struct {
+	conn      *fakeVTGateConn
+	failAfter int
+	results   []*sqltypes.Result
+	update    func()
+	sent      int
+}
+
+func (fs *fakeStream) Recv() (*sqltypes.Result, error) {
+	if fs.sent == fs.failAfter {
+		return nil, vterrors.New(vtrpcpb.Code_UNAVAILABLE, "stream broken")
+	}
+	if fs.sent == len(fs.results) {
+		fs.update()
+		return nil, io.EOF
+	}
+	fs.sent++
+	return fs.results[fs.sent-1], nil
+}
+
+func (conn *fakeVTGateConn) CloseSession(ctx context.Context, session *vtgatepb.Session) error {
+	return conn.fv.call(conn.address)
+}
+
+func (conn *fakeVTGateConn) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (vtgateconn.VStreamReader, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (conn *fakeVTGateConn) Close() {
+	conn.fv.mu.Lock()
+	defer conn.fv.mu.Unlock()
+	conn.fv.closes[conn.address]++
+}
+
+// fakeDiscovery returns addresses that can be changed.
+type fakeDiscovery struct {
+	mu        sync.Mutex
+	addresses []string
+	err       error
+}
+
+func (fd *fakeDiscovery) set(addresses []string, err error) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	fd.addresses, fd.err = addresses, err
+}
+
+func (fd *fakeDiscovery) Addresses(ctx context.Context) ([]string, error) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	return fd.addresses, fd.err
+}
+
+var noBackoff = RetryPolicy{MaxAttempts: 3}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	_, protocol := newFakeVTGates(t)
+
+	_, err := New(ctx, Options{Protocol: protocol})
+	assert.ErrorContains(t, err, "vtgate discovery is required")
+	_, err = New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{}})
+	assert.ErrorContains(t, err, "no vtgate discovered")
+	_, err = New(ctx, Options{Protocol: protocol, Discovery: &fakeDiscovery{err: fmt.Errorf("topo down")}})
+	assert.ErrorContains(t, err, "topo down")
+	_, err = New(ctx, Options{Protocol: "unknown", Discovery: StaticDiscovery{"vtgate1"}})
+	assert.ErrorContains(t, err, "no vtgate discovered")
+
+	client, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate2", "vtgate1", "vtgate2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vtgate1", "vtgate2"}, client.Addresses())
+	client.Close()
+	_, err = client.Session("", nil).Execute(ctx, "select 1", nil)
+	assert.ErrorContains(t, err, "vtgate client is closed")
+}
+
+func TestClientPool(t *testing.T) {
+	ctx := context.Background()
+	fv, protocol := newFakeVTGates(t)
+	client, err := New(ctx, Options{
+		Protocol:       protocol,
+		Discovery:      StaticDiscovery{"vtgate1", "vtgate2"},
+		ConnsPerVTGate: 2,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	assert.Equal(t, 2, fv.count(fv.dials, "vtgate1"))
+	assert.Equal(t, 2, fv.count(fv.dials, "vtgate2"))
+
+	// The calls are spread over the vtgates.
+	session := client.Session("ks@primary", nil)
+	for i := 0; i < 4; i++ {
+		_, err := session.Execute(ctx, "select 1", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, fv.count(fv.calls, "vtgate1"))
+	assert.Equal(t, 2, fv.count(fv.calls, "vtgate2"))
+
+	client.Close()
+	assert.Equal(t, 2, fv.count(fv.closes, "vtgate1"))
+	assert.Equal(t, 2, fv.count(fv.closes, "vtgate2"))
+}
+
+func TestClientRefresh(t *testing.T) {
+	ctx := context.Background()
+	fv, protocol := newFakeVTGates(t)
+	discovery := &fakeDiscovery{addresses: []string{"vtgate1", "vtgate2"}}
+	client, err := New(ctx, Options{Protocol: protocol, Discovery: discovery, RefreshInterval: time.Hour})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// The vtgates that are gone are no longer used, but their connections
+	// are only closed on the next refresh.
+	discovery.set([]string{"vtgate2", "vtgate3"}, nil)
+	require.NoError(t, client.refresh(ctx))
+	assert.Equal(t, []string{"vtgate2", "vtgate3"}, client.Addresses())
+	assert.Equal(t, 1, fv.count(fv.dials, "vtgate2"))
+	assert.Equal(t, 1, fv.count(fv.dials, "vtgate3"))
+	assert.Zero(t, fv.count(fv.closes, "vtgate1"))
+	require.NoError(t, client.refresh(ctx))
+	assert.Equal(t, 1, fv.count(fv.closes, "vtgate1"))
+
+	// The known vtgates are kept if the discovery fails.
+	discovery.set(nil, fmt.Errorf("topo down"))
+	require.Error(t, client.refresh(ctx))
+	assert.Equal(t, []string{"vtgate2", "vtgate3"}, client.Addresses())
+}
+
+func TestSessionRetry(t *testing.T) {
+	ctx := context.Background()
+	fv, protocol := newFakeVTGates(t)
+	client, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate1", "vtgate2"}, Retry: noBackoff})
+	require.NoError(t, err)
+	defer client.Close()
+	session := client.Session("ks@primary", nil)
+
+	// The transient errors are retried on another vtgate.
+	fv.setError("vtgate1", vterrors.New(vtrpcpb.Code_UNAVAILABLE, "vtgate1 is down"))
+	for i := 0; i < 2; i++ {
+		result, err := session.Execute(ctx, "select 1", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "vtgate2", result.Rows[0][0].ToString())
+	}
+	results, err := session.ExecuteBatch(ctx, []string{"select 1"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "vtgate2", results[0].QueryResult.Rows[0][0].ToString())
+
+	// The writes are only retried when the policy allows it.
+	var failed bool
+	for i := 0; i < 2; i++ {
+		if _, err := session.Execute(ctx, "insert into t values (1)", nil); err != nil {
+			assert.ErrorContains(t, err, "vtgate1 is down")
+			failed = true
+		}
+	}
+	assert.True(t, failed)
+	failed = false
+	for i := 0; i < 2; i++ {
+		if _, err := session.ExecuteBatch(ctx, []string{"select 1", "delete from t"}, nil); err != nil {
+			assert.ErrorContains(t, err, "vtgate1 is down")
+			failed = true
+		}
+	}
+	assert.True(t, failed)
+	client.opts.Retry.RetryWrites = true
+	for i := 0; i < 2; i++ {
+		result, err := session.Execute(ctx, "insert into t values (1)", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "vtgate2", result.Rows[0][0].ToString())
+	}
+	client.opts.Retry.RetryWrites = false
+
+	// The other errors are not.
+	fv.setError("vtgate1", vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "bad query"))
+	failed = false
+	for i := 0; i < 2; i++ {
+		if _, err := session.Execute(ctx, "select 1", nil); err != nil {
+			assert.ErrorContains(t, err, "bad query")
+			failed = true
+		}
+	}
+	assert.True(t, failed)
+
+	// Neither are the calls of a session in a transaction.
+	fv.setError("vtgate1", nil)
+	_, err = session.Execute(ctx, "begin", nil)
+	require.NoError(t, err)
+	require.True(t, session.InTransaction())
+	fv.setError("vtgate1", vterrors.New(vtrpcpb.Code_UNAVAILABLE, "vtgate1 is down"))
+	fv.setError("vtgate2", vterrors.New(vtrpcpb.Code_UNAVAILABLE, "vtgate2 is down"))
+	calls := fv.count(fv.calls, "vtgate1") + fv.count(fv.calls, "vtgate2")
+	_, err = session.Execute(ctx, "commit", nil)
+	assert.ErrorContains(t, err, "is down")
+	assert.Equal(t, calls+1, fv.count(fv.calls, "vtgate1")+fv.count(fv.calls, "vtgate2"))
+}
+
+func TestSessionRetryAttempts(t *testing.T) {
+	ctx := context.Background()
+	fv, protocol := newFakeVTGates(t)
+	fv.setError("vtgate1", vterrors.New(vtrpcpb.Code_CLUSTER_EVENT, "reparent in progress"))
+	client, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate1"}, Retry: noBackoff})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// With a single vtgate, it is retried.
+	_, err = client.Session("ks@primary", nil).Execute(ctx, "select 1", nil)
+	assert.ErrorContains(t, err, "reparent in progress")
+	assert.Equal(t, 3, fv.count(fv.calls, "vtgate1"))
+
+	// The retries are disabled by default.
+	client.opts.Retry = RetryPolicy{}
+	_, err = client.Session("ks@primary", nil).Execute(ctx, "select 1", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 4, fv.count(fv.calls, "vtgate1"))
+
+	// The wait between the retries ends with the context.
+	client.opts.Retry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Session("ks@primary", nil).Execute(ctx, "select 1", nil)
+	assert.ErrorContains(t, err, "reparent in progress")
+	assert.Equal(t, 5, fv.count(fv.calls, "vtgate1"))
+}
+
+func TestSessionRetryAfterHint(t *testing.T) {
+	ctx := context.Background()
+	fv, protocol := newFakeVTGates(t)
+	timeout := vterrors.New(vtrpcpb.Code_DEADLINE_EXCEEDED, "query timed out")
+	fv.setError("vtgate1", vterrors.WithRetryAfter(timeout, 20*time.Millisecond))
+	client, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate1"}, Retry: noBackoff})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// The errors hinted by vtgate are retried, after waiting for the hint.
+	hinted := hintedRetries.Counts()["DEADLINE_EXCEEDED"]
+	start := time.Now()
+	_, err = client.Session("ks@primary", nil).Execute(ctx, "select 1", nil)
+	assert.ErrorContains(t, err, "query timed out")
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	assert.Equal(t, 3, fv.count(fv.calls, "vtgate1"))
+	assert.Equal(t, hinted+2, hintedRetries.Counts()["DEADLINE_EXCEEDED"])
+
+	// Without a hint, the timeouts are not retried.
+	fv.setError("vtgate1", timeout)
+	_, err = client.Session("ks@primary", nil).Execute(ctx, "select 1", nil)
+	assert.ErrorContains(t, err, "query timed out")
+	assert.Equal(t, 4, fv.count(fv.calls, "vtgate1"))
+
+	// The writes that time out may still be running, so they are not
+	// retried even when they can be.
+	fv.setError("vtgate1", vterrors.WithRetryAfter(timeout, 20*time.Millisecond))
+	writes, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate1"}, Retry: RetryPolicy{MaxAttempts: 3, RetryWrites: true}})
+	require.NoError(t, err)
+	defer writes.Close()
+	_, err = writes.Session("ks@primary", nil).Execute(ctx, "update t set a = 1", nil)
+	assert.ErrorContains(t, err, "query timed out")
+	assert.Equal(t, 5, fv.count(fv.calls, "vtgate1"))
+
+	// The hints are capped by MaxBackoff.
+	fv.setError("vtgate1", vterrors.WithRetryAfter(timeout, time.Hour))
+	capped, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate1"}, Retry: RetryPolicy{MaxAttempts: 2, MaxBackoff: 10 * time.Millisecond}})
+	require.NoError(t, err)
+	defer capped.Close()
+	start = time.Now()
+	_, err = capped.Session("ks@primary", nil).Execute(ctx, "select 1", nil)
+	assert.ErrorContains(t, err, "query timed out")
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.Equal(t, 7, fv.count(fv.calls, "vtgate1"))
+}
+
+func TestSessionStream(t *testing.T) {
+	ctx := context.Background()
+	fv, protocol := newFakeVTGates(t)
+	client, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate1", "vtgate2"}, Retry: noBackoff})
+	require.NoError(t, err)
+	defer client.Close()
+	session := client.Session("ks@primary", nil)
+
+	var results []*sqltypes.Result
+	collect := func(result *sqltypes.Result) error {
+		results = append(results, result)
+		return nil
+	}
+	require.NoError(t, session.Stream(ctx, "select 1", nil, collect))
+	require.Len(t, results, 2)
+	assert.Equal(t, session.SessionPb().TargetString, results[1].Rows[0][0].ToString())
+
+	// The streams that fail before any result are retried.
+	fv.setError("vtgate1", vterrors.New(vtrpcpb.Code_UNAVAILABLE, "vtgate1 is down"))
+	streams := fv.count(fv.streams, "vtgate1")
+	for i := 0; i < 2; i++ {
+		results = nil
+		require.NoError(t, session.Stream(ctx, "select 'fail after 0'", nil, collect))
+		require.Len(t, results, 2)
+		assert.Equal(t, "vtgate2", results[1].Rows[0][0].ToString())
+	}
+	assert.Equal(t, streams+1, fv.count(fv.streams, "vtgate1"))
+
+	// The others are not.
+	fv.setError("vtgate2", vterrors.New(vtrpcpb.Code_UNAVAILABLE, "vtgate2 is down"))
+	results = nil
+	err = session.Stream(ctx, "select 'fail after 1'", nil, collect)
+	assert.ErrorContains(t, err, "stream broken")
+	require.Len(t, results, 1)
+	fv.setError("vtgate1", nil)
+	fv.setError("vtgate2", nil)
+	err = session.Stream(ctx, "select 1", nil, func(*sqltypes.Result) error { return fmt.Errorf("callback failed") })
+	assert.ErrorContains(t, err, "callback failed")
+
+	// The raw streams keep the session up to date.
+	stream, err := session.StreamExecute(ctx, "select 1", nil)
+	require.NoError(t, err)
+	for {
+		if _, err := stream.Recv(); err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+	}
+	assert.Contains(t, []string{"vtgate1", "vtgate2"}, session.SessionPb().TargetString)
+}
+
+func TestSessionClose(t *testing.T) {
+	ctx := context.Background()
+	fv, protocol := newFakeVTGates(t)
+	client, err := New(ctx, Options{Protocol: protocol, Discovery: StaticDiscovery{"vtgate1"}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	session := client.SessionFromPb(&vtgatepb.Session{InTransaction: true})
+	require.NoError(t, session.Close(ctx))
+	assert.Equal(t, 1, fv.count(fv.calls, "vtgate1"))
+}
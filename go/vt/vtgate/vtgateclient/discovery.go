@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgateclient
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// Discovery finds the addresses of the vtgates a Client connects to.
+type Discovery interface {
+	// Addresses returns the gRPC addresses of the vtgates.
+	Addresses(ctx context.Context) ([]string, error)
+}
+
+// StaticDiscovery is a fixed list of vtgate addresses.
+type StaticDiscovery []string
+
+// Addresses is part of the Discovery interface.
+func (d StaticDiscovery) Addresses(ctx context.Context) ([]string, error) {
+	return slices.Clone(d), nil
+}
+
+// TopoDiscovery finds the vtgates that registered their endpoint in the topo
+// of their cell, see the --topo-register-endpoint flag of vtgate.
+type TopoDiscovery struct {
+	// TS is the topo server of the cluster.
+	TS *topo.Server
+	// Cells are the cells to look up the vtgates in.
+	Cells []string
+	// StaleAfter is how long a vtgate is still used after its registration
+	// last changed, as seen by this client. The vtgates refresh it on every
+	// heartbeat. Zero means the vtgates are used until they are unregistered.
+	StaleAfter time.Duration
+
+	now func() time.Time
+
+	mu sync.Mutex
+	// endpoints are the endpoints of the vtgates of each cell.
+	endpoints map[string]*topo.VTGateEndpoints
+}
+
+// Addresses is part of the Discovery interface. The vtgates of the cells
+// that can be read are returned, and an error only if none could be found.
+func (d *TopoDiscovery) Addresses(ctx context.Context) ([]string, error) {
+	now := time.Now
+	if d.now != nil {
+		now = d.now
+	}
+
+	var (
+		addresses []string
+		rec       concurrency.AllErrorRecorder
+	)
+	for _, cell := range d.Cells {
+		endpoints, err := d.cellEndpoints(cell).Get(ctx, now())
+		if err != nil {
+			rec.RecordError(err)
+			continue
+		}
+		for _, endpoint := range endpoints {
+			if !slices.Contains(addresses, endpoint.Address) {
+				addresses = append(addresses, endpoint.Address)
+			}
+		}
+	}
+	if len(addresses) == 0 && rec.HasErrors() {
+		return nil, rec.Error()
+	}
+	return addresses, nil
+}
+
+// cellEndpoints returns the endpoints of the vtgates of the cell. They are
+// kept from a call to the next, to see which of them change.
+func (d *TopoDiscovery) cellEndpoints(cell string) *topo.VTGateEndpoints {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.endpoints == nil {
+		d.endpoints = make(map[string]*topo.VTGateEndpoints)
+	}
+	endpoints, ok := d.endpoints[cell]
+	if !ok {
+		endpoints = d.TS.NewVTGateEndpoints(cell, d.StaleAfter)
+		d.endpoints[cell] = endpoints
+	}
+	return endpoints
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgateclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestTopoDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	for cell, endpoints := range map[string][]*topo.VTGateEndpoint{
+		"zone1": {
+			{Name: "vtgate1", Address: "host1:15999"},
+			{Name: "vtgate2", Address: "host2:15999"},
+		},
+		"zone2": {
+			{Name: "vtgate3", Address: "host3:15999"},
+		},
+	} {
+		for _, endpoint := range endpoints {
+			require.NoError(t, ts.NewVTGateEndpoints(cell, 0).Save(ctx, endpoint))
+		}
+	}
+
+	now := time.Now()
+	d := &TopoDiscovery{TS: ts, Cells: []string{"zone1"}, StaleAfter: time.Minute, now: func() time.Time { return now }}
+	addresses, err := d.Addresses(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host1:15999", "host2:15999"}, addresses)
+
+	// The vtgates whose registration stopped changing are skipped.
+	require.NoError(t, ts.NewVTGateEndpoints("zone1", 0).Save(ctx, &topo.VTGateEndpoint{Name: "vtgate1", Address: "host1:15999"}))
+	now = now.Add(2 * time.Minute)
+	addresses, err = d.Addresses(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host1:15999"}, addresses)
+
+	// The vtgates of the cells that can be read are returned.
+	d.Cells = []string{"zone1", "zone2", "unknown"}
+	addresses, err = d.Addresses(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"host1:15999", "host3:15999"}, addresses)
+	d.Cells = []string{"unknown"}
+	_, err = d.Addresses(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgateclient
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// hintedRetries counts the retries that waited for the retry-after hint of
+// the error of the previous attempt.
+var hintedRetries = stats.NewCountersWithSingleLabel("VtgateClientHintedRetries", "Calls retried after waiting for the retry-after hint of vtgate, per error code", "Code")
+
+// RetryPolicy decides how the calls that fail with a transient error are
+// retried. A call is only retried when its session is neither in a
+// transaction nor holds reserved connections, and preferably on another
+// vtgate. The statements that write are only retried with RetryWrites.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a call is made. Values
+	// lower than 2 disable the retries.
+	MaxAttempts int
+	// InitialBackoff is how long to wait before the first retry. The wait
+	// doubles on every retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts. When the error of an
+	// attempt has a retry-after hint, see vterrors.RetryAfter, the next
+	// attempt waits for at least the hinted duration, still up to MaxBackoff.
+	MaxBackoff time.Duration
+	// Retryable returns true if the error of a call is worth retrying.
+	// It defaults to IsTransient.
+	Retryable func(err error) bool
+	// RetryWrites also retries the statements that are not reads, e.g.
+	// INSERT, UPDATE or DDL. The error of such a statement doesn't tell
+	// whether it was executed: even a CLUSTER_EVENT may come from one of
+	// the shards after the others executed it. So the statement may run
+	// twice, which is only safe when it is idempotent. The writes that time
+	// out are never retried, since they may still be running.
+	RetryWrites bool
+}
+
+// DefaultRetryPolicy retries the transient errors of the reads twice.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Retryable:      IsTransient,
+}
+
+// IsTransient returns true for the errors of the calls that vtgate didn't
+// serve because either it or the tablets were unavailable, e.g. during a
+// restart or a reparent, and for the errors that vtgate hinted to retry.
+func IsTransient(err error) bool {
+	if _, ok := vterrors.RetryAfter(err); ok {
+		return true
+	}
+	switch vterrors.Code(err) {
+	case vtrpcpb.Code_UNAVAILABLE, vtrpcpb.Code_CLUSTER_EVENT:
+		return true
+	}
+	return false
+}
+
+// retryable returns true if the error of the given attempt is retried. write
+// is true if the call may have written.
+func (rp *RetryPolicy) retryable(attempt int, err error, write bool) bool {
+	if attempt >= rp.MaxAttempts || (write && !rp.RetryWrites) {
+		return false
+	}
+	if write && vterrors.Code(err) == vtrpcpb.Code_DEADLINE_EXCEEDED {
+		return false
+	}
+	if rp.Retryable == nil {
+		return IsTransient(err)
+	}
+	return rp.Retryable(err)
+}
+
+// wait waits before the next attempt after the given one, that failed with
+// err. It returns the error of the context if it is done first.
+func (rp *RetryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	backoff := rp.InitialBackoff
+	for i := 1; i < attempt && backoff < rp.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if rp.MaxBackoff > 0 {
+		backoff = min(backoff, rp.MaxBackoff)
+	}
+	if hint, ok := vterrors.RetryAfter(err); ok {
+		if rp.MaxBackoff > 0 {
+			hint = min(hint, rp.MaxBackoff)
+		}
+		backoff = max(backoff, hint)
+		hintedRetries.Add(vterrors.Code(err).String(), 1)
+	}
+	if backoff <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
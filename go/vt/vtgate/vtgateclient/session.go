@@ -0,0 +1,211 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgateclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// Session is a vtgate session, comparable to a MySQL connection: its
+// target, transaction, system variables and so on carry over from a call
+// to the next. The vtgate session state is kept on the client side, so
+// every call can go to any vtgate. The functions of a session must not be
+// called concurrently.
+type Session struct {
+	client  *Client
+	session *vtgatepb.Session
+}
+
+// SessionPb returns the underlying proto session.
+func (s *Session) SessionPb() *vtgatepb.Session {
+	return s.session
+}
+
+// InTransaction returns true if the session has an open transaction.
+func (s *Session) InTransaction() bool {
+	return s.session.GetInTransaction()
+}
+
+// Execute executes the query.
+func (s *Session) Execute(ctx context.Context, query string, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	var result *sqltypes.Result
+	err := s.do(ctx, !isRead(query), func(sn *vtgateconn.VTGateSession) (err error) {
+		result, err = sn.Execute(ctx, query, bindVars)
+		return err
+	})
+	return result, err
+}
+
+// ExecuteBatch executes the queries, within the current transaction if any.
+// The error is about the call itself, the results hold the errors of the
+// queries.
+func (s *Session) ExecuteBatch(ctx context.Context, queries []string, bindVars []map[string]*querypb.BindVariable) ([]sqltypes.QueryResponse, error) {
+	write := slices.ContainsFunc(queries, func(query string) bool {
+		return !isRead(query)
+	})
+	var results []sqltypes.QueryResponse
+	err := s.do(ctx, write, func(sn *vtgateconn.VTGateSession) (err error) {
+		results, err = sn.ExecuteBatch(ctx, queries, bindVars)
+		return err
+	})
+	return results, err
+}
+
+// Prepare returns the fields of the results of the query.
+func (s *Session) Prepare(ctx context.Context, query string, bindVars map[string]*querypb.BindVariable) ([]*querypb.Field, error) {
+	var fields []*querypb.Field
+	err := s.do(ctx, false /*write*/, func(sn *vtgateconn.VTGateSession) (err error) {
+		fields, err = sn.Prepare(ctx, query, bindVars)
+		return err
+	})
+	return fields, err
+}
+
+// StreamExecute executes the query and returns the stream of its results,
+// to read until io.EOF or another error. Only the failures to start the
+// stream are retried, see Stream to retry the streams that fail before
+// returning any result.
+func (s *Session) StreamExecute(ctx context.Context, query string, bindVars map[string]*querypb.BindVariable) (sqltypes.ResultStream, error) {
+	var stream sqltypes.ResultStream
+	err := s.do(ctx, !isRead(query), func(sn *vtgateconn.VTGateSession) error {
+		results, err := sn.StreamExecute(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		stream = &sessionStream{ResultStream: results, s: s, sn: sn}
+		return nil
+	})
+	return stream, err
+}
+
+// Stream executes the query and calls the callback with every result it
+// streams, the first one holding the fields. The query is retried if it
+// fails before any result was passed to the callback. An error of the
+// callback ends the stream, and is returned.
+func (s *Session) Stream(ctx context.Context, query string, bindVars map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
+	return s.do(ctx, !isRead(query), func(sn *vtgateconn.VTGateSession) error {
+		results, err := sn.StreamExecute(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		stream := &sessionStream{ResultStream: results, s: s, sn: sn}
+		for streamed := false; ; streamed = true {
+			result, err := stream.Recv()
+			switch {
+			case errors.Is(err, io.EOF):
+				return nil
+			case err != nil && streamed:
+				return permanent{err}
+			case err != nil:
+				return err
+			}
+			if err := callback(result); err != nil {
+				return permanent{err}
+			}
+		}
+	})
+}
+
+// Close closes the session on vtgate, rolling back its open transaction and
+// releasing its reserved connections. It is only needed for the sessions
+// that may hold some.
+func (s *Session) Close(ctx context.Context) error {
+	return s.do(ctx, false /*write*/, func(sn *vtgateconn.VTGateSession) error {
+		return sn.CloseSession(ctx)
+	})
+}
+
+// permanent wraps the errors that must not be retried.
+type permanent struct {
+	err error
+}
+
+func (p permanent) Error() string {
+	return p.err.Error()
+}
+
+// sessionStream keeps the session up to date with the session updates
+// streamed along the results.
+type sessionStream struct {
+	sqltypes.ResultStream
+	s  *Session
+	sn *vtgateconn.VTGateSession
+}
+
+// Recv is part of the sqltypes.ResultStream interface.
+func (ss *sessionStream) Recv() (*sqltypes.Result, error) {
+	result, err := ss.ResultStream.Recv()
+	ss.s.session = ss.sn.SessionPb()
+	return result, err
+}
+
+// do makes the call on a vtgate, and retries it on the other vtgates
+// according to the retry policy of the client. The calls of the sessions
+// that are in a transaction, or hold reserved connections, are not retried
+// since their state on the failed vtgate is unknown. Neither are the calls
+// that may write, unless the policy retries the writes.
+func (s *Session) do(ctx context.Context, write bool, call func(sn *vtgateconn.VTGateSession) error) error {
+	policy := &s.client.opts.Retry
+	var failed []string
+	for attempt := 1; ; attempt++ {
+		conn, address, err := s.client.pick(failed)
+		if err != nil {
+			return err
+		}
+		stateful := s.stateful()
+		sn := conn.SessionFromPb(s.session)
+		err = call(sn)
+		if sn.SessionPb() != nil {
+			s.session = sn.SessionPb()
+		}
+		if p, ok := err.(permanent); ok {
+			return p.err
+		}
+		if err == nil || stateful || s.stateful() || !policy.retryable(attempt, err, write) {
+			return err
+		}
+		failed = append(failed, address)
+		if werr := policy.wait(ctx, attempt, err); werr != nil {
+			return err
+		}
+	}
+}
+
+// isRead returns true if the query only reads, so that running it again
+// after an error is harmless.
+func isRead(query string) bool {
+	switch sqlparser.Preview(query) {
+	case sqlparser.StmtSelect, sqlparser.StmtShow, sqlparser.StmtExplain, sqlparser.StmtUse:
+		return true
+	}
+	return false
+}
+
+// stateful returns true if the session has some state on the tablets.
+func (s *Session) stateful() bool {
+	return s.session.GetInTransaction() || s.session.GetInReservedConn()
+}
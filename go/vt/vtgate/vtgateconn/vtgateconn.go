@@ -43,6 +43,7 @@ func registerFlags(fs *pflag.FlagSet) {
 func init() {
 	servenv.OnParseFor("vttablet", registerFlags)
 	servenv.OnParseFor("vtclient", registerFlags)
+	servenv.OnParseFor("vtreplay", registerFlags)
 }
 
 // GetVTGateProtocol returns the protocol used to connect to vtgate as provided in the flag.
@@ -111,6 +112,13 @@ func (conn *VTGateConn) VStream(ctx context.Context, tabletType topodatapb.Table
 	return conn.impl.VStream(ctx, tabletType, vgtid, filter, flags)
 }
 
+// GetVStreamCheckpoints returns the last positions of the named VStreams,
+// with the checkpoint tokens to resume them from. Only the checkpoint of
+// streamName is returned if set.
+func (conn *VTGateConn) GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error) {
+	return conn.impl.GetVStreamCheckpoints(ctx, streamName)
+}
+
 // VTGateSession exposes the Vitess Execution API to the clients.
 // The object maintains client-side state and is comparable to a native MySQL connection.
 // For example, if you enable autocommit on a Session object, all subsequent calls will respect this.
@@ -156,6 +164,18 @@ func (sn *VTGateSession) Prepare(ctx context.Context, query string, bindVars map
 	return fields, err
 }
 
+// GetPlan returns the JSON serialized plan of the query for the target of
+// the session, without executing it.
+func (sn *VTGateSession) GetPlan(ctx context.Context, query string, bindVars map[string]*querypb.BindVariable) ([]byte, error) {
+	return sn.impl.GetPlan(ctx, sn.session, query, bindVars)
+}
+
+// CloseSession closes the session, rolling back its open transaction
+// and releasing its reserved connections, if any.
+func (sn *VTGateSession) CloseSession(ctx context.Context) error {
+	return sn.impl.CloseSession(ctx, sn.session)
+}
+
 //
 // The rest of this file is for the protocol implementations.
 //
@@ -175,6 +195,9 @@ type Impl interface {
 	// Prepare returns the fields information for the query as part of supporting prepare statements.
 	Prepare(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) (*vtgatepb.Session, []*querypb.Field, error)
 
+	// GetPlan returns the JSON serialized plan of the query, without executing it.
+	GetPlan(ctx context.Context, session *vtgatepb.Session, sql string, bindVariables map[string]*querypb.BindVariable) ([]byte, error)
+
 	// CloseSession closes the session provided by rolling back any active transaction.
 	CloseSession(ctx context.Context, session *vtgatepb.Session) error
 
@@ -184,6 +207,9 @@ type Impl interface {
 	// VStream streams binlogevents
 	VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (VStreamReader, error)
 
+	// GetVStreamCheckpoints returns the last positions of the named VStreams.
+	GetVStreamCheckpoints(ctx context.Context, streamName string) ([]*vtgatepb.VStreamCheckpoint, error)
+
 	// Close must be called for releasing resources.
 	Close()
 }
@@ -42,6 +42,9 @@ pull out the data when needed for the requested time period.
 For current metrics two api urls have been provided: one provides
 the raw data and the other one provides a single set of aggregate
 data which is suitable for easy collection by monitoring systems.
+The discovery metrics can also be queried over a time range with
+Between(), filtered by instance or outcome, and exported as JSON or
+CSV for offline analysis.
 
 Expiry is triggered by default if the collection is created via
 CreateOrReturnCollection() and uses an expiry period of
@@ -61,6 +64,7 @@ package collection
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -208,6 +212,30 @@ func (c *Collection) Since(t time.Time) ([]Metric, error) {
 	return c.collection[first:last], nil
 }
 
+// Between returns the Metrics on or after from and before until. A zero
+// until means there is no upper bound. We assume the metrics are stored
+// in ascending time.
+func (c *Collection) Between(from, until time.Time) ([]Metric, error) {
+	if c == nil {
+		return nil, errors.New("Collection.Between: c == nil")
+	}
+	c.Lock()
+	defer c.Unlock()
+	first := sort.Search(len(c.collection), func(i int) bool {
+		return !c.collection[i].When().Before(from)
+	})
+	last := len(c.collection)
+	if !until.IsZero() {
+		last = sort.Search(len(c.collection), func(i int) bool {
+			return !c.collection[i].When().Before(until)
+		})
+	}
+	if first >= last {
+		return nil, nil // nothing to return
+	}
+	return c.collection[first:last], nil
+}
+
 // removeBefore is called by StartAutoExpiration and removes collection values
 // before the given time.
 func (c *Collection) removeBefore(t time.Time) error {
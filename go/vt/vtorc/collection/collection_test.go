@@ -157,6 +157,40 @@ func TestSince(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestBetween(t *testing.T) {
+	var c *Collection
+	metrics, err := c.Between(ts2, ts)
+	assert.Nil(t, metrics)
+	assert.EqualError(t, err, "Collection.Between: c == nil")
+
+	c = &Collection{}
+	metrics, err = c.Between(ts2, ts)
+	assert.Nil(t, metrics)
+	assert.NoError(t, err)
+
+	tm := &testMetric{}
+	tm2 := &testMetric2{}
+	_ = c.Append(tm2)
+	_ = c.Append(tm)
+
+	metrics, err = c.Between(ts2, time.Time{})
+	assert.Equal(t, []Metric{tm2, tm}, metrics)
+	assert.NoError(t, err)
+
+	// until is exclusive
+	metrics, err = c.Between(ts2, ts)
+	assert.Equal(t, []Metric{tm2}, metrics)
+	assert.NoError(t, err)
+
+	metrics, err = c.Between(ts2.Add(time.Second), ts.Add(time.Second))
+	assert.Equal(t, []Metric{tm}, metrics)
+	assert.NoError(t, err)
+
+	metrics, err = c.Between(ts.Add(time.Second), time.Time{})
+	assert.Nil(t, metrics)
+	assert.NoError(t, err)
+}
+
 func TestRemoveBefore(t *testing.T) {
 	oldNamedCollection := namedCollection
 	defer func() {
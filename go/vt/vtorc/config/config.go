@@ -29,6 +29,12 @@ import (
 
 var configurationLoaded = make(chan bool)
 
+// The formats of the audit log.
+const (
+	AuditFormatText = "text"
+	AuditFormatJSON = "json"
+)
+
 const (
 	HealthPollSeconds                     = 1
 	AuditPageSize                         = 20
@@ -50,6 +56,9 @@ var (
 	auditToBackend                 = false
 	auditToSyslog                  = false
 	auditPurgeDuration             = 7 * 24 * time.Hour // Equivalent of 7 days
+	auditFormat                    = AuditFormatText
+	auditHTTPURL                   = ""
+	auditRateLimit                 = 0
 	recoveryPeriodBlockDuration    = 30 * time.Second
 	preventCrossCellFailover       = false
 	waitReplicasTimeout            = 30 * time.Second
@@ -58,6 +67,15 @@ var (
 	recoveryPollDuration           = 1 * time.Second
 	ersEnabled                     = true
 	convertTabletsWithErrantGTIDs  = false
+	reconcileExternalReparents     = false
+
+	discoveryBackendLatencyThreshold = 0 * time.Second
+	discoveryMinConcurrency          = 10
+	discoveryClusterRateLimit        = 0.0
+
+	plannedOperationsGracePeriod = 0 * time.Second
+
+	allowRecoverySimulation = false
 )
 
 // RegisterFlags registers the flags required by VTOrc
@@ -69,6 +87,9 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&auditFileLocation, "audit-file-location", auditFileLocation, "File location where the audit logs are to be stored")
 	fs.BoolVar(&auditToBackend, "audit-to-backend", auditToBackend, "Whether to store the audit log in the VTOrc database")
 	fs.BoolVar(&auditToSyslog, "audit-to-syslog", auditToSyslog, "Whether to store the audit log in the syslog")
+	fs.StringVar(&auditFormat, "audit-format", auditFormat, "Format of the audit log written to the file and syslog: text or json")
+	fs.StringVar(&auditHTTPURL, "audit-http-url", auditHTTPURL, "URL to which audit events are posted as JSON. Disabled when empty")
+	fs.IntVar(&auditRateLimit, "audit-rate-limit", auditRateLimit, "Maximum number of audit events written per second; events above the limit are dropped. 0 means unlimited")
 	fs.DurationVar(&auditPurgeDuration, "audit-purge-duration", auditPurgeDuration, "Duration for which audit logs are held before being purged. Should be in multiples of days")
 	fs.DurationVar(&recoveryPeriodBlockDuration, "recovery-period-block-duration", recoveryPeriodBlockDuration, "Duration for which a new recovery is blocked on an instance after running a recovery")
 	fs.MarkDeprecated("recovery-period-block-duration", "As of v20 this is ignored and will be removed in a future release.")
@@ -79,6 +100,12 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&recoveryPollDuration, "recovery-poll-duration", recoveryPollDuration, "Timer duration on which VTOrc polls its database to run a recovery")
 	fs.BoolVar(&ersEnabled, "allow-emergency-reparent", ersEnabled, "Whether VTOrc should be allowed to run emergency reparent operation when it detects a dead primary")
 	fs.BoolVar(&convertTabletsWithErrantGTIDs, "change-tablets-with-errant-gtid-to-drained", convertTabletsWithErrantGTIDs, "Whether VTOrc should be changing the type of tablets with errant GTIDs to DRAINED")
+	fs.BoolVar(&reconcileExternalReparents, "reconcile-external-reparents", reconcileExternalReparents, "Whether VTOrc should update the topology when it detects a primary that was promoted outside of Vitess, like TabletExternallyReparented does")
+	fs.DurationVar(&discoveryBackendLatencyThreshold, "discovery-backend-latency-threshold", discoveryBackendLatencyThreshold, "Average backend latency of the instance discoveries above which VTOrc reduces its discovery concurrency, and restores it once the latency is back below half of it. 0 disables the throttling")
+	fs.IntVar(&discoveryMinConcurrency, "discovery-min-concurrency", discoveryMinConcurrency, "Minimum number of instance discoveries VTOrc runs concurrently when throttled by --discovery-backend-latency-threshold")
+	fs.Float64Var(&discoveryClusterRateLimit, "discovery-cluster-rate-limit", discoveryClusterRateLimit, "Maximum number of instance discoveries per second of the tablets of a single shard, so that the discovery of a large keyspace can't starve the other shards. 0 means unlimited")
+	fs.BoolVar(&allowRecoverySimulation, "allow-recovery-simulation", allowRecoverySimulation, "Whether VTOrc exposes the API that simulates replication analyses, to run recovery drills without breaking MySQL. The simulated recoveries act on the cluster, so this is only meant for test and staging environments")
+	fs.DurationVar(&plannedOperationsGracePeriod, "planned-operations-grace-period", plannedOperationsGracePeriod, "Duration for which VTOrc defers the recovery of a dead primary during a reparent of its shard, or after a PlannedReparentShard or an online DDL cut-over on its shard. 0 disables the deferral")
 }
 
 // Configuration makes for vtorc configuration input, which can be provided by user via JSON formatted file.
@@ -94,12 +121,41 @@ type Configuration struct {
 	AuditToSyslog                         bool   // If true, audit messages are written to syslog
 	AuditToBackendDB                      bool   // If true, audit messages are written to the backend DB's `audit` table (default: true)
 	AuditPurgeDays                        uint   // Days after which audit entries are purged from the database
+	AuditFormat                           string // Format of the audit log written to the file and syslog: text or json
+	AuditHTTPURL                          string // URL to which audit events are posted as JSON. Disabled when empty.
+	AuditRateLimit                        int    // Maximum number of audit events written per second. 0 means unlimited.
 	RecoveryPeriodBlockSeconds            int    // (overrides `RecoveryPeriodBlockMinutes`) The time for which an instance's recovery is kept "active", so as to avoid concurrent recoveries on smae instance as well as flapping
 	PreventCrossDataCenterPrimaryFailover bool   // When true (default: false), cross-DC primary failover are not allowed, vtorc will do all it can to only fail over within same DC, or else not fail over at all.
 	WaitReplicasTimeoutSeconds            int    // Timeout on amount of time to wait for the replicas in case of ERS. Should be a small value because we should fail-fast. Should not be larger than LockTimeout since that is the total time we use for an ERS.
 	TolerableReplicationLagSeconds        int    // Amount of replication lag that is considered acceptable for a tablet to be eligible for promotion when Vitess makes the choice of a new primary in PRS.
 	TopoInformationRefreshSeconds         int    // Timer duration on which VTOrc refreshes the keyspace and vttablet records from the topo-server.
 	RecoveryPollSeconds                   int    // Timer duration on which VTOrc recovery analysis runs
+
+	// Runbooks of the analyses, keyed by analysis code (e.g. DeadPrimary). They are included
+	// in the API responses reporting these analyses.
+	Runbooks map[string]Runbook
+
+	// Hooks run on the recoveries. They receive the details of the recovery as JSON on their
+	// stdin. The pre recovery hooks run while the shard is locked, so they delay the recovery
+	// for up to their timeout on every attempt.
+	OnFailureDetectionHooks []RecoveryHook // Run in the background when VTOrc is about to recover from a failure
+	PreRecoveryHooks        []RecoveryHook // Run right before the recovery; a failing synchronous hook aborts it
+	PostRecoveryHooks       []RecoveryHook // Run once the recovery is done and the shard unlocked, whether it succeeded or not
+}
+
+// Runbook is the remediation context of an analysis, for the on-call engineers handling it.
+// At least one of URL and Content must be set.
+type Runbook struct {
+	URL     string `json:",omitempty"` // Link to the runbook
+	Content string `json:",omitempty"` // The runbook itself, in markdown
+}
+
+// RecoveryHook is a command run on the recoveries.
+type RecoveryHook struct {
+	Command        string // Shell command, run with bash -c as it is
+	TimeoutSeconds int    `json:",omitempty"` // Time after which the command is killed. 0 means 30 seconds
+	Retries        int    `json:",omitempty"` // Number of times the command is retried when it fails
+	Async          bool   `json:",omitempty"` // Whether the command runs in the background, without waiting for it
 }
 
 // ToJSONString will marshal this configuration as JSON
@@ -124,6 +180,9 @@ func UpdateConfigValuesFromFlags() {
 	Config.AuditToBackendDB = auditToBackend
 	Config.AuditToSyslog = auditToSyslog
 	Config.AuditPurgeDays = uint(auditPurgeDuration / (time.Hour * 24))
+	Config.AuditFormat = auditFormat
+	Config.AuditHTTPURL = auditHTTPURL
+	Config.AuditRateLimit = auditRateLimit
 	Config.RecoveryPeriodBlockSeconds = int(recoveryPeriodBlockDuration / time.Second)
 	Config.PreventCrossDataCenterPrimaryFailover = preventCrossCellFailover
 	Config.WaitReplicasTimeoutSeconds = int(waitReplicasTimeout / time.Second)
@@ -152,6 +211,73 @@ func SetConvertTabletWithErrantGTIDs(val bool) {
 	convertTabletsWithErrantGTIDs = val
 }
 
+// ReconcileExternalReparents reports whether VTOrc is allowed to update the topology to match a primary promoted outside of Vitess.
+func ReconcileExternalReparents() bool {
+	return reconcileExternalReparents
+}
+
+// SetReconcileExternalReparents sets the value for the reconcileExternalReparents variable. This should only be used from tests.
+func SetReconcileExternalReparents(val bool) {
+	reconcileExternalReparents = val
+}
+
+// DiscoveryBackendLatencyThreshold returns the average backend latency of the instance discoveries
+// above which VTOrc reduces its discovery concurrency. 0 means the concurrency is never reduced.
+func DiscoveryBackendLatencyThreshold() time.Duration {
+	return discoveryBackendLatencyThreshold
+}
+
+// SetDiscoveryBackendLatencyThreshold sets the value for the discoveryBackendLatencyThreshold variable. This should only be used from tests.
+func SetDiscoveryBackendLatencyThreshold(val time.Duration) {
+	discoveryBackendLatencyThreshold = val
+}
+
+// DiscoveryMinConcurrency returns the number of instance discoveries VTOrc runs concurrently when throttled.
+func DiscoveryMinConcurrency() int {
+	return discoveryMinConcurrency
+}
+
+// DiscoveryClusterRateLimit returns the maximum number of instance discoveries per second of a single shard.
+// 0 means the discoveries are not rate limited.
+func DiscoveryClusterRateLimit() float64 {
+	return discoveryClusterRateLimit
+}
+
+// SetDiscoveryClusterRateLimit sets the value for the discoveryClusterRateLimit variable. This should only be used from tests.
+func SetDiscoveryClusterRateLimit(val float64) {
+	discoveryClusterRateLimit = val
+}
+
+// PlannedOperationsGracePeriod returns the duration for which VTOrc defers the recovery of a dead primary after
+// a planned operation on its shard. 0 means the recoveries are never deferred.
+func PlannedOperationsGracePeriod() time.Duration {
+	return plannedOperationsGracePeriod
+}
+
+// SetPlannedOperationsGracePeriod sets the value for the plannedOperationsGracePeriod variable. This should only be used from tests.
+func SetPlannedOperationsGracePeriod(val time.Duration) {
+	plannedOperationsGracePeriod = val
+}
+
+// AllowRecoverySimulation reports whether VTOrc allows the simulation of replication analyses.
+func AllowRecoverySimulation() bool {
+	return allowRecoverySimulation
+}
+
+// SetAllowRecoverySimulation sets the value for the allowRecoverySimulation variable. This should only be used from tests.
+func SetAllowRecoverySimulation(val bool) {
+	allowRecoverySimulation = val
+}
+
+// RunbookFor returns the runbook configured for the given analysis code, or nil if there is none.
+func RunbookFor(analysis string) *Runbook {
+	runbook, ok := Config.Runbooks[analysis]
+	if !ok {
+		return nil
+	}
+	return &runbook
+}
+
 // LogConfigValues is used to log the config values.
 func LogConfigValues() {
 	b, _ := json.MarshalIndent(Config, "", "\t")
@@ -168,6 +294,7 @@ func newConfiguration() *Configuration {
 		AuditToSyslog:                         false,
 		AuditToBackendDB:                      false,
 		AuditPurgeDays:                        7,
+		AuditFormat:                           AuditFormatText,
 		RecoveryPeriodBlockSeconds:            30,
 		PreventCrossDataCenterPrimaryFailover: false,
 		WaitReplicasTimeoutSeconds:            30,
@@ -180,6 +307,34 @@ func (config *Configuration) postReadAdjustments() error {
 	if config.SQLite3DataFile == "" {
 		return fmt.Errorf("SQLite3DataFile must be set")
 	}
+	switch config.AuditFormat {
+	case AuditFormatText, AuditFormatJSON:
+	default:
+		return fmt.Errorf("AuditFormat must be %s or %s, got %q", AuditFormatText, AuditFormatJSON, config.AuditFormat)
+	}
+	for analysis, runbook := range config.Runbooks {
+		if runbook.URL == "" && runbook.Content == "" {
+			return fmt.Errorf("Runbook of %s must have a URL or a Content", analysis)
+		}
+	}
+	for _, hooks := range []struct {
+		name  string
+		hooks []RecoveryHook
+	}{
+		{"OnFailureDetectionHooks", config.OnFailureDetectionHooks},
+		{"PreRecoveryHooks", config.PreRecoveryHooks},
+		{"PostRecoveryHooks", config.PostRecoveryHooks},
+	} {
+		name := hooks.name
+		for i, hook := range hooks.hooks {
+			if hook.Command == "" {
+				return fmt.Errorf("%s[%d] must have a Command", name, i)
+			}
+			if hook.TimeoutSeconds < 0 || hook.Retries < 0 {
+				return fmt.Errorf("%s[%d] must not have a negative TimeoutSeconds or Retries", name, i)
+			}
+		}
+	}
 
 	return nil
 }
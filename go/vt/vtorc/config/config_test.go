@@ -17,6 +17,8 @@ limitations under the License.
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -232,3 +234,58 @@ func TestUpdateConfigValuesFromFlags(t *testing.T) {
 		require.Equal(t, testConfig, Config)
 	})
 }
+
+func TestRunbooks(t *testing.T) {
+	defer func() {
+		Config = newConfiguration()
+	}()
+	require.Nil(t, RunbookFor("DeadPrimary"))
+
+	configFile := filepath.Join(t.TempDir(), "vtorc.conf.json")
+	err := os.WriteFile(configFile, []byte(`{
+		"Runbooks": {
+			"DeadPrimary": {"URL": "https://runbooks.example.com/dead-primary"},
+			"ErrantGTIDDetected": {"URL": "https://runbooks.example.com/errant-gtid", "Content": "# Errant GTID\nCompare the executed GTID sets."}
+		}
+	}`), 0o644)
+	require.NoError(t, err)
+	_, err = read(configFile)
+	require.NoError(t, err)
+
+	require.Equal(t, &Runbook{URL: "https://runbooks.example.com/dead-primary"}, RunbookFor("DeadPrimary"))
+	require.Equal(t, &Runbook{
+		URL:     "https://runbooks.example.com/errant-gtid",
+		Content: "# Errant GTID\nCompare the executed GTID sets.",
+	}, RunbookFor("ErrantGTIDDetected"))
+	require.Nil(t, RunbookFor("UnreachablePrimary"))
+
+	// A runbook must have a URL or a content.
+	Config.Runbooks["UnreachablePrimary"] = Runbook{}
+	require.EqualError(t, Config.postReadAdjustments(), "Runbook of UnreachablePrimary must have a URL or a Content")
+}
+
+func TestRecoveryHooks(t *testing.T) {
+	defer func() {
+		Config = newConfiguration()
+	}()
+
+	configFile := filepath.Join(t.TempDir(), "vtorc.conf.json")
+	err := os.WriteFile(configFile, []byte(`{
+		"PreRecoveryHooks": [{"Command": "notify-oncall", "TimeoutSeconds": 10, "Retries": 2}],
+		"PostRecoveryHooks": [{"Command": "update-dashboard", "Async": true}]
+	}`), 0o644)
+	require.NoError(t, err)
+	_, err = read(configFile)
+	require.NoError(t, err)
+
+	require.Empty(t, Config.OnFailureDetectionHooks)
+	require.Equal(t, []RecoveryHook{{Command: "notify-oncall", TimeoutSeconds: 10, Retries: 2}}, Config.PreRecoveryHooks)
+	require.Equal(t, []RecoveryHook{{Command: "update-dashboard", Async: true}}, Config.PostRecoveryHooks)
+
+	// A hook must have a command, and no negative timeout or retries.
+	Config.OnFailureDetectionHooks = []RecoveryHook{{}}
+	require.EqualError(t, Config.postReadAdjustments(), "OnFailureDetectionHooks[0] must have a Command")
+	Config.OnFailureDetectionHooks = nil
+	Config.PostRecoveryHooks[0].Retries = -1
+	require.EqualError(t, Config.postReadAdjustments(), "PostRecoveryHooks[0] must not have a negative TimeoutSeconds or Retries")
+}
@@ -292,6 +292,8 @@ CREATE TABLE vitess_keyspace (
 	keyspace varchar(128) NOT NULL,
 	keyspace_type smallint(5) NOT NULL,
 	durability_policy varchar(512) NOT NULL,
+	disable_discovery tinyint NOT NULL DEFAULT 0,
+	disable_recovery tinyint NOT NULL DEFAULT 0,
 	PRIMARY KEY (keyspace)
 )`,
 	`
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"vitess.io/vitess/go/vt/vtorc/collection"
+)
+
+// MetricStatus filters the discovery metrics by the outcome of the discovery.
+type MetricStatus string
+
+const (
+	// MetricStatusAny selects all the discoveries.
+	MetricStatusAny MetricStatus = ""
+	// MetricStatusSuccess selects the successful discoveries.
+	MetricStatusSuccess MetricStatus = "success"
+	// MetricStatusFailure selects the failed discoveries.
+	MetricStatusFailure MetricStatus = "failure"
+)
+
+// ParseMetricStatus parses the status of a MetricsQuery.
+func ParseMetricStatus(status string) (MetricStatus, error) {
+	switch s := MetricStatus(status); s {
+	case MetricStatusAny, MetricStatusSuccess, MetricStatusFailure:
+		return s, nil
+	}
+	return MetricStatusAny, fmt.Errorf("invalid discovery status %q, expected %q or %q", status, MetricStatusSuccess, MetricStatusFailure)
+}
+
+// MetricsQuery selects the discovery metrics to export.
+type MetricsQuery struct {
+	Since       time.Time    // metrics taken on or after this time
+	Until       time.Time    // metrics taken before this time, no bound if zero
+	TabletAlias string       // metrics of this instance only, if set
+	Status      MetricStatus // metrics of the discoveries with this outcome only, if set
+}
+
+// MetricRecord is the exported form of a discovery Metric.
+type MetricRecord struct {
+	Timestamp                   time.Time
+	TabletAlias                 string
+	BackendLatencySeconds       float64
+	InstanceLatencySeconds      float64
+	TotalLatencySeconds         float64
+	Error                       string
+	InstancePollSecondsExceeded uint64
+}
+
+// matches returns whether the metric is selected by the query.
+func (q MetricsQuery) matches(m *Metric) bool {
+	if q.TabletAlias != "" && m.TabletAlias != q.TabletAlias {
+		return false
+	}
+	switch q.Status {
+	case MetricStatusSuccess:
+		return m.Err == nil
+	case MetricStatusFailure:
+		return m.Err != nil
+	}
+	return true
+}
+
+// filter returns the metrics of the collection selected by the query.
+func (q MetricsQuery) filter(c *collection.Collection) ([]collection.Metric, error) {
+	results, err := c.Between(q.Since, q.Until)
+	if err != nil {
+		return nil, err
+	}
+	var selected []collection.Metric
+	for _, result := range results {
+		if q.matches(result.(*Metric)) {
+			selected = append(selected, result)
+		}
+	}
+	return selected, nil
+}
+
+// QueryMetrics returns the raw discovery metrics of the collection selected by the query.
+func QueryMetrics(c *collection.Collection, q MetricsQuery) ([]MetricRecord, error) {
+	results, err := q.filter(c)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]MetricRecord, 0, len(results))
+	for _, result := range results {
+		m := result.(*Metric)
+		record := MetricRecord{
+			Timestamp:                   m.Timestamp,
+			TabletAlias:                 m.TabletAlias,
+			BackendLatencySeconds:       m.BackendLatency.Seconds(),
+			InstanceLatencySeconds:      m.InstanceLatency.Seconds(),
+			TotalLatencySeconds:         m.TotalLatency.Seconds(),
+			InstancePollSecondsExceeded: m.InstancePollSecondsDurationCount,
+		}
+		if m.Err != nil {
+			record.Error = m.Err.Error()
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// QueryAggregated returns the aggregated discovery metrics of the collection selected by the query.
+func QueryAggregated(c *collection.Collection, q MetricsQuery) (AggregatedDiscoveryMetrics, error) {
+	results, err := q.filter(c)
+	if err != nil {
+		return AggregatedDiscoveryMetrics{}, err
+	}
+	return aggregate(results), nil
+}
+
+// WriteMetricsCSV writes the raw discovery metrics as CSV, with a header row.
+func WriteMetricsCSV(w io.Writer, records []MetricRecord) error {
+	rows := make([]any, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, record)
+	}
+	return writeCSV(w, reflect.TypeOf(MetricRecord{}), rows)
+}
+
+// WriteAggregatedCSV writes the aggregated discovery metrics as CSV, with a header row.
+func WriteAggregatedCSV(w io.Writer, metrics AggregatedDiscoveryMetrics) error {
+	return writeCSV(w, reflect.TypeOf(metrics), []any{metrics})
+}
+
+// writeCSV writes the rows, which are structs of type t, with a column per field.
+func writeCSV(w io.Writer, t reflect.Type, rows []any) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, t.NumField())
+	for i := range record {
+		record[i] = t.Field(i).Name
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		v := reflect.ValueOf(row)
+		for i := range record {
+			record[i] = csvValue(v.Field(i))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvValue formats a field of a row of the CSV export.
+func csvValue(v reflect.Value) string {
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	switch v.Kind() {
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		return v.String()
+	}
+}
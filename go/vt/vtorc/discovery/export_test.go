@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtorc/collection"
+)
+
+func newTestCollection(t *testing.T, start time.Time) *collection.Collection {
+	c := &collection.Collection{}
+	for i, alias := range []string{"zone1-100", "zone1-101", "zone1-100", "zone1-101"} {
+		m := &Metric{
+			Timestamp:      start.Add(time.Duration(i) * time.Second),
+			TabletAlias:    alias,
+			BackendLatency: 10 * time.Millisecond,
+			TotalLatency:   time.Duration(i+1) * 100 * time.Millisecond,
+		}
+		if i == 3 {
+			m.Err = errors.New("connection refused")
+		}
+		require.NoError(t, c.Append(m))
+	}
+	return c
+}
+
+func TestQueryMetrics(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	c := newTestCollection(t, start)
+
+	tests := []struct {
+		name  string
+		query MetricsQuery
+		want  []string
+	}{{
+		name:  "all",
+		query: MetricsQuery{Since: start},
+		want:  []string{"zone1-100", "zone1-101", "zone1-100", "zone1-101"},
+	}, {
+		name:  "time range",
+		query: MetricsQuery{Since: start.Add(time.Second), Until: start.Add(3 * time.Second)},
+		want:  []string{"zone1-101", "zone1-100"},
+	}, {
+		name:  "instance",
+		query: MetricsQuery{Since: start, TabletAlias: "zone1-101"},
+		want:  []string{"zone1-101", "zone1-101"},
+	}, {
+		name:  "failures",
+		query: MetricsQuery{Since: start, Status: MetricStatusFailure},
+		want:  []string{"zone1-101"},
+	}, {
+		name:  "successes of an instance",
+		query: MetricsQuery{Since: start, TabletAlias: "zone1-101", Status: MetricStatusSuccess},
+		want:  []string{"zone1-101"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := QueryMetrics(c, tt.query)
+			require.NoError(t, err)
+			var aliases []string
+			for _, record := range records {
+				aliases = append(aliases, record.TabletAlias)
+			}
+			assert.Equal(t, tt.want, aliases)
+		})
+	}
+
+	records, err := QueryMetrics(c, MetricsQuery{Since: start, Status: MetricStatusFailure})
+	require.NoError(t, err)
+	assert.Equal(t, []MetricRecord{{
+		Timestamp:             start.Add(3 * time.Second),
+		TabletAlias:           "zone1-101",
+		BackendLatencySeconds: 0.01,
+		TotalLatencySeconds:   0.4,
+		Error:                 "connection refused",
+	}}, records)
+
+	aggregated, err := QueryAggregated(c, MetricsQuery{Since: start, TabletAlias: "zone1-100"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, aggregated.CountDistinctInstanceKeys)
+	assert.EqualValues(t, 0, aggregated.FailedDiscoveries)
+	assert.InDelta(t, 0.3, aggregated.MaxTotalSeconds, 1e-9)
+}
+
+func TestParseMetricStatus(t *testing.T) {
+	status, err := ParseMetricStatus("failure")
+	require.NoError(t, err)
+	assert.Equal(t, MetricStatusFailure, status)
+
+	status, err = ParseMetricStatus("")
+	require.NoError(t, err)
+	assert.Equal(t, MetricStatusAny, status)
+
+	_, err = ParseMetricStatus("unknown")
+	assert.EqualError(t, err, `invalid discovery status "unknown", expected "success" or "failure"`)
+}
+
+func TestWriteMetricsCSV(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	records, err := QueryMetrics(newTestCollection(t, start), MetricsQuery{Since: start.Add(2 * time.Second)})
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, WriteMetricsCSV(buf, records))
+	assert.Equal(t, `Timestamp,TabletAlias,BackendLatencySeconds,InstanceLatencySeconds,TotalLatencySeconds,Error,InstancePollSecondsExceeded
+2024-05-01T10:00:02Z,zone1-100,0.01,0,0.3,,0
+2024-05-01T10:00:03Z,zone1-101,0.01,0,0.4,connection refused,0
+`, buf.String())
+}
+
+func TestWriteAggregatedCSV(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	aggregated, err := QueryAggregated(newTestCollection(t, start), MetricsQuery{Since: start})
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, WriteAggregatedCSV(buf, aggregated))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "FirstSeen,LastSeen,CountDistinctInstanceKeys,"), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "2024-05-01T10:00:00Z,2024-05-01T10:00:03Z,2,2,1,1,"), lines[1])
+}
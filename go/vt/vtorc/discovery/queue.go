@@ -16,38 +16,80 @@
 
 /*
 
-package discovery manages a queue of discovery requests: an ordered
+package discovery manages a queue of discovery requests: a prioritized
 queue with no duplicates.
 
-push() operation never blocks while pop() blocks on an empty queue.
+push() operation blocks on a full queue while pop() blocks on an empty queue.
+The keys of a higher priority are popped first. Keys of the same priority are
+popped in order within a cluster, and in turn across the clusters, which can
+be rate limited so that a single large cluster can't starve the others.
 
 */
 
 package discovery
 
 import (
+	"context"
+	"math"
 	"sync"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/vtorc/config"
 )
 
+// Priority is the priority of a discovery request. The requests of a higher
+// priority are consumed first.
+type Priority int
+
+const (
+	// PriorityNormal is the priority of the periodic discoveries.
+	PriorityNormal Priority = iota
+	// PriorityPrimary is the priority of the discoveries of the primaries.
+	PriorityPrimary
+	// PriorityRecovery is the priority of the discoveries of the instances
+	// of the clusters with a recovery in progress.
+	PriorityRecovery
+
+	numPriorities
+)
+
 // QueueMetric contains the queue's active and queued sizes
 type QueueMetric struct {
 	Active int
 	Queued int
 }
 
+// queuedKey is a key waiting in the queue.
+type queuedKey struct {
+	key      string
+	cluster  string
+	priority Priority
+	queuedAt time.Time
+}
+
+// priorityLevel holds the keys of a priority, in order within each cluster.
+type priorityLevel struct {
+	clusters map[string][]*queuedKey
+	// order is the order in which the clusters with queued keys take turns.
+	order []string
+}
+
 // Queue contains information for managing discovery requests
 type Queue struct {
 	sync.Mutex
 
 	name         string
 	done         chan struct{}
-	queue        chan string
-	queuedKeys   map[string]time.Time
+	capacity     int
+	notFull      *sync.Cond
+	notify       chan struct{}
+	levels       [numPriorities]priorityLevel
+	queuedKeys   map[string]*queuedKey
 	consumedKeys map[string]time.Time
+	limiters     map[string]*rate.Limiter
 	metrics      []QueueMetric
 }
 
@@ -70,12 +112,7 @@ func CreateOrReturnQueue(name string) *Queue {
 		return q
 	}
 
-	q := &Queue{
-		name:         name,
-		queuedKeys:   make(map[string]time.Time),
-		consumedKeys: make(map[string]time.Time),
-		queue:        make(chan string, config.DiscoveryQueueCapacity),
-	}
+	q := newQueue(name, config.DiscoveryQueueCapacity)
 	go q.startMonitoring()
 
 	discoveryQueue[name] = q
@@ -83,6 +120,22 @@ func CreateOrReturnQueue(name string) *Queue {
 	return q
 }
 
+func newQueue(name string, capacity int) *Queue {
+	q := &Queue{
+		name:         name,
+		capacity:     capacity,
+		notify:       make(chan struct{}, 1),
+		queuedKeys:   make(map[string]*queuedKey),
+		consumedKeys: make(map[string]time.Time),
+		limiters:     make(map[string]*rate.Limiter),
+	}
+	q.notFull = sync.NewCond(&q.Mutex)
+	for i := range q.levels {
+		q.levels[i].clusters = make(map[string][]*queuedKey)
+	}
+	return q
+}
+
 // monitoring queue sizes until we are told to stop
 func (q *Queue) startMonitoring() {
 	log.Infof("Queue.startMonitoring(%s)", q.name)
@@ -111,57 +164,194 @@ func (q *Queue) collectStatistics() {
 	}
 }
 
-// QueueLen returns the length of the queue (channel size + queued size)
+// QueueLen returns the number of the queued keys
 func (q *Queue) QueueLen() int {
 	q.Lock()
 	defer q.Unlock()
 
-	return len(q.queue) + len(q.queuedKeys)
+	return len(q.queuedKeys)
 }
 
-// Push enqueues a key if it is not on a queue and is not being
-// processed; silently returns otherwise.
+// Push enqueues a key with the normal priority, outside of any cluster.
 func (q *Queue) Push(key string) {
+	q.PushWithPriority(key, "", PriorityNormal)
+}
+
+// PushWithPriority enqueues a key of the given cluster if it is not on a
+// queue and is not being processed; silently returns otherwise. A key that
+// is already queued with a lower priority is moved up to the given priority.
+// It blocks while the queue is full.
+func (q *Queue) PushWithPriority(key string, cluster string, priority Priority) {
 	q.Lock()
 	defer q.Unlock()
 
-	// is it enqueued already?
-	if _, found := q.queuedKeys[key]; found {
-		return
-	}
-
 	// is it being processed now?
 	if _, found := q.consumedKeys[key]; found {
 		return
 	}
 
-	q.queuedKeys[key] = time.Now()
-	q.queue <- key
+	queuedAt := time.Now()
+	// is it enqueued already?
+	if queued, found := q.queuedKeys[key]; found {
+		if queued.priority >= priority {
+			return
+		}
+		// The key stays in its former level, where it is skipped once it is
+		// no longer the one queued.
+		queuedAt = queued.queuedAt
+	} else {
+		for len(q.queuedKeys) >= q.capacity {
+			q.notFull.Wait()
+		}
+		// The key may have been pushed while waiting.
+		if _, found := q.queuedKeys[key]; found {
+			return
+		}
+		if _, found := q.consumedKeys[key]; found {
+			return
+		}
+	}
+
+	queued := &queuedKey{key: key, cluster: cluster, priority: priority, queuedAt: queuedAt}
+	q.queuedKeys[key] = queued
+	level := &q.levels[priority]
+	if _, found := level.clusters[cluster]; !found {
+		level.order = append(level.order, cluster)
+	}
+	level.clusters[cluster] = append(level.clusters[cluster], queued)
+	q.signal()
+}
+
+// signal wakes up a consumer waiting for a key.
+func (q *Queue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
 }
 
 // Consume fetches a key to process; blocks if queue is empty.
 // Release must be called once after Consume.
 func (q *Queue) Consume() string {
-	q.Lock()
-	queue := q.queue
-	q.Unlock()
-
-	key := <-queue
-
-	q.Lock()
-	defer q.Unlock()
+	key, _ := q.ConsumeContext(context.Background())
+	return key
+}
 
-	// alarm if have been waiting for too long
-	timeOnQueue := time.Since(q.queuedKeys[key])
-	if timeOnQueue > time.Duration(config.Config.InstancePollSeconds)*time.Second {
-		log.Warningf("key %v spent %.4fs waiting on a discoveryQueue", key, timeOnQueue.Seconds())
+// ConsumeContext is like Consume, but returns the error of the context
+// if it is done before a key is queued. Release must not be called then.
+func (q *Queue) ConsumeContext(ctx context.Context) (string, error) {
+	for {
+		q.Lock()
+		queued, wait := q.pop(time.Now())
+		if queued != nil {
+			key := queued.key
+			// alarm if have been waiting for too long
+			timeOnQueue := time.Since(queued.queuedAt)
+			if timeOnQueue > time.Duration(config.Config.InstancePollSeconds)*time.Second {
+				log.Warningf("key %v spent %.4fs waiting on a discoveryQueue", key, timeOnQueue.Seconds())
+			}
+
+			q.consumedKeys[key] = queued.queuedAt
+			delete(q.queuedKeys, key)
+			q.notFull.Signal()
+			// Let another consumer pick the remaining keys.
+			if len(q.queuedKeys) > 0 {
+				q.signal()
+			}
+			q.Unlock()
+			return key, nil
+		}
+		q.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			// The queued keys are all rate limited.
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-q.notify:
+		case <-timeout:
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return "", ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
 	}
+}
 
-	q.consumedKeys[key] = q.queuedKeys[key]
-
-	delete(q.queuedKeys, key)
+// pop removes the next key to process from the queue, taking the clusters
+// in turn within the highest priority. When the queued keys are all rate
+// limited, it returns nil and how long to wait for one of them.
+func (q *Queue) pop(now time.Time) (*queuedKey, time.Duration) {
+	var wait time.Duration
+	for priority := numPriorities - 1; priority >= 0; priority-- {
+		level := &q.levels[priority]
+		for i := 0; i < len(level.order); {
+			cluster := level.order[i]
+			keys := level.clusters[cluster]
+			// Skip the keys that were moved to another priority.
+			for len(keys) > 0 && q.queuedKeys[keys[0].key] != keys[0] {
+				keys = keys[1:]
+			}
+			if len(keys) == 0 {
+				delete(level.clusters, cluster)
+				level.order = append(level.order[:i], level.order[i+1:]...)
+				continue
+			}
+			level.clusters[cluster] = keys
+			if delay := q.reserve(cluster, now); delay > 0 {
+				if wait == 0 || delay < wait {
+					wait = delay
+				}
+				i++
+				continue
+			}
+
+			queued := keys[0]
+			keys = keys[1:]
+			// The cluster takes its turn after the other ones.
+			level.order = append(level.order[:i], level.order[i+1:]...)
+			if len(keys) == 0 {
+				delete(level.clusters, cluster)
+			} else {
+				level.clusters[cluster] = keys
+				level.order = append(level.order, cluster)
+			}
+			return queued, 0
+		}
+	}
+	return nil, wait
+}
 
-	return key
+// reserve takes a discovery from the rate limit of the cluster, and returns
+// how long to wait before the cluster can be discovered again if there is
+// none left.
+func (q *Queue) reserve(cluster string, now time.Time) time.Duration {
+	limit := config.DiscoveryClusterRateLimit()
+	if cluster == "" || limit <= 0 {
+		return 0
+	}
+	limiter, found := q.limiters[cluster]
+	burst := int(math.Ceil(limit))
+	if !found {
+		limiter = rate.NewLimiter(rate.Limit(limit), burst)
+		q.limiters[cluster] = limiter
+	} else if limiter.Limit() != rate.Limit(limit) {
+		limiter.SetLimitAt(now, rate.Limit(limit))
+		limiter.SetBurstAt(now, burst)
+	}
+	reservation := limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay
+	}
+	return 0
 }
 
 // Release removes a key from a list of being processed keys
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+func consumeAll(q *Queue) []string {
+	var keys []string
+	for q.QueueLen() > 0 {
+		keys = append(keys, q.Consume())
+	}
+	return keys
+}
+
+func TestQueuePriorities(t *testing.T) {
+	q := newQueue("test", 100)
+
+	q.Push("zone1-100")
+	q.PushWithPriority("zone1-101", "ks/-80", PriorityPrimary)
+	q.PushWithPriority("zone1-102", "ks/80-", PriorityRecovery)
+	q.Push("zone1-103")
+	// A queued key is moved up to a higher priority, but never down.
+	q.PushWithPriority("zone1-103", "ks/-80", PriorityPrimary)
+	q.Push("zone1-102")
+	assert.Equal(t, 4, q.QueueLen())
+
+	assert.Equal(t, []string{"zone1-102", "zone1-101", "zone1-103", "zone1-100"}, consumeAll(q))
+
+	// The keys being processed are not queued again until they are released.
+	q.Push("zone1-100")
+	assert.Zero(t, q.QueueLen())
+	q.Release("zone1-100")
+	q.Push("zone1-100")
+	assert.Equal(t, []string{"zone1-100"}, consumeAll(q))
+}
+
+func TestQueueClustersTakeTurns(t *testing.T) {
+	q := newQueue("test", 100)
+
+	for _, key := range []string{"a1", "a2", "a3"} {
+		q.PushWithPriority(key, "ks/-80", PriorityNormal)
+	}
+	for _, key := range []string{"b1", "b2"} {
+		q.PushWithPriority(key, "ks/80-", PriorityNormal)
+	}
+	assert.Equal(t, []string{"a1", "b1", "a2", "b2", "a3"}, consumeAll(q))
+}
+
+func TestQueueClusterRateLimit(t *testing.T) {
+	defer config.SetDiscoveryClusterRateLimit(0)
+	config.SetDiscoveryClusterRateLimit(2)
+	q := newQueue("test", 100)
+
+	for _, key := range []string{"a1", "a2", "a3"} {
+		q.PushWithPriority(key, "ks/-80", PriorityNormal)
+	}
+	q.PushWithPriority("b1", "ks/80-", PriorityNormal)
+	q.Push("c1")
+
+	// The burst of the limited cluster is used up, without holding up the other ones.
+	assert.Equal(t, []string{"a1", "b1", "c1", "a2"}, []string{q.Consume(), q.Consume(), q.Consume(), q.Consume()})
+	now := time.Now()
+	_, wait := q.pop(now)
+	assert.Greater(t, wait, time.Duration(0))
+
+	// The next key of the limited cluster is consumed once the limit allows it.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key, err := q.ConsumeContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "a3", key)
+	assert.GreaterOrEqual(t, time.Since(now), 100*time.Millisecond)
+}
+
+func TestQueuePushBlocksWhenFull(t *testing.T) {
+	q := newQueue("test", 1)
+
+	q.Push("zone1-100")
+	pushed := make(chan struct{})
+	go func() {
+		defer close(pushed)
+		q.Push("zone1-101")
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("push should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, "zone1-100", q.Consume())
+	<-pushed
+	assert.Equal(t, "zone1-101", q.Consume())
+}
+
+func TestQueueConsumeContext(t *testing.T) {
+	q := newQueue("test", 100)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := q.ConsumeContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// A waiting consumer is woken up by a push.
+	consumed := make(chan string)
+	go func() {
+		consumed <- q.Consume()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Push("zone1-100")
+	assert.Equal(t, "zone1-100", <-consumed)
+}
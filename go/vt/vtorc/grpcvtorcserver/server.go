@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package grpcvtorcserver contains the gRPC implementation of the server side
+of the VTOrc API, which exposes the failure detections, the recoveries in
+progress and the state of the discovery of VTOrc.
+*/
+package grpcvtorcserver
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+	"vitess.io/vitess/go/vt/vtorc/logic"
+
+	vtorcpb "vitess.io/vitess/go/vt/proto/vtorc"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/proto/vttime"
+)
+
+// timestampLayouts are the layouts of the timestamps of the recoveries,
+// depending on whether the backend returns them as times or as strings.
+var timestampLayouts = []string{time.RFC3339, "2006-01-02 15:04:05"}
+
+// server is our gRPC server.
+type server struct {
+	vtorcpb.UnimplementedVTOrcServer
+}
+
+// checkKeyspaceShard rejects the filtering by shard without a keyspace, like the HTTP API.
+func checkKeyspaceShard(keyspace string, shard string) error {
+	if shard != "" && keyspace == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "filtering by shard without keyspace isn't supported")
+	}
+	return nil
+}
+
+// GetFailureDetections is part of the vtorcpb.VTOrcServer interface.
+func (s *server) GetFailureDetections(ctx context.Context, request *vtorcpb.GetFailureDetectionsRequest) (*vtorcpb.GetFailureDetectionsResponse, error) {
+	if err := checkKeyspaceShard(request.Keyspace, request.Shard); err != nil {
+		return nil, err
+	}
+	entries, err := inst.GetReplicationAnalysis(request.Keyspace, request.Shard, &inst.ReplicationAnalysisHints{})
+	if err != nil {
+		return nil, vterrors.Wrap(err, "failed to analyze the replication")
+	}
+	response := &vtorcpb.GetFailureDetectionsResponse{}
+	for _, entry := range entries {
+		if entry.Analysis == inst.NoProblem {
+			continue
+		}
+		response.Detections = append(response.Detections, &vtorcpb.FailureDetection{
+			Analysis:     string(entry.Analysis),
+			TabletAlias:  entry.AnalyzedInstanceAlias,
+			Keyspace:     entry.ClusterDetails.Keyspace,
+			Shard:        entry.ClusterDetails.Shard,
+			Description:  entry.Description,
+			IsActionable: logic.IsActionableAnalysis(entry),
+		})
+	}
+	return response, nil
+}
+
+// GetActiveRecoveries is part of the vtorcpb.VTOrcServer interface.
+func (s *server) GetActiveRecoveries(ctx context.Context, request *vtorcpb.GetActiveRecoveriesRequest) (*vtorcpb.GetActiveRecoveriesResponse, error) {
+	if err := checkKeyspaceShard(request.Keyspace, request.Shard); err != nil {
+		return nil, err
+	}
+	recoveries, err := logic.ReadActiveRecoveries(request.Keyspace, request.Shard)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "failed to read the active recoveries")
+	}
+	response := &vtorcpb.GetActiveRecoveriesResponse{}
+	for _, recovery := range recoveries {
+		response.Recoveries = append(response.Recoveries, recoveryToProto(recovery))
+	}
+	return response, nil
+}
+
+// GetDiscoveryQueueStatus is part of the vtorcpb.VTOrcServer interface.
+func (s *server) GetDiscoveryQueueStatus(ctx context.Context, request *vtorcpb.GetDiscoveryQueueStatusRequest) (*vtorcpb.GetDiscoveryQueueStatusResponse, error) {
+	status := logic.GetDiscoveryQueueStatus()
+	return &vtorcpb.GetDiscoveryQueueStatusResponse{
+		QueueLength:                 int64(status.QueueLength),
+		ConcurrencyLimit:            int64(status.ConcurrencyLimit),
+		Running:                     int64(status.Running),
+		RecentDiscoveries:           int64(status.RecentDiscoveries),
+		FirstDiscoveryCycleComplete: status.FirstDiscoveryCycleComplete,
+	}, nil
+}
+
+func recoveryToProto(recovery *logic.TopologyRecovery) *vtorcpb.Recovery {
+	r := &vtorcpb.Recovery{
+		Id:             recovery.ID,
+		DetectionId:    recovery.DetectionID,
+		Analysis:       string(recovery.AnalysisEntry.Analysis),
+		TabletAlias:    recovery.AnalysisEntry.AnalyzedInstanceAlias,
+		Keyspace:       recovery.AnalysisEntry.ClusterDetails.Keyspace,
+		Shard:          recovery.AnalysisEntry.ClusterDetails.Shard,
+		StartTime:      timestampToProto(recovery.RecoveryStartTimestamp),
+		EndTime:        timestampToProto(recovery.RecoveryEndTimestamp),
+		IsSuccessful:   recovery.IsSuccessful,
+		SuccessorAlias: recovery.SuccessorAlias,
+	}
+	for _, err := range recovery.AllErrors {
+		if err != "" {
+			r.Errors = append(r.Errors, err)
+		}
+	}
+	return r
+}
+
+// timestampToProto converts a timestamp of a recovery, returning nil when
+// it is not set or cannot be parsed.
+func timestampToProto(timestamp string) *vttime.Time {
+	if timestamp == "" {
+		return nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			return protoutil.TimeToProto(t)
+		}
+	}
+	return nil
+}
+
+// StartServer registers the VTOrc gRPC service.
+func StartServer(s *grpc.Server) {
+	vtorcpb.RegisterVTOrcServer(s, &server{})
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtorcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+	"vitess.io/vitess/go/vt/vtorc/logic"
+
+	vtorcpb "vitess.io/vitess/go/vt/proto/vtorc"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestShardWithoutKeyspace(t *testing.T) {
+	s := &server{}
+	ctx := context.Background()
+
+	_, err := s.GetFailureDetections(ctx, &vtorcpb.GetFailureDetectionsRequest{Shard: "-80"})
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	_, err = s.GetActiveRecoveries(ctx, &vtorcpb.GetActiveRecoveriesRequest{Shard: "-80"})
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+}
+
+func TestGetActiveRecoveries(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+	s := &server{}
+	ctx := context.Background()
+
+	recovery, err := logic.AttemptRecoveryRegistration(&inst.ReplicationAnalysis{
+		AnalyzedInstanceAlias: "zone1-0000000100",
+		ClusterDetails: inst.ClusterInfo{
+			Keyspace: "ks",
+			Shard:    "-80",
+		},
+		Analysis: inst.DeadPrimary,
+	})
+	require.NoError(t, err)
+
+	res, err := s.GetActiveRecoveries(ctx, &vtorcpb.GetActiveRecoveriesRequest{Keyspace: "ks"})
+	require.NoError(t, err)
+	require.Len(t, res.Recoveries, 1)
+	got := res.Recoveries[0]
+	assert.Equal(t, recovery.ID, got.Id)
+	assert.Equal(t, string(inst.DeadPrimary), got.Analysis)
+	assert.Equal(t, "zone1-0000000100", got.TabletAlias)
+	assert.Equal(t, "ks", got.Keyspace)
+	assert.Equal(t, "-80", got.Shard)
+	assert.NotNil(t, got.StartTime)
+	assert.Nil(t, got.EndTime)
+	assert.Empty(t, got.Errors)
+
+	res, err = s.GetActiveRecoveries(ctx, &vtorcpb.GetActiveRecoveriesRequest{Keyspace: "ks", Shard: "80-"})
+	require.NoError(t, err)
+	assert.Empty(t, res.Recoveries)
+}
+
+func TestGetFailureDetections(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+	s := &server{}
+
+	// Without any tablet, there is nothing to detect.
+	res, err := s.GetFailureDetections(context.Background(), &vtorcpb.GetFailureDetectionsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, res.Detections)
+}
+
+func TestGetDiscoveryQueueStatus(t *testing.T) {
+	s := &server{}
+
+	res, err := s.GetDiscoveryQueueStatus(context.Background(), &vtorcpb.GetDiscoveryQueueStatusRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, config.DiscoveryMaxConcurrency, res.ConcurrencyLimit)
+	assert.Zero(t, res.Running)
+}
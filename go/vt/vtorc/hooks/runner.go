@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package hooks runs the commands configured to be run on the recoveries of
+VTOrc. The commands receive the details of the recovery as JSON on their
+stdin. They are run as they are configured, since the details could hold
+anything the shell would interpret.
+*/
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+// The phases of a recovery in which the hooks run.
+const (
+	PhaseFailureDetection = "failure-detection"
+	PhasePreRecovery      = "pre-recovery"
+	PhasePostRecovery     = "post-recovery"
+)
+
+var (
+	hookRuns     = stats.NewCountersWithMultiLabels("RecoveryHookRuns", "Number of runs of the recovery hooks", []string{"Phase", "Result"})
+	hookDuration = stats.NewTimings("RecoveryHookDuration", "Duration of the runs of the recovery hooks", "Phase")
+
+	// retryDelay is the time to wait before retrying a failed hook.
+	retryDelay = time.Second
+	// defaultTimeout is the time after which the hooks without a timeout of
+	// their own are killed.
+	defaultTimeout = 30 * time.Second
+)
+
+// Event is the JSON document the hooks receive on their stdin.
+type Event struct {
+	Phase                string   `json:"phase"`
+	Analysis             string   `json:"analysis"`
+	Keyspace             string   `json:"keyspace"`
+	Shard                string   `json:"shard"`
+	FailedTabletAlias    string   `json:"failed_tablet_alias"`
+	SuccessorTabletAlias string   `json:"successor_tablet_alias,omitempty"`
+	RecoveryID           int64    `json:"recovery_id,omitempty"`
+	IsSuccessful         bool     `json:"is_successful,omitempty"`
+	Errors               []string `json:"errors,omitempty"`
+}
+
+// HookRunner runs the recovery hooks.
+type HookRunner struct {
+	// run runs a command with the given stdin. It is replaced in tests.
+	run func(ctx context.Context, command string, stdin []byte) ([]byte, error)
+
+	async sync.WaitGroup
+}
+
+// NewHookRunner returns a HookRunner running the commands with bash.
+func NewHookRunner() *HookRunner {
+	return &HookRunner{run: runCommand}
+}
+
+func runCommand(ctx context.Context, command string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	return cmd.CombinedOutput()
+}
+
+// Run runs the hooks for the event, in order. The synchronous hooks are
+// waited for, and their errors returned, while the asynchronous ones run
+// in the background and their errors are only logged.
+func (r *HookRunner) Run(ctx context.Context, hooks []config.RecoveryHook, event *Event) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	stdin, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	rec := concurrency.AllErrorRecorder{}
+	for i, hook := range hooks {
+		if hook.Async {
+			r.async.Add(1)
+			go func() {
+				defer r.async.Done()
+				// The hook outlives the caller, but not its own timeout.
+				if err := r.runHook(context.WithoutCancel(ctx), hook, event.Phase, stdin); err != nil {
+					log.Errorf("Asynchronous %s hook %d failed: %v", event.Phase, i, err)
+				}
+			}()
+			continue
+		}
+		if err := r.runHook(ctx, hook, event.Phase, stdin); err != nil {
+			rec.RecordError(fmt.Errorf("%s hook %d: %w", event.Phase, i, err))
+		}
+	}
+	return rec.Error()
+}
+
+// Wait waits for the asynchronous hooks to complete, or for the context to be done.
+func (r *HookRunner) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.async.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runHook runs a hook, retrying it as configured when it fails.
+func (r *HookRunner) runHook(ctx context.Context, hook config.RecoveryHook, phase string, stdin []byte) error {
+	var err error
+	for attempt := 0; attempt <= hook.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return err
+			}
+		}
+		if err = r.runOnce(ctx, hook, phase, stdin); err == nil {
+			return nil
+		}
+		log.Warningf("%s hook %q failed, attempt %d of %d: %v", phase, hook.Command, attempt+1, hook.Retries+1, err)
+	}
+	return err
+}
+
+func (r *HookRunner) runOnce(ctx context.Context, hook config.RecoveryHook, phase string, stdin []byte) error {
+	timeout := defaultTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	output, err := r.run(ctx, hook.Command, stdin)
+	hookDuration.Record(phase, start)
+	if err != nil {
+		hookRuns.Add([]string{phase, "Failure"}, 1)
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w: %v", ctx.Err(), err)
+		}
+		return fmt.Errorf("%w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+	hookRuns.Add([]string{phase, "Success"}, 1)
+	log.Infof("%s hook %q succeeded, output: %s", phase, hook.Command, strings.TrimSpace(string(output)))
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+var testEvent = &Event{
+	Phase:                PhasePostRecovery,
+	Analysis:             "DeadPrimary",
+	Keyspace:             "ks",
+	Shard:                "-80",
+	FailedTabletAlias:    "zone1-0000000100",
+	SuccessorTabletAlias: "zone1-0000000101",
+	RecoveryID:           3,
+	IsSuccessful:         true,
+}
+
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	r := NewHookRunner()
+
+	// The command gets the event on its stdin.
+	err := r.Run(context.Background(), []config.RecoveryHook{{
+		Command: "cat > " + filepath.Join(dir, "event.json"),
+	}}, testEvent)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "event.json"))
+	require.NoError(t, err)
+	var event Event
+	require.NoError(t, json.Unmarshal(data, &event))
+	assert.Equal(t, *testEvent, event)
+
+	// The failures of the synchronous hooks are returned, with their output.
+	err = r.Run(context.Background(), []config.RecoveryHook{
+		{Command: "echo broken; exit 1"},
+		{Command: "true"},
+	}, testEvent)
+	assert.ErrorContains(t, err, "post-recovery hook 0: exit status 1, output: broken")
+	assert.NotContains(t, err.Error(), "hook 1")
+
+	// The commands are run as they are, not as templates of the event.
+	err = r.Run(context.Background(), []config.RecoveryHook{{
+		Command: "echo -n '{{.Keyspace}}' > " + filepath.Join(dir, "command"),
+	}}, testEvent)
+	require.NoError(t, err)
+	data, err = os.ReadFile(filepath.Join(dir, "command"))
+	require.NoError(t, err)
+	assert.Equal(t, "{{.Keyspace}}", string(data))
+
+	// The hooks are killed once they time out.
+	start := time.Now()
+	err = r.Run(context.Background(), []config.RecoveryHook{{Command: "sleep 10", TimeoutSeconds: 1}}, testEvent)
+	assert.ErrorContains(t, err, "context deadline exceeded")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRunDefaultTimeout(t *testing.T) {
+	defer func(timeout time.Duration) {
+		defaultTimeout = timeout
+	}(defaultTimeout)
+	defaultTimeout = 10 * time.Millisecond
+
+	r := &HookRunner{run: func(ctx context.Context, command string, stdin []byte) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	// The hooks without a timeout are killed after the default one.
+	err := r.Run(context.Background(), []config.RecoveryHook{{Command: "hook"}}, testEvent)
+	assert.ErrorContains(t, err, "context deadline exceeded")
+}
+
+func TestRunRetries(t *testing.T) {
+	defer func(delay time.Duration) {
+		retryDelay = delay
+	}(retryDelay)
+	retryDelay = time.Millisecond
+
+	var attempts int
+	r := &HookRunner{run: func(ctx context.Context, command string, stdin []byte) ([]byte, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("failed")
+		}
+		return nil, nil
+	}}
+
+	require.NoError(t, r.Run(context.Background(), []config.RecoveryHook{{Command: "hook", Retries: 2}}, testEvent))
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	require.Error(t, r.Run(context.Background(), []config.RecoveryHook{{Command: "hook", Retries: 1}}, testEvent))
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRunAsync(t *testing.T) {
+	var mu sync.Mutex
+	var commands []string
+	release := make(chan struct{})
+	r := &HookRunner{run: func(ctx context.Context, command string, stdin []byte) ([]byte, error) {
+		if command == "async" {
+			<-release
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		commands = append(commands, command)
+		return nil, errors.New("the failures of the asynchronous hooks are only logged")
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := r.Run(ctx, []config.RecoveryHook{
+		{Command: "async", Async: true},
+		{Command: "sync"},
+	}, testEvent)
+	// The asynchronous hook doesn't hold up the synchronous ones, nor is it
+	// stopped when the context of the caller is canceled.
+	require.Error(t, err)
+	cancel()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer waitCancel()
+	assert.ErrorIs(t, r.Wait(waitCtx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, r.Wait(context.Background()))
+	assert.Equal(t, []string{"sync", "async"}, commands)
+}
@@ -56,6 +56,7 @@ const (
 	LockedSemiSyncPrimary                  AnalysisCode = "LockedSemiSyncPrimary"
 	BinlogServerFailingToConnectToPrimary  AnalysisCode = "BinlogServerFailingToConnectToPrimary"
 	ErrantGTIDDetected                     AnalysisCode = "ErrantGTIDDetected"
+	PrimaryExternallyReparented            AnalysisCode = "PrimaryExternallyReparented"
 )
 
 type StructureAnalysisCode string
@@ -137,8 +138,10 @@ type ReplicationAnalysis struct {
 func (replicationAnalysis *ReplicationAnalysis) MarshalJSON() ([]byte, error) {
 	i := struct {
 		ReplicationAnalysis
+		Runbook *config.Runbook `json:",omitempty"`
 	}{}
 	i.ReplicationAnalysis = *replicationAnalysis
+	i.Runbook = config.RunbookFor(string(replicationAnalysis.Analysis))
 
 	return json.Marshal(i)
 }
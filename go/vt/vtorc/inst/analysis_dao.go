@@ -27,8 +27,6 @@ import (
 	"google.golang.org/protobuf/encoding/prototext"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
-	"vitess.io/vitess/go/vt/topo"
-	"vitess.io/vitess/go/vt/vtctl/reparentutil"
 	"vitess.io/vitess/go/vt/vtorc/config"
 	"vitess.io/vitess/go/vt/vtorc/db"
 	"vitess.io/vitess/go/vt/vtorc/util"
@@ -53,23 +51,35 @@ func initializeAnalysisDaoPostConfiguration() {
 	recentInstantAnalysis = cache.New(time.Duration(config.Config.RecoveryPollSeconds*2)*time.Second, time.Second)
 }
 
-type clusterAnalysis struct {
-	hasClusterwideAction bool
-	totalTablets         int
-	primaryAlias         string
-	durability           reparentutil.Durabler
-}
-
 // GetReplicationAnalysis will check for replication problems (dead primary; unreachable primary; etc)
 func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAnalysisHints) ([]*ReplicationAnalysis, error) {
-	var result []*ReplicationAnalysis
-	appendAnalysis := func(analysis *ReplicationAnalysis) {
-		if analysis.Analysis == NoProblem && len(analysis.StructureAnalysis) == 0 {
-			return
+	snapshots, err := readInstanceSnapshots(keyspace, shard)
+	analyses, clusters := analyzeAllInstanceSnapshots(snapshots)
+
+	if hints.AuditAnalysis {
+		// Every analysis is audited, so that the changelog also records the problems that cleared.
+		for _, a := range analyses {
+			if a.CountReplicas > 0 {
+				// Interesting enough for analysis
+				go func() {
+					_ = auditInstanceAnalysisInChangelog(a.AnalyzedInstanceAlias, a.Analysis)
+				}()
+			}
 		}
-		result = append(result, analysis)
 	}
 
+	result := postProcessAnalyses(filterProblems(analyses), clusters)
+
+	if err != nil {
+		log.Error(err)
+	}
+	// TODO: result, err = getConcensusReplicationAnalysis(result)
+	return result, err
+}
+
+// readInstanceSnapshots reads the snapshots of the instances in the given keyspace and shard from the backend database.
+func readInstanceSnapshots(keyspace string, shard string) ([]*InstanceSnapshot, error) {
+	var snapshots []*InstanceSnapshot
 	// TODO(sougou); deprecate ReduceReplicationAnalysisCount
 	args := sqlutils.Args(config.Config.ReasonableReplicationLagSeconds, ValidSecondsFromSeenToLastAttemptedCheck(), config.Config.ReasonableReplicationLagSeconds, keyspace, shard)
 	query := `
@@ -291,11 +301,9 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 		vitess_tablet.primary_timestamp DESC
 	`
 
-	clusters := make(map[string]*clusterAnalysis)
 	err := db.Db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
-		a := &ReplicationAnalysis{
-			Analysis: NoProblem,
-		}
+		snapshot := &InstanceSnapshot{}
+		a := &snapshot.State
 
 		tablet := &topodatapb.Tablet{}
 		opts := prototext.UnmarshalOptions{DiscardUnknown: true}
@@ -312,6 +320,8 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 			}
 		}
 
+		snapshot.Tablet = tablet
+		snapshot.PrimaryTablet = primaryTablet
 		a.TabletType = tablet.Type
 		a.AnalyzedKeyspace = m.GetString("keyspace")
 		a.AnalyzedShard = m.GetString("shard")
@@ -331,7 +341,9 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 			LogPos:  m.GetUint32("binary_log_pos"),
 			Type:    BinaryLog,
 		}
-		isStaleBinlogCoordinates := m.GetBool("is_stale_binlog_coordinates")
+		snapshot.IsStaleBinlogCoordinates = m.GetBool("is_stale_binlog_coordinates")
+		snapshot.IsInvalid = m.GetBool("is_invalid")
+		snapshot.DurabilityPolicy = m.GetString("durability_policy")
 		a.ClusterDetails.Keyspace = m.GetString("keyspace")
 		a.ClusterDetails.Shard = m.GetString("shard")
 		a.GTIDMode = m.GetString("gtid_mode")
@@ -384,271 +396,10 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 				log.Infof(analysisMessage)
 			}
 		}
-		keyspaceShard := getKeyspaceShardName(a.ClusterDetails.Keyspace, a.ClusterDetails.Shard)
-		if clusters[keyspaceShard] == nil {
-			clusters[keyspaceShard] = &clusterAnalysis{}
-			if a.TabletType == topodatapb.TabletType_PRIMARY {
-				a.IsClusterPrimary = true
-				clusters[keyspaceShard].primaryAlias = a.AnalyzedInstanceAlias
-			}
-			durabilityPolicy := m.GetString("durability_policy")
-			if durabilityPolicy == "" {
-				log.Errorf("ignoring keyspace %v because no durability_policy is set. Please set it using SetKeyspaceDurabilityPolicy", a.AnalyzedKeyspace)
-				return nil
-			}
-			durability, err := reparentutil.GetDurabilityPolicy(durabilityPolicy)
-			if err != nil {
-				log.Errorf("can't get the durability policy %v - %v. Skipping keyspace - %v.", durabilityPolicy, err, a.AnalyzedKeyspace)
-				return nil
-			}
-			clusters[keyspaceShard].durability = durability
-		}
-		// ca has clusterwide info
-		ca := clusters[keyspaceShard]
-		// Increment the total number of tablets.
-		ca.totalTablets += 1
-		if ca.hasClusterwideAction {
-			// We can only take one cluster level action at a time.
-			return nil
-		}
-		if ca.durability == nil {
-			// We failed to load the durability policy, so we shouldn't run any analysis
-			return nil
-		}
-		isInvalid := m.GetBool("is_invalid")
-		if a.IsClusterPrimary && isInvalid {
-			a.Analysis = InvalidPrimary
-			a.Description = "VTOrc hasn't been able to reach the primary even once since restart/shutdown"
-		} else if isInvalid {
-			a.Analysis = InvalidReplica
-			a.Description = "VTOrc hasn't been able to reach the replica even once since restart/shutdown"
-		} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountReplicas == 0 {
-			a.Analysis = DeadPrimaryWithoutReplicas
-			a.Description = "Primary cannot be reached by vtorc and has no replica"
-			ca.hasClusterwideAction = true
-			//
-		} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountValidReplicas == a.CountReplicas && a.CountValidReplicatingReplicas == 0 {
-			a.Analysis = DeadPrimary
-			a.Description = "Primary cannot be reached by vtorc and none of its replicas is replicating"
-			ca.hasClusterwideAction = true
-			//
-		} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountReplicas > 0 && a.CountValidReplicas == 0 && a.CountValidReplicatingReplicas == 0 {
-			a.Analysis = DeadPrimaryAndReplicas
-			a.Description = "Primary cannot be reached by vtorc and none of its replicas is replicating"
-			ca.hasClusterwideAction = true
-			//
-		} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountValidReplicas < a.CountReplicas && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas == 0 {
-			a.Analysis = DeadPrimaryAndSomeReplicas
-			a.Description = "Primary cannot be reached by vtorc; some of its replicas are unreachable and none of its reachable replicas is replicating"
-			ca.hasClusterwideAction = true
-			//
-		} else if a.IsClusterPrimary && !a.IsPrimary {
-			a.Analysis = PrimaryHasPrimary
-			a.Description = "Primary is replicating from somewhere else"
-			ca.hasClusterwideAction = true
-			//
-		} else if a.IsClusterPrimary && a.IsReadOnly {
-			a.Analysis = PrimaryIsReadOnly
-			a.Description = "Primary is read-only"
-			//
-		} else if a.IsClusterPrimary && reparentutil.SemiSyncAckers(ca.durability, tablet) != 0 && !a.SemiSyncPrimaryEnabled {
-			a.Analysis = PrimarySemiSyncMustBeSet
-			a.Description = "Primary semi-sync must be set"
-			//
-		} else if a.IsClusterPrimary && reparentutil.SemiSyncAckers(ca.durability, tablet) == 0 && a.SemiSyncPrimaryEnabled {
-			a.Analysis = PrimarySemiSyncMustNotBeSet
-			a.Description = "Primary semi-sync must not be set"
-			//
-		} else if topo.IsReplicaType(a.TabletType) && a.ErrantGTID != "" {
-			a.Analysis = ErrantGTIDDetected
-			a.Description = "Tablet has errant GTIDs"
-		} else if topo.IsReplicaType(a.TabletType) && ca.primaryAlias == "" && a.ShardPrimaryTermTimestamp == "" {
-			// ClusterHasNoPrimary should only be detected when the shard record doesn't have any primary term start time specified either.
-			a.Analysis = ClusterHasNoPrimary
-			a.Description = "Cluster has no primary"
-			ca.hasClusterwideAction = true
-		} else if topo.IsReplicaType(a.TabletType) && ca.primaryAlias == "" && a.ShardPrimaryTermTimestamp != "" {
-			// If there are no primary tablets, but the shard primary start time isn't empty, then we know
-			// the primary tablet was deleted.
-			a.Analysis = PrimaryTabletDeleted
-			a.Description = "Primary tablet has been deleted"
-			ca.hasClusterwideAction = true
-		} else if topo.IsReplicaType(a.TabletType) && !a.IsReadOnly {
-			a.Analysis = ReplicaIsWritable
-			a.Description = "Replica is writable"
-			//
-		} else if topo.IsReplicaType(a.TabletType) && a.IsPrimary {
-			a.Analysis = NotConnectedToPrimary
-			a.Description = "Not connected to the primary"
-			//
-		} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && ca.primaryAlias != "" && a.AnalyzedInstancePrimaryAlias != ca.primaryAlias {
-			a.Analysis = ConnectedToWrongPrimary
-			a.Description = "Connected to wrong primary"
-			//
-		} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && a.ReplicationStopped {
-			a.Analysis = ReplicationStopped
-			a.Description = "Replication is stopped"
-			//
-		} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && reparentutil.IsReplicaSemiSync(ca.durability, primaryTablet, tablet) && !a.SemiSyncReplicaEnabled {
-			a.Analysis = ReplicaSemiSyncMustBeSet
-			a.Description = "Replica semi-sync must be set"
-			//
-		} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && !reparentutil.IsReplicaSemiSync(ca.durability, primaryTablet, tablet) && a.SemiSyncReplicaEnabled {
-			a.Analysis = ReplicaSemiSyncMustNotBeSet
-			a.Description = "Replica semi-sync must not be set"
-			//
-			// TODO(sougou): Events below here are either ignored or not possible.
-		} else if a.IsPrimary && !a.LastCheckValid && a.CountLaggingReplicas == a.CountReplicas && a.CountDelayedReplicas < a.CountReplicas && a.CountValidReplicatingReplicas > 0 {
-			a.Analysis = UnreachablePrimaryWithLaggingReplicas
-			a.Description = "Primary cannot be reached by vtorc and all of its replicas are lagging"
-			//
-		} else if a.IsPrimary && !a.LastCheckValid && !a.LastCheckPartialSuccess && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas > 0 {
-			// partial success is here to reduce noise
-			a.Analysis = UnreachablePrimary
-			a.Description = "Primary cannot be reached by vtorc but it has replicating replicas; possibly a network/host issue"
-			//
-		} else if a.IsPrimary && !a.LastCheckValid && a.LastCheckPartialSuccess && a.CountReplicasFailingToConnectToPrimary > 0 && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas > 0 {
-			// there's partial success, but also at least one replica is failing to connect to primary
-			a.Analysis = UnreachablePrimary
-			a.Description = "Primary cannot be reached by vtorc but it has replicating replicas; possibly a network/host issue"
-			//
-		} else if a.IsPrimary && a.SemiSyncPrimaryEnabled && a.SemiSyncPrimaryStatus && a.SemiSyncPrimaryWaitForReplicaCount > 0 && a.SemiSyncPrimaryClients < a.SemiSyncPrimaryWaitForReplicaCount {
-			if isStaleBinlogCoordinates {
-				a.Analysis = LockedSemiSyncPrimary
-				a.Description = "Semi sync primary is locked since it doesn't get enough replica acknowledgements"
-			} else {
-				a.Analysis = LockedSemiSyncPrimaryHypothesis
-				a.Description = "Semi sync primary seems to be locked, more samplings needed to validate"
-			}
-			//
-		} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas == 1 && a.CountValidReplicas == a.CountReplicas && a.CountValidReplicatingReplicas == 0 {
-			a.Analysis = PrimarySingleReplicaNotReplicating
-			a.Description = "Primary is reachable but its single replica is not replicating"
-		} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas == 1 && a.CountValidReplicas == 0 {
-			a.Analysis = PrimarySingleReplicaDead
-			a.Description = "Primary is reachable but its single replica is dead"
-			//
-		} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas > 1 && a.CountValidReplicas == a.CountReplicas && a.CountValidReplicatingReplicas == 0 {
-			a.Analysis = AllPrimaryReplicasNotReplicating
-			a.Description = "Primary is reachable but none of its replicas is replicating"
-			//
-		} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas > 1 && a.CountValidReplicas < a.CountReplicas && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas == 0 {
-			a.Analysis = AllPrimaryReplicasNotReplicatingOrDead
-			a.Description = "Primary is reachable but none of its replicas is replicating"
-			//
-		} else if a.IsBinlogServer && a.IsFailingToConnectToPrimary {
-			a.Analysis = BinlogServerFailingToConnectToPrimary
-			a.Description = "Binlog server is unable to connect to its primary"
-			//
-		}
-		//		 else if a.IsPrimary && a.CountReplicas == 0 {
-		//			a.Analysis = PrimaryWithoutReplicas
-		//			a.Description = "Primary has no replicas"
-		//		}
-
-		{
-			// Moving on to structure analysis
-			// We also do structural checks. See if there's potential danger in promotions
-			if a.IsPrimary && a.CountLoggingReplicas == 0 && a.CountReplicas > 1 {
-				a.StructureAnalysis = append(a.StructureAnalysis, NoLoggingReplicasStructureWarning)
-			}
-			if a.IsPrimary && a.CountReplicas > 1 &&
-				!a.OracleGTIDImmediateTopology &&
-				!a.MariaDBGTIDImmediateTopology &&
-				!a.BinlogServerImmediateTopology {
-				a.StructureAnalysis = append(a.StructureAnalysis, NoFailoverSupportStructureWarning)
-			}
-			if a.IsPrimary && a.CountStatementBasedLoggingReplicas > 0 && a.CountMixedBasedLoggingReplicas > 0 {
-				a.StructureAnalysis = append(a.StructureAnalysis, StatementAndMixedLoggingReplicasStructureWarning)
-			}
-			if a.IsPrimary && a.CountStatementBasedLoggingReplicas > 0 && a.CountRowBasedLoggingReplicas > 0 {
-				a.StructureAnalysis = append(a.StructureAnalysis, StatementAndRowLoggingReplicasStructureWarning)
-			}
-			if a.IsPrimary && a.CountMixedBasedLoggingReplicas > 0 && a.CountRowBasedLoggingReplicas > 0 {
-				a.StructureAnalysis = append(a.StructureAnalysis, MixedAndRowLoggingReplicasStructureWarning)
-			}
-			if a.IsPrimary && a.CountDistinctMajorVersionsLoggingReplicas > 1 {
-				a.StructureAnalysis = append(a.StructureAnalysis, MultipleMajorVersionsLoggingReplicasStructureWarning)
-			}
-
-			if a.CountReplicas > 0 && (a.GTIDMode != a.MinReplicaGTIDMode || a.GTIDMode != a.MaxReplicaGTIDMode) {
-				a.StructureAnalysis = append(a.StructureAnalysis, DifferentGTIDModesStructureWarning)
-			}
-			if a.MaxReplicaGTIDErrant != "" {
-				a.StructureAnalysis = append(a.StructureAnalysis, ErrantGTIDStructureWarning)
-			}
-
-			if a.IsPrimary && a.IsReadOnly {
-				a.StructureAnalysis = append(a.StructureAnalysis, NoWriteablePrimaryStructureWarning)
-			}
-
-			if a.IsPrimary && a.SemiSyncPrimaryEnabled && !a.SemiSyncPrimaryStatus && a.SemiSyncPrimaryWaitForReplicaCount > 0 && a.SemiSyncPrimaryClients < a.SemiSyncPrimaryWaitForReplicaCount {
-				a.StructureAnalysis = append(a.StructureAnalysis, NotEnoughValidSemiSyncReplicasStructureWarning)
-			}
-		}
-		appendAnalysis(a)
-
-		if a.CountReplicas > 0 && hints.AuditAnalysis {
-			// Interesting enough for analysis
-			go func() {
-				_ = auditInstanceAnalysisInChangelog(a.AnalyzedInstanceAlias, a.Analysis)
-			}()
-		}
+		snapshots = append(snapshots, snapshot)
 		return nil
 	})
-
-	result = postProcessAnalyses(result, clusters)
-
-	if err != nil {
-		log.Error(err)
-	}
-	// TODO: result, err = getConcensusReplicationAnalysis(result)
-	return result, err
-}
-
-// postProcessAnalyses is used to update different analyses based on the information gleaned from looking at all the analyses together instead of individual data.
-func postProcessAnalyses(result []*ReplicationAnalysis, clusters map[string]*clusterAnalysis) []*ReplicationAnalysis {
-	for {
-		// Store whether we have changed the result of replication analysis or not.
-		resultChanged := false
-
-		// Go over all the analyses.
-		for _, analysis := range result {
-			// If one of them is an InvalidPrimary, then we see if all the other tablets in this keyspace shard are
-			// unable to replicate or not.
-			if analysis.Analysis == InvalidPrimary {
-				keyspaceName := analysis.ClusterDetails.Keyspace
-				shardName := analysis.ClusterDetails.Shard
-				keyspaceShard := getKeyspaceShardName(keyspaceName, shardName)
-				totalReplicas := clusters[keyspaceShard].totalTablets - 1
-				var notReplicatingReplicas []int
-				for idx, replicaAnalysis := range result {
-					if replicaAnalysis.ClusterDetails.Keyspace == keyspaceName &&
-						replicaAnalysis.ClusterDetails.Shard == shardName && topo.IsReplicaType(replicaAnalysis.TabletType) {
-						// If the replica's last check is invalid or its replication is stopped, then we consider as not replicating.
-						if !replicaAnalysis.LastCheckValid || replicaAnalysis.ReplicationStopped {
-							notReplicatingReplicas = append(notReplicatingReplicas, idx)
-						}
-					}
-				}
-				// If none of the other tablets are able to replicate, then we conclude that this primary is not just Invalid, but also Dead.
-				// In this case, we update the analysis for the primary tablet and remove all the analyses of the replicas.
-				if totalReplicas > 0 && len(notReplicatingReplicas) == totalReplicas {
-					resultChanged = true
-					analysis.Analysis = DeadPrimary
-					for i := len(notReplicatingReplicas) - 1; i >= 0; i-- {
-						idxToRemove := notReplicatingReplicas[i]
-						result = append(result[0:idxToRemove], result[idxToRemove+1:]...)
-					}
-					break
-				}
-			}
-		}
-		if !resultChanged {
-			break
-		}
-	}
-	return result
+	return snapshots, err
 }
 
 // auditInstanceAnalysisInChangelog will write down an instance's analysis in the database_instance_analysis_changelog table.
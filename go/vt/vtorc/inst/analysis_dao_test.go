@@ -43,7 +43,7 @@ var (
 		`INSERT INTO vitess_tablet VALUES('zone1-0000000112','localhost',6747,'ks','0','zone1',3,'0001-01-01 00:00:00+00:00',X'616c6961733a7b63656c6c3a227a6f6e653122207569643a3131327d20686f73746e616d653a226c6f63616c686f73742220706f72745f6d61703a7b6b65793a2267727063222076616c75653a363734367d20706f72745f6d61703a7b6b65793a227674222076616c75653a363734357d206b657973706163653a226b73222073686172643a22302220747970653a52444f4e4c59206d7973716c5f686f73746e616d653a226c6f63616c686f737422206d7973716c5f706f72743a363734372064625f7365727665725f76657273696f6e3a22382e302e3331222064656661756c745f636f6e6e5f636f6c6c6174696f6e3a3435');`,
 		`INSERT INTO vitess_tablet VALUES('zone2-0000000200','localhost',6756,'ks','0','zone2',2,'0001-01-01 00:00:00+00:00',X'616c6961733a7b63656c6c3a227a6f6e653222207569643a3230307d20686f73746e616d653a226c6f63616c686f73742220706f72745f6d61703a7b6b65793a2267727063222076616c75653a363735357d20706f72745f6d61703a7b6b65793a227674222076616c75653a363735347d206b657973706163653a226b73222073686172643a22302220747970653a5245504c494341206d7973716c5f686f73746e616d653a226c6f63616c686f737422206d7973716c5f706f72743a363735362064625f7365727665725f76657273696f6e3a22382e302e3331222064656661756c745f636f6e6e5f636f6c6c6174696f6e3a3435');`,
 		`INSERT INTO vitess_shard VALUES('ks','0','zone1-0000000101','2022-12-28 07:23:25.129898+00:00');`,
-		`INSERT INTO vitess_keyspace VALUES('ks',0,'semi_sync');`,
+		`INSERT INTO vitess_keyspace VALUES('ks',0,'semi_sync',0,0);`,
 	}
 )
 
@@ -842,6 +842,37 @@ func TestGetReplicationAnalysis(t *testing.T) {
 	}
 }
 
+// TestGetReplicationAnalysisAuditsNoProblem verifies that GetReplicationAnalysis audits the analyses
+// without any problem, even though it doesn't return them, so that a problem that clears is recorded.
+func TestGetReplicationAnalysisAuditsNoProblem(t *testing.T) {
+	oldRecentInstantAnalysisCache := recentInstantAnalysis
+	recentInstantAnalysis = cache.New(2*time.Minute, 100*time.Millisecond)
+	defer func() {
+		recentInstantAnalysis = oldRecentInstantAnalysisCache
+		db.ClearVTOrcDatabase()
+	}()
+
+	for _, query := range initialSQL {
+		_, err := db.ExecVTOrc(query)
+		require.NoError(t, err)
+	}
+
+	got, err := GetReplicationAnalysis("", "", &ReplicationAnalysisHints{AuditAnalysis: true})
+	require.NoError(t, err)
+	require.Len(t, got, 0)
+
+	// The primary has replicas, so its NoProblem analysis is written to the changelog.
+	require.Eventually(t, func() bool {
+		var count int
+		err := db.QueryVTOrc(`select count(*) as c from database_instance_analysis_changelog where analysis = ?`,
+			sqlutils.Args(string(NoProblem)), func(row sqlutils.RowMap) error {
+				count = row.GetInt("c")
+				return nil
+			})
+		return err == nil && count > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
 // TestAuditInstanceAnalysisInChangelog tests the functionality of the auditInstanceAnalysisInChangelog function
 // and verifies that we write the correct number of times to the database.
 func TestAuditInstanceAnalysisInChangelog(t *testing.T) {
@@ -0,0 +1,419 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inst
+
+import (
+	"slices"
+
+	"vitess.io/vitess/go/vt/log"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil"
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+// InstanceSnapshot is the state of a single tablet, its MySQL instance and the
+// replicas of that instance, as observed by VTOrc. A set of snapshots is the input
+// of the analysis engine.
+type InstanceSnapshot struct {
+	// Tablet is the tablet record of the analyzed instance.
+	Tablet *topodatapb.Tablet
+	// PrimaryTablet is the tablet record of the instance's replication source, if any.
+	PrimaryTablet *topodatapb.Tablet
+	// DurabilityPolicy is the durability policy of the tablet's keyspace.
+	DurabilityPolicy string
+	// IsInvalid is true if VTOrc has not been able to reach the instance even once.
+	IsInvalid bool
+	// IsStaleBinlogCoordinates is true if the binlog coordinates of the instance
+	// have not moved for a while.
+	IsStaleBinlogCoordinates bool
+	// State holds the instance and replica state that the analysis is based on.
+	// The Analysis, Description, StructureAnalysis and IsClusterPrimary fields are
+	// computed by the engine and ignored here.
+	State ReplicationAnalysis
+}
+
+type clusterAnalysis struct {
+	hasClusterwideAction bool
+	totalTablets         int
+	primaryAlias         string
+	externalPrimaryAlias string
+	durability           reparentutil.Durabler
+}
+
+// AnalyzeInstanceSnapshots runs the replication analysis over the given snapshots and
+// returns all the problems found. It doesn't access the backend database or the topo
+// server, so it can be used with snapshots coming from any source.
+func AnalyzeInstanceSnapshots(snapshots []*InstanceSnapshot) []*ReplicationAnalysis {
+	analyses, clusters := analyzeAllInstanceSnapshots(snapshots)
+	return postProcessAnalyses(filterProblems(analyses), clusters)
+}
+
+// analyzeAllInstanceSnapshots computes the analysis of every instance that can be analyzed,
+// including the ones without any problem.
+func analyzeAllInstanceSnapshots(snapshots []*InstanceSnapshot) ([]*ReplicationAnalysis, map[string]*clusterAnalysis) {
+	// The first tablet of a shard decides the shard primary, so we look at primaries first
+	// and at the most recent primary term first.
+	snapshots = slices.Clone(snapshots)
+	slices.SortStableFunc(snapshots, func(x, y *InstanceSnapshot) int {
+		if x.Tablet.GetType() != y.Tablet.GetType() {
+			return int(x.Tablet.GetType()) - int(y.Tablet.GetType())
+		}
+		return y.State.PrimaryTimeStamp.Compare(x.State.PrimaryTimeStamp)
+	})
+
+	externalPrimaries := findExternalPrimaries(snapshots)
+	var analyses []*ReplicationAnalysis
+	clusters := make(map[string]*clusterAnalysis)
+	for _, snapshot := range snapshots {
+		if snapshot.Tablet == nil {
+			continue
+		}
+		if a := analyzeInstanceSnapshot(snapshot, clusters, externalPrimaries); a != nil {
+			analyses = append(analyses, a)
+		}
+	}
+	return analyses, clusters
+}
+
+// filterProblems returns the analyses that found a problem or a structure warning.
+func filterProblems(analyses []*ReplicationAnalysis) []*ReplicationAnalysis {
+	var result []*ReplicationAnalysis
+	for _, a := range analyses {
+		if a.Analysis == NoProblem && len(a.StructureAnalysis) == 0 {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+// findExternalPrimaries returns, by keyspace shard, the alias of the tablet that was promoted
+// outside of Vitess, if any. Such a tablet isn't a primary in the topo, but its MySQL is
+// writable, doesn't replicate, and is either the replication source of the primary of the
+// topo, or has replicas while the primary of the topo is unreachable.
+// The snapshots must be sorted as in AnalyzeInstanceSnapshots.
+func findExternalPrimaries(snapshots []*InstanceSnapshot) map[string]string {
+	topoPrimaries := make(map[string]*ReplicationAnalysis)
+	for _, snapshot := range snapshots {
+		if snapshot.Tablet == nil || snapshot.State.TabletType != topodatapb.TabletType_PRIMARY {
+			continue
+		}
+		keyspaceShard := getKeyspaceShardName(snapshot.State.ClusterDetails.Keyspace, snapshot.State.ClusterDetails.Shard)
+		if topoPrimaries[keyspaceShard] == nil {
+			topoPrimaries[keyspaceShard] = &snapshot.State
+		}
+	}
+
+	externalPrimaries := make(map[string]string)
+	for _, snapshot := range snapshots {
+		a := &snapshot.State
+		if snapshot.Tablet == nil || snapshot.IsInvalid || !topo.IsReplicaType(a.TabletType) ||
+			!a.LastCheckValid || !a.IsPrimary || a.IsReadOnly {
+			continue
+		}
+		keyspaceShard := getKeyspaceShardName(a.ClusterDetails.Keyspace, a.ClusterDetails.Shard)
+		topoPrimary := topoPrimaries[keyspaceShard]
+		if topoPrimary == nil {
+			continue
+		}
+		replicatesFrom := topoPrimary.LastCheckValid && !topoPrimary.IsPrimary && topoPrimary.AnalyzedInstancePrimaryAlias == a.AnalyzedInstanceAlias
+		replacesUnreachable := !topoPrimary.LastCheckValid && a.CountValidReplicatingReplicas > 0
+		if replicatesFrom || replacesUnreachable {
+			externalPrimaries[keyspaceShard] = a.AnalyzedInstanceAlias
+		}
+	}
+	return externalPrimaries
+}
+
+// analyzeInstanceSnapshot computes the analysis of a single instance. It returns nil if
+// the instance can't be analyzed.
+func analyzeInstanceSnapshot(snapshot *InstanceSnapshot, clusters map[string]*clusterAnalysis, externalPrimaries map[string]string) *ReplicationAnalysis {
+	a := snapshot.State
+	a.Analysis = NoProblem
+	a.Description = ""
+	a.StructureAnalysis = nil
+	a.IsClusterPrimary = false
+
+	primaryTablet := snapshot.PrimaryTablet
+	if primaryTablet == nil {
+		primaryTablet = &topodatapb.Tablet{}
+	}
+
+	keyspaceShard := getKeyspaceShardName(a.ClusterDetails.Keyspace, a.ClusterDetails.Shard)
+	if clusters[keyspaceShard] == nil {
+		clusters[keyspaceShard] = &clusterAnalysis{externalPrimaryAlias: externalPrimaries[keyspaceShard]}
+		if a.TabletType == topodatapb.TabletType_PRIMARY {
+			a.IsClusterPrimary = true
+			clusters[keyspaceShard].primaryAlias = a.AnalyzedInstanceAlias
+		}
+		durabilityPolicy := snapshot.DurabilityPolicy
+		if durabilityPolicy == "" {
+			log.Errorf("ignoring keyspace %v because no durability_policy is set. Please set it using SetKeyspaceDurabilityPolicy", a.AnalyzedKeyspace)
+			return nil
+		}
+		durability, err := reparentutil.GetDurabilityPolicy(durabilityPolicy)
+		if err != nil {
+			log.Errorf("can't get the durability policy %v - %v. Skipping keyspace - %v.", durabilityPolicy, err, a.AnalyzedKeyspace)
+			return nil
+		}
+		clusters[keyspaceShard].durability = durability
+	}
+	// ca has clusterwide info
+	ca := clusters[keyspaceShard]
+	// Increment the total number of tablets.
+	ca.totalTablets += 1
+	if ca.durability == nil {
+		// We failed to load the durability policy, so we shouldn't run any analysis
+		return nil
+	}
+	// An external reparent is always analyzed, but it only takes over the shard when VTOrc
+	// reconciles it. Otherwise, the shard would never get out of the analysis, and its other
+	// problems would never be analyzed.
+	reconcile := config.ReconcileExternalReparents()
+	isExternalPrimary := ca.externalPrimaryAlias != "" && a.AnalyzedInstanceAlias == ca.externalPrimaryAlias
+	if reconcile && ca.externalPrimaryAlias != "" && !isExternalPrimary {
+		// Until the topo is reconciled, the other tablets would be repaired against the
+		// primary of the topo, so we don't analyze them.
+		return nil
+	}
+	if ca.hasClusterwideAction && !isExternalPrimary {
+		// We can only take one cluster level action at a time.
+		return nil
+	}
+	if isExternalPrimary {
+		a.Analysis = PrimaryExternallyReparented
+		a.Description = "Tablet was promoted to primary outside of Vitess"
+		ca.hasClusterwideAction = ca.hasClusterwideAction || reconcile
+		//
+	} else if a.IsClusterPrimary && snapshot.IsInvalid {
+		a.Analysis = InvalidPrimary
+		a.Description = "VTOrc hasn't been able to reach the primary even once since restart/shutdown"
+	} else if snapshot.IsInvalid {
+		a.Analysis = InvalidReplica
+		a.Description = "VTOrc hasn't been able to reach the replica even once since restart/shutdown"
+	} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountReplicas == 0 {
+		a.Analysis = DeadPrimaryWithoutReplicas
+		a.Description = "Primary cannot be reached by vtorc and has no replica"
+		ca.hasClusterwideAction = true
+		//
+	} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountValidReplicas == a.CountReplicas && a.CountValidReplicatingReplicas == 0 {
+		a.Analysis = DeadPrimary
+		a.Description = "Primary cannot be reached by vtorc and none of its replicas is replicating"
+		ca.hasClusterwideAction = true
+		//
+	} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountReplicas > 0 && a.CountValidReplicas == 0 && a.CountValidReplicatingReplicas == 0 {
+		a.Analysis = DeadPrimaryAndReplicas
+		a.Description = "Primary cannot be reached by vtorc and none of its replicas is replicating"
+		ca.hasClusterwideAction = true
+		//
+	} else if a.IsClusterPrimary && !a.LastCheckValid && a.CountValidReplicas < a.CountReplicas && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas == 0 {
+		a.Analysis = DeadPrimaryAndSomeReplicas
+		a.Description = "Primary cannot be reached by vtorc; some of its replicas are unreachable and none of its reachable replicas is replicating"
+		ca.hasClusterwideAction = true
+		//
+	} else if a.IsClusterPrimary && !a.IsPrimary {
+		a.Analysis = PrimaryHasPrimary
+		a.Description = "Primary is replicating from somewhere else"
+		ca.hasClusterwideAction = true
+		//
+	} else if a.IsClusterPrimary && a.IsReadOnly {
+		a.Analysis = PrimaryIsReadOnly
+		a.Description = "Primary is read-only"
+		//
+	} else if a.IsClusterPrimary && reparentutil.SemiSyncAckers(ca.durability, snapshot.Tablet) != 0 && !a.SemiSyncPrimaryEnabled {
+		a.Analysis = PrimarySemiSyncMustBeSet
+		a.Description = "Primary semi-sync must be set"
+		//
+	} else if a.IsClusterPrimary && reparentutil.SemiSyncAckers(ca.durability, snapshot.Tablet) == 0 && a.SemiSyncPrimaryEnabled {
+		a.Analysis = PrimarySemiSyncMustNotBeSet
+		a.Description = "Primary semi-sync must not be set"
+		//
+	} else if topo.IsReplicaType(a.TabletType) && a.ErrantGTID != "" {
+		a.Analysis = ErrantGTIDDetected
+		a.Description = "Tablet has errant GTIDs"
+	} else if topo.IsReplicaType(a.TabletType) && ca.primaryAlias == "" && a.ShardPrimaryTermTimestamp == "" {
+		// ClusterHasNoPrimary should only be detected when the shard record doesn't have any primary term start time specified either.
+		a.Analysis = ClusterHasNoPrimary
+		a.Description = "Cluster has no primary"
+		ca.hasClusterwideAction = true
+	} else if topo.IsReplicaType(a.TabletType) && ca.primaryAlias == "" && a.ShardPrimaryTermTimestamp != "" {
+		// If there are no primary tablets, but the shard primary start time isn't empty, then we know
+		// the primary tablet was deleted.
+		a.Analysis = PrimaryTabletDeleted
+		a.Description = "Primary tablet has been deleted"
+		ca.hasClusterwideAction = true
+	} else if topo.IsReplicaType(a.TabletType) && !a.IsReadOnly {
+		a.Analysis = ReplicaIsWritable
+		a.Description = "Replica is writable"
+		//
+	} else if topo.IsReplicaType(a.TabletType) && a.IsPrimary {
+		a.Analysis = NotConnectedToPrimary
+		a.Description = "Not connected to the primary"
+		//
+	} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && ca.primaryAlias != "" && a.AnalyzedInstancePrimaryAlias != ca.primaryAlias {
+		a.Analysis = ConnectedToWrongPrimary
+		a.Description = "Connected to wrong primary"
+		//
+	} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && a.ReplicationStopped {
+		a.Analysis = ReplicationStopped
+		a.Description = "Replication is stopped"
+		//
+	} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && reparentutil.IsReplicaSemiSync(ca.durability, primaryTablet, snapshot.Tablet) && !a.SemiSyncReplicaEnabled {
+		a.Analysis = ReplicaSemiSyncMustBeSet
+		a.Description = "Replica semi-sync must be set"
+		//
+	} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && !reparentutil.IsReplicaSemiSync(ca.durability, primaryTablet, snapshot.Tablet) && a.SemiSyncReplicaEnabled {
+		a.Analysis = ReplicaSemiSyncMustNotBeSet
+		a.Description = "Replica semi-sync must not be set"
+		//
+		// TODO(sougou): Events below here are either ignored or not possible.
+	} else if a.IsPrimary && !a.LastCheckValid && a.CountLaggingReplicas == a.CountReplicas && a.CountDelayedReplicas < a.CountReplicas && a.CountValidReplicatingReplicas > 0 {
+		a.Analysis = UnreachablePrimaryWithLaggingReplicas
+		a.Description = "Primary cannot be reached by vtorc and all of its replicas are lagging"
+		//
+	} else if a.IsPrimary && !a.LastCheckValid && !a.LastCheckPartialSuccess && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas > 0 {
+		// partial success is here to reduce noise
+		a.Analysis = UnreachablePrimary
+		a.Description = "Primary cannot be reached by vtorc but it has replicating replicas; possibly a network/host issue"
+		//
+	} else if a.IsPrimary && !a.LastCheckValid && a.LastCheckPartialSuccess && a.CountReplicasFailingToConnectToPrimary > 0 && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas > 0 {
+		// there's partial success, but also at least one replica is failing to connect to primary
+		a.Analysis = UnreachablePrimary
+		a.Description = "Primary cannot be reached by vtorc but it has replicating replicas; possibly a network/host issue"
+		//
+	} else if a.IsPrimary && a.SemiSyncPrimaryEnabled && a.SemiSyncPrimaryStatus && a.SemiSyncPrimaryWaitForReplicaCount > 0 && a.SemiSyncPrimaryClients < a.SemiSyncPrimaryWaitForReplicaCount {
+		if snapshot.IsStaleBinlogCoordinates {
+			a.Analysis = LockedSemiSyncPrimary
+			a.Description = "Semi sync primary is locked since it doesn't get enough replica acknowledgements"
+		} else {
+			a.Analysis = LockedSemiSyncPrimaryHypothesis
+			a.Description = "Semi sync primary seems to be locked, more samplings needed to validate"
+		}
+		//
+	} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas == 1 && a.CountValidReplicas == a.CountReplicas && a.CountValidReplicatingReplicas == 0 {
+		a.Analysis = PrimarySingleReplicaNotReplicating
+		a.Description = "Primary is reachable but its single replica is not replicating"
+	} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas == 1 && a.CountValidReplicas == 0 {
+		a.Analysis = PrimarySingleReplicaDead
+		a.Description = "Primary is reachable but its single replica is dead"
+		//
+	} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas > 1 && a.CountValidReplicas == a.CountReplicas && a.CountValidReplicatingReplicas == 0 {
+		a.Analysis = AllPrimaryReplicasNotReplicating
+		a.Description = "Primary is reachable but none of its replicas is replicating"
+		//
+	} else if a.IsPrimary && a.LastCheckValid && a.CountReplicas > 1 && a.CountValidReplicas < a.CountReplicas && a.CountValidReplicas > 0 && a.CountValidReplicatingReplicas == 0 {
+		a.Analysis = AllPrimaryReplicasNotReplicatingOrDead
+		a.Description = "Primary is reachable but none of its replicas is replicating"
+		//
+	} else if a.IsBinlogServer && a.IsFailingToConnectToPrimary {
+		a.Analysis = BinlogServerFailingToConnectToPrimary
+		a.Description = "Binlog server is unable to connect to its primary"
+		//
+	}
+	//		 else if a.IsPrimary && a.CountReplicas == 0 {
+	//			a.Analysis = PrimaryWithoutReplicas
+	//			a.Description = "Primary has no replicas"
+	//		}
+
+	{
+		// Moving on to structure analysis
+		// We also do structural checks. See if there's potential danger in promotions
+		if a.IsPrimary && a.CountLoggingReplicas == 0 && a.CountReplicas > 1 {
+			a.StructureAnalysis = append(a.StructureAnalysis, NoLoggingReplicasStructureWarning)
+		}
+		if a.IsPrimary && a.CountReplicas > 1 &&
+			!a.OracleGTIDImmediateTopology &&
+			!a.MariaDBGTIDImmediateTopology &&
+			!a.BinlogServerImmediateTopology {
+			a.StructureAnalysis = append(a.StructureAnalysis, NoFailoverSupportStructureWarning)
+		}
+		if a.IsPrimary && a.CountStatementBasedLoggingReplicas > 0 && a.CountMixedBasedLoggingReplicas > 0 {
+			a.StructureAnalysis = append(a.StructureAnalysis, StatementAndMixedLoggingReplicasStructureWarning)
+		}
+		if a.IsPrimary && a.CountStatementBasedLoggingReplicas > 0 && a.CountRowBasedLoggingReplicas > 0 {
+			a.StructureAnalysis = append(a.StructureAnalysis, StatementAndRowLoggingReplicasStructureWarning)
+		}
+		if a.IsPrimary && a.CountMixedBasedLoggingReplicas > 0 && a.CountRowBasedLoggingReplicas > 0 {
+			a.StructureAnalysis = append(a.StructureAnalysis, MixedAndRowLoggingReplicasStructureWarning)
+		}
+		if a.IsPrimary && a.CountDistinctMajorVersionsLoggingReplicas > 1 {
+			a.StructureAnalysis = append(a.StructureAnalysis, MultipleMajorVersionsLoggingReplicasStructureWarning)
+		}
+
+		if a.CountReplicas > 0 && (a.GTIDMode != a.MinReplicaGTIDMode || a.GTIDMode != a.MaxReplicaGTIDMode) {
+			a.StructureAnalysis = append(a.StructureAnalysis, DifferentGTIDModesStructureWarning)
+		}
+		if a.MaxReplicaGTIDErrant != "" {
+			a.StructureAnalysis = append(a.StructureAnalysis, ErrantGTIDStructureWarning)
+		}
+
+		if a.IsPrimary && a.IsReadOnly {
+			a.StructureAnalysis = append(a.StructureAnalysis, NoWriteablePrimaryStructureWarning)
+		}
+
+		if a.IsPrimary && a.SemiSyncPrimaryEnabled && !a.SemiSyncPrimaryStatus && a.SemiSyncPrimaryWaitForReplicaCount > 0 && a.SemiSyncPrimaryClients < a.SemiSyncPrimaryWaitForReplicaCount {
+			a.StructureAnalysis = append(a.StructureAnalysis, NotEnoughValidSemiSyncReplicasStructureWarning)
+		}
+	}
+	return &a
+}
+
+// postProcessAnalyses is used to update different analyses based on the information gleaned from looking at all the analyses together instead of individual data.
+func postProcessAnalyses(result []*ReplicationAnalysis, clusters map[string]*clusterAnalysis) []*ReplicationAnalysis {
+	for {
+		// Store whether we have changed the result of replication analysis or not.
+		resultChanged := false
+
+		// Go over all the analyses.
+		for _, analysis := range result {
+			// If one of them is an InvalidPrimary, then we see if all the other tablets in this keyspace shard are
+			// unable to replicate or not.
+			if analysis.Analysis == InvalidPrimary {
+				keyspaceName := analysis.ClusterDetails.Keyspace
+				shardName := analysis.ClusterDetails.Shard
+				keyspaceShard := getKeyspaceShardName(keyspaceName, shardName)
+				totalReplicas := clusters[keyspaceShard].totalTablets - 1
+				var notReplicatingReplicas []int
+				for idx, replicaAnalysis := range result {
+					if replicaAnalysis.ClusterDetails.Keyspace == keyspaceName &&
+						replicaAnalysis.ClusterDetails.Shard == shardName && topo.IsReplicaType(replicaAnalysis.TabletType) {
+						// If the replica's last check is invalid or its replication is stopped, then we consider as not replicating.
+						if !replicaAnalysis.LastCheckValid || replicaAnalysis.ReplicationStopped {
+							notReplicatingReplicas = append(notReplicatingReplicas, idx)
+						}
+					}
+				}
+				// If none of the other tablets are able to replicate, then we conclude that this primary is not just Invalid, but also Dead.
+				// In this case, we update the analysis for the primary tablet and remove all the analyses of the replicas.
+				if totalReplicas > 0 && len(notReplicatingReplicas) == totalReplicas {
+					resultChanged = true
+					analysis.Analysis = DeadPrimary
+					for i := len(notReplicatingReplicas) - 1; i >= 0; i-- {
+						idxToRemove := notReplicatingReplicas[i]
+						result = append(result[0:idxToRemove], result[idxToRemove+1:]...)
+					}
+					break
+				}
+			}
+		}
+		if !resultChanged {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inst
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+func newTestSnapshot(uid uint32, tabletType topodatapb.TabletType, durability string, state ReplicationAnalysis) *InstanceSnapshot {
+	tablet := &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: uid},
+		Keyspace: "ks",
+		Shard:    "0",
+		Type:     tabletType,
+	}
+	state.TabletType = tabletType
+	state.AnalyzedInstanceAlias = topoproto.TabletAliasString(tablet.Alias)
+	state.AnalyzedKeyspace = "ks"
+	state.AnalyzedShard = "0"
+	state.ClusterDetails = ClusterInfo{Keyspace: "ks", Shard: "0"}
+	return &InstanceSnapshot{
+		Tablet:           tablet,
+		DurabilityPolicy: durability,
+		State:            state,
+	}
+}
+
+func TestAnalyzeInstanceSnapshots(t *testing.T) {
+	tests := []struct {
+		name                       string
+		reconcileExternalReparents bool
+		snapshots                  []*InstanceSnapshot
+		want                       map[string]AnalysisCode
+	}{
+		{
+			name: "healthy shard",
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "none", ReplicationAnalysis{
+					IsPrimary:                     true,
+					LastCheckValid:                true,
+					CountReplicas:                 1,
+					CountValidReplicas:            1,
+					CountValidReplicatingReplicas: 1,
+				}),
+				newTestSnapshot(101, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000100",
+				}),
+			},
+			want: map[string]AnalysisCode{},
+		}, {
+			name: "dead primary",
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(101, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					ReplicationStopped:           true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000100",
+				}),
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "none", ReplicationAnalysis{
+					IsPrimary:          true,
+					CountReplicas:      1,
+					CountValidReplicas: 1,
+				}),
+			},
+			want: map[string]AnalysisCode{
+				"zone1-0000000100": DeadPrimary,
+			},
+		}, {
+			name: "replication stopped",
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "none", ReplicationAnalysis{
+					IsPrimary:                     true,
+					LastCheckValid:                true,
+					CountReplicas:                 2,
+					CountValidReplicas:            2,
+					CountValidReplicatingReplicas: 1,
+					CountLoggingReplicas:          2,
+					OracleGTIDImmediateTopology:   true,
+				}),
+				newTestSnapshot(101, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					ReplicationStopped:           true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000100",
+				}),
+			},
+			want: map[string]AnalysisCode{
+				"zone1-0000000101": ReplicationStopped,
+			},
+		}, {
+			name:                       "externally reparented",
+			reconcileExternalReparents: true,
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000101",
+				}),
+				newTestSnapshot(101, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					IsPrimary:                     true,
+					LastCheckValid:                true,
+					CountReplicas:                 2,
+					CountValidReplicas:            2,
+					CountValidReplicatingReplicas: 2,
+				}),
+				newTestSnapshot(102, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000101",
+				}),
+			},
+			want: map[string]AnalysisCode{
+				"zone1-0000000101": PrimaryExternallyReparented,
+			},
+		}, {
+			name: "externally reparented without reconciliation",
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000101",
+				}),
+				newTestSnapshot(101, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					IsPrimary:                     true,
+					LastCheckValid:                true,
+					CountReplicas:                 2,
+					CountValidReplicas:            2,
+					CountValidReplicatingReplicas: 2,
+				}),
+				newTestSnapshot(102, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000101",
+				}),
+			},
+			want: map[string]AnalysisCode{
+				"zone1-0000000100": PrimaryHasPrimary,
+				"zone1-0000000101": PrimaryExternallyReparented,
+			},
+		}, {
+			name:                       "externally reparented with unreachable primary",
+			reconcileExternalReparents: true,
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "none", ReplicationAnalysis{
+					IsPrimary: true,
+				}),
+				newTestSnapshot(101, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					IsPrimary:                     true,
+					LastCheckValid:                true,
+					CountReplicas:                 1,
+					CountValidReplicas:            1,
+					CountValidReplicatingReplicas: 1,
+				}),
+				newTestSnapshot(102, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					LastCheckValid:               true,
+					IsReadOnly:                   true,
+					AnalyzedInstancePrimaryAlias: "zone1-0000000101",
+				}),
+			},
+			want: map[string]AnalysisCode{
+				"zone1-0000000101": PrimaryExternallyReparented,
+			},
+		}, {
+			name: "writable replica without replicas",
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "none", ReplicationAnalysis{
+					IsPrimary:      true,
+					LastCheckValid: true,
+					CountReplicas:  1,
+				}),
+				newTestSnapshot(101, topodatapb.TabletType_REPLICA, "none", ReplicationAnalysis{
+					IsPrimary:      true,
+					LastCheckValid: true,
+				}),
+			},
+			want: map[string]AnalysisCode{
+				"zone1-0000000100": PrimarySingleReplicaDead,
+				"zone1-0000000101": ReplicaIsWritable,
+			},
+		}, {
+			name: "no durability policy",
+			snapshots: []*InstanceSnapshot{
+				newTestSnapshot(100, topodatapb.TabletType_PRIMARY, "", ReplicationAnalysis{
+					IsPrimary: true,
+				}),
+			},
+			want: map[string]AnalysisCode{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldReconcile := config.ReconcileExternalReparents()
+			config.SetReconcileExternalReparents(tt.reconcileExternalReparents)
+			defer config.SetReconcileExternalReparents(oldReconcile)
+
+			got := make(map[string]AnalysisCode)
+			for _, analysis := range AnalyzeInstanceSnapshots(tt.snapshots) {
+				got[analysis.AnalyzedInstanceAlias] = analysis.Analysis
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
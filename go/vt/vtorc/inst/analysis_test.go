@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inst
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+func TestReplicationAnalysisMarshalJSONRunbook(t *testing.T) {
+	oldRunbooks := config.Config.Runbooks
+	defer func() {
+		config.Config.Runbooks = oldRunbooks
+	}()
+	config.Config.Runbooks = map[string]config.Runbook{
+		string(DeadPrimary): {URL: "https://runbooks.example.com/dead-primary", Content: "Check the primary's host."},
+	}
+
+	unmarshal := func(analysis *ReplicationAnalysis) map[string]any {
+		b, err := json.Marshal(analysis)
+		require.NoError(t, err)
+		var fields map[string]any
+		require.NoError(t, json.Unmarshal(b, &fields))
+		return fields
+	}
+
+	fields := unmarshal(&ReplicationAnalysis{AnalyzedInstanceAlias: "zone1-0000000100", Analysis: DeadPrimary})
+	require.Equal(t, "zone1-0000000100", fields["AnalyzedInstanceAlias"])
+	require.Equal(t, map[string]any{
+		"URL":     "https://runbooks.example.com/dead-primary",
+		"Content": "Check the primary's host.",
+	}, fields["Runbook"])
+
+	fields = unmarshal(&ReplicationAnalysis{AnalyzedInstanceAlias: "zone1-0000000100", Analysis: ReplicationStopped})
+	require.NotContains(t, fields, "Runbook")
+}
@@ -17,78 +17,153 @@
 package inst
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/rcrowley/go-metrics"
 
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/vtorc/config"
 	"vitess.io/vitess/go/vt/vtorc/db"
 )
 
-var auditOperationCounter = metrics.NewCounter()
+var (
+	auditOperationCounter = metrics.NewCounter()
+	auditDroppedCounter   = metrics.NewCounter()
+)
 
 func init() {
 	_ = metrics.Register("audit.write", auditOperationCounter)
+	_ = metrics.Register("audit.dropped", auditDroppedCounter)
+}
+
+// AuditEvent is a single audited operation.
+type AuditEvent struct {
+	AuditID     int64     `json:"audit_id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+	AuditType   string    `json:"audit_type"`
+	TabletAlias string    `json:"alias"`
+	Keyspace    string    `json:"keyspace"`
+	Shard       string    `json:"shard"`
+	Message     string    `json:"message"`
+}
+
+// String returns the audit event in the free-text audit log format.
+func (event *AuditEvent) String() string {
+	return fmt.Sprintf("%s\t%s\t%s\t[%s:%s]\t%s\t", event.Timestamp.Format("2006-01-02 15:04:05"), event.AuditType, event.TabletAlias, event.Keyspace, event.Shard, event.Message)
 }
 
 // AuditOperation creates and writes a new audit entry by given params
 func AuditOperation(auditType string, tabletAlias string, message string) error {
-	keyspace := ""
-	shard := ""
+	event := &AuditEvent{
+		Timestamp:   time.Now(),
+		AuditType:   auditType,
+		TabletAlias: tabletAlias,
+		Message:     message,
+	}
 	if tabletAlias != "" {
-		keyspace, shard, _ = GetKeyspaceShardName(tabletAlias)
+		event.Keyspace, event.Shard, _ = GetKeyspaceShardName(tabletAlias)
+	}
+	if !allowAuditEvent(event.Timestamp) {
+		auditDroppedCounter.Inc(1)
+		return nil
 	}
 
-	auditWrittenToFile := false
-	if config.Config.AuditLogFile != "" {
-		auditWrittenToFile = true
-		go func() {
-			f, err := os.OpenFile(config.Config.AuditLogFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0640)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-
-			defer f.Close()
-			text := fmt.Sprintf("%s\t%s\t%s\t[%s:%s]\t%s\t\n", time.Now().Format("2006-01-02 15:04:05"), auditType, tabletAlias, keyspace, shard, message)
-			if _, err = f.WriteString(text); err != nil {
-				log.Error(err)
-			}
-		}()
+	var errs []error
+	logged := false
+	for _, sink := range auditSinks() {
+		if err := sink.WriteAuditEvent(event); err != nil {
+			log.Errorf("Error writing audit event to %s: %v", sink.Name(), err)
+			errs = append(errs, err)
+		}
+		if sink.Name() != backendAuditSinkName {
+			logged = true
+		}
 	}
-	if config.Config.AuditToBackendDB {
-		_, err := db.ExecVTOrc(`
-			insert
-				into audit (
-					audit_timestamp, audit_type, alias, keyspace, shard, message
-				) VALUES (
-					NOW(), ?, ?, ?, ?, ?
-				)
-			`,
-			auditType,
-			tabletAlias,
-			keyspace,
-			shard,
-			message,
-		)
-		if err != nil {
-			log.Error(err)
-			return err
+	if !logged {
+		log.Infof("auditType:%s alias:%s keyspace:%s shard:%s message:%s", event.AuditType, event.TabletAlias, event.Keyspace, event.Shard, event.Message)
+	}
+	auditOperationCounter.Inc(1)
+
+	return errors.Join(errs...)
+}
+
+// AuditFilter filters the audit events read from the backend database.
+// Empty fields do not filter anything.
+type AuditFilter struct {
+	TabletAlias string
+	Keyspace    string
+	Shard       string
+	AuditType   string
+	// Limit is the maximum number of events returned. config.AuditPageSize is used when it is 0.
+	Limit int
+	// Page is the page of events to return, in pages of Limit events.
+	Page int
+}
+
+// ReadAuditEvents returns the audit events matching the filter from the backend database, most recent first.
+func ReadAuditEvents(filter *AuditFilter) ([]*AuditEvent, error) {
+	var conditions []string
+	var args []any
+	addCondition := func(column, value string) {
+		if value != "" {
+			conditions = append(conditions, column+" = ?")
+			args = append(args, value)
 		}
 	}
-	logMessage := fmt.Sprintf("auditType:%s alias:%s keyspace:%s shard:%s message:%s", auditType, tabletAlias, keyspace, shard, message)
-	if syslogMessage(logMessage) {
-		auditWrittenToFile = true
+	addCondition("alias", filter.TabletAlias)
+	addCondition("keyspace", filter.Keyspace)
+	addCondition("shard", filter.Shard)
+	addCondition("audit_type", filter.AuditType)
+	whereCondition := ""
+	if len(conditions) > 0 {
+		whereCondition = "where " + strings.Join(conditions, " and ")
 	}
-	if !auditWrittenToFile {
-		log.Infof(logMessage)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = config.AuditPageSize
 	}
-	auditOperationCounter.Inc(1)
+	query := fmt.Sprintf(`
+		select
+			audit_id,
+			audit_timestamp,
+			audit_type,
+			alias,
+			keyspace,
+			shard,
+			message
+		from
+			audit
+		%s
+		order by
+			audit_timestamp desc, audit_id desc
+		limit ?
+		offset ?
+		`, whereCondition)
+	args = append(args, limit, filter.Page*limit)
 
-	return nil
+	var events []*AuditEvent
+	err := db.QueryVTOrc(query, args, func(m sqlutils.RowMap) error {
+		// The SQLite driver returns timestamps in the RFC 3339 format.
+		timestamp, err := time.Parse(time.RFC3339, m.GetString("audit_timestamp"))
+		if err != nil {
+			timestamp = m.GetTime("audit_timestamp")
+		}
+		events = append(events, &AuditEvent{
+			AuditID:     m.GetInt64("audit_id"),
+			Timestamp:   timestamp,
+			AuditType:   m.GetString("audit_type"),
+			TabletAlias: m.GetString("alias"),
+			Keyspace:    m.GetString("keyspace"),
+			Shard:       m.GetString("shard"),
+			Message:     m.GetString("message"),
+		})
+		return nil
+	})
+	return events, err
 }
 
 // ExpireAudit removes old rows from the audit table
@@ -27,6 +27,11 @@ func EnableAuditSyslog() (err error) {
 	return errors.New("syslog is not supported on windows")
 }
 
+// auditSyslogEnabled returns whether writes to syslog are enabled.
+func auditSyslogEnabled() bool {
+	return false
+}
+
 func syslogMessage(logMessage string) bool {
 	return false
 }
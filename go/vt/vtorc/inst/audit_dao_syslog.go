@@ -18,26 +18,48 @@
 
 package inst
 
-import "log/syslog"
+import (
+	"log/syslog"
+	"sync"
+)
 
-// syslogWriter is optional, and defaults to nil (disabled)
-var syslogWriter *syslog.Writer
+var (
+	syslogWriterMu sync.Mutex
+	// syslogWriter is optional, and defaults to nil (disabled)
+	syslogWriter *syslog.Writer
+)
 
 // EnableAuditSyslog enables, if possible, writes to syslog. These will execute _in addition_ to normal logging
-func EnableAuditSyslog() (err error) {
-	syslogWriter, err = syslog.New(syslog.LOG_ERR, "vtorc")
+func EnableAuditSyslog() error {
+	writer, err := syslog.New(syslog.LOG_ERR, "vtorc")
 	if err != nil {
-		syslogWriter = nil
+		return err
 	}
-	return err
+	syslogWriterMu.Lock()
+	defer syslogWriterMu.Unlock()
+	if syslogWriter != nil {
+		_ = syslogWriter.Close()
+	}
+	syslogWriter = writer
+	return nil
+}
+
+// auditSyslogEnabled returns whether writes to syslog are enabled.
+func auditSyslogEnabled() bool {
+	syslogWriterMu.Lock()
+	defer syslogWriterMu.Unlock()
+	return syslogWriter != nil
 }
 
 func syslogMessage(logMessage string) bool {
-	if syslogWriter == nil {
+	syslogWriterMu.Lock()
+	writer := syslogWriter
+	syslogWriterMu.Unlock()
+	if writer == nil {
 		return false
 	}
 	go func() {
-		_ = syslogWriter.Info(logMessage)
+		_ = writer.Info(logMessage)
 	}()
 	return true
 }
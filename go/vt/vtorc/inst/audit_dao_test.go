@@ -17,6 +17,7 @@ limitations under the License.
 package inst
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"testing"
@@ -127,6 +128,122 @@ func TestAuditOperation(t *testing.T) {
 	})
 }
 
+// recordingAuditSink is an AuditSink that keeps the events it is given.
+type recordingAuditSink struct {
+	events []*AuditEvent
+}
+
+func (sink *recordingAuditSink) Name() string {
+	return "recording"
+}
+
+func (sink *recordingAuditSink) WriteAuditEvent(event *AuditEvent) error {
+	sink.events = append(sink.events, event)
+	return nil
+}
+
+// TestAuditSinks tests the structured audit events, the registered sinks and the rate limit.
+func TestAuditSinks(t *testing.T) {
+	originalConfig := *config.Config
+	defer func() {
+		*config.Config = originalConfig
+		registeredAuditSinks = nil
+		auditLimiter = nil
+	}()
+	config.Config.AuditToSyslog = false
+	config.Config.AuditToBackendDB = false
+
+	sink := &recordingAuditSink{}
+	RegisterAuditSink(sink)
+
+	t.Run("json file", func(t *testing.T) {
+		file, err := os.CreateTemp("", "test-auditing-*")
+		require.NoError(t, err)
+		defer os.Remove(file.Name())
+		config.Config.AuditLogFile = file.Name()
+		config.Config.AuditFormat = config.AuditFormatJSON
+		defer func() {
+			config.Config.AuditLogFile = ""
+		}()
+
+		err = AuditOperation("test-audit-json", "", "test-message")
+		require.NoError(t, err)
+
+		// The write happens in a separate go-routine.
+		var event AuditEvent
+		require.Eventually(t, func() bool {
+			fileContent, err := os.ReadFile(file.Name())
+			return err == nil && json.Unmarshal(fileContent, &event) == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, "test-audit-json", event.AuditType)
+		require.Equal(t, "test-message", event.Message)
+	})
+
+	t.Run("registered sink", func(t *testing.T) {
+		sink.events = nil
+		err := AuditOperation("test-audit-sink", "zone-1-0000000100", "test-message")
+		require.NoError(t, err)
+		require.Len(t, sink.events, 1)
+		require.Equal(t, "test-audit-sink", sink.events[0].AuditType)
+		require.Equal(t, "zone-1-0000000100", sink.events[0].TabletAlias)
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		sink.events = nil
+		config.Config.AuditRateLimit = 2
+		defer func() {
+			config.Config.AuditRateLimit = 0
+		}()
+		for i := 0; i < 5; i++ {
+			require.NoError(t, AuditOperation("test-audit-rate-limit", "", fmt.Sprintf("message %d", i)))
+		}
+		require.Len(t, sink.events, 2)
+	})
+}
+
+// TestReadAuditEvents tests that the audit events can be read from the database with filters.
+func TestReadAuditEvents(t *testing.T) {
+	originalAuditBackend := config.Config.AuditToBackendDB
+	defer func() {
+		config.Config.AuditToBackendDB = originalAuditBackend
+	}()
+	config.Config.AuditToBackendDB = true
+
+	orcDb, err := db.OpenVTOrc()
+	require.NoError(t, err)
+	defer func() {
+		_, err = orcDb.Exec("delete from audit")
+		require.NoError(t, err)
+	}()
+
+	for _, event := range []*AuditEvent{
+		{AuditType: "recover", TabletAlias: "zone-1-0000000100", Keyspace: "ks", Shard: "-80", Message: "first"},
+		{AuditType: "recover", TabletAlias: "zone-1-0000000200", Keyspace: "ks", Shard: "80-", Message: "second"},
+		{AuditType: "discover", TabletAlias: "zone-1-0000000100", Keyspace: "ks", Shard: "-80", Message: "third"},
+	} {
+		require.NoError(t, backendAuditSink{}.WriteAuditEvent(event))
+	}
+
+	messages := func(filter *AuditFilter) []string {
+		events, err := ReadAuditEvents(filter)
+		require.NoError(t, err)
+		var messages []string
+		for _, event := range events {
+			messages = append(messages, event.Message)
+		}
+		return messages
+	}
+	require.Equal(t, []string{"third", "second", "first"}, messages(&AuditFilter{}))
+	require.Equal(t, []string{"second", "first"}, messages(&AuditFilter{AuditType: "recover"}))
+	require.Equal(t, []string{"third", "first"}, messages(&AuditFilter{TabletAlias: "zone-1-0000000100"}))
+	require.Equal(t, []string{"second"}, messages(&AuditFilter{Keyspace: "ks", Shard: "80-"}))
+	require.Equal(t, []string{"second"}, messages(&AuditFilter{Limit: 1, Page: 1}))
+
+	events, err := ReadAuditEvents(&AuditFilter{Limit: 1})
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), events[0].Timestamp, time.Minute)
+}
+
 // audit presents a single audit entry (namely in the database)
 type audit struct {
 	AuditID          int64
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inst
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+)
+
+const (
+	backendAuditSinkName = "backend"
+	fileAuditSinkName    = "file"
+	syslogAuditSinkName  = "syslog"
+	httpAuditSinkName    = "http"
+
+	// auditHTTPTimeout is the timeout of the requests posting audit events.
+	auditHTTPTimeout = 10 * time.Second
+)
+
+// AuditSink is a destination of audit events.
+type AuditSink interface {
+	// Name identifies the sink in logs.
+	Name() string
+	// WriteAuditEvent writes the audit event. Sinks that are slow to write
+	// should write asynchronously, since audits are on the recovery path.
+	WriteAuditEvent(event *AuditEvent) error
+}
+
+var (
+	registeredAuditSinksMu sync.Mutex
+	registeredAuditSinks   []AuditSink
+)
+
+// RegisterAuditSink registers a sink that receives all the audit events, in
+// addition to the sinks enabled by the configuration.
+func RegisterAuditSink(sink AuditSink) {
+	registeredAuditSinksMu.Lock()
+	defer registeredAuditSinksMu.Unlock()
+	registeredAuditSinks = append(registeredAuditSinks, sink)
+}
+
+// auditSinks returns the sinks enabled by the configuration, followed by the registered sinks.
+func auditSinks() []AuditSink {
+	var sinks []AuditSink
+	if config.Config.AuditToBackendDB {
+		sinks = append(sinks, backendAuditSink{})
+	}
+	if config.Config.AuditLogFile != "" {
+		sinks = append(sinks, fileAuditSink{path: config.Config.AuditLogFile, format: config.Config.AuditFormat})
+	}
+	if config.Config.AuditToSyslog && enableSyslogOnce() {
+		sinks = append(sinks, syslogAuditSink{format: config.Config.AuditFormat})
+	}
+	if config.Config.AuditHTTPURL != "" {
+		sinks = append(sinks, httpAuditSink{url: config.Config.AuditHTTPURL})
+	}
+
+	registeredAuditSinksMu.Lock()
+	defer registeredAuditSinksMu.Unlock()
+	return append(sinks, registeredAuditSinks...)
+}
+
+// formatAuditEvent formats the audit event as a single line in the given format.
+func formatAuditEvent(event *AuditEvent, format string) (string, error) {
+	if format == config.AuditFormatJSON {
+		buf, err := json.Marshal(event)
+		if err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+	return event.String(), nil
+}
+
+// backendAuditSink writes audit events to the audit table of the backend database.
+type backendAuditSink struct{}
+
+func (backendAuditSink) Name() string {
+	return backendAuditSinkName
+}
+
+func (backendAuditSink) WriteAuditEvent(event *AuditEvent) error {
+	_, err := db.ExecVTOrc(`
+		insert
+			into audit (
+				audit_timestamp, audit_type, alias, keyspace, shard, message
+			) VALUES (
+				NOW(), ?, ?, ?, ?, ?
+			)
+		`,
+		event.AuditType,
+		event.TabletAlias,
+		event.Keyspace,
+		event.Shard,
+		event.Message,
+	)
+	return err
+}
+
+// fileAuditSink appends audit events to a file, one per line.
+type fileAuditSink struct {
+	path   string
+	format string
+}
+
+func (fileAuditSink) Name() string {
+	return fileAuditSinkName
+}
+
+func (sink fileAuditSink) WriteAuditEvent(event *AuditEvent) error {
+	line, err := formatAuditEvent(event, sink.format)
+	if err != nil {
+		return err
+	}
+	go func() {
+		f, err := os.OpenFile(sink.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0640)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		defer f.Close()
+		if _, err = f.WriteString(line + "\n"); err != nil {
+			log.Error(err)
+		}
+	}()
+	return nil
+}
+
+var syslogOnce sync.Once
+
+// enableSyslogOnce enables the writes to syslog the first time it is called,
+// unless they were already enabled at startup, and returns whether syslog is
+// available.
+func enableSyslogOnce() bool {
+	syslogOnce.Do(func() {
+		if auditSyslogEnabled() {
+			return
+		}
+		if err := EnableAuditSyslog(); err != nil {
+			log.Errorf("Cannot write the audit log to syslog: %v", err)
+		}
+	})
+	return auditSyslogEnabled()
+}
+
+// syslogAuditSink writes audit events to syslog.
+type syslogAuditSink struct {
+	format string
+}
+
+func (syslogAuditSink) Name() string {
+	return syslogAuditSinkName
+}
+
+func (sink syslogAuditSink) WriteAuditEvent(event *AuditEvent) error {
+	message := fmt.Sprintf("auditType:%s alias:%s keyspace:%s shard:%s message:%s", event.AuditType, event.TabletAlias, event.Keyspace, event.Shard, event.Message)
+	if sink.format == config.AuditFormatJSON {
+		var err error
+		if message, err = formatAuditEvent(event, sink.format); err != nil {
+			return err
+		}
+	}
+	syslogMessage(message)
+	return nil
+}
+
+// httpAuditSink posts audit events as JSON to a URL.
+type httpAuditSink struct {
+	url string
+}
+
+var auditHTTPClient = &http.Client{Timeout: auditHTTPTimeout}
+
+func (httpAuditSink) Name() string {
+	return httpAuditSinkName
+}
+
+func (sink httpAuditSink) WriteAuditEvent(event *AuditEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	go func() {
+		resp, err := auditHTTPClient.Post(sink.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Errorf("Error posting audit event to %s: %v", sink.url, err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			log.Errorf("Error posting audit event to %s: %s", sink.url, resp.Status)
+		}
+	}()
+	return nil
+}
+
+var (
+	auditLimiterMu sync.Mutex
+	auditLimiter   *rate.Limiter
+)
+
+// allowAuditEvent returns whether an audit event can be written at the given
+// time, according to the configured rate limit.
+func allowAuditEvent(now time.Time) bool {
+	limit := config.Config.AuditRateLimit
+	if limit <= 0 {
+		return true
+	}
+	auditLimiterMu.Lock()
+	defer auditLimiterMu.Unlock()
+	if auditLimiter == nil || auditLimiter.Burst() != limit {
+		auditLimiter = rate.NewLimiter(rate.Limit(limit), limit)
+	}
+	return auditLimiter.AllowN(now, 1)
+}
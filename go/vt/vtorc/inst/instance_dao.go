@@ -727,6 +727,35 @@ func ReadOutdatedInstanceKeys() ([]string, error) {
 	return res, err
 }
 
+// ReadAllInstanceKeys reads and returns keys for all the known instances, i.e. all the tablets
+// read from the topo server as well as the instances already discovered, skipping the ones
+// that are set to be forgotten.
+func ReadAllInstanceKeys() ([]string, error) {
+	var res []string
+	query := `
+		SELECT
+			alias
+		FROM
+			vitess_tablet
+		UNION
+		SELECT
+			alias
+		FROM
+			database_instance
+		`
+	err := db.QueryVTOrc(query, nil, func(m sqlutils.RowMap) error {
+		tabletAlias := m.GetString("alias")
+		if !InstanceIsForgotten(tabletAlias) {
+			res = append(res, tabletAlias)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+	return res, err
+}
+
 func mkInsertOdku(table string, columns []string, values []string, nrRows int, insertIgnore bool) (string, error) {
 	if len(columns) == 0 {
 		return "", errors.New("Column list cannot be empty")
@@ -1072,6 +1101,45 @@ func ForgetInstance(tabletAlias string) error {
 	return nil
 }
 
+// ForgetKeyspaceTablets forgets all the tablets of the given keyspace, so that
+// they are not analyzed anymore. It is used when this VTOrc stops owning the keyspace,
+// or when the discovery of the keyspace is disabled.
+func ForgetKeyspaceTablets(keyspace string) error {
+	// Delete from the 'database_instance' table first, since the tablets of the
+	// keyspace are found in the 'vitess_tablet' table.
+	_, err := db.ExecVTOrc(`
+			delete
+				from database_instance
+			where
+				alias in (select alias from vitess_tablet where keyspace = ?)`,
+		keyspace,
+	)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	sqlResult, err := db.ExecVTOrc(`
+			delete
+				from vitess_tablet
+			where
+				keyspace = ?`,
+		keyspace,
+	)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	rows, err := sqlResult.RowsAffected()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if rows > 0 {
+		_ = AuditOperation("forget-keyspace", "", fmt.Sprintf("Forgotten tablets of keyspace %v: %d", keyspace, rows))
+	}
+	return nil
+}
+
 // ForgetLongUnseenInstances will remove entries of all instances that have long since been last seen.
 func ForgetLongUnseenInstances() error {
 	sqlResult, err := db.ExecVTOrc(`
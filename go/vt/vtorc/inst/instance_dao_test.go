@@ -499,6 +499,32 @@ from database_instance`, func(rowMap sqlutils.RowMap) error {
 	}
 }
 
+func TestReadAllInstanceKeys(t *testing.T) {
+	// wait for the forgetAliases cache to be initialized to prevent data race.
+	waitForCacheInitialization()
+
+	oldCache := forgetAliases
+	defer func() {
+		forgetAliases = oldCache
+		db.ClearVTOrcDatabase()
+	}()
+	forgetAliases = cache.New(time.Minute, time.Minute)
+
+	for _, query := range append(initialSQL,
+		"update database_instance set last_checked = now()",
+		`INSERT INTO vitess_tablet VALUES('zone1-0000000103','localhost',7706,'ks','0','zone1',2,'0001-01-01 00:00:00+00:00','');`,
+	) {
+		_, err := db.ExecVTOrc(query)
+		require.NoError(t, err)
+	}
+	forgetAliases.Set("zone2-0000000200", true, cache.DefaultExpiration)
+
+	// Up to date instances and tablets without mysql data are read, but not the forgotten ones.
+	tabletAliases, err := ReadAllInstanceKeys()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"zone1-0000000100", "zone1-0000000101", "zone1-0000000112", "zone1-0000000103"}, tabletAliases)
+}
+
 // TestUpdateInstanceLastChecked is used to test the functionality of UpdateInstanceLastChecked and verify its failure modes and successes.
 func TestUpdateInstanceLastChecked(t *testing.T) {
 	tests := []struct {
@@ -664,6 +690,38 @@ func TestForgetInstanceAndInstanceIsForgotten(t *testing.T) {
 	}
 }
 
+func TestForgetKeyspaceTablets(t *testing.T) {
+	// Clear the database after the test. The easiest way to do that is to run all the initialization commands again.
+	defer func() {
+		db.ClearVTOrcDatabase()
+	}()
+
+	for _, query := range initialSQL {
+		_, err := db.ExecVTOrc(query)
+		require.NoError(t, err)
+	}
+
+	readAliases := func(table string) []string {
+		var aliases []string
+		err := db.QueryVTOrc("select alias from "+table+" order by alias", nil, func(row sqlutils.RowMap) error {
+			aliases = append(aliases, row.GetString("alias"))
+			return nil
+		})
+		require.NoError(t, err)
+		return aliases
+	}
+	allTablets := []string{"zone1-0000000100", "zone1-0000000101", "zone1-0000000112", "zone2-0000000200"}
+
+	// Forgetting another keyspace keeps the tablets.
+	require.NoError(t, ForgetKeyspaceTablets("other"))
+	require.Equal(t, allTablets, readAliases("vitess_tablet"))
+	require.Equal(t, allTablets, readAliases("database_instance"))
+
+	require.NoError(t, ForgetKeyspaceTablets("ks"))
+	require.Empty(t, readAliases("vitess_tablet"))
+	require.Empty(t, readAliases("database_instance"))
+}
+
 func TestSnapshotTopologies(t *testing.T) {
 	// Clear the database after the test. The easiest way to do that is to run all the initialization commands again.
 	defer func() {
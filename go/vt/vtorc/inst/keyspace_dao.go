@@ -38,7 +38,9 @@ func ReadKeyspace(keyspaceName string) (*topo.KeyspaceInfo, error) {
 	query := `
 		select
 			keyspace_type,
-			durability_policy
+			durability_policy,
+			disable_discovery,
+			disable_recovery
 		from
 			vitess_keyspace
 		where keyspace=?
@@ -50,6 +52,12 @@ func ReadKeyspace(keyspaceName string) (*topo.KeyspaceInfo, error) {
 	err := db.QueryVTOrc(query, args, func(row sqlutils.RowMap) error {
 		keyspace.KeyspaceType = topodatapb.KeyspaceType(row.GetInt32("keyspace_type"))
 		keyspace.DurabilityPolicy = row.GetString("durability_policy")
+		if row.GetBool("disable_discovery") || row.GetBool("disable_recovery") {
+			keyspace.VtorcConfig = &topodatapb.VtorcConfig{
+				DisableDiscovery: row.GetBool("disable_discovery"),
+				DisableRecovery:  row.GetBool("disable_recovery"),
+			}
+		}
 		keyspace.SetKeyspaceName(keyspaceName)
 		return nil
 	})
@@ -67,18 +75,46 @@ func SaveKeyspace(keyspace *topo.KeyspaceInfo) error {
 	_, err := db.ExecVTOrc(`
 		replace
 			into vitess_keyspace (
-				keyspace, keyspace_type, durability_policy
+				keyspace, keyspace_type, durability_policy, disable_discovery, disable_recovery
 			) values (
-				?, ?, ?
+				?, ?, ?, ?, ?
 			)
 		`,
 		keyspace.KeyspaceName(),
 		int(keyspace.KeyspaceType),
 		keyspace.GetDurabilityPolicy(),
+		keyspace.GetVtorcConfig().GetDisableDiscovery(),
+		keyspace.GetVtorcConfig().GetDisableRecovery(),
 	)
 	return err
 }
 
+// IsKeyspaceDiscoveryDisabled returns whether the VtorcConfig of the keyspace disables discovery of its tablets.
+// Keyspaces that VTOrc hasn't read yet are considered enabled.
+func IsKeyspaceDiscoveryDisabled(keyspaceName string) (bool, error) {
+	ki, err := ReadKeyspace(keyspaceName)
+	if err == ErrKeyspaceNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return ki.GetVtorcConfig().GetDisableDiscovery(), nil
+}
+
+// IsKeyspaceRecoveryDisabled returns whether the VtorcConfig of the keyspace disables recoveries.
+// Disabling discovery disables recoveries too.
+func IsKeyspaceRecoveryDisabled(keyspaceName string) (bool, error) {
+	ki, err := ReadKeyspace(keyspaceName)
+	if err == ErrKeyspaceNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return ki.GetVtorcConfig().GetDisableDiscovery() || ki.GetVtorcConfig().GetDisableRecovery(), nil
+}
+
 // GetDurabilityPolicy gets the durability policy for the given keyspace.
 func GetDurabilityPolicy(keyspace string) (reparentutil.Durabler, error) {
 	ki, err := ReadKeyspace(keyspace)
@@ -80,6 +80,18 @@ func TestSaveAndReadKeyspace(t *testing.T) {
 				DurabilityPolicy: "none",
 			},
 			semiSyncAckersWanted: 0,
+		}, {
+			name:         "Success with vtorc config",
+			keyspaceName: "ks6",
+			keyspace: &topodatapb.Keyspace{
+				KeyspaceType:     topodatapb.KeyspaceType_NORMAL,
+				DurabilityPolicy: "none",
+				VtorcConfig: &topodatapb.VtorcConfig{
+					DisableRecovery: true,
+				},
+			},
+			keyspaceWanted:       nil,
+			semiSyncAckersWanted: 0,
 		}, {
 			name:           "No keyspace found",
 			keyspaceName:   "ks5",
@@ -124,3 +136,57 @@ func TestSaveAndReadKeyspace(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyspaceVtorcConfig(t *testing.T) {
+	// Clear the database after the test. The easiest way to do that is to run all the initialization commands again.
+	defer func() {
+		db.ClearVTOrcDatabase()
+	}()
+
+	tests := []struct {
+		name                    string
+		vtorcConfig             *topodatapb.VtorcConfig
+		discoveryDisabledWanted bool
+		recoveryDisabledWanted  bool
+	}{
+		{
+			name: "no config",
+		}, {
+			name:                   "recovery disabled",
+			vtorcConfig:            &topodatapb.VtorcConfig{DisableRecovery: true},
+			recoveryDisabledWanted: true,
+		}, {
+			name:                    "discovery disabled",
+			vtorcConfig:             &topodatapb.VtorcConfig{DisableDiscovery: true},
+			discoveryDisabledWanted: true,
+			recoveryDisabledWanted:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyspaceInfo := &topo.KeyspaceInfo{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy: "none",
+					VtorcConfig:      tt.vtorcConfig,
+				},
+			}
+			keyspaceInfo.SetKeyspaceName("ks")
+			require.NoError(t, SaveKeyspace(keyspaceInfo))
+
+			discoveryDisabled, err := IsKeyspaceDiscoveryDisabled("ks")
+			require.NoError(t, err)
+			require.Equal(t, tt.discoveryDisabledWanted, discoveryDisabled)
+			recoveryDisabled, err := IsKeyspaceRecoveryDisabled("ks")
+			require.NoError(t, err)
+			require.Equal(t, tt.recoveryDisabledWanted, recoveryDisabled)
+		})
+	}
+
+	// Keyspaces that haven't been read yet are enabled.
+	discoveryDisabled, err := IsKeyspaceDiscoveryDisabled("unknown")
+	require.NoError(t, err)
+	require.False(t, discoveryDisabled)
+	recoveryDisabled, err := IsKeyspaceRecoveryDisabled("unknown")
+	require.NoError(t, err)
+	require.False(t, recoveryDisabled)
+}
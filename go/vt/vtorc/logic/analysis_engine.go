@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// RecommendedAction is a problem found by the analysis engine along with
+// the recovery VTOrc would run to fix it.
+type RecommendedAction struct {
+	Analysis *inst.ReplicationAnalysis
+	// Recovery is the name of the recovery VTOrc would run. It is empty if VTOrc
+	// doesn't have a recovery for the problem.
+	Recovery string
+	// IsActionable is true if the recovery changes the topology.
+	IsActionable bool
+	// IsClusterWide is true if the recovery affects the whole shard.
+	IsClusterWide bool
+}
+
+// AnalyzeSnapshots runs the VTOrc analysis engine over the given instance snapshots
+// and returns the problems found together with the recoveries VTOrc would run for them.
+// Nothing is executed, and neither the backend database nor the topo server are used, which
+// makes this usable from other components and tooling without running VTOrc.
+func AnalyzeSnapshots(snapshots []*inst.InstanceSnapshot) []*RecommendedAction {
+	analyses := inst.AnalyzeInstanceSnapshots(snapshots)
+	actions := make([]*RecommendedAction, 0, len(analyses))
+	for _, analysis := range analyses {
+		recoveryFunctionCode := getCheckAndRecoverFunctionCode(analysis.Analysis, analysis.AnalyzedInstanceAlias)
+		actions = append(actions, &RecommendedAction{
+			Analysis:      analysis,
+			Recovery:      getRecoverFunctionName(recoveryFunctionCode),
+			IsActionable:  hasActionableRecovery(recoveryFunctionCode),
+			IsClusterWide: isClusterWideRecovery(recoveryFunctionCode),
+		})
+	}
+	return actions
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+func TestAnalyzeSnapshots(t *testing.T) {
+	snapshots := []*inst.InstanceSnapshot{{
+		Tablet: &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			Keyspace: "ks",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		DurabilityPolicy: "none",
+		State: inst.ReplicationAnalysis{
+			AnalyzedInstanceAlias: "zone1-0000000100",
+			TabletType:            topodatapb.TabletType_PRIMARY,
+			ClusterDetails:        inst.ClusterInfo{Keyspace: "ks", Shard: "0"},
+			IsPrimary:             true,
+			LastCheckValid:        true,
+			IsReadOnly:            true,
+		},
+	}}
+
+	actions := AnalyzeSnapshots(snapshots)
+	require.Len(t, actions, 1)
+	require.Equal(t, inst.PrimaryIsReadOnly, actions[0].Analysis.Analysis)
+	require.Equal(t, FixPrimaryRecoveryName, actions[0].Recovery)
+	require.True(t, actions[0].IsActionable)
+	require.False(t, actions[0].IsClusterWide)
+}
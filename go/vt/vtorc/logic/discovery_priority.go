@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/discovery"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// discoveryTarget is the cluster and the discovery priority of a tablet.
+type discoveryTarget struct {
+	cluster  string
+	priority discovery.Priority
+}
+
+// readDiscoveryTargets returns the cluster and the discovery priority of the
+// known tablets: the tablets of the shards with a recovery in progress are
+// discovered first, then the primaries, then the other tablets.
+func readDiscoveryTargets() (map[string]discoveryTarget, error) {
+	recoveries, err := ReadActiveRecoveries("", "")
+	if err != nil {
+		return nil, err
+	}
+	inRecovery := make(map[string]bool, len(recoveries))
+	for _, recovery := range recoveries {
+		inRecovery[topoproto.KeyspaceShardString(recovery.AnalysisEntry.ClusterDetails.Keyspace, recovery.AnalysisEntry.ClusterDetails.Shard)] = true
+	}
+
+	targets := make(map[string]discoveryTarget)
+	query := "select alias, keyspace, shard, tablet_type from vitess_tablet"
+	err = db.QueryVTOrc(query, nil, func(m sqlutils.RowMap) error {
+		target := discoveryTarget{
+			cluster:  topoproto.KeyspaceShardString(m.GetString("keyspace"), m.GetString("shard")),
+			priority: discovery.PriorityNormal,
+		}
+		switch {
+		case inRecovery[target.cluster]:
+			target.priority = discovery.PriorityRecovery
+		case topodatapb.TabletType(m.GetInt("tablet_type")) == topodatapb.TabletType_PRIMARY:
+			target.priority = discovery.PriorityPrimary
+		}
+		targets[m.GetString("alias")] = target
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// pushForDiscovery pushes the tablets to the discovery queue, with their
+// cluster and discovery priority. The tablets are pushed with the normal
+// priority if they can't be read.
+func pushForDiscovery(queue *discovery.Queue, tabletAliases []string) {
+	targets, err := readDiscoveryTargets()
+	if err != nil {
+		log.Error(err)
+	}
+	for _, tabletAlias := range tabletAliases {
+		target, found := targets[tabletAlias]
+		if !found {
+			queue.Push(tabletAlias)
+			continue
+		}
+		queue.PushWithPriority(tabletAlias, target.cluster, target.priority)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/discovery"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestPushForDiscovery(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+
+	// A replica of another shard, which has a recovery in progress.
+	tab200 := proto.Clone(tab101).(*topodatapb.Tablet)
+	tab200.Alias.Uid = 200
+	tab200.Shard = "80-"
+	for _, tablet := range []*topodatapb.Tablet{tab100, tab101, tab102, tab200} {
+		require.NoError(t, inst.SaveTablet(tablet))
+	}
+	_, err := writeTopologyRecovery(NewTopologyRecovery(inst.ReplicationAnalysis{
+		AnalyzedInstanceAlias: topoproto.TabletAliasString(tab200.Alias),
+		ClusterDetails: inst.ClusterInfo{
+			Keyspace: keyspace,
+			Shard:    "80-",
+		},
+		Analysis: inst.ReplicationStopped,
+	}))
+	require.NoError(t, err)
+
+	queue := discovery.CreateOrReturnQueue("TestPushForDiscovery")
+	pushForDiscovery(queue, []string{
+		topoproto.TabletAliasString(tab101.Alias),
+		topoproto.TabletAliasString(tab102.Alias),
+		"zone-1-0000000999",
+		topoproto.TabletAliasString(tab100.Alias),
+		topoproto.TabletAliasString(tab200.Alias),
+	})
+
+	// The tablets of the shard in recovery come first, then the primaries, and
+	// the other tablets take turns with the unknown ones.
+	var discovered []string
+	for queue.QueueLen() > 0 {
+		tabletAlias := queue.Consume()
+		queue.Release(tabletAlias)
+		discovered = append(discovered, tabletAlias)
+	}
+	assert.Equal(t, []string{
+		topoproto.TabletAliasString(tab200.Alias),
+		topoproto.TabletAliasString(tab100.Alias),
+		topoproto.TabletAliasString(tab101.Alias),
+		"zone-1-0000000999",
+		topoproto.TabletAliasString(tab102.Alias),
+	}, discovered)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/discovery"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const (
+	// topoDiscoverySourceName is the default discovery source, the tablet
+	// records of the topo.
+	topoDiscoverySourceName = "topo"
+	// fileDiscoverySourceName is the discovery source reading the tablets
+	// from --discovery-file.
+	fileDiscoverySourceName = "file"
+)
+
+var (
+	discoverySourceName = topoDiscoverySourceName
+	discoveryFile       string
+
+	// tabletSource is the discovery source of the tablets, nil to read them
+	// from the topo.
+	tabletSource DiscoverySource
+
+	discoverySourcesMu sync.Mutex
+	discoverySources   = make(map[string]DiscoverySource)
+)
+
+func init() {
+	RegisterDiscoverySource(fileDiscoverySourceName, &fileDiscoverySource{path: func() string { return discoveryFile }})
+}
+
+// DiscoverySource is an alternative to the topo for listing the tablets that
+// VTOrc discovers, e.g. from a service catalog or from the endpoints of an
+// orchestrator of containers. The sources are compiled in: they register
+// themselves with RegisterDiscoverySource, usually from an init function,
+// and one of them is selected with --discovery-source.
+//
+// The keyspaces and the shards are still read from the topo, as are the
+// tablets of a shard around its recoveries.
+type DiscoverySource interface {
+	// Tablets returns all the tablets of the source. VTOrc only keeps the
+	// primary and replica tablets of the keyspaces that it watches, and
+	// forgets the tablets that are no longer returned.
+	Tablets(ctx context.Context) ([]*topodatapb.Tablet, error)
+}
+
+// RegisterDiscoverySource registers a discovery source under a name, for it
+// to be selected with --discovery-source.
+func RegisterDiscoverySource(name string, source DiscoverySource) {
+	discoverySourcesMu.Lock()
+	defer discoverySourcesMu.Unlock()
+	if _, ok := discoverySources[name]; ok || name == topoDiscoverySourceName {
+		panic(fmt.Sprintf("discovery source %s is already registered", name))
+	}
+	discoverySources[name] = source
+}
+
+// getDiscoverySource returns the discovery source with the given name, or
+// nil for the topo.
+func getDiscoverySource(name string) (DiscoverySource, error) {
+	if name == topoDiscoverySourceName {
+		return nil, nil
+	}
+	discoverySourcesMu.Lock()
+	defer discoverySourcesMu.Unlock()
+	source, ok := discoverySources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown discovery source %s", name)
+	}
+	return source, nil
+}
+
+// refreshTabletsFromSource saves the watched tablets of the discovery source,
+// forgets the ones that it no longer returns, and queues the new or changed
+// ones for discovery.
+func refreshTabletsFromSource(ctx context.Context, source DiscoverySource, queue *discovery.Queue) {
+	tablets, err := source.Tablets(ctx)
+	if err != nil {
+		log.Errorf("Error fetching the tablets from discovery source %v: %v", discoverySourceName, err)
+		return
+	}
+	clusters := clustersToWatch
+	if ownership != nil {
+		clusters = ownership.filterClusters(clustersToWatch)
+		if len(clusters) == 0 {
+			return
+		}
+	}
+	watched := slices.DeleteFunc(tablets, func(tablet *topodatapb.Tablet) bool {
+		return !isTabletWatched(tablet, clusters)
+	})
+	inventory.refresh(watched, "select alias from vitess_tablet", nil, queue.Push, false /* forceRefresh */, nil)
+}
+
+// isTabletWatched returns whether the tablet is in one of the clusters, in
+// the format of --clusters_to_watch. All the tablets are watched when
+// clusters is empty.
+func isTabletWatched(tablet *topodatapb.Tablet, clusters []string) bool {
+	if len(clusters) == 0 {
+		return true
+	}
+	for _, cluster := range clusters {
+		keyspace, shard, hasShard := strings.Cut(cluster, "/")
+		if keyspace != tablet.Keyspace {
+			continue
+		}
+		if !hasShard || shard == tablet.Shard {
+			return true
+		}
+	}
+	return false
+}
+
+// fileDiscoverySource reads the tablets from a file holding a JSON array of
+// tablet records, read again on every refresh.
+type fileDiscoverySource struct {
+	path func() string
+}
+
+// Tablets is part of the DiscoverySource interface.
+func (fds *fileDiscoverySource) Tablets(ctx context.Context) ([]*topodatapb.Tablet, error) {
+	path := fds.path()
+	if path == "" {
+		return nil, fmt.Errorf("--discovery-file is required by the %s discovery source", fileDiscoverySourceName)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid discovery file %s: %w", path, err)
+	}
+	tablets := make([]*topodatapb.Tablet, 0, len(records))
+	for i, record := range records {
+		tablet := &topodatapb.Tablet{}
+		if err := protojson.Unmarshal(record, tablet); err != nil {
+			return nil, fmt.Errorf("invalid tablet %d in discovery file %s: %w", i, path, err)
+		}
+		if tablet.Alias == nil {
+			return nil, fmt.Errorf("tablet %d in discovery file %s has no alias", i, path)
+		}
+		tablets = append(tablets, tablet)
+	}
+	return tablets, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/discovery"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+type fakeDiscoverySource struct {
+	tablets []*topodatapb.Tablet
+}
+
+func (fds *fakeDiscoverySource) Tablets(ctx context.Context) ([]*topodatapb.Tablet, error) {
+	return fds.tablets, nil
+}
+
+func TestRegisterDiscoverySource(t *testing.T) {
+	source := &fakeDiscoverySource{}
+	RegisterDiscoverySource("test_register", source)
+	assert.Panics(t, func() { RegisterDiscoverySource("test_register", source) })
+	assert.Panics(t, func() { RegisterDiscoverySource(topoDiscoverySourceName, source) })
+
+	got, err := getDiscoverySource("test_register")
+	require.NoError(t, err)
+	assert.Same(t, source, got)
+	got, err = getDiscoverySource(topoDiscoverySourceName)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	_, err = getDiscoverySource(fileDiscoverySourceName)
+	require.NoError(t, err)
+	_, err = getDiscoverySource("unknown")
+	assert.EqualError(t, err, "unknown discovery source unknown")
+}
+
+func TestIsTabletWatched(t *testing.T) {
+	tablet := &topodatapb.Tablet{Keyspace: "ks", Shard: "-80"}
+	assert.True(t, isTabletWatched(tablet, nil))
+	assert.True(t, isTabletWatched(tablet, []string{"other", "ks"}))
+	assert.True(t, isTabletWatched(tablet, []string{"ks/-80"}))
+	assert.False(t, isTabletWatched(tablet, []string{"ks/80-"}))
+	assert.False(t, isTabletWatched(tablet, []string{"other"}))
+}
+
+func TestFileDiscoverySource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tablets.json")
+	source := &fileDiscoverySource{path: func() string { return path }}
+
+	_, err := source.Tablets(context.Background())
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"alias": {"cell": "zone-1", "uid": 100}, "hostname": "localhost", "keyspace": "ks", "shard": "0", "type": "PRIMARY", "mysql_hostname": "localhost", "mysql_port": 100},
+		{"alias": {"cell": "zone-1", "uid": 101}, "keyspace": "ks", "shard": "0", "type": "REPLICA"}
+	]`), 0o644))
+	tablets, err := source.Tablets(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tablets, 2)
+	assert.Equal(t, "zone-1-0000000100", topoproto.TabletAliasString(tablets[0].Alias))
+	assert.Equal(t, topodatapb.TabletType_PRIMARY, tablets[0].Type)
+	assert.EqualValues(t, 100, tablets[0].MysqlPort)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, tablets[1].Type)
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"keyspace": "ks"}]`), 0o644))
+	_, err = source.Tablets(context.Background())
+	assert.ErrorContains(t, err, "has no alias")
+
+	source = &fileDiscoverySource{path: func() string { return "" }}
+	_, err = source.Tablets(context.Background())
+	assert.ErrorContains(t, err, "--discovery-file is required")
+}
+
+func TestRefreshTabletsFromSource(t *testing.T) {
+	defer db.ClearVTOrcDatabase()
+	oldClustersToWatch := clustersToWatch
+	defer func() {
+		clustersToWatch = oldClustersToWatch
+	}()
+	clustersToWatch = []string{keyspace}
+
+	// The tablets are forgotten once the forgotten instances cache is initialized.
+	config.MarkConfigurationLoaded()
+	require.Eventually(t, func() bool {
+		_, err := inst.ReadAllInstanceKeys()
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// The forgotten tablets are remembered for a while, so this test has tablets of its own.
+	newTablet := func(tablet *topodatapb.Tablet, uid uint32) *topodatapb.Tablet {
+		tablet = proto.Clone(tablet).(*topodatapb.Tablet)
+		tablet.Alias.Uid = uid
+		return tablet
+	}
+	tab300, tab301, tab302 := newTablet(tab100, 300), newTablet(tab101, 301), newTablet(tab102, 302)
+	other := newTablet(tab101, 303)
+	other.Keyspace = "other"
+	source := &fakeDiscoverySource{tablets: []*topodatapb.Tablet{tab300, tab301, tab302, other}}
+	queue := discovery.CreateOrReturnQueue("TestRefreshTabletsFromSource")
+
+	refreshTabletsFromSource(context.Background(), source, queue)
+	// The tablet of the unwatched keyspace is skipped.
+	queued := []string{queue.Consume(), queue.Consume(), queue.Consume()}
+	for _, alias := range queued {
+		queue.Release(alias)
+	}
+	sort.Strings(queued)
+	assert.Equal(t, []string{"zone-1-0000000300", "zone-1-0000000301", "zone-1-0000000302"}, queued)
+	assert.Zero(t, queue.QueueLen())
+	tablet, err := inst.ReadTablet("zone-1-0000000301")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(tab301, tablet))
+	_, err = inst.ReadTablet("zone-1-0000000303")
+	assert.Equal(t, inst.ErrTabletAliasNil, err)
+
+	// The unchanged tablets aren't queued again, and the ones the source no
+	// longer returns are forgotten.
+	source.tablets = []*topodatapb.Tablet{tab300}
+	refreshTabletsFromSource(context.Background(), source, queue)
+	assert.Zero(t, queue.QueueLen())
+	_, err = inst.ReadTablet("zone-1-0000000301")
+	assert.Equal(t, inst.ErrTabletAliasNil, err)
+}
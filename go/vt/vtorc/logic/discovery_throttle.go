@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+// discoveryThrottleWindow is the number of backend latency samples averaged
+// before the discovery concurrency is adjusted.
+const discoveryThrottleWindow = 50
+
+// discoveryThrottle limits the number of the discovery workers that run
+// DiscoverInstance at the same time.
+var discoveryThrottle = newDiscoveryThrottler(config.DiscoveryMaxConcurrency)
+
+func init() {
+	stats.NewGaugeFunc("DiscoveryConcurrencyLimit", "Number of instance discoveries allowed to run concurrently", func() int64 {
+		return int64(discoveryThrottle.concurrencyLimit())
+	})
+}
+
+// discoveryThrottler adapts the discovery concurrency to the backend latency
+// of the discoveries: when their average latency exceeds the threshold, the
+// backend is overloaded, and adding more concurrent discoveries would only
+// make it worse. The concurrency is then halved, down to the minimum, and
+// grown back by a tenth of the maximum at a time once the latency is below
+// half of the threshold.
+type discoveryThrottler struct {
+	maxConcurrency int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	running int
+	samples int
+	total   time.Duration
+}
+
+func newDiscoveryThrottler(maxConcurrency int) *discoveryThrottler {
+	t := &discoveryThrottler{
+		maxConcurrency: maxConcurrency,
+		limit:          maxConcurrency,
+	}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// acquire waits until a discovery can run.
+func (t *discoveryThrottler) acquire() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.running >= t.limit {
+		t.cond.Wait()
+	}
+	t.running++
+}
+
+// release must be called once the discovery allowed by acquire is done.
+func (t *discoveryThrottler) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running--
+	t.cond.Signal()
+}
+
+func (t *discoveryThrottler) concurrencyLimit() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit
+}
+
+// runningDiscoveries returns the number of the discoveries that are running.
+func (t *discoveryThrottler) runningDiscoveries() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.running
+}
+
+// recordBackendLatency adds the backend latency of a discovery, and adjusts
+// the concurrency once enough samples are collected.
+func (t *discoveryThrottler) recordBackendLatency(latency time.Duration) {
+	threshold := config.DiscoveryBackendLatencyThreshold()
+	if threshold <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples++
+	t.total += latency
+	if t.samples < discoveryThrottleWindow {
+		return
+	}
+	average := t.total / time.Duration(t.samples)
+	t.samples, t.total = 0, 0
+
+	limit := t.limit
+	switch {
+	case average > threshold:
+		limit = max(limit/2, min(config.DiscoveryMinConcurrency(), t.maxConcurrency), 1)
+	case average < threshold/2:
+		limit = min(limit+max(t.maxConcurrency/10, 1), t.maxConcurrency)
+	}
+	if limit == t.limit {
+		return
+	}
+	log.Infof("Average backend latency of the discoveries is %v, changing the discovery concurrency from %d to %d", average, t.limit, limit)
+	t.limit = limit
+	// Wake up the workers which may now run.
+	t.cond.Broadcast()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/vt/vtorc/config"
+)
+
+func TestDiscoveryThrottler(t *testing.T) {
+	defer config.SetDiscoveryBackendLatencyThreshold(0)
+
+	recordWindow := func(throttle *discoveryThrottler, latency time.Duration) {
+		for i := 0; i < discoveryThrottleWindow; i++ {
+			throttle.recordBackendLatency(latency)
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		throttle := newDiscoveryThrottler(300)
+		recordWindow(throttle, time.Second)
+		assert.Equal(t, 300, throttle.concurrencyLimit())
+	})
+
+	config.SetDiscoveryBackendLatencyThreshold(100 * time.Millisecond)
+
+	t.Run("latency spike and recovery", func(t *testing.T) {
+		throttle := newDiscoveryThrottler(300)
+		recordWindow(throttle, 80*time.Millisecond)
+		assert.Equal(t, 300, throttle.concurrencyLimit())
+
+		recordWindow(throttle, 200*time.Millisecond)
+		assert.Equal(t, 150, throttle.concurrencyLimit())
+		recordWindow(throttle, 200*time.Millisecond)
+		assert.Equal(t, 75, throttle.concurrencyLimit())
+		for i := 0; i < 10; i++ {
+			recordWindow(throttle, 200*time.Millisecond)
+		}
+		assert.Equal(t, config.DiscoveryMinConcurrency(), throttle.concurrencyLimit())
+
+		// Between half of the threshold and the threshold, the concurrency is kept.
+		recordWindow(throttle, 80*time.Millisecond)
+		assert.Equal(t, config.DiscoveryMinConcurrency(), throttle.concurrencyLimit())
+
+		recordWindow(throttle, 10*time.Millisecond)
+		assert.Equal(t, config.DiscoveryMinConcurrency()+30, throttle.concurrencyLimit())
+		for i := 0; i < 10; i++ {
+			recordWindow(throttle, 10*time.Millisecond)
+		}
+		assert.Equal(t, 300, throttle.concurrencyLimit())
+	})
+
+	t.Run("workers wait for the limit", func(t *testing.T) {
+		throttle := newDiscoveryThrottler(20)
+		recordWindow(throttle, time.Second)
+		assert.Equal(t, 10, throttle.concurrencyLimit())
+		for i := 0; i < 10; i++ {
+			throttle.acquire()
+		}
+
+		acquired := make(chan struct{})
+		go func() {
+			throttle.acquire()
+			close(acquired)
+		}()
+		select {
+		case <-acquired:
+			t.Fatal("acquired a discovery above the limit")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		throttle.release()
+		select {
+		case <-acquired:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a discovery to be allowed")
+		}
+	})
+}
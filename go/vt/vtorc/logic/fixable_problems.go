@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// The outcomes of the fix of a problem.
+const (
+	ProblemFixDryRun       = "dry-run"
+	ProblemFixFixed        = "fixed"
+	ProblemFixAlreadyFixed = "already-fixed"
+	ProblemFixFailed       = "failed"
+)
+
+// problemFixes describes how the misconfigurations that VTOrc fixes through
+// the tablet manager are fixed. The fixes don't need a failover, so they can
+// be applied on demand.
+var problemFixes = map[inst.AnalysisCode]string{
+	inst.PrimaryIsReadOnly:           "set the primary read-write",
+	inst.PrimarySemiSyncMustBeSet:    "enable semi-sync on the primary",
+	inst.PrimarySemiSyncMustNotBeSet: "disable semi-sync on the primary",
+	inst.ReplicaIsWritable:           "set the replica read-only and point it at the shard primary",
+	inst.ReplicaSemiSyncMustBeSet:    "enable semi-sync on the replica and point it at the shard primary",
+	inst.ReplicaSemiSyncMustNotBeSet: "disable semi-sync on the replica and point it at the shard primary",
+	inst.NotConnectedToPrimary:       "set the replica read-only and point it at the shard primary",
+	inst.ConnectedToWrongPrimary:     "set the replica read-only and point it at the shard primary",
+	inst.ReplicationStopped:          "set the replica read-only and restart its replication from the shard primary",
+}
+
+// FixableProblem is a problem found by the replication analysis, classified
+// by whether it can be fixed on demand through the fix problems API.
+type FixableProblem struct {
+	TabletAlias string
+	Keyspace    string
+	Shard       string
+	Analysis    inst.AnalysisCode
+	Description string
+	Fixable     bool
+	Fix         string          `json:",omitempty"`
+	Runbook     *config.Runbook `json:",omitempty"`
+}
+
+// ProblemFix is the outcome of the fix of a problem.
+type ProblemFix struct {
+	TabletAlias string
+	Keyspace    string
+	Shard       string
+	Analysis    inst.AnalysisCode
+	Fix         string
+	State       string
+	Error       string          `json:",omitempty"`
+	Runbook     *config.Runbook `json:",omitempty"`
+}
+
+// classifyProblem tells if the problem of the analysis can be fixed on demand.
+func classifyProblem(entry *inst.ReplicationAnalysis) FixableProblem {
+	problem := FixableProblem{
+		TabletAlias: entry.AnalyzedInstanceAlias,
+		Keyspace:    entry.AnalyzedKeyspace,
+		Shard:       entry.AnalyzedShard,
+		Analysis:    entry.Analysis,
+		Description: entry.Description,
+		Runbook:     config.RunbookFor(string(entry.Analysis)),
+	}
+	fix, ok := problemFixes[entry.Analysis]
+	if !ok {
+		return problem
+	}
+	switch getCheckAndRecoverFunctionCode(entry.Analysis, entry.AnalyzedInstanceAlias) {
+	case fixPrimaryFunc, fixReplicaFunc:
+		problem.Fixable = true
+		problem.Fix = fix
+	}
+	return problem
+}
+
+// GetFixableProblems returns the problems of the tablets of the keyspace and
+// shard, when given, and whether they can be fixed on demand.
+func GetFixableProblems(keyspace string, shard string) ([]FixableProblem, error) {
+	entries, err := inst.GetReplicationAnalysis(keyspace, shard, &inst.ReplicationAnalysisHints{})
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]FixableProblem, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Analysis == inst.NoProblem {
+			continue
+		}
+		problems = append(problems, classifyProblem(entry))
+	}
+	return problems, nil
+}
+
+// FixProblems fixes the fixable problems of the tablets of the keyspace and
+// shard, when given, one after the other. When tablet aliases are given,
+// only the problems of those tablets are fixed. The problems are fixed even
+// if the recoveries are disabled, since an operator asked for it. In dry run
+// mode, the problems that would be fixed are returned without fixing them.
+func FixProblems(keyspace string, shard string, tabletAliases []string, dryRun bool) ([]ProblemFix, error) {
+	entries, err := inst.GetReplicationAnalysis(keyspace, shard, &inst.ReplicationAnalysisHints{})
+	if err != nil {
+		return nil, err
+	}
+	return fixProblems(entries, tabletAliases, dryRun, fixProblem), nil
+}
+
+// fixProblems fixes the fixable problems of the analyses with the given fix
+// function, and returns the outcome of each fix.
+func fixProblems(entries []*inst.ReplicationAnalysis, tabletAliases []string, dryRun bool, fix func(*inst.ReplicationAnalysis) (bool, error)) []ProblemFix {
+	var fixes []ProblemFix
+	for _, entry := range entries {
+		if len(tabletAliases) > 0 && !slices.Contains(tabletAliases, entry.AnalyzedInstanceAlias) {
+			continue
+		}
+		problem := classifyProblem(entry)
+		if !problem.Fixable {
+			continue
+		}
+		result := ProblemFix{
+			TabletAlias: problem.TabletAlias,
+			Keyspace:    problem.Keyspace,
+			Shard:       problem.Shard,
+			Analysis:    problem.Analysis,
+			Fix:         problem.Fix,
+			Runbook:     problem.Runbook,
+			State:       ProblemFixDryRun,
+		}
+		if !dryRun {
+			_ = inst.AuditOperation("fix-problem", entry.AnalyzedInstanceAlias, fmt.Sprintf("Fixing %v: %v", entry.Analysis, problem.Fix))
+			fixed, err := fix(entry)
+			switch {
+			case err != nil:
+				result.State = ProblemFixFailed
+				result.Error = err.Error()
+			case fixed:
+				result.State = ProblemFixFixed
+			default:
+				result.State = ProblemFixAlreadyFixed
+			}
+		}
+		fixes = append(fixes, result)
+	}
+	return fixes
+}
+
+// fixProblem fixes the problem of the analysis under the shard lock, unless
+// it was fixed in the meantime. It tells if it fixed the problem.
+func fixProblem(entry *inst.ReplicationAnalysis) (fixed bool, err error) {
+	ctx, unlock, err := LockShard(context.Background(), entry.AnalyzedInstanceAlias, getLockAction(entry.AnalyzedInstanceAlias, entry.Analysis))
+	if err != nil {
+		return false, err
+	}
+	defer unlock(&err)
+
+	// Like before any recovery, the tablet and the shard primary are refreshed
+	// to find out whether the problem is still there.
+	if err := RefreshKeyspaceAndShard(entry.AnalyzedKeyspace, entry.AnalyzedShard); err != nil {
+		return false, err
+	}
+	refreshTabletInfoOfShard(ctx, entry.AnalyzedKeyspace, entry.AnalyzedShard)
+	DiscoverInstance(entry.AnalyzedInstanceAlias, true)
+	primaryTablet, err := shardPrimary(entry.AnalyzedKeyspace, entry.AnalyzedShard)
+	if err != nil {
+		return false, err
+	}
+	if primaryTabletAlias := topoproto.TabletAliasString(primaryTablet.Alias); primaryTabletAlias != entry.AnalyzedInstanceAlias {
+		DiscoverInstance(primaryTabletAlias, true)
+	}
+	alreadyFixed, err := checkIfAlreadyFixed(entry)
+	if err != nil || alreadyFixed {
+		return false, err
+	}
+
+	log.Infof("Fixing %v on tablet %v on demand", entry.Analysis, entry.AnalyzedInstanceAlias)
+	recoverFunc := getCheckAndRecoverFunction(getCheckAndRecoverFunctionCode(entry.Analysis, entry.AnalyzedInstanceAlias))
+	attempted, _, err := recoverFunc(ctx, entry)
+	if err != nil {
+		return false, err
+	}
+	if !attempted {
+		return false, fmt.Errorf("an active or recent recovery on tablet %v prevents fixing %v", entry.AnalyzedInstanceAlias, entry.Analysis)
+	}
+	return true, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+func TestClassifyProblem(t *testing.T) {
+	tests := []struct {
+		analysis inst.AnalysisCode
+		fixable  bool
+	}{
+		{analysis: inst.ReplicaIsWritable, fixable: true},
+		{analysis: inst.ReplicaSemiSyncMustNotBeSet, fixable: true},
+		{analysis: inst.ConnectedToWrongPrimary, fixable: true},
+		{analysis: inst.PrimaryIsReadOnly, fixable: true},
+		{analysis: inst.PrimarySemiSyncMustBeSet, fixable: true},
+		{analysis: inst.DeadPrimary, fixable: false},
+		{analysis: inst.ErrantGTIDDetected, fixable: false},
+		{analysis: inst.UnreachablePrimary, fixable: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.analysis), func(t *testing.T) {
+			problem := classifyProblem(&inst.ReplicationAnalysis{
+				AnalyzedInstanceAlias: "zone1-0000000100",
+				AnalyzedKeyspace:      "ks",
+				AnalyzedShard:         "0",
+				Analysis:              tt.analysis,
+				Description:           "description",
+			})
+			assert.Equal(t, tt.fixable, problem.Fixable)
+			assert.Equal(t, tt.fixable, problem.Fix != "")
+			assert.Equal(t, "zone1-0000000100", problem.TabletAlias)
+			assert.Equal(t, "ks", problem.Keyspace)
+			assert.Equal(t, "0", problem.Shard)
+			assert.Equal(t, "description", problem.Description)
+		})
+	}
+}
+
+func TestClassifyProblemRunbook(t *testing.T) {
+	oldRunbooks := config.Config.Runbooks
+	defer func() {
+		config.Config.Runbooks = oldRunbooks
+	}()
+	config.Config.Runbooks = map[string]config.Runbook{
+		string(inst.PrimaryIsReadOnly): {URL: "https://runbooks.example.com/primary-is-read-only"},
+	}
+
+	problem := classifyProblem(&inst.ReplicationAnalysis{AnalyzedInstanceAlias: "zone1-0000000100", Analysis: inst.PrimaryIsReadOnly})
+	assert.Equal(t, &config.Runbook{URL: "https://runbooks.example.com/primary-is-read-only"}, problem.Runbook)
+	problem = classifyProblem(&inst.ReplicationAnalysis{AnalyzedInstanceAlias: "zone1-0000000101", Analysis: inst.DeadPrimary})
+	assert.Nil(t, problem.Runbook)
+
+	fixes := fixProblems([]*inst.ReplicationAnalysis{
+		{AnalyzedInstanceAlias: "zone1-0000000100", Analysis: inst.PrimaryIsReadOnly},
+	}, nil, true, nil)
+	assert.Len(t, fixes, 1)
+	assert.Equal(t, &config.Runbook{URL: "https://runbooks.example.com/primary-is-read-only"}, fixes[0].Runbook)
+}
+
+func TestFixProblems(t *testing.T) {
+	entries := []*inst.ReplicationAnalysis{
+		{AnalyzedInstanceAlias: "zone1-0000000100", Analysis: inst.PrimaryIsReadOnly},
+		{AnalyzedInstanceAlias: "zone1-0000000101", Analysis: inst.ReplicaIsWritable},
+		{AnalyzedInstanceAlias: "zone1-0000000102", Analysis: inst.ReplicaSemiSyncMustNotBeSet},
+		{AnalyzedInstanceAlias: "zone1-0000000103", Analysis: inst.UnreachablePrimary},
+		{AnalyzedInstanceAlias: "zone1-0000000104", Analysis: inst.NoProblem},
+	}
+	var fixed []string
+	fix := func(entry *inst.ReplicationAnalysis) (bool, error) {
+		fixed = append(fixed, entry.AnalyzedInstanceAlias)
+		switch entry.Analysis {
+		case inst.ReplicaIsWritable:
+			return false, nil
+		case inst.ReplicaSemiSyncMustNotBeSet:
+			return false, errors.New("tablet unreachable")
+		}
+		return true, nil
+	}
+
+	// In dry run mode, nothing is fixed.
+	fixes := fixProblems(entries, nil, true, fix)
+	assert.Empty(t, fixed)
+	assert.Len(t, fixes, 3)
+	for _, f := range fixes {
+		assert.Equal(t, ProblemFixDryRun, f.State)
+	}
+
+	// Only the fixable problems are fixed.
+	fixes = fixProblems(entries, nil, false, fix)
+	assert.Equal(t, []string{"zone1-0000000100", "zone1-0000000101", "zone1-0000000102"}, fixed)
+	assert.Equal(t, []ProblemFix{{
+		TabletAlias: "zone1-0000000100",
+		Analysis:    inst.PrimaryIsReadOnly,
+		Fix:         problemFixes[inst.PrimaryIsReadOnly],
+		State:       ProblemFixFixed,
+	}, {
+		TabletAlias: "zone1-0000000101",
+		Analysis:    inst.ReplicaIsWritable,
+		Fix:         problemFixes[inst.ReplicaIsWritable],
+		State:       ProblemFixAlreadyFixed,
+	}, {
+		TabletAlias: "zone1-0000000102",
+		Analysis:    inst.ReplicaSemiSyncMustNotBeSet,
+		Fix:         problemFixes[inst.ReplicaSemiSyncMustNotBeSet],
+		State:       ProblemFixFailed,
+		Error:       "tablet unreachable",
+	}}, fixes)
+
+	// The fixes can be limited to some tablets.
+	fixed = nil
+	fixes = fixProblems(entries, []string{"zone1-0000000101", "zone1-0000000103"}, false, fix)
+	assert.Equal(t, []string{"zone1-0000000101"}, fixed)
+	assert.Len(t, fixes, 1)
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+	"vitess.io/vitess/go/vt/vtorc/process"
+)
+
+const (
+	// jobInitialBackoff is how long a job waits before running again after it
+	// panicked. The backoff doubles with every consecutive panic of the job, up
+	// to the maximum backoff of the job, which is jobMaxBackoff by default.
+	jobInitialBackoff = 5 * time.Second
+	jobMaxBackoff     = 5 * time.Minute
+)
+
+var (
+	jobRuns    = stats.NewCountersWithSingleLabel("JobRuns", "Number of runs of the periodic jobs of VTOrc", "Job")
+	jobErrors  = stats.NewCountersWithSingleLabel("JobErrors", "Number of runs of the periodic jobs of VTOrc that returned an error", "Job")
+	jobPanics  = stats.NewCountersWithSingleLabel("JobPanics", "Number of runs of the periodic jobs of VTOrc that panicked", "Job")
+	jobSkips   = stats.NewCountersWithSingleLabel("JobSkips", "Number of times a periodic job of VTOrc didn't run because it was still running, or backing off after a panic", "Job")
+	jobTimings = stats.NewTimings("JobTimings", "Duration of the runs of the periodic jobs of VTOrc", "Job")
+
+	discoveryJobs = newJobSupervisor()
+)
+
+// JobStatus is the health of a periodic job of VTOrc.
+type JobStatus struct {
+	Name string
+	// Running is the number of runs of the job in progress.
+	Running      int
+	Runs         int64
+	Errors       int64
+	Panics       int64
+	LastStart    time.Time
+	LastDuration time.Duration
+	LastError    string `json:",omitempty"`
+	LastPanic    string `json:",omitempty"`
+	// BackoffUntil is set while the job waits before running again after a panic.
+	BackoffUntil *time.Time `json:",omitempty"`
+}
+
+// supervisedJob is a periodic job run by a jobSupervisor.
+type supervisedJob struct {
+	status JobStatus
+	run    func() error
+	// exclusive jobs are not run while a previous run is in progress.
+	exclusive bool
+	// maxBackoff caps the backoff of the job after consecutive panics.
+	maxBackoff        time.Duration
+	consecutivePanics int
+}
+
+// jobSupervisor runs the periodic jobs of VTOrc. The panics of the jobs, and
+// of the goroutines they start with goRun, are recovered, so that they don't
+// crash VTOrc nor silently stop the job, and a job that panicked backs off
+// before it runs again on its next tick. VTOrc reports itself unhealthy while
+// a job backs off.
+type jobSupervisor struct {
+	mu   sync.Mutex
+	jobs map[string]*supervisedJob
+	// names are the names of the jobs in registration order.
+	names []string
+	now   func() time.Time
+	// running tracks the runs of the jobs in progress.
+	running sync.WaitGroup
+}
+
+func newJobSupervisor() *jobSupervisor {
+	return &jobSupervisor{
+		jobs: make(map[string]*supervisedJob),
+		now:  time.Now,
+	}
+}
+
+// register adds a job, or replaces the job with the same name. A zero
+// maxBackoff is jobMaxBackoff.
+func (js *jobSupervisor) register(name string, exclusive bool, maxBackoff time.Duration, run func() error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if _, ok := js.jobs[name]; !ok {
+		js.names = append(js.names, name)
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = jobMaxBackoff
+	}
+	js.jobs[name] = &supervisedJob{
+		status:     JobStatus{Name: name},
+		run:        run,
+		exclusive:  exclusive,
+		maxBackoff: maxBackoff,
+	}
+}
+
+// start runs a job in a new goroutine, unless it may not run now.
+func (js *jobSupervisor) start(name string) {
+	job := js.begin(name)
+	if job == nil {
+		return
+	}
+	go js.execute(job)
+}
+
+// run runs a job in the calling goroutine, unless it may not run now.
+func (js *jobSupervisor) run(name string) {
+	job := js.begin(name)
+	if job == nil {
+		return
+	}
+	js.execute(job)
+}
+
+// goRun runs f in a new goroutine that is part of the work of the job, such
+// as a recovery started by CheckAndRecover. A panic of f is recovered and
+// recorded as a panic of the job.
+func (js *jobSupervisor) goRun(name string, f func()) {
+	js.running.Add(1)
+	go func() {
+		defer js.running.Done()
+		defer func() {
+			if panicked := recover(); panicked != nil {
+				log.Errorf("VTOrc job %s panicked: %v\n%s", name, panicked, debug.Stack())
+				js.mu.Lock()
+				defer js.mu.Unlock()
+				if job, ok := js.jobs[name]; ok {
+					js.panickedLocked(job, panicked)
+				}
+			}
+		}()
+		f()
+	}()
+}
+
+// begin marks a run of the job as started, and returns the job. It returns nil
+// if the job doesn't exist, or may not run now.
+func (js *jobSupervisor) begin(name string) *supervisedJob {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	job, ok := js.jobs[name]
+	if !ok {
+		log.Errorf("Unknown VTOrc job %s", name)
+		return nil
+	}
+	now := js.now()
+	backingOff := job.status.BackoffUntil != nil && now.Before(*job.status.BackoffUntil)
+	if backingOff || (job.exclusive && job.status.Running > 0) {
+		jobSkips.Add(name, 1)
+		return nil
+	}
+	job.status.BackoffUntil = nil
+	job.status.Running++
+	js.running.Add(1)
+	job.status.LastStart = now
+	return job
+}
+
+// execute runs the job, and records its outcome.
+func (js *jobSupervisor) execute(job *supervisedJob) {
+	defer js.running.Done()
+	name := job.status.Name
+	start := js.now()
+	var err error
+	var panicked any
+	func() {
+		defer func() {
+			if panicked = recover(); panicked != nil {
+				log.Errorf("VTOrc job %s panicked: %v\n%s", name, panicked, debug.Stack())
+			}
+		}()
+		err = job.run()
+	}()
+	jobTimings.Record(name, start)
+	jobRuns.Add(name, 1)
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	job.status.Running--
+	job.status.Runs++
+	job.status.LastDuration = js.now().Sub(start)
+	switch {
+	case panicked != nil:
+		js.panickedLocked(job, panicked)
+		return
+	case err != nil:
+		jobErrors.Add(name, 1)
+		job.status.Errors++
+		job.status.LastError = err.Error()
+		log.Errorf("VTOrc job %s failed: %v", name, err)
+	}
+	job.consecutivePanics = 0
+}
+
+// panickedLocked records a panic of the job, and backs the job off.
+func (js *jobSupervisor) panickedLocked(job *supervisedJob, panicked any) {
+	jobPanics.Add(job.status.Name, 1)
+	job.status.Panics++
+	job.status.LastPanic = fmt.Sprint(panicked)
+	job.consecutivePanics++
+	backoff := min(jobInitialBackoff, job.maxBackoff)
+	for i := 1; i < job.consecutivePanics && backoff < job.maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoffUntil := js.now().Add(min(backoff, job.maxBackoff))
+	job.status.BackoffUntil = &backoffUntil
+}
+
+// backingOff returns the names of the jobs that back off after a panic.
+func (js *jobSupervisor) backingOff() []string {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	now := js.now()
+	var names []string
+	for _, name := range js.names {
+		if backoffUntil := js.jobs[name].status.BackoffUntil; backoffUntil != nil && now.Before(*backoffUntil) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// wait waits for the runs of the jobs in progress to end.
+func (js *jobSupervisor) wait() {
+	js.running.Wait()
+}
+
+// statuses returns the health of the jobs, in registration order.
+func (js *jobSupervisor) statuses() []JobStatus {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(js.names))
+	for _, name := range js.names {
+		status := js.jobs[name].status
+		if status.BackoffUntil != nil {
+			backoffUntil := *status.BackoffUntil
+			status.BackoffUntil = &backoffUntil
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// GetJobStatuses returns the health of the periodic jobs of ContinuousDiscovery.
+func GetJobStatuses() []JobStatus {
+	return discoveryJobs.statuses()
+}
+
+func init() {
+	process.BackingOffJobs = func() []string {
+		return discoveryJobs.backingOff()
+	}
+}
+
+// The names of the periodic jobs of ContinuousDiscovery.
+const (
+	healthTickJob                         = "HealthTick"
+	forgetLongUnseenInstancesJob          = "ForgetLongUnseenInstances"
+	expireAuditJob                        = "ExpireAudit"
+	expireStaleInstanceBinlogCoordsJob    = "ExpireStaleInstanceBinlogCoordinates"
+	expireRecoveryDetectionHistoryJob     = "ExpireRecoveryDetectionHistory"
+	expireTopologyRecoveryHistoryJob      = "ExpireTopologyRecoveryHistory"
+	expireTopologyRecoveryStepsHistoryJob = "ExpireTopologyRecoveryStepsHistory"
+	expireInstanceAnalysisChangelogJob    = "ExpireInstanceAnalysisChangelog"
+	checkAndRecoverJob                    = "CheckAndRecover"
+	snapshotTopologiesJob                 = "SnapshotTopologies"
+	refreshAllInformationJob              = "RefreshAllInformation"
+)
+
+// caretakingJobs are the internal maintenance jobs run on caretaking ticks.
+var caretakingJobs = []string{
+	forgetLongUnseenInstancesJob,
+	expireAuditJob,
+	expireStaleInstanceBinlogCoordsJob,
+	expireRecoveryDetectionHistoryJob,
+	expireTopologyRecoveryHistoryJob,
+	expireTopologyRecoveryStepsHistoryJob,
+}
+
+// registerDiscoveryJobs registers the periodic jobs of ContinuousDiscovery.
+func registerDiscoveryJobs() {
+	discoveryJobs.register(healthTickJob, false, 0, func() error {
+		onHealthTick()
+		return nil
+	})
+	discoveryJobs.register(forgetLongUnseenInstancesJob, false, 0, inst.ForgetLongUnseenInstances)
+	discoveryJobs.register(expireAuditJob, false, 0, inst.ExpireAudit)
+	discoveryJobs.register(expireStaleInstanceBinlogCoordsJob, false, 0, inst.ExpireStaleInstanceBinlogCoordinates)
+	discoveryJobs.register(expireRecoveryDetectionHistoryJob, false, 0, ExpireRecoveryDetectionHistory)
+	discoveryJobs.register(expireTopologyRecoveryHistoryJob, false, 0, ExpireTopologyRecoveryHistory)
+	discoveryJobs.register(expireTopologyRecoveryStepsHistoryJob, false, 0, ExpireTopologyRecoveryStepsHistory)
+	discoveryJobs.register(expireInstanceAnalysisChangelogJob, false, 0, inst.ExpireInstanceAnalysisChangelog)
+	// CheckAndRecover is not re-entrant, it can only be running once at any point in time.
+	// It must not stop recovering the cluster for long after a panic, so its backoff is
+	// capped at the recovery poll interval.
+	discoveryJobs.register(checkAndRecoverJob, true, time.Duration(config.Config.RecoveryPollSeconds)*time.Second, func() error {
+		CheckAndRecover()
+		return nil
+	})
+	discoveryJobs.register(snapshotTopologiesJob, false, 0, inst.SnapshotTopologies)
+	discoveryJobs.register(refreshAllInformationJob, false, 0, func() error {
+		refreshAllInformation()
+		return nil
+	})
+}
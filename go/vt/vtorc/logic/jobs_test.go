@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSupervisorPanics(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	js := newJobSupervisor()
+	js.now = func() time.Time { return now }
+
+	runs := 0
+	shouldPanic := true
+	js.register("job", false, 0, func() error {
+		runs++
+		if shouldPanic {
+			panic("boom")
+		}
+		return nil
+	})
+	panicsBefore := jobPanics.Counts()["job"]
+
+	// The panic is recovered, and the job backs off.
+	js.run("job")
+	require.Equal(t, 1, runs)
+	statuses := js.statuses()
+	require.Len(t, statuses, 1)
+	assert.EqualValues(t, 1, statuses[0].Panics)
+	assert.Equal(t, "boom", statuses[0].LastPanic)
+	assert.Zero(t, statuses[0].Running)
+	require.NotNil(t, statuses[0].BackoffUntil)
+	assert.Equal(t, now.Add(jobInitialBackoff), *statuses[0].BackoffUntil)
+	assert.EqualValues(t, 1, jobPanics.Counts()["job"]-panicsBefore)
+
+	// The job doesn't run while it backs off.
+	now = now.Add(jobInitialBackoff - time.Second)
+	js.run("job")
+	require.Equal(t, 1, runs)
+
+	// The backoff doubles with the consecutive panics.
+	now = now.Add(time.Second)
+	js.run("job")
+	require.Equal(t, 2, runs)
+	assert.Equal(t, now.Add(2*jobInitialBackoff), *js.statuses()[0].BackoffUntil)
+
+	// The job restarts once it backed off, and a successful run resets the backoff.
+	shouldPanic = false
+	now = now.Add(2 * jobInitialBackoff)
+	js.run("job")
+	require.Equal(t, 3, runs)
+	status := js.statuses()[0]
+	assert.Nil(t, status.BackoffUntil)
+	assert.EqualValues(t, 3, status.Runs)
+	assert.EqualValues(t, 2, status.Panics)
+	assert.Zero(t, js.jobs["job"].consecutivePanics)
+}
+
+func TestJobSupervisorMaxBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	js := newJobSupervisor()
+	js.now = func() time.Time { return now }
+	js.register("job", false, 0, func() error {
+		panic("boom")
+	})
+	for i := 0; i < 20; i++ {
+		js.run("job")
+		now = *js.statuses()[0].BackoffUntil
+	}
+	status := js.statuses()[0]
+	assert.EqualValues(t, 20, status.Panics)
+	assert.Equal(t, jobMaxBackoff, status.BackoffUntil.Sub(status.LastStart))
+}
+
+func TestJobSupervisorCappedBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	js := newJobSupervisor()
+	js.now = func() time.Time { return now }
+	js.register("job", true, time.Second, func() error {
+		panic("boom")
+	})
+	for i := 0; i < 5; i++ {
+		js.run("job")
+		status := js.statuses()[0]
+		assert.Equal(t, time.Second, status.BackoffUntil.Sub(status.LastStart))
+		now = *status.BackoffUntil
+	}
+}
+
+func TestJobSupervisorGoRun(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	js := newJobSupervisor()
+	js.now = func() time.Time { return now }
+	js.register("job", false, 0, func() error {
+		js.goRun("job", func() {
+			panic("boom")
+		})
+		return nil
+	})
+	assert.Empty(t, js.backingOff())
+
+	// The panic of the goroutine started by the job is recovered, and backs
+	// the job off.
+	js.run("job")
+	js.wait()
+	status := js.statuses()[0]
+	assert.EqualValues(t, 1, status.Panics)
+	assert.Equal(t, "boom", status.LastPanic)
+	require.NotNil(t, status.BackoffUntil)
+	assert.Equal(t, []string{"job"}, js.backingOff())
+
+	now = *status.BackoffUntil
+	assert.Empty(t, js.backingOff())
+}
+
+func TestJobSupervisorErrors(t *testing.T) {
+	js := newJobSupervisor()
+	js.register("job", false, 0, func() error {
+		return errors.New("failed")
+	})
+	js.run("job")
+	js.run("job")
+	status := js.statuses()[0]
+	assert.EqualValues(t, 2, status.Runs)
+	assert.EqualValues(t, 2, status.Errors)
+	assert.Equal(t, "failed", status.LastError)
+	// Errors don't back off the job.
+	assert.Nil(t, status.BackoffUntil)
+}
+
+func TestJobSupervisorExclusive(t *testing.T) {
+	js := newJobSupervisor()
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	js.register("exclusive", true, 0, func() error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	js.register("reentrant", false, 0, func() error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	js.start("exclusive")
+	<-started
+	js.start("exclusive")
+	js.start("reentrant")
+	js.start("reentrant")
+	<-started
+	<-started
+
+	statuses := js.statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "exclusive", statuses[0].Name)
+	assert.Equal(t, 1, statuses[0].Running)
+	assert.Equal(t, "reentrant", statuses[1].Name)
+	assert.Equal(t, 2, statuses[1].Running)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		statuses := js.statuses()
+		return statuses[0].Running == 0 && statuses[1].Running == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	statuses = js.statuses()
+	assert.EqualValues(t, 1, statuses[0].Runs)
+	assert.EqualValues(t, 2, statuses[1].Runs)
+
+	// Unknown jobs are ignored.
+	js.run("unknown")
+}
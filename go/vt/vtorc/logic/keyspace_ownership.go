@@ -0,0 +1,371 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// The VTOrcs that share the keyspaces register themselves, and record which
+// of them owns each keyspace, in the global topo.
+const (
+	vtorcMembersPath         = "vtorc_members"
+	vtorcKeyspaceLeadersPath = "vtorc_keyspace_leaders"
+)
+
+var (
+	ownedKeyspaces     = stats.NewGaugesWithSingleLabel("OwnedKeyspaces", "Keyspaces that this VTOrc monitors and repairs, when the keyspaces are shared among the VTOrcs", "Keyspace")
+	keyspaceTakeovers  = stats.NewCountersWithSingleLabel("KeyspaceTakeovers", "Keyspaces that this VTOrc took over from a VTOrc that stopped", "Keyspace")
+	vtorcMembersActive = stats.NewGauge("VTOrcMembers", "Number of live VTOrcs that share the keyspaces")
+
+	keyspaceSharding   bool
+	keyspaceShardingID string
+
+	// ownership is nil unless the keyspaces are shared among the VTOrcs.
+	ownership *keyspaceOwnership
+)
+
+// keyspaceShardingMemberID returns the name under which this VTOrc shares the keyspaces.
+func keyspaceShardingMemberID() string {
+	if keyspaceShardingID != "" {
+		return keyspaceShardingID
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Fatalf("Failed to get the hostname for --keyspace-sharding-id: %v", err)
+	}
+	return fmt.Sprintf("%s-%d", hostname, servenv.Port())
+}
+
+// refreshKeyspaceOwnership refreshes the keyspaces that this VTOrc owns, when
+// the keyspaces are shared among the VTOrcs.
+func refreshKeyspaceOwnership() {
+	if ownership == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+	keyspaces, err := watchedKeyspaces(ctx)
+	if err == nil {
+		err = ownership.refresh(ctx, keyspaces)
+	}
+	if err != nil {
+		// Without knowing which keyspaces are still ours, we stop monitoring all of them.
+		log.Errorf("Failed to refresh the owned keyspaces: %v", err)
+		ownership.setOwned(nil)
+	}
+}
+
+// keyspaceLeader is the record of the VTOrc that owns a keyspace.
+type keyspaceLeader struct {
+	Owner string `json:"owner"`
+}
+
+// keyspaceOwnership shares the keyspaces among the VTOrcs that run with
+// --keyspace-sharding. Each keyspace is assigned to one of the live VTOrcs by
+// rendezvous hashing, so that few keyspaces move when VTOrcs come and go, and
+// the assigned VTOrc only monitors and repairs the keyspace once it holds its
+// leader record. The record is handed over by the previous owner when it is
+// no longer assigned the keyspace, or taken over when the previous owner
+// stopped renewing it.
+//
+// The registrations and the records are renewed on every refresh, which
+// changes their versions. They are stale once their versions didn't change
+// for 3 heartbeats, as observed by this VTOrc, so that the clocks of the
+// VTOrcs don't need to agree.
+type keyspaceOwnership struct {
+	ts *topo.Server
+	id string
+	// members are the registrations of the VTOrcs that share the keyspaces.
+	members *topo.Registry
+	// leaders tells whether the owners still renew the leader records.
+	leaders *topo.LivenessTracker
+	now     func() time.Time
+
+	mu    sync.Mutex
+	owned map[string]bool
+}
+
+// newKeyspaceOwnership returns the ownership of the keyspaces by the VTOrc
+// with the given id, which refreshes it every heartbeat.
+func newKeyspaceOwnership(ts *topo.Server, id string, heartbeat time.Duration) *keyspaceOwnership {
+	return &keyspaceOwnership{
+		ts:      ts,
+		id:      id,
+		members: topo.NewRegistry(ts, topo.GlobalCell, vtorcMembersPath, 3*heartbeat),
+		leaders: topo.NewLivenessTracker(3 * heartbeat),
+		now:     time.Now,
+		owned:   make(map[string]bool),
+	}
+}
+
+// owns returns true if this VTOrc monitors and repairs the keyspace.
+func (ko *keyspaceOwnership) owns(keyspace string) bool {
+	if ko == nil {
+		return true
+	}
+	ko.mu.Lock()
+	defer ko.mu.Unlock()
+	return ko.owned[keyspace]
+}
+
+// filterClusters returns the clusters to watch, in the format of
+// --clusters_to_watch, of the keyspaces that this VTOrc owns. The owned
+// keyspaces are returned when clusters is empty.
+func (ko *keyspaceOwnership) filterClusters(clusters []string) []string {
+	ko.mu.Lock()
+	defer ko.mu.Unlock()
+	var filtered []string
+	if len(clusters) == 0 {
+		for keyspace := range ko.owned {
+			filtered = append(filtered, keyspace)
+		}
+		sort.Strings(filtered)
+		return filtered
+	}
+	for _, cluster := range clusters {
+		keyspace, _, _ := strings.Cut(cluster, "/")
+		if ko.owned[keyspace] {
+			filtered = append(filtered, cluster)
+		}
+	}
+	return filtered
+}
+
+// assignee returns the member that the keyspace is assigned to, by
+// rendezvous hashing: the member with the highest hash of its name and
+// the keyspace.
+func assignee(members []string, keyspace string) string {
+	var best string
+	var bestHash uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(member))
+		_, _ = h.Write([]byte{'/'})
+		_, _ = h.Write([]byte(keyspace))
+		if sum := mix64(h.Sum64()); best == "" || sum > bestHash || (sum == bestHash && member < best) {
+			best, bestHash = member, sum
+		}
+	}
+	return best
+}
+
+// mix64 is the finalizer of MurmurHash3. FNV alone barely spreads the
+// hashes of names that only differ by a character, like vtorc-1 and vtorc-2.
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// refresh registers this VTOrc, then claims, renews or releases the leader
+// records of the keyspaces. The tablets of the keyspaces that this VTOrc
+// no longer owns are forgotten. The keyspaces whose records could not be
+// renewed are no longer owned, so that two VTOrcs never repair the same
+// keyspace.
+func (ko *keyspaceOwnership) refresh(ctx context.Context, keyspaces []string) error {
+	members, err := ko.register(ctx)
+	if err != nil {
+		return err
+	}
+	vtorcMembersActive.Set(int64(len(members)))
+	live := make(map[string]bool, len(members))
+	for _, member := range members {
+		live[member] = true
+	}
+
+	owned := make(map[string]bool, len(keyspaces))
+	for _, keyspace := range keyspaces {
+		ok, err := ko.refreshKeyspace(ctx, keyspace, assignee(members, keyspace), live)
+		if err != nil {
+			log.Warningf("Failed to refresh the ownership of keyspace %v: %v", keyspace, err)
+			continue
+		}
+		if ok {
+			owned[keyspace] = true
+		}
+	}
+	ko.leaders.Retain(keyspaces)
+	ko.setOwned(owned)
+	return nil
+}
+
+// register saves the registration of this VTOrc, and returns the live
+// members, sorted by name. The stale registrations are deleted.
+func (ko *keyspaceOwnership) register(ctx context.Context) ([]string, error) {
+	if err := ko.members.Register(ctx, ko.id, nil); err != nil {
+		return nil, err
+	}
+	registered, err := ko.members.Members(ctx, ko.now())
+	if err != nil {
+		return nil, err
+	}
+	// Clean up after the VTOrcs that didn't unregister. A VTOrc that is
+	// still alive registers itself again on its next refresh.
+	if err := ko.members.DeleteStale(ctx); err != nil {
+		log.Warningf("Failed to delete the stale VTOrc registrations: %v", err)
+	}
+	members := make([]string, 0, len(registered))
+	for _, member := range registered {
+		members = append(members, member.Name)
+	}
+	return members, nil
+}
+
+// refreshKeyspace claims, renews or releases the leader record of the
+// keyspace, and returns true if this VTOrc owns the keyspace. The records
+// are only changed at the version that was read, so that two VTOrcs never
+// claim the same keyspace.
+func (ko *keyspaceOwnership) refreshKeyspace(ctx context.Context, keyspace, assigned string, live map[string]bool) (bool, error) {
+	conn, err := ko.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return false, err
+	}
+	leaderPath := path.Join(vtorcKeyspaceLeadersPath, keyspace)
+	data, version, err := conn.Get(ctx, leaderPath)
+	if topo.IsErrType(err, topo.NoNode) {
+		ko.leaders.Forget(keyspace)
+		if assigned != ko.id {
+			return false, nil
+		}
+		record, err := ko.leaderRecord()
+		if err != nil {
+			return false, err
+		}
+		if _, err := conn.Create(ctx, leaderPath, record); err != nil {
+			return false, err
+		}
+		log.Infof("Claimed the ownership of keyspace %v", keyspace)
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var leader keyspaceLeader
+	if err := json.Unmarshal(data, &leader); err != nil {
+		// A record that can't be decoded is taken over like a stale one.
+		log.Warningf("Invalid VTOrc leader record of keyspace %v: %v", keyspace, err)
+	}
+	renewed := ko.leaders.Observe(keyspace, version, ko.now())
+	switch {
+	case leader.Owner == ko.id && assigned != ko.id:
+		// Hand the keyspace over to the VTOrc it is assigned to.
+		if err := conn.Delete(ctx, leaderPath, version); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return false, err
+		}
+		log.Infof("Released the ownership of keyspace %v to %v", keyspace, assigned)
+		return false, nil
+	case assigned != ko.id:
+		return false, nil
+	case leader.Owner != ko.id && live[leader.Owner] && renewed:
+		// The previous owner hands the keyspace over on its next refresh.
+		return false, nil
+	}
+	record, err := ko.leaderRecord()
+	if err != nil {
+		return false, err
+	}
+	if _, err := conn.Update(ctx, leaderPath, record, version); err != nil {
+		return false, err
+	}
+	if leader.Owner != ko.id {
+		keyspaceTakeovers.Add(keyspace, 1)
+		log.Infof("Took over the ownership of keyspace %v from %v", keyspace, leader.Owner)
+	}
+	return true, nil
+}
+
+func (ko *keyspaceOwnership) leaderRecord() ([]byte, error) {
+	return json.Marshal(&keyspaceLeader{Owner: ko.id})
+}
+
+// setOwned sets the owned keyspaces, and forgets the tablets of the
+// keyspaces that are no longer owned, so that they are not analyzed anymore.
+func (ko *keyspaceOwnership) setOwned(owned map[string]bool) {
+	ko.mu.Lock()
+	previous := ko.owned
+	ko.owned = make(map[string]bool, len(owned))
+	for keyspace := range owned {
+		ko.owned[keyspace] = true
+		ownedKeyspaces.Set(keyspace, 1)
+	}
+	ko.mu.Unlock()
+
+	for keyspace := range previous {
+		if owned[keyspace] {
+			continue
+		}
+		ownedKeyspaces.Reset(keyspace)
+		if err := inst.ForgetKeyspaceTablets(keyspace); err != nil {
+			log.Errorf("Failed to forget the tablets of keyspace %v: %v", keyspace, err)
+		}
+	}
+}
+
+// close releases the keyspaces owned by this VTOrc and unregisters it, so
+// that the other VTOrcs take them over on their next refresh.
+func (ko *keyspaceOwnership) close() {
+	if ko == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+	conn, err := ko.ts.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		log.Warningf("Failed to release the owned keyspaces: %v", err)
+		return
+	}
+	if err := ko.members.Unregister(ctx, ko.id); err != nil && !topo.IsErrType(err, topo.NoNode) {
+		log.Warningf("Failed to unregister from the VTOrcs that share the keyspaces: %v", err)
+	}
+	ko.mu.Lock()
+	defer ko.mu.Unlock()
+	for keyspace := range ko.owned {
+		leaderPath := path.Join(vtorcKeyspaceLeadersPath, keyspace)
+		data, version, err := conn.Get(ctx, leaderPath)
+		if err != nil {
+			continue
+		}
+		var leader keyspaceLeader
+		if err := json.Unmarshal(data, &leader); err != nil || leader.Owner != ko.id {
+			continue
+		}
+		if err := conn.Delete(ctx, leaderPath, version); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			log.Warningf("Failed to release the ownership of keyspace %v: %v", keyspace, err)
+		}
+	}
+	ko.owned = make(map[string]bool)
+}
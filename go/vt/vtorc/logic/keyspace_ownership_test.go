@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtorc/db"
+)
+
+func TestAssignee(t *testing.T) {
+	members := []string{"vtorc-1", "vtorc-2", "vtorc-3"}
+	var keyspaces []string
+	for i := 0; i < 30; i++ {
+		keyspaces = append(keyspaces, fmt.Sprintf("ks%d", i))
+	}
+
+	assigned := make(map[string]string)
+	counts := make(map[string]int)
+	for _, keyspace := range keyspaces {
+		assigned[keyspace] = assignee(members, keyspace)
+		counts[assigned[keyspace]]++
+	}
+	// Every member is assigned some keyspaces.
+	for _, member := range members {
+		require.NotZero(t, counts[member], member)
+	}
+	// Removing a member only moves the keyspaces that were assigned to it.
+	for _, keyspace := range keyspaces {
+		owner := assignee(members[:2], keyspace)
+		if assigned[keyspace] != "vtorc-3" {
+			require.Equal(t, assigned[keyspace], owner, keyspace)
+		}
+	}
+	require.Empty(t, assignee(nil, "ks"))
+}
+
+func TestKeyspaceOwnership(t *testing.T) {
+	db.ClearVTOrcDatabase()
+	defer db.ClearVTOrcDatabase()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	keyspaces := []string{"ks1", "ks2", "ks3", "ks4", "ks5", "ks6", "ks7", "ks8"}
+	now := time.Now()
+	a := newKeyspaceOwnership(ts, "vtorc-a", time.Second)
+	a.now = func() time.Time { return now }
+	b := newKeyspaceOwnership(ts, "vtorc-b", time.Second)
+	b.now = func() time.Time { return now }
+
+	// Alone, the first VTOrc owns all the keyspaces.
+	require.NoError(t, a.refresh(ctx, keyspaces))
+	require.Equal(t, keyspaces, a.filterClusters(nil))
+
+	// Once the second VTOrc joins, the first one hands over the keyspaces
+	// assigned to the second one, which claims them on its next refresh.
+	require.NoError(t, b.refresh(ctx, keyspaces))
+	require.Empty(t, b.filterClusters(nil))
+	require.NoError(t, a.refresh(ctx, keyspaces))
+	require.NoError(t, b.refresh(ctx, keyspaces))
+	members := []string{"vtorc-a", "vtorc-b"}
+	for _, keyspace := range keyspaces {
+		owner := assignee(members, keyspace)
+		require.Equal(t, owner == "vtorc-a", a.owns(keyspace), keyspace)
+		require.Equal(t, owner == "vtorc-b", b.owns(keyspace), keyspace)
+	}
+	require.NotEmpty(t, a.filterClusters(nil))
+	require.NotEmpty(t, b.filterClusters(nil))
+
+	// The clusters to watch are filtered by keyspace.
+	ownedByA := a.filterClusters(nil)[0]
+	require.Equal(t, []string{ownedByA + "/-80", ownedByA}, a.filterClusters([]string{ownedByA + "/-80", ownedByA, "unknown/0"}))
+
+	// When the first VTOrc stops refreshing, the second one takes over its
+	// keyspaces once its records are stale.
+	now = now.Add(2 * time.Second)
+	require.NoError(t, b.refresh(ctx, keyspaces))
+	require.False(t, b.owns(ownedByA))
+	takeovers := keyspaceTakeovers.Counts()[ownedByA]
+	now = now.Add(2 * time.Second)
+	require.NoError(t, b.refresh(ctx, keyspaces))
+	require.Equal(t, keyspaces, b.filterClusters(nil))
+	require.Equal(t, takeovers+1, keyspaceTakeovers.Counts()[ownedByA])
+
+	// A VTOrc that stops hands over its keyspaces at once.
+	b.close()
+	require.Empty(t, b.filterClusters(nil))
+	require.NoError(t, a.refresh(ctx, keyspaces))
+	require.Equal(t, keyspaces, a.filterClusters(nil))
+}
+
+func TestKeyspaceOwnershipClockSkew(t *testing.T) {
+	db.ClearVTOrcDatabase()
+	defer db.ClearVTOrcDatabase()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// The clock of the second VTOrc is an hour ahead of the first one's.
+	keyspaces := []string{"ks1", "ks2", "ks3", "ks4", "ks5", "ks6", "ks7", "ks8"}
+	now := time.Now()
+	a := newKeyspaceOwnership(ts, "vtorc-a", time.Second)
+	a.now = func() time.Time { return now }
+	b := newKeyspaceOwnership(ts, "vtorc-b", time.Second)
+	b.now = func() time.Time { return now.Add(time.Hour) }
+
+	require.NoError(t, a.refresh(ctx, keyspaces))
+	require.NoError(t, b.refresh(ctx, keyspaces))
+	require.NoError(t, a.refresh(ctx, keyspaces))
+	require.NoError(t, b.refresh(ctx, keyspaces))
+
+	// While both VTOrcs refresh, each keyspace is owned by exactly one of them.
+	for i := 0; i < 5; i++ {
+		now = now.Add(2 * time.Second)
+		require.NoError(t, a.refresh(ctx, keyspaces))
+		require.NoError(t, b.refresh(ctx, keyspaces))
+		for _, keyspace := range keyspaces {
+			require.NotEqual(t, a.owns(keyspace), b.owns(keyspace), keyspace)
+		}
+	}
+	require.NotEmpty(t, a.filterClusters(nil))
+	require.NotEmpty(t, b.filterClusters(nil))
+}
+
+func TestKeyspaceOwnershipDisabled(t *testing.T) {
+	var ko *keyspaceOwnership
+	require.True(t, ko.owns("ks"))
+	ko.close()
+}
@@ -18,6 +18,8 @@ package logic
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -30,48 +32,23 @@ import (
 
 // RefreshAllKeyspacesAndShards reloads the keyspace and shard information for the keyspaces that vtorc is concerned with.
 func RefreshAllKeyspacesAndShards() {
-	var keyspaces []string
-	if len(clustersToWatch) == 0 { // all known keyspaces
-		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
-		defer cancel()
-		var err error
-		// Get all the keyspaces
-		keyspaces, err = ts.GetKeyspaces(ctx)
-		if err != nil {
-			log.Error(err)
-			return
-		}
-	} else {
-		// Parse input and build list of keyspaces
-		for _, ks := range clustersToWatch {
-			if strings.Contains(ks, "/") {
-				// This is a keyspace/shard specification
-				input := strings.Split(ks, "/")
-				keyspaces = append(keyspaces, input[0])
-			} else {
-				// Assume this is a keyspace
-				keyspaces = append(keyspaces, ks)
-			}
-		}
-		if len(keyspaces) == 0 {
-			log.Errorf("Found no keyspaces for input: %+v", clustersToWatch)
-			return
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+	keyspaces, err := watchedKeyspaces(ctx)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if ownership != nil {
+		keyspaces = slices.DeleteFunc(keyspaces, func(keyspace string) bool {
+			return !ownership.owns(keyspace)
+		})
 	}
 
-	// Sort the list of keyspaces.
-	// The list can have duplicates because the input to clusters to watch may have multiple shards of the same keyspace
-	sort.Strings(keyspaces)
 	refreshCtx, refreshCancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
 	defer refreshCancel()
 	var wg sync.WaitGroup
-	for idx, keyspace := range keyspaces {
-		// Check if the current keyspace name is the same as the last one.
-		// If it is, then we know we have already refreshed its information.
-		// We do not need to do it again.
-		if idx != 0 && keyspace == keyspaces[idx-1] {
-			continue
-		}
+	for _, keyspace := range keyspaces {
 		wg.Add(2)
 		go func(keyspace string) {
 			defer wg.Done()
@@ -85,6 +62,38 @@ func RefreshAllKeyspacesAndShards() {
 	wg.Wait()
 }
 
+// watchedKeyspaces returns the sorted keyspaces that vtorc is concerned with, from
+// the clusters to watch or, if none, from the topo.
+func watchedKeyspaces(ctx context.Context) ([]string, error) {
+	if len(clustersToWatch) == 0 { // all known keyspaces
+		keyspaces, err := ts.GetKeyspaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(keyspaces)
+		return keyspaces, nil
+	}
+	// Parse input and build list of keyspaces
+	var keyspaces []string
+	for _, ks := range clustersToWatch {
+		if strings.Contains(ks, "/") {
+			// This is a keyspace/shard specification
+			input := strings.Split(ks, "/")
+			keyspaces = append(keyspaces, input[0])
+		} else {
+			// Assume this is a keyspace
+			keyspaces = append(keyspaces, ks)
+		}
+	}
+	if len(keyspaces) == 0 {
+		return nil, fmt.Errorf("found no keyspaces for input: %+v", clustersToWatch)
+	}
+	// Sort the list of keyspaces, and remove the duplicates that come from
+	// multiple shards of the same keyspace in the clusters to watch.
+	sort.Strings(keyspaces)
+	return slices.Compact(keyspaces), nil
+}
+
 // RefreshKeyspaceAndShard refreshes the keyspace record and shard record for the given keyspace and shard.
 func RefreshKeyspaceAndShard(keyspaceName string, shardName string) error {
 	err := refreshKeyspace(keyspaceName)
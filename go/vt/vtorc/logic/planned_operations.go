@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/constants/sidecar"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const (
+	// recentPlannedReparentQuery reads the last PlannedReparentShard recorded in the reparent journal after the given time.
+	recentPlannedReparentQuery = "select primary_alias, time_created_ns from %s.reparent_journal where action_name = 'PlannedReparentShard' and time_created_ns >= %d order by time_created_ns desc limit 1"
+	// recentCutOverQuery reads a running online DDL migration which attempted its cut-over in the last given seconds.
+	recentCutOverQuery = "select migration_uuid, mysql_table from %s.schema_migrations where migration_status = 'running' and last_cutover_attempt_timestamp >= now() - interval %d second limit 1"
+)
+
+// recentPlannedOperation returns a description of the planned maintenance operation that was run on the shard of the
+// dead primary within the planned operations grace period, or that is still in progress, if any. A reparent is in
+// progress when the shard record already names another primary. Otherwise the operations are read from the other
+// tablets of the shard, in parallel: a PlannedReparentShard leaves a row in the reparent journal of the new primary and
+// an online DDL cut-over updates the migration record, and both are replicated from the primary. The new primary of
+// a reparent that is still in progress is read too, since the replicas might not have its journal row yet. An empty
+// string is returned if no tablet could be read, so that the recovery is not blocked by the failure.
+func recentPlannedOperation(ctx context.Context, keyspace, shard, deadPrimaryAlias string) string {
+	gracePeriod := config.PlannedOperationsGracePeriod()
+	if gracePeriod <= 0 {
+		return ""
+	}
+
+	si, err := ts.GetShard(ctx, keyspace, shard)
+	if err != nil {
+		log.Errorf("recentPlannedOperation: error fetching shard %v/%v: %v", keyspace, shard, err)
+		return ""
+	}
+	if si.PrimaryAlias != nil && topoproto.TabletAliasString(si.PrimaryAlias) != deadPrimaryAlias {
+		return fmt.Sprintf("reparent to %v in progress", topoproto.TabletAliasString(si.PrimaryAlias))
+	}
+
+	sidecarDBName := sidecar.DefaultName
+	if ki, err := ts.GetKeyspace(ctx, keyspace); err == nil && ki.SidecarDbName != "" {
+		sidecarDBName = ki.SidecarDbName
+	}
+	queries := []string{
+		fmt.Sprintf(recentPlannedReparentQuery, sqlescape.EscapeID(sidecarDBName), time.Now().Add(-gracePeriod).UnixNano()),
+		fmt.Sprintf(recentCutOverQuery, sqlescape.EscapeID(sidecarDBName), int64(math.Ceil(gracePeriod.Seconds()))),
+	}
+
+	tablets, err := ts.GetTabletMapForShard(ctx, keyspace, shard)
+	if err != nil {
+		log.Errorf("recentPlannedOperation: error fetching tablets for keyspace/shard %v/%v: %v", keyspace, shard, err)
+		return ""
+	}
+	aliases := make([]string, 0, len(tablets))
+	for alias, tabletInfo := range tablets {
+		if alias == deadPrimaryAlias || (tabletInfo.Type != topodatapb.TabletType_PRIMARY && !topo.IsReplicaType(tabletInfo.Type)) {
+			continue
+		}
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	operations := make([]string, len(aliases))
+	var wg sync.WaitGroup
+	for i, alias := range aliases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			operation, err := readPlannedOperation(ctx, tablets[alias].Tablet, queries)
+			if err != nil {
+				log.Warningf("recentPlannedOperation: error reading the planned operations from %v: %v", alias, err)
+				return
+			}
+			operations[i] = operation
+		}()
+	}
+	wg.Wait()
+	for _, operation := range operations {
+		if operation != "" {
+			return operation
+		}
+	}
+	return ""
+}
+
+// readPlannedOperation runs the queries of the planned operations on the tablet, and describes the first operation found.
+func readPlannedOperation(ctx context.Context, tablet *topodatapb.Tablet, queries []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+
+	var operations [2]sqltypes.Row
+	for i, query := range queries {
+		qr, err := tmc.ExecuteFetchAsDba(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return "", err
+		}
+		if rows := sqltypes.Proto3ToResult(qr).Rows; len(rows) > 0 {
+			operations[i] = rows[0]
+		}
+	}
+
+	if row := operations[0]; row != nil {
+		createdNs, _ := row[1].ToInt64()
+		return fmt.Sprintf("PlannedReparentShard to %s at %v", row[0].ToString(), time.Unix(0, createdNs).UTC().Format(time.RFC3339)), nil
+	}
+	if row := operations[1]; row != nil {
+		return fmt.Sprintf("online DDL cut-over of migration %s on table %s", row[0].ToString(), row[1].ToString()), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// plannedOperationsTMC answers the queries of the planned operations with the given results, and records them.
+type plannedOperationsTMC struct {
+	tmclient.TabletManagerClient
+
+	mu             sync.Mutex
+	queries        []string
+	failingTablets map[string]bool
+	reparent       *sqltypes.Result
+	// reparentTablets, when set, are the only tablets that have the reparent journal row.
+	reparentTablets map[string]bool
+	cutOver         *sqltypes.Result
+
+	// concurrent, when set, holds the queries until that many of them run at once.
+	concurrent int
+	running    int
+	allRunning chan struct{}
+}
+
+func (fake *plannedOperationsTMC) ExecuteFetchAsDba(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error) {
+	if fake.concurrent > 0 {
+		fake.mu.Lock()
+		fake.running++
+		if fake.running == fake.concurrent {
+			close(fake.allRunning)
+		}
+		fake.mu.Unlock()
+		select {
+		case <-fake.allRunning:
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	alias := topoproto.TabletAliasString(tablet.Alias)
+	query := string(req.Query)
+	fake.queries = append(fake.queries, query)
+	if fake.failingTablets[alias] {
+		return nil, errors.New("tablet unreachable")
+	}
+	switch {
+	case strings.HasPrefix(query, "select primary_alias") && fake.reparent != nil && (fake.reparentTablets == nil || fake.reparentTablets[alias]):
+		return sqltypes.ResultToProto3(fake.reparent), nil
+	case strings.HasPrefix(query, "select migration_uuid") && fake.cutOver != nil:
+		return sqltypes.ResultToProto3(fake.cutOver), nil
+	}
+	return &querypb.QueryResult{}, nil
+}
+
+func TestRecentPlannedOperation(t *testing.T) {
+	oldTs, oldTmc := ts, tmc
+	defer func() {
+		ts, tmc = oldTs, oldTmc
+		config.SetPlannedOperationsGracePeriod(0)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{SidecarDbName: "_vt_custom"}))
+	_, err := ts.GetOrCreateShard(ctx, keyspace, shard)
+	require.NoError(t, err)
+	for _, tablet := range []*topodatapb.Tablet{tab100, tab101, tab102} {
+		require.NoError(t, ts.CreateTablet(ctx, tablet))
+	}
+	primaryAlias := topoproto.TabletAliasString(tab100.Alias)
+	reparentedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		fake     *plannedOperationsTMC
+		expected string
+	}{{
+		name: "no planned operation",
+		fake: &plannedOperationsTMC{},
+	}, {
+		name: "planned reparent",
+		fake: &plannedOperationsTMC{
+			reparent: sqltypes.MakeTestResult(sqltypes.MakeTestFields("primary_alias|time_created_ns", "varchar|int64"),
+				"zone-1-0000000100|"+sqltypes.NewInt64(reparentedAt.UnixNano()).ToString()),
+		},
+		expected: "PlannedReparentShard to zone-1-0000000100 at 2024-03-01T10:00:00Z",
+	}, {
+		name: "online DDL cut-over",
+		fake: &plannedOperationsTMC{
+			cutOver: sqltypes.MakeTestResult(sqltypes.MakeTestFields("migration_uuid|mysql_table", "varchar|varchar"),
+				"8a797518_f25c_11ea_bab4_0242c0a8b007|t1"),
+		},
+		expected: "online DDL cut-over of migration 8a797518_f25c_11ea_bab4_0242c0a8b007 on table t1",
+	}, {
+		name: "one replica unreachable",
+		fake: &plannedOperationsTMC{
+			failingTablets: map[string]bool{topoproto.TabletAliasString(tab101.Alias): true},
+			cutOver: sqltypes.MakeTestResult(sqltypes.MakeTestFields("migration_uuid|mysql_table", "varchar|varchar"),
+				"8a797518_f25c_11ea_bab4_0242c0a8b007|t1"),
+		},
+		expected: "online DDL cut-over of migration 8a797518_f25c_11ea_bab4_0242c0a8b007 on table t1",
+	}, {
+		name: "all replicas unreachable",
+		fake: &plannedOperationsTMC{
+			failingTablets: map[string]bool{
+				topoproto.TabletAliasString(tab101.Alias): true,
+				topoproto.TabletAliasString(tab102.Alias): true,
+			},
+		},
+	}}
+
+	t.Run("disabled", func(t *testing.T) {
+		fake := &plannedOperationsTMC{}
+		tmc = fake
+		assert.Empty(t, recentPlannedOperation(ctx, keyspace, shard, primaryAlias))
+		assert.Empty(t, fake.queries)
+	})
+
+	config.SetPlannedOperationsGracePeriod(90 * time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmc = tt.fake
+			assert.Equal(t, tt.expected, recentPlannedOperation(ctx, keyspace, shard, primaryAlias))
+			require.NotEmpty(t, tt.fake.queries)
+			for _, query := range tt.fake.queries {
+				assert.Contains(t, query, "from `_vt_custom`.")
+			}
+		})
+	}
+
+	t.Run("replicas queried in parallel", func(t *testing.T) {
+		fake := &plannedOperationsTMC{concurrent: 2, allRunning: make(chan struct{})}
+		tmc = fake
+		assert.Empty(t, recentPlannedOperation(ctx, keyspace, shard, primaryAlias))
+		select {
+		case <-fake.allRunning:
+		default:
+			assert.Fail(t, "the replicas weren't queried in parallel")
+		}
+	})
+
+	// The journal row of a reparent in progress might only be on the new primary.
+	_, err = ts.UpdateTabletFields(ctx, tab102.Alias, func(tablet *topodatapb.Tablet) error {
+		tablet.Type = topodatapb.TabletType_PRIMARY
+		return nil
+	})
+	require.NoError(t, err)
+	tmc = &plannedOperationsTMC{
+		reparent: sqltypes.MakeTestResult(sqltypes.MakeTestFields("primary_alias|time_created_ns", "varchar|int64"),
+			"zone-1-0000000102|"+sqltypes.NewInt64(reparentedAt.UnixNano()).ToString()),
+		reparentTablets: map[string]bool{topoproto.TabletAliasString(tab102.Alias): true},
+	}
+	assert.Equal(t, "PlannedReparentShard to zone-1-0000000102 at 2024-03-01T10:00:00Z", recentPlannedOperation(ctx, keyspace, shard, primaryAlias))
+
+	// Once the shard record names the new primary, the reparent is in progress.
+	_, err = ts.UpdateShardFields(ctx, keyspace, shard, func(si *topo.ShardInfo) error {
+		si.PrimaryAlias = tab102.Alias
+		return nil
+	})
+	require.NoError(t, err)
+	tmc = &plannedOperationsTMC{}
+	assert.Equal(t, "reparent to zone-1-0000000102 in progress", recentPlannedOperation(ctx, keyspace, shard, primaryAlias))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// maxPollNowSnapshots is the number of poll now snapshots that are remembered,
+// so that their status can be read. The oldest ones are forgotten first.
+const maxPollNowSnapshots = 10
+
+var (
+	// ErrDiscoveryNotRunning is returned when a poll now is requested before the discovery is started.
+	ErrDiscoveryNotRunning = errors.New("instance discovery is not running")
+
+	pollNow = newPollNowTracker()
+)
+
+// PollNowStatus is the status of a poll now snapshot, i.e. of the discovery of all
+// the instances known when it was requested.
+type PollNowStatus struct {
+	Token       string
+	RequestedAt time.Time
+	CompletedAt *time.Time
+	Instances   int
+	Pending     int
+	Completed   bool
+}
+
+// pollNowSnapshot is a poll now request and the instances it is still waiting for.
+type pollNowSnapshot struct {
+	token       string
+	requestedAt time.Time
+	completedAt time.Time
+	instances   int
+	pending     map[string]bool
+}
+
+func (s *pollNowSnapshot) status() *PollNowStatus {
+	status := &PollNowStatus{
+		Token:       s.token,
+		RequestedAt: s.requestedAt,
+		Instances:   s.instances,
+		Pending:     len(s.pending),
+		Completed:   len(s.pending) == 0,
+	}
+	if status.Completed {
+		completedAt := s.completedAt
+		status.CompletedAt = &completedAt
+	}
+	return status
+}
+
+// pollNowTracker tracks the poll now snapshots, and the instances that they
+// wait for. The instances of a snapshot are discovered by the regular
+// discovery workers, so they respect the discovery concurrency limits.
+type pollNowTracker struct {
+	mu        sync.Mutex
+	seq       int64
+	snapshots []*pollNowSnapshot
+}
+
+func newPollNowTracker() *pollNowTracker {
+	return &pollNowTracker{}
+}
+
+// add starts a snapshot of the given instances and returns it.
+func (t *pollNowTracker) add(tabletAliases []string, now time.Time) *pollNowSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	snapshot := &pollNowSnapshot{
+		token:       fmt.Sprintf("%d-%d", now.UnixNano(), t.seq),
+		requestedAt: now,
+		completedAt: now,
+		pending:     make(map[string]bool, len(tabletAliases)),
+	}
+	for _, tabletAlias := range tabletAliases {
+		snapshot.pending[tabletAlias] = true
+	}
+	snapshot.instances = len(snapshot.pending)
+	t.snapshots = append(t.snapshots, snapshot)
+	if len(t.snapshots) > maxPollNowSnapshots {
+		t.snapshots = t.snapshots[len(t.snapshots)-maxPollNowSnapshots:]
+	}
+	return snapshot
+}
+
+// isPending returns whether the instance is waited for by a snapshot. Such an
+// instance is discovered even if it was checked recently.
+func (t *pollNowTracker) isPending(tabletAlias string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, snapshot := range t.snapshots {
+		if snapshot.pending[tabletAlias] {
+			return true
+		}
+	}
+	return false
+}
+
+// discovered marks the instance as discovered in all the snapshots.
+func (t *pollNowTracker) discovered(tabletAlias string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, snapshot := range t.snapshots {
+		if !snapshot.pending[tabletAlias] {
+			continue
+		}
+		delete(snapshot.pending, tabletAlias)
+		if len(snapshot.pending) == 0 {
+			snapshot.completedAt = now
+		}
+	}
+}
+
+// status returns the status of the snapshot with the given token, or nil if it is unknown.
+func (t *pollNowTracker) status(token string) *PollNowStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, snapshot := range t.snapshots {
+		if snapshot.token == token {
+			return snapshot.status()
+		}
+	}
+	return nil
+}
+
+// RequestPollNow enqueues the discovery of all the known instances, even the
+// ones that were checked recently, and returns the status of the snapshot.
+// Its token can then be used to poll for the completion of the snapshot with
+// GetPollNowStatus.
+func RequestPollNow() (*PollNowStatus, error) {
+	queue := discoveryQueue
+	if queue == nil {
+		return nil, ErrDiscoveryNotRunning
+	}
+	tabletAliases, err := inst.ReadAllInstanceKeys()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := pollNow.add(tabletAliases, time.Now())
+	status := pollNow.status(snapshot.token)
+	log.Infof("Poll now %s: discovering %d instances", snapshot.token, status.Instances)
+	_ = inst.AuditOperation("poll-now", "", fmt.Sprintf("Requested the discovery of %d instances, token %s", status.Instances, snapshot.token))
+
+	// Pushing to the queue blocks while it is full, so it mustn't hold up the caller.
+	go pushForDiscovery(queue, tabletAliases)
+	return status, nil
+}
+
+// GetPollNowStatus returns the status of the poll now snapshot with the given
+// token, or nil if there is no such snapshot.
+func GetPollNowStatus(token string) *PollNowStatus {
+	return pollNow.status(token)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/discovery"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+func TestPollNowTracker(t *testing.T) {
+	tracker := newPollNowTracker()
+	now := time.Now()
+	first := tracker.add([]string{"zone1-100", "zone1-101"}, now)
+	second := tracker.add([]string{"zone1-101"}, now)
+	require.NotEqual(t, first.token, second.token)
+
+	status := tracker.status(first.token)
+	require.NotNil(t, status)
+	assert.Equal(t, 2, status.Instances)
+	assert.Equal(t, 2, status.Pending)
+	assert.False(t, status.Completed)
+	assert.Nil(t, status.CompletedAt)
+	assert.True(t, tracker.isPending("zone1-100"))
+	assert.False(t, tracker.isPending("zone1-102"))
+
+	// An instance is discovered for all the snapshots waiting for it.
+	completedAt := now.Add(time.Second)
+	tracker.discovered("zone1-101", completedAt)
+	assert.Equal(t, 1, tracker.status(first.token).Pending)
+	status = tracker.status(second.token)
+	assert.True(t, status.Completed)
+	require.NotNil(t, status.CompletedAt)
+	assert.Equal(t, completedAt, *status.CompletedAt)
+
+	tracker.discovered("zone1-100", completedAt)
+	assert.True(t, tracker.status(first.token).Completed)
+	assert.False(t, tracker.isPending("zone1-100"))
+	assert.Nil(t, tracker.status("unknown"))
+
+	// Only the latest snapshots are remembered.
+	for i := 0; i < maxPollNowSnapshots; i++ {
+		tracker.add([]string{fmt.Sprintf("zone1-%d", i)}, now)
+	}
+	assert.Nil(t, tracker.status(first.token))
+	assert.Len(t, tracker.snapshots, maxPollNowSnapshots)
+}
+
+func TestRequestPollNow(t *testing.T) {
+	oldQueue, oldTracker, oldKeys := discoveryQueue, pollNow, recentDiscoveryOperationKeys
+	defer func() {
+		discoveryQueue, pollNow, recentDiscoveryOperationKeys = oldQueue, oldTracker, oldKeys
+		db.ClearVTOrcDatabase()
+	}()
+	pollNow = newPollNowTracker()
+	recentDiscoveryOperationKeys = cache.New(time.Minute, time.Second)
+
+	discoveryQueue = nil
+	_, err := RequestPollNow()
+	require.ErrorIs(t, err, ErrDiscoveryNotRunning)
+
+	// The instances are read once the forgotten instances cache is initialized.
+	config.MarkConfigurationLoaded()
+	require.Eventually(t, func() bool {
+		_, err := inst.ReadAllInstanceKeys()
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	discoveryQueue = discovery.CreateOrReturnQueue("TestRequestPollNow")
+	tablets := []string{topoproto.TabletAliasString(tab100.Alias), topoproto.TabletAliasString(tab101.Alias)}
+	require.NoError(t, inst.SaveTablet(tab100))
+	require.NoError(t, inst.SaveTablet(tab101))
+
+	status, err := RequestPollNow()
+	require.NoError(t, err)
+	assert.NotEmpty(t, status.Token)
+	assert.Equal(t, 2, status.Instances)
+	assert.Equal(t, 2, status.Pending)
+
+	// All the known instances are enqueued, and the snapshot completes once they are discovered.
+	var discovered []string
+	for range tablets {
+		tabletAlias := discoveryQueue.Consume()
+		assert.True(t, pollNow.isPending(tabletAlias))
+		discovered = append(discovered, tabletAlias)
+		discoveryQueue.Release(tabletAlias)
+		pollNow.discovered(tabletAlias, time.Now())
+	}
+	assert.ElementsMatch(t, tablets, discovered)
+	status = GetPollNowStatus(status.Token)
+	require.NotNil(t, status)
+	assert.True(t, status.Completed)
+	assert.Zero(t, status.Pending)
+
+	// An instance stays pending until its discovery succeeds.
+	unknown := "zone-1-0000000404"
+	snapshot := pollNow.add([]string{unknown}, time.Now())
+	discoverQueuedInstance(unknown)
+	assert.True(t, pollNow.isPending(unknown))
+	assert.False(t, pollNow.status(snapshot.token).Completed)
+}
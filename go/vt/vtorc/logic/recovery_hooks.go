@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/hooks"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+// recoveryHooks runs the hooks configured on the recoveries.
+var recoveryHooks = hooks.NewHookRunner()
+
+func init() {
+	// The asynchronous hooks are given a chance to complete when VTOrc shuts down.
+	RegisterShutdownHook("recovery hooks", func(ctx context.Context) error {
+		return recoveryHooks.Wait(ctx)
+	})
+}
+
+// newHookEvent returns the details of the recovery of the analysis given to
+// the hooks of the phase. The topology recovery is only set once the recovery
+// is done.
+func newHookEvent(phase string, analysisEntry *inst.ReplicationAnalysis, topologyRecovery *TopologyRecovery) *hooks.Event {
+	event := &hooks.Event{
+		Phase:             phase,
+		Analysis:          string(analysisEntry.Analysis),
+		Keyspace:          analysisEntry.AnalyzedKeyspace,
+		Shard:             analysisEntry.AnalyzedShard,
+		FailedTabletAlias: analysisEntry.AnalyzedInstanceAlias,
+	}
+	if topologyRecovery != nil {
+		event.RecoveryID = topologyRecovery.ID
+		event.SuccessorTabletAlias = topologyRecovery.SuccessorAlias
+		event.IsSuccessful = topologyRecovery.IsSuccessful
+		for _, err := range topologyRecovery.AllErrors {
+			if err != "" {
+				event.Errors = append(event.Errors, err)
+			}
+		}
+	}
+	return event
+}
+
+// runFailureDetectionHooks runs the hooks of the failure about to be recovered.
+// They all run in the background, not to delay the recovery while the shard is
+// locked, and their failures are only logged.
+func runFailureDetectionHooks(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) {
+	event := newHookEvent(hooks.PhaseFailureDetection, analysisEntry, nil)
+	detectionHooks := make([]config.RecoveryHook, 0, len(config.Config.OnFailureDetectionHooks))
+	for _, hook := range config.Config.OnFailureDetectionHooks {
+		hook.Async = true
+		detectionHooks = append(detectionHooks, hook)
+	}
+	if err := recoveryHooks.Run(ctx, detectionHooks, event); err != nil {
+		log.Errorf("Failure detection hooks of %v on %v: %v", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias, err)
+	}
+}
+
+// runPreRecoveryHooks runs the hooks that run before a recovery. A failure of
+// a synchronous hook is returned, to abort the recovery.
+func runPreRecoveryHooks(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) error {
+	return recoveryHooks.Run(ctx, config.Config.PreRecoveryHooks, newHookEvent(hooks.PhasePreRecovery, analysisEntry, nil))
+}
+
+// runPostRecoveryHooks runs the hooks of a recovery that is done, once the
+// shard is unlocked. Their failures are only logged.
+func runPostRecoveryHooks(ctx context.Context, analysisEntry *inst.ReplicationAnalysis, topologyRecovery *TopologyRecovery) {
+	event := newHookEvent(hooks.PhasePostRecovery, analysisEntry, topologyRecovery)
+	if err := recoveryHooks.Run(ctx, config.Config.PostRecoveryHooks, event); err != nil {
+		log.Errorf("Post recovery hooks of %v on %v: %v", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias, err)
+	}
+}
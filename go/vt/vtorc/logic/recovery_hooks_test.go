@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/vt/vtorc/hooks"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+func TestNewHookEvent(t *testing.T) {
+	analysisEntry := &inst.ReplicationAnalysis{
+		AnalyzedInstanceAlias: "zone1-0000000100",
+		AnalyzedKeyspace:      keyspace,
+		AnalyzedShard:         shard,
+		Analysis:              inst.DeadPrimary,
+	}
+
+	assert.Equal(t, &hooks.Event{
+		Phase:             hooks.PhasePreRecovery,
+		Analysis:          string(inst.DeadPrimary),
+		Keyspace:          keyspace,
+		Shard:             shard,
+		FailedTabletAlias: "zone1-0000000100",
+	}, newHookEvent(hooks.PhasePreRecovery, analysisEntry, nil))
+
+	// Once the recovery is done, its outcome is included.
+	topologyRecovery := NewTopologyRecovery(*analysisEntry)
+	topologyRecovery.ID = 7
+	topologyRecovery.SuccessorAlias = "zone1-0000000101"
+	topologyRecovery.AllErrors = []string{"", "replica zone1-0000000102 failed to reparent"}
+	assert.Equal(t, &hooks.Event{
+		Phase:                hooks.PhasePostRecovery,
+		Analysis:             string(inst.DeadPrimary),
+		Keyspace:             keyspace,
+		Shard:                shard,
+		FailedTabletAlias:    "zone1-0000000100",
+		SuccessorTabletAlias: "zone1-0000000101",
+		RecoveryID:           7,
+		Errors:               []string{"replica zone1-0000000102 failed to reparent"},
+	}, newHookEvent(hooks.PhasePostRecovery, analysisEntry, topologyRecovery))
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// maxRecoverySimulations is the number of recovery simulations that are
+// remembered, so that their outcome can be read. The oldest ones are
+// forgotten first.
+const maxRecoverySimulations = 100
+
+// The states of a recovery simulation.
+const (
+	RecoverySimulationPending   = "pending"
+	RecoverySimulationRunning   = "running"
+	RecoverySimulationCompleted = "completed"
+	RecoverySimulationFailed    = "failed"
+	RecoverySimulationSkipped   = "skipped"
+)
+
+var (
+	// ErrRecoverySimulationDisabled is returned when an analysis is simulated while --allow-recovery-simulation is unset.
+	ErrRecoverySimulationDisabled = errors.New("recovery simulation is disabled, it requires --allow-recovery-simulation")
+
+	recoverySimulations = newRecoverySimulationTracker()
+
+	// simulatedRecoveriesCounter counts the finished recovery simulations, which aren't counted with the recoveries.
+	simulatedRecoveriesCounter = stats.NewCountersWithMultiLabels("SimulatedRecoveries", "Count of the finished recovery simulations", []string{
+		"Analysis",
+		"DryRun",
+		"State",
+	})
+)
+
+// RecoverySimulation is an analysis injected into the recovery pipeline, so
+// that recovery drills can run without breaking MySQL. The analysis is
+// handled once by the next recovery run, exactly like one that was detected,
+// except that it is never considered as already fixed. In dry run mode, the
+// pipeline stops right before the recovery itself.
+type RecoverySimulation struct {
+	ID          int64
+	Analysis    inst.AnalysisCode
+	TabletAlias string
+	Keyspace    string
+	Shard       string
+	DryRun      bool
+	State       string
+	Error       string `json:",omitempty"`
+	// SkipReason is why the recovery of the analysis was skipped, e.g.
+	// because the recoveries are disabled.
+	SkipReason  string `json:",omitempty"`
+	RequestedAt time.Time
+	FinishedAt  *time.Time      `json:",omitempty"`
+	Runbook     *config.Runbook `json:",omitempty"`
+
+	entry *inst.ReplicationAnalysis
+}
+
+// recoverySimulationTracker tracks the recovery simulations, from their
+// request until the recovery of their analysis finishes.
+type recoverySimulationTracker struct {
+	mu          sync.Mutex
+	seq         int64
+	simulations []*RecoverySimulation
+}
+
+func newRecoverySimulationTracker() *recoverySimulationTracker {
+	return &recoverySimulationTracker{}
+}
+
+// add adds a pending simulation of the given analysis of the tablet, and
+// returns a copy of it.
+func (t *recoverySimulationTracker) add(analysis inst.AnalysisCode, tablet *topodatapb.Tablet, dryRun bool, now time.Time) RecoverySimulation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	tabletAlias := topoproto.TabletAliasString(tablet.Alias)
+	simulation := &RecoverySimulation{
+		ID:          t.seq,
+		Analysis:    analysis,
+		TabletAlias: tabletAlias,
+		Keyspace:    tablet.Keyspace,
+		Shard:       tablet.Shard,
+		DryRun:      dryRun,
+		State:       RecoverySimulationPending,
+		RequestedAt: now,
+		Runbook:     config.RunbookFor(string(analysis)),
+		entry: &inst.ReplicationAnalysis{
+			AnalyzedInstanceAlias: tabletAlias,
+			TabletType:            tablet.Type,
+			ClusterDetails:        inst.ClusterInfo{Keyspace: tablet.Keyspace, Shard: tablet.Shard},
+			AnalyzedKeyspace:      tablet.Keyspace,
+			AnalyzedShard:         tablet.Shard,
+			IsPrimary:             tablet.Type == topodatapb.TabletType_PRIMARY,
+			IsClusterPrimary:      tablet.Type == topodatapb.TabletType_PRIMARY,
+			Analysis:              analysis,
+			Description:           fmt.Sprintf("Simulated through the recovery simulation API, simulation %d", t.seq),
+		},
+	}
+	t.simulations = append(t.simulations, simulation)
+	if len(t.simulations) > maxRecoverySimulations {
+		t.simulations = t.simulations[len(t.simulations)-maxRecoverySimulations:]
+	}
+	return *simulation
+}
+
+// start marks the pending simulations as running, and returns their analyses
+// by simulation, so that the recovery run handles them once.
+func (t *recoverySimulationTracker) start() map[*inst.ReplicationAnalysis]*RecoverySimulation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := make(map[*inst.ReplicationAnalysis]*RecoverySimulation)
+	for _, simulation := range t.simulations {
+		if simulation.State == RecoverySimulationPending {
+			simulation.State = RecoverySimulationRunning
+			entries[simulation.entry] = simulation
+		}
+	}
+	return entries
+}
+
+// running returns the running simulation of the analysis, or nil if the
+// analysis was detected rather than simulated.
+func (t *recoverySimulationTracker) running(entry *inst.ReplicationAnalysis) *RecoverySimulation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, simulation := range t.simulations {
+		if simulation.entry == entry && simulation.State == RecoverySimulationRunning {
+			return simulation
+		}
+	}
+	return nil
+}
+
+// skip records why the recovery of the simulated analysis is skipped. It
+// does nothing if the analysis was detected rather than simulated.
+func (t *recoverySimulationTracker) skip(simulation *RecoverySimulation, reason string) {
+	if simulation == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	simulation.SkipReason = reason
+}
+
+// finish records the outcome of the recovery of the simulated analysis.
+func (t *recoverySimulationTracker) finish(simulation *RecoverySimulation, err error, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case err != nil:
+		simulation.State = RecoverySimulationFailed
+		simulation.Error = err.Error()
+	case simulation.SkipReason != "":
+		simulation.State = RecoverySimulationSkipped
+	default:
+		simulation.State = RecoverySimulationCompleted
+	}
+	simulation.FinishedAt = &now
+	simulatedRecoveriesCounter.Add([]string{string(simulation.Analysis), strconv.FormatBool(simulation.DryRun), simulation.State}, 1)
+}
+
+// list returns a copy of the simulations, the most recent last.
+func (t *recoverySimulationTracker) list() []RecoverySimulation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	simulations := make([]RecoverySimulation, 0, len(t.simulations))
+	for _, simulation := range t.simulations {
+		simulations = append(simulations, *simulation)
+	}
+	return simulations
+}
+
+// SimulateAnalysis injects the given analysis of a tablet into the next
+// recovery run, which then handles it like a detected one. When the tablet
+// alias is empty, the analysis is simulated on the primary of the shard, which
+// is what the primary analyses like DeadPrimary expect. It is only allowed
+// with --allow-recovery-simulation, since the recoveries act on the cluster
+// unless dryRun is set.
+func SimulateAnalysis(analysis inst.AnalysisCode, tabletAlias string, keyspace string, shard string, dryRun bool) (*RecoverySimulation, error) {
+	if !config.AllowRecoverySimulation() {
+		return nil, ErrRecoverySimulationDisabled
+	}
+	var tablet *topodatapb.Tablet
+	var err error
+	if tabletAlias != "" {
+		tablet, err = inst.ReadTablet(tabletAlias)
+	} else {
+		tablet, err = shardPrimary(keyspace, shard)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if getCheckAndRecoverFunctionCode(analysis, topoproto.TabletAliasString(tablet.Alias)) == noRecoveryFunc {
+		return nil, fmt.Errorf("VTOrc has no recovery for analysis %q", analysis)
+	}
+
+	simulation := recoverySimulations.add(analysis, tablet, dryRun, time.Now())
+	log.Infof("Recovery simulation %d: simulating %v on %v, dry run: %v", simulation.ID, analysis, simulation.TabletAlias, dryRun)
+	_ = inst.AuditOperation("simulate-analysis", simulation.TabletAlias, fmt.Sprintf("Simulating %v, dry run: %v, simulation %d", analysis, dryRun, simulation.ID))
+	return &simulation, nil
+}
+
+// GetRecoverySimulations returns the recent recovery simulations and their
+// outcome, the most recent last.
+func GetRecoverySimulations() []RecoverySimulation {
+	return recoverySimulations.list()
+}
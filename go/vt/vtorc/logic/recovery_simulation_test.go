@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestSimulateAnalysis(t *testing.T) {
+	orcDb, err := db.OpenVTOrc()
+	require.NoError(t, err)
+	oldTs, oldSimulations := ts, recoverySimulations
+	defer func() {
+		ts, recoverySimulations = oldTs, oldSimulations
+		config.SetAllowRecoverySimulation(false)
+		_, err = orcDb.Exec("delete from vitess_tablet")
+		require.NoError(t, err)
+	}()
+	recoverySimulations = newRecoverySimulationTracker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	_, err = ts.GetOrCreateShard(ctx, keyspace, shard)
+	require.NoError(t, err)
+	require.NoError(t, ts.CreateTablet(ctx, tab100))
+	require.NoError(t, inst.SaveTablet(tab100))
+	primaryAlias := topoproto.TabletAliasString(tab100.Alias)
+
+	_, err = SimulateAnalysis(inst.DeadPrimary, "", keyspace, shard, true)
+	require.ErrorIs(t, err, ErrRecoverySimulationDisabled)
+
+	config.SetAllowRecoverySimulation(true)
+	_, err = SimulateAnalysis(inst.NoProblem, "", keyspace, shard, true)
+	require.ErrorContains(t, err, `VTOrc has no recovery for analysis "NoProblem"`)
+	_, err = SimulateAnalysis(inst.ReplicationStopped, "zone-1-0000000404", "", "", true)
+	require.Error(t, err)
+
+	// Without a tablet, the analysis is simulated on the shard primary.
+	simulation, err := SimulateAnalysis(inst.DeadPrimary, "", keyspace, shard, true)
+	require.NoError(t, err)
+	assert.Equal(t, primaryAlias, simulation.TabletAlias)
+	assert.Equal(t, RecoverySimulationPending, simulation.State)
+
+	// The simulation is handled once, by the next recovery run.
+	entries := recoverySimulations.start()
+	require.Len(t, entries, 1)
+	assert.Empty(t, recoverySimulations.start())
+	for entry, s := range entries {
+		assert.Equal(t, inst.DeadPrimary, entry.Analysis)
+		assert.Equal(t, primaryAlias, entry.AnalyzedInstanceAlias)
+		assert.Same(t, s, recoverySimulations.running(entry))
+
+		// Nothing is broken, but the simulated analysis isn't considered as fixed.
+		alreadyFixed, err := checkIfAlreadyFixed(entry)
+		require.NoError(t, err)
+		assert.False(t, alreadyFixed)
+
+		// The dry run goes through the pipeline up to the recovery itself.
+		err = executeCheckAndRecoverFunction(entry)
+		require.NoError(t, err)
+		recoverySimulations.finish(s, err, s.RequestedAt)
+		assert.Nil(t, recoverySimulations.running(entry))
+		si, err := ts.GetShard(ctx, keyspace, shard)
+		require.NoError(t, err)
+		assert.Nil(t, si.PrimaryAlias, "the dry run must not reparent the shard")
+	}
+
+	require.NoError(t, inst.SaveTablet(tab101))
+	replicaSimulation, err := SimulateAnalysis(inst.ReplicationStopped, topoproto.TabletAliasString(tab101.Alias), "", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, keyspace, replicaSimulation.Keyspace)
+	assert.Equal(t, shard, replicaSimulation.Shard)
+
+	simulations := GetRecoverySimulations()
+	require.Len(t, simulations, 2)
+	assert.Equal(t, RecoverySimulationCompleted, simulations[0].State)
+	assert.NotNil(t, simulations[0].FinishedAt)
+	assert.Equal(t, RecoverySimulationPending, simulations[1].State)
+	assert.Equal(t, inst.ReplicationStopped, simulations[1].Analysis)
+
+	// The simulation is skipped rather than completed while the recoveries are disabled.
+	require.NoError(t, DisableRecovery())
+	defer func() {
+		require.NoError(t, EnableRecovery())
+	}()
+	skippedKey := strings.Join([]string{string(inst.ReplicationStopped), "false", RecoverySimulationSkipped}, ".")
+	skipped := simulatedRecoveriesCounter.Counts()[skippedKey]
+	for entry, s := range recoverySimulations.start() {
+		err = executeCheckAndRecoverFunction(entry)
+		require.NoError(t, err)
+		recoverySimulations.finish(s, err, s.RequestedAt)
+	}
+	simulations = GetRecoverySimulations()
+	require.Len(t, simulations, 2)
+	assert.Equal(t, RecoverySimulationSkipped, simulations[1].State)
+	assert.Equal(t, "recoveries are disabled globally", simulations[1].SkipReason)
+	assert.EqualValues(t, skipped+1, simulatedRecoveriesCounter.Counts()[skippedKey])
+}
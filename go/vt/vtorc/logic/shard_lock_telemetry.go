@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// maxShardLockWaits is the number of shard lock waits that are remembered,
+// so that they can be diagnosed. The oldest ones are forgotten first.
+const maxShardLockWaits = 100
+
+// The outcomes of a shard lock wait.
+const (
+	ShardLockWaiting   = "waiting"
+	ShardLockAcquired  = "acquired"
+	ShardLockContended = "contended"
+	ShardLockFailed    = "failed"
+)
+
+var (
+	shardLockWaitTimings = stats.NewMultiTimings("ShardLockWait", "Time spent acquiring shard locks for recoveries, by outcome", []string{"Keyspace", "Shard", "Outcome"})
+	shardLockHeldTimings = stats.NewMultiTimings("ShardLockHeld", "Time the shard locks acquired for recoveries were held", []string{"Keyspace", "Shard"})
+	shardLockContentions = stats.NewCountersWithMultiLabels("ShardLockContentions", "Number of shard locks that couldn't be acquired for recoveries because they were held by someone else", []string{"Keyspace", "Shard"})
+
+	shardLockWaits = newShardLockWaitTracker()
+
+	// shardLocksHeld is the number of shard locks acquired and not yet
+	// released. Unlike shardsLockCounter, it doesn't count the attempts to
+	// acquire them.
+	shardLocksHeld atomic.Int64
+)
+
+func init() {
+	stats.NewGaugeFunc("ShardLocksHeld", "Number of shard locks held by VTOrc", shardLocksHeld.Load)
+}
+
+// ShardLockWait is an attempt of VTOrc to acquire a shard lock for a
+// recovery, and its outcome. When the lock is held by someone else, Holder is
+// the lock they hold, as read from the topo server.
+type ShardLockWait struct {
+	Keyspace    string
+	Shard       string
+	TabletAlias string
+	Action      string
+	StartedAt   time.Time
+	WaitTime    time.Duration
+	Outcome     string
+	Error       string     `json:",omitempty"`
+	Holder      *topo.Lock `json:",omitempty"`
+	ReleasedAt  *time.Time `json:",omitempty"`
+	HeldFor     time.Duration
+}
+
+// shardLockWaitTracker tracks the recent shard lock waits, from the start of
+// the attempt until the lock is released.
+type shardLockWaitTracker struct {
+	mu    sync.Mutex
+	waits []*ShardLockWait
+}
+
+func newShardLockWaitTracker() *shardLockWaitTracker {
+	return &shardLockWaitTracker{}
+}
+
+// start records the start of an attempt to lock the shard.
+func (t *shardLockWaitTracker) start(keyspace, shard, tabletAlias, action string, now time.Time) *ShardLockWait {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	wait := &ShardLockWait{
+		Keyspace:    keyspace,
+		Shard:       shard,
+		TabletAlias: tabletAlias,
+		Action:      action,
+		StartedAt:   now,
+		Outcome:     ShardLockWaiting,
+	}
+	t.waits = append(t.waits, wait)
+	if len(t.waits) > maxShardLockWaits {
+		t.waits = t.waits[len(t.waits)-maxShardLockWaits:]
+	}
+	return wait
+}
+
+// finish records the outcome of the attempt to lock the shard.
+func (t *shardLockWaitTracker) finish(wait *ShardLockWait, outcome string, err error, holder *topo.Lock, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	wait.WaitTime = now.Sub(wait.StartedAt)
+	wait.Outcome = outcome
+	if err != nil {
+		wait.Error = err.Error()
+	}
+	wait.Holder = holder
+}
+
+// release records that the acquired lock was released.
+func (t *shardLockWaitTracker) release(wait *ShardLockWait, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	wait.ReleasedAt = &now
+	wait.HeldFor = now.Sub(wait.StartedAt) - wait.WaitTime
+}
+
+// list returns a copy of the waits on the shards of the keyspace, or on all
+// the shards if keyspace is empty, the most recent last.
+func (t *shardLockWaitTracker) list(keyspace, shard string) []ShardLockWait {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	waits := make([]ShardLockWait, 0, len(t.waits))
+	for _, wait := range t.waits {
+		if (keyspace != "" && wait.Keyspace != keyspace) || (shard != "" && wait.Shard != shard) {
+			continue
+		}
+		waits = append(waits, *wait)
+	}
+	return waits
+}
+
+// finishShardLockWait records the outcome of the attempt to lock the shard
+// of the wait, in the shard lock metrics and in the recent waits. When the
+// lock is held by someone else, the holder is read from the topo server.
+func finishShardLockWait(wait *ShardLockWait, err error) {
+	now := time.Now()
+	outcome := ShardLockAcquired
+	var holder *topo.Lock
+	switch {
+	case err == nil:
+		shardLocksHeld.Add(1)
+	case topo.IsErrType(err, topo.NodeExists), topo.IsErrType(err, topo.Timeout):
+		outcome = ShardLockContended
+		shardLockContentions.Add([]string{wait.Keyspace, wait.Shard}, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+		defer cancel()
+		var holderErr error
+		if holder, holderErr = ts.GetShardLockHolder(ctx, wait.Keyspace, wait.Shard); holderErr != nil {
+			log.Warningf("Unable to read the holder of the lock of shard %v/%v: %v", wait.Keyspace, wait.Shard, holderErr)
+		}
+	default:
+		outcome = ShardLockFailed
+	}
+	shardLockWaitTimings.Add([]string{wait.Keyspace, wait.Shard, outcome}, now.Sub(wait.StartedAt))
+	shardLockWaits.finish(wait, outcome, err, holder, now)
+}
+
+// releaseShardLockWait records that the lock acquired by the wait was released.
+func releaseShardLockWait(wait *ShardLockWait) {
+	now := time.Now()
+	shardLocksHeld.Add(-1)
+	shardLockWaits.release(wait, now)
+	shardLockHeldTimings.Add([]string{wait.Keyspace, wait.Shard}, wait.HeldFor)
+}
+
+// GetShardLockWaits returns the recent attempts to acquire shard locks for
+// recoveries, and their outcome, the most recent last. They can be filtered
+// by keyspace and shard.
+func GetShardLockWaits(keyspace, shard string) []ShardLockWait {
+	return shardLockWaits.list(keyspace, shard)
+}
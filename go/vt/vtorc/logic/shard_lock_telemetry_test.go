@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestShardLockWaits(t *testing.T) {
+	orcDb, err := db.OpenVTOrc()
+	require.NoError(t, err)
+	oldTs, oldWaits, oldLockTimeout := ts, shardLockWaits, topo.LockTimeout
+	defer func() {
+		ts, shardLockWaits, topo.LockTimeout = oldTs, oldWaits, oldLockTimeout
+		_, err = orcDb.Exec("delete from vitess_tablet")
+		require.NoError(t, err)
+	}()
+	shardLockWaits = newShardLockWaitTracker()
+	topo.LockTimeout = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	require.NoError(t, ts.CreateKeyspace(ctx, keyspace, &topodatapb.Keyspace{}))
+	_, err = ts.GetOrCreateShard(ctx, keyspace, shard)
+	require.NoError(t, err)
+	require.NoError(t, inst.SaveTablet(tab100))
+	tabletAlias := topoproto.TabletAliasString(tab100.Alias)
+	contentionsStart := shardLockContentions.Counts()[keyspace+"."+shard]
+
+	_, unlock, err := LockShard(ctx, tabletAlias, "first recovery")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, shardLocksHeld.Load())
+	waits := GetShardLockWaits("", "")
+	require.Len(t, waits, 1)
+	assert.Equal(t, ShardLockAcquired, waits[0].Outcome)
+	assert.Nil(t, waits[0].ReleasedAt)
+
+	// The lock is held, so the next recovery can't get it.
+	_, _, err = LockShard(ctx, tabletAlias, "second recovery")
+	require.Error(t, err)
+	assert.EqualValues(t, 1, shardLocksHeld.Load())
+	var unlockErr error
+	unlock(&unlockErr)
+	require.NoError(t, unlockErr)
+	assert.EqualValues(t, 0, shardLocksHeld.Load())
+
+	waits = GetShardLockWaits(keyspace, shard)
+	require.Len(t, waits, 2)
+	assert.Equal(t, "first recovery", waits[0].Action)
+	assert.NotNil(t, waits[0].ReleasedAt)
+	assert.Equal(t, "second recovery", waits[1].Action)
+	assert.Equal(t, ShardLockContended, waits[1].Outcome)
+	assert.Equal(t, tabletAlias, waits[1].TabletAlias)
+	assert.NotEmpty(t, waits[1].Error)
+	assert.GreaterOrEqual(t, waits[1].WaitTime, topo.LockTimeout)
+	assert.EqualValues(t, 1, shardLockContentions.Counts()[keyspace+"."+shard]-contentionsStart)
+
+	assert.Empty(t, GetShardLockWaits("other_ks", ""))
+}
+
+func TestShardLockWaitTracker(t *testing.T) {
+	tracker := newShardLockWaitTracker()
+	start := time.Now()
+	for i := 0; i < maxShardLockWaits+5; i++ {
+		tracker.start(keyspace, shard, "zone-1-0000000100", "action", start)
+	}
+	waits := tracker.list("", "")
+	require.Len(t, waits, maxShardLockWaits)
+	assert.Equal(t, ShardLockWaiting, waits[0].Outcome)
+
+	wait := tracker.start(keyspace, shard, "zone-1-0000000100", "action", start)
+	tracker.finish(wait, ShardLockFailed, errors.New("topo is down"), nil, start.Add(time.Second))
+	tracker.release(wait, start.Add(3*time.Second))
+	waits = tracker.list(keyspace, shard)
+	last := waits[len(waits)-1]
+	assert.Equal(t, ShardLockFailed, last.Outcome)
+	assert.Equal(t, "topo is down", last.Error)
+	assert.Equal(t, time.Second, last.WaitTime)
+	assert.Equal(t, 2*time.Second, last.HeldFor)
+}
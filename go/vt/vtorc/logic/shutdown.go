@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+)
+
+var (
+	// vtorcCtx is canceled when VTOrc shuts down, which stops the discovery
+	// loop, the discovery workers, and the periodic jobs.
+	vtorcCtx, cancelVTOrcCtx = context.WithCancel(context.Background())
+	// discoveryRoutines are the goroutines started by ContinuousDiscovery.
+	discoveryRoutines sync.WaitGroup
+
+	shutdownOnce sync.Once
+	shutdownErr  error
+
+	shutdownHooksMu sync.Mutex
+	shutdownHooks   []shutdownHook
+)
+
+type shutdownHook struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// RegisterShutdownHook registers a function run when VTOrc shuts down, once
+// the discovery and the recoveries stopped, and before the topo is closed.
+// The hooks run in the reverse order of their registration.
+func RegisterShutdownHook(name string, hook func(ctx context.Context) error) {
+	shutdownHooksMu.Lock()
+	defer shutdownHooksMu.Unlock()
+	shutdownHooks = append(shutdownHooks, shutdownHook{name: name, run: hook})
+}
+
+// Shutdown stops VTOrc without exiting the process, e.g. when it is embedded
+// in another program: it stops the discovery and the periodic jobs, waits for
+// the runs in progress and for the shard locks to be released, runs the
+// shutdown hooks, and closes the topo. The waits are bounded by ctx and by
+// --shutdown_wait_time. Only the first call shuts VTOrc down, the later ones
+// return its error.
+func Shutdown(ctx context.Context) error {
+	shutdownOnce.Do(func() {
+		shutdownErr = shutdown(ctx)
+	})
+	return shutdownErr
+}
+
+func shutdown(ctx context.Context) error {
+	log.Infof("Starting VTOrc shutdown")
+	atomic.StoreInt32(&hasReceivedSIGTERM, 1)
+	cancelVTOrcCtx()
+	ctx, cancel := context.WithTimeout(ctx, shutdownWaitTime)
+	defer cancel()
+
+	discoveryMetrics.StopAutoExpiration()
+	_ = inst.AuditOperation("shutdown", "", "Triggered via Shutdown")
+	if err := waitContext(ctx, discoveryRoutines.Wait); err != nil {
+		log.Warningf("Timed out waiting for the discovery to stop")
+	}
+	if err := waitContext(ctx, discoveryJobs.wait); err != nil {
+		log.Warningf("Timed out waiting for the periodic jobs in progress to end")
+	}
+	// wait for the locks to be released
+	waitForLocksRelease()
+
+	shutdownHooksMu.Lock()
+	hooks := shutdownHooks
+	shutdownHooksMu.Unlock()
+	rec := &concurrency.AllErrorRecorder{}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].run(ctx); err != nil {
+			log.Errorf("VTOrc shutdown hook %s failed: %v", hooks[i].name, err)
+			rec.RecordError(fmt.Errorf("shutdown hook %s: %w", hooks[i].name, err))
+		}
+	}
+
+	// Hand the owned keyspaces over to the other VTOrcs.
+	ownership.close()
+	inventory.close()
+	if ts != nil {
+		ts.Close()
+	}
+	log.Infof("VTOrc closed")
+	return rec.Error()
+}
+
+// waitContext calls wait, and returns the error of the context if it is
+// done before wait returns.
+func waitContext(ctx context.Context, wait func()) error {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
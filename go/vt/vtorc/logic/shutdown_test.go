@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	// Restore the state of VTOrc for the other tests.
+	oldTs, oldInventory, oldDiscoveryJobs, oldDiscoveryQueue := ts, inventory, discoveryJobs, discoveryQueue
+	defer func() {
+		ts, inventory, discoveryJobs, discoveryQueue = oldTs, oldInventory, oldDiscoveryJobs, oldDiscoveryQueue
+		vtorcCtx, cancelVTOrcCtx = context.WithCancel(context.Background())
+		shutdownOnce = sync.Once{}
+		shutdownErr = nil
+		shutdownHooks = nil
+		atomic.StoreInt32(&hasReceivedSIGTERM, 0)
+	}()
+	ts = nil
+	inventory = newTabletInventory()
+	discoveryJobs = newJobSupervisor()
+
+	// The discovery workers wait on the empty queue.
+	handleDiscoveryRequests(vtorcCtx)
+
+	// A job is running.
+	started, release := make(chan struct{}), make(chan struct{})
+	discoveryJobs.register("test", false, 0, func() error {
+		close(started)
+		<-release
+		return nil
+	})
+	discoveryJobs.start("test")
+	<-started
+
+	var hooks []string
+	RegisterShutdownHook("first", func(ctx context.Context) error {
+		hooks = append(hooks, "first")
+		return nil
+	})
+	RegisterShutdownHook("second", func(ctx context.Context) error {
+		hooks = append(hooks, "second")
+		return errors.New("failed")
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- Shutdown(context.Background())
+	}()
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before the job ended")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Error(t, vtorcCtx.Err())
+	assert.Empty(t, hooks, "the hooks run once the job ended")
+	_, _, err := LockShard(context.Background(), "zone-1-0000000100", "test")
+	assert.EqualError(t, err, "can't lock shard: SIGTERM received")
+
+	close(release)
+	require.EqualError(t, <-done, "shutdown hook second: failed")
+	assert.Equal(t, []string{"second", "first"}, hooks)
+
+	// The discovery workers stopped.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, waitContext(ctx, discoveryRoutines.Wait))
+
+	// VTOrc only shuts down once.
+	require.EqualError(t, Shutdown(context.Background()), "shutdown hook second: failed")
+	assert.Len(t, hooks, 2)
+}
+
+func TestWaitContext(t *testing.T) {
+	require.NoError(t, waitContext(context.Background(), func() {}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	block := make(chan struct{})
+	defer close(block)
+	require.ErrorIs(t, waitContext(ctx, func() { <-block }), context.Canceled)
+}
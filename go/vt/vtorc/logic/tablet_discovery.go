@@ -20,7 +20,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -29,15 +28,13 @@ import (
 	"github.com/spf13/pflag"
 
 	"google.golang.org/protobuf/encoding/prototext"
-	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/vt/external/golib/sqlutils"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/topo"
-	"vitess.io/vitess/go/vt/topo/topoproto"
-	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtorc/config"
 	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/discovery"
 	"vitess.io/vitess/go/vt/vtorc/inst"
 	"vitess.io/vitess/go/vt/vtorc/process"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
@@ -59,6 +56,10 @@ var (
 func RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&clustersToWatch, "clusters_to_watch", clustersToWatch, "Comma-separated list of keyspaces or keyspace/shards that this instance will monitor and repair. Defaults to all clusters in the topology. Example: \"ks1,ks2/-80\"")
 	fs.DurationVar(&shutdownWaitTime, "shutdown_wait_time", shutdownWaitTime, "Maximum time to wait for VTOrc to release all the locks that it is holding before shutting down on SIGTERM")
+	fs.BoolVar(&keyspaceSharding, "keyspace-sharding", keyspaceSharding, "Share the keyspaces among all the VTOrcs running with this flag, so that each of them monitors and repairs a subset of the keyspaces, and takes over the keyspaces of the VTOrcs that stop")
+	fs.StringVar(&keyspaceShardingID, "keyspace-sharding-id", keyspaceShardingID, "Name under which this VTOrc shares the keyspaces with the other VTOrcs, which must be unique among them. Defaults to the hostname and port of this VTOrc")
+	fs.StringVar(&discoverySourceName, "discovery-source", discoverySourceName, "Source of the tablets to discover: topo to read the tablet records of the topo, file to read them from --discovery-file, or a compiled-in source. The keyspaces and shards are always read from the topo")
+	fs.StringVar(&discoveryFile, "discovery-file", discoveryFile, "File holding a JSON array of the tablet records to discover, read again on every topo information refresh, when --discovery-source is file")
 }
 
 // OpenTabletDiscovery opens the vitess topo if enables and returns a ticker
@@ -66,6 +67,14 @@ func RegisterFlags(fs *pflag.FlagSet) {
 func OpenTabletDiscovery() <-chan time.Time {
 	ts = topo.Open()
 	tmc = inst.InitializeTMC()
+	source, err := getDiscoverySource(discoverySourceName)
+	if err != nil {
+		log.Exitf("Invalid --discovery-source: %v", err)
+	}
+	tabletSource = source
+	if keyspaceSharding {
+		ownership = newKeyspaceOwnership(ts, keyspaceShardingMemberID(), time.Second*time.Duration(config.Config.TopoInformationRefreshSeconds))
+	}
 	// Clear existing cache and perform a new refresh.
 	if _, err := db.ExecVTOrc("delete from vitess_tablet"); err != nil {
 		log.Error(err)
@@ -82,15 +91,29 @@ func populateAllInformation() {
 	process.FirstDiscoveryCycleComplete.Store(true)
 }
 
-// refreshAllTablets reloads the tablets from topo and discovers the ones which haven't been refreshed in a while
+// refreshAllTablets reloads the tablets from topo, or from the discovery source, and discovers the ones which haven't been refreshed in a while
 func refreshAllTablets() {
+	if tabletSource != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+		defer cancel()
+		refreshTabletsFromSource(ctx, tabletSource, discovery.CreateOrReturnQueue("DEFAULT"))
+		return
+	}
 	refreshTabletsUsing(func(tabletAlias string) {
 		DiscoverInstance(tabletAlias, false /* forceDiscovery */)
 	}, false /* forceRefresh */)
 }
 
 func refreshTabletsUsing(loader func(tabletAlias string), forceRefresh bool) {
-	if len(clustersToWatch) == 0 { // all known clusters
+	clusters := clustersToWatch
+	if ownership != nil {
+		// Only refresh the tablets of the keyspaces that this VTOrc owns.
+		clusters = ownership.filterClusters(clustersToWatch)
+		if len(clusters) == 0 {
+			return
+		}
+	}
+	if len(clusters) == 0 { // all known clusters
 		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
 		defer cancel()
 		cells, err := ts.GetKnownCells(ctx)
@@ -113,7 +136,7 @@ func refreshTabletsUsing(loader func(tabletAlias string), forceRefresh bool) {
 	} else {
 		// Parse input and build list of keyspaces / shards
 		var keyspaceShards []*topo.KeyspaceShard
-		for _, ks := range clustersToWatch {
+		for _, ks := range clusters {
 			if strings.Contains(ks, "/") {
 				// This is a keyspace/shard specification
 				input := strings.Split(ks, "/")
@@ -138,7 +161,7 @@ func refreshTabletsUsing(loader func(tabletAlias string), forceRefresh bool) {
 			}
 		}
 		if len(keyspaceShards) == 0 {
-			log.Errorf("Found no keyspaceShards for input: %+v", clustersToWatch)
+			log.Errorf("Found no keyspaceShards for input: %+v", clusters)
 			return
 		}
 		refreshCtx, refreshCancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
@@ -148,7 +171,7 @@ func refreshTabletsUsing(loader func(tabletAlias string), forceRefresh bool) {
 			wg.Add(1)
 			go func(ks *topo.KeyspaceShard) {
 				defer wg.Done()
-				refreshTabletsInKeyspaceShard(refreshCtx, ks.Keyspace, ks.Shard, loader, forceRefresh, nil)
+				refreshTabletsInKeyspaceShardUsing(refreshCtx, ks.Keyspace, ks.Shard, loader, forceRefresh, forceRefresh /* fromTopo */, nil)
 			}(ks)
 		}
 		wg.Wait()
@@ -156,14 +179,14 @@ func refreshTabletsUsing(loader func(tabletAlias string), forceRefresh bool) {
 }
 
 func refreshTabletsInCell(ctx context.Context, cell string, loader func(tabletAlias string), forceRefresh bool) {
-	tablets, err := topotools.GetTabletMapForCell(ctx, ts, cell)
+	tablets, err := inventory.cellTablets(ctx, cell)
 	if err != nil {
 		log.Errorf("Error fetching topo info for cell %v: %v", cell, err)
 		return
 	}
 	query := "select alias from vitess_tablet where cell = ?"
 	args := sqlutils.Args(cell)
-	refreshTablets(tablets, query, args, loader, forceRefresh, nil)
+	inventory.refresh(tablets, query, args, loader, forceRefresh, nil)
 }
 
 // forceRefreshAllTabletsInShard is used to refresh all the tablet's information (both MySQL information and topo records)
@@ -187,69 +210,21 @@ func refreshTabletInfoOfShard(ctx context.Context, keyspace, shard string) {
 	}, false, nil)
 }
 
+// refreshTabletsInKeyspaceShard refreshes the tablets of the given keyspace-shard. The forced refreshes,
+// and the ones around recoveries, always read the tablets from the topo-server.
 func refreshTabletsInKeyspaceShard(ctx context.Context, keyspace, shard string, loader func(tabletAlias string), forceRefresh bool, tabletsToIgnore []string) {
-	tablets, err := ts.GetTabletMapForShard(ctx, keyspace, shard)
+	refreshTabletsInKeyspaceShardUsing(ctx, keyspace, shard, loader, forceRefresh, true /* fromTopo */, tabletsToIgnore)
+}
+
+func refreshTabletsInKeyspaceShardUsing(ctx context.Context, keyspace, shard string, loader func(tabletAlias string), forceRefresh bool, fromTopo bool, tabletsToIgnore []string) {
+	tablets, err := inventory.shardTablets(ctx, keyspace, shard, fromTopo)
 	if err != nil {
 		log.Errorf("Error fetching tablets for keyspace/shard %v/%v: %v", keyspace, shard, err)
 		return
 	}
 	query := "select alias from vitess_tablet where keyspace = ? and shard = ?"
 	args := sqlutils.Args(keyspace, shard)
-	refreshTablets(tablets, query, args, loader, forceRefresh, tabletsToIgnore)
-}
-
-func refreshTablets(tablets map[string]*topo.TabletInfo, query string, args []any, loader func(tabletAlias string), forceRefresh bool, tabletsToIgnore []string) {
-	// Discover new tablets.
-	latestInstances := make(map[string]bool)
-	var wg sync.WaitGroup
-	for _, tabletInfo := range tablets {
-		tablet := tabletInfo.Tablet
-		if tablet.Type != topodatapb.TabletType_PRIMARY && !topo.IsReplicaType(tablet.Type) {
-			continue
-		}
-		tabletAliasString := topoproto.TabletAliasString(tablet.Alias)
-		latestInstances[tabletAliasString] = true
-		old, err := inst.ReadTablet(tabletAliasString)
-		if err != nil && err != inst.ErrTabletAliasNil {
-			log.Error(err)
-			continue
-		}
-		if !forceRefresh && proto.Equal(tablet, old) {
-			continue
-		}
-		if err := inst.SaveTablet(tablet); err != nil {
-			log.Error(err)
-			continue
-		}
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if slices.Contains(tabletsToIgnore, topoproto.TabletAliasString(tablet.Alias)) {
-				return
-			}
-			loader(tabletAliasString)
-		}()
-		log.Infof("Discovered: %v", tablet)
-	}
-	wg.Wait()
-
-	// Forget tablets that were removed.
-	var toForget []string
-	err := db.QueryVTOrc(query, args, func(row sqlutils.RowMap) error {
-		tabletAlias := row.GetString("alias")
-		if !latestInstances[tabletAlias] {
-			toForget = append(toForget, tabletAlias)
-		}
-		return nil
-	})
-	if err != nil {
-		log.Error(err)
-	}
-	for _, tabletAlias := range toForget {
-		if err := inst.ForgetInstance(tabletAlias); err != nil {
-			log.Error(err)
-		}
-	}
+	inventory.refresh(tablets, query, args, loader, forceRefresh, tabletsToIgnore)
 }
 
 func getLockAction(analysedInstance string, code inst.AnalysisCode) string {
@@ -272,7 +247,9 @@ func LockShard(ctx context.Context, tabletAlias string, lockAction string) (cont
 	}
 
 	atomic.AddInt32(&shardsLockCounter, 1)
+	wait := shardLockWaits.start(tablet.Keyspace, tablet.Shard, tabletAlias, lockAction, time.Now())
 	ctx, unlock, err := ts.TryLockShard(ctx, tablet.Keyspace, tablet.Shard, lockAction)
+	finishShardLockWait(wait, err)
 	if err != nil {
 		atomic.AddInt32(&shardsLockCounter, -1)
 		return nil, nil, err
@@ -280,6 +257,7 @@ func LockShard(ctx context.Context, tabletAlias string, lockAction string) (cont
 	return ctx, func(e *error) {
 		defer atomic.AddInt32(&shardsLockCounter, -1)
 		unlock(e)
+		releaseShardLockWait(wait)
 	}, nil
 }
 
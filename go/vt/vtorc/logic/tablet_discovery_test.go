@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/vt/external/golib/sqlutils"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/proto/vttime"
+	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtorc/db"
@@ -209,6 +210,26 @@ func TestRefreshTabletsInKeyspaceShard(t *testing.T) {
 		// Also the old tablet should be forgotten
 		verifyRefreshTabletsInKeyspaceShard(t, false, 1, tablets, nil)
 	})
+
+	t.Run("disable discovery for the keyspace and call refreshTabletsInKeyspaceShard again", func(t *testing.T) {
+		keyspaceInfo := &topo.KeyspaceInfo{
+			Keyspace: &topodatapb.Keyspace{
+				DurabilityPolicy: "none",
+				VtorcConfig:      &topodatapb.VtorcConfig{DisableDiscovery: true},
+			},
+		}
+		keyspaceInfo.SetKeyspaceName(keyspace)
+		require.NoError(t, inst.SaveKeyspace(keyspaceInfo))
+
+		var instancesRefreshed atomic.Int32
+		refreshTabletsInKeyspaceShard(context.Background(), keyspace, shard, func(string) {
+			instancesRefreshed.Add(1)
+		}, true, nil)
+		// No tablet should be refreshed, and the known tablets should be forgotten, so that
+		// their stale instances aren't analyzed anymore.
+		assert.EqualValues(t, 0, instancesRefreshed.Load())
+		verifyTabletCount(t, 0)
+	})
 }
 
 func TestShardPrimary(t *testing.T) {
@@ -0,0 +1,377 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"path"
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/external/golib/sqlutils"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var (
+	// inventory is the tablet inventory of VTOrc.
+	inventory = newTabletInventory()
+
+	tabletInventoryTopoReads = stats.NewCountersWithSingleLabel("TabletInventoryTopoReads", "Number of times the tablet inventory read the tablets from the topo, by scope", "Scope")
+	tabletInventoryCacheHits = stats.NewCountersWithSingleLabel("TabletInventoryCacheHits", "Number of times the tablet inventory served the tablets from its watches of the topo, by scope", "Scope")
+)
+
+// tabletInventory is the single source of the tablets of the topo for
+// VTOrc. Both the periodic refresh of the tablets and the refreshes of the
+// tablets of a shard around recoveries go through it, so that:
+//   - the tablets of each cell are watched once, and the periodic refreshes
+//     are served from the watches instead of scanning the topo again, when
+//     the topo implementation supports recursive watches. The refreshes
+//     around recoveries always read the topo, since they must see the
+//     changes that the recovery just made.
+//   - the changes to the backend are serialized, so that a refresh never
+//     forgets a tablet that a concurrent refresh saved from a more recent
+//     read of the topo.
+//   - the tablets are forgotten the same way by all the refreshes: only the
+//     known tablets of the refreshed scope that are no longer in the topo,
+//     or no longer of a type that VTOrc manages, are forgotten, and nothing
+//     is forgotten when the topo could not be read.
+type tabletInventory struct {
+	// refreshMu serializes the changes to the backend.
+	refreshMu sync.Mutex
+
+	mu sync.Mutex
+	// ctx is the context of the watches, canceled on close.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// topoServer is the topo-server of the watches.
+	topoServer *topo.Server
+	// watches are the watches of the tablets of the cells, by cell.
+	watches map[string]*cellWatch
+	// unwatchable are the cells whose topo doesn't support recursive watches.
+	unwatchable map[string]bool
+}
+
+// cellWatch is the watch of the tablets of a cell.
+type cellWatch struct {
+	// tablets are the tablets of the cell, by alias.
+	tablets map[string]*topodatapb.Tablet
+}
+
+func newTabletInventory() *tabletInventory {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &tabletInventory{
+		ctx:         ctx,
+		cancel:      cancel,
+		watches:     make(map[string]*cellWatch),
+		unwatchable: make(map[string]bool),
+	}
+}
+
+// close stops watching the tablets of the cells.
+func (ti *tabletInventory) close() {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.cancel()
+	ti.watches = make(map[string]*cellWatch)
+}
+
+// checkTopoServerLocked stops the watches when the topo-server changed.
+func (ti *tabletInventory) checkTopoServerLocked() {
+	if ti.topoServer == ts {
+		return
+	}
+	ti.cancel()
+	ti.ctx, ti.cancel = context.WithCancel(context.Background())
+	ti.topoServer = ts
+	ti.watches = make(map[string]*cellWatch)
+	ti.unwatchable = make(map[string]bool)
+}
+
+// cellTablets returns the tablets of the cell, from its watch if it is
+// watched. Otherwise they are read from the topo, and the cell is watched
+// for the next times, if possible.
+func (ti *tabletInventory) cellTablets(ctx context.Context, cell string) ([]*topodatapb.Tablet, error) {
+	if tablets, ok := ti.watchedTablets([]string{cell}, func(*topodatapb.Tablet) bool { return true }); ok {
+		tabletInventoryCacheHits.Add("cell", 1)
+		return tablets, nil
+	}
+	tabletInventoryTopoReads.Add("cell", 1)
+	tabletMap, err := topotools.GetTabletMapForCell(ctx, ts, cell)
+	if err != nil {
+		return nil, err
+	}
+	ti.watchCell(cell)
+	return tabletsOf(tabletMap), nil
+}
+
+// shardTablets returns the tablets of the shard. Unless they must be read
+// from the topo, they are served from the watches of the cells, if all of
+// them are watched.
+func (ti *tabletInventory) shardTablets(ctx context.Context, keyspace, shard string, fromTopo bool) ([]*topodatapb.Tablet, error) {
+	cells, err := ts.GetKnownCells(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !fromTopo {
+		if tablets, ok := ti.watchedTablets(cells, func(tablet *topodatapb.Tablet) bool {
+			return tablet.Keyspace == keyspace && tablet.Shard == shard
+		}); ok {
+			tabletInventoryCacheHits.Add("shard", 1)
+			return tablets, nil
+		}
+	}
+	tabletInventoryTopoReads.Add("shard", 1)
+	tabletMap, err := ts.GetTabletMapForShard(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+	for _, cell := range cells {
+		ti.watchCell(cell)
+	}
+	return tabletsOf(tabletMap), nil
+}
+
+// watchedTablets returns the tablets of the given cells that match the
+// filter, if all the cells are watched.
+func (ti *tabletInventory) watchedTablets(cells []string, filter func(tablet *topodatapb.Tablet) bool) ([]*topodatapb.Tablet, bool) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.checkTopoServerLocked()
+	var tablets []*topodatapb.Tablet
+	for _, cell := range cells {
+		watch, ok := ti.watches[cell]
+		if !ok {
+			return nil, false
+		}
+		for _, tablet := range watch.tablets {
+			if filter(tablet) {
+				tablets = append(tablets, tablet)
+			}
+		}
+	}
+	return tablets, true
+}
+
+// watchCell starts watching the tablets of the cell, unless it is already
+// watched or its topo doesn't support recursive watches. The watch stops on
+// errors, and the cell is then read from the topo until it is watched again.
+func (ti *tabletInventory) watchCell(cell string) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.checkTopoServerLocked()
+	if _, ok := ti.watches[cell]; ok || ti.unwatchable[cell] || ti.ctx.Err() != nil {
+		return
+	}
+	conn, err := ts.ConnForCell(ti.ctx, cell)
+	if err != nil {
+		log.Warningf("Failed to watch the tablets of cell %v: %v", cell, err)
+		return
+	}
+	ctx, cancel := context.WithCancel(ti.ctx)
+	current, changes, err := conn.WatchRecursive(ctx, topo.TabletsPath)
+	if err != nil {
+		cancel()
+		if topo.IsErrType(err, topo.NoImplementation) {
+			ti.unwatchable[cell] = true
+			return
+		}
+		log.Warningf("Failed to watch the tablets of cell %v: %v", cell, err)
+		return
+	}
+
+	watch := &cellWatch{tablets: make(map[string]*topodatapb.Tablet, len(current))}
+	for _, wd := range current {
+		if tablet := parseWatchedTablet(wd); tablet != nil {
+			watch.tablets[topoproto.TabletAliasString(tablet.Alias)] = tablet
+		}
+	}
+	ti.watches[cell] = watch
+	log.Infof("Watching the tablets of cell %v", cell)
+
+	go ti.followWatch(cell, watch, changes, cancel)
+}
+
+// followWatch applies the changes of the watch of the cell until its channel
+// is closed, and then forgets the watch, so that the cell is read from the
+// topo until it is watched again.
+func (ti *tabletInventory) followWatch(cell string, watch *cellWatch, changes <-chan *topo.WatchDataRecursive, cancel context.CancelFunc) {
+	defer cancel()
+	for wd := range changes {
+		if !ti.applyWatchData(cell, watch, wd) {
+			// Stop the watch, and drain its changes until it is closed.
+			cancel()
+		}
+	}
+
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if ti.watches[cell] == watch {
+		delete(ti.watches, cell)
+	}
+}
+
+// applyWatchData applies a change to the tablets of the cell. It returns
+// false when the watch failed or was stopped.
+func (ti *tabletInventory) applyWatchData(cell string, watch *cellWatch, wd *topo.WatchDataRecursive) bool {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if ti.watches[cell] != watch {
+		return false
+	}
+	switch {
+	case wd.Err == nil:
+		if tablet := parseWatchedTablet(wd); tablet != nil {
+			watch.tablets[topoproto.TabletAliasString(tablet.Alias)] = tablet
+		}
+	case topo.IsErrType(wd.Err, topo.NoNode):
+		// The deleted tablet records have no contents, only their path.
+		if path.Base(wd.Path) == topo.TabletFile {
+			delete(watch.tablets, path.Base(path.Dir(wd.Path)))
+		}
+	default:
+		if !topo.IsErrType(wd.Err, topo.Interrupted) {
+			log.Warningf("The watch of the tablets of cell %v failed: %v", cell, wd.Err)
+		}
+		delete(ti.watches, cell)
+		return false
+	}
+	return true
+}
+
+// parseWatchedTablet returns the tablet record of the watched file, or nil
+// if the file isn't a tablet record. The current values of a watch don't
+// always have their full path, so the alias is read from the record.
+func parseWatchedTablet(wd *topo.WatchDataRecursive) *topodatapb.Tablet {
+	if path.Base(wd.Path) != topo.TabletFile {
+		return nil
+	}
+	tablet := &topodatapb.Tablet{}
+	if err := tablet.UnmarshalVT(wd.Contents); err != nil || tablet.Alias == nil {
+		return nil
+	}
+	return tablet
+}
+
+// tabletsOf returns the tablets of the tablet map.
+func tabletsOf(tabletMap map[string]*topo.TabletInfo) []*topodatapb.Tablet {
+	tablets := make([]*topodatapb.Tablet, 0, len(tabletMap))
+	for _, tabletInfo := range tabletMap {
+		tablets = append(tablets, tabletInfo.Tablet)
+	}
+	return tablets
+}
+
+// refresh saves the tablets read from the topo for a scope in the backend,
+// and loads the ones that changed, or all of them when forced, except the
+// ones to ignore. The known tablets of the scope, selected by the query,
+// that are no longer in the topo are forgotten.
+func (ti *tabletInventory) refresh(tablets []*topodatapb.Tablet, query string, args []any, loader func(tabletAlias string), forceRefresh bool, tabletsToIgnore []string) {
+	toLoad := ti.save(tablets, query, args, forceRefresh)
+
+	var wg sync.WaitGroup
+	for _, tabletAlias := range toLoad {
+		if slices.Contains(tabletsToIgnore, tabletAlias) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loader(tabletAlias)
+		}()
+	}
+	wg.Wait()
+}
+
+// save saves the tablets in the backend, forgets the known tablets of the
+// scope that are no longer in the topo, and returns the tablets to load.
+func (ti *tabletInventory) save(tablets []*topodatapb.Tablet, query string, args []any, forceRefresh bool) (toLoad []string) {
+	ti.refreshMu.Lock()
+	defer ti.refreshMu.Unlock()
+
+	// Discover new tablets.
+	latestInstances := make(map[string]bool)
+	discoveryDisabled := make(map[string]bool)
+	for _, tablet := range tablets {
+		if tablet.Type != topodatapb.TabletType_PRIMARY && !topo.IsReplicaType(tablet.Type) {
+			continue
+		}
+		tabletAliasString := topoproto.TabletAliasString(tablet.Alias)
+		latestInstances[tabletAliasString] = true
+		// Tablets of keyspaces that have discovery disabled are never polled, and are forgotten,
+		// so that their stale instances don't raise analyses.
+		disabled, checked := discoveryDisabled[tablet.Keyspace]
+		if !checked {
+			var err error
+			disabled, err = inst.IsKeyspaceDiscoveryDisabled(tablet.Keyspace)
+			if err != nil {
+				log.Error(err)
+			}
+			discoveryDisabled[tablet.Keyspace] = disabled
+			if disabled {
+				if err := inst.ForgetKeyspaceTablets(tablet.Keyspace); err != nil {
+					log.Errorf("Failed to forget the tablets of keyspace %v: %v", tablet.Keyspace, err)
+				}
+			}
+		}
+		if disabled {
+			continue
+		}
+		old, err := inst.ReadTablet(tabletAliasString)
+		if err != nil && err != inst.ErrTabletAliasNil {
+			log.Error(err)
+			continue
+		}
+		if !forceRefresh && proto.Equal(tablet, old) {
+			continue
+		}
+		if err := inst.SaveTablet(tablet); err != nil {
+			log.Error(err)
+			continue
+		}
+		toLoad = append(toLoad, tabletAliasString)
+		log.Infof("Discovered: %v", tablet)
+	}
+
+	// Forget tablets that were removed.
+	var toForget []string
+	err := db.QueryVTOrc(query, args, func(row sqlutils.RowMap) error {
+		tabletAlias := row.GetString("alias")
+		if !latestInstances[tabletAlias] {
+			toForget = append(toForget, tabletAlias)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+	for _, tabletAlias := range toForget {
+		if err := inst.ForgetInstance(tabletAlias); err != nil {
+			log.Error(err)
+		}
+	}
+	return toLoad
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func tabletAliases(tablets []*topodatapb.Tablet) []string {
+	var aliases []string
+	for _, tablet := range tablets {
+		aliases = append(aliases, topoproto.TabletAliasString(tablet.Alias))
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+func TestTabletInventoryWatch(t *testing.T) {
+	oldTs := ts
+	oldInventory := inventory
+	defer func() {
+		inventory.close()
+		ts = oldTs
+		inventory = oldInventory
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	inventory = newTabletInventory()
+	_, err := ts.GetOrCreateShard(ctx, keyspace, shard)
+	require.NoError(t, err)
+	for _, tablet := range []*topodatapb.Tablet{tab100, tab101} {
+		require.NoError(t, ts.CreateTablet(ctx, tablet))
+	}
+
+	// The first read of the cell scans the topo, and starts watching it.
+	topoReads := tabletInventoryTopoReads.Counts()["cell"]
+	cacheHits := tabletInventoryCacheHits.Counts()["cell"]
+	tablets, err := inventory.cellTablets(ctx, cell1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone-1-0000000100", "zone-1-0000000101"}, tabletAliases(tablets))
+	assert.EqualValues(t, topoReads+1, tabletInventoryTopoReads.Counts()["cell"])
+
+	// The next reads are served from the watch, which follows the changes of the tablets.
+	require.NoError(t, ts.CreateTablet(ctx, tab102))
+	_, err = ts.UpdateTabletFields(ctx, tab101.Alias, func(tablet *topodatapb.Tablet) error {
+		tablet.Type = topodatapb.TabletType_DRAINED
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, ts.DeleteTablet(ctx, tab100.Alias))
+	assert.Eventually(t, func() bool {
+		tablets, err = inventory.cellTablets(ctx, cell1)
+		if err != nil || len(tablets) != 2 {
+			return false
+		}
+		sort.Slice(tablets, func(i, j int) bool { return tablets[i].Alias.Uid < tablets[j].Alias.Uid })
+		return tablets[0].Type == topodatapb.TabletType_DRAINED && proto.Equal(tablets[1], tab102)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.EqualValues(t, topoReads+1, tabletInventoryTopoReads.Counts()["cell"])
+	assert.Greater(t, tabletInventoryCacheHits.Counts()["cell"], cacheHits)
+
+	// The periodic refreshes of a shard are served from the watches too,
+	// but not the refreshes that must see the latest changes.
+	shardTopoReads := tabletInventoryTopoReads.Counts()["shard"]
+	tablets, err = inventory.shardTablets(ctx, keyspace, shard, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone-1-0000000101", "zone-1-0000000102"}, tabletAliases(tablets))
+	tablets, err = inventory.shardTablets(ctx, "other", shard, false)
+	require.NoError(t, err)
+	assert.Empty(t, tablets)
+	assert.EqualValues(t, shardTopoReads, tabletInventoryTopoReads.Counts()["shard"])
+	_, err = inventory.shardTablets(ctx, keyspace, shard, true)
+	require.NoError(t, err)
+	assert.EqualValues(t, shardTopoReads+1, tabletInventoryTopoReads.Counts()["shard"])
+
+	// Once the watches are stopped, the tablets are read from the topo.
+	inventory.close()
+	_, err = inventory.cellTablets(ctx, cell1)
+	require.NoError(t, err)
+	assert.EqualValues(t, topoReads+2, tabletInventoryTopoReads.Counts()["cell"])
+}
+
+func TestTabletInventoryWatchFailure(t *testing.T) {
+	oldTs := ts
+	oldInventory := inventory
+	defer func() {
+		inventory.close()
+		ts = oldTs
+		inventory = oldInventory
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	inventory = newTabletInventory()
+	require.NoError(t, ts.CreateTablet(ctx, tab100))
+
+	_, err := inventory.cellTablets(ctx, cell1)
+	require.NoError(t, err)
+	inventory.mu.Lock()
+	watch := inventory.watches[cell1]
+	inventory.mu.Unlock()
+	require.NotNil(t, watch)
+
+	// A failed watch stops serving the cell, which is read from the topo again.
+	assert.False(t, inventory.applyWatchData(cell1, watch, &topo.WatchDataRecursive{WatchData: topo.WatchData{Err: topo.NewError(topo.Timeout, topo.TabletsPath)}}))
+	topoReads := tabletInventoryTopoReads.Counts()["cell"]
+	tablets, err := inventory.cellTablets(ctx, cell1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zone-1-0000000100"}, tabletAliases(tablets))
+	assert.EqualValues(t, topoReads+1, tabletInventoryTopoReads.Counts()["cell"])
+	// The changes of the stopped watch are no longer applied.
+	assert.False(t, inventory.applyWatchData(cell1, watch, &topo.WatchDataRecursive{Path: "tablets/zone-1-0000000100/Tablet", WatchData: topo.WatchData{Err: topo.NewError(topo.NoNode, "")}}))
+	tablets, err = inventory.cellTablets(ctx, cell1)
+	require.NoError(t, err)
+	assert.Len(t, tablets, 1)
+
+	// A watch whose changes end without an error stops serving the cell too.
+	inventory.mu.Lock()
+	watch = inventory.watches[cell1]
+	inventory.mu.Unlock()
+	require.NotNil(t, watch)
+	changes := make(chan *topo.WatchDataRecursive)
+	close(changes)
+	inventory.followWatch(cell1, watch, changes, func() {})
+	inventory.mu.Lock()
+	_, ok := inventory.watches[cell1]
+	inventory.mu.Unlock()
+	assert.False(t, ok)
+}
+
+func TestTabletInventoryRefreshForgets(t *testing.T) {
+	oldTs := ts
+	oldInventory := inventory
+	defer func() {
+		inventory.close()
+		ts = oldTs
+		inventory = oldInventory
+		db.ClearVTOrcDatabase()
+	}()
+
+	// The tablets are forgotten once the forgotten instances cache is initialized.
+	config.MarkConfigurationLoaded()
+	require.Eventually(t, func() bool {
+		_, err := inst.ReadAllInstanceKeys()
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts = memorytopo.NewServer(ctx, cell1)
+	inventory = newTabletInventory()
+	_, err := ts.GetOrCreateShard(ctx, keyspace, shard)
+	require.NoError(t, err)
+	// The forgotten tablets are remembered for a while, so this test has tablets of its own.
+	tab200 := proto.Clone(tab100).(*topodatapb.Tablet)
+	tab200.Alias.Uid = 200
+	tab201 := proto.Clone(tab101).(*topodatapb.Tablet)
+	tab201.Alias.Uid = 201
+	for _, tablet := range []*topodatapb.Tablet{tab200, tab201} {
+		require.NoError(t, ts.CreateTablet(ctx, tablet))
+	}
+
+	refreshTabletsInCell(ctx, cell1, func(string) {}, false)
+	verifyTabletCount(t, 2)
+
+	// The tablets removed from the topo are forgotten by the refreshes of
+	// the cell served from the watch, like by the refreshes of the shard.
+	require.NoError(t, ts.DeleteTablet(ctx, tab201.Alias))
+	assert.Eventually(t, func() bool {
+		tablets, _ := inventory.watchedTablets([]string{cell1}, func(*topodatapb.Tablet) bool { return true })
+		return len(tablets) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	refreshTabletsInCell(ctx, cell1, func(string) {}, false)
+	verifyTabletCount(t, 1)
+
+	require.NoError(t, ts.DeleteTablet(ctx, tab200.Alias))
+	refreshTabletsInKeyspaceShard(ctx, keyspace, shard, func(string) {}, false, nil)
+	verifyTabletCount(t, 0)
+}
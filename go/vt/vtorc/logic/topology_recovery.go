@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand/v2"
+	"strconv"
 	"time"
 
 	"vitess.io/vitess/go/stats"
@@ -44,6 +45,7 @@ const (
 	ElectNewPrimaryRecoveryName                      string = "ElectNewPrimary"
 	FixPrimaryRecoveryName                           string = "FixPrimary"
 	FixReplicaRecoveryName                           string = "FixReplica"
+	ReconcileExternalReparentRecoveryName            string = "ReconcileExternalReparent"
 	RecoverErrantGTIDDetectedName                    string = "RecoverErrantGTIDDetected"
 )
 
@@ -61,12 +63,14 @@ var (
 	// detectedProblems is used to track the number of detected problems.
 	//
 	// When an issue is active it will be set to 1, when it is no longer active
-	// it will be reset back to 0.
+	// it will be reset back to 0. The problems injected by a recovery simulation
+	// are labeled as simulated.
 	detectedProblems = stats.NewGaugesWithMultiLabels("DetectedProblems", "Count of the different detected problems", []string{
 		"Analysis",
 		"TabletAlias",
 		"Keyspace",
 		"Shard",
+		"Simulated",
 	})
 
 	// recoveriesCounter counts the number of recoveries that VTOrc has performed
@@ -77,6 +81,9 @@ var (
 
 	// recoveriesFailureCounter counts the number of failed recoveries that VTOrc has performed
 	recoveriesFailureCounter = stats.NewCountersWithSingleLabel("FailedRecoveries", "Count of the different failed recoveries performed", "RecoveryType", actionableRecoveriesNames...)
+
+	// recoveriesDeferredCounter counts the number of recoveries that VTOrc has deferred because of a planned operation on the shard
+	recoveriesDeferredCounter = stats.NewCountersWithSingleLabel("DeferredRecoveries", "Count of the different recoveries deferred because of a recent planned operation", "RecoveryType", actionableRecoveriesNames...)
 )
 
 // recoveryFunction is the code of the recovery function to be used
@@ -95,6 +102,7 @@ const (
 	fixPrimaryFunc
 	fixReplicaFunc
 	recoverErrantGTIDDetectedFunc
+	reconcileExternalReparentFunc
 )
 
 // TopologyRecovery represents an entry in the topology_recovery table
@@ -305,6 +313,12 @@ func getCheckAndRecoverFunctionCode(analysisCode inst.AnalysisCode, tabletAlias
 			return noRecoveryFunc
 		}
 		return recoverErrantGTIDDetectedFunc
+	case inst.PrimaryExternallyReparented:
+		if !config.ReconcileExternalReparents() {
+			log.Infof("VTOrc not configured to reconcile external reparents, skipping recovering %v", analysisCode)
+			return noRecoveryFunc
+		}
+		return reconcileExternalReparentFunc
 	case inst.PrimaryHasPrimary:
 		return recoverPrimaryHasPrimaryFunc
 	case inst.LockedSemiSyncPrimary:
@@ -337,6 +351,11 @@ func getCheckAndRecoverFunctionCode(analysisCode inst.AnalysisCode, tabletAlias
 	return noRecoveryFunc
 }
 
+// IsActionableAnalysis tells if VTOrc would run an actionable recovery for the analysis.
+func IsActionableAnalysis(entry *inst.ReplicationAnalysis) bool {
+	return hasActionableRecovery(getCheckAndRecoverFunctionCode(entry.Analysis, entry.AnalyzedInstanceAlias))
+}
+
 // hasActionableRecovery tells if a recoveryFunction has an actionable recovery or not
 func hasActionableRecovery(recoveryFunctionCode recoveryFunction) bool {
 	switch recoveryFunctionCode {
@@ -360,6 +379,8 @@ func hasActionableRecovery(recoveryFunctionCode recoveryFunction) bool {
 		return true
 	case recoverErrantGTIDDetectedFunc:
 		return true
+	case reconcileExternalReparentFunc:
+		return true
 	default:
 		return false
 	}
@@ -390,6 +411,8 @@ func getCheckAndRecoverFunction(recoveryFunctionCode recoveryFunction) (
 		return fixReplica
 	case recoverErrantGTIDDetectedFunc:
 		return recoverErrantGTIDDetected
+	case reconcileExternalReparentFunc:
+		return reconcileExternalReparent
 	default:
 		return nil
 	}
@@ -419,6 +442,8 @@ func getRecoverFunctionName(recoveryFunctionCode recoveryFunction) string {
 		return FixReplicaRecoveryName
 	case recoverErrantGTIDDetectedFunc:
 		return RecoverErrantGTIDDetectedName
+	case reconcileExternalReparentFunc:
+		return ReconcileExternalReparentRecoveryName
 	default:
 		return ""
 	}
@@ -427,7 +452,7 @@ func getRecoverFunctionName(recoveryFunctionCode recoveryFunction) string {
 // isClusterWideRecovery returns whether the given recovery is a cluster-wide recovery or not
 func isClusterWideRecovery(recoveryFunctionCode recoveryFunction) bool {
 	switch recoveryFunctionCode {
-	case recoverDeadPrimaryFunc, electNewPrimaryFunc, recoverPrimaryTabletDeletedFunc:
+	case recoverDeadPrimaryFunc, electNewPrimaryFunc, recoverPrimaryTabletDeletedFunc, reconcileExternalReparentFunc:
 		return true
 	default:
 		return false
@@ -446,6 +471,8 @@ func analysisEntriesHaveSameRecovery(prevAnalysis, newAnalysis *inst.Replication
 func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (err error) {
 	countPendingRecoveries.Add(1)
 	defer countPendingRecoveries.Add(-1)
+	// The recoveries of simulated analyses aren't counted with the others.
+	simulation := recoverySimulations.running(analysisEntry)
 
 	checkAndRecoverFunctionCode := getCheckAndRecoverFunctionCode(analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias)
 	isActionableRecovery := hasActionableRecovery(checkAndRecoverFunctionCode)
@@ -482,10 +509,40 @@ func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (er
 	} else if recoveryDisabledGlobally {
 		log.Infof("CheckAndRecover: Analysis: %+v, Tablet: %+v: NOT Recovering host (disabled globally)",
 			analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias)
+		recoverySimulations.skip(simulation, "recoveries are disabled globally")
 
 		return err
 	}
 
+	// Check for recovery being disabled for the keyspace
+	if recoveryDisabledForKeyspace, err := inst.IsKeyspaceRecoveryDisabled(analysisEntry.AnalyzedKeyspace); err != nil {
+		log.Errorf("Unable to determine if recovery is disabled for keyspace %v: %v", analysisEntry.AnalyzedKeyspace, err)
+	} else if recoveryDisabledForKeyspace {
+		log.Infof("CheckAndRecover: Analysis: %+v, Tablet: %+v: NOT Recovering host (disabled for keyspace %v)",
+			analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias, analysisEntry.AnalyzedKeyspace)
+		recoverySimulations.skip(simulation, fmt.Sprintf("recoveries are disabled for keyspace %v", analysisEntry.AnalyzedKeyspace))
+
+		return nil
+	}
+
+	// Check for the keyspace being owned by another VTOrc
+	if !ownership.owns(analysisEntry.AnalyzedKeyspace) {
+		log.Infof("CheckAndRecover: Analysis: %+v, Tablet: %+v: NOT Recovering host (keyspace %v is owned by another VTOrc)",
+			analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias, analysisEntry.AnalyzedKeyspace)
+		recoverySimulations.skip(simulation, fmt.Sprintf("keyspace %v is owned by another VTOrc", analysisEntry.AnalyzedKeyspace))
+		return nil
+	}
+
+	// The post recovery hooks of the recovery run once the shard is unlocked,
+	// so that they don't delay the other operations on the shard.
+	var hookedRecovery *TopologyRecovery
+	defer func() {
+		if hookedRecovery != nil {
+			// The context of the recovery might have expired, the hooks are bounded by their own timeouts instead.
+			runPostRecoveryHooks(context.Background(), analysisEntry, hookedRecovery)
+		}
+	}()
+
 	// We lock the shard here and then refresh the tablets information
 	ctx, unlock, err := LockShard(context.Background(), analysisEntry.AnalyzedInstanceAlias, getLockAction(analysisEntry.AnalyzedInstanceAlias, analysisEntry.Analysis))
 	if err != nil {
@@ -551,26 +608,59 @@ func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (er
 			log.Infof("Analysis: %v on tablet %v - No longer valid, some other agent must have fixed the problem.", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias)
 			return nil
 		}
+		// A primary can look dead while a planned maintenance operation is demoting it or holding its locks.
+		// We don't fight the operation, and wait for the grace period to find if the problem persists after it.
+		if checkAndRecoverFunctionCode == recoverDeadPrimaryFunc {
+			if operation := recentPlannedOperation(ctx, analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard, analysisEntry.AnalyzedInstanceAlias); operation != "" {
+				log.Infof("Analysis: %v on tablet %v - Deferring the recovery because of a recent %v", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias, operation)
+				if simulation == nil {
+					recoveriesDeferredCounter.Add(getRecoverFunctionName(checkAndRecoverFunctionCode), 1)
+				}
+				recoverySimulations.skip(simulation, fmt.Sprintf("the recovery is deferred because of a recent %v", operation))
+				return nil
+			}
+		}
+	}
+
+	if isActionableRecovery {
+		runFailureDetectionHooks(ctx, analysisEntry)
+	}
+
+	// A simulated analysis in dry run mode stops right before the recovery.
+	if simulation != nil && simulation.DryRun {
+		log.Infof("executeCheckAndRecoverFunction: not running %v on %v, the analysis is simulated in dry run mode",
+			getRecoverFunctionName(checkAndRecoverFunctionCode), analysisEntry.AnalyzedInstanceAlias)
+		return nil
 	}
 
 	// Actually attempt recovery:
 	if isActionableRecovery || util.ClearToLog("executeCheckAndRecoverFunction: recovery", analysisEntry.AnalyzedInstanceAlias) {
 		log.Infof("executeCheckAndRecoverFunction: proceeding with %+v recovery on %+v; isRecoverable?: %+v", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias, isActionableRecovery)
 	}
+	if isActionableRecovery {
+		if err := runPreRecoveryHooks(ctx, analysisEntry); err != nil {
+			log.Errorf("executeCheckAndRecoverFunction: not running %v on %v, a pre recovery hook failed: %v",
+				getRecoverFunctionName(checkAndRecoverFunctionCode), analysisEntry.AnalyzedInstanceAlias, err)
+			return err
+		}
+	}
 	recoveryAttempted, topologyRecovery, err := getCheckAndRecoverFunction(checkAndRecoverFunctionCode)(ctx, analysisEntry)
 	if !recoveryAttempted {
 		return err
 	}
-	recoveryName := getRecoverFunctionName(checkAndRecoverFunctionCode)
-	recoveriesCounter.Add(recoveryName, 1)
-	if err != nil {
-		recoveriesFailureCounter.Add(recoveryName, 1)
-	} else {
-		recoveriesSuccessfulCounter.Add(recoveryName, 1)
+	if simulation == nil {
+		recoveryName := getRecoverFunctionName(checkAndRecoverFunctionCode)
+		recoveriesCounter.Add(recoveryName, 1)
+		if err != nil {
+			recoveriesFailureCounter.Add(recoveryName, 1)
+		} else {
+			recoveriesSuccessfulCounter.Add(recoveryName, 1)
+		}
 	}
 	if topologyRecovery == nil {
 		return err
 	}
+	hookedRecovery = topologyRecovery
 	if b, err := json.Marshal(topologyRecovery); err == nil {
 		log.Infof("Topology recovery: %+v", string(b))
 	} else {
@@ -593,6 +683,11 @@ func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (er
 
 // checkIfAlreadyFixed checks whether the problem that the analysis entry represents has already been fixed by another agent or not
 func checkIfAlreadyFixed(analysisEntry *inst.ReplicationAnalysis) (bool, error) {
+	// A simulated analysis isn't found by the replication analysis, since nothing is broken.
+	if recoverySimulations.running(analysisEntry) != nil {
+		return false, nil
+	}
+
 	// Run a replication analysis again. We will check if the problem persisted
 	analysisEntries, err := inst.GetReplicationAnalysis(analysisEntry.ClusterDetails.Keyspace, analysisEntry.ClusterDetails.Shard, &inst.ReplicationAnalysisHints{})
 	if err != nil {
@@ -618,6 +713,10 @@ func CheckAndRecover() {
 		log.Error(err)
 		return
 	}
+	simulations := recoverySimulations.start()
+	for entry := range simulations {
+		replicationAnalysis = append(replicationAnalysis, entry)
+	}
 
 	// Regardless of if the problem is solved or not we want to monitor active
 	// issues, we use a map of labels and set a counter to `1` for each problem
@@ -630,6 +729,7 @@ func CheckAndRecover() {
 				e.AnalyzedInstanceAlias,
 				e.AnalyzedKeyspace,
 				e.AnalyzedShard,
+				strconv.FormatBool(simulations[e] != nil),
 			}
 
 			key := detectedProblems.GetLabelName(names[:]...)
@@ -649,11 +749,15 @@ func CheckAndRecover() {
 	for _, j := range rand.Perm(len(replicationAnalysis)) {
 		analysisEntry := replicationAnalysis[j]
 
-		go func() {
-			if err := executeCheckAndRecoverFunction(analysisEntry); err != nil {
+		discoveryJobs.goRun(checkAndRecoverJob, func() {
+			err := executeCheckAndRecoverFunction(analysisEntry)
+			if err != nil {
 				log.Error(err)
 			}
-		}()
+			if simulation := simulations[analysisEntry]; simulation != nil {
+				recoverySimulations.finish(simulation, err, time.Now())
+			}
+		})
 	}
 }
 
@@ -821,3 +925,42 @@ func recoverErrantGTIDDetected(ctx context.Context, analysisEntry *inst.Replicat
 	err = changeTabletType(ctx, analyzedTablet, topodatapb.TabletType_DRAINED, reparentutil.IsReplicaSemiSync(durabilityPolicy, primaryTablet, analyzedTablet))
 	return true, topologyRecovery, err
 }
+
+// reconcileExternalReparent updates the topology to match a primary that was promoted outside of Vitess.
+// Like TabletExternallyReparented, it changes the type of the new primary to PRIMARY, which makes the
+// tablet take over the shard record. The former primary then demotes itself once it sees the change.
+func reconcileExternalReparent(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
+	topologyRecovery, err = AttemptRecoveryRegistration(analysisEntry)
+	if topologyRecovery == nil {
+		_ = AuditTopologyRecovery(topologyRecovery, fmt.Sprintf("found an active or recent recovery on %+v. Will not issue another reconcileExternalReparent.", analysisEntry.AnalyzedInstanceAlias))
+		return false, nil, err
+	}
+	log.Infof("Analysis: %v, will reconcile the topology with primary %+v", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias)
+	var promotedReplica *inst.Instance
+	// This has to be done in the end; whether successful or not, we should mark that the recovery is done.
+	// So that after the active period passes, we are able to run other recoveries.
+	defer func() {
+		_ = resolveRecovery(topologyRecovery, promotedReplica)
+	}()
+
+	analyzedTablet, err := inst.ReadTablet(analysisEntry.AnalyzedInstanceAlias)
+	if err != nil {
+		return false, topologyRecovery, err
+	}
+
+	durabilityPolicy, err := inst.GetDurabilityPolicy(analyzedTablet.Keyspace)
+	if err != nil {
+		log.Infof("Could not read the durability policy for %v/%v", analyzedTablet.Keyspace, analyzedTablet.Shard)
+		return false, topologyRecovery, err
+	}
+
+	err = changeTabletType(ctx, analyzedTablet, topodatapb.TabletType_PRIMARY, reparentutil.SemiSyncAckers(durabilityPolicy, analyzedTablet) > 0)
+	if err != nil {
+		return true, topologyRecovery, err
+	}
+	promotedReplica, _, _ = inst.ReadInstance(analysisEntry.AnalyzedInstanceAlias)
+	message := fmt.Sprintf("reconciled the topology with externally promoted primary %+v", analysisEntry.AnalyzedInstanceAlias)
+	_ = AuditTopologyRecovery(topologyRecovery, message)
+	_ = inst.AuditOperation(ReconcileExternalReparentRecoveryName, analysisEntry.AnalyzedInstanceAlias, message)
+	return true, topologyRecovery, nil
+}
@@ -218,6 +218,23 @@ func ReadActiveClusterRecoveries(keyspace string, shard string) ([]*TopologyReco
 	return readRecoveries(whereClause, ``, sqlutils.Args(keyspace, shard))
 }
 
+// ReadActiveRecoveries reads the recoveries in progress, of the given keyspace
+// and shard when they are set.
+func ReadActiveRecoveries(keyspace string, shard string) ([]*TopologyRecovery, error) {
+	whereConditions := []string{"end_recovery IS NULL"}
+	var args []any
+	if keyspace != "" {
+		whereConditions = append(whereConditions, "keyspace=?")
+		args = append(args, keyspace)
+	}
+	if shard != "" {
+		whereConditions = append(whereConditions, "shard=?")
+		args = append(args, shard)
+	}
+	whereClause := fmt.Sprintf("where %s", strings.Join(whereConditions, " and "))
+	return readRecoveries(whereClause, ``, args)
+}
+
 // ReadRecentRecoveries reads latest recovery entries from topology_recovery
 func ReadRecentRecoveries(page int) ([]*TopologyRecovery, error) {
 	whereConditions := []string{}
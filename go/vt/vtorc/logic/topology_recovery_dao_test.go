@@ -69,6 +69,45 @@ func TestTopologyRecovery(t *testing.T) {
 	})
 }
 
+func TestReadActiveRecoveries(t *testing.T) {
+	defer func() {
+		db.ClearVTOrcDatabase()
+	}()
+
+	var recoveries []*TopologyRecovery
+	for _, recoveryShard := range []string{"-80", "80-", "-80"} {
+		recovery, err := writeTopologyRecovery(NewTopologyRecovery(inst.ReplicationAnalysis{
+			AnalyzedInstanceAlias: "zone1-0000000101",
+			ClusterDetails: inst.ClusterInfo{
+				Keyspace: keyspace,
+				Shard:    recoveryShard,
+			},
+			Analysis: inst.DeadPrimary,
+		}))
+		require.NoError(t, err)
+		recoveries = append(recoveries, recovery)
+	}
+	// The resolved recoveries are no longer active.
+	require.NoError(t, writeResolveRecovery(recoveries[2]))
+
+	active, err := ReadActiveRecoveries("", "")
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	require.EqualValues(t, recoveries[1].ID, active[0].ID)
+	require.EqualValues(t, recoveries[0].ID, active[1].ID)
+	require.NotEmpty(t, active[0].RecoveryStartTimestamp)
+	require.Empty(t, active[0].RecoveryEndTimestamp)
+
+	active, err = ReadActiveRecoveries(keyspace, "-80")
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.EqualValues(t, recoveries[0].ID, active[0].ID)
+
+	active, err = ReadActiveRecoveries("other", "")
+	require.NoError(t, err)
+	require.Empty(t, active)
+}
+
 func TestExpireTableData(t *testing.T) {
 	oldVal := config.Config.AuditPurgeDays
 	config.Config.AuditPurgeDays = 10
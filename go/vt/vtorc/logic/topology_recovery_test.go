@@ -202,6 +202,7 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 		name                         string
 		ersEnabled                   bool
 		convertTabletWithErrantGTIDs bool
+		reconcileExternalReparents   bool
 		analysisCode                 inst.AnalysisCode
 		wantRecoveryFunction         recoveryFunction
 	}{
@@ -257,6 +258,16 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 			convertTabletWithErrantGTIDs: false,
 			analysisCode:                 inst.ErrantGTIDDetected,
 			wantRecoveryFunction:         noRecoveryFunc,
+		}, {
+			name:                       "PrimaryExternallyReparented",
+			reconcileExternalReparents: true,
+			analysisCode:               inst.PrimaryExternallyReparented,
+			wantRecoveryFunction:       reconcileExternalReparentFunc,
+		}, {
+			name:                       "PrimaryExternallyReparented with --reconcile-external-reparents false",
+			reconcileExternalReparents: false,
+			analysisCode:               inst.PrimaryExternallyReparented,
+			wantRecoveryFunction:       noRecoveryFunc,
 		},
 	}
 
@@ -270,6 +281,10 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 			config.SetConvertTabletWithErrantGTIDs(tt.convertTabletWithErrantGTIDs)
 			defer config.SetConvertTabletWithErrantGTIDs(convertErrantVal)
 
+			reconcileVal := config.ReconcileExternalReparents()
+			config.SetReconcileExternalReparents(tt.reconcileExternalReparents)
+			defer config.SetReconcileExternalReparents(reconcileVal)
+
 			gotFunc := getCheckAndRecoverFunctionCode(tt.analysisCode, "")
 			require.EqualValues(t, tt.wantRecoveryFunction, gotFunc)
 		})
@@ -17,8 +17,10 @@
 package logic
 
 import (
+	"context"
 	"os"
 	"os/signal"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -35,6 +37,7 @@ import (
 	"vitess.io/vitess/go/vt/vtorc/discovery"
 	"vitess.io/vitess/go/vt/vtorc/inst"
 	ometrics "vitess.io/vitess/go/vt/vtorc/metrics"
+	"vitess.io/vitess/go/vt/vtorc/process"
 	"vitess.io/vitess/go/vt/vtorc/util"
 )
 
@@ -84,32 +87,55 @@ func instancePollSecondsDuration() time.Duration {
 	return time.Duration(config.Config.InstancePollSeconds) * time.Second
 }
 
+// DiscoveryQueueStatus is the state of the discovery of the tablets.
+type DiscoveryQueueStatus struct {
+	QueueLength                 int
+	ConcurrencyLimit            int
+	Running                     int
+	RecentDiscoveries           int
+	FirstDiscoveryCycleComplete bool
+}
+
+// GetDiscoveryQueueStatus returns the state of the discovery of the tablets.
+func GetDiscoveryQueueStatus() DiscoveryQueueStatus {
+	status := DiscoveryQueueStatus{
+		ConcurrencyLimit:            discoveryThrottle.concurrencyLimit(),
+		Running:                     discoveryThrottle.runningDiscoveries(),
+		FirstDiscoveryCycleComplete: process.FirstDiscoveryCycleComplete.Load(),
+	}
+	if discoveryQueue != nil {
+		status.QueueLength = discoveryQueue.QueueLen()
+	}
+	if recentDiscoveryOperationKeys != nil {
+		status.RecentDiscoveries = recentDiscoveryOperationKeys.ItemCount()
+	}
+	return status
+}
+
 // acceptSighupSignal registers for SIGHUP signal from the OS to reload the configuration files.
-func acceptSighupSignal() {
+func acceptSighupSignal(ctx context.Context) {
 	c := make(chan os.Signal, 1)
 
 	signal.Notify(c, syscall.SIGHUP)
-	go func() {
-		for range c {
+	defer signal.Stop(c)
+	for {
+		select {
+		case <-c:
 			log.Infof("Received SIGHUP. Reloading configuration")
 			_ = inst.AuditOperation("reload-configuration", "", "Triggered via SIGHUP")
 			config.Reload()
 			discoveryMetrics.SetExpirePeriod(time.Duration(config.DiscoveryCollectionRetentionSeconds) * time.Second)
+		case <-ctx.Done():
+			return
 		}
-	}()
+	}
 }
 
 // closeVTOrc runs all the operations required to cleanly shutdown VTOrc
 func closeVTOrc() {
-	log.Infof("Starting VTOrc shutdown")
-	atomic.StoreInt32(&hasReceivedSIGTERM, 1)
-	discoveryMetrics.StopAutoExpiration()
-	// Poke other go routines to stop cleanly here ...
-	_ = inst.AuditOperation("shutdown", "", "Triggered via SIGTERM")
-	// wait for the locks to be released
-	waitForLocksRelease()
-	ts.Close()
-	log.Infof("VTOrc closed")
+	if err := Shutdown(context.Background()); err != nil {
+		log.Errorf("VTOrc shutdown: %v", err)
+	}
 }
 
 // waitForLocksRelease is used to wait for release of locks
@@ -132,28 +158,47 @@ func waitForLocksRelease() {
 }
 
 // handleDiscoveryRequests iterates the discoveryQueue channel and calls upon
-// instance discovery per entry.
-func handleDiscoveryRequests() {
+// instance discovery per entry, until the context is done.
+func handleDiscoveryRequests(ctx context.Context) {
 	discoveryQueue = discovery.CreateOrReturnQueue("DEFAULT")
 	// create a pool of discovery workers
 	for i := uint(0); i < config.DiscoveryMaxConcurrency; i++ {
+		discoveryRoutines.Add(1)
 		go func() {
+			defer discoveryRoutines.Done()
 			for {
-				tabletAlias := discoveryQueue.Consume()
-				DiscoverInstance(tabletAlias, false /* forceDiscovery */)
+				discoveryThrottle.acquire()
+				tabletAlias, err := discoveryQueue.ConsumeContext(ctx)
+				if err != nil {
+					discoveryThrottle.release()
+					return
+				}
+				discoverQueuedInstance(tabletAlias)
 				discoveryQueue.Release(tabletAlias)
+				discoveryThrottle.release()
 			}
 		}()
 	}
 }
 
+// discoverQueuedInstance discovers an instance of the discovery queue. The
+// instances of a poll now snapshot are discovered even if they were checked
+// recently, and are only marked as discovered in the snapshot once their
+// discovery succeeds. The failed ones are retried by the next health ticks.
+func discoverQueuedInstance(tabletAlias string) {
+	if DiscoverInstance(tabletAlias, pollNow.isPending(tabletAlias) /* forceDiscovery */) {
+		pollNow.discovered(tabletAlias, time.Now())
+	}
+}
+
 // DiscoverInstance will attempt to discover (poll) an instance (unless
 // it is already up-to-date) and will also ensure that its primary and
-// replicas (if any) are also checked.
-func DiscoverInstance(tabletAlias string, forceDiscovery bool) {
+// replicas (if any) are also checked. It returns false if the instance
+// couldn't be read.
+func DiscoverInstance(tabletAlias string, forceDiscovery bool) (ok bool) {
 	if inst.InstanceIsForgotten(tabletAlias) {
 		log.Infof("discoverInstance: skipping discovery of %+v because it is set to be forgotten", tabletAlias)
-		return
+		return true
 	}
 
 	// create stopwatch entries
@@ -177,7 +222,7 @@ func DiscoverInstance(tabletAlias string, forceDiscovery bool) {
 	}()
 
 	if tabletAlias == "" {
-		return
+		return true
 	}
 
 	// Calculate the expiry period each time as InstancePollSeconds
@@ -185,7 +230,7 @@ func DiscoverInstance(tabletAlias string, forceDiscovery bool) {
 	// it is not possible to change the cache's default expiry..
 	if existsInCacheError := recentDiscoveryOperationKeys.Add(tabletAlias, true, instancePollSecondsDuration()); existsInCacheError != nil && !forceDiscovery {
 		// Just recently attempted
-		return
+		return true
 	}
 
 	latency.Start("backend")
@@ -193,7 +238,7 @@ func DiscoverInstance(tabletAlias string, forceDiscovery bool) {
 	latency.Stop("backend")
 	if !forceDiscovery && found && instance.IsUpToDate && instance.IsLastCheckValid {
 		// we've already discovered this one. Skip!
-		return
+		return true
 	}
 
 	discoveriesCounter.Inc(1)
@@ -205,6 +250,7 @@ func DiscoverInstance(tabletAlias string, forceDiscovery bool) {
 	totalLatency := latency.Elapsed("total")
 	backendLatency := latency.Elapsed("backend")
 	instanceLatency := latency.Elapsed("instance")
+	discoveryThrottle.recordBackendLatency(backendLatency)
 
 	if forceDiscovery {
 		log.Infof("Force discovered - %+v, err - %v", instance, err)
@@ -229,7 +275,7 @@ func DiscoverInstance(tabletAlias string, forceDiscovery bool) {
 				instanceLatency.Seconds(),
 				err)
 		}
-		return
+		return false
 	}
 
 	metric = &discovery.Metric{
@@ -241,6 +287,7 @@ func DiscoverInstance(tabletAlias string, forceDiscovery bool) {
 		Err:             nil,
 	}
 	_ = discoveryMetrics.Append(metric)
+	return true
 }
 
 // onHealthTick handles the actions to take to discover/poll instances
@@ -263,100 +310,104 @@ func onHealthTick() {
 	}()
 	// avoid any logging unless there's something to be done
 	if len(tabletAliases) > 0 {
-		for _, tabletAlias := range tabletAliases {
-			if tabletAlias != "" {
-				discoveryQueue.Push(tabletAlias)
-			}
-		}
+		tabletAliases = slices.DeleteFunc(tabletAliases, func(tabletAlias string) bool { return tabletAlias == "" })
+		pushForDiscovery(discoveryQueue, tabletAliases)
 	}
 }
 
+// StartContinuousDiscovery runs ContinuousDiscovery in a goroutine, which
+// Shutdown waits for.
+func StartContinuousDiscovery() {
+	discoveryRoutines.Add(1)
+	go func() {
+		defer discoveryRoutines.Done()
+		ContinuousDiscovery()
+	}()
+}
+
 // ContinuousDiscovery starts an asynchronous infinite discovery process where instances are
 // periodically investigated and their status captured, and long since unseen instances are
-// purged and forgotten.
-// nolint SA1015: using time.Tick leaks the underlying ticker
+// purged and forgotten. It returns once VTOrc is shut down.
 func ContinuousDiscovery() {
 	log.Infof("continuous discovery: setting up")
 	recentDiscoveryOperationKeys = cache.New(instancePollSecondsDuration(), time.Second)
 
-	go handleDiscoveryRequests()
+	ctx := vtorcCtx
+	handleDiscoveryRequests(ctx)
 
-	healthTick := time.Tick(config.HealthPollSeconds * time.Second)
-	caretakingTick := time.Tick(time.Minute)
-	recoveryTick := time.Tick(time.Duration(config.Config.RecoveryPollSeconds) * time.Second)
+	healthTick := time.NewTicker(config.HealthPollSeconds * time.Second)
+	defer healthTick.Stop()
+	caretakingTick := time.NewTicker(time.Minute)
+	defer caretakingTick.Stop()
+	recoveryTick := time.NewTicker(time.Duration(config.Config.RecoveryPollSeconds) * time.Second)
+	defer recoveryTick.Stop()
 	tabletTopoTick := OpenTabletDiscovery()
-	var recoveryEntrance int64
 	var snapshotTopologiesTick <-chan time.Time
 	if config.Config.SnapshotTopologiesIntervalHours > 0 {
-		snapshotTopologiesTick = time.Tick(time.Duration(config.Config.SnapshotTopologiesIntervalHours) * time.Hour)
+		ticker := time.NewTicker(time.Duration(config.Config.SnapshotTopologiesIntervalHours) * time.Hour)
+		defer ticker.Stop()
+		snapshotTopologiesTick = ticker.C
 	}
 
 	go func() {
 		_ = ometrics.InitMetrics()
 	}()
-	go acceptSighupSignal()
+	discoveryRoutines.Add(1)
+	go func() {
+		defer discoveryRoutines.Done()
+		acceptSighupSignal(ctx)
+	}()
 	// On termination of the server, we should close VTOrc cleanly
 	servenv.OnTermSync(closeVTOrc)
 
+	registerDiscoveryJobs()
+
 	log.Infof("continuous discovery: starting")
 	for {
 		select {
-		case <-healthTick:
-			go func() {
-				onHealthTick()
-			}()
-		case <-caretakingTick:
+		case <-healthTick.C:
+			discoveryJobs.start(healthTickJob)
+		case <-caretakingTick.C:
 			// Various periodic internal maintenance tasks
-			go func() {
-				go inst.ForgetLongUnseenInstances()
-				go inst.ExpireAudit()
-				go inst.ExpireStaleInstanceBinlogCoordinates()
-				go ExpireRecoveryDetectionHistory()
-				go ExpireTopologyRecoveryHistory()
-				go ExpireTopologyRecoveryStepsHistory()
-			}()
-		case <-recoveryTick:
-			go func() {
-				go inst.ExpireInstanceAnalysisChangelog()
-
-				go func() {
-					// This function is non re-entrant (it can only be running once at any point in time)
-					if atomic.CompareAndSwapInt64(&recoveryEntrance, 0, 1) {
-						defer atomic.StoreInt64(&recoveryEntrance, 0)
-					} else {
-						return
-					}
-					CheckAndRecover()
-				}()
-			}()
+			for _, name := range caretakingJobs {
+				discoveryJobs.start(name)
+			}
+		case <-recoveryTick.C:
+			discoveryJobs.start(expireInstanceAnalysisChangelogJob)
+			discoveryJobs.start(checkAndRecoverJob)
 		case <-snapshotTopologiesTick:
-			go func() {
-				go inst.SnapshotTopologies()
-			}()
+			discoveryJobs.start(snapshotTopologiesJob)
 		case <-tabletTopoTick:
-			refreshAllInformation()
+			discoveryJobs.run(refreshAllInformationJob)
+		case <-ctx.Done():
+			log.Infof("continuous discovery: stopped")
+			return
 		}
 	}
 }
 
 // refreshAllInformation refreshes both shard and tablet information. This is meant to be run on tablet topo ticks.
 func refreshAllInformation() {
+	// Refresh the owned keyspaces first, since the refreshes below only
+	// cover the keyspaces that this VTOrc owns.
+	refreshKeyspaceOwnership()
+
 	// Create a wait group
 	var wg sync.WaitGroup
 
 	// Refresh all keyspace information.
 	wg.Add(1)
-	go func() {
+	discoveryJobs.goRun(refreshAllInformationJob, func() {
 		defer wg.Done()
 		RefreshAllKeyspacesAndShards()
-	}()
+	})
 
 	// Refresh all tablets.
 	wg.Add(1)
-	go func() {
+	discoveryJobs.goRun(refreshAllInformationJob, func() {
 		defer wg.Done()
 		refreshAllTablets()
-	}()
+	})
 
 	// Wait for both the refreshes to complete
 	wg.Wait()
@@ -26,9 +26,16 @@ import (
 
 var FirstDiscoveryCycleComplete atomic.Bool
 
+// BackingOffJobs returns the periodic jobs of VTOrc that back off after a
+// panic. It is set by the package that runs the jobs.
+var BackingOffJobs = func() []string { return nil }
+
 type NodeHealth struct {
 	Healthy      bool
 	LastReported time.Time
+	// BackingOffJobs are the periodic jobs that back off after a panic,
+	// which make VTOrc unhealthy.
+	BackingOffJobs []string `json:",omitempty"`
 }
 
 var ThisNodeHealth = &NodeHealth{}
@@ -57,7 +64,8 @@ func writeHealthToDatabase() bool {
 func HealthTest() (health *NodeHealth, discoveredOnce bool) {
 	ThisNodeHealth.LastReported = time.Now()
 	discoveredOnce = FirstDiscoveryCycleComplete.Load()
-	ThisNodeHealth.Healthy = writeHealthToDatabase()
+	ThisNodeHealth.BackingOffJobs = BackingOffJobs()
+	ThisNodeHealth.Healthy = writeHealthToDatabase() && len(ThisNodeHealth.BackingOffJobs) == 0
 
 	return ThisNodeHealth, discoveredOnce
 }
@@ -19,9 +19,11 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"vitess.io/vitess/go/acl"
@@ -48,9 +50,30 @@ const (
 	databaseStateAPI              = "/api/database-state"
 	healthAPI                     = "/debug/health"
 	AggregatedDiscoveryMetricsAPI = "/api/aggregated-discovery-metrics"
+	discoveryMetricsAPI           = "/api/discovery-metrics"
+	auditAPI                      = "/api/audit"
+	pollNowAPI                    = "/api/poll-now"
+	pollNowStatusAPI              = "/api/poll-now-status"
+	simulateAnalysisAPI           = "/api/simulate-analysis"
+	recoverySimulationsAPI        = "/api/recovery-simulations"
+	shardLockWaitsAPI             = "/api/shard-lock-waits"
+	fixableProblemsAPI            = "/api/fixable-problems"
+	fixProblemsAPI                = "/api/fix-problems"
+	jobsAPI                       = "/debug/jobs"
 
 	shardWithoutKeyspaceFilteringErrorStr = "Filtering by shard without keyspace isn't supported"
 	notAValidValueForSeconds              = "Invalid value for seconds"
+	notAValidValueForLimit                = "Invalid value for limit"
+	notAValidValueForPage                 = "Invalid value for page"
+	notAValidValueForSince                = "Invalid value for since, expected an RFC 3339 time"
+	notAValidValueForUntil                = "Invalid value for until, expected an RFC 3339 time"
+	notAValidValueForAggregate            = "Invalid value for aggregate"
+	notAValidValueForFormat               = "Invalid value for format, expected json or csv"
+	pollNowTokenRequiredErrorStr          = "Token is required"
+	pollNowTokenNotFoundErrorStr          = "No poll now snapshot found for the token"
+	analysisRequiredErrorStr              = "Analysis is required"
+	tabletOrShardRequiredErrorStr         = "Either the tablet alias or the keyspace and shard are required"
+	notAValidValueForDryRun               = "Invalid value for dryRun"
 )
 
 var (
@@ -64,6 +87,16 @@ var (
 		databaseStateAPI,
 		healthAPI,
 		AggregatedDiscoveryMetricsAPI,
+		discoveryMetricsAPI,
+		auditAPI,
+		pollNowAPI,
+		pollNowStatusAPI,
+		simulateAnalysisAPI,
+		recoverySimulationsAPI,
+		shardLockWaitsAPI,
+		fixableProblemsAPI,
+		fixProblemsAPI,
+		jobsAPI,
 	}
 )
 
@@ -92,6 +125,26 @@ func (v *vtorcAPI) ServeHTTP(response http.ResponseWriter, request *http.Request
 		databaseStateAPIHandler(response)
 	case AggregatedDiscoveryMetricsAPI:
 		AggregatedDiscoveryMetricsAPIHandler(response, request)
+	case discoveryMetricsAPI:
+		discoveryMetricsAPIHandler(response, request)
+	case auditAPI:
+		auditAPIHandler(response, request)
+	case pollNowAPI:
+		pollNowAPIHandler(response)
+	case pollNowStatusAPI:
+		pollNowStatusAPIHandler(response, request)
+	case simulateAnalysisAPI:
+		simulateAnalysisAPIHandler(response, request)
+	case recoverySimulationsAPI:
+		recoverySimulationsAPIHandler(response)
+	case shardLockWaitsAPI:
+		shardLockWaitsAPIHandler(response, request)
+	case fixableProblemsAPI:
+		fixableProblemsAPIHandler(response, request)
+	case fixProblemsAPI:
+		fixProblemsAPIHandler(response, request)
+	case jobsAPI:
+		jobsAPIHandler(response)
 	default:
 		// This should be unreachable. Any endpoint which isn't registered is automatically redirected to /debug/status.
 		// This code will only be reachable if we register an API but don't handle it here. That will be a bug.
@@ -104,11 +157,13 @@ func getACLPermissionLevelForAPI(apiEndpoint string) string {
 	switch apiEndpoint {
 	case problemsAPI, errantGTIDsAPI:
 		return acl.MONITORING
-	case disableGlobalRecoveriesAPI, enableGlobalRecoveriesAPI:
+	case disableGlobalRecoveriesAPI, enableGlobalRecoveriesAPI, pollNowAPI, simulateAnalysisAPI, fixProblemsAPI:
 		return acl.ADMIN
 	case replicationAnalysisAPI:
 		return acl.MONITORING
-	case healthAPI, databaseStateAPI:
+	case healthAPI, databaseStateAPI, jobsAPI:
+		return acl.MONITORING
+	case discoveryMetricsAPI, auditAPI, pollNowStatusAPI, recoverySimulationsAPI, shardLockWaitsAPI, fixableProblemsAPI:
 		return acl.MONITORING
 	}
 	return acl.ADMIN
@@ -205,6 +260,258 @@ func AggregatedDiscoveryMetricsAPIHandler(response http.ResponseWriter, request
 	returnAsJSON(response, http.StatusOK, metric)
 }
 
+// discoveryMetricsAPIHandler is the handler for the discoveryMetricsAPI endpoint
+func discoveryMetricsAPIHandler(response http.ResponseWriter, request *http.Request) {
+	// This api supports filtering by time range, tablet alias and discovery status,
+	// and exports either the raw or the aggregated metrics as json or csv.
+	query := request.URL.Query()
+	q := discovery.MetricsQuery{
+		TabletAlias: query.Get("alias"),
+	}
+	var err error
+	if q.Status, err = discovery.ParseMetricStatus(query.Get("status")); err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if qUntil := query.Get("until"); qUntil != "" {
+		if q.Until, err = time.Parse(time.RFC3339, qUntil); err != nil {
+			http.Error(response, notAValidValueForUntil, http.StatusBadRequest)
+			return
+		}
+	}
+	if qSince := query.Get("since"); qSince != "" {
+		if q.Since, err = time.Parse(time.RFC3339, qSince); err != nil {
+			http.Error(response, notAValidValueForSince, http.StatusBadRequest)
+			return
+		}
+	} else {
+		// default to the last 60 seconds
+		seconds := 60
+		if qSeconds := query.Get("seconds"); qSeconds != "" {
+			if seconds, err = strconv.Atoi(qSeconds); err != nil {
+				http.Error(response, notAValidValueForSeconds, http.StatusBadRequest)
+				return
+			}
+		}
+		q.Since = time.Now().Add(time.Duration(-1*seconds) * time.Second)
+	}
+	aggregated := false
+	if qAggregate := query.Get("aggregate"); qAggregate != "" {
+		if aggregated, err = strconv.ParseBool(qAggregate); err != nil {
+			http.Error(response, notAValidValueForAggregate, http.StatusBadRequest)
+			return
+		}
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(response, notAValidValueForFormat, http.StatusBadRequest)
+		return
+	}
+
+	c := collection.CreateOrReturnCollection(logic.DiscoveryMetricsName)
+	var result any
+	if aggregated {
+		result, err = discovery.QueryAggregated(c, q)
+	} else {
+		result, err = discovery.QueryMetrics(c, q)
+	}
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if format != "csv" {
+		returnAsJSON(response, http.StatusOK, result)
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	switch result := result.(type) {
+	case discovery.AggregatedDiscoveryMetrics:
+		err = discovery.WriteAggregatedCSV(buf, result)
+	case []discovery.MetricRecord:
+		err = discovery.WriteMetricsCSV(buf, result)
+	}
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	response.Header().Set("Content-Disposition", `attachment; filename="discovery-metrics.csv"`)
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(buf.Bytes())
+}
+
+// auditAPIHandler is the handler for the auditAPI endpoint
+func auditAPIHandler(response http.ResponseWriter, request *http.Request) {
+	// This api supports filtering by tablet alias, keyspace, shard and audit type, and pagination.
+	query := request.URL.Query()
+	filter := &inst.AuditFilter{
+		TabletAlias: query.Get("alias"),
+		Keyspace:    query.Get("keyspace"),
+		Shard:       query.Get("shard"),
+		AuditType:   query.Get("type"),
+	}
+	if filter.Shard != "" && filter.Keyspace == "" {
+		http.Error(response, shardWithoutKeyspaceFilteringErrorStr, http.StatusBadRequest)
+		return
+	}
+	var err error
+	if qLimit := query.Get("limit"); qLimit != "" {
+		if filter.Limit, err = strconv.Atoi(qLimit); err != nil || filter.Limit < 0 {
+			http.Error(response, notAValidValueForLimit, http.StatusBadRequest)
+			return
+		}
+	}
+	if qPage := query.Get("page"); qPage != "" {
+		if filter.Page, err = strconv.Atoi(qPage); err != nil || filter.Page < 0 {
+			http.Error(response, notAValidValueForPage, http.StatusBadRequest)
+			return
+		}
+	}
+	events, err := inst.ReadAuditEvents(filter)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, events)
+}
+
+// pollNowAPIHandler is the handler for the pollNowAPI endpoint. It enqueues the discovery of all
+// the known instances and returns a snapshot token that can be polled with the pollNowStatusAPI.
+func pollNowAPIHandler(response http.ResponseWriter) {
+	status, err := logic.RequestPollNow()
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusAccepted, status)
+}
+
+// pollNowStatusAPIHandler is the handler for the pollNowStatusAPI endpoint
+func pollNowStatusAPIHandler(response http.ResponseWriter, request *http.Request) {
+	token := request.URL.Query().Get("token")
+	if token == "" {
+		http.Error(response, pollNowTokenRequiredErrorStr, http.StatusBadRequest)
+		return
+	}
+	status := logic.GetPollNowStatus(token)
+	if status == nil {
+		http.Error(response, pollNowTokenNotFoundErrorStr, http.StatusNotFound)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, status)
+}
+
+// simulateAnalysisAPIHandler is the handler for the simulateAnalysisAPI endpoint. It injects the analysis
+// into the next recovery run, on the given tablet or on the primary of the given shard.
+func simulateAnalysisAPIHandler(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	analysis := query.Get("analysis")
+	if analysis == "" {
+		http.Error(response, analysisRequiredErrorStr, http.StatusBadRequest)
+		return
+	}
+	tabletAlias := query.Get("tablet")
+	keyspace := query.Get("keyspace")
+	shard := query.Get("shard")
+	if tabletAlias == "" && (keyspace == "" || shard == "") {
+		http.Error(response, tabletOrShardRequiredErrorStr, http.StatusBadRequest)
+		return
+	}
+	dryRun := false
+	if qDryRun := query.Get("dryRun"); qDryRun != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(qDryRun); err != nil {
+			http.Error(response, notAValidValueForDryRun, http.StatusBadRequest)
+			return
+		}
+	}
+
+	simulation, err := logic.SimulateAnalysis(inst.AnalysisCode(analysis), tabletAlias, keyspace, shard, dryRun)
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, logic.ErrRecoverySimulationDisabled) {
+			code = http.StatusForbidden
+		}
+		http.Error(response, err.Error(), code)
+		return
+	}
+	returnAsJSON(response, http.StatusAccepted, simulation)
+}
+
+// recoverySimulationsAPIHandler is the handler for the recoverySimulationsAPI endpoint
+func recoverySimulationsAPIHandler(response http.ResponseWriter) {
+	returnAsJSON(response, http.StatusOK, logic.GetRecoverySimulations())
+}
+
+// jobsAPIHandler is the handler for the jobsAPI endpoint. It shows the health of the periodic
+// jobs of VTOrc, their runs, errors and panics, and whether they are backing off after a panic.
+func jobsAPIHandler(response http.ResponseWriter) {
+	returnAsJSON(response, http.StatusOK, logic.GetJobStatuses())
+}
+
+// shardLockWaitsAPIHandler is the handler for the shardLockWaitsAPI endpoint. It lists the recent
+// attempts to acquire shard locks for recoveries, how long they took, and who held the lock when
+// it couldn't be acquired.
+func shardLockWaitsAPIHandler(response http.ResponseWriter, request *http.Request) {
+	// This api also supports filtering by shard and keyspace provided.
+	shard := request.URL.Query().Get("shard")
+	keyspace := request.URL.Query().Get("keyspace")
+	if shard != "" && keyspace == "" {
+		http.Error(response, shardWithoutKeyspaceFilteringErrorStr, http.StatusBadRequest)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, logic.GetShardLockWaits(keyspace, shard))
+}
+
+// fixableProblemsAPIHandler is the handler for the fixableProblemsAPI endpoint. It lists the problems
+// found by the replication analysis, and whether they can be fixed through the fixProblemsAPI.
+func fixableProblemsAPIHandler(response http.ResponseWriter, request *http.Request) {
+	// This api also supports filtering by shard and keyspace provided.
+	shard := request.URL.Query().Get("shard")
+	keyspace := request.URL.Query().Get("keyspace")
+	if shard != "" && keyspace == "" {
+		http.Error(response, shardWithoutKeyspaceFilteringErrorStr, http.StatusBadRequest)
+		return
+	}
+	problems, err := logic.GetFixableProblems(keyspace, shard)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, problems)
+}
+
+// fixProblemsAPIHandler is the handler for the fixProblemsAPI endpoint. It fixes the fixable problems
+// of the given keyspace and shard, or of the given comma separated list of tablets, and returns the
+// outcome of each fix.
+func fixProblemsAPIHandler(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	shard := query.Get("shard")
+	keyspace := query.Get("keyspace")
+	if shard != "" && keyspace == "" {
+		http.Error(response, shardWithoutKeyspaceFilteringErrorStr, http.StatusBadRequest)
+		return
+	}
+	var tabletAliases []string
+	if tablets := query.Get("tablets"); tablets != "" {
+		tabletAliases = strings.Split(tablets, ",")
+	}
+	dryRun := false
+	if qDryRun := query.Get("dryRun"); qDryRun != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(qDryRun); err != nil {
+			http.Error(response, notAValidValueForDryRun, http.StatusBadRequest)
+			return
+		}
+	}
+	fixes, err := logic.FixProblems(keyspace, shard, tabletAliases, dryRun)
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	returnAsJSON(response, http.StatusOK, fixes)
+}
+
 // disableGlobalRecoveriesAPIHandler is the handler for the disableGlobalRecoveriesAPI endpoint
 func disableGlobalRecoveriesAPIHandler(response http.ResponseWriter) {
 	err := logic.DisableRecovery()
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -31,6 +33,36 @@ func TestGetACLPermissionLevelForAPI(t *testing.T) {
 		}, {
 			apiEndpoint: healthAPI,
 			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: discoveryMetricsAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: auditAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: pollNowAPI,
+			want:        acl.ADMIN,
+		}, {
+			apiEndpoint: pollNowStatusAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: simulateAnalysisAPI,
+			want:        acl.ADMIN,
+		}, {
+			apiEndpoint: recoverySimulationsAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: shardLockWaitsAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: jobsAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: fixableProblemsAPI,
+			want:        acl.MONITORING,
+		}, {
+			apiEndpoint: fixProblemsAPI,
+			want:        acl.ADMIN,
 		}, {
 			apiEndpoint: "gibberish",
 			want:        acl.ADMIN,
@@ -43,3 +75,89 @@ func TestGetACLPermissionLevelForAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestPollNowStatusAPIHandler(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{
+			url:      pollNowStatusAPI,
+			wantCode: http.StatusBadRequest,
+			wantBody: pollNowTokenRequiredErrorStr,
+		}, {
+			url:      pollNowStatusAPI + "?token=unknown",
+			wantCode: http.StatusNotFound,
+			wantBody: pollNowTokenNotFoundErrorStr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			pollNowStatusAPIHandler(response, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			require.Equal(t, tt.wantCode, response.Code)
+			require.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestSimulateAnalysisAPIHandler(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{
+			url:      simulateAnalysisAPI + "?keyspace=ks&shard=0",
+			wantCode: http.StatusBadRequest,
+			wantBody: analysisRequiredErrorStr,
+		}, {
+			url:      simulateAnalysisAPI + "?analysis=DeadPrimary&keyspace=ks",
+			wantCode: http.StatusBadRequest,
+			wantBody: tabletOrShardRequiredErrorStr,
+		}, {
+			url:      simulateAnalysisAPI + "?analysis=DeadPrimary&keyspace=ks&shard=0&dryRun=maybe",
+			wantCode: http.StatusBadRequest,
+			wantBody: notAValidValueForDryRun,
+		}, {
+			url:      simulateAnalysisAPI + "?analysis=DeadPrimary&keyspace=ks&shard=0",
+			wantCode: http.StatusForbidden,
+			wantBody: "--allow-recovery-simulation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			simulateAnalysisAPIHandler(response, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			require.Equal(t, tt.wantCode, response.Code)
+			require.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestFixProblemsAPIHandler(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{
+			url:      fixProblemsAPI + "?shard=0",
+			wantCode: http.StatusBadRequest,
+			wantBody: shardWithoutKeyspaceFilteringErrorStr,
+		}, {
+			url:      fixProblemsAPI + "?keyspace=ks&shard=0&dryRun=maybe",
+			wantCode: http.StatusBadRequest,
+			wantBody: notAValidValueForDryRun,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			fixProblemsAPIHandler(response, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			require.Equal(t, tt.wantCode, response.Code)
+			require.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -24,5 +24,5 @@ import (
 // StartVTOrcDiscovery starts VTOrc discovery serving
 func StartVTOrcDiscovery() {
 	log.Info("Starting Discovery")
-	go logic.ContinuousDiscovery()
+	logic.StartContinuousDiscovery()
 }
@@ -138,6 +138,16 @@ func (client *FakeTabletManagerClient) UnlockTables(ctx context.Context, tablet
 	return nil
 }
 
+// AcquireSnapshotLock is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) AcquireSnapshotLock(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.AcquireSnapshotLockRequest) (*tabletmanagerdatapb.AcquireSnapshotLockResponse, error) {
+	return &tabletmanagerdatapb.AcquireSnapshotLockResponse{}, nil
+}
+
+// ReleaseSnapshotLock is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) ReleaseSnapshotLock(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (*tabletmanagerdatapb.ReleaseSnapshotLockResponse, error) {
+	return &tabletmanagerdatapb.ReleaseSnapshotLockResponse{}, nil
+}
+
 //
 // Various read-write methods
 //
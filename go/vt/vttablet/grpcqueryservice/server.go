@@ -57,6 +57,26 @@ func (q *query) Execute(ctx context.Context, request *querypb.ExecuteRequest) (r
 	}, nil
 }
 
+// ExecuteBatch is part of the queryservice.QueryServer interface
+func (q *query) ExecuteBatch(ctx context.Context, request *querypb.ExecuteBatchRequest) (response *querypb.ExecuteBatchResponse, err error) {
+	defer q.server.HandlePanic(&err)
+	ctx = callerid.NewContext(callinfo.GRPCCallInfo(ctx),
+		request.EffectiveCallerId,
+		request.ImmediateCallerId,
+	)
+	results, err := q.server.ExecuteBatch(ctx, request.Target, request.Queries, request.TransactionId, request.ReservedId, request.Options)
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	response = &querypb.ExecuteBatchResponse{
+		Results: make([]*querypb.QueryResult, 0, len(results)),
+	}
+	for _, result := range results {
+		response.Results = append(response.Results, sqltypes.ResultToProto3(result))
+	}
+	return response, nil
+}
+
 // StreamExecute is part of the queryservice.QueryServer interface
 func (q *query) StreamExecute(request *querypb.StreamExecuteRequest, stream queryservicepb.Query_StreamExecuteServer) (err error) {
 	defer q.server.HandlePanic(&err)
@@ -496,7 +516,15 @@ func (q *query) Release(ctx context.Context, request *querypb.ReleaseRequest) (r
 // GetSchema implements the QueryServer interface
 func (q *query) GetSchema(request *querypb.GetSchemaRequest, stream queryservicepb.Query_GetSchemaServer) (err error) {
 	defer q.server.HandlePanic(&err)
-	err = q.server.GetSchema(stream.Context(), request.Target, request.TableType, request.TableNames, stream.Send)
+	ctx := callerid.NewContext(callinfo.GRPCCallInfo(stream.Context()),
+		request.EffectiveCallerId,
+		request.ImmediateCallerId,
+	)
+	// Only Vitess itself sends GetSchema, so its internal CallerID is trusted.
+	if component := callerid.InternalComponentFromCallerID(request.EffectiveCallerId); component != "" {
+		ctx = callerid.NewInternalContext(ctx, component)
+	}
+	err = q.server.GetSchema(ctx, request.Target, request.TableType, request.TableNames, stream.Send)
 	return vterrors.ToGRPC(err)
 }
 
@@ -137,6 +137,34 @@ func (conn *gRPCQueryClient) Execute(ctx context.Context, target *querypb.Target
 	return sqltypes.Proto3ToResult(er.Result), nil
 }
 
+// ExecuteBatch sends a batch of queries to vttablet in a single round trip.
+func (conn *gRPCQueryClient) ExecuteBatch(ctx context.Context, target *querypb.Target, queries []*querypb.BoundQuery, transactionID, reservedID int64, options *querypb.ExecuteOptions) ([]*sqltypes.Result, error) {
+	conn.mu.RLock()
+	defer conn.mu.RUnlock()
+	if conn.cc == nil {
+		return nil, tabletconn.ConnClosed
+	}
+
+	req := &querypb.ExecuteBatchRequest{
+		EffectiveCallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		ImmediateCallerId: callerid.ImmediateCallerIDFromContext(ctx),
+		Target:            target,
+		Queries:           queries,
+		TransactionId:     transactionID,
+		Options:           options,
+		ReservedId:        reservedID,
+	}
+	er, err := conn.c.ExecuteBatch(ctx, req)
+	if err != nil {
+		return nil, tabletconn.ErrorFromGRPC(err)
+	}
+	results := make([]*sqltypes.Result, 0, len(er.Results))
+	for _, result := range er.Results {
+		results = append(results, sqltypes.Proto3ToResult(result))
+	}
+	return results, nil
+}
+
 // StreamExecute executes the query and streams results back through callback.
 func (conn *gRPCQueryClient) StreamExecute(ctx context.Context, target *querypb.Target, query string, bindVars map[string]*querypb.BindVariable, transactionID int64, reservedID int64, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) error {
 	// All streaming clients should follow the code pattern below.
@@ -1099,9 +1127,11 @@ func (conn *gRPCQueryClient) GetSchema(ctx context.Context, target *querypb.Targ
 		}
 
 		stream, err := conn.c.GetSchema(ctx, &querypb.GetSchemaRequest{
-			Target:     target,
-			TableType:  tableType,
-			TableNames: tableNames,
+			Target:            target,
+			TableType:         tableType,
+			TableNames:        tableNames,
+			EffectiveCallerId: callerid.EffectiveCallerIDFromContext(ctx),
+			ImmediateCallerId: callerid.ImmediateCallerIDFromContext(ctx),
 		})
 		if err != nil {
 			return nil, tabletconn.ErrorFromGRPC(err)
@@ -494,6 +494,26 @@ func (client *Client) UnlockTables(ctx context.Context, tablet *topodatapb.Table
 	return err
 }
 
+// AcquireSnapshotLock is part of the tmclient.TabletManagerClient interface.
+func (client *Client) AcquireSnapshotLock(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.AcquireSnapshotLockRequest) (*tabletmanagerdatapb.AcquireSnapshotLockResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.AcquireSnapshotLock(ctx, req)
+}
+
+// ReleaseSnapshotLock is part of the tmclient.TabletManagerClient interface.
+func (client *Client) ReleaseSnapshotLock(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (*tabletmanagerdatapb.ReleaseSnapshotLockResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.ReleaseSnapshotLock(ctx, req)
+}
+
 // ExecuteQuery is part of the tmclient.TabletManagerClient interface.
 func (client *Client) ExecuteQuery(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ExecuteQueryRequest) (*querypb.QueryResult, error) {
 	c, closer, err := client.dialer.dial(ctx, tablet)
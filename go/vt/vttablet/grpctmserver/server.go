@@ -199,6 +199,18 @@ func (s *server) UnlockTables(ctx context.Context, req *tabletmanagerdatapb.Unlo
 	return &tabletmanagerdatapb.UnlockTablesResponse{}, nil
 }
 
+func (s *server) AcquireSnapshotLock(ctx context.Context, request *tabletmanagerdatapb.AcquireSnapshotLockRequest) (response *tabletmanagerdatapb.AcquireSnapshotLockResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "AcquireSnapshotLock", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.AcquireSnapshotLock(ctx, request)
+}
+
+func (s *server) ReleaseSnapshotLock(ctx context.Context, request *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (response *tabletmanagerdatapb.ReleaseSnapshotLockResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "ReleaseSnapshotLock", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.ReleaseSnapshotLock(ctx, request)
+}
+
 func (s *server) ExecuteQuery(ctx context.Context, request *tabletmanagerdatapb.ExecuteQueryRequest) (response *tabletmanagerdatapb.ExecuteQueryResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "ExecuteQuery", request, response, false /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
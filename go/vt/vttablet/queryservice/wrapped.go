@@ -188,6 +188,19 @@ func (ws *wrappedService) Execute(ctx context.Context, target *querypb.Target, q
 	return qr, err
 }
 
+// ExecuteBatch implements the QueryService interface
+func (ws *wrappedService) ExecuteBatch(ctx context.Context, target *querypb.Target, queries []*querypb.BoundQuery, transactionID, reservedID int64, options *querypb.ExecuteOptions) (qrs []*sqltypes.Result, err error) {
+	inDedicatedConn := transactionID != 0 || reservedID != 0
+	err = ws.wrapper(ctx, target, ws.impl, "ExecuteBatch", inDedicatedConn, func(ctx context.Context, target *querypb.Target, conn QueryService) (bool, error) {
+		var innerErr error
+		qrs, innerErr = conn.ExecuteBatch(ctx, target, queries, transactionID, reservedID, options)
+		// You cannot retry if you're in a transaction.
+		retryable := canRetry(ctx, innerErr) && (!inDedicatedConn)
+		return retryable, innerErr
+	})
+	return qrs, err
+}
+
 // StreamExecute implements the QueryService interface
 func (ws *wrappedService) StreamExecute(ctx context.Context, target *querypb.Target, query string, bindVars map[string]*querypb.BindVariable, transactionID int64, reservedID int64, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) error {
 	inDedicatedConn := transactionID != 0 || reservedID != 0
@@ -66,6 +66,7 @@ type SandboxConn struct {
 	// These Count vars report how often the corresponding
 	// functions were called.
 	ExecCount                atomic.Int64
+	ExecBatchCount           atomic.Int64
 	BeginCount               atomic.Int64
 	CommitCount              atomic.Int64
 	RollbackCount            atomic.Int64
@@ -110,6 +111,13 @@ type SandboxConn struct {
 	VStreamErrors []error
 	VStreamCh     chan *binlogdatapb.VEvent
 
+	// VStreamRowsPosition is the GTID position of the snapshots VStreamRows
+	// streams the rows from.
+	VStreamRowsPosition string
+
+	// StreamHealthResponse is sent by StreamHealth, if set.
+	StreamHealthResponse *querypb.StreamHealthResponse
+
 	// transaction id generator
 	TransactionID atomic.Int64
 
@@ -240,6 +248,20 @@ func (sbc *SandboxConn) Execute(ctx context.Context, target *querypb.Target, que
 	return sbc.getNextResult(stmt), nil
 }
 
+// ExecuteBatch is part of the QueryService interface.
+func (sbc *SandboxConn) ExecuteBatch(ctx context.Context, target *querypb.Target, queries []*querypb.BoundQuery, transactionID, reservedID int64, options *querypb.ExecuteOptions) ([]*sqltypes.Result, error) {
+	sbc.ExecBatchCount.Add(1)
+	results := make([]*sqltypes.Result, 0, len(queries))
+	for _, query := range queries {
+		qr, err := sbc.Execute(ctx, target, query.Sql, query.BindVariables, transactionID, reservedID, options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, qr)
+	}
+	return results, nil
+}
+
 // StreamExecute is part of the QueryService interface.
 func (sbc *SandboxConn) StreamExecute(ctx context.Context, target *querypb.Target, query string, bindVars map[string]*querypb.BindVariable, transactionID int64, reservedID int64, options *querypb.ExecuteOptions, callback func(*sqltypes.Result) error) error {
 	sbc.panicIfNeeded()
@@ -472,6 +494,9 @@ var SandboxSQRowCount = int64(10)
 
 // StreamHealth always mocks a "healthy" result.
 func (sbc *SandboxConn) StreamHealth(ctx context.Context, callback func(*querypb.StreamHealthResponse) error) error {
+	if sbc.StreamHealthResponse != nil {
+		return callback(sbc.StreamHealthResponse)
+	}
 	return nil
 }
 
@@ -550,9 +575,30 @@ func (sbc *SandboxConn) VStream(ctx context.Context, request *binlogdatapb.VStre
 	return ctx.Err()
 }
 
-// VStreamRows is part of the QueryService interface.
+// VStreamRows is part of the QueryService interface. It streams the rows of
+// the next result, as if they were read from a snapshot at VStreamRowsPosition.
 func (sbc *SandboxConn) VStreamRows(ctx context.Context, request *binlogdatapb.VStreamRowsRequest, send func(*binlogdatapb.VStreamRowsResponse) error) error {
-	return fmt.Errorf("not implemented in test")
+	sbc.panicIfNeeded()
+	sbc.sExecMu.Lock()
+	sbc.appendToQueries(&querypb.BoundQuery{Sql: request.Query})
+	if err := sbc.getError(); err != nil {
+		sbc.sExecMu.Unlock()
+		return err
+	}
+	parse, _ := sbc.parser.Parse(request.Query)
+	result := sbc.getNextResult(parse)
+	sbc.sExecMu.Unlock()
+
+	if err := send(&binlogdatapb.VStreamRowsResponse{
+		Fields: result.Fields,
+		Gtid:   sbc.VStreamRowsPosition,
+	}); err != nil {
+		return err
+	}
+	if len(result.Rows) == 0 {
+		return nil
+	}
+	return send(&binlogdatapb.VStreamRowsResponse{Rows: sqltypes.RowsToProto3(result.Rows)})
 }
 
 // VStreamTables is part of the QueryService interface.
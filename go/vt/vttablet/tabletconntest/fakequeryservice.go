@@ -423,6 +423,34 @@ func (f *FakeQueryService) Execute(ctx context.Context, target *querypb.Target,
 	return &ExecuteQueryResult, nil
 }
 
+// ExecuteBatch is part of the queryservice.QueryService interface
+func (f *FakeQueryService) ExecuteBatch(ctx context.Context, target *querypb.Target, queries []*querypb.BoundQuery, transactionID, reservedID int64, options *querypb.ExecuteOptions) ([]*sqltypes.Result, error) {
+	if f.HasError {
+		return nil, f.TabletError
+	}
+	if f.Panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	results := make([]*sqltypes.Result, 0, len(queries))
+	for _, query := range queries {
+		if query.Sql != ExecuteQuery {
+			f.t.Errorf("invalid ExecuteBatch.Queries.Sql: got %v expected %v", query.Sql, ExecuteQuery)
+		}
+		if !sqltypes.BindVariablesEqual(query.BindVariables, ExecuteBindVars) {
+			f.t.Errorf("invalid ExecuteBatch.Queries.BindVariables: got %v expected %v", query.BindVariables, ExecuteBindVars)
+		}
+		results = append(results, &ExecuteQueryResult)
+	}
+	if !proto.Equal(options, TestExecuteOptions) {
+		f.t.Errorf("invalid ExecuteBatch.ExecuteOptions: got %v expected %v", options, TestExecuteOptions)
+	}
+	f.checkTargetCallerID(ctx, "ExecuteBatch", target)
+	if transactionID != f.ExpectedTransactionID {
+		f.t.Errorf("invalid ExecuteBatch.TransactionId: got %v expected %v", transactionID, f.ExpectedTransactionID)
+	}
+	return results, nil
+}
+
 // StreamExecuteQuery is a fake test query for streaming.
 const StreamExecuteQuery = "streamExecuteQuery"
 
@@ -431,6 +431,51 @@ func testExecutePanics(t *testing.T, conn queryservice.QueryService, f *FakeQuer
 	})
 }
 
+var executeBatchQueries = []*querypb.BoundQuery{{
+	Sql:           ExecuteQuery,
+	BindVariables: ExecuteBindVars,
+}, {
+	Sql:           ExecuteQuery,
+	BindVariables: ExecuteBindVars,
+}}
+
+func testExecuteBatch(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testExecuteBatch")
+	f.ExpectedTransactionID = ExecuteTransactionID
+	ctx := context.Background()
+	ctx = callerid.NewContext(ctx, TestCallerID, TestVTGateCallerID)
+	qrs, err := conn.ExecuteBatch(ctx, TestTarget, executeBatchQueries, ExecuteTransactionID, ReserveConnectionID, TestExecuteOptions)
+	if err != nil {
+		t.Fatalf("ExecuteBatch failed: %v", err)
+	}
+	if len(qrs) != len(executeBatchQueries) {
+		t.Fatalf("Unexpected number of results from ExecuteBatch: got %v wanted %v", len(qrs), len(executeBatchQueries))
+	}
+	for _, qr := range qrs {
+		if !qr.Equal(&ExecuteQueryResult) {
+			t.Errorf("Unexpected result from ExecuteBatch: got %v wanted %v", qr, ExecuteQueryResult)
+		}
+	}
+}
+
+func testExecuteBatchError(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testExecuteBatchError")
+	f.HasError = true
+	testErrorHelper(t, f, "ExecuteBatch", func(ctx context.Context) error {
+		_, err := conn.ExecuteBatch(ctx, TestTarget, executeBatchQueries, ExecuteTransactionID, ReserveConnectionID, TestExecuteOptions)
+		return err
+	})
+	f.HasError = false
+}
+
+func testExecuteBatchPanics(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
+	t.Log("testExecuteBatchPanics")
+	testPanicHelper(t, f, "ExecuteBatch", func(ctx context.Context) error {
+		_, err := conn.ExecuteBatch(ctx, TestTarget, executeBatchQueries, ExecuteTransactionID, ReserveConnectionID, TestExecuteOptions)
+		return err
+	})
+}
+
 func testBeginExecute(t *testing.T, conn queryservice.QueryService, f *FakeQueryService) {
 	t.Log("testBeginExecute")
 	f.ExpectedTransactionID = beginTransactionID
@@ -937,6 +982,7 @@ func TestSuite(t *testing.T, protocol string, tablet *topodatapb.Tablet, fake *F
 		testConcludeTransaction,
 		testReadTransaction,
 		testExecute,
+		testExecuteBatch,
 		testBeginExecute,
 		testStreamExecute,
 		testBeginStreamExecute,
@@ -957,6 +1003,7 @@ func TestSuite(t *testing.T, protocol string, tablet *topodatapb.Tablet, fake *F
 		testConcludeTransactionError,
 		testReadTransactionError,
 		testExecuteError,
+		testExecuteBatchError,
 		testBeginExecuteErrorInBegin,
 		testBeginExecuteErrorInExecute,
 		testStreamExecuteError,
@@ -980,6 +1027,7 @@ func TestSuite(t *testing.T, protocol string, tablet *topodatapb.Tablet, fake *F
 		testConcludeTransactionPanics,
 		testReadTransactionPanics,
 		testExecutePanics,
+		testExecuteBatchPanics,
 		testBeginExecutePanics,
 		testStreamExecutePanics,
 		testBeginStreamExecutePanics,
@@ -0,0 +1,340 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/pools/smartconnpool"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/callinfo"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// dbaAuditURL is the endpoint serving the audit log of the DBA statements.
+const dbaAuditURL = "/debug/dba_audit"
+
+var (
+	dbaFetchPoolSize    = 10
+	dbaFetchPoolTimeout time.Duration
+	dbaAuditLogSize     = 1000
+)
+
+func registerDBAFetchFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&dbaFetchPoolSize, "dba_fetch_pool_size", dbaFetchPoolSize, "Size of the connection pool of ExecuteFetchAsDba, which bounds how many DBA statements run concurrently")
+	fs.DurationVar(&dbaFetchPoolTimeout, "dba_fetch_pool_timeout", dbaFetchPoolTimeout, "How long ExecuteFetchAsDba waits for a connection of its pool before failing. 0 waits as long as the request allows")
+	fs.IntVar(&dbaAuditLogSize, "dba_audit_log_size", dbaAuditLogSize, "Number of the most recent ExecuteFetchAsDba statements kept in the audit log served on "+dbaAuditURL)
+}
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerDBAFetchFlags)
+	servenv.OnParseFor("vttablet", registerDBAFetchFlags)
+}
+
+// dbaFetch returns the pool and the audit log of ExecuteFetchAsDba, creating
+// them on first use.
+func (tm *TabletManager) dbaFetch() (*smartconnpool.ConnPool[*dbconnpool.DBConnection], *dbaAuditLog) {
+	tm.dbaFetchOnce.Do(func() {
+		tm.dbaFetchPool = smartconnpool.NewPool(&smartconnpool.Config[*dbconnpool.DBConnection]{
+			Capacity:    int64(dbaFetchPoolSize),
+			IdleTimeout: mysqlctl.DbaIdleTimeout,
+		})
+		tm.dbaFetchPool.Open(func(ctx context.Context) (*dbconnpool.DBConnection, error) {
+			return tm.MysqlDaemon.GetDbaConnection(ctx)
+		}, nil)
+		tm.dbaAudit = newDBAAuditLog(dbaAuditLogSize, tm.Env.Parser())
+	})
+	return tm.dbaFetchPool, tm.dbaAudit
+}
+
+// exportDBAFetch publishes the stats of the pool of ExecuteFetchAsDba and the
+// audit log endpoint, in the namespace of the query service, so that each
+// tablet of a vtcombo has its own.
+func (tm *TabletManager) exportDBAFetch() {
+	env, ok := tm.QueryServiceControl.(tabletenv.Env)
+	if !ok {
+		// The query service is a mock.
+		return
+	}
+	pool, audit := tm.dbaFetch()
+	pool.RegisterStats(env.Exporter(), "DbaFetchConnPool")
+	env.Exporter().HandleFunc(dbaAuditURL, audit.ServeHTTP)
+}
+
+// closeDBAFetch closes the pool of ExecuteFetchAsDba, if it was created.
+func (tm *TabletManager) closeDBAFetch() {
+	if tm.dbaFetchPool != nil {
+		tm.dbaFetchPool.Close()
+	}
+}
+
+// getDBAFetchConn returns a connection of the pool of ExecuteFetchAsDba.
+func (tm *TabletManager) getDBAFetchConn(ctx context.Context) (*smartconnpool.Pooled[*dbconnpool.DBConnection], error) {
+	pool, _ := tm.dbaFetch()
+	if dbaFetchPoolTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dbaFetchPoolTimeout)
+		defer cancel()
+	}
+	conn, err := pool.Get(ctx, nil)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to get a connection for ExecuteFetchAsDba")
+	}
+	return conn, nil
+}
+
+// recycleDBAFetchConn returns a connection to the pool of ExecuteFetchAsDba.
+// Unless reuse is set, the connection is closed and the pool replaces it with
+// a new one, since the statements may have left state in its session.
+func recycleDBAFetchConn(conn *smartconnpool.Pooled[*dbconnpool.DBConnection], reuse bool) {
+	if !reuse {
+		conn.Close()
+	}
+	conn.Recycle()
+}
+
+// keepsDBAFetchSession returns whether the statements leave the session of the
+// connection as they found it, apart from the current database and the settings
+// of the request, so that the connection can be reused by the next request.
+// Statements like SET, LOCK TABLES or BEGIN, temporary tables, advisory locks,
+// user variable assignments, and the statements that can't be parsed, leave or
+// may leave state in the session.
+func keepsDBAFetchSession(queries []string, parser *sqlparser.Parser) bool {
+	for _, query := range queries {
+		stmt, err := parser.Parse(query)
+		if err != nil {
+			return false
+		}
+		if leavesSessionState(stmt) {
+			return false
+		}
+		switch stmt := stmt.(type) {
+		case sqlparser.DDLStatement:
+			if stmt.IsTemporary() {
+				return false
+			}
+		case *sqlparser.Select:
+			if stmt.Into != nil {
+				return false
+			}
+		case sqlparser.DBDDLStatement, *sqlparser.Insert, *sqlparser.Update, *sqlparser.Delete, *sqlparser.Union, *sqlparser.Show:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// leavesSessionState returns whether the statement takes an advisory lock or
+// assigns a user variable, both of which outlive the statement in the session.
+func leavesSessionState(stmt sqlparser.Statement) bool {
+	leaves := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.LockingFunc, *sqlparser.AssignmentExpr:
+			leaves = true
+			return false, nil
+		}
+		return true, nil
+	}, stmt)
+	return leaves
+}
+
+// resetDBAFetchSession restores the settings of the session that the request
+// changed, and returns whether the connection can be reused.
+func resetDBAFetchSession(conn *dbconnpool.DBConnection, disableBinlogs, disableForeignKeyChecks bool) bool {
+	if conn.IsClosed() {
+		return false
+	}
+	if disableForeignKeyChecks {
+		if _, err := conn.ExecuteFetch("SET SESSION foreign_key_checks = ON", 0, false); err != nil {
+			return false
+		}
+	}
+	if disableBinlogs {
+		if _, err := conn.ExecuteFetch("SET sql_log_bin = ON", 0, false); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// dbaAuditRecord is the audit record of a statement run by ExecuteFetchAsDba.
+type dbaAuditRecord struct {
+	Time time.Time
+	// User is the user authenticated by the gRPC server, if any.
+	User string
+	// CallerID is the principal of the effective caller ID of the request.
+	CallerID   string
+	RemoteAddr string
+	DbName     string
+	Query      string
+	// RowsAffected and RowsReturned are zero if the statement failed.
+	RowsAffected uint64
+	RowsReturned int
+	// Duration is the duration of the whole request, which may have more than
+	// one statement.
+	Duration time.Duration
+	Error    string `json:",omitempty"`
+}
+
+// dbaAuditLog keeps the audit records of the most recent DBA statements.
+type dbaAuditLog struct {
+	size   int
+	parser *sqlparser.Parser
+
+	mu      sync.Mutex
+	records []*dbaAuditRecord
+}
+
+func newDBAAuditLog(size int, parser *sqlparser.Parser) *dbaAuditLog {
+	return &dbaAuditLog{size: size, parser: parser}
+}
+
+// credentialsRegexp matches the statements which may hold a password, like
+// CREATE USER ... IDENTIFIED BY or CHANGE REPLICATION SOURCE TO ... PASSWORD.
+var credentialsRegexp = regexp.MustCompile(`(?i)identified|password`)
+
+// redact returns the query as it is recorded in the audit log. The statements
+// which may hold a password are recorded with their values redacted, or only
+// with their first keywords if they can't be parsed.
+func (l *dbaAuditLog) redact(query string) string {
+	if !credentialsRegexp.MatchString(query) {
+		return query
+	}
+	if redacted, err := l.parser.RedactSQLQuery(query); err == nil {
+		return redacted
+	}
+	keywords := strings.Fields(query)
+	if len(keywords) > 2 {
+		keywords = keywords[:2]
+	}
+	return strings.Join(append(keywords, "[REDACTED]"), " ")
+}
+
+// record adds the audit records of a request. The queries are the statements
+// of the request, and the results those of the statements which succeeded, in
+// order: the statement following them is the one which failed with err. If
+// the request failed before its statements were split, the sql is recorded.
+func (l *dbaAuditLog) record(ctx context.Context, dbName, sql string, queries []string, results []*querypb.QueryResult, err error, start time.Time) {
+	template := dbaAuditRecord{
+		Time:     start,
+		User:     servenv.StaticAuthUsernameFromContext(ctx),
+		DbName:   dbName,
+		Duration: time.Since(start),
+	}
+	if ci, ok := callinfo.FromContext(ctx); ok {
+		template.RemoteAddr = ci.RemoteAddr()
+	}
+	if ef := callerid.EffectiveCallerIDFromContext(ctx); ef != nil {
+		template.CallerID = ef.Principal
+	}
+	if len(queries) == 0 {
+		queries = []string{sql}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, query := range queries {
+		record := template
+		record.Query = l.redact(query)
+		if i < len(results) {
+			record.RowsAffected = results[i].RowsAffected
+			record.RowsReturned = len(results[i].Rows)
+		} else if err != nil {
+			// The following statements did not run.
+			record.Error = err.Error()
+			l.add(&record)
+			return
+		}
+		l.add(&record)
+	}
+}
+
+func (l *dbaAuditLog) add(record *dbaAuditRecord) {
+	l.records = append(l.records, record)
+	if over := len(l.records) - l.size; over > 0 {
+		l.records = l.records[over:]
+	}
+}
+
+// get returns the most recent audit records, oldest first. If user is not
+// empty, only the records of the requests of that user or caller ID are
+// returned. If limit is positive, at most that many records are returned.
+func (l *dbaAuditLog) get(user string, limit int) []*dbaAuditRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]*dbaAuditRecord, 0, len(l.records))
+	for _, record := range l.records {
+		if user != "" && record.User != user && record.CallerID != user {
+			continue
+		}
+		records = append(records, record)
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+	return records
+}
+
+// ServeHTTP serves the audit records as JSON. The user and limit parameters
+// filter them, as in get.
+func (l *dbaAuditLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var limit int
+	if s := r.FormValue("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil {
+			http.Error(w, fmt.Sprintf("invalid limit %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, err := json.MarshalIndent(l.get(r.FormValue("user"), limit), "", "  ")
+	if err != nil {
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Write(b)
+}
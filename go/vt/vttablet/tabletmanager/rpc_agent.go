@@ -69,6 +69,10 @@ type RPCTM interface {
 
 	UnlockTables(ctx context.Context) error
 
+	AcquireSnapshotLock(ctx context.Context, req *tabletmanagerdatapb.AcquireSnapshotLockRequest) (*tabletmanagerdatapb.AcquireSnapshotLockResponse, error)
+
+	ReleaseSnapshotLock(ctx context.Context, req *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (*tabletmanagerdatapb.ReleaseSnapshotLockResponse, error)
+
 	ExecuteQuery(ctx context.Context, req *tabletmanagerdatapb.ExecuteQueryRequest) (*querypb.QueryResult, error)
 
 	ExecuteFetchAsDba(ctx context.Context, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error)
@@ -18,6 +18,7 @@ package tabletmanager
 
 import (
 	"context"
+	"time"
 
 	"vitess.io/vitess/go/constants/sidecar"
 	"vitess.io/vitess/go/sqlescape"
@@ -78,16 +79,45 @@ func (tm *TabletManager) executeMultiFetchAsDba(
 	disableBinlogs bool,
 	disableForeignKeyChecks bool,
 	validateQueries func(queries []string, countCreate int) error,
-) ([]*querypb.QueryResult, error) {
+) (results []*querypb.QueryResult, err error) {
+	// Audit every statement, including those of the requests which fail.
+	start := time.Now()
+	var queries []string
+	defer func() {
+		_, audit := tm.dbaFetch()
+		audit.record(ctx, dbName, sql, queries, results, err, start)
+	}()
+
 	if err := tm.waitForGrantsToHaveApplied(ctx); err != nil {
 		return nil, err
 	}
-	// Get a connection.
-	conn, err := tm.MysqlDaemon.GetDbaConnection(ctx)
+	// Get a connection of the dedicated pool.
+	pooled, err := tm.getDBAFetchConn(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
+	// The connection is only reused if the request ends with a clean session.
+	reuse := false
+	defer func() {
+		recycleDBAFetchConn(pooled, reuse)
+	}()
+	conn := pooled.Conn
+
+	// A previous request may have selected a database in the session. If the
+	// request doesn't select its own, its statements run on a new connection,
+	// without a current database, as they would have before reusing them.
+	selected := false
+	if dbName != "" {
+		// This execute might fail if db does not exist.
+		// Error is ignored because given query might create this database.
+		_, useErr := conn.ExecuteFetch("USE "+sqlescape.EscapeID(dbName), 1, false)
+		selected = useErr == nil
+	}
+	if !selected {
+		if err := conn.Reconnect(ctx); err != nil {
+			return nil, err
+		}
+	}
 
 	// Disable binlogs if necessary.
 	if disableBinlogs {
@@ -105,13 +135,9 @@ func (tm *TabletManager) executeMultiFetchAsDba(
 		}
 	}
 
-	if dbName != "" {
-		// This execute might fail if db does not exist.
-		// Error is ignored because given query might create this database.
-		_, _ = conn.ExecuteFetch("USE "+sqlescape.EscapeID(dbName), 1, false)
-	}
-
-	queries, _, countCreate, allowZeroInDate, err := analyzeExecuteFetchAsDbaMultiQuery(sql, tm.Env.Parser())
+	var countCreate int
+	var allowZeroInDate bool
+	queries, _, countCreate, allowZeroInDate, err = analyzeExecuteFetchAsDbaMultiQuery(sql, tm.Env.Parser())
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +158,7 @@ func (tm *TabletManager) executeMultiFetchAsDba(
 	// TODO(shlomi): we use ExecuteFetchMulti for backwards compatibility. In v20 we will not accept
 	// multi statement queries in ExecuteFetchAsDBA. This will be rewritten as:
 	//  (in v20): result, err := ExecuteFetch(uq, int(req.MaxRows), true /*wantFields*/)
-	results := make([]*querypb.QueryResult, 0, len(queries))
+	results = make([]*querypb.QueryResult, 0, len(queries))
 	result, more, err := conn.ExecuteFetchMulti(uq, maxRows, true /*wantFields*/)
 	if err == nil {
 		results = append(results, sqltypes.ResultToProto3(result))
@@ -140,29 +166,16 @@ func (tm *TabletManager) executeMultiFetchAsDba(
 	for more {
 		result, more, _, err = conn.ReadQueryResult(maxRows, true /*wantFields*/)
 		if err != nil {
-			return nil, err
+			// Keep the results of the previous statements for the audit.
+			break
 		}
 		results = append(results, sqltypes.ResultToProto3(result))
 	}
 
-	// Re-enable FK checks if necessary.
-	if disableForeignKeyChecks && !conn.IsClosed() {
-		_, err := conn.ExecuteFetch("SET SESSION foreign_key_checks = ON", 0, false)
-		if err != nil {
-			// If we can't reset the FK checks flag,
-			// let's just close the connection.
-			conn.Close()
-		}
-	}
-
-	// Re-enable binlogs if necessary.
-	if disableBinlogs && !conn.IsClosed() {
-		_, err := conn.ExecuteFetch("SET sql_log_bin = ON", 0, false)
-		if err != nil {
-			// if we can't reset the sql_log_bin flag,
-			// let's just close the connection.
-			conn.Close()
-		}
+	// Re-enable FK checks and binlogs, so that the connection can be reused.
+	// The sql_mode of allowZeroInDate isn't restored, so its connection is not.
+	if !allowZeroInDate && keepsDBAFetchSession(queries, tm.Env.Parser()) {
+		reuse = resetDBAFetchSession(conn, disableBinlogs, disableForeignKeyChecks)
 	}
 
 	if err == nil && reloadSchema {
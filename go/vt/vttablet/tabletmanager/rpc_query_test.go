@@ -18,8 +18,13 @@ package tabletmanager
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,12 +32,14 @@ import (
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletservermock"
 
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 )
 
@@ -146,3 +153,172 @@ func TestTabletManager_ExecuteFetchAsDba(t *testing.T) {
 		require.Contains(t, got, w)
 	}
 }
+
+func TestTabletManager_ExecuteFetchAsDbaAudit(t *testing.T) {
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("dba-user", "", ""), nil)
+	cp := mysql.ConnParams{}
+	db := fakesqldb.New(t)
+	db.AddQuery("insert into t values (1)", &sqltypes.Result{RowsAffected: 1})
+	db.AddQuery("select * from t", sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2"))
+	db.AddRejectedQuery("drop table x", errors.New("unknown table"))
+	daemon := mysqlctl.NewFakeMysqlDaemon(db)
+
+	tm := &TabletManager{
+		MysqlDaemon:            daemon,
+		DBConfigs:              dbconfigs.NewTestDBConfigs(cp, cp, "db"),
+		QueryServiceControl:    tabletservermock.NewController(),
+		_waitForGrantsComplete: make(chan struct{}),
+		Env:                    vtenv.NewTestEnv(),
+	}
+	close(tm._waitForGrantsComplete)
+	defer tm.closeDBAFetch()
+
+	_, err := tm.ExecuteMultiFetchAsDba(ctx, &tabletmanagerdatapb.ExecuteMultiFetchAsDbaRequest{
+		Sql:     []byte("insert into t values (1);select * from t;drop table x;select 2"),
+		MaxRows: 10,
+	})
+	require.ErrorContains(t, err, "unknown table")
+
+	_, err = tm.ExecuteFetchAsDba(ctx, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte(""),
+		MaxRows: 10,
+	})
+	require.Error(t, err)
+
+	_, audit := tm.dbaFetch()
+	records := audit.get("", 0)
+	require.Len(t, records, 4)
+	for _, record := range records {
+		assert.Equal(t, "dba-user", record.CallerID)
+	}
+	assert.Equal(t, "insert into t values (1)", records[0].Query)
+	assert.EqualValues(t, 1, records[0].RowsAffected)
+	assert.Empty(t, records[0].Error)
+	assert.Equal(t, "select * from t", records[1].Query)
+	assert.Equal(t, 2, records[1].RowsReturned)
+	assert.Equal(t, "drop table x", records[2].Query)
+	assert.Contains(t, records[2].Error, "unknown table")
+	// The empty request is recorded as is.
+	assert.Equal(t, "", records[3].Query)
+	assert.Contains(t, records[3].Error, "no statements found")
+
+	assert.Len(t, audit.get("dba-user", 2), 2)
+	assert.Empty(t, audit.get("other-user", 0))
+
+	pool, _ := tm.dbaFetch()
+	assert.EqualValues(t, 0, pool.InUse())
+}
+
+func TestTabletManager_ExecuteFetchAsDbaReuse(t *testing.T) {
+	ctx := context.Background()
+	cp := mysql.ConnParams{}
+	db := fakesqldb.New(t)
+	db.AddQuery("USE `db`", &sqltypes.Result{})
+	db.AddQuery("select * from t", &sqltypes.Result{})
+	db.AddQuery("set @a = 1", &sqltypes.Result{})
+	db.AddQuery("select get_lock('l', 1) from dual", &sqltypes.Result{})
+	db.AddQuery("select @a := 1 from dual", &sqltypes.Result{})
+	db.AddQuery("SET sql_log_bin = OFF", &sqltypes.Result{})
+	db.AddQuery("SET sql_log_bin = ON", &sqltypes.Result{})
+	daemon := mysqlctl.NewFakeMysqlDaemon(db)
+
+	tm := &TabletManager{
+		MysqlDaemon:            daemon,
+		DBConfigs:              dbconfigs.NewTestDBConfigs(cp, cp, "db"),
+		QueryServiceControl:    tabletservermock.NewController(),
+		_waitForGrantsComplete: make(chan struct{}),
+		Env:                    vtenv.NewTestEnv(),
+	}
+	close(tm._waitForGrantsComplete)
+	defer tm.closeDBAFetch()
+
+	pool, _ := tm.dbaFetch()
+	connID := func() int64 {
+		conn, err := pool.Get(ctx, nil)
+		require.NoError(t, err)
+		defer conn.Recycle()
+		return conn.Conn.ID()
+	}
+	execute := func(dbName, query string) {
+		_, err := tm.ExecuteFetchAsDba(ctx, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:          []byte(query),
+			DbName:         dbName,
+			MaxRows:        10,
+			DisableBinlogs: true,
+		})
+		require.NoError(t, err)
+	}
+
+	// The connections are reused once their session is reset.
+	execute("db", "select * from t")
+	id := connID()
+	execute("db", "select * from t")
+	assert.Equal(t, id, connID())
+	assert.Equal(t, 2, db.GetQueryCalledNum("SET sql_log_bin = ON"))
+
+	// The statements which leave state in the session don't reuse them.
+	execute("db", "set @a = 1")
+	assert.NotEqual(t, id, connID())
+	// Neither do the reads which take an advisory lock or assign a variable.
+	for _, query := range []string{"select get_lock('l', 1) from dual", "select @a := 1 from dual"} {
+		id = connID()
+		execute("db", query)
+		assert.NotEqual(t, id, connID(), query)
+	}
+
+	// Without a database, the statements run on a new connection.
+	id = connID()
+	execute("", "select * from t")
+	assert.NotEqual(t, id, connID())
+}
+
+func TestDBAAuditLog(t *testing.T) {
+	audit := newDBAAuditLog(2, sqlparser.NewTestParser())
+	ctx := context.Background()
+	start := time.Now()
+	audit.record(ctx, "db", "select 1", []string{"select 1"}, []*querypb.QueryResult{{}}, nil, start)
+	audit.record(ctx, "db", "select 2", []string{"select 2"}, []*querypb.QueryResult{{}}, nil, start)
+	audit.record(ctx, "db", "select 3", []string{"select 3"}, []*querypb.QueryResult{{}}, nil, start)
+
+	records := audit.get("", 0)
+	require.Len(t, records, 2)
+	assert.Equal(t, "select 2", records[0].Query)
+	assert.Equal(t, "select 3", records[1].Query)
+
+	t.Run("redaction", func(t *testing.T) {
+		audit := newDBAAuditLog(10, sqlparser.NewTestParser())
+		for _, query := range []string{
+			"create user 'u'@'%' identified by 'secret'",
+			"alter user u identified with mysql_native_password by 'secret'",
+			"change replication source to source_password = 'secret'",
+			"update mysql.user set authentication_string = 'secret' where user = 'password'",
+		} {
+			audit.record(ctx, "db", query, []string{query}, []*querypb.QueryResult{{}}, nil, start)
+		}
+		records := audit.get("", 0)
+		require.Len(t, records, 4)
+		assert.Equal(t, "create user [REDACTED]", records[0].Query)
+		assert.Equal(t, "alter user [REDACTED]", records[1].Query)
+		assert.Equal(t, "change replication [REDACTED]", records[2].Query)
+		// The statements which parse are normalized.
+		assert.Contains(t, records[3].Query, "authentication_string = :authentication_string")
+		for _, record := range records {
+			assert.NotContains(t, record.Query, "secret")
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		audit.ServeHTTP(w, httptest.NewRequest(http.MethodGet, dbaAuditURL+"?limit=1", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var got []*dbaAuditRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		require.Len(t, got, 1)
+		assert.Equal(t, "select 3", got[0].Query)
+		assert.Equal(t, "db", got[0].DbName)
+
+		w = httptest.NewRecorder()
+		audit.ServeHTTP(w, httptest.NewRequest(http.MethodGet, dbaAuditURL+"?limit=x", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -30,6 +30,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 
@@ -665,6 +666,34 @@ func (tm *TabletManager) SetReplicationSource(ctx context.Context, parentAlias *
 	return tm.setReplicationSourceLocked(ctx, parentAlias, timeCreatedNS, waitPosition, forceStartReplication, semiSyncAction)
 }
 
+// refreshReplicationCredentials configures the replication source again once
+// the db credentials were rotated, so that the replication connects to the
+// source with the new repl credentials.
+func (tm *TabletManager) refreshReplicationCredentials() {
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+	if err := tm.lock(ctx); err != nil {
+		log.Errorf("Failed to configure the replication source with the rotated credentials: %v", err)
+		return
+	}
+	defer tm.unlock()
+
+	status, err := tm.MysqlDaemon.ReplicationStatus()
+	if err == mysql.ErrNotReplica || (err == nil && status.SourceHost == "") {
+		// Replication is not configured, e.g. on a primary.
+		return
+	}
+	if err == nil {
+		wasReplicating := status.IOHealthy() || status.SQLHealthy()
+		err = tm.MysqlDaemon.SetReplicationSource(ctx, status.SourceHost, status.SourcePort, wasReplicating, wasReplicating)
+	}
+	if err != nil {
+		log.Errorf("Failed to configure the replication source with the rotated credentials: %v", err)
+		return
+	}
+	log.Infof("Configured the replication source %s:%d with the rotated credentials", status.SourceHost, status.SourcePort)
+}
+
 func (tm *TabletManager) setReplicationSourceSemiSyncNoAction(ctx context.Context, parentAlias *topodatapb.TabletAlias, timeCreatedNS int64, waitPosition string, forceStartReplication bool) error {
 	log.Infof("SetReplicationSource: parent: %v  position: %v force: %v", parentAlias, waitPosition, forceStartReplication)
 	if err := tm.lock(ctx); err != nil {
@@ -22,6 +22,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+
+	"vitess.io/vitess/go/vt/mysqlctl"
 )
 
 // TestWaitForGrantsToHaveApplied tests that waitForGrantsToHaveApplied only succeeds after waitForDBAGrants has been called.
@@ -42,3 +45,25 @@ func TestWaitForGrantsToHaveApplied(t *testing.T) {
 	err = tm.waitForGrantsToHaveApplied(secondContext)
 	require.NoError(t, err)
 }
+
+// TestRefreshReplicationCredentials tests that the replication source is configured again, restarting the replication if it was running.
+func TestRefreshReplicationCredentials(t *testing.T) {
+	fmd := mysqlctl.NewFakeMysqlDaemon(nil)
+	tm := &TabletManager{
+		MysqlDaemon: fmd,
+		actionSema:  semaphore.NewWeighted(1),
+	}
+
+	// Nothing to do when the replication is not configured.
+	tm.refreshReplicationCredentials()
+	require.Zero(t, fmd.ExpectedExecuteSuperQueryCurrent)
+
+	fmd.CurrentSourceHost = "primary"
+	fmd.CurrentSourcePort = 3306
+	fmd.Replicating = true
+	fmd.SetReplicationSourceInputs = []string{"primary:3306"}
+	fmd.ExpectedExecuteSuperQueryList = []string{"STOP SLAVE", "FAKE SET MASTER", "START SLAVE"}
+	tm.refreshReplicationCredentials()
+	require.NoError(t, fmd.CheckSuperQueryList())
+	require.True(t, fmd.Replicating)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/servenv"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+const (
+	// globalReadLockStatement blocks all writes, and is supported by all flavors.
+	globalReadLockStatement = "FLUSH TABLES WITH READ LOCK"
+	// backupLockStatement only blocks the operations that would make a snapshot of
+	// the InnoDB data files inconsistent (DDL, non-InnoDB writes), and lets DML through.
+	// It is supported from MySQL 8.0 on.
+	backupLockStatement = "LOCK INSTANCE FOR BACKUP"
+)
+
+var snapshotLockTimeout = 5 * time.Minute
+
+func registerSnapshotLockFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&snapshotLockTimeout, "snapshot_lock_timeout", snapshotLockTimeout, "How long to hold the lock acquired by AcquireSnapshotLock before releasing it automatically, if the request does not specify a timeout")
+}
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerSnapshotLockFlags)
+	servenv.OnParseFor("vttablet", registerSnapshotLockFlags)
+}
+
+// AcquireSnapshotLock locks the instance so that an external tool can take a consistent
+// snapshot of its data files. The lock is released by ReleaseSnapshotLock, or automatically
+// once the timeout elapses, so that a crashed tool cannot leave the tablet locked.
+func (tm *TabletManager) AcquireSnapshotLock(ctx context.Context, req *tabletmanagerdatapb.AcquireSnapshotLockRequest) (*tabletmanagerdatapb.AcquireSnapshotLockResponse, error) {
+	timeout := snapshotLockTimeout
+	if d, ok, err := protoutil.DurationFromProto(req.Timeout); err != nil {
+		return nil, err
+	} else if ok {
+		timeout = d
+	}
+	if timeout <= 0 {
+		return nil, errors.New("snapshot lock timeout must be positive")
+	}
+
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if tm._snapshotLockConnection != nil {
+		return nil, errors.New("snapshot lock already held on this tablet")
+	}
+
+	lockStatement, err := tm.snapshotLockStatement(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := tm.MysqlDaemon.GetDbaConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Closing the connection releases the lock, so the connection must be closed
+	// if we return for any reason before storing it in the TabletManager object.
+	defer func() {
+		if tm._snapshotLockConnection != conn {
+			conn.Close()
+		}
+	}()
+
+	if _, err := conn.ExecuteFetch(lockStatement, 0, false); err != nil {
+		return nil, err
+	}
+	// The position is only consistent with the snapshot if the lock blocks
+	// the writes, which LOCK INSTANCE FOR BACKUP doesn't.
+	var position string
+	if lockStatement == globalReadLockStatement {
+		pos, err := tm.MysqlDaemon.PrimaryPosition()
+		if err != nil {
+			return nil, err
+		}
+		position = replication.EncodePosition(pos)
+	}
+	log.Infof("[%v] Snapshot lock acquired with %s at position %q", conn.ConnectionID, lockStatement, position)
+
+	tm._snapshotLockConnection = conn
+	tm._snapshotLockStatement = lockStatement
+	tm._snapshotLockTimer = time.AfterFunc(timeout, func() {
+		tm.mutex.Lock()
+		defer tm.mutex.Unlock()
+
+		// The lock may have been released, and a new one acquired, in the meantime.
+		if tm._snapshotLockConnection == conn {
+			log.Errorf("snapshot lock timed out after %v and was released - the snapshot may be inconsistent", timeout)
+			if err := tm.releaseSnapshotLockHoldingMutex(); err != nil {
+				log.Errorf("failed to release snapshot lock: %v", err)
+			}
+		}
+	})
+
+	return &tabletmanagerdatapb.AcquireSnapshotLockResponse{
+		LockStatement: lockStatement,
+		Position:      position,
+		ExpireTime:    protoutil.TimeToProto(time.Now().Add(timeout)),
+	}, nil
+}
+
+// snapshotLockStatement returns the lightest statement the flavor of the instance
+// supports to get a consistent snapshot point.
+func (tm *TabletManager) snapshotLockStatement(ctx context.Context) (string, error) {
+	version, err := tm.MysqlDaemon.GetVersionString(ctx)
+	if err != nil {
+		return "", err
+	}
+	flavor, serverVersion, err := mysqlctl.ParseVersionString(version)
+	if err != nil {
+		return "", err
+	}
+	if flavor != mysqlctl.FlavorMariaDB && serverVersion.Major >= 8 {
+		return backupLockStatement, nil
+	}
+	return globalReadLockStatement, nil
+}
+
+// ReleaseSnapshotLock releases the lock acquired by AcquireSnapshotLock.
+func (tm *TabletManager) ReleaseSnapshotLock(ctx context.Context, req *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (*tabletmanagerdatapb.ReleaseSnapshotLockResponse, error) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if tm._snapshotLockConnection == nil {
+		return nil, errors.New("snapshot lock is not held on this tablet")
+	}
+	if err := tm.releaseSnapshotLockHoldingMutex(); err != nil {
+		return nil, err
+	}
+	return &tabletmanagerdatapb.ReleaseSnapshotLockResponse{}, nil
+}
+
+func (tm *TabletManager) releaseSnapshotLockHoldingMutex() error {
+	tm._snapshotLockTimer.Stop()
+	conn := tm._snapshotLockConnection
+	unlockStatement := "UNLOCK TABLES"
+	if tm._snapshotLockStatement == backupLockStatement {
+		unlockStatement = "UNLOCK INSTANCE"
+	}
+	// Closing the connection releases the lock even if the unlock statement fails.
+	defer conn.Close()
+	tm._snapshotLockConnection = nil
+	tm._snapshotLockTimer = nil
+	tm._snapshotLockStatement = ""
+	if _, err := conn.ExecuteFetch(unlockStatement, 0, false); err != nil {
+		return err
+	}
+	log.Infof("[%v] Snapshot lock released", conn.ConnectionID)
+	return nil
+}
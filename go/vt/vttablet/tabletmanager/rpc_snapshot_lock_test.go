@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/mysqlctl"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func TestSnapshotLock(t *testing.T) {
+	tcases := []struct {
+		version  string
+		lock     string
+		unlock   string
+		position string
+	}{
+		{
+			// The backup lock lets the writes through, so the position isn't
+			// consistent with the snapshot.
+			version: "Ver 8.0.32 MySQL Community Server - GPL",
+			lock:    "LOCK INSTANCE FOR BACKUP",
+			unlock:  "UNLOCK INSTANCE",
+		},
+		{
+			version:  "Ver 5.7.40-log MySQL Community Server (GPL)",
+			lock:     "FLUSH TABLES WITH READ LOCK",
+			unlock:   "UNLOCK TABLES",
+			position: "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+		},
+		{
+			version:  "Ver 10.6.12-MariaDB MariaDB Server",
+			lock:     "FLUSH TABLES WITH READ LOCK",
+			unlock:   "UNLOCK TABLES",
+			position: "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+		},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.version, func(t *testing.T) {
+			ctx := context.Background()
+			db := fakesqldb.New(t)
+			defer db.Close()
+			db.AddQueryPattern(".*", &sqltypes.Result{})
+			daemon := mysqlctl.NewFakeMysqlDaemon(db)
+			daemon.Version = tcase.version
+			pos, err := replication.DecodePosition("MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5")
+			require.NoError(t, err)
+			daemon.CurrentPrimaryPosition = pos
+			tm := &TabletManager{MysqlDaemon: daemon}
+
+			resp, err := tm.AcquireSnapshotLock(ctx, &tabletmanagerdatapb.AcquireSnapshotLockRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tcase.lock, resp.LockStatement)
+			assert.Equal(t, tcase.position, resp.Position)
+			assert.WithinDuration(t, time.Now().Add(snapshotLockTimeout), protoutil.TimeFromProto(resp.ExpireTime), time.Minute)
+
+			_, err = tm.AcquireSnapshotLock(ctx, &tabletmanagerdatapb.AcquireSnapshotLockRequest{})
+			require.ErrorContains(t, err, "snapshot lock already held")
+
+			_, err = tm.ReleaseSnapshotLock(ctx, &tabletmanagerdatapb.ReleaseSnapshotLockRequest{})
+			require.NoError(t, err)
+			_, err = tm.ReleaseSnapshotLock(ctx, &tabletmanagerdatapb.ReleaseSnapshotLockRequest{})
+			require.ErrorContains(t, err, "snapshot lock is not held")
+
+			queries := strings.Split(db.QueryLog(), ";")
+			assert.Contains(t, queries, strings.ToLower(tcase.lock))
+			assert.Contains(t, queries, strings.ToLower(tcase.unlock))
+		})
+	}
+}
+
+func TestSnapshotLockTimeout(t *testing.T) {
+	ctx := context.Background()
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	daemon := mysqlctl.NewFakeMysqlDaemon(db)
+	daemon.Version = "Ver 8.0.32 MySQL Community Server - GPL"
+	tm := &TabletManager{MysqlDaemon: daemon}
+
+	_, err := tm.AcquireSnapshotLock(ctx, &tabletmanagerdatapb.AcquireSnapshotLockRequest{
+		Timeout: protoutil.DurationToProto(-time.Second),
+	})
+	require.ErrorContains(t, err, "snapshot lock timeout must be positive")
+
+	_, err = tm.AcquireSnapshotLock(ctx, &tabletmanagerdatapb.AcquireSnapshotLockRequest{
+		Timeout: protoutil.DurationToProto(10 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		tm.mutex.Lock()
+		defer tm.mutex.Unlock()
+		return tm._snapshotLockConnection == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Once released by the timeout, the lock can be acquired again.
+	_, err = tm.AcquireSnapshotLock(ctx, &tabletmanagerdatapb.AcquireSnapshotLockRequest{})
+	require.NoError(t, err)
+	_, err = tm.ReleaseSnapshotLock(ctx, &tabletmanagerdatapb.ReleaseSnapshotLockRequest{})
+	require.NoError(t, err)
+}
@@ -51,6 +51,7 @@ import (
 	"vitess.io/vitess/go/flagutil"
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/netutil"
+	"vitess.io/vitess/go/pools/smartconnpool"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/stats"
@@ -167,6 +168,16 @@ type TabletManager struct {
 	// when we transition back from something like PRIMARY.
 	baseTabletType topodatapb.TabletType
 
+	// dbaFetchOnce creates dbaFetchPool and dbaAudit, the pool and the audit
+	// log of ExecuteFetchAsDba, on first use. See dbaFetch.
+	dbaFetchOnce sync.Once
+	dbaFetchPool *smartconnpool.ConnPool[*dbconnpool.DBConnection]
+	dbaAudit     *dbaAuditLog
+
+	// unregisterCredentialsRotation stops configuring the replication source
+	// again when the db credentials are rotated.
+	unregisterCredentialsRotation func()
+
 	// actionSema is there to run only one action at a time.
 	// This semaphore can be held for long periods of time (hours),
 	// like in the case of a restore. This semaphore must be obtained
@@ -206,6 +217,10 @@ type TabletManager struct {
 	// _lockTablesConnection is used to get and release the table read locks to pause replication
 	_lockTablesConnection *dbconnpool.DBConnection
 	_lockTablesTimer      *time.Timer
+	// _snapshotLockConnection holds the lock acquired by AcquireSnapshotLock
+	_snapshotLockConnection *dbconnpool.DBConnection
+	_snapshotLockTimer      *time.Timer
+	_snapshotLockStatement  string
 	// _isBackupRunning tells us whether there is a backup that is currently running
 	_isBackupRunning bool
 }
@@ -403,6 +418,8 @@ func (tm *TabletManager) Start(tablet *topodatapb.Tablet, config *tabletenv.Tabl
 	// in any specific order.
 	tm.startShardSync()
 	tm.exportStats()
+	tm.exportDBAFetch()
+	tm.unregisterCredentialsRotation = dbconfigs.OnCredentialsRotated(tm.refreshReplicationCredentials)
 	servenv.OnRun(tm.registerTabletManager)
 
 	restoring, err := tm.handleRestore(tm.BatchCtx, config)
@@ -488,6 +505,10 @@ func (tm *TabletManager) Stop() {
 		tm.VDiffEngine.Close()
 	}
 
+	if tm.unregisterCredentialsRotation != nil {
+		tm.unregisterCredentialsRotation()
+	}
+	tm.closeDBAFetch()
 	tm.MysqlDaemon.Close()
 	tm.tmState.Close()
 }
@@ -44,6 +44,10 @@ import (
 
 var publishRetryInterval = 30 * time.Second
 
+// deniedTablesRetryInterval is how long the primary waits before it tries to
+// remove the expired denied tables again.
+var deniedTablesRetryInterval = time.Minute
+
 func registerStateFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&publishRetryInterval, "publish_retry_interval", publishRetryInterval, "how long vttablet waits to retry publishing the tablet record")
 }
@@ -79,6 +83,13 @@ type tmState struct {
 	tablet          *topodatapb.Tablet
 	isPublishing    bool
 
+	// deniedTablesTimer removes the denied tables with a TTL from the shard
+	// record when they expire. Only the primary runs it.
+	deniedTablesTimer *time.Timer
+	// deniedTablesRetryTime delays the next removal when the last one failed,
+	// or had to keep expired tables that a workflow owns.
+	deniedTablesRetryTime time.Time
+
 	// displayState contains the current snapshot of the internal state
 	// and has its own mutex.
 	displayState displayState
@@ -118,6 +129,9 @@ func (ts *tmState) Close() {
 	defer ts.mu.Unlock()
 
 	ts.isOpen = false
+	if ts.deniedTablesTimer != nil {
+		ts.deniedTablesTimer.Stop()
+	}
 	ts.cancel()
 }
 
@@ -146,12 +160,19 @@ func (ts *tmState) RefreshFromTopoInfo(ctx context.Context, shardInfo *topo.Shar
 	if shardInfo != nil {
 		ts.isResharding = len(shardInfo.SourceShards) > 0
 
+		// The tables stay denied until they are removed from the shard
+		// record, so that all the tablets lift them at the same time.
+		var nextExpiry time.Time
 		ts.deniedTables = make(map[topodatapb.TabletType][]string)
 		for _, tc := range shardInfo.TabletControls {
 			if topo.InCellList(ts.tm.tabletAlias.Cell, tc.Cells) {
 				ts.deniedTables[tc.TabletType] = tc.DeniedTables
 			}
+			if expireTime := topo.NextDeniedTableExpireTime(tc); !expireTime.IsZero() && !tc.Frozen && (nextExpiry.IsZero() || expireTime.Before(nextExpiry)) {
+				nextExpiry = expireTime
+			}
 		}
+		ts.scheduleDeniedTablesExpiryLocked(nextExpiry)
 	}
 
 	if srvKeyspace != nil {
@@ -180,6 +201,111 @@ func (ts *tmState) RefreshFromTopoInfo(ctx context.Context, shardInfo *topo.Shar
 	_ = ts.updateLocked(ctx)
 }
 
+// scheduleDeniedTablesExpiryLocked schedules the removal of the denied tables
+// from the shard record when the next ones expire, if this tablet is the
+// primary.
+func (ts *tmState) scheduleDeniedTablesExpiryLocked(nextExpiry time.Time) {
+	if ts.deniedTablesTimer != nil {
+		ts.deniedTablesTimer.Stop()
+		ts.deniedTablesTimer = nil
+	}
+	if nextExpiry.IsZero() || ts.tablet.Type != topodatapb.TabletType_PRIMARY {
+		return
+	}
+	if nextExpiry.Before(ts.deniedTablesRetryTime) {
+		nextExpiry = ts.deniedTablesRetryTime
+	}
+	keyspace, shard := ts.tablet.Keyspace, ts.tablet.Shard
+	ts.deniedTablesTimer = time.AfterFunc(time.Until(nextExpiry), func() {
+		ts.removeExpiredDeniedTables(keyspace, shard)
+	})
+}
+
+// removeExpiredDeniedTables removes the expired denied tables from the shard
+// record, except the ones a MoveTables workflow owns: the workflow removes
+// them when it completes. The tablets refresh their state when the shard
+// record changes.
+func (ts *tmState) removeExpiredDeniedTables(keyspace, shard string) {
+	kept, err := ts.removeExpiredDeniedTablesLocked(keyspace, shard)
+	if err != nil {
+		log.Errorf("Failed to remove the expired denied tables of shard %v/%v: %v", keyspace, shard, err)
+	}
+	if err == nil && !kept {
+		return
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if !ts.isOpen {
+		return
+	}
+	ts.deniedTablesRetryTime = time.Now().Add(deniedTablesRetryInterval)
+	ts.scheduleDeniedTablesExpiryLocked(ts.deniedTablesRetryTime)
+}
+
+// removeExpiredDeniedTablesLocked removes the expired denied tables from the
+// shard record while holding the keyspace lock, which the workflows also hold
+// when they change the denied tables. It returns whether expired tables were
+// kept.
+func (ts *tmState) removeExpiredDeniedTablesLocked(keyspace, shard string) (kept bool, err error) {
+	ctx, cancel := context.WithTimeout(ts.ctx, topo.RemoteOperationTimeout)
+	defer cancel()
+	ctx, unlock, lockErr := ts.tm.TopoServer.LockKeyspace(ctx, keyspace, "RemoveExpiredDeniedTables")
+	if lockErr != nil {
+		return false, lockErr
+	}
+	defer unlock(&err)
+
+	moving, err := tablesMovedByWorkflows(ctx, ts.tm.TopoServer, keyspace)
+	if err != nil {
+		return false, err
+	}
+	_, err = ts.tm.TopoServer.UpdateShardFields(ctx, keyspace, shard, func(si *topo.ShardInfo) error {
+		var removed bool
+		removed, kept = si.RemoveExpiredDeniedTables(time.Now(), func(table string) bool { return moving[table] })
+		if !removed {
+			return topo.NewError(topo.NoUpdateNeeded, si.ShardName())
+		}
+		return nil
+	})
+	if topo.IsErrType(err, topo.NoUpdateNeeded) {
+		err = nil
+	}
+	return kept, err
+}
+
+// tablesMovedByWorkflows returns the tables that the routing rules route from
+// or to the keyspace: a MoveTables workflow is moving them.
+func tablesMovedByWorkflows(ctx context.Context, ts *topo.Server, keyspace string) (map[string]bool, error) {
+	routingRules, err := ts.GetRoutingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	moving := make(map[string]bool)
+	for _, rule := range routingRules.GetRules() {
+		fromKeyspace, fromTable := splitRoutedTable(rule.FromTable)
+		ownsTable := fromKeyspace == keyspace
+		for _, toTable := range rule.ToTables {
+			if toKeyspace, _ := splitRoutedTable(toTable); toKeyspace == keyspace {
+				ownsTable = true
+			}
+		}
+		if ownsTable {
+			moving[fromTable] = true
+		}
+	}
+	return moving, nil
+}
+
+// splitRoutedTable splits a routing rule table of the form
+// [keyspace.]table[@tablet_type] into its keyspace and table.
+func splitRoutedTable(name string) (keyspace, table string) {
+	name, _, _ = strings.Cut(name, "@")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
 func (ts *tmState) ChangeTabletType(ctx context.Context, tabletType topodatapb.TabletType, action DBAction) error {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
@@ -362,7 +488,7 @@ func (ts *tmState) applyDenyList(ctx context.Context) (err error) {
 		// that we don't add a rule to deny all tables
 		if len(tables) > 0 {
 			log.Infof("Denying tables %v", strings.Join(tables, ", "))
-			qr := rules.NewQueryRule("enforce denied tables", "denied_table", rules.QRFailRetry)
+			qr := rules.NewQueryRule(rules.DeniedTablesRuleDescription, "denied_table", rules.QRFailRetry)
 			for _, t := range tables {
 				qr.AddTableCond(t)
 			}
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/mysqlctl"
@@ -38,7 +39,9 @@ import (
 
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
 
 func TestStateOpenClose(t *testing.T) {
@@ -136,6 +139,64 @@ func TestStateDenyList(t *testing.T) {
 	assert.Equal(t, `[{"Description":"enforce denied tables","Name":"denied_table","TableNames":["t1"],"Action":"FAIL_RETRY"}]`, string(b))
 }
 
+func TestStateDenyListExpiry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	tm := newTestTM(t, ts, 1, "ks", "0")
+	defer tm.Stop()
+
+	fmd := tm.MysqlDaemon.(*mysqlctl.FakeMysqlDaemon)
+	fmd.Schema = &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{{
+			Name: "t1",
+		}},
+	}
+	tm.tmState.mu.Lock()
+	tm.tmState.tablet.Type = topodatapb.TabletType_PRIMARY
+	tm.tmState.mu.Unlock()
+
+	// The routing rules of a MoveTables workflow own t2.
+	require.NoError(t, ts.SaveRoutingRules(ctx, &vschemapb.RoutingRules{
+		Rules: []*vschemapb.RoutingRule{{FromTable: "t2", ToTables: []string{"ks.t2"}}},
+	}))
+
+	// The primary removes the expired denied tables from the shard record,
+	// except the ones a workflow owns, and the tablets stop enforcing them
+	// when they refresh.
+	expireTime := protoutil.TimeToProto(time.Now().Add(500 * time.Millisecond))
+	_, err := ts.UpdateShardFields(ctx, "ks", "0", func(si *topo.ShardInfo) error {
+		si.TabletControls = []*topodatapb.Shard_TabletControl{{
+			TabletType:              topodatapb.TabletType_PRIMARY,
+			DeniedTables:            []string{"t1", "t2", "t3"},
+			DeniedTablesExpireTimes: map[string]*vttimepb.Time{"t1": expireTime, "t2": expireTime},
+		}}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, tm.tmState.RefreshFromTopo(ctx))
+	tm.tmState.mu.Lock()
+	assert.Equal(t, map[topodatapb.TabletType][]string{topodatapb.TabletType_PRIMARY: {"t1", "t2", "t3"}}, tm.tmState.deniedTables)
+	tm.tmState.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		si, err := ts.GetShard(ctx, "ks", "0")
+		return err == nil && len(si.TabletControls) == 1 && len(si.TabletControls[0].DeniedTables) == 2
+	}, 5*time.Second, 50*time.Millisecond)
+	si, err := ts.GetShard(ctx, "ks", "0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t2", "t3"}, si.TabletControls[0].DeniedTables)
+	utils.MustMatch(t, map[string]*vttimepb.Time{"t2": expireTime}, si.TabletControls[0].DeniedTablesExpireTimes)
+
+	require.NoError(t, tm.tmState.RefreshFromTopo(ctx))
+	tm.tmState.mu.Lock()
+	assert.Equal(t, map[topodatapb.TabletType][]string{topodatapb.TabletType_PRIMARY: {"t2", "t3"}}, tm.tmState.deniedTables)
+	// The removal is retried later for the table the workflow owns.
+	assert.NotNil(t, tm.tmState.deniedTablesTimer)
+	assert.True(t, tm.tmState.deniedTablesRetryTime.After(time.Now()))
+	tm.tmState.mu.Unlock()
+}
+
 func TestStateTabletControls(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -59,6 +59,8 @@ type controller struct {
 
 	id           int32
 	workflow     string
+	priority     streamPriority
+	config       workflowConfig
 	source       *binlogdatapb.BinlogSource
 	stopPos      string
 	tabletPicker *discovery.TabletPicker
@@ -113,6 +115,11 @@ func newController(ctx context.Context, params map[string]string, dbClientFactor
 	}
 
 	ct.stopPos = params["stop_pos"]
+	workflowType, _ := strconv.ParseInt(params["workflow_type"], 10, 32)
+	ct.priority = priorityForStream(ct.workflow, binlogdatapb.VReplicationWorkflowType(workflowType), ct.source, vre.env.Parser())
+	if ct.config, err = parseWorkflowConfig(params["options"]); err != nil {
+		log.Warningf("Ignoring the invalid options of the VReplication controller %d for workflow %q: %v", ct.id, ct.workflow, err)
+	}
 
 	if ct.source.GetExternalMysql() == "" {
 		if v := params["cell"]; v != "" {
@@ -256,6 +263,8 @@ func (ct *controller) runBlp(ctx context.Context) (err error) {
 		defer vsClient.Close(ctx)
 
 		vr := newVReplicator(ct.id, ct.source, vsClient, ct.blpStats, dbClient, ct.mysqld, ct.vre)
+		vr.priority = ct.priority
+		vr.config = ct.config
 		err = vr.Replicate(ctx)
 		ct.lastWorkflowError.Record(err)
 
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// reverseWorkflowSuffix is the suffix of the workflows that replicate back
+// to the original source after traffic has been switched.
+const reverseWorkflowSuffix = "_reverse"
+
+// streamPriority is the priority of a stream when the copy phases of the
+// streams of a tablet compete for resources.
+type streamPriority int
+
+const (
+	// streamPriorityBulk is the priority of the streams that only move data,
+	// and can wait without affecting correctness.
+	streamPriorityBulk streamPriority = iota
+	// streamPriorityCritical is the priority of the streams needed for
+	// correctness: reverse replication, that a failover or a traffic switch
+	// relies on, and Materialize rollups.
+	streamPriorityCritical
+)
+
+func (p streamPriority) String() string {
+	switch p {
+	case streamPriorityCritical:
+		return "critical"
+	default:
+		return "bulk"
+	}
+}
+
+// priorityForStream returns the priority of a stream given its workflow name and type,
+// and its source.
+func priorityForStream(workflow string, workflowType binlogdatapb.VReplicationWorkflowType, source *binlogdatapb.BinlogSource, parser *sqlparser.Parser) streamPriority {
+	if strings.HasSuffix(workflow, reverseWorkflowSuffix) {
+		return streamPriorityCritical
+	}
+	if workflowType != binlogdatapb.VReplicationWorkflowType_Materialize {
+		return streamPriorityBulk
+	}
+	for _, rule := range source.GetFilter().GetRules() {
+		stmt, err := parser.Parse(rule.Filter)
+		if err != nil {
+			continue
+		}
+		if sel, ok := stmt.(*sqlparser.Select); ok && sel.GroupBy != nil {
+			return streamPriorityCritical
+		}
+	}
+	return streamPriorityBulk
+}
+
+// copyLane is a slot to run a copy phase, held or awaited by a stream.
+type copyLane struct {
+	priority streamPriority
+	// preempt interrupts the copy phase running in the lane.
+	preempt   context.CancelFunc
+	preempted bool
+	granted   chan struct{}
+}
+
+// copyLanes schedules the copy phases of the streams of a tablet. At most
+// size copy phases run concurrently, or any number if size is not positive,
+// and waiting critical streams are granted a lane before bulk ones.
+// Under resource pressure, that is when a critical stream waits for a lane
+// or lags behind while replicating, running bulk copy phases are preempted.
+// A preempted copy phase stops like it does when the copy phase duration
+// elapses, and resumes from its last copied row once it gets a lane again.
+type copyLanes struct {
+	mu      sync.Mutex
+	size    int
+	running map[*copyLane]struct{}
+	waiting []*copyLane
+	// lagging is the set of critical streams lagging behind while replicating.
+	lagging map[int32]struct{}
+}
+
+func newCopyLanes(size int) *copyLanes {
+	return &copyLanes{
+		size:    size,
+		running: make(map[*copyLane]struct{}),
+		lagging: make(map[int32]struct{}),
+	}
+}
+
+// acquire waits for a lane to run a copy phase with the given priority. The returned
+// function must be called to release the lane once the copy phase ends. preempt is
+// called, at most once, if the copy phase must stop to yield its lane.
+func (cl *copyLanes) acquire(ctx context.Context, priority streamPriority, preempt context.CancelFunc) (release func(), err error) {
+	defer globalStats.CopyLaneWaitTimings.Record(priority.String(), time.Now())
+
+	lane := &copyLane{
+		priority: priority,
+		preempt:  preempt,
+		granted:  make(chan struct{}),
+	}
+	cl.mu.Lock()
+	cl.waiting = append(cl.waiting, lane)
+	cl.schedule()
+	cl.mu.Unlock()
+
+	release = func() {
+		cl.mu.Lock()
+		defer cl.mu.Unlock()
+		delete(cl.running, lane)
+		cl.schedule()
+	}
+	select {
+	case <-lane.granted:
+		return release, nil
+	case <-ctx.Done():
+	}
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	select {
+	case <-lane.granted:
+		// The lane was granted concurrently, give it back.
+		delete(cl.running, lane)
+	default:
+		for i, waiting := range cl.waiting {
+			if waiting == lane {
+				cl.waiting = append(cl.waiting[:i], cl.waiting[i+1:]...)
+				break
+			}
+		}
+	}
+	cl.schedule()
+	return nil, ctx.Err()
+}
+
+// setLagging records whether the critical stream with the given id lags behind.
+// While any critical stream lags, no bulk copy phase runs.
+func (cl *copyLanes) setLagging(id int32, lagging bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if _, ok := cl.lagging[id]; ok == lagging {
+		return
+	}
+	if lagging {
+		log.Infof("vreplication stream %d is lagging, pausing the bulk copy phases", id)
+		cl.lagging[id] = struct{}{}
+	} else {
+		delete(cl.lagging, id)
+	}
+	cl.schedule()
+}
+
+// schedule grants the free lanes to the waiting streams, by priority then in
+// arrival order, and preempts the bulk copy phases that critical streams need
+// to yield. It must be called with mu held.
+func (cl *copyLanes) schedule() {
+	for len(cl.waiting) > 0 {
+		next := 0
+		for i, lane := range cl.waiting {
+			if lane.priority > cl.waiting[next].priority {
+				next = i
+			}
+		}
+		lane := cl.waiting[next]
+		if !cl.canRun(lane.priority) {
+			break
+		}
+		cl.waiting = append(cl.waiting[:next], cl.waiting[next+1:]...)
+		cl.running[lane] = struct{}{}
+		close(lane.granted)
+	}
+
+	// Bulk copy phases stop altogether while a critical stream lags, and otherwise
+	// yield as many lanes as there are critical streams waiting for one.
+	yield := 0
+	if len(cl.lagging) > 0 {
+		yield = len(cl.running)
+	} else {
+		for _, lane := range cl.waiting {
+			if lane.priority == streamPriorityCritical {
+				yield++
+			}
+		}
+	}
+	for lane := range cl.running {
+		if lane.preempted {
+			yield--
+		}
+	}
+	for lane := range cl.running {
+		if yield <= 0 {
+			break
+		}
+		if lane.priority != streamPriorityBulk || lane.preempted || lane.preempt == nil {
+			continue
+		}
+		lane.preempted = true
+		lane.preempt()
+		globalStats.CopyLanePreemptions.Add(lane.priority.String(), 1)
+		yield--
+	}
+}
+
+// canRun returns true if a copy phase with the given priority can be granted a lane now.
+// It must be called with mu held.
+func (cl *copyLanes) canRun(priority streamPriority) bool {
+	if priority == streamPriorityBulk && len(cl.lagging) > 0 {
+		return false
+	}
+	return cl.size <= 0 || len(cl.running) < cl.size
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestPriorityForStream(t *testing.T) {
+	source := func(filters ...string) *binlogdatapb.BinlogSource {
+		bls := &binlogdatapb.BinlogSource{Filter: &binlogdatapb.Filter{}}
+		for _, filter := range filters {
+			bls.Filter.Rules = append(bls.Filter.Rules, &binlogdatapb.Rule{Match: "t1", Filter: filter})
+		}
+		return bls
+	}
+	testcases := []struct {
+		name         string
+		workflow     string
+		workflowType binlogdatapb.VReplicationWorkflowType
+		source       *binlogdatapb.BinlogSource
+		want         streamPriority
+	}{{
+		name:         "move tables",
+		workflow:     "commerce2customer",
+		workflowType: binlogdatapb.VReplicationWorkflowType_MoveTables,
+		source:       source("select * from t1"),
+		want:         streamPriorityBulk,
+	}, {
+		name:         "reverse move tables",
+		workflow:     "commerce2customer_reverse",
+		workflowType: binlogdatapb.VReplicationWorkflowType_MoveTables,
+		source:       source("select * from t1"),
+		want:         streamPriorityCritical,
+	}, {
+		name:         "reverse reshard",
+		workflow:     "cust2cust_reverse",
+		workflowType: binlogdatapb.VReplicationWorkflowType_Reshard,
+		source:       source("-80"),
+		want:         streamPriorityCritical,
+	}, {
+		name:         "materialize",
+		workflow:     "sales",
+		workflowType: binlogdatapb.VReplicationWorkflowType_Materialize,
+		source:       source("select id, val from t1"),
+		want:         streamPriorityBulk,
+	}, {
+		name:         "materialize rollup",
+		workflow:     "sales",
+		workflowType: binlogdatapb.VReplicationWorkflowType_Materialize,
+		source:       source("select id, val from t2", "select kgroup, count(*) as cnt from t1 group by kgroup"),
+		want:         streamPriorityCritical,
+	}, {
+		name:         "group by outside of materialize",
+		workflow:     "online_ddl",
+		workflowType: binlogdatapb.VReplicationWorkflowType_OnlineDDL,
+		source:       source("select kgroup, count(*) as cnt from t1 group by kgroup"),
+		want:         streamPriorityBulk,
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, priorityForStream(tc.workflow, tc.workflowType, tc.source, sqlparser.NewTestParser()))
+		})
+	}
+}
+
+// acquireAsync acquires a lane in the background. The returned channel yields the release
+// function once the lane is granted.
+func acquireAsync(ctx context.Context, cl *copyLanes, priority streamPriority, preempt context.CancelFunc) <-chan func() {
+	ch := make(chan func(), 1)
+	go func() {
+		release, err := cl.acquire(ctx, priority, preempt)
+		if err == nil {
+			ch <- release
+		}
+	}()
+	return ch
+}
+
+func waitForLane(t *testing.T, ch <-chan func()) func() {
+	t.Helper()
+	select {
+	case release := <-ch:
+		return release
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "lane was not granted")
+		return nil
+	}
+}
+
+func assertNoLane(t *testing.T, ch <-chan func()) {
+	t.Helper()
+	select {
+	case <-ch:
+		require.FailNow(t, "lane should not have been granted")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCopyLanesLimit(t *testing.T) {
+	ctx := context.Background()
+	cl := newCopyLanes(1)
+
+	release1 := waitForLane(t, acquireAsync(ctx, cl, streamPriorityBulk, func() {}))
+	bulk := acquireAsync(ctx, cl, streamPriorityBulk, func() {})
+	assertNoLane(t, bulk)
+
+	release1()
+	release2 := waitForLane(t, bulk)
+	release2()
+}
+
+func TestCopyLanesUnlimited(t *testing.T) {
+	ctx := context.Background()
+	cl := newCopyLanes(0)
+
+	var preempted atomic.Bool
+	release1 := waitForLane(t, acquireAsync(ctx, cl, streamPriorityBulk, func() { preempted.Store(true) }))
+	release2 := waitForLane(t, acquireAsync(ctx, cl, streamPriorityCritical, func() {}))
+	assert.False(t, preempted.Load())
+	release1()
+	release2()
+}
+
+func TestCopyLanesCriticalPreemptsBulk(t *testing.T) {
+	ctx := context.Background()
+	cl := newCopyLanes(1)
+	preemptions := globalStats.CopyLanePreemptions.Counts()["bulk"]
+
+	preempted := make(chan struct{})
+	releaseBulk := waitForLane(t, acquireAsync(ctx, cl, streamPriorityBulk, func() { close(preempted) }))
+
+	// A bulk stream queues without preempting, a critical one preempts the running bulk copy.
+	bulk := acquireAsync(ctx, cl, streamPriorityBulk, func() {})
+	assertNoLane(t, bulk)
+	critical := acquireAsync(ctx, cl, streamPriorityCritical, func() {})
+	select {
+	case <-preempted:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "bulk copy was not preempted")
+	}
+	assert.Equal(t, preemptions+1, globalStats.CopyLanePreemptions.Counts()["bulk"])
+
+	// The critical stream is granted the lane ahead of the waiting bulk stream.
+	releaseBulk()
+	releaseCritical := waitForLane(t, critical)
+	assertNoLane(t, bulk)
+	releaseCritical()
+	waitForLane(t, bulk)()
+}
+
+func TestCopyLanesAtomicCopyIsNotPreempted(t *testing.T) {
+	ctx := context.Background()
+	cl := newCopyLanes(1)
+
+	releaseAtomic := waitForLane(t, acquireAsync(ctx, cl, streamPriorityBulk, nil))
+	critical := acquireAsync(ctx, cl, streamPriorityCritical, func() {})
+	assertNoLane(t, critical)
+	releaseAtomic()
+	waitForLane(t, critical)()
+}
+
+func TestCopyLanesLagging(t *testing.T) {
+	ctx := context.Background()
+	cl := newCopyLanes(0)
+
+	var preempted atomic.Bool
+	releaseBulk := waitForLane(t, acquireAsync(ctx, cl, streamPriorityBulk, func() { preempted.Store(true) }))
+
+	// While a critical stream lags, running bulk copies are preempted and no new one starts.
+	cl.setLagging(1, true)
+	assert.True(t, preempted.Load())
+	bulk := acquireAsync(ctx, cl, streamPriorityBulk, func() {})
+	assertNoLane(t, bulk)
+	waitForLane(t, acquireAsync(ctx, cl, streamPriorityCritical, func() {}))()
+
+	releaseBulk()
+	assertNoLane(t, bulk)
+	cl.setLagging(1, false)
+	waitForLane(t, bulk)()
+}
+
+func TestCopyLanesCanceled(t *testing.T) {
+	cl := newCopyLanes(1)
+
+	release := waitForLane(t, acquireAsync(context.Background(), cl, streamPriorityBulk, func() {}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cl.acquire(ctx, streamPriorityBulk, func() {})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, cl.waiting)
+
+	release()
+	waitForLane(t, acquireAsync(context.Background(), cl, streamPriorityBulk, func() {}))()
+	assert.Empty(t, cl.running)
+}
@@ -106,6 +106,13 @@ type Engine struct {
 
 	throttlerClient *throttle.Client
 
+	// activity is the query traffic of the tablet, which the streams add to
+	// while they copy rows and apply events.
+	activity *tabletenv.Activity
+
+	// copyLanes schedules the copy phases of the streams by priority.
+	copyLanes *copyLanes
+
 	// This should only be set in Test Engines in order to short
 	// circuit functions as needed in unit tests. It's automatically
 	// enabled in NewSimpleTestEngine. This should NOT be used in
@@ -129,7 +136,7 @@ type PostCopyAction struct {
 
 // NewEngine creates a new Engine.
 // A nil ts means that the Engine is disabled.
-func NewEngine(env *vtenv.Environment, config *tabletenv.TabletConfig, ts *topo.Server, cell string, mysqld mysqlctl.MysqlDaemon, lagThrottler *throttle.Throttler) *Engine {
+func NewEngine(env *vtenv.Environment, config *tabletenv.TabletConfig, ts *topo.Server, cell string, mysqld mysqlctl.MysqlDaemon, lagThrottler *throttle.Throttler, activity *tabletenv.Activity) *Engine {
 	vre := &Engine{
 		env:             env,
 		controllers:     make(map[int32]*controller),
@@ -139,11 +146,21 @@ func NewEngine(env *vtenv.Environment, config *tabletenv.TabletConfig, ts *topo.
 		journaler:       make(map[string]*journalEvent),
 		ec:              newExternalConnector(env, config.ExternalConnections),
 		throttlerClient: throttle.NewBackgroundClient(lagThrottler, throttlerapp.VReplicationName, throttle.ThrottleCheckPrimaryWrite),
+		activity:        activity,
+		copyLanes:       newCopyLanes(vreplicationMaxConcurrentCopies),
 	}
 
 	return vre
 }
 
+// recordActivity records the writes of the streams as query traffic of the
+// tablet. Test engines have no activity.
+func (vre *Engine) recordActivity() {
+	if vre.activity != nil {
+		vre.activity.Record()
+	}
+}
+
 // InitDBConfig should be invoked after the db name is computed.
 func (vre *Engine) InitDBConfig(dbcfgs *dbconfigs.DBConfigs) {
 	// If we're already initialized, it's a test engine. Ignore the call.
@@ -173,6 +190,7 @@ func NewTestEngine(ts *topo.Server, cell string, mysqld mysqlctl.MysqlDaemon, db
 		dbName:                  dbname,
 		journaler:               make(map[string]*journalEvent),
 		ec:                      newExternalConnector(env, externalConfig),
+		copyLanes:               newCopyLanes(vreplicationMaxConcurrentCopies),
 	}
 	return vre
 }
@@ -192,6 +210,7 @@ func NewSimpleTestEngine(ts *topo.Server, cell string, mysqld mysqlctl.MysqlDaem
 		dbName:                  dbname,
 		journaler:               make(map[string]*journalEvent),
 		ec:                      newExternalConnector(env, externalConfig),
+		copyLanes:               newCopyLanes(vreplicationMaxConcurrentCopies),
 		shortcircuit:            true,
 	}
 	return vre
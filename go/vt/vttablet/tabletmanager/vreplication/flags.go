@@ -39,6 +39,8 @@ var (
 
 	vreplicationStoreCompressedGTID   = false
 	vreplicationParallelInsertWorkers = 1
+
+	vreplicationMaxConcurrentCopies = 0
 )
 
 func registerVReplicationFlags(fs *pflag.FlagSet) {
@@ -59,6 +61,7 @@ func registerVReplicationFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&vreplicationStoreCompressedGTID, "vreplication_store_compressed_gtid", vreplicationStoreCompressedGTID, "Store compressed gtids in the pos column of the sidecar database's vreplication table")
 
 	fs.IntVar(&vreplicationParallelInsertWorkers, "vreplication-parallel-insert-workers", vreplicationParallelInsertWorkers, "Number of parallel insertion workers to use during copy phase. Set <= 1 to disable parallelism, or > 1 to enable concurrent insertion during copy phase.")
+	fs.IntVar(&vreplicationMaxConcurrentCopies, "vreplication-max-concurrent-copies", vreplicationMaxConcurrentCopies, "Maximum number of streams running their copy phase concurrently on a tablet. Critical streams, like reverse replication and Materialize rollups, preempt the copy phase of bulk streams when they need a slot. Set <= 0 for no limit.")
 
 	// Deprecated and ignored in v19.
 	fs.String("vreplication_tablet_type", tabletTypesStr, "Comma-separated list of tablet types used as a source.")
@@ -35,6 +35,8 @@ type InsertGenerator struct {
 	state  string
 	dbname string
 	now    int64
+
+	options string
 }
 
 // NewInsertGenerator creates a new InsertGenerator.
@@ -53,6 +55,10 @@ func NewInsertGenerator(state binlogdatapb.VReplicationWorkflowState, dbname str
 func (ig *InsertGenerator) AddRow(workflow string, bls *binlogdatapb.BinlogSource, pos, cell, tabletTypes string,
 	workflowType binlogdatapb.VReplicationWorkflowType, workflowSubType binlogdatapb.VReplicationWorkflowSubType, deferSecondaryKeys bool) {
 	protoutil.SortBinlogSourceTables(bls)
+	options := "'{}'"
+	if ig.options != "" {
+		options = encodeString(ig.options)
+	}
 	fmt.Fprintf(ig.buf, "%s(%v, %v, %v, %v, %v, %v, %v, %v, 0, '%v', %v, %d, %d, %v, %v)",
 		ig.prefix,
 		encodeString(workflow),
@@ -68,11 +74,16 @@ func (ig *InsertGenerator) AddRow(workflow string, bls *binlogdatapb.BinlogSourc
 		workflowType,
 		workflowSubType,
 		deferSecondaryKeys,
-		"'{}'",
+		options,
 	)
 	ig.prefix = ", "
 }
 
+// SetOptions sets the options of the workflow, as JSON, for the rows added after it.
+func (ig *InsertGenerator) SetOptions(options string) {
+	ig.options = options
+}
+
 // String returns the generated statement.
 func (ig *InsertGenerator) String() string {
 	return ig.buf.String()
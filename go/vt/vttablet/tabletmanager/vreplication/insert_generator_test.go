@@ -35,4 +35,9 @@ func TestInsertGenerator(t *testing.T) {
 	ig.AddRow("g", &binlogdatapb.BinlogSource{Keyspace: "h"}, "i", "j", "k", binlogdatapb.VReplicationWorkflowType_Reshard, binlogdatapb.VReplicationWorkflowSubType_Partial, true)
 	want += `, ('g', 'keyspace:\"h\"', 'i', 9223372036854775807, 9223372036854775807, 'j', 'k', 111, 0, 'Stopped', 'a', 4, 1, true, '{}')`
 	assert.Equal(t, ig.String(), want)
+
+	ig.SetOptions(`{"parallel_insert_workers":4}`)
+	ig.AddRow("l", &binlogdatapb.BinlogSource{Keyspace: "m"}, "n", "o", "p", binlogdatapb.VReplicationWorkflowType_Reshard, binlogdatapb.VReplicationWorkflowSubType_None, false)
+	want += `, ('l', 'keyspace:\"m\"', 'n', 9223372036854775807, 9223372036854775807, 'o', 'p', 111, 0, 'Stopped', 'a', 4, 0, false, '{\"parallel_insert_workers\":4}')`
+	assert.Equal(t, ig.String(), want)
 }
@@ -61,10 +61,15 @@ type vrStats struct {
 	controllers map[int32]*controller
 
 	ThrottledCount *stats.Counter
+
+	CopyLanePreemptions *stats.CountersWithSingleLabel
+	CopyLaneWaitTimings *stats.Timings
 }
 
 func (st *vrStats) register() {
 	st.ThrottledCount = stats.NewCounter("", "")
+	st.CopyLanePreemptions = stats.NewCountersWithSingleLabel("VReplicationCopyLanePreemptions", "Number of copy phases preempted to yield to critical streams, by priority", "Priority")
+	st.CopyLaneWaitTimings = stats.NewTimings("VReplicationCopyLaneWaitTimings", "Time spent by copy phases waiting for a copy lane, by priority", "Priority")
 	stats.NewGaugeFunc("VReplicationStreamCount", "Number of vreplication streams", st.numControllers)
 	stats.NewGaugeFunc("VReplicationLagSecondsMax", "Max vreplication seconds behind primary", st.maxReplicationLagSeconds)
 	stats.NewStringMapFuncWithMultiLabels(
@@ -527,6 +532,35 @@ func (st *vrStats) register() {
 			}
 			return result
 		})
+
+	stats.NewCountersFuncWithMultiLabels(
+		"VReplicationPriorityCopyRowCount",
+		"vreplication rows copied aggregated by stream priority",
+		[]string{"priority"},
+		func() map[string]int64 {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			result := make(map[string]int64)
+			for _, ct := range st.controllers {
+				result[ct.priority.String()] += ct.blpStats.CopyRowCount.Get()
+			}
+			return result
+		})
+	stats.NewCountersFuncWithMultiLabels(
+		"VReplicationPriorityQueryCount",
+		"vreplication query counts aggregated by stream priority and phase",
+		[]string{"priority", "phase"},
+		func() map[string]int64 {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			result := make(map[string]int64)
+			for _, ct := range st.controllers {
+				for phase, count := range ct.blpStats.QueryCount.Counts() {
+					result[ct.priority.String()+"."+phase] += count
+				}
+			}
+			return result
+		})
 }
 
 func (st *vrStats) numControllers() int64 {
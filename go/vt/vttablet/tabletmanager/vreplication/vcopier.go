@@ -395,6 +395,13 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 	ctx, cancel := context.WithTimeout(ctx, vttablet.CopyPhaseDuration)
 	defer cancel()
 
+	// A preempted copy phase ends like one whose duration elapsed.
+	releaseLane, err := vc.vr.vre.copyLanes.acquire(ctx, vc.vr.priority, cancel)
+	if err != nil {
+		return nil
+	}
+	defer releaseLane()
+
 	var lastpkpb *querypb.QueryResult
 	if lastpkqr := copyState[tableName]; lastpkqr != nil {
 		lastpkpb = sqltypes.ResultToProto3(lastpkqr)
@@ -405,7 +412,7 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 	copyStateGCTicker := time.NewTicker(copyStateGCInterval)
 	defer copyStateGCTicker.Stop()
 
-	parallelism := getInsertParallelism()
+	parallelism := vc.vr.config.insertParallelism()
 	copyWorkerFactory := vc.newCopyWorkerFactory(parallelism)
 	copyWorkQueue := vc.newCopyWorkQueue(parallelism, copyWorkerFactory)
 	defer copyWorkQueue.close()
@@ -501,6 +508,7 @@ func (vc *vcopier) copyTable(ctx context.Context, tableName string, copyState ma
 		if len(rows.Rows) == 0 {
 			return nil
 		}
+		vc.vr.vre.recordActivity()
 
 		// Clone rows, since pointer values will change while async work is
 		// happening. Can skip this when there's no parallelism.
@@ -82,10 +82,17 @@ func (vc *vcopier) copyAll(ctx context.Context, settings binlogplayer.VRSettings
 	ctx, cancel := context.WithTimeout(ctx, vttablet.CopyPhaseDuration)
 	defer cancel()
 
+	// CopyAll cannot resume after an interruption, so it is never preempted.
+	releaseLane, err := vc.vr.vre.copyLanes.acquire(ctx, vc.vr.priority, nil)
+	if err != nil {
+		return err
+	}
+	defer releaseLane()
+
 	rowsCopiedTicker := time.NewTicker(rowsCopiedUpdateInterval)
 	defer rowsCopiedTicker.Stop()
 
-	parallelism := getInsertParallelism()
+	parallelism := vc.vr.config.insertParallelism()
 	copyWorkerFactory := vc.newCopyWorkerFactory(parallelism)
 	var copyWorkQueue *vcopierCopyWorkQueue
 
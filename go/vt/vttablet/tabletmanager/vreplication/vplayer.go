@@ -125,15 +125,15 @@ func newVPlayer(vr *vreplicator, settings binlogplayer.VRSettings, copyState map
 	}
 	if batchMode {
 		// relayLogMaxSize is effectively the limit used when not batching.
-		maxAllowedPacket := int64(relayLogMaxSize)
+		maxAllowedPacket := int64(vr.config.relayLogMaxSize())
 		// We explicitly do NOT want to batch this, we want to send it down the wire
 		// immediately so we use ExecuteFetch directly.
 		res, err := vr.dbClient.ExecuteFetch("select @@session.max_allowed_packet as max_allowed_packet", 1)
 		if err != nil {
-			log.Errorf("Error getting max_allowed_packet, will use the relay_log_max_size value of %d bytes: %v", relayLogMaxSize, err)
+			log.Errorf("Error getting max_allowed_packet, will use the relay_log_max_size value of %d bytes: %v", vr.config.relayLogMaxSize(), err)
 		} else {
 			if maxAllowedPacket, err = res.Rows[0][0].ToInt64(); err != nil {
-				log.Errorf("Error getting max_allowed_packet, will use the relay_log_max_size value of %d bytes: %v", relayLogMaxSize, err)
+				log.Errorf("Error getting max_allowed_packet, will use the relay_log_max_size value of %d bytes: %v", vr.config.relayLogMaxSize(), err)
 			}
 		}
 		// Leave 64 bytes of room for the commit to be sure that we have a more than
@@ -256,7 +256,7 @@ func (vp *vplayer) fetchAndApply(ctx context.Context) (err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	relay := newRelayLog(ctx, relayLogMaxItems, relayLogMaxSize)
+	relay := newRelayLog(ctx, vp.vr.config.relayLogMaxItems(), vp.vr.config.relayLogMaxSize())
 
 	streamErr := make(chan error, 1)
 	go func() {
@@ -459,6 +459,7 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 	// can estimate this value more accurately.
 	defer vp.vr.stats.ReplicationLagSeconds.Store(math.MaxInt64)
 	defer vp.vr.stats.VReplicationLags.Add(strconv.Itoa(int(vp.vr.id)), math.MaxInt64)
+	defer vp.updateCopyLanes(0)
 	var sbm int64 = -1
 	for {
 		if ctx.Err() != nil {
@@ -476,10 +477,14 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 		}
 		// No events were received. This likely means that there's a network partition.
 		// So, we should assume we're falling behind.
+		if len(items) != 0 {
+			vp.vr.vre.recordActivity()
+		}
 		if len(items) == 0 {
 			behind := time.Now().UnixNano() - vp.lastTimestampNs - vp.timeOffsetNs
 			vp.vr.stats.ReplicationLagSeconds.Store(behind / 1e9)
 			vp.vr.stats.VReplicationLags.Add(strconv.Itoa(int(vp.vr.id)), time.Duration(behind/1e9)*time.Second)
+			vp.updateCopyLanes(behind / 1e9)
 		}
 		// Empty transactions are saved at most once every idleTimeout.
 		// This covers two situations:
@@ -536,11 +541,21 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 		if sbm >= 0 {
 			vp.vr.stats.ReplicationLagSeconds.Store(sbm)
 			vp.vr.stats.VReplicationLags.Add(strconv.Itoa(int(vp.vr.id)), time.Duration(sbm)*time.Second)
+			vp.updateCopyLanes(sbm)
 		}
 
 	}
 }
 
+// updateCopyLanes pauses the bulk copy phases of the tablet while a critical
+// stream lags behind by more than the replica lag tolerance.
+func (vp *vplayer) updateCopyLanes(lagSeconds int64) {
+	if vp.vr.priority != streamPriorityCritical || vp.phase != "replicate" {
+		return
+	}
+	vp.vr.vre.copyLanes.setLagging(vp.vr.id, lagSeconds > int64(replicaLagTolerance/time.Second))
+}
+
 func hasAnotherCommit(items [][]*binlogdatapb.VEvent, i, j int) bool {
 	for i < len(items) {
 		for j < len(items[i]) {
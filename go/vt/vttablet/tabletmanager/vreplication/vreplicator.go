@@ -110,6 +110,13 @@ type vreplicator struct {
 	WorkflowSubType int32
 	WorkflowName    string
 
+	// priority decides which copy phases yield when the copy phases of the
+	// streams of the tablet compete for resources.
+	priority streamPriority
+
+	// config holds the settings of the workflow that override the flags of the tablet.
+	config workflowConfig
+
 	throttleUpdatesRateLimiter *timer.RateLimiter
 }
 
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"encoding/json"
+)
+
+// workflowConfig holds the settings of a workflow that override the vreplication
+// flags of the tablet. They are stored in the options column of the workflow's
+// streams, as the JSON encoding of a vtctldata.WorkflowOptions.
+type workflowConfig struct {
+	RelayLogMaxItems      int64 `json:"relay_log_max_items,omitempty"`
+	RelayLogMaxSize       int64 `json:"relay_log_max_size,omitempty"`
+	ParallelInsertWorkers int64 `json:"parallel_insert_workers,omitempty"`
+}
+
+// parseWorkflowConfig parses the options column of a stream. The settings that
+// are not set, or can't be parsed, fall back to the flags of the tablet.
+func parseWorkflowConfig(options string) (workflowConfig, error) {
+	var config workflowConfig
+	if options == "" {
+		return config, nil
+	}
+	if err := json.Unmarshal([]byte(options), &config); err != nil {
+		return workflowConfig{}, err
+	}
+	return config, nil
+}
+
+// relayLogMaxItems returns the maximum number of rows for target buffering.
+func (c workflowConfig) relayLogMaxItems() int {
+	if c.RelayLogMaxItems > 0 {
+		return int(c.RelayLogMaxItems)
+	}
+	return relayLogMaxItems
+}
+
+// relayLogMaxSize returns the maximum size, in bytes, of the target buffering.
+func (c workflowConfig) relayLogMaxSize() int {
+	if c.RelayLogMaxSize > 0 {
+		return int(c.RelayLogMaxSize)
+	}
+	return relayLogMaxSize
+}
+
+// insertParallelism returns the number of parallel workers to use for inserting
+// batches during the copy phase.
+func (c workflowConfig) insertParallelism() int {
+	if c.ParallelInsertWorkers > 0 {
+		return int(c.ParallelInsertWorkers)
+	}
+	return getInsertParallelism()
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowConfig(t *testing.T) {
+	config, err := parseWorkflowConfig("")
+	require.NoError(t, err)
+	require.Equal(t, relayLogMaxItems, config.relayLogMaxItems())
+	require.Equal(t, relayLogMaxSize, config.relayLogMaxSize())
+	require.Equal(t, getInsertParallelism(), config.insertParallelism())
+
+	// Options of other features are ignored.
+	config, err = parseWorkflowConfig(`{"tenant_id":"1","relay_log_max_items":10,"relay_log_max_size":2048,"parallel_insert_workers":3}`)
+	require.NoError(t, err)
+	require.Equal(t, 10, config.relayLogMaxItems())
+	require.Equal(t, 2048, config.relayLogMaxSize())
+	require.Equal(t, 3, config.insertParallelism())
+
+	config, err = parseWorkflowConfig(`{"relay_log_max_items":`)
+	require.Error(t, err)
+	require.Equal(t, relayLogMaxItems, config.relayLogMaxItems())
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/vt/tableacl"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// The decisions of an ACL evaluation.
+const (
+	// aclAllowed means that the query is allowed by the table ACLs.
+	aclAllowed = "allowed"
+	// aclDenied means that the query is denied by the table ACLs.
+	aclDenied = "denied"
+	// aclNotEnforced means that the table ACLs deny the query, but that the
+	// tablet lets it pass because the table ACLs are not enforced, either
+	// because strict table ACLs are disabled, or because they are in dry run mode.
+	aclNotEnforced = "not_enforced"
+	// aclExempt means that the caller is exempted from the table ACL checks.
+	aclExempt = "exempt"
+)
+
+// aclRuleEvaluation is the evaluation of the table ACL rule that matches a
+// table of the query.
+type aclRuleEvaluation struct {
+	// Group is the table group of the matching rule.
+	Group string
+	// Rule is the table name or prefix of the matching rule, or empty if no
+	// rule matches the table, in which case all the callers are denied.
+	Rule    string
+	Allowed bool
+}
+
+// aclTableEvaluation is the evaluation of the access of the caller to a table
+// of the query.
+type aclTableEvaluation struct {
+	Table string
+	Role  string
+	aclRuleEvaluation
+	// DryRun is the evaluation of the dry run table ACL config, if one is loaded.
+	DryRun *aclRuleEvaluation `json:",omitempty"`
+}
+
+// aclEvaluation tells which table ACL rules match the query of a caller, and
+// the resulting decision.
+type aclEvaluation struct {
+	Username string
+	Groups   []string
+	Query    string
+	Tables   []aclTableEvaluation
+	Decision string
+	// DryRunDecision is the decision of the dry run table ACL config, if one is loaded.
+	DryRunDecision string `json:",omitempty"`
+}
+
+// evaluateACL evaluates the table ACLs for the query of the caller, the same
+// way the query executor checks them, without running the query.
+func (tsv *TabletServer) evaluateACL(callerID *querypb.VTGateCallerID, query string) (*aclEvaluation, error) {
+	stmt, err := tsv.qe.env.Environment().Parser().Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	eval := &aclEvaluation{
+		Username: callerID.Username,
+		Groups:   callerID.Groups,
+		Query:    query,
+		Tables:   []aclTableEvaluation{},
+		Decision: aclAllowed,
+	}
+	if tsv.qe.exemptACL != nil && tsv.qe.exemptACL.IsMember(callerID) {
+		eval.Decision = aclExempt
+		return eval, nil
+	}
+
+	dryRunAllowed := true
+	dryRunLoaded := false
+	for _, perm := range planbuilder.BuildPermissions(stmt) {
+		// The dummy dual table is never checked.
+		if perm.TableName == "dual" {
+			continue
+		}
+		authorized, rule := tableacl.Evaluate(perm.TableName, perm.Role)
+		table := aclTableEvaluation{
+			Table: perm.TableName,
+			Role:  perm.Role.Name(),
+			aclRuleEvaluation: aclRuleEvaluation{
+				Group:   authorized.GroupName,
+				Rule:    rule,
+				Allowed: authorized.IsMember(callerID),
+			},
+		}
+		if dryRunAuthorized, dryRunRule := tableacl.EvaluateDryRun(perm.TableName, perm.Role); dryRunAuthorized != nil {
+			dryRunLoaded = true
+			table.DryRun = &aclRuleEvaluation{
+				Group:   dryRunAuthorized.GroupName,
+				Rule:    dryRunRule,
+				Allowed: dryRunAuthorized.IsMember(callerID),
+			}
+			dryRunAllowed = dryRunAllowed && table.DryRun.Allowed
+		}
+		if !table.Allowed {
+			switch {
+			case tsv.qe.enableTableACLDryRun || !tsv.qe.strictTableACL:
+				if eval.Decision == aclAllowed {
+					eval.Decision = aclNotEnforced
+				}
+			default:
+				eval.Decision = aclDenied
+			}
+		}
+		eval.Tables = append(eval.Tables, table)
+	}
+	if dryRunLoaded {
+		eval.DryRunDecision = aclAllowed
+		if !dryRunAllowed {
+			eval.DryRunDecision = aclDenied
+		}
+	}
+	return eval, nil
+}
+
+// aclEvaluateHandler serves which table ACL rules would match the query of a
+// caller, given by its user and comma separated groups, and the resulting
+// decision of the table ACLs and of the dry run table ACLs.
+func aclEvaluateHandler(tsv *TabletServer, w http.ResponseWriter, r *http.Request) {
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+
+	query := r.FormValue("query")
+	if query == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+	callerID := &querypb.VTGateCallerID{Username: r.FormValue("user")}
+	if groups := r.FormValue("groups"); groups != "" {
+		callerID.Groups = strings.Split(groups, ",")
+	}
+	eval, err := tsv.evaluateACL(callerID, query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(eval)
+}
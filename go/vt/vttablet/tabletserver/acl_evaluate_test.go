@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/tableacl"
+	"vitess.io/vitess/go/vt/tableacl/simpleacl"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tableaclpb "vitess.io/vitess/go/vt/proto/tableacl"
+)
+
+func TestEvaluateACL(t *testing.T) {
+	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int64())
+	tableacl.Register(aclName, &simpleacl.Factory{})
+	tableacl.SetDefaultACL(aclName)
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+
+	err := tableacl.InitFromProto(&tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group01",
+			TableNamesOrPrefixes: []string{"test_table", "test_data%"},
+			Readers:              []string{"u1", "u2"},
+			Writers:              []string{"u1"},
+		}},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, enableStrictTableACL, db)
+	defer tsv.StopService()
+
+	eval, err := tsv.evaluateACL(&querypb.VTGateCallerID{Username: "u2"}, "insert into test_table select * from test_data_1")
+	require.NoError(t, err)
+	assert.Equal(t, aclDenied, eval.Decision)
+	assert.Empty(t, eval.DryRunDecision)
+	assert.Equal(t, []aclTableEvaluation{
+		{Table: "test_table", Role: "WRITER", aclRuleEvaluation: aclRuleEvaluation{Group: "group01", Rule: "test_table", Allowed: false}},
+		{Table: "test_data_1", Role: "READER", aclRuleEvaluation: aclRuleEvaluation{Group: "group01", Rule: "test_data%", Allowed: true}},
+	}, eval.Tables)
+
+	eval, err = tsv.evaluateACL(&querypb.VTGateCallerID{Username: "u1"}, "select * from unknown_table")
+	require.NoError(t, err)
+	assert.Equal(t, aclDenied, eval.Decision)
+	assert.Empty(t, eval.Tables[0].Rule)
+
+	// The denials aren't enforced without strict table ACLs.
+	tsv.qe.strictTableACL = false
+	eval, err = tsv.evaluateACL(&querypb.VTGateCallerID{Username: "u1"}, "select * from unknown_table")
+	require.NoError(t, err)
+	assert.Equal(t, aclNotEnforced, eval.Decision)
+	tsv.qe.strictTableACL = true
+
+	_, err = tsv.evaluateACL(&querypb.VTGateCallerID{Username: "u1"}, "selec * from test_table")
+	require.Error(t, err)
+
+	// A dry run config that no longer lets u2 read test_table.
+	err = tableacl.InitDryRunFromProto(&tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group02",
+			TableNamesOrPrefixes: []string{"test_table"},
+			Readers:              []string{"u1"},
+		}},
+	})
+	require.NoError(t, err)
+	defer tableacl.ClearDryRun()
+
+	eval, err = tsv.evaluateACL(&querypb.VTGateCallerID{Username: "u2"}, "select * from test_table")
+	require.NoError(t, err)
+	assert.Equal(t, aclAllowed, eval.Decision)
+	assert.Equal(t, aclDenied, eval.DryRunDecision)
+	assert.Equal(t, &aclRuleEvaluation{Group: "group02", Rule: "test_table", Allowed: false}, eval.Tables[0].DryRun)
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/debug/acl/evaluate?"+url.Values{
+		"user":   {"u3"},
+		"groups": {"g1,u1"},
+		"query":  {"select * from test_table"},
+	}.Encode(), nil)
+	require.NoError(t, err)
+	aclEvaluateHandler(tsv, resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	eval = &aclEvaluation{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), eval))
+	assert.Equal(t, []string{"g1", "u1"}, eval.Groups)
+	assert.Equal(t, aclAllowed, eval.Decision)
+	assert.Equal(t, aclAllowed, eval.DryRunDecision)
+
+	resp = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/debug/acl/evaluate?user=u1", nil)
+	require.NoError(t, err)
+	aclEvaluateHandler(tsv, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package callerroles maps the callers of vttablet to MySQL 8 roles.
+
+The immediate caller of a query, which is the user authenticated by vtgate,
+and its groups are mapped to MySQL roles. The roles are activated with SET ROLE
+on the pooled connection the query or transaction runs on, and reset to the
+connection's default roles when the connection goes back to the pool. This
+provides MySQL-level privilege separation behind vttablet's shared pools.
+
+The attributes of the effective caller can also be passed through to MySQL as
+user-defined variables, so that they can be used by triggers, views or audit
+plugins.
+
+The mapping is read from a JSON file:
+
+	{
+	  "users": {"alice": ["app_reader"]},
+	  "groups": {"admins": ["app_admin", "app_reader"]},
+	  "default_roles": ["app_reader"],
+	  "passthrough_attributes": true
+	}
+*/
+package callerroles
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The user-defined variables the effective caller attributes are passed through as.
+const (
+	PrincipalVariable    = "@vitess_caller_principal"
+	ComponentVariable    = "@vitess_caller_component"
+	SubcomponentVariable = "@vitess_caller_subcomponent"
+	UsernameVariable     = "@vitess_caller_username"
+)
+
+// Config is the mapping of callers to MySQL roles.
+type Config struct {
+	// Users maps the usernames of the immediate callers to roles.
+	Users map[string][]string `json:"users,omitempty"`
+	// Groups maps the groups of the immediate callers to roles.
+	Groups map[string][]string `json:"groups,omitempty"`
+	// DefaultRoles are activated for the callers that are not mapped to any role.
+	// When empty, such callers keep the default roles of vttablet's MySQL user.
+	DefaultRoles []string `json:"default_roles,omitempty"`
+	// PassthroughAttributes sets the effective caller attributes as user-defined
+	// variables on the connection. Since the connections of the pool are then
+	// specific to a caller, this lowers the reuse of pooled connections.
+	PassthroughAttributes bool `json:"passthrough_attributes,omitempty"`
+}
+
+// Queries are the statements that activate the roles and attributes of a caller
+// on a connection, and the ones that reset them.
+type Queries struct {
+	Apply []string
+	Reset []string
+}
+
+// Mapper maps callers to their roles and attributes.
+type Mapper struct {
+	config *Config
+}
+
+// NewMapper returns a Mapper for the given configuration.
+func NewMapper(config *Config) (*Mapper, error) {
+	check := func(roles []string) error {
+		for _, role := range roles {
+			if role == "" {
+				return fmt.Errorf("empty role name")
+			}
+		}
+		return nil
+	}
+	if err := check(config.DefaultRoles); err != nil {
+		return nil, fmt.Errorf("invalid default roles: %w", err)
+	}
+	for user, roles := range config.Users {
+		if err := check(roles); err != nil {
+			return nil, fmt.Errorf("invalid roles for user %s: %w", user, err)
+		}
+	}
+	for group, roles := range config.Groups {
+		if err := check(roles); err != nil {
+			return nil, fmt.Errorf("invalid roles for group %s: %w", group, err)
+		}
+	}
+	return &Mapper{config: config}, nil
+}
+
+// Load reads the configuration file at path and returns its Mapper.
+func Load(path string) (*Mapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read caller role mapping file %s: %w", path, err)
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("cannot parse caller role mapping file %s: %w", path, err)
+	}
+	return NewMapper(config)
+}
+
+// Roles returns the sorted roles of the immediate caller, which are the roles
+// of its username and of all its groups, or the default roles if there are none.
+func (m *Mapper) Roles(immediate *querypb.VTGateCallerID) []string {
+	set := make(map[string]bool)
+	if immediate != nil {
+		for _, role := range m.config.Users[immediate.Username] {
+			set[role] = true
+		}
+		for _, group := range immediate.Groups {
+			for _, role := range m.config.Groups[group] {
+				set[role] = true
+			}
+		}
+	}
+	if len(set) == 0 {
+		return m.config.DefaultRoles
+	}
+	roles := make([]string, 0, len(set))
+	for role := range set {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Queries returns the queries that activate the roles and attributes of the
+// caller, or nil if nothing needs to be changed on the connection.
+func (m *Mapper) Queries(effective *vtrpcpb.CallerID, immediate *querypb.VTGateCallerID) *Queries {
+	var apply, reset []string
+	if roles := m.Roles(immediate); len(roles) > 0 {
+		specs := make([]string, 0, len(roles))
+		for _, role := range roles {
+			specs = append(specs, roleSpec(role))
+		}
+		apply = append(apply, "set role "+strings.Join(specs, ", "))
+		reset = append(reset, "set role default")
+	}
+	if m.config.PassthroughAttributes {
+		apply = append(apply, fmt.Sprintf("set %s = %s, %s = %s, %s = %s, %s = %s",
+			PrincipalVariable, sqltypes.EncodeStringSQL(effective.GetPrincipal()),
+			ComponentVariable, sqltypes.EncodeStringSQL(effective.GetComponent()),
+			SubcomponentVariable, sqltypes.EncodeStringSQL(effective.GetSubcomponent()),
+			UsernameVariable, sqltypes.EncodeStringSQL(immediate.GetUsername()),
+		))
+		reset = append(reset, fmt.Sprintf("set %s = null, %s = null, %s = null, %s = null",
+			PrincipalVariable, ComponentVariable, SubcomponentVariable, UsernameVariable))
+	}
+	if len(apply) == 0 {
+		return nil
+	}
+	return &Queries{Apply: apply, Reset: reset}
+}
+
+// roleSpec returns the quoted role name for the given role, which is either
+// a name, or a name and a host separated by @.
+func roleSpec(role string) string {
+	if name, host, ok := strings.Cut(role, "@"); ok {
+		return sqltypes.EncodeStringSQL(name) + "@" + sqltypes.EncodeStringSQL(host)
+	}
+	return sqltypes.EncodeStringSQL(role)
+}
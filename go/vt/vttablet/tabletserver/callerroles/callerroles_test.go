@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callerroles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestRoles(t *testing.T) {
+	mapper, err := NewMapper(&Config{
+		Users:  map[string][]string{"alice": {"reader"}},
+		Groups: map[string][]string{"admins": {"admin", "reader"}, "ops": {"ops@localhost"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"reader"}, mapper.Roles(&querypb.VTGateCallerID{Username: "alice"}))
+	assert.Equal(t, []string{"admin", "ops@localhost", "reader"}, mapper.Roles(&querypb.VTGateCallerID{Username: "alice", Groups: []string{"admins", "ops"}}))
+	assert.Empty(t, mapper.Roles(&querypb.VTGateCallerID{Username: "bob"}))
+	assert.Empty(t, mapper.Roles(nil))
+	assert.Nil(t, mapper.Queries(nil, &querypb.VTGateCallerID{Username: "bob"}))
+
+	queries := mapper.Queries(nil, &querypb.VTGateCallerID{Username: "carol", Groups: []string{"ops"}})
+	assert.Equal(t, &Queries{
+		Apply: []string{"set role 'ops'@'localhost'"},
+		Reset: []string{"set role default"},
+	}, queries)
+
+	mapper, err = NewMapper(&Config{DefaultRoles: []string{"nobody"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nobody"}, mapper.Roles(&querypb.VTGateCallerID{Username: "bob"}))
+
+	_, err = NewMapper(&Config{Groups: map[string][]string{"admins": {""}}})
+	assert.ErrorContains(t, err, "invalid roles for group admins")
+}
+
+func TestPassthroughAttributes(t *testing.T) {
+	mapper, err := NewMapper(&Config{PassthroughAttributes: true})
+	require.NoError(t, err)
+
+	queries := mapper.Queries(&vtrpcpb.CallerID{Principal: "o'brien", Component: "api"}, &querypb.VTGateCallerID{Username: "app"})
+	assert.Equal(t, &Queries{
+		Apply: []string{"set @vitess_caller_principal = 'o\\'brien', @vitess_caller_component = 'api', @vitess_caller_subcomponent = '', @vitess_caller_username = 'app'"},
+		Reset: []string{"set @vitess_caller_principal = null, @vitess_caller_component = null, @vitess_caller_subcomponent = null, @vitess_caller_username = null"},
+	}, queries)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "roles.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"users": {"alice": ["reader"]}, "passthrough_attributes": true}`), 0600))
+	mapper, err := Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reader"}, mapper.Roles(&querypb.VTGateCallerID{Username: "alice"}))
+	assert.True(t, mapper.config.PassthroughAttributes)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"users": `), 0600))
+	_, err = Load(file)
+	assert.ErrorContains(t, err, "cannot parse caller role mapping file")
+
+	_, err = Load(filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "cannot read caller role mapping file")
+}
@@ -157,6 +157,23 @@ func (dbc *Conn) Exec(ctx context.Context, query string, maxrows int, wantfields
 }
 
 func (dbc *Conn) execOnce(ctx context.Context, query string, maxrows int, wantfields bool) (*sqltypes.Result, error) {
+	return dbc.execOnceWith(ctx, query, func() (*sqltypes.Result, error) {
+		return dbc.conn.ExecuteFetch(query, maxrows, wantfields)
+	})
+}
+
+// execMultiOnce executes all the statements of the specified query, discarding
+// their results, but does not retry on connection errors.
+func (dbc *Conn) execMultiOnce(ctx context.Context, query string) error {
+	_, err := dbc.execOnceWith(ctx, query, func() (*sqltypes.Result, error) {
+		return nil, dbc.conn.ExecuteFetchMultiDrain(query)
+	})
+	return err
+}
+
+// execOnceWith runs fetch, which executes query, and kills the query if ctx is
+// done before it completes.
+func (dbc *Conn) execOnceWith(ctx context.Context, query string, fetch func() (*sqltypes.Result, error)) (*sqltypes.Result, error) {
 	dbc.current.Store(&query)
 	defer dbc.current.Store(nil)
 
@@ -176,7 +193,7 @@ func (dbc *Conn) execOnce(ctx context.Context, query string, maxrows int, wantfi
 
 	ch := make(chan execResult)
 	go func() {
-		result, err := dbc.conn.ExecuteFetch(query, maxrows, wantfields)
+		result, err := fetch()
 		ch <- execResult{result, err}
 	}()
 
@@ -363,8 +380,9 @@ func (dbc *Conn) Close() {
 }
 
 // ApplySetting implements the pools.Resource interface.
+// The setting queries can be made of several statements.
 func (dbc *Conn) ApplySetting(ctx context.Context, setting *smartconnpool.Setting) error {
-	if _, err := dbc.execOnce(ctx, setting.ApplyQuery(), 1, false); err != nil {
+	if err := dbc.execMultiOnce(ctx, setting.ApplyQuery()); err != nil {
 		return err
 	}
 	dbc.setting = setting
@@ -373,7 +391,7 @@ func (dbc *Conn) ApplySetting(ctx context.Context, setting *smartconnpool.Settin
 
 // ResetSetting implements the pools.Resource interface.
 func (dbc *Conn) ResetSetting(ctx context.Context) error {
-	if _, err := dbc.execOnce(ctx, dbc.setting.ResetQuery(), 1, false); err != nil {
+	if err := dbc.execMultiOnce(ctx, dbc.setting.ResetQuery()); err != nil {
 		return err
 	}
 	dbc.setting = nil
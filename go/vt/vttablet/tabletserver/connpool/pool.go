@@ -62,6 +62,10 @@ type Pool struct {
 
 	appDebugParams dbconfigs.Connector
 	getConnTime    *servenv.TimingsWrapper
+
+	// unregisterRotation stops expiring the connections when the credentials
+	// are rotated, once the pool is closed.
+	unregisterRotation func()
 }
 
 // NewPool creates a new Pool. The name is used
@@ -77,6 +81,7 @@ func NewPool(env tabletenv.Env, name string, cfg tabletenv.ConnPoolConfig) *Pool
 		IdleTimeout:     cfg.IdleTimeout,
 		MaxLifetime:     cfg.MaxLifetime,
 		RefreshInterval: mysqlctl.PoolDynamicHostnameResolution,
+		MaxWaiters:      int64(cfg.MaxWaiters),
 	}
 
 	if name != "" {
@@ -110,11 +115,18 @@ func (cp *Pool) Open(appParams, dbaParams, appDebugParams dbconfigs.Connector) {
 
 	cp.ConnPool.Open(connect, refresh)
 	cp.dbaPool.Open(dbaParams)
+	if cp.unregisterRotation == nil {
+		cp.unregisterRotation = dbconfigs.OnCredentialsRotated(cp.ConnPool.ExpireConnections)
+	}
 }
 
 // Close will close the pool and wait for connections to be returned before
 // exiting.
 func (cp *Pool) Close() {
+	if cp.unregisterRotation != nil {
+		cp.unregisterRotation()
+		cp.unregisterRotation = nil
+	}
 	cp.ConnPool.Close()
 	cp.dbaPool.Close()
 }
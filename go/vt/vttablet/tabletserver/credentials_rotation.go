@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// credentialsRotation rotates the MySQL credentials of the tablet at runtime,
+// without restarting it.
+type credentialsRotation struct {
+	rotations *stats.CountersWithSingleLabel
+
+	mu        sync.Mutex
+	lastTime  time.Time
+	lastError string
+}
+
+func newCredentialsRotation(exporter *servenv.Exporter) *credentialsRotation {
+	return &credentialsRotation{
+		rotations: exporter.NewCountersWithSingleLabel("CredentialsRotations", "Rotations of the MySQL credentials, by result", "Result"),
+	}
+}
+
+// CredentialsRotationStatus is the status of the last rotation of the MySQL credentials.
+type CredentialsRotationStatus struct {
+	LastRotation time.Time `json:",omitempty"`
+	LastError    string    `json:",omitempty"`
+}
+
+func (cr *credentialsRotation) record(err error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.lastTime = time.Now()
+	cr.lastError = ""
+	if err != nil {
+		cr.lastError = err.Error()
+		cr.rotations.Add("Failed", 1)
+		return
+	}
+	cr.rotations.Add("Succeeded", 1)
+}
+
+func (cr *credentialsRotation) status() CredentialsRotationStatus {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return CredentialsRotationStatus{LastRotation: cr.lastTime, LastError: cr.lastError}
+}
+
+// RotateCredentials loads the MySQL credentials again from the credentials
+// server, e.g. the credentials file or Vault, and only uses them once the app,
+// dba and repl users could connect with them. It then expires the pooled
+// connections, which are re-established gradually with the new credentials,
+// and the tablet manager configures the replication source again with the new
+// repl credentials. If the new credentials don't work, the previous ones are
+// kept, and so are the pooled connections.
+func (tsv *TabletServer) RotateCredentials(ctx context.Context) error {
+	err := tsv.rotateCredentials(ctx)
+	tsv.credentialsRotation.record(err)
+	if err != nil {
+		log.Errorf("Failed to rotate the MySQL credentials: %v", err)
+		return err
+	}
+	log.Infof("Rotated the MySQL credentials")
+	return nil
+}
+
+func (tsv *TabletServer) rotateCredentials(ctx context.Context) error {
+	if tsv.config.DB == nil {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the tablet server is not initialized")
+	}
+	err := dbconfigs.ReloadCredentials(func(candidate dbconfigs.CredentialsServer) error {
+		for _, user := range []struct {
+			name      string
+			connector dbconfigs.Connector
+		}{
+			{"app", tsv.config.DB.AppWithDB()},
+			{"dba", tsv.config.DB.DbaWithDB()},
+			{"repl", tsv.config.DB.ReplConnector()},
+		} {
+			connector := user.connector.WithCredentialsServer(candidate)
+			conn, err := connector.Connect(ctx)
+			if err != nil {
+				return vterrors.Wrapf(err, "failed to connect with the new %s credentials", user.name)
+			}
+			conn.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	dbconfigs.CredentialsRotated()
+	return nil
+}
+
+func (tsv *TabletServer) registerCredentialsRotationHandler() {
+	tsv.exporter.HandleFunc("/debug/rotate_credentials", func(w http.ResponseWriter, r *http.Request) {
+		credentialsRotationHandler(tsv, w, r)
+	})
+}
+
+// credentialsRotationHandler shows the status of the last rotation of the
+// MySQL credentials, and rotates them on POST.
+func credentialsRotationHandler(tsv *TabletServer, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(tsv.credentialsRotation.status())
+		return
+	}
+
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	if err := tsv.RotateCredentials(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tsv.credentialsRotation.status())
+}
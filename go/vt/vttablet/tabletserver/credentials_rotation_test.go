@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestRotateCredentials(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+
+	// Open a pooled connection.
+	ctx = callerid.NewContext(ctx, nil, callerid.NewImmediateCallerID("user"))
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+	db.AddQuery("select 1 from dual limit 10001", &sqltypes.Result{})
+	_, err := tsv.Execute(ctx, &target, "select 1 from dual", nil, 0, 0, nil)
+	require.NoError(t, err)
+	require.NotZero(t, tsv.qe.conns.Active())
+	expired := tsv.qe.conns.Metrics.ExpiredClosed()
+
+	// The pooled connections are kept when the new credentials don't work.
+	db.EnableConnFail()
+	err = tsv.RotateCredentials(ctx)
+	require.ErrorContains(t, err, "failed to connect with the new app credentials")
+	require.Equal(t, expired, tsv.qe.conns.Metrics.ExpiredClosed())
+	require.Contains(t, tsv.credentialsRotation.status().LastError, "failed to connect with the new app credentials")
+	db.DisableConnFail()
+
+	// Otherwise they are expired, and reopened on demand.
+	require.NoError(t, tsv.RotateCredentials(ctx))
+	require.Greater(t, tsv.qe.conns.Metrics.ExpiredClosed(), expired)
+	require.Empty(t, tsv.credentialsRotation.status().LastError)
+	_, err = tsv.Execute(ctx, &target, "select 1 from dual", nil, 0, 0, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, tsv.credentialsRotation.rotations.Counts()["Failed"])
+	require.EqualValues(t, 1, tsv.credentialsRotation.rotations.Counts()["Succeeded"])
+}
+
+func TestCredentialsRotationHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/rotate_credentials", nil)
+	w := httptest.NewRecorder()
+	credentialsRotationHandler(tsv, w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var status CredentialsRotationStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.True(t, status.LastRotation.IsZero())
+
+	req = httptest.NewRequest(http.MethodPost, "/debug/rotate_credentials", nil)
+	w = httptest.NewRecorder()
+	credentialsRotationHandler(tsv, w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.False(t, status.LastRotation.IsZero())
+	require.Empty(t, status.LastError)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -204,7 +205,7 @@ func (hs *healthStreamer) unregister(ch chan *querypb.StreamHealthResponse) {
 	delete(hs.clients, ch)
 }
 
-func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, ptsTimestamp time.Time, lag time.Duration, err error, serving bool) {
+func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, ptsTimestamp time.Time, lag time.Duration, signals *querypb.ReplicationLagSignals, err error, serving bool) {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
 
@@ -220,7 +221,10 @@ func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, ptsTimes
 		hs.state.RealtimeStats.HealthError = ""
 	}
 	hs.state.RealtimeStats.ReplicationLagSeconds = uint32(lag.Seconds())
+	hs.state.RealtimeStats.ReplicationLagSignals = signals
+	hs.state.RealtimeStats.FreshnessScore = freshnessScore(tabletType, lag, signals, err, hs.degradedThreshold)
 	hs.state.Serving = serving
+	hs.recordReplicationLagSignals()
 
 	hs.state.RealtimeStats.FilteredReplicationLagSeconds, hs.state.RealtimeStats.BinlogPlayersCount = blpFunc()
 	hs.state.RealtimeStats.Qps = hs.stats.QPSRates.TotalRate()
@@ -235,6 +239,54 @@ func (hs *healthStreamer) ChangeState(tabletType topodatapb.TabletType, ptsTimes
 	})
 }
 
+// freshnessScore combines the replication lag signals into a score between 0 and 1.
+// It goes down linearly with the worst of the lags, from 1 when the replica is caught
+// up to 0 when it lags the degraded threshold or more. A replica whose replication
+// is broken scores 0, and a primary always scores 1.
+func freshnessScore(tabletType topodatapb.TabletType, lag time.Duration, signals *querypb.ReplicationLagSignals, err error, degradedThreshold time.Duration) float64 {
+	if tabletType == topodatapb.TabletType_PRIMARY {
+		return 1
+	}
+	if err != nil || degradedThreshold <= 0 {
+		return 0
+	}
+	worst := lag
+	for _, signal := range lagSignalsByName(signals) {
+		if signal != nil {
+			worst = max(worst, time.Duration(*signal)*time.Second)
+		}
+	}
+	if worst >= degradedThreshold {
+		return 0
+	}
+	return 1 - float64(worst)/float64(degradedThreshold)
+}
+
+// recordReplicationLagSignals exports the replication lag signals and the freshness score of
+// the current state. Signals that are not measured are reported as -1.
+func (hs *healthStreamer) recordReplicationLagSignals() {
+	rs := hs.state.RealtimeStats
+	for name, signal := range lagSignalsByName(rs.ReplicationLagSignals) {
+		value := int64(-1)
+		if signal != nil {
+			value = int64(*signal)
+		}
+		hs.stats.ReplicationLagSignals.Set(name, value)
+	}
+	hs.stats.FreshnessPercent.Set(int64(math.Round(rs.FreshnessScore * 100)))
+}
+
+// lagSignalsByName returns the replication lag signals keyed by the name they are exported with.
+func lagSignalsByName(signals *querypb.ReplicationLagSignals) map[string]*uint32 {
+	if signals == nil {
+		signals = &querypb.ReplicationLagSignals{}
+	}
+	return map[string]*uint32{
+		"SQLThread": signals.SqlThreadLagSeconds,
+		"Heartbeat": signals.HeartbeatLagSeconds,
+	}
+}
+
 func (hs *healthStreamer) broadCastToClients(shr *querypb.StreamHealthResponse) {
 	for ch := range hs.clients {
 		select {
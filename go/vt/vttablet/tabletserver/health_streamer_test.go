@@ -128,7 +128,7 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 	}
 	assert.Truef(t, proto.Equal(want, shr), "want: %v, got: %v", want, shr)
 
-	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 0, nil, false)
+	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 0, nil, nil, false)
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -138,13 +138,14 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 		RealtimeStats: &querypb.RealtimeStats{
 			FilteredReplicationLagSeconds: 1,
 			BinlogPlayersCount:            2,
+			FreshnessScore:                1,
 		},
 	}
 	assert.Truef(t, proto.Equal(want, shr), "want: %v, got: %v", want, shr)
 
 	// Test primary and timestamp.
 	now := time.Now()
-	hs.ChangeState(topodatapb.TabletType_PRIMARY, now, 0, nil, true)
+	hs.ChangeState(topodatapb.TabletType_PRIMARY, now, 0, nil, nil, true)
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -156,12 +157,13 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 		RealtimeStats: &querypb.RealtimeStats{
 			FilteredReplicationLagSeconds: 1,
 			BinlogPlayersCount:            2,
+			FreshnessScore:                1,
 		},
 	}
 	assert.Truef(t, proto.Equal(want, shr), "want: %v, got: %v", want, shr)
 
 	// Test non-serving, and 0 timestamp for non-primary.
-	hs.ChangeState(topodatapb.TabletType_REPLICA, now, 1*time.Second, nil, false)
+	hs.ChangeState(topodatapb.TabletType_REPLICA, now, 1*time.Second, nil, nil, false)
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -172,12 +174,13 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 			ReplicationLagSeconds:         1,
 			FilteredReplicationLagSeconds: 1,
 			BinlogPlayersCount:            2,
+			FreshnessScore:                1 - 1.0/30,
 		},
 	}
 	assert.Truef(t, proto.Equal(want, shr), "want: %v, got: %v", want, shr)
 
 	// Test Health error.
-	hs.ChangeState(topodatapb.TabletType_REPLICA, now, 0, errors.New("repl err"), false)
+	hs.ChangeState(topodatapb.TabletType_REPLICA, now, 0, nil, errors.New("repl err"), false)
 	shr = <-ch
 	want = &querypb.StreamHealthResponse{
 		Target: &querypb.Target{
@@ -193,6 +196,73 @@ func TestHealthStreamerBroadcast(t *testing.T) {
 	assert.Truef(t, proto.Equal(want, shr), "want: %v, got: %v", want, shr)
 }
 
+func TestHealthStreamerReplicationLagSignals(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	cfg := newConfig(db)
+	cfg.SignalWhenSchemaChange = false
+
+	env := tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "ReplTrackerTest")
+	alias := &topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	blpFunc = testBlpFunc
+	hs := newHealthStreamer(env, alias, &schema.Engine{})
+	hs.InitDBConfig(&querypb.Target{}, dbconfigs.New(db.ConnParams()))
+	hs.Open()
+	defer hs.Close()
+
+	ch, cancel := testStream(hs)
+	defer cancel()
+	<-ch
+
+	signals := &querypb.ReplicationLagSignals{
+		SqlThreadLagSeconds: proto.Uint32(2),
+		HeartbeatLagSeconds: proto.Uint32(6),
+	}
+	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 2*time.Second, signals, nil, true)
+	shr := <-ch
+	assert.Truef(t, proto.Equal(signals, shr.RealtimeStats.ReplicationLagSignals), "got: %v", shr.RealtimeStats.ReplicationLagSignals)
+	assert.Equal(t, 1-6.0/30, shr.RealtimeStats.FreshnessScore)
+	assert.Equal(t, map[string]int64{"SQLThread": 2, "Heartbeat": 6}, env.Stats().ReplicationLagSignals.Counts())
+	assert.EqualValues(t, 80, env.Stats().FreshnessPercent.Get())
+
+	// Signals that are not measured are exported as -1.
+	hs.ChangeState(topodatapb.TabletType_REPLICA, time.Time{}, 3*time.Second, &querypb.ReplicationLagSignals{SqlThreadLagSeconds: proto.Uint32(3)}, nil, true)
+	shr = <-ch
+	assert.Nil(t, shr.RealtimeStats.ReplicationLagSignals.HeartbeatLagSeconds)
+	assert.Equal(t, 0.9, shr.RealtimeStats.FreshnessScore)
+	assert.Equal(t, map[string]int64{"SQLThread": 3, "Heartbeat": -1}, env.Stats().ReplicationLagSignals.Counts())
+	assert.EqualValues(t, 90, env.Stats().FreshnessPercent.Get())
+}
+
+func TestFreshnessScore(t *testing.T) {
+	heartbeat := func(seconds uint32) *querypb.ReplicationLagSignals {
+		return &querypb.ReplicationLagSignals{HeartbeatLagSeconds: proto.Uint32(seconds)}
+	}
+	tests := []struct {
+		name       string
+		tabletType topodatapb.TabletType
+		lag        time.Duration
+		signals    *querypb.ReplicationLagSignals
+		err        error
+		want       float64
+	}{
+		{name: "primary", tabletType: topodatapb.TabletType_PRIMARY, lag: time.Minute, want: 1},
+		{name: "caught up", tabletType: topodatapb.TabletType_REPLICA, want: 1},
+		{name: "reported lag", tabletType: topodatapb.TabletType_REPLICA, lag: 15 * time.Second, want: 0.5},
+		{name: "worst signal", tabletType: topodatapb.TabletType_REPLICA, lag: 3 * time.Second, signals: heartbeat(15), want: 0.5},
+		{name: "beyond threshold", tabletType: topodatapb.TabletType_RDONLY, signals: heartbeat(45), want: 0},
+		{name: "replication error", tabletType: topodatapb.TabletType_REPLICA, err: errors.New("replication is not running"), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, freshnessScore(tt.tabletType, tt.lag, tt.signals, tt.err, 30*time.Second))
+		})
+	}
+}
+
 func TestReloadSchema(t *testing.T) {
 	testcases := []struct {
 		name               string
@@ -30,7 +30,7 @@ import (
 	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/stats"
-	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
@@ -177,8 +177,8 @@ type messageManager struct {
 	minBackoff   time.Duration
 	maxBackoff   time.Duration
 	batchSize    int
-	pollerTicks  *timer.Timer
-	purgeTicks   *timer.Timer
+	pollerTicks  *tabletenv.IdleTimer
+	purgeTicks   *tabletenv.IdleTimer
 	postponeSema *semaphore.Weighted
 
 	mu     sync.Mutex
@@ -255,8 +255,8 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 		maxBackoff:      table.MessageInfo.MaxBackoff,
 		batchSize:       table.MessageInfo.BatchSize,
 		cache:           newCache(table.MessageInfo.CacheSize),
-		pollerTicks:     timer.NewTimer(table.MessageInfo.PollInterval),
-		purgeTicks:      timer.NewTimer(table.MessageInfo.PollInterval),
+		pollerTicks:     tsv.Activity().NewTimer("MessagePoller", table.MessageInfo.PollInterval),
+		purgeTicks:      tsv.Activity().NewTimer("MessagePurge", table.MessageInfo.PollInterval),
 		postponeSema:    postponeSema,
 		messagesPending: true,
 	}
@@ -626,6 +626,12 @@ func (mm *messageManager) send(ctx context.Context, receiver *receiverWithStatus
 	return mm.postpone(ctx, mm.tsv, mm.ackWaitTime, ids)
 }
 
+// internalContext returns the context of the queries that the message manager
+// generates for itself, which are excluded from the per-user and per-table metrics.
+func internalContext() context.Context {
+	return callerid.NewInternalContext(tabletenv.LocalContext(), "Messager")
+}
+
 func (mm *messageManager) postpone(ctx context.Context, tsv TabletService, ackWaitTime time.Duration, ids []string) error {
 	// Use the semaphore to limit parallelism.
 	if err := mm.postponeSema.Acquire(ctx, 1); err != nil {
@@ -633,7 +639,7 @@ func (mm *messageManager) postpone(ctx context.Context, tsv TabletService, ackWa
 		return err
 	}
 	defer mm.postponeSema.Release(1)
-	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), ackWaitTime)
+	ctx, cancel := context.WithTimeout(internalContext(), ackWaitTime)
 	defer cancel()
 	if _, err := tsv.PostponeMessages(ctx, nil, mm, ids); err != nil {
 		// This can happen during spikes. Record the incident for monitoring.
@@ -784,7 +790,7 @@ func (mm *messageManager) runPoller() {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), mm.pollerTicks.Interval())
+	ctx, cancel := context.WithTimeout(internalContext(), mm.pollerTicks.Interval())
 	defer func() {
 		mm.tsv.LogError()
 		cancel()
@@ -827,7 +833,7 @@ func (mm *messageManager) runPoller() {
 
 func (mm *messageManager) runPurge() {
 	go func() {
-		ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), mm.purgeTicks.Interval())
+		ctx, cancel := context.WithTimeout(internalContext(), mm.purgeTicks.Interval())
 		defer func() {
 			mm.tsv.LogError()
 			cancel()
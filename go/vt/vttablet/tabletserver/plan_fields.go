@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// cachedFields is the result of the field query of a plan, as returned by
+// MySQL for a schema epoch.
+type cachedFields struct {
+	epoch  uint32
+	fields []*querypb.Field
+}
+
+// fieldsCacheable returns true if the statement is a field query whose
+// result only depends on the schema: the types of the bind variables
+// could change the types of the columns.
+func fieldsCacheable(planID planbuilder.PlanType, statement sqlparser.Statement) bool {
+	if planID != planbuilder.PlanSelectImpossible {
+		return false
+	}
+	hasArguments := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.Argument, sqlparser.ListArg:
+			hasArguments = true
+			return false, nil
+		}
+		return true, nil
+	}, statement)
+	return !hasArguments
+}
+
+// cachedFieldsResult returns a copy of the cached result of the field
+// query of the plan, or nil if it isn't cached for the schema epoch.
+func (ep *TabletPlan) cachedFieldsResult(epoch uint32) *sqltypes.Result {
+	cached := ep.fields.Load()
+	if cached == nil || cached.epoch != epoch {
+		return nil
+	}
+	// The fields are modified by the callers, so each gets its own copy.
+	fields := make([]*querypb.Field, 0, len(cached.fields))
+	for _, field := range cached.fields {
+		fields = append(fields, field.CloneVT())
+	}
+	return &sqltypes.Result{Fields: fields}
+}
+
+// cacheFieldsResult caches the result of the field query of the plan
+// for the schema epoch.
+func (ep *TabletPlan) cacheFieldsResult(epoch uint32, result *sqltypes.Result) {
+	fields := make([]*querypb.Field, 0, len(result.Fields))
+	for _, field := range result.Fields {
+		fields = append(fields, field.CloneVT())
+	}
+	ep.fields.Store(&cachedFields{epoch: epoch, fields: fields})
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+)
+
+func TestFieldsCacheable(t *testing.T) {
+	testcases := []struct {
+		query     string
+		planID    planbuilder.PlanType
+		cacheable bool
+	}{
+		{"select a, b from t where 1 != 1", planbuilder.PlanSelectImpossible, true},
+		{"select a, b from t join u on t.id = u.id where 1 != 1", planbuilder.PlanSelectImpossible, true},
+		{"select :v, b from t where 1 != 1", planbuilder.PlanSelectImpossible, false},
+		{"select a from t where a in ::list and 1 != 1", planbuilder.PlanSelectImpossible, false},
+		{"select a, b from t", planbuilder.PlanSelect, false},
+	}
+	parser := sqlparser.NewTestParser()
+	for _, tcase := range testcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tcase.query)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.cacheable, fieldsCacheable(tcase.planID, stmt))
+		})
+	}
+}
+
+func TestQueryExecutorFieldCache(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	fieldQuery := "select * from t where 1 != 1 limit 10001"
+	fields := sqltypes.MakeTestFields("a|b", "int64|varchar")
+	fields[0].Database = "db"
+	db.AddQuery(fieldQuery, sqltypes.MakeTestResult(fields))
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, enableFieldCache, db)
+	defer tsv.StopService()
+	plan := newTestQueryExecutor(ctx, tsv, "select * from t where 1 != 1", 0).plan
+	require.True(t, plan.fieldsCacheable)
+
+	execute := func() *sqltypes.Result {
+		qre := newTestQueryExecutor(ctx, tsv, "select * from t where 1 != 1", 0)
+		qre.plan = plan
+		qr, err := qre.Execute()
+		require.NoError(t, err)
+		return qr
+	}
+	hits, misses := tsv.qe.fieldCacheHits.Get(), tsv.qe.fieldCacheMisses.Get()
+
+	// The fields are only queried once.
+	qr := execute()
+	assert.Equal(t, fields, qr.Fields)
+	qr.Fields[0].Database = "ks"
+	qr = execute()
+	assert.Equal(t, fields, qr.Fields)
+	assert.Equal(t, 1, db.GetQueryCalledNum(fieldQuery))
+	assert.EqualValues(t, hits+1, tsv.qe.fieldCacheHits.Get())
+	assert.EqualValues(t, misses+1, tsv.qe.fieldCacheMisses.Get())
+
+	// A schema change invalidates the cached fields.
+	tsv.qe.schemaChanged(map[string]*schema.Table{}, nil, []*schema.Table{{Name: sqlparser.NewIdentifierCS("t")}}, nil)
+	execute()
+	assert.Equal(t, 2, db.GetQueryCalledNum(fieldQuery))
+}
+
+func TestQueryExecutorFieldCacheDisabled(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	fieldQuery := "select * from t where 1 != 1 limit 10001"
+	db.AddQuery(fieldQuery, sqltypes.MakeTestResult(sqltypes.MakeTestFields("a|b", "int64|varchar")))
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	qre := newTestQueryExecutor(ctx, tsv, "select * from t where 1 != 1", 0)
+	assert.False(t, qre.plan.fieldsCacheable)
+	for i := 0; i < 2; i++ {
+		qre := newTestQueryExecutor(ctx, tsv, "select * from t where 1 != 1", 0)
+		_, err := qre.Execute()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, db.GetQueryCalledNum(fieldQuery))
+}
@@ -178,6 +178,10 @@ type Plan struct {
 
 	// NeedsReservedConn indicates at a reserved connection is needed to execute this plan
 	NeedsReservedConn bool
+
+	// UnsafeReason is set for DMLs that are unsafe for statement-based
+	// replication, and says why.
+	UnsafeReason string
 }
 
 // TableName returns the table name for the plan.
@@ -258,6 +262,7 @@ func Build(env *vtenv.Environment, statement sqlparser.Statement, tables map[str
 		return nil, err
 	}
 	plan.Permissions = BuildPermissions(statement)
+	plan.UnsafeReason = unsafeReason(statement, plan.Table)
 	return plan, nil
 }
 
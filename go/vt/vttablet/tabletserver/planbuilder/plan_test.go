@@ -48,12 +48,14 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 		NextCount         string                 `json:",omitempty"`
 		WhereClause       *sqlparser.ParsedQuery `json:",omitempty"`
 		NeedsReservedConn bool                   `json:",omitempty"`
+		UnsafeReason      string                 `json:",omitempty"`
 	}{
-		PlanID:      p.PlanID,
-		TableName:   p.TableName(),
-		Permissions: p.Permissions,
-		FullQuery:   p.FullQuery,
-		WhereClause: p.WhereClause,
+		PlanID:       p.PlanID,
+		TableName:    p.TableName(),
+		Permissions:  p.Permissions,
+		FullQuery:    p.FullQuery,
+		WhereClause:  p.WhereClause,
+		UnsafeReason: p.UnsafeReason,
 	}
 	if p.NextCount != nil {
 		mplan.NextCount = sqlparser.String(p.NextCount)
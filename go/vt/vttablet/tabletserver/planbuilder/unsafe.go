@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+)
+
+// nonDeterministicFuncs are the functions whose result can differ between
+// the primary and the replicas, or the vreplication streams, when the
+// statement is replicated as is.
+var nonDeterministicFuncs = map[string]bool{
+	"uuid":            true,
+	"uuid_short":      true,
+	"rand":            true,
+	"user":            true,
+	"current_user":    true,
+	"session_user":    true,
+	"system_user":     true,
+	"found_rows":      true,
+	"row_count":       true,
+	"load_file":       true,
+	"sleep":           true,
+	"version":         true,
+	"master_pos_wait": true,
+	"source_pos_wait": true,
+	"connection_id":   true,
+}
+
+// unsafeReason returns why the DML is unsafe for statement-based
+// replication, or an empty string if it is safe. table is the table of
+// single-table DMLs, if known.
+func unsafeReason(stmt sqlparser.Statement, table *schema.Table) string {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Update:
+		if stmt.Limit != nil && len(stmt.TableExprs) == 1 {
+			if reason := unsafeLimitReason(stmt.OrderBy, table); reason != "" {
+				return reason
+			}
+		}
+	case *sqlparser.Delete:
+		if stmt.Limit != nil && len(stmt.TableExprs) == 1 {
+			if reason := unsafeLimitReason(stmt.OrderBy, table); reason != "" {
+				return reason
+			}
+		}
+	case *sqlparser.Insert:
+		if reason := unsafeInsertRowsReason(stmt.Rows); reason != "" {
+			return reason
+		}
+	default:
+		return ""
+	}
+	return nonDeterministicFuncReason(stmt)
+}
+
+// unsafeLimitReason returns why a LIMIT with the ORDER BY can change
+// different rows on the replicas: the rows are only in the same order if
+// they are sorted by the primary key.
+func unsafeLimitReason(orderBy sqlparser.OrderBy, table *schema.Table) string {
+	if len(orderBy) == 0 {
+		return "LIMIT without ORDER BY"
+	}
+	if table == nil {
+		return ""
+	}
+	if !table.HasPrimary() {
+		return fmt.Sprintf("LIMIT on table %s without a primary key", table.Name.String())
+	}
+	ordered := make(map[string]bool, len(orderBy))
+	for _, order := range orderBy {
+		if col, ok := order.Expr.(*sqlparser.ColName); ok {
+			ordered[col.Name.Lowered()] = true
+		}
+	}
+	for _, pk := range table.PKColumns {
+		// The columns of the table aren't always known, e.g. while the
+		// schema is loading: any ORDER BY is then given the benefit of the doubt.
+		if pk >= len(table.Fields) {
+			return ""
+		}
+		name := sqlparser.NewIdentifierCI(table.Fields[pk].Name)
+		if !ordered[name.Lowered()] {
+			return fmt.Sprintf("LIMIT with an ORDER BY that doesn't include the primary key column %s", name.String())
+		}
+	}
+	return ""
+}
+
+// unsafeInsertRowsReason returns why the rows of an INSERT ... SELECT can
+// differ on the replicas.
+func unsafeInsertRowsReason(rows sqlparser.InsertRows) string {
+	sel, ok := rows.(sqlparser.SelectStatement)
+	if !ok {
+		return ""
+	}
+	if sel.GetLimit() != nil && len(sel.GetOrderBy()) == 0 {
+		return "INSERT ... SELECT with LIMIT without ORDER BY"
+	}
+	return ""
+}
+
+// nonDeterministicFuncReason returns why the statement is unsafe if it
+// calls a non-deterministic function.
+func nonDeterministicFuncReason(stmt sqlparser.Statement) string {
+	var reason string
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.FuncExpr:
+			if nonDeterministicFuncs[node.Name.Lowered()] {
+				reason = fmt.Sprintf("non-deterministic function %s()", node.Name.Lowered())
+			}
+		case *sqlparser.CurTimeFuncExpr:
+			if node.Name.Lowered() == "sysdate" {
+				reason = "non-deterministic function sysdate()"
+			}
+		case *sqlparser.LockingFunc:
+			reason = fmt.Sprintf("non-deterministic function %s()", node.Type.ToString())
+		}
+		return reason == "", nil
+	}, stmt)
+	return reason
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestUnsafeReason(t *testing.T) {
+	table := schema.NewTable("t", schema.NoType)
+	table.Fields = []*querypb.Field{{Name: "id1"}, {Name: "id2"}, {Name: "val"}}
+	table.PKColumns = []int{0, 1}
+
+	tcases := []struct {
+		query string
+		want  string
+	}{{
+		query: "update t set val = 1 where val = 2",
+	}, {
+		query: "update t set val = 1 limit 1",
+		want:  "LIMIT without ORDER BY",
+	}, {
+		query: "update t set val = 1 order by val limit 1",
+		want:  "LIMIT with an ORDER BY that doesn't include the primary key column id1",
+	}, {
+		query: "delete from t order by id1 limit 1",
+		want:  "LIMIT with an ORDER BY that doesn't include the primary key column id2",
+	}, {
+		query: "delete from t order by ID2 desc, id1 limit 1",
+	}, {
+		query: "update t set val = rand() where id1 = 1",
+		want:  "non-deterministic function rand()",
+	}, {
+		query: "insert into t(id1, id2, val) values (1, 2, now())",
+	}, {
+		query: "insert into t(id1, id2, val) values (1, 2, sysdate())",
+		want:  "non-deterministic function sysdate()",
+	}, {
+		query: "insert into t(id1, id2, val) select id1, id2, val from t limit 5",
+		want:  "INSERT ... SELECT with LIMIT without ORDER BY",
+	}, {
+		query: "select rand() from t limit 1",
+	}}
+	parser := sqlparser.NewTestParser()
+	for _, tcase := range tcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tcase.query)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.want, unsafeReason(stmt, table))
+		})
+	}
+}
@@ -45,6 +45,7 @@ import (
 	"vitess.io/vitess/go/vt/tableacl"
 	tacl "vitess.io/vitess/go/vt/tableacl/acl"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/callerroles"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
@@ -69,6 +70,20 @@ type TabletPlan struct {
 	RowsAffected uint64
 	RowsReturned uint64
 	ErrorCount   uint64
+
+	// StreamBlockedCount and StreamBlockedTime measure the results of the
+	// streaming queries of the plan that were blocked by client backpressure.
+	StreamBlockedCount uint64
+	StreamBlockedTime  uint64
+
+	// fieldsCacheable is true if the result of the field query of the plan
+	// doesn't depend on its bind variables, so that it can be cached.
+	fieldsCacheable bool
+	// fields is the cached result of the field query of the plan.
+	fields atomic.Pointer[cachedFields]
+	// warmupSample is the final SQL of a query of the plan, which the warmup
+	// replays once MySQL restarted.
+	warmupSample atomic.Pointer[string]
 }
 
 // AddStats updates the stats for the current TabletPlan.
@@ -92,6 +107,18 @@ func (ep *TabletPlan) Stats() (queryCount uint64, duration, mysqlTime time.Durat
 	return
 }
 
+// AddStreamBlocked records a result of the plan that was blocked for the given
+// duration by client backpressure.
+func (ep *TabletPlan) AddStreamBlocked(blocked time.Duration) {
+	atomic.AddUint64(&ep.StreamBlockedCount, 1)
+	atomic.AddUint64(&ep.StreamBlockedTime, uint64(blocked))
+}
+
+// StreamBlockedStats returns the client backpressure stats of the TabletPlan.
+func (ep *TabletPlan) StreamBlockedStats() (count uint64, blocked time.Duration) {
+	return atomic.LoadUint64(&ep.StreamBlockedCount), time.Duration(atomic.LoadUint64(&ep.StreamBlockedTime))
+}
+
 // buildAuthorized builds 'Authorized', which is the runtime part for 'Permissions'.
 func (ep *TabletPlan) buildAuthorized() {
 	ep.Authorized = make([]*tableacl.ACLResult, len(ep.Permissions))
@@ -168,6 +195,9 @@ type QueryEngine struct {
 	// that we start more than one transaction per hot row (range).
 	// For implementation details, please see BeginExecute() in tabletserver.go.
 	txSerializer *txserializer.TxSerializer
+	// warmer records the hottest queries, to warm up MySQL with them
+	// when the query engine opens again. It's nil if the warmup is disabled.
+	warmer *queryWarmer
 
 	// Vars
 	maxResultSize    atomic.Int64
@@ -183,6 +213,11 @@ type QueryEngine struct {
 
 	strictTransTables bool
 
+	// binlogFormat is the global binlog_format of MySQL, read on the first
+	// check of an unsafe statement after the engine opened.
+	binlogFormatMu sync.Mutex
+	binlogFormat   string
+
 	consolidatorMode atomic.Value
 
 	// stats
@@ -193,6 +228,10 @@ type QueryEngine struct {
 	// stats flags
 	enablePerWorkloadTableMetrics bool
 
+	// enableFieldCache caches the results of the field queries of the plans.
+	enableFieldCache                 bool
+	fieldCacheHits, fieldCacheMisses *stats.Counter
+
 	// Loggers
 	accessCheckerLogger *logutil.ThrottledLogger
 }
@@ -208,6 +247,7 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		se:                            se,
 		queryRuleSources:              rules.NewMap(),
 		enablePerWorkloadTableMetrics: config.EnablePerWorkloadTableMetrics,
+		enableFieldCache:              config.EnableFieldCache,
 	}
 
 	// Cache for query plans: user configured size with a doorkeeper by default to prevent one-off queries
@@ -237,6 +277,7 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		log.Info("Stream consolidator is not enabled.")
 	}
 	qe.txSerializer = txserializer.New(env)
+	qe.warmer = newQueryWarmer(env, qe)
 
 	qe.strictTableACL = config.StrictTableACL
 	qe.enableTableACLDryRun = config.EnableTableACLDryRun
@@ -293,6 +334,9 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		labels = []string{"Table", "Plan", "Workload"}
 	}
 
+	qe.fieldCacheHits = env.Exporter().NewCounter("FieldQueryCacheHits", "Field queries answered from the cached fields of their plan, without querying MySQL")
+	qe.fieldCacheMisses = env.Exporter().NewCounter("FieldQueryCacheMisses", "Field queries of cacheable plans that were sent to MySQL")
+
 	qe.queryCounts = env.Exporter().NewCountersWithMultiLabels("QueryCounts", "query counts", labels)
 	qe.queryCountsWithTabletType = env.Exporter().NewCountersWithMultiLabels("QueryCountsWithTabletType", "query counts with tablet type labels", []string{"Table", "Plan", "TabletType"})
 	qe.queryTimes = env.Exporter().NewCountersWithMultiLabels("QueryTimesNs", "query times in ns", labels)
@@ -338,9 +382,15 @@ func (qe *QueryEngine) Open() error {
 	}
 
 	qe.streamConns.Open(config.DB.AppWithDB(), config.DB.DbaWithDB(), config.DB.AppDebugWithDB())
+	qe.binlogFormatMu.Lock()
+	qe.binlogFormat = ""
+	qe.binlogFormatMu.Unlock()
 	qe.se.RegisterNotifier("qe", qe.schemaChanged, true)
 	qe.plans.EnsureOpen()
 	qe.settings.EnsureOpen()
+	// The warmup runs before the tablet serves, to spare the first
+	// queries the latency of a cold buffer pool.
+	qe.warmer.Open()
 	qe.isOpen.Store(true)
 	return nil
 }
@@ -355,6 +405,7 @@ func (qe *QueryEngine) Close() {
 	// Close in reverse order of Open.
 	qe.se.UnregisterNotifier("qe")
 
+	qe.warmer.Close()
 	qe.plans.Close()
 	qe.settings.Close()
 
@@ -375,8 +426,9 @@ func (qe *QueryEngine) getPlan(curSchema *currentSchema, sql string) (*TabletPla
 		return nil, err
 	}
 	plan := &TabletPlan{Plan: splan, Original: sql}
-	plan.Rules = qe.queryRuleSources.FilterByPlan(sql, plan.PlanID, plan.TableNames()...)
+	plan.Rules = qe.queryRuleSources.FilterByPlan(sql, plan.PlanID, ruleTableNames(plan)...)
 	plan.buildAuthorized()
+	plan.fieldsCacheable = qe.enableFieldCache && fieldsCacheable(plan.PlanID, statement)
 	if sqlparser.CachePlan(statement) {
 		return plan, nil
 	}
@@ -384,6 +436,22 @@ func (qe *QueryEngine) getPlan(curSchema *currentSchema, sql string) (*TabletPla
 	return plan, errNoCache
 }
 
+// ruleTableNames returns the names of the tables that the query rules of the
+// plan match. The DDL plans have no tables, so the rules match the tables
+// that the DDL affects instead.
+func ruleTableNames(plan *TabletPlan) []string {
+	if ddl, ok := plan.FullStmt.(sqlparser.DDLStatement); ok && plan.PlanID == planbuilder.PlanDDL {
+		var names []string
+		for _, table := range ddl.AffectedTables() {
+			names = append(names, table.Name.String())
+		}
+		if len(names) > 0 {
+			return names
+		}
+	}
+	return plan.TableNames()
+}
+
 // GetPlan returns the TabletPlan that for the query. Plans are cached in an LRU cache.
 func (qe *QueryEngine) GetPlan(ctx context.Context, logStats *tabletenv.LogStats, sql string, skipQueryPlanCache bool) (*TabletPlan, error) {
 	span, _ := trace.NewSpan(ctx, "QueryEngine.GetPlan")
@@ -472,8 +540,9 @@ func (qe *QueryEngine) GetMessageStreamPlan(name string) (*TabletPlan, error) {
 	return plan, nil
 }
 
-// GetConnSetting returns system settings for the connection.
-func (qe *QueryEngine) GetConnSetting(ctx context.Context, settings []string) (*smartconnpool.Setting, error) {
+// GetConnSetting returns system settings for the connection, along with the
+// queries that activate the caller's roles and attributes, if any.
+func (qe *QueryEngine) GetConnSetting(ctx context.Context, settings []string, caller *callerroles.Queries) (*smartconnpool.Setting, error) {
 	span, _ := trace.NewSpan(ctx, "QueryEngine.GetConnSetting")
 	defer span.Finish()
 
@@ -482,15 +551,30 @@ func (qe *QueryEngine) GetConnSetting(ctx context.Context, settings []string) (*
 		_, _ = buf.WriteString(q)
 		_ = buf.WriteByte(';')
 	}
+	if caller != nil {
+		for _, q := range caller.Apply {
+			_, _ = buf.WriteString(q)
+			_ = buf.WriteByte(';')
+		}
+	}
 
 	cacheKey := SettingsCacheKey(buf.String())
 	connSetting, _, err := qe.settings.GetOrLoad(cacheKey, 0, func() (*smartconnpool.Setting, error) {
-		// build the setting queries
-		query, resetQuery, err := planbuilder.BuildSettingQuery(settings, qe.env.Environment().Parser())
-		if err != nil {
-			return nil, err
+		var queries, resetQueries []string
+		if len(settings) > 0 {
+			// build the setting queries
+			query, resetQuery, err := planbuilder.BuildSettingQuery(settings, qe.env.Environment().Parser())
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, query)
+			resetQueries = append(resetQueries, resetQuery)
 		}
-		return smartconnpool.NewSetting(query, resetQuery), nil
+		if caller != nil {
+			queries = append(queries, caller.Apply...)
+			resetQueries = append(resetQueries, caller.Reset...)
+		}
+		return smartconnpool.NewSetting(strings.Join(queries, "; "), strings.Join(resetQueries, "; ")), nil
 	})
 	return connSetting, err
 }
@@ -530,6 +614,40 @@ func (qe *QueryEngine) IsMySQLReachable() error {
 	return nil
 }
 
+// statementBasedBinlog returns whether MySQL logs the statements themselves
+// in its binlog, i.e. whether its binlog_format is STATEMENT or MIXED, in which
+// case the statements unsafe for statement-based replication are checked. It
+// returns true if the binlog_format can't be read.
+func (qe *QueryEngine) statementBasedBinlog(ctx context.Context) bool {
+	qe.binlogFormatMu.Lock()
+	defer qe.binlogFormatMu.Unlock()
+	if qe.binlogFormat == "" {
+		binlogFormat, err := qe.readBinlogFormat(ctx)
+		if err != nil {
+			logUnsafeStatement.Warningf("Failed to read the binlog_format, checking the unsafe statements: %v", err)
+			return true
+		}
+		qe.binlogFormat = binlogFormat
+	}
+	return strings.EqualFold(qe.binlogFormat, "STATEMENT") || strings.EqualFold(qe.binlogFormat, "MIXED")
+}
+
+func (qe *QueryEngine) readBinlogFormat(ctx context.Context) (string, error) {
+	conn, err := qe.conns.Get(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Recycle()
+	qr, err := conn.Conn.Exec(ctx, "select @@global.binlog_format", 1, false)
+	if err != nil {
+		return "", err
+	}
+	if len(qr.Rows) != 1 {
+		return "", vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for binlog_format: %v", qr.Rows)
+	}
+	return qr.Rows[0][0].ToString(), nil
+}
+
 func (qe *QueryEngine) schemaChanged(tables map[string]*schema.Table, created, altered, dropped []*schema.Table) {
 	qe.schemaMu.Lock()
 	defer qe.schemaMu.Unlock()
@@ -606,6 +724,9 @@ type perQueryStats struct {
 	RowsAffected uint64
 	RowsReturned uint64
 	ErrorCount   uint64
+
+	StreamBlockedCount uint64
+	StreamBlockedTime  time.Duration
 }
 
 func (qe *QueryEngine) handleHTTPQueryPlans(response http.ResponseWriter, request *http.Request) {
@@ -640,6 +761,7 @@ func (qe *QueryEngine) handleHTTPQueryStats(response http.ResponseWriter, reques
 		pqstats.Table = plan.TableName().String()
 		pqstats.Plan = plan.PlanID
 		pqstats.QueryCount, pqstats.Time, pqstats.MysqlTime, pqstats.RowsAffected, pqstats.RowsReturned, pqstats.ErrorCount = plan.Stats()
+		pqstats.StreamBlockedCount, pqstats.StreamBlockedTime = plan.StreamBlockedStats()
 
 		qstats = append(qstats, pqstats)
 		return true
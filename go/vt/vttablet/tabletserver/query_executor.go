@@ -103,6 +103,26 @@ func allocStreamResult() *sqltypes.Result {
 	return streamResultPool.Get().(*sqltypes.Result)
 }
 
+// chunkStreamRows returns a callback that splits the results it is passed into
+// results of at most maxRows rows before handing them to callback.
+func chunkStreamRows(callback StreamCallback, maxRows int) StreamCallback {
+	return func(result *sqltypes.Result) error {
+		if len(result.Rows) <= maxRows {
+			return callback(result)
+		}
+		for i := 0; i < len(result.Rows); i += maxRows {
+			chunk := &sqltypes.Result{Rows: result.Rows[i:min(i+maxRows, len(result.Rows))]}
+			if i == 0 {
+				chunk.Fields = result.Fields
+			}
+			if err := callback(chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func (qre *QueryExecutor) shouldConsolidate() bool {
 	co := qre.options.GetConsolidator()
 	switch co {
@@ -138,13 +158,20 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		vtErrorCode := vterrors.Code(err)
 		errCode = vtErrorCode.String()
 
+		// The per-table metrics only count the queries of the users.
+		internal := callerid.InternalComponentFromContext(qre.ctx) != ""
+
 		if reply == nil {
-			qre.tsv.qe.AddStats(qre.plan.PlanID, tableName, qre.options.GetWorkloadName(), qre.targetTabletType, 1, duration, mysqlTime, 0, 0, 1, errCode)
+			if !internal {
+				qre.tsv.qe.AddStats(qre.plan.PlanID, tableName, qre.options.GetWorkloadName(), qre.targetTabletType, 1, duration, mysqlTime, 0, 0, 1, errCode)
+			}
 			qre.plan.AddStats(1, duration, mysqlTime, 0, 0, 1)
 			return
 		}
 
-		qre.tsv.qe.AddStats(qre.plan.PlanID, tableName, qre.options.GetWorkloadName(), qre.targetTabletType, 1, duration, mysqlTime, int64(reply.RowsAffected), int64(len(reply.Rows)), 0, errCode)
+		if !internal {
+			qre.tsv.qe.AddStats(qre.plan.PlanID, tableName, qre.options.GetWorkloadName(), qre.targetTabletType, 1, duration, mysqlTime, int64(reply.RowsAffected), int64(len(reply.Rows)), 0, errCode)
+		}
 		qre.plan.AddStats(1, duration, mysqlTime, reply.RowsAffected, uint64(len(reply.Rows)), 0)
 		qre.logStats.RowsAffected = int(reply.RowsAffected)
 		qre.logStats.Rows = reply.Rows
@@ -155,6 +182,14 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		return nil, err
 	}
 
+	if err = qre.checkUnsafeStatement(); err != nil {
+		return nil, err
+	}
+
+	if err = qre.intercept("Execute"); err != nil {
+		return nil, err
+	}
+
 	if qre.plan.PlanID == p.PlanNextval {
 		return qre.execNextval()
 	}
@@ -172,6 +207,9 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 				return nil, vterrors.Wrap(err, "failed to execute system setting on the connection")
 			}
 		}
+		if leavesUntrackedState(qre.plan.PlanID) {
+			conn.recordUntrackedState()
+		}
 		return qre.txConnExec(conn)
 	}
 
@@ -182,7 +220,7 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
 			qre.bindVars[sqltypes.BvSchemaName] = sqltypes.StringBindVariable(qre.tsv.config.DB.DBName)
 		}
-		qr, err := qre.execSelect()
+		qr, err := qre.execSelectOrCachedFields()
 		if err != nil {
 			return nil, err
 		}
@@ -275,6 +313,17 @@ func (qre *QueryExecutor) execAsTransaction(f func(conn *StatefulConnection) (*s
 	return result, nil
 }
 
+// leavesUntrackedState returns true for the plans whose statements can leave
+// session state, like locks, that re-running the setup queries of a reserved
+// connection would not restore.
+func leavesUntrackedState(planID p.PlanType) bool {
+	switch planID {
+	case p.PlanSelectLockFunc, p.PlanOtherAdmin, p.PlanFlush, p.PlanLockTables, p.PlanUnlockTables, p.PlanCallProc:
+		return true
+	}
+	return false
+}
+
 func (qre *QueryExecutor) txConnExec(conn *StatefulConnection) (*sqltypes.Result, error) {
 	switch qre.plan.PlanID {
 	case p.PlanInsert, p.PlanUpdate, p.PlanDelete, p.PlanSet:
@@ -326,6 +375,14 @@ func (qre *QueryExecutor) Stream(callback StreamCallback) error {
 		return err
 	}
 
+	if err := qre.intercept("Stream"); err != nil {
+		return err
+	}
+
+	if chunkRows, _ := qre.streamChunkSize(); chunkRows > 0 {
+		callback = chunkStreamRows(callback, chunkRows)
+	}
+
 	switch qre.plan.PlanID {
 	case p.PlanSelectStream:
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
@@ -379,6 +436,9 @@ func (qre *QueryExecutor) Stream(callback StreamCallback) error {
 				return vterrors.Wrap(err, "failed to execute system setting on the connection")
 			}
 		}
+		if leavesUntrackedState(qre.plan.PlanID) {
+			txConn.recordUntrackedState()
+		}
 		conn = txConn.UnderlyingDBConn()
 	} else {
 		dbConn, err := qre.getStreamConn()
@@ -431,6 +491,28 @@ func (qre *QueryExecutor) MessageStream(callback StreamCallback) error {
 	return nil
 }
 
+// checkUnsafeStatement warns about or rejects the DMLs that are unsafe for
+// statement-based replication, depending on --unsafe-statement-mode. They are
+// only checked when MySQL logs statements in its binlog.
+func (qre *QueryExecutor) checkUnsafeStatement() error {
+	mode := qre.tsv.Config().UnsafeStatementMode
+	if mode != tabletenv.Warn && mode != tabletenv.Reject {
+		return nil
+	}
+	if qre.plan.UnsafeReason == "" || tabletenv.IsLocalContext(qre.ctx) {
+		return nil
+	}
+	if !qre.tsv.qe.statementBasedBinlog(qre.ctx) {
+		return nil
+	}
+	qre.tsv.Stats().UnsafeStatements.Add([]string{qre.plan.TableName().String(), mode}, 1)
+	if mode == tabletenv.Reject {
+		return vterrors.VT09026(qre.plan.UnsafeReason)
+	}
+	logUnsafeStatement.Warningf("Statement unsafe for statement-based replication (%s): %s", qre.plan.UnsafeReason, qre.tsv.env.Parser().TruncateForLog(qre.query))
+	return nil
+}
+
 // checkPermissions returns an error if the query does not pass all checks
 // (denied query, table ACL).
 func (qre *QueryExecutor) checkPermissions() error {
@@ -455,8 +537,15 @@ func (qre *QueryExecutor) checkPermissions() error {
 
 	switch action {
 	case rules.QRFail:
+		if strings.HasPrefix(desc, rules.TableKillSwitchRuleDescription) {
+			qre.tsv.Stats().TableKillSwitchQueries.Add(qre.plan.TableName().String(), 1)
+			return vterrors.VT09025(desc)
+		}
 		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "disallowed due to rule: %s", desc)
 	case rules.QRFailRetry:
+		if desc == rules.DeniedTablesRuleDescription {
+			qre.tsv.Stats().DeniedTableQueries.Add(qre.plan.TableName().String(), 1)
+		}
 		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "disallowed due to rule: %s", desc)
 	case rules.QRBuffer:
 		if ruleCancelCtx != nil {
@@ -499,7 +588,7 @@ func (qre *QueryExecutor) checkPermissions() error {
 	}
 
 	for i, auth := range qre.plan.Authorized {
-		if err := qre.checkAccess(auth, qre.plan.Permissions[i].TableName, callerID); err != nil {
+		if err := qre.checkAccess(auth, qre.plan.Permissions[i].TableName, qre.plan.Permissions[i].Role, callerID); err != nil {
 			return err
 		}
 	}
@@ -507,7 +596,8 @@ func (qre *QueryExecutor) checkPermissions() error {
 	return nil
 }
 
-func (qre *QueryExecutor) checkAccess(authorized *tableacl.ACLResult, tableName string, callerID *querypb.VTGateCallerID) error {
+func (qre *QueryExecutor) checkAccess(authorized *tableacl.ACLResult, tableName string, role tableacl.Role, callerID *querypb.VTGateCallerID) error {
+	qre.checkDryRunAccess(tableName, role, callerID)
 	statsKey := []string{tableName, authorized.GroupName, qre.plan.PlanID.String(), callerID.Username}
 	if !authorized.IsMember(callerID) {
 		if qre.tsv.qe.enableTableACLDryRun {
@@ -536,6 +626,18 @@ func (qre *QueryExecutor) checkAccess(authorized *tableacl.ACLResult, tableName
 	return nil
 }
 
+// checkDryRunAccess checks the access of the caller to the table against the
+// dry run table ACL config, if one is loaded. The denials are only counted and
+// logged, so that a new config can be validated before it is enforced.
+func (qre *QueryExecutor) checkDryRunAccess(tableName string, role tableacl.Role, callerID *querypb.VTGateCallerID) {
+	authorized, _ := tableacl.EvaluateDryRun(tableName, role)
+	if authorized == nil || authorized.IsMember(callerID) {
+		return
+	}
+	qre.tsv.Stats().TableaclDryRunDenied.Add([]string{tableName, authorized.GroupName, qre.plan.PlanID.String(), callerID.Username}, 1)
+	qre.tsv.qe.accessCheckerLogger.Infof("%s command would be denied to user '%s' for table '%s' by the dry run ACL config", qre.plan.PlanID.String(), callerID.Username, tableName)
+}
+
 func (qre *QueryExecutor) execDDL(conn *StatefulConnection) (*sqltypes.Result, error) {
 	// Let's see if this is a normal DDL statement or an Online DDL statement.
 	// An Online DDL statement is identified by /*vt+ .. */ comment with expected directives, like uuid etc.
@@ -582,6 +684,9 @@ func (qre *QueryExecutor) execDDL(conn *StatefulConnection) (*sqltypes.Result, e
 	if err != nil {
 		return nil, err
 	}
+	if isTemporaryTable {
+		conn.recordTempTable()
+	}
 	// Only perform this operation when the connection has transaction open.
 	// TODO: This actually does not retain the old transaction. We should see how to provide correct behaviour to client.
 	if conn.txProps != nil {
@@ -690,6 +795,27 @@ func (qre *QueryExecutor) execNextval() (*sqltypes.Result, error) {
 	}, nil
 }
 
+// execSelectOrCachedFields answers the field queries of the plans that cache
+// their fields from the cache, and executes the other selects.
+func (qre *QueryExecutor) execSelectOrCachedFields() (*sqltypes.Result, error) {
+	// The settings of the connection could change the metadata of the fields.
+	if !qre.plan.fieldsCacheable || qre.setting != nil {
+		return qre.execSelect()
+	}
+	epoch := qre.tsv.qe.schema.Load().epoch
+	if qr := qre.plan.cachedFieldsResult(epoch); qr != nil {
+		qre.tsv.qe.fieldCacheHits.Add(1)
+		return qr, nil
+	}
+	qre.tsv.qe.fieldCacheMisses.Add(1)
+	qr, err := qre.execSelect()
+	if err != nil {
+		return nil, err
+	}
+	qre.plan.cacheFieldsResult(epoch, qr)
+	return qr, nil
+}
+
 // execSelect sends a query to mysql only if another identical query is not running. Otherwise, it waits and
 // reuses the result. If the plan is missing field info, it sends the query to mysql requesting full info.
 func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
@@ -697,6 +823,7 @@ func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	qre.tsv.qe.warmer.sample(qre.plan, sqlWithoutComments)
 	// Check tablet type.
 	if qre.shouldConsolidate() {
 		q, original := qre.tsv.qe.consolidator.Create(sqlWithoutComments)
@@ -773,14 +900,19 @@ func (qre *QueryExecutor) execOther() (*sqltypes.Result, error) {
 	return qre.execDBConn(conn.Conn, qre.query, true)
 }
 
+// getConn gets a connection from the query pool. When the pool is exhausted,
+// the query waits in its admission queue, where the queries are served in the
+// order of their priority.
 func (qre *QueryExecutor) getConn() (*connpool.PooledConn, error) {
 	span, ctx := trace.NewSpan(qre.ctx, "QueryExecutor.getConn")
 	defer span.Finish()
 
-	defer func(start time.Time) {
-		qre.logStats.WaitingForConnection += time.Since(start)
-	}(time.Now())
+	start := time.Now()
+	ctx = smartconnpool.WithPriority(ctx, qre.tsv.getPriorityFromOptions(qre.options))
 	conn, err := qre.tsv.qe.conns.Get(ctx, qre.setting)
+	waited := time.Since(start)
+	qre.logStats.WaitingForConnection += waited
+	qre.recordQueryPoolWait(start, waited, err)
 
 	switch err {
 	case nil:
@@ -791,6 +923,24 @@ func (qre *QueryExecutor) getConn() (*connpool.PooledConn, error) {
 	return nil, err
 }
 
+// recordQueryPoolWait records the time the query waited for a query pool
+// connection, and why it gave up waiting if it didn't get one.
+func (qre *QueryExecutor) recordQueryPoolWait(start time.Time, waited time.Duration, err error) {
+	stats := qre.tsv.stats
+	workload := qre.options.GetWorkloadName()
+	switch err {
+	case nil:
+		stats.QueryPoolQueueTimings.Record(workload, start)
+		if slo := qre.tsv.config.OltpReadPool.QueueSLO; slo > 0 && waited > slo {
+			stats.QueryPoolQueueSLOExceeded.Add(workload, 1)
+		}
+	case smartconnpool.ErrQueueFull:
+		stats.QueryPoolQueueRejections.Add([]string{workload, "QueueFull"}, 1)
+	case smartconnpool.ErrTimeout, smartconnpool.ErrCtxTimeout:
+		stats.QueryPoolQueueRejections.Add([]string{workload, "Deadline"}, 1)
+	}
+}
+
 func (qre *QueryExecutor) getStreamConn() (*connpool.PooledConn, error) {
 	span, ctx := trace.NewSpan(qre.ctx, "QueryExecutor.getStreamConn")
 	defer span.Finish()
@@ -823,6 +973,9 @@ func (qre *QueryExecutor) txFetch(conn *StatefulConnection, record bool) (*sqlty
 	if record {
 		conn.TxProperties().RecordQuery(sql)
 	}
+	if qre.plan.PlanID == p.PlanSet {
+		conn.recordSetupQuery(sql)
+	}
 	return qr, nil
 }
 
@@ -831,6 +984,17 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	if err != nil {
 		return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s", err)
 	}
+	// The query without comments is used by the consolidators, so it must not
+	// have the hint, which differs from one execution to the other.
+	queryWithoutComments := query
+	if qre.tsv.config.MaxExecutionTimeHint {
+		switch qre.plan.PlanID {
+		case p.PlanSelect, p.PlanSelectStream:
+			if deadline, ok := qre.ctx.Deadline(); ok {
+				query = addMaxExecutionTimeHint(query, time.Until(deadline))
+			}
+		}
+	}
 	if qre.tsv.config.AnnotateQueries {
 		username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
 		if username == "" {
@@ -849,7 +1013,7 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	}
 
 	if qre.marginComments.Leading == "" && qre.marginComments.Trailing == "" {
-		return query, query, nil
+		return query, queryWithoutComments, nil
 	}
 
 	var buf strings.Builder
@@ -857,7 +1021,40 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	buf.WriteString(qre.marginComments.Leading)
 	buf.WriteString(query)
 	buf.WriteString(qre.marginComments.Trailing)
-	return buf.String(), query, nil
+	return buf.String(), queryWithoutComments, nil
+}
+
+// addMaxExecutionTimeHint adds a MAX_EXECUTION_TIME optimizer hint to the
+// SELECT, so that MySQL aborts it once the timeout has elapsed. The hint is
+// merged into the optimizer hint comment of the query if it has one, since
+// MySQL only accepts one. The query is returned as is if it is not a SELECT,
+// if it already has a MAX_EXECUTION_TIME hint, or if the timeout is below
+// the precision of the hint.
+func addMaxExecutionTimeHint(query string, timeout time.Duration) string {
+	ms := timeout.Milliseconds()
+	if ms <= 0 || len(query) < len("select ") || !strings.EqualFold(query[:len("select ")], "select ") {
+		return query
+	}
+	hint := fmt.Sprintf("MAX_EXECUTION_TIME(%d)", ms)
+	pos := len("select ")
+	for strings.HasPrefix(query[pos:], "/*") {
+		end := strings.Index(query[pos:], "*/")
+		if end == -1 {
+			return query
+		}
+		end += pos
+		if strings.HasPrefix(query[pos:], "/*+") {
+			if strings.Contains(strings.ToUpper(query[pos:end]), "MAX_EXECUTION_TIME") {
+				return query
+			}
+			return strings.TrimRight(query[:end], " ") + " " + hint + " " + query[end:]
+		}
+		pos = end + len("*/")
+		for pos < len(query) && query[pos] == ' ' {
+			pos++
+		}
+	}
+	return query[:len("select ")] + "/*+ " + hint + " */ " + query[len("select "):]
 }
 
 func rewriteOUTParamError(err error) error {
@@ -1111,6 +1308,9 @@ func (qre *QueryExecutor) execStreamSQL(conn *connpool.PooledConn, isTransaction
 		defer span.Finish()
 		return callback(result)
 	}
+	if threshold := qre.tsv.config.StreamBackpressureThreshold; threshold > 0 {
+		callBackClosingSpan = qre.withBackpressureTracking(threshold, callBackClosingSpan)
+	}
 
 	start := time.Now()
 	defer qre.logStats.AddRewrittenSQL(sql, start)
@@ -1121,22 +1321,80 @@ func (qre *QueryExecutor) execStreamSQL(conn *connpool.PooledConn, isTransaction
 	// This change will ensure that long-running streaming stateful queries get gracefully shutdown during ServingTypeChange
 	// once their grace period is over.
 	qd := NewQueryDetail(qre.logStats.Ctx, conn.Conn)
+	_, streamBufferSize := qre.streamChunkSize()
 	if isTransaction {
 		qre.tsv.statefulql.Add(qd)
 		defer qre.tsv.statefulql.Remove(qd)
-		return conn.Conn.StreamOnce(ctx, sql, callBackClosingSpan, allocStreamResult, int(qre.tsv.qe.streamBufferSize.Load()), sqltypes.IncludeFieldsOrDefault(qre.options))
+		return conn.Conn.StreamOnce(ctx, sql, callBackClosingSpan, allocStreamResult, streamBufferSize, sqltypes.IncludeFieldsOrDefault(qre.options))
 	}
 	qre.tsv.olapql.Add(qd)
 	defer qre.tsv.olapql.Remove(qd)
-	return conn.Conn.Stream(ctx, sql, callBackClosingSpan, allocStreamResult, int(qre.tsv.qe.streamBufferSize.Load()), sqltypes.IncludeFieldsOrDefault(qre.options))
+	return conn.Conn.Stream(ctx, sql, callBackClosingSpan, allocStreamResult, streamBufferSize, sqltypes.IncludeFieldsOrDefault(qre.options))
 }
 
-func (qre *QueryExecutor) recordUserQuery(queryType string, duration int64) {
+// withBackpressureTracking wraps the callback of a streaming query to record the
+// results that the client takes longer than the threshold to receive.
+func (qre *QueryExecutor) withBackpressureTracking(threshold time.Duration, callback func(*sqltypes.Result) error) func(*sqltypes.Result) error {
+	return func(result *sqltypes.Result) error {
+		start := time.Now()
+		err := callback(result)
+		if blocked := time.Since(start); blocked >= threshold {
+			qre.recordStreamBlocked(blocked)
+		}
+		return err
+	}
+}
+
+// recordStreamBlocked records a result of the streaming query that was blocked
+// by client backpressure, and calls the backpressure hook if one is set.
+func (qre *QueryExecutor) recordStreamBlocked(blocked time.Duration) {
+	if callerid.InternalComponentFromContext(qre.ctx) != "" {
+		// The per-user metrics only count the queries of the users.
+		qre.plan.AddStreamBlocked(blocked)
+		return
+	}
 	username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
 	if username == "" {
 		username = callerid.GetUsername(callerid.ImmediateCallerIDFromContext(qre.ctx))
 	}
 	tableName := qre.plan.TableName().String()
+	qre.tsv.Stats().UserStreamBlockedCount.Add([]string{tableName, username}, 1)
+	qre.tsv.Stats().UserStreamBlockedNs.Add([]string{tableName, username}, int64(blocked))
+	qre.plan.AddStreamBlocked(blocked)
+	if hook := qre.tsv.streamBackpressureHook.Load(); hook != nil {
+		(*hook)(StreamBackpressure{
+			CallerID: username,
+			Table:    tableName,
+			Query:    qre.plan.Original,
+			Blocked:  blocked,
+		})
+	}
+}
+
+// recordInternalQuery records a query that the given component of Vitess generated for itself.
+func (qre *QueryExecutor) recordInternalQuery(component, tableName, queryType string, duration int64) {
+	qre.tsv.Stats().InternalQueryCount.Add([]string{tableName, component, queryType}, 1)
+	qre.tsv.Stats().InternalQueryTimesNs.Add([]string{tableName, component, queryType}, duration)
+}
+
+// streamChunkSize returns the maximum number of rows and bytes per streamed result
+// requested by the client, within the limits of the query server configuration.
+func (qre *QueryExecutor) streamChunkSize() (int, int) {
+	return sqltypes.StreamChunkSize(qre.options, int(qre.tsv.qe.streamBufferSize.Load()), qre.tsv.config.MaxStreamBufferSize)
+}
+
+// recordUserQuery records the query in the per-user metrics, or in the metrics
+// of the Vitess-internal queries if Vitess generated it for itself.
+func (qre *QueryExecutor) recordUserQuery(queryType string, duration int64) {
+	tableName := qre.plan.TableName().String()
+	if component := callerid.InternalComponentFromContext(qre.ctx); component != "" {
+		qre.recordInternalQuery(component, tableName, queryType, duration)
+		return
+	}
+	username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
+	if username == "" {
+		username = callerid.GetUsername(callerid.ImmediateCallerIDFromContext(qre.ctx))
+	}
 	qre.tsv.Stats().UserTableQueryCount.Add([]string{tableName, username, queryType}, 1)
 	qre.tsv.Stats().UserTableQueryTimesNs.Add([]string{tableName, username, queryType}, duration)
 }
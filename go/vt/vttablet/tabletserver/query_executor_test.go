@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/pools/smartconnpool"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtenv"
@@ -441,6 +442,115 @@ func TestQueryExecutorQueryAnnotation(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorMaxExecutionTimeHint(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	fields := sqltypes.MakeTestFields("a|b", "int64|varchar")
+	selectResult := sqltypes.MakeTestResult(fields, "1|aaa")
+	var executed []string
+	db.AddQueryPatternWithCallback(`select /\*\+ MAX_EXECUTION_TIME\(\d+\) \*/ \* from t limit 10001`, selectResult, func(query string) {
+		executed = append(executed, query)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	tsv.config.MaxExecutionTimeHint = true
+	defer tsv.StopService()
+
+	qre := newTestQueryExecutor(ctx, tsv, "select * from t", 0)
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, selectResult, got)
+	require.Len(t, executed, 1)
+	var ms int64
+	_, err = fmt.Sscanf(executed[0], "select /*+ MAX_EXECUTION_TIME(%d) */", &ms)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, ms, time.Hour.Milliseconds())
+	assert.Greater(t, ms, time.Minute.Milliseconds())
+}
+
+func TestQueryExecutorInternalQueryStats(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	db.AddQuery(query, &sqltypes.Result{})
+
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("user", "", ""), nil)
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	userKey := "test_table.user.Execute"
+	internalKey := "test_table.Messager.Execute"
+	tableKey := "test_table.Select"
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	_, err := qre.Execute()
+	require.NoError(t, err)
+	userCount := tsv.stats.UserTableQueryCount.Counts()[userKey]
+	tableCount := tsv.qe.queryCounts.Counts()[tableKey]
+	assert.EqualValues(t, 1, userCount)
+	assert.EqualValues(t, 1, tableCount)
+
+	// The internal queries are only counted in the internal metrics.
+	qre = newTestQueryExecutor(callerid.NewInternalContext(ctx, "Messager"), tsv, query, 0)
+	_, err = qre.Execute()
+	require.NoError(t, err)
+	assert.EqualValues(t, userCount, tsv.stats.UserTableQueryCount.Counts()[userKey])
+	assert.EqualValues(t, tableCount, tsv.qe.queryCounts.Counts()[tableKey])
+	assert.EqualValues(t, 1, tsv.stats.InternalQueryCount.Counts()[internalKey])
+	assert.Positive(t, tsv.stats.InternalQueryTimesNs.Counts()[internalKey])
+
+	// The internal effective CallerID can be sent by any client, so the
+	// queries which use it are counted as the queries of users.
+	qre = newTestQueryExecutor(callerid.NewContext(context.Background(), callerid.NewInternalCallerID("SchemaTracker"), nil), tsv, query, 0)
+	_, err = qre.Execute()
+	require.NoError(t, err)
+	assert.EqualValues(t, tableCount+1, tsv.qe.queryCounts.Counts()[tableKey])
+	assert.Zero(t, tsv.stats.InternalQueryCount.Counts()["test_table.SchemaTracker.Execute"])
+}
+
+func TestAddMaxExecutionTimeHint(t *testing.T) {
+	tests := []struct {
+		query   string
+		timeout time.Duration
+		want    string
+	}{{
+		query:   "select a from t",
+		timeout: 1500 * time.Millisecond,
+		want:    "select /*+ MAX_EXECUTION_TIME(1500) */ a from t",
+	}, {
+		query:   "select /* comment */ a from t",
+		timeout: time.Second,
+		want:    "select /*+ MAX_EXECUTION_TIME(1000) */ /* comment */ a from t",
+	}, {
+		query:   "select /*+ SET_VAR(sort_buffer_size = 16M) */ a from t",
+		timeout: time.Second,
+		want:    "select /*+ SET_VAR(sort_buffer_size = 16M) MAX_EXECUTION_TIME(1000) */ a from t",
+	}, {
+		query:   "select /* comment */ /*+ SET_VAR(sort_buffer_size = 16M) */ a from t",
+		timeout: time.Second,
+		want:    "select /* comment */ /*+ SET_VAR(sort_buffer_size = 16M) MAX_EXECUTION_TIME(1000) */ a from t",
+	}, {
+		query:   "select /*+ max_execution_time(10) */ a from t",
+		timeout: time.Second,
+		want:    "select /*+ max_execution_time(10) */ a from t",
+	}, {
+		query:   "select a from t",
+		timeout: time.Microsecond,
+		want:    "select a from t",
+	}, {
+		query:   "with x as (select a from t) select a from x",
+		timeout: time.Second,
+		want:    "with x as (select a from t) select a from x",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, addMaxExecutionTimeHint(tt.query, tt.timeout))
+		})
+	}
+}
+
 // TestQueryExecutorSelectImpossible is separate because it's a special case
 // because the "in transaction" case is a no-op.
 func TestQueryExecutorSelectImpossible(t *testing.T) {
@@ -1135,6 +1245,64 @@ func TestQueryExecutorTableAclDryRun(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorTableAclDryRunConfig(t *testing.T) {
+	aclName := fmt.Sprintf("simpleacl-test-%d", rand.Int64())
+	tableacl.Register(aclName, &simpleacl.Factory{})
+	tableacl.SetDefaultACL(aclName)
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows:   [][]sqltypes.Value{},
+	}
+	db.AddQuery(query, want)
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	username := "u1"
+	callerID := &querypb.VTGateCallerID{
+		Username: username,
+	}
+	ctx := callerid.NewContext(context.Background(), nil, callerID)
+
+	err := tableacl.InitFromProto(&tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group02",
+			TableNamesOrPrefixes: []string{"test_table"},
+			Readers:              []string{"u1"},
+		}},
+	})
+	require.NoError(t, err)
+	// The dry run config no longer lets u1 read test_table.
+	err = tableacl.InitDryRunFromProto(&tableaclpb.Config{
+		TableGroups: []*tableaclpb.TableGroupSpec{{
+			Name:                 "group03",
+			TableNamesOrPrefixes: []string{"test_table"},
+			Readers:              []string{"u2"},
+		}},
+	})
+	require.NoError(t, err)
+	defer tableacl.ClearDryRun()
+
+	tableACLStatsKey := strings.Join([]string{
+		"test_table",
+		"group03",
+		planbuilder.PlanSelect.String(),
+		username,
+	}, ".")
+	tsv := newTestTabletServer(ctx, enableStrictTableACL, db)
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	defer tsv.StopService()
+	beforeCount := tsv.stats.TableaclDryRunDenied.Counts()[tableACLStatsKey]
+	// The query is allowed by the enforced config, the dry run denial is only counted.
+	_, err = qre.Execute()
+	require.NoError(t, err)
+	afterCount := tsv.stats.TableaclDryRunDenied.Counts()[tableACLStatsKey]
+	assert.EqualValues(t, 1, afterCount-beforeCount)
+}
+
 func TestQueryExecutorDenyListQRFail(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -1242,6 +1410,84 @@ func TestQueryExecutorDenyListQRRetry(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorDeniedTables(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	deniedRule := rules.NewQueryRule(rules.DeniedTablesRuleDescription, "denied_table", rules.QRFailRetry)
+	deniedRule.AddTableCond("test_table")
+
+	rulesName := "deniedTables"
+	qrs := rules.New()
+	qrs.Add(deniedRule)
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	require.NoError(t, tsv.qe.queryRuleSources.SetRules(rulesName, qrs))
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	defer tsv.StopService()
+
+	_, err := qre.Execute()
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualValues(t, 1, tsv.Stats().DeniedTableQueries.Counts()["test_table"])
+}
+
+func TestQueryExecutorUnsafeStatements(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "update test_table set name_string = 'a' limit 1"
+	db.AddQuery(query, &sqltypes.Result{RowsAffected: 1})
+	binlogFormat := func(format string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("@@global.binlog_format", "varchar"), format)
+	}
+	db.AddQuery("select @@global.binlog_format", binlogFormat("STATEMENT"))
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	// The unsafe statements are executed as usual when the check is disabled.
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	assert.Equal(t, "LIMIT without ORDER BY", qre.plan.UnsafeReason)
+	_, err := qre.Execute()
+	require.NoError(t, err)
+
+	// They are only counted in warn mode.
+	tsv.config.UnsafeStatementMode = tabletenv.Warn
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, tsv.Stats().UnsafeStatements.Counts()["test_table.warn"])
+
+	// They fail in reject mode.
+	tsv.config.UnsafeStatementMode = tabletenv.Reject
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.ErrorContains(t, err, "VT09026: statement is unsafe for statement-based replication: LIMIT without ORDER BY")
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualValues(t, 1, tsv.Stats().UnsafeStatements.Counts()["test_table.reject"])
+
+	// The safe statements and the local queries are not rejected.
+	safeQuery := "update test_table set name_string = 'a' where pk = 1"
+	db.AddQuery(safeQuery+" limit 10001", &sqltypes.Result{RowsAffected: 1})
+	_, err = newTestQueryExecutor(ctx, tsv, safeQuery, 0).Execute()
+	require.NoError(t, err)
+	_, err = newTestQueryExecutor(tabletenv.LocalContext(), tsv, query, 0).Execute()
+	require.NoError(t, err)
+
+	// Nor are the unsafe statements when MySQL logs the rows in its binlog.
+	tsv.qe.binlogFormat = ""
+	db.AddQuery("select @@global.binlog_format", binlogFormat("ROW"))
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, tsv.Stats().UnsafeStatements.Counts()["test_table.reject"])
+}
+
 func TestReplaceSchemaName(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -1300,6 +1546,161 @@ func TestReplaceSchemaName(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorStreamChunkSize(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+
+	query := "select * from test_table"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt32(1), sqltypes.NewInt32(10), sqltypes.NewInt32(100)},
+			{sqltypes.NewInt32(2), sqltypes.NewInt32(20), sqltypes.NewInt32(200)},
+			{sqltypes.NewInt32(3), sqltypes.NewInt32(30), sqltypes.NewInt32(300)},
+		},
+	}
+	db.AddQuery(query, want)
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	qre := newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	qre.options = &querypb.ExecuteOptions{
+		StreamChunkMaxRows:  2,
+		StreamChunkMaxBytes: 1 << 40,
+	}
+	chunkRows, chunkBytes := qre.streamChunkSize()
+	assert.Equal(t, 2, chunkRows)
+	assert.Equal(t, tsv.config.MaxStreamBufferSize, chunkBytes)
+
+	var rowCounts []int
+	got := &sqltypes.Result{}
+	err := qre.Stream(func(qr *sqltypes.Result) error {
+		if qr.Fields != nil {
+			got.Fields = qr.Fields
+		}
+		if len(qr.Rows) > 0 {
+			rowCounts = append(rowCounts, len(qr.Rows))
+			got.Rows = append(got.Rows, qr.Rows...)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, rowCounts)
+	assert.Equal(t, want.Rows, got.Rows)
+}
+
+func TestQueryExecutorStreamBackpressure(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+
+	query := "select * from test_table"
+	db.AddQuery(query, &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{
+			{sqltypes.NewInt32(1), sqltypes.NewInt32(10), sqltypes.NewInt32(100)},
+			{sqltypes.NewInt32(2), sqltypes.NewInt32(20), sqltypes.NewInt32(200)},
+		},
+	})
+
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("slow_consumer", "", ""), nil)
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.config.StreamBackpressureThreshold = 10 * time.Millisecond
+	var events []StreamBackpressure
+	tsv.SetStreamBackpressureHook(func(event StreamBackpressure) {
+		events = append(events, event)
+	})
+
+	qre := newTestQueryExecutorStreaming(ctx, tsv, query, 0)
+	qre.options = &querypb.ExecuteOptions{
+		StreamChunkMaxRows:  1,
+		StreamChunkMaxBytes: 1 << 40,
+	}
+	// The client is slow to receive the first row only.
+	var rows int
+	err := qre.Stream(func(qr *sqltypes.Result) error {
+		if len(qr.Rows) > 0 {
+			if rows == 0 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			rows += len(qr.Rows)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, rows)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "slow_consumer", events[0].CallerID)
+	assert.Equal(t, "test_table", events[0].Table)
+	assert.Equal(t, query, events[0].Query)
+	assert.GreaterOrEqual(t, events[0].Blocked, 20*time.Millisecond)
+
+	key := "test_table.slow_consumer"
+	assert.EqualValues(t, 1, tsv.stats.UserStreamBlockedCount.Counts()[key])
+	assert.GreaterOrEqual(t, tsv.stats.UserStreamBlockedNs.Counts()[key], int64(20*time.Millisecond))
+	count, blocked := qre.plan.StreamBlockedStats()
+	assert.EqualValues(t, 1, count)
+	assert.Equal(t, events[0].Blocked, blocked)
+}
+
+func TestQueryExecutorQueryPoolQueue(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.config.OltpReadPool.QueueSLO = time.Millisecond
+	rejectedStart := tsv.stats.QueryPoolQueueRejections.Counts()
+	queuedStart := tsv.stats.QueryPoolQueueTimings.Counts()["TabletServerTest.oltp"]
+	sloExceededStart := tsv.stats.QueryPoolQueueSLOExceeded.Counts()["oltp"]
+
+	// Exhaust the query pool, and let a single query wait for a connection.
+	tsv.qe.conns.SetCapacity(1)
+	tsv.qe.conns.SetMaxWaiters(1)
+	held, err := tsv.qe.conns.Get(ctx, nil)
+	require.NoError(t, err)
+
+	newQRE := func(ctx context.Context, workload string) *QueryExecutor {
+		qre := newTestQueryExecutor(ctx, tsv, "select * from test_table limit 1000", 0)
+		qre.options = &querypb.ExecuteOptions{WorkloadName: workload, Priority: "10"}
+		return qre
+	}
+	got := make(chan error)
+	go func() {
+		conn, err := newQRE(ctx, "oltp").getConn()
+		if err == nil {
+			conn.Recycle()
+		}
+		got <- err
+	}()
+	for tsv.qe.conns.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The queue is full, so the next query is rejected right away.
+	_, err = newQRE(ctx, "batch").getConn()
+	require.ErrorIs(t, err, smartconnpool.ErrQueueFull)
+
+	// Without a limit, the query waits until its deadline.
+	tsv.qe.conns.SetMaxWaiters(0)
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = newQRE(shortCtx, "batch").getConn()
+	require.ErrorIs(t, err, smartconnpool.ErrTimeout)
+
+	time.Sleep(2 * time.Millisecond)
+	held.Recycle()
+	require.NoError(t, <-got)
+
+	assert.EqualValues(t, 1, tsv.stats.QueryPoolQueueRejections.Counts()["batch.QueueFull"]-rejectedStart["batch.QueueFull"])
+	assert.EqualValues(t, 1, tsv.stats.QueryPoolQueueRejections.Counts()["batch.Deadline"]-rejectedStart["batch.Deadline"])
+	assert.EqualValues(t, 1, tsv.stats.QueryPoolQueueTimings.Counts()["TabletServerTest.oltp"]-queuedStart)
+	assert.EqualValues(t, 1, tsv.stats.QueryPoolQueueSLOExceeded.Counts()["oltp"]-sloExceededStart)
+}
+
 func TestQueryExecutorShouldConsolidate(t *testing.T) {
 	testCases := []struct {
 		// whether or not the consolidator is enabled by default on the tablet
@@ -1487,6 +1888,7 @@ const (
 	smallResultSize
 	disableOnlineDDL
 	enableConsolidator
+	enableFieldCache
 )
 
 // newTestQueryExecutor uses a package level variable testTabletServer defined in tabletserver_test.go
@@ -1527,6 +1929,9 @@ func newTestTabletServer(ctx context.Context, flags executorFlags, db *fakesqldb
 	} else {
 		cfg.Consolidator = tabletenv.Disable
 	}
+	if flags&enableFieldCache > 0 {
+		cfg.EnableFieldCache = true
+	}
 	dbconfigs := newDBConfigs(db)
 	cfg.DB = dbconfigs
 	srvTopoCounts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// QueryInterceptor inspects the queries of the users before the tablet
+// executes them, to enforce the policies of an organization, e.g. detect
+// personal data or guard against costly queries, without changing the query
+// engine. Interceptors are compiled in: they register themselves with
+// RegisterQueryInterceptor, usually from an init function, and are enabled
+// in order with --query-interceptors.
+type QueryInterceptor interface {
+	// Intercept is called before the query is executed. It can annotate the
+	// query, and rejects it by returning an error, which is returned to the
+	// client. It must not modify the plan nor the bind variables.
+	Intercept(ctx context.Context, query *InterceptedQuery) error
+}
+
+// QueryInterceptorFunc adapts a function to the QueryInterceptor interface.
+type QueryInterceptorFunc func(ctx context.Context, query *InterceptedQuery) error
+
+// Intercept is part of the QueryInterceptor interface.
+func (f QueryInterceptorFunc) Intercept(ctx context.Context, query *InterceptedQuery) error {
+	return f(ctx, query)
+}
+
+// InterceptedQuery is a query seen by the query interceptors.
+type InterceptedQuery struct {
+	// Method is Execute or Stream.
+	Method string
+	// SQL is the query, without its margin comments.
+	SQL           string
+	BindVariables map[string]*querypb.BindVariable
+	// Plan is the plan of the query, with its type and its tables.
+	Plan *planbuilder.Plan
+
+	// EffectiveCallerID and ImmediateCallerID identify the caller, they can
+	// be nil.
+	EffectiveCallerID *vtrpcpb.CallerID
+	ImmediateCallerID *querypb.VTGateCallerID
+
+	// TransactionID and ReservedID are the transaction and the reserved
+	// connection the query is executed in, 0 if none.
+	TransactionID int64
+	ReservedID    int64
+	TabletType    topodatapb.TabletType
+
+	comments []string
+}
+
+// InTransaction returns whether the query is executed in a transaction.
+func (q *InterceptedQuery) InTransaction() bool {
+	return q.TransactionID != 0
+}
+
+// Annotate adds a comment to the query sent to MySQL, e.g. for it to show up
+// in the slow query log.
+func (q *InterceptedQuery) Annotate(comment string) {
+	q.comments = append(q.comments, comment)
+}
+
+var (
+	queryInterceptorsMu sync.Mutex
+	queryInterceptors   = make(map[string]QueryInterceptor)
+)
+
+// RegisterQueryInterceptor registers a query interceptor under a name, for it
+// to be enabled with --query-interceptors.
+func RegisterQueryInterceptor(name string, interceptor QueryInterceptor) {
+	queryInterceptorsMu.Lock()
+	defer queryInterceptorsMu.Unlock()
+	if _, ok := queryInterceptors[name]; ok {
+		panic(fmt.Sprintf("query interceptor %s is already registered", name))
+	}
+	queryInterceptors[name] = interceptor
+}
+
+type namedQueryInterceptor struct {
+	name        string
+	interceptor QueryInterceptor
+}
+
+// enabledQueryInterceptors returns the registered query interceptors with
+// the given names, in order.
+func enabledQueryInterceptors(names []string) ([]namedQueryInterceptor, error) {
+	queryInterceptorsMu.Lock()
+	defer queryInterceptorsMu.Unlock()
+	var enabled []namedQueryInterceptor
+	for _, name := range names {
+		interceptor, ok := queryInterceptors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown query interceptor %s", name)
+		}
+		enabled = append(enabled, namedQueryInterceptor{name: name, interceptor: interceptor})
+	}
+	return enabled, nil
+}
+
+// intercept passes the query to the enabled query interceptors, and adds
+// their annotations to the query sent to MySQL.
+func (qre *QueryExecutor) intercept(method string) error {
+	interceptors := qre.tsv.queryInterceptors
+	if len(interceptors) == 0 || tabletenv.IsLocalContext(qre.ctx) {
+		return nil
+	}
+	query := &InterceptedQuery{
+		Method:            method,
+		SQL:               qre.query,
+		BindVariables:     qre.bindVars,
+		Plan:              qre.plan.Plan,
+		EffectiveCallerID: callerid.EffectiveCallerIDFromContext(qre.ctx),
+		ImmediateCallerID: callerid.ImmediateCallerIDFromContext(qre.ctx),
+		TransactionID:     qre.logStats.TransactionID,
+		ReservedID:        qre.logStats.ReservedID,
+		TabletType:        qre.targetTabletType,
+	}
+	for _, qi := range interceptors {
+		if err := qi.interceptor.Intercept(qre.ctx, query); err != nil {
+			qre.tsv.Stats().InterceptorRejections.Add(qi.name, 1)
+			if vterrors.Code(err) == vtrpcpb.Code_UNKNOWN {
+				err = vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, err.Error())
+			}
+			return vterrors.Wrapf(err, "rejected by query interceptor %s", qi.name)
+		}
+	}
+	if len(query.comments) == 0 {
+		return nil
+	}
+	var buf strings.Builder
+	for _, comment := range query.comments {
+		buf.WriteString("/* ")
+		// The comment must not end the MySQL comment.
+		buf.WriteString(strings.ReplaceAll(comment, "*/", "* /"))
+		buf.WriteString(" */ ")
+	}
+	buf.WriteString(qre.marginComments.Leading)
+	qre.marginComments.Leading = buf.String()
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/vterrors"
+	p "vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestRegisterQueryInterceptor(t *testing.T) {
+	noop := QueryInterceptorFunc(func(context.Context, *InterceptedQuery) error { return nil })
+	RegisterQueryInterceptor("test_register_1", noop)
+	RegisterQueryInterceptor("test_register_2", noop)
+	assert.Panics(t, func() { RegisterQueryInterceptor("test_register_1", noop) })
+
+	enabled, err := enabledQueryInterceptors([]string{"test_register_2", "test_register_1"})
+	require.NoError(t, err)
+	require.Len(t, enabled, 2)
+	assert.Equal(t, "test_register_2", enabled[0].name)
+	assert.Equal(t, "test_register_1", enabled[1].name)
+
+	_, err = enabledQueryInterceptors([]string{"test_register_1", "unknown"})
+	assert.EqualError(t, err, "unknown query interceptor unknown")
+}
+
+func TestQueryExecutorIntercept(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	want := &sqltypes.Result{}
+	db.AddQuery(query, want)
+	db.AddQuery("/* guarded */ /* by me */ "+query, want)
+
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("me", "", ""), nil)
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	var seen []*InterceptedQuery
+	tsv.queryInterceptors = []namedQueryInterceptor{{
+		name: "annotate",
+		interceptor: QueryInterceptorFunc(func(ctx context.Context, query *InterceptedQuery) error {
+			seen = append(seen, query)
+			query.Annotate("guarded")
+			query.Annotate("by " + query.EffectiveCallerID.Principal)
+			return nil
+		}),
+	}, {
+		name: "cost_guard",
+		interceptor: QueryInterceptorFunc(func(ctx context.Context, query *InterceptedQuery) error {
+			switch query.Plan.PlanID {
+			case p.PlanDelete, p.PlanDeleteLimit:
+				if !query.InTransaction() {
+					return errors.New("deletes must be executed in a transaction")
+				}
+			}
+			return nil
+		}),
+	}}
+
+	db.ResetQueryLog()
+	_, err := newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, "/* guarded */ /* by me */ "+query, db.QueryLog())
+	require.Len(t, seen, 1)
+	assert.Equal(t, "Execute", seen[0].Method)
+	assert.Equal(t, query, seen[0].SQL)
+	assert.Equal(t, p.PlanSelect, seen[0].Plan.PlanID)
+	assert.Equal(t, "test_table", seen[0].Plan.TableName().String())
+	assert.Zero(t, seen[0].TransactionID)
+
+	_, err = newTestQueryExecutor(ctx, tsv, "delete from test_table where pk = 1", 0).Execute()
+	require.EqualError(t, err, "rejected by query interceptor cost_guard: deletes must be executed in a transaction")
+	assert.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err))
+	assert.EqualValues(t, 1, tsv.Stats().InterceptorRejections.Counts()["cost_guard"])
+
+	// The local queries are not intercepted.
+	seen = nil
+	_, err = newTestQueryExecutor(tabletenv.LocalContext(), tsv, query, 0).Execute()
+	require.NoError(t, err)
+	assert.Empty(t, seen)
+}
+
+func TestQueryExecutorInterceptStream(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	db.AddQuery(query, &sqltypes.Result{})
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.queryInterceptors = []namedQueryInterceptor{{
+		name: "reject",
+		interceptor: QueryInterceptorFunc(func(ctx context.Context, query *InterceptedQuery) error {
+			assert.Equal(t, "Stream", query.Method)
+			return vterrors.Errorf(vtrpcpb.Code_PERMISSION_DENIED, "no streaming")
+		}),
+	}}
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	err := qre.Stream(func(*sqltypes.Result) error { return nil })
+	require.EqualError(t, err, "rejected by query interceptor reject: no streaming")
+	assert.Equal(t, vtrpcpb.Code_PERMISSION_DENIED, vterrors.Code(err))
+}
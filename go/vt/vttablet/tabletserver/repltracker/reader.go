@@ -76,6 +76,8 @@ func newHeartbeatReader(env tabletenv.Env) *heartbeatReader {
 		enabled:  true,
 		now:      time.Now,
 		interval: heartbeatInterval,
+		// The lag is reported in the health stream, so the reads don't
+		// back off while the tablet is idle.
 		ticks:    timer.NewTimer(heartbeatInterval),
 		errorLog: logutil.NewThrottledLogger("HeartbeatReporter", 60*time.Second),
 		pool: connpool.NewPool(env, "HeartbeatReadPool", tabletenv.ConnPoolConfig{
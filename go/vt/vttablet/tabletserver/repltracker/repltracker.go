@@ -144,6 +144,38 @@ func (rt *ReplTracker) Status() (time.Duration, error) {
 	return rt.poller.Status()
 }
 
+// LagSignals reports the replication lag as measured by each of the signals
+// available in the current mode, given the lag and error that Status just
+// returned, so that the replication status is not fetched twice. The lag of
+// the replication SQL thread is always measured, while the heartbeat lag is
+// only measured in heartbeat mode. It returns nil for a primary or when
+// replication tracking is disabled.
+func (rt *ReplTracker) LagSignals(lag time.Duration, err error) *querypb.ReplicationLagSignals {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.isPrimary || rt.mode == tabletenv.Disable {
+		return nil
+	}
+
+	signals := &querypb.ReplicationLagSignals{}
+	if rt.mode == tabletenv.Heartbeat {
+		if err == nil {
+			signals.HeartbeatLagSeconds = lagSeconds(lag)
+		}
+		lag, err = rt.poller.Status()
+	}
+	if err == nil {
+		signals.SqlThreadLagSeconds = lagSeconds(lag)
+	}
+	return signals
+}
+
+func lagSeconds(lag time.Duration) *uint32 {
+	seconds := uint32(lag.Seconds())
+	return &seconds
+}
+
 // EnableHeartbeat enables or disables writes of heartbeat. This functionality
 // is only used by tests.
 func (rt *ReplTracker) EnableHeartbeat(enable bool) {
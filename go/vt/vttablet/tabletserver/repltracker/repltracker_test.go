@@ -96,3 +96,57 @@ func TestReplTracker(t *testing.T) {
 	_, err = rt.Status()
 	assert.Equal(t, "err", err.Error())
 }
+
+func TestReplTrackerLagSignals(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.ReplicationTracker.Mode = tabletenv.Heartbeat
+	cfg.ReplicationTracker.HeartbeatInterval = time.Second
+	params := db.ConnParams()
+	cp := *params
+	cfg.DB = dbconfigs.NewTestDBConfigs(cp, cp, "")
+	env := tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "ReplTrackerTest")
+	alias := &topodatapb.TabletAlias{
+		Cell: "cell",
+		Uid:  1,
+	}
+	target := &querypb.Target{}
+	mysqld := mysqlctl.NewFakeMysqlDaemon(nil)
+	mysqld.Replicating = true
+	mysqld.ReplicationLagSeconds = 2
+
+	rt := NewReplTracker(env, alias)
+	rt.InitDBConfig(target, mysqld)
+	defer rt.Close()
+
+	rt.MakePrimary()
+	assert.Nil(t, rt.LagSignals(rt.Status()))
+
+	rt.MakeNonPrimary()
+	rt.hr.lastKnownLag = 5 * time.Second
+	signals := rt.LagSignals(rt.Status())
+	assert.EqualValues(t, 2, *signals.SqlThreadLagSeconds)
+	assert.EqualValues(t, 5, *signals.HeartbeatLagSeconds)
+
+	rt.hr.lastKnownError = errors.New("err")
+	signals = rt.LagSignals(rt.Status())
+	assert.EqualValues(t, 2, *signals.SqlThreadLagSeconds)
+	assert.Nil(t, signals.HeartbeatLagSeconds)
+	rt.Close()
+
+	// Without a heartbeat, only the SQL thread lag is measured, and it is
+	// the lag that Status already fetched.
+	cfg.ReplicationTracker.Mode = tabletenv.Polling
+	rt = NewReplTracker(env, alias)
+	rt.InitDBConfig(target, mysqld)
+	rt.MakeNonPrimary()
+	signals = rt.LagSignals(7*time.Second, nil)
+	assert.EqualValues(t, 7, *signals.SqlThreadLagSeconds)
+	assert.Nil(t, signals.HeartbeatLagSeconds)
+
+	mysqld.ReplicationStatusError = errors.New("err")
+	signals = rt.LagSignals(rt.Status())
+	assert.Nil(t, signals.SqlThreadLagSeconds)
+}
@@ -38,6 +38,14 @@ import (
 
 const (
 	bufferedTableRuleName = "buffered_table"
+
+	// DeniedTablesRuleDescription is the description of the rule that
+	// enforces the denied tables of the shard tablet controls.
+	DeniedTablesRuleDescription = "enforce denied tables"
+
+	// TableKillSwitchRuleDescription prefixes the description of the rules
+	// that enforce the table kill switches.
+	TableKillSwitchRuleDescription = "table kill switch"
 )
 
 // Rules is used to store and execute rules for the tabletserver.
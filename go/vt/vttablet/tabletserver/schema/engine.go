@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,7 +38,6 @@ import (
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/stats"
-	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/dbconfigs"
 	"vitess.io/vitess/go/vt/dbconnpool"
@@ -85,9 +85,11 @@ type Engine struct {
 	SkipMetaCheck bool
 
 	historian *historian
+	// history keeps the last versions of the schema observed by reload.
+	history *schemaHistory
 
 	conns         *connpool.Pool
-	ticks         *timer.Timer
+	ticks         *tabletenv.IdleTimer
 	reloadTimeout time.Duration
 
 	// dbCreationFailed is for preventing log spam.
@@ -110,7 +112,7 @@ func NewEngine(env tabletenv.Env) *Engine {
 			Size:        3,
 			IdleTimeout: env.Config().OltpReadPool.IdleTimeout,
 		}),
-		ticks: timer.NewTimer(reloadTime),
+		ticks: env.Activity().NewTimer("SchemaReload", reloadTime),
 	}
 	se.schemaCopy = env.Config().SignalWhenSchemaChange
 	_ = env.Exporter().NewGaugeDurationFunc("SchemaReloadTime", "vttablet keeps table schemas in its own memory and periodically refreshes it from MySQL. This config controls the reload time.", se.ticks.Interval)
@@ -120,6 +122,7 @@ func NewEngine(env tabletenv.Env) *Engine {
 	se.SchemaReloadTimings = env.Exporter().NewTimings("SchemaReload", "time taken to reload the schema", "type")
 	se.reloadTimeout = env.Config().SchemaChangeReloadTimeout
 	env.Exporter().HandleFunc("/debug/schema", se.handleDebugSchema)
+	env.Exporter().HandleFunc("/debug/schema/history", se.handleDebugSchemaHistory)
 	env.Exporter().HandleFunc("/schemaz", func(w http.ResponseWriter, r *http.Request) {
 		// Ensure schema engine is Open. If vttablet came up in a non_serving role,
 		// the schema engine may not have been initialized.
@@ -132,6 +135,7 @@ func NewEngine(env tabletenv.Env) *Engine {
 		schemazHandler(se.GetSchema(), w, r)
 	})
 	se.historian = newHistorian(env.Config().TrackSchemaVersions, env.Config().SchemaVersionMaxAgeSeconds, se.conns)
+	se.history = newSchemaHistory(env.Config().SchemaHistorySize)
 	return se
 }
 
@@ -542,6 +546,8 @@ func (se *Engine) reload(ctx context.Context, includeStats bool) error {
 		}
 	}
 
+	se.history.record(time.Now(), se.tables, created, altered, dropped)
+
 	// Update se.tables
 	for k, t := range changedTables {
 		se.tables[k] = t
@@ -818,6 +824,36 @@ func (se *Engine) handleHTTPSchema(response http.ResponseWriter) {
 	response.Write(buf.Bytes())
 }
 
+// handleDebugSchemaHistory serves the versions of the schema kept in the
+// history, oldest first. The first version has every table of the schema
+// as created. If the since parameter is set, only the versions after it
+// are returned.
+func (se *Engine) handleDebugSchemaHistory(response http.ResponseWriter, request *http.Request) {
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+	var since int64
+	if param := request.URL.Query().Get("since"); param != "" {
+		var err error
+		since, err = strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			http.Error(response, fmt.Sprintf("invalid since parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	b, err := json.MarshalIndent(se.history.since(since), "", " ")
+	if err != nil {
+		response.Write([]byte(err.Error()))
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	json.HTMLEscape(buf, b)
+	response.Write(buf.Bytes())
+}
+
 // Test methods. Do not use in non-test code.
 
 // NewEngineForTests creates a new engine, that can't query the
@@ -828,6 +864,7 @@ func NewEngineForTests() *Engine {
 		isOpen:    true,
 		tables:    make(map[string]*Table),
 		historian: newHistorian(false, 0, nil),
+		history:   newSchemaHistory(0),
 		env:       tabletenv.NewEnv(vtenv.NewTestEnv(), tabletenv.NewDefaultConfig(), "SchemaEngineForTests"),
 	}
 	return se
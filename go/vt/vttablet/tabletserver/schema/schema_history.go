@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// SchemaVersion is a version of the schema observed by the engine when it
+// reloaded the schema, with the changes from the previous version.
+type SchemaVersion struct {
+	Version int64        `json:"version"`
+	Time    time.Time    `json:"time"`
+	Created []string     `json:"created,omitempty"`
+	Altered []*TableDiff `json:"altered,omitempty"`
+	Dropped []string     `json:"dropped,omitempty"`
+}
+
+// TableDiff is the change of a table between two versions of the schema.
+// The changed columns are described as "name: old type -> new type".
+type TableDiff struct {
+	Name              string   `json:"name"`
+	AddedColumns      []string `json:"addedColumns,omitempty"`
+	DroppedColumns    []string `json:"droppedColumns,omitempty"`
+	ChangedColumns    []string `json:"changedColumns,omitempty"`
+	OldPrimaryKey     []string `json:"oldPrimaryKey,omitempty"`
+	NewPrimaryKey     []string `json:"newPrimaryKey,omitempty"`
+	PrimaryKeyChanged bool     `json:"primaryKeyChanged,omitempty"`
+}
+
+// schemaHistory keeps the last versions of the schema observed by the engine,
+// so that operators can find out what changed and when. It keeps at most size
+// versions, and none if size is not positive.
+type schemaHistory struct {
+	mu       sync.Mutex
+	size     int
+	version  int64
+	versions []*SchemaVersion
+}
+
+func newSchemaHistory(size int) *schemaHistory {
+	return &schemaHistory{size: size}
+}
+
+// record adds a version of the schema if any table was created, altered or
+// dropped. previous is the schema before the changes, in which the altered
+// tables are looked up to compute their diffs.
+func (sh *schemaHistory) record(now time.Time, previous map[string]*Table, created, altered, dropped []*Table) {
+	if sh.size <= 0 || len(created)+len(altered)+len(dropped) == 0 {
+		return
+	}
+	version := &SchemaVersion{
+		Time:    now,
+		Created: extractNamesFromTablesList(created),
+		Dropped: extractNamesFromTablesList(dropped),
+	}
+	for _, table := range altered {
+		diff := diffTables(previous[table.Name.String()], table)
+		version.Altered = append(version.Altered, diff)
+	}
+	sort.Strings(version.Created)
+	sort.Strings(version.Dropped)
+	sort.Slice(version.Altered, func(i, j int) bool {
+		return version.Altered[i].Name < version.Altered[j].Name
+	})
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.version++
+	version.Version = sh.version
+	sh.versions = append(sh.versions, version)
+	if len(sh.versions) > sh.size {
+		sh.versions = slices.Delete(sh.versions, 0, len(sh.versions)-sh.size)
+	}
+}
+
+// since returns the versions kept after the given one, oldest first.
+func (sh *schemaHistory) since(version int64) []*SchemaVersion {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	i := sort.Search(len(sh.versions), func(i int) bool {
+		return sh.versions[i].Version > version
+	})
+	return slices.Clone(sh.versions[i:])
+}
+
+// diffTables returns the changes of a table from its previous version, which
+// is nil if it is unknown.
+func diffTables(previous, table *Table) *TableDiff {
+	diff := &TableDiff{Name: table.Name.String()}
+	if previous == nil {
+		return diff
+	}
+	previousFields := make(map[string]*querypb.Field, len(previous.Fields))
+	for _, field := range previous.Fields {
+		previousFields[field.Name] = field
+	}
+	fields := make(map[string]bool, len(table.Fields))
+	for _, field := range table.Fields {
+		fields[field.Name] = true
+		previousField, ok := previousFields[field.Name]
+		switch {
+		case !ok:
+			diff.AddedColumns = append(diff.AddedColumns, field.Name)
+		case columnType(previousField) != columnType(field):
+			diff.ChangedColumns = append(diff.ChangedColumns, fmt.Sprintf("%s: %s -> %s", field.Name, columnType(previousField), columnType(field)))
+		}
+	}
+	for _, field := range previous.Fields {
+		if !fields[field.Name] {
+			diff.DroppedColumns = append(diff.DroppedColumns, field.Name)
+		}
+	}
+	oldPK, newPK := primaryKey(previous), primaryKey(table)
+	if !slices.Equal(oldPK, newPK) {
+		diff.OldPrimaryKey, diff.NewPrimaryKey = oldPK, newPK
+		diff.PrimaryKeyChanged = true
+	}
+	return diff
+}
+
+// columnType returns the MySQL type of a column, or its Vitess type if the
+// MySQL one is unknown.
+func columnType(field *querypb.Field) string {
+	if field.ColumnType != "" {
+		return field.ColumnType
+	}
+	return field.Type.String()
+}
+
+// primaryKey returns the names of the primary key columns of a table.
+func primaryKey(table *Table) []string {
+	var names []string
+	for _, i := range table.PKColumns {
+		if i < len(table.Fields) {
+			names = append(names, table.Fields[i].Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func newHistoryTable(name string, pk []int, fields ...*querypb.Field) *Table {
+	table := NewTable(name, NoType)
+	table.Fields = fields
+	table.PKColumns = pk
+	return table
+}
+
+func TestSchemaHistory(t *testing.T) {
+	id := &querypb.Field{Name: "id", Type: sqltypes.Int32, ColumnType: "int"}
+	bigID := &querypb.Field{Name: "id", Type: sqltypes.Int64, ColumnType: "bigint"}
+	name := &querypb.Field{Name: "name", Type: sqltypes.VarChar, ColumnType: "varchar(10)"}
+	email := &querypb.Field{Name: "email", Type: sqltypes.VarChar}
+
+	sh := newSchemaHistory(2)
+	now := time.Now()
+	t1 := newHistoryTable("t1", []int{0}, id, name)
+	t2 := newHistoryTable("t2", nil, id)
+	sh.record(now, map[string]*Table{}, []*Table{t2, t1}, nil, nil)
+	// Reloads without changes are not recorded.
+	sh.record(now, map[string]*Table{"t1": t1, "t2": t2}, nil, nil, nil)
+
+	t1Altered := newHistoryTable("t1", []int{1, 0}, email, bigID)
+	sh.record(now.Add(time.Minute), map[string]*Table{"t1": t1, "t2": t2}, nil, []*Table{t1Altered}, []*Table{t2})
+
+	versions := sh.since(0)
+	require.Len(t, versions, 2)
+	assert.Equal(t, &SchemaVersion{Version: 1, Time: now, Created: []string{"t1", "t2"}}, versions[0])
+	assert.Equal(t, &SchemaVersion{
+		Version: 2,
+		Time:    now.Add(time.Minute),
+		Altered: []*TableDiff{{
+			Name:              "t1",
+			AddedColumns:      []string{"email"},
+			DroppedColumns:    []string{"name"},
+			ChangedColumns:    []string{"id: int -> bigint"},
+			OldPrimaryKey:     []string{"id"},
+			NewPrimaryKey:     []string{"id", "email"},
+			PrimaryKeyChanged: true,
+		}},
+		Dropped: []string{"t2"},
+	}, versions[1])
+
+	// The oldest versions are evicted once the history is full.
+	sh.record(now.Add(2*time.Minute), map[string]*Table{"t1": t1Altered}, []*Table{t2}, nil, nil)
+	versions = sh.since(0)
+	require.Len(t, versions, 2)
+	assert.EqualValues(t, 2, versions[0].Version)
+	assert.EqualValues(t, 3, versions[1].Version)
+	versions = sh.since(2)
+	require.Len(t, versions, 1)
+	assert.EqualValues(t, 3, versions[0].Version)
+	assert.Empty(t, sh.since(3))
+
+	// A history without size keeps nothing.
+	sh = newSchemaHistory(0)
+	sh.record(now, map[string]*Table{}, []*Table{t1}, nil, nil)
+	assert.Empty(t, sh.since(0))
+}
+
+func TestSchemaHistoryURL(t *testing.T) {
+	se := NewEngineForTests()
+	se.history = newSchemaHistory(10)
+	t1 := newHistoryTable("t1", nil, &querypb.Field{Name: "id", Type: sqltypes.Int32})
+	se.history.record(time.Now(), map[string]*Table{}, []*Table{t1}, nil, nil)
+	se.history.record(time.Now(), map[string]*Table{"t1": t1}, nil, nil, []*Table{t1})
+
+	request := httptest.NewRequest("GET", "/debug/schema/history?since=1", nil)
+	response := httptest.NewRecorder()
+	se.handleDebugSchemaHistory(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	var versions []*SchemaVersion
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &versions))
+	require.Len(t, versions, 1)
+	assert.EqualValues(t, 2, versions[0].Version)
+	assert.Equal(t, []string{"t1"}, versions[0].Dropped)
+
+	request = httptest.NewRequest("GET", "/debug/schema/history?since=x", nil)
+	response = httptest.NewRecorder()
+	se.handleDebugSchemaHistory(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
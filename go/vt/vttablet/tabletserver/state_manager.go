@@ -152,6 +152,7 @@ type (
 		MakeNonPrimary()
 		Close()
 		Status() (time.Duration, error)
+		LagSignals(lag time.Duration, err error) *querypb.ReplicationLagSignals
 	}
 
 	queryEngine interface {
@@ -704,7 +705,11 @@ func (sm *stateManager) Broadcast() {
 	defer sm.mu.Unlock()
 
 	lag, err := sm.refreshReplHealthLocked()
-	sm.hs.ChangeState(sm.target.TabletType, sm.ptsTimestamp, lag, err, sm.isServingLocked())
+	var signals *querypb.ReplicationLagSignals
+	if sm.target.TabletType != topodatapb.TabletType_PRIMARY {
+		signals = sm.rt.LagSignals(lag, err)
+	}
+	sm.hs.ChangeState(sm.target.TabletType, sm.ptsTimestamp, lag, signals, err, sm.isServingLocked())
 }
 
 func (sm *stateManager) refreshReplHealthLocked() (time.Duration, error) {
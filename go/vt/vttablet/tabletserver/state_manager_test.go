@@ -630,6 +630,8 @@ func TestStateManagerWaitForRequests(t *testing.T) {
 func TestStateManagerNotify(t *testing.T) {
 	sm := newTestStateManager(t)
 	defer sm.StopService()
+	signals := &querypb.ReplicationLagSignals{SqlThreadLagSeconds: proto.Uint32(1)}
+	sm.rt.(*testReplTracker).signals = signals
 
 	blpFunc = testBlpFunc
 
@@ -652,6 +654,7 @@ func TestStateManagerNotify(t *testing.T) {
 	sm.Broadcast()
 
 	gotshr := <-ch
+	assert.Truef(t, proto.Equal(signals, gotshr.RealtimeStats.ReplicationLagSignals), "got: %v", gotshr.RealtimeStats.ReplicationLagSignals)
 	// Remove things we don't care about:
 	gotshr.RealtimeStats = nil
 	wantshr := &querypb.StreamHealthResponse{
@@ -836,8 +839,9 @@ func (te *testSchemaEngine) Close() {
 
 type testReplTracker struct {
 	testOrderState
-	lag time.Duration
-	err error
+	lag     time.Duration
+	err     error
+	signals *querypb.ReplicationLagSignals
 }
 
 func (te *testReplTracker) MakePrimary() {
@@ -859,6 +863,10 @@ func (te *testReplTracker) Status() (time.Duration, error) {
 	return te.lag, te.err
 }
 
+func (te *testReplTracker) LagSignals(time.Duration, error) *querypb.ReplicationLagSignals {
+	return te.signals
+}
+
 type testQueryEngine struct {
 	testOrderState
 
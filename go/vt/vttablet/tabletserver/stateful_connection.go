@@ -19,6 +19,7 @@ package tabletserver
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"vitess.io/vitess/go/mysql/sqlerror"
@@ -50,8 +51,24 @@ type StatefulConnection struct {
 	enforceTimeout bool
 	timeout        time.Duration
 	expiryTime     time.Time
+
+	// setupQueries are the queries that changed the session state of the
+	// reserved connection. They are run again if the MySQL connection has to
+	// be re-established.
+	setupQueries []string
+	// tempTables is set once a temporary table was created on the reserved
+	// connection. Its session state can then no longer be re-established.
+	tempTables bool
+	// untrackedState is set once a statement left session state that the
+	// setup queries do not describe, like a lock, on the reserved connection.
+	// Its session state can then no longer be re-established either.
+	untrackedState bool
 }
 
+// maxSetupQueries is the number of distinct setup queries recorded on a
+// reserved connection. Past it, its session state is no longer tracked.
+const maxSetupQueries = 64
+
 // Properties contains meta information about the connection
 type Properties struct {
 	EffectiveCaller *vtrpcpb.CallerID
@@ -93,7 +110,13 @@ func (sc *StatefulConnection) Exec(ctx context.Context, query string, maxrows in
 		if sc.IsInTransaction() {
 			return nil, vterrors.Errorf(vtrpcpb.Code_ABORTED, "transaction was aborted: %v", sc.txProps.Conclusion)
 		}
-		return nil, vterrors.New(vtrpcpb.Code_ABORTED, "connection was aborted")
+		if !sc.isRecoverable() {
+			return nil, vterrors.New(vtrpcpb.Code_ABORTED, "connection was aborted")
+		}
+		// The MySQL connection of the reserved connection was lost since it was last used.
+		if err := sc.reestablish(ctx, nil); err != nil {
+			return nil, err
+		}
 	}
 	r, err := sc.dbConn.Conn.ExecOnce(ctx, query, maxrows, wantfields)
 	if err != nil {
@@ -102,9 +125,21 @@ func (sc *StatefulConnection) Exec(ctx context.Context, query string, maxrows in
 			case <-ctx.Done():
 				// If the context is done, the query was killed.
 				// So, don't trigger a mysql check.
+				return nil, err
 			default:
 				sc.env.CheckMySQL()
 			}
+			if sc.isRecoverable() {
+				if sqlerror.IsConnLostDuringQuery(err) {
+					// The query may have run, so it is not retried.
+					sc.Stats().ReservedConnRecoveries.Add("Lost", 1)
+					return nil, sc.lostError(err)
+				}
+				if err := sc.reestablish(ctx, err); err != nil {
+					return nil, err
+				}
+				return sc.dbConn.Conn.ExecOnce(ctx, query, maxrows, wantfields)
+			}
 			return nil, err
 		}
 		return nil, err
@@ -112,6 +147,79 @@ func (sc *StatefulConnection) Exec(ctx context.Context, query string, maxrows in
 	return r, nil
 }
 
+// isRecoverable returns true for a reserved connection outside of a
+// transaction, whose MySQL connection can be re-established if it is lost.
+func (sc *StatefulConnection) isRecoverable() bool {
+	return sc.dbConn != nil && sc.IsTainted() && !sc.IsInTransaction()
+}
+
+// reestablish reconnects to MySQL and runs the setup queries of the reserved
+// connection again. If its session state cannot be rebuilt, the returned
+// error tells vtgate to recreate the reservation.
+func (sc *StatefulConnection) reestablish(ctx context.Context, cause error) error {
+	recoveries := sc.Stats().ReservedConnRecoveries
+	if sc.tempTables || sc.untrackedState {
+		recoveries.Add("Lost", 1)
+		return sc.lostError(cause)
+	}
+	// Reconnect applies the setting of the connection again.
+	err := sc.dbConn.Conn.Reconnect(ctx)
+	for _, query := range sc.setupQueries {
+		if err != nil {
+			break
+		}
+		_, err = sc.dbConn.Conn.ExecOnce(ctx, query, 0 /*maxrows*/, false /*wantFields*/)
+	}
+	if err != nil {
+		recoveries.Add("Lost", 1)
+		return sc.lostError(err)
+	}
+	recoveries.Add("Reestablished", 1)
+	return nil
+}
+
+// lostError returns the error for a reserved connection whose MySQL
+// connection was lost and could not be re-established.
+func (sc *StatefulConnection) lostError(cause error) error {
+	msg := vterrors.ReservedConnLost
+	if sc.tempTables {
+		msg = vterrors.ReservedConnTempTablesLost
+	}
+	if cause == nil {
+		return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, msg, sc.ConnID)
+	}
+	return vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, msg+": %v", sc.ConnID, cause)
+}
+
+// recordSetupQuery records a query that changed the session state of the
+// connection, so that it can be run again if the connection is re-established.
+func (sc *StatefulConnection) recordSetupQuery(query string) {
+	if sc.untrackedState {
+		return
+	}
+	if i := slices.Index(sc.setupQueries, query); i >= 0 {
+		// Running the query again after the others sets the same state.
+		sc.setupQueries = slices.Delete(sc.setupQueries, i, i+1)
+	}
+	if len(sc.setupQueries) == maxSetupQueries {
+		sc.recordUntrackedState()
+		return
+	}
+	sc.setupQueries = append(sc.setupQueries, query)
+}
+
+// recordUntrackedState records that the session state of the connection is
+// no longer described by its setup queries.
+func (sc *StatefulConnection) recordUntrackedState() {
+	sc.untrackedState = true
+	sc.setupQueries = nil
+}
+
+// recordTempTable records that a temporary table was created on the connection.
+func (sc *StatefulConnection) recordTempTable() {
+	sc.tempTables = true
+}
+
 func (sc *StatefulConnection) execWithRetry(ctx context.Context, query string, maxrows int, wantfields bool) (string, error) {
 	if sc.IsClosed() {
 		return "", vterrors.New(vtrpcpb.Code_CANCELED, "connection is closed")
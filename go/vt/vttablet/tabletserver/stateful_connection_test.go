@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconfigs"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestStatefulConnectionReestablishReserved(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := fakesqldb.New(t)
+	defer db.Close()
+	setQuery := "set @@sql_mode = 'STRICT_TRANS_TABLES'"
+	db.AddQuery(setQuery, &sqltypes.Result{})
+	db.AddQuery("select 1", &sqltypes.Result{})
+
+	pool := newActivePool()
+	params := dbconfigs.New(db.ConnParams())
+	pool.Open(params, params, params)
+	defer pool.Close()
+	recoveries := pool.env.Stats().ReservedConnRecoveries
+	reestablished, lost := recoveries.Counts()["Reestablished"], recoveries.Counts()["Lost"]
+
+	conn, err := pool.NewConn(ctx, &querypb.ExecuteOptions{}, nil)
+	require.NoError(t, err)
+	defer conn.Release(tx.ConnRelease)
+	require.NoError(t, conn.Taint(ctx, nil))
+	_, err = conn.Exec(ctx, setQuery, 0, false)
+	require.NoError(t, err)
+	conn.recordSetupQuery(setQuery)
+
+	// The lost MySQL connection is re-established with its settings.
+	conn.dbConn.Conn.Close()
+	_, err = conn.Exec(ctx, "select 1", 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, db.GetQueryCalledNum(setQuery))
+	assert.Equal(t, reestablished+1, recoveries.Counts()["Reestablished"])
+
+	// A reserved connection with temporary tables cannot be re-established.
+	conn.recordTempTable()
+	conn.dbConn.Conn.Close()
+	_, err = conn.Exec(ctx, "select 1", 1, false)
+	require.Error(t, err)
+	assert.True(t, vterrors.RxReservedConnTempTablesLost.MatchString(err.Error()), err.Error())
+	assert.Equal(t, lost+1, recoveries.Counts()["Lost"])
+	assert.Equal(t, 2, db.GetQueryCalledNum(setQuery))
+}
+
+func TestStatefulConnectionUntrackedState(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQuery("select 1", &sqltypes.Result{})
+
+	pool := newActivePool()
+	params := dbconfigs.New(db.ConnParams())
+	pool.Open(params, params, params)
+	defer pool.Close()
+
+	conn, err := pool.NewConn(ctx, &querypb.ExecuteOptions{}, nil)
+	require.NoError(t, err)
+	defer conn.Release(tx.ConnRelease)
+	require.NoError(t, conn.Taint(ctx, nil))
+
+	// The session state left by a lock is not described by the setup queries,
+	// so the reserved connection is lost with its MySQL connection.
+	conn.recordUntrackedState()
+	conn.dbConn.Conn.Close()
+	_, err = conn.Exec(ctx, "select 1", 1, false)
+	require.Error(t, err)
+	assert.True(t, vterrors.RxReservedConnLost.MatchString(err.Error()), err.Error())
+	assert.False(t, vterrors.RxReservedConnTempTablesLost.MatchString(err.Error()), err.Error())
+}
+
+func TestStatefulConnectionSetupQueries(t *testing.T) {
+	conn := &StatefulConnection{}
+
+	// The queries run again are moved after the others.
+	conn.recordSetupQuery("set @@sql_mode = ''")
+	conn.recordSetupQuery("set @@autocommit = 0")
+	conn.recordSetupQuery("set @@sql_mode = ''")
+	assert.Equal(t, []string{"set @@autocommit = 0", "set @@sql_mode = ''"}, conn.setupQueries)
+
+	// Past the limit, the session state is no longer tracked.
+	for i := 0; i < maxSetupQueries; i++ {
+		conn.recordSetupQuery(fmt.Sprintf("set @@max_execution_time = %d", i))
+	}
+	assert.True(t, conn.untrackedState)
+	assert.Empty(t, conn.setupQueries)
+	conn.recordSetupQuery("set @@sql_mode = ''")
+	assert.Empty(t, conn.setupQueries)
+}
+
+func TestStatefulConnectionNotReserved(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.AddQuery("select 1", &sqltypes.Result{})
+
+	pool := newActivePool()
+	params := dbconfigs.New(db.ConnParams())
+	pool.Open(params, params, params)
+	defer pool.Close()
+
+	conn, err := pool.NewConn(ctx, &querypb.ExecuteOptions{}, nil)
+	require.NoError(t, err)
+	defer conn.Release(tx.ConnRelease)
+
+	// Only reserved connections are re-established.
+	conn.dbConn.Conn.Close()
+	_, err = conn.Exec(ctx, "select 1", 1, false)
+	require.ErrorContains(t, err, "connection was aborted")
+}
@@ -0,0 +1,494 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// tableKillSwitchesQueryRuleSource is the query rule source that enforces
+	// the table kill switches.
+	tableKillSwitchesQueryRuleSource = "TABLE_KILL_SWITCHES"
+
+	// tableKillSwitchesAuditSize is the number of changes of the kill switches
+	// that are kept for the audit.
+	tableKillSwitchesAuditSize = 100
+)
+
+// The scopes of the table kill switches.
+const (
+	TableKillSwitchScopeTablet   = "tablet"
+	TableKillSwitchScopeKeyspace = "keyspace"
+)
+
+// The changes of the table kill switches that are audited.
+const (
+	TableKillSwitchSet    = "set"
+	TableKillSwitchClear  = "clear"
+	TableKillSwitchExpire = "expire"
+)
+
+// tableKillSwitchesRefreshInterval is how often the kill switches of the
+// keyspace are read from the global topo.
+var tableKillSwitchesRefreshInterval = 10 * time.Second
+
+var (
+	// tableKillSwitchReadPlans are the plans that read a table.
+	tableKillSwitchReadPlans = []planbuilder.PlanType{
+		planbuilder.PlanSelect,
+		planbuilder.PlanSelectLockFunc,
+		planbuilder.PlanSelectStream,
+	}
+	// tableKillSwitchWritePlans are the plans that write a table.
+	tableKillSwitchWritePlans = []planbuilder.PlanType{
+		planbuilder.PlanNextval,
+		planbuilder.PlanInsert,
+		planbuilder.PlanInsertMessage,
+		planbuilder.PlanUpdate,
+		planbuilder.PlanUpdateLimit,
+		planbuilder.PlanDelete,
+		planbuilder.PlanDeleteLimit,
+		planbuilder.PlanLoad,
+		planbuilder.PlanDDL,
+	}
+)
+
+// TableKillSwitch disables the reads and/or the writes of a table, until it
+// is cleared or it expires. The queries that it disables fail with VT09025.
+type TableKillSwitch struct {
+	Table      string
+	Scope      string
+	Reads      bool
+	Writes     bool
+	Reason     string
+	SetBy      string
+	ExpireTime time.Time
+}
+
+// TableKillSwitchChange is a change of the table kill switches, kept for the
+// audit.
+type TableKillSwitchChange struct {
+	Time   time.Time
+	Action string
+	By     string
+	TableKillSwitch
+}
+
+// tableKillSwitches disables at runtime the reads and/or the writes of some
+// tables, to contain an incident or to investigate a data corruption. The
+// kill switches of the tablet are only kept in memory, while the ones of the
+// keyspace are stored in the global topo, and read periodically by all the
+// tablets of the keyspace. They are enforced through query rules.
+type tableKillSwitches struct {
+	tsv   *TabletServer
+	ts    *topo.Server
+	ticks *timer.Timer
+	now   func() time.Time
+
+	mu       sync.Mutex
+	keyspace string
+	// tablet are the kill switches of the tablet, by table.
+	tablet map[string]*TableKillSwitch
+	// keyspaceSwitches are the last read kill switches of the keyspace, by table.
+	keyspaceSwitches map[string]*TableKillSwitch
+	// keyspaceClearedBy are who cleared the last read cleared kill switches
+	// of the keyspace, by table.
+	keyspaceClearedBy map[string]string
+	// active are the kill switches that are enforced, by scope and table.
+	active map[string]*TableKillSwitch
+	// audit are the last changes of the kill switches.
+	audit []TableKillSwitchChange
+	// expiry fires when the first active kill switch expires.
+	expiry *time.Timer
+}
+
+// newTableKillSwitches returns the kill switches of the tablet server, and
+// registers the query rule source that enforces them.
+func newTableKillSwitches(tsv *TabletServer, ts *topo.Server) *tableKillSwitches {
+	tks := &tableKillSwitches{
+		tsv:               tsv,
+		ts:                ts,
+		ticks:             timer.NewTimer(tableKillSwitchesRefreshInterval),
+		now:               time.Now,
+		tablet:            make(map[string]*TableKillSwitch),
+		keyspaceSwitches:  make(map[string]*TableKillSwitch),
+		keyspaceClearedBy: make(map[string]string),
+		active:            make(map[string]*TableKillSwitch),
+	}
+	tsv.RegisterQueryRuleSource(tableKillSwitchesQueryRuleSource)
+	return tks
+}
+
+// InitDBConfig starts reading the kill switches of the keyspace of the tablet
+// from the global topo.
+func (tks *tableKillSwitches) InitDBConfig(keyspace string) {
+	tks.mu.Lock()
+	tks.keyspace = keyspace
+	tks.mu.Unlock()
+	if tks.ts == nil || keyspace == "" || tks.ticks.Running() {
+		return
+	}
+	tks.ticks.Start(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+		defer cancel()
+		if err := tks.refresh(ctx); err != nil {
+			log.Warningf("Failed to read the table kill switches of keyspace %v: %v", keyspace, err)
+		}
+	})
+	tks.ticks.Trigger()
+}
+
+// Close stops reading the kill switches of the keyspace.
+func (tks *tableKillSwitches) Close() {
+	tks.ticks.Stop()
+	tks.mu.Lock()
+	defer tks.mu.Unlock()
+	if tks.expiry != nil {
+		tks.expiry.Stop()
+	}
+}
+
+// getKeyspace returns the keyspace of the tablet.
+func (tks *tableKillSwitches) getKeyspace() string {
+	tks.mu.Lock()
+	defer tks.mu.Unlock()
+	return tks.keyspace
+}
+
+// Set sets the kill switch of a table. A kill switch that neither disables
+// the reads nor the writes clears the kill switch of the table.
+func (tks *tableKillSwitches) Set(ctx context.Context, ks TableKillSwitch) error {
+	if ks.Table == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing table")
+	}
+	if !ks.Reads && !ks.Writes {
+		return tks.Clear(ctx, ks.Scope, ks.Table, ks.SetBy)
+	}
+	switch ks.Scope {
+	case TableKillSwitchScopeTablet:
+		tks.mu.Lock()
+		defer tks.mu.Unlock()
+		tks.tablet[ks.Table] = &ks
+		tks.applyLocked(ks.SetBy)
+		return nil
+	case TableKillSwitchScopeKeyspace:
+		return tks.updateKeyspace(ctx, ks.SetBy, func(keyspace string) (map[string]*topo.TableKillSwitch, error) {
+			return tks.ts.SetTableKillSwitch(ctx, keyspace, &topo.TableKillSwitch{
+				Table:      ks.Table,
+				Reads:      ks.Reads,
+				Writes:     ks.Writes,
+				Reason:     ks.Reason,
+				SetBy:      ks.SetBy,
+				ExpireTime: ks.ExpireTime,
+			}, tks.now())
+		})
+	}
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid table kill switch scope: %q", ks.Scope)
+}
+
+// Clear clears the kill switch of a table.
+func (tks *tableKillSwitches) Clear(ctx context.Context, scope, table, by string) error {
+	switch scope {
+	case TableKillSwitchScopeTablet:
+		tks.mu.Lock()
+		defer tks.mu.Unlock()
+		delete(tks.tablet, table)
+		tks.applyLocked(by)
+		return nil
+	case TableKillSwitchScopeKeyspace:
+		return tks.updateKeyspace(ctx, by, func(keyspace string) (map[string]*topo.TableKillSwitch, error) {
+			return tks.ts.ClearTableKillSwitch(ctx, keyspace, table, by, tks.now())
+		})
+	}
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid table kill switch scope: %q", scope)
+}
+
+// List returns the active kill switches, sorted by table and scope, and the
+// audit of their last changes.
+func (tks *tableKillSwitches) List() ([]TableKillSwitch, []TableKillSwitchChange) {
+	tks.mu.Lock()
+	defer tks.mu.Unlock()
+	switches := make([]TableKillSwitch, 0, len(tks.active))
+	for _, ks := range tks.active {
+		switches = append(switches, *ks)
+	}
+	sort.Slice(switches, func(i, j int) bool {
+		if switches[i].Table != switches[j].Table {
+			return switches[i].Table < switches[j].Table
+		}
+		return switches[i].Scope < switches[j].Scope
+	})
+	return switches, append([]TableKillSwitchChange(nil), tks.audit...)
+}
+
+// updateKeyspace updates the kill switches of the keyspace in the global
+// topo, then enforces them.
+func (tks *tableKillSwitches) updateKeyspace(ctx context.Context, by string, update func(keyspace string) (map[string]*topo.TableKillSwitch, error)) error {
+	keyspace := tks.getKeyspace()
+	if tks.ts == nil || keyspace == "" {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "the keyspace of the tablet is unknown")
+	}
+	switches, err := update(keyspace)
+	if err != nil {
+		return err
+	}
+
+	tks.mu.Lock()
+	defer tks.mu.Unlock()
+	tks.loadKeyspaceLocked(switches)
+	tks.applyLocked(by)
+	return nil
+}
+
+// refresh reads the kill switches of the keyspace from the global topo, then
+// enforces them.
+func (tks *tableKillSwitches) refresh(ctx context.Context) error {
+	switches, err := tks.ts.GetTableKillSwitches(ctx, tks.getKeyspace())
+	if err != nil {
+		return err
+	}
+
+	tks.mu.Lock()
+	defer tks.mu.Unlock()
+	tks.loadKeyspaceLocked(switches)
+	tks.applyLocked("")
+	return nil
+}
+
+// loadKeyspaceLocked replaces the kill switches of the keyspace with the ones
+// read from the global topo, and remembers who cleared the cleared ones.
+func (tks *tableKillSwitches) loadKeyspaceLocked(switches map[string]*topo.TableKillSwitch) {
+	tks.keyspaceSwitches = make(map[string]*TableKillSwitch)
+	tks.keyspaceClearedBy = make(map[string]string)
+	for table, ks := range switches {
+		if ks.Cleared() {
+			tks.keyspaceClearedBy[table] = ks.ClearedBy
+			continue
+		}
+		tks.keyspaceSwitches[table] = &TableKillSwitch{
+			Table:      table,
+			Scope:      TableKillSwitchScopeKeyspace,
+			Reads:      ks.Reads,
+			Writes:     ks.Writes,
+			Reason:     ks.Reason,
+			SetBy:      ks.SetBy,
+			ExpireTime: ks.ExpireTime,
+		}
+	}
+}
+
+// applyLocked enforces the kill switches that didn't expire, audits the
+// changes of the enforced kill switches and schedules their next expiry.
+// The kill switches that are no longer enforced are audited as expired, or
+// as cleared by whoever cleared them in the topo, or else by the given user.
+// A kill switch of the keyspace whose clearer is unknown, e.g. because the
+// keyspace was deleted, is audited as cleared by nobody.
+func (tks *tableKillSwitches) applyLocked(by string) {
+	now := tks.now()
+	var nextExpiry time.Time
+	active := make(map[string]*TableKillSwitch)
+	for _, switches := range []map[string]*TableKillSwitch{tks.tablet, tks.keyspaceSwitches} {
+		for table, ks := range switches {
+			if !ks.ExpireTime.IsZero() {
+				if !now.Before(ks.ExpireTime) {
+					if ks.Scope == TableKillSwitchScopeTablet {
+						delete(switches, table)
+					}
+					continue
+				}
+				if nextExpiry.IsZero() || ks.ExpireTime.Before(nextExpiry) {
+					nextExpiry = ks.ExpireTime
+				}
+			}
+			active[ks.Scope+"/"+table] = ks
+		}
+	}
+
+	changed := false
+	for key, ks := range active {
+		if prev, ok := tks.active[key]; ok && *prev == *ks {
+			continue
+		}
+		changed = true
+		tks.auditLocked(now, TableKillSwitchSet, ks.SetBy, ks)
+	}
+	for key, ks := range tks.active {
+		if _, ok := active[key]; ok {
+			continue
+		}
+		changed = true
+		switch {
+		case !ks.ExpireTime.IsZero() && !now.Before(ks.ExpireTime):
+			tks.auditLocked(now, TableKillSwitchExpire, "", ks)
+		case ks.Scope == TableKillSwitchScopeKeyspace && tks.keyspaceClearedBy[ks.Table] != "":
+			tks.auditLocked(now, TableKillSwitchClear, tks.keyspaceClearedBy[ks.Table], ks)
+		default:
+			tks.auditLocked(now, TableKillSwitchClear, by, ks)
+		}
+	}
+	tks.active = active
+
+	if tks.expiry != nil {
+		tks.expiry.Stop()
+		tks.expiry = nil
+	}
+	if !nextExpiry.IsZero() {
+		tks.expiry = time.AfterFunc(nextExpiry.Sub(now), func() {
+			tks.mu.Lock()
+			defer tks.mu.Unlock()
+			tks.applyLocked("")
+		})
+	}
+	if !changed {
+		return
+	}
+	if err := tks.tsv.SetQueryRules(tableKillSwitchesQueryRuleSource, tks.rulesLocked()); err != nil {
+		log.Warningf("Failed to load query rule set %s: %v", tableKillSwitchesQueryRuleSource, err)
+	}
+}
+
+// auditLocked records and logs a change of the kill switches.
+func (tks *tableKillSwitches) auditLocked(now time.Time, action, by string, ks *TableKillSwitch) {
+	change := TableKillSwitchChange{
+		Time:            now,
+		Action:          action,
+		By:              by,
+		TableKillSwitch: *ks,
+	}
+	log.Infof("Table kill switch %v by %q: %v of table %v, reads disabled: %v, writes disabled: %v, reason: %q, expires: %v",
+		action, by, ks.Scope, ks.Table, ks.Reads, ks.Writes, ks.Reason, ks.ExpireTime)
+	tks.audit = append(tks.audit, change)
+	if len(tks.audit) > tableKillSwitchesAuditSize {
+		tks.audit = tks.audit[len(tks.audit)-tableKillSwitchesAuditSize:]
+	}
+}
+
+// rulesLocked returns the query rules that enforce the active kill switches.
+func (tks *tableKillSwitches) rulesLocked() *rules.Rules {
+	qrs := rules.New()
+	for key, ks := range tks.active {
+		if ks.Reads {
+			qrs.Add(tableKillSwitchRule(key+"/reads", "reads", ks, tableKillSwitchReadPlans))
+		}
+		if ks.Writes {
+			qrs.Add(tableKillSwitchRule(key+"/writes", "writes", ks, tableKillSwitchWritePlans))
+		}
+	}
+	return qrs
+}
+
+// tableKillSwitchRule returns the query rule that fails the queries of the
+// given plans against the table of the kill switch.
+func tableKillSwitchRule(name, operations string, ks *TableKillSwitch, plans []planbuilder.PlanType) *rules.Rule {
+	description := fmt.Sprintf("%s: %s of table %s are disabled", rules.TableKillSwitchRuleDescription, operations, ks.Table)
+	if ks.Reason != "" {
+		description += ": " + ks.Reason
+	}
+	qr := rules.NewQueryRule(description, name, rules.QRFail)
+	qr.AddTableCond(ks.Table)
+	for _, plan := range plans {
+		qr.AddPlanCond(plan)
+	}
+	return qr
+}
+
+// tableKillSwitchesHandler lists the kill switches and their audit, or sets
+// or clears a kill switch. The changes are audited as made by the requester.
+func tableKillSwitchesHandler(tks *tableKillSwitches, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		switches, audit := tks.List()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"KillSwitches": switches,
+			"Audit":        audit,
+		})
+		return
+	}
+
+	if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+		acl.SendError(w, err)
+		return
+	}
+	ks := TableKillSwitch{
+		Table:  r.FormValue("table"),
+		Scope:  r.FormValue("scope"),
+		Reason: r.FormValue("reason"),
+		SetBy:  requester(r),
+	}
+	if ks.Scope == "" {
+		ks.Scope = TableKillSwitchScopeTablet
+	}
+	var err error
+	for name, value := range map[string]*bool{"reads": &ks.Reads, "writes": &ks.Writes} {
+		if param := r.FormValue(name); param != "" {
+			if *value, err = strconv.ParseBool(param); err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s: %v", name, err), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if param := r.FormValue("ttl"); param != "" {
+		ttl, err := time.ParseDuration(param)
+		if err != nil || ttl <= 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl: %q", param), http.StatusBadRequest)
+			return
+		}
+		ks.ExpireTime = tks.now().Add(ttl)
+	}
+	if err := tks.Set(r.Context(), ks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switches, _ := tks.List()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(switches)
+}
+
+// requester returns the identity of the client of the request: the common
+// name of its verified TLS client certificate, or else its remote address.
+// Nothing the client sends otherwise is trusted, so that the audit cannot be
+// forged.
+func requester(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		if cn := r.TLS.VerifiedChains[0][0].Subject.CommonName; cn != "" {
+			return cn
+		}
+	}
+	return r.RemoteAddr
+}
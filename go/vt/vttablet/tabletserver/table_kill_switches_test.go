@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callinfo"
+	"vitess.io/vitess/go/vt/callinfo/fakecallinfo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestTableKillSwitchesEnforced(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table limit 1000"
+	db.AddQuery(query, &sqltypes.Result{Fields: getTestTableFields()})
+	db.AddQuery("select * from test_table where 1 != 1", &sqltypes.Result{Fields: getTestTableFields()})
+
+	ctx := callinfo.NewContext(context.Background(), &fakecallinfo.FakeCallInfo{})
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tks := tsv.tableKillSwitches
+
+	// Disabling the writes doesn't affect the reads.
+	require.NoError(t, tks.Set(ctx, TableKillSwitch{Table: "test_table", Scope: TableKillSwitchScopeTablet, Writes: true}))
+	_, err := newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	// The DDLs write the table too.
+	_, err = newTestQueryExecutor(ctx, tsv, "truncate table test_table", 0).Execute()
+	assert.EqualError(t, err, "VT09025: table kill switch: writes of table test_table are disabled")
+
+	killed := tsv.Stats().TableKillSwitchQueries.Counts()["test_table"]
+	require.NoError(t, tks.Set(ctx, TableKillSwitch{Table: "test_table", Scope: TableKillSwitchScopeTablet, Reads: true, Reason: "corruption"}))
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	assert.Equal(t, vtrpcpb.Code_FAILED_PRECONDITION, vterrors.Code(err))
+	assert.EqualError(t, err, "VT09025: table kill switch: reads of table test_table are disabled: corruption")
+	assert.EqualValues(t, killed+1, tsv.Stats().TableKillSwitchQueries.Counts()["test_table"])
+
+	// The internal queries are not affected.
+	localCtx := tabletenv.LocalContext()
+	_, err = newTestQueryExecutor(localCtx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+
+	require.NoError(t, tks.Clear(ctx, TableKillSwitchScopeTablet, "test_table", "admin"))
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+}
+
+func TestTableKillSwitchesRules(t *testing.T) {
+	qrs := (&tableKillSwitches{active: map[string]*TableKillSwitch{
+		"tablet/t1": {Table: "t1", Scope: TableKillSwitchScopeTablet, Writes: true},
+	}}).rulesLocked()
+	assert.Empty(t, qrs.FilterByPlan("select * from t1", planbuilder.PlanSelect, "t1").CopyUnderlying())
+	assert.Len(t, qrs.FilterByPlan("insert into t1 values (1)", planbuilder.PlanInsert, "t1").CopyUnderlying(), 1)
+	assert.Len(t, qrs.FilterByPlan("update t1 join t2 set a = 1", planbuilder.PlanUpdate, "t2", "t1").CopyUnderlying(), 1)
+	assert.Empty(t, qrs.FilterByPlan("insert into t2 values (1)", planbuilder.PlanInsert, "t2").CopyUnderlying())
+	assert.Len(t, qrs.FilterByPlan("drop table t1", planbuilder.PlanDDL, "t1").CopyUnderlying(), 1)
+}
+
+func TestTableKillSwitchesExpiry(t *testing.T) {
+	ctx := context.Background()
+	tsv := NewTabletServer(ctx, vtenv.NewTestEnv(), "TabletServerTest", tabletenv.NewDefaultConfig(), memorytopo.NewServer(ctx, ""), &topodatapb.TabletAlias{}, stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type"))
+	tks := tsv.tableKillSwitches
+	defer tks.Close()
+
+	now := time.Now()
+	tks.now = func() time.Time { return now }
+	require.NoError(t, tks.Set(ctx, TableKillSwitch{Table: "t1", Scope: TableKillSwitchScopeTablet, Reads: true, SetBy: "alice", ExpireTime: now.Add(time.Hour)}))
+	require.NoError(t, tks.Set(ctx, TableKillSwitch{Table: "t2", Scope: TableKillSwitchScopeTablet, Writes: true, SetBy: "alice"}))
+	switches, _ := tks.List()
+	require.Len(t, switches, 2)
+
+	// Expired kill switches are no longer enforced.
+	now = now.Add(time.Hour)
+	tks.mu.Lock()
+	tks.applyLocked("")
+	tks.mu.Unlock()
+	require.NoError(t, tks.Clear(ctx, TableKillSwitchScopeTablet, "t2", "bob"))
+
+	switches, audit := tks.List()
+	assert.Empty(t, switches)
+	require.Len(t, audit, 4)
+	assert.Equal(t, []string{TableKillSwitchSet, TableKillSwitchSet, TableKillSwitchExpire, TableKillSwitchClear},
+		[]string{audit[0].Action, audit[1].Action, audit[2].Action, audit[3].Action})
+	assert.Equal(t, "t1", audit[2].Table)
+	assert.Equal(t, "bob", audit[3].By)
+
+	assert.Error(t, tks.Set(ctx, TableKillSwitch{Table: "t1", Scope: "shard", Reads: true}))
+	assert.Error(t, tks.Set(ctx, TableKillSwitch{Scope: TableKillSwitchScopeTablet, Reads: true}))
+	// The keyspace scope needs the keyspace of the tablet.
+	assert.Error(t, tks.Set(ctx, TableKillSwitch{Table: "t1", Scope: TableKillSwitchScopeKeyspace, Reads: true}))
+}
+
+func TestTableKillSwitchesKeyspace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	defer ts.Close()
+	newTableKillSwitches := func() *tableKillSwitches {
+		tsv := NewTabletServer(ctx, vtenv.NewTestEnv(), "TabletServerTest", tabletenv.NewDefaultConfig(), ts, &topodatapb.TabletAlias{}, stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type"))
+		tsv.tableKillSwitches.keyspace = "ks"
+		return tsv.tableKillSwitches
+	}
+	tks1 := newTableKillSwitches()
+	tks2 := newTableKillSwitches()
+
+	require.NoError(t, tks1.Set(ctx, TableKillSwitch{Table: "t1", Scope: TableKillSwitchScopeKeyspace, Writes: true, SetBy: "alice"}))
+	require.NoError(t, tks1.Set(ctx, TableKillSwitch{Table: "t2", Scope: TableKillSwitchScopeKeyspace, Reads: true, SetBy: "alice"}))
+	switches, _ := tks1.List()
+	assert.Len(t, switches, 2)
+
+	// The other tablets of the keyspace enforce them once they read them.
+	require.NoError(t, tks2.refresh(ctx))
+	switches, _ = tks2.List()
+	require.Len(t, switches, 2)
+	assert.Equal(t, TableKillSwitch{Table: "t1", Scope: TableKillSwitchScopeKeyspace, Writes: true, SetBy: "alice"}, switches[0])
+
+	require.NoError(t, tks2.Clear(ctx, TableKillSwitchScopeKeyspace, "t1", "bob"))
+	require.NoError(t, tks1.refresh(ctx))
+	switches, audit := tks1.List()
+	require.Len(t, switches, 1)
+	assert.Equal(t, "t2", switches[0].Table)
+	// The clear is audited as made by whoever cleared the kill switch.
+	assert.Equal(t, TableKillSwitchClear, audit[len(audit)-1].Action)
+	assert.Equal(t, "bob", audit[len(audit)-1].By)
+
+	// Nobody is known to have cleared the kill switches of a deleted keyspace.
+	require.NoError(t, ts.DeleteTableKillSwitches(ctx, "ks"))
+	require.NoError(t, tks1.refresh(ctx))
+	switches, audit = tks1.List()
+	assert.Empty(t, switches)
+	assert.Equal(t, TableKillSwitchClear, audit[len(audit)-1].Action)
+	assert.Equal(t, "t2", audit[len(audit)-1].Table)
+	assert.Empty(t, audit[len(audit)-1].By)
+}
+
+func TestTableKillSwitchesHandler(t *testing.T) {
+	ctx := context.Background()
+	tsv := NewTabletServer(ctx, vtenv.NewTestEnv(), "TabletServerTest", tabletenv.NewDefaultConfig(), memorytopo.NewServer(ctx, ""), &topodatapb.TabletAlias{}, stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type"))
+	tks := tsv.tableKillSwitches
+	defer tks.Close()
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/debug/table_kill_switches", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		tableKillSwitchesHandler(tks, w, req)
+		return w
+	}
+
+	w := post(url.Values{"table": {"t1"}, "reads": {"true"}, "ttl": {"1h"}, "reason": {"incident"}, "user": {"mallory"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	switches, _ := tks.List()
+	require.Len(t, switches, 1)
+	assert.True(t, switches[0].Reads)
+	assert.False(t, switches[0].Writes)
+	assert.Equal(t, TableKillSwitchScopeTablet, switches[0].Scope)
+	// The requester is audited, rather than the user the client claims to be.
+	assert.Equal(t, "192.0.2.1:1234", switches[0].SetBy)
+	assert.False(t, switches[0].ExpireTime.IsZero())
+
+	assert.Equal(t, http.StatusBadRequest, post(url.Values{"table": {"t1"}, "reads": {"maybe"}}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(url.Values{"table": {"t1"}, "reads": {"true"}, "ttl": {"-1h"}}).Code)
+
+	// Neither reads nor writes clears the kill switch.
+	require.Equal(t, http.StatusOK, post(url.Values{"table": {"t1"}}).Code)
+	switches, audit := tks.List()
+	assert.Empty(t, switches)
+	assert.Len(t, audit, 2)
+
+	w = httptest.NewRecorder()
+	tableKillSwitchesHandler(tks, w, httptest.NewRequest(http.MethodGet, "/debug/table_kill_switches", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Audit":[`)
+}
+
+func TestTableKillSwitchesRequester(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/debug/table_kill_switches", nil)
+	assert.Equal(t, req.RemoteAddr, requester(req))
+
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "alice"}}}}}
+	assert.Equal(t, "alice", requester(req))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletenv
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// Activity tracks the query traffic of the tablet, so that the periodic
+// internal queries (message polls, schema reloads) can back off while the
+// tablet is idle, and resume as soon as there is traffic again. The heartbeat
+// reads don't back off, since they keep the replication lag fresh.
+type Activity struct {
+	threshold   time.Duration
+	maxInterval time.Duration
+	now         func() time.Time
+
+	lastActive atomic.Int64
+	idle       atomic.Bool
+
+	mu     sync.Mutex
+	timers map[*IdleTimer]struct{}
+
+	skippedPolls *stats.CountersWithSingleLabel
+}
+
+// NewActivity creates an Activity. The periodic queries back off once the
+// tablet has had no query for config.IdlePollThreshold, and never if it is 0.
+func NewActivity(config *TabletConfig, exporter *servenv.Exporter) *Activity {
+	a := &Activity{
+		now:          time.Now,
+		timers:       make(map[*IdleTimer]struct{}),
+		skippedPolls: exporter.NewCountersWithSingleLabel("IdlePollsSkipped", "Number of periodic internal queries skipped because the tablet was idle", "poller"),
+	}
+	if config != nil {
+		a.threshold, a.maxInterval = config.IdlePollThreshold, config.IdlePollMaxInterval
+	}
+	a.lastActive.Store(a.now().UnixNano())
+	exporter.NewGaugeFunc("TabletIdle", "Whether the periodic internal queries back off because the tablet is idle", func() int64 {
+		if a.Idle() {
+			return 1
+		}
+		return 0
+	})
+	return a
+}
+
+// Record records query traffic. If the tablet was idle, the periodic
+// queries are run right away and resume their regular interval.
+func (a *Activity) Record() {
+	if a.threshold <= 0 {
+		return
+	}
+	a.lastActive.Store(a.now().UnixNano())
+	if a.idle.Load() && a.idle.CompareAndSwap(true, false) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		for t := range a.timers {
+			// Trigger waits for the timer to be ready, which must not hold up the query.
+			go t.Trigger()
+		}
+	}
+}
+
+// Idle returns whether the tablet has had no query for the threshold.
+func (a *Activity) Idle() bool {
+	return a.isIdle(a.now())
+}
+
+// isIdle returns whether the tablet has had no query for the threshold.
+func (a *Activity) isIdle(now time.Time) bool {
+	if a.threshold <= 0 {
+		return false
+	}
+	if now.Sub(time.Unix(0, a.lastActive.Load())) < a.threshold {
+		return false
+	}
+	a.idle.Store(true)
+	return true
+}
+
+// NewTimer creates an IdleTimer that runs at the given interval, and backs
+// off while the tablet is idle. The name identifies the timer in the stats.
+func (a *Activity) NewTimer(name string, interval time.Duration) *IdleTimer {
+	return &IdleTimer{
+		Timer:    timer.NewTimer(interval),
+		name:     name,
+		activity: a,
+	}
+}
+
+// IdleTimer is a timer.Timer for periodic internal queries. While the tablet
+// is idle, it skips the ticks of the timer so that the interval between the
+// runs doubles, up to the maximum idle interval. It runs right away once the
+// tablet has traffic again.
+type IdleTimer struct {
+	*timer.Timer
+
+	name     string
+	activity *Activity
+
+	// lastRun and backoff are only used by the timer goroutine.
+	lastRun time.Time
+	backoff time.Duration
+}
+
+// Start starts the timer. See timer.Timer.Start.
+func (t *IdleTimer) Start(keephouse func()) {
+	t.activity.mu.Lock()
+	t.activity.timers[t] = struct{}{}
+	t.activity.mu.Unlock()
+
+	t.Timer.Start(func() {
+		if t.skip() {
+			return
+		}
+		keephouse()
+	})
+}
+
+// Stop stops the timer. See timer.Timer.Stop.
+func (t *IdleTimer) Stop() {
+	t.activity.mu.Lock()
+	delete(t.activity.timers, t)
+	t.activity.mu.Unlock()
+
+	t.Timer.Stop()
+}
+
+// skip returns whether the tick of the timer must be skipped, because the
+// tablet is idle and the backoff interval has not elapsed since the last run.
+func (t *IdleTimer) skip() bool {
+	now := t.activity.now()
+	interval := t.Interval()
+	if !t.activity.isIdle(now) {
+		t.lastRun, t.backoff = now, interval
+		return false
+	}
+	if now.Sub(t.lastRun) < t.backoff {
+		t.activity.skippedPolls.Add(t.name, 1)
+		return true
+	}
+	t.lastRun = now
+	t.backoff = max(min(2*t.backoff, t.activity.maxInterval), interval)
+	return false
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletenv
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// fakeClock is a settable clock for the tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestActivity(t *testing.T, threshold, maxInterval time.Duration) (*Activity, *fakeClock) {
+	config := NewDefaultConfig()
+	config.IdlePollThreshold = threshold
+	config.IdlePollMaxInterval = maxInterval
+	a := NewActivity(config, servenv.NewExporter(t.Name(), "Tablet"))
+	clock := &fakeClock{now: time.Now()}
+	a.now = clock.Now
+	a.lastActive.Store(clock.Now().UnixNano())
+	return a, clock
+}
+
+func TestIdleTimerBackoff(t *testing.T) {
+	a, clock := newTestActivity(t, time.Minute, 8*time.Second)
+	tmr := a.NewTimer("test", time.Second)
+
+	// The timer runs at every tick while the tablet has traffic.
+	for i := 0; i < 3; i++ {
+		clock.Add(time.Second)
+		assert.False(t, tmr.skip())
+	}
+
+	// Once idle, the interval between the runs doubles, up to the maximum.
+	skipped := a.skippedPolls.Counts()["test"]
+	clock.Add(time.Minute)
+	assert.False(t, tmr.skip())
+	var runs []int
+	for i := 1; i <= 30; i++ {
+		clock.Add(time.Second)
+		if !tmr.skip() {
+			runs = append(runs, i)
+		}
+	}
+	assert.Equal(t, []int{2, 6, 14, 22, 30}, runs)
+	assert.EqualValues(t, skipped+25, a.skippedPolls.Counts()["test"])
+
+	// Traffic resumes the regular interval.
+	a.Record()
+	clock.Add(time.Second)
+	assert.False(t, tmr.skip())
+	clock.Add(time.Second)
+	assert.False(t, tmr.skip())
+}
+
+func TestIdleTimerDisabled(t *testing.T) {
+	a, clock := newTestActivity(t, 0, time.Minute)
+	tmr := a.NewTimer("test", time.Second)
+	clock.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		clock.Add(time.Second)
+		assert.False(t, tmr.skip())
+	}
+	assert.False(t, a.isIdle(clock.Now()))
+}
+
+func TestIdleTimerResumesOnTraffic(t *testing.T) {
+	a, clock := newTestActivity(t, time.Minute, time.Hour)
+	tmr := a.NewTimer("test", 10*time.Millisecond)
+
+	var runs atomic.Int64
+	tmr.Start(func() { runs.Add(1) })
+	defer tmr.Stop()
+
+	// The timer backs off to the maximum interval while idle.
+	clock.Add(time.Hour)
+	skipped := a.skippedPolls.Counts()["test"]
+	require.Eventually(t, func() bool {
+		return a.skippedPolls.Counts()["test"] > skipped
+	}, 5*time.Second, 10*time.Millisecond)
+	runsBefore := runs.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, runsBefore, runs.Load())
+
+	// Traffic runs the timer right away.
+	a.Record()
+	require.Eventually(t, func() bool {
+		return runs.Load() > runsBefore
+	}, 5*time.Second, time.Millisecond)
+}
@@ -53,6 +53,8 @@ const (
 	NotOnPrimary = "notOnPrimary"
 	Polling      = "polling"
 	Heartbeat    = "heartbeat"
+	Warn         = "warn"
+	Reject       = "reject"
 )
 
 var (
@@ -132,6 +134,7 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.PassthroughDML, "queryserver-config-passthrough-dmls", defaultConfig.PassthroughDML, "query server pass through all dml statements without rewriting")
 
 	fs.IntVar(&currentConfig.StreamBufferSize, "queryserver-config-stream-buffer-size", defaultConfig.StreamBufferSize, "query server stream buffer size, the maximum number of bytes sent from vttablet for each stream call. It's recommended to keep this value in sync with vtgate's stream_buffer_size.")
+	fs.IntVar(&currentConfig.MaxStreamBufferSize, "queryserver-config-max-stream-buffer-size", defaultConfig.MaxStreamBufferSize, "query server max stream buffer size, the maximum number of bytes a client can request to be sent from vttablet for each stream call, overriding queryserver-config-stream-buffer-size.")
 
 	fs.Int64Var(&currentConfig.QueryCacheMemory, "queryserver-config-query-cache-memory", defaultConfig.QueryCacheMemory, "query server query cache size in bytes, maximum amount of memory to be used for caching. vttablet analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 
@@ -141,6 +144,8 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&currentConfig.Olap.TxTimeout, "queryserver-config-olap-transaction-timeout", defaultConfig.Olap.TxTimeout, "query server transaction timeout (in seconds), after which a transaction in an OLAP session will be killed")
 	fs.DurationVar(&currentConfig.Oltp.QueryTimeout, "queryserver-config-query-timeout", defaultConfig.Oltp.QueryTimeout, "query server query timeout, this is the query timeout in vttablet side. If a query takes more than this timeout, it will be killed.")
 	fs.DurationVar(&currentConfig.OltpReadPool.Timeout, "queryserver-config-query-pool-timeout", defaultConfig.OltpReadPool.Timeout, "query server query pool timeout, it is how long vttablet waits for a connection from the query pool. If set to 0 (default) then the overall query timeout is used instead.")
+	fs.IntVar(&currentConfig.OltpReadPool.MaxWaiters, "queryserver-config-query-pool-max-waiters", defaultConfig.OltpReadPool.MaxWaiters, "query server query pool admission queue limit, it is the maximum number of queries that can wait for a connection from the query pool, in the order of their priority. Queries beyond it are rejected right away. If set to 0 (default) then there is no limit.")
+	fs.DurationVar(&currentConfig.OltpReadPool.QueueSLO, "queryserver-config-query-pool-queue-slo", defaultConfig.OltpReadPool.QueueSLO, "query server query pool queue time objective, the queries that wait longer than it for a connection from the query pool are counted per workload in QueryPoolQueueSLOExceeded. If set to 0 (default) then they are not counted.")
 	fs.DurationVar(&currentConfig.OlapReadPool.Timeout, "queryserver-config-stream-pool-timeout", defaultConfig.OlapReadPool.Timeout, "query server stream pool timeout, it is how long vttablet waits for a connection from the stream pool. If set to 0 (default) then there is no timeout.")
 	fs.DurationVar(&currentConfig.TxPool.Timeout, "queryserver-config-txpool-timeout", defaultConfig.TxPool.Timeout, "query server transaction pool timeout, it is how long vttablet waits if tx pool is full")
 	fs.DurationVar(&currentConfig.OltpReadPool.IdleTimeout, "queryserver-config-idle-timeout", defaultConfig.OltpReadPool.IdleTimeout, "query server idle timeout, vttablet manages various mysql connection pools. This config means if a connection has not been used in given idle timeout, this connection will be removed from pool. This effectively manages number of connection objects and optimize the pool performance.")
@@ -161,9 +166,16 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&currentConfig.TerseErrors, "queryserver-config-terse-errors", defaultConfig.TerseErrors, "prevent bind vars from escaping in client error messages")
 	fs.IntVar(&currentConfig.TruncateErrorLen, "queryserver-config-truncate-error-len", defaultConfig.TruncateErrorLen, "truncate errors sent to client if they are longer than this value (0 means do not truncate)")
 	fs.BoolVar(&currentConfig.AnnotateQueries, "queryserver-config-annotate-queries", defaultConfig.AnnotateQueries, "prefix queries to MySQL backend with comment indicating vtgate principal (user) and target tablet type")
+	fs.BoolVar(&currentConfig.MaxExecutionTimeHint, "queryserver-config-max-execution-time-hint", defaultConfig.MaxExecutionTimeHint, "push the timeout of SELECT queries down to MySQL as a MAX_EXECUTION_TIME optimizer hint, so that MySQL aborts them at the same deadline")
+	fs.DurationVar(&currentConfig.StreamBackpressureThreshold, "queryserver-config-stream-backpressure-threshold", defaultConfig.StreamBackpressureThreshold, "time a streaming query can be blocked sending a result to its client before the stall is recorded as backpressure, per caller and per query (0 disables the tracking)")
+	fs.DurationVar(&currentConfig.IdlePollThreshold, "idle-poll-threshold", defaultConfig.IdlePollThreshold, "time without any query after which the tablet is idle, and its periodic internal queries (message polls, schema reloads) back off until there is traffic again (0 disables the back off)")
+	fs.DurationVar(&currentConfig.IdlePollMaxInterval, "idle-poll-max-interval", defaultConfig.IdlePollMaxInterval, "maximum interval the periodic internal queries of an idle tablet back off to, see --idle-poll-threshold")
 	fs.BoolVar(&currentConfig.WatchReplication, "watch_replication_stream", false, "When enabled, vttablet will stream the MySQL replication stream from the local server, and use it to update schema when it sees a DDL.")
 	fs.BoolVar(&currentConfig.TrackSchemaVersions, "track_schema_versions", false, "When enabled, vttablet will store versions of schemas at each position that a DDL is applied and allow retrieval of the schema corresponding to a position")
 	fs.Int64Var(&currentConfig.SchemaVersionMaxAgeSeconds, "schema-version-max-age-seconds", 0, "max age of schema version records to kept in memory by the vreplication historian")
+	fs.IntVar(&currentConfig.SchemaHistorySize, "schema-history-size", defaultConfig.SchemaHistorySize, "number of versions of the schema, with the changes between them, that the schema engine keeps in memory and serves on /debug/schema/history (0 disables the history)")
+	fs.StringVar(&currentConfig.UnsafeStatementMode, "unsafe-statement-mode", defaultConfig.UnsafeStatementMode, "what to do with the DMLs that are unsafe for statement-based replication when the binlog_format is STATEMENT or MIXED, e.g. an UPDATE with a LIMIT without an ORDER BY on the primary key, or calling a non-deterministic function: disable, warn to log and count them, or reject to fail them")
+	fs.StringSliceVar(&currentConfig.QueryInterceptors, "query-interceptors", defaultConfig.QueryInterceptors, "comma separated names of the compiled-in query interceptors that inspect, annotate or reject the queries of the users before they are executed, in order")
 	fs.BoolVar(&currentConfig.TwoPCEnable, "twopc_enable", defaultConfig.TwoPCEnable, "if the flag is on, 2pc is enabled. Other 2pc flags must be supplied.")
 	fs.StringVar(&currentConfig.TwoPCCoordinatorAddress, "twopc_coordinator_address", defaultConfig.TwoPCCoordinatorAddress, "address of the (VTGate) process(es) that will be used to notify of abandoned transactions.")
 	SecondsVar(fs, &currentConfig.TwoPCAbandonAge, "twopc_abandon_age", defaultConfig.TwoPCAbandonAge, "time in seconds. Any unresolved transaction older than this time will be sent to the coordinator to be resolved.")
@@ -214,9 +226,15 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.Int64Var(&currentConfig.RowStreamer.MaxMySQLReplLagSecs, "vreplication_copy_phase_max_mysql_replication_lag", 43200, "The maximum MySQL replication lag (in seconds) that can exist on a vstreamer (source) before starting another round of copying rows. This helps to limit the impact on the source tablet.")
 
 	fs.BoolVar(&currentConfig.EnableViews, "queryserver-enable-views", false, "Enable views support in vttablet.")
+	fs.BoolVar(&currentConfig.EnableFieldCache, "queryserver-enable-field-cache", false, "Cache the column metadata returned by the field queries of each query plan, until the schema changes, instead of querying MySQL every time.")
 
 	fs.BoolVar(&currentConfig.EnablePerWorkloadTableMetrics, "enable-per-workload-table-metrics", defaultConfig.EnablePerWorkloadTableMetrics, "If true, query counts and query error metrics include a label that identifies the workload")
 
+	fs.StringVar(&currentConfig.Warmup.File, "warmup-queries-file", defaultConfig.Warmup.File, "File where the hottest read queries are periodically recorded. Once MySQL restarted, e.g. after a restart or a restore, those queries are replayed to warm up the MySQL buffer pool before the tablet starts serving. Empty disables the warmup.")
+	fs.IntVar(&currentConfig.Warmup.Queries, "warmup-queries", defaultConfig.Warmup.Queries, "Number of hottest read queries that are recorded for the warmup.")
+	fs.IntVar(&currentConfig.Warmup.MaxRows, "warmup-max-rows", defaultConfig.Warmup.MaxRows, "Maximum number of rows read by each replayed query without a limit during the warmup.")
+	fs.DurationVar(&currentConfig.Warmup.Timeout, "warmup-timeout", defaultConfig.Warmup.Timeout, "Maximum duration of the warmup, after which the tablet starts serving even if not all the queries were replayed.")
+
 	fs.BoolVar(&currentConfig.Unmanaged, "unmanaged", false, "Indicates an unmanaged tablet, i.e. using an external mysql-compatible database")
 }
 
@@ -322,6 +340,7 @@ type TabletConfig struct {
 	Consolidator                     string        `json:"consolidator,omitempty"`
 	PassthroughDML                   bool          `json:"passthroughDML,omitempty"`
 	StreamBufferSize                 int           `json:"streamBufferSize,omitempty"`
+	MaxStreamBufferSize              int           `json:"maxStreamBufferSize,omitempty"`
 	ConsolidatorStreamTotalSize      int64         `json:"consolidatorStreamTotalSize,omitempty"`
 	ConsolidatorStreamQuerySize      int64         `json:"consolidatorStreamQuerySize,omitempty"`
 	QueryCacheMemory                 int64         `json:"queryCacheMemory,omitempty"`
@@ -332,11 +351,19 @@ type TabletConfig struct {
 	WatchReplication                 bool          `json:"watchReplication,omitempty"`
 	TrackSchemaVersions              bool          `json:"trackSchemaVersions,omitempty"`
 	SchemaVersionMaxAgeSeconds       int64         `json:"schemaVersionMaxAgeSeconds,omitempty"`
-	TerseErrors                      bool          `json:"terseErrors,omitempty"`
-	TruncateErrorLen                 int           `json:"truncateErrorLen,omitempty"`
-	AnnotateQueries                  bool          `json:"annotateQueries,omitempty"`
-	MessagePostponeParallelism       int           `json:"messagePostponeParallelism,omitempty"`
-	SignalWhenSchemaChange           bool          `json:"signalWhenSchemaChange,omitempty"`
+	SchemaHistorySize                int           `json:"schemaHistorySize,omitempty"`
+	// UnsafeStatementMode can be disable, warn, or reject. Default is disable.
+	UnsafeStatementMode         string        `json:"unsafeStatementMode,omitempty"`
+	QueryInterceptors           []string      `json:"queryInterceptors,omitempty"`
+	TerseErrors                 bool          `json:"terseErrors,omitempty"`
+	TruncateErrorLen            int           `json:"truncateErrorLen,omitempty"`
+	AnnotateQueries             bool          `json:"annotateQueries,omitempty"`
+	MaxExecutionTimeHint        bool          `json:"maxExecutionTimeHint,omitempty"`
+	StreamBackpressureThreshold time.Duration `json:"streamBackpressureThreshold,omitempty"`
+	IdlePollThreshold           time.Duration `json:"idlePollThreshold,omitempty"`
+	IdlePollMaxInterval         time.Duration `json:"idlePollMaxInterval,omitempty"`
+	MessagePostponeParallelism  int           `json:"messagePostponeParallelism,omitempty"`
+	SignalWhenSchemaChange      bool          `json:"signalWhenSchemaChange,omitempty"`
 
 	ExternalConnections map[string]*dbconfigs.DBConfigs `json:"externalConnections,omitempty"`
 
@@ -368,7 +395,11 @@ type TabletConfig struct {
 
 	EnableViews bool `json:"-"`
 
+	EnableFieldCache bool `json:"-"`
+
 	EnablePerWorkloadTableMetrics bool `json:"-"`
+
+	Warmup WarmupConfig `json:"warmup,omitempty"`
 }
 
 func (cfg *TabletConfig) MarshalJSON() ([]byte, error) {
@@ -379,6 +410,9 @@ func (cfg *TabletConfig) MarshalJSON() ([]byte, error) {
 		SchemaReloadInterval             string `json:"schemaReloadIntervalSeconds,omitempty"`
 		SignalSchemaChangeReloadInterval string `json:"signalSchemaChangeReloadIntervalSeconds,omitempty"`
 		SchemaChangeReloadTimeout        string `json:"schemaChangeReloadTimeout,omitempty"`
+		StreamBackpressureThreshold      string `json:"streamBackpressureThreshold,omitempty"`
+		IdlePollThreshold                string `json:"idlePollThreshold,omitempty"`
+		IdlePollMaxInterval              string `json:"idlePollMaxInterval,omitempty"`
 	}{
 		TCProxy: TCProxy(*cfg),
 	}
@@ -395,6 +429,18 @@ func (cfg *TabletConfig) MarshalJSON() ([]byte, error) {
 		tmp.SchemaChangeReloadTimeout = d.String()
 	}
 
+	if d := cfg.StreamBackpressureThreshold; d != 0 {
+		tmp.StreamBackpressureThreshold = d.String()
+	}
+
+	if d := cfg.IdlePollThreshold; d != 0 {
+		tmp.IdlePollThreshold = d.String()
+	}
+
+	if d := cfg.IdlePollMaxInterval; d != 0 {
+		tmp.IdlePollMaxInterval = d.String()
+	}
+
 	return json.Marshal(&tmp)
 }
 
@@ -406,6 +452,9 @@ func (cfg *TabletConfig) UnmarshalJSON(data []byte) (err error) {
 		SchemaReloadInterval             string `json:"schemaReloadIntervalSeconds,omitempty"`
 		SignalSchemaChangeReloadInterval string `json:"signalSchemaChangeReloadIntervalSeconds,omitempty"`
 		SchemaChangeReloadTimeout        string `json:"schemaChangeReloadTimeout,omitempty"`
+		StreamBackpressureThreshold      string `json:"streamBackpressureThreshold,omitempty"`
+		IdlePollThreshold                string `json:"idlePollThreshold,omitempty"`
+		IdlePollMaxInterval              string `json:"idlePollMaxInterval,omitempty"`
 	}
 
 	tmp.TCProxy = TCProxy(*cfg)
@@ -443,6 +492,33 @@ func (cfg *TabletConfig) UnmarshalJSON(data []byte) (err error) {
 		cfg.SchemaChangeReloadTimeout = 0
 	}
 
+	if tmp.StreamBackpressureThreshold != "" {
+		cfg.StreamBackpressureThreshold, err = time.ParseDuration(tmp.StreamBackpressureThreshold)
+		if err != nil {
+			return err
+		}
+	} else {
+		cfg.StreamBackpressureThreshold = 0
+	}
+
+	if tmp.IdlePollThreshold != "" {
+		cfg.IdlePollThreshold, err = time.ParseDuration(tmp.IdlePollThreshold)
+		if err != nil {
+			return err
+		}
+	} else {
+		cfg.IdlePollThreshold = 0
+	}
+
+	if tmp.IdlePollMaxInterval != "" {
+		cfg.IdlePollMaxInterval, err = time.ParseDuration(tmp.IdlePollMaxInterval)
+		if err != nil {
+			return err
+		}
+	} else {
+		cfg.IdlePollMaxInterval = 0
+	}
+
 	return nil
 }
 
@@ -453,6 +529,12 @@ type ConnPoolConfig struct {
 	IdleTimeout        time.Duration `json:"idleTimeoutSeconds,omitempty"`
 	MaxLifetime        time.Duration `json:"maxLifetimeSeconds,omitempty"`
 	PrefillParallelism int           `json:"prefillParallelism,omitempty"`
+	// MaxWaiters is the maximum number of clients that can wait for a
+	// connection, or 0 for no limit.
+	MaxWaiters int `json:"maxWaiters,omitempty"`
+	// QueueSLO is the wait time for a connection above which a wait is
+	// counted as exceeding the objective, or 0 to not count them.
+	QueueSLO time.Duration `json:"queueSLOSeconds,omitempty"`
 }
 
 func (cfg *ConnPoolConfig) MarshalJSON() ([]byte, error) {
@@ -463,6 +545,7 @@ func (cfg *ConnPoolConfig) MarshalJSON() ([]byte, error) {
 		Timeout     string `json:"timeoutSeconds,omitempty"`
 		IdleTimeout string `json:"idleTimeoutSeconds,omitempty"`
 		MaxLifetime string `json:"maxLifetimeSeconds,omitempty"`
+		QueueSLO    string `json:"queueSLOSeconds,omitempty"`
 	}{
 		Proxy: Proxy(*cfg),
 	}
@@ -479,6 +562,10 @@ func (cfg *ConnPoolConfig) MarshalJSON() ([]byte, error) {
 		tmp.MaxLifetime = d.String()
 	}
 
+	if d := cfg.QueueSLO; d != 0 {
+		tmp.QueueSLO = d.String()
+	}
+
 	return json.Marshal(&tmp)
 }
 
@@ -489,6 +576,8 @@ func (cfg *ConnPoolConfig) UnmarshalJSON(data []byte) (err error) {
 		IdleTimeout        string `json:"idleTimeoutSeconds,omitempty"`
 		MaxLifetime        string `json:"maxLifetimeSeconds,omitempty"`
 		PrefillParallelism int    `json:"prefillParallelism,omitempty"`
+		MaxWaiters         int    `json:"maxWaiters,omitempty"`
+		QueueSLO           string `json:"queueSLOSeconds,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &tmp); err != nil {
@@ -516,8 +605,16 @@ func (cfg *ConnPoolConfig) UnmarshalJSON(data []byte) (err error) {
 		}
 	}
 
+	if tmp.QueueSLO != "" {
+		cfg.QueueSLO, err = time.ParseDuration(tmp.QueueSLO)
+		if err != nil {
+			return err
+		}
+	}
+
 	cfg.Size = tmp.Size
 	cfg.PrefillParallelism = tmp.PrefillParallelism
+	cfg.MaxWaiters = tmp.MaxWaiters
 
 	return nil
 }
@@ -822,6 +919,62 @@ type RowStreamerConfig struct {
 	MaxMySQLReplLagSecs int64 `json:"maxMySQLReplLagSecs,omitempty"`
 }
 
+// WarmupConfig contains the configuration of the warmup of the MySQL buffer pool,
+// which replays the hottest queries recorded before MySQL restarted.
+type WarmupConfig struct {
+	// File is where the hottest queries are recorded. Empty disables the warmup.
+	File string `json:"file,omitempty"`
+	// Queries is the number of hottest queries that are recorded.
+	Queries int `json:"queries,omitempty"`
+	// MaxRows is the number of rows read by each replayed query without a limit.
+	MaxRows int `json:"maxRows,omitempty"`
+	// Timeout bounds the duration of the warmup.
+	Timeout time.Duration `json:"timeoutSeconds,omitempty"`
+}
+
+func (cfg *WarmupConfig) MarshalJSON() ([]byte, error) {
+	type Proxy WarmupConfig
+
+	tmp := struct {
+		Proxy
+		Timeout string `json:"timeoutSeconds,omitempty"`
+	}{
+		Proxy: Proxy(*cfg),
+	}
+
+	if d := cfg.Timeout; d != 0 {
+		tmp.Timeout = d.String()
+	}
+
+	return json.Marshal(&tmp)
+}
+
+func (cfg *WarmupConfig) UnmarshalJSON(data []byte) (err error) {
+	var tmp struct {
+		File    string `json:"file,omitempty"`
+		Queries int    `json:"queries,omitempty"`
+		MaxRows int    `json:"maxRows,omitempty"`
+		Timeout string `json:"timeoutSeconds,omitempty"`
+	}
+
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if tmp.Timeout != "" {
+		cfg.Timeout, err = time.ParseDuration(tmp.Timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg.File = tmp.File
+	cfg.Queries = tmp.Queries
+	cfg.MaxRows = tmp.MaxRows
+
+	return nil
+}
+
 // NewCurrentConfig returns a copy of the current config.
 func NewCurrentConfig() *TabletConfig {
 	return currentConfig.Clone()
@@ -889,6 +1042,11 @@ func (c *TabletConfig) Verify() error {
 	if v := c.HotRowProtection.MaxConcurrency; v <= 0 {
 		return fmt.Errorf("--hot_row_protection_concurrent_transactions must be > 0 (specified value: %v)", v)
 	}
+	switch c.UnsafeStatementMode {
+	case Disable, Warn, Reject:
+	default:
+		return fmt.Errorf("--unsafe-statement-mode must be one of %s, %s or %s (specified value: %v)", Disable, Warn, Reject, c.UnsafeStatementMode)
+	}
 	return nil
 }
 
@@ -1050,8 +1208,9 @@ var defaultConfig = TabletConfig{
 	// memory copies.  so with the encoding overhead, this seems to work
 	// great (the overhead makes the final packets on the wire about twice
 	// bigger than this).
-	StreamBufferSize: 32 * 1024,
-	QueryCacheMemory: 32 * 1024 * 1024, // 32 mb for our query cache
+	StreamBufferSize:    32 * 1024,
+	MaxStreamBufferSize: 4 * 1024 * 1024,
+	QueryCacheMemory:    32 * 1024 * 1024, // 32 mb for our query cache
 	// The doorkeeper for the plan cache is disabled by default in endtoend tests to ensure
 	// results are consistent between runs.
 	QueryCacheDoorkeeper: !servenv.TestingEndtoend,
@@ -1061,6 +1220,9 @@ var defaultConfig = TabletConfig{
 	// but in busy systems with many tables, some queries may take longer than anticipated.
 	// Therefore, the default value should be generous to ensure completion.
 	SchemaChangeReloadTimeout:  30 * time.Second,
+	SchemaHistorySize:          100,
+	UnsafeStatementMode:        Disable,
+	IdlePollMaxInterval:        time.Minute,
 	MessagePostponeParallelism: 4,
 	SignalWhenSchemaChange:     true,
 
@@ -1085,6 +1247,12 @@ var defaultConfig = TabletConfig{
 
 	EnablePerWorkloadTableMetrics: false,
 	EnableSettingsPool:            true,
+
+	Warmup: WarmupConfig{
+		Queries: 100,
+		MaxRows: 10000,
+		Timeout: 30 * time.Second,
+	},
 }
 
 // defaultTxThrottlerConfig returns the default TxThrottlerConfigFlag object based on
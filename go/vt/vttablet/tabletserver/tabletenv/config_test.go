@@ -53,6 +53,8 @@ func TestConfigParse(t *testing.T) {
 			Timeout:     10 * time.Second,
 			IdleTimeout: 20 * time.Second,
 			MaxLifetime: 50 * time.Second,
+			MaxWaiters:  100,
+			QueueSLO:    5 * time.Millisecond,
 		},
 		RowStreamer: RowStreamerConfig{
 			MaxInnoDBTrxHistLen: 1000,
@@ -87,6 +89,8 @@ oltp: {}
 oltpReadPool:
   idleTimeoutSeconds: 20s
   maxLifetimeSeconds: 50s
+  maxWaiters: 100
+  queueSLOSeconds: 5ms
   size: 16
   timeoutSeconds: 10s
 replicationTracker: {}
@@ -94,6 +98,7 @@ rowStreamer:
   maxInnoDBTrxHistLen: 1000
   maxMySQLReplLagSecs: 400
 txPool: {}
+warmup: {}
 `
 	assert.Equal(t, wantBytes, string(gotBytes))
 
@@ -110,6 +115,8 @@ oltpReadPool:
   size: 16
   idleTimeoutSeconds: 20s
   maxLifetimeSeconds: 50s
+  maxWaiters: 100
+  queueSLOSeconds: 5ms
 `)
 	gotCfg := cfg
 	gotCfg.DB = cfg.DB.Clone()
@@ -136,6 +143,8 @@ hotRowProtection:
   maxGlobalQueueSize: 1000
   maxQueueSize: 20
   mode: disable
+idlePollMaxInterval: 1m0s
+maxStreamBufferSize: 4194304
 messagePostponeParallelism: 4
 olap:
   txTimeoutSeconds: 30s
@@ -158,6 +167,7 @@ rowStreamer:
   maxInnoDBTrxHistLen: 1000000
   maxMySQLReplLagSecs: 43200
 schemaChangeReloadTimeout: 30s
+schemaHistorySize: 100
 schemaReloadIntervalSeconds: 30m0s
 signalWhenSchemaChange: true
 streamBufferSize: 32768
@@ -165,6 +175,11 @@ txPool:
   idleTimeoutSeconds: 30m0s
   size: 20
   timeoutSeconds: 1s
+unsafeStatementMode: disable
+warmup:
+  maxRows: 10000
+  queries: 100
+  timeoutSeconds: 30s
 `
 	utils.MustMatch(t, want, string(gotBytes))
 }
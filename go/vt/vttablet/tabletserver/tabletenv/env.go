@@ -32,6 +32,7 @@ type Env interface {
 	Config() *TabletConfig
 	Exporter() *servenv.Exporter
 	Stats() *Stats
+	Activity() *Activity
 	LogError()
 	Environment() *vtenv.Environment
 }
@@ -40,6 +41,7 @@ type testEnv struct {
 	config   *TabletConfig
 	exporter *servenv.Exporter
 	stats    *Stats
+	activity *Activity
 	env      *vtenv.Environment
 }
 
@@ -51,6 +53,7 @@ func NewEnv(env *vtenv.Environment, config *TabletConfig, exporterName string) E
 		config:   config,
 		exporter: exporter,
 		stats:    NewStats(exporter),
+		activity: NewActivity(config, exporter),
 		env:      env,
 	}
 }
@@ -59,6 +62,7 @@ func (*testEnv) CheckMySQL()                        {}
 func (te *testEnv) Config() *TabletConfig           { return te.config }
 func (te *testEnv) Exporter() *servenv.Exporter     { return te.exporter }
 func (te *testEnv) Stats() *Stats                   { return te.stats }
+func (te *testEnv) Activity() *Activity             { return te.activity }
 func (te *testEnv) Environment() *vtenv.Environment { return te.env }
 
 func (te *testEnv) LogError() {
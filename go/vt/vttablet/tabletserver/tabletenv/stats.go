@@ -39,16 +39,33 @@ type Stats struct {
 	UserTableQueryTimesNs  *stats.CountersWithMultiLabels // Per CallerID/table latencies
 	UserTransactionCount   *stats.CountersWithMultiLabels // Per CallerID transaction counts
 	UserTransactionTimesNs *stats.CountersWithMultiLabels // Per CallerID transaction latencies
+	InternalQueryCount     *stats.CountersWithMultiLabels // Per component/table counts of Vitess-internal queries
+	InternalQueryTimesNs   *stats.CountersWithMultiLabels // Per component/table latencies of Vitess-internal queries
+	UserStreamBlockedCount *stats.CountersWithMultiLabels // Per CallerID/table counts of streamed results blocked by the client
+	UserStreamBlockedNs    *stats.CountersWithMultiLabels // Per CallerID/table time streamed results were blocked by the client
 	ResultHistogram        *stats.Histogram               // Row count histograms
 	TableaclAllowed        *stats.CountersWithMultiLabels // Number of allows
 	TableaclDenied         *stats.CountersWithMultiLabels // Number of denials
 	TableaclPseudoDenied   *stats.CountersWithMultiLabels // Number of pseudo denials
+	TableaclDryRunDenied   *stats.CountersWithMultiLabels // Number of denials of the dry run config
+	DeniedTableQueries     *stats.CountersWithSingleLabel // Per table queries rejected by the denied tables of the shard
+	TableKillSwitchQueries *stats.CountersWithSingleLabel // Per table queries rejected by the table kill switches
+	UnsafeStatements       *stats.CountersWithMultiLabels // Per table/action DMLs unsafe for statement-based replication
+	InterceptorRejections  *stats.CountersWithSingleLabel // Per interceptor queries rejected by the query interceptors
 
 	UserActiveReservedCount *stats.CountersWithSingleLabel // Per CallerID active reserved connection counts
 	UserReservedCount       *stats.CountersWithSingleLabel // Per CallerID reserved connection counts
 	UserReservedTimesNs     *stats.CountersWithSingleLabel // Per CallerID reserved connection duration
+	ReservedConnRecoveries  *stats.CountersWithSingleLabel // Reserved connections whose MySQL connection was lost, by outcome
 
 	QueryTimingsByTabletType *servenv.TimingsWrapper // Query timings split by current tablet type
+
+	QueryPoolQueueTimings     *servenv.TimingsWrapper        // Per workload time spent waiting for a query pool connection
+	QueryPoolQueueSLOExceeded *stats.CountersWithSingleLabel // Per workload query pool waits longer than the queue time objective
+	QueryPoolQueueRejections  *stats.CountersWithMultiLabels // Per workload/reason queries that gave up waiting for a query pool connection
+
+	ReplicationLagSignals *stats.GaugesWithSingleLabel // Per signal replication lag in seconds, -1 if not measured
+	FreshnessPercent      *stats.Gauge                 // Replication freshness score reported through StreamHealth, as a percentage
 }
 
 // NewStats instantiates a new set of stats scoped by exporter.
@@ -88,16 +105,33 @@ func NewStats(exporter *servenv.Exporter) *Stats {
 		UserTableQueryTimesNs:  exporter.NewCountersWithMultiLabels("UserTableQueryTimesNs", "Total latency for each CallerID/table combination", []string{"TableName", "CallerID", "Type"}),
 		UserTransactionCount:   exporter.NewCountersWithMultiLabels("UserTransactionCount", "transactions received for each CallerID", []string{"CallerID", "Conclusion"}),
 		UserTransactionTimesNs: exporter.NewCountersWithMultiLabels("UserTransactionTimesNs", "Total transaction latency for each CallerID", []string{"CallerID", "Conclusion"}),
+		InternalQueryCount:     exporter.NewCountersWithMultiLabels("InternalQueryCount", "Queries generated by Vitess itself for each component/table combination", []string{"TableName", "Component", "Type"}),
+		InternalQueryTimesNs:   exporter.NewCountersWithMultiLabels("InternalQueryTimesNs", "Total latency of the queries generated by Vitess itself for each component/table combination", []string{"TableName", "Component", "Type"}),
+		UserStreamBlockedCount: exporter.NewCountersWithMultiLabels("UserStreamBlockedCount", "Streamed results blocked by client backpressure beyond the threshold for each CallerID/table combination", []string{"TableName", "CallerID"}),
+		UserStreamBlockedNs:    exporter.NewCountersWithMultiLabels("UserStreamBlockedNs", "Total time streamed results were blocked by client backpressure beyond the threshold for each CallerID/table combination", []string{"TableName", "CallerID"}),
 		ResultHistogram:        exporter.NewHistogram("Results", "Distribution of rows returned", []int64{0, 1, 5, 10, 50, 100, 500, 1000, 5000, 10000}),
 		TableaclAllowed:        exporter.NewCountersWithMultiLabels("TableACLAllowed", "ACL acceptances", []string{"TableName", "TableGroup", "PlanID", "Username"}),
 		TableaclDenied:         exporter.NewCountersWithMultiLabels("TableACLDenied", "ACL denials", []string{"TableName", "TableGroup", "PlanID", "Username"}),
 		TableaclPseudoDenied:   exporter.NewCountersWithMultiLabels("TableACLPseudoDenied", "ACL pseudodenials", []string{"TableName", "TableGroup", "PlanID", "Username"}),
+		TableaclDryRunDenied:   exporter.NewCountersWithMultiLabels("TableACLDryRunDenied", "ACL denials of the dry run config, which are not enforced", []string{"TableName", "TableGroup", "PlanID", "Username"}),
+		DeniedTableQueries:     exporter.NewCountersWithSingleLabel("DeniedTableQueries", "Queries rejected because their table is denied on the shard", "TableName"),
+		TableKillSwitchQueries: exporter.NewCountersWithSingleLabel("TableKillSwitchQueries", "Queries rejected because a kill switch disabled the reads or the writes of their table", "TableName"),
+		UnsafeStatements:       exporter.NewCountersWithMultiLabels("UnsafeStatements", "DMLs unsafe for statement-based replication, by whether they were only warned about or rejected", []string{"TableName", "Action"}),
+		InterceptorRejections:  exporter.NewCountersWithSingleLabel("QueryInterceptorRejections", "Queries rejected by the query interceptors", "Interceptor"),
 
 		UserActiveReservedCount: exporter.NewCountersWithSingleLabel("UserActiveReservedCount", "active reserved connection for each CallerID", "CallerID"),
 		UserReservedCount:       exporter.NewCountersWithSingleLabel("UserReservedCount", "reserved connection received for each CallerID", "CallerID"),
 		UserReservedTimesNs:     exporter.NewCountersWithSingleLabel("UserReservedTimesNs", "Total reserved connection latency for each CallerID", "CallerID"),
+		ReservedConnRecoveries:  exporter.NewCountersWithSingleLabel("ReservedConnRecoveries", "Reserved connections whose MySQL connection was lost, by outcome", "outcome", "Reestablished", "Lost"),
 
 		QueryTimingsByTabletType: exporter.NewTimings("QueryTimingsByTabletType", "Query timings broken down by active tablet type", "TabletType"),
+
+		QueryPoolQueueTimings:     exporter.NewTimings("QueryPoolQueueTime", "Time spent waiting for a query pool connection for each workload", "Workload"),
+		QueryPoolQueueSLOExceeded: exporter.NewCountersWithSingleLabel("QueryPoolQueueSLOExceeded", "Query pool waits longer than the queue time objective for each workload", "Workload"),
+		QueryPoolQueueRejections:  exporter.NewCountersWithMultiLabels("QueryPoolQueueRejections", "Queries that gave up waiting for a query pool connection for each workload, because the queue was full or their deadline was exceeded", []string{"Workload", "Reason"}),
+
+		ReplicationLagSignals: exporter.NewGaugesWithSingleLabel("ReplicationLagSignals", "Replication lag in seconds as measured by each signal, -1 if the signal is not measured", "Signal", "SQLThread", "IOThread", "Heartbeat"),
+		FreshnessPercent:      exporter.NewGauge("ReplicationFreshnessPercent", "Replication freshness score reported through StreamHealth, as a percentage"),
 	}
 	stats.QPSRates = exporter.NewRates("QPS", stats.QueryTimings, 15*60/5, 5*time.Second)
 	return stats
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -54,6 +55,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/onlineddl"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/callerroles"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/gc"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/messager"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
@@ -78,6 +80,10 @@ var logPoolFull = logutil.NewThrottledLogger("PoolFull", 1*time.Minute)
 
 var logComputeRowSerializerKey = logutil.NewThrottledLogger("ComputeRowSerializerKey", 1*time.Minute)
 
+// logUnsafeStatement is for throttling the warnings about the statements
+// unsafe for statement-based replication.
+var logUnsafeStatement = logutil.NewThrottledLogger("UnsafeStatement", 1*time.Minute)
+
 // TabletServer implements the RPC interface for the query service.
 // TabletServer is initialized in the following sequence:
 // NewTabletServer->InitDBConfig->SetServingType.
@@ -95,6 +101,7 @@ type TabletServer struct {
 	exporter               *servenv.Exporter
 	config                 *tabletenv.TabletConfig
 	stats                  *tabletenv.Stats
+	activity               *tabletenv.Activity
 	QueryTimeout           atomic.Int64
 	TerseErrors            bool
 	TruncateErrorLen       int
@@ -118,6 +125,13 @@ type TabletServer struct {
 	lagThrottler *throttle.Throttler
 	tableGC      *gc.TableGC
 
+	tableKillSwitches *tableKillSwitches
+
+	credentialsRotation *credentialsRotation
+
+	// queryInterceptors inspect, annotate or reject the queries of the users, in order.
+	queryInterceptors []namedQueryInterceptor
+
 	// sm manages state transitions.
 	sm                *stateManager
 	onlineDDLExecutor *onlineddl.Executor
@@ -125,6 +139,13 @@ type TabletServer struct {
 	// alias is used for identifying this tabletserver in healthcheck responses.
 	alias *topodatapb.TabletAlias
 
+	// callerRoles maps the callers to the MySQL roles activated on their connections.
+	callerRoles atomic.Pointer[callerroles.Mapper]
+
+	// streamBackpressureHook, if set, is called when a streaming query is
+	// blocked by its client for longer than the backpressure threshold.
+	streamBackpressureHook atomic.Pointer[StreamBackpressureHook]
+
 	// This field is only stored for testing
 	checkMysqlGaugeFunc *stats.GaugeFunc
 
@@ -150,6 +171,7 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv := &TabletServer{
 		exporter:               exporter,
 		stats:                  tabletenv.NewStats(exporter),
+		activity:               tabletenv.NewActivity(config, exporter),
 		config:                 config,
 		TerseErrors:            config.TerseErrors,
 		TruncateErrorLen:       config.TruncateErrorLen,
@@ -160,6 +182,12 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	}
 	tsv.QueryTimeout.Store(config.Oltp.QueryTimeout.Nanoseconds())
 
+	interceptors, err := enabledQueryInterceptors(config.QueryInterceptors)
+	if err != nil {
+		log.Exitf("Invalid --query-interceptors: %v", err)
+	}
+	tsv.queryInterceptors = interceptors
+
 	srvTopoServer := srvtopo.NewResilientServer(ctx, topoServer, srvTopoCounts)
 
 	tabletTypeFunc := func() topodatapb.TabletType {
@@ -183,6 +211,8 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv.txThrottler = txthrottler.NewTxThrottler(tsv, topoServer)
 	tsv.te = NewTxEngine(tsv)
 	tsv.messager = messager.NewEngine(tsv, tsv.se, tsv.vstreamer)
+	tsv.tableKillSwitches = newTableKillSwitches(tsv, topoServer)
+	tsv.credentialsRotation = newCredentialsRotation(tsv.exporter)
 
 	tsv.tableGC = gc.NewTableGC(tsv, topoServer, tsv.lagThrottler)
 	tsv.onlineDDLExecutor = onlineddl.NewExecutor(tsv, alias, topoServer, tsv.lagThrottler, tabletTypeFunc, tsv.onlineDDLExecutorToggleTableBuffer, tsv.tableGC.RequestChecks)
@@ -228,6 +258,9 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv.registerMigrationStatusHandler()
 	tsv.registerThrottlerHandlers()
 	tsv.registerDebugEnvHandler()
+	tsv.registerACLEvaluateHandler()
+	tsv.registerTableKillSwitchesHandler()
+	tsv.registerCredentialsRotationHandler()
 
 	return tsv
 }
@@ -275,6 +308,7 @@ func (tsv *TabletServer) InitDBConfig(target *querypb.Target, dbcfgs *dbconfigs.
 	tsv.onlineDDLExecutor.InitDBConfig(target.Keyspace, target.Shard, dbcfgs.DBName)
 	tsv.lagThrottler.InitDBConfig(target.Keyspace, target.Shard)
 	tsv.tableGC.InitDBConfig(target.Keyspace, target.Shard, dbcfgs.DBName)
+	tsv.tableKillSwitches.InitDBConfig(target.Keyspace)
 	return nil
 }
 
@@ -301,6 +335,11 @@ func (tsv *TabletServer) Stats() *tabletenv.Stats {
 	return tsv.stats
 }
 
+// Activity satisfies tabletenv.Env.
+func (tsv *TabletServer) Activity() *tabletenv.Activity {
+	return tsv.activity
+}
+
 // Environment satisfies tabletenv.Env.
 func (tsv *TabletServer) Environment() *vtenv.Environment {
 	return tsv.env
@@ -350,15 +389,28 @@ func (tsv *TabletServer) initACL(tableACLConfigFile string, enforceTableACLConfi
 	}
 }
 
-// InitACL loads the table ACL and sets up a SIGHUP handler for reloading it.
-func (tsv *TabletServer) InitACL(tableACLConfigFile string, enforceTableACLConfig bool, reloadACLConfigFileInterval time.Duration) {
+// initDryRunACL loads the table ACL that is evaluated in dry run mode, if any.
+func (tsv *TabletServer) initDryRunACL(tableACLDryRunConfigFile string) {
+	if tableACLDryRunConfigFile == "" {
+		return
+	}
+	if err := tableacl.InitDryRun(tableACLDryRunConfigFile); err != nil {
+		log.Errorf("Fail to initialize the dry run Table ACL: %v", err)
+	}
+}
+
+// InitACL loads the table ACL, and the one that is evaluated in dry run mode,
+// and sets up a SIGHUP handler for reloading them.
+func (tsv *TabletServer) InitACL(tableACLConfigFile string, tableACLDryRunConfigFile string, enforceTableACLConfig bool, reloadACLConfigFileInterval time.Duration) {
 	tsv.initACL(tableACLConfigFile, enforceTableACLConfig)
+	tsv.initDryRunACL(tableACLDryRunConfigFile)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGHUP)
 	go func() {
 		for range sigChan {
 			tsv.initACL(tableACLConfigFile, enforceTableACLConfig)
+			tsv.initDryRunACL(tableACLDryRunConfigFile)
 		}
 	}()
 
@@ -372,6 +424,72 @@ func (tsv *TabletServer) InitACL(tableACLConfigFile string, enforceTableACLConfi
 	}
 }
 
+// InitCallerRoles loads the mapping of callers to MySQL roles from the given file.
+func (tsv *TabletServer) InitCallerRoles(path string) error {
+	mapper, err := callerroles.Load(path)
+	if err != nil {
+		return err
+	}
+	tsv.callerRoles.Store(mapper)
+	return nil
+}
+
+// StreamBackpressure describes a result of a streaming query that its client
+// took longer than the backpressure threshold to receive.
+type StreamBackpressure struct {
+	// CallerID is the principal, or the username, of the caller.
+	CallerID string
+	// Table is the table of the query plan.
+	Table string
+	// Query is the normalized query.
+	Query string
+	// Blocked is how long sending the result was blocked.
+	Blocked time.Duration
+}
+
+// StreamBackpressureHook is called for every result of a streaming query that
+// is blocked by its client for longer than the backpressure threshold.
+type StreamBackpressureHook func(StreamBackpressure)
+
+// SetStreamBackpressureHook sets the hook that is called when a streaming query
+// is blocked by its client for longer than the backpressure threshold. The hook
+// runs on the streaming path, so it must not block. A nil hook removes it.
+func (tsv *TabletServer) SetStreamBackpressureHook(hook StreamBackpressureHook) {
+	if hook == nil {
+		tsv.streamBackpressureHook.Store(nil)
+		return
+	}
+	tsv.streamBackpressureHook.Store(&hook)
+}
+
+// connSetting returns the setting to apply to the connection the query runs on,
+// which is made of the system settings, and of the caller's roles and attributes
+// when a caller role mapping is loaded. It returns nil when there is nothing to apply.
+func (tsv *TabletServer) connSetting(ctx context.Context, settings []string) (*smartconnpool.Setting, error) {
+	var caller *callerroles.Queries
+	if mapper := tsv.callerRoles.Load(); mapper != nil {
+		caller = mapper.Queries(callerid.EffectiveCallerIDFromContext(ctx), callerid.ImmediateCallerIDFromContext(ctx))
+	}
+	if len(settings) == 0 && caller == nil {
+		return nil, nil
+	}
+	return tsv.qe.GetConnSetting(ctx, settings, caller)
+}
+
+// callerPreQueries returns the given pre-queries of a reserved connection, along
+// with the queries that activate the caller's roles and attributes.
+func (tsv *TabletServer) callerPreQueries(ctx context.Context, preQueries []string) []string {
+	mapper := tsv.callerRoles.Load()
+	if mapper == nil {
+		return preQueries
+	}
+	caller := mapper.Queries(callerid.EffectiveCallerIDFromContext(ctx), callerid.ImmediateCallerIDFromContext(ctx))
+	if caller == nil {
+		return preQueries
+	}
+	return append(slices.Clone(preQueries), caller.Apply...)
+}
+
 // SetServingType changes the serving type of the tabletserver. It starts or
 // stops internal services as deemed necessary.
 // Returns true if the state of QueryService or the tablet type changed.
@@ -408,7 +526,7 @@ func (tsv *TabletServer) StopService() {
 func (tsv *TabletServer) IsHealthy() error {
 	if topoproto.IsServingType(tsv.sm.Target().TabletType) {
 		_, err := tsv.Execute(
-			tabletenv.LocalContext(),
+			callerid.NewInternalContext(tabletenv.LocalContext(), "HealthCheck"),
 			nil,
 			"/* health */ select 1 from dual",
 			nil,
@@ -501,12 +619,9 @@ func (tsv *TabletServer) begin(ctx context.Context, target *querypb.Target, save
 			if tsv.txThrottler.Throttle(tsv.getPriorityFromOptions(options), options.GetWorkloadName()) {
 				return errTxThrottled
 			}
-			var connSetting *smartconnpool.Setting
-			if len(settings) > 0 {
-				connSetting, err = tsv.qe.GetConnSetting(ctx, settings)
-				if err != nil {
-					return err
-				}
+			connSetting, err := tsv.connSetting(ctx, settings)
+			if err != nil {
+				return err
 			}
 			transactionID, beginSQL, sessionStateChanges, err := tsv.te.Begin(ctx, savepointQueries, reservedID, connSetting, options)
 			state.TransactionID = transactionID
@@ -792,6 +907,28 @@ func (tsv *TabletServer) Execute(ctx context.Context, target *querypb.Target, sq
 	return tsv.execute(ctx, target, sql, bindVariables, transactionID, reservedID, nil, options)
 }
 
+// ExecuteBatch executes a batch of queries one after the other, and returns one result per query.
+// The whole batch is sent in a single round trip, which makes it cheap to resolve a large number of
+// point lookups against this tablet. Execution stops at the first error.
+func (tsv *TabletServer) ExecuteBatch(ctx context.Context, target *querypb.Target, queries []*querypb.BoundQuery, transactionID, reservedID int64, options *querypb.ExecuteOptions) ([]*sqltypes.Result, error) {
+	span, ctx := trace.NewSpan(ctx, "TabletServer.ExecuteBatch")
+	defer span.Finish()
+
+	if transactionID != 0 && reservedID != 0 && transactionID != reservedID {
+		return nil, vterrors.New(vtrpcpb.Code_INTERNAL, "[BUG] transactionID and reserveID must match if both are non-zero")
+	}
+
+	results := make([]*sqltypes.Result, 0, len(queries))
+	for _, query := range queries {
+		result, err := tsv.execute(ctx, target, query.Sql, query.BindVariables, transactionID, reservedID, nil, options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
 func (tsv *TabletServer) execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID int64, reservedID int64, settings []string, options *querypb.ExecuteOptions) (result *sqltypes.Result, err error) {
 	allowOnShutdown := false
 	timeout := tsv.loadQueryTimeout()
@@ -829,12 +966,9 @@ func (tsv *TabletServer) execute(ctx context.Context, target *querypb.Target, sq
 			logStats.ReservedID = reservedID
 			logStats.TransactionID = transactionID
 
-			var connSetting *smartconnpool.Setting
-			if len(settings) > 0 {
-				connSetting, err = tsv.qe.GetConnSetting(ctx, settings)
-				if err != nil {
-					return err
-				}
+			connSetting, err := tsv.connSetting(ctx, settings)
+			if err != nil {
+				return err
 			}
 			targetType, err := tsv.resolveTargetType(ctx, target)
 			if err != nil {
@@ -935,12 +1069,9 @@ func (tsv *TabletServer) streamExecute(ctx context.Context, target *querypb.Targ
 			logStats.ReservedID = reservedID
 			logStats.TransactionID = transactionID
 
-			var connSetting *smartconnpool.Setting
-			if len(settings) > 0 {
-				connSetting, err = tsv.qe.GetConnSetting(ctx, settings)
-				if err != nil {
-					return err
-				}
+			connSetting, err := tsv.connSetting(ctx, settings)
+			if err != nil {
+				return err
 			}
 			qre := &QueryExecutor{
 				query:            query,
@@ -955,7 +1086,11 @@ func (tsv *TabletServer) streamExecute(ctx context.Context, target *querypb.Targ
 				targetTabletType: target.GetTabletType(),
 				setting:          connSetting,
 			}
-			return qre.Stream(callback)
+			return qre.Stream(func(result *sqltypes.Result) error {
+				// A long stream is traffic for as long as it sends results.
+				tsv.activity.Record()
+				return callback(result)
+			})
 		},
 	)
 }
@@ -1099,7 +1234,10 @@ func (tsv *TabletServer) MessageStream(ctx context.Context, target *querypb.Targ
 				logStats: logStats,
 				tsv:      tsv,
 			}
-			return qre.MessageStream(callback)
+			return qre.MessageStream(func(result *sqltypes.Result) error {
+				tsv.activity.Record()
+				return callback(result)
+			})
 		},
 	)
 }
@@ -1184,7 +1322,11 @@ func (tsv *TabletServer) VStream(ctx context.Context, request *binlogdatapb.VStr
 	if err := tsv.sm.VerifyTarget(ctx, request.Target); err != nil {
 		return err
 	}
-	return tsv.vstreamer.Stream(ctx, request.Position, request.TableLastPKs, request.Filter, throttlerapp.VStreamerName, send)
+	tsv.activity.Record()
+	return tsv.vstreamer.Stream(ctx, request.Position, request.TableLastPKs, request.Filter, throttlerapp.VStreamerName, func(events []*binlogdatapb.VEvent) error {
+		tsv.activity.Record()
+		return send(events)
+	})
 }
 
 // VStreamRows streams rows from the specified starting point.
@@ -1200,7 +1342,11 @@ func (tsv *TabletServer) VStreamRows(ctx context.Context, request *binlogdatapb.
 		}
 		row = r.Rows[0]
 	}
-	return tsv.vstreamer.StreamRows(ctx, request.Query, row, send)
+	tsv.activity.Record()
+	return tsv.vstreamer.StreamRows(ctx, request.Query, row, func(rows *binlogdatapb.VStreamRowsResponse) error {
+		tsv.activity.Record()
+		return send(rows)
+	})
 }
 
 // VStreamTables streams all tables.
@@ -1208,7 +1354,11 @@ func (tsv *TabletServer) VStreamTables(ctx context.Context, request *binlogdatap
 	if err := tsv.sm.VerifyTarget(ctx, request.Target); err != nil {
 		return err
 	}
-	return tsv.vstreamer.StreamTables(ctx, send)
+	tsv.activity.Record()
+	return tsv.vstreamer.StreamTables(ctx, func(tables *binlogdatapb.VStreamTablesResponse) error {
+		tsv.activity.Record()
+		return send(tables)
+	})
 }
 
 // VStreamResults streams rows from the specified starting point.
@@ -1216,7 +1366,11 @@ func (tsv *TabletServer) VStreamResults(ctx context.Context, target *querypb.Tar
 	if err := tsv.sm.VerifyTarget(ctx, target); err != nil {
 		return err
 	}
-	return tsv.vstreamer.StreamResults(ctx, query, send)
+	tsv.activity.Record()
+	return tsv.vstreamer.StreamResults(ctx, query, func(results *binlogdatapb.VStreamResultsResponse) error {
+		tsv.activity.Record()
+		return send(results)
+	})
 }
 
 // ReserveBeginExecute implements the QueryService interface
@@ -1249,7 +1403,7 @@ func (tsv *TabletServer) ReserveBeginExecute(ctx context.Context, target *queryp
 				return err
 			}
 			defer tsv.stats.QueryTimingsByTabletType.Record(targetType.String(), time.Now())
-			connID, sessionStateChanges, err = tsv.te.ReserveBegin(ctx, options, preQueries, postBeginQueries)
+			connID, sessionStateChanges, err = tsv.te.ReserveBegin(ctx, options, tsv.callerPreQueries(ctx, preQueries), postBeginQueries)
 			if err != nil {
 				return err
 			}
@@ -1299,7 +1453,7 @@ func (tsv *TabletServer) ReserveBeginStreamExecute(
 				return err
 			}
 			defer tsv.stats.QueryTimingsByTabletType.Record(targetType.String(), time.Now())
-			connID, sessionStateChanges, err = tsv.te.ReserveBegin(ctx, options, preQueries, postBeginQueries)
+			connID, sessionStateChanges, err = tsv.te.ReserveBegin(ctx, options, tsv.callerPreQueries(ctx, preQueries), postBeginQueries)
 			if err != nil {
 				return err
 			}
@@ -1357,7 +1511,7 @@ func (tsv *TabletServer) ReserveExecute(ctx context.Context, target *querypb.Tar
 				return err
 			}
 			defer tsv.stats.QueryTimingsByTabletType.Record(targetType.String(), time.Now())
-			state.ReservedID, err = tsv.te.Reserve(ctx, options, transactionID, preQueries)
+			state.ReservedID, err = tsv.te.Reserve(ctx, options, transactionID, tsv.callerPreQueries(ctx, preQueries))
 			if err != nil {
 				return err
 			}
@@ -1412,7 +1566,7 @@ func (tsv *TabletServer) ReserveStreamExecute(
 				return err
 			}
 			defer tsv.stats.QueryTimingsByTabletType.Record(targetType.String(), time.Now())
-			state.ReservedID, err = tsv.te.Reserve(ctx, options, transactionID, preQueries)
+			state.ReservedID, err = tsv.te.Reserve(ctx, options, transactionID, tsv.callerPreQueries(ctx, preQueries))
 			if err != nil {
 				return err
 			}
@@ -1509,6 +1663,12 @@ func (tsv *TabletServer) GetSchema(ctx context.Context, target *querypb.Target,
 				logStats: logStats,
 				tsv:      tsv,
 			}
+			if component := callerid.InternalComponentFromContext(ctx); component != "" {
+				// Like schema tracking, the requests of Vitess itself are counted apart.
+				defer func(start time.Time) {
+					qre.recordInternalQuery(component, "", "GetSchema", int64(time.Since(start)))
+				}(time.Now())
+			}
 			return qre.GetSchemaDefinitions(tableType, tableNames, callback)
 		},
 	)
@@ -1547,6 +1707,7 @@ func (tsv *TabletServer) execRequest(
 	if err = tsv.sm.StartRequest(ctx, target, allowOnShutdown); err != nil {
 		return err
 	}
+	tsv.activity.Record()
 
 	ctx, cancel := withTimeout(ctx, timeout, options)
 	defer func() {
@@ -1680,7 +1841,7 @@ func convertErrorCode(err error) vtrpcpb.Code {
 	case sqlerror.ERDiskFull, sqlerror.EROutOfMemory, sqlerror.EROutOfSortMemory, sqlerror.ERConCount, sqlerror.EROutOfResources, sqlerror.ERRecordFileFull, sqlerror.ERHostIsBlocked,
 		sqlerror.ERCantCreateThread, sqlerror.ERTooManyDelayedThreads, sqlerror.ERNetPacketTooLarge, sqlerror.ERTooManyUserConnections, sqlerror.ERLockTableFull, sqlerror.ERUserLimitReached:
 		errCode = vtrpcpb.Code_RESOURCE_EXHAUSTED
-	case sqlerror.ERLockWaitTimeout:
+	case sqlerror.ERLockWaitTimeout, sqlerror.ERQueryTimeout:
 		errCode = vtrpcpb.Code_DEADLINE_EXCEEDED
 	case sqlerror.CRServerGone, sqlerror.ERServerShutdown, sqlerror.ERServerIsntAvailable, sqlerror.CRConnectionError, sqlerror.CRConnHostError:
 		errCode = vtrpcpb.Code_UNAVAILABLE
@@ -1791,6 +1952,7 @@ func (tsv *TabletServer) HandlePanic(err *error) {
 // Close shuts down any remaining go routines
 func (tsv *TabletServer) Close(ctx context.Context) error {
 	tsv.sm.closeAll()
+	tsv.tableKillSwitches.Close()
 	tsv.stats.Stop()
 	return nil
 }
@@ -1971,6 +2133,18 @@ func (tsv *TabletServer) registerThrottlerHandlers() {
 	tsv.registerThrottlerThrottleAppHandler()
 }
 
+func (tsv *TabletServer) registerACLEvaluateHandler() {
+	tsv.exporter.HandleFunc("/debug/acl/evaluate", func(w http.ResponseWriter, r *http.Request) {
+		aclEvaluateHandler(tsv, w, r)
+	})
+}
+
+func (tsv *TabletServer) registerTableKillSwitchesHandler() {
+	tsv.exporter.HandleFunc("/debug/table_kill_switches", func(w http.ResponseWriter, r *http.Request) {
+		tableKillSwitchesHandler(tsv.tableKillSwitches, w, r)
+	})
+}
+
 func (tsv *TabletServer) registerDebugEnvHandler() {
 	tsv.exporter.HandleFunc("/debug/env", func(w http.ResponseWriter, r *http.Request) {
 		debugEnvHandler(tsv, w, r)
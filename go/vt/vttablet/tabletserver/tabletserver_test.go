@@ -24,6 +24,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"syscall"
@@ -712,6 +713,43 @@ func TestTabletServerExecNonExistentConnection(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestTabletServerExecuteBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+
+	result1 := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	result2 := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "2")
+	db.AddQueryPattern("select 1 .*", result1)
+	db.AddQueryPattern("select 2 .*", result2)
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+	options := &querypb.ExecuteOptions{}
+
+	// every query gets its own result, in the order of the batch
+	results, err := tsv.ExecuteBatch(ctx, &target, []*querypb.BoundQuery{
+		{Sql: "select 1 from dual"},
+		{Sql: "select 2 from dual"},
+		{Sql: "select 1 from dual"},
+	}, 0, 0, options)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, result1.Rows, results[0].Rows)
+	assert.Equal(t, result2.Rows, results[1].Rows)
+	assert.Equal(t, result1.Rows, results[2].Rows)
+
+	// the batch fails as a whole when one of its queries fails
+	_, err = tsv.ExecuteBatch(ctx, &target, []*querypb.BoundQuery{
+		{Sql: "select 1 from dual"},
+		{Sql: "select 3 from dual"},
+	}, 0, 0, options)
+	require.Error(t, err)
+
+	_, err = tsv.ExecuteBatch(ctx, &target, []*querypb.BoundQuery{{Sql: "select 1 from dual"}}, 1, 2, options)
+	require.EqualError(t, err, "[BUG] transactionID and reserveID must match if both are non-zero")
+}
+
 func TestTabletServerReleaseNonExistentConnection(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -843,6 +881,36 @@ func TestTabletServerStreamExecute(t *testing.T) {
 	}
 }
 
+func TestTabletServerStreamExecuteActivity(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.IdlePollThreshold = 100 * time.Millisecond
+	db, tsv := setupTabletServerTestCustom(t, ctx, cfg, "", vtenv.NewTestEnv())
+	defer tsv.StopService()
+	defer db.Close()
+
+	executeSQL := "select * from test_table limit 1000"
+	db.AddQuery(executeSQL, &sqltypes.Result{
+		Fields: []*querypb.Field{{Type: sqltypes.VarBinary}},
+		Rows:   [][]sqltypes.Value{{sqltypes.NewVarBinary("row01")}},
+	})
+
+	// The tablet isn't idle for as long as the stream sends results.
+	sent := 0
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+	err := tsv.StreamExecute(ctx, &target, executeSQL, nil, 0, 0, nil, func(*sqltypes.Result) error {
+		if sent > 0 {
+			assert.False(t, tsv.activity.Idle())
+		}
+		sent++
+		require.Eventually(t, tsv.activity.Idle, 5*time.Second, 10*time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Greater(t, sent, 1)
+}
+
 func TestTabletServerStreamExecuteComments(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -2092,7 +2160,7 @@ func TestACLHUP(t *testing.T) {
 	err = f.Close()
 	require.NoError(t, err)
 
-	tsv.InitACL(f.Name(), true, 0)
+	tsv.InitACL(f.Name(), "", true, 0)
 
 	groups1 := tableacl.GetCurrentConfig().TableGroups
 	if name1 := groups1[0].GetName(); name1 != "group01" {
@@ -2234,6 +2302,45 @@ func TestReserveExecute_WithoutTx(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCallerRoles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, tsv := setupTabletServerTest(t, ctx, "")
+	defer tsv.StopService()
+	defer db.Close()
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+
+	mappingFile := filepath.Join(t.TempDir(), "roles.json")
+	require.NoError(t, os.WriteFile(mappingFile, []byte(`{"users": {"alice": ["app_reader"]}, "groups": {"admins": ["app_admin@%"]}}`), 0600))
+	require.NoError(t, tsv.InitCallerRoles(mappingFile))
+
+	db.AddQuery("set role 'app_admin'@'%', 'app_reader'", &sqltypes.Result{})
+	db.AddQuery("set role default", &sqltypes.Result{})
+	ctx = callerid.NewContext(ctx, nil, &querypb.VTGateCallerID{Username: "alice", Groups: []string{"admins"}})
+
+	// The roles are activated on the pooled connection before the query.
+	_, err := tsv.Execute(ctx, &target, "select 42", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, db.GetQueryCalledNum("set role 'app_admin'@'%', 'app_reader'"))
+
+	// And on reserved connections.
+	tsv.config.EnableSettingsPool = false
+	db.ResetQueryLog()
+	state, _, err := tsv.ReserveExecute(ctx, &target, nil, "select 42", nil, 0, &querypb.ExecuteOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, strings.Split(db.QueryLog(), ";"), "set role 'app_admin'@'%', 'app_reader'")
+	require.NoError(t, tsv.Release(ctx, &target, 0, state.ReservedID))
+
+	// Callers that are not mapped to any role keep the connection's default roles.
+	called := db.GetQueryCalledNum("set role 'app_admin'@'%', 'app_reader'")
+	ctx = callerid.NewContext(context.Background(), nil, &querypb.VTGateCallerID{Username: "bob"})
+	_, err = tsv.Execute(ctx, &target, "select 42", nil, 0, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, called, db.GetQueryCalledNum("set role 'app_admin'@'%', 'app_reader'"))
+
+	require.ErrorContains(t, tsv.InitCallerRoles(filepath.Join(t.TempDir(), "missing.json")), "cannot read caller role mapping file")
+}
+
 func TestReserveExecute_WithTx(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
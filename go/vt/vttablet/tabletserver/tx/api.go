@@ -57,6 +57,8 @@ type (
 		Autocommit      bool
 		Conclusion      string
 		LogToFile       bool
+		// Tag is the name given by the application to the transaction, if any.
+		Tag string
 
 		Stats *servenv.TimingsWrapper
 	}
@@ -144,7 +146,7 @@ func (p *Properties) String(sanitize bool, parser *sqlparser.Parser) string {
 	}
 
 	return fmt.Sprintf(
-		"'%v'\t'%v'\t%v\t%v\t%.6f\t%v\t%v\t\n",
+		"'%v'\t'%v'\t%v\t%v\t%.6f\t%v\t%v\t'%v'\t\n",
 		p.EffectiveCaller,
 		p.ImmediateCaller,
 		p.StartTime.Format(time.StampMicro),
@@ -152,5 +154,6 @@ func (p *Properties) String(sanitize bool, parser *sqlparser.Parser) string {
 		p.EndTime.Sub(p.StartTime).Seconds(),
 		p.Conclusion,
 		printQueries(),
+		p.Tag,
 	)
 }
@@ -590,6 +590,7 @@ func (te *TxEngine) taintConn(ctx context.Context, conn *StatefulConnection, pre
 			conn.Releasef("error during connection setup: %s\n%v", query, err)
 			return err
 		}
+		conn.recordSetupQuery(query)
 	}
 	return nil
 }
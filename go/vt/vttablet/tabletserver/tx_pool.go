@@ -280,6 +280,7 @@ func (tp *TxPool) begin(ctx context.Context, options *querypb.ExecuteOptions, re
 	}
 
 	conn.txProps = tp.NewTxProps(immediateCaller, effectiveCaller, autocommit)
+	conn.txProps.Tag = options.GetTransactionTag()
 
 	return beginQueries, sessionStateChanges, nil
 }
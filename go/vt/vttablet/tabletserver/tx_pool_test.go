@@ -161,6 +161,20 @@ func TestTxPoolTransactionIsolation(t *testing.T) {
 	requireLogs(t, db.QueryLog(), "begin")
 }
 
+func TestTxPoolTransactionTag(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, txPool, _, closer := setup(t)
+	defer closer()
+
+	conn, _, _, err := txPool.Begin(ctx, &querypb.ExecuteOptions{TransactionTag: "checkout.payment"}, false, 0, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "checkout.payment", conn.TxProperties().Tag)
+	require.Contains(t, conn.String(false, txPool.env.Environment().Parser()), "\t'checkout.payment'\t")
+	conn.Release(tx.TxClose)
+}
+
 func TestTxPoolAutocommit(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -32,6 +32,7 @@ import (
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 )
 
 var (
@@ -41,6 +42,7 @@ var (
 				<th>Transaction id</th>
 				<th>Effective caller</th>
 				<th>Immediate caller</th>
+				<th>Tag</th>
 				<th>Start</th>
 				<th>End</th>
 				<th>Duration</th>
@@ -56,9 +58,10 @@ var (
 	}
 	txlogzTmpl = template.Must(template.New("example").Funcs(txlogzFuncMap).Parse(`
 		<tr class="{{.ColorLevel}}">
-			<td>{{.TransactionID}}</td>
-			<td>{{.EffectiveCallerID | getEffectiveCaller}}</td>
-			<td>{{.ImmediateCallerID | getImmediateCaller}}</td>
+			<td>{{.ConnID}}</td>
+			<td>{{.EffectiveCaller | getEffectiveCaller}}</td>
+			<td>{{.ImmediateCaller | getImmediateCaller}}</td>
+			<td>{{.Tag}}</td>
 			<td>{{.StartTime | stampMicro}}</td>
 			<td>{{.EndTime | stampMicro}}</td>
 			<td>{{.Duration}}</td>
@@ -138,10 +141,11 @@ func writeTransactionData(w http.ResponseWriter, txc *StatefulConnection) {
 		level = "high"
 	}
 	tmplData := struct {
-		*StatefulConnection
+		*tx.Properties
+		ConnID     tx.ConnID
 		Duration   float64
 		ColorLevel string
-	}{txc, duration, level}
+	}{props, txc.ConnID, duration, level}
 	if err := txlogzTmpl.Execute(w, tmplData); err != nil {
 		log.Errorf("txlogz: couldn't execute template: %v", err)
 	}
@@ -88,6 +88,38 @@ func testHandler(req *http.Request, t *testing.T) {
 	streamlog.SetRedactDebugUIQueries(false)
 }
 
+func TestTxlogzTransactionData(t *testing.T) {
+	txConn := &StatefulConnection{
+		ConnID: 123456,
+		txProps: &tx.Properties{
+			EffectiveCaller: callerid.NewEffectiveCallerID("effective-caller", "component", "subcomponent"),
+			ImmediateCaller: callerid.NewImmediateCallerID("immediate-caller"),
+			StartTime:       time.Now(),
+			Conclusion:      "kill",
+			Queries:         []string{"select * from test"},
+			Tag:             "checkout.payment",
+		},
+	}
+	txConn.txProps.EndTime = txConn.txProps.StartTime.Add(2 * time.Second)
+
+	response := httptest.NewRecorder()
+	writeTransactionData(response, txConn)
+	body := response.Body.String()
+	for _, want := range []string{
+		`<tr class="high">`,
+		"<td>123456</td>",
+		"<td>effective-caller</td>",
+		"<td>immediate-caller</td>",
+		"<td>checkout.payment</td>",
+		"<td>kill</td>",
+		"select * from test",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("transaction data does not contain %q: %s", want, body)
+		}
+	}
+}
+
 func TestTxlogzHandler(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/txlogz?timeout=0&limit=10", nil)
 	testHandler(req, t)
@@ -0,0 +1,313 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// warmupRecordInterval is how often the hottest queries are recorded.
+var warmupRecordInterval = 1 * time.Minute
+
+// warmupQuery is a hot read query, as recorded in the warmup file.
+type warmupQuery struct {
+	Query  string   `json:"query"`
+	Tables []string `json:"tables"`
+	Count  uint64   `json:"count"`
+}
+
+// queryWarmer records the hottest read queries of the query engine to a file,
+// so that the buffer pool of MySQL can be warmed up by replaying them once
+// MySQL restarted, e.g. after a restart of the tablet or a restore, before
+// the tablet serves queries again.
+type queryWarmer struct {
+	qe     *QueryEngine
+	config tabletenv.WarmupConfig
+	ticks  *timer.Timer
+
+	replays  *stats.Counter
+	errors   *stats.Counter
+	duration *stats.Gauge
+	recorded atomic.Bool
+
+	// mysqlStarted is when MySQL started, as of the last warmup. The query
+	// engine opens again on every transition out of the not connected state,
+	// but MySQL only needs to be warmed up again once it restarted.
+	mysqlStarted time.Time
+}
+
+// newQueryWarmer returns a queryWarmer for the query engine, or nil if the
+// warmup is disabled.
+func newQueryWarmer(env tabletenv.Env, qe *QueryEngine) *queryWarmer {
+	config := env.Config().Warmup
+	if config.File == "" {
+		return nil
+	}
+	return &queryWarmer{
+		qe:       qe,
+		config:   config,
+		ticks:    timer.NewTimer(warmupRecordInterval),
+		replays:  env.Exporter().NewCounter("WarmupQueries", "Number of hot queries replayed to warm up the MySQL buffer pool"),
+		errors:   env.Exporter().NewCounter("WarmupErrors", "Number of failed replays of hot queries to warm up the MySQL buffer pool"),
+		duration: env.Exporter().NewGauge("WarmupDurationMs", "Duration of the last warmup of the MySQL buffer pool, in milliseconds"),
+	}
+}
+
+// Open warms up MySQL with the queries recorded in the file if MySQL
+// restarted since the last warmup, then starts recording the hottest queries.
+// It must be called once the connection pools of the query engine are open.
+func (qw *queryWarmer) Open() {
+	if qw == nil {
+		return
+	}
+	if started, restarted := qw.mysqlRestarted(); restarted {
+		qw.warmUp()
+		qw.mysqlStarted = started
+	}
+	qw.recorded.Store(false)
+	qw.ticks.Start(func() {
+		if err := qw.record(); err != nil {
+			log.Warningf("Failed to record the hottest queries for the warmup: %v", err)
+		}
+	})
+}
+
+// Close stops recording the hottest queries, after recording them one last
+// time. It must be called while the query plans are still cached.
+func (qw *queryWarmer) Close() {
+	if qw == nil {
+		return
+	}
+	qw.ticks.Stop()
+	if err := qw.record(); err != nil {
+		log.Warningf("Failed to record the hottest queries for the warmup: %v", err)
+	}
+}
+
+// mysqlRestarted returns when MySQL started, and whether that is after the
+// last warmup. MySQL is assumed to have restarted if its uptime can't be read.
+func (qw *queryWarmer) mysqlRestarted() (time.Time, bool) {
+	uptime, err := qw.mysqlUptime()
+	if err != nil {
+		log.Warningf("Failed to read the uptime of MySQL, warming it up: %v", err)
+		return time.Time{}, true
+	}
+	started := time.Now().Add(-uptime)
+	// The uptime is in seconds, so the start time is only accurate to a
+	// second or so.
+	return started, qw.mysqlStarted.IsZero() || started.Sub(qw.mysqlStarted) > 2*time.Second
+}
+
+func (qw *queryWarmer) mysqlUptime() (time.Duration, error) {
+	ctx := tabletenv.LocalContext()
+	conn, err := qw.qe.conns.Get(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Recycle()
+	qr, err := conn.Conn.Exec(ctx, "show global status like 'Uptime'", 1, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 2 {
+		return 0, fmt.Errorf("unexpected result for the uptime of MySQL: %v", qr.Rows)
+	}
+	seconds, err := qr.Rows[0][1].ToCastInt64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// sample keeps the final SQL of a read query of the plan, which the warmup
+// replays if the plan is among the hottest. Only the first query of each plan
+// is kept.
+func (qw *queryWarmer) sample(plan *TabletPlan, sql string) {
+	if qw == nil || plan.PlanID != planbuilder.PlanSelect || plan.warmupSample.Load() != nil {
+		return
+	}
+	plan.warmupSample.Store(&sql)
+}
+
+// hottestQueries returns the sampled read queries of the plan cache that ran
+// the most.
+func (qw *queryWarmer) hottestQueries() []warmupQuery {
+	var queries []warmupQuery
+	qw.qe.ForEachPlan(func(plan *TabletPlan) bool {
+		if plan.PlanID != planbuilder.PlanSelect || len(plan.AllTables) == 0 {
+			return true
+		}
+		sample := plan.warmupSample.Load()
+		if sample == nil {
+			return true
+		}
+		count, _, _, _, _, _ := plan.Stats()
+		if count == 0 {
+			return true
+		}
+		query := warmupQuery{Query: *sample, Count: count}
+		for _, table := range plan.AllTables {
+			query.Tables = append(query.Tables, table.Name.String())
+		}
+		queries = append(queries, query)
+		return true
+	})
+	sort.SliceStable(queries, func(i, j int) bool {
+		return queries[i].Count > queries[j].Count
+	})
+	if len(queries) > qw.config.Queries {
+		queries = queries[:qw.config.Queries]
+	}
+	return queries
+}
+
+// record writes the hottest queries to the file. The file is replaced
+// atomically, so that a crash never leaves a truncated file behind. The file
+// is not overwritten until queries ran since the query engine opened, so that
+// the queries recorded before a restart survive until the tablet serves again.
+func (qw *queryWarmer) record() error {
+	queries := qw.hottestQueries()
+	if len(queries) == 0 && !qw.recorded.Load() {
+		return nil
+	}
+	data, err := json.MarshalIndent(queries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(qw.config.File), filepath.Base(qw.config.File)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), qw.config.File); err != nil {
+		return err
+	}
+	qw.recorded.Store(true)
+	return nil
+}
+
+// load reads the queries recorded in the file. A missing file has no queries.
+func (qw *queryWarmer) load() ([]warmupQuery, error) {
+	data, err := os.ReadFile(qw.config.File)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var queries []warmupQuery
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("invalid warmup file %v: %v", qw.config.File, err)
+	}
+	return queries, nil
+}
+
+// warmUp replays the recorded queries, the hottest first, until all of them
+// are replayed or the warmup times out. The queries on tables that no longer
+// exist are skipped.
+func (qw *queryWarmer) warmUp() {
+	queries, err := qw.load()
+	if err != nil {
+		log.Warningf("Skipping the warmup: %v", err)
+		return
+	}
+	if len(queries) == 0 {
+		return
+	}
+
+	start := time.Now()
+	defer func() {
+		qw.duration.Set(time.Since(start).Milliseconds())
+	}()
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), qw.config.Timeout)
+	defer cancel()
+
+	log.Infof("Warming up the MySQL buffer pool with %d queries", len(queries))
+	current := qw.qe.schema.Load().tables
+	for _, query := range queries {
+		if ctx.Err() != nil {
+			log.Infof("Warmup timed out after %v", qw.config.Timeout)
+			return
+		}
+		if slices.ContainsFunc(query.Tables, func(table string) bool {
+			_, ok := current[table]
+			return !ok
+		}) {
+			continue
+		}
+		if err := qw.replay(ctx, query.Query); err != nil {
+			qw.errors.Add(1)
+			log.Warningf("Failed to replay query %v for the warmup: %v", qw.qe.env.Environment().Parser().TruncateForLog(query.Query), err)
+			continue
+		}
+		qw.replays.Add(1)
+	}
+	log.Infof("Warmup done in %v", time.Since(start))
+}
+
+// replay runs the query, and discards its rows. A query without a limit only
+// reads its first rows.
+func (qw *queryWarmer) replay(ctx context.Context, query string) error {
+	stmt, err := qw.qe.env.Environment().Parser().Parse(query)
+	if err != nil {
+		return err
+	}
+	sel, ok := stmt.(sqlparser.SelectStatement)
+	if !ok {
+		return fmt.Errorf("not a read query")
+	}
+	if sel.GetLimit() == nil {
+		sel.SetLimit(sqlparser.NewLimitWithoutOffset(qw.config.MaxRows))
+	}
+	sel.SetComments(sqlparser.Comments{"/* warmup */"})
+
+	conn, err := qw.qe.streamConns.Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Recycle()
+	return conn.Conn.Stream(ctx, sqlparser.String(sel), func(*sqltypes.Result) error {
+		return nil
+	}, allocStreamResult, int(qw.qe.streamBufferSize.Load()), querypb.ExecuteOptions_TYPE_ONLY)
+}
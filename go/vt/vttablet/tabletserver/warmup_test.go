@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/cache/theine"
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema/schematest"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+)
+
+func TestQueryWarmer(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	schematest.AddDefaultQueries(db)
+	addSchemaEngineQueries(db)
+
+	file := filepath.Join(t.TempDir(), "warmup.json")
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.DB = newDBConfigs(db)
+	cfg.Warmup.File = file
+	cfg.Warmup.Queries = 2
+	cfg.Warmup.MaxRows = 100
+	env := tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "TabletServerTest")
+	se := schema.NewEngine(env)
+	se.InitDBConfig(cfg.DB.DbaWithDB())
+	qe := NewQueryEngine(env, se)
+	require.NotNil(t, qe.warmer)
+	// Cache the plans of the queries that only run once.
+	qe.plans = theine.NewStore[PlanCacheKey, *TabletPlan](4*1024*1024, false)
+
+	require.NoError(t, se.Open())
+	defer se.Close()
+	// There is nothing to warm up yet.
+	db.AddQuery("show global status like 'Uptime'", uptimeResult(1000))
+	require.NoError(t, qe.Open())
+
+	ctx := context.Background()
+	counts := map[string]uint64{
+		"select * from test_table_01":                              3,
+		"select * from test_table_02":                              5,
+		"select * from test_table_01 join test_table_03 on 1 != 1": 1,
+		"insert into test_table_03(pk) values (1)":                 10,
+	}
+	for query, count := range counts {
+		plan, err := qe.GetPlan(ctx, tabletenv.NewLogStats(ctx, "GetPlan"), query, false)
+		require.NoError(t, err)
+		plan.AddStats(count, time.Millisecond, time.Millisecond, 0, 0, 0)
+		qe.warmer.sample(plan, query)
+	}
+
+	// Closing records the hottest reads.
+	qe.Close()
+	queries, err := qe.warmer.load()
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, warmupQuery{Query: "select * from test_table_02", Tables: []string{"test_table_02"}, Count: 5}, queries[0])
+	assert.Equal(t, warmupQuery{Query: "select * from test_table_01", Tables: []string{"test_table_01"}, Count: 3}, queries[1])
+
+	// Opening once MySQL restarted replays the hottest reads.
+	db.AddQuery("show global status like 'Uptime'", uptimeResult(0))
+	db.AddQuery("select /* warmup */ * from test_table_01 limit 100", &sqltypes.Result{})
+	db.AddQuery("select /* warmup */ * from test_table_02 limit 100", &sqltypes.Result{})
+	replays := qe.warmer.replays.Get()
+	require.NoError(t, qe.Open())
+	assert.EqualValues(t, 2, qe.warmer.replays.Get()-replays)
+	assert.EqualValues(t, 1, db.GetQueryCalledNum("select /* warmup */ * from test_table_01 limit 100"))
+	assert.EqualValues(t, 1, db.GetQueryCalledNum("select /* warmup */ * from test_table_02 limit 100"))
+
+	// Opening again while MySQL kept running doesn't replay them again.
+	qe.Close()
+	require.NoError(t, qe.Open())
+	defer qe.Close()
+	assert.EqualValues(t, 2, qe.warmer.replays.Get()-replays)
+	assert.EqualValues(t, 1, db.GetQueryCalledNum("select /* warmup */ * from test_table_01 limit 100"))
+
+	// The recorded queries survive until queries run again.
+	require.NoError(t, qe.warmer.record())
+	queries, err = qe.warmer.load()
+	require.NoError(t, err)
+	assert.Len(t, queries, 2)
+}
+
+func TestQueryWarmerInvalidFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "warmup.json")
+	require.NoError(t, os.WriteFile(file, []byte("not json"), 0o644))
+	qw := &queryWarmer{config: tabletenv.WarmupConfig{File: file}}
+	_, err := qw.load()
+	assert.ErrorContains(t, err, "invalid warmup file")
+}
+
+func uptimeResult(seconds int) *sqltypes.Result {
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("Variable_name|Value", "varchar|varchar"), fmt.Sprintf("Uptime|%d", seconds))
+}
@@ -115,6 +115,14 @@ type TabletManagerClient interface {
 
 	UnlockTables(ctx context.Context, tablet *topodatapb.Tablet) error
 
+	// AcquireSnapshotLock locks the tablet's instance so that an external tool
+	// can take a consistent snapshot of its data files. The lock is released
+	// by ReleaseSnapshotLock, or automatically after the requested timeout.
+	AcquireSnapshotLock(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.AcquireSnapshotLockRequest) (*tabletmanagerdatapb.AcquireSnapshotLockResponse, error)
+
+	// ReleaseSnapshotLock releases the lock acquired by AcquireSnapshotLock.
+	ReleaseSnapshotLock(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (*tabletmanagerdatapb.ReleaseSnapshotLockResponse, error)
+
 	// ExecuteQuery executes a query remotely on the tablet.
 	// req.DbName is ignored in favor of using the tablet's DbName field, and,
 	// if req.CallerId is nil, the effective callerid will be extracted from
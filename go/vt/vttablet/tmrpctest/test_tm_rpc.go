@@ -621,6 +621,58 @@ func tmRPCTestApplySchemaPanic(ctx context.Context, t *testing.T, client tmclien
 	expectHandleRPCPanic(t, "ApplySchema", true /*verbose*/, err)
 }
 
+var testAcquireSnapshotLockRequest = &tabletmanagerdatapb.AcquireSnapshotLockRequest{
+	Timeout: protoutil.DurationToProto(time.Minute),
+}
+var testAcquireSnapshotLockResponse = &tabletmanagerdatapb.AcquireSnapshotLockResponse{
+	LockStatement: "LOCK INSTANCE FOR BACKUP",
+	Position:      "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+	ExpireTime:    protoutil.TimeToProto(time.Unix(1700000000, 0)),
+}
+
+func (fra *fakeRPCTM) AcquireSnapshotLock(ctx context.Context, req *tabletmanagerdatapb.AcquireSnapshotLockRequest) (*tabletmanagerdatapb.AcquireSnapshotLockResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "AcquireSnapshotLock request", req, testAcquireSnapshotLockRequest)
+	return testAcquireSnapshotLockResponse, nil
+}
+
+func tmRPCTestAcquireSnapshotLock(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	resp, err := client.AcquireSnapshotLock(ctx, tablet, testAcquireSnapshotLockRequest)
+	compareError(t, "AcquireSnapshotLock", err, resp, testAcquireSnapshotLockResponse)
+}
+
+func tmRPCTestAcquireSnapshotLockPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.AcquireSnapshotLock(ctx, tablet, testAcquireSnapshotLockRequest)
+	expectHandleRPCPanic(t, "AcquireSnapshotLock", true /*verbose*/, err)
+}
+
+var testReleaseSnapshotLockCalled = false
+
+func (fra *fakeRPCTM) ReleaseSnapshotLock(ctx context.Context, req *tabletmanagerdatapb.ReleaseSnapshotLockRequest) (*tabletmanagerdatapb.ReleaseSnapshotLockResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	testReleaseSnapshotLockCalled = true
+	return &tabletmanagerdatapb.ReleaseSnapshotLockResponse{}, nil
+}
+
+func tmRPCTestReleaseSnapshotLock(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.ReleaseSnapshotLock(ctx, tablet, &tabletmanagerdatapb.ReleaseSnapshotLockRequest{})
+	if err != nil {
+		t.Errorf("ReleaseSnapshotLock failed: %v", err)
+	}
+	if !testReleaseSnapshotLockCalled {
+		t.Errorf("ReleaseSnapshotLock didn't call the server side")
+	}
+}
+
+func tmRPCTestReleaseSnapshotLockPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.ReleaseSnapshotLock(ctx, tablet, &tabletmanagerdatapb.ReleaseSnapshotLockRequest{})
+	expectHandleRPCPanic(t, "ReleaseSnapshotLock", true /*verbose*/, err)
+}
+
 var testExecuteQueryQuery = []byte("drop table t")
 
 func (fra *fakeRPCTM) ExecuteQuery(ctx context.Context, req *tabletmanagerdatapb.ExecuteQueryRequest) (*querypb.QueryResult, error) {
@@ -1401,6 +1453,8 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestReloadSchema(ctx, t, client, tablet)
 	tmRPCTestPreflightSchema(ctx, t, client, tablet)
 	tmRPCTestApplySchema(ctx, t, client, tablet)
+	tmRPCTestAcquireSnapshotLock(ctx, t, client, tablet)
+	tmRPCTestReleaseSnapshotLock(ctx, t, client, tablet)
 	tmRPCTestExecuteFetch(ctx, t, client, tablet)
 
 	// Replication related methods
@@ -1461,6 +1515,8 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestReloadSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestPreflightSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestApplySchemaPanic(ctx, t, client, tablet)
+	tmRPCTestAcquireSnapshotLockPanic(ctx, t, client, tablet)
+	tmRPCTestReleaseSnapshotLockPanic(ctx, t, client, tablet)
 	tmRPCTestExecuteFetchPanic(ctx, t, client, tablet)
 
 	// Replication related methods
@@ -156,6 +156,11 @@ type Config struct {
 	ExternalTopoGlobalRoot string
 
 	VtgateTabletRefreshInterval time.Duration
+
+	// FailoverSimulation runs vtcombo with a small embedded vtorc, and
+	// accepts fault injections, to test how the applications handle
+	// failovers. See the --failover-simulation flag of vtcombo.
+	FailoverSimulation bool
 }
 
 // InitSchemas is a shortcut for tests that just want to setup a single
@@ -255,6 +255,9 @@ func VtcomboProcess(environment Environment, args *Config, mysql MySQLManager) (
 	if args.PersistentMode && args.DataDir != "" {
 		vt.ExtraArgs = append(vt.ExtraArgs, []string{"--vschema-persistence-dir", path.Join(args.DataDir, "vschema_data")}...)
 	}
+	if args.FailoverSimulation {
+		vt.ExtraArgs = append(vt.ExtraArgs, "--failover-simulation")
+	}
 	if args.TransactionMode != "" {
 		vt.ExtraArgs = append(vt.ExtraArgs, []string{"--transaction_mode", args.TransactionMode}...)
 	}